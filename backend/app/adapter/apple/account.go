@@ -0,0 +1,66 @@
+package apple
+
+import (
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/sso"
+)
+
+var _ sso.Account = (*Account)(nil)
+
+// Account reads user's account data from the identity token issued by Apple.
+type Account struct {
+	publicKeys PublicKeys
+	timer      timer.Timer
+	clientID   string
+}
+
+// GetSingleSignOnUser verifies the identity token and retrieves the stable
+// user ID and email from it. Apple only shares the email the first time the
+// user authorizes Short, so the email is empty on later sign ins. The account
+// is already linked by then, which only relies on the user ID. Emails not
+// verified by Apple are ignored so that they can't be used to take over the
+// existing account with the same email. The identity token has to carry the
+// nonce of the sign in session, otherwise it may be issued for another sign
+// in and replayed.
+func (a Account) GetSingleSignOnUser(identityTokenStr string, session sso.Session) (entity.SSOUser, error) {
+	token, err := parseIdentityToken(identityTokenStr)
+	if err != nil {
+		return entity.SSOUser{}, err
+	}
+	if token.header.KeyID == "" {
+		return entity.SSOUser{}, ErrInvalidIdentityToken("key ID can't be empty")
+	}
+
+	publicKey, err := a.publicKeys.GetPublicKey(token.header.KeyID)
+	if err != nil {
+		return entity.SSOUser{}, err
+	}
+
+	err = token.verifySignature(publicKey)
+	if err != nil {
+		return entity.SSOUser{}, err
+	}
+
+	err = token.verifyClaims(a.clientID, session.Nonce, a.timer.Now())
+	if err != nil {
+		return entity.SSOUser{}, err
+	}
+
+	ssoUser := entity.SSOUser{ID: token.claims.Subject}
+	if token.claims.Email != "" && token.claims.isEmailVerified() {
+		ssoUser.Email = token.claims.Email
+		ssoUser.EmailVerified = true
+	}
+	return ssoUser, nil
+}
+
+// NewAccount creates Account which verifies identity tokens issued for the
+// app with the given client ID.
+func NewAccount(publicKeys PublicKeys, timer timer.Timer, clientID string) Account {
+	return Account{
+		publicKeys: publicKeys,
+		timer:      timer,
+		clientID:   clientID,
+	}
+}
@@ -0,0 +1,246 @@
+// +build integration all
+
+package apple
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/app/fw/webreq"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/sso"
+)
+
+// signingKey simulates the key pairs which Apple signs identity tokens with.
+type signingKey struct {
+	id         string
+	privateKey *rsa.PrivateKey
+}
+
+func (s signingKey) sign(t *testing.T, header map[string]interface{}, claims map[string]interface{}) string {
+	encode := func(v interface{}) string {
+		buf, err := json.Marshal(v)
+		assert.Equal(t, nil, err)
+		return base64.RawURLEncoding.EncodeToString(buf)
+	}
+
+	signed := encode(header) + "." + encode(claims)
+	hashed := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.privateKey, crypto.SHA256, hashed[:])
+	assert.Equal(t, nil, err)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func (s signingKey) signClaims(t *testing.T, claims map[string]interface{}) string {
+	header := map[string]interface{}{
+		"kid": s.id,
+		"alg": "RS256",
+	}
+	return s.sign(t, header, claims)
+}
+
+func newSigningKey(t *testing.T, id string) signingKey {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Equal(t, nil, err)
+	return signingKey{id: id, privateKey: privateKey}
+}
+
+func newPublicKeysResponse(t *testing.T, keys ...signingKey) *http.Response {
+	type jsonWebKey struct {
+		KeyType   string `json:"kty"`
+		KeyID     string `json:"kid"`
+		Use       string `json:"use"`
+		Algorithm string `json:"alg"`
+		Modulus   string `json:"n"`
+		Exponent  string `json:"e"`
+	}
+
+	webKeys := []jsonWebKey{}
+	for _, key := range keys {
+		publicKey := key.privateKey.PublicKey
+		webKeys = append(webKeys, jsonWebKey{
+			KeyType:   "RSA",
+			KeyID:     key.id,
+			Use:       "sig",
+			Algorithm: "RS256",
+			Modulus:   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+			Exponent:  base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+		})
+	}
+	buf, err := json.Marshal(map[string]interface{}{"keys": webKeys})
+	assert.Equal(t, nil, err)
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       ioutil.NopCloser(bytes.NewReader(buf)),
+	}
+}
+
+func TestAccount_GetSingleSignOnUser(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 6, 1, 8, 0, 0, 0, time.UTC)
+	clientID := "com.short-d.web"
+	appleKey := newSigningKey(t, "86D88Kf")
+	otherKey := newSigningKey(t, "eXaunmL")
+
+	validClaims := func() map[string]interface{} {
+		return map[string]interface{}{
+			"iss":            "https://appleid.apple.com",
+			"aud":            clientID,
+			"exp":            now.Add(10 * time.Minute).Unix(),
+			"iat":            now.Unix(),
+			"sub":            "001234.a1b2c3d4e5f6.0123",
+			"email":          "alpha@privaterelay.appleid.com",
+			"email_verified": "true",
+			"nonce":          "nonce_12345",
+		}
+	}
+	withClaim := func(key string, value interface{}) map[string]interface{} {
+		claims := validClaims()
+		claims[key] = value
+		return claims
+	}
+	withoutClaim := func(key string) map[string]interface{} {
+		claims := validClaims()
+		delete(claims, key)
+		return claims
+	}
+
+	testCases := []struct {
+		name            string
+		identityToken   string
+		expectHasErr    bool
+		expectedSSOUser entity.SSOUser
+	}{
+		{
+			name:          "first authorization with email",
+			identityToken: appleKey.signClaims(t, validClaims()),
+			expectHasErr:  false,
+			expectedSSOUser: entity.SSOUser{
+				ID:            "001234.a1b2c3d4e5f6.0123",
+				Email:         "alpha@privaterelay.appleid.com",
+				EmailVerified: true,
+			},
+		},
+		{
+			name:          "email verified encoded as boolean",
+			identityToken: appleKey.signClaims(t, withClaim("email_verified", true)),
+			expectHasErr:  false,
+			expectedSSOUser: entity.SSOUser{
+				ID:            "001234.a1b2c3d4e5f6.0123",
+				Email:         "alpha@privaterelay.appleid.com",
+				EmailVerified: true,
+			},
+		},
+		{
+			name:          "later authorization without email",
+			identityToken: appleKey.signClaims(t, withoutClaim("email")),
+			expectHasErr:  false,
+			expectedSSOUser: entity.SSOUser{
+				ID: "001234.a1b2c3d4e5f6.0123",
+			},
+		},
+		{
+			name:          "email not verified",
+			identityToken: appleKey.signClaims(t, withClaim("email_verified", "false")),
+			expectHasErr:  false,
+			expectedSSOUser: entity.SSOUser{
+				ID: "001234.a1b2c3d4e5f6.0123",
+			},
+		},
+		{
+			name:          "malformed token",
+			identityToken: "not-a-token",
+			expectHasErr:  true,
+		},
+		{
+			name:          "signed by unknown key",
+			identityToken: otherKey.signClaims(t, validClaims()),
+			expectHasErr:  true,
+		},
+		{
+			name: "signed by another key with Apple's key ID",
+			identityToken: otherKey.sign(t, map[string]interface{}{
+				"kid": appleKey.id,
+				"alg": "RS256",
+			}, validClaims()),
+			expectHasErr: true,
+		},
+		{
+			name: "unsupported algorithm",
+			identityToken: appleKey.sign(t, map[string]interface{}{
+				"kid": appleKey.id,
+				"alg": "none",
+			}, validClaims()),
+			expectHasErr: true,
+		},
+		{
+			name:          "unexpected issuer",
+			identityToken: appleKey.signClaims(t, withClaim("iss", "https://example.com")),
+			expectHasErr:  true,
+		},
+		{
+			name:          "issued for another app",
+			identityToken: appleKey.signClaims(t, withClaim("aud", "com.example.web")),
+			expectHasErr:  true,
+		},
+		{
+			name:          "token expired",
+			identityToken: appleKey.signClaims(t, withClaim("exp", now.Add(-time.Second).Unix())),
+			expectHasErr:  true,
+		},
+		{
+			name:          "subject missing",
+			identityToken: appleKey.signClaims(t, withoutClaim("sub")),
+			expectHasErr:  true,
+		},
+		{
+			name:          "issued for another sign in",
+			identityToken: appleKey.signClaims(t, withClaim("nonce", "nonce_67890")),
+			expectHasErr:  true,
+		},
+		{
+			name:          "nonce missing",
+			identityToken: appleKey.signClaims(t, withoutClaim("nonce")),
+			expectHasErr:  true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			httpRequest := webreq.NewHTTPFake(
+				func(req *http.Request) (response *http.Response, e error) {
+					assert.Equal(t, "https", req.URL.Scheme)
+					assert.Equal(t, "appleid.apple.com", req.URL.Host)
+					assert.Equal(t, "/auth/keys", req.URL.Path)
+					assert.Equal(t, "GET", req.Method)
+					return newPublicKeysResponse(t, appleKey), nil
+				})
+			tm := timer.NewStub(now)
+			account := NewAccount(NewPublicKeys(httpRequest, tm), tm, clientID)
+
+			gotSSOUser, err := account.GetSingleSignOnUser(testCase.identityToken, sso.Session{Nonce: "nonce_12345"})
+			if testCase.expectHasErr {
+				assert.NotEqual(t, nil, err)
+				return
+			}
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedSSOUser, gotSSOUser)
+		})
+	}
+}
@@ -0,0 +1,15 @@
+package apple
+
+// API represents Sign in with Apple client.
+type API struct {
+	IdentityProvider IdentityProvider
+	Account          Account
+}
+
+// NewAPI creates Sign in with Apple client.
+func NewAPI(identityProvider IdentityProvider, account Account) API {
+	return API{
+		IdentityProvider: identityProvider,
+		Account:          account,
+	}
+}
@@ -0,0 +1,63 @@
+package apple
+
+import (
+	"errors"
+	"net/url"
+
+	"github.com/short-d/short/backend/app/usecase/sso"
+)
+
+// More info here: https://developer.apple.com/documentation/sign_in_with_apple/sign_in_with_apple_js/incorporating_sign_in_with_apple_into_other_platforms
+const (
+	appleAuthorizationAPI = "https://appleid.apple.com/auth/authorize"
+	appleScopes           = "email"
+	appleResponseType     = "code id_token"
+	// Apple requires the authorization response to be posted to the redirect
+	// URI whenever any scope is requested.
+	appleResponseMode = "form_post"
+)
+
+var _ sso.IdentityProvider = (*IdentityProvider)(nil)
+
+// IdentityProvider represents Sign in with Apple service.
+type IdentityProvider struct {
+	clientID    string
+	redirectURI string
+}
+
+// GetAuthorizationURL retrieves the URL of Apple sign in page.
+func (i IdentityProvider) GetAuthorizationURL(session sso.Session) string {
+	u, err := url.Parse(appleAuthorizationAPI)
+	if err != nil {
+		return ""
+	}
+	query := u.Query()
+	query.Set("client_id", i.clientID)
+	query.Set("redirect_uri", i.redirectURI)
+	query.Set("scope", appleScopes)
+	query.Set("response_type", appleResponseType)
+	query.Set("response_mode", appleResponseMode)
+	query.Set("state", session.State)
+	query.Set("nonce", session.Nonce)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// RequestAccessToken returns the identity token posted by Apple to the
+// redirect URI as is. The identity token already proves the user's identity
+// once its signature is verified, so it is used in place of an access token
+// instead of being exchanged for one.
+func (i IdentityProvider) RequestAccessToken(identityToken string, session sso.Session) (accessToken string, err error) {
+	if identityToken == "" {
+		return "", errors.New("identity token can't be empty")
+	}
+	return identityToken, nil
+}
+
+// NewIdentityProvider initializes Sign in with Apple client.
+func NewIdentityProvider(clientID string, redirectURI string) IdentityProvider {
+	return IdentityProvider{
+		clientID:    clientID,
+		redirectURI: redirectURI,
+	}
+}
@@ -0,0 +1,73 @@
+// +build integration all
+
+package apple
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/short/backend/app/usecase/sso"
+)
+
+func TestIdentityProvider_GetAuthorizationURL(t *testing.T) {
+	t.Parallel()
+	clientID := "com.short-d.web"
+	redirectURI := "https://localhost/oauth/apple/sign-in/callback"
+	identityProvider := NewIdentityProvider(clientID, redirectURI)
+
+	session := sso.Session{State: "state_12345", CodeVerifier: "verifier_12345", Nonce: "nonce_12345"}
+	urlResponse := identityProvider.GetAuthorizationURL(session)
+
+	parsedURL, err := url.Parse(urlResponse)
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "https", parsedURL.Scheme)
+	assert.Equal(t, "appleid.apple.com", parsedURL.Host)
+	assert.Equal(t, "/auth/authorize", parsedURL.Path)
+	assert.Equal(t, "code id_token", parsedURL.Query().Get("response_type"))
+	assert.Equal(t, "form_post", parsedURL.Query().Get("response_mode"))
+	assert.Equal(t, "email", parsedURL.Query().Get("scope"))
+	assert.Equal(t, clientID, parsedURL.Query().Get("client_id"))
+	assert.Equal(t, redirectURI, parsedURL.Query().Get("redirect_uri"))
+	assert.Equal(t, session.State, parsedURL.Query().Get("state"))
+	assert.Equal(t, session.Nonce, parsedURL.Query().Get("nonce"))
+}
+
+func TestIdentityProvider_RequestAccessToken(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name                string
+		identityToken       string
+		expectHasErr        bool
+		expectedAccessToken string
+	}{
+		{
+			name:          "empty identity token",
+			identityToken: "",
+			expectHasErr:  true,
+		},
+		{
+			name:                "identity token provided",
+			identityToken:       "header.payload.signature",
+			expectHasErr:        false,
+			expectedAccessToken: "header.payload.signature",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			identityProvider := NewIdentityProvider("com.short-d.web", "https://localhost/oauth/apple/sign-in/callback")
+			accessToken, err := identityProvider.RequestAccessToken(testCase.identityToken, sso.Session{CodeVerifier: "verifier_12345"})
+			if testCase.expectHasErr {
+				assert.NotEqual(t, nil, err)
+				return
+			}
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedAccessToken, accessToken)
+		})
+	}
+}
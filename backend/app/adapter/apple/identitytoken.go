@@ -0,0 +1,135 @@
+package apple
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	appleIssuer          = "https://appleid.apple.com"
+	identityTokenSignAlg = "RS256"
+)
+
+// ErrInvalidIdentityToken represents the identity token is malformed, not
+// signed by Apple, issued for another app, or expired.
+type ErrInvalidIdentityToken string
+
+func (e ErrInvalidIdentityToken) Error() string {
+	return fmt.Sprintf("invalid identity token: %s", string(e))
+}
+
+type identityTokenHeader struct {
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+}
+
+// identityTokenClaims represents the claims about the user in the identity
+// token. Apple encodes email_verified as either a boolean or a string.
+type identityTokenClaims struct {
+	Issuer        string      `json:"iss"`
+	Audience      string      `json:"aud"`
+	ExpireAt      int64       `json:"exp"`
+	Subject       string      `json:"sub"`
+	Email         string      `json:"email"`
+	EmailVerified interface{} `json:"email_verified"`
+	Nonce         string      `json:"nonce"`
+}
+
+func (i identityTokenClaims) isEmailVerified() bool {
+	switch verified := i.EmailVerified.(type) {
+	case bool:
+		return verified
+	case string:
+		return verified == "true"
+	default:
+		return false
+	}
+}
+
+type identityToken struct {
+	header    identityTokenHeader
+	claims    identityTokenClaims
+	signed    string
+	signature []byte
+}
+
+func (i identityToken) verifySignature(publicKey *rsa.PublicKey) error {
+	hashed := sha256.Sum256([]byte(i.signed))
+	err := rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, hashed[:], i.signature)
+	if err != nil {
+		return ErrInvalidIdentityToken("signature mismatch")
+	}
+	return nil
+}
+
+func (i identityToken) verifyClaims(clientID string, nonce string, now time.Time) error {
+	if i.claims.Issuer != appleIssuer {
+		return ErrInvalidIdentityToken(fmt.Sprintf("unexpected issuer %s", i.claims.Issuer))
+	}
+	if i.claims.Audience != clientID {
+		return ErrInvalidIdentityToken(fmt.Sprintf("unexpected audience %s", i.claims.Audience))
+	}
+	expireAt := time.Unix(i.claims.ExpireAt, 0)
+	if !now.Before(expireAt) {
+		return ErrInvalidIdentityToken(fmt.Sprintf("expired at %v", expireAt))
+	}
+	if i.claims.Subject == "" {
+		return ErrInvalidIdentityToken("subject can't be empty")
+	}
+	if nonce == "" || subtle.ConstantTimeCompare([]byte(i.claims.Nonce), []byte(nonce)) != 1 {
+		return ErrInvalidIdentityToken("nonce mismatch")
+	}
+	return nil
+}
+
+func parseIdentityToken(token string) (identityToken, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return identityToken{}, ErrInvalidIdentityToken("expect 3 parts separated by dots")
+	}
+
+	var header identityTokenHeader
+	err := decodeTokenPart(parts[0], &header)
+	if err != nil {
+		return identityToken{}, err
+	}
+	if header.Algorithm != identityTokenSignAlg {
+		return identityToken{}, ErrInvalidIdentityToken(fmt.Sprintf("unexpected algorithm %s", header.Algorithm))
+	}
+
+	var claims identityTokenClaims
+	err = decodeTokenPart(parts[1], &claims)
+	if err != nil {
+		return identityToken{}, err
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return identityToken{}, ErrInvalidIdentityToken(err.Error())
+	}
+	return identityToken{
+		header:    header,
+		claims:    claims,
+		signed:    parts[0] + "." + parts[1],
+		signature: signature,
+	}, nil
+}
+
+func decodeTokenPart(part string, v interface{}) error {
+	buf, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return ErrInvalidIdentityToken(err.Error())
+	}
+	err = json.Unmarshal(buf, v)
+	if err != nil {
+		return ErrInvalidIdentityToken(err.Error())
+	}
+	return nil
+}
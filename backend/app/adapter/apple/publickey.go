@@ -0,0 +1,142 @@
+package apple
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/app/fw/webreq"
+)
+
+const (
+	applePublicKeysAPI = "https://appleid.apple.com/auth/keys"
+	// Apple rotates its public keys from time to time, so the cached keys are
+	// refreshed regularly and whenever a token is signed by an unknown key.
+	publicKeysCacheDuration = 24 * time.Hour
+	// Prevents tokens signed by made up keys from making Short fetch the
+	// public keys on every request.
+	publicKeysMinRefreshInterval = time.Minute
+)
+
+// ErrPublicKeyNotFound represents no public key of Apple matches the key ID
+// of the identity token.
+type ErrPublicKeyNotFound string
+
+func (e ErrPublicKeyNotFound) Error() string {
+	return fmt.Sprintf("public key not found: %s", string(e))
+}
+
+type publicKeyCache struct {
+	mutex     sync.Mutex
+	keys      map[string]*rsa.PublicKey
+	fetchedAt time.Time
+}
+
+// PublicKeys fetches the public keys used by Apple to sign identity tokens
+// from Apple's JSON Web Key Set endpoint and caches them.
+type PublicKeys struct {
+	httpRequest webreq.HTTP
+	timer       timer.Timer
+	cache       *publicKeyCache
+}
+
+// GetPublicKey retrieves the public key with the given key ID.
+func (p PublicKeys) GetPublicKey(keyID string) (*rsa.PublicKey, error) {
+	p.cache.mutex.Lock()
+	defer p.cache.mutex.Unlock()
+
+	now := p.timer.Now()
+	cacheAge := now.Sub(p.cache.fetchedAt)
+
+	key, ok := p.cache.keys[keyID]
+	if ok && cacheAge < publicKeysCacheDuration {
+		return key, nil
+	}
+	if !ok && p.cache.keys != nil && cacheAge < publicKeysMinRefreshInterval {
+		return nil, ErrPublicKeyNotFound(keyID)
+	}
+
+	keys, err := p.fetchPublicKeys()
+	if err != nil && ok {
+		// Keep signing users in with the stale key while Apple is unavailable.
+		return key, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	p.cache.keys = keys
+	p.cache.fetchedAt = now
+
+	key, ok = keys[keyID]
+	if !ok {
+		return nil, ErrPublicKeyNotFound(keyID)
+	}
+	return key, nil
+}
+
+func (p PublicKeys) fetchPublicKeys() (map[string]*rsa.PublicKey, error) {
+	type jsonWebKey struct {
+		KeyType   string `json:"kty"`
+		KeyID     string `json:"kid"`
+		Use       string `json:"use"`
+		Algorithm string `json:"alg"`
+		Modulus   string `json:"n"`
+		Exponent  string `json:"e"`
+	}
+	type jsonWebKeySet struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+
+	var keySet jsonWebKeySet
+	err := p.httpRequest.JSON(http.MethodGet, applePublicKeysAPI, map[string]string{}, "", &keySet)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, webKey := range keySet.Keys {
+		if webKey.KeyType != "RSA" || webKey.Use != "sig" {
+			continue
+		}
+		key, err := newRSAPublicKey(webKey.Modulus, webKey.Exponent)
+		if err != nil {
+			return nil, err
+		}
+		keys[webKey.KeyID] = key
+	}
+	return keys, nil
+}
+
+func newRSAPublicKey(modulus string, exponent string) (*rsa.PublicKey, error) {
+	modulusBytes, err := base64.RawURLEncoding.DecodeString(modulus)
+	if err != nil {
+		return nil, err
+	}
+	exponentBytes, err := base64.RawURLEncoding.DecodeString(exponent)
+	if err != nil {
+		return nil, err
+	}
+
+	e := new(big.Int).SetBytes(exponentBytes)
+	if !e.IsInt64() || e.Int64() > int64(^uint32(0)>>1) {
+		return nil, fmt.Errorf("public key exponent is too large: %s", exponent)
+	}
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(modulusBytes),
+		E: int(e.Int64()),
+	}, nil
+}
+
+// NewPublicKeys creates PublicKeys with an empty cache.
+func NewPublicKeys(httpRequest webreq.HTTP, timer timer.Timer) PublicKeys {
+	return PublicKeys{
+		httpRequest: httpRequest,
+		timer:       timer,
+		cache:       &publicKeyCache{},
+	}
+}
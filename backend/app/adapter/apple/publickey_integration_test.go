@@ -0,0 +1,74 @@
+// +build integration all
+
+package apple
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/app/fw/webreq"
+)
+
+func TestPublicKeys_GetPublicKey(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 6, 1, 8, 0, 0, 0, time.UTC)
+	oldKey := newSigningKey(t, "86D88Kf")
+	newKey := newSigningKey(t, "eXaunmL")
+
+	fetchCount := 0
+	appleKeys := []signingKey{oldKey}
+	var appleErr error
+	httpRequest := webreq.NewHTTPFake(
+		func(req *http.Request) (response *http.Response, e error) {
+			fetchCount++
+			if appleErr != nil {
+				return nil, appleErr
+			}
+			return newPublicKeysResponse(t, appleKeys...), nil
+		})
+	tm := timer.NewStub(now)
+	publicKeys := NewPublicKeys(httpRequest, &tm)
+
+	key, err := publicKeys.GetPublicKey(oldKey.id)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, oldKey.privateKey.PublicKey, *key)
+	assert.Equal(t, 1, fetchCount)
+
+	tm.CurrentTime = now.Add(time.Hour)
+	key, err = publicKeys.GetPublicKey(oldKey.id)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, oldKey.privateKey.PublicKey, *key)
+	assert.Equal(t, 1, fetchCount)
+
+	// Apple rotates its keys.
+	appleKeys = []signingKey{oldKey, newKey}
+	key, err = publicKeys.GetPublicKey(newKey.id)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, newKey.privateKey.PublicKey, *key)
+	assert.Equal(t, 2, fetchCount)
+
+	tm.CurrentTime = now.Add(time.Hour + 10*time.Second)
+	_, err = publicKeys.GetPublicKey("unknown")
+	assert.Equal(t, ErrPublicKeyNotFound("unknown"), err)
+	assert.Equal(t, 2, fetchCount)
+
+	tm.CurrentTime = now.Add(2 * time.Hour)
+	_, err = publicKeys.GetPublicKey("unknown")
+	assert.Equal(t, ErrPublicKeyNotFound("unknown"), err)
+	assert.Equal(t, 3, fetchCount)
+
+	tm.CurrentTime = now.Add(48 * time.Hour)
+	appleErr = errors.New("service unavailable")
+	key, err = publicKeys.GetPublicKey(newKey.id)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, newKey.privateKey.PublicKey, *key)
+	assert.Equal(t, 4, fetchCount)
+
+	_, err = publicKeys.GetPublicKey("unknown")
+	assert.NotEqual(t, nil, err)
+}
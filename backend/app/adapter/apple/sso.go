@@ -0,0 +1,9 @@
+package apple
+
+import "github.com/short-d/short/backend/app/usecase/sso"
+
+// AccountLinker links user's Apple account with Short account.
+type AccountLinker sso.AccountLinker
+
+// SingleSignOn enables users to sign in through their Apple account.
+type SingleSignOn sso.SingleSignOn
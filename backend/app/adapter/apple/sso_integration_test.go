@@ -0,0 +1,153 @@
+// +build integration all
+
+package apple
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/crypto"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/app/fw/webreq"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/authenticator"
+	"github.com/short-d/short/backend/app/usecase/email"
+	"github.com/short-d/short/backend/app/usecase/keygen"
+	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/sso"
+	"github.com/short-d/short/backend/app/usecase/verification"
+)
+
+func TestSingleSignOn_SignIn(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 6, 1, 8, 0, 0, 0, time.UTC)
+	clientID := "com.short-d.web"
+	appleKey := newSigningKey(t, "86D88Kf")
+
+	firstAuthorization := appleKey.signClaims(t, map[string]interface{}{
+		"iss":            "https://appleid.apple.com",
+		"aud":            clientID,
+		"exp":            now.Add(10 * time.Minute).Unix(),
+		"sub":            "001234.a1b2c3d4e5f6.0123",
+		"email":          "alpha@example.com",
+		"email_verified": "true",
+		"nonce":          "nonce_12345",
+	})
+	laterAuthorization := appleKey.signClaims(t, map[string]interface{}{
+		"iss":   "https://appleid.apple.com",
+		"aud":   clientID,
+		"exp":   now.Add(10 * time.Minute).Unix(),
+		"sub":   "001234.a1b2c3d4e5f6.0123",
+		"nonce": "nonce_12345",
+	})
+
+	testCases := []struct {
+		name           string
+		identityTokens []string
+		users          []entity.User
+		availableKeys  []keygen.Key
+		expectedUser   entity.User
+	}{
+		{
+			name:           "account with same email found",
+			identityTokens: []string{firstAuthorization},
+			users: []entity.User{
+				{
+					ID:            "alpha",
+					Email:         "alpha@example.com",
+					EmailVerified: true,
+				},
+			},
+			expectedUser: entity.User{
+				ID:            "alpha",
+				Email:         "alpha@example.com",
+				EmailVerified: true,
+			},
+		},
+		{
+			name:           "apple no longer shares email after first authorization",
+			identityTokens: []string{firstAuthorization, laterAuthorization},
+			users:          []entity.User{},
+			availableKeys:  []keygen.Key{"beta", "gamma"},
+			expectedUser: entity.User{
+				ID:            "beta",
+				Email:         "alpha@example.com",
+				EmailVerified: true,
+			},
+		},
+		{
+			name:           "account linked before email is lost",
+			identityTokens: []string{laterAuthorization},
+			users: []entity.User{
+				{
+					ID:            "alpha",
+					Email:         "alpha@example.com",
+					EmailVerified: true,
+				},
+			},
+			availableKeys: []keygen.Key{"beta"},
+			expectedUser: entity.User{
+				ID: "beta",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			httpRequest := webreq.NewHTTPFake(
+				func(req *http.Request) (response *http.Response, e error) {
+					return newPublicKeysResponse(t, appleKey), nil
+				})
+			tm := timer.NewStub(now)
+			identityProvider := NewIdentityProvider(clientID, "https://localhost/oauth/apple/sign-in/callback")
+			account := NewAccount(NewPublicKeys(httpRequest, tm), tm, clientID)
+
+			auth := authenticator.NewAuthenticatorFake(now, time.Minute)
+
+			keyFetcher := keygen.NewKeyFetcherFake(testCase.availableKeys)
+			keyGen, err := keygen.NewRemote(1, &keyFetcher)
+			assert.Equal(t, nil, err)
+
+			userRepo := repository.NewUserFake(testCase.users)
+			emailVerifier := verification.NewEmailVerifier(
+				crypto.NewTokenizerFake(),
+				tm,
+				&userRepo,
+				email.NewSenderFake(nil),
+				url.URL{Scheme: "https", Host: "short-d.com"},
+				24*time.Hour,
+			)
+			ssoMap, err := repository.NewsSSOMapFake([]string{}, []string{})
+			assert.Equal(t, nil, err)
+
+			linker := sso.NewAccountLinkerFactory(keyGen, &userRepo, emailVerifier).NewAccountLinker(&ssoMap)
+			singleSignOn := sso.NewFactory(auth).NewSingleSignOn(identityProvider, account, linker)
+
+			for _, identityToken := range testCase.identityTokens {
+				authToken, err := singleSignOn.SignIn(identityToken, "state_12345", sso.Session{State: "state_12345", Nonce: "nonce_12345"})
+				assert.Equal(t, nil, err)
+
+				payload := map[string]string{}
+				err = json.Unmarshal([]byte(authToken), &payload)
+				assert.Equal(t, nil, err)
+				assert.Equal(t, testCase.expectedUser.ID, payload["id"])
+			}
+
+			user, err := userRepo.GetUserByID(testCase.expectedUser.ID)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedUser, user)
+
+			shortUserID, err := ssoMap.GetShortUserID("001234.a1b2c3d4e5f6.0123")
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedUser.ID, shortUserID)
+		})
+	}
+}
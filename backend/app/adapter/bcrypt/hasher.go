@@ -0,0 +1,33 @@
+package bcrypt
+
+import (
+	"github.com/short-d/short/backend/app/usecase/emailpassword"
+	xbcrypt "golang.org/x/crypto/bcrypt"
+)
+
+var _ emailpassword.PasswordHasher = (*Hasher)(nil)
+
+// Hasher hashes passwords with bcrypt.
+type Hasher struct {
+	cost int
+}
+
+// Hash derives a salted bcrypt hash from the password.
+func (h Hasher) Hash(password string) (string, error) {
+	hash, err := xbcrypt.GenerateFromPassword([]byte(password), h.cost)
+	return string(hash), err
+}
+
+// Verify checks whether the bcrypt hash is derived from the password.
+func (h Hasher) Verify(passwordHash string, password string) (bool, error) {
+	err := xbcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(password))
+	if err == xbcrypt.ErrMismatchedHashAndPassword {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// NewHasher creates bcrypt password hasher with the given cost.
+func NewHasher(cost int) Hasher {
+	return Hasher{cost: cost}
+}
@@ -0,0 +1,63 @@
+// +build !integration all
+
+package bcrypt
+
+import (
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+	xbcrypt "golang.org/x/crypto/bcrypt"
+)
+
+func TestHasher_Verify(t *testing.T) {
+	t.Parallel()
+
+	hasher := NewHasher(xbcrypt.MinCost)
+	hash, err := hasher.Hash("correct horse 1")
+	assert.Equal(t, nil, err)
+	assert.NotEqual(t, "correct horse 1", hash)
+
+	testCases := []struct {
+		name          string
+		passwordHash  string
+		password      string
+		hasErr        bool
+		expectedMatch bool
+	}{
+		{
+			name:          "correct password",
+			passwordHash:  hash,
+			password:      "correct horse 1",
+			hasErr:        false,
+			expectedMatch: true,
+		},
+		{
+			name:          "wrong password",
+			passwordHash:  hash,
+			password:      "wrong horse 1",
+			hasErr:        false,
+			expectedMatch: false,
+		},
+		{
+			name:         "malformed hash",
+			passwordHash: "correct horse 1",
+			password:     "correct horse 1",
+			hasErr:       true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			isMatched, err := hasher.Verify(testCase.passwordHash, testCase.password)
+			if testCase.hasErr {
+				assert.NotEqual(t, nil, err)
+				return
+			}
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedMatch, isMatched)
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package dispatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/short-d/app/fw/logger"
+	"github.com/short-d/short/backend/app/usecase/webhook"
+)
+
+var _ webhook.Dispatcher = (*HTTP)(nil)
+
+// ErrUnexpectedStatus represents the webhook responds with a status code
+// other than 2xx.
+type ErrUnexpectedStatus int
+
+func (e ErrUnexpectedStatus) Error() string {
+	return fmt.Sprintf("unexpected status code: %d", int(e))
+}
+
+// HTTP delivers events to a webhook by posting them as JSON.
+type HTTP struct {
+	client     http.Client
+	logger     logger.Logger
+	webhookURL string
+}
+
+// Dispatch posts the event in the background and logs the failure, if any.
+// Events are dropped when the webhook is not configured.
+func (h HTTP) Dispatch(event webhook.Event) {
+	if h.webhookURL == "" {
+		return
+	}
+	go func() {
+		err := h.post(event)
+		if err != nil {
+			h.logger.Error(fmt.Errorf("fail to dispatch %s event: %v", event.Type, err))
+		}
+	}()
+}
+
+func (h HTTP) post(event webhook.Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, h.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		return ErrUnexpectedStatus(res.StatusCode)
+	}
+	return nil
+}
+
+// NewHTTP creates HTTP. Each delivery is abandoned after timeout.
+func NewHTTP(logger logger.Logger, webhookURL string, timeout time.Duration) HTTP {
+	return HTTP{
+		client:     http.Client{Timeout: timeout},
+		logger:     logger,
+		webhookURL: webhookURL,
+	}
+}
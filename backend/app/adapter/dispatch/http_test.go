@@ -0,0 +1,75 @@
+// +build !integration all
+
+package dispatch
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/logger"
+	"github.com/short-d/short/backend/app/usecase/webhook"
+)
+
+func TestHTTP_post(t *testing.T) {
+	t.Parallel()
+
+	event := webhook.Event{
+		Type:       webhook.LinkFlagged,
+		OccurredAt: time.Date(2020, 6, 1, 8, 0, 0, 0, time.UTC),
+		Data: webhook.LinkFlaggedData{
+			LongLink: "http://malware.example.com",
+			UserID:   "alpha",
+		},
+	}
+
+	testCases := []struct {
+		name         string
+		statusCode   int
+		expectedBody string
+		expectedErr  error
+	}{
+		{
+			name:       "delivered",
+			statusCode: http.StatusNoContent,
+			expectedBody: `{"type":"link.flagged","occurred_at":"2020-06-01T08:00:00Z",` +
+				`"data":{"long_link":"http://malware.example.com","user_id":"alpha"}}`,
+		},
+		{
+			name:       "rejected by webhook",
+			statusCode: http.StatusInternalServerError,
+			expectedBody: `{"type":"link.flagged","occurred_at":"2020-06-01T08:00:00Z",` +
+				`"data":{"long_link":"http://malware.example.com","user_id":"alpha"}}`,
+			expectedErr: ErrUnexpectedStatus(http.StatusInternalServerError),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			var body, contentType string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				buf, _ := ioutil.ReadAll(r.Body)
+				body = string(buf)
+				contentType = r.Header.Get("Content-Type")
+				w.WriteHeader(testCase.statusCode)
+			}))
+			defer server.Close()
+
+			entryRepo := logger.NewEntryRepoFake()
+			lg, err := logger.NewFake(logger.LogOff, &entryRepo)
+			assert.Equal(t, nil, err)
+
+			dispatcher := NewHTTP(lg, server.URL, time.Second)
+			err = dispatcher.post(event)
+			assert.Equal(t, testCase.expectedErr, err)
+			assert.Equal(t, testCase.expectedBody, body)
+			assert.Equal(t, "application/json", contentType)
+		})
+	}
+}
@@ -19,7 +19,7 @@ type Account struct {
 }
 
 // GetSingleSignOnUser retrieves user's email and name from Facebook API.
-func (g Account) GetSingleSignOnUser(accessToken string) (entity.SSOUser, error) {
+func (g Account) GetSingleSignOnUser(accessToken string, session sso.Session) (entity.SSOUser, error) {
 	type response struct {
 		ID    string `json:"id"`
 		Email string `json:"email"`
@@ -12,6 +12,7 @@ import (
 	"github.com/short-d/app/fw/assert"
 	"github.com/short-d/app/fw/webreq"
 	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/sso"
 )
 
 func TestAccount_GetSingleSignOnUser(t *testing.T) {
@@ -84,7 +85,7 @@ func TestAccount_GetSingleSignOnUser(t *testing.T) {
 				})
 			facebookAccount := NewAccount(httpRequest)
 
-			gotSSOUser, err := facebookAccount.GetSingleSignOnUser("access_token")
+			gotSSOUser, err := facebookAccount.GetSingleSignOnUser("access_token", sso.Session{})
 
 			if testCase.expectHasErr {
 				assert.NotEqual(t, nil, err)
@@ -19,7 +19,7 @@ type Account struct {
 }
 
 // GetSingleSignOnUser retrieves user's email and name from Github.
-func (a Account) GetSingleSignOnUser(accessToken string) (entity.SSOUser, error) {
+func (a Account) GetSingleSignOnUser(accessToken string, session sso.Session) (entity.SSOUser, error) {
 	type response struct {
 		Viewer struct {
 			ID    string `json:"id"`
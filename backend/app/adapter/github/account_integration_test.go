@@ -12,6 +12,7 @@ import (
 	"github.com/short-d/app/fw/assert"
 	"github.com/short-d/app/fw/graphql"
 	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/sso"
 )
 
 func TestAccount_GetSingleSignOnUser(t *testing.T) {
@@ -92,7 +93,7 @@ func TestAccount_GetSingleSignOnUser(t *testing.T) {
 				})
 			githubAccount := NewAccount(graphQLClientFactory)
 
-			gotSSOUser, err := githubAccount.GetSingleSignOnUser("access_token")
+			gotSSOUser, err := githubAccount.GetSingleSignOnUser("access_token", sso.Session{})
 			if testCase.expectHasErr {
 				assert.NotEqual(t, nil, err)
 				return
@@ -32,18 +32,19 @@ type IdentityProvider struct {
 }
 
 // GetAuthorizationURL retrieves the URL of Github sign in page.
-func (g IdentityProvider) GetAuthorizationURL() string {
+func (g IdentityProvider) GetAuthorizationURL(session sso.Session) string {
 	scopes := strings.Join([]string{
 		readUserProfileScope,
 	}, " ")
 	escapedScope := url.QueryEscape(scopes)
 	clientID := g.clientID
-	return fmt.Sprintf("%s?client_id=%s&scope=%s", authorizationAPI, clientID, escapedScope)
+	state := url.QueryEscape(session.State)
+	return fmt.Sprintf("%s?client_id=%s&scope=%s&state=%s", authorizationAPI, clientID, escapedScope, state)
 }
 
 // RequestAccessToken retrieves access token of user's Github account using
 // authorization code.
-func (g IdentityProvider) RequestAccessToken(authorizationCode string) (accessToken string, err error) {
+func (g IdentityProvider) RequestAccessToken(authorizationCode string, session sso.Session) (accessToken string, err error) {
 	clientID := g.clientID
 	clientSecret := g.clientSecret
 
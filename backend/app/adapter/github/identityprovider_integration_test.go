@@ -12,6 +12,7 @@ import (
 
 	"github.com/short-d/app/fw/assert"
 	"github.com/short-d/app/fw/webreq"
+	"github.com/short-d/short/backend/app/usecase/sso"
 )
 
 func TestIdentityProvider_GetAuthorizationURL(t *testing.T) {
@@ -24,7 +25,8 @@ func TestIdentityProvider_GetAuthorizationURL(t *testing.T) {
 	clientSecret := "client_secret"
 	identityProvider := NewIdentityProvider(httpRequest, clientID, clientSecret)
 
-	urlResponse := identityProvider.GetAuthorizationURL()
+	session := sso.Session{State: "state_12345", CodeVerifier: "verifier_12345"}
+	urlResponse := identityProvider.GetAuthorizationURL(session)
 
 	parsedUrl, err := url.Parse(urlResponse)
 	assert.Equal(t, nil, err)
@@ -32,6 +34,7 @@ func TestIdentityProvider_GetAuthorizationURL(t *testing.T) {
 	assert.Equal(t, "github.com", parsedUrl.Host)
 	assert.Equal(t, "/login/oauth/authorize", parsedUrl.Path)
 	assert.Equal(t, clientID, parsedUrl.Query().Get("client_id"))
+	assert.Equal(t, session.State, parsedUrl.Query().Get("state"))
 	assert.Equal(t, "read:user", parsedUrl.Query().Get("scope"))
 }
 
@@ -115,7 +118,7 @@ func TestIdentityProvider_RequestAccessToken(t *testing.T) {
 				})
 			identityProvider := NewIdentityProvider(httpRequest, testCase.clientID, testCase.clientSecret)
 
-			actualAccessToken, err := identityProvider.RequestAccessToken(testCase.authorizationCode)
+			actualAccessToken, err := identityProvider.RequestAccessToken(testCase.authorizationCode, sso.Session{CodeVerifier: "verifier_12345"})
 
 			if testCase.expectHasErr {
 				assert.NotEqual(t, nil, err)
@@ -19,12 +19,13 @@ type Account struct {
 }
 
 // GetSingleSignOnUser retrieves user's email and name from Google API.
-func (a Account) GetSingleSignOnUser(accessToken string) (entity.SSOUser, error) {
+func (a Account) GetSingleSignOnUser(accessToken string, session sso.Session) (entity.SSOUser, error) {
 	// https://developers.google.com/identity/protocols/OpenIDConnect#obtainuserinfo
 	type response struct {
-		Email string `json:"email"`
-		Name  string `json:"name"`
-		ID    string `json:"sub"`
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+		Name          string `json:"name"`
+		ID            string `json:"sub"`
 	}
 
 	var res response
@@ -38,9 +39,10 @@ func (a Account) GetSingleSignOnUser(accessToken string) (entity.SSOUser, error)
 	}
 
 	return entity.SSOUser{
-		Email: res.Email,
-		Name:  res.Name,
-		ID:    res.ID,
+		Email:         res.Email,
+		EmailVerified: res.EmailVerified,
+		Name:          res.Name,
+		ID:            res.ID,
 	}, nil
 }
 
@@ -12,6 +12,7 @@ import (
 	"github.com/short-d/app/fw/assert"
 	"github.com/short-d/app/fw/webreq"
 	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/sso"
 )
 
 func TestAccount_GetSingleSignOnUser(t *testing.T) {
@@ -37,15 +38,17 @@ func TestAccount_GetSingleSignOnUser(t *testing.T) {
 {
       "sub": "bcBi3AMeOV3Zg3AlOPyn",
       "name": "Google User",
-      "email": "googleUser@gmail.com"
+      "email": "googleUser@gmail.com",
+      "email_verified": true
 }
 `,
 				)))},
 			expectHasErr: false,
 			expectedSSOUser: entity.SSOUser{
-				ID:    "bcBi3AMeOV3Zg3AlOPyn",
-				Name:  "Google User",
-				Email: "googleUser@gmail.com",
+				ID:            "bcBi3AMeOV3Zg3AlOPyn",
+				Name:          "Google User",
+				Email:         "googleUser@gmail.com",
+				EmailVerified: true,
 			},
 		},
 		{
@@ -84,7 +87,7 @@ func TestAccount_GetSingleSignOnUser(t *testing.T) {
 				})
 			googleAccount := NewAccount(httpRequest)
 
-			gotSSOUser, err := googleAccount.GetSingleSignOnUser("access_token")
+			gotSSOUser, err := googleAccount.GetSingleSignOnUser("access_token", sso.Session{})
 
 			if testCase.expectHasErr {
 				assert.NotEqual(t, nil, err)
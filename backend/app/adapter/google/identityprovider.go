@@ -32,7 +32,7 @@ type IdentityProvider struct {
 }
 
 // GetAuthorizationURL retrieves the URL of Google sign in page.
-func (g IdentityProvider) GetAuthorizationURL() string {
+func (g IdentityProvider) GetAuthorizationURL(session sso.Session) string {
 	clientID := g.clientID
 	redirectURI := g.redirectURI
 
@@ -49,6 +49,7 @@ func (g IdentityProvider) GetAuthorizationURL() string {
 	query.Set("scope", strings.Join(scopes, " "))
 	query.Set("include_granted_scopes", "true")
 	query.Set("response_type", "code")
+	query.Set("state", session.State)
 	u.RawQuery = query.Encode()
 
 	return u.String()
@@ -56,7 +57,7 @@ func (g IdentityProvider) GetAuthorizationURL() string {
 
 // RequestAccessToken retrieves access token of user's Google account using
 // authorization code.
-func (g IdentityProvider) RequestAccessToken(authorizationCode string) (string, error) {
+func (g IdentityProvider) RequestAccessToken(authorizationCode string, session sso.Session) (string, error) {
 	grantType := "authorization_code"
 	clientID := g.clientID
 	clientSecret := g.clientSecret
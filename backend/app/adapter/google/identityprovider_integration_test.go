@@ -12,6 +12,7 @@ import (
 
 	"github.com/short-d/app/fw/assert"
 	"github.com/short-d/app/fw/webreq"
+	"github.com/short-d/short/backend/app/usecase/sso"
 )
 
 func TestIdentityProvider_GetAuthorizationURL(t *testing.T) {
@@ -25,7 +26,8 @@ func TestIdentityProvider_GetAuthorizationURL(t *testing.T) {
 	redirectURI := "http://localhost/oauth/google/sign-in/callback"
 	identityProvider := NewIdentityProvider(httpRequest, clientID, clientSecret, redirectURI)
 
-	urlResponse := identityProvider.GetAuthorizationURL()
+	session := sso.Session{State: "state_12345", CodeVerifier: "verifier_12345"}
+	urlResponse := identityProvider.GetAuthorizationURL(session)
 
 	parsedUrl, err := url.Parse(urlResponse)
 	assert.Equal(t, nil, err)
@@ -36,6 +38,7 @@ func TestIdentityProvider_GetAuthorizationURL(t *testing.T) {
 	assert.Equal(t, "email profile", parsedUrl.Query().Get("scope"))
 	assert.Equal(t, "code", parsedUrl.Query().Get("response_type"))
 	assert.Equal(t, clientID, parsedUrl.Query().Get("client_id"))
+	assert.Equal(t, session.State, parsedUrl.Query().Get("state"))
 	assert.Equal(t, redirectURI, parsedUrl.Query().Get("redirect_uri"))
 }
 
@@ -138,7 +141,7 @@ func TestIdentityProvider_RequestAccessToken(t *testing.T) {
 				})
 			identityProvider := NewIdentityProvider(httpRequest, testCase.clientID, testCase.clientSecret, testCase.redirectURI)
 
-			actualAccessToken, err := identityProvider.RequestAccessToken(testCase.authorizationCode)
+			actualAccessToken, err := identityProvider.RequestAccessToken(testCase.authorizationCode, sso.Session{CodeVerifier: "verifier_12345"})
 
 			if testCase.expectHasErr {
 				assert.NotEqual(t, nil, err)
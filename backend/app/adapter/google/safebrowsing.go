@@ -76,8 +76,18 @@ type SafeBrowsing struct {
 	httpRequest webreq.HTTP
 }
 
-// HasURL checks whether a given URL is blacklisted by Google.
-func (s SafeBrowsing) HasURL(url string) (bool, error) {
+// threatScores ranks the threats confirmed by Google above the threats only
+// potentially harmful.
+var threatScores = map[threatType]risk.Score{
+	malware:               risk.ScoreMalicious,
+	socialEngineering:     risk.ScoreMalicious,
+	potentiallyHarmfulApp: risk.ScoreSuspicious,
+	unwantedSoftware:      risk.ScoreSuspicious,
+}
+
+// GetURLScore retrieves the score of a given URL based on the most severe
+// threat Google found.
+func (s SafeBrowsing) GetURLScore(url string) (risk.Score, error) {
 	api := s.auth(safeBrowsingLookupAPI)
 	body := lookupAPIRequest{
 		ThreatInfo: threatInfo{
@@ -98,7 +108,7 @@ func (s SafeBrowsing) HasURL(url string) (bool, error) {
 
 	buf, err := json.Marshal(body)
 	if err != nil {
-		return false, err
+		return risk.ScoreSafe, err
 	}
 
 	headers := map[string]string{
@@ -108,9 +118,20 @@ func (s SafeBrowsing) HasURL(url string) (bool, error) {
 	res := lookupAPIResponse{}
 	err = s.httpRequest.JSON(http.MethodPost, api, headers, string(buf), &res)
 	if err != nil {
-		return false, err
+		return risk.ScoreSafe, err
+	}
+
+	score := risk.ScoreSafe
+	for _, match := range res.Matches {
+		threatScore, ok := threatScores[match.ThreatType]
+		if !ok {
+			threatScore = risk.ScoreSuspicious
+		}
+		if threatScore > score {
+			score = threatScore
+		}
 	}
-	return len(res.Matches) > 0, nil
+	return score, nil
 }
 
 func (s SafeBrowsing) auth(baseURL string) string {
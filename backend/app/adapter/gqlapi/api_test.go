@@ -3,27 +3,40 @@
 package gqlapi
 
 import (
+	"net/url"
 	"testing"
 	"time"
 
 	"github.com/short-d/app/fw/assert"
 	"github.com/short-d/app/fw/graphql"
 	"github.com/short-d/app/fw/logger"
+	"github.com/short-d/app/fw/metrics"
 	"github.com/short-d/app/fw/timer"
 	"github.com/short-d/short/backend/app/adapter/gqlapi/resolver"
 	"github.com/short-d/short/backend/app/entity"
 	"github.com/short-d/short/backend/app/fw/filesystem"
+	"github.com/short-d/short/backend/app/fw/ipallow"
 	"github.com/short-d/short/backend/app/usecase/authenticator"
 	"github.com/short-d/short/backend/app/usecase/authorizer"
 	"github.com/short-d/short/backend/app/usecase/authorizer/rbac"
 	"github.com/short-d/short/backend/app/usecase/authorizer/rbac/role"
 	"github.com/short-d/short/backend/app/usecase/changelog"
+	"github.com/short-d/short/backend/app/usecase/email"
+	"github.com/short-d/short/backend/app/usecase/featureflag"
 	"github.com/short-d/short/backend/app/usecase/keygen"
+	"github.com/short-d/short/backend/app/usecase/linkhealth"
+	"github.com/short-d/short/backend/app/usecase/maintenance"
+	"github.com/short-d/short/backend/app/usecase/preference"
+	"github.com/short-d/short/backend/app/usecase/ratelimit"
 	"github.com/short-d/short/backend/app/usecase/repository"
 	"github.com/short-d/short/backend/app/usecase/requester"
 	"github.com/short-d/short/backend/app/usecase/risk"
+	"github.com/short-d/short/backend/app/usecase/share"
 	"github.com/short-d/short/backend/app/usecase/shortlink"
+	"github.com/short-d/short/backend/app/usecase/stats"
 	"github.com/short-d/short/backend/app/usecase/validator"
+	"github.com/short-d/short/backend/app/usecase/visit"
+	"github.com/short-d/short/backend/app/usecase/webhook"
 )
 
 func TestGraphQlAPI(t *testing.T) {
@@ -34,16 +47,18 @@ func TestGraphQlAPI(t *testing.T) {
 
 	shortLinkRepo := repository.NewShortLinkFake(nil, map[string]entity.ShortLink{})
 	userShortLinkRepo := repository.NewUserShortLinkRepoFake([]entity.User{}, []entity.ShortLink{})
-	retriever := shortlink.NewRetrieverPersist(&shortLinkRepo, &userShortLinkRepo)
+	retriever := shortlink.NewRetrieverPersist(&shortLinkRepo, &userShortLinkRepo, 0)
 	keyFetcher := keygen.NewKeyFetcherFake([]keygen.Key{})
-	keyGen, err := keygen.NewKeyGenerator(2, &keyFetcher)
+	keyGen, err := keygen.NewRemote(2, &keyFetcher)
 	assert.Equal(t, nil, err)
 
-	longLinkValidator := validator.NewLongLink()
+	longLinkValidator := validator.NewLongLink(nil, nil, validator.FragmentPreserve, validator.PlainHTTPAllow)
 	customAliasValidator := validator.NewCustomAlias()
 	tm := timer.NewStub(now)
-	riskDetector := risk.NewDetector(blacklist)
+	riskDetector := risk.NewDetector(blacklist, risk.NewDenylistFake(nil), risk.StrictThresholds)
+	flaggedLinkRepo := repository.NewFlaggedShortLinkFake(nil)
 
+	preferencesRepo := repository.NewUserPreferencesFake(nil)
 	creator := shortlink.NewCreatorPersist(
 		&shortLinkRepo,
 		&userShortLinkRepo,
@@ -52,6 +67,23 @@ func TestGraphQlAPI(t *testing.T) {
 		customAliasValidator,
 		tm,
 		riskDetector,
+		&flaggedLinkRepo,
+		shortlink.DefaultChecks,
+		shortlink.LongLinkUniquenessNone,
+		shortlink.AliasQuota{},
+		authorizer.Authorizer{},
+		email.NewSenderFake(nil),
+		webhook.NewDispatcherFake(),
+		&preferencesRepo,
+		maintenance.Mode{},
+		metrics.NewFake(),
+		0,
+		shortlink.ExpirationPolicy{},
+		&repository.AliasSkeletonFake{},
+		shortlink.RelationRetry{},
+		&repository.AliasReservationFake{},
+		shortlink.ReservationTTL{},
+		shortlink.ChainedLink{},
 	)
 
 	updater := shortlink.NewUpdaterPersist(
@@ -61,7 +93,15 @@ func TestGraphQlAPI(t *testing.T) {
 		customAliasValidator,
 		tm,
 		riskDetector,
+		shortlink.DefaultChecks,
+		&repository.AliasSkeletonFake{},
+		&repository.FlaggedShortLinkFake{},
+		email.NewSenderFake(nil),
+		maintenance.Mode{},
+		&repository.AliasReservationFake{},
+		shortlink.ChainedLink{},
 	)
+	deleter := shortlink.NewDeleterPersist(&shortLinkRepo, &userShortLinkRepo, tm, maintenance.Mode{})
 
 	s := requester.NewReCaptchaFake(requester.VerifyResponse{})
 	verifier := requester.NewReCaptchaVerifier(s)
@@ -77,7 +117,58 @@ func TestGraphQlAPI(t *testing.T) {
 	rb := rbac.NewRBAC(fakeRolesRepo)
 	au := authorizer.NewAuthorizer(rb)
 	changeLog := changelog.NewPersist(keyGen, tm, &changeLogRepo, &userChangeLogRepo, au)
-	r := resolver.NewResolver(lg, retriever, creator, updater, changeLog, verifier, auth)
+	shortLinkAuditRepo := repository.NewShortLinkAuditFake(nil)
+	expirer := shortlink.NewExpirerPersist(&shortLinkRepo, &shortLinkAuditRepo, au, tm, maintenance.Mode{})
+	baseURL, err := url.Parse("https://short-d.com/r")
+	assert.Equal(t, nil, err)
+	metaTag := shortlink.NewMetaTagPersist(&shortLinkRepo)
+	shortLinkShare := share.NewShare(*baseURL, share.NewQRCodeGeneratorFake(), metaTag, share.Signer{})
+
+	visitRepo := repository.NewVisitFake([]entity.Visit{})
+	visitStats := visit.NewStatsPersist(&visitRepo, &userShortLinkRepo)
+
+	statusChecker := shortlink.NewStatusCheckerPersist(&shortLinkRepo, &userShortLinkRepo, tm)
+
+	linkHealthRepo := repository.NewLinkHealthFake([]string{}, []entity.LinkHealth{})
+	toggle := featureflag.NewToggleFake(map[featureflag.Flag]bool{})
+	linkHealthReporter := linkhealth.NewReporterPersist(
+		&shortLinkRepo,
+		&userShortLinkRepo,
+		&linkHealthRepo,
+		toggle,
+	)
+
+	urlValidator := shortlink.NewURLValidatorConcurrent(longLinkValidator, riskDetector, 2)
+
+	r := resolver.NewResolver(
+		lg,
+		retriever,
+		creator,
+		updater,
+		deleter,
+		changeLog,
+		verifier,
+		auth,
+		shortLinkShare,
+		visitStats,
+		statusChecker,
+		linkHealthReporter,
+		urlValidator,
+		risk.DomainDenylist{},
+		preference.NewPreference(&preferencesRepo),
+		shortlink.NewAliasCheckerPersist(
+			&shortLinkRepo,
+			customAliasValidator,
+			ratelimit.NewMemory(tm, 0, time.Minute),
+			&repository.AliasReservationFake{},
+			tm,
+		),
+		expirer,
+		maintenance.Switch{},
+		featureflag.Switch{},
+		stats.ServicePersist{},
+		ipallow.Policy{},
+	)
 
 	schema := "schema.graphql"
 	fileSystem := filesystem.NewLocal()
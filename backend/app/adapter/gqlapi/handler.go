@@ -0,0 +1,47 @@
+package gqlapi
+
+import (
+	"net/http"
+
+	"github.com/short-d/app/fw/graphql"
+	"github.com/short-d/app/fw/network"
+	"github.com/short-d/short/backend/app/adapter/gqlapi/resolver"
+	"github.com/short-d/short/backend/app/fw/tenant"
+)
+
+var _ graphql.Handler = (*Handler)(nil)
+
+// Handler serves GraphQL requests with the IP address of the client and the
+// tenant of the host attached to the request context. Queries can be referred
+// to by their hashes once persisted.
+type Handler struct {
+	handler http.Handler
+	network network.Network
+	tenants tenant.Hosts
+}
+
+// ServeHTTP resolves the client IP address and the tenant before executing the
+// request.
+func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	connection := h.network.FromHTTP(r)
+	ctx := resolver.WithClientIP(r.Context(), connection.ClientIP)
+	ctx = tenant.NewContext(ctx, h.tenants.Resolve(r))
+	h.handler.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// NewHandler creates GraphQL handler which resolves the client IP address
+// with the given network and the tenant with the given hosts, and persists
+// queries in the given store. Persisted queries are not supported when store
+// is nil.
+func NewHandler(
+	handler graphql.GraphGopherHandler,
+	network network.Network,
+	store PersistedQueryStore,
+	tenants tenant.Hosts,
+) Handler {
+	return Handler{
+		handler: newPersistedQueries(handler, store),
+		network: network,
+		tenants: tenants,
+	}
+}
@@ -8,16 +8,35 @@ import (
 
 // ShortLinkInput represents possible ShortLink attributes
 type ShortLinkInput struct {
-	LongLink    *string
-	CustomAlias *string
-	ExpireAt    *time.Time
+	LongLink          *string
+	CustomAlias       *string
+	ExpireAt          *time.Time
+	TrackVisits       *bool
+	PassthroughQuery  *bool
+	PassthroughPath   *bool
+	MaxRedirectsPerIP *int32
+	OneTime           *bool
+	Description       *string
+	ReservationToken  *string
 }
 
 // CreateShortLinkInput converts GraphQL ShortLinkInput into consumable entity for use cases.
 func (s ShortLinkInput) CreateShortLinkInput() entity.ShortLinkInput {
+	var maxRedirectsPerIP *int
+	if s.MaxRedirectsPerIP != nil {
+		maxRedirects := int(*s.MaxRedirectsPerIP)
+		maxRedirectsPerIP = &maxRedirects
+	}
 	return entity.ShortLinkInput{
-		LongLink:    s.LongLink,
-		CustomAlias: s.CustomAlias,
-		ExpireAt:    s.ExpireAt,
+		LongLink:          s.LongLink,
+		CustomAlias:       s.CustomAlias,
+		ExpireAt:          s.ExpireAt,
+		TrackVisits:       s.TrackVisits,
+		PassthroughQuery:  s.PassthroughQuery,
+		PassthroughPath:   s.PassthroughPath,
+		MaxRedirectsPerIP: maxRedirectsPerIP,
+		OneTime:           s.OneTime,
+		Description:       s.Description,
+		ReservationToken:  s.ReservationToken,
 	}
 }
@@ -0,0 +1,26 @@
+package input
+
+import (
+	"time"
+
+	"github.com/short-d/short/backend/app/entity"
+)
+
+// UserPreferencesInput represents possible UserPreferences attributes
+type UserPreferencesInput struct {
+	DefaultExpireAfterSeconds *int32
+	DefaultTrackVisits        *bool
+}
+
+// CreateUserPreferences converts GraphQL UserPreferencesInput into consumable
+// entity for use cases.
+func (u UserPreferencesInput) CreateUserPreferences() entity.UserPreferences {
+	preferences := entity.UserPreferences{
+		DefaultTrackVisits: u.DefaultTrackVisits,
+	}
+	if u.DefaultExpireAfterSeconds != nil {
+		expireAfter := time.Duration(*u.DefaultExpireAfterSeconds) * time.Second
+		preferences.DefaultExpireAfter = &expireAfter
+	}
+	return preferences
+}
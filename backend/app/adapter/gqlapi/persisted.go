@@ -0,0 +1,202 @@
+package gqlapi
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Error messages and codes defined by the Automatic Persisted Queries protocol,
+// which clients rely on to register the queries.
+const (
+	errPersistedQueryNotFound     = "PersistedQueryNotFound"
+	errPersistedQueryNotSupported = "PersistedQueryNotSupported"
+	errPersistedQueryHashMismatch = "provided sha does not match query"
+
+	errCodePersistedQueryNotFound     = "PERSISTED_QUERY_NOT_FOUND"
+	errCodePersistedQueryNotSupported = "PERSISTED_QUERY_NOT_SUPPORTED"
+	errCodePersistedQueryHashMismatch = "PERSISTED_QUERY_HASH_MISMATCH"
+)
+
+// PersistedQueryStore saves GraphQL queries by the SHA-256 hashes of the
+// queries, such as in memory or in Redis.
+type PersistedQueryStore interface {
+	GetQuery(hash string) (string, bool, error)
+	SaveQuery(hash string, query string) error
+}
+
+var _ PersistedQueryStore = (*PersistedQueryMemory)(nil)
+
+// PersistedQueryMemory saves at most maxQueries queries in memory. New queries
+// are not saved once it is full, so that clients registering unlimited
+// queries can't exhaust the memory.
+type PersistedQueryMemory struct {
+	mutex      *sync.RWMutex
+	queries    map[string]string
+	maxQueries int
+}
+
+// GetQuery retrieves the query with the given hash.
+func (p PersistedQueryMemory) GetQuery(hash string) (string, bool, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	query, ok := p.queries[hash]
+	return query, ok, nil
+}
+
+// SaveQuery saves the query with the given hash unless the store is full.
+func (p PersistedQueryMemory) SaveQuery(hash string, query string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if len(p.queries) >= p.maxQueries {
+		return nil
+	}
+	p.queries[hash] = query
+	return nil
+}
+
+// NewPersistedQueryMemory creates PersistedQueryMemory which saves at most
+// maxQueries queries.
+func NewPersistedQueryMemory(maxQueries int) PersistedQueryMemory {
+	return PersistedQueryMemory{
+		mutex:      &sync.RWMutex{},
+		queries:    make(map[string]string),
+		maxQueries: maxQueries,
+	}
+}
+
+type persistedQueryExtension struct {
+	Version    int    `json:"version"`
+	SHA256Hash string `json:"sha256Hash"`
+}
+
+type persistedQueryRequest struct {
+	Query      string `json:"query"`
+	Extensions struct {
+		PersistedQuery *persistedQueryExtension `json:"persistedQuery"`
+	} `json:"extensions"`
+}
+
+// persistedQueries implements Automatic Persisted Queries. Clients send the
+// hash of the query instead of the query, and only send the full query to
+// register it when the server responds with PersistedQueryNotFound. Requests
+// without the hash are passed through as is. Persisted queries are not
+// supported when store is nil.
+type persistedQueries struct {
+	handler http.Handler
+	store   PersistedQueryStore
+}
+
+// ServeHTTP fills in the query of the request from the store, or saves the
+// query in the request to the store, before executing the request.
+func (p persistedQueries) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		p.handler.ServeHTTP(w, r)
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	var request persistedQueryRequest
+	err = json.Unmarshal(body, &request)
+	if err != nil || request.Extensions.PersistedQuery == nil {
+		p.handler.ServeHTTP(w, r)
+		return
+	}
+
+	if p.store == nil {
+		writePersistedQueryError(w, http.StatusOK, errPersistedQueryNotSupported, errCodePersistedQueryNotSupported)
+		return
+	}
+
+	hash := strings.ToLower(request.Extensions.PersistedQuery.SHA256Hash)
+	if request.Query != "" {
+		p.register(w, r, hash, request.Query)
+		return
+	}
+
+	query, ok, err := p.store.GetQuery(hash)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !ok {
+		writePersistedQueryError(w, http.StatusOK, errPersistedQueryNotFound, errCodePersistedQueryNotFound)
+		return
+	}
+
+	body, err = withQuery(body, query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	r.ContentLength = int64(len(body))
+	p.handler.ServeHTTP(w, r)
+}
+
+// register saves the query when it matches the hash, so that it can be
+// referred to by the hash in the following requests.
+func (p persistedQueries) register(w http.ResponseWriter, r *http.Request, hash string, query string) {
+	sum := sha256.Sum256([]byte(query))
+	if hex.EncodeToString(sum[:]) != hash {
+		writePersistedQueryError(w, http.StatusBadRequest, errPersistedQueryHashMismatch, errCodePersistedQueryHashMismatch)
+		return
+	}
+
+	err := p.store.SaveQuery(hash, query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	p.handler.ServeHTTP(w, r)
+}
+
+// withQuery sets the query of the request body while keeping the other fields.
+func withQuery(body []byte, query string) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	err := json.Unmarshal(body, &fields)
+	if err != nil {
+		return nil, err
+	}
+
+	fields["query"], err = json.Marshal(query)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(fields)
+}
+
+func writePersistedQueryError(w http.ResponseWriter, status int, message string, code string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"errors": []map[string]interface{}{
+			{
+				"message": message,
+				"extensions": map[string]interface{}{
+					"code": code,
+				},
+			},
+		},
+	})
+}
+
+func newPersistedQueries(handler http.Handler, store PersistedQueryStore) persistedQueries {
+	return persistedQueries{
+		handler: handler,
+		store:   store,
+	}
+}
@@ -0,0 +1,182 @@
+// +build !integration all
+
+package gqlapi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+)
+
+// queryEcho responds with the query it receives.
+type queryEcho struct{}
+
+func (q queryEcho) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Query         string                 `json:"query"`
+		OperationName string                 `json:"operationName"`
+		Variables     map[string]interface{} `json:"variables"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	json.NewEncoder(w).Encode(request)
+}
+
+type persistedQueryResponse struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+	Errors        []struct {
+		Message    string `json:"message"`
+		Extensions struct {
+			Code string `json:"code"`
+		} `json:"extensions"`
+	} `json:"errors"`
+}
+
+const viewerQuery = `query Viewer($alias: String!) { viewer { shortLink(alias: $alias) { alias } } }`
+
+func hashQuery(query string) string {
+	sum := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(sum[:])
+}
+
+func persistedQueryBody(query string, hash string) string {
+	body := map[string]interface{}{
+		"operationName": "Viewer",
+		"variables":     map[string]interface{}{"alias": "google"},
+		"extensions": map[string]interface{}{
+			"persistedQuery": map[string]interface{}{
+				"version":    1,
+				"sha256Hash": hash,
+			},
+		},
+	}
+	if query != "" {
+		body["query"] = query
+	}
+	buf, _ := json.Marshal(body)
+	return string(buf)
+}
+
+func postQuery(t *testing.T, handler http.Handler, body string) (int, persistedQueryResponse) {
+	r := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	buf, err := ioutil.ReadAll(w.Result().Body)
+	assert.Equal(t, nil, err)
+
+	var response persistedQueryResponse
+	err = json.Unmarshal(buf, &response)
+	assert.Equal(t, nil, err)
+	return w.Code, response
+}
+
+func TestPersistedQueries_RegisterThenReuse(t *testing.T) {
+	t.Parallel()
+
+	store := NewPersistedQueryMemory(10)
+	handler := newPersistedQueries(queryEcho{}, store)
+	hash := hashQuery(viewerQuery)
+
+	status, response := postQuery(t, handler, persistedQueryBody("", hash))
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, 1, len(response.Errors))
+	assert.Equal(t, errPersistedQueryNotFound, response.Errors[0].Message)
+	assert.Equal(t, errCodePersistedQueryNotFound, response.Errors[0].Extensions.Code)
+
+	status, response = postQuery(t, handler, persistedQueryBody(viewerQuery, hash))
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, viewerQuery, response.Query)
+
+	status, response = postQuery(t, handler, persistedQueryBody("", hash))
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, 0, len(response.Errors))
+	assert.Equal(t, viewerQuery, response.Query)
+	assert.Equal(t, "Viewer", response.OperationName)
+	assert.Equal(t, map[string]interface{}{"alias": "google"}, response.Variables)
+}
+
+func TestPersistedQueries_HashMismatch(t *testing.T) {
+	t.Parallel()
+
+	store := NewPersistedQueryMemory(10)
+	handler := newPersistedQueries(queryEcho{}, store)
+	hash := hashQuery(viewerQuery)
+	tamperedQuery := `query { viewer { shortLinks { alias longLink } } }`
+
+	status, response := postQuery(t, handler, persistedQueryBody(tamperedQuery, hash))
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, 1, len(response.Errors))
+	assert.Equal(t, errCodePersistedQueryHashMismatch, response.Errors[0].Extensions.Code)
+
+	_, ok, err := store.GetQuery(hash)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, ok)
+
+	status, response = postQuery(t, handler, persistedQueryBody("", hash))
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, 1, len(response.Errors))
+	assert.Equal(t, errCodePersistedQueryNotFound, response.Errors[0].Extensions.Code)
+}
+
+func TestPersistedQueries_PassThrough(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		store         PersistedQueryStore
+		body          string
+		expectedQuery string
+		expectedCode  string
+	}{
+		{
+			name:          "request without hash",
+			store:         NewPersistedQueryMemory(10),
+			body:          `{"query":"query { viewer { email } }"}`,
+			expectedQuery: "query { viewer { email } }",
+		},
+		{
+			name:          "persisted queries disabled",
+			store:         nil,
+			body:          persistedQueryBody("", hashQuery(viewerQuery)),
+			expectedQuery: "",
+			expectedCode:  errCodePersistedQueryNotSupported,
+		},
+		{
+			name:          "store full",
+			store:         NewPersistedQueryMemory(0),
+			body:          persistedQueryBody(viewerQuery, hashQuery(viewerQuery)),
+			expectedQuery: viewerQuery,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := newPersistedQueries(queryEcho{}, testCase.store)
+			status, response := postQuery(t, handler, testCase.body)
+			assert.Equal(t, http.StatusOK, status)
+			assert.Equal(t, testCase.expectedQuery, response.Query)
+			if testCase.expectedCode == "" {
+				assert.Equal(t, 0, len(response.Errors))
+				return
+			}
+			assert.Equal(t, 1, len(response.Errors))
+			assert.Equal(t, testCase.expectedCode, response.Errors[0].Extensions.Code)
+		})
+	}
+}
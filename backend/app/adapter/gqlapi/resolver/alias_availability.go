@@ -0,0 +1,36 @@
+package resolver
+
+import (
+	"github.com/short-d/short/backend/app/usecase/shortlink"
+	"github.com/short-d/short/backend/app/usecase/validator"
+)
+
+var aliasAvailabilityStatuses = map[shortlink.AliasAvailabilityStatus]string{
+	shortlink.AliasAvailable: "AVAILABLE",
+	shortlink.AliasTaken:     "TAKEN",
+	shortlink.AliasInvalid:   "INVALID",
+}
+
+// AliasAvailability retrieves whether a custom alias can be used to create a
+// short link.
+type AliasAvailability struct {
+	availability shortlink.AliasAvailability
+}
+
+// Status retrieves whether the alias is available, taken or invalid.
+func (a AliasAvailability) Status() string {
+	return aliasAvailabilityStatuses[a.availability.Status]
+}
+
+// Violation retrieves why the alias is invalid.
+func (a AliasAvailability) Violation() *string {
+	if a.availability.Violation == validator.Valid {
+		return nil
+	}
+	violation := string(a.availability.Violation)
+	return &violation
+}
+
+func newAliasAvailability(availability shortlink.AliasAvailability) AliasAvailability {
+	return AliasAvailability{availability: availability}
+}
@@ -0,0 +1,32 @@
+package resolver
+
+import (
+	"github.com/short-d/short/backend/app/adapter/gqlapi/scalar"
+	"github.com/short-d/short/backend/app/entity"
+)
+
+// AliasReservation retrieves the custom alias held for the user before the
+// short link is created under it.
+type AliasReservation struct {
+	reservation entity.AliasReservation
+}
+
+// Alias retrieves the reserved alias.
+func (a AliasReservation) Alias() string {
+	return a.reservation.Alias
+}
+
+// Token retrieves the token which claims the alias when creating the short
+// link.
+func (a AliasReservation) Token() string {
+	return a.reservation.Token
+}
+
+// ExpireAt retrieves the time when the alias is released to others.
+func (a AliasReservation) ExpireAt() scalar.Time {
+	return scalar.Time{Time: a.reservation.ExpireAt}
+}
+
+func newAliasReservation(reservation entity.AliasReservation) AliasReservation {
+	return AliasReservation{reservation: reservation}
+}
@@ -0,0 +1,33 @@
+package resolver
+
+import "github.com/short-d/short/backend/app/usecase/shortlink"
+
+var aliasStatuses = map[shortlink.AliasStatus]string{
+	shortlink.AliasStatusActive:   "ACTIVE",
+	shortlink.AliasStatusExpired:  "EXPIRED",
+	shortlink.AliasStatusNotFound: "NOT_FOUND",
+}
+
+// AliasResolution retrieves the status of an alias.
+type AliasResolution struct {
+	resolution shortlink.AliasResolution
+}
+
+// Alias retrieves the requested alias.
+func (a AliasResolution) Alias() string {
+	return a.resolution.Alias
+}
+
+// Status retrieves whether the alias can redirect users to a long link.
+func (a AliasResolution) Status() string {
+	return aliasStatuses[a.resolution.Status]
+}
+
+// LongLink retrieves the long link of the alias if the viewer owns it.
+func (a AliasResolution) LongLink() *string {
+	return a.resolution.LongLink
+}
+
+func newAliasResolution(resolution shortlink.AliasResolution) AliasResolution {
+	return AliasResolution{resolution: resolution}
+}
@@ -1,24 +1,42 @@
 package resolver
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/short-d/short/backend/app/adapter/gqlapi/input"
 	"github.com/short-d/short/backend/app/adapter/gqlapi/scalar"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/fw/ipallow"
+	"github.com/short-d/short/backend/app/fw/tenant"
 	"github.com/short-d/short/backend/app/usecase/authenticator"
 	"github.com/short-d/short/backend/app/usecase/changelog"
+	"github.com/short-d/short/backend/app/usecase/featureflag"
+	"github.com/short-d/short/backend/app/usecase/maintenance"
+	"github.com/short-d/short/backend/app/usecase/preference"
+	"github.com/short-d/short/backend/app/usecase/risk"
+	"github.com/short-d/short/backend/app/usecase/share"
 	"github.com/short-d/short/backend/app/usecase/shortlink"
 )
 
 // AuthMutation represents GraphQL mutation resolver that acts differently based
 // on the identify of the user
 type AuthMutation struct {
-	authToken        *string
-	authenticator    authenticator.Authenticator
-	changeLog        changelog.ChangeLog
-	shortLinkCreator shortlink.Creator
-	shortLinkUpdater shortlink.Updater
+	authToken         *string
+	authenticator     authenticator.Authenticator
+	changeLog         changelog.ChangeLog
+	shortLinkCreator  shortlink.Creator
+	shortLinkUpdater  shortlink.Updater
+	shortLinkDeleter  shortlink.Deleter
+	shortLinkExpirer  shortlink.Expirer
+	shortLinkShare    share.Share
+	domainDenylist    risk.DomainDenylist
+	preferences       preference.Preference
+	maintenanceSwitch maintenance.Switch
+	featureFlagSwitch featureflag.Switch
+	adminPolicy       ipallow.Policy
 }
 
 // CreateShortLinkArgs represents the possible parameters for CreateShortLink endpoint
@@ -28,39 +46,161 @@ type CreateShortLinkArgs struct {
 }
 
 // CreateShortLink creates mapping between an alias and a long link for a given user
-func (a AuthMutation) CreateShortLink(args *CreateShortLinkArgs) (*ShortLink, error) {
+func (a AuthMutation) CreateShortLink(ctx context.Context, args *CreateShortLinkArgs) (*ShortLink, error) {
 	user, err := viewer(a.authToken, a.authenticator)
 	if err != nil {
 		return nil, ErrInvalidAuthToken{}
 	}
+	ctx = tenant.NewContext(ctx, user.TenantID)
 
 	shortLink := args.ShortLink.CreateShortLinkInput()
 	isPublic := args.IsPublic
 
-	newShortLink, err := a.shortLinkCreator.CreateShortLink(shortLink, user, isPublic)
+	newShortLink, err := a.shortLinkCreator.CreateShortLink(ctx, shortLink, user, isPublic)
 	if err == nil {
-		return &ShortLink{shortLink: newShortLink}, nil
+		return &ShortLink{
+			shortLink:      newShortLink,
+			shortLinkShare: a.shortLinkShare,
+		}, nil
 	}
+	return nil, newCreateShortLinkError(err, shortLink)
+}
+
+// CreateShortLinksArgs represents the possible parameters for
+// CreateShortLinks endpoint
+type CreateShortLinksArgs struct {
+	ShortLinks []input.ShortLinkInput
+}
+
+// CreateShortLinks creates many short links for the user at once. The inputs
+// failing to create short links are reported along with the short links
+// created, instead of failing the whole batch.
+func (a AuthMutation) CreateShortLinks(ctx context.Context, args *CreateShortLinksArgs) (CreateShortLinksResult, error) {
+	user, err := viewer(a.authToken, a.authenticator)
+	if err != nil {
+		return CreateShortLinksResult{}, ErrInvalidAuthToken{}
+	}
+	ctx = tenant.NewContext(ctx, user.TenantID)
+
+	shortLinks := make([]entity.ShortLinkInput, 0, len(args.ShortLinks))
+	for _, shortLink := range args.ShortLinks {
+		shortLinks = append(shortLinks, shortLink.CreateShortLinkInput())
+	}
+
+	report, err := a.shortLinkCreator.CreateShortLinks(ctx, shortLinks, user)
+	if err == nil {
+		return newCreateShortLinksResult(report, shortLinks, a.shortLinkShare), nil
+	}
+
+	var (
+		tm shortlink.ErrTooManyShortLinks
+		ro shortlink.ErrServiceReadOnly
+	)
+	if errors.As(err, &ro) {
+		return CreateShortLinksResult{}, ErrServiceReadOnly{}
+	}
+	if errors.As(err, &tm) {
+		return CreateShortLinksResult{}, ErrTooManyShortLinks(len(args.ShortLinks))
+	}
+	return CreateShortLinksResult{}, ErrUnknown{}
+}
 
+// newCreateShortLinkError converts the failure of creating the short link
+// into GraphQL error.
+func newCreateShortLinkError(err error, shortLink entity.ShortLinkInput) GraphQLError {
 	var (
 		ae shortlink.ErrAliasExist
+		ar shortlink.ErrAliasReserved
 		l  shortlink.ErrInvalidLongLink
 		c  shortlink.ErrInvalidCustomAlias
+		ca shortlink.ErrConfusableAlias
 		m  shortlink.ErrMaliciousLongLink
+		sr shortlink.ErrSelfReferentialLink
+		q  shortlink.ErrAliasQuotaExceeded
+		ro shortlink.ErrServiceReadOnly
+		dl shortlink.ErrDescriptionTooLong
 	)
+	if errors.As(err, &ro) {
+		return ErrServiceReadOnly{}
+	}
 	if errors.As(err, &ae) {
-		return nil, ErrAliasExist(shortLink.GetCustomAlias(""))
+		return ErrAliasExist(shortLink.GetCustomAlias(""))
+	}
+	if errors.As(err, &ar) {
+		return ErrAliasReserved(shortLink.GetCustomAlias(""))
+	}
+	if errors.As(err, &dl) {
+		return ErrDescriptionTooLong(dl)
+	}
+	if errors.As(err, &q) {
+		return ErrAliasQuotaExceeded{}
 	}
 	if errors.As(err, &l) {
-		return nil, ErrInvalidLongLink{shortLink.GetLongLink(""), string(l.Violation)}
+		return ErrInvalidLongLink{shortLink.GetLongLink(""), string(l.Violation)}
 	}
 	if errors.As(err, &c) {
-		return nil, ErrInvalidCustomAlias{shortLink.GetCustomAlias(""), string(c.Violation)}
+		return ErrInvalidCustomAlias{shortLink.GetCustomAlias(""), string(c.Violation)}
+	}
+	if errors.As(err, &ca) {
+		return ErrConfusableAlias{shortLink.GetCustomAlias(""), ca.ExistingAlias}
 	}
 	if errors.As(err, &m) {
-		return nil, ErrMaliciousContent(shortLink.GetLongLink(""))
+		return ErrMaliciousContent(shortLink.GetLongLink(""))
 	}
-	return nil, ErrUnknown{}
+	if errors.As(err, &sr) {
+		return ErrSelfReferentialLink(shortLink.GetLongLink(""))
+	}
+	return ErrUnknown{}
+}
+
+// PreviewShortLinkArgs represents the possible parameters for PreviewShortLink
+// endpoint
+type PreviewShortLinkArgs struct {
+	ShortLink input.ShortLinkInput
+}
+
+// PreviewShortLink retrieves the short link CreateShortLink would create
+// without saving it
+func (a AuthMutation) PreviewShortLink(ctx context.Context, args *PreviewShortLinkArgs) (ShortLinkPreview, error) {
+	_, err := viewer(a.authToken, a.authenticator)
+	if err != nil {
+		return ShortLinkPreview{}, ErrInvalidAuthToken{}
+	}
+
+	shortLink := args.ShortLink.CreateShortLinkInput()
+
+	preview, err := a.shortLinkCreator.PreviewShortLink(ctx, shortLink)
+	if err == nil {
+		return newShortLinkPreview(preview, a.shortLinkShare), nil
+	}
+
+	var (
+		l  shortlink.ErrInvalidLongLink
+		c  shortlink.ErrInvalidCustomAlias
+		ca shortlink.ErrConfusableAlias
+		m  shortlink.ErrMaliciousLongLink
+		sr shortlink.ErrSelfReferentialLink
+		dl shortlink.ErrDescriptionTooLong
+	)
+	if errors.As(err, &dl) {
+		return ShortLinkPreview{}, ErrDescriptionTooLong(dl)
+	}
+	if errors.As(err, &l) {
+		return ShortLinkPreview{}, ErrInvalidLongLink{shortLink.GetLongLink(""), string(l.Violation)}
+	}
+	if errors.As(err, &c) {
+		return ShortLinkPreview{}, ErrInvalidCustomAlias{shortLink.GetCustomAlias(""), string(c.Violation)}
+	}
+	if errors.As(err, &ca) {
+		return ShortLinkPreview{}, ErrConfusableAlias{shortLink.GetCustomAlias(""), ca.ExistingAlias}
+	}
+	if errors.As(err, &m) {
+		return ShortLinkPreview{}, ErrMaliciousContent(shortLink.GetLongLink(""))
+	}
+	if errors.As(err, &sr) {
+		return ShortLinkPreview{}, ErrSelfReferentialLink(shortLink.GetLongLink(""))
+	}
+	return ShortLinkPreview{}, ErrUnknown{}
 }
 
 // UpdateShortLinkArgs represents the possible parameters for updateShortLink endpoint
@@ -70,30 +210,47 @@ type UpdateShortLinkArgs struct {
 }
 
 // UpdateShortLink updates the relationship between the short link and the user
-func (a AuthMutation) UpdateShortLink(args *UpdateShortLinkArgs) (*ShortLink, error) {
+func (a AuthMutation) UpdateShortLink(ctx context.Context, args *UpdateShortLinkArgs) (*ShortLink, error) {
 	user, err := viewer(a.authToken, a.authenticator)
 	if err != nil {
 		return nil, ErrInvalidAuthToken{}
 	}
+	ctx = tenant.NewContext(ctx, user.TenantID)
 
 	update := args.ShortLink.CreateShortLinkInput()
 
-	newShortLink, err := a.shortLinkUpdater.UpdateShortLink(args.OldAlias, update, user)
+	newShortLink, err := a.shortLinkUpdater.UpdateShortLink(ctx, args.OldAlias, update, user)
 	if err == nil {
-		return &ShortLink{shortLink: newShortLink}, nil
+		return &ShortLink{
+			shortLink:      newShortLink,
+			shortLinkShare: a.shortLinkShare,
+		}, nil
 	}
 
 	var (
 		ae shortlink.ErrAliasExist
+		ar shortlink.ErrAliasReserved
 		l  shortlink.ErrInvalidLongLink
 		c  shortlink.ErrInvalidCustomAlias
 		m  shortlink.ErrMaliciousLongLink
+		sr shortlink.ErrSelfReferentialLink
 		nf shortlink.ErrShortLinkNotFound
 		ns shortlink.ErrEmptyAlias
+		ro shortlink.ErrServiceReadOnly
+		dl shortlink.ErrDescriptionTooLong
 	)
+	if errors.As(err, &ro) {
+		return nil, ErrServiceReadOnly{}
+	}
 	if errors.As(err, &ae) {
 		return nil, ErrAliasExist(update.GetCustomAlias(""))
 	}
+	if errors.As(err, &ar) {
+		return nil, ErrAliasReserved(update.GetCustomAlias(""))
+	}
+	if errors.As(err, &dl) {
+		return nil, ErrDescriptionTooLong(dl)
+	}
 	if errors.As(err, &l) {
 		return nil, ErrInvalidLongLink{update.GetLongLink(""), string(l.Violation)}
 	}
@@ -103,6 +260,9 @@ func (a AuthMutation) UpdateShortLink(args *UpdateShortLinkArgs) (*ShortLink, er
 	if errors.As(err, &m) {
 		return nil, ErrMaliciousContent(update.GetLongLink(""))
 	}
+	if errors.As(err, &sr) {
+		return nil, ErrSelfReferentialLink(update.GetLongLink(""))
+	}
 	if errors.As(err, &nf) {
 		return nil, ErrShortLinkNotFound(args.OldAlias)
 	}
@@ -112,6 +272,197 @@ func (a AuthMutation) UpdateShortLink(args *UpdateShortLinkArgs) (*ShortLink, er
 	return nil, ErrUnknown{}
 }
 
+// CloneShortLinkArgs represents the possible parameters for cloneShortLink endpoint
+type CloneShortLinkArgs struct {
+	SourceAlias string
+	NewAlias    *string
+}
+
+// CloneShortLink creates a copy of a short link owned by the user under a new alias
+func (a AuthMutation) CloneShortLink(ctx context.Context, args *CloneShortLinkArgs) (*ShortLink, error) {
+	user, err := viewer(a.authToken, a.authenticator)
+	if err != nil {
+		return nil, ErrInvalidAuthToken{}
+	}
+	ctx = tenant.NewContext(ctx, user.TenantID)
+
+	newAlias := ""
+	if args.NewAlias != nil {
+		newAlias = *args.NewAlias
+	}
+
+	newShortLink, err := a.shortLinkCreator.CloneShortLink(ctx, args.SourceAlias, newAlias, user)
+	if err == nil {
+		return &ShortLink{
+			shortLink:      newShortLink,
+			shortLinkShare: a.shortLinkShare,
+		}, nil
+	}
+
+	var (
+		ae shortlink.ErrAliasExist
+		ar shortlink.ErrAliasReserved
+		l  shortlink.ErrInvalidLongLink
+		c  shortlink.ErrInvalidCustomAlias
+		ca shortlink.ErrConfusableAlias
+		m  shortlink.ErrMaliciousLongLink
+		sr shortlink.ErrSelfReferentialLink
+		nf shortlink.ErrShortLinkNotFound
+		q  shortlink.ErrAliasQuotaExceeded
+		ro shortlink.ErrServiceReadOnly
+	)
+	if errors.As(err, &ro) {
+		return nil, ErrServiceReadOnly{}
+	}
+	if errors.As(err, &ae) {
+		return nil, ErrAliasExist(newAlias)
+	}
+	if errors.As(err, &ar) {
+		return nil, ErrAliasReserved(newAlias)
+	}
+	if errors.As(err, &q) {
+		return nil, ErrAliasQuotaExceeded{}
+	}
+	if errors.As(err, &l) {
+		return nil, ErrInvalidLongLink{l.LongLink, string(l.Violation)}
+	}
+	if errors.As(err, &c) {
+		return nil, ErrInvalidCustomAlias{newAlias, string(c.Violation)}
+	}
+	if errors.As(err, &ca) {
+		return nil, ErrConfusableAlias{newAlias, ca.ExistingAlias}
+	}
+	if errors.As(err, &m) {
+		return nil, ErrMaliciousContent(string(m))
+	}
+	if errors.As(err, &sr) {
+		return nil, ErrSelfReferentialLink(string(sr))
+	}
+	if errors.As(err, &nf) {
+		return nil, ErrShortLinkNotFound(args.SourceAlias)
+	}
+	return nil, ErrUnknown{}
+}
+
+// ReserveAliasArgs represents the possible parameters for ReserveAlias
+// endpoint
+type ReserveAliasArgs struct {
+	Alias      string
+	TTLSeconds *int32
+}
+
+// ReserveAlias holds the custom alias for the user without a long link until
+// the short link is created under it or the reservation expires
+func (a AuthMutation) ReserveAlias(ctx context.Context, args *ReserveAliasArgs) (AliasReservation, error) {
+	user, err := viewer(a.authToken, a.authenticator)
+	if err != nil {
+		return AliasReservation{}, ErrInvalidAuthToken{}
+	}
+	ctx = tenant.NewContext(ctx, user.TenantID)
+
+	var ttl time.Duration
+	if args.TTLSeconds != nil {
+		ttl = time.Duration(*args.TTLSeconds) * time.Second
+	}
+
+	reservation, err := a.shortLinkCreator.ReserveAlias(ctx, args.Alias, user, ttl)
+	if err == nil {
+		return newAliasReservation(reservation), nil
+	}
+
+	var (
+		ae shortlink.ErrAliasExist
+		ar shortlink.ErrAliasReserved
+		c  shortlink.ErrInvalidCustomAlias
+		it shortlink.ErrInvalidReservationTTL
+		ro shortlink.ErrServiceReadOnly
+	)
+	if errors.As(err, &ro) {
+		return AliasReservation{}, ErrServiceReadOnly{}
+	}
+	if errors.As(err, &ae) {
+		return AliasReservation{}, ErrAliasExist(args.Alias)
+	}
+	if errors.As(err, &ar) {
+		return AliasReservation{}, ErrAliasReserved(args.Alias)
+	}
+	if errors.As(err, &it) {
+		return AliasReservation{}, ErrInvalidReservationTTL(it)
+	}
+	if errors.As(err, &c) {
+		return AliasReservation{}, ErrInvalidCustomAlias{args.Alias, string(c.Violation)}
+	}
+	return AliasReservation{}, ErrUnknown{}
+}
+
+// DeleteExpiredShortLinks removes all expired short links owned by the user
+func (a AuthMutation) DeleteExpiredShortLinks(ctx context.Context) (int32, error) {
+	user, err := viewer(a.authToken, a.authenticator)
+	if err != nil {
+		return 0, ErrInvalidAuthToken{}
+	}
+	ctx = tenant.NewContext(ctx, user.TenantID)
+
+	count, err := a.shortLinkDeleter.DeleteExpiredShortLinks(ctx, user)
+	if err == nil {
+		return int32(count), nil
+	}
+
+	var (
+		ro shortlink.ErrServiceReadOnly
+	)
+	if errors.As(err, &ro) {
+		return 0, ErrServiceReadOnly{}
+	}
+	return 0, ErrUnknown{}
+}
+
+// ExpireShortLinkArgs represents the possible parameters for ExpireShortLink
+// endpoint
+type ExpireShortLinkArgs struct {
+	Alias  string
+	Reason string
+}
+
+// ExpireShortLink stops any short link from redirecting immediately while
+// keeping its history. The reason is recorded for auditing.
+func (a AuthMutation) ExpireShortLink(ctx context.Context, args *ExpireShortLinkArgs) (*ShortLink, error) {
+	err := checkAdminIP(ctx, a.adminPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := viewer(a.authToken, a.authenticator)
+	if err != nil {
+		return nil, ErrInvalidAuthToken{}
+	}
+	ctx = tenant.NewContext(ctx, user.TenantID)
+
+	shortLink, err := a.shortLinkExpirer.ExpireShortLink(ctx, args.Alias, args.Reason, user)
+	if err == nil {
+		return &ShortLink{
+			shortLink:      shortLink,
+			shortLinkShare: a.shortLinkShare,
+		}, nil
+	}
+
+	var (
+		u  shortlink.ErrUnauthorizedAction
+		nf shortlink.ErrShortLinkNotFound
+		ro shortlink.ErrServiceReadOnly
+	)
+	if errors.As(err, &ro) {
+		return nil, ErrServiceReadOnly{}
+	}
+	if errors.As(err, &u) {
+		return nil, ErrUnauthorizedAction(fmt.Sprintf("user %s is not allowed to expire the short link %s", user.ID, args.Alias))
+	}
+	if errors.As(err, &nf) {
+		return nil, ErrShortLinkNotFound(args.Alias)
+	}
+	return nil, ErrUnknown{}
+}
+
 // ChangeInput represents possible properties for Change
 type ChangeInput struct {
 	Title           string
@@ -215,18 +566,166 @@ func (a AuthMutation) ViewChangeLog() (scalar.Time, error) {
 	return scalar.Time{Time: lastViewedAt}, err
 }
 
+// ReloadDomainDenylist reads the denied domains from the denylist file again
+// so that the changes take effect without restarting the service.
+func (a AuthMutation) ReloadDomainDenylist(ctx context.Context) (int32, error) {
+	err := checkAdminIP(ctx, a.adminPolicy)
+	if err != nil {
+		return 0, err
+	}
+
+	user, err := viewer(a.authToken, a.authenticator)
+	if err != nil {
+		return 0, ErrInvalidAuthToken{}
+	}
+
+	count, err := a.domainDenylist.Reload(user)
+	if err == nil {
+		return int32(count), nil
+	}
+
+	var (
+		u risk.ErrUnauthorizedAction
+	)
+	if errors.As(err, &u) {
+		return 0, ErrUnauthorizedAction(fmt.Sprintf("user %s is not allowed to reload domain denylist", user.ID))
+	}
+	return 0, ErrUnknown{}
+}
+
+// SetReadOnlyModeArgs represents the possible parameters for SetReadOnlyMode
+// endpoint
+type SetReadOnlyModeArgs struct {
+	IsReadOnly bool
+}
+
+// SetReadOnlyMode turns read-only mode on or off at runtime, so that schema
+// changes can be made while short links keep redirecting.
+func (a AuthMutation) SetReadOnlyMode(ctx context.Context, args *SetReadOnlyModeArgs) (bool, error) {
+	err := checkAdminIP(ctx, a.adminPolicy)
+	if err != nil {
+		return false, err
+	}
+
+	user, err := viewer(a.authToken, a.authenticator)
+	if err != nil {
+		return false, ErrInvalidAuthToken{}
+	}
+
+	err = a.maintenanceSwitch.SetReadOnly(user, args.IsReadOnly)
+	if err == nil {
+		return args.IsReadOnly, nil
+	}
+
+	var (
+		u maintenance.ErrUnauthorizedAction
+	)
+	if errors.As(err, &u) {
+		return false, ErrUnauthorizedAction(fmt.Sprintf("user %s is not allowed to switch read-only mode", user.ID))
+	}
+	return false, ErrUnknown{}
+}
+
+// SetFeatureFlagArgs represents the possible parameters for SetFeatureFlag
+// endpoint
+type SetFeatureFlagArgs struct {
+	Flag      string
+	IsEnabled *bool
+}
+
+// SetFeatureFlag turns a feature on or off for everyone at runtime, so that
+// new behaviors can be rolled back without a deployment. The override is
+// cleared when IsEnabled is omitted.
+func (a AuthMutation) SetFeatureFlag(ctx context.Context, args *SetFeatureFlagArgs) (bool, error) {
+	err := checkAdminIP(ctx, a.adminPolicy)
+	if err != nil {
+		return false, err
+	}
+
+	user, err := viewer(a.authToken, a.authenticator)
+	if err != nil {
+		return false, ErrInvalidAuthToken{}
+	}
+
+	flag := featureflag.Flag(args.Flag)
+	if args.IsEnabled == nil {
+		err = a.featureFlagSwitch.ClearOverride(user, flag)
+	} else {
+		err = a.featureFlagSwitch.Override(user, flag, *args.IsEnabled)
+	}
+	if err == nil {
+		return a.featureFlagSwitch.IsEnabled(flag), nil
+	}
+
+	var (
+		u  featureflag.ErrUnauthorizedAction
+		uf featureflag.ErrUnknownFlag
+	)
+	if errors.As(err, &u) {
+		return false, ErrUnauthorizedAction(fmt.Sprintf("user %s is not allowed to override feature flags", user.ID))
+	}
+	if errors.As(err, &uf) {
+		return false, ErrUnknownFeatureFlag(args.Flag)
+	}
+	return false, ErrUnknown{}
+}
+
+// UpdatePreferencesArgs represents the possible parameters for
+// UpdatePreferences endpoint
+type UpdatePreferencesArgs struct {
+	Preferences input.UserPreferencesInput
+}
+
+// UpdatePreferences replaces the default settings applied to the short links
+// created by the user
+func (a AuthMutation) UpdatePreferences(args *UpdatePreferencesArgs) (UserPreferences, error) {
+	user, err := viewer(a.authToken, a.authenticator)
+	if err != nil {
+		return UserPreferences{}, ErrInvalidAuthToken{}
+	}
+
+	preferences, err := a.preferences.UpdatePreferences(user, args.Preferences.CreateUserPreferences())
+	if err == nil {
+		return newUserPreferences(preferences), nil
+	}
+
+	var (
+		ip preference.ErrInvalidPreferences
+	)
+	if errors.As(err, &ip) {
+		return UserPreferences{}, ErrInvalidPreferences(string(ip))
+	}
+	return UserPreferences{}, ErrUnknown{}
+}
+
 func newAuthMutation(
 	authToken *string,
 	authenticator authenticator.Authenticator,
 	changeLog changelog.ChangeLog,
 	shortLinkCreator shortlink.Creator,
 	shortLinkUpdater shortlink.Updater,
+	shortLinkDeleter shortlink.Deleter,
+	shortLinkExpirer shortlink.Expirer,
+	shortLinkShare share.Share,
+	domainDenylist risk.DomainDenylist,
+	preferences preference.Preference,
+	maintenanceSwitch maintenance.Switch,
+	featureFlagSwitch featureflag.Switch,
+	adminPolicy ipallow.Policy,
 ) AuthMutation {
 	return AuthMutation{
-		authToken:        authToken,
-		authenticator:    authenticator,
-		changeLog:        changeLog,
-		shortLinkCreator: shortLinkCreator,
-		shortLinkUpdater: shortLinkUpdater,
+		authToken:         authToken,
+		authenticator:     authenticator,
+		changeLog:         changeLog,
+		shortLinkCreator:  shortLinkCreator,
+		shortLinkUpdater:  shortLinkUpdater,
+		shortLinkDeleter:  shortLinkDeleter,
+		shortLinkExpirer:  shortLinkExpirer,
+		shortLinkShare:    shortLinkShare,
+		domainDenylist:    domainDenylist,
+		preferences:       preferences,
+		maintenanceSwitch: maintenanceSwitch,
+		featureFlagSwitch: featureFlagSwitch,
+		adminPolicy:       adminPolicy,
 	}
 }
@@ -0,0 +1,248 @@
+// +build !integration all
+
+package resolver
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/crypto"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/adapter/gqlapi/input"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/fw/ipallow"
+	"github.com/short-d/short/backend/app/fw/ptr"
+	"github.com/short-d/short/backend/app/usecase/authenticator"
+	"github.com/short-d/short/backend/app/usecase/featureflag"
+	"github.com/short-d/short/backend/app/usecase/maintenance"
+	"github.com/short-d/short/backend/app/usecase/preference"
+	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/risk"
+	"github.com/short-d/short/backend/app/usecase/share"
+	"github.com/short-d/short/backend/app/usecase/shortlink"
+	"github.com/short-d/short/backend/app/usecase/validator"
+)
+
+// bulkCreatorStub reports the given result for any batch of short links.
+type bulkCreatorStub struct {
+	shortlink.Creator
+	report shortlink.BulkReport
+	err    error
+}
+
+func (b bulkCreatorStub) CreateShortLinks(
+	ctx context.Context,
+	shortLinkInputs []entity.ShortLinkInput,
+	user entity.User,
+) (shortlink.BulkReport, error) {
+	return b.report, b.err
+}
+
+func TestAuthMutation_CreateShortLinks(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+
+	baseURL, err := url.Parse("https://short-d.com/r")
+	assert.Equal(t, nil, err)
+	shortLinkShare := share.NewShare(
+		*baseURL,
+		share.NewQRCodeGeneratorFake(),
+		shortlink.NewMetaTagPersist(nil),
+		share.Signer{},
+	)
+
+	user := entity.User{ID: "12345", Email: "alpha@example.com"}
+	shortLinkInputs := []input.ShortLinkInput{
+		{LongLink: ptr.String("https://www.google.com"), CustomAlias: ptr.String("google")},
+		{LongLink: ptr.String("https://www.taken.com"), CustomAlias: ptr.String("taken")},
+		{LongLink: ptr.String("not a url"), CustomAlias: ptr.String("broken")},
+		{LongLink: ptr.String("https://github.com"), CustomAlias: ptr.String("github")},
+		{LongLink: ptr.String("https://www.google.com"), CustomAlias: ptr.String("api")},
+		{LongLink: ptr.String("https://www.google.com"), CustomAlias: ptr.String("search")},
+	}
+
+	type inputError struct {
+		index     int32
+		code      string
+		violation *string
+	}
+
+	testCases := []struct {
+		name            string
+		report          shortlink.BulkReport
+		err             error
+		expectedErr     error
+		expectedAliases []string
+		expectedErrors  []inputError
+	}{
+		{
+			name: "mixed success and failure",
+			report: shortlink.BulkReport{
+				Created: []entity.ShortLink{
+					{Alias: "google", LongLink: "https://www.google.com"},
+					{Alias: "github", LongLink: "https://github.com"},
+				},
+				Failures: []shortlink.BulkFailure{
+					{Index: 1, Err: shortlink.ErrAliasExist("short link alias already exist")},
+					{Index: 2, Err: shortlink.ErrInvalidLongLink{LongLink: "not a url", Violation: validator.LongLinkNotURL}},
+					{Index: 4, Err: shortlink.ErrInvalidCustomAlias{Violation: validator.ReservedAlias}},
+					{Index: 5, Err: errors.New("connection reset")},
+				},
+			},
+			expectedAliases: []string{"google", "github"},
+			expectedErrors: []inputError{
+				{index: 1, code: ErrCodeAliasAlreadyExist},
+				{index: 2, code: ErrCodeInvalidLongLink, violation: ptr.String(validator.LongLinkNotURL)},
+				{index: 4, code: ErrCodeInvalidCustomAlias, violation: ptr.String(validator.ReservedAlias)},
+				{index: 5, code: string(ErrCodeUnknown)},
+			},
+		},
+		{
+			name: "all short links created",
+			report: shortlink.BulkReport{
+				Created: []entity.ShortLink{
+					{Alias: "google", LongLink: "https://www.google.com"},
+				},
+				Failures: []shortlink.BulkFailure{},
+			},
+			expectedAliases: []string{"google"},
+			expectedErrors:  []inputError{},
+		},
+		{
+			name:        "too many short links",
+			err:         shortlink.ErrTooManyShortLinks("at most 100 short links can be created at once"),
+			expectedErr: ErrTooManyShortLinks(len(shortLinkInputs)),
+		},
+		{
+			name:        "service read-only",
+			err:         shortlink.ErrServiceReadOnly("create short links"),
+			expectedErr: ErrServiceReadOnly{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			tokenizer := crypto.NewTokenizerFake()
+			userRepo := repository.NewUserFake(nil)
+			auth := authenticator.NewAuthenticator(tokenizer, timer.NewStub(now), time.Hour, &userRepo)
+			authToken, err := auth.GenerateToken(user)
+			assert.Equal(t, nil, err)
+
+			mutation := newAuthMutation(
+				&authToken,
+				auth,
+				nil,
+				bulkCreatorStub{report: testCase.report, err: testCase.err},
+				nil,
+				nil,
+				nil,
+				shortLinkShare,
+				risk.DomainDenylist{},
+				preference.Preference{},
+				maintenance.Switch{},
+				featureflag.Switch{},
+				ipallow.Policy{},
+			)
+
+			result, err := mutation.CreateShortLinks(context.Background(), &CreateShortLinksArgs{
+				ShortLinks: shortLinkInputs,
+			})
+			if testCase.expectedErr != nil {
+				assert.Equal(t, testCase.expectedErr, err)
+				return
+			}
+			assert.Equal(t, nil, err)
+
+			aliases := []string{}
+			for _, shortLink := range result.ShortLinks() {
+				aliases = append(aliases, *shortLink.Alias())
+			}
+			assert.Equal(t, testCase.expectedAliases, aliases)
+
+			inputErrors := []inputError{}
+			for _, gqlErr := range result.Errors() {
+				inputErrors = append(inputErrors, inputError{
+					index:     gqlErr.Index(),
+					code:      gqlErr.Code(),
+					violation: gqlErr.Violation(),
+				})
+			}
+			assert.Equal(t, testCase.expectedErrors, inputErrors)
+		})
+	}
+}
+
+func TestAuthMutation_AdminIPPolicy(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		clientIP    string
+		expectedErr error
+	}{
+		{
+			name:        "client IP within allowlist",
+			clientIP:    "10.0.0.2",
+			expectedErr: ErrInvalidAuthToken{},
+		},
+		{
+			name:        "client IP outside allowlist",
+			clientIP:    "198.51.100.9",
+			expectedErr: ErrUnauthorizedAction("client IP 198.51.100.9 is not allowed to perform admin actions"),
+		},
+		{
+			name:        "unknown client IP",
+			clientIP:    "",
+			expectedErr: ErrUnauthorizedAction("client IP  is not allowed to perform admin actions"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			adminPolicy, err := ipallow.NewPolicy(nil, []string{"10.0.0.0/8"})
+			assert.Equal(t, nil, err)
+
+			userRepo := repository.NewUserFake(nil)
+			auth := authenticator.NewAuthenticator(crypto.NewTokenizerFake(), timer.NewStub(time.Now()), time.Hour, &userRepo)
+			invalidToken := "invalid"
+			mutation := newAuthMutation(
+				&invalidToken,
+				auth,
+				nil,
+				nil,
+				nil,
+				nil,
+				nil,
+				share.Share{},
+				risk.DomainDenylist{},
+				preference.Preference{},
+				maintenance.Switch{},
+				featureflag.Switch{},
+				adminPolicy,
+			)
+			ctx := WithClientIP(context.Background(), testCase.clientIP)
+
+			_, err = mutation.SetFeatureFlag(ctx, &SetFeatureFlagArgs{Flag: "search"})
+			assert.Equal(t, testCase.expectedErr, err)
+
+			_, err = mutation.SetReadOnlyMode(ctx, &SetReadOnlyModeArgs{IsReadOnly: true})
+			assert.Equal(t, testCase.expectedErr, err)
+
+			_, err = mutation.ReloadDomainDenylist(ctx)
+			assert.Equal(t, testCase.expectedErr, err)
+
+			_, err = mutation.ExpireShortLink(ctx, &ExpireShortLinkArgs{Alias: "google"})
+			assert.Equal(t, testCase.expectedErr, err)
+		})
+	}
+}
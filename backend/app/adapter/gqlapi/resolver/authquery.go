@@ -1,13 +1,22 @@
 package resolver
 
 import (
+	"context"
+	"errors"
 	"time"
 
 	"github.com/short-d/short/backend/app/adapter/gqlapi/scalar"
 	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/fw/ipallow"
+	"github.com/short-d/short/backend/app/fw/tenant"
 	"github.com/short-d/short/backend/app/usecase/authenticator"
 	"github.com/short-d/short/backend/app/usecase/changelog"
+	"github.com/short-d/short/backend/app/usecase/linkhealth"
+	"github.com/short-d/short/backend/app/usecase/preference"
+	"github.com/short-d/short/backend/app/usecase/share"
 	"github.com/short-d/short/backend/app/usecase/shortlink"
+	"github.com/short-d/short/backend/app/usecase/stats"
+	"github.com/short-d/short/backend/app/usecase/visit"
 )
 
 // AuthQuery represents GraphQL query resolver that acts differently based
@@ -17,6 +26,14 @@ type AuthQuery struct {
 	authenticator      authenticator.Authenticator
 	changeLog          changelog.ChangeLog
 	shortLinkRetriever shortlink.Retriever
+	shortLinkShare     share.Share
+	visitStats         visit.Stats
+	statusChecker      shortlink.StatusChecker
+	linkHealthReporter linkhealth.Reporter
+	urlValidator       shortlink.URLValidator
+	preferences        preference.Preference
+	serviceStats       stats.Service
+	adminPolicy        ipallow.Policy
 }
 
 // ShortLinkArgs represents possible parameters for ShortLink endpoint
@@ -26,17 +43,42 @@ type ShortLinkArgs struct {
 }
 
 // ShortLink retrieves an ShortLink persistent storage given alias and expiration time.
-func (v AuthQuery) ShortLink(args *ShortLinkArgs) (*ShortLink, error) {
+// The description is left out since the short link may be owned by another
+// user.
+func (v AuthQuery) ShortLink(ctx context.Context, args *ShortLinkArgs) (*ShortLink, error) {
 	var expireAt *time.Time
 	if args.ExpireAfter != nil {
 		expireAt = &args.ExpireAfter.Time
 	}
 
-	s, err := v.shortLinkRetriever.GetShortLink(args.Alias, expireAt)
+	s, err := v.shortLinkRetriever.GetShortLink(ctx, args.Alias, expireAt)
 	if err != nil {
 		return nil, err
 	}
-	return &ShortLink{shortLink: s}, nil
+	s.Description = ""
+	shortLink := newShortLink(s, v.shortLinkShare)
+	return &shortLink, nil
+}
+
+// IsOwnerArgs represents possible parameters for IsOwner endpoint
+type IsOwnerArgs struct {
+	Alias string
+}
+
+// IsOwner checks whether the user owns the short link. The short links owned
+// by other users and the missing aliases are both reported as not owned.
+func (v AuthQuery) IsOwner(ctx context.Context, args *IsOwnerArgs) (bool, error) {
+	user, err := viewer(v.authToken, v.authenticator)
+	if err != nil {
+		return false, ErrInvalidAuthToken{}
+	}
+	ctx = tenant.NewContext(ctx, user.TenantID)
+
+	isOwner, err := v.shortLinkRetriever.IsOwner(ctx, args.Alias, user)
+	if err != nil {
+		return false, ErrUnknown{}
+	}
+	return isOwner, nil
 }
 
 // ChangeLog retrieves full ChangeLog from persistent storage
@@ -75,36 +117,428 @@ func (v AuthQuery) AllChanges() ([]Change, error) {
 	return gqlChanges, nil
 }
 
-// ShortLinks retrieves short links created by a given user from persistent storage
-func (v AuthQuery) ShortLinks() ([]ShortLink, error) {
+// ShortLinksArgs represents possible parameters for ShortLinks endpoint
+type ShortLinksArgs struct {
+	Aliases *[]string
+}
+
+// ShortLinks retrieves short links created by a given user from persistent
+// storage. When aliases are given, only the short links of those aliases are
+// retrieved in one batch.
+func (v AuthQuery) ShortLinks(ctx context.Context, args *ShortLinksArgs) ([]ShortLink, error) {
 	user, err := viewer(v.authToken, v.authenticator)
 	if err != nil {
 		return []ShortLink{}, ErrInvalidAuthToken{}
 	}
+	ctx = tenant.NewContext(ctx, user.TenantID)
 
-	shortLinks, err := v.shortLinkRetriever.GetShortLinksByUser(user)
+	var shortLinks []entity.ShortLink
+	if args.Aliases == nil {
+		shortLinks, err = v.shortLinkRetriever.GetShortLinksByUser(ctx, user)
+	} else {
+		shortLinks, err = v.shortLinkRetriever.GetShortLinksByAliases(ctx, user, *args.Aliases)
+	}
+	var ta shortlink.ErrTooManyAliases
+	if errors.As(err, &ta) {
+		return []ShortLink{}, ErrTooManyAliases(len(*args.Aliases))
+	}
 	if err != nil {
 		return []ShortLink{}, err
 	}
 
 	var gqlShortLinks []ShortLink
-	for _, v := range shortLinks {
-		gqlShortLinks = append(gqlShortLinks, newShortLink(v))
+	for _, shortLink := range shortLinks {
+		gqlShortLinks = append(gqlShortLinks, newShortLink(shortLink, v.shortLinkShare))
 	}
 
 	return gqlShortLinks, nil
 }
 
+// ShortLinkPageArgs represents possible parameters for ShortLinkPage endpoint
+type ShortLinkPageArgs struct {
+	First int32
+	After *string
+}
+
+// ShortLinkPage retrieves a page of short links created by a given user from
+// persistent storage, starting from the most recently created one.
+func (v AuthQuery) ShortLinkPage(ctx context.Context, args *ShortLinkPageArgs) (ShortLinkPage, error) {
+	user, err := viewer(v.authToken, v.authenticator)
+	if err != nil {
+		return ShortLinkPage{}, ErrInvalidAuthToken{}
+	}
+	ctx = tenant.NewContext(ctx, user.TenantID)
+
+	after := ""
+	if args.After != nil {
+		after = *args.After
+	}
+
+	page, err := v.shortLinkRetriever.GetShortLinkPageByUser(ctx, user, int(args.First), after)
+	if err == nil {
+		return newShortLinkPage(page, v.shortLinkShare), nil
+	}
+
+	var (
+		ic shortlink.ErrInvalidCursor
+		ps shortlink.ErrInvalidPageSize
+	)
+	if errors.As(err, &ic) {
+		return ShortLinkPage{}, ErrInvalidCursor(after)
+	}
+	if errors.As(err, &ps) {
+		return ShortLinkPage{}, ErrInvalidLimit(args.First)
+	}
+	return ShortLinkPage{}, err
+}
+
+// RecentShortLinksArgs represents possible parameters for RecentShortLinks
+// endpoint
+type RecentShortLinksArgs struct {
+	Limit int32
+}
+
+// RecentShortLinks retrieves the short links most recently created by a given
+// user from persistent storage, starting from the most recently created one.
+func (v AuthQuery) RecentShortLinks(ctx context.Context, args *RecentShortLinksArgs) ([]ShortLink, error) {
+	user, err := viewer(v.authToken, v.authenticator)
+	if err != nil {
+		return []ShortLink{}, ErrInvalidAuthToken{}
+	}
+	ctx = tenant.NewContext(ctx, user.TenantID)
+
+	shortLinks, err := v.shortLinkRetriever.GetRecentShortLinksByUser(ctx, user, int(args.Limit))
+	var ps shortlink.ErrInvalidPageSize
+	if errors.As(err, &ps) {
+		return []ShortLink{}, ErrInvalidLimit(args.Limit)
+	}
+	if err != nil {
+		return []ShortLink{}, err
+	}
+
+	gqlShortLinks := []ShortLink{}
+	for _, shortLink := range shortLinks {
+		gqlShortLinks = append(gqlShortLinks, newShortLink(shortLink, v.shortLinkShare))
+	}
+	return gqlShortLinks, nil
+}
+
+// BrokenShortLinks retrieves short links created by a given user whose long
+// links are no longer reachable
+func (v AuthQuery) BrokenShortLinks(ctx context.Context) ([]ShortLink, error) {
+	user, err := viewer(v.authToken, v.authenticator)
+	if err != nil {
+		return []ShortLink{}, ErrInvalidAuthToken{}
+	}
+
+	shortLinks, err := v.linkHealthReporter.GetBrokenShortLinks(ctx, user)
+	if err != nil {
+		return []ShortLink{}, err
+	}
+
+	gqlShortLinks := []ShortLink{}
+	for _, shortLink := range shortLinks {
+		gqlShortLinks = append(gqlShortLinks, newShortLink(shortLink, v.shortLinkShare))
+	}
+	return gqlShortLinks, nil
+}
+
+// LinkHealthArgs represents possible parameters for LinkHealth endpoint
+type LinkHealthArgs struct {
+	Alias string
+}
+
+// LinkHealth retrieves the outcome of the latest health checks on the long
+// link of a short link owned by the user
+func (v AuthQuery) LinkHealth(ctx context.Context, args *LinkHealthArgs) (LinkHealth, error) {
+	user, err := viewer(v.authToken, v.authenticator)
+	if err != nil {
+		return LinkHealth{}, ErrInvalidAuthToken{}
+	}
+
+	linkHealth, err := v.linkHealthReporter.GetLinkHealth(ctx, user, args.Alias)
+	if err == nil {
+		return newLinkHealth(linkHealth), nil
+	}
+
+	var nf shortlink.ErrShortLinkNotFound
+	if errors.As(err, &nf) {
+		return LinkHealth{}, ErrShortLinkNotFound(args.Alias)
+	}
+	return LinkHealth{}, ErrUnknown{}
+}
+
+// ServiceStats retrieves the statistics of the whole service for admins
+func (v AuthQuery) ServiceStats(ctx context.Context) (ServiceStats, error) {
+	err := checkAdminIP(ctx, v.adminPolicy)
+	if err != nil {
+		return ServiceStats{}, err
+	}
+
+	user, err := viewer(v.authToken, v.authenticator)
+	if err != nil {
+		return ServiceStats{}, ErrInvalidAuthToken{}
+	}
+
+	serviceStats, err := v.serviceStats.GetServiceStats(ctx, user)
+	if err == nil {
+		return newServiceStats(serviceStats), nil
+	}
+
+	var u stats.ErrUnauthorizedAction
+	if errors.As(err, &u) {
+		return ServiceStats{}, ErrUnauthorizedAction(u.Error())
+	}
+	return ServiceStats{}, ErrUnknown{}
+}
+
+var granularities = map[string]visit.Granularity{
+	"HOUR": visit.GranularityHour,
+	"DAY":  visit.GranularityDay,
+}
+
+// ClickTimeSeriesArgs represents possible parameters for ClickTimeSeries
+// endpoint
+type ClickTimeSeriesArgs struct {
+	Alias       string
+	Granularity string
+	From        scalar.Time
+	To          scalar.Time
+}
+
+// ClickTimeSeries retrieves the number of clicks of a short link owned by the
+// user bucketed by hour or day.
+func (v AuthQuery) ClickTimeSeries(ctx context.Context, args *ClickTimeSeriesArgs) ([]TimeBucket, error) {
+	user, err := viewer(v.authToken, v.authenticator)
+	if err != nil {
+		return []TimeBucket{}, ErrInvalidAuthToken{}
+	}
+
+	buckets, err := v.visitStats.GetClickTimeSeries(
+		ctx,
+		args.Alias,
+		user,
+		granularities[args.Granularity],
+		args.From.Time,
+		args.To.Time,
+	)
+	if err == nil {
+		var gqlBuckets []TimeBucket
+		for _, bucket := range buckets {
+			gqlBuckets = append(gqlBuckets, newTimeBucket(bucket))
+		}
+		return gqlBuckets, nil
+	}
+
+	var (
+		nf shortlink.ErrShortLinkNotFound
+		tr visit.ErrInvalidTimeRange
+		tb visit.ErrTooManyTimeBuckets
+	)
+	if errors.As(err, &nf) {
+		return []TimeBucket{}, ErrShortLinkNotFound(args.Alias)
+	}
+	if errors.As(err, &tr) {
+		return []TimeBucket{}, ErrInvalidTimeRange(tr.Error())
+	}
+	if errors.As(err, &tb) {
+		return []TimeBucket{}, ErrInvalidTimeRange(tb.Error())
+	}
+	return []TimeBucket{}, ErrUnknown{}
+}
+
+// TopReferrersArgs represents possible parameters for TopReferrers endpoint
+type TopReferrersArgs struct {
+	Alias string
+	Limit int32
+}
+
+// TopReferrers retrieves the hosts referring the most clicks to a short link
+// owned by the user.
+func (v AuthQuery) TopReferrers(ctx context.Context, args *TopReferrersArgs) ([]ReferrerStat, error) {
+	user, err := viewer(v.authToken, v.authenticator)
+	if err != nil {
+		return []ReferrerStat{}, ErrInvalidAuthToken{}
+	}
+
+	stats, err := v.visitStats.GetTopReferrers(ctx, args.Alias, user, int(args.Limit))
+	if err == nil {
+		gqlStats := []ReferrerStat{}
+		for _, stat := range stats {
+			gqlStats = append(gqlStats, newReferrerStat(stat))
+		}
+		return gqlStats, nil
+	}
+
+	var (
+		nf shortlink.ErrShortLinkNotFound
+		il visit.ErrInvalidLimit
+	)
+	if errors.As(err, &nf) {
+		return []ReferrerStat{}, ErrShortLinkNotFound(args.Alias)
+	}
+	if errors.As(err, &il) {
+		return []ReferrerStat{}, ErrInvalidLimit(args.Limit)
+	}
+	return []ReferrerStat{}, ErrUnknown{}
+}
+
+// DeviceBreakdownArgs represents possible parameters for DeviceBreakdown
+// endpoint
+type DeviceBreakdownArgs struct {
+	Alias string
+}
+
+// DeviceBreakdown retrieves the clicks of a short link owned by the user
+// grouped by device class, browser and operating system.
+func (v AuthQuery) DeviceBreakdown(ctx context.Context, args *DeviceBreakdownArgs) (DeviceBreakdown, error) {
+	user, err := viewer(v.authToken, v.authenticator)
+	if err != nil {
+		return DeviceBreakdown{}, ErrInvalidAuthToken{}
+	}
+
+	breakdown, err := v.visitStats.GetDeviceBreakdown(ctx, args.Alias, user)
+	if err == nil {
+		return newDeviceBreakdown(breakdown), nil
+	}
+
+	var nf shortlink.ErrShortLinkNotFound
+	if errors.As(err, &nf) {
+		return DeviceBreakdown{}, ErrShortLinkNotFound(args.Alias)
+	}
+	return DeviceBreakdown{}, ErrUnknown{}
+}
+
+// CampaignBreakdownArgs represents possible parameters for CampaignBreakdown
+// endpoint
+type CampaignBreakdownArgs struct {
+	Alias string
+}
+
+// CampaignBreakdown retrieves the clicks of a short link owned by the user
+// grouped by UTM source, medium and campaign.
+func (v AuthQuery) CampaignBreakdown(ctx context.Context, args *CampaignBreakdownArgs) ([]CampaignStat, error) {
+	user, err := viewer(v.authToken, v.authenticator)
+	if err != nil {
+		return []CampaignStat{}, ErrInvalidAuthToken{}
+	}
+
+	stats, err := v.visitStats.GetCampaignBreakdown(ctx, args.Alias, user)
+	if err == nil {
+		gqlStats := []CampaignStat{}
+		for _, stat := range stats {
+			gqlStats = append(gqlStats, newCampaignStat(stat))
+		}
+		return gqlStats, nil
+	}
+
+	var nf shortlink.ErrShortLinkNotFound
+	if errors.As(err, &nf) {
+		return []CampaignStat{}, ErrShortLinkNotFound(args.Alias)
+	}
+	return []CampaignStat{}, ErrUnknown{}
+}
+
+// ResolveAliasesArgs represents possible parameters for ResolveAliases endpoint
+type ResolveAliasesArgs struct {
+	Aliases []string
+}
+
+// ResolveAliases retrieves the status of many aliases at once. The long links
+// are only visible to the owners.
+func (v AuthQuery) ResolveAliases(ctx context.Context, args *ResolveAliasesArgs) ([]AliasResolution, error) {
+	var viewerPtr *entity.User
+	user, err := viewer(v.authToken, v.authenticator)
+	if err == nil {
+		viewerPtr = &user
+	}
+
+	resolutions, err := v.statusChecker.ResolveAliases(ctx, args.Aliases, viewerPtr)
+	if err == nil {
+		gqlResolutions := []AliasResolution{}
+		for _, resolution := range resolutions {
+			gqlResolutions = append(gqlResolutions, newAliasResolution(resolution))
+		}
+		return gqlResolutions, nil
+	}
+
+	var ta shortlink.ErrTooManyAliases
+	if errors.As(err, &ta) {
+		return []AliasResolution{}, ErrTooManyAliases(len(args.Aliases))
+	}
+	return []AliasResolution{}, ErrUnknown{}
+}
+
+// ValidateURLsArgs represents possible parameters for ValidateURLs endpoint
+type ValidateURLsArgs struct {
+	URLs []string
+}
+
+// ValidateURLs checks whether the URLs can be used as long links without
+// creating short links.
+func (v AuthQuery) ValidateURLs(args *ValidateURLsArgs) ([]URLValidationResult, error) {
+	_, err := viewer(v.authToken, v.authenticator)
+	if err != nil {
+		return []URLValidationResult{}, ErrInvalidAuthToken{}
+	}
+
+	results, err := v.urlValidator.ValidateURLs(args.URLs)
+	if err == nil {
+		gqlResults := []URLValidationResult{}
+		for _, result := range results {
+			gqlResults = append(gqlResults, newURLValidationResult(result))
+		}
+		return gqlResults, nil
+	}
+
+	var tu shortlink.ErrTooManyURLs
+	if errors.As(err, &tu) {
+		return []URLValidationResult{}, ErrTooManyURLs(len(args.URLs))
+	}
+	return []URLValidationResult{}, ErrUnknown{}
+}
+
+// Preferences fetches the default settings applied to the short links created
+// by the user
+func (v AuthQuery) Preferences() (UserPreferences, error) {
+	user, err := viewer(v.authToken, v.authenticator)
+	if err != nil {
+		return UserPreferences{}, ErrInvalidAuthToken{}
+	}
+
+	preferences, err := v.preferences.GetPreferences(user)
+	if err != nil {
+		return UserPreferences{}, ErrUnknown{}
+	}
+	return newUserPreferences(preferences), nil
+}
+
 func newAuthQuery(
 	authToken *string,
 	authenticator authenticator.Authenticator,
 	changeLog changelog.ChangeLog,
 	shortLinkRetriever shortlink.Retriever,
+	shortLinkShare share.Share,
+	visitStats visit.Stats,
+	statusChecker shortlink.StatusChecker,
+	linkHealthReporter linkhealth.Reporter,
+	urlValidator shortlink.URLValidator,
+	preferences preference.Preference,
+	serviceStats stats.Service,
+	adminPolicy ipallow.Policy,
 ) AuthQuery {
 	return AuthQuery{
 		authToken:          authToken,
 		authenticator:      authenticator,
 		changeLog:          changeLog,
 		shortLinkRetriever: shortLinkRetriever,
+		shortLinkShare:     shortLinkShare,
+		visitStats:         visitStats,
+		statusChecker:      statusChecker,
+		linkHealthReporter: linkHealthReporter,
+		urlValidator:       urlValidator,
+		preferences:        preferences,
+		serviceStats:       serviceStats,
+		adminPolicy:        adminPolicy,
 	}
 }
@@ -3,6 +3,9 @@
 package resolver
 
 import (
+	"context"
+	"fmt"
+	"net/url"
 	"testing"
 	"time"
 
@@ -11,14 +14,21 @@ import (
 	"github.com/short-d/app/fw/timer"
 	"github.com/short-d/short/backend/app/adapter/gqlapi/scalar"
 	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/fw/ipallow"
 	"github.com/short-d/short/backend/app/usecase/authenticator"
 	"github.com/short-d/short/backend/app/usecase/authorizer"
 	"github.com/short-d/short/backend/app/usecase/authorizer/rbac"
 	"github.com/short-d/short/backend/app/usecase/authorizer/rbac/role"
 	"github.com/short-d/short/backend/app/usecase/changelog"
+	"github.com/short-d/short/backend/app/usecase/featureflag"
 	"github.com/short-d/short/backend/app/usecase/keygen"
+	"github.com/short-d/short/backend/app/usecase/linkhealth"
+	"github.com/short-d/short/backend/app/usecase/preference"
 	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/share"
 	"github.com/short-d/short/backend/app/usecase/shortlink"
+	"github.com/short-d/short/backend/app/usecase/stats"
+	"github.com/short-d/short/backend/app/usecase/visit"
 )
 
 type shortLinkMap = map[string]entity.ShortLink
@@ -29,6 +39,15 @@ func TestAuthQuery_ShortLink(t *testing.T) {
 	before := now.Add(-5 * time.Second)
 	after := now.Add(5 * time.Second)
 
+	baseURL, err := url.Parse("https://short-d.com/r")
+	assert.Equal(t, nil, err)
+	shortLinkShare := share.NewShare(
+		*baseURL,
+		share.NewQRCodeGeneratorFake(),
+		shortlink.NewMetaTagPersist(nil),
+		share.Signer{},
+	)
+
 	testCases := []struct {
 		name              string
 		user              entity.User
@@ -83,6 +102,27 @@ func TestAuthQuery_ShortLink(t *testing.T) {
 				shortLink: entity.ShortLink{
 					ExpireAt: &after,
 				},
+				shortLinkShare: shortLinkShare,
+			},
+		},
+		{
+			name:  "description left out",
+			alias: "220uFicCJj",
+			expireAfter: &scalar.Time{
+				Time: now,
+			},
+			shortLinks: shortLinkMap{
+				"220uFicCJj": entity.ShortLink{
+					ExpireAt:    &after,
+					Description: "Campaign for the spring sale",
+				},
+			},
+			hasErr: false,
+			expectedShortLink: &ShortLink{
+				shortLink: entity.ShortLink{
+					ExpireAt: &after,
+				},
+				shortLinkShare: shortLinkShare,
 			},
 		},
 	}
@@ -93,10 +133,10 @@ func TestAuthQuery_ShortLink(t *testing.T) {
 			t.Parallel()
 			fakeShortLinkRepo := repository.NewShortLinkFake(nil, testCase.shortLinks)
 			fakeUserShortLinkRepo := repository.NewUserShortLinkRepoFake(nil, nil)
-			retrieverFake := shortlink.NewRetrieverPersist(&fakeShortLinkRepo, &fakeUserShortLinkRepo)
+			retrieverFake := shortlink.NewRetrieverPersist(&fakeShortLinkRepo, &fakeUserShortLinkRepo, 0)
 
 			keyFetcher := keygen.NewKeyFetcherFake([]keygen.Key{})
-			keyGen, err := keygen.NewKeyGenerator(2, &keyFetcher)
+			keyGen, err := keygen.NewRemote(2, &keyFetcher)
 			assert.Equal(t, nil, err)
 
 			timerFake := timer.NewStub(now)
@@ -110,19 +150,51 @@ func TestAuthQuery_ShortLink(t *testing.T) {
 			changeLog := changelog.NewPersist(keyGen, timerFake, &changeLogRepo, &userChangeLogRepo, au)
 
 			tokenizer := crypto.NewTokenizerFake()
-			auth := authenticator.NewAuthenticator(tokenizer, timerFake, time.Hour)
+			userRepo := repository.NewUserFake(nil)
+			auth := authenticator.NewAuthenticator(tokenizer, timerFake, time.Hour, &userRepo)
 
 			authToken, err := auth.GenerateToken(testCase.user)
 			assert.Equal(t, nil, err)
 
-			query := newAuthQuery(&authToken, auth, changeLog, retrieverFake)
+			visitRepo := repository.NewVisitFake([]entity.Visit{})
+			visitStats := visit.NewStatsPersist(&visitRepo, &fakeUserShortLinkRepo)
+
+			statusChecker := shortlink.NewStatusCheckerPersist(
+				&fakeShortLinkRepo,
+				&fakeUserShortLinkRepo,
+				timerFake,
+			)
+
+			linkHealthRepo := repository.NewLinkHealthFake([]string{}, []entity.LinkHealth{})
+			toggle := featureflag.NewToggleFake(map[featureflag.Flag]bool{})
+			linkHealthReporter := linkhealth.NewReporterPersist(
+				&fakeShortLinkRepo,
+				&fakeUserShortLinkRepo,
+				&linkHealthRepo,
+				toggle,
+			)
+
+			query := newAuthQuery(
+				&authToken,
+				auth,
+				changeLog,
+				retrieverFake,
+				shortLinkShare,
+				visitStats,
+				statusChecker,
+				linkHealthReporter,
+				shortlink.URLValidatorConcurrent{},
+				preference.Preference{},
+				stats.ServicePersist{},
+				ipallow.Policy{},
+			)
 
 			shortLinkArgs := &ShortLinkArgs{
 				Alias:       testCase.alias,
 				ExpireAfter: testCase.expireAfter,
 			}
 
-			s, err := query.ShortLink(shortLinkArgs)
+			s, err := query.ShortLink(context.Background(), shortLinkArgs)
 
 			if testCase.hasErr {
 				assert.NotEqual(t, nil, err)
@@ -132,3 +204,156 @@ func TestAuthQuery_ShortLink(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthQuery_ShortLinks(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+
+	baseURL, err := url.Parse("https://short-d.com/r")
+	assert.Equal(t, nil, err)
+	shortLinkShare := share.NewShare(
+		*baseURL,
+		share.NewQRCodeGeneratorFake(),
+		shortlink.NewMetaTagPersist(nil),
+		share.Signer{},
+	)
+
+	owner := entity.User{ID: "12345", Email: "alpha@example.com"}
+	otherUser := entity.User{ID: "12346", Email: "beta@example.com"}
+	google := entity.ShortLink{Alias: "google", LongLink: "https://www.google.com"}
+	github := entity.ShortLink{Alias: "github", LongLink: "https://www.github.com"}
+	facebook := entity.ShortLink{Alias: "facebook", LongLink: "https://www.facebook.com"}
+
+	var tooManyAliases []string
+	for idx := 0; idx <= 100; idx++ {
+		tooManyAliases = append(tooManyAliases, fmt.Sprintf("alias%03d", idx))
+	}
+
+	testCases := []struct {
+		name            string
+		aliases         *[]string
+		expectedErr     error
+		expectedAliases []string
+	}{
+		{
+			name:            "all owned short links",
+			expectedAliases: []string{"google", "facebook"},
+		},
+		{
+			name:            "owned and non-owned aliases",
+			aliases:         &[]string{"facebook", "github", "google", "tea"},
+			expectedAliases: []string{"facebook", "google"},
+		},
+		{
+			name:        "too many aliases",
+			aliases:     &tooManyAliases,
+			expectedErr: ErrTooManyAliases(len(tooManyAliases)),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			fakeShortLinkRepo := repository.NewShortLinkFake(nil, shortLinkMap{
+				google.Alias:   google,
+				github.Alias:   github,
+				facebook.Alias: facebook,
+			})
+			fakeUserShortLinkRepo := repository.NewUserShortLinkRepoFake(
+				[]entity.User{owner, otherUser, owner},
+				[]entity.ShortLink{google, github, facebook},
+			)
+			retriever := shortlink.NewRetrieverPersist(&fakeShortLinkRepo, &fakeUserShortLinkRepo, 0)
+
+			tokenizer := crypto.NewTokenizerFake()
+			userRepo := repository.NewUserFake(nil)
+			auth := authenticator.NewAuthenticator(tokenizer, timer.NewStub(now), time.Hour, &userRepo)
+			authToken, err := auth.GenerateToken(owner)
+			assert.Equal(t, nil, err)
+
+			query := newAuthQuery(
+				&authToken,
+				auth,
+				nil,
+				retriever,
+				shortLinkShare,
+				nil,
+				nil,
+				nil,
+				shortlink.URLValidatorConcurrent{},
+				preference.Preference{},
+				stats.ServicePersist{},
+				ipallow.Policy{},
+			)
+
+			gqlShortLinks, err := query.ShortLinks(context.Background(), &ShortLinksArgs{
+				Aliases: testCase.aliases,
+			})
+			if testCase.expectedErr != nil {
+				assert.Equal(t, testCase.expectedErr, err)
+				return
+			}
+			assert.Equal(t, nil, err)
+
+			var aliases []string
+			for _, gqlShortLink := range gqlShortLinks {
+				aliases = append(aliases, *gqlShortLink.Alias())
+			}
+			assert.Equal(t, testCase.expectedAliases, aliases)
+		})
+	}
+}
+
+func TestAuthQuery_ServiceStatsAdminIPPolicy(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		clientIP    string
+		expectedErr error
+	}{
+		{
+			name:        "client IP within allowlist",
+			clientIP:    "10.0.0.2",
+			expectedErr: ErrInvalidAuthToken{},
+		},
+		{
+			name:        "client IP outside allowlist",
+			clientIP:    "198.51.100.9",
+			expectedErr: ErrUnauthorizedAction("client IP 198.51.100.9 is not allowed to perform admin actions"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			adminPolicy, err := ipallow.NewPolicy(nil, []string{"10.0.0.0/8"})
+			assert.Equal(t, nil, err)
+
+			userRepo := repository.NewUserFake(nil)
+			auth := authenticator.NewAuthenticator(crypto.NewTokenizerFake(), timer.NewStub(time.Now()), time.Hour, &userRepo)
+			invalidToken := "invalid"
+			query := newAuthQuery(
+				&invalidToken,
+				auth,
+				nil,
+				nil,
+				share.Share{},
+				nil,
+				nil,
+				nil,
+				shortlink.URLValidatorConcurrent{},
+				preference.Preference{},
+				stats.ServicePersist{},
+				adminPolicy,
+			)
+
+			ctx := WithClientIP(context.Background(), testCase.clientIP)
+			_, err = query.ServiceStats(ctx)
+			assert.Equal(t, testCase.expectedErr, err)
+		})
+	}
+}
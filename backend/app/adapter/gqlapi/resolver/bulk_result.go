@@ -0,0 +1,78 @@
+package resolver
+
+import (
+	"fmt"
+
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/share"
+	"github.com/short-d/short/backend/app/usecase/shortlink"
+)
+
+// CreateShortLinksResult retrieves the short links created in a batch along
+// with the inputs failing to create short links.
+type CreateShortLinksResult struct {
+	shortLinks []*ShortLink
+	errors     []ShortLinkInputError
+}
+
+// ShortLinks retrieves the short links created in the order of their inputs.
+func (c CreateShortLinksResult) ShortLinks() []*ShortLink {
+	return c.shortLinks
+}
+
+// Errors retrieves why the inputs fail to create short links.
+func (c CreateShortLinksResult) Errors() []ShortLinkInputError {
+	return c.errors
+}
+
+// ShortLinkInputError retrieves why an input in a batch fails to create a
+// short link.
+type ShortLinkInputError struct {
+	index int
+	err   GraphQLError
+}
+
+// Index retrieves the position of the input in the batch.
+func (s ShortLinkInputError) Index() int32 {
+	return int32(s.index)
+}
+
+// Code retrieves the error code, the same as the one returned by
+// createShortLink for the input.
+func (s ShortLinkInputError) Code() string {
+	return fmt.Sprint(s.err.Extensions()["code"])
+}
+
+// Violation retrieves which rule the long link or the custom alias of the
+// input breaks, if any.
+func (s ShortLinkInputError) Violation() *string {
+	violation, ok := s.err.Extensions()["violation"].(string)
+	if !ok {
+		return nil
+	}
+	return &violation
+}
+
+func newCreateShortLinksResult(
+	report shortlink.BulkReport,
+	shortLinkInputs []entity.ShortLinkInput,
+	shortLinkShare share.Share,
+) CreateShortLinksResult {
+	result := CreateShortLinksResult{
+		shortLinks: []*ShortLink{},
+		errors:     []ShortLinkInputError{},
+	}
+	for _, shortLink := range report.Created {
+		result.shortLinks = append(result.shortLinks, &ShortLink{
+			shortLink:      shortLink,
+			shortLinkShare: shortLinkShare,
+		})
+	}
+	for _, failure := range report.Failures {
+		result.errors = append(result.errors, ShortLinkInputError{
+			index: failure.Index,
+			err:   newCreateShortLinkError(failure.Err, shortLinkInputs[failure.Index]),
+		})
+	}
+	return result
+}
@@ -0,0 +1,32 @@
+package resolver
+
+import "github.com/short-d/short/backend/app/usecase/visit"
+
+// CampaignStat retrieves the number of clicks attributed to a UTM campaign.
+type CampaignStat struct {
+	campaignStat visit.CampaignStat
+}
+
+// Source retrieves the UTM source of the campaign.
+func (c CampaignStat) Source() string {
+	return c.campaignStat.Campaign.Source
+}
+
+// Medium retrieves the UTM medium of the campaign.
+func (c CampaignStat) Medium() string {
+	return c.campaignStat.Campaign.Medium
+}
+
+// Campaign retrieves the UTM campaign name.
+func (c CampaignStat) Campaign() string {
+	return c.campaignStat.Campaign.Name
+}
+
+// Clicks retrieves the number of clicks attributed to the campaign.
+func (c CampaignStat) Clicks() int32 {
+	return int32(c.campaignStat.Clicks)
+}
+
+func newCampaignStat(campaignStat visit.CampaignStat) CampaignStat {
+	return CampaignStat{campaignStat: campaignStat}
+}
@@ -0,0 +1,33 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/short-d/short/backend/app/fw/ipallow"
+)
+
+type clientIPKey struct{}
+
+// WithClientIP attaches the IP address of the client to the request context
+// so that the resolvers can rate limit clients.
+func WithClientIP(ctx context.Context, clientIP string) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, clientIP)
+}
+
+// clientIP retrieves the IP address of the client from the request context.
+// Empty string is returned when it is unknown.
+func clientIP(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey{}).(string)
+	return ip
+}
+
+// checkAdminIP rejects admin operations requested by the clients outside of
+// the admin IP allowlist, the same way as the admin HTTP APIs.
+func checkAdminIP(ctx context.Context, adminPolicy ipallow.Policy) error {
+	ip := clientIP(ctx)
+	if adminPolicy.IsIPAllowed(ip) {
+		return nil
+	}
+	return ErrUnauthorizedAction(fmt.Sprintf("client IP %s is not allowed to perform admin actions", ip))
+}
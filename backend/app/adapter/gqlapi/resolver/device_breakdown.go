@@ -0,0 +1,52 @@
+package resolver
+
+import "github.com/short-d/short/backend/app/usecase/visit"
+
+// DeviceBreakdown retrieves the clicks of a short link grouped by the devices
+// of the visitors.
+type DeviceBreakdown struct {
+	breakdown visit.DeviceBreakdown
+}
+
+// DeviceClasses retrieves the clicks for each device class.
+func (d DeviceBreakdown) DeviceClasses() []DeviceStat {
+	return newDeviceStats(d.breakdown.DeviceClasses)
+}
+
+// Browsers retrieves the clicks for each browser.
+func (d DeviceBreakdown) Browsers() []DeviceStat {
+	return newDeviceStats(d.breakdown.Browsers)
+}
+
+// OperatingSystems retrieves the clicks for each operating system.
+func (d DeviceBreakdown) OperatingSystems() []DeviceStat {
+	return newDeviceStats(d.breakdown.OperatingSystems)
+}
+
+// DeviceStat retrieves the number of clicks coming from a device class,
+// browser or operating system.
+type DeviceStat struct {
+	deviceStat visit.DeviceStat
+}
+
+// Name retrieves the device class, browser or operating system.
+func (d DeviceStat) Name() string {
+	return d.deviceStat.Name
+}
+
+// Clicks retrieves the number of clicks coming from the device.
+func (d DeviceStat) Clicks() int32 {
+	return int32(d.deviceStat.Clicks)
+}
+
+func newDeviceStats(deviceStats []visit.DeviceStat) []DeviceStat {
+	gqlStats := []DeviceStat{}
+	for _, deviceStat := range deviceStats {
+		gqlStats = append(gqlStats, DeviceStat{deviceStat: deviceStat})
+	}
+	return gqlStats
+}
+
+func newDeviceBreakdown(breakdown visit.DeviceBreakdown) DeviceBreakdown {
+	return DeviceBreakdown{breakdown: breakdown}
+}
@@ -5,17 +5,33 @@ type ErrCode string
 
 // The constants enumerate all supported error codes.
 const (
-	ErrCodeUnknown            ErrCode = "unknown"
-	ErrCodeAliasAlreadyExist          = "aliasAlreadyExist"
-	ErrCodeShortLinkNotFound          = "shortLinkNotFound"
-	ErrCodeEmptyAlias                 = "emptyAlias"
-	ErrCodeRequesterNotHuman          = "requesterNotHuman"
-	ErrCodeInvalidLongLink            = "invalidLongLink"
-	ErrCodeInvalidCustomAlias         = "invalidCustomAlias"
-	ErrCodeAliasWithFragment          = "aliasWithFragment"
-	ErrCodeMaliciousContent           = "maliciousContent"
-	ErrCodeInvalidAuthToken           = "invalidAuthToken"
-	ErrCodeUnauthorizedAction         = "unauthorizedAction"
+	ErrCodeUnknown               ErrCode = "unknown"
+	ErrCodeAliasAlreadyExist             = "aliasAlreadyExist"
+	ErrCodeShortLinkNotFound             = "shortLinkNotFound"
+	ErrCodeEmptyAlias                    = "emptyAlias"
+	ErrCodeRequesterNotHuman             = "requesterNotHuman"
+	ErrCodeInvalidLongLink               = "invalidLongLink"
+	ErrCodeInvalidCustomAlias            = "invalidCustomAlias"
+	ErrCodeAliasWithFragment             = "aliasWithFragment"
+	ErrCodeMaliciousContent              = "maliciousContent"
+	ErrCodeInvalidAuthToken              = "invalidAuthToken"
+	ErrCodeUnauthorizedAction            = "unauthorizedAction"
+	ErrCodeInvalidTimeRange              = "invalidTimeRange"
+	ErrCodeTooManyAliases                = "tooManyAliases"
+	ErrCodeInvalidLimit                  = "invalidLimit"
+	ErrCodeSelfReferential               = "selfReferentialLink"
+	ErrCodeTooManyURLs                   = "tooManyURLs"
+	ErrCodeInvalidCursor                 = "invalidCursor"
+	ErrCodeAliasQuotaExceeded            = "aliasQuotaExceeded"
+	ErrCodeInvalidPreferences            = "invalidPreferences"
+	ErrCodeTooManyAliasChecks            = "tooManyAliasChecks"
+	ErrCodeServiceReadOnly               = "serviceReadOnly"
+	ErrCodeConfusableAlias               = "confusableAlias"
+	ErrCodeDescriptionTooLong            = "descriptionTooLong"
+	ErrCodeUnknownFeatureFlag            = "unknownFeatureFlag"
+	ErrCodeAliasReserved                 = "aliasReserved"
+	ErrCodeTooManyShortLinks             = "tooManyShortLinks"
+	ErrCodeInvalidReservationTTL         = "invalidReservationTTL"
 )
 
 // GraphQLError represents a GraphAPI error.
@@ -139,6 +155,26 @@ func (e ErrInvalidLongLink) Error() string {
 	return "long link is invalid"
 }
 
+// ErrSelfReferentialLink signifies that the provided long link redirects back
+// to Short.
+type ErrSelfReferentialLink string
+
+var _ GraphQLError = (*ErrSelfReferentialLink)(nil)
+
+// Extensions keeps structured error metadata so that the clients can gracefully
+// handle the error.
+func (e ErrSelfReferentialLink) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code":     ErrCodeSelfReferential,
+		"longLink": string(e),
+	}
+}
+
+// Error retrieves the human readable error message.
+func (e ErrSelfReferentialLink) Error() string {
+	return "long link redirects back to short link"
+}
+
 // ErrInvalidCustomAlias signifies that the provided custom alias has incorrect
 // format.
 type ErrInvalidCustomAlias struct {
@@ -218,3 +254,299 @@ func (e ErrUnauthorizedAction) Extensions() map[string]interface{} {
 func (e ErrUnauthorizedAction) Error() string {
 	return "unauthorized action"
 }
+
+// ErrInvalidTimeRange signifies the requested time range is not supported.
+type ErrInvalidTimeRange string
+
+var _ GraphQLError = (*ErrInvalidTimeRange)(nil)
+
+// Extensions keeps structured error metadata so that the clients can reliably
+// handle the error.
+func (e ErrInvalidTimeRange) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code":   ErrCodeInvalidTimeRange,
+		"reason": string(e),
+	}
+}
+
+// Error retrieves the human readable error message.
+func (e ErrInvalidTimeRange) Error() string {
+	return "time range is invalid"
+}
+
+// ErrTooManyAliases signifies too many aliases are requested at once.
+type ErrTooManyAliases int
+
+var _ GraphQLError = (*ErrTooManyAliases)(nil)
+
+// Extensions keeps structured error metadata so that the clients can reliably
+// handle the error.
+func (e ErrTooManyAliases) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code":  ErrCodeTooManyAliases,
+		"count": int(e),
+	}
+}
+
+// Error retrieves the human readable error message.
+func (e ErrTooManyAliases) Error() string {
+	return "too many aliases requested"
+}
+
+// ErrTooManyURLs signifies too many URLs are validated at once.
+type ErrTooManyURLs int
+
+var _ GraphQLError = (*ErrTooManyURLs)(nil)
+
+// Extensions keeps structured error metadata so that the clients can reliably
+// handle the error.
+func (e ErrTooManyURLs) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code":  ErrCodeTooManyURLs,
+		"count": int(e),
+	}
+}
+
+// Error retrieves the human readable error message.
+func (e ErrTooManyURLs) Error() string {
+	return "too many URLs requested"
+}
+
+// ErrInvalidLimit signifies the requested maximum number of results is not
+// supported.
+type ErrInvalidLimit int
+
+var _ GraphQLError = (*ErrInvalidLimit)(nil)
+
+// Extensions keeps structured error metadata so that the clients can reliably
+// handle the error.
+func (e ErrInvalidLimit) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code":  ErrCodeInvalidLimit,
+		"limit": int(e),
+	}
+}
+
+// Error retrieves the human readable error message.
+func (e ErrInvalidLimit) Error() string {
+	return "limit is invalid"
+}
+
+// ErrInvalidCursor signifies the pagination cursor is not issued by the
+// server.
+type ErrInvalidCursor string
+
+var _ GraphQLError = (*ErrInvalidCursor)(nil)
+
+// Extensions keeps structured error metadata so that the clients can reliably
+// handle the error.
+func (e ErrInvalidCursor) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code":   ErrCodeInvalidCursor,
+		"cursor": string(e),
+	}
+}
+
+// Error retrieves the human readable error message.
+func (e ErrInvalidCursor) Error() string {
+	return "cursor is invalid"
+}
+
+// ErrAliasQuotaExceeded signifies the user already owns the maximum number of
+// active short links allowed.
+type ErrAliasQuotaExceeded struct{}
+
+var _ GraphQLError = (*ErrAliasQuotaExceeded)(nil)
+
+// Extensions keeps structured error metadata so that the clients can reliably
+// handle the error.
+func (e ErrAliasQuotaExceeded) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code": ErrCodeAliasQuotaExceeded,
+	}
+}
+
+// Error retrieves the human readable error message.
+func (e ErrAliasQuotaExceeded) Error() string {
+	return "alias quota exceeded"
+}
+
+// ErrInvalidPreferences signifies the preferences can't be applied to short
+// links.
+type ErrInvalidPreferences string
+
+var _ GraphQLError = (*ErrInvalidPreferences)(nil)
+
+// Extensions keeps structured error metadata so that the clients can reliably
+// handle the error.
+func (e ErrInvalidPreferences) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code":   ErrCodeInvalidPreferences,
+		"reason": string(e),
+	}
+}
+
+// Error retrieves the human readable error message.
+func (e ErrInvalidPreferences) Error() string {
+	return "preferences are invalid"
+}
+
+// ErrTooManyAliasChecks signifies the client checks the availability of
+// aliases too often.
+type ErrTooManyAliasChecks struct{}
+
+var _ GraphQLError = (*ErrTooManyAliasChecks)(nil)
+
+// Extensions keeps structured error metadata so that the clients can reliably
+// handle the error.
+func (e ErrTooManyAliasChecks) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code": ErrCodeTooManyAliasChecks,
+	}
+}
+
+// Error retrieves the human readable error message.
+func (e ErrTooManyAliasChecks) Error() string {
+	return "too many aliases checked"
+}
+
+// ErrServiceReadOnly signifies short links can't be changed while the service
+// is in read-only mode.
+type ErrServiceReadOnly struct{}
+
+var _ GraphQLError = (*ErrServiceReadOnly)(nil)
+
+// Extensions keeps structured error metadata so that the clients can reliably
+// handle the error.
+func (e ErrServiceReadOnly) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code": ErrCodeServiceReadOnly,
+	}
+}
+
+// Error retrieves the human readable error message.
+func (e ErrServiceReadOnly) Error() string {
+	return "service is read-only"
+}
+
+// ErrConfusableAlias signifies the custom alias looks like an existing alias.
+type ErrConfusableAlias struct {
+	customAlias   string
+	existingAlias string
+}
+
+var _ GraphQLError = (*ErrConfusableAlias)(nil)
+
+// Extensions keeps structured error metadata so that the clients can reliably
+// handle the error.
+func (e ErrConfusableAlias) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code":          ErrCodeConfusableAlias,
+		"customAlias":   e.customAlias,
+		"existingAlias": e.existingAlias,
+	}
+}
+
+// Error retrieves the human readable error message.
+func (e ErrConfusableAlias) Error() string {
+	return "custom alias looks like an existing alias"
+}
+
+// ErrDescriptionTooLong signifies the description of the short link has more
+// characters than allowed.
+type ErrDescriptionTooLong int
+
+var _ GraphQLError = (*ErrDescriptionTooLong)(nil)
+
+// Extensions keeps structured error metadata so that the clients can reliably
+// handle the error.
+func (e ErrDescriptionTooLong) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code":   ErrCodeDescriptionTooLong,
+		"length": int(e),
+	}
+}
+
+// Error retrieves the human readable error message.
+func (e ErrDescriptionTooLong) Error() string {
+	return "description is too long"
+}
+
+// ErrUnknownFeatureFlag signifies the feature flag is not consulted by the
+// service.
+type ErrUnknownFeatureFlag string
+
+var _ GraphQLError = (*ErrUnknownFeatureFlag)(nil)
+
+// Extensions keeps structured error metadata so that the clients can reliably
+// handle the error.
+func (e ErrUnknownFeatureFlag) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code": ErrCodeUnknownFeatureFlag,
+		"flag": string(e),
+	}
+}
+
+// Error retrieves the human readable error message.
+func (e ErrUnknownFeatureFlag) Error() string {
+	return "unknown feature flag"
+}
+
+// ErrAliasReserved signifies a wanted custom alias is held by another user for
+// the time being.
+type ErrAliasReserved string
+
+var _ GraphQLError = (*ErrAliasReserved)(nil)
+
+// Extensions keeps structured error metadata so that the clients can reliably
+// handle the error.
+func (e ErrAliasReserved) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code":  ErrCodeAliasReserved,
+		"alias": string(e),
+	}
+}
+
+// Error retrieves the human readable error message.
+func (e ErrAliasReserved) Error() string {
+	return "alias is reserved by another user"
+}
+
+// ErrTooManyShortLinks signifies too many short links are created at once.
+type ErrTooManyShortLinks int
+
+var _ GraphQLError = (*ErrTooManyShortLinks)(nil)
+
+// Extensions keeps structured error metadata so that the clients can reliably
+// handle the error.
+func (e ErrTooManyShortLinks) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code":  ErrCodeTooManyShortLinks,
+		"count": int(e),
+	}
+}
+
+// Error retrieves the human readable error message.
+func (e ErrTooManyShortLinks) Error() string {
+	return "too many short links requested"
+}
+
+// ErrInvalidReservationTTL signifies the alias can't be reserved for the
+// requested duration.
+type ErrInvalidReservationTTL string
+
+var _ GraphQLError = (*ErrInvalidReservationTTL)(nil)
+
+// Extensions keeps structured error metadata so that the clients can reliably
+// handle the error.
+func (e ErrInvalidReservationTTL) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code":   ErrCodeInvalidReservationTTL,
+		"reason": string(e),
+	}
+}
+
+// Error retrieves the human readable error message.
+func (e ErrInvalidReservationTTL) Error() string {
+	return "reservation TTL is invalid"
+}
@@ -0,0 +1,46 @@
+package resolver
+
+import (
+	"github.com/short-d/short/backend/app/adapter/gqlapi/scalar"
+	"github.com/short-d/short/backend/app/entity"
+)
+
+var linkHealthStatuses = map[entity.LinkHealthStatus]string{
+	entity.LinkHealthUnknown: "UNKNOWN",
+	entity.LinkHealthHealthy: "HEALTHY",
+	entity.LinkHealthBroken:  "BROKEN",
+}
+
+// LinkHealth retrieves the outcome of the latest health checks on the long
+// link of a short link.
+type LinkHealth struct {
+	linkHealth entity.LinkHealth
+}
+
+// Alias retrieves the alias of the short link.
+func (l LinkHealth) Alias() string {
+	return l.linkHealth.Alias
+}
+
+// Status retrieves whether the long link is reachable.
+func (l LinkHealth) Status() string {
+	return linkHealthStatuses[l.linkHealth.Status]
+}
+
+// ConsecutiveFailures retrieves the number of failed checks in a row.
+func (l LinkHealth) ConsecutiveFailures() int32 {
+	return int32(l.linkHealth.ConsecutiveFailures)
+}
+
+// LastCheckedAt retrieves the time of the latest check, which is empty when
+// the long link has never been checked.
+func (l LinkHealth) LastCheckedAt() *scalar.Time {
+	if l.linkHealth.LastCheckedAt.IsZero() {
+		return nil
+	}
+	return &scalar.Time{Time: l.linkHealth.LastCheckedAt}
+}
+
+func newLinkHealth(linkHealth entity.LinkHealth) LinkHealth {
+	return LinkHealth{linkHealth: linkHealth}
+}
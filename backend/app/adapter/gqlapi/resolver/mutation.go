@@ -2,9 +2,15 @@ package resolver
 
 import (
 	"github.com/short-d/app/fw/logger"
+	"github.com/short-d/short/backend/app/fw/ipallow"
 	"github.com/short-d/short/backend/app/usecase/authenticator"
 	"github.com/short-d/short/backend/app/usecase/changelog"
+	"github.com/short-d/short/backend/app/usecase/featureflag"
+	"github.com/short-d/short/backend/app/usecase/maintenance"
+	"github.com/short-d/short/backend/app/usecase/preference"
 	"github.com/short-d/short/backend/app/usecase/requester"
+	"github.com/short-d/short/backend/app/usecase/risk"
+	"github.com/short-d/short/backend/app/usecase/share"
 	"github.com/short-d/short/backend/app/usecase/shortlink"
 )
 
@@ -13,9 +19,17 @@ type Mutation struct {
 	logger            logger.Logger
 	shortLinkCreator  shortlink.Creator
 	shortLinkUpdater  shortlink.Updater
+	shortLinkDeleter  shortlink.Deleter
+	shortLinkExpirer  shortlink.Expirer
 	requesterVerifier requester.Verifier
 	authenticator     authenticator.Authenticator
 	changeLog         changelog.ChangeLog
+	shortLinkShare    share.Share
+	domainDenylist    risk.DomainDenylist
+	preferences       preference.Preference
+	maintenanceSwitch maintenance.Switch
+	featureFlagSwitch featureflag.Switch
+	adminPolicy       ipallow.Policy
 }
 
 // AuthMutationArgs represents possible parameters for AuthMutation endpoint
@@ -42,6 +56,14 @@ func (m Mutation) AuthMutation(args *AuthMutationArgs) (*AuthMutation, error) {
 		m.changeLog,
 		m.shortLinkCreator,
 		m.shortLinkUpdater,
+		m.shortLinkDeleter,
+		m.shortLinkExpirer,
+		m.shortLinkShare,
+		m.domainDenylist,
+		m.preferences,
+		m.maintenanceSwitch,
+		m.featureFlagSwitch,
+		m.adminPolicy,
 	)
 	return &authMutation, nil
 }
@@ -51,15 +73,31 @@ func newMutation(
 	changeLog changelog.ChangeLog,
 	shortLinkCreator shortlink.Creator,
 	shortLinkUpdater shortlink.Updater,
+	shortLinkDeleter shortlink.Deleter,
+	shortLinkExpirer shortlink.Expirer,
 	requesterVerifier requester.Verifier,
 	authenticator authenticator.Authenticator,
+	shortLinkShare share.Share,
+	domainDenylist risk.DomainDenylist,
+	preferences preference.Preference,
+	maintenanceSwitch maintenance.Switch,
+	featureFlagSwitch featureflag.Switch,
+	adminPolicy ipallow.Policy,
 ) Mutation {
 	return Mutation{
 		logger:            logger,
 		changeLog:         changeLog,
 		shortLinkCreator:  shortLinkCreator,
 		shortLinkUpdater:  shortLinkUpdater,
+		shortLinkDeleter:  shortLinkDeleter,
+		shortLinkExpirer:  shortLinkExpirer,
 		requesterVerifier: requesterVerifier,
 		authenticator:     authenticator,
+		shortLinkShare:    shortLinkShare,
+		domainDenylist:    domainDenylist,
+		preferences:       preferences,
+		maintenanceSwitch: maintenanceSwitch,
+		featureFlagSwitch: featureFlagSwitch,
+		adminPolicy:       adminPolicy,
 	}
 }
@@ -1,10 +1,19 @@
 package resolver
 
 import (
+	"context"
+	"errors"
+
 	"github.com/short-d/app/fw/logger"
+	"github.com/short-d/short/backend/app/fw/ipallow"
 	"github.com/short-d/short/backend/app/usecase/authenticator"
 	"github.com/short-d/short/backend/app/usecase/changelog"
+	"github.com/short-d/short/backend/app/usecase/linkhealth"
+	"github.com/short-d/short/backend/app/usecase/preference"
+	"github.com/short-d/short/backend/app/usecase/share"
 	"github.com/short-d/short/backend/app/usecase/shortlink"
+	"github.com/short-d/short/backend/app/usecase/stats"
+	"github.com/short-d/short/backend/app/usecase/visit"
 )
 
 // Query represents GraphQL query resolver
@@ -13,6 +22,15 @@ type Query struct {
 	authenticator      authenticator.Authenticator
 	changeLog          changelog.ChangeLog
 	shortLinkRetriever shortlink.Retriever
+	shortLinkShare     share.Share
+	visitStats         visit.Stats
+	statusChecker      shortlink.StatusChecker
+	linkHealthReporter linkhealth.Reporter
+	urlValidator       shortlink.URLValidator
+	preferences        preference.Preference
+	aliasChecker       shortlink.AliasChecker
+	serviceStats       stats.Service
+	adminPolicy        ipallow.Policy
 }
 
 // AuthQueryArgs represents possible parameters for AuthQuery endpoint
@@ -22,20 +40,73 @@ type AuthQueryArgs struct {
 
 // AuthQuery extracts user information from authentication token
 func (q Query) AuthQuery(args *AuthQueryArgs) (*AuthQuery, error) {
-	authQuery := newAuthQuery(args.AuthToken, q.authenticator, q.changeLog, q.shortLinkRetriever)
+	authQuery := newAuthQuery(
+		args.AuthToken,
+		q.authenticator,
+		q.changeLog,
+		q.shortLinkRetriever,
+		q.shortLinkShare,
+		q.visitStats,
+		q.statusChecker,
+		q.linkHealthReporter,
+		q.urlValidator,
+		q.preferences,
+		q.serviceStats,
+		q.adminPolicy,
+	)
 	return &authQuery, nil
 }
 
+// IsAliasAvailableArgs represents possible parameters for IsAliasAvailable
+// endpoint
+type IsAliasAvailableArgs struct {
+	Alias string
+}
+
+// IsAliasAvailable checks whether a custom alias can be used to create a
+// short link, explaining why when the alias is invalid.
+func (q Query) IsAliasAvailable(ctx context.Context, args *IsAliasAvailableArgs) (*AliasAvailability, error) {
+	availability, err := q.aliasChecker.CheckAlias(ctx, args.Alias, clientIP(ctx))
+	if err == nil {
+		gqlAvailability := newAliasAvailability(availability)
+		return &gqlAvailability, nil
+	}
+
+	var tc shortlink.ErrTooManyAliasChecks
+	if errors.As(err, &tc) {
+		return nil, ErrTooManyAliasChecks{}
+	}
+	return nil, ErrUnknown{}
+}
+
 func newQuery(
 	logger logger.Logger,
 	authenticator authenticator.Authenticator,
 	changeLog changelog.ChangeLog,
 	shortLinkRetriever shortlink.Retriever,
+	shortLinkShare share.Share,
+	visitStats visit.Stats,
+	statusChecker shortlink.StatusChecker,
+	linkHealthReporter linkhealth.Reporter,
+	urlValidator shortlink.URLValidator,
+	preferences preference.Preference,
+	aliasChecker shortlink.AliasChecker,
+	serviceStats stats.Service,
+	adminPolicy ipallow.Policy,
 ) Query {
 	return Query{
 		logger:             logger,
 		authenticator:      authenticator,
 		changeLog:          changeLog,
 		shortLinkRetriever: shortLinkRetriever,
+		shortLinkShare:     shortLinkShare,
+		visitStats:         visitStats,
+		statusChecker:      statusChecker,
+		linkHealthReporter: linkHealthReporter,
+		urlValidator:       urlValidator,
+		preferences:        preferences,
+		aliasChecker:       aliasChecker,
+		serviceStats:       serviceStats,
+		adminPolicy:        adminPolicy,
 	}
 }
@@ -3,6 +3,8 @@
 package resolver
 
 import (
+	"context"
+	"net/url"
 	"testing"
 	"time"
 
@@ -10,14 +12,23 @@ import (
 	"github.com/short-d/app/fw/logger"
 	"github.com/short-d/app/fw/timer"
 	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/fw/ipallow"
 	"github.com/short-d/short/backend/app/usecase/authenticator"
 	"github.com/short-d/short/backend/app/usecase/authorizer"
 	"github.com/short-d/short/backend/app/usecase/authorizer/rbac"
 	"github.com/short-d/short/backend/app/usecase/authorizer/rbac/role"
 	"github.com/short-d/short/backend/app/usecase/changelog"
+	"github.com/short-d/short/backend/app/usecase/featureflag"
 	"github.com/short-d/short/backend/app/usecase/keygen"
+	"github.com/short-d/short/backend/app/usecase/linkhealth"
+	"github.com/short-d/short/backend/app/usecase/preference"
+	"github.com/short-d/short/backend/app/usecase/ratelimit"
 	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/share"
 	"github.com/short-d/short/backend/app/usecase/shortlink"
+	"github.com/short-d/short/backend/app/usecase/stats"
+	"github.com/short-d/short/backend/app/usecase/validator"
+	"github.com/short-d/short/backend/app/usecase/visit"
 )
 
 func TestQuery_AuthQuery(t *testing.T) {
@@ -65,13 +76,13 @@ func TestQuery_AuthQuery(t *testing.T) {
 			fakeShortLinkRepo := repository.NewShortLinkFake(nil, map[string]entity.ShortLink{})
 			fakeUserShortLinkRepo := repository.NewUserShortLinkRepoFake(nil, nil)
 			auth := authenticator.NewAuthenticatorFake(time.Now(), time.Hour)
-			retrieverFake := shortlink.NewRetrieverPersist(&fakeShortLinkRepo, &fakeUserShortLinkRepo)
+			retrieverFake := shortlink.NewRetrieverPersist(&fakeShortLinkRepo, &fakeUserShortLinkRepo, 0)
 			entryRepo := logger.NewEntryRepoFake()
 			lg, err := logger.NewFake(logger.LogOff, &entryRepo)
 			assert.Equal(t, nil, err)
 
 			keyFetcher := keygen.NewKeyFetcherFake([]keygen.Key{})
-			keyGen, err := keygen.NewKeyGenerator(2, &keyFetcher)
+			keyGen, err := keygen.NewRemote(2, &keyFetcher)
 			assert.Equal(t, nil, err)
 
 			tm := timer.NewStub(now)
@@ -84,7 +95,44 @@ func TestQuery_AuthQuery(t *testing.T) {
 
 			changeLog := changelog.NewPersist(keyGen, tm, &changeLogRepo, &userChangeLogRepo, au)
 
-			query := newQuery(lg, auth, changeLog, retrieverFake)
+			baseURL, err := url.Parse("https://short-d.com/r")
+			assert.Equal(t, nil, err)
+			metaTag := shortlink.NewMetaTagPersist(&fakeShortLinkRepo)
+			shortLinkShare := share.NewShare(*baseURL, share.NewQRCodeGeneratorFake(), metaTag, share.Signer{})
+
+			visitRepo := repository.NewVisitFake([]entity.Visit{})
+			visitStats := visit.NewStatsPersist(&visitRepo, &fakeUserShortLinkRepo)
+
+			statusChecker := shortlink.NewStatusCheckerPersist(
+				&fakeShortLinkRepo,
+				&fakeUserShortLinkRepo,
+				tm,
+			)
+
+			linkHealthRepo := repository.NewLinkHealthFake([]string{}, []entity.LinkHealth{})
+			toggle := featureflag.NewToggleFake(map[featureflag.Flag]bool{})
+			linkHealthReporter := linkhealth.NewReporterPersist(
+				&fakeShortLinkRepo,
+				&fakeUserShortLinkRepo,
+				&linkHealthRepo,
+				toggle,
+			)
+
+			query := newQuery(
+				lg,
+				auth,
+				changeLog,
+				retrieverFake,
+				shortLinkShare,
+				visitStats,
+				statusChecker,
+				linkHealthReporter,
+				shortlink.URLValidatorConcurrent{},
+				preference.Preference{},
+				shortlink.AliasCheckerPersist{},
+				stats.ServicePersist{},
+				ipallow.Policy{},
+			)
 
 			assert.Equal(t, nil, err)
 			authQueryArgs := AuthQueryArgs{AuthToken: testCase.authToken}
@@ -97,3 +145,80 @@ func TestQuery_AuthQuery(t *testing.T) {
 		})
 	}
 }
+
+func TestQuery_IsAliasAvailable(t *testing.T) {
+	t.Parallel()
+
+	reservedAlias := "ReservedAlias"
+	testCases := []struct {
+		name              string
+		alias             string
+		expectedStatus    string
+		expectedViolation *string
+	}{
+		{
+			name:           "alias available",
+			alias:          "my-blog",
+			expectedStatus: "AVAILABLE",
+		},
+		{
+			name:           "alias taken",
+			alias:          "google",
+			expectedStatus: "TAKEN",
+		},
+		{
+			name:              "reserved word",
+			alias:             "graphql",
+			expectedStatus:    "INVALID",
+			expectedViolation: &reservedAlias,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			shortLinkRepo := repository.NewShortLinkFake(nil, map[string]entity.ShortLink{
+				"google": {Alias: "google", LongLink: "https://www.google.com"},
+			})
+			tm := timer.NewStub(time.Now())
+			aliasChecker := shortlink.NewAliasCheckerPersist(
+				&shortLinkRepo,
+				validator.NewCustomAlias(),
+				ratelimit.NewMemory(tm, 10, time.Minute),
+				&repository.AliasReservationFake{},
+				tm,
+			)
+			query := Query{aliasChecker: aliasChecker}
+
+			ctx := WithClientIP(context.Background(), "127.0.0.1")
+			availability, err := query.IsAliasAvailable(ctx, &IsAliasAvailableArgs{Alias: testCase.alias})
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedStatus, availability.Status())
+			assert.Equal(t, testCase.expectedViolation, availability.Violation())
+		})
+	}
+}
+
+func TestQuery_IsAliasAvailableRateLimited(t *testing.T) {
+	t.Parallel()
+
+	shortLinkRepo := repository.NewShortLinkFake(nil, map[string]entity.ShortLink{})
+	tm := timer.NewStub(time.Now())
+	aliasChecker := shortlink.NewAliasCheckerPersist(
+		&shortLinkRepo,
+		validator.NewCustomAlias(),
+		ratelimit.NewMemory(tm, 1, time.Minute),
+		&repository.AliasReservationFake{},
+		tm,
+	)
+	query := Query{aliasChecker: aliasChecker}
+	ctx := WithClientIP(context.Background(), "127.0.0.1")
+
+	_, err := query.IsAliasAvailable(ctx, &IsAliasAvailableArgs{Alias: "alpha"})
+	assert.Equal(t, nil, err)
+
+	_, err = query.IsAliasAvailable(ctx, &IsAliasAvailableArgs{Alias: "beta"})
+	assert.Equal(t, ErrTooManyAliasChecks{}, err)
+}
@@ -0,0 +1,22 @@
+package resolver
+
+import "github.com/short-d/short/backend/app/usecase/visit"
+
+// ReferrerStat retrieves the number of clicks coming from a referring host.
+type ReferrerStat struct {
+	referrerStat visit.ReferrerStat
+}
+
+// Referrer retrieves the referring host.
+func (r ReferrerStat) Referrer() string {
+	return r.referrerStat.Referrer
+}
+
+// Clicks retrieves the number of clicks coming from the referring host.
+func (r ReferrerStat) Clicks() int32 {
+	return int32(r.referrerStat.Clicks)
+}
+
+func newReferrerStat(referrerStat visit.ReferrerStat) ReferrerStat {
+	return ReferrerStat{referrerStat: referrerStat}
+}
@@ -2,10 +2,19 @@ package resolver
 
 import (
 	"github.com/short-d/app/fw/logger"
+	"github.com/short-d/short/backend/app/fw/ipallow"
 	"github.com/short-d/short/backend/app/usecase/authenticator"
 	"github.com/short-d/short/backend/app/usecase/changelog"
+	"github.com/short-d/short/backend/app/usecase/featureflag"
+	"github.com/short-d/short/backend/app/usecase/linkhealth"
+	"github.com/short-d/short/backend/app/usecase/maintenance"
+	"github.com/short-d/short/backend/app/usecase/preference"
 	"github.com/short-d/short/backend/app/usecase/requester"
+	"github.com/short-d/short/backend/app/usecase/risk"
+	"github.com/short-d/short/backend/app/usecase/share"
 	"github.com/short-d/short/backend/app/usecase/shortlink"
+	"github.com/short-d/short/backend/app/usecase/stats"
+	"github.com/short-d/short/backend/app/usecase/visit"
 )
 
 // Resolver contains GraphQL request handlers.
@@ -14,25 +23,62 @@ type Resolver struct {
 	Mutation
 }
 
-// NewResolver creates a new GraphQL resolver.
+// NewResolver creates a new GraphQL resolver. Admin operations are only
+// allowed for the clients within adminPolicy.
 func NewResolver(
 	logger logger.Logger,
 	shortLinkRetriever shortlink.Retriever,
 	shortLinkCreator shortlink.Creator,
 	shortLinkUpdater shortlink.Updater,
+	shortLinkDeleter shortlink.Deleter,
 	changeLog changelog.ChangeLog,
 	requesterVerifier requester.Verifier,
 	authenticator authenticator.Authenticator,
+	shortLinkShare share.Share,
+	visitStats visit.Stats,
+	shortLinkStatusChecker shortlink.StatusChecker,
+	linkHealthReporter linkhealth.Reporter,
+	urlValidator shortlink.URLValidator,
+	domainDenylist risk.DomainDenylist,
+	preferences preference.Preference,
+	aliasChecker shortlink.AliasChecker,
+	shortLinkExpirer shortlink.Expirer,
+	maintenanceSwitch maintenance.Switch,
+	featureFlagSwitch featureflag.Switch,
+	serviceStats stats.Service,
+	adminPolicy ipallow.Policy,
 ) Resolver {
 	return Resolver{
-		Query: newQuery(logger, authenticator, changeLog, shortLinkRetriever),
+		Query: newQuery(
+			logger,
+			authenticator,
+			changeLog,
+			shortLinkRetriever,
+			shortLinkShare,
+			visitStats,
+			shortLinkStatusChecker,
+			linkHealthReporter,
+			urlValidator,
+			preferences,
+			aliasChecker,
+			serviceStats,
+			adminPolicy,
+		),
 		Mutation: newMutation(
 			logger,
 			changeLog,
 			shortLinkCreator,
 			shortLinkUpdater,
+			shortLinkDeleter,
+			shortLinkExpirer,
 			requesterVerifier,
 			authenticator,
+			shortLinkShare,
+			domainDenylist,
+			preferences,
+			maintenanceSwitch,
+			featureFlagSwitch,
+			adminPolicy,
 		),
 	}
 }
@@ -0,0 +1,39 @@
+package resolver
+
+import "github.com/short-d/short/backend/app/usecase/stats"
+
+// ServiceStats retrieves the statistics of the whole service.
+type ServiceStats struct {
+	serviceStats stats.ServiceStats
+}
+
+// ShortLinks retrieves the total number of short links.
+func (s ServiceStats) ShortLinks() int32 {
+	return int32(s.serviceStats.ShortLinks)
+}
+
+// Users retrieves the total number of users.
+func (s ServiceStats) Users() int32 {
+	return int32(s.serviceStats.Users)
+}
+
+// ShortLinksLastDay retrieves the number of short links created in the last
+// 24 hours.
+func (s ServiceStats) ShortLinksLastDay() int32 {
+	return int32(s.serviceStats.ShortLinksLastDay)
+}
+
+// ClicksLastDay retrieves the number of visits to short links in the last 24
+// hours.
+func (s ServiceStats) ClicksLastDay() int32 {
+	return int32(s.serviceStats.ClicksLastDay)
+}
+
+// FlaggedShortLinks retrieves the number of short links flagged as unsafe.
+func (s ServiceStats) FlaggedShortLinks() int32 {
+	return int32(s.serviceStats.FlaggedShortLinks)
+}
+
+func newServiceStats(serviceStats stats.ServiceStats) ServiceStats {
+	return ServiceStats{serviceStats: serviceStats}
+}
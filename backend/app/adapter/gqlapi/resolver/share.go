@@ -0,0 +1,71 @@
+package resolver
+
+import (
+	"context"
+
+	"github.com/short-d/short/backend/app/entity/metatag"
+	"github.com/short-d/short/backend/app/fw/tenant"
+	"github.com/short-d/short/backend/app/usecase/share"
+)
+
+// ShareBundle retrieves the information needed to share a short link. Each
+// field is only computed when it is requested.
+type ShareBundle struct {
+	tenantID       string
+	alias          string
+	shortLinkShare share.Share
+}
+
+// ShortLinkURL retrieves the full URL of the short link.
+func (s ShareBundle) ShortLinkURL() string {
+	return s.shortLinkShare.ShortLinkURL(s.alias)
+}
+
+// QRCodeArgs represents possible parameters for QRCode endpoint
+type QRCodeArgs struct {
+	Size int32
+}
+
+// QRCode retrieves the QR code of the short link URL in data URL format.
+func (s ShareBundle) QRCode(args *QRCodeArgs) (string, error) {
+	return s.shortLinkShare.QRCodeDataURL(s.alias, int(args.Size))
+}
+
+// OpenGraphTags retrieves the open graph tags of the short link within the
+// tenant owning the short link.
+func (s ShareBundle) OpenGraphTags(ctx context.Context) (OpenGraphTags, error) {
+	ctx = tenant.NewContext(ctx, s.tenantID)
+	openGraphTags, err := s.shortLinkShare.OpenGraphTags(ctx, s.alias)
+	if err != nil {
+		return OpenGraphTags{}, err
+	}
+	return newOpenGraphTags(openGraphTags), nil
+}
+
+func newShareBundle(tenantID string, alias string, shortLinkShare share.Share) ShareBundle {
+	return ShareBundle{tenantID: tenantID, alias: alias, shortLinkShare: shortLinkShare}
+}
+
+// OpenGraphTags retrieves requested fields of open graph meta tags.
+type OpenGraphTags struct {
+	openGraphTags metatag.OpenGraph
+}
+
+// Title retrieves the title of the open graph meta tags.
+func (o OpenGraphTags) Title() *string {
+	return o.openGraphTags.Title
+}
+
+// Description retrieves the description of the open graph meta tags.
+func (o OpenGraphTags) Description() *string {
+	return o.openGraphTags.Description
+}
+
+// ImageURL retrieves the image URL of the open graph meta tags.
+func (o OpenGraphTags) ImageURL() *string {
+	return o.openGraphTags.ImageURL
+}
+
+func newOpenGraphTags(openGraphTags metatag.OpenGraph) OpenGraphTags {
+	return OpenGraphTags{openGraphTags: openGraphTags}
+}
@@ -0,0 +1,163 @@
+// +build !integration all
+
+package resolver
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/entity/metatag"
+	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/share"
+	"github.com/short-d/short/backend/app/usecase/shortlink"
+)
+
+func newShareFake(t *testing.T, shortLinks map[string]entity.ShortLink) share.Share {
+	baseURL, err := url.Parse("https://short-d.com/r")
+	assert.Equal(t, nil, err)
+
+	shortLinkRepo := repository.NewShortLinkFake(nil, shortLinks)
+	metaTag := shortlink.NewMetaTagPersist(&shortLinkRepo)
+	return share.NewShare(*baseURL, share.NewQRCodeGeneratorFake(), metaTag, share.Signer{})
+}
+
+func TestShareBundle_ShortLinkURL(t *testing.T) {
+	t.Parallel()
+	shortLinkShare := newShareFake(t, map[string]entity.ShortLink{})
+	shortLinkResolver := newShortLink(entity.ShortLink{Alias: "220uFicCJj"}, shortLinkShare)
+
+	got := shortLinkResolver.Share().ShortLinkURL()
+	assert.Equal(t, "https://short-d.com/r/220uFicCJj", got)
+}
+
+func TestShareBundle_QRCode(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name            string
+		args            QRCodeArgs
+		expectedDataURL string
+	}{
+		{
+			name: "default size",
+			args: QRCodeArgs{Size: 256},
+			// base64 of "https://short-d.com/r/220uFicCJj,256"
+			expectedDataURL: "data:image/png;base64,aHR0cHM6Ly9zaG9ydC1kLmNvbS9yLzIyMHVGaWNDSmosMjU2",
+		},
+		{
+			name: "custom size",
+			args: QRCodeArgs{Size: 128},
+			// base64 of "https://short-d.com/r/220uFicCJj,128"
+			expectedDataURL: "data:image/png;base64,aHR0cHM6Ly9zaG9ydC1kLmNvbS9yLzIyMHVGaWNDSmosMTI4",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			shortLinkShare := newShareFake(t, map[string]entity.ShortLink{})
+			shortLinkResolver := newShortLink(entity.ShortLink{Alias: "220uFicCJj"}, shortLinkShare)
+
+			got, err := shortLinkResolver.Share().QRCode(&testCase.args)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedDataURL, got)
+		})
+	}
+}
+
+func TestShareBundle_OpenGraphTags(t *testing.T) {
+	t.Parallel()
+	title := "Google"
+	description := "Search the world's information"
+	imageURL := "https://www.google.com/logo.png"
+
+	testCases := []struct {
+		name                string
+		shortLinks          map[string]entity.ShortLink
+		tenantID            string
+		expHasErr           bool
+		expectedTitle       *string
+		expectedDescription *string
+		expectedImageURL    *string
+	}{
+		{
+			name: "short link found",
+			shortLinks: map[string]entity.ShortLink{
+				"220uFicCJj": {
+					Alias: "220uFicCJj",
+					OpenGraphTags: metatag.OpenGraph{
+						Title:       &title,
+						Description: &description,
+						ImageURL:    &imageURL,
+					},
+				},
+			},
+			expHasErr:           false,
+			expectedTitle:       &title,
+			expectedDescription: &description,
+			expectedImageURL:    &imageURL,
+		},
+		{
+			name:       "short link not found",
+			shortLinks: map[string]entity.ShortLink{},
+			expHasErr:  true,
+		},
+		{
+			name: "short link of tenant",
+			shortLinks: map[string]entity.ShortLink{
+				"220uFicCJj": {
+					TenantID: "acme",
+					Alias:    "220uFicCJj",
+					OpenGraphTags: metatag.OpenGraph{
+						Title:       &title,
+						Description: &description,
+						ImageURL:    &imageURL,
+					},
+				},
+			},
+			tenantID:            "acme",
+			expectedTitle:       &title,
+			expectedDescription: &description,
+			expectedImageURL:    &imageURL,
+		},
+		{
+			name: "short link of another tenant",
+			shortLinks: map[string]entity.ShortLink{
+				"220uFicCJj": {
+					TenantID: "acme",
+					Alias:    "220uFicCJj",
+					OpenGraphTags: metatag.OpenGraph{
+						Title: &title,
+					},
+				},
+			},
+			tenantID:  "globex",
+			expHasErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			shortLinkShare := newShareFake(t, testCase.shortLinks)
+			shortLinkResolver := newShortLink(entity.ShortLink{
+				TenantID: testCase.tenantID,
+				Alias:    "220uFicCJj",
+			}, shortLinkShare)
+
+			openGraphTags, err := shortLinkResolver.Share().OpenGraphTags(context.Background())
+			if testCase.expHasErr {
+				assert.NotEqual(t, nil, err)
+				return
+			}
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedTitle, openGraphTags.Title())
+			assert.Equal(t, testCase.expectedDescription, openGraphTags.Description())
+			assert.Equal(t, testCase.expectedImageURL, openGraphTags.ImageURL())
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package resolver
+
+import (
+	"github.com/short-d/short/backend/app/usecase/share"
+	"github.com/short-d/short/backend/app/usecase/shortlink"
+)
+
+// ShortLinkPage represents a page of short links ordered from the most
+// recently created one.
+type ShortLinkPage struct {
+	page           shortlink.ShortLinkPage
+	shortLinkShare share.Share
+}
+
+// ShortLinks retrieves the short links in the page.
+func (s ShortLinkPage) ShortLinks() []ShortLink {
+	gqlShortLinks := []ShortLink{}
+	for _, shortLink := range s.page.ShortLinks {
+		gqlShortLinks = append(gqlShortLinks, newShortLink(shortLink, s.shortLinkShare))
+	}
+	return gqlShortLinks
+}
+
+// EndCursor retrieves the cursor of the last short link in the page, which
+// fetches the next page when passed back to the server.
+func (s ShortLinkPage) EndCursor() *string {
+	if s.page.EndCursor == "" {
+		return nil
+	}
+	return &s.page.EndCursor
+}
+
+// HasNextPage retrieves whether there are more short links after the page.
+func (s ShortLinkPage) HasNextPage() bool {
+	return s.page.HasNextPage
+}
+
+func newShortLinkPage(page shortlink.ShortLinkPage, shortLinkShare share.Share) ShortLinkPage {
+	return ShortLinkPage{
+		page:           page,
+		shortLinkShare: shortLinkShare,
+	}
+}
@@ -0,0 +1,36 @@
+package resolver
+
+import (
+	"github.com/short-d/short/backend/app/usecase/share"
+	"github.com/short-d/short/backend/app/usecase/shortlink"
+)
+
+// ShortLinkPreview retrieves the short link CreateShortLink would create,
+// which is not saved.
+type ShortLinkPreview struct {
+	preview        shortlink.ShortLinkPreview
+	shortLinkShare share.Share
+}
+
+// ShortLink retrieves the unsaved short link.
+func (s ShortLinkPreview) ShortLink() ShortLink {
+	return newShortLink(s.preview.ShortLink, s.shortLinkShare)
+}
+
+// IsAutoAlias retrieves whether the alias is generated.
+func (s ShortLinkPreview) IsAutoAlias() bool {
+	return s.preview.IsAutoAlias
+}
+
+// IsAliasAvailable retrieves whether the alias can be used by a new short
+// link right now.
+func (s ShortLinkPreview) IsAliasAvailable() bool {
+	return s.preview.IsAliasAvailable
+}
+
+func newShortLinkPreview(preview shortlink.ShortLinkPreview, shortLinkShare share.Share) ShortLinkPreview {
+	return ShortLinkPreview{
+		preview:        preview,
+		shortLinkShare: shortLinkShare,
+	}
+}
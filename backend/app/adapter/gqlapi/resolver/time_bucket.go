@@ -0,0 +1,25 @@
+package resolver
+
+import (
+	"github.com/short-d/short/backend/app/adapter/gqlapi/scalar"
+	"github.com/short-d/short/backend/app/usecase/visit"
+)
+
+// TimeBucket retrieves the number of clicks within a period of time.
+type TimeBucket struct {
+	timeBucket visit.TimeBucket
+}
+
+// Start retrieves the beginning of the time bucket.
+func (t TimeBucket) Start() scalar.Time {
+	return scalar.Time{Time: t.timeBucket.Start}
+}
+
+// Clicks retrieves the number of clicks within the time bucket.
+func (t TimeBucket) Clicks() int32 {
+	return int32(t.timeBucket.Clicks)
+}
+
+func newTimeBucket(timeBucket visit.TimeBucket) TimeBucket {
+	return TimeBucket{timeBucket: timeBucket}
+}
@@ -3,11 +3,13 @@ package resolver
 import (
 	"github.com/short-d/short/backend/app/adapter/gqlapi/scalar"
 	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/share"
 )
 
 // ShortLink retrieves requested fields of ShortLink entity.
 type ShortLink struct {
-	shortLink entity.ShortLink
+	shortLink      entity.ShortLink
+	shortLinkShare share.Share
 }
 
 // Alias retrieves the alias of ShortLink entity.
@@ -20,6 +22,13 @@ func (s ShortLink) LongLink() *string {
 	return &s.shortLink.LongLink
 }
 
+// OriginalLongLink retrieves the long link of ShortLink entity as given by the
+// user.
+func (s ShortLink) OriginalLongLink() *string {
+	originalLongLink := s.shortLink.GetOriginalLongLink()
+	return &originalLongLink
+}
+
 // ExpireAt retrieves the expiration time of ShortLink entity.
 func (s ShortLink) ExpireAt() *scalar.Time {
 	if s.shortLink.ExpireAt == nil {
@@ -29,6 +38,43 @@ func (s ShortLink) ExpireAt() *scalar.Time {
 	return &scalar.Time{Time: *s.shortLink.ExpireAt}
 }
 
-func newShortLink(shortLink entity.ShortLink) ShortLink {
-	return ShortLink{shortLink: shortLink}
+// TrackVisits retrieves whether the visits of ShortLink entity are tracked.
+func (s ShortLink) TrackVisits() bool {
+	return s.shortLink.TrackVisits
+}
+
+// PassthroughQuery retrieves whether the query parameters of the requests to
+// ShortLink entity are forwarded to the long link.
+func (s ShortLink) PassthroughQuery() bool {
+	return s.shortLink.PassthroughQuery
+}
+
+// PassthroughPath retrieves whether the path following the alias in the
+// requests to the short link is appended to the long link.
+func (s ShortLink) PassthroughPath() bool {
+	return s.shortLink.PassthroughPath
+}
+
+// MaxRedirectsPerIP retrieves how many times each client IP can be redirected
+// through ShortLink entity within a window.
+func (s ShortLink) MaxRedirectsPerIP() int32 {
+	return int32(s.shortLink.MaxRedirectsPerIP)
+}
+
+// Description retrieves the note about ShortLink entity for the owner's own
+// reference.
+func (s ShortLink) Description() string {
+	return s.shortLink.Description
+}
+
+// Share retrieves the information needed to share the short link.
+func (s ShortLink) Share() ShareBundle {
+	return newShareBundle(s.shortLink.TenantID, s.shortLink.Alias, s.shortLinkShare)
+}
+
+func newShortLink(shortLink entity.ShortLink, shortLinkShare share.Share) ShortLink {
+	return ShortLink{
+		shortLink:      shortLink,
+		shortLinkShare: shortLinkShare,
+	}
 }
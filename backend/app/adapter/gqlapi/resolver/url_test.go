@@ -29,6 +29,37 @@ func TestShortLink_LongLink(t *testing.T) {
 	assert.Equal(t, got, expected, "*shortLinkResolver.LongLink() = %v; want %v", expected, got)
 }
 
+func TestShortLink_OriginalLongLink(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name      string
+		shortLink entity.ShortLink
+		expected  string
+	}{
+		{
+			name: "original long link",
+			shortLink: entity.ShortLink{
+				LongLink:         "https://example.com/docs",
+				OriginalLongLink: "http://example.com/docs#intro",
+			},
+			expected: "http://example.com/docs#intro",
+		},
+		{
+			name:      "saved before original long links",
+			shortLink: entity.ShortLink{LongLink: "https://example.com/docs"},
+			expected:  "https://example.com/docs",
+		},
+	}
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			shortLinkResolver := ShortLink{shortLink: testCase.shortLink}
+			assert.Equal(t, testCase.expected, *shortLinkResolver.OriginalLongLink())
+		})
+	}
+}
+
 func TestShortLink_ExpireAt(t *testing.T) {
 	t.Parallel()
 	timeAfter := time.Now().Add(5 * time.Second)
@@ -0,0 +1,37 @@
+package resolver
+
+import "github.com/short-d/short/backend/app/usecase/shortlink"
+
+var validationStatuses = map[shortlink.ValidationStatus]string{
+	shortlink.ValidationStatusValid:     "VALID",
+	shortlink.ValidationStatusInvalid:   "INVALID",
+	shortlink.ValidationStatusMalicious: "MALICIOUS",
+}
+
+// URLValidationResult retrieves whether a URL can be used as a long link.
+type URLValidationResult struct {
+	result shortlink.ValidationResult
+}
+
+// URL retrieves the validated URL.
+func (u URLValidationResult) URL() string {
+	return u.result.URL
+}
+
+// Status retrieves whether the URL can be used as a long link.
+func (u URLValidationResult) Status() string {
+	return validationStatuses[u.result.Status]
+}
+
+// Violation retrieves the reason why the URL is invalid.
+func (u URLValidationResult) Violation() *string {
+	if u.result.Status != shortlink.ValidationStatusInvalid {
+		return nil
+	}
+	violation := string(u.result.Violation)
+	return &violation
+}
+
+func newURLValidationResult(result shortlink.ValidationResult) URLValidationResult {
+	return URLValidationResult{result: result}
+}
@@ -0,0 +1,33 @@
+package resolver
+
+import (
+	"time"
+
+	"github.com/short-d/short/backend/app/entity"
+)
+
+// UserPreferences retrieves the default settings applied to the short links
+// created by a user.
+type UserPreferences struct {
+	preferences entity.UserPreferences
+}
+
+// DefaultExpireAfterSeconds retrieves how long after creation the short links
+// expire by default.
+func (u UserPreferences) DefaultExpireAfterSeconds() *int32 {
+	if u.preferences.DefaultExpireAfter == nil {
+		return nil
+	}
+	secs := int32(*u.preferences.DefaultExpireAfter / time.Second)
+	return &secs
+}
+
+// DefaultTrackVisits retrieves whether visits to the short links are tracked
+// by default.
+func (u UserPreferences) DefaultTrackVisits() *bool {
+	return u.preferences.DefaultTrackVisits
+}
+
+func newUserPreferences(preferences entity.UserPreferences) UserPreferences {
+	return UserPreferences{preferences: preferences}
+}
@@ -16,7 +16,7 @@ var _ proto.MetaTagServiceServer = (*MetaTagServer)(nil)
 
 // GetOpenGraphTags fetches Open Graph tags for a given short link.
 func (m MetaTagServer) GetOpenGraphTags(ctx context.Context, req *proto.GetOpenGraphTagsRequest) (*proto.GetOpenGraphTagsResponse, error) {
-	openGraphMetaTags, err := m.metaTag.GetOpenGraphTags(req.GetAlias())
+	openGraphMetaTags, err := m.metaTag.GetOpenGraphTags(ctx, req.GetAlias())
 	if err != nil {
 		return &proto.GetOpenGraphTagsResponse{}, err
 	}
@@ -41,7 +41,7 @@ func (m MetaTagServer) GetOpenGraphTags(ctx context.Context, req *proto.GetOpenG
 
 // GetTwitterTags fetches Twitter tags for a given short link.
 func (m MetaTagServer) GetTwitterTags(ctx context.Context, req *proto.GetTwitterTagsRequest) (*proto.GetTwitterTagsResponse, error) {
-	twitterMetaTags, err := m.metaTag.GetTwitterTags(req.GetAlias())
+	twitterMetaTags, err := m.metaTag.GetTwitterTags(ctx, req.GetAlias())
 	if err != nil {
 		return &proto.GetTwitterTagsResponse{}, err
 	}
@@ -2,10 +2,14 @@ package kgs
 
 import (
 	"context"
+	"crypto/tls"
+	"fmt"
+	"time"
 
-	"github.com/short-d/app/fw/rpc"
 	"github.com/short-d/kgs/app/adapter/rpc/proto"
 	"github.com/short-d/short/backend/app/usecase/keygen"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 )
 
 var _ keygen.KeyFetcher = (*RPC)(nil)
@@ -35,15 +39,86 @@ func (k RPC) FetchKeys(maxCount int) ([]keygen.Key, error) {
 	return keys, nil
 }
 
-// NewRPC initializes GRPC client for key generation service APIs.
-func NewRPC(hostname string, port int) (RPC, error) {
-	connection, err := rpc.
-		NewClientConnBuilder(hostname, port).
-		InsecureTLS().
-		Build()
+// ConnectRetry configures how connecting to key generation service is retried
+// when the service is not reachable yet, such as when both services start at
+// the same time.
+type ConnectRetry struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	DialTimeout    time.Duration
+}
+
+type dialer func(target string, timeout time.Duration) (proto.KeyGenClient, error)
+
+// connector makes at most MaxAttempts attempts to connect to key generation
+// service, doubling the wait between consecutive attempts up to MaxBackoff.
+type connector struct {
+	dial  dialer
+	retry ConnectRetry
+	sleep func(duration time.Duration)
+}
+
+func (c connector) connect(target string) (RPC, error) {
+	maxAttempts := c.retry.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	backoff := c.retry.InitialBackoff
+
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		var gRPCClient proto.KeyGenClient
+		gRPCClient, err = c.dial(target, c.retry.DialTimeout)
+		if err == nil {
+			return RPC{gRPCClient: gRPCClient}, nil
+		}
+		if attempt < maxAttempts {
+			c.sleep(backoff)
+			backoff *= 2
+			if c.retry.MaxBackoff > 0 && backoff > c.retry.MaxBackoff {
+				backoff = c.retry.MaxBackoff
+			}
+		}
+	}
+	return RPC{}, fmt.Errorf(
+		"fail to connect to key generation service at %s after %d attempts: %w",
+		target, maxAttempts, err,
+	)
+}
+
+// dialGRPC waits until the connection is ready or the timeout is reached.
+// Zero timeout waits until the connection is ready.
+func dialGRPC(target string, timeout time.Duration) (proto.KeyGenClient, error) {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	gRPCTLS := credentials.NewTLS(&tls.Config{
+		InsecureSkipVerify: true,
+	})
+	connection, err := grpc.DialContext(
+		ctx,
+		target,
+		grpc.WithTransportCredentials(gRPCTLS),
+		grpc.WithBlock(),
+	)
 	if err != nil {
-		return RPC{}, err
+		return nil, err
+	}
+	return proto.NewKeyGenClient(connection), nil
+}
+
+// NewRPC initializes GRPC client for key generation service APIs, retrying
+// with exponential backoff until the service is reachable.
+func NewRPC(hostname string, port int, retry ConnectRetry) (RPC, error) {
+	c := connector{
+		dial:  dialGRPC,
+		retry: retry,
+		sleep: time.Sleep,
 	}
-	gRPCClient := proto.NewKeyGenClient(connection)
-	return RPC{gRPCClient: gRPCClient}, nil
+	return c.connect(fmt.Sprintf("%s:%d", hostname, port))
 }
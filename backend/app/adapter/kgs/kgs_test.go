@@ -0,0 +1,142 @@
+// +build !integration all
+
+package kgs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/kgs/app/adapter/rpc/proto"
+	"github.com/short-d/short/backend/app/usecase/keygen"
+	"google.golang.org/grpc"
+)
+
+type keyGenClientFake struct {
+	proto.KeyGenClient
+	keys []string
+}
+
+func (k keyGenClientFake) AllocateKeys(
+	ctx context.Context,
+	in *proto.AllocateKeysRequest,
+	opts ...grpc.CallOption,
+) (*proto.AllocateKeysResponse, error) {
+	return &proto.AllocateKeysResponse{Keys: k.keys[:in.MaxKeyCount]}, nil
+}
+
+func TestConnector_Connect(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name           string
+		dialErrs       []error
+		retry          ConnectRetry
+		hasErr         bool
+		expectedDials  int
+		expectedSleeps []time.Duration
+	}{
+		{
+			name:     "connected on first attempt",
+			dialErrs: []error{},
+			retry: ConnectRetry{
+				MaxAttempts:    5,
+				InitialBackoff: time.Second,
+			},
+			expectedDials:  1,
+			expectedSleeps: []time.Duration{},
+		},
+		{
+			name:     "connected after dialer fails twice",
+			dialErrs: []error{errors.New("connection refused"), errors.New("connection refused")},
+			retry: ConnectRetry{
+				MaxAttempts:    5,
+				InitialBackoff: time.Second,
+			},
+			expectedDials:  3,
+			expectedSleeps: []time.Duration{time.Second, 2 * time.Second},
+		},
+		{
+			name: "backoff capped",
+			dialErrs: []error{
+				errors.New("connection refused"),
+				errors.New("connection refused"),
+				errors.New("connection refused"),
+			},
+			retry: ConnectRetry{
+				MaxAttempts:    5,
+				InitialBackoff: time.Second,
+				MaxBackoff:     3 * time.Second,
+			},
+			expectedDials:  4,
+			expectedSleeps: []time.Duration{time.Second, 2 * time.Second, 3 * time.Second},
+		},
+		{
+			name: "fail after all attempts",
+			dialErrs: []error{
+				errors.New("connection refused"),
+				errors.New("connection refused"),
+				errors.New("connection refused"),
+			},
+			retry: ConnectRetry{
+				MaxAttempts:    3,
+				InitialBackoff: time.Second,
+			},
+			hasErr:         true,
+			expectedDials:  3,
+			expectedSleeps: []time.Duration{time.Second, 2 * time.Second},
+		},
+		{
+			name:     "dial once without attempts configured",
+			dialErrs: []error{errors.New("connection refused")},
+			retry: ConnectRetry{
+				MaxAttempts:    0,
+				InitialBackoff: time.Second,
+			},
+			hasErr:         true,
+			expectedDials:  1,
+			expectedSleeps: []time.Duration{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			dials := 0
+			dial := func(target string, timeout time.Duration) (proto.KeyGenClient, error) {
+				assert.Equal(t, "kgs.example.com:8080", target)
+				dials++
+				if dials <= len(testCase.dialErrs) {
+					return nil, testCase.dialErrs[dials-1]
+				}
+				return keyGenClientFake{keys: []string{"abc", "def"}}, nil
+			}
+			sleeps := []time.Duration{}
+			c := connector{
+				dial:  dial,
+				retry: testCase.retry,
+				sleep: func(duration time.Duration) {
+					sleeps = append(sleeps, duration)
+				},
+			}
+
+			rpc, err := c.connect("kgs.example.com:8080")
+			assert.Equal(t, testCase.expectedDials, dials)
+			assert.Equal(t, testCase.expectedSleeps, sleeps)
+			if testCase.hasErr {
+				assert.NotEqual(t, nil, err)
+				assert.Equal(t, true, errors.Is(err, testCase.dialErrs[len(testCase.dialErrs)-1]))
+				return
+			}
+			assert.Equal(t, nil, err)
+
+			keys, err := rpc.FetchKeys(2)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, []keygen.Key{"abc", "def"}, keys)
+		})
+	}
+}
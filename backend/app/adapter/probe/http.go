@@ -0,0 +1,221 @@
+package probe
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+
+	"github.com/short-d/short/backend/app/usecase/featureflag"
+	"github.com/short-d/short/backend/app/usecase/linkhealth"
+)
+
+var _ linkhealth.Prober = (*HTTP)(nil)
+
+const maxRedirects = 5
+
+// userAgent identifies the prober to the destinations, so that they can
+// exclude it in robots.txt.
+const userAgent = "ShortLinkHealthCheck/1.0"
+
+// ErrForbiddenIP represents the long link resolves to an address which must
+// not be reached from the server, such as loopback or private network
+// addresses.
+type ErrForbiddenIP string
+
+func (e ErrForbiddenIP) Error() string {
+	return fmt.Sprintf("forbidden IP address: %s", string(e))
+}
+
+// ErrUnhealthyStatus represents the long link responds with a status code
+// indicating it is gone or failing.
+type ErrUnhealthyStatus int
+
+func (e ErrUnhealthyStatus) Error() string {
+	return fmt.Sprintf("unhealthy status code: %d", int(e))
+}
+
+// HTTP checks whether long links are reachable through HTTP requests.
+type HTTP struct {
+	client http.Client
+}
+
+// Probe issues a HEAD request to the long link, falling back to GET when HEAD
+// is not supported. The response body is never read. The long link is skipped
+// when robots.txt of the destination disallows the prober.
+func (h HTTP) Probe(longLink string) error {
+	allowed, err := h.isAllowedByRobots(longLink)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return linkhealth.ErrProbeSkipped("disallowed by robots.txt")
+	}
+
+	statusCode, err := h.request(http.MethodHead, longLink)
+	if err != nil {
+		return err
+	}
+	if statusCode == http.StatusMethodNotAllowed || statusCode == http.StatusNotImplemented {
+		statusCode, err = h.request(http.MethodGet, longLink)
+		if err != nil {
+			return err
+		}
+	}
+
+	if isUnhealthyStatus(statusCode) {
+		return ErrUnhealthyStatus(statusCode)
+	}
+	return nil
+}
+
+func (h HTTP) request(method string, longLink string) (int, error) {
+	req, err := http.NewRequest(method, longLink, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	res, err := h.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	return res.StatusCode, nil
+}
+
+// isAllowedByRobots fetches robots.txt of the destination. The long link is
+// allowed when robots.txt is missing or can't be fetched, in which case the
+// probe itself reports the failure.
+func (h HTTP) isAllowedByRobots(longLink string) (bool, error) {
+	u, err := url.Parse(longLink)
+	if err != nil {
+		return false, err
+	}
+	robotsURL := url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/robots.txt"}
+
+	req, err := http.NewRequest(http.MethodGet, robotsURL.String(), nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	res, err := h.client.Do(req)
+	if err != nil {
+		return true, nil
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return true, nil
+	}
+
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	return parseRobots(res.Body, userAgent).isAllowed(path), nil
+}
+
+// isUnhealthyStatus treats client errors such as 401, 403 and 429 as healthy
+// because the destination still exists.
+func isUnhealthyStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusNotFound, http.StatusGone:
+		return true
+	}
+	return statusCode >= http.StatusInternalServerError
+}
+
+// isPublicIP checks whether the IP address is routable on the public
+// internet.
+func isPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() ||
+		ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() {
+		return false
+	}
+	for _, network := range privateNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+var privateNetworks = mustParseCIDRs(
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"172.16.0.0/12",
+	"192.0.0.0/24",
+	"192.168.0.0/16",
+	"198.18.0.0/15",
+	"fc00::/7",
+)
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	var networks []*net.IPNet
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+// newClient creates a HTTP client which refuses to connect to the IP
+// addresses rejected by isAllowedIP. The check happens after DNS resolution
+// for every connection, including redirects, to prevent server side request
+// forgery.
+func newClient(timeout time.Duration, isAllowedIP func(ip net.IP) bool) http.Client {
+	dialer := net.Dialer{
+		Timeout: timeout,
+		Control: func(network string, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || !isAllowedIP(ip) {
+				return ErrForbiddenIP(host)
+			}
+			return nil
+		},
+	}
+	transport := &http.Transport{
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   timeout,
+		ResponseHeaderTimeout: timeout,
+		DisableKeepAlives:     true,
+	}
+	return http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return errors.New("too many redirects")
+			}
+			return nil
+		},
+	}
+}
+
+// NewHTTP creates HTTP prober which gives up on a long link after timeout.
+// Only public IP addresses are reached unless ProbeInternalHosts is turned on,
+// which is checked again for every connection.
+func NewHTTP(timeout time.Duration, toggle featureflag.Toggle) HTTP {
+	isAllowedIP := func(ip net.IP) bool {
+		return isPublicIP(ip) || toggle.IsEnabled(featureflag.ProbeInternalHosts, nil)
+	}
+	return HTTP{client: newClient(timeout, isAllowedIP)}
+}
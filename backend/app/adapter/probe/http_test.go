@@ -0,0 +1,254 @@
+// +build !integration all
+
+package probe
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/authorizer"
+	"github.com/short-d/short/backend/app/usecase/authorizer/rbac"
+	"github.com/short-d/short/backend/app/usecase/authorizer/rbac/role"
+	"github.com/short-d/short/backend/app/usecase/featureflag"
+	"github.com/short-d/short/backend/app/usecase/linkhealth"
+	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/webhook"
+)
+
+const testTimeout = 100 * time.Millisecond
+
+func allowAllIPs(ip net.IP) bool {
+	return true
+}
+
+func newStatusServer(statusCode *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(int(atomic.LoadInt32(statusCode)))
+	}))
+}
+
+func newSlowServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(10 * testTimeout):
+		}
+	}))
+}
+
+func TestHTTP_Probe(t *testing.T) {
+	t.Parallel()
+
+	okStatus := int32(http.StatusOK)
+	okServer := newStatusServer(&okStatus)
+	defer okServer.Close()
+
+	notFoundStatus := int32(http.StatusNotFound)
+	notFoundServer := newStatusServer(&notFoundStatus)
+	defer notFoundServer.Close()
+
+	forbiddenStatus := int32(http.StatusForbidden)
+	forbiddenServer := newStatusServer(&forbiddenStatus)
+	defer forbiddenServer.Close()
+
+	headNotAllowedServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer headNotAllowedServer.Close()
+
+	slowServer := newSlowServer()
+	defer slowServer.Close()
+
+	robotsServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			w.Write([]byte("User-agent: ShortLinkHealthCheck\nDisallow: /private\n"))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer robotsServer.Close()
+
+	testCases := []struct {
+		name        string
+		longLink    string
+		isAllowedIP func(ip net.IP) bool
+		hasErr      bool
+	}{
+		{
+			name:        "destination responds 200",
+			longLink:    okServer.URL,
+			isAllowedIP: allowAllIPs,
+			hasErr:      false,
+		},
+		{
+			name:        "destination responds 404",
+			longLink:    notFoundServer.URL,
+			isAllowedIP: allowAllIPs,
+			hasErr:      true,
+		},
+		{
+			name:        "destination requires authorization",
+			longLink:    forbiddenServer.URL,
+			isAllowedIP: allowAllIPs,
+			hasErr:      false,
+		},
+		{
+			name:        "destination does not support HEAD",
+			longLink:    headNotAllowedServer.URL,
+			isAllowedIP: allowAllIPs,
+			hasErr:      false,
+		},
+		{
+			name:        "destination times out",
+			longLink:    slowServer.URL,
+			isAllowedIP: allowAllIPs,
+			hasErr:      true,
+		},
+		{
+			name:        "destination disallows prober in robots.txt",
+			longLink:    robotsServer.URL + "/private/page",
+			isAllowedIP: allowAllIPs,
+			hasErr:      true,
+		},
+		{
+			name:        "destination allows prober in robots.txt",
+			longLink:    robotsServer.URL + "/public/page",
+			isAllowedIP: allowAllIPs,
+			hasErr:      false,
+		},
+		{
+			name:        "destination in private network",
+			longLink:    okServer.URL,
+			isAllowedIP: isPublicIP,
+			hasErr:      true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			prober := HTTP{client: newClient(testTimeout, testCase.isAllowedIP)}
+			err := prober.Probe(testCase.longLink)
+			assert.Equal(t, testCase.hasErr, err != nil)
+		})
+	}
+}
+
+func TestHTTP_ProbeInternalHosts(t *testing.T) {
+	t.Parallel()
+
+	okStatus := int32(http.StatusOK)
+	okServer := newStatusServer(&okStatus)
+	defer okServer.Close()
+
+	admin := entity.User{ID: "alpha"}
+	roleRepo := repository.NewUserRoleFake(map[string][]role.Role{
+		admin.ID: {role.Admin},
+	})
+	toggle := featureflag.NewOverrideToggle(featureflag.NewToggleFake(nil))
+	flagSwitch := featureflag.NewSwitch(toggle, authorizer.NewAuthorizer(rbac.NewRBAC(roleRepo)))
+	prober := NewHTTP(testTimeout, toggle)
+
+	err := prober.Probe(okServer.URL)
+	assert.Equal(t, true, errors.As(err, new(ErrForbiddenIP)))
+
+	err = flagSwitch.Override(admin, featureflag.ProbeInternalHosts, true)
+	assert.Equal(t, nil, err)
+	err = prober.Probe(okServer.URL)
+	assert.Equal(t, nil, err)
+
+	err = flagSwitch.ClearOverride(admin, featureflag.ProbeInternalHosts)
+	assert.Equal(t, nil, err)
+	err = prober.Probe(okServer.URL)
+	assert.Equal(t, true, errors.As(err, new(ErrForbiddenIP)))
+}
+
+func TestHTTP_ProbeWithChecker(t *testing.T) {
+	t.Parallel()
+
+	okStatus := int32(http.StatusOK)
+	okServer := newStatusServer(&okStatus)
+	defer okServer.Close()
+
+	deadStatus := int32(http.StatusNotFound)
+	deadServer := newStatusServer(&deadStatus)
+	defer deadServer.Close()
+
+	slowServer := newSlowServer()
+	defer slowServer.Close()
+
+	userShortLinkRepo := repository.NewUserShortLinkRepoFake(nil, nil)
+	shortLinkRepo := repository.NewShortLinkFake(&userShortLinkRepo, map[string]entity.ShortLink{
+		"ok":   {Alias: "ok", LongLink: okServer.URL},
+		"dead": {Alias: "dead", LongLink: deadServer.URL},
+		"slow": {Alias: "slow", LongLink: slowServer.URL},
+	})
+	linkHealthRepo := repository.NewLinkHealthFake([]string{"ok", "dead", "slow"}, nil)
+	prober := HTTP{client: newClient(testTimeout, allowAllIPs)}
+	now := time.Date(2020, 5, 1, 10, 30, 0, 0, time.UTC)
+	checker := linkhealth.NewChecker(
+		&shortLinkRepo,
+		&linkHealthRepo,
+		prober,
+		webhook.NewDispatcherFake(),
+		timer.NewStub(now),
+		10,
+		3,
+	)
+
+	expectedStatuses := []map[string]entity.LinkHealthStatus{
+		{
+			"ok":   entity.LinkHealthHealthy,
+			"dead": entity.LinkHealthUnknown,
+			"slow": entity.LinkHealthUnknown,
+		},
+		{
+			"ok":   entity.LinkHealthHealthy,
+			"dead": entity.LinkHealthUnknown,
+			"slow": entity.LinkHealthUnknown,
+		},
+		{
+			"ok":   entity.LinkHealthHealthy,
+			"dead": entity.LinkHealthBroken,
+			"slow": entity.LinkHealthBroken,
+		},
+	}
+	for _, expected := range expectedStatuses {
+		err := checker.CheckLinks()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, expected, getStatuses(t, linkHealthRepo))
+	}
+
+	atomic.StoreInt32(&deadStatus, http.StatusOK)
+	err := checker.CheckLinks()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, map[string]entity.LinkHealthStatus{
+		"ok":   entity.LinkHealthHealthy,
+		"dead": entity.LinkHealthHealthy,
+		"slow": entity.LinkHealthBroken,
+	}, getStatuses(t, linkHealthRepo))
+}
+
+func getStatuses(t *testing.T, linkHealthRepo repository.LinkHealthFake) map[string]entity.LinkHealthStatus {
+	linkHealths, err := linkHealthRepo.FindLinkHealthByAliases(context.Background(), []string{"ok", "dead", "slow"})
+	assert.Equal(t, nil, err)
+
+	statuses := make(map[string]entity.LinkHealthStatus)
+	for _, linkHealth := range linkHealths {
+		statuses[linkHealth.Alias] = linkHealth.Status
+	}
+	return statuses
+}
@@ -0,0 +1,123 @@
+package probe
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// maxRobotsSize caps the part of robots.txt being parsed, beyond which the
+// rules are ignored.
+const maxRobotsSize = 500 * 1024
+
+type robotsRule struct {
+	allow   bool
+	pattern string
+	matcher *regexp.Regexp
+}
+
+// robots represents the rules in robots.txt applying to the prober.
+type robots struct {
+	rules []robotsRule
+}
+
+// isAllowed picks the rule with the longest pattern matching the path. Allow
+// wins when an allow rule and a disallow rule are equally long.
+func (r robots) isAllowed(path string) bool {
+	allowed := true
+	longest := -1
+	for _, rule := range r.rules {
+		if !rule.matcher.MatchString(path) {
+			continue
+		}
+		if len(rule.pattern) > longest || (len(rule.pattern) == longest && rule.allow) {
+			allowed = rule.allow
+			longest = len(rule.pattern)
+		}
+	}
+	return allowed
+}
+
+type robotsGroup struct {
+	userAgents []string
+	rules      []robotsRule
+}
+
+// parseRobots keeps the rules of the groups naming userAgent, falling back to
+// the groups for every user agent when none does.
+func parseRobots(body io.Reader, userAgent string) robots {
+	var groups []*robotsGroup
+	var group *robotsGroup
+	inUserAgents := false
+
+	scanner := bufio.NewScanner(io.LimitReader(body, maxRobotsSize))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = line[:idx]
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key := strings.ToLower(strings.TrimSpace(parts[0]))
+		value := strings.TrimSpace(parts[1])
+
+		switch key {
+		case "user-agent":
+			if !inUserAgents {
+				group = &robotsGroup{}
+				groups = append(groups, group)
+				inUserAgents = true
+			}
+			group.userAgents = append(group.userAgents, strings.ToLower(value))
+		case "allow", "disallow":
+			inUserAgents = false
+			if group == nil || value == "" {
+				continue
+			}
+			group.rules = append(group.rules, robotsRule{
+				allow:   key == "allow",
+				pattern: value,
+				matcher: compileRobotsPattern(value),
+			})
+		}
+	}
+
+	token := strings.ToLower(userAgent)
+	if idx := strings.Index(token, "/"); idx >= 0 {
+		token = token[:idx]
+	}
+
+	var named, wildcard []robotsRule
+	for _, group := range groups {
+		for _, agent := range group.userAgents {
+			if agent == "*" {
+				wildcard = append(wildcard, group.rules...)
+				break
+			}
+			if agent != "" && strings.Contains(token, agent) {
+				named = append(named, group.rules...)
+				break
+			}
+		}
+	}
+	if named != nil {
+		return robots{rules: named}
+	}
+	return robots{rules: wildcard}
+}
+
+// compileRobotsPattern matches the prefix of paths, where "*" matches any
+// sequence of characters and a trailing "$" matches the end of the path.
+func compileRobotsPattern(pattern string) *regexp.Regexp {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+
+	expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*")
+	if anchored {
+		expr += "$"
+	}
+	return regexp.MustCompile(expr)
+}
@@ -0,0 +1,92 @@
+// +build !integration all
+
+package probe
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+)
+
+func TestRobots_IsAllowed(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name            string
+		robotsTxt       string
+		path            string
+		expectedAllowed bool
+	}{
+		{
+			name:            "empty robots.txt",
+			robotsTxt:       "",
+			path:            "/promo",
+			expectedAllowed: true,
+		},
+		{
+			name:            "disallowed for every user agent",
+			robotsTxt:       "User-agent: *\nDisallow: /\n",
+			path:            "/promo",
+			expectedAllowed: false,
+		},
+		{
+			name:            "empty disallow allows everything",
+			robotsTxt:       "User-agent: *\nDisallow:\n",
+			path:            "/promo",
+			expectedAllowed: true,
+		},
+		{
+			name:            "path outside disallowed prefix",
+			robotsTxt:       "User-agent: *\nDisallow: /admin\n",
+			path:            "/promo",
+			expectedAllowed: true,
+		},
+		{
+			name:            "named group overrides wildcard group",
+			robotsTxt:       "User-agent: *\nDisallow: /\n\nUser-agent: ShortLinkHealthCheck\nDisallow: /admin\n",
+			path:            "/promo",
+			expectedAllowed: true,
+		},
+		{
+			name:            "group for other user agents ignored",
+			robotsTxt:       "User-agent: Googlebot\nDisallow: /\n",
+			path:            "/promo",
+			expectedAllowed: true,
+		},
+		{
+			name:            "user agents sharing group",
+			robotsTxt:       "User-agent: Googlebot\nUser-agent: shortlinkhealthcheck\nDisallow: /promo # campaigns\n",
+			path:            "/promo/summer",
+			expectedAllowed: false,
+		},
+		{
+			name:            "longer allow rule wins",
+			robotsTxt:       "User-agent: *\nDisallow: /promo\nAllow: /promo/public\n",
+			path:            "/promo/public/summer",
+			expectedAllowed: true,
+		},
+		{
+			name:            "wildcard pattern",
+			robotsTxt:       "User-agent: *\nDisallow: /*.pdf$\n",
+			path:            "/files/report.pdf",
+			expectedAllowed: false,
+		},
+		{
+			name:            "end anchored pattern",
+			robotsTxt:       "User-agent: *\nDisallow: /*.pdf$\n",
+			path:            "/files/report.pdf?page=2",
+			expectedAllowed: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			robots := parseRobots(strings.NewReader(testCase.robotsTxt), userAgent)
+			assert.Equal(t, testCase.expectedAllowed, robots.isAllowed(testCase.path))
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package qrcode
+
+import (
+	"github.com/short-d/short/backend/app/usecase/share"
+	goqrcode "github.com/skip2/go-qrcode"
+)
+
+var _ share.QRCodeGenerator = (*Generator)(nil)
+
+// Generator encodes content into QR code images with medium error recovery
+// level.
+type Generator struct{}
+
+// GeneratePNG encodes the content into a square PNG image with the given
+// width in pixels.
+func (g Generator) GeneratePNG(content string, size int) ([]byte, error) {
+	return goqrcode.Encode(content, goqrcode.Medium, size)
+}
+
+// NewGenerator creates QR code generator.
+func NewGenerator() Generator {
+	return Generator{}
+}
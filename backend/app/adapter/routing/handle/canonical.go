@@ -0,0 +1,80 @@
+package handle
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/short-d/short/backend/app/fw/netutil"
+)
+
+// ErrInvalidDomainRedirect represents the domain redirect not formatted as
+// domain=canonical URL.
+type ErrInvalidDomainRedirect string
+
+func (e ErrInvalidDomainRedirect) Error() string {
+	return fmt.Sprintf("invalid domain redirect: %s", string(e))
+}
+
+// CanonicalDomain permanently redirects the requests arriving on the aliased
+// domains of the short links, such as www or retired domains, to the
+// canonical domain before the alias is resolved, so that the visits and the
+// referrers are recorded under a single domain.
+// The zero value of CanonicalDomain redirects nothing.
+type CanonicalDomain struct {
+	canonicalURLs map[string]url.URL
+}
+
+// RedirectURL decides the URL on the canonical domain for the request, keeping
+// the path and the query of the request. It returns false when the request
+// already arrives on the canonical domain or the domain isn't aliased.
+func (c CanonicalDomain) RedirectURL(r *http.Request) (string, bool) {
+	canonicalURL, ok := c.canonicalURLs[netutil.NormalizeHost(r.Host)]
+	if !ok {
+		return "", false
+	}
+
+	canonicalURL.Path = r.URL.Path
+	canonicalURL.RawPath = r.URL.RawPath
+	canonicalURL.RawQuery = r.URL.RawQuery
+	return canonicalURL.String(), true
+}
+
+// NewCanonicalDomain creates CanonicalDomain from the entries formatted as
+// domain=canonical URL, such as www.s.short-d.com=https://s.short-d.com.
+func NewCanonicalDomain(entries []string) (CanonicalDomain, error) {
+	canonicalURLs := make(map[string]url.URL, len(entries))
+	for _, entry := range entries {
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			return CanonicalDomain{}, ErrInvalidDomainRedirect(entry)
+		}
+
+		domain := netutil.NormalizeHost(strings.TrimSpace(parts[0]))
+		canonicalURL, err := url.Parse(strings.TrimSpace(parts[1]))
+		if domain == "" || err != nil || canonicalURL.Host == "" {
+			return CanonicalDomain{}, ErrInvalidDomainRedirect(entry)
+		}
+		if canonicalURL.Scheme != "http" && canonicalURL.Scheme != "https" {
+			return CanonicalDomain{}, ErrInvalidDomainRedirect(entry)
+		}
+		if netutil.NormalizeHost(canonicalURL.Host) == domain {
+			return CanonicalDomain{}, ErrInvalidDomainRedirect(entry)
+		}
+		canonicalURLs[domain] = url.URL{
+			Scheme: canonicalURL.Scheme,
+			Host:   canonicalURL.Host,
+		}
+	}
+
+	for domain, canonicalURL := range canonicalURLs {
+		_, ok := canonicalURLs[netutil.NormalizeHost(canonicalURL.Host)]
+		if ok {
+			return CanonicalDomain{}, ErrInvalidDomainRedirect(
+				fmt.Sprintf("%s=%s redirects again", domain, canonicalURL.String()),
+			)
+		}
+	}
+	return CanonicalDomain{canonicalURLs: canonicalURLs}, nil
+}
@@ -0,0 +1,134 @@
+// +build !integration all
+
+package handle
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+)
+
+func TestCanonicalDomain_RedirectURL(t *testing.T) {
+	t.Parallel()
+
+	redirects := []string{
+		"www.s.short-d.com=https://s.short-d.com",
+		"old-short.com = https://s.short-d.com",
+		"localhost:8080=http://localhost:8081",
+	}
+
+	testCases := []struct {
+		name               string
+		requestURL         string
+		expectedIsRedirect bool
+		expectedURL        string
+	}{
+		{
+			name:               "canonical domain",
+			requestURL:         "https://s.short-d.com/r/promo",
+			expectedIsRedirect: false,
+		},
+		{
+			name:               "unknown domain",
+			requestURL:         "https://short-d.com/r/promo",
+			expectedIsRedirect: false,
+		},
+		{
+			name:               "www domain",
+			requestURL:         "https://www.s.short-d.com/r/promo",
+			expectedIsRedirect: true,
+			expectedURL:        "https://s.short-d.com/r/promo",
+		},
+		{
+			name:               "old domain with query",
+			requestURL:         "http://old-short.com/r/promo?utm_source=email&ref=a%20b",
+			expectedIsRedirect: true,
+			expectedURL:        "https://s.short-d.com/r/promo?utm_source=email&ref=a%20b",
+		},
+		{
+			name:               "domain in upper case with default port",
+			requestURL:         "https://WWW.S.Short-D.com:443/r/Promo",
+			expectedIsRedirect: true,
+			expectedURL:        "https://s.short-d.com/r/Promo",
+		},
+		{
+			name:               "domain with port",
+			requestURL:         "http://localhost:8080/r/promo",
+			expectedIsRedirect: true,
+			expectedURL:        "http://localhost:8081/r/promo",
+		},
+		{
+			name:               "domain with other port",
+			requestURL:         "http://localhost:9090/r/promo",
+			expectedIsRedirect: false,
+		},
+	}
+
+	canonicalDomain, err := NewCanonicalDomain(redirects)
+	assert.Equal(t, nil, err)
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, testCase.requestURL, nil)
+			redirectURL, isRedirect := canonicalDomain.RedirectURL(req)
+			assert.Equal(t, testCase.expectedIsRedirect, isRedirect)
+			assert.Equal(t, testCase.expectedURL, redirectURL)
+		})
+	}
+}
+
+func TestNewCanonicalDomain(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		redirects   []string
+		expectedErr error
+	}{
+		{
+			name: "no redirect",
+		},
+		{
+			name:      "valid redirects",
+			redirects: []string{"www.s.short-d.com=https://s.short-d.com"},
+		},
+		{
+			name:        "missing canonical URL",
+			redirects:   []string{"www.s.short-d.com"},
+			expectedErr: ErrInvalidDomainRedirect("www.s.short-d.com"),
+		},
+		{
+			name:        "canonical URL without scheme",
+			redirects:   []string{"www.s.short-d.com=s.short-d.com"},
+			expectedErr: ErrInvalidDomainRedirect("www.s.short-d.com=s.short-d.com"),
+		},
+		{
+			name:        "redirect to itself",
+			redirects:   []string{"s.short-d.com=https://s.short-d.com"},
+			expectedErr: ErrInvalidDomainRedirect("s.short-d.com=https://s.short-d.com"),
+		},
+		{
+			name: "redirect chain",
+			redirects: []string{
+				"old-short.com=https://www.s.short-d.com",
+				"www.s.short-d.com=https://s.short-d.com",
+			},
+			expectedErr: ErrInvalidDomainRedirect("old-short.com=https://www.s.short-d.com redirects again"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewCanonicalDomain(testCase.redirects)
+			assert.Equal(t, testCase.expectedErr, err)
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package handle
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/short-d/app/fw/network"
+	"github.com/short-d/app/fw/router"
+	"github.com/short-d/short/backend/app/usecase/authenticator"
+	"github.com/short-d/short/backend/app/usecase/emailpassword"
+)
+
+// RegisterRequest represents the request received from Register API.
+type RegisterRequest struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// SignInRequest represents the request received from Sign In API.
+type SignInRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// PasswordResetRequest represents the request received from Request Password
+// Reset API.
+type PasswordResetRequest struct {
+	Email string `json:"email"`
+}
+
+// ResetPasswordRequest represents the request received from Reset Password
+// API.
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+// AuthTokenResponse represents the authentication token issued to the user.
+type AuthTokenResponse struct {
+	AuthToken string `json:"auth_token"`
+}
+
+// Register creates an account with email and password and signs the user in.
+func Register(
+	account emailpassword.Account,
+	authenticator authenticator.Authenticator,
+) router.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params router.Params) {
+		var body RegisterRequest
+		defer r.Body.Close()
+		err := json.NewDecoder(r.Body).Decode(&body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		user, err := account.Register(r.Context(), body.Name, body.Email, body.Password)
+		if err != nil {
+			http.Error(w, err.Error(), emailPasswordErrorStatus(err))
+			return
+		}
+
+		authToken, err := authenticator.GenerateToken(user)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusCreated, AuthTokenResponse{AuthToken: authToken})
+	}
+}
+
+// SignIn issues an authentication token to the user with matching email and
+// password, and rejects clients attempting too many sign ins.
+func SignIn(account emailpassword.Account, network network.Network) router.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params router.Params) {
+		var body SignInRequest
+		defer r.Body.Close()
+		err := json.NewDecoder(r.Body).Decode(&body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		connection := network.FromHTTP(r)
+		authToken, err := account.SignIn(body.Email, body.Password, connection.ClientIP)
+		if err != nil {
+			http.Error(w, err.Error(), emailPasswordErrorStatus(err))
+			return
+		}
+		writeJSON(w, http.StatusOK, AuthTokenResponse{AuthToken: authToken})
+	}
+}
+
+// RequestPasswordReset emails a password reset link to the account with the
+// email. It responds the same way whether the email is registered or not, and
+// rejects clients requesting too many resets.
+func RequestPasswordReset(
+	passwordReset emailpassword.PasswordReset,
+	network network.Network,
+) router.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params router.Params) {
+		var body PasswordResetRequest
+		defer r.Body.Close()
+		err := json.NewDecoder(r.Body).Decode(&body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		connection := network.FromHTTP(r)
+		err = passwordReset.RequestPasswordReset(body.Email, connection.ClientIP)
+		if err != nil {
+			http.Error(w, err.Error(), emailPasswordErrorStatus(err))
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+// ResetPassword replaces the password of the user with the token from the
+// password reset link.
+func ResetPassword(passwordReset emailpassword.PasswordReset) router.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params router.Params) {
+		var body ResetPasswordRequest
+		defer r.Body.Close()
+		err := json.NewDecoder(r.Body).Decode(&body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		err = passwordReset.ResetPassword(body.Token, body.Password)
+		if err != nil {
+			http.Error(w, err.Error(), emailPasswordErrorStatus(err))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func emailPasswordErrorStatus(err error) int {
+	var (
+		ie emailpassword.ErrInvalidEmail
+		wp emailpassword.ErrWeakPassword
+		ee emailpassword.ErrEmailExist
+		ic emailpassword.ErrInvalidCredentials
+		ta emailpassword.ErrTooManyAttempts
+		tr emailpassword.ErrTooManyResetRequests
+		it emailpassword.ErrInvalidToken
+		tu emailpassword.ErrTokenUsed
+		te emailpassword.ErrTokenExpired
+	)
+	switch {
+	case errors.As(err, &ie), errors.As(err, &wp), errors.As(err, &it):
+		return http.StatusBadRequest
+	case errors.As(err, &ee), errors.As(err, &tu):
+		return http.StatusConflict
+	case errors.As(err, &te):
+		return http.StatusGone
+	case errors.As(err, &ic):
+		return http.StatusUnauthorized
+	case errors.As(err, &ta), errors.As(err, &tr):
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
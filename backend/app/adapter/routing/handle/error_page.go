@@ -0,0 +1,176 @@
+package handle
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+
+	"github.com/short-d/short/backend/app/usecase/shortlink"
+)
+
+// LinkErrorReason explains why an alias can't redirect users to its long
+// link.
+type LinkErrorReason string
+
+// The constants enumerate all supported link error reasons.
+const (
+	LinkErrorReasonNotFound LinkErrorReason = "notFound"
+	LinkErrorReasonExpired  LinkErrorReason = "expired"
+)
+
+// Branding represents the look of the pages rendered for white-labeled
+// deployments.
+type Branding struct {
+	ProductName  string
+	LogoURL      string
+	PrimaryColor string
+}
+
+// ErrorPageData represents the context available to error page templates.
+type ErrorPageData struct {
+	Alias      string
+	Reason     LinkErrorReason
+	StatusCode int
+	HomeURL    string
+	Branding   Branding
+}
+
+// ErrorPage represents the page served when an alias can't redirect users.
+// The zero value renders the default page, so that clients and crawlers can
+// tell unknown aliases (404) from expired ones (410) by the status code.
+type ErrorPage struct {
+	template    *template.Template
+	redirectURL string
+}
+
+func (e ErrorPage) serve(
+	w http.ResponseWriter,
+	r *http.Request,
+	data ErrorPageData,
+	branding *Branding,
+	webFrontendURL url.URL,
+) {
+	if e.redirectURL != "" {
+		http.Redirect(w, r, e.redirectURL, http.StatusSeeOther)
+		return
+	}
+
+	tmpl := e.template
+	if tmpl == nil {
+		tmpl = defaultErrorPage
+	}
+
+	data.Branding = DefaultBranding
+	if branding != nil {
+		data.Branding = *branding
+	}
+
+	var buf bytes.Buffer
+	err := tmpl.Execute(&buf, data)
+	if err != nil {
+		serve404(w, r, webFrontendURL)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(data.StatusCode)
+	w.Write(buf.Bytes())
+}
+
+// ErrorPages represents the pages served for each link error reason. The
+// default pages are rendered with Branding when it is provided. Interstitial
+// serves a page linking to the long link instead of redirecting users when
+// the short link expired within the grace window.
+type ErrorPages struct {
+	NotFound     ErrorPage
+	Expired      ErrorPage
+	Interstitial bool
+	Branding     *Branding
+}
+
+// InterstitialPageData represents the context available to the interstitial
+// page of the short links expired within the grace window.
+type InterstitialPageData struct {
+	Alias    string
+	LongLink string
+	ExpireAt string
+	Branding Branding
+}
+
+func serveExpiredInterstitial(
+	w http.ResponseWriter,
+	r *http.Request,
+	data InterstitialPageData,
+	errorPages ErrorPages,
+	webFrontendURL url.URL,
+) {
+	data.Branding = DefaultBranding
+	if errorPages.Branding != nil {
+		data.Branding = *errorPages.Branding
+	}
+
+	var buf bytes.Buffer
+	err := defaultInterstitialPage.Execute(&buf, data)
+	if err != nil {
+		serve404(w, r, webFrontendURL)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+func serveLinkError(
+	w http.ResponseWriter,
+	r *http.Request,
+	alias string,
+	err error,
+	errorPages ErrorPages,
+	webFrontendURL url.URL,
+) {
+	homeURL := webFrontendURL
+	homeURL.Path = "/"
+
+	var (
+		notFound shortlink.ErrShortLinkNotFound
+		expired  shortlink.ErrShortLinkExpired
+	)
+	switch {
+	case errors.As(err, &notFound):
+		data := ErrorPageData{
+			Alias:      alias,
+			Reason:     LinkErrorReasonNotFound,
+			StatusCode: http.StatusNotFound,
+			HomeURL:    homeURL.String(),
+		}
+		errorPages.NotFound.serve(w, r, data, errorPages.Branding, webFrontendURL)
+	case errors.As(err, &expired):
+		data := ErrorPageData{
+			Alias:      alias,
+			Reason:     LinkErrorReasonExpired,
+			StatusCode: http.StatusGone,
+			HomeURL:    homeURL.String(),
+		}
+		errorPages.Expired.serve(w, r, data, errorPages.Branding, webFrontendURL)
+	default:
+		// Don't tell crawlers the alias is missing when it can't be retrieved.
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+// NewTemplateErrorPage creates ErrorPage which renders the given HTML
+// template with ErrorPageData.
+func NewTemplateErrorPage(name string, content string) (ErrorPage, error) {
+	tmpl, err := template.New(name).Parse(content)
+	if err != nil {
+		return ErrorPage{}, err
+	}
+	return ErrorPage{template: tmpl}, nil
+}
+
+// NewRedirectErrorPage creates ErrorPage which redirects users to the given
+// URL.
+func NewRedirectErrorPage(redirectURL string) ErrorPage {
+	return ErrorPage{redirectURL: redirectURL}
+}
@@ -0,0 +1,120 @@
+package handle
+
+import "html/template"
+
+// DefaultBranding represents the look of the web frontend.
+var DefaultBranding = Branding{
+	ProductName:  "Short",
+	PrimaryColor: "#e91e63",
+}
+
+var defaultErrorPage = template.Must(template.New("error_page").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>{{.StatusCode}} | {{.Branding.ProductName}}</title>
+  <style>
+    body {
+      align-items: center;
+      background-color: {{.Branding.PrimaryColor}};
+      color: #fff;
+      display: flex;
+      flex-direction: column;
+      font-family: sans-serif;
+      height: 100vh;
+      justify-content: center;
+      margin: 0;
+    }
+
+    .logo {
+      max-height: 64px;
+    }
+
+    .code {
+      font-size: 220px;
+      font-weight: 200;
+      letter-spacing: 20px;
+      line-height: 220px;
+    }
+
+    .to-home {
+      font-weight: 300;
+      letter-spacing: 2px;
+      margin-top: 80px;
+    }
+
+    .to-home a {
+      border-bottom: 1px solid #fff;
+      color: #fff;
+      font-weight: 500;
+      padding-bottom: 2px;
+      text-decoration: none;
+    }
+  </style>
+</head>
+<body>
+  {{if .Branding.LogoURL}}<img class="logo" src="{{.Branding.LogoURL}}" alt="{{.Branding.ProductName}}">{{end}}
+  <div class="code">{{.StatusCode}}</div>
+  <div class="to-home">
+    Take me back to <a href="{{.HomeURL}}">{{.Branding.ProductName}}</a>.
+  </div>
+</body>
+</html>
+`))
+
+var defaultInterstitialPage = template.Must(template.New("interstitial_page").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <meta name="robots" content="noindex">
+  <title>Expired link | {{.Branding.ProductName}}</title>
+  <style>
+    body {
+      align-items: center;
+      background-color: {{.Branding.PrimaryColor}};
+      color: #fff;
+      display: flex;
+      flex-direction: column;
+      font-family: sans-serif;
+      height: 100vh;
+      justify-content: center;
+      margin: 0;
+    }
+
+    .logo {
+      max-height: 64px;
+    }
+
+    .notice {
+      font-size: 24px;
+      font-weight: 300;
+      letter-spacing: 1px;
+      text-align: center;
+    }
+
+    .to-long-link {
+      font-weight: 300;
+      letter-spacing: 2px;
+      margin-top: 80px;
+    }
+
+    .to-long-link a {
+      border-bottom: 1px solid #fff;
+      color: #fff;
+      font-weight: 500;
+      padding-bottom: 2px;
+      text-decoration: none;
+    }
+  </style>
+</head>
+<body>
+  {{if .Branding.LogoURL}}<img class="logo" src="{{.Branding.LogoURL}}" alt="{{.Branding.ProductName}}">{{end}}
+  <div class="notice">This link expired at {{.ExpireAt}} and will stop working soon.</div>
+  <div class="to-long-link">
+    Continue to <a href="{{.LongLink}}" rel="noreferrer">{{.LongLink}}</a>.
+  </div>
+</body>
+</html>
+`))
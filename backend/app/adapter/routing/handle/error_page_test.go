@@ -0,0 +1,184 @@
+// +build !integration all
+
+package handle
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/short/backend/app/usecase/shortlink"
+)
+
+func TestServeLinkError(t *testing.T) {
+	t.Parallel()
+
+	notFoundPage, err := NewTemplateErrorPage(
+		"404.html",
+		"<p>{{.Alias}} is {{.Reason}}</p>",
+	)
+	assert.Equal(t, nil, err)
+	expiredPage, err := NewTemplateErrorPage(
+		"410.html",
+		"<p>{{.Alias}} has {{.Reason}}</p>",
+	)
+	assert.Equal(t, nil, err)
+	brandedPage, err := NewTemplateErrorPage(
+		"branded.html",
+		"<p>{{.Branding.ProductName}} can't find {{.Alias}}</p>",
+	)
+	assert.Equal(t, nil, err)
+	branding := Branding{
+		ProductName:  "Acme Links",
+		LogoURL:      "https://acme.com/logo.png",
+		PrimaryColor: "#123456",
+	}
+
+	testCases := []struct {
+		name               string
+		alias              string
+		err                error
+		errorPages         ErrorPages
+		expectedStatusCode int
+		expectedLocation   string
+		expectedBody       string
+		expectedBodyParts  []string
+	}{
+		{
+			name:               "default not found page",
+			alias:              "google",
+			err:                shortlink.ErrShortLinkNotFound("google"),
+			errorPages:         ErrorPages{},
+			expectedStatusCode: http.StatusNotFound,
+			expectedBodyParts: []string{
+				"<title>404 | Short</title>",
+			},
+		},
+		{
+			name:               "default expired page",
+			alias:              "google",
+			err:                shortlink.ErrShortLinkExpired("shortlink expired"),
+			errorPages:         ErrorPages{},
+			expectedStatusCode: http.StatusGone,
+			expectedBodyParts: []string{
+				"<title>410 | Short</title>",
+			},
+		},
+		{
+			name:               "retrieval failure",
+			alias:              "google",
+			err:                errors.New("connection refused"),
+			errorPages:         ErrorPages{NotFound: notFoundPage, Expired: expiredPage},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+		{
+			name:               "custom not found page",
+			alias:              "google",
+			err:                shortlink.ErrShortLinkNotFound("google"),
+			errorPages:         ErrorPages{NotFound: notFoundPage, Expired: expiredPage},
+			expectedStatusCode: http.StatusNotFound,
+			expectedBody:       "<p>google is notFound</p>",
+		},
+		{
+			name:               "custom expired page",
+			alias:              "google",
+			err:                shortlink.ErrShortLinkExpired("shortlink expired"),
+			errorPages:         ErrorPages{NotFound: notFoundPage, Expired: expiredPage},
+			expectedStatusCode: http.StatusGone,
+			expectedBody:       "<p>google has expired</p>",
+		},
+		{
+			name:               "escape alias in custom page",
+			alias:              "<script>",
+			err:                shortlink.ErrShortLinkNotFound("<script>"),
+			errorPages:         ErrorPages{NotFound: notFoundPage},
+			expectedStatusCode: http.StatusNotFound,
+			expectedBody:       "<p>&lt;script&gt; is notFound</p>",
+		},
+		{
+			name:               "only expired page configured",
+			alias:              "google",
+			err:                shortlink.ErrShortLinkNotFound("google"),
+			errorPages:         ErrorPages{Expired: expiredPage},
+			expectedStatusCode: http.StatusNotFound,
+			expectedBodyParts: []string{
+				"<title>404 | Short</title>",
+			},
+		},
+		{
+			name:               "redirect to custom not found page",
+			alias:              "google",
+			err:                shortlink.ErrShortLinkNotFound("google"),
+			errorPages:         ErrorPages{NotFound: NewRedirectErrorPage("https://example.com/missing")},
+			expectedStatusCode: http.StatusSeeOther,
+			expectedLocation:   "https://example.com/missing",
+		},
+		{
+			name:               "branded default not found page",
+			alias:              "google",
+			err:                shortlink.ErrShortLinkNotFound("google"),
+			errorPages:         ErrorPages{Branding: &branding},
+			expectedStatusCode: http.StatusNotFound,
+			expectedBodyParts: []string{
+				"<title>404 | Acme Links</title>",
+				"background-color: #123456;",
+				`<img class="logo" src="https://acme.com/logo.png" alt="Acme Links">`,
+				`<a href="https://short-d.com/">Acme Links</a>`,
+			},
+		},
+		{
+			name:               "branded default expired page",
+			alias:              "google",
+			err:                shortlink.ErrShortLinkExpired("shortlink expired"),
+			errorPages:         ErrorPages{Branding: &branding},
+			expectedStatusCode: http.StatusGone,
+			expectedBodyParts: []string{
+				"<title>410 | Acme Links</title>",
+				"background-color: #123456;",
+			},
+		},
+		{
+			name:               "custom page with default branding",
+			alias:              "google",
+			err:                shortlink.ErrShortLinkNotFound("google"),
+			errorPages:         ErrorPages{NotFound: brandedPage},
+			expectedStatusCode: http.StatusNotFound,
+			expectedBody:       "<p>Short can't find google</p>",
+		},
+		{
+			name:               "custom page with configured branding",
+			alias:              "google",
+			err:                shortlink.ErrShortLinkNotFound("google"),
+			errorPages:         ErrorPages{NotFound: brandedPage, Branding: &branding},
+			expectedStatusCode: http.StatusNotFound,
+			expectedBody:       "<p>Acme Links can't find google</p>",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			webFrontendURL, err := url.Parse("https://short-d.com")
+			assert.Equal(t, nil, err)
+
+			req := httptest.NewRequest(http.MethodGet, "/r/"+testCase.alias, nil)
+			w := httptest.NewRecorder()
+
+			serveLinkError(w, req, testCase.alias, testCase.err, testCase.errorPages, *webFrontendURL)
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedLocation, w.Header().Get("Location"))
+			if testCase.expectedBody != "" {
+				assert.Equal(t, testCase.expectedBody, w.Body.String())
+			}
+			for _, part := range testCase.expectedBodyParts {
+				assert.Equal(t, true, strings.Contains(w.Body.String(), part))
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package handle
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/short-d/app/fw/network"
+	"github.com/short-d/app/fw/router"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/ratelimit"
+	"github.com/short-d/short/backend/app/usecase/shortlink"
+)
+
+const (
+	guestSessionCookie       = "guest_session"
+	guestSessionIDBytes      = 16
+	guestSessionMaxAgeSecond = 365 * 24 * 60 * 60
+)
+
+// GuestAttribution groups the short links created by signed out users under an
+// anonymous session kept in a cookie, and hands them over to the account the
+// user signs in later. Signed out users need to wait for the cooldown between
+// creations. The zero value disables guest creation.
+type GuestAttribution struct {
+	enabled      bool
+	guestSession shortlink.GuestSession
+	cooldown     ratelimit.Cooldown
+	network      network.Network
+}
+
+// beginCreation retrieves the guest session of the request, starting a new one
+// when the request doesn't have any, and begins the cooldown of the session.
+// Requests without a session are also identified by the client IP and user
+// agent, so that dropping the cookie doesn't skip the cooldown.
+func (g GuestAttribution) beginCreation(w http.ResponseWriter, r *http.Request) (string, error) {
+	cookie, err := r.Cookie(guestSessionCookie)
+	if err == nil && cookie.Value != "" {
+		return cookie.Value, g.startCooldown(w, "session|"+cookie.Value)
+	}
+
+	connection := g.network.FromHTTP(r)
+	err = g.startCooldown(w, "client|"+connection.ClientIP+"|"+r.UserAgent())
+	if err != nil {
+		return "", err
+	}
+
+	buf := make([]byte, guestSessionIDBytes)
+	_, err = rand.Read(buf)
+	if err != nil {
+		return "", err
+	}
+	sessionID := hex.EncodeToString(buf)
+	http.SetCookie(w, &http.Cookie{
+		Name:     guestSessionCookie,
+		Value:    sessionID,
+		Path:     "/",
+		MaxAge:   guestSessionMaxAgeSecond,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return sessionID, g.startCooldown(w, "session|"+sessionID)
+}
+
+// startCooldown sets Retry-After header when the previous cooldown of the key
+// is not over yet.
+func (g GuestAttribution) startCooldown(w http.ResponseWriter, key string) error {
+	err := g.cooldown.Start(key)
+	var tooSoon ratelimit.ErrTooSoon
+	if errors.As(err, &tooSoon) {
+		retryAfter := int(math.Ceil(tooSoon.Wait.Seconds()))
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	}
+	return err
+}
+
+// claim transfers the short links of the guest session to the user and ends
+// the session.
+func (g GuestAttribution) claim(w http.ResponseWriter, r *http.Request, user entity.User) error {
+	if !g.enabled {
+		return nil
+	}
+
+	cookie, err := r.Cookie(guestSessionCookie)
+	if err != nil || cookie.Value == "" {
+		return nil
+	}
+
+	_, err = g.guestSession.Claim(r.Context(), cookie.Value, user)
+	if err != nil {
+		return err
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     guestSessionCookie,
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return nil
+}
+
+// GuestLinks fetches the short links created in the guest session of the
+// request.
+func GuestLinks(guestAttribution GuestAttribution) router.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params router.Params) {
+		if !guestAttribution.enabled {
+			http.Error(w, "guest creation is disabled", http.StatusNotFound)
+			return
+		}
+
+		shortLinks := []ShortLink{}
+		cookie, err := r.Cookie(guestSessionCookie)
+		if err == nil && cookie.Value != "" {
+			guestShortLinks, err := guestAttribution.guestSession.GetShortLinks(r.Context(), cookie.Value)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			for _, shortLink := range guestShortLinks {
+				shortLinks = append(shortLinks, newShortLink(shortLink))
+			}
+		}
+		writeJSON(w, http.StatusOK, shortLinks)
+	}
+}
+
+// NewGuestAttribution creates GuestAttribution.
+func NewGuestAttribution(
+	enabled bool,
+	guestSession shortlink.GuestSession,
+	cooldown ratelimit.Cooldown,
+	network network.Network,
+) GuestAttribution {
+	return GuestAttribution{
+		enabled:      enabled,
+		guestSession: guestSession,
+		cooldown:     cooldown,
+		network:      network,
+	}
+}
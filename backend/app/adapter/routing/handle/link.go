@@ -0,0 +1,368 @@
+package handle
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/short-d/app/fw/router"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/fw/tenant"
+	"github.com/short-d/short/backend/app/usecase/authenticator"
+	"github.com/short-d/short/backend/app/usecase/ratelimit"
+	"github.com/short-d/short/backend/app/usecase/share"
+	"github.com/short-d/short/backend/app/usecase/shortlink"
+)
+
+const defaultQRCodeSize = 256
+
+// CreateLinkRequest represents the request received from Create Link API.
+type CreateLinkRequest struct {
+	LongLink          string     `json:"long_link"`
+	CustomAlias       *string    `json:"custom_alias,omitempty"`
+	ExpireAt          *time.Time `json:"expire_at,omitempty"`
+	TrackVisits       *bool      `json:"track_visits,omitempty"`
+	PassthroughQuery  *bool      `json:"passthrough_query,omitempty"`
+	PassthroughPath   *bool      `json:"passthrough_path,omitempty"`
+	MaxRedirectsPerIP *int       `json:"max_redirects_per_ip,omitempty"`
+	OneTime           *bool      `json:"one_time,omitempty"`
+	Description       *string    `json:"description,omitempty"`
+	ReservationToken  *string    `json:"reservation_token,omitempty"`
+	IncludeQR         bool       `json:"include_qr,omitempty"`
+	QRCodeSize        *int       `json:"qr_code_size,omitempty"`
+}
+
+// CreateLinkResponse represents the response to the Create Link API request.
+// The QR code is only included when requested.
+type CreateLinkResponse struct {
+	ShortLink
+	QRCode   *string  `json:"qr_code,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// CreateLink creates a short link owned by the signed in user. When guest
+// creation is enabled, the short links created by signed out users are
+// attributed to their guest sessions instead. The QR code of the short link is
+// included in the response when requested so that clients don't need another
+// round trip to fetch it.
+func CreateLink(
+	shortLinkCreator shortlink.Creator,
+	authenticator authenticator.Authenticator,
+	guestAttribution GuestAttribution,
+	shortLinkShare share.Share,
+) router.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params router.Params) {
+		user, err := authenticator.GetUser(getBearerToken(r))
+		isGuest := err != nil
+		if isGuest && !guestAttribution.enabled {
+			http.Error(w, "invalid auth token", http.StatusUnauthorized)
+			return
+		}
+
+		var body CreateLinkRequest
+		defer r.Body.Close()
+		err = json.NewDecoder(r.Body).Decode(&body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		shortLinkInput := entity.ShortLinkInput{
+			LongLink:          &body.LongLink,
+			CustomAlias:       body.CustomAlias,
+			ExpireAt:          body.ExpireAt,
+			TrackVisits:       body.TrackVisits,
+			PassthroughQuery:  body.PassthroughQuery,
+			PassthroughPath:   body.PassthroughPath,
+			MaxRedirectsPerIP: body.MaxRedirectsPerIP,
+			OneTime:           body.OneTime,
+			Description:       body.Description,
+			ReservationToken:  body.ReservationToken,
+		}
+		var shortLink entity.ShortLink
+		if isGuest {
+			shortLink, err = createGuestLink(w, r, shortLinkCreator, guestAttribution, shortLinkInput)
+		} else {
+			ctx := tenant.NewContext(r.Context(), user.TenantID)
+			shortLink, err = shortLinkCreator.CreateShortLink(ctx, shortLinkInput, user, false)
+		}
+		if err != nil {
+			http.Error(w, err.Error(), createLinkErrorStatus(err))
+			return
+		}
+		writeJSON(w, http.StatusCreated, newCreateLinkResponse(shortLink, shortLinkShare, body))
+	}
+}
+
+// newCreateLinkResponse never fails because of the QR code since the short link
+// is already created. An empty QR code is returned with a warning instead.
+func newCreateLinkResponse(
+	shortLink entity.ShortLink,
+	shortLinkShare share.Share,
+	body CreateLinkRequest,
+) CreateLinkResponse {
+	resp := CreateLinkResponse{ShortLink: newSharedShortLink(shortLink, shortLinkShare)}
+	if !body.IncludeQR {
+		return resp
+	}
+
+	size := defaultQRCodeSize
+	if body.QRCodeSize != nil {
+		size = *body.QRCodeSize
+	}
+	qrCode, err := shortLinkShare.QRCodeDataURL(shortLink.Alias, size)
+	if err != nil {
+		resp.Warnings = append(resp.Warnings, fmt.Sprintf("fail to generate QR code: %s", err))
+	}
+	resp.QRCode = &qrCode
+	return resp
+}
+
+func createGuestLink(
+	w http.ResponseWriter,
+	r *http.Request,
+	shortLinkCreator shortlink.Creator,
+	guestAttribution GuestAttribution,
+	shortLinkInput entity.ShortLinkInput,
+) (entity.ShortLink, error) {
+	sessionID, err := guestAttribution.beginCreation(w, r)
+	if err != nil {
+		return entity.ShortLink{}, err
+	}
+	return shortLinkCreator.CreateGuestShortLink(r.Context(), shortLinkInput, sessionID)
+}
+
+func createLinkErrorStatus(err error) int {
+	var (
+		ae shortlink.ErrAliasExist
+		ar shortlink.ErrAliasReserved
+		l  shortlink.ErrInvalidLongLink
+		c  shortlink.ErrInvalidCustomAlias
+		ca shortlink.ErrConfusableAlias
+		m  shortlink.ErrMaliciousLongLink
+		sr shortlink.ErrSelfReferentialLink
+		q  shortlink.ErrAliasQuotaExceeded
+		ro shortlink.ErrServiceReadOnly
+		ts ratelimit.ErrTooSoon
+		dl shortlink.ErrDescriptionTooLong
+	)
+	switch {
+	case errors.As(err, &ts):
+		return http.StatusTooManyRequests
+	case errors.As(err, &ro):
+		return http.StatusServiceUnavailable
+	case errors.As(err, &ae), errors.As(err, &ar), errors.As(err, &ca):
+		return http.StatusConflict
+	case errors.As(err, &q):
+		return http.StatusForbidden
+	case errors.As(err, &l), errors.As(err, &c), errors.As(err, &sr), errors.As(err, &dl):
+		return http.StatusBadRequest
+	case errors.As(err, &m):
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// GetLink fetches a short link owned by the signed in user. The short links
+// owned by other users are reported as not found, without revealing whether
+// the alias is taken.
+func GetLink(
+	shortLinkRetriever shortlink.Retriever,
+	authenticator authenticator.Authenticator,
+	shortLinkShare share.Share,
+) router.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params router.Params) {
+		user, err := authenticator.GetUser(getBearerToken(r))
+		if err != nil {
+			http.Error(w, "invalid auth token", http.StatusUnauthorized)
+			return
+		}
+
+		ctx := tenant.NewContext(r.Context(), user.TenantID)
+		shortLinks, err := shortLinkRetriever.GetShortLinksByAliases(ctx, user, []string{params["alias"]})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if len(shortLinks) < 1 {
+			http.Error(w, "short link not found", http.StatusNotFound)
+			return
+		}
+		writeShortLink(w, http.StatusOK, shortLinks[0], shortLinkShare)
+	}
+}
+
+// LinkOwnership represents the response of Link Ownership API.
+type LinkOwnership struct {
+	IsOwner bool `json:"is_owner"`
+}
+
+// GetLinkOwnership checks whether the signed in user owns the short link,
+// so that integrations can decide whether to show edit controls. The short
+// links owned by other users and the missing aliases are both reported as
+// not owned, without revealing whether the alias exists.
+func GetLinkOwnership(
+	shortLinkRetriever shortlink.Retriever,
+	authenticator authenticator.Authenticator,
+) router.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params router.Params) {
+		user, err := authenticator.GetUser(getBearerToken(r))
+		if err != nil {
+			http.Error(w, "invalid auth token", http.StatusUnauthorized)
+			return
+		}
+
+		ctx := tenant.NewContext(r.Context(), user.TenantID)
+		isOwner, err := shortLinkRetriever.IsOwner(ctx, params["alias"], user)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, http.StatusOK, LinkOwnership{IsOwner: isOwner})
+	}
+}
+
+const defaultLinkPageSize = 30
+
+// ListLinks fetches a page of the short links owned by the signed in user,
+// from the most recently created one. The pages are selected with page and
+// per_page query parameters. Following GitHub's convention, the URLs of the
+// neighboring pages are returned in Link header and the number of short links
+// across all pages in X-Total-Count header, so that generic HTTP clients can
+// paginate without parsing the body.
+func ListLinks(
+	shortLinkRetriever shortlink.Retriever,
+	authenticator authenticator.Authenticator,
+	shortLinkShare share.Share,
+) router.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params router.Params) {
+		user, err := authenticator.GetUser(getBearerToken(r))
+		if err != nil {
+			http.Error(w, "invalid auth token", http.StatusUnauthorized)
+			return
+		}
+
+		query := r.URL.Query()
+		page, err := getPositiveInt(query, "page", 1)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		pageSize, err := getPositiveInt(query, "per_page", defaultLinkPageSize)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		ctx := tenant.NewContext(r.Context(), user.TenantID)
+		numberedPage, err := shortLinkRetriever.GetNumberedShortLinkPageByUser(ctx, user, page, pageSize)
+		if err != nil {
+			http.Error(w, err.Error(), listLinksErrorStatus(err))
+			return
+		}
+
+		shortLinks := []ShortLink{}
+		for _, shortLink := range numberedPage.ShortLinks {
+			shortLinks = append(shortLinks, newSharedShortLink(shortLink, shortLinkShare))
+		}
+
+		lastPage := (numberedPage.TotalCount + pageSize - 1) / pageSize
+		link := pageLinks(r.URL, page, lastPage)
+		if link != "" {
+			w.Header().Set("Link", link)
+		}
+		w.Header().Set("X-Total-Count", strconv.Itoa(numberedPage.TotalCount))
+		writeJSON(w, http.StatusOK, shortLinks)
+	}
+}
+
+func getPositiveInt(query url.Values, key string, defaultValue int) (int, error) {
+	value := query.Get(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	num, err := strconv.Atoi(value)
+	if err != nil || num <= 0 {
+		return 0, fmt.Errorf("%s must be a positive integer", key)
+	}
+	return num, nil
+}
+
+func listLinksErrorStatus(err error) int {
+	var (
+		ps shortlink.ErrInvalidPageSize
+		pn shortlink.ErrInvalidPageNumber
+	)
+	if errors.As(err, &ps) || errors.As(err, &pn) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// pageLinks formats the Link header pointing to the first, previous, next and
+// last pages relative to the requested one. Links beyond the existing pages
+// are left out.
+func pageLinks(requestURL *url.URL, page int, lastPage int) string {
+	var links []string
+	if page > 1 && lastPage > 0 {
+		prevPage := page - 1
+		if prevPage > lastPage {
+			prevPage = lastPage
+		}
+		links = append(links,
+			pageLink(requestURL, 1, "first"),
+			pageLink(requestURL, prevPage, "prev"),
+		)
+	}
+	if page < lastPage {
+		links = append(links,
+			pageLink(requestURL, page+1, "next"),
+			pageLink(requestURL, lastPage, "last"),
+		)
+	}
+	return strings.Join(links, ", ")
+}
+
+func pageLink(requestURL *url.URL, page int, rel string) string {
+	query := requestURL.Query()
+	query.Set("page", strconv.Itoa(page))
+	pageURL := url.URL{
+		Path:     requestURL.Path,
+		RawQuery: query.Encode(),
+	}
+	return fmt.Sprintf(`<%s>; rel="%s"`, pageURL.String(), rel)
+}
+
+func writeShortLink(
+	w http.ResponseWriter,
+	statusCode int,
+	shortLink entity.ShortLink,
+	shortLinkShare share.Share,
+) {
+	writeJSON(w, statusCode, newSharedShortLink(shortLink, shortLinkShare))
+}
+
+func newSharedShortLink(shortLink entity.ShortLink, shortLinkShare share.Share) ShortLink {
+	sharedShortLink := newShortLink(shortLink)
+	sharedShortLink.ShortLinkURL = shortLinkShare.ShortLinkURL(shortLink.Alias)
+	return sharedShortLink
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, body interface{}) {
+	respBody, err := json.Marshal(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	w.Write(respBody)
+}
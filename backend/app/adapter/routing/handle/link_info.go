@@ -0,0 +1,149 @@
+package handle
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/short-d/app/fw/network"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/authenticator"
+	"github.com/short-d/short/backend/app/usecase/ratelimit"
+	"github.com/short-d/short/backend/app/usecase/share"
+	"github.com/short-d/short/backend/app/usecase/shortlink"
+)
+
+// linkInfoSuffix is appended to the alias to ask for the info of the short
+// link instead of being redirected, such as /r/google+.
+const linkInfoSuffix = "+"
+
+// LinkInfo represents the info of a short link served at the alias with the
+// link info suffix. The short links limiting the total visits, such as
+// one-time short links, are private, so they are reported as not found to the
+// users other than the owners. The visit counts are only shown to the owners
+// of the short links tracking visits.
+type LinkInfo struct {
+	Alias      string     `json:"alias"`
+	LongLink   string     `json:"long_link,omitempty"`
+	CreatedAt  *time.Time `json:"created_at,omitempty"`
+	ExpireAt   *time.Time `json:"expire_at,omitempty"`
+	VisitCount *int       `json:"visit_count,omitempty"`
+}
+
+// isLinkInfoRequest decides whether the request asks for the info of the
+// short link. Aliases ending with the link info suffix which resolve to short
+// links on their own are still redirected, so that the suffix never shadows
+// existing aliases.
+func isLinkInfoRequest(
+	ctx context.Context,
+	signedAlias string,
+	aliasSigner share.Signer,
+	shortLinkRetriever shortlink.Retriever,
+) bool {
+	if !strings.HasSuffix(signedAlias, linkInfoSuffix) {
+		return false
+	}
+
+	alias, err := aliasSigner.Verify(signedAlias)
+	if err != nil {
+		return true
+	}
+	_, err = shortLinkRetriever.GetShortLink(ctx, alias, nil)
+	var notFound shortlink.ErrShortLinkNotFound
+	return errors.As(err, &notFound)
+}
+
+// serveLinkInfo responds with the info of the short link in JSON when the
+// link info suffix is appended to the alias, without redirecting users to the
+// long link, so neither visits nor redirection events are recorded. The
+// requests are limited together with the redirects through the same alias.
+func serveLinkInfo(
+	w http.ResponseWriter,
+	r *http.Request,
+	signedAlias string,
+	shortLinkRetriever shortlink.Retriever,
+	authenticator authenticator.Authenticator,
+	network network.Network,
+	rateLimiter ratelimit.Limiter,
+	timer timer.Timer,
+	aliasSigner share.Signer,
+) {
+	alias, err := aliasSigner.Verify(strings.TrimSuffix(signedAlias, linkInfoSuffix))
+	if err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	connection := network.FromHTTP(r)
+	allowed, err := rateLimiter.Allow(rateLimitKey(r.Context(), alias, connection.ClientIP))
+	if err == nil && !allowed {
+		w.WriteHeader(http.StatusTooManyRequests)
+		return
+	}
+
+	now := timer.Now()
+	shortLink, err := shortLinkRetriever.GetShortLink(r.Context(), alias, &now)
+	var (
+		notFound shortlink.ErrShortLinkNotFound
+		expired  shortlink.ErrShortLinkExpired
+	)
+	switch {
+	case errors.As(err, &notFound):
+		http.Error(w, "short link not found", http.StatusNotFound)
+		return
+	case errors.As(err, &expired):
+		http.Error(w, "short link expired", http.StatusGone)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	isOwner := isLinkOwner(r, alias, shortLinkRetriever, authenticator)
+	// The response differs between the owners and other users.
+	w.Header().Set("Cache-Control", "private, no-cache")
+	if isPrivateLink(shortLink) && !isOwner {
+		http.Error(w, "short link not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, newLinkInfo(shortLink, isOwner))
+}
+
+// isLinkOwner reports the short link as not owned when the user is signed out
+// or the ownership can't be checked.
+func isLinkOwner(
+	r *http.Request,
+	alias string,
+	shortLinkRetriever shortlink.Retriever,
+	authenticator authenticator.Authenticator,
+) bool {
+	user, err := authenticator.GetUser(getBearerToken(r))
+	if err != nil {
+		return false
+	}
+	isOwner, err := shortLinkRetriever.IsOwner(r.Context(), alias, user)
+	return err == nil && isOwner
+}
+
+// isPrivateLink decides whether the short link is hidden from the users
+// other than the owners.
+func isPrivateLink(shortLink entity.ShortLink) bool {
+	return shortLink.MaxVisits > 0
+}
+
+func newLinkInfo(shortLink entity.ShortLink, isOwner bool) LinkInfo {
+	info := LinkInfo{
+		Alias:     shortLink.Alias,
+		LongLink:  shortLink.LongLink,
+		CreatedAt: shortLink.CreatedAt,
+		ExpireAt:  shortLink.ExpireAt,
+	}
+	if isOwner && shortLink.TrackVisits {
+		visitCount := shortLink.VisitCount
+		info.VisitCount = &visitCount
+	}
+	return info
+}
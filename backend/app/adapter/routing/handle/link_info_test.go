@@ -0,0 +1,241 @@
+// +build !integration all
+
+package handle
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/logger"
+	"github.com/short-d/app/fw/metrics"
+	"github.com/short-d/app/fw/network"
+	"github.com/short-d/app/fw/router"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/adapter/request"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/authenticator"
+	"github.com/short-d/short/backend/app/usecase/keygen"
+	"github.com/short-d/short/backend/app/usecase/ratelimit"
+	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/share"
+	"github.com/short-d/short/backend/app/usecase/shortlink"
+	"github.com/short-d/short/backend/app/usecase/visit"
+)
+
+func TestLongLink_LinkInfo(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	createdAt := now.Add(-time.Hour)
+	owner := entity.User{ID: "alpha", Email: "alpha@example.com"}
+	other := entity.User{ID: "beta", Email: "beta@example.com"}
+	google := entity.ShortLink{
+		Alias:       "google",
+		LongLink:    "https://www.google.com",
+		CreatedAt:   &createdAt,
+		TrackVisits: true,
+		VisitCount:  7,
+		Description: "search engine",
+	}
+	secret := entity.ShortLink{
+		Alias:     "secret",
+		LongLink:  "https://www.google.com/secret",
+		CreatedAt: &createdAt,
+		MaxVisits: 1,
+	}
+	cpp := entity.ShortLink{
+		Alias:    "c++",
+		LongLink: "https://isocpp.org",
+	}
+	visitCount := 7
+
+	testCases := []struct {
+		name               string
+		alias              string
+		user               *entity.User
+		expectedStatusCode int
+		expectedLocation   string
+		expectedInfo       *LinkInfo
+		expectedVisits     int
+	}{
+		{
+			name:               "redirect without suffix",
+			alias:              "google",
+			expectedStatusCode: http.StatusSeeOther,
+			expectedLocation:   "https://www.google.com",
+			expectedVisits:     1,
+		},
+		{
+			name:               "link info with suffix",
+			alias:              "google+",
+			expectedStatusCode: http.StatusOK,
+			expectedInfo: &LinkInfo{
+				Alias:     "google",
+				LongLink:  "https://www.google.com",
+				CreatedAt: &createdAt,
+			},
+		},
+		{
+			name:               "link info with visit count for owner",
+			alias:              "google+",
+			user:               &owner,
+			expectedStatusCode: http.StatusOK,
+			expectedInfo: &LinkInfo{
+				Alias:      "google",
+				LongLink:   "https://www.google.com",
+				CreatedAt:  &createdAt,
+				VisitCount: &visitCount,
+			},
+		},
+		{
+			name:               "link info without visit count for other users",
+			alias:              "google+",
+			user:               &other,
+			expectedStatusCode: http.StatusOK,
+			expectedInfo: &LinkInfo{
+				Alias:     "google",
+				LongLink:  "https://www.google.com",
+				CreatedAt: &createdAt,
+			},
+		},
+		{
+			name:               "private link info for signed out users",
+			alias:              "secret+",
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name:               "private link info for other users",
+			alias:              "secret+",
+			user:               &other,
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name:               "private link info for owner",
+			alias:              "secret+",
+			user:               &owner,
+			expectedStatusCode: http.StatusOK,
+			expectedInfo: &LinkInfo{
+				Alias:     "secret",
+				LongLink:  "https://www.google.com/secret",
+				CreatedAt: &createdAt,
+			},
+		},
+		{
+			name:               "alias ending with suffix",
+			alias:              "c++",
+			expectedStatusCode: http.StatusSeeOther,
+			expectedLocation:   "https://isocpp.org",
+		},
+		{
+			name:               "link info of alias ending with suffix",
+			alias:              "c+++",
+			expectedStatusCode: http.StatusOK,
+			expectedInfo: &LinkInfo{
+				Alias:    "c++",
+				LongLink: "https://isocpp.org",
+			},
+		},
+		{
+			name:               "link info of missing alias",
+			alias:              "yahoo+",
+			expectedStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			tm := timer.NewStub(now)
+			entryRepo := logger.NewEntryRepoFake()
+			lg, err := logger.NewFake(logger.LogOff, &entryRepo)
+			assert.Equal(t, nil, err)
+
+			keyFetcher := keygen.NewKeyFetcherFake([]keygen.Key{"request"})
+			keyGen, err := keygen.NewRemote(1, &keyFetcher)
+			assert.Equal(t, nil, err)
+
+			geo := visit.NewGeoFake(nil)
+			instrumentationFactory := request.NewInstrumentationFactory(
+				lg,
+				tm,
+				metrics.NewFake(),
+				analyticsRecorder{events: make(chan string, 2)},
+				keyGen,
+				request.NewClient(network.NewProxy(), geo),
+			)
+
+			userShortLinkRepo := repository.NewUserShortLinkRepoFake(
+				[]entity.User{owner, owner},
+				[]entity.ShortLink{google, secret},
+			)
+			shortLinkRepo := repository.NewShortLinkFake(&userShortLinkRepo, map[string]entity.ShortLink{
+				"google": google,
+				"secret": secret,
+				"c++":    cpp,
+			})
+			visitRepo := repository.NewVisitFake([]entity.Visit{})
+			visitTracker := visit.NewTrackerPersist(
+				tm,
+				visit.Details{},
+				visit.NewUserAgentParserFake(nil),
+				visit.NewBuffer(&visitRepo, geo, visit.IPModeNone, tm, lg, 0, 0),
+				visit.CountBuffer{},
+			)
+			auth := authenticator.NewAuthenticatorFake(now, time.Hour)
+
+			handle := LongLink(
+				instrumentationFactory,
+				shortlink.NewRetrieverPersist(&shortLinkRepo, &userShortLinkRepo, 0),
+				visitTracker,
+				network.NewProxy(),
+				ratelimit.NewMemory(tm, 0, time.Minute),
+				ratelimit.NewMemory(tm, 0, time.Minute),
+				tm,
+				url.URL{Scheme: "https", Host: "short-d.com"},
+				ErrorPages{},
+				share.Signer{},
+				shortlink.QueryConflictKeepLongLink,
+				Redirect{},
+				CanonicalDomain{},
+				auth,
+			)
+
+			req := httptest.NewRequest(http.MethodGet, "/r/"+url.PathEscape(testCase.alias), nil)
+			if testCase.user != nil {
+				authToken, err := auth.GenerateToken(*testCase.user)
+				assert.Equal(t, nil, err)
+				req.Header.Set("Authorization", "Bearer "+authToken)
+			}
+			w := httptest.NewRecorder()
+			handle(w, req, router.Params{"alias": testCase.alias})
+
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedLocation, w.Header().Get("Location"))
+			if testCase.expectedInfo != nil {
+				assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+				var info LinkInfo
+				err = json.Unmarshal(w.Body.Bytes(), &info)
+				assert.Equal(t, nil, err)
+				assert.Equal(t, *testCase.expectedInfo, info)
+			}
+
+			visits, err := visitRepo.FindVisitsByAlias(context.Background(), "google", now, now.Add(time.Second))
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedVisits, len(visits))
+
+			// Reading the link info doesn't use up the visits of private
+			// short links.
+			isConsumed, err := shortLinkRepo.ConsumeVisit(context.Background(), "secret")
+			assert.Equal(t, nil, err)
+			assert.Equal(t, true, isConsumed)
+		})
+	}
+}
@@ -0,0 +1,965 @@
+// +build !integration all
+
+package handle
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/logger"
+	"github.com/short-d/app/fw/metrics"
+	"github.com/short-d/app/fw/network"
+	"github.com/short-d/app/fw/router"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/adapter/request"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/fw/ptr"
+	"github.com/short-d/short/backend/app/usecase/authenticator"
+	"github.com/short-d/short/backend/app/usecase/authorizer"
+	"github.com/short-d/short/backend/app/usecase/email"
+	"github.com/short-d/short/backend/app/usecase/keygen"
+	"github.com/short-d/short/backend/app/usecase/maintenance"
+	"github.com/short-d/short/backend/app/usecase/ratelimit"
+	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/risk"
+	"github.com/short-d/short/backend/app/usecase/share"
+	"github.com/short-d/short/backend/app/usecase/shortlink"
+	"github.com/short-d/short/backend/app/usecase/validator"
+	"github.com/short-d/short/backend/app/usecase/visit"
+	"github.com/short-d/short/backend/app/usecase/webhook"
+)
+
+func TestCreateLink(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	user := entity.User{ID: "alpha", Email: "alpha@example.com"}
+
+	testCases := []struct {
+		name               string
+		shortLinks         map[string]entity.ShortLink
+		availableKeys      []keygen.Key
+		blockedLongLinks   map[string]bool
+		isSignedIn         bool
+		body               string
+		expectedStatusCode int
+		expectedShortLink  ShortLink
+	}{
+		{
+			name:               "create with custom alias",
+			isSignedIn:         true,
+			body:               `{"long_link": "https://www.google.com", "custom_alias": "google"}`,
+			expectedStatusCode: http.StatusCreated,
+			expectedShortLink: ShortLink{
+				Alias:        "google",
+				ShortLinkURL: "https://short-d.com/r/google",
+				LongLink:     "https://www.google.com",
+				CreatedAt:    &now,
+			},
+		},
+		{
+			name:               "create with auto alias",
+			availableKeys:      []keygen.Key{"test"},
+			isSignedIn:         true,
+			body:               `{"long_link": "https://www.google.com"}`,
+			expectedStatusCode: http.StatusCreated,
+			expectedShortLink: ShortLink{
+				Alias:     "test",
+				LongLink:  "https://www.google.com",
+				CreatedAt: &now,
+			},
+		},
+		{
+			name:               "not signed in",
+			isSignedIn:         false,
+			body:               `{"long_link": "https://www.google.com", "custom_alias": "google"}`,
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:               "malformed body",
+			isSignedIn:         true,
+			body:               `{"long_link":`,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "invalid long link",
+			isSignedIn:         true,
+			body:               `{"long_link": "google", "custom_alias": "google"}`,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "invalid custom alias",
+			isSignedIn:         true,
+			body:               `{"long_link": "https://www.google.com", "custom_alias": "google#"}`,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "alias exists",
+			shortLinks: map[string]entity.ShortLink{
+				"google": {Alias: "google", LongLink: "https://www.google.com"},
+			},
+			isSignedIn:         true,
+			body:               `{"long_link": "https://www.google.com", "custom_alias": "google"}`,
+			expectedStatusCode: http.StatusConflict,
+		},
+		{
+			name:               "malicious long link",
+			blockedLongLinks:   map[string]bool{"https://malware.wicar.org": true},
+			isSignedIn:         true,
+			body:               `{"long_link": "https://malware.wicar.org", "custom_alias": "google"}`,
+			expectedStatusCode: http.StatusForbidden,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			shortLinks := map[string]entity.ShortLink{}
+			for alias, shortLink := range testCase.shortLinks {
+				shortLinks[alias] = shortLink
+			}
+			shortLinkRepo := repository.NewShortLinkFake(nil, shortLinks)
+			userShortLinkRepo := repository.NewUserShortLinkRepoFake(nil, nil)
+			keyFetcher := keygen.NewKeyFetcherFake(testCase.availableKeys)
+			keyGen, err := keygen.NewRemote(2, &keyFetcher)
+			assert.Equal(t, nil, err)
+			flaggedLinkRepo := repository.NewFlaggedShortLinkFake(nil)
+			preferencesRepo := repository.NewUserPreferencesFake(nil)
+			creator := shortlink.NewCreatorPersist(
+				&shortLinkRepo,
+				&userShortLinkRepo,
+				keyGen,
+				validator.NewLongLink(nil, nil, validator.FragmentPreserve, validator.PlainHTTPAllow),
+				validator.NewCustomAlias(),
+				timer.NewStub(now),
+				risk.NewDetector(
+					risk.NewBlackListFake(testCase.blockedLongLinks),
+					risk.NewDenylistFake(nil),
+					risk.StrictThresholds,
+				),
+				&flaggedLinkRepo,
+				shortlink.DefaultChecks,
+				shortlink.LongLinkUniquenessNone,
+				shortlink.AliasQuota{},
+				authorizer.Authorizer{},
+				email.NewSenderFake(nil),
+				webhook.NewDispatcherFake(),
+				&preferencesRepo,
+				maintenance.Mode{},
+				metrics.NewFake(),
+				0,
+				shortlink.ExpirationPolicy{},
+				&repository.AliasSkeletonFake{},
+				shortlink.RelationRetry{},
+				&repository.AliasReservationFake{},
+				shortlink.ReservationTTL{},
+				shortlink.ChainedLink{},
+			)
+			auth := authenticator.NewAuthenticatorFake(now, time.Hour)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/links", bytes.NewBufferString(testCase.body))
+			if testCase.isSignedIn {
+				authToken, err := auth.GenerateToken(user)
+				assert.Equal(t, nil, err)
+				req.Header.Set("Authorization", "Bearer "+authToken)
+			}
+			w := httptest.NewRecorder()
+
+			CreateLink(creator, auth, GuestAttribution{}, newShare(t))(w, req, router.Params{})
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			if testCase.expectedStatusCode != http.StatusCreated {
+				return
+			}
+
+			var shortLink ShortLink
+			err = json.Unmarshal(w.Body.Bytes(), &shortLink)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedShortLink.Alias, shortLink.Alias)
+			assert.Equal(t, "https://short-d.com/r/"+shortLink.Alias, shortLink.ShortLinkURL)
+			assert.Equal(t, testCase.expectedShortLink.LongLink, shortLink.LongLink)
+			assert.Equal(t, true, testCase.expectedShortLink.CreatedAt.Equal(*shortLink.CreatedAt))
+
+			isExist, err := userShortLinkRepo.HasMapping(req.Context(), user, shortLink.Alias)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, true, isExist)
+		})
+	}
+}
+
+// failingQRCodeGenerator fails to generate any QR code.
+type failingQRCodeGenerator struct{}
+
+func (f failingQRCodeGenerator) GeneratePNG(content string, size int) ([]byte, error) {
+	return nil, errors.New("QR code generator is down")
+}
+
+func TestCreateLink_IncludeQR(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	user := entity.User{ID: "alpha", Email: "alpha@example.com"}
+
+	testCases := []struct {
+		name             string
+		qrCodeGenerator  share.QRCodeGenerator
+		body             string
+		expectedQRCode   *string
+		expectedWarnings []string
+	}{
+		{
+			name:            "QR code not requested",
+			qrCodeGenerator: share.NewQRCodeGeneratorFake(),
+			body:            `{"long_link": "https://www.google.com", "custom_alias": "google"}`,
+		},
+		{
+			name:            "QR code requested",
+			qrCodeGenerator: share.NewQRCodeGeneratorFake(),
+			body:            `{"long_link": "https://www.google.com", "custom_alias": "google", "include_qr": true}`,
+			expectedQRCode: ptr.String(
+				"data:image/png;base64," + base64.StdEncoding.EncodeToString([]byte("https://short-d.com/r/google,256")),
+			),
+		},
+		{
+			name:            "QR code size clamped",
+			qrCodeGenerator: share.NewQRCodeGeneratorFake(),
+			body:            `{"long_link": "https://www.google.com", "custom_alias": "google", "include_qr": true, "qr_code_size": 4096}`,
+			expectedQRCode: ptr.String(
+				"data:image/png;base64," + base64.StdEncoding.EncodeToString([]byte("https://short-d.com/r/google,1024")),
+			),
+		},
+		{
+			name:             "QR code generation failed",
+			qrCodeGenerator:  failingQRCodeGenerator{},
+			body:             `{"long_link": "https://www.google.com", "custom_alias": "google", "include_qr": true}`,
+			expectedQRCode:   ptr.String(""),
+			expectedWarnings: []string{"fail to generate QR code: QR code generator is down"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			userShortLinkRepo := repository.NewUserShortLinkRepoFake(nil, nil)
+			shortLinkRepo := repository.NewShortLinkFake(&userShortLinkRepo, map[string]entity.ShortLink{})
+			keyFetcher := keygen.NewKeyFetcherFake(nil)
+			keyGen, err := keygen.NewRemote(2, &keyFetcher)
+			assert.Equal(t, nil, err)
+			flaggedLinkRepo := repository.NewFlaggedShortLinkFake(nil)
+			preferencesRepo := repository.NewUserPreferencesFake(nil)
+			creator := shortlink.NewCreatorPersist(
+				&shortLinkRepo,
+				&userShortLinkRepo,
+				keyGen,
+				validator.NewLongLink(nil, nil, validator.FragmentPreserve, validator.PlainHTTPAllow),
+				validator.NewCustomAlias(),
+				timer.NewStub(now),
+				risk.NewDetector(
+					risk.NewBlackListFake(nil),
+					risk.NewDenylistFake(nil),
+					risk.StrictThresholds,
+				),
+				&flaggedLinkRepo,
+				shortlink.DefaultChecks,
+				shortlink.LongLinkUniquenessNone,
+				shortlink.AliasQuota{},
+				authorizer.Authorizer{},
+				email.NewSenderFake(nil),
+				webhook.NewDispatcherFake(),
+				&preferencesRepo,
+				maintenance.Mode{},
+				metrics.NewFake(),
+				0,
+				shortlink.ExpirationPolicy{},
+				&repository.AliasSkeletonFake{},
+				shortlink.RelationRetry{},
+				&repository.AliasReservationFake{},
+				shortlink.ReservationTTL{},
+				shortlink.ChainedLink{},
+			)
+			auth := authenticator.NewAuthenticatorFake(now, time.Hour)
+			baseURL, err := url.Parse("https://short-d.com/r")
+			assert.Equal(t, nil, err)
+			shortLinkShare := share.NewShare(*baseURL, testCase.qrCodeGenerator, shortlink.NewMetaTagPersist(nil), share.Signer{})
+
+			authToken, err := auth.GenerateToken(user)
+			assert.Equal(t, nil, err)
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/links", bytes.NewBufferString(testCase.body))
+			req.Header.Set("Authorization", "Bearer "+authToken)
+			w := httptest.NewRecorder()
+
+			CreateLink(creator, auth, GuestAttribution{}, shortLinkShare)(w, req, router.Params{})
+			assert.Equal(t, http.StatusCreated, w.Code)
+
+			var resp CreateLinkResponse
+			err = json.Unmarshal(w.Body.Bytes(), &resp)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, "google", resp.Alias)
+			assert.Equal(t, "https://short-d.com/r/google", resp.ShortLinkURL)
+			assert.Equal(t, testCase.expectedQRCode, resp.QRCode)
+			assert.Equal(t, testCase.expectedWarnings, resp.Warnings)
+		})
+	}
+}
+
+func TestCreateLink_Guest(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	user := entity.User{ID: "alpha", Email: "alpha@example.com"}
+
+	userShortLinkRepo := repository.NewUserShortLinkRepoFake(nil, nil)
+	shortLinkRepo := repository.NewShortLinkFake(&userShortLinkRepo, map[string]entity.ShortLink{})
+	keyFetcher := keygen.NewKeyFetcherFake(nil)
+	keyGen, err := keygen.NewRemote(2, &keyFetcher)
+	assert.Equal(t, nil, err)
+	flaggedLinkRepo := repository.NewFlaggedShortLinkFake(nil)
+	preferencesRepo := repository.NewUserPreferencesFake(nil)
+	creator := shortlink.NewCreatorPersist(
+		&shortLinkRepo,
+		&userShortLinkRepo,
+		keyGen,
+		validator.NewLongLink(nil, nil, validator.FragmentPreserve, validator.PlainHTTPAllow),
+		validator.NewCustomAlias(),
+		timer.NewStub(now),
+		risk.NewDetector(
+			risk.NewBlackListFake(nil),
+			risk.NewDenylistFake(nil),
+			risk.StrictThresholds,
+		),
+		&flaggedLinkRepo,
+		shortlink.DefaultChecks,
+		shortlink.LongLinkUniquenessNone,
+		shortlink.AliasQuota{},
+		authorizer.Authorizer{},
+		email.NewSenderFake(nil),
+		webhook.NewDispatcherFake(),
+		&preferencesRepo,
+		maintenance.Mode{},
+		metrics.NewFake(),
+		0,
+		shortlink.ExpirationPolicy{},
+		&repository.AliasSkeletonFake{},
+		shortlink.RelationRetry{},
+		&repository.AliasReservationFake{},
+		shortlink.ReservationTTL{},
+		shortlink.ChainedLink{},
+	)
+	auth := authenticator.NewAuthenticatorFake(now, time.Hour)
+	guestSession := shortlink.NewGuestSessionPersist(&shortLinkRepo, &userShortLinkRepo)
+	guestAttribution := NewGuestAttribution(true, guestSession, ratelimit.Cooldown{}, network.NewProxy())
+	createLink := CreateLink(creator, auth, guestAttribution, newShare(t))
+
+	// The first creation starts a guest session.
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/links", bytes.NewBufferString(
+		`{"long_link": "https://www.google.com", "custom_alias": "google"}`,
+	))
+	w := httptest.NewRecorder()
+	createLink(w, req, router.Params{})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	cookies := w.Result().Cookies()
+	assert.Equal(t, 1, len(cookies))
+	sessionCookie := cookies[0]
+	assert.Equal(t, guestSessionCookie, sessionCookie.Name)
+	assert.Equal(t, true, sessionCookie.HttpOnly)
+
+	// Later creations in the same session are grouped together.
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/links", bytes.NewBufferString(
+		`{"long_link": "https://www.bing.com", "custom_alias": "bing"}`,
+	))
+	req.AddCookie(sessionCookie)
+	w = httptest.NewRecorder()
+	createLink(w, req, router.Params{})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, 0, len(w.Result().Cookies()))
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/guest/links", nil)
+	req.AddCookie(sessionCookie)
+	w = httptest.NewRecorder()
+	GuestLinks(guestAttribution)(w, req, router.Params{})
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var guestShortLinks []ShortLink
+	err = json.Unmarshal(w.Body.Bytes(), &guestShortLinks)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(guestShortLinks))
+	assert.Equal(t, "google", guestShortLinks[0].Alias)
+	assert.Equal(t, "bing", guestShortLinks[1].Alias)
+
+	// Signing in claims the short links of the session and ends it.
+	req = httptest.NewRequest(http.MethodGet, "/oauth/github/sign-in/callback", nil)
+	req.AddCookie(sessionCookie)
+	w = httptest.NewRecorder()
+	err = guestAttribution.claim(w, req, user)
+	assert.Equal(t, nil, err)
+
+	cookies = w.Result().Cookies()
+	assert.Equal(t, 1, len(cookies))
+	assert.Equal(t, guestSessionCookie, cookies[0].Name)
+	assert.Equal(t, -1, cookies[0].MaxAge)
+
+	for _, alias := range []string{"google", "bing"} {
+		isExist, err := userShortLinkRepo.HasMapping(req.Context(), user, alias)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, isExist)
+	}
+	aliases, err := userShortLinkRepo.FindAliasesBySession(req.Context(), sessionCookie.Value)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(aliases))
+}
+
+func TestCreateLink_GuestCooldown(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	user := entity.User{ID: "alpha", Email: "alpha@example.com"}
+	tm := timer.NewStub(now)
+
+	userShortLinkRepo := repository.NewUserShortLinkRepoFake(nil, nil)
+	shortLinkRepo := repository.NewShortLinkFake(&userShortLinkRepo, map[string]entity.ShortLink{})
+	keyFetcher := keygen.NewKeyFetcherFake(nil)
+	keyGen, err := keygen.NewRemote(2, &keyFetcher)
+	assert.Equal(t, nil, err)
+	flaggedLinkRepo := repository.NewFlaggedShortLinkFake(nil)
+	preferencesRepo := repository.NewUserPreferencesFake(nil)
+	creator := shortlink.NewCreatorPersist(
+		&shortLinkRepo,
+		&userShortLinkRepo,
+		keyGen,
+		validator.NewLongLink(nil, nil, validator.FragmentPreserve, validator.PlainHTTPAllow),
+		validator.NewCustomAlias(),
+		&tm,
+		risk.NewDetector(
+			risk.NewBlackListFake(nil),
+			risk.NewDenylistFake(nil),
+			risk.StrictThresholds,
+		),
+		&flaggedLinkRepo,
+		shortlink.DefaultChecks,
+		shortlink.LongLinkUniquenessNone,
+		shortlink.AliasQuota{},
+		authorizer.Authorizer{},
+		email.NewSenderFake(nil),
+		webhook.NewDispatcherFake(),
+		&preferencesRepo,
+		maintenance.Mode{},
+		metrics.NewFake(),
+		0,
+		shortlink.ExpirationPolicy{},
+		&repository.AliasSkeletonFake{},
+		shortlink.RelationRetry{},
+		&repository.AliasReservationFake{},
+		shortlink.ReservationTTL{},
+		shortlink.ChainedLink{},
+	)
+	auth := authenticator.NewAuthenticatorFake(now, time.Hour)
+	guestSession := shortlink.NewGuestSessionPersist(&shortLinkRepo, &userShortLinkRepo)
+	guestAttribution := NewGuestAttribution(
+		true,
+		guestSession,
+		ratelimit.NewCooldown(&tm, time.Minute),
+		network.NewProxy(),
+	)
+	createLink := CreateLink(creator, auth, guestAttribution, newShare(t))
+	newRequest := func(alias string) *http.Request {
+		return httptest.NewRequest(http.MethodPost, "/api/v1/links", bytes.NewBufferString(
+			fmt.Sprintf(`{"long_link": "https://www.google.com", "custom_alias": "%s"}`, alias),
+		))
+	}
+
+	req := newRequest("google")
+	w := httptest.NewRecorder()
+	createLink(w, req, router.Params{})
+	assert.Equal(t, http.StatusCreated, w.Code)
+	sessionCookie := w.Result().Cookies()[0]
+
+	// Creating again within the cooldown is rejected, with or without the
+	// session.
+	tm.CurrentTime = now.Add(20 * time.Second)
+	req = newRequest("bing")
+	req.AddCookie(sessionCookie)
+	w = httptest.NewRecorder()
+	createLink(w, req, router.Params{})
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "40", w.Header().Get("Retry-After"))
+
+	req = newRequest("bing")
+	w = httptest.NewRecorder()
+	createLink(w, req, router.Params{})
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, 0, len(w.Result().Cookies()))
+
+	// Signed in users are not subject to the cooldown.
+	authToken, err := auth.GenerateToken(user)
+	assert.Equal(t, nil, err)
+	req = newRequest("yahoo")
+	req.Header.Set("Authorization", "Bearer "+authToken)
+	req.AddCookie(sessionCookie)
+	w = httptest.NewRecorder()
+	createLink(w, req, router.Params{})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	// Creating after the cooldown succeeds.
+	tm.CurrentTime = now.Add(time.Minute)
+	req = newRequest("bing")
+	req.AddCookie(sessionCookie)
+	w = httptest.NewRecorder()
+	createLink(w, req, router.Params{})
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	aliases, err := userShortLinkRepo.FindAliasesBySession(req.Context(), sessionCookie.Value)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"google", "bing"}, aliases)
+}
+
+func TestCreateLink_ReadOnly(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	user := entity.User{ID: "alpha", Email: "alpha@example.com"}
+	tm := timer.NewStub(now)
+
+	userShortLinkRepo := repository.NewUserShortLinkRepoFake(nil, nil)
+	shortLinkRepo := repository.NewShortLinkFake(&userShortLinkRepo, map[string]entity.ShortLink{
+		"google": {Alias: "google", LongLink: "https://www.google.com"},
+	})
+	keyFetcher := keygen.NewKeyFetcherFake(nil)
+	keyGen, err := keygen.NewRemote(2, &keyFetcher)
+	assert.Equal(t, nil, err)
+	flaggedLinkRepo := repository.NewFlaggedShortLinkFake(nil)
+	preferencesRepo := repository.NewUserPreferencesFake(nil)
+	creator := shortlink.NewCreatorPersist(
+		&shortLinkRepo,
+		&userShortLinkRepo,
+		keyGen,
+		validator.NewLongLink(nil, nil, validator.FragmentPreserve, validator.PlainHTTPAllow),
+		validator.NewCustomAlias(),
+		tm,
+		risk.NewDetector(
+			risk.NewBlackListFake(nil),
+			risk.NewDenylistFake(nil),
+			risk.StrictThresholds,
+		),
+		&flaggedLinkRepo,
+		shortlink.DefaultChecks,
+		shortlink.LongLinkUniquenessNone,
+		shortlink.AliasQuota{},
+		authorizer.Authorizer{},
+		email.NewSenderFake(nil),
+		webhook.NewDispatcherFake(),
+		&preferencesRepo,
+		maintenance.NewMode(true),
+		metrics.NewFake(),
+		0,
+		shortlink.ExpirationPolicy{},
+		&repository.AliasSkeletonFake{},
+		shortlink.RelationRetry{},
+		&repository.AliasReservationFake{},
+		shortlink.ReservationTTL{},
+		shortlink.ChainedLink{},
+	)
+	auth := authenticator.NewAuthenticatorFake(now, time.Hour)
+
+	authToken, err := auth.GenerateToken(user)
+	assert.Equal(t, nil, err)
+	body := `{"long_link": "https://www.facebook.com", "custom_alias": "facebook"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/links", bytes.NewBufferString(body))
+	req.Header.Set("Authorization", "Bearer "+authToken)
+	w := httptest.NewRecorder()
+
+	CreateLink(creator, auth, GuestAttribution{}, newShare(t))(w, req, router.Params{})
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	isExist, err := shortLinkRepo.IsAliasExist(req.Context(), "facebook")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, isExist)
+
+	entryRepo := logger.NewEntryRepoFake()
+	lg, err := logger.NewFake(logger.LogOff, &entryRepo)
+	assert.Equal(t, nil, err)
+	geo := visit.NewGeoFake(nil)
+	instrumentationFactory := request.NewInstrumentationFactory(
+		lg,
+		tm,
+		metrics.NewFake(),
+		analyticsRecorder{events: make(chan string, 2)},
+		keyGen,
+		request.NewClient(network.NewProxy(), geo),
+	)
+	visitRepo := repository.NewVisitFake([]entity.Visit{})
+	visitTracker := visit.NewTrackerPersist(
+		tm,
+		visit.Details{},
+		visit.NewUserAgentParserFake(nil),
+		visit.NewBuffer(&visitRepo, geo, visit.IPModeNone, tm, lg, 0, 0),
+		visit.CountBuffer{},
+	)
+
+	handle := LongLink(
+		instrumentationFactory,
+		shortlink.NewRetrieverPersist(&shortLinkRepo, &userShortLinkRepo, 0),
+		visitTracker,
+		network.NewProxy(),
+		ratelimit.NewMemory(tm, 0, time.Minute),
+		ratelimit.NewMemory(tm, 0, time.Minute),
+		tm,
+		url.URL{Scheme: "https", Host: "short-d.com"},
+		ErrorPages{},
+		share.Signer{},
+		shortlink.QueryConflictKeepLongLink,
+		Redirect{},
+		CanonicalDomain{},
+		auth,
+	)
+
+	req = httptest.NewRequest(http.MethodGet, "/r/google", nil)
+	w = httptest.NewRecorder()
+	handle(w, req, router.Params{"alias": "google"})
+
+	assert.Equal(t, http.StatusSeeOther, w.Code)
+	assert.Equal(t, "https://www.google.com", w.Header().Get("Location"))
+}
+
+func TestGetLink(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	owner := entity.User{ID: "alpha", Email: "alpha@example.com"}
+	google := entity.ShortLink{Alias: "google", LongLink: "https://www.google.com"}
+	bing := entity.ShortLink{Alias: "bing", LongLink: "https://www.bing.com"}
+	cafe := entity.ShortLink{Alias: "caf\u00e9", LongLink: "https://www.cafe.com"}
+
+	testCases := []struct {
+		name               string
+		user               *entity.User
+		alias              string
+		expectedStatusCode int
+		expectedShortLink  ShortLink
+	}{
+		{
+			name:               "owned short link",
+			user:               &owner,
+			alias:              "google",
+			expectedStatusCode: http.StatusOK,
+			expectedShortLink: ShortLink{
+				Alias:        "google",
+				ShortLinkURL: "https://short-d.com/r/google",
+				LongLink:     "https://www.google.com",
+			},
+		},
+		{
+			name:               "not signed in",
+			user:               nil,
+			alias:              "google",
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:               "owned short link requested in decomposed form",
+			user:               &owner,
+			alias:              "cafe\u0301",
+			expectedStatusCode: http.StatusOK,
+			expectedShortLink: ShortLink{
+				Alias:        "caf\u00e9",
+				ShortLinkURL: "https://short-d.com/r/caf%C3%A9",
+				LongLink:     "https://www.cafe.com",
+			},
+		},
+		{
+			name:               "short link owned by another user",
+			user:               &entity.User{ID: "beta", Email: "beta@example.com"},
+			alias:              "google",
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name:               "short link not found",
+			user:               &owner,
+			alias:              "yahoo",
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name:               "short link owned by nobody",
+			user:               &owner,
+			alias:              "bing",
+			expectedStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			shortLinkRepo := repository.NewShortLinkFake(nil, map[string]entity.ShortLink{
+				google.Alias: google,
+				bing.Alias:   bing,
+				cafe.Alias:   cafe,
+			})
+			userShortLinkRepo := repository.NewUserShortLinkRepoFake(
+				[]entity.User{owner, owner},
+				[]entity.ShortLink{google, cafe},
+			)
+			retriever := shortlink.NewRetrieverPersist(&shortLinkRepo, &userShortLinkRepo, 0)
+			auth := authenticator.NewAuthenticatorFake(now, time.Hour)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/links/"+url.PathEscape(testCase.alias), nil)
+			if testCase.user != nil {
+				authToken, err := auth.GenerateToken(*testCase.user)
+				assert.Equal(t, nil, err)
+				req.Header.Set("Authorization", "Bearer "+authToken)
+			}
+			w := httptest.NewRecorder()
+
+			GetLink(retriever, auth, newShare(t))(w, req, router.Params{"alias": testCase.alias})
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			if testCase.expectedStatusCode != http.StatusOK {
+				return
+			}
+
+			var shortLink ShortLink
+			err := json.Unmarshal(w.Body.Bytes(), &shortLink)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedShortLink, shortLink)
+		})
+	}
+}
+
+func TestGetLinkOwnership(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	owner := entity.User{ID: "alpha", Email: "alpha@example.com"}
+	google := entity.ShortLink{Alias: "google", LongLink: "https://www.google.com"}
+
+	testCases := []struct {
+		name               string
+		user               *entity.User
+		alias              string
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name:               "owned short link",
+			user:               &owner,
+			alias:              "google",
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"is_owner":true}`,
+		},
+		{
+			name:               "not signed in",
+			user:               nil,
+			alias:              "google",
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedBody:       "invalid auth token\n",
+		},
+		{
+			name:               "short link owned by another user",
+			user:               &entity.User{ID: "beta", Email: "beta@example.com"},
+			alias:              "google",
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"is_owner":false}`,
+		},
+		{
+			name:               "short link not found",
+			user:               &entity.User{ID: "beta", Email: "beta@example.com"},
+			alias:              "yahoo",
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"is_owner":false}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			shortLinkRepo := repository.NewShortLinkFake(nil, map[string]entity.ShortLink{
+				google.Alias: google,
+			})
+			userShortLinkRepo := repository.NewUserShortLinkRepoFake(
+				[]entity.User{owner},
+				[]entity.ShortLink{google},
+			)
+			retriever := shortlink.NewRetrieverPersist(&shortLinkRepo, &userShortLinkRepo, 0)
+			auth := authenticator.NewAuthenticatorFake(now, time.Hour)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/links/"+testCase.alias+"/ownership", nil)
+			if testCase.user != nil {
+				authToken, err := auth.GenerateToken(*testCase.user)
+				assert.Equal(t, nil, err)
+				req.Header.Set("Authorization", "Bearer "+authToken)
+			}
+			w := httptest.NewRecorder()
+
+			GetLinkOwnership(retriever, auth)(w, req, router.Params{"alias": testCase.alias})
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedBody, w.Body.String())
+		})
+	}
+}
+
+func TestListLinks(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	owner := entity.User{ID: "alpha", Email: "alpha@example.com"}
+
+	var users []entity.User
+	var shortLinks []entity.ShortLink
+	for _, alias := range []string{"a", "b", "c", "d", "e"} {
+		createdAt := now.Add(time.Duration(len(shortLinks)) * time.Minute)
+		users = append(users, owner)
+		shortLinks = append(shortLinks, entity.ShortLink{
+			Alias:     alias,
+			LongLink:  "https://www.google.com",
+			CreatedAt: &createdAt,
+		})
+	}
+
+	testCases := []struct {
+		name               string
+		user               *entity.User
+		path               string
+		expectedStatusCode int
+		expectedAliases    []string
+		expectedLink       string
+		expectedTotalCount string
+	}{
+		{
+			name:               "first page",
+			user:               &owner,
+			path:               "/api/v1/links?per_page=2",
+			expectedStatusCode: http.StatusOK,
+			expectedAliases:    []string{"e", "d"},
+			expectedLink: `</api/v1/links?page=2&per_page=2>; rel="next", ` +
+				`</api/v1/links?page=3&per_page=2>; rel="last"`,
+			expectedTotalCount: "5",
+		},
+		{
+			name:               "middle page",
+			user:               &owner,
+			path:               "/api/v1/links?page=2&per_page=2",
+			expectedStatusCode: http.StatusOK,
+			expectedAliases:    []string{"c", "b"},
+			expectedLink: `</api/v1/links?page=1&per_page=2>; rel="first", ` +
+				`</api/v1/links?page=1&per_page=2>; rel="prev", ` +
+				`</api/v1/links?page=3&per_page=2>; rel="next", ` +
+				`</api/v1/links?page=3&per_page=2>; rel="last"`,
+			expectedTotalCount: "5",
+		},
+		{
+			name:               "last page",
+			user:               &owner,
+			path:               "/api/v1/links?page=3&per_page=2",
+			expectedStatusCode: http.StatusOK,
+			expectedAliases:    []string{"a"},
+			expectedLink: `</api/v1/links?page=1&per_page=2>; rel="first", ` +
+				`</api/v1/links?page=2&per_page=2>; rel="prev"`,
+			expectedTotalCount: "5",
+		},
+		{
+			name:               "page beyond last page",
+			user:               &owner,
+			path:               "/api/v1/links?page=5&per_page=2",
+			expectedStatusCode: http.StatusOK,
+			expectedAliases:    []string{},
+			expectedLink: `</api/v1/links?page=1&per_page=2>; rel="first", ` +
+				`</api/v1/links?page=3&per_page=2>; rel="prev"`,
+			expectedTotalCount: "5",
+		},
+		{
+			name:               "single page",
+			user:               &owner,
+			path:               "/api/v1/links",
+			expectedStatusCode: http.StatusOK,
+			expectedAliases:    []string{"e", "d", "c", "b", "a"},
+			expectedLink:       "",
+			expectedTotalCount: "5",
+		},
+		{
+			name:               "no short link",
+			user:               &entity.User{ID: "beta", Email: "beta@example.com"},
+			path:               "/api/v1/links",
+			expectedStatusCode: http.StatusOK,
+			expectedAliases:    []string{},
+			expectedLink:       "",
+			expectedTotalCount: "0",
+		},
+		{
+			name:               "not signed in",
+			user:               nil,
+			path:               "/api/v1/links",
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:               "invalid page",
+			user:               &owner,
+			path:               "/api/v1/links?page=0",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "page size too large",
+			user:               &owner,
+			path:               "/api/v1/links?per_page=101",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			shortLinkRepo := repository.NewShortLinkFake(nil, map[string]entity.ShortLink{})
+			userShortLinkRepo := repository.NewUserShortLinkRepoFake(users, shortLinks)
+			retriever := shortlink.NewRetrieverPersist(&shortLinkRepo, &userShortLinkRepo, 0)
+			auth := authenticator.NewAuthenticatorFake(now, time.Hour)
+
+			req := httptest.NewRequest(http.MethodGet, testCase.path, nil)
+			if testCase.user != nil {
+				authToken, err := auth.GenerateToken(*testCase.user)
+				assert.Equal(t, nil, err)
+				req.Header.Set("Authorization", "Bearer "+authToken)
+			}
+			w := httptest.NewRecorder()
+
+			ListLinks(retriever, auth, newShare(t))(w, req, router.Params{})
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			if testCase.expectedStatusCode != http.StatusOK {
+				return
+			}
+
+			assert.Equal(t, testCase.expectedLink, w.Header().Get("Link"))
+			assert.Equal(t, testCase.expectedTotalCount, w.Header().Get("X-Total-Count"))
+
+			var page []ShortLink
+			err := json.Unmarshal(w.Body.Bytes(), &page)
+			assert.Equal(t, nil, err)
+
+			aliases := []string{}
+			for _, shortLink := range page {
+				aliases = append(aliases, shortLink.Alias)
+				assert.Equal(t, "https://short-d.com/r/"+shortLink.Alias, shortLink.ShortLinkURL)
+			}
+			assert.Equal(t, testCase.expectedAliases, aliases)
+		})
+	}
+}
+
+func newShare(t *testing.T) share.Share {
+	baseURL, err := url.Parse("https://short-d.com/r")
+	assert.Equal(t, nil, err)
+	return share.NewShare(*baseURL, share.NewQRCodeGeneratorFake(), shortlink.NewMetaTagPersist(nil), share.Signer{})
+}
@@ -1,39 +1,176 @@
 package handle
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
+	"time"
 
+	"github.com/short-d/app/fw/network"
 	"github.com/short-d/app/fw/router"
 	"github.com/short-d/app/fw/timer"
 	"github.com/short-d/short/backend/app/adapter/request"
+	"github.com/short-d/short/backend/app/fw/tenant"
+	"github.com/short-d/short/backend/app/usecase/authenticator"
+	"github.com/short-d/short/backend/app/usecase/ratelimit"
+	"github.com/short-d/short/backend/app/usecase/share"
 	"github.com/short-d/short/backend/app/usecase/shortlink"
+	"github.com/short-d/short/backend/app/usecase/visit"
 )
 
-// LongLink translates alias to the original long link.
+// LongLink redirects users from the alias to the long link of the short link.
+// Requests with invalid alias signatures are rejected with 403 Forbidden, and
+// clients redirected through the same alias too often are rejected with 429
+// Too Many Requests without recording visits. Users are shown the error pages
+// when the alias is missing, expired or has no visits left. Visits are
+// tracked unless the short link opts out of visit tracking.
 func LongLink(
 	instrumentationFactory request.InstrumentationFactory,
 	shortLinkRetriever shortlink.Retriever,
+	visitTracker visit.Tracker,
+	network network.Network,
+	rateLimiter ratelimit.Limiter,
+	linkRateLimiter ratelimit.VariableLimiter,
 	timer timer.Timer,
 	webFrontendURL url.URL,
+	errorPages ErrorPages,
+	aliasSigner share.Signer,
+	queryConflict shortlink.QueryConflict,
+	redirect Redirect,
+	canonicalDomain CanonicalDomain,
+	authenticator authenticator.Authenticator,
 ) router.Handle {
 	return func(w http.ResponseWriter, r *http.Request, params router.Params) {
-		alias := params["alias"]
+		canonicalURL, ok := canonicalDomain.RedirectURL(r)
+		if ok {
+			http.Redirect(w, r, canonicalURL, http.StatusMovedPermanently)
+			return
+		}
+
+		signedAlias := params["alias"]
+		extraPath := trailingPath(r.URL.EscapedPath())
+		if extraPath == "" && isLinkInfoRequest(r.Context(), signedAlias, aliasSigner, shortLinkRetriever) {
+			serveLinkInfo(
+				w,
+				r,
+				signedAlias,
+				shortLinkRetriever,
+				authenticator,
+				network,
+				rateLimiter,
+				timer,
+				aliasSigner,
+			)
+			return
+		}
+
+		alias, err := aliasSigner.Verify(signedAlias)
+		if err != nil {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
 
 		i := instrumentationFactory.NewHTTP(r)
-		i.RedirectingAliasToLongLink(alias)
+
+		connection := network.FromHTTP(r)
+		allowed, err := rateLimiter.Allow(rateLimitKey(r.Context(), alias, connection.ClientIP))
+		if err == nil && !allowed {
+			i.RedirectingAliasToLongLink(alias)
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
 
 		now := timer.Now()
-		s, err := shortLinkRetriever.GetShortLink(alias, &now)
+		s, err := shortLinkRetriever.GetShortLink(r.Context(), alias, &now)
 		if err != nil {
+			i.RedirectingAliasToLongLink(alias)
 			i.LongLinkRetrievalFailed(err)
-			serve404(w, r, webFrontendURL)
+			serveLinkError(w, r, alias, err, errorPages, webFrontendURL)
 			return
 		}
 		i.LongLinkRetrievalSucceed()
 
+		if extraPath != "" && !s.PassthroughPath {
+			serveLinkError(w, r, alias, shortlink.ErrShortLinkNotFound(alias), errorPages, webFrontendURL)
+			return
+		}
+
+		key := rateLimitKey(r.Context(), alias, connection.ClientIP)
+		allowed, err = linkRateLimiter.AllowUpTo(key, s.MaxRedirectsPerIP)
+		if err == nil && !allowed {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		err = shortLinkRetriever.ConsumeVisit(r.Context(), s)
+		if err != nil {
+			serveLinkError(w, r, alias, err, errorPages, webFrontendURL)
+			return
+		}
+
 		longLink := s.LongLink
-		http.Redirect(w, r, longLink, http.StatusSeeOther)
+		if s.PassthroughPath {
+			longLink = shortlink.JoinPath(longLink, extraPath)
+		}
+		if s.PassthroughQuery || s.PassthroughPath {
+			longLink = queryConflict.MergeQuery(longLink, r.URL.Query())
+		}
+		w.Header().Set("Cache-Control", redirect.CacheControl(s, now))
+		inGrace := shortLinkRetriever.GetExpiryState(s, now) == shortlink.ExpiryStateGrace
+		if inGrace {
+			w.Header().Set("Warning", expiredWarning(*s.ExpireAt))
+		}
+		if inGrace && errorPages.Interstitial {
+			data := InterstitialPageData{
+				Alias:    alias,
+				LongLink: longLink,
+				ExpireAt: s.ExpireAt.UTC().Format(time.RFC3339),
+			}
+			serveExpiredInterstitial(w, r, data, errorPages, webFrontendURL)
+		} else {
+			http.Redirect(w, r, longLink, redirect.StatusCode())
+		}
+		if !s.TrackVisits {
+			return
+		}
+		i.RedirectingAliasToLongLink(alias)
 		i.RedirectedAliasToLongLink(s)
+
+		visitor := visit.Visitor{
+			IPAddress:   connection.ClientIP,
+			Referrer:    r.Referer(),
+			UserAgent:   r.UserAgent(),
+			Destination: longLink,
+		}
+		err = visitTracker.TrackVisit(r.Context(), alias, visitor)
+		if err != nil {
+			i.VisitTrackingFailed(err)
+		}
+	}
+}
+
+// expiredWarning describes the short link expired within the grace window,
+// which still redirects users.
+func expiredWarning(expireAt time.Time) string {
+	return fmt.Sprintf(`299 - "short link expired at %s"`, expireAt.UTC().Format(time.RFC3339))
+}
+
+// trailingPath retrieves the escaped path following the alias in
+// /r/{alias}/{path}, such as /extra in /r/google/extra. The path is appended
+// to the long link of the short links which opt in to path passthrough, while
+// the other short links show users the not found error page for such paths.
+func trailingPath(escapedPath string) string {
+	segments := strings.SplitN(escapedPath, "/", 4)
+	if len(segments) < 4 || segments[3] == "" {
+		return ""
 	}
+	return "/" + segments[3]
+}
+
+// rateLimitKey limits the redirects of each client through each alias
+// separately. The same alias of different tenants is limited separately too.
+func rateLimitKey(ctx context.Context, alias string, clientIP string) string {
+	return fmt.Sprintf("%s|%s|%s", tenant.FromContext(ctx), alias, clientIP)
 }
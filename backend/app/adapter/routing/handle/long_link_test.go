@@ -0,0 +1,991 @@
+// +build !integration all
+
+package handle
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/ctx"
+	"github.com/short-d/app/fw/logger"
+	"github.com/short-d/app/fw/metrics"
+	"github.com/short-d/app/fw/network"
+	"github.com/short-d/app/fw/router"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/adapter/request"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/fw/ptr"
+	"github.com/short-d/short/backend/app/fw/tenant"
+	"github.com/short-d/short/backend/app/usecase/authenticator"
+	"github.com/short-d/short/backend/app/usecase/keygen"
+	"github.com/short-d/short/backend/app/usecase/ratelimit"
+	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/share"
+	"github.com/short-d/short/backend/app/usecase/shortlink"
+	"github.com/short-d/short/backend/app/usecase/visit"
+)
+
+// analyticsRecorder captures the names of the tracked events.
+type analyticsRecorder struct {
+	events chan string
+}
+
+func (a analyticsRecorder) Identify(userID string, traits map[string]string) {}
+
+func (a analyticsRecorder) Track(eventName string, properties map[string]string, userID string, ctx ctx.ExecutionContext) {
+	a.events <- eventName
+}
+
+func (a analyticsRecorder) Group(userID string, groupID string) {}
+
+func (a analyticsRecorder) Alias(prevUserID string, newUserID string) {}
+
+func TestLongLink(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	before := now.Add(-time.Hour)
+
+	testCases := []struct {
+		name                 string
+		shortLink            entity.ShortLink
+		alias                string
+		query                string
+		queryConflict        shortlink.QueryConflict
+		redirect             Redirect
+		expectedStatusCode   int
+		expectedLocation     string
+		expectedCacheControl string
+		expectedVisits       int
+		expectedEvents       []string
+	}{
+		{
+			name: "track visits",
+			shortLink: entity.ShortLink{
+				Alias:       "google",
+				LongLink:    "https://www.google.com",
+				TrackVisits: true,
+			},
+			alias:                "google",
+			expectedStatusCode:   http.StatusSeeOther,
+			expectedLocation:     "https://www.google.com",
+			expectedCacheControl: "no-cache",
+			expectedVisits:       1,
+			expectedEvents: []string{
+				"RedirectingAliasToLongLink",
+				"RedirectedAliasToLongLink",
+			},
+		},
+		{
+			name: "permanent redirect of non-editable short link",
+			shortLink: entity.ShortLink{
+				Alias:       "google",
+				LongLink:    "https://www.google.com",
+				TrackVisits: false,
+			},
+			alias:                "google",
+			redirect:             Redirect{statusCode: http.StatusPermanentRedirect, maxAge: time.Hour},
+			expectedStatusCode:   http.StatusPermanentRedirect,
+			expectedLocation:     "https://www.google.com",
+			expectedCacheControl: "public, max-age=3600",
+			expectedVisits:       0,
+			expectedEvents:       []string{},
+		},
+		{
+			name: "visit tracking disabled",
+			shortLink: entity.ShortLink{
+				Alias:       "google",
+				LongLink:    "https://www.google.com",
+				TrackVisits: false,
+			},
+			alias:                "google",
+			expectedStatusCode:   http.StatusSeeOther,
+			expectedLocation:     "https://www.google.com",
+			expectedCacheControl: "no-cache",
+			expectedVisits:       0,
+			expectedEvents:       []string{},
+		},
+		{
+			name: "long link with fragment",
+			shortLink: entity.ShortLink{
+				Alias:       "app",
+				LongLink:    "https://example.com/app?tab=1#/settings",
+				TrackVisits: false,
+			},
+			alias:                "app",
+			expectedStatusCode:   http.StatusSeeOther,
+			expectedLocation:     "https://example.com/app?tab=1#/settings",
+			expectedCacheControl: "no-cache",
+			expectedVisits:       0,
+			expectedEvents:       []string{},
+		},
+		{
+			name: "redirect to canonical long link",
+			shortLink: entity.ShortLink{
+				Alias:            "docs",
+				LongLink:         "https://example.com/docs",
+				OriginalLongLink: "http://example.com/docs#intro",
+				TrackVisits:      false,
+			},
+			alias:                "docs",
+			expectedStatusCode:   http.StatusSeeOther,
+			expectedLocation:     "https://example.com/docs",
+			expectedCacheControl: "no-cache",
+			expectedVisits:       0,
+			expectedEvents:       []string{},
+		},
+		{
+			name: "ignore query without passthrough",
+			shortLink: entity.ShortLink{
+				Alias:       "promo",
+				LongLink:    "https://example.com/promo?id=1",
+				TrackVisits: false,
+			},
+			alias:                "promo",
+			query:                "?ref=x",
+			queryConflict:        shortlink.QueryConflictKeepLongLink,
+			expectedStatusCode:   http.StatusSeeOther,
+			expectedLocation:     "https://example.com/promo?id=1",
+			expectedCacheControl: "no-cache",
+			expectedVisits:       0,
+			expectedEvents:       []string{},
+		},
+		{
+			name: "pass query through",
+			shortLink: entity.ShortLink{
+				Alias:            "promo",
+				LongLink:         "https://example.com/promo?id=1",
+				TrackVisits:      false,
+				PassthroughQuery: true,
+			},
+			alias:                "promo",
+			query:                "?ref=x",
+			queryConflict:        shortlink.QueryConflictKeepLongLink,
+			expectedStatusCode:   http.StatusSeeOther,
+			expectedLocation:     "https://example.com/promo?id=1&ref=x",
+			expectedCacheControl: "no-cache",
+			expectedVisits:       0,
+			expectedEvents:       []string{},
+		},
+		{
+			name: "pass query through keeps conflicting query of long link",
+			shortLink: entity.ShortLink{
+				Alias:            "promo",
+				LongLink:         "https://example.com/promo?ref=owner",
+				TrackVisits:      false,
+				PassthroughQuery: true,
+			},
+			alias:                "promo",
+			query:                "?ref=x&lang=en",
+			queryConflict:        shortlink.QueryConflictKeepLongLink,
+			expectedStatusCode:   http.StatusSeeOther,
+			expectedLocation:     "https://example.com/promo?ref=owner&lang=en",
+			expectedCacheControl: "no-cache",
+			expectedVisits:       0,
+			expectedEvents:       []string{},
+		},
+		{
+			name: "pass query through overrides conflicting query of long link",
+			shortLink: entity.ShortLink{
+				Alias:            "promo",
+				LongLink:         "https://example.com/promo?ref=owner",
+				TrackVisits:      false,
+				PassthroughQuery: true,
+			},
+			alias:                "promo",
+			query:                "?ref=x&lang=en",
+			queryConflict:        shortlink.QueryConflictOverride,
+			expectedStatusCode:   http.StatusSeeOther,
+			expectedLocation:     "https://example.com/promo?lang=en&ref=x",
+			expectedCacheControl: "no-cache",
+			expectedVisits:       0,
+			expectedEvents:       []string{},
+		},
+		{
+			name: "pass path through",
+			shortLink: entity.ShortLink{
+				Alias:           "docs",
+				LongLink:        "https://example.com/docs/",
+				TrackVisits:     false,
+				PassthroughPath: true,
+			},
+			alias:                "docs",
+			query:                "/guide/intro",
+			queryConflict:        shortlink.QueryConflictKeepLongLink,
+			expectedStatusCode:   http.StatusSeeOther,
+			expectedLocation:     "https://example.com/docs/guide/intro",
+			expectedCacheControl: "no-cache",
+			expectedVisits:       0,
+			expectedEvents:       []string{},
+		},
+		{
+			name: "pass path through with query",
+			shortLink: entity.ShortLink{
+				Alias:           "docs",
+				LongLink:        "https://example.com/docs?lang=en&id=1#top",
+				TrackVisits:     false,
+				PassthroughPath: true,
+			},
+			alias:                "docs",
+			query:                "//guide?lang=fr&ref=x",
+			queryConflict:        shortlink.QueryConflictKeepLongLink,
+			expectedStatusCode:   http.StatusSeeOther,
+			expectedLocation:     "https://example.com/docs/guide?lang=en&id=1&ref=x#top",
+			expectedCacheControl: "no-cache",
+			expectedVisits:       0,
+			expectedEvents:       []string{},
+		},
+		{
+			name: "pass path through overrides conflicting query of long link",
+			shortLink: entity.ShortLink{
+				Alias:           "docs",
+				LongLink:        "https://example.com/docs?lang=en",
+				TrackVisits:     false,
+				PassthroughPath: true,
+			},
+			alias:                "docs",
+			query:                "/guide?lang=fr",
+			queryConflict:        shortlink.QueryConflictOverride,
+			expectedStatusCode:   http.StatusSeeOther,
+			expectedLocation:     "https://example.com/docs/guide?lang=fr",
+			expectedCacheControl: "no-cache",
+			expectedVisits:       0,
+			expectedEvents:       []string{},
+		},
+		{
+			name: "trailing slash without path passthrough",
+			shortLink: entity.ShortLink{
+				Alias:       "docs",
+				LongLink:    "https://example.com/docs",
+				TrackVisits: false,
+			},
+			alias:                "docs",
+			query:                "/",
+			queryConflict:        shortlink.QueryConflictKeepLongLink,
+			expectedStatusCode:   http.StatusSeeOther,
+			expectedLocation:     "https://example.com/docs",
+			expectedCacheControl: "no-cache",
+			expectedVisits:       0,
+			expectedEvents:       []string{},
+		},
+		{
+			name: "extra path without path passthrough",
+			shortLink: entity.ShortLink{
+				Alias:            "docs",
+				LongLink:         "https://example.com/docs",
+				TrackVisits:      true,
+				PassthroughQuery: true,
+			},
+			alias:              "docs",
+			query:              "/guide",
+			queryConflict:      shortlink.QueryConflictKeepLongLink,
+			expectedStatusCode: http.StatusNotFound,
+			expectedVisits:     0,
+			expectedEvents:     []string{},
+		},
+		{
+			name: "alias not found",
+			shortLink: entity.ShortLink{
+				Alias:       "google",
+				LongLink:    "https://www.google.com",
+				TrackVisits: true,
+			},
+			alias:              "facebook",
+			expectedStatusCode: http.StatusNotFound,
+			expectedVisits:     0,
+			expectedEvents: []string{
+				"RedirectingAliasToLongLink",
+			},
+		},
+		{
+			name: "short link expired",
+			shortLink: entity.ShortLink{
+				Alias:       "google",
+				LongLink:    "https://www.google.com",
+				ExpireAt:    &before,
+				TrackVisits: true,
+			},
+			alias:              "google",
+			expectedStatusCode: http.StatusGone,
+			expectedVisits:     0,
+			expectedEvents: []string{
+				"RedirectingAliasToLongLink",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			tm := timer.NewStub(now)
+			entryRepo := logger.NewEntryRepoFake()
+			lg, err := logger.NewFake(logger.LogOff, &entryRepo)
+			assert.Equal(t, nil, err)
+
+			keyFetcher := keygen.NewKeyFetcherFake([]keygen.Key{"request"})
+			keyGen, err := keygen.NewRemote(1, &keyFetcher)
+			assert.Equal(t, nil, err)
+
+			geo := visit.NewGeoFake(nil)
+			analytics := analyticsRecorder{events: make(chan string)}
+			instrumentationFactory := request.NewInstrumentationFactory(
+				lg,
+				tm,
+				metrics.NewFake(),
+				analytics,
+				keyGen,
+				request.NewClient(network.NewProxy(), geo),
+			)
+
+			userShortLinkRepo := repository.NewUserShortLinkRepoFake(nil, nil)
+			shortLinkRepo := repository.NewShortLinkFake(&userShortLinkRepo, map[string]entity.ShortLink{
+				testCase.shortLink.Alias: testCase.shortLink,
+			})
+			visitRepo := repository.NewVisitFake([]entity.Visit{})
+			visitTracker := visit.NewTrackerPersist(
+				tm,
+				visit.Details{},
+				visit.NewUserAgentParserFake(nil),
+				visit.NewBuffer(&visitRepo, geo, visit.IPModeNone, tm, lg, 0, 0),
+				visit.CountBuffer{},
+			)
+
+			handle := LongLink(
+				instrumentationFactory,
+				shortlink.NewRetrieverPersist(&shortLinkRepo, &userShortLinkRepo, 0),
+				visitTracker,
+				network.NewProxy(),
+				ratelimit.NewMemory(tm, 0, time.Minute),
+				ratelimit.NewMemory(tm, 0, time.Minute),
+				tm,
+				url.URL{Scheme: "https", Host: "short-d.com"},
+				ErrorPages{},
+				share.Signer{},
+				testCase.queryConflict,
+				testCase.redirect,
+				CanonicalDomain{},
+				authenticator.NewAuthenticatorFake(now, time.Hour),
+			)
+
+			alias := testCase.alias
+			req := httptest.NewRequest(http.MethodGet, "/r/"+alias+testCase.query, nil)
+			w := httptest.NewRecorder()
+			handle(w, req, router.Params{"alias": alias})
+
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedLocation, w.Header().Get("Location"))
+			assert.Equal(t, testCase.expectedCacheControl, w.Header().Get("Cache-Control"))
+
+			visits, err := visitRepo.FindVisitsByAlias(context.Background(), alias, now, now.Add(time.Second))
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedVisits, len(visits))
+
+			events := []string{}
+			for len(events) < len(testCase.expectedEvents) {
+				select {
+				case event := <-analytics.events:
+					events = append(events, event)
+				case <-time.After(time.Second):
+					t.Fatalf("expect events %v, got %v", testCase.expectedEvents, events)
+				}
+			}
+			assert.SameElements(t, testCase.expectedEvents, events)
+
+			select {
+			case event := <-analytics.events:
+				t.Fatalf("unexpected event %s", event)
+			case <-time.After(100 * time.Millisecond):
+			}
+		})
+	}
+}
+
+func TestLongLink_SignedAlias(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	signer := share.NewSigner("secret")
+	signedAlias := signer.Sign("google")
+	signature := strings.TrimPrefix(signedAlias, "google")
+
+	testCases := []struct {
+		name               string
+		signer             share.Signer
+		alias              string
+		expectedStatusCode int
+		expectedLocation   string
+	}{
+		{
+			name:               "signing disabled",
+			signer:             share.Signer{},
+			alias:              "google",
+			expectedStatusCode: http.StatusSeeOther,
+			expectedLocation:   "https://www.google.com",
+		},
+		{
+			name:               "valid signature",
+			signer:             signer,
+			alias:              signedAlias,
+			expectedStatusCode: http.StatusSeeOther,
+			expectedLocation:   "https://www.google.com",
+		},
+		{
+			name:               "tampered alias",
+			signer:             signer,
+			alias:              "googlf" + signature,
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			name:               "tampered signature",
+			signer:             signer,
+			alias:              signedAlias + "A",
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			name:               "signed with another secret",
+			signer:             signer,
+			alias:              share.NewSigner("guessed").Sign("google"),
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			name:               "unsigned alias",
+			signer:             signer,
+			alias:              "google",
+			expectedStatusCode: http.StatusForbidden,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			tm := timer.NewStub(now)
+			entryRepo := logger.NewEntryRepoFake()
+			lg, err := logger.NewFake(logger.LogOff, &entryRepo)
+			assert.Equal(t, nil, err)
+
+			keyFetcher := keygen.NewKeyFetcherFake([]keygen.Key{"request"})
+			keyGen, err := keygen.NewRemote(1, &keyFetcher)
+			assert.Equal(t, nil, err)
+
+			geo := visit.NewGeoFake(nil)
+			instrumentationFactory := request.NewInstrumentationFactory(
+				lg,
+				tm,
+				metrics.NewFake(),
+				analyticsRecorder{events: make(chan string, 2)},
+				keyGen,
+				request.NewClient(network.NewProxy(), geo),
+			)
+
+			userShortLinkRepo := repository.NewUserShortLinkRepoFake(nil, nil)
+			shortLinkRepo := repository.NewShortLinkFake(&userShortLinkRepo, map[string]entity.ShortLink{
+				"google": {Alias: "google", LongLink: "https://www.google.com"},
+				"googlf": {Alias: "googlf", LongLink: "https://www.bing.com"},
+			})
+			visitRepo := repository.NewVisitFake([]entity.Visit{})
+			visitTracker := visit.NewTrackerPersist(
+				tm,
+				visit.Details{},
+				visit.NewUserAgentParserFake(nil),
+				visit.NewBuffer(&visitRepo, geo, visit.IPModeNone, tm, lg, 0, 0),
+				visit.CountBuffer{},
+			)
+
+			handle := LongLink(
+				instrumentationFactory,
+				shortlink.NewRetrieverPersist(&shortLinkRepo, &userShortLinkRepo, 0),
+				visitTracker,
+				network.NewProxy(),
+				ratelimit.NewMemory(tm, 0, time.Minute),
+				ratelimit.NewMemory(tm, 0, time.Minute),
+				tm,
+				url.URL{Scheme: "https", Host: "short-d.com"},
+				ErrorPages{},
+				testCase.signer,
+				shortlink.QueryConflictKeepLongLink,
+				Redirect{},
+				CanonicalDomain{},
+				authenticator.NewAuthenticatorFake(now, time.Hour),
+			)
+
+			req := httptest.NewRequest(http.MethodGet, "/r/"+testCase.alias, nil)
+			w := httptest.NewRecorder()
+			handle(w, req, router.Params{"alias": testCase.alias})
+
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedLocation, w.Header().Get("Location"))
+		})
+	}
+}
+
+func TestLongLink_MaxRedirectsPerIP(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	type redirect struct {
+		tenantID           string
+		clientIP           string
+		expectedStatusCode int
+	}
+
+	testCases := []struct {
+		name              string
+		maxRedirectsPerIP int
+		redirects         []redirect
+		expectedVisits    int
+	}{
+		{
+			name:              "unlimited",
+			maxRedirectsPerIP: 0,
+			redirects: []redirect{
+				{clientIP: "1.1.1.1", expectedStatusCode: http.StatusSeeOther},
+				{clientIP: "1.1.1.1", expectedStatusCode: http.StatusSeeOther},
+				{clientIP: "1.1.1.1", expectedStatusCode: http.StatusSeeOther},
+			},
+			expectedVisits: 3,
+		},
+		{
+			name:              "IP hits the cap",
+			maxRedirectsPerIP: 2,
+			redirects: []redirect{
+				{clientIP: "1.1.1.1", expectedStatusCode: http.StatusSeeOther},
+				{clientIP: "1.1.1.1", expectedStatusCode: http.StatusSeeOther},
+				{clientIP: "1.1.1.1", expectedStatusCode: http.StatusTooManyRequests},
+				{clientIP: "2.2.2.2", expectedStatusCode: http.StatusSeeOther},
+				{clientIP: "1.1.1.1", expectedStatusCode: http.StatusTooManyRequests},
+			},
+			expectedVisits: 3,
+		},
+		{
+			name:              "tenants limited separately",
+			maxRedirectsPerIP: 1,
+			redirects: []redirect{
+				{clientIP: "1.1.1.1", expectedStatusCode: http.StatusSeeOther},
+				{clientIP: "1.1.1.1", expectedStatusCode: http.StatusTooManyRequests},
+				{tenantID: "acme", clientIP: "1.1.1.1", expectedStatusCode: http.StatusSeeOther},
+			},
+			expectedVisits: 1,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			tm := timer.NewStub(now)
+			entryRepo := logger.NewEntryRepoFake()
+			lg, err := logger.NewFake(logger.LogOff, &entryRepo)
+			assert.Equal(t, nil, err)
+
+			keyFetcher := keygen.NewKeyFetcherFake([]keygen.Key{"request"})
+			keyGen, err := keygen.NewRemote(1, &keyFetcher)
+			assert.Equal(t, nil, err)
+
+			geo := visit.NewGeoFake(nil)
+			instrumentationFactory := request.NewInstrumentationFactory(
+				lg,
+				tm,
+				metrics.NewFake(),
+				analyticsRecorder{events: make(chan string, 2*len(testCase.redirects))},
+				keyGen,
+				request.NewClient(network.NewProxy(), geo),
+			)
+
+			userShortLinkRepo := repository.NewUserShortLinkRepoFake(nil, nil)
+			shortLinkRepo := repository.NewShortLinkFake(&userShortLinkRepo, map[string]entity.ShortLink{
+				"promo": {
+					Alias:             "promo",
+					LongLink:          "https://www.google.com",
+					TrackVisits:       true,
+					MaxRedirectsPerIP: testCase.maxRedirectsPerIP,
+				},
+			})
+			err = shortLinkRepo.CreateShortLink(tenant.NewContext(context.Background(), "acme"), entity.ShortLinkInput{
+				CustomAlias:       ptr.String("promo"),
+				LongLink:          ptr.String("https://www.google.com"),
+				MaxRedirectsPerIP: &testCase.maxRedirectsPerIP,
+			})
+			assert.Equal(t, nil, err)
+			visitRepo := repository.NewVisitFake([]entity.Visit{})
+			visitTracker := visit.NewTrackerPersist(
+				tm,
+				visit.Details{},
+				visit.NewUserAgentParserFake(nil),
+				visit.NewBuffer(&visitRepo, geo, visit.IPModeNone, tm, lg, 0, 0),
+				visit.CountBuffer{},
+			)
+
+			handle := LongLink(
+				instrumentationFactory,
+				shortlink.NewRetrieverPersist(&shortLinkRepo, &userShortLinkRepo, 0),
+				visitTracker,
+				network.NewProxy(),
+				ratelimit.NewMemory(tm, 0, time.Minute),
+				ratelimit.NewMemory(tm, 0, time.Minute),
+				tm,
+				url.URL{Scheme: "https", Host: "short-d.com"},
+				ErrorPages{},
+				share.Signer{},
+				shortlink.QueryConflictKeepLongLink,
+				Redirect{},
+				CanonicalDomain{},
+				authenticator.NewAuthenticatorFake(now, time.Hour),
+			)
+
+			for _, redirect := range testCase.redirects {
+				req := httptest.NewRequest(http.MethodGet, "/r/promo", nil)
+				req = req.WithContext(tenant.NewContext(req.Context(), redirect.tenantID))
+				req.Header.Set("X-Forwarded-For", redirect.clientIP)
+				w := httptest.NewRecorder()
+				handle(w, req, router.Params{"alias": "promo"})
+
+				assert.Equal(t, redirect.expectedStatusCode, w.Code)
+			}
+
+			visits, err := visitRepo.FindVisitsByAlias(context.Background(), "promo", now, now.Add(time.Second))
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedVisits, len(visits))
+		})
+	}
+}
+
+func TestLongLink_OneTime(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	expireAt := now.Add(shortlink.OneTimeLifetime)
+	const visitors = 10
+
+	tm := timer.NewStub(now)
+	entryRepo := logger.NewEntryRepoFake()
+	lg, err := logger.NewFake(logger.LogOff, &entryRepo)
+	assert.Equal(t, nil, err)
+
+	userShortLinkRepo := repository.NewUserShortLinkRepoFake(nil, nil)
+	shortLinkRepo := repository.NewShortLinkFake(&userShortLinkRepo, map[string]entity.ShortLink{
+		"secret": {
+			Alias:     "secret",
+			LongLink:  "https://www.google.com",
+			ExpireAt:  &expireAt,
+			MaxVisits: 1,
+		},
+	})
+
+	// The request IDs are generated concurrently.
+	keyGen := keygen.NewCryptoRandom(&shortLinkRepo, "")
+	geo := visit.NewGeoFake(nil)
+	instrumentationFactory := request.NewInstrumentationFactory(
+		lg,
+		tm,
+		metrics.NewFake(),
+		analyticsRecorder{events: make(chan string, 2*(visitors+1))},
+		keyGen,
+		request.NewClient(network.NewProxy(), geo),
+	)
+	visitRepo := repository.NewVisitFake([]entity.Visit{})
+	visitTracker := visit.NewTrackerPersist(
+		tm,
+		visit.Details{},
+		visit.NewUserAgentParserFake(nil),
+		visit.NewBuffer(&visitRepo, geo, visit.IPModeNone, tm, lg, 0, 0),
+		visit.CountBuffer{},
+	)
+	redirect, err := NewRedirect(http.StatusMovedPermanently, time.Hour, false)
+	assert.Equal(t, nil, err)
+
+	handle := LongLink(
+		instrumentationFactory,
+		shortlink.NewRetrieverPersist(&shortLinkRepo, &userShortLinkRepo, 0),
+		visitTracker,
+		network.NewProxy(),
+		ratelimit.NewMemory(tm, 0, time.Minute),
+		ratelimit.NewMemory(tm, 0, time.Minute),
+		tm,
+		url.URL{Scheme: "https", Host: "short-d.com"},
+		ErrorPages{},
+		share.Signer{},
+		shortlink.QueryConflictKeepLongLink,
+		redirect,
+		CanonicalDomain{},
+		authenticator.NewAuthenticatorFake(now, time.Hour),
+	)
+
+	resolve := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/r/secret", nil)
+		w := httptest.NewRecorder()
+		handle(w, req, router.Params{"alias": "secret"})
+		return w
+	}
+
+	responses := make(chan *httptest.ResponseRecorder, visitors)
+	for idx := 0; idx < visitors; idx++ {
+		go func() {
+			responses <- resolve()
+		}()
+	}
+
+	redirected := 0
+	for idx := 0; idx < visitors; idx++ {
+		w := <-responses
+		if w.Code == http.StatusGone {
+			continue
+		}
+		redirected++
+		assert.Equal(t, http.StatusMovedPermanently, w.Code)
+		assert.Equal(t, "https://www.google.com", w.Header().Get("Location"))
+		assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	}
+	assert.Equal(t, 1, redirected)
+
+	w := resolve()
+	assert.Equal(t, http.StatusGone, w.Code)
+}
+
+func TestLongLink_CanonicalDomain(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name               string
+		requestURL         string
+		expectedStatusCode int
+		expectedLocation   string
+		expectedVisits     int
+	}{
+		{
+			name:               "canonical domain",
+			requestURL:         "https://s.short-d.com/r/promo?utm_source=email",
+			expectedStatusCode: http.StatusSeeOther,
+			expectedLocation:   "https://www.google.com?utm_source=email",
+			expectedVisits:     1,
+		},
+		{
+			name:               "aliased domain",
+			requestURL:         "https://www.s.short-d.com/r/promo?utm_source=email",
+			expectedStatusCode: http.StatusMovedPermanently,
+			expectedLocation:   "https://s.short-d.com/r/promo?utm_source=email",
+			expectedVisits:     0,
+		},
+		{
+			name:               "old domain",
+			requestURL:         "http://old-short.com/r/promo",
+			expectedStatusCode: http.StatusMovedPermanently,
+			expectedLocation:   "https://s.short-d.com/r/promo",
+			expectedVisits:     0,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			tm := timer.NewStub(now)
+			entryRepo := logger.NewEntryRepoFake()
+			lg, err := logger.NewFake(logger.LogOff, &entryRepo)
+			assert.Equal(t, nil, err)
+
+			keyFetcher := keygen.NewKeyFetcherFake([]keygen.Key{"request"})
+			keyGen, err := keygen.NewRemote(1, &keyFetcher)
+			assert.Equal(t, nil, err)
+
+			geo := visit.NewGeoFake(nil)
+			instrumentationFactory := request.NewInstrumentationFactory(
+				lg,
+				tm,
+				metrics.NewFake(),
+				analyticsRecorder{events: make(chan string, 2)},
+				keyGen,
+				request.NewClient(network.NewProxy(), geo),
+			)
+
+			userShortLinkRepo := repository.NewUserShortLinkRepoFake(nil, nil)
+			shortLinkRepo := repository.NewShortLinkFake(&userShortLinkRepo, map[string]entity.ShortLink{
+				"promo": {
+					Alias:            "promo",
+					LongLink:         "https://www.google.com",
+					TrackVisits:      true,
+					PassthroughQuery: true,
+				},
+			})
+			visitRepo := repository.NewVisitFake([]entity.Visit{})
+			visitTracker := visit.NewTrackerPersist(
+				tm,
+				visit.Details{},
+				visit.NewUserAgentParserFake(nil),
+				visit.NewBuffer(&visitRepo, geo, visit.IPModeNone, tm, lg, 0, 0),
+				visit.CountBuffer{},
+			)
+
+			canonicalDomain, err := NewCanonicalDomain([]string{
+				"www.s.short-d.com=https://s.short-d.com",
+				"old-short.com=https://s.short-d.com",
+			})
+			assert.Equal(t, nil, err)
+
+			handle := LongLink(
+				instrumentationFactory,
+				shortlink.NewRetrieverPersist(&shortLinkRepo, &userShortLinkRepo, 0),
+				visitTracker,
+				network.NewProxy(),
+				ratelimit.NewMemory(tm, 0, time.Minute),
+				ratelimit.NewMemory(tm, 0, time.Minute),
+				tm,
+				url.URL{Scheme: "https", Host: "short-d.com"},
+				ErrorPages{},
+				share.Signer{},
+				shortlink.QueryConflictKeepLongLink,
+				Redirect{},
+				canonicalDomain,
+				authenticator.NewAuthenticatorFake(now, time.Hour),
+			)
+
+			req := httptest.NewRequest(http.MethodGet, testCase.requestURL, nil)
+			w := httptest.NewRecorder()
+			handle(w, req, router.Params{"alias": "promo"})
+
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedLocation, w.Header().Get("Location"))
+
+			visits, err := visitRepo.FindVisitsByAlias(context.Background(), "promo", now, now.Add(time.Second))
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedVisits, len(visits))
+		})
+	}
+}
+
+func TestLongLink_ExpiryGrace(t *testing.T) {
+	t.Parallel()
+
+	expireAt := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name               string
+		now                time.Time
+		interstitial       bool
+		expectedStatusCode int
+		expectedLocation   string
+		expectedWarning    string
+		expectedBody       string
+		expectedVisits     int
+	}{
+		{
+			name:               "active",
+			now:                expireAt.Add(-time.Minute),
+			expectedStatusCode: http.StatusSeeOther,
+			expectedLocation:   "https://www.google.com",
+			expectedVisits:     1,
+		},
+		{
+			name:               "expired within grace window",
+			now:                expireAt.Add(30 * time.Minute),
+			expectedStatusCode: http.StatusSeeOther,
+			expectedLocation:   "https://www.google.com",
+			expectedWarning:    `299 - "short link expired at 2020-06-01T00:00:00Z"`,
+			expectedVisits:     1,
+		},
+		{
+			name:               "expired within grace window with interstitial",
+			now:                expireAt.Add(30 * time.Minute),
+			interstitial:       true,
+			expectedStatusCode: http.StatusOK,
+			expectedWarning:    `299 - "short link expired at 2020-06-01T00:00:00Z"`,
+			expectedBody:       `<a href="https://www.google.com" rel="noreferrer">`,
+			expectedVisits:     1,
+		},
+		{
+			name:               "expired beyond grace window",
+			now:                expireAt.Add(time.Hour + time.Minute),
+			interstitial:       true,
+			expectedStatusCode: http.StatusGone,
+			expectedVisits:     0,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			tm := timer.NewStub(testCase.now)
+			entryRepo := logger.NewEntryRepoFake()
+			lg, err := logger.NewFake(logger.LogOff, &entryRepo)
+			assert.Equal(t, nil, err)
+
+			keyFetcher := keygen.NewKeyFetcherFake([]keygen.Key{"request"})
+			keyGen, err := keygen.NewRemote(1, &keyFetcher)
+			assert.Equal(t, nil, err)
+
+			geo := visit.NewGeoFake(nil)
+			instrumentationFactory := request.NewInstrumentationFactory(
+				lg,
+				tm,
+				metrics.NewFake(),
+				analyticsRecorder{events: make(chan string, 2)},
+				keyGen,
+				request.NewClient(network.NewProxy(), geo),
+			)
+
+			userShortLinkRepo := repository.NewUserShortLinkRepoFake(nil, nil)
+			shortLinkRepo := repository.NewShortLinkFake(&userShortLinkRepo, map[string]entity.ShortLink{
+				"promo": {
+					Alias:       "promo",
+					LongLink:    "https://www.google.com",
+					ExpireAt:    &expireAt,
+					TrackVisits: true,
+				},
+			})
+			visitRepo := repository.NewVisitFake([]entity.Visit{})
+			visitTracker := visit.NewTrackerPersist(
+				tm,
+				visit.Details{},
+				visit.NewUserAgentParserFake(nil),
+				visit.NewBuffer(&visitRepo, geo, visit.IPModeNone, tm, lg, 0, 0),
+				visit.CountBuffer{},
+			)
+
+			handle := LongLink(
+				instrumentationFactory,
+				shortlink.NewRetrieverPersist(&shortLinkRepo, &userShortLinkRepo, time.Hour),
+				visitTracker,
+				network.NewProxy(),
+				ratelimit.NewMemory(tm, 0, time.Minute),
+				ratelimit.NewMemory(tm, 0, time.Minute),
+				tm,
+				url.URL{Scheme: "https", Host: "short-d.com"},
+				ErrorPages{Interstitial: testCase.interstitial},
+				share.Signer{},
+				shortlink.QueryConflictKeepLongLink,
+				Redirect{},
+				CanonicalDomain{},
+				authenticator.NewAuthenticatorFake(testCase.now, time.Hour),
+			)
+
+			req := httptest.NewRequest(http.MethodGet, "/r/promo", nil)
+			w := httptest.NewRecorder()
+			handle(w, req, router.Params{"alias": "promo"})
+
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedLocation, w.Header().Get("Location"))
+			assert.Equal(t, testCase.expectedWarning, w.Header().Get("Warning"))
+			assert.Equal(t, true, strings.Contains(w.Body.String(), testCase.expectedBody))
+
+			visits, err := visitRepo.FindVisitsByAlias(context.Background(), "promo", testCase.now, testCase.now.Add(time.Second))
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedVisits, len(visits))
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package handle
+
+import (
+	"net/http"
+	"net/http/pprof"
+
+	"github.com/short-d/app/fw/router"
+	"github.com/short-d/short/backend/app/usecase/authenticator"
+	"github.com/short-d/short/backend/app/usecase/authorizer"
+)
+
+// ProfilePathPrefix is the path under which the runtime profiles of the
+// service are served.
+const ProfilePathPrefix = "/api/v1/admin/debug/pprof"
+
+const profileMountPath = "/api/v1/admin"
+
+// Profile serves the runtime profiles of the service to admins in the format
+// expected by "go tool pprof". The profiles are only served when isEnabled
+// is true.
+func Profile(
+	authenticator authenticator.Authenticator,
+	authorizer authorizer.Authorizer,
+	isEnabled bool,
+) router.Handle {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	profiles := http.StripPrefix(profileMountPath, mux)
+
+	return func(w http.ResponseWriter, r *http.Request, params router.Params) {
+		if !isEnabled {
+			http.NotFound(w, r)
+			return
+		}
+
+		user := getUser(r, authenticator)
+		if user == nil {
+			http.Error(w, "invalid auth token", http.StatusUnauthorized)
+			return
+		}
+
+		canView, err := authorizer.CanViewProfile(*user)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !canView {
+			http.Error(w, "not allowed to view profiles", http.StatusForbidden)
+			return
+		}
+		profiles.ServeHTTP(w, r)
+	}
+}
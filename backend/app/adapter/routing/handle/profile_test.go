@@ -0,0 +1,102 @@
+// +build !integration all
+
+package handle
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/router"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/authenticator"
+	"github.com/short-d/short/backend/app/usecase/authorizer"
+	"github.com/short-d/short/backend/app/usecase/authorizer/rbac"
+	"github.com/short-d/short/backend/app/usecase/authorizer/rbac/role"
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+func TestProfile(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	admin := entity.User{ID: "alpha"}
+	basic := entity.User{ID: "beta"}
+
+	testCases := []struct {
+		name               string
+		isEnabled          bool
+		user               *entity.User
+		path               string
+		expectedStatusCode int
+	}{
+		{
+			name:               "admin views profile index",
+			isEnabled:          true,
+			user:               &admin,
+			path:               "/api/v1/admin/debug/pprof/",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "admin views heap profile",
+			isEnabled:          true,
+			user:               &admin,
+			path:               "/api/v1/admin/debug/pprof/heap",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "admin views command line",
+			isEnabled:          true,
+			user:               &admin,
+			path:               "/api/v1/admin/debug/pprof/cmdline",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "user without admin role",
+			isEnabled:          true,
+			user:               &basic,
+			path:               "/api/v1/admin/debug/pprof/",
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			name:               "user not signed in",
+			isEnabled:          true,
+			path:               "/api/v1/admin/debug/pprof/",
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:               "profiling disabled",
+			isEnabled:          false,
+			user:               &admin,
+			path:               "/api/v1/admin/debug/pprof/",
+			expectedStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			auth := authenticator.NewAuthenticatorFake(now, time.Hour)
+			userRoleRepo := repository.NewUserRoleFake(map[string][]role.Role{
+				admin.ID: {role.Basic, role.Admin},
+				basic.ID: {role.Basic},
+			})
+			authorizer := authorizer.NewAuthorizer(rbac.NewRBAC(userRoleRepo))
+
+			req := httptest.NewRequest(http.MethodGet, testCase.path, nil)
+			if testCase.user != nil {
+				authToken, err := auth.GenerateToken(*testCase.user)
+				assert.Equal(t, nil, err)
+				req.Header.Set("Authorization", "Bearer "+authToken)
+			}
+			w := httptest.NewRecorder()
+
+			Profile(auth, authorizer, testCase.isEnabled)(w, req, router.Params{})
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+		})
+	}
+}
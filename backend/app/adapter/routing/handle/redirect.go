@@ -0,0 +1,90 @@
+package handle
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/short-d/short/backend/app/entity"
+)
+
+// ErrUnsupportedRedirectStatus represents the status code can't be used to
+// redirect users to long links.
+type ErrUnsupportedRedirectStatus int
+
+func (e ErrUnsupportedRedirectStatus) Error() string {
+	return fmt.Sprintf("unsupported redirect status code: %d", int(e))
+}
+
+// Redirect decides the status code of the redirects to long links and how
+// long browsers may cache them. The zero value of Redirect responds with 303
+// See Other without caching.
+type Redirect struct {
+	statusCode    int
+	maxAge        time.Duration
+	editableLinks bool
+}
+
+// StatusCode retrieves the status code of the redirects.
+func (r Redirect) StatusCode() int {
+	if r.statusCode == 0 {
+		return http.StatusSeeOther
+	}
+	return r.statusCode
+}
+
+// CacheControl decides the Cache-Control header of the redirect to the long
+// link. Temporary redirects and the redirects of editable short links are
+// revalidated on every visit, so that the changes to the long links take
+// effect immediately. Permanent redirects of short links which can't be
+// edited are cached up to maxAge, but never beyond the expiration of the
+// short link. The redirects of the short links limiting the total visits are
+// never stored, so that every visit reaches the server.
+func (r Redirect) CacheControl(shortLink entity.ShortLink, now time.Time) string {
+	if shortLink.MaxVisits > 0 {
+		return "no-store"
+	}
+	if !isPermanentRedirect(r.StatusCode()) || r.editableLinks {
+		return "no-cache"
+	}
+
+	maxAge := r.maxAge
+	if shortLink.ExpireAt != nil {
+		untilExpire := shortLink.ExpireAt.Sub(now)
+		if untilExpire < maxAge {
+			maxAge = untilExpire
+		}
+	}
+
+	seconds := int64(maxAge / time.Second)
+	if seconds <= 0 {
+		return "no-cache"
+	}
+	return fmt.Sprintf("public, max-age=%d", seconds)
+}
+
+func isPermanentRedirect(statusCode int) bool {
+	return statusCode == http.StatusMovedPermanently ||
+		statusCode == http.StatusPermanentRedirect
+}
+
+// NewRedirect creates Redirect. Zero statusCode redirects with 303 See Other.
+// maxAge only applies to permanent redirects when the short links can't be
+// edited.
+func NewRedirect(statusCode int, maxAge time.Duration, editableLinks bool) (Redirect, error) {
+	switch statusCode {
+	case 0,
+		http.StatusMovedPermanently,
+		http.StatusFound,
+		http.StatusSeeOther,
+		http.StatusTemporaryRedirect,
+		http.StatusPermanentRedirect:
+	default:
+		return Redirect{}, ErrUnsupportedRedirectStatus(statusCode)
+	}
+	return Redirect{
+		statusCode:    statusCode,
+		maxAge:        maxAge,
+		editableLinks: editableLinks,
+	}, nil
+}
@@ -0,0 +1,190 @@
+// +build !integration all
+
+package handle
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/short/backend/app/entity"
+)
+
+func TestRedirect_CacheControl(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	inTenMinutes := now.Add(10 * time.Minute)
+	expired := now.Add(-time.Minute)
+
+	testCases := []struct {
+		name                 string
+		statusCode           int
+		maxAge               time.Duration
+		editableLinks        bool
+		shortLink            entity.ShortLink
+		expectedStatusCode   int
+		expectedCacheControl string
+	}{
+		{
+			name:                 "default redirect",
+			statusCode:           0,
+			maxAge:               time.Hour,
+			editableLinks:        false,
+			shortLink:            entity.ShortLink{Alias: "google"},
+			expectedStatusCode:   http.StatusSeeOther,
+			expectedCacheControl: "no-cache",
+		},
+		{
+			name:                 "302 non-editable link",
+			statusCode:           http.StatusFound,
+			maxAge:               time.Hour,
+			editableLinks:        false,
+			shortLink:            entity.ShortLink{Alias: "google"},
+			expectedStatusCode:   http.StatusFound,
+			expectedCacheControl: "no-cache",
+		},
+		{
+			name:                 "307 non-editable link",
+			statusCode:           http.StatusTemporaryRedirect,
+			maxAge:               time.Hour,
+			editableLinks:        false,
+			shortLink:            entity.ShortLink{Alias: "google"},
+			expectedStatusCode:   http.StatusTemporaryRedirect,
+			expectedCacheControl: "no-cache",
+		},
+		{
+			name:                 "307 editable link",
+			statusCode:           http.StatusTemporaryRedirect,
+			maxAge:               time.Hour,
+			editableLinks:        true,
+			shortLink:            entity.ShortLink{Alias: "google"},
+			expectedStatusCode:   http.StatusTemporaryRedirect,
+			expectedCacheControl: "no-cache",
+		},
+		{
+			name:                 "301 editable link",
+			statusCode:           http.StatusMovedPermanently,
+			maxAge:               time.Hour,
+			editableLinks:        true,
+			shortLink:            entity.ShortLink{Alias: "google"},
+			expectedStatusCode:   http.StatusMovedPermanently,
+			expectedCacheControl: "no-cache",
+		},
+		{
+			name:                 "308 editable link",
+			statusCode:           http.StatusPermanentRedirect,
+			maxAge:               time.Hour,
+			editableLinks:        true,
+			shortLink:            entity.ShortLink{Alias: "google"},
+			expectedStatusCode:   http.StatusPermanentRedirect,
+			expectedCacheControl: "no-cache",
+		},
+		{
+			name:                 "301 non-editable link",
+			statusCode:           http.StatusMovedPermanently,
+			maxAge:               time.Hour,
+			editableLinks:        false,
+			shortLink:            entity.ShortLink{Alias: "google"},
+			expectedStatusCode:   http.StatusMovedPermanently,
+			expectedCacheControl: "public, max-age=3600",
+		},
+		{
+			name:                 "308 non-editable link",
+			statusCode:           http.StatusPermanentRedirect,
+			maxAge:               24 * time.Hour,
+			editableLinks:        false,
+			shortLink:            entity.ShortLink{Alias: "google"},
+			expectedStatusCode:   http.StatusPermanentRedirect,
+			expectedCacheControl: "public, max-age=86400",
+		},
+		{
+			name:                 "308 non-editable link without max age",
+			statusCode:           http.StatusPermanentRedirect,
+			maxAge:               0,
+			editableLinks:        false,
+			shortLink:            entity.ShortLink{Alias: "google"},
+			expectedStatusCode:   http.StatusPermanentRedirect,
+			expectedCacheControl: "no-cache",
+		},
+		{
+			name:                 "max age capped by expiration",
+			statusCode:           http.StatusPermanentRedirect,
+			maxAge:               time.Hour,
+			editableLinks:        false,
+			shortLink:            entity.ShortLink{Alias: "google", ExpireAt: &inTenMinutes},
+			expectedStatusCode:   http.StatusPermanentRedirect,
+			expectedCacheControl: "public, max-age=600",
+		},
+		{
+			name:                 "expiration after max age",
+			statusCode:           http.StatusMovedPermanently,
+			maxAge:               time.Minute,
+			editableLinks:        false,
+			shortLink:            entity.ShortLink{Alias: "google", ExpireAt: &inTenMinutes},
+			expectedStatusCode:   http.StatusMovedPermanently,
+			expectedCacheControl: "public, max-age=60",
+		},
+		{
+			name:                 "expired link",
+			statusCode:           http.StatusMovedPermanently,
+			maxAge:               time.Hour,
+			editableLinks:        false,
+			shortLink:            entity.ShortLink{Alias: "google", ExpireAt: &expired},
+			expectedStatusCode:   http.StatusMovedPermanently,
+			expectedCacheControl: "no-cache",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			redirect, err := NewRedirect(testCase.statusCode, testCase.maxAge, testCase.editableLinks)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedStatusCode, redirect.StatusCode())
+			assert.Equal(t, testCase.expectedCacheControl, redirect.CacheControl(testCase.shortLink, now))
+		})
+	}
+}
+
+func TestNewRedirect(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		statusCode  int
+		expectedErr error
+	}{
+		{
+			name:       "default status code",
+			statusCode: 0,
+		},
+		{
+			name:       "308 Permanent Redirect",
+			statusCode: http.StatusPermanentRedirect,
+		},
+		{
+			name:        "200 OK",
+			statusCode:  http.StatusOK,
+			expectedErr: ErrUnsupportedRedirectStatus(http.StatusOK),
+		},
+		{
+			name:        "304 Not Modified",
+			statusCode:  http.StatusNotModified,
+			expectedErr: ErrUnsupportedRedirectStatus(http.StatusNotModified),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewRedirect(testCase.statusCode, time.Hour, true)
+			assert.Equal(t, testCase.expectedErr, err)
+		})
+	}
+}
@@ -49,11 +49,13 @@ type SearchResponse struct {
 
 // ShortLink represents the short_link field of Search API respond.
 type ShortLink struct {
-	Alias     string     `json:"alias,omitempty"`
-	LongLink  string     `json:"long_link,omitempty"`
-	ExpireAt  *time.Time `json:"expire_at,omitempty"`
-	CreatedAt *time.Time `json:"created_at,omitempty"`
-	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	Alias        string     `json:"alias,omitempty"`
+	ShortLinkURL string     `json:"short_link_url,omitempty"`
+	LongLink     string     `json:"long_link,omitempty"`
+	ExpireAt     *time.Time `json:"expire_at,omitempty"`
+	CreatedAt    *time.Time `json:"created_at,omitempty"`
+	UpdatedAt    *time.Time `json:"updated_at,omitempty"`
+	Description  string     `json:"description,omitempty"`
 }
 
 // User represents the user field of Search API respond.
@@ -103,7 +105,7 @@ func Search(
 			return
 		}
 
-		results, err := searcher.Search(query, filter)
+		results, err := searcher.Search(r.Context(), query, filter)
 		if err != nil {
 			i.SearchFailed(err)
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -187,11 +189,12 @@ func newSearchResponse(result search.Result) SearchResponse {
 
 func newShortLink(shortLink entity.ShortLink) ShortLink {
 	return ShortLink{
-		Alias:     shortLink.Alias,
-		LongLink:  shortLink.LongLink,
-		ExpireAt:  shortLink.ExpireAt,
-		CreatedAt: shortLink.CreatedAt,
-		UpdatedAt: shortLink.UpdatedAt,
+		Alias:       shortLink.Alias,
+		LongLink:    shortLink.LongLink,
+		ExpireAt:    shortLink.ExpireAt,
+		CreatedAt:   shortLink.CreatedAt,
+		UpdatedAt:   shortLink.UpdatedAt,
+		Description: shortLink.Description,
 	}
 }
 
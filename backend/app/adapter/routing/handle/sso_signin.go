@@ -1,17 +1,47 @@
 package handle
 
 import (
+	"errors"
 	"net/http"
 	"net/url"
+	"path"
+	"strings"
 
 	"github.com/short-d/app/fw/router"
+	"github.com/short-d/short/backend/app/usecase/authenticator"
 	"github.com/short-d/short/backend/app/usecase/sso"
 )
 
+const (
+	ssoSessionCookie         = "sso_session"
+	ssoSessionMaxAgeSecond   = 10 * 60
+	ssoSessionSecretSplitter = "."
+)
+
 // SSOSignIn redirects user to the sign in page.
 func SSOSignIn(
 	singleSignOn sso.SingleSignOn,
 	webFrontendURL string,
+) router.Handle {
+	return ssoSignIn(singleSignOn, webFrontendURL, false)
+}
+
+// AppleSignIn redirects user to the sign in page of Apple. Apple posts the
+// callback from its own site, so the sign in session is kept in a cookie
+// which is also sent with cross site requests.
+func AppleSignIn(
+	singleSignOn sso.SingleSignOn,
+	webFrontendURL string,
+) router.Handle {
+	return ssoSignIn(singleSignOn, webFrontendURL, true)
+}
+
+// ssoSignIn starts a new sign in session, which is kept in a short-lived
+// cookie only sent to the callback of the identity provider.
+func ssoSignIn(
+	singleSignOn sso.SingleSignOn,
+	webFrontendURL string,
+	isCrossSite bool,
 ) router.Handle {
 	return func(w http.ResponseWriter, r *http.Request, params router.Params) {
 		token := getToken(params)
@@ -19,26 +49,128 @@ func SSOSignIn(
 			http.Redirect(w, r, webFrontendURL, http.StatusSeeOther)
 			return
 		}
-		signInLink := singleSignOn.GetSignInLink()
+
+		session, err := sso.NewSession()
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		value := strings.Join(
+			[]string{session.State, session.CodeVerifier, session.Nonce},
+			ssoSessionSecretSplitter,
+		)
+		setSSOSessionCookie(w, r.URL.Path, value, ssoSessionMaxAgeSecond, isCrossSite)
+
+		signInLink := singleSignOn.GetSignInLink(session)
 		http.Redirect(w, r, signInLink, http.StatusSeeOther)
 	}
 }
 
 // SSOSignInCallback generates Short's authentication token given identity provider's authorization code.
+// The short links created in the guest session before signing in are claimed
+// by the user.
 func SSOSignInCallback(
 	singleSignOn sso.SingleSignOn,
+	authenticator authenticator.Authenticator,
+	guestAttribution GuestAttribution,
 	webFrontendURL url.URL,
 ) router.Handle {
 	return func(w http.ResponseWriter, r *http.Request, params router.Params) {
+		session := takeSSOSession(w, r, false)
 		code := params["code"]
+		state := params["state"]
+		signIn(w, r, singleSignOn, code, state, session, authenticator, guestAttribution, webFrontendURL)
+	}
+}
 
-		authToken, err := singleSignOn.SignIn(code)
-		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			return
-		}
+// AppleSignInCallback generates Short's authentication token given the
+// identity token which Apple posts to the redirect URI as a form.
+func AppleSignInCallback(
+	singleSignOn sso.SingleSignOn,
+	authenticator authenticator.Authenticator,
+	guestAttribution GuestAttribution,
+	webFrontendURL url.URL,
+) router.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params router.Params) {
+		session := takeSSOSession(w, r, true)
+		identityToken := r.PostFormValue("id_token")
+		state := r.PostFormValue("state")
+		signIn(w, r, singleSignOn, identityToken, state, session, authenticator, guestAttribution, webFrontendURL)
+	}
+}
+
+// takeSSOSession retrieves the sign in session started by the browser and
+// removes it, so that each session is used at most once. The zero Session is
+// returned when the session is missing or malformed, which fails the state
+// check.
+func takeSSOSession(w http.ResponseWriter, r *http.Request, isCrossSite bool) sso.Session {
+	cookie, err := r.Cookie(ssoSessionCookie)
+	if err != nil {
+		return sso.Session{}
+	}
+	signInPath := path.Dir(r.URL.Path)
+	setSSOSessionCookie(w, signInPath, "", -1, isCrossSite)
 
-		webFrontendURL = setToken(webFrontendURL, authToken)
-		http.Redirect(w, r, webFrontendURL.String(), http.StatusSeeOther)
+	secrets := strings.Split(cookie.Value, ssoSessionSecretSplitter)
+	if len(secrets) != 3 {
+		return sso.Session{}
 	}
+	return sso.Session{
+		State:        secrets[0],
+		CodeVerifier: secrets[1],
+		Nonce:        secrets[2],
+	}
+}
+
+// setSSOSessionCookie scopes the cookie to the sign in path, which the
+// callback path is under, so that the sign ins through different identity
+// providers don't share sessions.
+func setSSOSessionCookie(w http.ResponseWriter, signInPath string, value string, maxAge int, isCrossSite bool) {
+	cookie := &http.Cookie{
+		Name:     ssoSessionCookie,
+		Value:    value,
+		Path:     signInPath,
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+	if isCrossSite {
+		// Browsers only accept cross site cookies over HTTPS.
+		cookie.SameSite = http.SameSiteNoneMode
+		cookie.Secure = true
+	}
+	http.SetCookie(w, cookie)
+}
+
+func signIn(
+	w http.ResponseWriter,
+	r *http.Request,
+	singleSignOn sso.SingleSignOn,
+	authorizationCode string,
+	state string,
+	session sso.Session,
+	authenticator authenticator.Authenticator,
+	guestAttribution GuestAttribution,
+	webFrontendURL url.URL,
+) {
+	authToken, err := singleSignOn.SignIn(authorizationCode, state, session)
+	var invalidState sso.ErrInvalidState
+	if errors.As(err, &invalidState) {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	// Failing to claim the guest session shouldn't block signing in. The
+	// session is kept so that the short links are claimed next time.
+	user, err := authenticator.GetUser(authToken)
+	if err == nil {
+		guestAttribution.claim(w, r, user)
+	}
+
+	webFrontendURL = setToken(webFrontendURL, authToken)
+	http.Redirect(w, r, webFrontendURL.String(), http.StatusSeeOther)
 }
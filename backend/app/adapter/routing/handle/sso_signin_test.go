@@ -0,0 +1,126 @@
+// +build !integration all
+
+package handle
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/crypto"
+	"github.com/short-d/app/fw/router"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/authenticator"
+	"github.com/short-d/short/backend/app/usecase/email"
+	"github.com/short-d/short/backend/app/usecase/keygen"
+	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/sso"
+	"github.com/short-d/short/backend/app/usecase/verification"
+)
+
+func newTestSingleSignOn(t *testing.T, auth authenticator.Authenticator) sso.SingleSignOn {
+	keyFetcher := keygen.NewKeyFetcherFake([]keygen.Key{})
+	keyGen, err := keygen.NewRemote(1, &keyFetcher)
+	assert.Equal(t, nil, err)
+
+	userRepo := repository.NewUserFake([]entity.User{{ID: "alpha"}})
+	emailVerifier := verification.NewEmailVerifier(
+		crypto.NewTokenizerFake(),
+		timer.NewStub(time.Now()),
+		&userRepo,
+		email.NewSenderFake(nil),
+		url.URL{Scheme: "https", Host: "short-d.com"},
+		24*time.Hour,
+	)
+	ssoMap, err := repository.NewsSSOMapFake([]string{"sso_alpha"}, []string{"alpha"})
+	assert.Equal(t, nil, err)
+
+	linker := sso.NewAccountLinkerFactory(keyGen, &userRepo, emailVerifier).NewAccountLinker(&ssoMap)
+	return sso.NewFactory(auth).NewSingleSignOn(
+		sso.NewIdentityProviderFake("https://idp.example.com/sign-in", "access_token"),
+		sso.NewAccountFake(entity.SSOUser{ID: "sso_alpha"}),
+		linker,
+	)
+}
+
+func TestSSOSignInCallback(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name             string
+		callbackState    func(issuedState string) string
+		hasSessionCookie bool
+		expectedStatus   int
+		expectedSignedIn bool
+	}{
+		{
+			name: "state matches sign in session",
+			callbackState: func(issuedState string) string {
+				return issuedState
+			},
+			hasSessionCookie: true,
+			expectedStatus:   http.StatusSeeOther,
+			expectedSignedIn: true,
+		},
+		{
+			name: "state mismatches sign in session",
+			callbackState: func(issuedState string) string {
+				return "forged"
+			},
+			hasSessionCookie: true,
+			expectedStatus:   http.StatusBadRequest,
+		},
+		{
+			name: "sign in session missing",
+			callbackState: func(issuedState string) string {
+				return issuedState
+			},
+			hasSessionCookie: false,
+			expectedStatus:   http.StatusBadRequest,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			auth := authenticator.NewAuthenticatorFake(time.Now(), time.Hour)
+			singleSignOn := newTestSingleSignOn(t, auth)
+
+			signInReq := httptest.NewRequest(http.MethodGet, "/oauth/twitter/sign-in", nil)
+			signInRes := httptest.NewRecorder()
+			SSOSignIn(singleSignOn, "https://short-d.com")(signInRes, signInReq, router.Params{})
+			assert.Equal(t, http.StatusSeeOther, signInRes.Code)
+
+			cookies := signInRes.Result().Cookies()
+			assert.Equal(t, 1, len(cookies))
+			sessionCookie := cookies[0]
+			assert.Equal(t, "/oauth/twitter/sign-in", sessionCookie.Path)
+			assert.Equal(t, true, sessionCookie.HttpOnly)
+			assert.Equal(t, http.SameSiteLaxMode, sessionCookie.SameSite)
+			issuedState := strings.Split(sessionCookie.Value, ".")[0]
+
+			callbackReq := httptest.NewRequest(http.MethodGet, "/oauth/twitter/sign-in/callback", nil)
+			if testCase.hasSessionCookie {
+				callbackReq.AddCookie(sessionCookie)
+			}
+			callbackRes := httptest.NewRecorder()
+			params := router.Params{
+				"code":  "authorized",
+				"state": testCase.callbackState(issuedState),
+			}
+			frontendURL := url.URL{Scheme: "https", Host: "short-d.com"}
+			SSOSignInCallback(singleSignOn, auth, GuestAttribution{}, frontendURL)(callbackRes, callbackReq, params)
+
+			assert.Equal(t, testCase.expectedStatus, callbackRes.Code)
+			location := callbackRes.Header().Get("Location")
+			assert.Equal(t, testCase.expectedSignedIn, strings.Contains(location, "token="))
+		})
+	}
+}
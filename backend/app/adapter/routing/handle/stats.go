@@ -0,0 +1,52 @@
+package handle
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/short-d/app/fw/router"
+	"github.com/short-d/short/backend/app/usecase/authenticator"
+	"github.com/short-d/short/backend/app/usecase/stats"
+)
+
+// ServiceStatsResponse represents the statistics of the whole service.
+type ServiceStatsResponse struct {
+	ShortLinks        int `json:"short_links"`
+	Users             int `json:"users"`
+	ShortLinksLastDay int `json:"short_links_last_day"`
+	ClicksLastDay     int `json:"clicks_last_day"`
+	FlaggedShortLinks int `json:"flagged_short_links"`
+}
+
+// ServiceStats fetches the statistics of the whole service for admins.
+func ServiceStats(
+	serviceStats stats.Service,
+	authenticator authenticator.Authenticator,
+) router.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params router.Params) {
+		user, err := authenticator.GetUser(getBearerToken(r))
+		if err != nil {
+			http.Error(w, "invalid auth token", http.StatusUnauthorized)
+			return
+		}
+
+		statsOfService, err := serviceStats.GetServiceStats(r.Context(), user)
+		var u stats.ErrUnauthorizedAction
+		if errors.As(err, &u) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, ServiceStatsResponse{
+			ShortLinks:        statsOfService.ShortLinks,
+			Users:             statsOfService.Users,
+			ShortLinksLastDay: statsOfService.ShortLinksLastDay,
+			ClicksLastDay:     statsOfService.ClicksLastDay,
+			FlaggedShortLinks: statsOfService.FlaggedShortLinks,
+		})
+	}
+}
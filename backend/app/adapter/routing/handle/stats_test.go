@@ -0,0 +1,110 @@
+// +build !integration all
+
+package handle
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/router"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/authenticator"
+	"github.com/short-d/short/backend/app/usecase/authorizer"
+	"github.com/short-d/short/backend/app/usecase/authorizer/rbac"
+	"github.com/short-d/short/backend/app/usecase/authorizer/rbac/role"
+	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/stats"
+)
+
+func TestServiceStats(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	hourAgo := now.Add(-time.Hour)
+	admin := entity.User{ID: "alpha"}
+	basic := entity.User{ID: "beta"}
+
+	testCases := []struct {
+		name               string
+		user               *entity.User
+		expectedStatusCode int
+		expectedResponse   ServiceStatsResponse
+	}{
+		{
+			name:               "admin views service stats",
+			user:               &admin,
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: ServiceStatsResponse{
+				ShortLinks:        2,
+				Users:             2,
+				ShortLinksLastDay: 1,
+				ClicksLastDay:     1,
+				FlaggedShortLinks: 0,
+			},
+		},
+		{
+			name:               "user without admin role",
+			user:               &basic,
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			name:               "user not signed in",
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			auth := authenticator.NewAuthenticatorFake(now, time.Hour)
+			userRoleRepo := repository.NewUserRoleFake(map[string][]role.Role{
+				admin.ID: {role.Basic, role.Admin},
+				basic.ID: {role.Basic},
+			})
+			au := authorizer.NewAuthorizer(rbac.NewRBAC(userRoleRepo))
+
+			userShortLinkRepo := repository.NewUserShortLinkRepoFake(nil, nil)
+			shortLinkRepo := repository.NewShortLinkFake(&userShortLinkRepo, map[string]entity.ShortLink{
+				"old":     {Alias: "old", LongLink: "https://www.google.com"},
+				"hourAgo": {Alias: "hourAgo", LongLink: "https://github.com", CreatedAt: &hourAgo},
+			})
+			userRepo := repository.NewUserFake([]entity.User{admin, basic})
+			visitRepo := repository.NewVisitFake([]entity.Visit{{Alias: "hourAgo", VisitedAt: hourAgo}})
+			flaggedShortLinkRepo := repository.NewFlaggedShortLinkFake(nil)
+			serviceStats := stats.NewServicePersist(
+				&shortLinkRepo,
+				&userRepo,
+				&visitRepo,
+				&flaggedShortLinkRepo,
+				au,
+				timer.NewStub(now),
+			)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/stats", nil)
+			if testCase.user != nil {
+				authToken, err := auth.GenerateToken(*testCase.user)
+				assert.Equal(t, nil, err)
+				req.Header.Set("Authorization", "Bearer "+authToken)
+			}
+			w := httptest.NewRecorder()
+
+			ServiceStats(serviceStats, auth)(w, req, router.Params{})
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			if w.Code != http.StatusOK {
+				return
+			}
+
+			var response ServiceStatsResponse
+			err := json.Unmarshal(w.Body.Bytes(), &response)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedResponse, response)
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package handle
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/short-d/app/fw/router"
+	"github.com/short-d/short/backend/app/usecase/authenticator"
+	"github.com/short-d/short/backend/app/usecase/verification"
+)
+
+// VerifyEmail verifies the email of the user with the token from the link
+// sent to the email, and redirects the user to the web frontend afterwards.
+func VerifyEmail(
+	emailVerifier verification.EmailVerifier,
+	webFrontendURL url.URL,
+) router.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params router.Params) {
+		err := emailVerifier.VerifyEmail(params["token"])
+		if err != nil {
+			http.Error(w, err.Error(), verifyEmailErrorStatus(err))
+			return
+		}
+		http.Redirect(w, r, webFrontendURL.String(), http.StatusSeeOther)
+	}
+}
+
+// SendVerificationEmail emails the signed in user a new verification link.
+func SendVerificationEmail(
+	emailVerifier verification.EmailVerifier,
+	authenticator authenticator.Authenticator,
+) router.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params router.Params) {
+		user, err := authenticator.GetUser(getBearerToken(r))
+		if err != nil {
+			http.Error(w, "invalid auth token", http.StatusUnauthorized)
+			return
+		}
+
+		err = emailVerifier.ResendVerification(user.ID)
+		if err != nil {
+			http.Error(w, err.Error(), verifyEmailErrorStatus(err))
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func verifyEmailErrorStatus(err error) int {
+	var (
+		it verification.ErrInvalidToken
+		ee verification.ErrEmptyEmail
+		tu verification.ErrTokenUsed
+		te verification.ErrTokenExpired
+	)
+	switch {
+	case errors.As(err, &it), errors.As(err, &ee):
+		return http.StatusBadRequest
+	case errors.As(err, &tu):
+		return http.StatusConflict
+	case errors.As(err, &te):
+		return http.StatusGone
+	default:
+		return http.StatusInternalServerError
+	}
+}
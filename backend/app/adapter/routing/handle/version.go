@@ -0,0 +1,37 @@
+package handle
+
+import (
+	"net/http"
+
+	"github.com/short-d/app/fw/router"
+	"github.com/short-d/short/backend/app/fw/buildinfo"
+	"github.com/short-d/short/backend/app/usecase/featureflag"
+)
+
+// VersionResponse represents the build running the service and the feature
+// flags turned on for the whole deployment.
+type VersionResponse struct {
+	Version      string   `json:"version"`
+	Commit       string   `json:"commit"`
+	BuildTime    string   `json:"build_time"`
+	FeatureFlags []string `json:"feature_flags"`
+}
+
+// Version reports the build running the service and the feature flags turned
+// on for the whole deployment, so that operators can confirm what is deployed.
+func Version(buildInfo buildinfo.Provider, featureToggle featureflag.Toggle) router.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params router.Params) {
+		info := buildInfo.GetInfo()
+		featureFlags := []string{}
+		for _, flag := range featureflag.EnabledFlags(featureToggle) {
+			featureFlags = append(featureFlags, string(flag))
+		}
+
+		writeJSON(w, http.StatusOK, VersionResponse{
+			Version:      info.Version,
+			Commit:       info.Commit,
+			BuildTime:    info.BuildTime,
+			FeatureFlags: featureFlags,
+		})
+	}
+}
@@ -0,0 +1,79 @@
+// +build !integration all
+
+package handle
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/router"
+	"github.com/short-d/short/backend/app/fw/buildinfo"
+	"github.com/short-d/short/backend/app/usecase/featureflag"
+)
+
+func TestVersion(t *testing.T) {
+	t.Parallel()
+
+	buildInfo := buildinfo.NewProviderFake(buildinfo.Info{
+		Version:   "v1.4.2",
+		Commit:    "9f2c1e7",
+		BuildTime: "2020-06-01T00:00:00Z",
+	})
+
+	testCases := []struct {
+		name             string
+		flags            map[featureflag.Flag]bool
+		expectedResponse VersionResponse
+	}{
+		{
+			name:  "no feature flag turned on",
+			flags: map[featureflag.Flag]bool{},
+			expectedResponse: VersionResponse{
+				Version:      "v1.4.2",
+				Commit:       "9f2c1e7",
+				BuildTime:    "2020-06-01T00:00:00Z",
+				FeatureFlags: []string{},
+			},
+		},
+		{
+			name: "some feature flags turned on",
+			flags: map[featureflag.Flag]bool{
+				featureflag.LinkHealthCheck:    true,
+				featureflag.BrokenLinkReport:   false,
+				featureflag.ProbeInternalHosts: true,
+			},
+			expectedResponse: VersionResponse{
+				Version:   "v1.4.2",
+				Commit:    "9f2c1e7",
+				BuildTime: "2020-06-01T00:00:00Z",
+				FeatureFlags: []string{
+					string(featureflag.LinkHealthCheck),
+					string(featureflag.ProbeInternalHosts),
+				},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			toggle := featureflag.NewToggleFake(testCase.flags)
+			req := httptest.NewRequest(http.MethodGet, "/version", nil)
+			w := httptest.NewRecorder()
+
+			Version(buildInfo, toggle)(w, req, router.Params{})
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+			var response VersionResponse
+			err := json.Unmarshal(w.Body.Bytes(), &response)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedResponse, response)
+		})
+	}
+}
@@ -3,18 +3,32 @@ package routing
 import (
 	"net/url"
 
+	"github.com/short-d/app/fw/network"
 	"github.com/short-d/app/fw/router"
 	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/adapter/apple"
 	"github.com/short-d/short/backend/app/adapter/facebook"
 	"github.com/short-d/short/backend/app/adapter/github"
 	"github.com/short-d/short/backend/app/adapter/google"
 	"github.com/short-d/short/backend/app/adapter/request"
 	"github.com/short-d/short/backend/app/adapter/routing/handle"
+	"github.com/short-d/short/backend/app/adapter/twitter"
+	"github.com/short-d/short/backend/app/fw/buildinfo"
+	"github.com/short-d/short/backend/app/fw/ipallow"
+	"github.com/short-d/short/backend/app/fw/tenant"
 	"github.com/short-d/short/backend/app/usecase/authenticator"
+	"github.com/short-d/short/backend/app/usecase/authorizer"
+	"github.com/short-d/short/backend/app/usecase/emailpassword"
 	"github.com/short-d/short/backend/app/usecase/feature"
+	"github.com/short-d/short/backend/app/usecase/featureflag"
+	"github.com/short-d/short/backend/app/usecase/ratelimit"
 	"github.com/short-d/short/backend/app/usecase/search"
+	"github.com/short-d/short/backend/app/usecase/share"
 	"github.com/short-d/short/backend/app/usecase/shortlink"
 	"github.com/short-d/short/backend/app/usecase/sso"
+	"github.com/short-d/short/backend/app/usecase/stats"
+	"github.com/short-d/short/backend/app/usecase/verification"
+	"github.com/short-d/short/backend/app/usecase/visit"
 )
 
 // NewShort creates HTTP routing table.
@@ -23,20 +37,60 @@ func NewShort(
 	webFrontendURL string,
 	timer timer.Timer,
 	shortLinkRetriever shortlink.Retriever,
+	shortLinkCreator shortlink.Creator,
+	visitTracker visit.Tracker,
+	network network.Network,
+	redirectRateLimiter ratelimit.Limiter,
+	linkRateLimiter ratelimit.VariableLimiter,
 	featureDecisionMakerFactory feature.DecisionMakerFactory,
 	githubSSO github.SingleSignOn,
 	facebookSSO facebook.SingleSignOn,
 	googleSSO google.SingleSignOn,
+	twitterSSO twitter.SingleSignOn,
+	appleSSO apple.SingleSignOn,
 	authenticator authenticator.Authenticator,
 	search search.Search,
 	swaggerUIDir string,
 	openAPISpecPath string,
+	errorPages handle.ErrorPages,
+	guestAttribution handle.GuestAttribution,
+	emailVerifier verification.EmailVerifier,
+	emailPasswordAccount emailpassword.Account,
+	passwordReset emailpassword.PasswordReset,
+	shortLinkShare share.Share,
+	authorizer authorizer.Authorizer,
+	isProfilingEnabled bool,
+	aliasSigner share.Signer,
+	adminPolicy ipallow.Policy,
+	queryConflict shortlink.QueryConflict,
+	redirect handle.Redirect,
+	canonicalDomain handle.CanonicalDomain,
+	serviceStats stats.Service,
+	buildInfo buildinfo.Provider,
+	featureToggle featureflag.Toggle,
+	tenantHosts tenant.Hosts,
 ) []router.Route {
 	frontendURL, err := url.Parse(webFrontendURL)
 	if err != nil {
 		panic(err)
 	}
-	return []router.Route{
+	redirectHandle := handle.LongLink(
+		instrumentationFactory,
+		shortLinkRetriever,
+		visitTracker,
+		network,
+		redirectRateLimiter,
+		linkRateLimiter,
+		timer,
+		*frontendURL,
+		errorPages,
+		aliasSigner,
+		queryConflict,
+		redirect,
+		canonicalDomain,
+		authenticator,
+	)
+	routes := []router.Route{
 		{
 			Method: "GET",
 			Path:   "/oauth/github/sign-in",
@@ -50,6 +104,8 @@ func NewShort(
 			Path:   "/oauth/github/sign-in/callback",
 			Handle: handle.SSOSignInCallback(
 				sso.SingleSignOn(githubSSO),
+				authenticator,
+				guestAttribution,
 				*frontendURL,
 			),
 		},
@@ -66,6 +122,8 @@ func NewShort(
 			Path:   "/oauth/facebook/sign-in/callback",
 			Handle: handle.SSOSignInCallback(
 				sso.SingleSignOn(facebookSSO),
+				authenticator,
+				guestAttribution,
 				*frontendURL,
 			),
 		},
@@ -82,19 +140,58 @@ func NewShort(
 			Path:   "/oauth/google/sign-in/callback",
 			Handle: handle.SSOSignInCallback(
 				sso.SingleSignOn(googleSSO),
+				authenticator,
+				guestAttribution,
 				*frontendURL,
 			),
 		},
 		{
 			Method: "GET",
-			Path:   "/r/:alias",
-			Handle: handle.LongLink(
-				instrumentationFactory,
-				shortLinkRetriever,
-				timer,
+			Path:   "/oauth/twitter/sign-in",
+			Handle: handle.SSOSignIn(
+				sso.SingleSignOn(twitterSSO),
+				webFrontendURL,
+			),
+		},
+		{
+			Method: "GET",
+			Path:   "/oauth/twitter/sign-in/callback",
+			Handle: handle.SSOSignInCallback(
+				sso.SingleSignOn(twitterSSO),
+				authenticator,
+				guestAttribution,
+				*frontendURL,
+			),
+		},
+		{
+			Method: "GET",
+			Path:   "/oauth/apple/sign-in",
+			Handle: handle.AppleSignIn(
+				sso.SingleSignOn(appleSSO),
+				webFrontendURL,
+			),
+		},
+		{
+			Method: "POST",
+			Path:   "/oauth/apple/sign-in/callback",
+			Handle: handle.AppleSignInCallback(
+				sso.SingleSignOn(appleSSO),
+				authenticator,
+				guestAttribution,
 				*frontendURL,
 			),
 		},
+		{
+			Method: "GET",
+			Path:   "/r/:alias",
+			Handle: redirectHandle,
+		},
+		{
+			Method:      "GET",
+			Path:        "/r/:alias/",
+			MatchPrefix: true,
+			Handle:      redirectHandle,
+		},
 		{
 			Method: "GET",
 			Path:   "/features/:featureID",
@@ -118,6 +215,83 @@ func NewShort(
 				authenticator,
 			),
 		},
+		{
+			Method: "POST",
+			Path:   "/api/v1/links",
+			Handle: handle.CreateLink(shortLinkCreator, authenticator, guestAttribution, shortLinkShare),
+		},
+		{
+			Method: "GET",
+			Path:   "/api/v1/links",
+			Handle: handle.ListLinks(shortLinkRetriever, authenticator, shortLinkShare),
+		},
+		{
+			Method: "GET",
+			Path:   "/api/v1/guest/links",
+			Handle: handle.GuestLinks(guestAttribution),
+		},
+		{
+			Method: "GET",
+			Path:   "/api/v1/links/:alias",
+			Handle: handle.GetLink(shortLinkRetriever, authenticator, shortLinkShare),
+		},
+		{
+			Method: "GET",
+			Path:   "/api/v1/links/:alias/ownership",
+			Handle: handle.GetLinkOwnership(shortLinkRetriever, authenticator),
+		},
+		{
+			Method: "GET",
+			Path:   verification.VerifyEmailPath,
+			Handle: handle.VerifyEmail(emailVerifier, *frontendURL),
+		},
+		{
+			Method: "POST",
+			Path:   "/api/v1/email/verification",
+			Handle: handle.SendVerificationEmail(emailVerifier, authenticator),
+		},
+		{
+			Method: "POST",
+			Path:   "/api/v1/auth/register",
+			Handle: handle.Register(emailPasswordAccount, authenticator),
+		},
+		{
+			Method: "POST",
+			Path:   "/api/v1/auth/sign-in",
+			Handle: handle.SignIn(emailPasswordAccount, network),
+		},
+		{
+			Method: "POST",
+			Path:   "/api/v1/auth/password-reset",
+			Handle: handle.RequestPasswordReset(passwordReset, network),
+		},
+		{
+			Method: "POST",
+			Path:   "/api/v1/auth/reset-password",
+			Handle: handle.ResetPassword(passwordReset),
+		},
+		{
+			Method: "GET",
+			Path:   "/api/v1/admin/stats",
+			Handle: adminPolicy.Handle(handle.ServiceStats(serviceStats, authenticator)),
+		},
+		{
+			Method: "GET",
+			Path:   "/version",
+			Handle: adminPolicy.Handle(handle.Version(buildInfo, featureToggle)),
+		},
+		{
+			Method:      "GET",
+			Path:        handle.ProfilePathPrefix,
+			MatchPrefix: true,
+			Handle:      adminPolicy.Handle(handle.Profile(authenticator, authorizer, isProfilingEnabled)),
+		},
+		{
+			Method:      "POST",
+			Path:        handle.ProfilePathPrefix,
+			MatchPrefix: true,
+			Handle:      adminPolicy.Handle(handle.Profile(authenticator, authorizer, isProfilingEnabled)),
+		},
 		{
 			Method:      "GET",
 			Path:        "/api",
@@ -131,4 +305,8 @@ func NewShort(
 			Handle:      handle.ServeDir(swaggerUIDir),
 		},
 	}
+	for idx := range routes {
+		routes[idx].Handle = tenantHosts.Handle(routes[idx].Handle)
+	}
+	return routes
 }
@@ -0,0 +1,217 @@
+// +build !integration all
+
+package routing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/crypto"
+	"github.com/short-d/app/fw/router"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/adapter/apple"
+	"github.com/short-d/short/backend/app/adapter/facebook"
+	"github.com/short-d/short/backend/app/adapter/github"
+	"github.com/short-d/short/backend/app/adapter/google"
+	"github.com/short-d/short/backend/app/adapter/request"
+	"github.com/short-d/short/backend/app/adapter/routing/handle"
+	"github.com/short-d/short/backend/app/adapter/twitter"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/fw/buildinfo"
+	"github.com/short-d/short/backend/app/fw/cors"
+	"github.com/short-d/short/backend/app/fw/ipallow"
+	"github.com/short-d/short/backend/app/fw/tenant"
+	"github.com/short-d/short/backend/app/usecase/authenticator"
+	"github.com/short-d/short/backend/app/usecase/authorizer"
+	"github.com/short-d/short/backend/app/usecase/email"
+	"github.com/short-d/short/backend/app/usecase/emailpassword"
+	"github.com/short-d/short/backend/app/usecase/featureflag"
+	"github.com/short-d/short/backend/app/usecase/keygen"
+	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/route"
+	"github.com/short-d/short/backend/app/usecase/search"
+	"github.com/short-d/short/backend/app/usecase/share"
+	"github.com/short-d/short/backend/app/usecase/shortlink"
+	"github.com/short-d/short/backend/app/usecase/sso"
+	"github.com/short-d/short/backend/app/usecase/stats"
+	"github.com/short-d/short/backend/app/usecase/verification"
+)
+
+func TestNewShort_ReservedRoutes(t *testing.T) {
+	t.Parallel()
+
+	routes := newTestShortRouter(t, shortRouterOverrides{})
+
+	for _, rt := range routes {
+		segment := strings.SplitN(strings.TrimPrefix(rt.Path, "/"), "/", 2)[0]
+		if segment == "" {
+			continue
+		}
+		assert.Equal(t, true, route.IsReserved(segment))
+	}
+}
+
+func TestNewShort_ProfileRoutes(t *testing.T) {
+	t.Parallel()
+
+	routes := newTestShortRouter(t, shortRouterOverrides{})
+
+	profileRoutes := 0
+	for _, rt := range routes {
+		if rt.Path == "/api" {
+			break
+		}
+		if rt.Path == handle.ProfilePathPrefix {
+			profileRoutes++
+		}
+	}
+	assert.Equal(t, 2, profileRoutes)
+}
+
+func TestNewShort_AppleSignInCallbackCrossOrigin(t *testing.T) {
+	t.Parallel()
+
+	auth := authenticator.NewAuthenticatorFake(time.Now(), time.Hour)
+	routes := newTestShortRouter(t, shortRouterOverrides{
+		appleSSO:      newTestAppleSSO(t, auth),
+		authenticator: auth,
+	})
+
+	httpRouter := router.NewHTTPHandler()
+	for _, rt := range routes {
+		err := httpRouter.AddRoute(rt.Method, rt.MatchPrefix, rt.Path, rt.Handle)
+		assert.Equal(t, nil, err)
+	}
+	corsPolicy, err := cors.NewPolicy(
+		[]string{""},
+		[]string{"GET", "POST"},
+		[]string{"Accept", "Content-Type", "Authorization"},
+		false,
+	)
+	assert.Equal(t, nil, err)
+	handler := corsPolicy.Handler(&httpRouter)
+
+	signInReq := httptest.NewRequest(http.MethodGet, "/oauth/apple/sign-in", nil)
+	signInRes := httptest.NewRecorder()
+	handler.ServeHTTP(signInRes, signInReq)
+	assert.Equal(t, http.StatusSeeOther, signInRes.Code)
+
+	// The session cookie has to be sent along with the callback Apple posts
+	// from its own site.
+	cookies := signInRes.Result().Cookies()
+	assert.Equal(t, 1, len(cookies))
+	sessionCookie := cookies[0]
+	assert.Equal(t, "/oauth/apple/sign-in", sessionCookie.Path)
+	assert.Equal(t, http.SameSiteNoneMode, sessionCookie.SameSite)
+	assert.Equal(t, true, sessionCookie.Secure)
+	assert.Equal(t, true, sessionCookie.HttpOnly)
+
+	// Apple posts the identity token from its own origin with form_post
+	// response mode, which the default CORS policy doesn't allow.
+	form := url.Values{
+		"id_token": {"apple-token"},
+		"state":    {strings.Split(sessionCookie.Value, ".")[0]},
+	}
+	callbackReq := httptest.NewRequest(
+		http.MethodPost,
+		"/oauth/apple/sign-in/callback",
+		strings.NewReader(form.Encode()),
+	)
+	callbackReq.Header.Set("Origin", "https://appleid.apple.com")
+	callbackReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	callbackReq.AddCookie(sessionCookie)
+	callbackRes := httptest.NewRecorder()
+	handler.ServeHTTP(callbackRes, callbackReq)
+
+	assert.Equal(t, http.StatusSeeOther, callbackRes.Code)
+	assert.Equal(t, "", callbackRes.Header().Get("Access-Control-Allow-Origin"))
+	location := callbackRes.Header().Get("Location")
+	assert.Equal(t, true, strings.HasPrefix(location, "http://localhost:3000"))
+	assert.Equal(t, true, strings.Contains(location, "token="))
+
+	cookies = callbackRes.Result().Cookies()
+	assert.Equal(t, 1, len(cookies))
+	assert.Equal(t, "sso_session", cookies[0].Name)
+	assert.Equal(t, -1, cookies[0].MaxAge)
+	assert.Equal(t, http.SameSiteNoneMode, cookies[0].SameSite)
+}
+
+// shortRouterOverrides replaces the dependencies of the routes created by
+// newTestShortRouter. The zero values are used for the fields left empty.
+type shortRouterOverrides struct {
+	appleSSO      apple.SingleSignOn
+	authenticator authenticator.Authenticator
+}
+
+func newTestShortRouter(t *testing.T, overrides shortRouterOverrides) []router.Route {
+	t.Helper()
+
+	return NewShort(
+		request.InstrumentationFactory{},
+		"http://localhost:3000",
+		timer.NewStub(time.Now()),
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		github.SingleSignOn{},
+		facebook.SingleSignOn{},
+		google.SingleSignOn{},
+		twitter.SingleSignOn{},
+		overrides.appleSSO,
+		overrides.authenticator,
+		search.Search{},
+		"",
+		"",
+		handle.ErrorPages{},
+		handle.GuestAttribution{},
+		verification.EmailVerifier{},
+		emailpassword.Account{},
+		emailpassword.PasswordReset{},
+		share.Share{},
+		authorizer.Authorizer{},
+		false,
+		share.Signer{},
+		ipallow.Policy{},
+		shortlink.QueryConflictKeepLongLink,
+		handle.Redirect{},
+		handle.CanonicalDomain{},
+		stats.ServicePersist{},
+		buildinfo.ProviderFake{},
+		featureflag.ToggleFake{},
+		tenant.Hosts{},
+	)
+}
+
+func newTestAppleSSO(t *testing.T, auth authenticator.Authenticator) apple.SingleSignOn {
+	keyFetcher := keygen.NewKeyFetcherFake([]keygen.Key{})
+	keyGen, err := keygen.NewRemote(1, &keyFetcher)
+	assert.Equal(t, nil, err)
+
+	userRepo := repository.NewUserFake([]entity.User{{ID: "alpha"}})
+	emailVerifier := verification.NewEmailVerifier(
+		crypto.NewTokenizerFake(),
+		timer.NewStub(time.Now()),
+		&userRepo,
+		email.NewSenderFake(nil),
+		url.URL{Scheme: "https", Host: "short-d.com"},
+		24*time.Hour,
+	)
+	ssoMap, err := repository.NewsSSOMapFake([]string{"sso_alpha"}, []string{"alpha"})
+	assert.Equal(t, nil, err)
+
+	linker := sso.NewAccountLinkerFactory(keyGen, &userRepo, emailVerifier).NewAccountLinker(&ssoMap)
+	return apple.SingleSignOn(sso.NewFactory(auth).NewSingleSignOn(
+		sso.NewIdentityProviderFake("https://appleid.apple.com/auth/authorize", "apple-token"),
+		sso.NewAccountFake(entity.SSOUser{ID: "sso_alpha"}),
+		linker,
+	))
+}
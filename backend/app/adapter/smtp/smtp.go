@@ -0,0 +1,72 @@
+package smtp
+
+import (
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/usecase/email"
+)
+
+var _ email.Sender = (*Sender)(nil)
+
+// Sender delivers emails through an SMTP server.
+type Sender struct {
+	addr     string
+	auth     smtp.Auth
+	from     string
+	timer    timer.Timer
+	sendMail func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// Send composes a plain text email and delivers it to the SMTP server.
+func (s Sender) Send(to string, subject string, body string) error {
+	message := composeMessage(s.from, to, subject, body, s.timer.Now())
+	return s.sendMail(s.addr, s.auth, s.from, []string{to}, message)
+}
+
+// composeMessage formats the email following RFC 5322. The subject is encoded
+// so that it can contain non ASCII characters, and every line of the body ends
+// with CRLF.
+func composeMessage(from string, to string, subject string, body string, sentAt time.Time) []byte {
+	headers := []string{
+		fmt.Sprintf("From: %s", from),
+		fmt.Sprintf("To: %s", to),
+		fmt.Sprintf("Subject: %s", mime.QEncoding.Encode("utf-8", subject)),
+		fmt.Sprintf("Date: %s", sentAt.Format(time.RFC1123Z)),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=utf-8",
+	}
+
+	body = strings.ReplaceAll(body, "\r\n", "\n")
+	body = strings.ReplaceAll(body, "\n", "\r\n")
+	return []byte(strings.Join(headers, "\r\n") + "\r\n\r\n" + body + "\r\n")
+}
+
+// NewSender creates Sender. Emails are sent without authentication when
+// username is empty.
+func NewSender(
+	timer timer.Timer,
+	host string,
+	port int,
+	username string,
+	password string,
+	from string,
+) Sender {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return Sender{
+		addr:     net.JoinHostPort(host, strconv.Itoa(port)),
+		auth:     auth,
+		from:     from,
+		timer:    timer,
+		sendMail: smtp.SendMail,
+	}
+}
@@ -0,0 +1,84 @@
+// +build !integration all
+
+package smtp
+
+import (
+	"net/smtp"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/timer"
+)
+
+func TestComposeMessage(t *testing.T) {
+	t.Parallel()
+
+	sentAt := time.Date(2020, 6, 1, 8, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name            string
+		subject         string
+		body            string
+		expectedMessage string
+	}{
+		{
+			name:    "plain text",
+			subject: "Your short link was flagged",
+			body:    "Hello\nThe link is under review.",
+			expectedMessage: "From: noreply@short-d.com\r\n" +
+				"To: alpha@example.com\r\n" +
+				"Subject: Your short link was flagged\r\n" +
+				"Date: Mon, 01 Jun 2020 08:00:00 +0000\r\n" +
+				"MIME-Version: 1.0\r\n" +
+				"Content-Type: text/plain; charset=utf-8\r\n" +
+				"\r\n" +
+				"Hello\r\nThe link is under review.\r\n",
+		},
+		{
+			name:    "non ASCII subject",
+			subject: "Café",
+			body:    "Hello\r\nWorld",
+			expectedMessage: "From: noreply@short-d.com\r\n" +
+				"To: alpha@example.com\r\n" +
+				"Subject: =?utf-8?q?Caf=C3=A9?=\r\n" +
+				"Date: Mon, 01 Jun 2020 08:00:00 +0000\r\n" +
+				"MIME-Version: 1.0\r\n" +
+				"Content-Type: text/plain; charset=utf-8\r\n" +
+				"\r\n" +
+				"Hello\r\nWorld\r\n",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			message := composeMessage("noreply@short-d.com", "alpha@example.com", testCase.subject, testCase.body, sentAt)
+			assert.Equal(t, testCase.expectedMessage, string(message))
+		})
+	}
+}
+
+func TestSender_Send(t *testing.T) {
+	t.Parallel()
+
+	sentAt := time.Date(2020, 6, 1, 8, 0, 0, 0, time.UTC)
+	tm := timer.NewStub(sentAt)
+	sender := NewSender(tm, "smtp.example.com", 587, "", "", "noreply@short-d.com")
+
+	var gotAddr, gotFrom string
+	var gotTo []string
+	var gotMessage []byte
+	sender.sendMail = func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error {
+		gotAddr, gotFrom, gotTo, gotMessage = addr, from, to, msg
+		return nil
+	}
+
+	err := sender.Send("alpha@example.com", "Hello", "Hi there")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "smtp.example.com:587", gotAddr)
+	assert.Equal(t, "noreply@short-d.com", gotFrom)
+	assert.Equal(t, []string{"alpha@example.com"}, gotTo)
+	assert.Equal(t, string(composeMessage("noreply@short-d.com", "alpha@example.com", "Hello", "Hi there", sentAt)), string(gotMessage))
+}
@@ -0,0 +1,134 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/short-d/short/backend/app/adapter/sqldb/table"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/fw/tenant"
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+var _ repository.AliasReservation = (*AliasReservationSQL)(nil)
+
+// AliasReservationSQL accesses the reservations of custom aliases in
+// alias_reservation table through SQL. Only the reservations of the tenant
+// attached to the context are accessed.
+type AliasReservationSQL struct {
+	db *sql.DB
+}
+
+// CreateReservation holds the alias for the user with a single conditional
+// upsert, so that concurrent reservations of the same alias can't both
+// succeed. The reservation expired by now or held by the same user is
+// replaced. It reports false when another user holds the alias.
+func (a AliasReservationSQL) CreateReservation(
+	ctx context.Context,
+	reservation entity.AliasReservation,
+	now time.Time,
+) (bool, error) {
+	statement := fmt.Sprintf(`
+INSERT INTO "%s" ("%s","%s","%s","%s","%s")
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT ("%s","%s") DO UPDATE
+SET "%s"=EXCLUDED."%s","%s"=EXCLUDED."%s","%s"=EXCLUDED."%s"
+WHERE "%s"."%s"<=$6 OR "%s"."%s"=EXCLUDED."%s";
+`,
+		table.AliasReservation.TableName,
+		table.AliasReservation.ColumnTenantID,
+		table.AliasReservation.ColumnAlias,
+		table.AliasReservation.ColumnUserID,
+		table.AliasReservation.ColumnToken,
+		table.AliasReservation.ColumnExpireAt,
+		table.AliasReservation.ColumnTenantID,
+		table.AliasReservation.ColumnAlias,
+		table.AliasReservation.ColumnUserID, table.AliasReservation.ColumnUserID,
+		table.AliasReservation.ColumnToken, table.AliasReservation.ColumnToken,
+		table.AliasReservation.ColumnExpireAt, table.AliasReservation.ColumnExpireAt,
+		table.AliasReservation.TableName, table.AliasReservation.ColumnExpireAt,
+		table.AliasReservation.TableName, table.AliasReservation.ColumnUserID,
+		table.AliasReservation.ColumnUserID,
+	)
+
+	result, err := a.db.ExecContext(
+		ctx,
+		statement,
+		tenant.FromContext(ctx),
+		reservation.Alias,
+		reservation.UserID,
+		reservation.Token,
+		reservation.ExpireAt.UTC(),
+		now.UTC(),
+	)
+	if err != nil {
+		return false, err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return count == 1, nil
+}
+
+// FindReservation fetches the latest reservation of the alias from
+// alias_reservation table, which may have expired.
+func (a AliasReservationSQL) FindReservation(ctx context.Context, alias string) (entity.AliasReservation, error) {
+	query := fmt.Sprintf(`
+SELECT "%s","%s","%s"
+FROM "%s"
+WHERE "%s"=$1 AND "%s"=$2;
+`,
+		table.AliasReservation.ColumnUserID,
+		table.AliasReservation.ColumnToken,
+		table.AliasReservation.ColumnExpireAt,
+		table.AliasReservation.TableName,
+		table.AliasReservation.ColumnTenantID,
+		table.AliasReservation.ColumnAlias,
+	)
+
+	reservation := entity.AliasReservation{Alias: alias}
+	err := a.db.QueryRowContext(ctx, query, tenant.FromContext(ctx), alias).Scan(
+		&reservation.UserID,
+		&reservation.Token,
+		&reservation.ExpireAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.AliasReservation{}, repository.ErrEntryNotFound(
+			fmt.Sprintf("reservation not found (alias=%s)", alias),
+		)
+	}
+	if err != nil {
+		return entity.AliasReservation{}, err
+	}
+	reservation.ExpireAt = reservation.ExpireAt.UTC()
+	return reservation, nil
+}
+
+// DeleteReservation releases the alias held with the given token from
+// alias_reservation table.
+func (a AliasReservationSQL) DeleteReservation(ctx context.Context, alias string, token string) error {
+	statement := fmt.Sprintf(`
+DELETE FROM "%s"
+WHERE "%s"=$1 AND "%s"=$2 AND "%s"=$3;
+`,
+		table.AliasReservation.TableName,
+		table.AliasReservation.ColumnTenantID,
+		table.AliasReservation.ColumnAlias,
+		table.AliasReservation.ColumnToken,
+	)
+
+	_, err := a.db.ExecContext(ctx, statement, tenant.FromContext(ctx), alias, token)
+	return err
+}
+
+// NewAliasReservationSQL creates AliasReservationSQL
+func NewAliasReservationSQL(db *sql.DB) AliasReservationSQL {
+	return AliasReservationSQL{
+		db: db,
+	}
+}
@@ -0,0 +1,195 @@
+// +build integration all
+
+package sqldb_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/db/dbtest"
+	"github.com/short-d/short/backend/app/adapter/sqldb"
+	"github.com/short-d/short/backend/app/entity"
+)
+
+func TestAliasReservationSQL_CreateReservation(t *testing.T) {
+	now := time.Date(2020, 5, 1, 8, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name                string
+		reservations        []entity.AliasReservation
+		reservation         entity.AliasReservation
+		expectedIsReserved  bool
+		expectedReservation entity.AliasReservation
+	}{
+		{
+			name:         "alias not reserved",
+			reservations: []entity.AliasReservation{},
+			reservation: entity.AliasReservation{
+				Alias:    "google",
+				UserID:   "alpha",
+				Token:    "token1",
+				ExpireAt: now.Add(10 * time.Minute),
+			},
+			expectedIsReserved: true,
+			expectedReservation: entity.AliasReservation{
+				Alias:    "google",
+				UserID:   "alpha",
+				Token:    "token1",
+				ExpireAt: now.Add(10 * time.Minute),
+			},
+		},
+		{
+			name: "alias held by another user",
+			reservations: []entity.AliasReservation{
+				{
+					Alias:    "google",
+					UserID:   "beta",
+					Token:    "token2",
+					ExpireAt: now.Add(time.Minute),
+				},
+			},
+			reservation: entity.AliasReservation{
+				Alias:    "google",
+				UserID:   "alpha",
+				Token:    "token1",
+				ExpireAt: now.Add(10 * time.Minute),
+			},
+			expectedIsReserved: false,
+			expectedReservation: entity.AliasReservation{
+				Alias:    "google",
+				UserID:   "beta",
+				Token:    "token2",
+				ExpireAt: now.Add(time.Minute),
+			},
+		},
+		{
+			name: "reservation of another user expired",
+			reservations: []entity.AliasReservation{
+				{
+					Alias:    "google",
+					UserID:   "beta",
+					Token:    "token2",
+					ExpireAt: now,
+				},
+			},
+			reservation: entity.AliasReservation{
+				Alias:    "google",
+				UserID:   "alpha",
+				Token:    "token1",
+				ExpireAt: now.Add(10 * time.Minute),
+			},
+			expectedIsReserved: true,
+			expectedReservation: entity.AliasReservation{
+				Alias:    "google",
+				UserID:   "alpha",
+				Token:    "token1",
+				ExpireAt: now.Add(10 * time.Minute),
+			},
+		},
+		{
+			name: "alias reserved again by same user",
+			reservations: []entity.AliasReservation{
+				{
+					Alias:    "google",
+					UserID:   "alpha",
+					Token:    "token0",
+					ExpireAt: now.Add(time.Minute),
+				},
+			},
+			reservation: entity.AliasReservation{
+				Alias:    "google",
+				UserID:   "alpha",
+				Token:    "token1",
+				ExpireAt: now.Add(10 * time.Minute),
+			},
+			expectedIsReserved: true,
+			expectedReservation: entity.AliasReservation{
+				Alias:    "google",
+				UserID:   "alpha",
+				Token:    "token1",
+				ExpireAt: now.Add(10 * time.Minute),
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			dbtest.AccessTestDB(
+				dbConnector,
+				dbMigrationTool,
+				dbMigrationRoot,
+				dbConfig,
+				func(sqlDB *sql.DB) {
+					ctx := context.Background()
+					reservationRepo := sqldb.NewAliasReservationSQL(sqlDB)
+					for _, reservation := range testCase.reservations {
+						isReserved, err := reservationRepo.CreateReservation(ctx, reservation, now.Add(-time.Hour))
+						assert.Equal(t, nil, err)
+						assert.Equal(t, true, isReserved)
+					}
+
+					isReserved, err := reservationRepo.CreateReservation(ctx, testCase.reservation, now)
+					assert.Equal(t, nil, err)
+					assert.Equal(t, testCase.expectedIsReserved, isReserved)
+
+					reservation, err := reservationRepo.FindReservation(ctx, testCase.reservation.Alias)
+					assert.Equal(t, nil, err)
+					assert.Equal(t, testCase.expectedReservation, reservation)
+				},
+			)
+		})
+	}
+}
+
+func TestAliasReservationSQL_DeleteReservation(t *testing.T) {
+	now := time.Date(2020, 5, 1, 8, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name          string
+		token         string
+		expectedFound bool
+	}{
+		{
+			name:          "token matched",
+			token:         "token1",
+			expectedFound: false,
+		},
+		{
+			name:          "token mismatched",
+			token:         "token2",
+			expectedFound: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			dbtest.AccessTestDB(
+				dbConnector,
+				dbMigrationTool,
+				dbMigrationRoot,
+				dbConfig,
+				func(sqlDB *sql.DB) {
+					ctx := context.Background()
+					reservationRepo := sqldb.NewAliasReservationSQL(sqlDB)
+					reservation := entity.AliasReservation{
+						Alias:    "google",
+						UserID:   "alpha",
+						Token:    "token1",
+						ExpireAt: now.Add(10 * time.Minute),
+					}
+					_, err := reservationRepo.CreateReservation(ctx, reservation, now)
+					assert.Equal(t, nil, err)
+
+					err = reservationRepo.DeleteReservation(ctx, "google", testCase.token)
+					assert.Equal(t, nil, err)
+
+					_, err = reservationRepo.FindReservation(ctx, "google")
+					assert.Equal(t, testCase.expectedFound, err == nil)
+				},
+			)
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/short-d/short/backend/app/adapter/sqldb/table"
+	"github.com/short-d/short/backend/app/fw/tenant"
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+var _ repository.AliasSkeleton = (*AliasSkeletonSQL)(nil)
+
+// AliasSkeletonSQL accesses the skeletons of aliases in alias_skeleton table
+// through SQL. Only the aliases of the tenant attached to the context are
+// accessed.
+type AliasSkeletonSQL struct {
+	db *sql.DB
+}
+
+// CreateAliasSkeleton saves the skeleton of the alias into alias_skeleton
+// table, replacing the one left by a deleted short link with the same alias.
+func (a AliasSkeletonSQL) CreateAliasSkeleton(ctx context.Context, alias string, skeleton string) error {
+	statement := fmt.Sprintf(`
+INSERT INTO "%s" ("%s","%s","%s")
+VALUES ($1, $2, $3)
+ON CONFLICT ("%s","%s") DO UPDATE
+SET "%s"=EXCLUDED."%s";
+`,
+		table.AliasSkeleton.TableName,
+		table.AliasSkeleton.ColumnTenantID,
+		table.AliasSkeleton.ColumnAlias,
+		table.AliasSkeleton.ColumnSkeleton,
+		table.AliasSkeleton.ColumnTenantID,
+		table.AliasSkeleton.ColumnAlias,
+		table.AliasSkeleton.ColumnSkeleton,
+		table.AliasSkeleton.ColumnSkeleton,
+	)
+
+	_, err := a.db.ExecContext(ctx, statement, tenant.FromContext(ctx), alias, skeleton)
+	return err
+}
+
+// RenameAliasSkeleton replaces the skeleton of the old alias with the skeleton
+// of the new alias in alias_skeleton table within a single statement, so that
+// the renamed short link is never left without a skeleton.
+func (a AliasSkeletonSQL) RenameAliasSkeleton(ctx context.Context, oldAlias string, newAlias string, skeleton string) error {
+	statement := fmt.Sprintf(`
+WITH "old_skeleton" AS (
+	DELETE FROM "%s"
+	WHERE "%s"=$1 AND "%s"=$2
+)
+INSERT INTO "%s" ("%s","%s","%s")
+VALUES ($1, $3, $4)
+ON CONFLICT ("%s","%s") DO UPDATE
+SET "%s"=EXCLUDED."%s";
+`,
+		table.AliasSkeleton.TableName,
+		table.AliasSkeleton.ColumnTenantID,
+		table.AliasSkeleton.ColumnAlias,
+		table.AliasSkeleton.TableName,
+		table.AliasSkeleton.ColumnTenantID,
+		table.AliasSkeleton.ColumnAlias,
+		table.AliasSkeleton.ColumnSkeleton,
+		table.AliasSkeleton.ColumnTenantID,
+		table.AliasSkeleton.ColumnAlias,
+		table.AliasSkeleton.ColumnSkeleton,
+		table.AliasSkeleton.ColumnSkeleton,
+	)
+
+	_, err := a.db.ExecContext(ctx, statement, tenant.FromContext(ctx), oldAlias, newAlias, skeleton)
+	return err
+}
+
+// FindAliasBySkeleton finds the first alias in alphabetical order with the
+// given skeleton other than the excluded alias. The skeletons of the short
+// links deleted or renamed since are ignored.
+func (a AliasSkeletonSQL) FindAliasBySkeleton(ctx context.Context, skeleton string, excludedAlias string) (string, bool, error) {
+	query := fmt.Sprintf(`
+SELECT "%s"."%s"
+FROM "%s"
+JOIN "%s" ON "%s"."%s"="%s"."%s" AND "%s"."%s"="%s"."%s"
+WHERE "%s"."%s"=$3 AND "%s"."%s"=$1 AND "%s"."%s"<>$2
+ORDER BY "%s"."%s"
+LIMIT 1;
+`,
+		table.AliasSkeleton.TableName, table.AliasSkeleton.ColumnAlias,
+		table.AliasSkeleton.TableName,
+		table.ShortLink.TableName,
+		table.ShortLink.TableName, table.ShortLink.ColumnTenantID,
+		table.AliasSkeleton.TableName, table.AliasSkeleton.ColumnTenantID,
+		table.ShortLink.TableName, table.ShortLink.ColumnAlias,
+		table.AliasSkeleton.TableName, table.AliasSkeleton.ColumnAlias,
+		table.AliasSkeleton.TableName, table.AliasSkeleton.ColumnTenantID,
+		table.AliasSkeleton.TableName, table.AliasSkeleton.ColumnSkeleton,
+		table.AliasSkeleton.TableName, table.AliasSkeleton.ColumnAlias,
+		table.AliasSkeleton.TableName, table.AliasSkeleton.ColumnAlias,
+	)
+
+	var alias string
+	err := a.db.QueryRowContext(ctx, query, skeleton, excludedAlias, tenant.FromContext(ctx)).Scan(&alias)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return alias, true, nil
+}
+
+// NewAliasSkeletonSQL creates AliasSkeletonSQL
+func NewAliasSkeletonSQL(db *sql.DB) AliasSkeletonSQL {
+	return AliasSkeletonSQL{
+		db: db,
+	}
+}
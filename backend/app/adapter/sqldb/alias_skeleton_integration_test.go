@@ -0,0 +1,123 @@
+// +build integration all
+
+package sqldb_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/db/dbtest"
+	"github.com/short-d/short/backend/app/adapter/sqldb"
+)
+
+func TestAliasSkeletonSQL_FindAliasBySkeleton(t *testing.T) {
+	testCases := []struct {
+		name               string
+		shortLinkTableRows []shortLinkTableRow
+		skeletons          map[string]string
+		skeleton           string
+		excludedAlias      string
+		expectedFound      bool
+		expectedAlias      string
+	}{
+		{
+			name: "confusable alias found",
+			shortLinkTableRows: []shortLinkTableRow{
+				{alias: "paypal", longLink: "https://www.paypal.com"},
+			},
+			skeletons:     map[string]string{"paypal": "paypal"},
+			skeleton:      "paypal",
+			excludedAlias: "paypa1",
+			expectedFound: true,
+			expectedAlias: "paypal",
+		},
+		{
+			name: "no alias with same skeleton",
+			shortLinkTableRows: []shortLinkTableRow{
+				{alias: "paypal", longLink: "https://www.paypal.com"},
+			},
+			skeletons:     map[string]string{"paypal": "paypal"},
+			skeleton:      "stripe",
+			excludedAlias: "stripe",
+			expectedFound: false,
+		},
+		{
+			name: "skip excluded alias",
+			shortLinkTableRows: []shortLinkTableRow{
+				{alias: "paypal", longLink: "https://www.paypal.com"},
+			},
+			skeletons:     map[string]string{"paypal": "paypal"},
+			skeleton:      "paypal",
+			excludedAlias: "paypal",
+			expectedFound: false,
+		},
+		{
+			name:               "skip deleted short link",
+			shortLinkTableRows: []shortLinkTableRow{},
+			skeletons:          map[string]string{"paypal": "paypal"},
+			skeleton:           "paypal",
+			excludedAlias:      "paypa1",
+			expectedFound:      false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			dbtest.AccessTestDB(
+				dbConnector,
+				dbMigrationTool,
+				dbMigrationRoot,
+				dbConfig,
+				func(sqlDB *sql.DB) {
+					insertShortLinkTableRows(t, sqlDB, testCase.shortLinkTableRows)
+
+					ctx := context.Background()
+					aliasSkeletonRepo := sqldb.NewAliasSkeletonSQL(sqlDB)
+					for alias, skeleton := range testCase.skeletons {
+						err := aliasSkeletonRepo.CreateAliasSkeleton(ctx, alias, skeleton)
+						assert.Equal(t, nil, err)
+					}
+
+					alias, found, err := aliasSkeletonRepo.FindAliasBySkeleton(ctx, testCase.skeleton, testCase.excludedAlias)
+					assert.Equal(t, nil, err)
+					assert.Equal(t, testCase.expectedFound, found)
+					assert.Equal(t, testCase.expectedAlias, alias)
+				},
+			)
+		})
+	}
+}
+
+func TestAliasSkeletonSQL_RenameAliasSkeleton(t *testing.T) {
+	dbtest.AccessTestDB(
+		dbConnector,
+		dbMigrationTool,
+		dbMigrationRoot,
+		dbConfig,
+		func(sqlDB *sql.DB) {
+			insertShortLinkTableRows(t, sqlDB, []shortLinkTableRow{
+				{alias: "stripe", longLink: "https://www.stripe.com"},
+				{alias: "paydays", longLink: "https://www.stripe.com"},
+			})
+
+			ctx := context.Background()
+			aliasSkeletonRepo := sqldb.NewAliasSkeletonSQL(sqlDB)
+			err := aliasSkeletonRepo.CreateAliasSkeleton(ctx, "stripe", "stripe")
+			assert.Equal(t, nil, err)
+
+			err = aliasSkeletonRepo.RenameAliasSkeleton(ctx, "stripe", "paydays", "paydays")
+			assert.Equal(t, nil, err)
+
+			_, found, err := aliasSkeletonRepo.FindAliasBySkeleton(ctx, "stripe", "str1pe")
+			assert.Equal(t, nil, err)
+			assert.Equal(t, false, found)
+
+			alias, found, err := aliasSkeletonRepo.FindAliasBySkeleton(ctx, "paydays", "paydays5")
+			assert.Equal(t, nil, err)
+			assert.Equal(t, true, found)
+			assert.Equal(t, "paydays", alias)
+		},
+	)
+}
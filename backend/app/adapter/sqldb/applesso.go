@@ -0,0 +1,89 @@
+package sqldb
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/short-d/app/fw/logger"
+	"github.com/short-d/short/backend/app/adapter/sqldb/table"
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+var _ repository.SSOMap = (*AppleSSOSql)(nil)
+
+// AppleSSOSql accesses mapping between Apple and Short accounts in
+// SQL database.
+type AppleSSOSql struct {
+	db     *sql.DB
+	logger logger.Logger
+}
+
+// GetShortUserID retrieves the internal user ID that is linked to the user's
+// Apple account.
+func (g AppleSSOSql) GetShortUserID(ssoUserID string) (string, error) {
+	query := fmt.Sprintf(`
+SELECT "%s"
+FROM "%s"
+WHERE "%s"=$1;
+`,
+		table.AppleSSO.ColumnShortUserID,
+		table.AppleSSO.TableName,
+		table.AppleSSO.ColumnAppleUserID,
+	)
+	var id string
+	err := g.db.QueryRow(query, ssoUserID).Scan(&id)
+	if err == nil {
+		return id, err
+	}
+	if err == sql.ErrNoRows {
+		return "", repository.ErrEntryNotFound(
+			fmt.Sprintf("user with Apple ID %s not found", ssoUserID),
+		)
+	}
+	g.logger.Error(err)
+	return "", err
+}
+
+// IsSSOUserExist checks whether mapping for a given Apple account exists in
+// the database.
+func (g AppleSSOSql) IsSSOUserExist(ssoUserID string) (bool, error) {
+	query := fmt.Sprintf(`
+SELECT "%s"
+FROM "%s"
+WHERE "%s"=$1;
+`,
+		table.AppleSSO.ColumnAppleUserID,
+		table.AppleSSO.TableName,
+		table.AppleSSO.ColumnAppleUserID,
+	)
+	var id string
+	err := g.db.QueryRow(query, ssoUserID).Scan(&id)
+	if err == nil {
+		return true, err
+	}
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	g.logger.Error(err)
+	return false, err
+}
+
+// CreateMapping creates links user's Apple and Short accounts in the
+// database.
+func (g AppleSSOSql) CreateMapping(ssoUserID string, userID string) error {
+	statement := fmt.Sprintf(`
+INSERT INTO "%s" ("%s", "%s")
+VALUES ($1, $2);
+`,
+		table.AppleSSO.TableName,
+		table.AppleSSO.ColumnAppleUserID,
+		table.AppleSSO.ColumnShortUserID,
+	)
+	_, err := g.db.Exec(statement, ssoUserID, userID)
+	return err
+}
+
+// NewAppleSSOSql creates AppleSSOSql.
+func NewAppleSSOSql(db *sql.DB, logger logger.Logger) AppleSSOSql {
+	return AppleSSOSql{db: db, logger: logger}
+}
@@ -0,0 +1,72 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/short-d/short/backend/app/adapter/sqldb/table"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/fw/tenant"
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+var _ repository.FlaggedShortLink = (*FlaggedShortLinkSQL)(nil)
+
+// FlaggedShortLinkSQL accesses the short links pending for review in
+// flagged_short_link table through SQL.
+type FlaggedShortLinkSQL struct {
+	db *sql.DB
+}
+
+// CreateFlaggedShortLink inserts a new flagged short link of the tenant
+// attached to the context into flagged_short_link table. The short link
+// flagged again, such as after its long link is updated, keeps the latest risk
+// score and flagging time.
+func (f FlaggedShortLinkSQL) CreateFlaggedShortLink(ctx context.Context, flaggedShortLink entity.FlaggedShortLink) error {
+	statement := fmt.Sprintf(`
+INSERT INTO "%s" ("%s","%s","%s","%s")
+VALUES ($1, $2, $3, $4)
+ON CONFLICT ("%s","%s") DO UPDATE
+SET "%s"=EXCLUDED."%s", "%s"=EXCLUDED."%s";
+`,
+		table.FlaggedShortLink.TableName,
+		table.FlaggedShortLink.ColumnTenantID,
+		table.FlaggedShortLink.ColumnAlias,
+		table.FlaggedShortLink.ColumnRiskScore,
+		table.FlaggedShortLink.ColumnFlaggedAt,
+		table.FlaggedShortLink.ColumnTenantID,
+		table.FlaggedShortLink.ColumnAlias,
+		table.FlaggedShortLink.ColumnRiskScore,
+		table.FlaggedShortLink.ColumnRiskScore,
+		table.FlaggedShortLink.ColumnFlaggedAt,
+		table.FlaggedShortLink.ColumnFlaggedAt,
+	)
+
+	_, err := f.db.ExecContext(
+		ctx,
+		statement,
+		tenant.FromContext(ctx),
+		flaggedShortLink.Alias,
+		flaggedShortLink.RiskScore,
+		flaggedShortLink.FlaggedAt.UTC(),
+	)
+	return err
+}
+
+// CountFlaggedShortLinks counts all the short links in flagged_short_link
+// table across all the tenants.
+func (f FlaggedShortLinkSQL) CountFlaggedShortLinks(ctx context.Context) (int, error) {
+	statement := fmt.Sprintf(`SELECT COUNT(*) FROM "%s";`, table.FlaggedShortLink.TableName)
+
+	var count int
+	err := f.db.QueryRowContext(ctx, statement).Scan(&count)
+	return count, err
+}
+
+// NewFlaggedShortLinkSQL creates FlaggedShortLinkSQL
+func NewFlaggedShortLinkSQL(db *sql.DB) FlaggedShortLinkSQL {
+	return FlaggedShortLinkSQL{
+		db: db,
+	}
+}
@@ -0,0 +1,115 @@
+// +build integration all
+
+package sqldb_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/db/dbtest"
+	"github.com/short-d/short/backend/app/adapter/sqldb"
+	"github.com/short-d/short/backend/app/adapter/sqldb/table"
+	"github.com/short-d/short/backend/app/entity"
+)
+
+func TestFlaggedShortLinkSQL_CreateFlaggedShortLink(t *testing.T) {
+	now := mustParseTime(t, "2020-05-01T08:02:16-07:00")
+
+	testCases := []struct {
+		name               string
+		shortLinkTableRows []shortLinkTableRow
+		previousFlag       *entity.FlaggedShortLink
+		flaggedShortLink   entity.FlaggedShortLink
+		expHasErr          bool
+	}{
+		{
+			name: "flag existing short link",
+			shortLinkTableRows: []shortLinkTableRow{
+				{
+					alias:    "220uFicCJj",
+					longLink: "http://suspicious.example.com",
+				},
+			},
+			flaggedShortLink: entity.FlaggedShortLink{
+				Alias:     "220uFicCJj",
+				RiskScore: 50,
+				FlaggedAt: now,
+			},
+			expHasErr: false,
+		},
+		{
+			name: "flag short link again",
+			shortLinkTableRows: []shortLinkTableRow{
+				{
+					alias:    "220uFicCJj",
+					longLink: "http://suspicious.example.com",
+				},
+			},
+			previousFlag: &entity.FlaggedShortLink{
+				Alias:     "220uFicCJj",
+				RiskScore: 40,
+				FlaggedAt: now.Add(-time.Hour),
+			},
+			flaggedShortLink: entity.FlaggedShortLink{
+				Alias:     "220uFicCJj",
+				RiskScore: 50,
+				FlaggedAt: now,
+			},
+			expHasErr: false,
+		},
+		{
+			name:               "short link not found",
+			shortLinkTableRows: []shortLinkTableRow{},
+			flaggedShortLink: entity.FlaggedShortLink{
+				Alias:     "220uFicCJj",
+				RiskScore: 50,
+				FlaggedAt: now,
+			},
+			expHasErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			dbtest.AccessTestDB(
+				dbConnector,
+				dbMigrationTool,
+				dbMigrationRoot,
+				dbConfig,
+				func(sqlDB *sql.DB) {
+					insertShortLinkTableRows(t, sqlDB, testCase.shortLinkTableRows)
+
+					flaggedShortLinkRepo := sqldb.NewFlaggedShortLinkSQL(sqlDB)
+					if testCase.previousFlag != nil {
+						err := flaggedShortLinkRepo.CreateFlaggedShortLink(context.Background(), *testCase.previousFlag)
+						assert.Equal(t, nil, err)
+					}
+					err := flaggedShortLinkRepo.CreateFlaggedShortLink(context.Background(), testCase.flaggedShortLink)
+					if testCase.expHasErr {
+						assert.NotEqual(t, nil, err)
+						return
+					}
+					assert.Equal(t, nil, err)
+
+					query := fmt.Sprintf(`SELECT "%s", "%s" FROM "%s" WHERE "%s"=$1;`,
+						table.FlaggedShortLink.ColumnRiskScore,
+						table.FlaggedShortLink.ColumnFlaggedAt,
+						table.FlaggedShortLink.TableName,
+						table.FlaggedShortLink.ColumnAlias,
+					)
+					var riskScore int
+					var flaggedAt time.Time
+					err = sqlDB.QueryRow(query, testCase.flaggedShortLink.Alias).
+						Scan(&riskScore, &flaggedAt)
+					assert.Equal(t, nil, err)
+					assert.Equal(t, testCase.flaggedShortLink.RiskScore, riskScore)
+					assert.Equal(t, true, testCase.flaggedShortLink.FlaggedAt.Equal(flaggedAt))
+				},
+			)
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package sqldb
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+var _ repository.KeyCounter = (*KeyCounterSQL)(nil)
+
+const keyCounterSequence = "key_counter"
+
+// KeyCounterSQL hands out incrementing IDs from key_counter sequence through
+// SQL. The IDs are never reused, even if the transaction using them is rolled
+// back.
+type KeyCounterSQL struct {
+	db *sql.DB
+}
+
+// NextKeyID advances key_counter sequence and returns its new value.
+func (k KeyCounterSQL) NextKeyID() (uint64, error) {
+	var id int64
+	err := k.db.QueryRow(`SELECT nextval($1);`, keyCounterSequence).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(id), nil
+}
+
+// PeekKeyID returns the value NextKeyID will return without advancing
+// key_counter sequence. Concurrent callers of NextKeyID may take the value
+// before it is used.
+func (k KeyCounterSQL) PeekKeyID() (uint64, error) {
+	statement := fmt.Sprintf(`
+SELECT CASE WHEN "is_called" THEN "last_value" + 1 ELSE "last_value" END
+FROM "%s";`,
+		keyCounterSequence,
+	)
+
+	var id int64
+	err := k.db.QueryRow(statement).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(id), nil
+}
+
+// NewKeyCounterSQL creates KeyCounterSQL
+func NewKeyCounterSQL(db *sql.DB) KeyCounterSQL {
+	return KeyCounterSQL{
+		db: db,
+	}
+}
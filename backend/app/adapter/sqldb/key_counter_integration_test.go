@@ -0,0 +1,59 @@
+// +build integration all
+
+package sqldb_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/db/dbtest"
+	"github.com/short-d/short/backend/app/adapter/sqldb"
+)
+
+func TestKeyCounterSQL_NextKeyID(t *testing.T) {
+	dbtest.AccessTestDB(
+		dbConnector,
+		dbMigrationTool,
+		dbMigrationRoot,
+		dbConfig,
+		func(sqlDB *sql.DB) {
+			keyCounter := sqldb.NewKeyCounterSQL(sqlDB)
+
+			prevID, err := keyCounter.NextKeyID()
+			assert.Equal(t, nil, err)
+
+			for i := 0; i < 10; i++ {
+				id, err := keyCounter.NextKeyID()
+				assert.Equal(t, nil, err)
+				assert.Equal(t, prevID+1, id)
+				prevID = id
+			}
+		},
+	)
+}
+
+func TestKeyCounterSQL_PeekKeyID(t *testing.T) {
+	dbtest.AccessTestDB(
+		dbConnector,
+		dbMigrationTool,
+		dbMigrationRoot,
+		dbConfig,
+		func(sqlDB *sql.DB) {
+			keyCounter := sqldb.NewKeyCounterSQL(sqlDB)
+
+			for i := 0; i < 3; i++ {
+				peekedID, err := keyCounter.PeekKeyID()
+				assert.Equal(t, nil, err)
+
+				samePeekedID, err := keyCounter.PeekKeyID()
+				assert.Equal(t, nil, err)
+				assert.Equal(t, peekedID, samePeekedID)
+
+				id, err := keyCounter.NextKeyID()
+				assert.Equal(t, nil, err)
+				assert.Equal(t, peekedID, id)
+			}
+		},
+	)
+}
@@ -0,0 +1,168 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/short-d/short/backend/app/adapter/sqldb/table"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/fw/tenant"
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+var _ repository.LinkHealth = (*LinkHealthSQL)(nil)
+
+// LinkHealthSQL accesses the health of long links in link_health table
+// through SQL.
+type LinkHealthSQL struct {
+	db *sql.DB
+}
+
+// FindLinkHealthToCheck fetches the health of the long links of the short
+// links across all the tenants which have not been checked for the longest
+// time. Never checked short links come first with unknown status.
+func (l LinkHealthSQL) FindLinkHealthToCheck(limit int) ([]entity.LinkHealth, error) {
+	statement := fmt.Sprintf(`
+SELECT "%s"."%s","%s"."%s",COALESCE("%s"."%s",$2),COALESCE("%s"."%s",0),"%s"."%s"
+FROM "%s"
+LEFT JOIN "%s" ON "%s"."%s"="%s"."%s" AND "%s"."%s"="%s"."%s"
+ORDER BY "%s"."%s" ASC NULLS FIRST, "%s"."%s" ASC, "%s"."%s" ASC
+LIMIT $1;`,
+		table.ShortLink.TableName, table.ShortLink.ColumnTenantID,
+		table.ShortLink.TableName, table.ShortLink.ColumnAlias,
+		table.LinkHealth.TableName, table.LinkHealth.ColumnStatus,
+		table.LinkHealth.TableName, table.LinkHealth.ColumnConsecutiveFailures,
+		table.LinkHealth.TableName, table.LinkHealth.ColumnLastCheckedAt,
+		table.ShortLink.TableName,
+		table.LinkHealth.TableName,
+		table.ShortLink.TableName, table.ShortLink.ColumnTenantID,
+		table.LinkHealth.TableName, table.LinkHealth.ColumnTenantID,
+		table.ShortLink.TableName, table.ShortLink.ColumnAlias,
+		table.LinkHealth.TableName, table.LinkHealth.ColumnAlias,
+		table.LinkHealth.TableName, table.LinkHealth.ColumnLastCheckedAt,
+		table.ShortLink.TableName, table.ShortLink.ColumnAlias,
+		table.ShortLink.TableName, table.ShortLink.ColumnTenantID,
+	)
+
+	rows, err := l.db.Query(statement, limit, string(entity.LinkHealthUnknown))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	linkHealths := []entity.LinkHealth{}
+	for rows.Next() {
+		var (
+			linkHealth    entity.LinkHealth
+			lastCheckedAt *time.Time
+		)
+		err = rows.Scan(
+			&linkHealth.TenantID,
+			&linkHealth.Alias,
+			&linkHealth.Status,
+			&linkHealth.ConsecutiveFailures,
+			&lastCheckedAt,
+		)
+		if err != nil {
+			return linkHealths, err
+		}
+		if lastCheckedAt != nil {
+			linkHealth.LastCheckedAt = lastCheckedAt.UTC()
+		}
+		linkHealths = append(linkHealths, linkHealth)
+	}
+	return linkHealths, rows.Err()
+}
+
+// FindLinkHealthByAliases fetches the health of the long links of the given
+// short links of the tenant attached to the context from link_health table.
+// Short links never checked are skipped.
+func (l LinkHealthSQL) FindLinkHealthByAliases(ctx context.Context, aliases []string) ([]entity.LinkHealth, error) {
+	if len(aliases) == 0 {
+		return []entity.LinkHealth{}, nil
+	}
+
+	statement := fmt.Sprintf(`
+SELECT "%s","%s","%s","%s"
+FROM "%s"
+WHERE "%s"=$1 AND "%s" IN (%s);`,
+		table.LinkHealth.ColumnAlias,
+		table.LinkHealth.ColumnStatus,
+		table.LinkHealth.ColumnConsecutiveFailures,
+		table.LinkHealth.ColumnLastCheckedAt,
+		table.LinkHealth.TableName,
+		table.LinkHealth.ColumnTenantID,
+		table.LinkHealth.ColumnAlias,
+		composeParamListFrom(2, len(aliases)),
+	)
+
+	args := make([]interface{}, 0, len(aliases)+1)
+	args = append(args, tenant.FromContext(ctx))
+	for _, alias := range aliases {
+		args = append(args, alias)
+	}
+
+	rows, err := l.db.QueryContext(ctx, statement, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	linkHealths := []entity.LinkHealth{}
+	for rows.Next() {
+		linkHealth := entity.LinkHealth{TenantID: tenant.FromContext(ctx)}
+		err = rows.Scan(
+			&linkHealth.Alias,
+			&linkHealth.Status,
+			&linkHealth.ConsecutiveFailures,
+			&linkHealth.LastCheckedAt,
+		)
+		if err != nil {
+			return linkHealths, err
+		}
+		linkHealth.LastCheckedAt = linkHealth.LastCheckedAt.UTC()
+		linkHealths = append(linkHealths, linkHealth)
+	}
+	return linkHealths, rows.Err()
+}
+
+// UpsertLinkHealth creates or replaces the health of the long link of a short
+// link in link_health table.
+func (l LinkHealthSQL) UpsertLinkHealth(linkHealth entity.LinkHealth) error {
+	statement := fmt.Sprintf(`
+INSERT INTO "%s" ("%s","%s","%s","%s","%s")
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT ("%s","%s") DO UPDATE
+SET "%s"=EXCLUDED."%s", "%s"=EXCLUDED."%s", "%s"=EXCLUDED."%s";`,
+		table.LinkHealth.TableName,
+		table.LinkHealth.ColumnTenantID,
+		table.LinkHealth.ColumnAlias,
+		table.LinkHealth.ColumnStatus,
+		table.LinkHealth.ColumnConsecutiveFailures,
+		table.LinkHealth.ColumnLastCheckedAt,
+		table.LinkHealth.ColumnTenantID,
+		table.LinkHealth.ColumnAlias,
+		table.LinkHealth.ColumnStatus, table.LinkHealth.ColumnStatus,
+		table.LinkHealth.ColumnConsecutiveFailures, table.LinkHealth.ColumnConsecutiveFailures,
+		table.LinkHealth.ColumnLastCheckedAt, table.LinkHealth.ColumnLastCheckedAt,
+	)
+
+	_, err := l.db.Exec(
+		statement,
+		linkHealth.TenantID,
+		linkHealth.Alias,
+		linkHealth.Status,
+		linkHealth.ConsecutiveFailures,
+		linkHealth.LastCheckedAt.UTC(),
+	)
+	return err
+}
+
+// NewLinkHealthSQL creates LinkHealthSQL
+func NewLinkHealthSQL(db *sql.DB) LinkHealthSQL {
+	return LinkHealthSQL{
+		db: db,
+	}
+}
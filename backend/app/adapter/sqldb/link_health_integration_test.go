@@ -0,0 +1,211 @@
+// +build integration all
+
+package sqldb_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/db/dbtest"
+	"github.com/short-d/short/backend/app/adapter/sqldb"
+	"github.com/short-d/short/backend/app/entity"
+)
+
+func TestLinkHealthSQL_FindLinkHealthToCheck(t *testing.T) {
+	now := mustParseTime(t, "2020-05-01T08:02:16-07:00")
+	hourAgo := now.Add(-time.Hour)
+
+	testCases := []struct {
+		name               string
+		shortLinkTableRows []shortLinkTableRow
+		linkHealths        []entity.LinkHealth
+		limit              int
+		expectedAliases    []string
+	}{
+		{
+			name:               "no short links",
+			shortLinkTableRows: []shortLinkTableRow{},
+			linkHealths:        []entity.LinkHealth{},
+			limit:              10,
+			expectedAliases:    []string{},
+		},
+		{
+			name: "never checked short links first",
+			shortLinkTableRows: []shortLinkTableRow{
+				{
+					alias:    "220uFicCJj",
+					longLink: "https://www.google.com",
+				},
+				{
+					alias:    "xvU0K8RL5S",
+					longLink: "https://short-d.com",
+				},
+				{
+					alias:    "yDOBcj5HIPbUAsw",
+					longLink: "https://github.com",
+				},
+			},
+			linkHealths: []entity.LinkHealth{
+				{
+					Alias:         "220uFicCJj",
+					Status:        entity.LinkHealthHealthy,
+					LastCheckedAt: now,
+				},
+				{
+					Alias:         "xvU0K8RL5S",
+					Status:        entity.LinkHealthHealthy,
+					LastCheckedAt: hourAgo,
+				},
+			},
+			limit:           2,
+			expectedAliases: []string{"yDOBcj5HIPbUAsw", "xvU0K8RL5S"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			dbtest.AccessTestDB(
+				dbConnector,
+				dbMigrationTool,
+				dbMigrationRoot,
+				dbConfig,
+				func(sqlDB *sql.DB) {
+					insertShortLinkTableRows(t, sqlDB, testCase.shortLinkTableRows)
+
+					linkHealthRepo := sqldb.NewLinkHealthSQL(sqlDB)
+					for _, linkHealth := range testCase.linkHealths {
+						err := linkHealthRepo.UpsertLinkHealth(linkHealth)
+						assert.Equal(t, nil, err)
+					}
+
+					linkHealths, err := linkHealthRepo.FindLinkHealthToCheck(testCase.limit)
+					assert.Equal(t, nil, err)
+
+					aliases := []string{}
+					for _, linkHealth := range linkHealths {
+						aliases = append(aliases, linkHealth.Alias)
+					}
+					assert.Equal(t, testCase.expectedAliases, aliases)
+				})
+		})
+	}
+}
+
+func TestLinkHealthSQL_UpsertLinkHealth(t *testing.T) {
+	now := mustParseTime(t, "2020-05-01T08:02:16-07:00")
+	hourAgo := now.Add(-time.Hour)
+
+	testCases := []struct {
+		name                string
+		shortLinkTableRows  []shortLinkTableRow
+		linkHealths         []entity.LinkHealth
+		aliases             []string
+		hasErr              bool
+		expectedLinkHealths []entity.LinkHealth
+	}{
+		{
+			name: "create link health",
+			shortLinkTableRows: []shortLinkTableRow{
+				{
+					alias:    "220uFicCJj",
+					longLink: "https://www.google.com",
+				},
+			},
+			linkHealths: []entity.LinkHealth{
+				{
+					Alias:               "220uFicCJj",
+					Status:              entity.LinkHealthUnknown,
+					ConsecutiveFailures: 1,
+					LastCheckedAt:       hourAgo,
+				},
+			},
+			aliases: []string{"220uFicCJj"},
+			hasErr:  false,
+			expectedLinkHealths: []entity.LinkHealth{
+				{
+					Alias:               "220uFicCJj",
+					Status:              entity.LinkHealthUnknown,
+					ConsecutiveFailures: 1,
+					LastCheckedAt:       hourAgo.UTC(),
+				},
+			},
+		},
+		{
+			name: "replace link health",
+			shortLinkTableRows: []shortLinkTableRow{
+				{
+					alias:    "220uFicCJj",
+					longLink: "https://www.google.com",
+				},
+			},
+			linkHealths: []entity.LinkHealth{
+				{
+					Alias:               "220uFicCJj",
+					Status:              entity.LinkHealthUnknown,
+					ConsecutiveFailures: 2,
+					LastCheckedAt:       hourAgo,
+				},
+				{
+					Alias:               "220uFicCJj",
+					Status:              entity.LinkHealthBroken,
+					ConsecutiveFailures: 3,
+					LastCheckedAt:       now,
+				},
+			},
+			aliases: []string{"220uFicCJj"},
+			hasErr:  false,
+			expectedLinkHealths: []entity.LinkHealth{
+				{
+					Alias:               "220uFicCJj",
+					Status:              entity.LinkHealthBroken,
+					ConsecutiveFailures: 3,
+					LastCheckedAt:       now.UTC(),
+				},
+			},
+		},
+		{
+			name:               "short link does not exist",
+			shortLinkTableRows: []shortLinkTableRow{},
+			linkHealths: []entity.LinkHealth{
+				{
+					Alias:         "220uFicCJj",
+					Status:        entity.LinkHealthHealthy,
+					LastCheckedAt: now,
+				},
+			},
+			aliases: []string{"220uFicCJj"},
+			hasErr:  true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			dbtest.AccessTestDB(
+				dbConnector,
+				dbMigrationTool,
+				dbMigrationRoot,
+				dbConfig,
+				func(sqlDB *sql.DB) {
+					insertShortLinkTableRows(t, sqlDB, testCase.shortLinkTableRows)
+
+					linkHealthRepo := sqldb.NewLinkHealthSQL(sqlDB)
+					var err error
+					for _, linkHealth := range testCase.linkHealths {
+						err = linkHealthRepo.UpsertLinkHealth(linkHealth)
+					}
+					if testCase.hasErr {
+						assert.NotEqual(t, nil, err)
+						return
+					}
+					assert.Equal(t, nil, err)
+
+					linkHealths, err := linkHealthRepo.FindLinkHealthByAliases(context.Background(), testCase.aliases)
+					assert.Equal(t, nil, err)
+					assert.Equal(t, testCase.expectedLinkHealths, linkHealths)
+				})
+		})
+	}
+}
@@ -0,0 +1,12 @@
+-- +migrate Up
+CREATE TABLE "visit"
+(
+    "id"         BIGSERIAL PRIMARY KEY,
+    "alias"      CHARACTER VARYING(50)    NOT NULL,
+    "visited_at" TIMESTAMP WITH TIME ZONE NOT NULL,
+    FOREIGN KEY ("alias") REFERENCES "short_link" ("alias") ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE INDEX "visit_alias_visited_at_idx" ON "visit" ("alias", "visited_at");
+
+-- +migrate Down
+DROP TABLE "visit";
@@ -0,0 +1,11 @@
+-- +migrate Up
+ALTER TABLE "visit"
+    ADD COLUMN "ip_address" CHARACTER VARYING(39) NOT NULL DEFAULT '';
+ALTER TABLE "visit"
+    ADD COLUMN "country_code" CHARACTER VARYING(2) NOT NULL DEFAULT '';
+
+-- +migrate Down
+ALTER TABLE "visit"
+    DROP COLUMN "country_code";
+ALTER TABLE "visit"
+    DROP COLUMN "ip_address";
@@ -0,0 +1,9 @@
+-- +migrate Up
+ALTER TABLE "visit"
+    ADD COLUMN "referrer" CHARACTER VARYING(253) NOT NULL DEFAULT '';
+CREATE INDEX "visit_alias_referrer_idx" ON "visit" ("alias", "referrer");
+
+-- +migrate Down
+DROP INDEX "visit_alias_referrer_idx";
+ALTER TABLE "visit"
+    DROP COLUMN "referrer";
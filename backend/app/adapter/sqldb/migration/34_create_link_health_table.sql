@@ -0,0 +1,13 @@
+-- +migrate Up
+CREATE TABLE "link_health"
+(
+    "alias"                CHARACTER VARYING(50)    PRIMARY KEY,
+    "status"               CHARACTER VARYING(10)    NOT NULL,
+    "consecutive_failures" INTEGER                  NOT NULL DEFAULT 0,
+    "last_checked_at"      TIMESTAMP WITH TIME ZONE NOT NULL,
+    FOREIGN KEY ("alias") REFERENCES "short_link" ("alias") ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE INDEX "link_health_last_checked_at_idx" ON "link_health" ("last_checked_at");
+
+-- +migrate Down
+DROP TABLE "link_health";
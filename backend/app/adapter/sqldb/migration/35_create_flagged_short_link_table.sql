@@ -0,0 +1,11 @@
+-- +migrate Up
+CREATE TABLE "flagged_short_link"
+(
+    "alias"      CHARACTER VARYING(50)    PRIMARY KEY,
+    "risk_score" INTEGER                  NOT NULL,
+    "flagged_at" TIMESTAMP WITH TIME ZONE NOT NULL,
+    FOREIGN KEY ("alias") REFERENCES "short_link" ("alias") ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+-- +migrate Down
+DROP TABLE "flagged_short_link";
@@ -0,0 +1,5 @@
+-- +migrate Up
+CREATE SEQUENCE "key_counter";
+
+-- +migrate Down
+DROP SEQUENCE "key_counter";
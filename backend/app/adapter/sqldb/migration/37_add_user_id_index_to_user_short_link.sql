@@ -0,0 +1,5 @@
+-- +migrate Up
+CREATE INDEX "user_short_link_user_id_idx" ON "user_short_link" ("user_id");
+
+-- +migrate Down
+DROP INDEX "user_short_link_user_id_idx";
@@ -0,0 +1,11 @@
+-- +migrate Up
+CREATE TABLE "guest_short_link"
+(
+    "session_id"       CHARACTER VARYING(64) NOT NULL,
+    "short_link_alias" CHARACTER VARYING(50) PRIMARY KEY,
+    FOREIGN KEY ("short_link_alias") REFERENCES "short_link" ("alias") ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE INDEX "guest_short_link_session_id_idx" ON "guest_short_link" ("session_id");
+
+-- +migrate Down
+DROP TABLE "guest_short_link";
@@ -0,0 +1,5 @@
+-- +migrate Up
+CREATE INDEX "short_link_long_link_idx" ON "short_link" USING HASH ("long_link");
+
+-- +migrate Down
+DROP INDEX "short_link_long_link_idx";
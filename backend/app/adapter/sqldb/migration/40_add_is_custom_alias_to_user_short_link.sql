@@ -0,0 +1,7 @@
+-- +migrate Up
+ALTER TABLE "user_short_link"
+    ADD COLUMN "is_custom_alias" BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +migrate Down
+ALTER TABLE "user_short_link"
+    DROP COLUMN "is_custom_alias";
@@ -0,0 +1,16 @@
+-- +migrate Up
+ALTER TABLE "user"
+    ADD COLUMN "email_verified" BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Accounts signed up through an identity provider before emails were verified
+-- got their emails from the provider, so they are trusted as verified.
+UPDATE "user"
+SET "email_verified" = TRUE
+WHERE "email" <> ''
+  AND ("id" IN (SELECT short_user_id FROM github_sso)
+    OR "id" IN (SELECT short_user_id FROM facebook_sso)
+    OR "id" IN (SELECT short_user_id FROM google_sso));
+
+-- +migrate Down
+ALTER TABLE "user"
+    DROP COLUMN "email_verified";
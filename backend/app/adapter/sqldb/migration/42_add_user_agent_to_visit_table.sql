@@ -0,0 +1,15 @@
+-- +migrate Up
+ALTER TABLE "visit"
+    ADD COLUMN "browser" CHARACTER VARYING(50) NOT NULL DEFAULT '';
+ALTER TABLE "visit"
+    ADD COLUMN "os" CHARACTER VARYING(50) NOT NULL DEFAULT '';
+ALTER TABLE "visit"
+    ADD COLUMN "device_class" CHARACTER VARYING(20) NOT NULL DEFAULT '';
+
+-- +migrate Down
+ALTER TABLE "visit"
+    DROP COLUMN "device_class";
+ALTER TABLE "visit"
+    DROP COLUMN "os";
+ALTER TABLE "visit"
+    DROP COLUMN "browser";
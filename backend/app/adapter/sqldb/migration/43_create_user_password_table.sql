@@ -0,0 +1,10 @@
+-- +migrate Up
+CREATE TABLE "user_password"
+(
+    "user_id"       CHARACTER VARYING(5) PRIMARY KEY,
+    "password_hash" CHARACTER VARYING(60) NOT NULL,
+    FOREIGN KEY ("user_id") REFERENCES "user" ("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+-- +migrate Down
+DROP TABLE "user_password";
@@ -0,0 +1,7 @@
+-- +migrate Up
+ALTER TABLE "user"
+    ADD COLUMN "tokens_revoked_at" TIMESTAMP WITH TIME ZONE;
+
+-- +migrate Down
+ALTER TABLE "user"
+    DROP COLUMN "tokens_revoked_at";
@@ -0,0 +1,7 @@
+-- +migrate Up
+ALTER TABLE "short_link"
+    ADD COLUMN "track_visits" BOOLEAN NOT NULL DEFAULT TRUE;
+
+-- +migrate Down
+ALTER TABLE "short_link"
+    DROP COLUMN "track_visits";
@@ -0,0 +1,11 @@
+-- +migrate Up
+CREATE TABLE "user_preferences"
+(
+    "user_id"                      CHARACTER VARYING(5) PRIMARY KEY,
+    "default_expire_after_seconds" BIGINT,
+    "default_track_visits"         BOOLEAN,
+    FOREIGN KEY ("user_id") REFERENCES "user" ("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+-- +migrate Down
+DROP TABLE "user_preferences";
@@ -0,0 +1,12 @@
+-- +migrate Up
+CREATE TABLE apple_sso
+(
+    apple_user_id CHARACTER VARYING(254) NOT NULL UNIQUE,
+    short_user_id CHARACTER VARYING(5) NOT NULL UNIQUE,
+    FOREIGN KEY (short_user_id) REFERENCES "user"(id)
+        ON UPDATE CASCADE
+        ON DELETE CASCADE
+);
+
+-- +migrate Down
+DROP TABLE apple_sso;
\ No newline at end of file
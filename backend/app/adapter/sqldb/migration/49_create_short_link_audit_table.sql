@@ -0,0 +1,13 @@
+-- +migrate Up
+CREATE TABLE "short_link_audit"
+(
+    "id"         SERIAL                   PRIMARY KEY,
+    "alias"      CHARACTER VARYING(50)    NOT NULL,
+    "action"     CHARACTER VARYING(20)    NOT NULL,
+    "reason"     TEXT                     NOT NULL,
+    "user_id"    CHARACTER VARYING(5)     NOT NULL,
+    "created_at" TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- +migrate Down
+DROP TABLE "short_link_audit";
@@ -0,0 +1,7 @@
+-- +migrate Up
+ALTER TABLE "short_link"
+    ADD COLUMN "visit_count" BIGINT NOT NULL DEFAULT 0;
+
+-- +migrate Down
+ALTER TABLE "short_link"
+    DROP COLUMN "visit_count";
@@ -0,0 +1,7 @@
+-- +migrate Up
+ALTER TABLE "short_link"
+    ADD COLUMN "original_long_link" TEXT NOT NULL DEFAULT '';
+
+-- +migrate Down
+ALTER TABLE "short_link"
+    DROP COLUMN "original_long_link";
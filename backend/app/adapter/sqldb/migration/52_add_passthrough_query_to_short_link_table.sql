@@ -0,0 +1,7 @@
+-- +migrate Up
+ALTER TABLE "short_link"
+    ADD COLUMN "passthrough_query" BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +migrate Down
+ALTER TABLE "short_link"
+    DROP COLUMN "passthrough_query";
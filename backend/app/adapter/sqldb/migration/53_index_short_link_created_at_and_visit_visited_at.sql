@@ -0,0 +1,7 @@
+-- +migrate Up
+CREATE INDEX "short_link_created_at_idx" ON "short_link" ("created_at");
+CREATE INDEX "visit_visited_at_idx" ON "visit" ("visited_at");
+
+-- +migrate Down
+DROP INDEX "visit_visited_at_idx";
+DROP INDEX "short_link_created_at_idx";
@@ -0,0 +1,10 @@
+-- +migrate Up
+CREATE TABLE "alias_skeleton"
+(
+    "alias"    CHARACTER VARYING(50) PRIMARY KEY,
+    "skeleton" CHARACTER VARYING(50) NOT NULL
+);
+CREATE INDEX "alias_skeleton_skeleton_idx" ON "alias_skeleton" ("skeleton");
+
+-- +migrate Down
+DROP TABLE "alias_skeleton";
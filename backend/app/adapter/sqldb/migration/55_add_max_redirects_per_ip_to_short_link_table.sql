@@ -0,0 +1,7 @@
+-- +migrate Up
+ALTER TABLE "short_link"
+    ADD COLUMN "max_redirects_per_ip" INTEGER NOT NULL DEFAULT 0;
+
+-- +migrate Down
+ALTER TABLE "short_link"
+    DROP COLUMN "max_redirects_per_ip";
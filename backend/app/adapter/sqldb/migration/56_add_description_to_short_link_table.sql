@@ -0,0 +1,7 @@
+-- +migrate Up
+ALTER TABLE "short_link"
+    ADD COLUMN "description" TEXT NOT NULL DEFAULT '';
+
+-- +migrate Down
+ALTER TABLE "short_link"
+    DROP COLUMN "description";
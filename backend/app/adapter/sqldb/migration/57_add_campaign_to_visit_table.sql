@@ -0,0 +1,15 @@
+-- +migrate Up
+ALTER TABLE "visit"
+    ADD COLUMN "utm_source" CHARACTER VARYING(100) NOT NULL DEFAULT '';
+ALTER TABLE "visit"
+    ADD COLUMN "utm_medium" CHARACTER VARYING(100) NOT NULL DEFAULT '';
+ALTER TABLE "visit"
+    ADD COLUMN "utm_campaign" CHARACTER VARYING(100) NOT NULL DEFAULT '';
+
+-- +migrate Down
+ALTER TABLE "visit"
+    DROP COLUMN "utm_campaign";
+ALTER TABLE "visit"
+    DROP COLUMN "utm_medium";
+ALTER TABLE "visit"
+    DROP COLUMN "utm_source";
@@ -0,0 +1,187 @@
+-- +migrate Up
+ALTER TABLE "user_short_link"
+    DROP CONSTRAINT "user_url_relation_url_alias_fkey";
+ALTER TABLE "public_short_link"
+    DROP CONSTRAINT "public_url_alias_fkey";
+ALTER TABLE "visit"
+    DROP CONSTRAINT "visit_alias_fkey";
+ALTER TABLE "link_health"
+    DROP CONSTRAINT "link_health_alias_fkey";
+ALTER TABLE "flagged_short_link"
+    DROP CONSTRAINT "flagged_short_link_alias_fkey";
+ALTER TABLE "guest_short_link"
+    DROP CONSTRAINT "guest_short_link_short_link_alias_fkey";
+
+ALTER TABLE "short_link"
+    ADD COLUMN "tenant_id" CHARACTER VARYING(50) NOT NULL DEFAULT '';
+ALTER TABLE "short_link"
+    DROP CONSTRAINT "Url_pkey";
+ALTER TABLE "short_link"
+    ADD CONSTRAINT "short_link_pkey" PRIMARY KEY ("tenant_id", "alias");
+
+ALTER TABLE "user_short_link"
+    ADD COLUMN "tenant_id" CHARACTER VARYING(50) NOT NULL DEFAULT '';
+ALTER TABLE "user_short_link"
+    DROP CONSTRAINT "pk_user_url_relation";
+ALTER TABLE "user_short_link"
+    ADD CONSTRAINT "pk_user_short_link" PRIMARY KEY ("tenant_id", "short_link_alias", "user_id");
+ALTER TABLE "user_short_link"
+    ADD CONSTRAINT "user_short_link_short_link_fkey"
+    FOREIGN KEY ("tenant_id", "short_link_alias") REFERENCES "short_link" ("tenant_id", "alias")
+        ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE "public_short_link"
+    ADD COLUMN "tenant_id" CHARACTER VARYING(50) NOT NULL DEFAULT '';
+ALTER TABLE "public_short_link"
+    ADD CONSTRAINT "public_short_link_short_link_fkey"
+    FOREIGN KEY ("tenant_id", "alias") REFERENCES "short_link" ("tenant_id", "alias")
+        ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE "visit"
+    ADD COLUMN "tenant_id" CHARACTER VARYING(50) NOT NULL DEFAULT '';
+ALTER TABLE "visit"
+    ADD CONSTRAINT "visit_short_link_fkey"
+    FOREIGN KEY ("tenant_id", "alias") REFERENCES "short_link" ("tenant_id", "alias")
+        ON DELETE CASCADE ON UPDATE CASCADE;
+DROP INDEX "visit_alias_visited_at_idx";
+CREATE INDEX "visit_tenant_id_alias_visited_at_idx" ON "visit" ("tenant_id", "alias", "visited_at");
+
+ALTER TABLE "link_health"
+    ADD COLUMN "tenant_id" CHARACTER VARYING(50) NOT NULL DEFAULT '';
+ALTER TABLE "link_health"
+    DROP CONSTRAINT "link_health_pkey";
+ALTER TABLE "link_health"
+    ADD CONSTRAINT "link_health_pkey" PRIMARY KEY ("tenant_id", "alias");
+ALTER TABLE "link_health"
+    ADD CONSTRAINT "link_health_short_link_fkey"
+    FOREIGN KEY ("tenant_id", "alias") REFERENCES "short_link" ("tenant_id", "alias")
+        ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE "flagged_short_link"
+    ADD COLUMN "tenant_id" CHARACTER VARYING(50) NOT NULL DEFAULT '';
+ALTER TABLE "flagged_short_link"
+    DROP CONSTRAINT "flagged_short_link_pkey";
+ALTER TABLE "flagged_short_link"
+    ADD CONSTRAINT "flagged_short_link_pkey" PRIMARY KEY ("tenant_id", "alias");
+ALTER TABLE "flagged_short_link"
+    ADD CONSTRAINT "flagged_short_link_short_link_fkey"
+    FOREIGN KEY ("tenant_id", "alias") REFERENCES "short_link" ("tenant_id", "alias")
+        ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE "guest_short_link"
+    ADD COLUMN "tenant_id" CHARACTER VARYING(50) NOT NULL DEFAULT '';
+ALTER TABLE "guest_short_link"
+    DROP CONSTRAINT "guest_short_link_pkey";
+ALTER TABLE "guest_short_link"
+    ADD CONSTRAINT "guest_short_link_pkey" PRIMARY KEY ("tenant_id", "short_link_alias");
+ALTER TABLE "guest_short_link"
+    ADD CONSTRAINT "guest_short_link_short_link_fkey"
+    FOREIGN KEY ("tenant_id", "short_link_alias") REFERENCES "short_link" ("tenant_id", "alias")
+        ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE "alias_skeleton"
+    ADD COLUMN "tenant_id" CHARACTER VARYING(50) NOT NULL DEFAULT '';
+ALTER TABLE "alias_skeleton"
+    DROP CONSTRAINT "alias_skeleton_pkey";
+ALTER TABLE "alias_skeleton"
+    ADD CONSTRAINT "alias_skeleton_pkey" PRIMARY KEY ("tenant_id", "alias");
+
+ALTER TABLE "short_link_audit"
+    ADD COLUMN "tenant_id" CHARACTER VARYING(50) NOT NULL DEFAULT '';
+
+ALTER TABLE "user"
+    ADD COLUMN "tenant_id" CHARACTER VARYING(50) NOT NULL DEFAULT '';
+
+-- +migrate Down
+ALTER TABLE "user"
+    DROP COLUMN "tenant_id";
+
+ALTER TABLE "short_link_audit"
+    DROP COLUMN "tenant_id";
+
+ALTER TABLE "alias_skeleton"
+    DROP CONSTRAINT "alias_skeleton_pkey";
+ALTER TABLE "alias_skeleton"
+    DROP COLUMN "tenant_id";
+ALTER TABLE "alias_skeleton"
+    ADD CONSTRAINT "alias_skeleton_pkey" PRIMARY KEY ("alias");
+
+ALTER TABLE "guest_short_link"
+    DROP CONSTRAINT "guest_short_link_short_link_fkey";
+ALTER TABLE "guest_short_link"
+    DROP CONSTRAINT "guest_short_link_pkey";
+ALTER TABLE "guest_short_link"
+    DROP COLUMN "tenant_id";
+ALTER TABLE "guest_short_link"
+    ADD CONSTRAINT "guest_short_link_pkey" PRIMARY KEY ("short_link_alias");
+
+ALTER TABLE "flagged_short_link"
+    DROP CONSTRAINT "flagged_short_link_short_link_fkey";
+ALTER TABLE "flagged_short_link"
+    DROP CONSTRAINT "flagged_short_link_pkey";
+ALTER TABLE "flagged_short_link"
+    DROP COLUMN "tenant_id";
+ALTER TABLE "flagged_short_link"
+    ADD CONSTRAINT "flagged_short_link_pkey" PRIMARY KEY ("alias");
+
+ALTER TABLE "link_health"
+    DROP CONSTRAINT "link_health_short_link_fkey";
+ALTER TABLE "link_health"
+    DROP CONSTRAINT "link_health_pkey";
+ALTER TABLE "link_health"
+    DROP COLUMN "tenant_id";
+ALTER TABLE "link_health"
+    ADD CONSTRAINT "link_health_pkey" PRIMARY KEY ("alias");
+
+DROP INDEX "visit_tenant_id_alias_visited_at_idx";
+ALTER TABLE "visit"
+    DROP CONSTRAINT "visit_short_link_fkey";
+ALTER TABLE "visit"
+    DROP COLUMN "tenant_id";
+CREATE INDEX "visit_alias_visited_at_idx" ON "visit" ("alias", "visited_at");
+
+ALTER TABLE "public_short_link"
+    DROP CONSTRAINT "public_short_link_short_link_fkey";
+ALTER TABLE "public_short_link"
+    DROP COLUMN "tenant_id";
+
+ALTER TABLE "user_short_link"
+    DROP CONSTRAINT "user_short_link_short_link_fkey";
+ALTER TABLE "user_short_link"
+    DROP CONSTRAINT "pk_user_short_link";
+ALTER TABLE "user_short_link"
+    DROP COLUMN "tenant_id";
+ALTER TABLE "user_short_link"
+    ADD CONSTRAINT "pk_user_url_relation" PRIMARY KEY ("short_link_alias", "user_id");
+
+ALTER TABLE "short_link"
+    DROP CONSTRAINT "short_link_pkey";
+ALTER TABLE "short_link"
+    DROP COLUMN "tenant_id";
+ALTER TABLE "short_link"
+    ADD CONSTRAINT "Url_pkey" PRIMARY KEY ("alias");
+
+ALTER TABLE "user_short_link"
+    ADD CONSTRAINT "user_url_relation_url_alias_fkey"
+    FOREIGN KEY ("short_link_alias") REFERENCES "short_link" ("alias")
+        ON DELETE CASCADE ON UPDATE CASCADE;
+ALTER TABLE "public_short_link"
+    ADD CONSTRAINT "public_url_alias_fkey"
+    FOREIGN KEY ("alias") REFERENCES "short_link" ("alias")
+        ON DELETE CASCADE ON UPDATE CASCADE;
+ALTER TABLE "visit"
+    ADD CONSTRAINT "visit_alias_fkey"
+    FOREIGN KEY ("alias") REFERENCES "short_link" ("alias")
+        ON DELETE CASCADE ON UPDATE CASCADE;
+ALTER TABLE "link_health"
+    ADD CONSTRAINT "link_health_alias_fkey"
+    FOREIGN KEY ("alias") REFERENCES "short_link" ("alias")
+        ON DELETE CASCADE ON UPDATE CASCADE;
+ALTER TABLE "flagged_short_link"
+    ADD CONSTRAINT "flagged_short_link_alias_fkey"
+    FOREIGN KEY ("alias") REFERENCES "short_link" ("alias")
+        ON DELETE CASCADE ON UPDATE CASCADE;
+ALTER TABLE "guest_short_link"
+    ADD CONSTRAINT "guest_short_link_short_link_alias_fkey"
+    FOREIGN KEY ("short_link_alias") REFERENCES "short_link" ("alias")
+        ON DELETE CASCADE ON UPDATE CASCADE;
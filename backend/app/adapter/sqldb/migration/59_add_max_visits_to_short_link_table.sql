@@ -0,0 +1,11 @@
+-- +migrate Up
+ALTER TABLE "short_link"
+    ADD COLUMN "max_visits" INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE "short_link"
+    ADD COLUMN "used_visits" INTEGER NOT NULL DEFAULT 0;
+
+-- +migrate Down
+ALTER TABLE "short_link"
+    DROP COLUMN "used_visits";
+ALTER TABLE "short_link"
+    DROP COLUMN "max_visits";
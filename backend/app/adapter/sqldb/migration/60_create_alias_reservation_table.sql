@@ -0,0 +1,13 @@
+-- +migrate Up
+CREATE TABLE "alias_reservation"
+(
+    "tenant_id" CHARACTER VARYING(50)    NOT NULL DEFAULT '',
+    "alias"     CHARACTER VARYING(50)    NOT NULL,
+    "user_id"   CHARACTER VARYING(5)     NOT NULL,
+    "token"     CHARACTER VARYING(64)    NOT NULL,
+    "expire_at" TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY ("tenant_id", "alias")
+);
+
+-- +migrate Down
+DROP TABLE "alias_reservation";
@@ -0,0 +1,24 @@
+package sqldb
+
+import (
+	"fmt"
+	"strings"
+)
+
+// composeParamList converts an slice to a parameters string with format: $1, $2, $3, ...
+func composeParamList(numParams int) string {
+	return composeParamListFrom(1, numParams)
+}
+
+// composeParamListFrom converts an slice to a parameters string starting from
+// the given position, such as $2, $3, $4, ... when some parameters precede the
+// list.
+func composeParamListFrom(start int, numParams int) string {
+	params := make([]string, 0, numParams)
+	for i := 0; i < numParams; i++ {
+		params = append(params, fmt.Sprintf("$%d", start+i))
+	}
+
+	parameterStr := strings.Join(params, ", ")
+	return parameterStr
+}
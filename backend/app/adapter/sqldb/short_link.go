@@ -1,89 +1,107 @@
 package sqldb
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/short-d/short/backend/app/adapter/sqldb/table"
 	"github.com/short-d/short/backend/app/entity"
 	"github.com/short-d/short/backend/app/entity/metatag"
+	"github.com/short-d/short/backend/app/fw/tenant"
 	"github.com/short-d/short/backend/app/usecase/repository"
 )
 
 var _ repository.ShortLink = (*ShortLinkSQL)(nil)
 
 // ShortLinkSQL accesses ShortLink information in short_link table through SQL.
+// Only the short links of the tenant attached to the context are accessed.
 type ShortLinkSQL struct {
-	db *sql.DB
+	db           *sql.DB
+	slowQueryLog SlowQueryLog
 }
 
 // UpdateOpenGraphTags updates OpenGraph meta tags for a given short link.
-func (s ShortLinkSQL) UpdateOpenGraphTags(alias string, openGraphTags metatag.OpenGraph) (entity.ShortLink, error) {
+func (s ShortLinkSQL) UpdateOpenGraphTags(ctx context.Context, alias string, openGraphTags metatag.OpenGraph) (entity.ShortLink, error) {
+	defer s.slowQueryLog.track("short_link.UpdateOpenGraphTags")()
+
 	statement := fmt.Sprintf(`
 UPDATE "%s"
 SET "%s"=$1, "%s"=$2, "%s"=$3
-WHERE "%s"=$4;`,
+WHERE "%s"=$4 AND "%s"=$5;`,
 		table.ShortLink.TableName,
 		table.ShortLink.ColumnOpenGraphTitle,
 		table.ShortLink.ColumnOpenGraphDescription,
 		table.ShortLink.ColumnOpenGraphImageURL,
+		table.ShortLink.ColumnTenantID,
 		table.ShortLink.ColumnAlias,
 	)
 
-	_, err := s.db.Exec(
+	_, err := s.db.ExecContext(
+		ctx,
 		statement,
 		openGraphTags.Title,
 		openGraphTags.Description,
 		openGraphTags.ImageURL,
+		tenant.FromContext(ctx),
 		alias,
 	)
 	if err != nil {
 		return entity.ShortLink{}, err
 	}
 
-	return s.GetShortLinkByAlias(alias)
+	return s.GetShortLinkByAlias(ctx, alias)
 }
 
 // UpdateTwitterTags updates Twitter meta tags for a given short link.
-func (s ShortLinkSQL) UpdateTwitterTags(alias string, twitterTags metatag.Twitter) (entity.ShortLink, error) {
+func (s ShortLinkSQL) UpdateTwitterTags(ctx context.Context, alias string, twitterTags metatag.Twitter) (entity.ShortLink, error) {
+	defer s.slowQueryLog.track("short_link.UpdateTwitterTags")()
+
 	statement := fmt.Sprintf(`
 UPDATE "%s"
 SET "%s"=$1, "%s"=$2, "%s"=$3
-WHERE "%s"=$4;`,
+WHERE "%s"=$4 AND "%s"=$5;`,
 		table.ShortLink.TableName,
 		table.ShortLink.ColumnTwitterTitle,
 		table.ShortLink.ColumnTwitterDescription,
 		table.ShortLink.ColumnTwitterImageURL,
+		table.ShortLink.ColumnTenantID,
 		table.ShortLink.ColumnAlias,
 	)
 
-	_, err := s.db.Exec(
+	_, err := s.db.ExecContext(
+		ctx,
 		statement,
 		twitterTags.Title,
 		twitterTags.Description,
 		twitterTags.ImageURL,
+		tenant.FromContext(ctx),
 		alias,
 	)
 	if err != nil {
 		return entity.ShortLink{}, err
 	}
 
-	return s.GetShortLinkByAlias(alias)
+	return s.GetShortLinkByAlias(ctx, alias)
 }
 
 // IsAliasExist checks whether a given alias exist in short_link table.
-func (s ShortLinkSQL) IsAliasExist(alias string) (bool, error) {
+func (s ShortLinkSQL) IsAliasExist(ctx context.Context, alias string) (bool, error) {
+	defer s.slowQueryLog.track("short_link.IsAliasExist")()
+
 	query := fmt.Sprintf(`
 SELECT "%s" 
 FROM "%s" 
-WHERE "%s"=$1;`,
+WHERE "%s"=$1 AND "%s"=$2;`,
 		table.ShortLink.ColumnAlias,
 		table.ShortLink.TableName,
+		table.ShortLink.ColumnTenantID,
 		table.ShortLink.ColumnAlias,
 	)
 
-	err := s.db.QueryRow(query, alias).Scan(&alias)
+	err := s.db.QueryRowContext(ctx, query, tenant.FromContext(ctx), alias).Scan(&alias)
 	if err == sql.ErrNoRows {
 		return false, nil
 	}
@@ -94,46 +112,82 @@ WHERE "%s"=$1;`,
 }
 
 // CreateShortLink inserts a new ShortLink into short_link table.
-func (s ShortLinkSQL) CreateShortLink(shortLinkInput entity.ShortLinkInput) error {
+func (s ShortLinkSQL) CreateShortLink(ctx context.Context, shortLinkInput entity.ShortLinkInput) error {
+	defer s.slowQueryLog.track("short_link.CreateShortLink")()
+
 	statement := fmt.Sprintf(`
-INSERT INTO "%s" ("%s","%s","%s","%s")
-VALUES ($1, $2, $3, $4);`,
+INSERT INTO "%s" ("%s","%s","%s","%s","%s","%s","%s","%s","%s","%s","%s","%s")
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12);`,
 		table.ShortLink.TableName,
+		table.ShortLink.ColumnTenantID,
 		table.ShortLink.ColumnAlias,
 		table.ShortLink.ColumnLongLink,
+		table.ShortLink.ColumnOriginalLongLink,
 		table.ShortLink.ColumnExpireAt,
 		table.ShortLink.ColumnCreatedAt,
+		table.ShortLink.ColumnTrackVisits,
+		table.ShortLink.ColumnPassthroughQuery,
+		table.ShortLink.ColumnPassthroughPath,
+		table.ShortLink.ColumnMaxRedirectsPerIP,
+		table.ShortLink.ColumnMaxVisits,
+		table.ShortLink.ColumnDescription,
 	)
-	_, err := s.db.Exec(
+	_, err := s.db.ExecContext(
+		ctx,
 		statement,
+		tenant.FromContext(ctx),
 		shortLinkInput.GetCustomAlias(""),
 		shortLinkInput.GetLongLink(""),
+		shortLinkInput.GetOriginalLongLink(""),
 		shortLinkInput.ExpireAt,
 		shortLinkInput.CreatedAt,
+		shortLinkInput.GetTrackVisits(true),
+		shortLinkInput.GetPassthroughQuery(false),
+		shortLinkInput.GetPassthroughPath(false),
+		shortLinkInput.GetMaxRedirectsPerIP(0),
+		shortLinkInput.GetMaxVisits(0),
+		shortLinkInput.GetDescription(""),
 	)
 	return err
 }
 
 // UpdateShortLink updates a ShortLink that exists within the short_link table.
-func (s ShortLinkSQL) UpdateShortLink(oldAlias string, shortLinkInput entity.ShortLinkInput) (entity.ShortLink, error) {
+func (s ShortLinkSQL) UpdateShortLink(ctx context.Context, oldAlias string, shortLinkInput entity.ShortLinkInput) (entity.ShortLink, error) {
+	defer s.slowQueryLog.track("short_link.UpdateShortLink")()
+
 	statement := fmt.Sprintf(`
 UPDATE "%s"
-SET "%s"=$1, "%s"=$2, "%s"=$3, "%s"=$4
-WHERE "%s"=$5;`,
+SET "%s"=$1, "%s"=$2, "%s"=$3, "%s"=$4, "%s"=$5, "%s"=$6, "%s"=$7, "%s"=$8, "%s"=$9, "%s"=$10
+WHERE "%s"=$11 AND "%s"=$12;`,
 		table.ShortLink.TableName,
 		table.ShortLink.ColumnAlias,
 		table.ShortLink.ColumnLongLink,
+		table.ShortLink.ColumnOriginalLongLink,
 		table.ShortLink.ColumnExpireAt,
 		table.ShortLink.ColumnUpdatedAt,
+		table.ShortLink.ColumnTrackVisits,
+		table.ShortLink.ColumnPassthroughQuery,
+		table.ShortLink.ColumnPassthroughPath,
+		table.ShortLink.ColumnMaxRedirectsPerIP,
+		table.ShortLink.ColumnDescription,
+		table.ShortLink.ColumnTenantID,
 		table.ShortLink.ColumnAlias,
 	)
 
-	_, err := s.db.Exec(
+	_, err := s.db.ExecContext(
+		ctx,
 		statement,
 		shortLinkInput.GetCustomAlias(""),
 		shortLinkInput.GetLongLink(""),
+		shortLinkInput.GetOriginalLongLink(""),
 		shortLinkInput.ExpireAt,
 		shortLinkInput.UpdatedAt,
+		shortLinkInput.GetTrackVisits(true),
+		shortLinkInput.GetPassthroughQuery(false),
+		shortLinkInput.GetPassthroughPath(false),
+		shortLinkInput.GetMaxRedirectsPerIP(0),
+		shortLinkInput.GetDescription(""),
+		tenant.FromContext(ctx),
 		oldAlias,
 	)
 
@@ -142,19 +196,28 @@ WHERE "%s"=$5;`,
 	}
 
 	return entity.ShortLink{
-		Alias:     shortLinkInput.GetCustomAlias(""),
-		LongLink:  shortLinkInput.GetLongLink(""),
-		ExpireAt:  shortLinkInput.ExpireAt,
-		UpdatedAt: shortLinkInput.UpdatedAt,
+		TenantID:          tenant.FromContext(ctx),
+		Alias:             shortLinkInput.GetCustomAlias(""),
+		LongLink:          shortLinkInput.GetLongLink(""),
+		OriginalLongLink:  shortLinkInput.GetOriginalLongLink(""),
+		ExpireAt:          shortLinkInput.ExpireAt,
+		UpdatedAt:         shortLinkInput.UpdatedAt,
+		TrackVisits:       shortLinkInput.GetTrackVisits(true),
+		PassthroughQuery:  shortLinkInput.GetPassthroughQuery(false),
+		PassthroughPath:   shortLinkInput.GetPassthroughPath(false),
+		MaxRedirectsPerIP: shortLinkInput.GetMaxRedirectsPerIP(0),
+		Description:       shortLinkInput.GetDescription(""),
 	}, nil
 }
 
 // GetShortLinkByAlias finds an ShortLink in short_link table given alias.
-func (s ShortLinkSQL) GetShortLinkByAlias(alias string) (entity.ShortLink, error) {
+func (s ShortLinkSQL) GetShortLinkByAlias(ctx context.Context, alias string) (entity.ShortLink, error) {
+	defer s.slowQueryLog.track("short_link.GetShortLinkByAlias")()
+
 	statement := fmt.Sprintf(`
-SELECT "%s","%s","%s","%s","%s","%s","%s","%s","%s","%s","%s"
+SELECT "%s","%s","%s","%s","%s","%s","%s","%s","%s","%s","%s","%s","%s","%s","%s","%s","%s","%s","%s"
 FROM "%s" 
-WHERE "%s"=$1;`,
+WHERE "%s"=$1 AND "%s"=$2;`,
 		table.ShortLink.ColumnAlias,
 		table.ShortLink.ColumnLongLink,
 		table.ShortLink.ColumnExpireAt,
@@ -166,13 +229,22 @@ WHERE "%s"=$1;`,
 		table.ShortLink.ColumnTwitterTitle,
 		table.ShortLink.ColumnTwitterDescription,
 		table.ShortLink.ColumnTwitterImageURL,
+		table.ShortLink.ColumnTrackVisits,
+		table.ShortLink.ColumnVisitCount,
+		table.ShortLink.ColumnOriginalLongLink,
+		table.ShortLink.ColumnPassthroughQuery,
+		table.ShortLink.ColumnPassthroughPath,
+		table.ShortLink.ColumnMaxRedirectsPerIP,
+		table.ShortLink.ColumnMaxVisits,
+		table.ShortLink.ColumnDescription,
 		table.ShortLink.TableName,
+		table.ShortLink.ColumnTenantID,
 		table.ShortLink.ColumnAlias,
 	)
 
-	row := s.db.QueryRow(statement, alias)
+	row := s.db.QueryRowContext(ctx, statement, tenant.FromContext(ctx), alias)
 
-	shortLink := entity.ShortLink{}
+	shortLink := entity.ShortLink{TenantID: tenant.FromContext(ctx)}
 	err := row.Scan(
 		&shortLink.Alias,
 		&shortLink.LongLink,
@@ -185,7 +257,18 @@ WHERE "%s"=$1;`,
 		&shortLink.TwitterTags.Title,
 		&shortLink.TwitterTags.Description,
 		&shortLink.TwitterTags.ImageURL,
+		&shortLink.TrackVisits,
+		&shortLink.VisitCount,
+		&shortLink.OriginalLongLink,
+		&shortLink.PassthroughQuery,
+		&shortLink.PassthroughPath,
+		&shortLink.MaxRedirectsPerIP,
+		&shortLink.MaxVisits,
+		&shortLink.Description,
 	)
+	if err == sql.ErrNoRows {
+		return entity.ShortLink{}, repository.ErrEntryNotFound(fmt.Sprintf("alias(%s)", alias))
+	}
 	if err != nil {
 		return entity.ShortLink{}, err
 	}
@@ -198,15 +281,17 @@ WHERE "%s"=$1;`,
 }
 
 // GetShortLinksByAliases finds ShortLinks for a list of aliases
-func (s ShortLinkSQL) GetShortLinksByAliases(aliases []string) ([]entity.ShortLink, error) {
+func (s ShortLinkSQL) GetShortLinksByAliases(ctx context.Context, aliases []string) ([]entity.ShortLink, error) {
+	defer s.slowQueryLog.track("short_link.GetShortLinksByAliases")()
+
 	if len(aliases) == 0 {
 		return []entity.ShortLink{}, nil
 	}
 
-	parameterStr := s.composeParamList(len(aliases))
+	parameterStr := composeParamListFrom(2, len(aliases))
 
 	// create a list of interface{} to hold aliases for db.Query()
-	aliasesInterface := []interface{}{}
+	aliasesInterface := []interface{}{tenant.FromContext(ctx)}
 	for _, alias := range aliases {
 		aliasesInterface = append(aliasesInterface, alias)
 	}
@@ -215,9 +300,9 @@ func (s ShortLinkSQL) GetShortLinksByAliases(aliases []string) ([]entity.ShortLi
 
 	// TODO: compare performance between Query and QueryRow. Prefer QueryRow for readability
 	statement := fmt.Sprintf(`
-SELECT "%s","%s","%s","%s","%s","%s","%s","%s","%s","%s","%s" 
+SELECT "%s","%s","%s","%s","%s","%s","%s","%s","%s","%s","%s","%s","%s","%s","%s","%s","%s","%s","%s" 
 FROM "%s"
-WHERE "%s" IN (%s);`,
+WHERE "%s"=$1 AND "%s" IN (%s);`,
 		table.ShortLink.ColumnAlias,
 		table.ShortLink.ColumnLongLink,
 		table.ShortLink.ColumnExpireAt,
@@ -229,25 +314,34 @@ WHERE "%s" IN (%s);`,
 		table.ShortLink.ColumnTwitterTitle,
 		table.ShortLink.ColumnTwitterDescription,
 		table.ShortLink.ColumnTwitterImageURL,
+		table.ShortLink.ColumnTrackVisits,
+		table.ShortLink.ColumnVisitCount,
+		table.ShortLink.ColumnOriginalLongLink,
+		table.ShortLink.ColumnPassthroughQuery,
+		table.ShortLink.ColumnPassthroughPath,
+		table.ShortLink.ColumnMaxRedirectsPerIP,
+		table.ShortLink.ColumnMaxVisits,
+		table.ShortLink.ColumnDescription,
 		table.ShortLink.TableName,
+		table.ShortLink.ColumnTenantID,
 		table.ShortLink.ColumnAlias,
 		parameterStr,
 	)
 
-	stmt, err := s.db.Prepare(statement)
+	stmt, err := s.db.PrepareContext(ctx, statement)
 	if err != nil {
 		return shortLinks, err
 	}
 	defer stmt.Close()
 
-	rows, err := stmt.Query(aliasesInterface...)
+	rows, err := stmt.QueryContext(ctx, aliasesInterface...)
 	if err != nil {
-		return shortLinks, nil
+		return shortLinks, err
 	}
 
 	defer rows.Close()
 	for rows.Next() {
-		shortLink := entity.ShortLink{}
+		shortLink := entity.ShortLink{TenantID: tenant.FromContext(ctx)}
 		err := rows.Scan(
 			&shortLink.Alias,
 			&shortLink.LongLink,
@@ -260,6 +354,14 @@ WHERE "%s" IN (%s);`,
 			&shortLink.TwitterTags.Title,
 			&shortLink.TwitterTags.Description,
 			&shortLink.TwitterTags.ImageURL,
+			&shortLink.TrackVisits,
+			&shortLink.VisitCount,
+			&shortLink.OriginalLongLink,
+			&shortLink.PassthroughQuery,
+			&shortLink.PassthroughPath,
+			&shortLink.MaxRedirectsPerIP,
+			&shortLink.MaxVisits,
+			&shortLink.Description,
 		)
 		if err != nil {
 			return shortLinks, err
@@ -275,20 +377,202 @@ WHERE "%s" IN (%s);`,
 	return shortLinks, nil
 }
 
-// composeParamList converts an slice to a parameters string with format: $1, $2, $3, ...
-func (s ShortLinkSQL) composeParamList(numParams int) string {
-	params := make([]string, 0, numParams)
-	for i := 0; i < numParams; i++ {
-		params = append(params, fmt.Sprintf("$%d", i+1))
+// DeleteShortLinks removes ShortLinks with the given aliases from short_link
+// table together with their relationships, and returns the number of removed
+// ShortLinks.
+func (s ShortLinkSQL) DeleteShortLinks(ctx context.Context, aliases []string) (int, error) {
+	defer s.slowQueryLog.track("short_link.DeleteShortLinks")()
+
+	if len(aliases) == 0 {
+		return 0, nil
+	}
+
+	aliasesInterface := []interface{}{tenant.FromContext(ctx)}
+	for _, alias := range aliases {
+		aliasesInterface = append(aliasesInterface, alias)
+	}
+
+	statement := fmt.Sprintf(`
+DELETE FROM "%s"
+WHERE "%s"=$1 AND "%s" IN (%s);`,
+		table.ShortLink.TableName,
+		table.ShortLink.ColumnTenantID,
+		table.ShortLink.ColumnAlias,
+		composeParamListFrom(2, len(aliases)),
+	)
+
+	result, err := s.db.ExecContext(ctx, statement, aliasesInterface...)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+// FindShortLinkByLongLink finds the earliest created ShortLink redirecting to
+// the given long link which is not expired at activeAt. The ShortLinks
+// limiting the total visits are skipped since they can't be shared.
+func (s ShortLinkSQL) FindShortLinkByLongLink(ctx context.Context, longLink string, activeAt time.Time) (entity.ShortLink, error) {
+	defer s.slowQueryLog.track("short_link.FindShortLinkByLongLink")()
+
+	statement := fmt.Sprintf(`
+SELECT "%s","%s","%s","%s","%s","%s","%s","%s","%s","%s","%s"
+FROM "%s"
+WHERE "%s"=$1 AND "%s"=$2 AND ("%s" IS NULL OR "%s">$3) AND "%s"=0
+ORDER BY "%s" ASC NULLS FIRST,"%s" ASC
+LIMIT 1;`,
+		table.ShortLink.ColumnAlias,
+		table.ShortLink.ColumnLongLink,
+		table.ShortLink.ColumnExpireAt,
+		table.ShortLink.ColumnCreatedAt,
+		table.ShortLink.ColumnUpdatedAt,
+		table.ShortLink.ColumnTrackVisits,
+		table.ShortLink.ColumnOriginalLongLink,
+		table.ShortLink.ColumnPassthroughQuery,
+		table.ShortLink.ColumnPassthroughPath,
+		table.ShortLink.ColumnMaxRedirectsPerIP,
+		table.ShortLink.ColumnDescription,
+		table.ShortLink.TableName,
+		table.ShortLink.ColumnTenantID,
+		table.ShortLink.ColumnLongLink,
+		table.ShortLink.ColumnExpireAt,
+		table.ShortLink.ColumnExpireAt,
+		table.ShortLink.ColumnMaxVisits,
+		table.ShortLink.ColumnCreatedAt,
+		table.ShortLink.ColumnAlias,
+	)
+
+	shortLink := entity.ShortLink{TenantID: tenant.FromContext(ctx)}
+	err := s.db.QueryRowContext(ctx, statement, tenant.FromContext(ctx), longLink, activeAt).Scan(
+		&shortLink.Alias,
+		&shortLink.LongLink,
+		&shortLink.ExpireAt,
+		&shortLink.CreatedAt,
+		&shortLink.UpdatedAt,
+		&shortLink.TrackVisits,
+		&shortLink.OriginalLongLink,
+		&shortLink.PassthroughQuery,
+		&shortLink.PassthroughPath,
+		&shortLink.MaxRedirectsPerIP,
+		&shortLink.Description,
+	)
+	if err == sql.ErrNoRows {
+		return entity.ShortLink{}, repository.ErrEntryNotFound("long link not found")
+	}
+	if err != nil {
+		return entity.ShortLink{}, err
+	}
+
+	shortLink.CreatedAt = utc(shortLink.CreatedAt)
+	shortLink.UpdatedAt = utc(shortLink.UpdatedAt)
+	shortLink.ExpireAt = utc(shortLink.ExpireAt)
+	return shortLink, nil
+}
+
+// IncreaseVisitCounts adds the increments to the visit counts of the short
+// links with a single statement so that either all or none of the increments
+// are applied. The increments of the aliases which do not exist are ignored.
+func (s ShortLinkSQL) IncreaseVisitCounts(ctx context.Context, increments map[string]int) error {
+	defer s.slowQueryLog.track("short_link.IncreaseVisitCounts")()
+
+	if len(increments) == 0 {
+		return nil
 	}
 
-	parameterStr := strings.Join(params, ", ")
-	return parameterStr
+	values := make([]string, 0, len(increments))
+	args := make([]interface{}, 0, 2*len(increments)+1)
+	args = append(args, tenant.FromContext(ctx))
+	for alias, increment := range increments {
+		values = append(values, fmt.Sprintf("($%d, $%d::BIGINT)", len(args)+1, len(args)+2))
+		args = append(args, alias, increment)
+	}
+
+	statement := fmt.Sprintf(`
+UPDATE "%s"
+SET "%s"="%s"."%s"+"increment"."count"
+FROM (VALUES %s) AS "increment"("alias", "count")
+WHERE "%s"."%s"=$1 AND "%s"."%s"="increment"."alias";`,
+		table.ShortLink.TableName,
+		table.ShortLink.ColumnVisitCount,
+		table.ShortLink.TableName,
+		table.ShortLink.ColumnVisitCount,
+		strings.Join(values, ", "),
+		table.ShortLink.TableName,
+		table.ShortLink.ColumnTenantID,
+		table.ShortLink.TableName,
+		table.ShortLink.ColumnAlias,
+	)
+
+	_, err := s.db.ExecContext(ctx, statement, args...)
+	return err
+}
+
+// ConsumeVisit uses up one of the visits of the ShortLink limiting the total
+// visits with a single conditional update, so that concurrent visits can't use
+// up more visits than allowed. It reports false when no visit is left.
+func (s ShortLinkSQL) ConsumeVisit(ctx context.Context, alias string) (bool, error) {
+	defer s.slowQueryLog.track("short_link.ConsumeVisit")()
+
+	statement := fmt.Sprintf(`
+UPDATE "%s"
+SET "%s"="%s"+1
+WHERE "%s"=$1 AND "%s"=$2 AND "%s"<"%s";`,
+		table.ShortLink.TableName,
+		table.ShortLink.ColumnUsedVisits,
+		table.ShortLink.ColumnUsedVisits,
+		table.ShortLink.ColumnTenantID,
+		table.ShortLink.ColumnAlias,
+		table.ShortLink.ColumnUsedVisits,
+		table.ShortLink.ColumnMaxVisits,
+	)
+
+	result, err := s.db.ExecContext(ctx, statement, tenant.FromContext(ctx), alias)
+	if err != nil {
+		return false, err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return count == 1, nil
+}
+
+// CountShortLinks counts all the short links in short_link table across all
+// the tenants.
+func (s ShortLinkSQL) CountShortLinks(ctx context.Context) (int, error) {
+	defer s.slowQueryLog.track("short_link.CountShortLinks")()
+
+	statement := fmt.Sprintf(`SELECT COUNT(*) FROM "%s";`, table.ShortLink.TableName)
+
+	var count int
+	err := s.db.QueryRowContext(ctx, statement).Scan(&count)
+	return count, err
+}
+
+// CountShortLinksCreatedSince counts the short links in short_link table
+// created at or after since across all the tenants.
+func (s ShortLinkSQL) CountShortLinksCreatedSince(ctx context.Context, since time.Time) (int, error) {
+	defer s.slowQueryLog.track("short_link.CountShortLinksCreatedSince")()
+
+	statement := fmt.Sprintf(`SELECT COUNT(*) FROM "%s" WHERE "%s">=$1;`,
+		table.ShortLink.TableName,
+		table.ShortLink.ColumnCreatedAt,
+	)
+
+	var count int
+	err := s.db.QueryRowContext(ctx, statement, since.UTC()).Scan(&count)
+	return count, err
 }
 
 // NewShortLinkSQL creates ShortLinkSQL
-func NewShortLinkSQL(db *sql.DB) ShortLinkSQL {
+func NewShortLinkSQL(db *sql.DB, slowQueryLog SlowQueryLog) ShortLinkSQL {
 	return ShortLinkSQL{
-		db: db,
+		db:           db,
+		slowQueryLog: slowQueryLog,
 	}
 }
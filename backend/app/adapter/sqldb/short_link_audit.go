@@ -0,0 +1,56 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/short-d/short/backend/app/adapter/sqldb/table"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/fw/tenant"
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+var _ repository.ShortLinkAudit = (*ShortLinkAuditSQL)(nil)
+
+// ShortLinkAuditSQL accesses the administrative actions taken on short links
+// in short_link_audit table through SQL.
+type ShortLinkAuditSQL struct {
+	db *sql.DB
+}
+
+// CreateAuditEntry inserts a new audit entry on a short link of the tenant
+// attached to the context into short_link_audit table.
+func (s ShortLinkAuditSQL) CreateAuditEntry(ctx context.Context, entry entity.ShortLinkAuditEntry) error {
+	statement := fmt.Sprintf(`
+INSERT INTO "%s" ("%s","%s","%s","%s","%s","%s")
+VALUES ($1, $2, $3, $4, $5, $6);
+`,
+		table.ShortLinkAudit.TableName,
+		table.ShortLinkAudit.ColumnTenantID,
+		table.ShortLinkAudit.ColumnAlias,
+		table.ShortLinkAudit.ColumnAction,
+		table.ShortLinkAudit.ColumnReason,
+		table.ShortLinkAudit.ColumnUserID,
+		table.ShortLinkAudit.ColumnCreatedAt,
+	)
+
+	_, err := s.db.ExecContext(
+		ctx,
+		statement,
+		tenant.FromContext(ctx),
+		entry.Alias,
+		string(entry.Action),
+		entry.Reason,
+		entry.UserID,
+		entry.CreatedAt.UTC(),
+	)
+	return err
+}
+
+// NewShortLinkAuditSQL creates ShortLinkAuditSQL
+func NewShortLinkAuditSQL(db *sql.DB) ShortLinkAuditSQL {
+	return ShortLinkAuditSQL{
+		db: db,
+	}
+}
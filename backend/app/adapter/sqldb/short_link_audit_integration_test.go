@@ -0,0 +1,58 @@
+// +build integration all
+
+package sqldb_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/db/dbtest"
+	"github.com/short-d/short/backend/app/adapter/sqldb"
+	"github.com/short-d/short/backend/app/adapter/sqldb/table"
+	"github.com/short-d/short/backend/app/entity"
+)
+
+func TestShortLinkAuditSQL_CreateAuditEntry(t *testing.T) {
+	now := mustParseTime(t, "2020-05-01T08:02:16-07:00")
+	entry := entity.ShortLinkAuditEntry{
+		Alias:     "220uFicCJj",
+		Action:    entity.ShortLinkActionExpire,
+		Reason:    "phishing reported",
+		UserID:    "alpha",
+		CreatedAt: now,
+	}
+
+	dbtest.AccessTestDB(
+		dbConnector,
+		dbMigrationTool,
+		dbMigrationRoot,
+		dbConfig,
+		func(sqlDB *sql.DB) {
+			auditRepo := sqldb.NewShortLinkAuditSQL(sqlDB)
+			err := auditRepo.CreateAuditEntry(context.Background(), entry)
+			assert.Equal(t, nil, err)
+
+			query := fmt.Sprintf(`SELECT "%s", "%s", "%s", "%s" FROM "%s" WHERE "%s"=$1;`,
+				table.ShortLinkAudit.ColumnAction,
+				table.ShortLinkAudit.ColumnReason,
+				table.ShortLinkAudit.ColumnUserID,
+				table.ShortLinkAudit.ColumnCreatedAt,
+				table.ShortLinkAudit.TableName,
+				table.ShortLinkAudit.ColumnAlias,
+			)
+			var action, reason, userID string
+			var createdAt time.Time
+			err = sqlDB.QueryRow(query, entry.Alias).
+				Scan(&action, &reason, &userID, &createdAt)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, string(entry.Action), action)
+			assert.Equal(t, entry.Reason, reason)
+			assert.Equal(t, entry.UserID, userID)
+			assert.Equal(t, true, entry.CreatedAt.Equal(createdAt))
+		},
+	)
+}
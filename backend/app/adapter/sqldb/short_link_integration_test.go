@@ -3,6 +3,7 @@
 package sqldb_test
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"testing"
@@ -78,6 +79,7 @@ func TestShortLinkSql_UpdateOGMetaTags(t *testing.T) {
 					Description: ptr.String("description1"),
 					ImageURL:    ptr.String("url1"),
 				},
+				TrackVisits: true,
 			},
 		},
 		{
@@ -113,6 +115,7 @@ func TestShortLinkSql_UpdateOGMetaTags(t *testing.T) {
 					Description: ptr.String("description1"),
 					ImageURL:    ptr.String("url1"),
 				},
+				TrackVisits: true,
 			},
 		},
 	}
@@ -127,9 +130,9 @@ func TestShortLinkSql_UpdateOGMetaTags(t *testing.T) {
 				func(sqlDB *sql.DB) {
 					insertShortLinkTableRows(t, sqlDB, testCase.tableRows)
 
-					shortLinkRepo := sqldb.NewShortLinkSQL(sqlDB)
+					shortLinkRepo := sqldb.NewShortLinkSQL(sqlDB, sqldb.SlowQueryLog{})
 
-					shortLink, err := shortLinkRepo.UpdateOpenGraphTags(testCase.alias, testCase.metaTags)
+					shortLink, err := shortLinkRepo.UpdateOpenGraphTags(context.Background(), testCase.alias, testCase.metaTags)
 					assert.Equal(t, nil, err)
 					assert.Equal(t, testCase.expectedShortLink, shortLink)
 				})
@@ -175,6 +178,7 @@ func TestShortLinkSql_UpdateTwitterTags(t *testing.T) {
 					Description: ptr.String("description2"),
 					ImageURL:    ptr.String("url2"),
 				},
+				TrackVisits: true,
 			},
 		},
 		{
@@ -210,6 +214,7 @@ func TestShortLinkSql_UpdateTwitterTags(t *testing.T) {
 					Description: ptr.String("description2"),
 					ImageURL:    ptr.String("url2"),
 				},
+				TrackVisits: true,
 			},
 		},
 	}
@@ -224,9 +229,9 @@ func TestShortLinkSql_UpdateTwitterTags(t *testing.T) {
 				func(sqlDB *sql.DB) {
 					insertShortLinkTableRows(t, sqlDB, testCase.tableRows)
 
-					shortLinkRepo := sqldb.NewShortLinkSQL(sqlDB)
+					shortLinkRepo := sqldb.NewShortLinkSQL(sqlDB, sqldb.SlowQueryLog{})
 
-					shortLink, err := shortLinkRepo.UpdateTwitterTags(testCase.alias, testCase.metaTags)
+					shortLink, err := shortLinkRepo.UpdateTwitterTags(context.Background(), testCase.alias, testCase.metaTags)
 					assert.Equal(t, nil, err)
 					assert.Equal(t, testCase.expectedShortLink, shortLink)
 				})
@@ -267,8 +272,8 @@ func TestShortLinkSql_IsAliasExist(t *testing.T) {
 				func(sqlDB *sql.DB) {
 					insertShortLinkTableRows(t, sqlDB, testCase.tableRows)
 
-					shortLinkRepo := sqldb.NewShortLinkSQL(sqlDB)
-					gotIsExist, err := shortLinkRepo.IsAliasExist(testCase.alias)
+					shortLinkRepo := sqldb.NewShortLinkSQL(sqlDB, sqldb.SlowQueryLog{})
+					gotIsExist, err := shortLinkRepo.IsAliasExist(context.Background(), testCase.alias)
 					assert.Equal(t, nil, err)
 					assert.Equal(t, testCase.expIsExist, gotIsExist)
 				})
@@ -341,6 +346,7 @@ func TestShortLinkSql_GetShortLinkByAlias(t *testing.T) {
 					Description: ptr.String("description1"),
 					ImageURL:    ptr.String("url1"),
 				},
+				TrackVisits: true,
 			},
 		},
 		{
@@ -391,6 +397,7 @@ func TestShortLinkSql_GetShortLinkByAlias(t *testing.T) {
 					Description: ptr.String("description1"),
 					ImageURL:    ptr.String("url1"),
 				},
+				TrackVisits: true,
 			},
 		},
 	}
@@ -405,8 +412,8 @@ func TestShortLinkSql_GetShortLinkByAlias(t *testing.T) {
 				func(sqlDB *sql.DB) {
 					insertShortLinkTableRows(t, sqlDB, testCase.tableRows)
 
-					shortLinkRepo := sqldb.NewShortLinkSQL(sqlDB)
-					shortLink, err := shortLinkRepo.GetShortLinkByAlias(testCase.alias)
+					shortLinkRepo := sqldb.NewShortLinkSQL(sqlDB, sqldb.SlowQueryLog{})
+					shortLink, err := shortLinkRepo.GetShortLinkByAlias(context.Background(), testCase.alias)
 
 					if testCase.hasErr {
 						assert.NotEqual(t, nil, err)
@@ -422,6 +429,8 @@ func TestShortLinkSql_GetShortLinkByAlias(t *testing.T) {
 
 func TestShortLinkSql_CreateShortLink(t *testing.T) {
 	now := mustParseTime(t, "2019-05-01T08:02:16-07:00")
+	noTracking := false
+	passthroughQuery := true
 
 	testCases := []struct {
 		name           string
@@ -475,6 +484,50 @@ func TestShortLinkSql_CreateShortLink(t *testing.T) {
 			},
 			hasErr: false,
 		},
+		{
+			name:      "create short link without tracking visits",
+			tableRows: []shortLinkTableRow{},
+			shortLinkInput: entity.ShortLinkInput{
+				CustomAlias: ptr.String("220uFicCJj"),
+				LongLink:    ptr.String("http://www.google.com"),
+				CreatedAt:   &now,
+				TrackVisits: &noTracking,
+			},
+			hasErr: false,
+		},
+		{
+			name:      "create short link with original long link",
+			tableRows: []shortLinkTableRow{},
+			shortLinkInput: entity.ShortLinkInput{
+				CustomAlias:      ptr.String("220uFicCJj"),
+				LongLink:         ptr.String("https://www.google.com"),
+				OriginalLongLink: ptr.String("http://www.google.com#search"),
+				CreatedAt:        &now,
+			},
+			hasErr: false,
+		},
+		{
+			name:      "create short link with query passthrough",
+			tableRows: []shortLinkTableRow{},
+			shortLinkInput: entity.ShortLinkInput{
+				CustomAlias:      ptr.String("220uFicCJj"),
+				LongLink:         ptr.String("https://www.google.com/search"),
+				CreatedAt:        &now,
+				PassthroughQuery: &passthroughQuery,
+			},
+			hasErr: false,
+		},
+		{
+			name:      "create short link with path passthrough",
+			tableRows: []shortLinkTableRow{},
+			shortLinkInput: entity.ShortLinkInput{
+				CustomAlias:     ptr.String("220uFicCJj"),
+				LongLink:        ptr.String("https://www.google.com/search"),
+				CreatedAt:       &now,
+				PassthroughPath: ptr.Bool(true),
+			},
+			hasErr: false,
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -487,8 +540,8 @@ func TestShortLinkSql_CreateShortLink(t *testing.T) {
 				func(sqlDB *sql.DB) {
 					insertShortLinkTableRows(t, sqlDB, testCase.tableRows)
 
-					shortLinkRepo := sqldb.NewShortLinkSQL(sqlDB)
-					err := shortLinkRepo.CreateShortLink(testCase.shortLinkInput)
+					shortLinkRepo := sqldb.NewShortLinkSQL(sqlDB, sqldb.SlowQueryLog{})
+					err := shortLinkRepo.CreateShortLink(context.Background(), testCase.shortLinkInput)
 
 					if testCase.hasErr {
 						assert.NotEqual(t, nil, err)
@@ -497,12 +550,16 @@ func TestShortLinkSql_CreateShortLink(t *testing.T) {
 
 					assert.Equal(t, nil, err)
 
-					shortLink, err := shortLinkRepo.GetShortLinkByAlias(testCase.shortLinkInput.GetCustomAlias(""))
+					shortLink, err := shortLinkRepo.GetShortLinkByAlias(context.Background(), testCase.shortLinkInput.GetCustomAlias(""))
 					assert.Equal(t, nil, err)
 					assert.Equal(t, *testCase.shortLinkInput.CustomAlias, shortLink.Alias)
 					assert.Equal(t, *testCase.shortLinkInput.LongLink, shortLink.LongLink)
+					assert.Equal(t, testCase.shortLinkInput.GetOriginalLongLink(""), shortLink.OriginalLongLink)
 					assert.Equal(t, testCase.shortLinkInput.ExpireAt, shortLink.ExpireAt)
 					assert.Equal(t, testCase.shortLinkInput.CreatedAt, shortLink.CreatedAt)
+					assert.Equal(t, testCase.shortLinkInput.GetTrackVisits(true), shortLink.TrackVisits)
+					assert.Equal(t, testCase.shortLinkInput.GetPassthroughQuery(false), shortLink.PassthroughQuery)
+					assert.Equal(t, testCase.shortLinkInput.GetPassthroughPath(false), shortLink.PassthroughPath)
 				},
 			)
 		})
@@ -587,14 +644,14 @@ func TestShortLinkSql_UpdateShortLink(t *testing.T) {
 					insertShortLinkTableRows(t, sqlDB, testCase.tableRows)
 					expectedShortLink := testCase.expectedShortLink
 
-					shortLinkRepo := sqldb.NewShortLinkSQL(sqlDB)
-					shortLink, err := shortLinkRepo.UpdateShortLink(
+					shortLinkRepo := sqldb.NewShortLinkSQL(sqlDB, sqldb.SlowQueryLog{})
+					shortLink, err := shortLinkRepo.UpdateShortLink(context.Background(),
 						testCase.oldAlias,
 						testCase.shortLinkInput,
 					)
 					assert.Equal(t, nil, err)
 
-					shortLink, err = shortLinkRepo.GetShortLinkByAlias(testCase.shortLinkInput.GetCustomAlias(""))
+					shortLink, err = shortLinkRepo.GetShortLinkByAlias(context.Background(), testCase.shortLinkInput.GetCustomAlias(""))
 					if testCase.hasErr {
 						assert.NotEqual(t, nil, err)
 						return
@@ -677,6 +734,7 @@ func TestShortLinkSql_GetShortLinkByAliases(t *testing.T) {
 						Description: ptr.String("description1"),
 						ImageURL:    ptr.String("url1"),
 					},
+					TrackVisits: true,
 				},
 				{
 					Alias:     "yDOBcj5HIPbUAsw",
@@ -694,6 +752,7 @@ func TestShortLinkSql_GetShortLinkByAliases(t *testing.T) {
 						Description: ptr.String("description2"),
 						ImageURL:    ptr.String("url2"),
 					},
+					TrackVisits: true,
 				},
 			},
 		},
@@ -709,8 +768,8 @@ func TestShortLinkSql_GetShortLinkByAliases(t *testing.T) {
 				func(sqlDB *sql.DB) {
 					insertShortLinkTableRows(t, sqlDB, testCase.tableRows)
 
-					shortLinkRepo := sqldb.NewShortLinkSQL(sqlDB)
-					shortLink, err := shortLinkRepo.GetShortLinksByAliases(testCase.aliases)
+					shortLinkRepo := sqldb.NewShortLinkSQL(sqlDB, sqldb.SlowQueryLog{})
+					shortLink, err := shortLinkRepo.GetShortLinksByAliases(context.Background(), testCase.aliases)
 
 					if testCase.hasErr {
 						assert.NotEqual(t, nil, err)
@@ -724,6 +783,176 @@ func TestShortLinkSql_GetShortLinkByAliases(t *testing.T) {
 	}
 }
 
+func TestShortLinkSql_DeleteShortLinks(t *testing.T) {
+	createdAt := mustParseTime(t, "2019-05-01T08:02:16-07:00")
+
+	testCases := []struct {
+		name              string
+		tableRows         []shortLinkTableRow
+		aliases           []string
+		expectedCount     int
+		expectedRemaining []string
+	}{
+		{
+			name:              "no alias given",
+			tableRows:         []shortLinkTableRow{},
+			aliases:           []string{},
+			expectedCount:     0,
+			expectedRemaining: []string{},
+		},
+		{
+			name: "delete existing aliases",
+			tableRows: []shortLinkTableRow{
+				{
+					alias:     "220uFicCJj",
+					longLink:  "http://www.google.com",
+					createdAt: &createdAt,
+				},
+				{
+					alias:     "yDOBcj5HIPbUAsw",
+					longLink:  "http://www.facebook.com",
+					createdAt: &createdAt,
+				},
+				{
+					alias:     "efpIZ4OS",
+					longLink:  "https://gmail.com",
+					createdAt: &createdAt,
+				},
+			},
+			aliases:           []string{"220uFicCJj", "efpIZ4OS", "does_not_exist"},
+			expectedCount:     2,
+			expectedRemaining: []string{"yDOBcj5HIPbUAsw"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			dbtest.AccessTestDB(
+				dbConnector,
+				dbMigrationTool,
+				dbMigrationRoot,
+				dbConfig,
+				func(sqlDB *sql.DB) {
+					insertShortLinkTableRows(t, sqlDB, testCase.tableRows)
+
+					shortLinkRepo := sqldb.NewShortLinkSQL(sqlDB, sqldb.SlowQueryLog{})
+					count, err := shortLinkRepo.DeleteShortLinks(context.Background(), testCase.aliases)
+					assert.Equal(t, nil, err)
+					assert.Equal(t, testCase.expectedCount, count)
+
+					remaining := []string{}
+					for _, tableRow := range testCase.tableRows {
+						isExist, err := shortLinkRepo.IsAliasExist(context.Background(), tableRow.alias)
+						assert.Equal(t, nil, err)
+						if isExist {
+							remaining = append(remaining, tableRow.alias)
+						}
+					}
+					assert.Equal(t, testCase.expectedRemaining, remaining)
+				},
+			)
+		})
+	}
+}
+
+func TestShortLinkSql_FindShortLinkByLongLink(t *testing.T) {
+	earlyCreatedAt := mustParseTime(t, "2019-05-01T08:02:16Z")
+	lateCreatedAt := mustParseTime(t, "2019-06-01T08:02:16Z")
+	activeAt := mustParseTime(t, "2019-07-01T08:02:16Z")
+	expiredAt := mustParseTime(t, "2019-06-15T08:02:16Z")
+	expireAt := mustParseTime(t, "2019-08-01T08:02:16Z")
+
+	testCases := []struct {
+		name              string
+		tableRows         []shortLinkTableRow
+		longLink          string
+		hasErr            bool
+		expectedShortLink entity.ShortLink
+	}{
+		{
+			name:      "long link not found",
+			tableRows: []shortLinkTableRow{},
+			longLink:  "https://www.google.com/",
+			hasErr:    true,
+		},
+		{
+			name: "earliest created short link",
+			tableRows: []shortLinkTableRow{
+				{
+					alias:     "yDOBcj5HIPbUAsw",
+					longLink:  "https://www.google.com/",
+					createdAt: &lateCreatedAt,
+				},
+				{
+					alias:     "220uFicCJj",
+					longLink:  "https://www.google.com/",
+					createdAt: &earlyCreatedAt,
+					expireAt:  &expireAt,
+				},
+				{
+					alias:     "efpIZ4OS",
+					longLink:  "https://gmail.com/",
+					createdAt: &earlyCreatedAt,
+				},
+			},
+			longLink: "https://www.google.com/",
+			expectedShortLink: entity.ShortLink{
+				Alias:       "220uFicCJj",
+				LongLink:    "https://www.google.com/",
+				ExpireAt:    &expireAt,
+				CreatedAt:   &earlyCreatedAt,
+				TrackVisits: true,
+			},
+		},
+		{
+			name: "skip expired short link",
+			tableRows: []shortLinkTableRow{
+				{
+					alias:     "220uFicCJj",
+					longLink:  "https://www.google.com/",
+					createdAt: &earlyCreatedAt,
+					expireAt:  &expiredAt,
+				},
+				{
+					alias:     "yDOBcj5HIPbUAsw",
+					longLink:  "https://www.google.com/",
+					createdAt: &lateCreatedAt,
+				},
+			},
+			longLink: "https://www.google.com/",
+			expectedShortLink: entity.ShortLink{
+				Alias:       "yDOBcj5HIPbUAsw",
+				LongLink:    "https://www.google.com/",
+				CreatedAt:   &lateCreatedAt,
+				TrackVisits: true,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			dbtest.AccessTestDB(
+				dbConnector,
+				dbMigrationTool,
+				dbMigrationRoot,
+				dbConfig,
+				func(sqlDB *sql.DB) {
+					insertShortLinkTableRows(t, sqlDB, testCase.tableRows)
+
+					shortLinkRepo := sqldb.NewShortLinkSQL(sqlDB, sqldb.SlowQueryLog{})
+					shortLink, err := shortLinkRepo.FindShortLinkByLongLink(context.Background(), testCase.longLink, activeAt)
+					if testCase.hasErr {
+						assert.NotEqual(t, nil, err)
+						return
+					}
+					assert.Equal(t, nil, err)
+					assert.Equal(t, testCase.expectedShortLink, shortLink)
+				},
+			)
+		})
+	}
+}
+
 func insertShortLinkTableRows(t *testing.T, sqlDB *sql.DB, tableRows []shortLinkTableRow) {
 	for _, tableRow := range tableRows {
 		_, err := sqlDB.Exec(
@@ -743,3 +972,127 @@ func insertShortLinkTableRows(t *testing.T, sqlDB *sql.DB, tableRows []shortLink
 		assert.Equal(t, nil, err)
 	}
 }
+
+func TestShortLinkSql_IncreaseVisitCounts(t *testing.T) {
+	createdAt := mustParseTime(t, "2019-05-01T08:02:16-07:00")
+
+	testCases := []struct {
+		name                string
+		tableRows           []shortLinkTableRow
+		incrementRounds     []map[string]int
+		expectedVisitCounts map[string]int
+	}{
+		{
+			name: "no increment",
+			tableRows: []shortLinkTableRow{
+				{alias: "220uFicCJj", longLink: "http://www.google.com", createdAt: &createdAt},
+			},
+			incrementRounds:     []map[string]int{{}},
+			expectedVisitCounts: map[string]int{"220uFicCJj": 0},
+		},
+		{
+			name: "increments accumulated",
+			tableRows: []shortLinkTableRow{
+				{alias: "220uFicCJj", longLink: "http://www.google.com", createdAt: &createdAt},
+				{alias: "yDOBcj5HIPbUAsw", longLink: "http://www.facebook.com", createdAt: &createdAt},
+				{alias: "efpIZ4OS", longLink: "https://gmail.com", createdAt: &createdAt},
+			},
+			incrementRounds: []map[string]int{
+				{"220uFicCJj": 3, "yDOBcj5HIPbUAsw": 1, "does_not_exist": 2},
+				{"220uFicCJj": 2},
+			},
+			expectedVisitCounts: map[string]int{
+				"220uFicCJj":      5,
+				"yDOBcj5HIPbUAsw": 1,
+				"efpIZ4OS":        0,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			dbtest.AccessTestDB(
+				dbConnector,
+				dbMigrationTool,
+				dbMigrationRoot,
+				dbConfig,
+				func(sqlDB *sql.DB) {
+					insertShortLinkTableRows(t, sqlDB, testCase.tableRows)
+
+					shortLinkRepo := sqldb.NewShortLinkSQL(sqlDB, sqldb.SlowQueryLog{})
+					for _, increments := range testCase.incrementRounds {
+						err := shortLinkRepo.IncreaseVisitCounts(context.Background(), increments)
+						assert.Equal(t, nil, err)
+					}
+
+					for alias, expectedVisitCount := range testCase.expectedVisitCounts {
+						shortLink, err := shortLinkRepo.GetShortLinkByAlias(context.Background(), alias)
+						assert.Equal(t, nil, err)
+						assert.Equal(t, expectedVisitCount, shortLink.VisitCount)
+					}
+				},
+			)
+		})
+	}
+}
+
+func TestShortLinkSql_ConsumeVisit(t *testing.T) {
+	testCases := []struct {
+		name              string
+		shortLinkInput    entity.ShortLinkInput
+		visits            int
+		expectedConsumed  []bool
+		expectedMaxVisits int
+	}{
+		{
+			name: "one-time short link",
+			shortLinkInput: entity.ShortLinkInput{
+				CustomAlias: ptr.String("220uFicCJj"),
+				LongLink:    ptr.String("http://www.google.com"),
+				MaxVisits:   ptr.Int(1),
+			},
+			visits:            3,
+			expectedConsumed:  []bool{true, false, false},
+			expectedMaxVisits: 1,
+		},
+		{
+			name: "short link without visit limit",
+			shortLinkInput: entity.ShortLinkInput{
+				CustomAlias: ptr.String("220uFicCJj"),
+				LongLink:    ptr.String("http://www.google.com"),
+			},
+			visits:            1,
+			expectedConsumed:  []bool{false},
+			expectedMaxVisits: 0,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			dbtest.AccessTestDB(
+				dbConnector,
+				dbMigrationTool,
+				dbMigrationRoot,
+				dbConfig,
+				func(sqlDB *sql.DB) {
+					shortLinkRepo := sqldb.NewShortLinkSQL(sqlDB, sqldb.SlowQueryLog{})
+					err := shortLinkRepo.CreateShortLink(context.Background(), testCase.shortLinkInput)
+					assert.Equal(t, nil, err)
+
+					alias := testCase.shortLinkInput.GetCustomAlias("")
+					var consumed []bool
+					for visit := 0; visit < testCase.visits; visit++ {
+						isConsumed, err := shortLinkRepo.ConsumeVisit(context.Background(), alias)
+						assert.Equal(t, nil, err)
+						consumed = append(consumed, isConsumed)
+					}
+					assert.Equal(t, testCase.expectedConsumed, consumed)
+
+					shortLink, err := shortLinkRepo.GetShortLinkByAlias(context.Background(), alias)
+					assert.Equal(t, nil, err)
+					assert.Equal(t, testCase.expectedMaxVisits, shortLink.MaxVisits)
+				},
+			)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package sqldb
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/short-d/app/fw/ctx"
+	"github.com/short-d/app/fw/logger"
+	"github.com/short-d/app/fw/metrics"
+	"github.com/short-d/app/fw/timer"
+)
+
+const slowQueryMetric = "slow-query"
+
+// SlowQueryLog warns about the SQL queries taking longer than the threshold
+// and counts them by query name, so that the slowdowns of the database can be
+// diagnosed in production. The zero value tracks nothing.
+type SlowQueryLog struct {
+	threshold time.Duration
+	logger    logger.Logger
+	timer     timer.Timer
+	metrics   metrics.Metrics
+}
+
+// track starts timing the query with the given name. The returned function
+// stops the timing and reports the query when it is slow.
+func (s SlowQueryLog) track(name string) func() {
+	if s.threshold <= 0 {
+		return func() {}
+	}
+
+	startAt := s.timer.Now()
+	return func() {
+		elapsed := s.timer.Now().Sub(startAt)
+		if elapsed < s.threshold {
+			return
+		}
+		s.logger.Warn(fmt.Sprintf("slow query: name=%s elapsed=%v threshold=%v", name, elapsed, s.threshold))
+		go s.metrics.Count(fmt.Sprintf("%s.%s", slowQueryMetric, name), 1, 1, ctx.ExecutionContext{})
+	}
+}
+
+// NewSlowQueryLog creates SlowQueryLog. Zero threshold turns the tracking
+// off.
+func NewSlowQueryLog(
+	threshold time.Duration,
+	logger logger.Logger,
+	timer timer.Timer,
+	metrics metrics.Metrics,
+) SlowQueryLog {
+	return SlowQueryLog{
+		threshold: threshold,
+		logger:    logger,
+		timer:     timer,
+		metrics:   metrics,
+	}
+}
@@ -0,0 +1,106 @@
+// +build !integration all
+
+package sqldb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/ctx"
+	"github.com/short-d/app/fw/logger"
+	"github.com/short-d/short/backend/app/fw/timertest"
+)
+
+// metricsRecorder captures the IDs of the counted metrics.
+type metricsRecorder struct {
+	metricIDs chan string
+}
+
+func (m metricsRecorder) Count(metricID string, point int, interval int, ctx ctx.ExecutionContext) {
+	m.metricIDs <- metricID
+}
+
+func (m metricsRecorder) Rate(metricID string, point float32, interval int, ctx ctx.ExecutionContext) {
+}
+
+func (m metricsRecorder) Gauge(metricID string, point float32, ctx ctx.ExecutionContext) {
+}
+
+func TestSlowQueryLog_Track(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 5, 1, 8, 2, 16, 0, time.UTC)
+
+	testCases := []struct {
+		name             string
+		threshold        time.Duration
+		elapsed          time.Duration
+		expectedMessages []string
+		expectedMetricID string
+	}{
+		{
+			name:      "fast query",
+			threshold: 500 * time.Millisecond,
+			elapsed:   499 * time.Millisecond,
+		},
+		{
+			name:      "slow query",
+			threshold: 500 * time.Millisecond,
+			elapsed:   2 * time.Second,
+			expectedMessages: []string{
+				"slow query: name=short_link.GetShortLinkByAlias elapsed=2s threshold=500ms",
+			},
+			expectedMetricID: "slow-query.short_link.GetShortLinkByAlias",
+		},
+		{
+			name:      "query as slow as threshold",
+			threshold: 500 * time.Millisecond,
+			elapsed:   500 * time.Millisecond,
+			expectedMessages: []string{
+				"slow query: name=short_link.GetShortLinkByAlias elapsed=500ms threshold=500ms",
+			},
+			expectedMetricID: "slow-query.short_link.GetShortLinkByAlias",
+		},
+		{
+			name:      "tracking turned off",
+			threshold: 0,
+			elapsed:   time.Hour,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			entryRepo := logger.NewEntryRepoFake()
+			lg, err := logger.NewFake(logger.LogInfo, &entryRepo)
+			assert.Equal(t, nil, err)
+			fakeTimer := timertest.NewFakeTimer(now)
+			metrics := metricsRecorder{metricIDs: make(chan string, 1)}
+			slowQueryLog := NewSlowQueryLog(testCase.threshold, lg, fakeTimer, metrics)
+
+			done := slowQueryLog.track("short_link.GetShortLinkByAlias")
+			fakeTimer.Advance(testCase.elapsed)
+			done()
+
+			var messages []string
+			for _, entry := range entryRepo.GetEntries() {
+				assert.Equal(t, logger.LogWarn, entry.Level)
+				messages = append(messages, entry.Message)
+			}
+			assert.Equal(t, testCase.expectedMessages, messages)
+
+			if testCase.expectedMetricID == "" {
+				return
+			}
+			select {
+			case metricID := <-metrics.metricIDs:
+				assert.Equal(t, testCase.expectedMetricID, metricID)
+			case <-time.After(time.Second):
+				t.Fatalf("expect metric %s to be counted", testCase.expectedMetricID)
+			}
+		})
+	}
+}
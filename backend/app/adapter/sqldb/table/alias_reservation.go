@@ -0,0 +1,19 @@
+package table
+
+// AliasReservation represents database table columns for 'alias_reservation'
+// table
+var AliasReservation = struct {
+	TableName      string
+	ColumnTenantID string
+	ColumnAlias    string
+	ColumnUserID   string
+	ColumnToken    string
+	ColumnExpireAt string
+}{
+	TableName:      "alias_reservation",
+	ColumnTenantID: "tenant_id",
+	ColumnAlias:    "alias",
+	ColumnUserID:   "user_id",
+	ColumnToken:    "token",
+	ColumnExpireAt: "expire_at",
+}
@@ -0,0 +1,14 @@
+package table
+
+// AliasSkeleton represents database table columns for 'alias_skeleton' table
+var AliasSkeleton = struct {
+	TableName      string
+	ColumnTenantID string
+	ColumnAlias    string
+	ColumnSkeleton string
+}{
+	TableName:      "alias_skeleton",
+	ColumnTenantID: "tenant_id",
+	ColumnAlias:    "alias",
+	ColumnSkeleton: "skeleton",
+}
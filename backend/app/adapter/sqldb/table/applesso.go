@@ -0,0 +1,12 @@
+package table
+
+// AppleSSO represents database table columns for 'apple_sso' table.
+var AppleSSO = struct {
+	TableName         string
+	ColumnAppleUserID string
+	ColumnShortUserID string
+}{
+	TableName:         "apple_sso",
+	ColumnAppleUserID: "apple_user_id",
+	ColumnShortUserID: "short_user_id",
+}
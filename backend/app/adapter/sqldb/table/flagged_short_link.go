@@ -0,0 +1,17 @@
+package table
+
+// FlaggedShortLink represents database table columns for 'flagged_short_link'
+// table
+var FlaggedShortLink = struct {
+	TableName       string
+	ColumnTenantID  string
+	ColumnAlias     string
+	ColumnRiskScore string
+	ColumnFlaggedAt string
+}{
+	TableName:       "flagged_short_link",
+	ColumnTenantID:  "tenant_id",
+	ColumnAlias:     "alias",
+	ColumnRiskScore: "risk_score",
+	ColumnFlaggedAt: "flagged_at",
+}
@@ -0,0 +1,15 @@
+package table
+
+// GuestShortLink represents database table columns for 'guest_short_link'
+// table
+var GuestShortLink = struct {
+	TableName            string
+	ColumnTenantID       string
+	ColumnSessionID      string
+	ColumnShortLinkAlias string
+}{
+	TableName:            "guest_short_link",
+	ColumnTenantID:       "tenant_id",
+	ColumnSessionID:      "session_id",
+	ColumnShortLinkAlias: "short_link_alias",
+}
@@ -0,0 +1,18 @@
+package table
+
+// LinkHealth represents database table columns for 'link_health' table
+var LinkHealth = struct {
+	TableName                 string
+	ColumnTenantID            string
+	ColumnAlias               string
+	ColumnStatus              string
+	ColumnConsecutiveFailures string
+	ColumnLastCheckedAt       string
+}{
+	TableName:                 "link_health",
+	ColumnTenantID:            "tenant_id",
+	ColumnAlias:               "alias",
+	ColumnStatus:              "status",
+	ColumnConsecutiveFailures: "consecutive_failures",
+	ColumnLastCheckedAt:       "last_checked_at",
+}
@@ -3,8 +3,10 @@ package table
 // ShortLink represents database table columns for 'short_link' table
 var ShortLink = struct {
 	TableName                  string
+	ColumnTenantID             string
 	ColumnAlias                string
 	ColumnLongLink             string
+	ColumnOriginalLongLink     string
 	ColumnCreatedAt            string
 	ColumnExpireAt             string
 	ColumnUpdatedAt            string
@@ -14,10 +16,20 @@ var ShortLink = struct {
 	ColumnTwitterTitle         string
 	ColumnTwitterDescription   string
 	ColumnTwitterImageURL      string
+	ColumnTrackVisits          string
+	ColumnVisitCount           string
+	ColumnPassthroughQuery     string
+	ColumnPassthroughPath      string
+	ColumnMaxRedirectsPerIP    string
+	ColumnMaxVisits            string
+	ColumnUsedVisits           string
+	ColumnDescription          string
 }{
 	TableName:                  "short_link",
+	ColumnTenantID:             "tenant_id",
 	ColumnAlias:                "alias",
 	ColumnLongLink:             "long_link",
+	ColumnOriginalLongLink:     "original_long_link",
 	ColumnCreatedAt:            "created_at",
 	ColumnExpireAt:             "expire_at",
 	ColumnUpdatedAt:            "updated_at",
@@ -27,4 +39,12 @@ var ShortLink = struct {
 	ColumnTwitterTitle:         "twitter_title",
 	ColumnTwitterDescription:   "twitter_description",
 	ColumnTwitterImageURL:      "twitter_image_url",
+	ColumnTrackVisits:          "track_visits",
+	ColumnVisitCount:           "visit_count",
+	ColumnPassthroughQuery:     "passthrough_query",
+	ColumnPassthroughPath:      "passthrough_path",
+	ColumnMaxRedirectsPerIP:    "max_redirects_per_ip",
+	ColumnMaxVisits:            "max_visits",
+	ColumnUsedVisits:           "used_visits",
+	ColumnDescription:          "description",
 }
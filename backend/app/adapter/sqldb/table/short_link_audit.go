@@ -0,0 +1,23 @@
+package table
+
+// ShortLinkAudit represents database table columns for 'short_link_audit'
+// table
+var ShortLinkAudit = struct {
+	TableName       string
+	ColumnTenantID  string
+	ColumnID        string
+	ColumnAlias     string
+	ColumnAction    string
+	ColumnReason    string
+	ColumnUserID    string
+	ColumnCreatedAt string
+}{
+	TableName:       "short_link_audit",
+	ColumnTenantID:  "tenant_id",
+	ColumnID:        "id",
+	ColumnAlias:     "alias",
+	ColumnAction:    "action",
+	ColumnReason:    "reason",
+	ColumnUserID:    "user_id",
+	ColumnCreatedAt: "created_at",
+}
@@ -0,0 +1,12 @@
+package table
+
+// TwitterSSO represents database table columns for 'twitter_sso' table.
+var TwitterSSO = struct {
+	TableName           string
+	ColumnTwitterUserID string
+	ColumnShortUserID   string
+}{
+	TableName:           "twitter_sso",
+	ColumnTwitterUserID: "twitter_user_id",
+	ColumnShortUserID:   "short_user_id",
+}
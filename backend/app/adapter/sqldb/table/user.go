@@ -2,19 +2,25 @@ package table
 
 // User represents database table columns for 'user' table
 var User = struct {
-	TableName            string
-	ColumnID             string
-	ColumnEmail          string
-	ColumnName           string
-	ColumnLastSignedInAt string
-	ColumnCreatedAt      string
-	ColumnUpdatedAt      string
+	TableName             string
+	ColumnTenantID        string
+	ColumnID              string
+	ColumnEmail           string
+	ColumnName            string
+	ColumnLastSignedInAt  string
+	ColumnCreatedAt       string
+	ColumnUpdatedAt       string
+	ColumnEmailVerified   string
+	ColumnTokensRevokedAt string
 }{
-	TableName:            "user",
-	ColumnID:             "id",
-	ColumnEmail:          "email",
-	ColumnName:           "name",
-	ColumnLastSignedInAt: "last_signed_in_at",
-	ColumnCreatedAt:      "created_at",
-	ColumnUpdatedAt:      "updated_at",
+	TableName:             "user",
+	ColumnTenantID:        "tenant_id",
+	ColumnID:              "id",
+	ColumnEmail:           "email",
+	ColumnName:            "name",
+	ColumnLastSignedInAt:  "last_signed_in_at",
+	ColumnCreatedAt:       "created_at",
+	ColumnUpdatedAt:       "updated_at",
+	ColumnEmailVerified:   "email_verified",
+	ColumnTokensRevokedAt: "tokens_revoked_at",
 }
@@ -0,0 +1,12 @@
+package table
+
+// UserPassword represents database table columns for 'user_password' table.
+var UserPassword = struct {
+	TableName          string
+	ColumnUserID       string
+	ColumnPasswordHash string
+}{
+	TableName:          "user_password",
+	ColumnUserID:       "user_id",
+	ColumnPasswordHash: "password_hash",
+}
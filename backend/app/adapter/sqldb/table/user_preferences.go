@@ -0,0 +1,15 @@
+package table
+
+// UserPreferences represents database table columns for 'user_preferences'
+// table.
+var UserPreferences = struct {
+	TableName                string
+	ColumnUserID             string
+	ColumnDefaultExpireAfter string
+	ColumnDefaultTrackVisits string
+}{
+	TableName:                "user_preferences",
+	ColumnUserID:             "user_id",
+	ColumnDefaultExpireAfter: "default_expire_after_seconds",
+	ColumnDefaultTrackVisits: "default_track_visits",
+}
@@ -3,10 +3,14 @@ package table
 // UserShortLink represents database table columns for 'user_short_link' table
 var UserShortLink = struct {
 	TableName            string
+	ColumnTenantID       string
 	ColumnUserID         string
 	ColumnShortLinkAlias string
+	ColumnIsCustomAlias  string
 }{
 	TableName:            "user_short_link",
+	ColumnTenantID:       "tenant_id",
 	ColumnUserID:         "user_id",
 	ColumnShortLinkAlias: "short_link_alias",
+	ColumnIsCustomAlias:  "is_custom_alias",
 }
@@ -0,0 +1,34 @@
+package table
+
+// Visit represents database table columns for 'visit' table
+var Visit = struct {
+	TableName         string
+	ColumnTenantID    string
+	ColumnID          string
+	ColumnAlias       string
+	ColumnIPAddress   string
+	ColumnCountryCode string
+	ColumnReferrer    string
+	ColumnBrowser     string
+	ColumnOS          string
+	ColumnDeviceClass string
+	ColumnUTMSource   string
+	ColumnUTMMedium   string
+	ColumnUTMCampaign string
+	ColumnVisitedAt   string
+}{
+	TableName:         "visit",
+	ColumnTenantID:    "tenant_id",
+	ColumnID:          "id",
+	ColumnAlias:       "alias",
+	ColumnIPAddress:   "ip_address",
+	ColumnCountryCode: "country_code",
+	ColumnReferrer:    "referrer",
+	ColumnBrowser:     "browser",
+	ColumnOS:          "os",
+	ColumnDeviceClass: "device_class",
+	ColumnUTMSource:   "utm_source",
+	ColumnUTMMedium:   "utm_medium",
+	ColumnUTMCampaign: "utm_campaign",
+	ColumnVisitedAt:   "visited_at",
+}
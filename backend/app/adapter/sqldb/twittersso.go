@@ -0,0 +1,89 @@
+package sqldb
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/short-d/app/fw/logger"
+	"github.com/short-d/short/backend/app/adapter/sqldb/table"
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+var _ repository.SSOMap = (*TwitterSSOSql)(nil)
+
+// TwitterSSOSql accesses mapping between Twitter and Short accounts in
+// SQL database.
+type TwitterSSOSql struct {
+	db     *sql.DB
+	logger logger.Logger
+}
+
+// GetShortUserID retrieves the internal user ID that is linked to the user's
+// Twitter account.
+func (g TwitterSSOSql) GetShortUserID(ssoUserID string) (string, error) {
+	query := fmt.Sprintf(`
+SELECT "%s"
+FROM "%s"
+WHERE "%s"=$1;
+`,
+		table.TwitterSSO.ColumnShortUserID,
+		table.TwitterSSO.TableName,
+		table.TwitterSSO.ColumnTwitterUserID,
+	)
+	var id string
+	err := g.db.QueryRow(query, ssoUserID).Scan(&id)
+	if err == nil {
+		return id, err
+	}
+	if err == sql.ErrNoRows {
+		return "", repository.ErrEntryNotFound(
+			fmt.Sprintf("user with Twitter ID %s not found", ssoUserID),
+		)
+	}
+	g.logger.Error(err)
+	return "", err
+}
+
+// IsSSOUserExist checks whether mapping for a given Twitter account exists in
+// the database.
+func (g TwitterSSOSql) IsSSOUserExist(ssoUserID string) (bool, error) {
+	query := fmt.Sprintf(`
+SELECT "%s"
+FROM "%s"
+WHERE "%s"=$1;
+`,
+		table.TwitterSSO.ColumnTwitterUserID,
+		table.TwitterSSO.TableName,
+		table.TwitterSSO.ColumnTwitterUserID,
+	)
+	var id string
+	err := g.db.QueryRow(query, ssoUserID).Scan(&id)
+	if err == nil {
+		return true, err
+	}
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	g.logger.Error(err)
+	return false, err
+}
+
+// CreateMapping creates links user's Twitter and Short accounts in the
+// database.
+func (g TwitterSSOSql) CreateMapping(ssoUserID string, userID string) error {
+	statement := fmt.Sprintf(`
+INSERT INTO "%s" ("%s", "%s")
+VALUES ($1, $2);
+`,
+		table.TwitterSSO.TableName,
+		table.TwitterSSO.ColumnTwitterUserID,
+		table.TwitterSSO.ColumnShortUserID,
+	)
+	_, err := g.db.Exec(statement, ssoUserID, userID)
+	return err
+}
+
+// NewTwitterSSOSql creates TwitterSSOSql.
+func NewTwitterSSOSql(db *sql.DB, logger logger.Logger) TwitterSSOSql {
+	return TwitterSSOSql{db: db, logger: logger}
+}
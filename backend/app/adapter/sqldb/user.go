@@ -3,6 +3,7 @@ package sqldb
 import (
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/short-d/short/backend/app/adapter/sqldb/table"
 	"github.com/short-d/short/backend/app/entity"
@@ -11,7 +12,8 @@ import (
 
 var _ repository.User = (*UserSQL)(nil)
 
-// UserSQL accesses User information in user table through SQL.
+// UserSQL accesses User information in user table through SQL. The user IDs
+// and emails are unique across all the tenants.
 type UserSQL struct {
 	db *sql.DB
 }
@@ -63,16 +65,18 @@ WHERE "%s"=$1;
 // GetUserByID finds an User in user table given user ID.
 func (u UserSQL) GetUserByID(id string) (entity.User, error) {
 	query := fmt.Sprintf(`
-SELECT "%s","%s","%s","%s","%s", "%s"
+SELECT "%s","%s","%s","%s","%s","%s","%s","%s"
 FROM "%s" 
 WHERE "%s"=$1;
 `,
+		table.User.ColumnTenantID,
 		table.User.ColumnID,
 		table.User.ColumnEmail,
 		table.User.ColumnName,
 		table.User.ColumnLastSignedInAt,
 		table.User.ColumnCreatedAt,
 		table.User.ColumnUpdatedAt,
+		table.User.ColumnEmailVerified,
 		table.User.TableName,
 		table.User.ColumnID,
 	)
@@ -81,12 +85,14 @@ WHERE "%s"=$1;
 
 	user := entity.User{}
 	err := row.Scan(
+		&user.TenantID,
 		&user.ID,
 		&user.Email,
 		&user.Name,
 		&user.LastSignedInAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.EmailVerified,
 	)
 
 	if err == nil {
@@ -106,16 +112,18 @@ WHERE "%s"=$1;
 // GetUserByEmail finds an User in user table given email.
 func (u UserSQL) GetUserByEmail(email string) (entity.User, error) {
 	query := fmt.Sprintf(`
-SELECT "%s","%s","%s","%s","%s", "%s"
+SELECT "%s","%s","%s","%s","%s","%s","%s","%s"
 FROM "%s" 
 WHERE "%s"=$1;
 `,
+		table.User.ColumnTenantID,
 		table.User.ColumnID,
 		table.User.ColumnEmail,
 		table.User.ColumnName,
 		table.User.ColumnLastSignedInAt,
 		table.User.ColumnCreatedAt,
 		table.User.ColumnUpdatedAt,
+		table.User.ColumnEmailVerified,
 		table.User.TableName,
 		table.User.ColumnEmail,
 	)
@@ -124,12 +132,14 @@ WHERE "%s"=$1;
 
 	user := entity.User{}
 	err := row.Scan(
+		&user.TenantID,
 		&user.ID,
 		&user.Email,
 		&user.Name,
 		&user.LastSignedInAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.EmailVerified,
 	)
 
 	if err == nil {
@@ -149,30 +159,127 @@ WHERE "%s"=$1;
 // CreateUser inserts a new User into user table.
 func (u UserSQL) CreateUser(user entity.User) error {
 	statement := fmt.Sprintf(`
-INSERT INTO "%s" ("%s", "%s","%s","%s","%s","%s")
-VALUES ($1, $2, $3, $4, $5, $6)
+INSERT INTO "%s" ("%s","%s","%s","%s","%s","%s","%s","%s")
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 `,
 		table.User.TableName,
+		table.User.ColumnTenantID,
 		table.User.ColumnID,
 		table.User.ColumnEmail,
 		table.User.ColumnName,
 		table.User.ColumnLastSignedInAt,
 		table.User.ColumnCreatedAt,
 		table.User.ColumnUpdatedAt,
+		table.User.ColumnEmailVerified,
 	)
 
 	_, err := u.db.Exec(
 		statement,
+		user.TenantID,
 		user.ID,
 		user.Email,
 		user.Name,
 		user.LastSignedInAt,
 		user.CreatedAt,
 		user.UpdatedAt,
+		user.EmailVerified,
 	)
 	return err
 }
 
+// MarkEmailVerified records that the owner of the user account has access to
+// its email.
+func (u UserSQL) MarkEmailVerified(id string) error {
+	statement := fmt.Sprintf(`
+UPDATE "%s"
+SET "%s"=TRUE
+WHERE "%s"=$1;
+`,
+		table.User.TableName,
+		table.User.ColumnEmailVerified,
+		table.User.ColumnID,
+	)
+
+	res, err := u.db.Exec(statement, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected < 1 {
+		return repository.ErrEntryNotFound("user account not found")
+	}
+	return nil
+}
+
+// RevokeTokens records that the tokens issued to the user before revokedAt
+// are no longer valid.
+func (u UserSQL) RevokeTokens(id string, revokedAt time.Time) error {
+	statement := fmt.Sprintf(`
+UPDATE "%s"
+SET "%s"=$1
+WHERE "%s"=$2;
+`,
+		table.User.TableName,
+		table.User.ColumnTokensRevokedAt,
+		table.User.ColumnID,
+	)
+
+	res, err := u.db.Exec(statement, revokedAt.UTC(), id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected < 1 {
+		return repository.ErrEntryNotFound("user account not found")
+	}
+	return nil
+}
+
+// GetTokensRevokedAt fetches the last time when the tokens of the user are
+// revoked from user table. Zero time is returned if the tokens are never
+// revoked.
+func (u UserSQL) GetTokensRevokedAt(id string) (time.Time, error) {
+	query := fmt.Sprintf(`
+SELECT "%s"
+FROM "%s"
+WHERE "%s"=$1;
+`,
+		table.User.ColumnTokensRevokedAt,
+		table.User.TableName,
+		table.User.ColumnID,
+	)
+
+	var revokedAt *time.Time
+	err := u.db.QueryRow(query, id).Scan(&revokedAt)
+	if err == sql.ErrNoRows {
+		return time.Time{}, repository.ErrEntryNotFound("user account not found")
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	if revokedAt == nil {
+		return time.Time{}, nil
+	}
+	return revokedAt.UTC(), nil
+}
+
+// CountUsers counts all the users in user table.
+func (u UserSQL) CountUsers() (int, error) {
+	query := fmt.Sprintf(`SELECT COUNT(*) FROM "%s";`, table.User.TableName)
+
+	var count int
+	err := u.db.QueryRow(query).Scan(&count)
+	return count, err
+}
+
 // NewUserSQL creates UserSQL
 func NewUserSQL(db *sql.DB) UserSQL {
 	return UserSQL{
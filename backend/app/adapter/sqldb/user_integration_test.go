@@ -16,8 +16,8 @@ import (
 )
 
 var insertUserRowSQL = fmt.Sprintf(`
-INSERT INTO "%s" (%s, %s, %s, %s, %s, %s)
-VALUES ($1, $2, $3, $4, $5, $6)`,
+INSERT INTO "%s" (%s, %s, %s, %s, %s, %s, %s)
+VALUES ($1, $2, $3, $4, $5, $6, $7)`,
 	table.User.TableName,
 	table.User.ColumnID,
 	table.User.ColumnEmail,
@@ -25,6 +25,7 @@ VALUES ($1, $2, $3, $4, $5, $6)`,
 	table.User.ColumnLastSignedInAt,
 	table.User.ColumnCreatedAt,
 	table.User.ColumnUpdatedAt,
+	table.User.ColumnEmailVerified,
 )
 
 type userTableRow struct {
@@ -34,6 +35,7 @@ type userTableRow struct {
 	lastSignedIn *time.Time
 	createdAt    *time.Time
 	updatedAt    *time.Time
+	verified     bool
 }
 
 func TestUserSql_IsIDExist(t *testing.T) {
@@ -341,6 +343,129 @@ func TestUserSql_CreateUser(t *testing.T) {
 	}
 }
 
+func TestUserSql_MarkEmailVerified(t *testing.T) {
+	testCases := []struct {
+		name      string
+		tableRows []userTableRow
+		id        string
+		hasErr    bool
+	}{
+		{
+			name:      "ID doesn't exist",
+			tableRows: []userTableRow{},
+			id:        "alpha",
+			hasErr:    true,
+		},
+		{
+			name: "email not verified",
+			tableRows: []userTableRow{
+				{id: "alpha", email: "alpha@example.com"},
+			},
+			id:     "alpha",
+			hasErr: false,
+		},
+		{
+			name: "email already verified",
+			tableRows: []userTableRow{
+				{id: "alpha", email: "alpha@example.com", verified: true},
+			},
+			id:     "alpha",
+			hasErr: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			dbtest.AccessTestDB(
+				dbConnector,
+				dbMigrationTool,
+				dbMigrationRoot,
+				dbConfig,
+				func(sqlDB *sql.DB) {
+					insertUserTableRows(t, sqlDB, testCase.tableRows)
+
+					userRepo := sqldb.NewUserSQL(sqlDB)
+					err := userRepo.MarkEmailVerified(testCase.id)
+					if testCase.hasErr {
+						assert.NotEqual(t, nil, err)
+						return
+					}
+					assert.Equal(t, nil, err)
+
+					user, err := userRepo.GetUserByID(testCase.id)
+					assert.Equal(t, nil, err)
+					assert.Equal(t, true, user.EmailVerified)
+				})
+		})
+	}
+}
+
+func TestUserSql_RevokeTokens(t *testing.T) {
+	revokedAt := mustParseTime(t, "2020-06-01T08:00:00Z")
+
+	testCases := []struct {
+		name              string
+		tableRows         []userTableRow
+		id                string
+		revokedTimes      int
+		hasErr            bool
+		expectedRevokedAt time.Time
+	}{
+		{
+			name:         "ID doesn't exist",
+			tableRows:    []userTableRow{},
+			id:           "alpha",
+			revokedTimes: 1,
+			hasErr:       true,
+		},
+		{
+			name: "tokens never revoked",
+			tableRows: []userTableRow{
+				{id: "alpha", email: "alpha@example.com"},
+			},
+			id:                "alpha",
+			revokedTimes:      0,
+			expectedRevokedAt: time.Time{},
+		},
+		{
+			name: "tokens revoked",
+			tableRows: []userTableRow{
+				{id: "alpha", email: "alpha@example.com"},
+			},
+			id:                "alpha",
+			revokedTimes:      1,
+			expectedRevokedAt: revokedAt,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			dbtest.AccessTestDB(
+				dbConnector,
+				dbMigrationTool,
+				dbMigrationRoot,
+				dbConfig,
+				func(sqlDB *sql.DB) {
+					insertUserTableRows(t, sqlDB, testCase.tableRows)
+
+					userRepo := sqldb.NewUserSQL(sqlDB)
+					for idx := 0; idx < testCase.revokedTimes; idx++ {
+						err := userRepo.RevokeTokens(testCase.id, revokedAt)
+						if testCase.hasErr {
+							assert.NotEqual(t, nil, err)
+							return
+						}
+						assert.Equal(t, nil, err)
+					}
+
+					gotRevokedAt, err := userRepo.GetTokensRevokedAt(testCase.id)
+					assert.Equal(t, nil, err)
+					assert.Equal(t, testCase.expectedRevokedAt, gotRevokedAt)
+				})
+		})
+	}
+}
+
 func insertUserTableRows(t *testing.T, sqlDB *sql.DB, tableRows []userTableRow) {
 	for _, tableRow := range tableRows {
 		_, err := sqlDB.Exec(
@@ -351,6 +476,7 @@ func insertUserTableRows(t *testing.T, sqlDB *sql.DB, tableRows []userTableRow)
 			tableRow.lastSignedIn,
 			tableRow.createdAt,
 			tableRow.updatedAt,
+			tableRow.verified,
 		)
 		assert.Equal(t, nil, err)
 	}
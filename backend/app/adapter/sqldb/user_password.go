@@ -0,0 +1,90 @@
+package sqldb
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/short-d/short/backend/app/adapter/sqldb/table"
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+var _ repository.UserPassword = (*UserPasswordSQL)(nil)
+
+// UserPasswordSQL accesses the password hashes of users in user_password
+// table through SQL.
+type UserPasswordSQL struct {
+	db *sql.DB
+}
+
+// CreatePasswordHash inserts the password hash of the user into user_password
+// table.
+func (u UserPasswordSQL) CreatePasswordHash(userID string, passwordHash string) error {
+	statement := fmt.Sprintf(`
+INSERT INTO "%s" ("%s","%s")
+VALUES ($1, $2);
+`,
+		table.UserPassword.TableName,
+		table.UserPassword.ColumnUserID,
+		table.UserPassword.ColumnPasswordHash,
+	)
+	_, err := u.db.Exec(statement, userID, passwordHash)
+	return err
+}
+
+// GetPasswordHash fetches the password hash of the user from user_password
+// table.
+func (u UserPasswordSQL) GetPasswordHash(userID string) (string, error) {
+	query := fmt.Sprintf(`
+SELECT "%s"
+FROM "%s"
+WHERE "%s"=$1;
+`,
+		table.UserPassword.ColumnPasswordHash,
+		table.UserPassword.TableName,
+		table.UserPassword.ColumnUserID,
+	)
+
+	var passwordHash string
+	err := u.db.QueryRow(query, userID).Scan(&passwordHash)
+	if err == sql.ErrNoRows {
+		return "", repository.ErrEntryNotFound(
+			fmt.Sprintf("password of user %s not found", userID),
+		)
+	}
+	return passwordHash, err
+}
+
+// UpdatePasswordHash replaces the password hash of the user in user_password
+// table.
+func (u UserPasswordSQL) UpdatePasswordHash(userID string, passwordHash string) error {
+	statement := fmt.Sprintf(`
+UPDATE "%s"
+SET "%s"=$1
+WHERE "%s"=$2;
+`,
+		table.UserPassword.TableName,
+		table.UserPassword.ColumnPasswordHash,
+		table.UserPassword.ColumnUserID,
+	)
+
+	res, err := u.db.Exec(statement, passwordHash, userID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected < 1 {
+		return repository.ErrEntryNotFound(
+			fmt.Sprintf("password of user %s not found", userID),
+		)
+	}
+	return nil
+}
+
+// NewUserPasswordSQL creates UserPasswordSQL
+func NewUserPasswordSQL(db *sql.DB) UserPasswordSQL {
+	return UserPasswordSQL{db: db}
+}
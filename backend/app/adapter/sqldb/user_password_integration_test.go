@@ -0,0 +1,152 @@
+// +build integration all
+
+package sqldb_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/db/dbtest"
+	"github.com/short-d/short/backend/app/adapter/sqldb"
+)
+
+func TestUserPasswordSQL_CreatePasswordHash(t *testing.T) {
+	testCases := []struct {
+		name          string
+		userTableRows []userTableRow
+		userID        string
+		passwordHash  string
+		hasErr        bool
+	}{
+		{
+			name: "user exists",
+			userTableRows: []userTableRow{
+				{
+					id:    "alpha",
+					email: "alpha@example.com",
+					name:  "Alpha",
+				},
+			},
+			userID:       "alpha",
+			passwordHash: "$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy",
+			hasErr:       false,
+		},
+		{
+			name:          "user does not exist",
+			userTableRows: []userTableRow{},
+			userID:        "alpha",
+			passwordHash:  "$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy",
+			hasErr:        true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			dbtest.AccessTestDB(
+				dbConnector,
+				dbMigrationTool,
+				dbMigrationRoot,
+				dbConfig,
+				func(sqlDB *sql.DB) {
+					insertUserTableRows(t, sqlDB, testCase.userTableRows)
+
+					userPasswordRepo := sqldb.NewUserPasswordSQL(sqlDB)
+					err := userPasswordRepo.CreatePasswordHash(testCase.userID, testCase.passwordHash)
+					if testCase.hasErr {
+						assert.NotEqual(t, nil, err)
+						return
+					}
+					assert.Equal(t, nil, err)
+
+					passwordHash, err := userPasswordRepo.GetPasswordHash(testCase.userID)
+					assert.Equal(t, nil, err)
+					assert.Equal(t, testCase.passwordHash, passwordHash)
+				})
+		})
+	}
+}
+
+func TestUserPasswordSQL_GetPasswordHash(t *testing.T) {
+	dbtest.AccessTestDB(
+		dbConnector,
+		dbMigrationTool,
+		dbMigrationRoot,
+		dbConfig,
+		func(sqlDB *sql.DB) {
+			insertUserTableRows(t, sqlDB, []userTableRow{
+				{
+					id:    "alpha",
+					email: "alpha@example.com",
+					name:  "Alpha",
+				},
+			})
+
+			userPasswordRepo := sqldb.NewUserPasswordSQL(sqlDB)
+			_, err := userPasswordRepo.GetPasswordHash("alpha")
+			assert.NotEqual(t, nil, err)
+		})
+}
+
+func TestUserPasswordSQL_UpdatePasswordHash(t *testing.T) {
+	testCases := []struct {
+		name           string
+		passwordHashes map[string]string
+		userID         string
+		passwordHash   string
+		hasErr         bool
+	}{
+		{
+			name: "password exists",
+			passwordHashes: map[string]string{
+				"alpha": "$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy",
+			},
+			userID:       "alpha",
+			passwordHash: "$2a$10$7EqJtq98hPqEX7fNZaFWoOhi5BWX4Z3Wl2nDTQMhVZKkOQr1EZ5ia",
+			hasErr:       false,
+		},
+		{
+			name:           "password does not exist",
+			passwordHashes: map[string]string{},
+			userID:         "alpha",
+			passwordHash:   "$2a$10$7EqJtq98hPqEX7fNZaFWoOhi5BWX4Z3Wl2nDTQMhVZKkOQr1EZ5ia",
+			hasErr:         true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			dbtest.AccessTestDB(
+				dbConnector,
+				dbMigrationTool,
+				dbMigrationRoot,
+				dbConfig,
+				func(sqlDB *sql.DB) {
+					insertUserTableRows(t, sqlDB, []userTableRow{
+						{
+							id:    "alpha",
+							email: "alpha@example.com",
+							name:  "Alpha",
+						},
+					})
+
+					userPasswordRepo := sqldb.NewUserPasswordSQL(sqlDB)
+					for userID, passwordHash := range testCase.passwordHashes {
+						err := userPasswordRepo.CreatePasswordHash(userID, passwordHash)
+						assert.Equal(t, nil, err)
+					}
+
+					err := userPasswordRepo.UpdatePasswordHash(testCase.userID, testCase.passwordHash)
+					if testCase.hasErr {
+						assert.NotEqual(t, nil, err)
+						return
+					}
+					assert.Equal(t, nil, err)
+
+					passwordHash, err := userPasswordRepo.GetPasswordHash(testCase.userID)
+					assert.Equal(t, nil, err)
+					assert.Equal(t, testCase.passwordHash, passwordHash)
+				})
+		})
+	}
+}
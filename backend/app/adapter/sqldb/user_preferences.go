@@ -0,0 +1,82 @@
+package sqldb
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/short-d/short/backend/app/adapter/sqldb/table"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+var _ repository.UserPreferences = (*UserPreferencesSQL)(nil)
+
+// UserPreferencesSQL accesses the preferences of users in user_preferences
+// table through SQL.
+type UserPreferencesSQL struct {
+	db *sql.DB
+}
+
+// GetPreferences fetches the preferences of the user from user_preferences
+// table. Users who never set preferences get empty preferences.
+func (u UserPreferencesSQL) GetPreferences(userID string) (entity.UserPreferences, error) {
+	query := fmt.Sprintf(`
+SELECT "%s","%s"
+FROM "%s"
+WHERE "%s"=$1;
+`,
+		table.UserPreferences.ColumnDefaultExpireAfter,
+		table.UserPreferences.ColumnDefaultTrackVisits,
+		table.UserPreferences.TableName,
+		table.UserPreferences.ColumnUserID,
+	)
+
+	var expireAfterSecs *int64
+	preferences := entity.UserPreferences{}
+	err := u.db.QueryRow(query, userID).Scan(&expireAfterSecs, &preferences.DefaultTrackVisits)
+	if err == sql.ErrNoRows {
+		return entity.UserPreferences{}, nil
+	}
+	if err != nil {
+		return entity.UserPreferences{}, err
+	}
+
+	if expireAfterSecs != nil {
+		expireAfter := time.Duration(*expireAfterSecs) * time.Second
+		preferences.DefaultExpireAfter = &expireAfter
+	}
+	return preferences, nil
+}
+
+// UpsertPreferences creates or replaces the preferences of the user in
+// user_preferences table.
+func (u UserPreferencesSQL) UpsertPreferences(userID string, preferences entity.UserPreferences) error {
+	statement := fmt.Sprintf(`
+INSERT INTO "%s" ("%s","%s","%s")
+VALUES ($1, $2, $3)
+ON CONFLICT ("%s") DO UPDATE
+SET "%s"=EXCLUDED."%s", "%s"=EXCLUDED."%s";
+`,
+		table.UserPreferences.TableName,
+		table.UserPreferences.ColumnUserID,
+		table.UserPreferences.ColumnDefaultExpireAfter,
+		table.UserPreferences.ColumnDefaultTrackVisits,
+		table.UserPreferences.ColumnUserID,
+		table.UserPreferences.ColumnDefaultExpireAfter, table.UserPreferences.ColumnDefaultExpireAfter,
+		table.UserPreferences.ColumnDefaultTrackVisits, table.UserPreferences.ColumnDefaultTrackVisits,
+	)
+
+	var expireAfterSecs *int64
+	if preferences.DefaultExpireAfter != nil {
+		secs := int64(*preferences.DefaultExpireAfter / time.Second)
+		expireAfterSecs = &secs
+	}
+	_, err := u.db.Exec(statement, userID, expireAfterSecs, preferences.DefaultTrackVisits)
+	return err
+}
+
+// NewUserPreferencesSQL creates UserPreferencesSQL
+func NewUserPreferencesSQL(db *sql.DB) UserPreferencesSQL {
+	return UserPreferencesSQL{db: db}
+}
@@ -0,0 +1,96 @@
+// +build integration all
+
+package sqldb_test
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/db/dbtest"
+	"github.com/short-d/short/backend/app/adapter/sqldb"
+	"github.com/short-d/short/backend/app/entity"
+)
+
+func TestUserPreferencesSQL_GetPreferences(t *testing.T) {
+	dbtest.AccessTestDB(
+		dbConnector,
+		dbMigrationTool,
+		dbMigrationRoot,
+		dbConfig,
+		func(sqlDB *sql.DB) {
+			insertUserTableRows(t, sqlDB, []userTableRow{
+				{
+					id:    "alpha",
+					email: "alpha@example.com",
+					name:  "Alpha",
+				},
+			})
+
+			userPreferencesRepo := sqldb.NewUserPreferencesSQL(sqlDB)
+			preferences, err := userPreferencesRepo.GetPreferences("alpha")
+			assert.Equal(t, nil, err)
+			assert.Equal(t, entity.UserPreferences{}, preferences)
+		})
+}
+
+func TestUserPreferencesSQL_UpsertPreferences(t *testing.T) {
+	expireAfter := 7 * 24 * time.Hour
+	trackVisits := false
+
+	testCases := []struct {
+		name        string
+		preferences []entity.UserPreferences
+	}{
+		{
+			name: "create preferences",
+			preferences: []entity.UserPreferences{
+				{
+					DefaultExpireAfter: &expireAfter,
+					DefaultTrackVisits: &trackVisits,
+				},
+			},
+		},
+		{
+			name: "replace preferences",
+			preferences: []entity.UserPreferences{
+				{
+					DefaultExpireAfter: &expireAfter,
+				},
+				{
+					DefaultTrackVisits: &trackVisits,
+				},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			dbtest.AccessTestDB(
+				dbConnector,
+				dbMigrationTool,
+				dbMigrationRoot,
+				dbConfig,
+				func(sqlDB *sql.DB) {
+					insertUserTableRows(t, sqlDB, []userTableRow{
+						{
+							id:    "alpha",
+							email: "alpha@example.com",
+							name:  "Alpha",
+						},
+					})
+
+					userPreferencesRepo := sqldb.NewUserPreferencesSQL(sqlDB)
+					for _, preferences := range testCase.preferences {
+						err := userPreferencesRepo.UpsertPreferences("alpha", preferences)
+						assert.Equal(t, nil, err)
+					}
+
+					preferences, err := userPreferencesRepo.GetPreferences("alpha")
+					assert.Equal(t, nil, err)
+					assert.Equal(t, testCase.preferences[len(testCase.preferences)-1], preferences)
+				})
+		})
+	}
+}
@@ -1,54 +1,140 @@
 package sqldb
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/short-d/short/backend/app/adapter/sqldb/table"
 	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/fw/tenant"
 	"github.com/short-d/short/backend/app/usecase/repository"
 )
 
 var _ repository.UserShortLink = (*UserShortLinkSQL)(nil)
 
 // UserShortLinkSQL accesses UserShortLink information in user_short_link
-// table.
+// table. Only the relations of the tenant attached to the context are
+// accessed.
 type UserShortLinkSQL struct {
-	db *sql.DB
+	db           *sql.DB
+	slowQueryLog SlowQueryLog
 }
 
 // CreateRelation establishes bi-directional relationship between a user and a
 // short link in user_short_link table.
-func (u UserShortLinkSQL) CreateRelation(user entity.User, shortLinkInput entity.ShortLinkInput) error {
+func (u UserShortLinkSQL) CreateRelation(ctx context.Context, user entity.User, shortLinkInput entity.ShortLinkInput, isCustomAlias bool) error {
+	defer u.slowQueryLog.track("user_short_link.CreateRelation")()
+
 	statement := fmt.Sprintf(`
-INSERT INTO "%s" ("%s","%s")
-VALUES ($1,$2)
+INSERT INTO "%s" ("%s","%s","%s","%s")
+VALUES ($1,$2,$3,$4)
 `,
 		table.UserShortLink.TableName,
+		table.UserShortLink.ColumnTenantID,
 		table.UserShortLink.ColumnUserID,
 		table.UserShortLink.ColumnShortLinkAlias,
+		table.UserShortLink.ColumnIsCustomAlias,
 	)
 
-	_, err := u.db.Exec(statement, user.ID, shortLinkInput.GetCustomAlias(""))
+	_, err := u.db.ExecContext(
+		ctx,
+		statement,
+		tenant.FromContext(ctx),
+		user.ID,
+		shortLinkInput.GetCustomAlias(""),
+		isCustomAlias,
+	)
 	return err
 }
 
+// CountAliasesByUser counts the ShortLinks of the given user which are not
+// expired at activeAt, either with custom aliases or auto generated ones.
+func (u UserShortLinkSQL) CountAliasesByUser(ctx context.Context, user entity.User, isCustomAlias bool, activeAt time.Time) (int, error) {
+	defer u.slowQueryLog.track("user_short_link.CountAliasesByUser")()
+
+	statement := fmt.Sprintf(`
+SELECT COUNT(*)
+FROM "%s"
+INNER JOIN "%s" ON "%s"."%s"="%s"."%s" AND "%s"."%s"="%s"."%s"
+WHERE "%s"."%s"=$4 AND "%s"."%s"=$1 AND "%s"."%s"=$2 AND ("%s"."%s" IS NULL OR "%s"."%s">$3);`,
+		table.UserShortLink.TableName,
+		table.ShortLink.TableName,
+		table.UserShortLink.TableName,
+		table.UserShortLink.ColumnTenantID,
+		table.ShortLink.TableName,
+		table.ShortLink.ColumnTenantID,
+		table.UserShortLink.TableName,
+		table.UserShortLink.ColumnShortLinkAlias,
+		table.ShortLink.TableName,
+		table.ShortLink.ColumnAlias,
+		table.UserShortLink.TableName,
+		table.UserShortLink.ColumnTenantID,
+		table.UserShortLink.TableName,
+		table.UserShortLink.ColumnUserID,
+		table.UserShortLink.TableName,
+		table.UserShortLink.ColumnIsCustomAlias,
+		table.ShortLink.TableName,
+		table.ShortLink.ColumnExpireAt,
+		table.ShortLink.TableName,
+		table.ShortLink.ColumnExpireAt,
+	)
+
+	var count int
+	err := u.db.QueryRowContext(ctx, statement, user.ID, isCustomAlias, activeAt, tenant.FromContext(ctx)).Scan(&count)
+	return count, err
+}
+
+// CountShortLinksByUser counts the ShortLinks created by the given user
+// without fetching them.
+func (u UserShortLinkSQL) CountShortLinksByUser(ctx context.Context, user entity.User) (int, error) {
+	defer u.slowQueryLog.track("user_short_link.CountShortLinksByUser")()
+
+	statement := fmt.Sprintf(`
+SELECT COUNT(*)
+FROM "%s"
+INNER JOIN "%s" ON "%s"."%s"="%s"."%s" AND "%s"."%s"="%s"."%s"
+WHERE "%s"."%s"=$1 AND "%s"."%s"=$2;`,
+		table.UserShortLink.TableName,
+		table.ShortLink.TableName,
+		table.UserShortLink.TableName,
+		table.UserShortLink.ColumnTenantID,
+		table.ShortLink.TableName,
+		table.ShortLink.ColumnTenantID,
+		table.UserShortLink.TableName,
+		table.UserShortLink.ColumnShortLinkAlias,
+		table.ShortLink.TableName,
+		table.ShortLink.ColumnAlias,
+		table.UserShortLink.TableName,
+		table.UserShortLink.ColumnTenantID,
+		table.UserShortLink.TableName,
+		table.UserShortLink.ColumnUserID,
+	)
+
+	var count int
+	err := u.db.QueryRowContext(ctx, statement, tenant.FromContext(ctx), user.ID).Scan(&count)
+	return count, err
+}
+
 // FindAliasesByUser fetches the aliases of all the ShortLinks created by the given user.
 // TODO(issue#260): allow API client to filter urls based on visibility.
-func (u UserShortLinkSQL) FindAliasesByUser(user entity.User) ([]string, error) {
-	statement := fmt.Sprintf(`SELECT "%s" FROM "%s" WHERE "%s"=$1;`,
+func (u UserShortLinkSQL) FindAliasesByUser(ctx context.Context, user entity.User) ([]string, error) {
+	defer u.slowQueryLog.track("user_short_link.FindAliasesByUser")()
+
+	statement := fmt.Sprintf(`SELECT "%s" FROM "%s" WHERE "%s"=$1 AND "%s"=$2;`,
 		table.UserShortLink.ColumnShortLinkAlias,
 		table.UserShortLink.TableName,
+		table.UserShortLink.ColumnTenantID,
 		table.UserShortLink.ColumnUserID,
 	)
 
 	var aliases []string
-	rows, err := u.db.Query(statement, user.ID)
-	// TODO(issue#711): errors should be checked before using defer
-	defer rows.Close()
+	rows, err := u.db.QueryContext(ctx, statement, tenant.FromContext(ctx), user.ID)
 	if err != nil {
-		return aliases, nil
+		return aliases, err
 	}
+	defer rows.Close()
 
 	for rows.Next() {
 		var alias string
@@ -63,17 +149,248 @@ func (u UserShortLinkSQL) FindAliasesByUser(user entity.User) ([]string, error)
 	return aliases, nil
 }
 
+// FindShortLinksByUser fetches at most limit ShortLinks created by the given
+// user after the cursor, ordered by creation time and alias in descending
+// order. Keyset pagination keeps the pages stable when new ShortLinks are
+// created in between and avoids scanning the skipped rows.
+func (u UserShortLinkSQL) FindShortLinksByUser(
+	ctx context.Context,
+	user entity.User,
+	after *repository.ShortLinkCursor,
+	limit int,
+) ([]entity.ShortLink, error) {
+	defer u.slowQueryLog.track("user_short_link.FindShortLinksByUser")()
+
+	return u.findShortLinksByUser(ctx, user, after, 0, limit)
+}
+
+// FindShortLinksByUserOffset fetches at most limit ShortLinks created by the
+// given user after skipping the first offset ones, in the same order as
+// FindShortLinksByUser, for the clients jumping to numbered pages.
+func (u UserShortLinkSQL) FindShortLinksByUserOffset(
+	ctx context.Context,
+	user entity.User,
+	offset int,
+	limit int,
+) ([]entity.ShortLink, error) {
+	defer u.slowQueryLog.track("user_short_link.FindShortLinksByUserOffset")()
+
+	return u.findShortLinksByUser(ctx, user, nil, offset, limit)
+}
+
+func (u UserShortLinkSQL) findShortLinksByUser(
+	ctx context.Context,
+	user entity.User,
+	after *repository.ShortLinkCursor,
+	offset int,
+	limit int,
+) ([]entity.ShortLink, error) {
+	createdAt := fmt.Sprintf(`COALESCE("%s"."%s",'0001-01-01 00:00:00+00')`,
+		table.ShortLink.TableName,
+		table.ShortLink.ColumnCreatedAt,
+	)
+	args := []interface{}{user.ID, limit, tenant.FromContext(ctx), offset}
+	keyset := ""
+	if after != nil {
+		keyset = fmt.Sprintf(`AND (%s,"%s"."%s")<($5,$6)`,
+			createdAt,
+			table.ShortLink.TableName,
+			table.ShortLink.ColumnAlias,
+		)
+		args = append(args, after.CreatedAt.UTC(), after.Alias)
+	}
+
+	statement := fmt.Sprintf(`
+SELECT "%s"."%s","%s"."%s","%s"."%s","%s"."%s","%s"."%s","%s"."%s","%s"."%s",
+"%s"."%s","%s"."%s","%s"."%s","%s"."%s","%s"."%s","%s"."%s","%s"."%s","%s"."%s","%s"."%s","%s"."%s"
+FROM "%s"
+INNER JOIN "%s" ON "%s"."%s"="%s"."%s" AND "%s"."%s"="%s"."%s"
+WHERE "%s"."%s"=$3 AND "%s"."%s"=$1 %s
+ORDER BY %s DESC,"%s"."%s" DESC
+LIMIT $2 OFFSET $4;`,
+		table.ShortLink.TableName, table.ShortLink.ColumnAlias,
+		table.ShortLink.TableName, table.ShortLink.ColumnLongLink,
+		table.ShortLink.TableName, table.ShortLink.ColumnExpireAt,
+		table.ShortLink.TableName, table.ShortLink.ColumnCreatedAt,
+		table.ShortLink.TableName, table.ShortLink.ColumnUpdatedAt,
+		table.ShortLink.TableName, table.ShortLink.ColumnOpenGraphTitle,
+		table.ShortLink.TableName, table.ShortLink.ColumnOpenGraphDescription,
+		table.ShortLink.TableName, table.ShortLink.ColumnOpenGraphImageURL,
+		table.ShortLink.TableName, table.ShortLink.ColumnTwitterTitle,
+		table.ShortLink.TableName, table.ShortLink.ColumnTwitterDescription,
+		table.ShortLink.TableName, table.ShortLink.ColumnTwitterImageURL,
+		table.ShortLink.TableName, table.ShortLink.ColumnOriginalLongLink,
+		table.ShortLink.TableName, table.ShortLink.ColumnPassthroughQuery,
+		table.ShortLink.TableName, table.ShortLink.ColumnPassthroughPath,
+		table.ShortLink.TableName, table.ShortLink.ColumnMaxRedirectsPerIP,
+		table.ShortLink.TableName, table.ShortLink.ColumnMaxVisits,
+		table.ShortLink.TableName, table.ShortLink.ColumnDescription,
+		table.UserShortLink.TableName,
+		table.ShortLink.TableName,
+		table.UserShortLink.TableName, table.UserShortLink.ColumnTenantID,
+		table.ShortLink.TableName, table.ShortLink.ColumnTenantID,
+		table.UserShortLink.TableName, table.UserShortLink.ColumnShortLinkAlias,
+		table.ShortLink.TableName, table.ShortLink.ColumnAlias,
+		table.UserShortLink.TableName, table.UserShortLink.ColumnTenantID,
+		table.UserShortLink.TableName, table.UserShortLink.ColumnUserID,
+		keyset,
+		createdAt,
+		table.ShortLink.TableName, table.ShortLink.ColumnAlias,
+	)
+
+	shortLinks := []entity.ShortLink{}
+	rows, err := u.db.QueryContext(ctx, statement, args...)
+	if err != nil {
+		return shortLinks, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		shortLink := entity.ShortLink{TenantID: tenant.FromContext(ctx)}
+		err = rows.Scan(
+			&shortLink.Alias,
+			&shortLink.LongLink,
+			&shortLink.ExpireAt,
+			&shortLink.CreatedAt,
+			&shortLink.UpdatedAt,
+			&shortLink.OpenGraphTags.Title,
+			&shortLink.OpenGraphTags.Description,
+			&shortLink.OpenGraphTags.ImageURL,
+			&shortLink.TwitterTags.Title,
+			&shortLink.TwitterTags.Description,
+			&shortLink.TwitterTags.ImageURL,
+			&shortLink.OriginalLongLink,
+			&shortLink.PassthroughQuery,
+			&shortLink.PassthroughPath,
+			&shortLink.MaxRedirectsPerIP,
+			&shortLink.MaxVisits,
+			&shortLink.Description,
+		)
+		if err != nil {
+			return shortLinks, err
+		}
+
+		shortLink.CreatedAt = utc(shortLink.CreatedAt)
+		shortLink.UpdatedAt = utc(shortLink.UpdatedAt)
+		shortLink.ExpireAt = utc(shortLink.ExpireAt)
+
+		shortLinks = append(shortLinks, shortLink)
+	}
+
+	return shortLinks, rows.Err()
+}
+
+// FindShortLinksByAliases fetches the ShortLinks of the given aliases created
+// by the given user, in no particular order. The aliases of the ShortLinks
+// created by other users are skipped.
+func (u UserShortLinkSQL) FindShortLinksByAliases(
+	ctx context.Context,
+	user entity.User,
+	aliases []string,
+) ([]entity.ShortLink, error) {
+	defer u.slowQueryLog.track("user_short_link.FindShortLinksByAliases")()
+
+	shortLinks := []entity.ShortLink{}
+	if len(aliases) == 0 {
+		return shortLinks, nil
+	}
+
+	statement := fmt.Sprintf(`
+SELECT "%s"."%s","%s"."%s","%s"."%s","%s"."%s","%s"."%s","%s"."%s","%s"."%s",
+"%s"."%s","%s"."%s","%s"."%s","%s"."%s","%s"."%s","%s"."%s","%s"."%s","%s"."%s","%s"."%s","%s"."%s"
+FROM "%s"
+INNER JOIN "%s" ON "%s"."%s"="%s"."%s" AND "%s"."%s"="%s"."%s"
+WHERE "%s"."%s"=$1 AND "%s"."%s"=$2 AND "%s"."%s" IN (%s);`,
+		table.ShortLink.TableName, table.ShortLink.ColumnAlias,
+		table.ShortLink.TableName, table.ShortLink.ColumnLongLink,
+		table.ShortLink.TableName, table.ShortLink.ColumnExpireAt,
+		table.ShortLink.TableName, table.ShortLink.ColumnCreatedAt,
+		table.ShortLink.TableName, table.ShortLink.ColumnUpdatedAt,
+		table.ShortLink.TableName, table.ShortLink.ColumnOpenGraphTitle,
+		table.ShortLink.TableName, table.ShortLink.ColumnOpenGraphDescription,
+		table.ShortLink.TableName, table.ShortLink.ColumnOpenGraphImageURL,
+		table.ShortLink.TableName, table.ShortLink.ColumnTwitterTitle,
+		table.ShortLink.TableName, table.ShortLink.ColumnTwitterDescription,
+		table.ShortLink.TableName, table.ShortLink.ColumnTwitterImageURL,
+		table.ShortLink.TableName, table.ShortLink.ColumnOriginalLongLink,
+		table.ShortLink.TableName, table.ShortLink.ColumnPassthroughQuery,
+		table.ShortLink.TableName, table.ShortLink.ColumnPassthroughPath,
+		table.ShortLink.TableName, table.ShortLink.ColumnMaxRedirectsPerIP,
+		table.ShortLink.TableName, table.ShortLink.ColumnMaxVisits,
+		table.ShortLink.TableName, table.ShortLink.ColumnDescription,
+		table.UserShortLink.TableName,
+		table.ShortLink.TableName,
+		table.UserShortLink.TableName, table.UserShortLink.ColumnTenantID,
+		table.ShortLink.TableName, table.ShortLink.ColumnTenantID,
+		table.UserShortLink.TableName, table.UserShortLink.ColumnShortLinkAlias,
+		table.ShortLink.TableName, table.ShortLink.ColumnAlias,
+		table.UserShortLink.TableName, table.UserShortLink.ColumnTenantID,
+		table.UserShortLink.TableName, table.UserShortLink.ColumnUserID,
+		table.UserShortLink.TableName, table.UserShortLink.ColumnShortLinkAlias,
+		composeParamListFrom(3, len(aliases)),
+	)
+
+	args := make([]interface{}, 0, len(aliases)+2)
+	args = append(args, tenant.FromContext(ctx), user.ID)
+	for _, alias := range aliases {
+		args = append(args, alias)
+	}
+
+	rows, err := u.db.QueryContext(ctx, statement, args...)
+	if err != nil {
+		return shortLinks, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		shortLink := entity.ShortLink{TenantID: tenant.FromContext(ctx)}
+		err = rows.Scan(
+			&shortLink.Alias,
+			&shortLink.LongLink,
+			&shortLink.ExpireAt,
+			&shortLink.CreatedAt,
+			&shortLink.UpdatedAt,
+			&shortLink.OpenGraphTags.Title,
+			&shortLink.OpenGraphTags.Description,
+			&shortLink.OpenGraphTags.ImageURL,
+			&shortLink.TwitterTags.Title,
+			&shortLink.TwitterTags.Description,
+			&shortLink.TwitterTags.ImageURL,
+			&shortLink.OriginalLongLink,
+			&shortLink.PassthroughQuery,
+			&shortLink.PassthroughPath,
+			&shortLink.MaxRedirectsPerIP,
+			&shortLink.MaxVisits,
+			&shortLink.Description,
+		)
+		if err != nil {
+			return shortLinks, err
+		}
+
+		shortLink.CreatedAt = utc(shortLink.CreatedAt)
+		shortLink.UpdatedAt = utc(shortLink.UpdatedAt)
+		shortLink.ExpireAt = utc(shortLink.ExpireAt)
+
+		shortLinks = append(shortLinks, shortLink)
+	}
+
+	return shortLinks, rows.Err()
+}
+
 // HasMapping checks whether a given short link is tied to a user.
-func (u UserShortLinkSQL) HasMapping(user entity.User, alias string) (bool, error) {
-	query := fmt.Sprintf(`SELECT "%s" FROM "%s" WHERE "%s"=$1 AND "%s"=$2`,
+func (u UserShortLinkSQL) HasMapping(ctx context.Context, user entity.User, alias string) (bool, error) {
+	defer u.slowQueryLog.track("user_short_link.HasMapping")()
+
+	query := fmt.Sprintf(`SELECT "%s" FROM "%s" WHERE "%s"=$1 AND "%s"=$2 AND "%s"=$3`,
 		table.UserShortLink.ColumnUserID,
 		table.UserShortLink.TableName,
+		table.UserShortLink.ColumnTenantID,
 		table.UserShortLink.ColumnUserID,
 		table.UserShortLink.ColumnShortLinkAlias,
 	)
 
 	var id string
-	err := u.db.QueryRow(query, user.ID, alias).Scan(&id)
+	err := u.db.QueryRowContext(ctx, query, tenant.FromContext(ctx), user.ID, alias).Scan(&id)
 	if err == sql.ErrNoRows {
 		return false, nil
 	}
@@ -83,9 +400,196 @@ func (u UserShortLinkSQL) HasMapping(user entity.User, alias string) (bool, erro
 	return true, nil
 }
 
+// CreateGuestRelation attributes a short link created by a signed out user to
+// the guest session in guest_short_link table.
+func (u UserShortLinkSQL) CreateGuestRelation(ctx context.Context, sessionID string, shortLinkInput entity.ShortLinkInput) error {
+	defer u.slowQueryLog.track("user_short_link.CreateGuestRelation")()
+
+	statement := fmt.Sprintf(`
+INSERT INTO "%s" ("%s","%s","%s")
+VALUES ($1,$2,$3)
+`,
+		table.GuestShortLink.TableName,
+		table.GuestShortLink.ColumnTenantID,
+		table.GuestShortLink.ColumnSessionID,
+		table.GuestShortLink.ColumnShortLinkAlias,
+	)
+
+	_, err := u.db.ExecContext(
+		ctx,
+		statement,
+		tenant.FromContext(ctx),
+		sessionID,
+		shortLinkInput.GetCustomAlias(""),
+	)
+	return err
+}
+
+// FindAliasesBySession fetches the aliases of all the ShortLinks created in
+// the given guest session.
+func (u UserShortLinkSQL) FindAliasesBySession(ctx context.Context, sessionID string) ([]string, error) {
+	defer u.slowQueryLog.track("user_short_link.FindAliasesBySession")()
+
+	statement := fmt.Sprintf(`SELECT "%s" FROM "%s" WHERE "%s"=$1 AND "%s"=$2;`,
+		table.GuestShortLink.ColumnShortLinkAlias,
+		table.GuestShortLink.TableName,
+		table.GuestShortLink.ColumnTenantID,
+		table.GuestShortLink.ColumnSessionID,
+	)
+
+	var aliases []string
+	rows, err := u.db.QueryContext(ctx, statement, tenant.FromContext(ctx), sessionID)
+	if err != nil {
+		return aliases, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var alias string
+		err = rows.Scan(&alias)
+		if err != nil {
+			return aliases, err
+		}
+
+		aliases = append(aliases, alias)
+	}
+
+	return aliases, nil
+}
+
+// ClaimSession moves the short links created in the given guest session from
+// guest_short_link table to user_short_link table in a single statement, and
+// returns the number of moved short links.
+func (u UserShortLinkSQL) ClaimSession(ctx context.Context, sessionID string, user entity.User) (int, error) {
+	defer u.slowQueryLog.track("user_short_link.ClaimSession")()
+
+	statement := fmt.Sprintf(`
+WITH "claimed" AS (
+	DELETE FROM "%s"
+	WHERE "%s"=$3 AND "%s"=$1
+	RETURNING "%s"
+)
+INSERT INTO "%s" ("%s","%s","%s")
+SELECT $3, $2, "%s" FROM "claimed";`,
+		table.GuestShortLink.TableName,
+		table.GuestShortLink.ColumnTenantID,
+		table.GuestShortLink.ColumnSessionID,
+		table.GuestShortLink.ColumnShortLinkAlias,
+		table.UserShortLink.TableName,
+		table.UserShortLink.ColumnTenantID,
+		table.UserShortLink.ColumnUserID,
+		table.UserShortLink.ColumnShortLinkAlias,
+		table.GuestShortLink.ColumnShortLinkAlias,
+	)
+
+	result, err := u.db.ExecContext(ctx, statement, sessionID, user.ID, tenant.FromContext(ctx))
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+// FindOrphanAliases fetches at most limit aliases after the given one, in
+// ascending order, which are referenced by user_short_link table but no
+// longer exist in short_link table.
+func (u UserShortLinkSQL) FindOrphanAliases(ctx context.Context, after string, limit int) ([]string, error) {
+	defer u.slowQueryLog.track("user_short_link.FindOrphanAliases")()
+
+	statement := fmt.Sprintf(`
+SELECT DISTINCT "%s"."%s"
+FROM "%s"
+LEFT JOIN "%s" ON "%s"."%s"="%s"."%s" AND "%s"."%s"="%s"."%s"
+WHERE "%s"."%s" IS NULL AND "%s"."%s"=$3 AND "%s"."%s">$1
+ORDER BY "%s"."%s"
+LIMIT $2;`,
+		table.UserShortLink.TableName, table.UserShortLink.ColumnShortLinkAlias,
+		table.UserShortLink.TableName,
+		table.ShortLink.TableName,
+		table.UserShortLink.TableName, table.UserShortLink.ColumnTenantID,
+		table.ShortLink.TableName, table.ShortLink.ColumnTenantID,
+		table.UserShortLink.TableName, table.UserShortLink.ColumnShortLinkAlias,
+		table.ShortLink.TableName, table.ShortLink.ColumnAlias,
+		table.ShortLink.TableName, table.ShortLink.ColumnAlias,
+		table.UserShortLink.TableName, table.UserShortLink.ColumnTenantID,
+		table.UserShortLink.TableName, table.UserShortLink.ColumnShortLinkAlias,
+		table.UserShortLink.TableName, table.UserShortLink.ColumnShortLinkAlias,
+	)
+
+	aliases := []string{}
+	rows, err := u.db.QueryContext(ctx, statement, after, limit, tenant.FromContext(ctx))
+	if err != nil {
+		return aliases, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var alias string
+		err = rows.Scan(&alias)
+		if err != nil {
+			return aliases, err
+		}
+
+		aliases = append(aliases, alias)
+	}
+
+	return aliases, rows.Err()
+}
+
+// DeleteOrphanRelations removes the relations of the given aliases from
+// user_short_link table, and returns the number of removed relations. The
+// relations of the aliases created again in short_link table in between are
+// kept.
+func (u UserShortLinkSQL) DeleteOrphanRelations(ctx context.Context, aliases []string) (int, error) {
+	defer u.slowQueryLog.track("user_short_link.DeleteOrphanRelations")()
+
+	if len(aliases) == 0 {
+		return 0, nil
+	}
+
+	statement := fmt.Sprintf(`
+DELETE FROM "%s"
+WHERE "%s"=$1 AND "%s" IN (%s) AND NOT EXISTS (
+	SELECT 1 FROM "%s"
+	WHERE "%s"."%s"="%s"."%s" AND "%s"."%s"="%s"."%s"
+);`,
+		table.UserShortLink.TableName,
+		table.UserShortLink.ColumnTenantID,
+		table.UserShortLink.ColumnShortLinkAlias,
+		composeParamListFrom(2, len(aliases)),
+		table.ShortLink.TableName,
+		table.ShortLink.TableName, table.ShortLink.ColumnTenantID,
+		table.UserShortLink.TableName, table.UserShortLink.ColumnTenantID,
+		table.ShortLink.TableName, table.ShortLink.ColumnAlias,
+		table.UserShortLink.TableName, table.UserShortLink.ColumnShortLinkAlias,
+	)
+
+	args := make([]interface{}, 0, len(aliases)+1)
+	args = append(args, tenant.FromContext(ctx))
+	for _, alias := range aliases {
+		args = append(args, alias)
+	}
+
+	result, err := u.db.ExecContext(ctx, statement, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
 // NewUserShortLinkSQL creates UserShortLinkSQL
-func NewUserShortLinkSQL(db *sql.DB) UserShortLinkSQL {
+func NewUserShortLinkSQL(db *sql.DB, slowQueryLog SlowQueryLog) UserShortLinkSQL {
 	return UserShortLinkSQL{
-		db: db,
+		db:           db,
+		slowQueryLog: slowQueryLog,
 	}
 }
@@ -3,28 +3,33 @@
 package sqldb_test
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/short-d/app/fw/assert"
 	"github.com/short-d/app/fw/db/dbtest"
 	"github.com/short-d/short/backend/app/adapter/sqldb"
 	"github.com/short-d/short/backend/app/adapter/sqldb/table"
 	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/repository"
 )
 
 var insertUserShortLinkRowSQL = fmt.Sprintf(`
-INSERT INTO %s (%s, %s)
-VALUES ($1, $2)`,
+INSERT INTO %s (%s, %s, %s)
+VALUES ($1, $2, $3)`,
 	table.UserShortLink.TableName,
 	table.UserShortLink.ColumnShortLinkAlias,
 	table.UserShortLink.ColumnUserID,
+	table.UserShortLink.ColumnIsCustomAlias,
 )
 
 type userShortLinkTableRow struct {
-	alias  string
-	userID string
+	alias         string
+	userID        string
+	isCustomAlias bool
 }
 
 func TestListShortLinkSql_FindAliasesByUser(t *testing.T) {
@@ -100,8 +105,8 @@ func TestListShortLinkSql_FindAliasesByUser(t *testing.T) {
 					insertShortLinkTableRows(t, sqlDB, testCase.shortLinkTableRows)
 					insertUserShortLinkTableRows(t, sqlDB, testCase.relationTableRows)
 
-					userShortLinkRepo := sqldb.NewUserShortLinkSQL(sqlDB)
-					result, err := userShortLinkRepo.FindAliasesByUser(testCase.user)
+					userShortLinkRepo := sqldb.NewUserShortLinkSQL(sqlDB, sqldb.SlowQueryLog{})
+					result, err := userShortLinkRepo.FindAliasesByUser(context.Background(), testCase.user)
 
 					if testCase.hasErr {
 						assert.NotEqual(t, nil, err)
@@ -114,6 +119,331 @@ func TestListShortLinkSql_FindAliasesByUser(t *testing.T) {
 	}
 }
 
+func TestListShortLinkSql_FindShortLinksByUser(t *testing.T) {
+	now := mustParseTime(t, "2019-05-01T08:02:16Z")
+	minuteAgo := now.Add(-time.Minute)
+	hourAgo := now.Add(-time.Hour)
+	hourLater := now.Add(time.Hour)
+	user := entity.User{ID: "test"}
+
+	userTableRows := []userTableRow{
+		{id: "test", email: "test@example.com"},
+		{id: "other", email: "other@example.com"},
+	}
+	shortLinkTableRows := []shortLinkTableRow{
+		{alias: "short", createdAt: &hourAgo},
+		{alias: "bing", createdAt: &minuteAgo},
+		{alias: "google", createdAt: &minuteAgo},
+		{alias: "legacy"},
+		{alias: "mozilla", createdAt: &now},
+	}
+	relationTableRows := []userShortLinkTableRow{
+		{alias: "short", userID: "test"},
+		{alias: "bing", userID: "test"},
+		{alias: "google", userID: "test"},
+		{alias: "legacy", userID: "test"},
+		{alias: "mozilla", userID: "other"},
+	}
+
+	testCases := []struct {
+		name            string
+		after           *repository.ShortLinkCursor
+		limit           int
+		newShortLinks   []shortLinkTableRow
+		expectedAliases []string
+	}{
+		{
+			name:            "first page",
+			limit:           2,
+			expectedAliases: []string{"google", "bing"},
+		},
+		{
+			name: "page after cursor",
+			after: &repository.ShortLinkCursor{
+				CreatedAt: minuteAgo,
+				Alias:     "bing",
+			},
+			limit:           2,
+			expectedAliases: []string{"short", "legacy"},
+		},
+		{
+			name: "page after cursor with new short links",
+			after: &repository.ShortLinkCursor{
+				CreatedAt: minuteAgo,
+				Alias:     "google",
+			},
+			limit: 2,
+			newShortLinks: []shortLinkTableRow{
+				{alias: "new", createdAt: &hourLater},
+			},
+			expectedAliases: []string{"bing", "short"},
+		},
+		{
+			name: "no short link after cursor",
+			after: &repository.ShortLinkCursor{
+				Alias: "legacy",
+			},
+			limit:           2,
+			expectedAliases: []string{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			dbtest.AccessTestDB(
+				dbConnector,
+				dbMigrationTool,
+				dbMigrationRoot,
+				dbConfig,
+				func(sqlDB *sql.DB) {
+					insertUserTableRows(t, sqlDB, userTableRows)
+					insertShortLinkTableRows(t, sqlDB, shortLinkTableRows)
+					insertUserShortLinkTableRows(t, sqlDB, relationTableRows)
+
+					insertShortLinkTableRows(t, sqlDB, testCase.newShortLinks)
+					for _, shortLink := range testCase.newShortLinks {
+						insertUserShortLinkTableRows(t, sqlDB, []userShortLinkTableRow{
+							{alias: shortLink.alias, userID: user.ID},
+						})
+					}
+
+					userShortLinkRepo := sqldb.NewUserShortLinkSQL(sqlDB, sqldb.SlowQueryLog{})
+					shortLinks, err := userShortLinkRepo.FindShortLinksByUser(
+						context.Background(),
+						user,
+						testCase.after,
+						testCase.limit,
+					)
+					assert.Equal(t, nil, err)
+
+					aliases := []string{}
+					for _, shortLink := range shortLinks {
+						aliases = append(aliases, shortLink.Alias)
+					}
+					assert.Equal(t, testCase.expectedAliases, aliases)
+				})
+		})
+	}
+}
+
+func TestListShortLinkSql_FindShortLinksByUserOffset(t *testing.T) {
+	now := mustParseTime(t, "2019-05-01T08:02:16Z")
+	minuteAgo := now.Add(-time.Minute)
+	hourAgo := now.Add(-time.Hour)
+	user := entity.User{ID: "test"}
+
+	userTableRows := []userTableRow{
+		{id: "test", email: "test@example.com"},
+		{id: "other", email: "other@example.com"},
+	}
+	shortLinkTableRows := []shortLinkTableRow{
+		{alias: "short", createdAt: &hourAgo},
+		{alias: "bing", createdAt: &minuteAgo},
+		{alias: "google", createdAt: &minuteAgo},
+		{alias: "legacy"},
+		{alias: "mozilla", createdAt: &now},
+	}
+	relationTableRows := []userShortLinkTableRow{
+		{alias: "short", userID: "test"},
+		{alias: "bing", userID: "test"},
+		{alias: "google", userID: "test"},
+		{alias: "legacy", userID: "test"},
+		{alias: "mozilla", userID: "other"},
+	}
+
+	testCases := []struct {
+		name            string
+		offset          int
+		limit           int
+		expectedAliases []string
+	}{
+		{
+			name:            "first page",
+			offset:          0,
+			limit:           2,
+			expectedAliases: []string{"google", "bing"},
+		},
+		{
+			name:            "second page",
+			offset:          2,
+			limit:           2,
+			expectedAliases: []string{"short", "legacy"},
+		},
+		{
+			name:            "partial last page",
+			offset:          3,
+			limit:           2,
+			expectedAliases: []string{"legacy"},
+		},
+		{
+			name:            "offset beyond short links",
+			offset:          4,
+			limit:           2,
+			expectedAliases: []string{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			dbtest.AccessTestDB(
+				dbConnector,
+				dbMigrationTool,
+				dbMigrationRoot,
+				dbConfig,
+				func(sqlDB *sql.DB) {
+					insertUserTableRows(t, sqlDB, userTableRows)
+					insertShortLinkTableRows(t, sqlDB, shortLinkTableRows)
+					insertUserShortLinkTableRows(t, sqlDB, relationTableRows)
+
+					userShortLinkRepo := sqldb.NewUserShortLinkSQL(sqlDB, sqldb.SlowQueryLog{})
+					shortLinks, err := userShortLinkRepo.FindShortLinksByUserOffset(
+						context.Background(),
+						user,
+						testCase.offset,
+						testCase.limit,
+					)
+					assert.Equal(t, nil, err)
+
+					aliases := []string{}
+					for _, shortLink := range shortLinks {
+						aliases = append(aliases, shortLink.Alias)
+					}
+					assert.Equal(t, testCase.expectedAliases, aliases)
+
+					count, err := userShortLinkRepo.CountShortLinksByUser(context.Background(), user)
+					assert.Equal(t, nil, err)
+					assert.Equal(t, 4, count)
+				})
+		})
+	}
+}
+
+func TestListShortLinkSql_FindShortLinksByAliases(t *testing.T) {
+	user := entity.User{ID: "test"}
+
+	userTableRows := []userTableRow{
+		{id: "test", email: "test@example.com"},
+		{id: "other", email: "other@example.com"},
+	}
+	shortLinkTableRows := []shortLinkTableRow{
+		{alias: "short", longLink: "https://short-d.com"},
+		{alias: "bing", longLink: "https://www.bing.com"},
+		{alias: "mozilla", longLink: "https://www.mozilla.org"},
+	}
+	relationTableRows := []userShortLinkTableRow{
+		{alias: "short", userID: "test"},
+		{alias: "bing", userID: "test"},
+		{alias: "mozilla", userID: "other"},
+	}
+
+	testCases := []struct {
+		name            string
+		aliases         []string
+		expectedAliases []string
+	}{
+		{
+			name:            "no alias",
+			aliases:         []string{},
+			expectedAliases: []string{},
+		},
+		{
+			name:            "owned aliases",
+			aliases:         []string{"bing", "short"},
+			expectedAliases: []string{"bing", "short"},
+		},
+		{
+			name:            "aliases owned by others and missing skipped",
+			aliases:         []string{"mozilla", "short", "google"},
+			expectedAliases: []string{"short"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			dbtest.AccessTestDB(
+				dbConnector,
+				dbMigrationTool,
+				dbMigrationRoot,
+				dbConfig,
+				func(sqlDB *sql.DB) {
+					insertUserTableRows(t, sqlDB, userTableRows)
+					insertShortLinkTableRows(t, sqlDB, shortLinkTableRows)
+					insertUserShortLinkTableRows(t, sqlDB, relationTableRows)
+
+					userShortLinkRepo := sqldb.NewUserShortLinkSQL(sqlDB, sqldb.SlowQueryLog{})
+					shortLinks, err := userShortLinkRepo.FindShortLinksByAliases(
+						context.Background(),
+						user,
+						testCase.aliases,
+					)
+					assert.Equal(t, nil, err)
+
+					aliases := []string{}
+					for _, shortLink := range shortLinks {
+						aliases = append(aliases, shortLink.Alias)
+					}
+					assert.SameElements(t, testCase.expectedAliases, aliases)
+				})
+		})
+	}
+}
+
+func TestListShortLinkSql_ClaimSession(t *testing.T) {
+	user := entity.User{ID: "test"}
+
+	dbtest.AccessTestDB(
+		dbConnector,
+		dbMigrationTool,
+		dbMigrationRoot,
+		dbConfig,
+		func(sqlDB *sql.DB) {
+			insertUserTableRows(t, sqlDB, []userTableRow{
+				{id: "test", email: "test@example.com"},
+			})
+			insertShortLinkTableRows(t, sqlDB, []shortLinkTableRow{
+				{alias: "google"},
+				{alias: "bing"},
+				{alias: "mozilla"},
+			})
+
+			userShortLinkRepo := sqldb.NewUserShortLinkSQL(sqlDB, sqldb.SlowQueryLog{})
+			guestShortLinks := map[string]string{
+				"google":  "session1",
+				"bing":    "session1",
+				"mozilla": "session2",
+			}
+			for alias, sessionID := range guestShortLinks {
+				alias := alias
+				err := userShortLinkRepo.CreateGuestRelation(
+					context.Background(),
+					sessionID,
+					entity.ShortLinkInput{CustomAlias: &alias},
+				)
+				assert.Equal(t, nil, err)
+			}
+
+			aliases, err := userShortLinkRepo.FindAliasesBySession(context.Background(), "session1")
+			assert.Equal(t, nil, err)
+			assert.SameElements(t, []string{"google", "bing"}, aliases)
+
+			claimed, err := userShortLinkRepo.ClaimSession(context.Background(), "session1", user)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, 2, claimed)
+
+			aliases, err = userShortLinkRepo.FindAliasesByUser(context.Background(), user)
+			assert.Equal(t, nil, err)
+			assert.SameElements(t, []string{"google", "bing"}, aliases)
+
+			aliases, err = userShortLinkRepo.FindAliasesBySession(context.Background(), "session1")
+			assert.Equal(t, nil, err)
+			assert.Equal(t, 0, len(aliases))
+
+			aliases, err = userShortLinkRepo.FindAliasesBySession(context.Background(), "session2")
+			assert.Equal(t, nil, err)
+			assert.Equal(t, []string{"mozilla"}, aliases)
+		})
+}
+
 func TestListShortLinkSql_HasMapping(t *testing.T) {
 	now := mustParseTime(t, "2019-05-01T08:02:16Z")
 
@@ -267,8 +597,8 @@ func TestListShortLinkSql_HasMapping(t *testing.T) {
 					insertShortLinkTableRows(t, sqlDB, testCase.shortLinkTableRows)
 					insertUserShortLinkTableRows(t, sqlDB, testCase.relationTableRows)
 
-					userShortLinkRepo := sqldb.NewUserShortLinkSQL(sqlDB)
-					result, err := userShortLinkRepo.HasMapping(testCase.user, testCase.alias)
+					userShortLinkRepo := sqldb.NewUserShortLinkSQL(sqlDB, sqldb.SlowQueryLog{})
+					result, err := userShortLinkRepo.HasMapping(context.Background(), testCase.user, testCase.alias)
 					assert.Equal(t, nil, err)
 					assert.Equal(t, testCase.expectIsFound, result)
 				})
@@ -276,6 +606,108 @@ func TestListShortLinkSql_HasMapping(t *testing.T) {
 	}
 }
 
+func TestUserShortLinkSql_CountAliasesByUser(t *testing.T) {
+	activeAt := mustParseTime(t, "2019-05-01T08:02:16Z")
+	expiredAt := activeAt.Add(-time.Hour)
+	expireAt := activeAt.Add(time.Hour)
+
+	shortLinkTableRows := []shortLinkTableRow{
+		{alias: "alpha", longLink: "https://www.google.com"},
+		{alias: "beta", longLink: "https://www.google.com", expireAt: &expireAt},
+		{alias: "gamma", longLink: "https://www.google.com", expireAt: &expiredAt},
+		{alias: "220uFicCJj", longLink: "https://www.google.com"},
+		{alias: "delta", longLink: "https://www.google.com"},
+	}
+	relationTableRows := []userShortLinkTableRow{
+		{alias: "alpha", userID: "test", isCustomAlias: true},
+		{alias: "beta", userID: "test", isCustomAlias: true},
+		{alias: "gamma", userID: "test", isCustomAlias: true},
+		{alias: "220uFicCJj", userID: "test", isCustomAlias: false},
+		{alias: "delta", userID: "other", isCustomAlias: true},
+	}
+
+	testCases := []struct {
+		name          string
+		isCustomAlias bool
+		expectedCount int
+	}{
+		{
+			name:          "active custom aliases",
+			isCustomAlias: true,
+			expectedCount: 2,
+		},
+		{
+			name:          "active auto aliases",
+			isCustomAlias: false,
+			expectedCount: 1,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			dbtest.AccessTestDB(
+				dbConnector,
+				dbMigrationTool,
+				dbMigrationRoot,
+				dbConfig,
+				func(sqlDB *sql.DB) {
+					insertShortLinkTableRows(t, sqlDB, shortLinkTableRows)
+					insertUserShortLinkTableRows(t, sqlDB, relationTableRows)
+
+					userShortLinkRepo := sqldb.NewUserShortLinkSQL(sqlDB, sqldb.SlowQueryLog{})
+					user := entity.User{ID: "test"}
+					count, err := userShortLinkRepo.CountAliasesByUser(context.Background(), user, testCase.isCustomAlias, activeAt)
+					assert.Equal(t, nil, err)
+					assert.Equal(t, testCase.expectedCount, count)
+				})
+		})
+	}
+}
+
+func TestUserShortLinkSql_PruneOrphanRelations(t *testing.T) {
+	dbtest.AccessTestDB(
+		dbConnector,
+		dbMigrationTool,
+		dbMigrationRoot,
+		dbConfig,
+		func(sqlDB *sql.DB) {
+			insertShortLinkTableRows(t, sqlDB, []shortLinkTableRow{
+				{alias: "google"},
+			})
+			insertUserShortLinkTableRows(t, sqlDB, []userShortLinkTableRow{
+				{alias: "google", userID: "alpha"},
+				{alias: "gone", userID: "alpha"},
+				{alias: "gone", userID: "beta"},
+				{alias: "lost", userID: "beta"},
+			})
+
+			userShortLinkRepo := sqldb.NewUserShortLinkSQL(sqlDB, sqldb.SlowQueryLog{})
+			aliases, err := userShortLinkRepo.FindOrphanAliases(context.Background(), "", 10)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, []string{"gone", "lost"}, aliases)
+
+			aliases, err = userShortLinkRepo.FindOrphanAliases(context.Background(), "gone", 10)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, []string{"lost"}, aliases)
+
+			aliases, err = userShortLinkRepo.FindOrphanAliases(context.Background(), "", 1)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, []string{"gone"}, aliases)
+
+			count, err := userShortLinkRepo.DeleteOrphanRelations(context.Background(), []string{"google", "gone"})
+			assert.Equal(t, nil, err)
+			assert.Equal(t, 2, count)
+
+			aliases, err = userShortLinkRepo.FindAliasesByUser(context.Background(), entity.User{ID: "alpha"})
+			assert.Equal(t, nil, err)
+			assert.Equal(t, []string{"google"}, aliases)
+
+			aliases, err = userShortLinkRepo.FindOrphanAliases(context.Background(), "", 10)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, []string{"lost"}, aliases)
+		})
+}
+
 func insertUserShortLinkTableRows(
 	t *testing.T,
 	sqlDB *sql.DB,
@@ -286,6 +718,7 @@ func insertUserShortLinkTableRows(
 			insertUserShortLinkRowSQL,
 			tableRow.alias,
 			tableRow.userID,
+			tableRow.isCustomAlias,
 		)
 		assert.Equal(t, nil, err)
 	}
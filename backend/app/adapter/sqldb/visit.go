@@ -0,0 +1,364 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/short-d/short/backend/app/adapter/sqldb/table"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/fw/tenant"
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+var _ repository.Visit = (*VisitSQL)(nil)
+
+// VisitSQL accesses the visits of short links in visit table through SQL.
+// Only the visits of the short links of the tenant attached to the context are
+// accessed.
+type VisitSQL struct {
+	db *sql.DB
+}
+
+// maxVisitsPerInsert keeps the parameters of each insert statement well
+// below the limit of PostgreSQL.
+const maxVisitsPerInsert = 1000
+
+// CreateVisits inserts the visits into visit table within a single
+// transaction so that either all or none of them are recorded. The visits of
+// the short links which no longer exist are skipped instead of failing the
+// others.
+func (v VisitSQL) CreateVisits(ctx context.Context, visits []entity.Visit) error {
+	if len(visits) == 0 {
+		return nil
+	}
+
+	tx, err := v.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	for start := 0; start < len(visits); start += maxVisitsPerInsert {
+		end := start + maxVisitsPerInsert
+		if end > len(visits) {
+			end = len(visits)
+		}
+
+		err = insertVisits(ctx, tx, visits[start:end])
+		if err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func insertVisits(ctx context.Context, tx *sql.Tx, visits []entity.Visit) error {
+	values := make([]string, 0, len(visits))
+	args := make([]interface{}, 0, 11*len(visits)+1)
+	args = append(args, tenant.FromContext(ctx))
+	for _, visit := range visits {
+		values = append(values, fmt.Sprintf(
+			"(%s, $%d::TIMESTAMP WITH TIME ZONE)",
+			composeParamListFrom(len(args)+1, 10),
+			len(args)+11,
+		))
+		args = append(args,
+			visit.Alias,
+			visit.IPAddress,
+			visit.CountryCode,
+			visit.Referrer,
+			visit.UserAgent.Browser,
+			visit.UserAgent.OS,
+			visit.UserAgent.DeviceClass,
+			visit.Campaign.Source,
+			visit.Campaign.Medium,
+			visit.Campaign.Name,
+			visit.VisitedAt.UTC(),
+		)
+	}
+
+	columns := fmt.Sprintf(`"%s","%s","%s","%s","%s","%s","%s","%s","%s","%s","%s"`,
+		table.Visit.ColumnAlias,
+		table.Visit.ColumnIPAddress,
+		table.Visit.ColumnCountryCode,
+		table.Visit.ColumnReferrer,
+		table.Visit.ColumnBrowser,
+		table.Visit.ColumnOS,
+		table.Visit.ColumnDeviceClass,
+		table.Visit.ColumnUTMSource,
+		table.Visit.ColumnUTMMedium,
+		table.Visit.ColumnUTMCampaign,
+		table.Visit.ColumnVisitedAt,
+	)
+	statement := fmt.Sprintf(`
+INSERT INTO "%s" ("%s",%s)
+SELECT $1,%s
+FROM (VALUES %s) AS "new_visit"(%s)
+WHERE EXISTS (
+	SELECT 1 FROM "%s"
+	WHERE "%s"."%s"=$1 AND "%s"."%s"="new_visit"."%s"
+);`,
+		table.Visit.TableName,
+		table.Visit.ColumnTenantID,
+		columns,
+		columns,
+		strings.Join(values, ", "),
+		columns,
+		table.ShortLink.TableName,
+		table.ShortLink.TableName,
+		table.ShortLink.ColumnTenantID,
+		table.ShortLink.TableName,
+		table.ShortLink.ColumnAlias,
+		table.Visit.ColumnAlias,
+	)
+
+	_, err := tx.ExecContext(ctx, statement, args...)
+	return err
+}
+
+// FindVisitsByAlias fetches the visits of a short link which happened within
+// [from, to) from visit table.
+func (v VisitSQL) FindVisitsByAlias(ctx context.Context, alias string, from time.Time, to time.Time) ([]entity.Visit, error) {
+	statement := fmt.Sprintf(`
+SELECT "%s","%s","%s","%s","%s","%s","%s","%s","%s","%s","%s"
+FROM "%s"
+WHERE "%s"=$4 AND "%s"=$1 AND "%s">=$2 AND "%s"<$3
+ORDER BY "%s";`,
+		table.Visit.ColumnAlias,
+		table.Visit.ColumnIPAddress,
+		table.Visit.ColumnCountryCode,
+		table.Visit.ColumnReferrer,
+		table.Visit.ColumnBrowser,
+		table.Visit.ColumnOS,
+		table.Visit.ColumnDeviceClass,
+		table.Visit.ColumnUTMSource,
+		table.Visit.ColumnUTMMedium,
+		table.Visit.ColumnUTMCampaign,
+		table.Visit.ColumnVisitedAt,
+		table.Visit.TableName,
+		table.Visit.ColumnTenantID,
+		table.Visit.ColumnAlias,
+		table.Visit.ColumnVisitedAt,
+		table.Visit.ColumnVisitedAt,
+		table.Visit.ColumnVisitedAt,
+	)
+
+	rows, err := v.db.QueryContext(ctx, statement, alias, from.UTC(), to.UTC(), tenant.FromContext(ctx))
+	if err != nil {
+		return []entity.Visit{}, err
+	}
+	defer rows.Close()
+
+	visits := []entity.Visit{}
+	for rows.Next() {
+		visit := entity.Visit{}
+		err = rows.Scan(
+			&visit.Alias,
+			&visit.IPAddress,
+			&visit.CountryCode,
+			&visit.Referrer,
+			&visit.UserAgent.Browser,
+			&visit.UserAgent.OS,
+			&visit.UserAgent.DeviceClass,
+			&visit.Campaign.Source,
+			&visit.Campaign.Medium,
+			&visit.Campaign.Name,
+			&visit.VisitedAt,
+		)
+		if err != nil {
+			return visits, err
+		}
+		visit.VisitedAt = visit.VisitedAt.UTC()
+		visits = append(visits, visit)
+	}
+	return visits, rows.Err()
+}
+
+// CountVisitsByTime counts the visits of a short link which happened within
+// [from, to) for each hour or day in UTC from visit table, keyed by the start
+// of the hour or the day. The unit is either hour or day. The visits are
+// grouped in the database over the index on tenant, alias and visit time, so
+// that the visits themselves are never loaded.
+func (v VisitSQL) CountVisitsByTime(ctx context.Context, alias string, unit string, from time.Time, to time.Time) (map[time.Time]int, error) {
+	statement := fmt.Sprintf(`
+SELECT EXTRACT(EPOCH FROM DATE_TRUNC($5, "%s" AT TIME ZONE 'UTC'))::BIGINT AS "bucket", COUNT(*)
+FROM "%s"
+WHERE "%s"=$4 AND "%s"=$1 AND "%s">=$2 AND "%s"<$3
+GROUP BY "bucket";`,
+		table.Visit.ColumnVisitedAt,
+		table.Visit.TableName,
+		table.Visit.ColumnTenantID,
+		table.Visit.ColumnAlias,
+		table.Visit.ColumnVisitedAt,
+		table.Visit.ColumnVisitedAt,
+	)
+
+	rows, err := v.db.QueryContext(ctx, statement, alias, from.UTC(), to.UTC(), tenant.FromContext(ctx), unit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[time.Time]int)
+	for rows.Next() {
+		var (
+			bucket int64
+			count  int
+		)
+		err = rows.Scan(&bucket, &count)
+		if err != nil {
+			return counts, err
+		}
+		counts[time.Unix(bucket, 0).UTC()] = count
+	}
+	return counts, rows.Err()
+}
+
+// CountVisitsByReferrer counts the visits of a short link for each referring
+// host from visit table.
+func (v VisitSQL) CountVisitsByReferrer(ctx context.Context, alias string) (map[string]int, error) {
+	statement := fmt.Sprintf(`
+SELECT "%s", COUNT(*)
+FROM "%s"
+WHERE "%s"=$2 AND "%s"=$1
+GROUP BY "%s";`,
+		table.Visit.ColumnReferrer,
+		table.Visit.TableName,
+		table.Visit.ColumnTenantID,
+		table.Visit.ColumnAlias,
+		table.Visit.ColumnReferrer,
+	)
+
+	rows, err := v.db.QueryContext(ctx, statement, alias, tenant.FromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var (
+			referrer string
+			count    int
+		)
+		err = rows.Scan(&referrer, &count)
+		if err != nil {
+			return counts, err
+		}
+		counts[referrer] = count
+	}
+	return counts, rows.Err()
+}
+
+// CountVisitsByUserAgent counts the visits of a short link for each
+// combination of browser, operating system and device class from visit table.
+func (v VisitSQL) CountVisitsByUserAgent(ctx context.Context, alias string) (map[entity.UserAgent]int, error) {
+	statement := fmt.Sprintf(`
+SELECT "%s","%s","%s", COUNT(*)
+FROM "%s"
+WHERE "%s"=$2 AND "%s"=$1
+GROUP BY "%s","%s","%s";`,
+		table.Visit.ColumnBrowser,
+		table.Visit.ColumnOS,
+		table.Visit.ColumnDeviceClass,
+		table.Visit.TableName,
+		table.Visit.ColumnTenantID,
+		table.Visit.ColumnAlias,
+		table.Visit.ColumnBrowser,
+		table.Visit.ColumnOS,
+		table.Visit.ColumnDeviceClass,
+	)
+
+	rows, err := v.db.QueryContext(ctx, statement, alias, tenant.FromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[entity.UserAgent]int)
+	for rows.Next() {
+		var (
+			userAgent entity.UserAgent
+			count     int
+		)
+		err = rows.Scan(
+			&userAgent.Browser,
+			&userAgent.OS,
+			&userAgent.DeviceClass,
+			&count,
+		)
+		if err != nil {
+			return counts, err
+		}
+		counts[userAgent] = count
+	}
+	return counts, rows.Err()
+}
+
+// CountVisitsByCampaign counts the visits of a short link for each
+// combination of UTM source, medium and campaign from visit table.
+func (v VisitSQL) CountVisitsByCampaign(ctx context.Context, alias string) (map[entity.Campaign]int, error) {
+	statement := fmt.Sprintf(`
+SELECT "%s","%s","%s", COUNT(*)
+FROM "%s"
+WHERE "%s"=$2 AND "%s"=$1
+GROUP BY "%s","%s","%s";`,
+		table.Visit.ColumnUTMSource,
+		table.Visit.ColumnUTMMedium,
+		table.Visit.ColumnUTMCampaign,
+		table.Visit.TableName,
+		table.Visit.ColumnTenantID,
+		table.Visit.ColumnAlias,
+		table.Visit.ColumnUTMSource,
+		table.Visit.ColumnUTMMedium,
+		table.Visit.ColumnUTMCampaign,
+	)
+
+	rows, err := v.db.QueryContext(ctx, statement, alias, tenant.FromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[entity.Campaign]int)
+	for rows.Next() {
+		var (
+			campaign entity.Campaign
+			count    int
+		)
+		err = rows.Scan(
+			&campaign.Source,
+			&campaign.Medium,
+			&campaign.Name,
+			&count,
+		)
+		if err != nil {
+			return counts, err
+		}
+		counts[campaign] = count
+	}
+	return counts, rows.Err()
+}
+
+// CountVisitsSince counts the visits of all short links in visit table across
+// all the tenants which happened at or after since.
+func (v VisitSQL) CountVisitsSince(since time.Time) (int, error) {
+	statement := fmt.Sprintf(`SELECT COUNT(*) FROM "%s" WHERE "%s">=$1;`,
+		table.Visit.TableName,
+		table.Visit.ColumnVisitedAt,
+	)
+
+	var count int
+	err := v.db.QueryRow(statement, since.UTC()).Scan(&count)
+	return count, err
+}
+
+// NewVisitSQL creates VisitSQL
+func NewVisitSQL(db *sql.DB) VisitSQL {
+	return VisitSQL{
+		db: db,
+	}
+}
@@ -0,0 +1,700 @@
+// +build integration all
+
+package sqldb_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/db/dbtest"
+	"github.com/short-d/short/backend/app/adapter/sqldb"
+	"github.com/short-d/short/backend/app/adapter/sqldb/table"
+	"github.com/short-d/short/backend/app/entity"
+)
+
+var insertVisitRowSQL = fmt.Sprintf(`
+INSERT INTO %s (%s, %s, %s, %s, %s, %s, %s, %s, %s)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);`,
+	table.Visit.TableName,
+	table.Visit.ColumnAlias,
+	table.Visit.ColumnReferrer,
+	table.Visit.ColumnBrowser,
+	table.Visit.ColumnOS,
+	table.Visit.ColumnDeviceClass,
+	table.Visit.ColumnUTMSource,
+	table.Visit.ColumnUTMMedium,
+	table.Visit.ColumnUTMCampaign,
+	table.Visit.ColumnVisitedAt,
+)
+
+type visitTableRow struct {
+	alias       string
+	referrer    string
+	browser     string
+	os          string
+	deviceClass string
+	utmSource   string
+	utmMedium   string
+	utmCampaign string
+	visitedAt   time.Time
+}
+
+func TestVisitSQL_CreateVisits(t *testing.T) {
+	now := mustParseTime(t, "2020-05-01T08:02:16-07:00")
+	visit := entity.Visit{
+		Alias:       "220uFicCJj",
+		IPAddress:   "192.0.2.0",
+		CountryCode: "US",
+		Referrer:    "twitter.com",
+		UserAgent: entity.UserAgent{
+			Browser:     "Chrome",
+			OS:          "Android",
+			DeviceClass: "mobile",
+		},
+		Campaign: entity.Campaign{
+			Source: "newsletter",
+			Medium: "email",
+			Name:   "spring_sale",
+		},
+		VisitedAt: now,
+	}
+	storedVisit := visit
+	storedVisit.VisitedAt = now.UTC()
+
+	testCases := []struct {
+		name               string
+		shortLinkTableRows []shortLinkTableRow
+		visits             []entity.Visit
+		expectedVisits     map[string][]entity.Visit
+	}{
+		{
+			name: "short link exists",
+			shortLinkTableRows: []shortLinkTableRow{
+				{
+					alias:    "220uFicCJj",
+					longLink: "https://www.google.com",
+				},
+			},
+			visits: []entity.Visit{visit, visit},
+			expectedVisits: map[string][]entity.Visit{
+				"220uFicCJj": {storedVisit, storedVisit},
+			},
+		},
+		{
+			name: "short link does not exist",
+			shortLinkTableRows: []shortLinkTableRow{
+				{
+					alias:    "google",
+					longLink: "https://www.google.com",
+				},
+			},
+			visits: []entity.Visit{
+				visit,
+				{
+					Alias:     "google",
+					VisitedAt: now,
+				},
+			},
+			expectedVisits: map[string][]entity.Visit{
+				"220uFicCJj": {},
+				"google": {
+					{
+						Alias:     "google",
+						VisitedAt: now.UTC(),
+					},
+				},
+			},
+		},
+		{
+			name:               "no visits",
+			shortLinkTableRows: []shortLinkTableRow{},
+			visits:             []entity.Visit{},
+			expectedVisits:     map[string][]entity.Visit{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			dbtest.AccessTestDB(
+				dbConnector,
+				dbMigrationTool,
+				dbMigrationRoot,
+				dbConfig,
+				func(sqlDB *sql.DB) {
+					insertShortLinkTableRows(t, sqlDB, testCase.shortLinkTableRows)
+
+					visitRepo := sqldb.NewVisitSQL(sqlDB)
+					err := visitRepo.CreateVisits(context.Background(), testCase.visits)
+					assert.Equal(t, nil, err)
+
+					for alias, expectedVisits := range testCase.expectedVisits {
+						visits, err := visitRepo.FindVisitsByAlias(
+							context.Background(),
+							alias,
+							now,
+							now.Add(time.Second),
+						)
+						assert.Equal(t, nil, err)
+						assert.Equal(t, expectedVisits, visits)
+					}
+				})
+		})
+	}
+}
+
+func TestVisitSQL_FindVisitsByAlias(t *testing.T) {
+	now := mustParseTime(t, "2020-05-01T08:02:16-07:00")
+	hourAgo := now.Add(-time.Hour)
+	dayAgo := now.AddDate(0, 0, -1)
+
+	testCases := []struct {
+		name               string
+		shortLinkTableRows []shortLinkTableRow
+		visitTableRows     []visitTableRow
+		alias              string
+		from               time.Time
+		to                 time.Time
+		expectedVisits     []entity.Visit
+	}{
+		{
+			name: "no visits",
+			shortLinkTableRows: []shortLinkTableRow{
+				{
+					alias:    "220uFicCJj",
+					longLink: "https://www.google.com",
+				},
+			},
+			visitTableRows: []visitTableRow{},
+			alias:          "220uFicCJj",
+			from:           dayAgo,
+			to:             now,
+			expectedVisits: []entity.Visit{},
+		},
+		{
+			name: "visits within the time range",
+			shortLinkTableRows: []shortLinkTableRow{
+				{
+					alias:    "220uFicCJj",
+					longLink: "https://www.google.com",
+				},
+				{
+					alias:    "yDOBcj5HIPbUAsw",
+					longLink: "https://github.com",
+				},
+			},
+			visitTableRows: []visitTableRow{
+				{
+					alias:     "220uFicCJj",
+					visitedAt: dayAgo,
+				},
+				{
+					alias:     "220uFicCJj",
+					visitedAt: hourAgo,
+				},
+				{
+					alias:     "220uFicCJj",
+					visitedAt: now,
+				},
+				{
+					alias:     "yDOBcj5HIPbUAsw",
+					visitedAt: hourAgo,
+				},
+			},
+			alias: "220uFicCJj",
+			from:  dayAgo,
+			to:    now,
+			expectedVisits: []entity.Visit{
+				{
+					Alias:     "220uFicCJj",
+					VisitedAt: dayAgo.UTC(),
+				},
+				{
+					Alias:     "220uFicCJj",
+					VisitedAt: hourAgo.UTC(),
+				},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			dbtest.AccessTestDB(
+				dbConnector,
+				dbMigrationTool,
+				dbMigrationRoot,
+				dbConfig,
+				func(sqlDB *sql.DB) {
+					insertShortLinkTableRows(t, sqlDB, testCase.shortLinkTableRows)
+					insertVisitTableRows(t, sqlDB, testCase.visitTableRows)
+
+					visitRepo := sqldb.NewVisitSQL(sqlDB)
+					visits, err := visitRepo.FindVisitsByAlias(
+						context.Background(),
+						testCase.alias,
+						testCase.from,
+						testCase.to,
+					)
+					assert.Equal(t, nil, err)
+					assert.Equal(t, testCase.expectedVisits, visits)
+				})
+		})
+	}
+}
+
+func TestVisitSQL_CountVisitsByTime(t *testing.T) {
+	now := mustParseTime(t, "2020-05-01T08:02:16-07:00")
+	shortLinkTableRows := []shortLinkTableRow{
+		{
+			alias:    "220uFicCJj",
+			longLink: "https://www.google.com",
+		},
+		{
+			alias:    "yDOBcj5HIPbUAsw",
+			longLink: "https://github.com",
+		},
+	}
+	visitTableRows := []visitTableRow{
+		{
+			alias:     "220uFicCJj",
+			visitedAt: now.AddDate(0, 0, -1),
+		},
+		{
+			alias:     "220uFicCJj",
+			visitedAt: now.Add(-time.Hour),
+		},
+		{
+			alias:     "220uFicCJj",
+			visitedAt: now.Add(-time.Minute),
+		},
+		{
+			alias:     "220uFicCJj",
+			visitedAt: now,
+		},
+		{
+			alias:     "220uFicCJj",
+			visitedAt: now.Add(time.Hour),
+		},
+		{
+			alias:     "yDOBcj5HIPbUAsw",
+			visitedAt: now,
+		},
+	}
+
+	testCases := []struct {
+		name           string
+		alias          string
+		unit           string
+		from           time.Time
+		to             time.Time
+		expectedCounts map[time.Time]int
+	}{
+		{
+			name:           "no visits",
+			alias:          "220uFicCJj",
+			unit:           "hour",
+			from:           now.AddDate(0, 0, -3),
+			to:             now.AddDate(0, 0, -2),
+			expectedCounts: map[time.Time]int{},
+		},
+		{
+			name:  "visits per hour",
+			alias: "220uFicCJj",
+			unit:  "hour",
+			from:  now.AddDate(0, 0, -1),
+			to:    now.Add(time.Hour),
+			expectedCounts: map[time.Time]int{
+				time.Date(2020, 4, 30, 15, 0, 0, 0, time.UTC): 1,
+				time.Date(2020, 5, 1, 14, 0, 0, 0, time.UTC):  1,
+				time.Date(2020, 5, 1, 15, 0, 0, 0, time.UTC):  2,
+			},
+		},
+		{
+			name:  "visits per day",
+			alias: "220uFicCJj",
+			unit:  "day",
+			from:  now.AddDate(0, 0, -1),
+			to:    now.Add(2 * time.Hour),
+			expectedCounts: map[time.Time]int{
+				time.Date(2020, 4, 30, 0, 0, 0, 0, time.UTC): 1,
+				time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC):  4,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			dbtest.AccessTestDB(
+				dbConnector,
+				dbMigrationTool,
+				dbMigrationRoot,
+				dbConfig,
+				func(sqlDB *sql.DB) {
+					insertShortLinkTableRows(t, sqlDB, shortLinkTableRows)
+					insertVisitTableRows(t, sqlDB, visitTableRows)
+
+					visitRepo := sqldb.NewVisitSQL(sqlDB)
+					counts, err := visitRepo.CountVisitsByTime(
+						context.Background(),
+						testCase.alias,
+						testCase.unit,
+						testCase.from,
+						testCase.to,
+					)
+					assert.Equal(t, nil, err)
+					assert.Equal(t, testCase.expectedCounts, counts)
+				})
+		})
+	}
+}
+
+func TestVisitSQL_CountVisitsByReferrer(t *testing.T) {
+	now := mustParseTime(t, "2020-05-01T08:02:16-07:00")
+
+	testCases := []struct {
+		name               string
+		shortLinkTableRows []shortLinkTableRow
+		visitTableRows     []visitTableRow
+		alias              string
+		expectedCounts     map[string]int
+	}{
+		{
+			name: "no visits",
+			shortLinkTableRows: []shortLinkTableRow{
+				{
+					alias:    "220uFicCJj",
+					longLink: "https://www.google.com",
+				},
+			},
+			visitTableRows: []visitTableRow{},
+			alias:          "220uFicCJj",
+			expectedCounts: map[string]int{},
+		},
+		{
+			name: "visits from several referrers",
+			shortLinkTableRows: []shortLinkTableRow{
+				{
+					alias:    "220uFicCJj",
+					longLink: "https://www.google.com",
+				},
+				{
+					alias:    "yDOBcj5HIPbUAsw",
+					longLink: "https://github.com",
+				},
+			},
+			visitTableRows: []visitTableRow{
+				{
+					alias:     "220uFicCJj",
+					referrer:  "twitter.com",
+					visitedAt: now,
+				},
+				{
+					alias:     "220uFicCJj",
+					referrer:  "twitter.com",
+					visitedAt: now,
+				},
+				{
+					alias:     "220uFicCJj",
+					referrer:  "",
+					visitedAt: now,
+				},
+				{
+					alias:     "yDOBcj5HIPbUAsw",
+					referrer:  "twitter.com",
+					visitedAt: now,
+				},
+			},
+			alias: "220uFicCJj",
+			expectedCounts: map[string]int{
+				"twitter.com": 2,
+				"":            1,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			dbtest.AccessTestDB(
+				dbConnector,
+				dbMigrationTool,
+				dbMigrationRoot,
+				dbConfig,
+				func(sqlDB *sql.DB) {
+					insertShortLinkTableRows(t, sqlDB, testCase.shortLinkTableRows)
+					insertVisitTableRows(t, sqlDB, testCase.visitTableRows)
+
+					visitRepo := sqldb.NewVisitSQL(sqlDB)
+					counts, err := visitRepo.CountVisitsByReferrer(context.Background(), testCase.alias)
+					assert.Equal(t, nil, err)
+					assert.Equal(t, testCase.expectedCounts, counts)
+				})
+		})
+	}
+}
+
+func TestVisitSQL_CountVisitsByUserAgent(t *testing.T) {
+	now := mustParseTime(t, "2020-05-01T08:02:16-07:00")
+
+	testCases := []struct {
+		name               string
+		shortLinkTableRows []shortLinkTableRow
+		visitTableRows     []visitTableRow
+		alias              string
+		expectedCounts     map[entity.UserAgent]int
+	}{
+		{
+			name: "no visits",
+			shortLinkTableRows: []shortLinkTableRow{
+				{
+					alias:    "220uFicCJj",
+					longLink: "https://www.google.com",
+				},
+			},
+			visitTableRows: []visitTableRow{},
+			alias:          "220uFicCJj",
+			expectedCounts: map[entity.UserAgent]int{},
+		},
+		{
+			name: "visits from several user agents",
+			shortLinkTableRows: []shortLinkTableRow{
+				{
+					alias:    "220uFicCJj",
+					longLink: "https://www.google.com",
+				},
+				{
+					alias:    "yDOBcj5HIPbUAsw",
+					longLink: "https://github.com",
+				},
+			},
+			visitTableRows: []visitTableRow{
+				{
+					alias:       "220uFicCJj",
+					browser:     "Chrome",
+					os:          "Android",
+					deviceClass: "mobile",
+					visitedAt:   now,
+				},
+				{
+					alias:       "220uFicCJj",
+					browser:     "Chrome",
+					os:          "Android",
+					deviceClass: "mobile",
+					visitedAt:   now,
+				},
+				{
+					alias:     "220uFicCJj",
+					visitedAt: now,
+				},
+				{
+					alias:       "yDOBcj5HIPbUAsw",
+					browser:     "Safari",
+					os:          "iOS",
+					deviceClass: "tablet",
+					visitedAt:   now,
+				},
+			},
+			alias: "220uFicCJj",
+			expectedCounts: map[entity.UserAgent]int{
+				{Browser: "Chrome", OS: "Android", DeviceClass: "mobile"}: 2,
+				{}: 1,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			dbtest.AccessTestDB(
+				dbConnector,
+				dbMigrationTool,
+				dbMigrationRoot,
+				dbConfig,
+				func(sqlDB *sql.DB) {
+					insertShortLinkTableRows(t, sqlDB, testCase.shortLinkTableRows)
+					insertVisitTableRows(t, sqlDB, testCase.visitTableRows)
+
+					visitRepo := sqldb.NewVisitSQL(sqlDB)
+					counts, err := visitRepo.CountVisitsByUserAgent(context.Background(), testCase.alias)
+					assert.Equal(t, nil, err)
+					assert.Equal(t, testCase.expectedCounts, counts)
+				})
+		})
+	}
+}
+
+func TestVisitSQL_CountVisitsByCampaign(t *testing.T) {
+	now := mustParseTime(t, "2020-05-01T08:02:16-07:00")
+
+	testCases := []struct {
+		name               string
+		shortLinkTableRows []shortLinkTableRow
+		visitTableRows     []visitTableRow
+		alias              string
+		expectedCounts     map[entity.Campaign]int
+	}{
+		{
+			name: "no visits",
+			shortLinkTableRows: []shortLinkTableRow{
+				{
+					alias:    "220uFicCJj",
+					longLink: "https://www.google.com",
+				},
+			},
+			visitTableRows: []visitTableRow{},
+			alias:          "220uFicCJj",
+			expectedCounts: map[entity.Campaign]int{},
+		},
+		{
+			name: "visits from several campaigns",
+			shortLinkTableRows: []shortLinkTableRow{
+				{
+					alias:    "220uFicCJj",
+					longLink: "https://www.google.com",
+				},
+				{
+					alias:    "yDOBcj5HIPbUAsw",
+					longLink: "https://github.com",
+				},
+			},
+			visitTableRows: []visitTableRow{
+				{
+					alias:       "220uFicCJj",
+					utmSource:   "newsletter",
+					utmMedium:   "email",
+					utmCampaign: "spring_sale",
+					visitedAt:   now,
+				},
+				{
+					alias:       "220uFicCJj",
+					utmSource:   "newsletter",
+					utmMedium:   "email",
+					utmCampaign: "spring_sale",
+					visitedAt:   now,
+				},
+				{
+					alias:     "220uFicCJj",
+					utmSource: "twitter",
+					visitedAt: now,
+				},
+				{
+					alias:     "220uFicCJj",
+					visitedAt: now,
+				},
+				{
+					alias:       "yDOBcj5HIPbUAsw",
+					utmCampaign: "launch",
+					visitedAt:   now,
+				},
+			},
+			alias: "220uFicCJj",
+			expectedCounts: map[entity.Campaign]int{
+				{Source: "newsletter", Medium: "email", Name: "spring_sale"}: 2,
+				{Source: "twitter"}: 1,
+				{}:                  1,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			dbtest.AccessTestDB(
+				dbConnector,
+				dbMigrationTool,
+				dbMigrationRoot,
+				dbConfig,
+				func(sqlDB *sql.DB) {
+					insertShortLinkTableRows(t, sqlDB, testCase.shortLinkTableRows)
+					insertVisitTableRows(t, sqlDB, testCase.visitTableRows)
+
+					visitRepo := sqldb.NewVisitSQL(sqlDB)
+					counts, err := visitRepo.CountVisitsByCampaign(context.Background(), testCase.alias)
+					assert.Equal(t, nil, err)
+					assert.Equal(t, testCase.expectedCounts, counts)
+				})
+		})
+	}
+}
+
+func TestVisitSQL_CountVisitsSince(t *testing.T) {
+	now := mustParseTime(t, "2020-05-01T08:02:16-07:00")
+	since := now.Add(-24 * time.Hour)
+
+	testCases := []struct {
+		name               string
+		shortLinkTableRows []shortLinkTableRow
+		visitTableRows     []visitTableRow
+		expectedCount      int
+	}{
+		{
+			name:          "no visits",
+			expectedCount: 0,
+		},
+		{
+			name: "visits before and after since",
+			shortLinkTableRows: []shortLinkTableRow{
+				{
+					alias:    "220uFicCJj",
+					longLink: "https://www.google.com",
+				},
+				{
+					alias:    "yDOBcj5HIPbUAsw",
+					longLink: "https://github.com",
+				},
+			},
+			visitTableRows: []visitTableRow{
+				{
+					alias:     "220uFicCJj",
+					visitedAt: since.Add(-time.Second),
+				},
+				{
+					alias:     "220uFicCJj",
+					visitedAt: since,
+				},
+				{
+					alias:     "yDOBcj5HIPbUAsw",
+					visitedAt: now,
+				},
+			},
+			expectedCount: 2,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			dbtest.AccessTestDB(
+				dbConnector,
+				dbMigrationTool,
+				dbMigrationRoot,
+				dbConfig,
+				func(sqlDB *sql.DB) {
+					insertShortLinkTableRows(t, sqlDB, testCase.shortLinkTableRows)
+					insertVisitTableRows(t, sqlDB, testCase.visitTableRows)
+
+					visitRepo := sqldb.NewVisitSQL(sqlDB)
+					count, err := visitRepo.CountVisitsSince(since)
+					assert.Equal(t, nil, err)
+					assert.Equal(t, testCase.expectedCount, count)
+				})
+		})
+	}
+}
+
+func insertVisitTableRows(t *testing.T, sqlDB *sql.DB, tableRows []visitTableRow) {
+	for _, tableRow := range tableRows {
+		_, err := sqlDB.Exec(
+			insertVisitRowSQL,
+			tableRow.alias,
+			tableRow.referrer,
+			tableRow.browser,
+			tableRow.os,
+			tableRow.deviceClass,
+			tableRow.utmSource,
+			tableRow.utmMedium,
+			tableRow.utmCampaign,
+			tableRow.visitedAt,
+		)
+		assert.Equal(t, nil, err)
+	}
+}
@@ -0,0 +1,72 @@
+package twitter
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/short-d/app/fw/webreq"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/sso"
+)
+
+const twitterAPI = "https://api.twitter.com/2/users/me"
+
+var _ sso.Account = (*Account)(nil)
+
+// Account accesses user's account data through Twitter API v2.
+type Account struct {
+	httpRequest webreq.HTTP
+}
+
+// GetSingleSignOnUser retrieves user's email and name from Twitter API. The
+// email is empty when the user hasn't confirmed it with Twitter or the app
+// isn't allowed to request it.
+func (a Account) GetSingleSignOnUser(accessToken string, session sso.Session) (entity.SSOUser, error) {
+	type response struct {
+		Data struct {
+			ID             string `json:"id"`
+			Name           string `json:"name"`
+			ConfirmedEmail string `json:"confirmed_email"`
+		} `json:"data"`
+	}
+
+	var twitterResponse response
+
+	u, err := url.Parse(twitterAPI)
+	if err != nil {
+		return entity.SSOUser{}, err
+	}
+
+	query := u.Query()
+	query.Set("user.fields", "confirmed_email")
+	u.RawQuery = query.Encode()
+
+	headers := map[string]string{
+		"Authorization": fmt.Sprintf("Bearer %s", accessToken),
+	}
+
+	err = a.httpRequest.JSON(http.MethodGet, u.String(), headers, "", &twitterResponse)
+	if err != nil {
+		return entity.SSOUser{}, err
+	}
+
+	if twitterResponse.Data.ID == "" {
+		return entity.SSOUser{}, errors.New("user ID can't be empty")
+	}
+
+	return entity.SSOUser{
+		ID:            twitterResponse.Data.ID,
+		Email:         twitterResponse.Data.ConfirmedEmail,
+		EmailVerified: twitterResponse.Data.ConfirmedEmail != "",
+		Name:          twitterResponse.Data.Name,
+	}, nil
+}
+
+// NewAccount initializes Twitter account API client.
+func NewAccount(httpRequest webreq.HTTP) Account {
+	return Account{
+		httpRequest: httpRequest,
+	}
+}
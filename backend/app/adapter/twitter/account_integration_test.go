@@ -0,0 +1,118 @@
+// +build integration all
+
+package twitter
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/webreq"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/sso"
+)
+
+func TestAccount_GetSingleSignOnUser(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name            string
+		httpResponse    *http.Response
+		httpErr         error
+		expectHasErr    bool
+		expectedSSOUser entity.SSOUser
+	}{
+		{
+			name:         "invalid access token",
+			httpResponse: nil,
+			httpErr:      errors.New("invalid access token"),
+			expectHasErr: true,
+		},
+		{
+			name: "user has id, email and name",
+			httpResponse: &http.Response{
+				StatusCode: http.StatusOK,
+				Body: ioutil.NopCloser(bytes.NewReader([]byte(`
+{
+	"data": {
+		"id": "2244994945",
+		"name": "Twitter User",
+		"username": "twitteruser",
+		"confirmed_email": "twitterUser@gmail.com"
+	}
+}
+`,
+				)))},
+			expectHasErr: false,
+			expectedSSOUser: entity.SSOUser{
+				ID:            "2244994945",
+				Name:          "Twitter User",
+				Email:         "twitterUser@gmail.com",
+				EmailVerified: true,
+			},
+		},
+		{
+			name: "user doesn't have email",
+			httpResponse: &http.Response{
+				StatusCode: http.StatusOK,
+				Body: ioutil.NopCloser(bytes.NewReader([]byte(`
+{
+	"data": {
+		"id": "2244994945",
+		"name": "Twitter User",
+		"username": "twitteruser"
+	}
+}
+`,
+				)))},
+			expectHasErr: false,
+			expectedSSOUser: entity.SSOUser{
+				ID:    "2244994945",
+				Name:  "Twitter User",
+				Email: "",
+			},
+		},
+		{
+			name: "user doesn't have id",
+			httpResponse: &http.Response{
+				StatusCode: http.StatusOK,
+				Body: ioutil.NopCloser(bytes.NewReader([]byte(`
+{
+	"errors": [{"message": "Unauthorized"}]
+}
+`,
+				)))},
+			expectHasErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			httpRequest := webreq.NewHTTPFake(
+				func(req *http.Request) (response *http.Response, e error) {
+					assert.Equal(t, "https", req.URL.Scheme)
+					assert.Equal(t, "api.twitter.com", req.URL.Host)
+					assert.Equal(t, "/2/users/me", req.URL.Path)
+					assert.Equal(t, "confirmed_email", req.URL.Query().Get("user.fields"))
+					assert.Equal(t, "Bearer access_token", req.Header.Get("Authorization"))
+					assert.Equal(t, "GET", req.Method)
+
+					return testCase.httpResponse, testCase.httpErr
+				})
+			twitterAccount := NewAccount(httpRequest)
+
+			gotSSOUser, err := twitterAccount.GetSingleSignOnUser("access_token", sso.Session{})
+
+			if testCase.expectHasErr {
+				assert.NotEqual(t, nil, err)
+				return
+			}
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedSSOUser, gotSSOUser)
+		})
+	}
+}
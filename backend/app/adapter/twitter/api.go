@@ -0,0 +1,15 @@
+package twitter
+
+// API represents Twitter API client.
+type API struct {
+	IdentityProvider IdentityProvider
+	Account          Account
+}
+
+// NewAPI creates Twitter API client.
+func NewAPI(identityProvider IdentityProvider, account Account) API {
+	return API{
+		IdentityProvider: identityProvider,
+		Account:          account,
+	}
+}
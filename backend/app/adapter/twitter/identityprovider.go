@@ -0,0 +1,102 @@
+package twitter
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/short-d/app/fw/webreq"
+	"github.com/short-d/short/backend/app/usecase/sso"
+)
+
+// More info here: https://developer.twitter.com/en/docs/authentication/oauth-2-0/authorization-code
+
+const (
+	twitterAuthorizationAPI = "https://twitter.com/i/oauth2/authorize"
+	twitterAccessTokenAPI   = "https://api.twitter.com/2/oauth2/token"
+	twitterScopes           = "users.read tweet.read users.email"
+	twitterResponseType     = "code"
+	twitterGrantType        = "authorization_code"
+
+	// Twitter requires PKCE even for confidential clients.
+	twitterCodeChallengeMethod = "S256"
+)
+
+var _ sso.IdentityProvider = (*IdentityProvider)(nil)
+
+// IdentityProvider represents Twitter OAuth service.
+type IdentityProvider struct {
+	clientID     string
+	clientSecret string
+	httpRequest  webreq.HTTP
+	redirectURI  string
+}
+
+// GetAuthorizationURL retrieves the URL of Twitter sign in page, challenging
+// the code verifier of the sign in session.
+func (i IdentityProvider) GetAuthorizationURL(session sso.Session) string {
+	u, err := url.Parse(twitterAuthorizationAPI)
+	if err != nil {
+		return ""
+	}
+
+	query := u.Query()
+	query.Set("client_id", i.clientID)
+	query.Set("redirect_uri", i.redirectURI)
+	query.Set("scope", twitterScopes)
+	query.Set("response_type", twitterResponseType)
+	query.Set("state", session.State)
+	query.Set("code_challenge", session.CodeChallenge())
+	query.Set("code_challenge_method", twitterCodeChallengeMethod)
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
+
+// RequestAccessToken retrieves access token of user's Twitter account using
+// authorization code, which is redeemed with the code verifier of the sign in
+// session.
+func (i IdentityProvider) RequestAccessToken(authorizationCode string, session sso.Session) (accessToken string, err error) {
+	type twitterAccessTokenResponse struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+		ExpiresIn   int    `json:"expires_in"`
+		Scope       string `json:"scope"`
+	}
+
+	body := url.Values{}
+	body.Set("code", authorizationCode)
+	body.Set("grant_type", twitterGrantType)
+	body.Set("redirect_uri", i.redirectURI)
+	body.Set("code_verifier", session.CodeVerifier)
+
+	credentials := fmt.Sprintf("%s:%s", i.clientID, i.clientSecret)
+	headers := map[string]string{
+		"Authorization": fmt.Sprintf("Basic %s", base64.StdEncoding.EncodeToString([]byte(credentials))),
+		"Content-Type":  "application/x-www-form-urlencoded",
+	}
+
+	apiRes := twitterAccessTokenResponse{}
+	err = i.httpRequest.JSON(http.MethodPost, twitterAccessTokenAPI, headers, body.Encode(), &apiRes)
+	if err != nil {
+		return "", err
+	}
+
+	return apiRes.AccessToken, nil
+}
+
+// NewIdentityProvider initializes Twitter OAuth service.
+func NewIdentityProvider(
+	httpRequest webreq.HTTP,
+	clientID string,
+	clientSecret string,
+	redirectURI string,
+) IdentityProvider {
+	return IdentityProvider{
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		httpRequest:  httpRequest,
+		redirectURI:  redirectURI,
+	}
+}
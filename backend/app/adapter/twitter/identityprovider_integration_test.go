@@ -0,0 +1,143 @@
+// +build integration all
+
+package twitter
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/webreq"
+	"github.com/short-d/short/backend/app/usecase/sso"
+)
+
+func TestIdentityProvider_GetAuthorizationURL(t *testing.T) {
+	t.Parallel()
+	httpRequest := webreq.NewHTTPFake(
+		func(req *http.Request) (response *http.Response, e error) {
+			return nil, nil
+		})
+	clientID := "id_12345"
+	clientSecret := "client_secret"
+	redirectURI := "http://localhost/oauth/twitter/sign-in/callback"
+	identityProvider := NewIdentityProvider(httpRequest, clientID, clientSecret, redirectURI)
+
+	session := sso.Session{State: "state_12345", CodeVerifier: "verifier_12345"}
+	urlResponse := identityProvider.GetAuthorizationURL(session)
+
+	parsedURL, err := url.Parse(urlResponse)
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "https", parsedURL.Scheme)
+	assert.Equal(t, "twitter.com", parsedURL.Host)
+	assert.Equal(t, "/i/oauth2/authorize", parsedURL.Path)
+	assert.Equal(t, "code", parsedURL.Query().Get("response_type"))
+	assert.Equal(t, clientID, parsedURL.Query().Get("client_id"))
+	assert.Equal(t, redirectURI, parsedURL.Query().Get("redirect_uri"))
+	assert.Equal(t, session.State, parsedURL.Query().Get("state"))
+	assert.Equal(t, session.CodeChallenge(), parsedURL.Query().Get("code_challenge"))
+	assert.Equal(t, "S256", parsedURL.Query().Get("code_challenge_method"))
+
+	expectedScope := []string{"users.read", "tweet.read", "users.email"}
+	actualScope := strings.Split(parsedURL.Query().Get("scope"), " ")
+	assert.SameElements(t, expectedScope, actualScope)
+}
+
+func TestIdentityProvider_RequestAccessToken(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name                string
+		httpResponse        *http.Response
+		httpErr             error
+		authorizationCode   string
+		expectHasErr        bool
+		expectedAccessToken string
+	}{
+		{
+			name:              "invalid authorization code",
+			httpResponse:      nil,
+			httpErr:           errors.New("invalid authorization code"),
+			authorizationCode: "invalidCode",
+			expectHasErr:      true,
+		},
+		{
+			name: "authorization code rejected",
+			httpResponse: &http.Response{
+				Status:     "400 Bad Request",
+				StatusCode: http.StatusBadRequest,
+				Body: ioutil.NopCloser(bytes.NewReader([]byte(`
+{
+	"error": "invalid_request",
+	"error_description": "Value passed for the authorization code was invalid."
+}
+`,
+				)))},
+			authorizationCode: "authorizationCode_1",
+			expectHasErr:      true,
+		},
+		{
+			name: "success",
+			httpResponse: &http.Response{
+				StatusCode: http.StatusOK,
+				Body: ioutil.NopCloser(bytes.NewReader([]byte(`
+{
+	"token_type": "bearer",
+	"expires_in": 7200,
+	"access_token": "bcBi3AMeOV3Zg3AlOPyn",
+	"scope": "users.read tweet.read users.email"
+}
+`,
+				)))},
+			authorizationCode:   "authorizationCode_1",
+			expectHasErr:        false,
+			expectedAccessToken: "bcBi3AMeOV3Zg3AlOPyn",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			clientID := "id_12345"
+			clientSecret := "client_secret"
+			redirectURI := "http://localhost/oauth/twitter/sign-in/callback"
+			httpRequest := webreq.NewHTTPFake(
+				func(req *http.Request) (response *http.Response, e error) {
+					assert.Equal(t, "https", req.URL.Scheme)
+					assert.Equal(t, "api.twitter.com", req.URL.Host)
+					assert.Equal(t, "/2/oauth2/token", req.URL.Path)
+					assert.Equal(t, "POST", req.Method)
+					assert.Equal(t, "application/json", req.Header.Get("Accept"))
+					assert.Equal(t, "application/x-www-form-urlencoded", req.Header.Get("Content-Type"))
+
+					credentials := base64.StdEncoding.EncodeToString([]byte("id_12345:client_secret"))
+					assert.Equal(t, "Basic "+credentials, req.Header.Get("Authorization"))
+
+					err := req.ParseForm()
+					assert.Equal(t, nil, err)
+					assert.Equal(t, testCase.authorizationCode, req.PostForm.Get("code"))
+					assert.Equal(t, "authorization_code", req.PostForm.Get("grant_type"))
+					assert.Equal(t, redirectURI, req.PostForm.Get("redirect_uri"))
+					assert.Equal(t, "verifier_12345", req.PostForm.Get("code_verifier"))
+
+					return testCase.httpResponse, testCase.httpErr
+				})
+			identityProvider := NewIdentityProvider(httpRequest, clientID, clientSecret, redirectURI)
+			actualAccessToken, err := identityProvider.RequestAccessToken(testCase.authorizationCode, sso.Session{CodeVerifier: "verifier_12345"})
+
+			if testCase.expectHasErr {
+				assert.NotEqual(t, nil, err)
+				return
+			}
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedAccessToken, actualAccessToken)
+		})
+	}
+}
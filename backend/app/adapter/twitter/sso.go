@@ -0,0 +1,9 @@
+package twitter
+
+import "github.com/short-d/short/backend/app/usecase/sso"
+
+// AccountLinker links user's Twitter account with Short account.
+type AccountLinker sso.AccountLinker
+
+// SingleSignOn enables users to sign in through their Twitter account.
+type SingleSignOn sso.SingleSignOn
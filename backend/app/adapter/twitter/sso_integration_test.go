@@ -0,0 +1,144 @@
+// +build integration all
+
+package twitter
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/crypto"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/app/fw/webreq"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/authenticator"
+	"github.com/short-d/short/backend/app/usecase/email"
+	"github.com/short-d/short/backend/app/usecase/keygen"
+	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/sso"
+	"github.com/short-d/short/backend/app/usecase/verification"
+)
+
+func TestSingleSignOn_SignIn(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		twitterUser   string
+		users         []entity.User
+		availableKeys []keygen.Key
+		expectedUser  entity.User
+	}{
+		{
+			name: "account with same email found",
+			twitterUser: `
+{
+	"data": {
+		"id": "2244994945",
+		"name": "Alpha",
+		"confirmed_email": "alpha@example.com"
+	}
+}
+`,
+			users: []entity.User{
+				{
+					ID:            "alpha",
+					Email:         "alpha@example.com",
+					EmailVerified: true,
+				},
+			},
+			expectedUser: entity.User{
+				ID:            "alpha",
+				Email:         "alpha@example.com",
+				EmailVerified: true,
+			},
+		},
+		{
+			name: "twitter does not return email",
+			twitterUser: `
+{
+	"data": {
+		"id": "2244994945",
+		"name": "Alpha"
+	}
+}
+`,
+			users: []entity.User{
+				{
+					ID:            "alpha",
+					Email:         "alpha@example.com",
+					EmailVerified: true,
+				},
+			},
+			availableKeys: []keygen.Key{"beta"},
+			expectedUser: entity.User{
+				ID:   "beta",
+				Name: "Alpha",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			httpRequest := webreq.NewHTTPFake(
+				func(req *http.Request) (response *http.Response, e error) {
+					body := testCase.twitterUser
+					if req.URL.Path == "/2/oauth2/token" {
+						body = `{"token_type": "bearer", "access_token": "bcBi3AMeOV3Zg3AlOPyn"}`
+					}
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body:       ioutil.NopCloser(bytes.NewReader([]byte(body))),
+					}, nil
+				})
+			identityProvider := NewIdentityProvider(httpRequest, "id_12345", "client_secret", "http://localhost/oauth/twitter/sign-in/callback")
+			account := NewAccount(httpRequest)
+
+			now := time.Now()
+			auth := authenticator.NewAuthenticatorFake(now, time.Minute)
+
+			keyFetcher := keygen.NewKeyFetcherFake(testCase.availableKeys)
+			keyGen, err := keygen.NewRemote(1, &keyFetcher)
+			assert.Equal(t, nil, err)
+
+			userRepo := repository.NewUserFake(testCase.users)
+			emailVerifier := verification.NewEmailVerifier(
+				crypto.NewTokenizerFake(),
+				timer.NewStub(now),
+				&userRepo,
+				email.NewSenderFake(nil),
+				url.URL{Scheme: "https", Host: "short-d.com"},
+				24*time.Hour,
+			)
+			ssoMap, err := repository.NewsSSOMapFake([]string{}, []string{})
+			assert.Equal(t, nil, err)
+
+			linker := sso.NewAccountLinkerFactory(keyGen, &userRepo, emailVerifier).NewAccountLinker(&ssoMap)
+			singleSignOn := sso.NewFactory(auth).NewSingleSignOn(identityProvider, account, linker)
+
+			authToken, err := singleSignOn.SignIn("authorizationCode_1", "state_12345", sso.Session{State: "state_12345"})
+			assert.Equal(t, nil, err)
+
+			payload := map[string]string{}
+			err = json.Unmarshal([]byte(authToken), &payload)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedUser.ID, payload["id"])
+
+			user, err := userRepo.GetUserByID(testCase.expectedUser.ID)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedUser, user)
+
+			shortUserID, err := ssoMap.GetShortUserID("2244994945")
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedUser.ID, shortUserID)
+		})
+	}
+}
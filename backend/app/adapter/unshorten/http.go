@@ -0,0 +1,79 @@
+package unshorten
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/short-d/short/backend/app/usecase/shortlink"
+)
+
+var _ shortlink.RedirectFollower = (*HTTP)(nil)
+
+// HTTP finds where short links of other URL shorteners redirect to through
+// HTTP requests, one redirect at a time.
+type HTTP struct {
+	client http.Client
+}
+
+// NextHop issues a HEAD request to the URL, falling back to GET when HEAD is
+// not supported. The redirect is never followed and the response body is
+// never read. Relative locations are resolved against the URL.
+func (h HTTP) NextHop(rawURL string) (string, error) {
+	res, err := h.request(http.MethodHead, rawURL)
+	if err != nil {
+		return "", err
+	}
+	if res.StatusCode == http.StatusMethodNotAllowed || res.StatusCode == http.StatusNotImplemented {
+		res, err = h.request(http.MethodGet, rawURL)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if !isRedirect(res.StatusCode) {
+		return "", nil
+	}
+	location, err := res.Location()
+	if err == http.ErrNoLocation {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return location.String(), nil
+}
+
+func (h HTTP) request(method string, rawURL string) (*http.Response, error) {
+	req, err := http.NewRequest(method, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := h.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	res.Body.Close()
+	return res, nil
+}
+
+func isRedirect(statusCode int) bool {
+	switch statusCode {
+	case http.StatusMovedPermanently,
+		http.StatusFound,
+		http.StatusSeeOther,
+		http.StatusTemporaryRedirect,
+		http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+// NewHTTP creates HTTP on top of client which gives up on a URL after timeout.
+func NewHTTP(client http.Client, timeout time.Duration) HTTP {
+	client.Timeout = timeout
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	return HTTP{client: client}
+}
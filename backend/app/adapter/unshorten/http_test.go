@@ -0,0 +1,78 @@
+// +build !integration all
+
+package unshorten
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+)
+
+func TestHTTP_NextHop(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name             string
+		path             string
+		expectedLocation string
+	}{
+		{
+			name:             "absolute redirect",
+			path:             "/abc",
+			expectedLocation: "https://www.example.com/page",
+		},
+		{
+			name:             "relative redirect",
+			path:             "/relative",
+			expectedLocation: "/next",
+		},
+		{
+			name:             "HEAD not allowed",
+			path:             "/get-only",
+			expectedLocation: "https://www.example.com/get",
+		},
+		{
+			name:             "not redirect",
+			path:             "/missing",
+			expectedLocation: "",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/abc":
+					http.Redirect(w, r, "https://www.example.com/page", http.StatusMovedPermanently)
+				case "/relative":
+					http.Redirect(w, r, "/next", http.StatusFound)
+				case "/get-only":
+					if r.Method != http.MethodGet {
+						w.WriteHeader(http.StatusMethodNotAllowed)
+						return
+					}
+					http.Redirect(w, r, "https://www.example.com/get", http.StatusTemporaryRedirect)
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			expectedLocation := testCase.expectedLocation
+			if expectedLocation == "/next" {
+				expectedLocation = server.URL + "/next"
+			}
+
+			follower := NewHTTP(http.Client{}, time.Second)
+			location, err := follower.NextHop(server.URL + testCase.path)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, expectedLocation, location)
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package useragent
+
+import (
+	"strings"
+
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/visit"
+)
+
+var _ visit.UserAgentParser = (*Parser)(nil)
+
+type rule struct {
+	name   string
+	tokens []string
+}
+
+var botTokens = []string{
+	"bot", "crawl", "spider", "slurp", "curl/", "wget/",
+	"python-requests", "go-http-client", "facebookexternalhit",
+}
+
+// browserRules are ordered from the most specific to the least specific
+// because most browsers also advertise the engines they are compatible with.
+var browserRules = []rule{
+	{name: "Edge", tokens: []string{"Edg/", "EdgA/", "EdgiOS/", "Edge/"}},
+	{name: "Opera", tokens: []string{"OPR/", "Opera"}},
+	{name: "Samsung Internet", tokens: []string{"SamsungBrowser/"}},
+	{name: "Firefox", tokens: []string{"Firefox/", "FxiOS/"}},
+	{name: "Chrome", tokens: []string{"Chrome/", "CriOS/"}},
+	{name: "Safari", tokens: []string{"Safari/"}},
+	{name: "Internet Explorer", tokens: []string{"MSIE ", "Trident/"}},
+}
+
+// osRules are ordered so that iOS is matched before macOS since iOS user
+// agents contain "like Mac OS X".
+var osRules = []rule{
+	{name: "Windows", tokens: []string{"Windows"}},
+	{name: "iOS", tokens: []string{"iPhone", "iPad", "iPod"}},
+	{name: "Android", tokens: []string{"Android"}},
+	{name: "Chrome OS", tokens: []string{"CrOS"}},
+	{name: "macOS", tokens: []string{"Macintosh", "Mac OS X"}},
+	{name: "Linux", tokens: []string{"Linux"}},
+}
+
+// Parser classifies User-Agent headers with well known product tokens. Parts
+// which cannot be recognized are left empty.
+type Parser struct{}
+
+// Parse extracts the browser, operating system and device class from the
+// User-Agent header.
+func (p Parser) Parse(userAgent string) entity.UserAgent {
+	userAgent = strings.TrimSpace(userAgent)
+	if userAgent == "" {
+		return entity.UserAgent{}
+	}
+
+	if isBot(userAgent) {
+		return entity.UserAgent{DeviceClass: visit.DeviceClassBot}
+	}
+
+	os := match(userAgent, osRules)
+	return entity.UserAgent{
+		Browser:     match(userAgent, browserRules),
+		OS:          os,
+		DeviceClass: getDeviceClass(userAgent, os),
+	}
+}
+
+func isBot(userAgent string) bool {
+	userAgent = strings.ToLower(userAgent)
+	for _, token := range botTokens {
+		if strings.Contains(userAgent, token) {
+			return true
+		}
+	}
+	return false
+}
+
+func match(userAgent string, rules []rule) string {
+	for _, rule := range rules {
+		for _, token := range rule.tokens {
+			if strings.Contains(userAgent, token) {
+				return rule.name
+			}
+		}
+	}
+	return ""
+}
+
+func getDeviceClass(userAgent string, os string) string {
+	switch {
+	case strings.Contains(userAgent, "iPad"),
+		strings.Contains(userAgent, "Tablet"),
+		os == "Android" && !strings.Contains(userAgent, "Mobile"):
+		return visit.DeviceClassTablet
+	case strings.Contains(userAgent, "Mobi"),
+		strings.Contains(userAgent, "iPhone"),
+		strings.Contains(userAgent, "iPod"),
+		strings.Contains(userAgent, "Windows Phone"):
+		return visit.DeviceClassMobile
+	case os != "":
+		return visit.DeviceClassDesktop
+	}
+	return ""
+}
+
+// NewParser creates User-Agent parser.
+func NewParser() Parser {
+	return Parser{}
+}
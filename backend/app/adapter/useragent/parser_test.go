@@ -0,0 +1,127 @@
+// +build !integration all
+
+package useragent
+
+import (
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/short/backend/app/entity"
+)
+
+func TestParser_Parse(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name              string
+		userAgent         string
+		expectedUserAgent entity.UserAgent
+	}{
+		{
+			name:              "empty user agent",
+			userAgent:         "",
+			expectedUserAgent: entity.UserAgent{},
+		},
+		{
+			name:      "Chrome on Windows",
+			userAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/86.0.4240.75 Safari/537.36",
+			expectedUserAgent: entity.UserAgent{
+				Browser:     "Chrome",
+				OS:          "Windows",
+				DeviceClass: "desktop",
+			},
+		},
+		{
+			name:      "Edge on Windows",
+			userAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/86.0.4240.75 Safari/537.36 Edg/86.0.622.38",
+			expectedUserAgent: entity.UserAgent{
+				Browser:     "Edge",
+				OS:          "Windows",
+				DeviceClass: "desktop",
+			},
+		},
+		{
+			name:      "Safari on macOS",
+			userAgent: "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_6) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/14.0 Safari/605.1.15",
+			expectedUserAgent: entity.UserAgent{
+				Browser:     "Safari",
+				OS:          "macOS",
+				DeviceClass: "desktop",
+			},
+		},
+		{
+			name:      "Firefox on Linux",
+			userAgent: "Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:81.0) Gecko/20100101 Firefox/81.0",
+			expectedUserAgent: entity.UserAgent{
+				Browser:     "Firefox",
+				OS:          "Linux",
+				DeviceClass: "desktop",
+			},
+		},
+		{
+			name:      "Safari on iPhone",
+			userAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 14_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/14.0 Mobile/15E148 Safari/604.1",
+			expectedUserAgent: entity.UserAgent{
+				Browser:     "Safari",
+				OS:          "iOS",
+				DeviceClass: "mobile",
+			},
+		},
+		{
+			name:      "Chrome on iPad",
+			userAgent: "Mozilla/5.0 (iPad; CPU OS 14_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) CriOS/86.0.4240.93 Mobile/15E148 Safari/604.1",
+			expectedUserAgent: entity.UserAgent{
+				Browser:     "Chrome",
+				OS:          "iOS",
+				DeviceClass: "tablet",
+			},
+		},
+		{
+			name:      "Samsung Internet on Android phone",
+			userAgent: "Mozilla/5.0 (Linux; Android 10; SM-G975F) AppleWebKit/537.36 (KHTML, like Gecko) SamsungBrowser/12.1 Chrome/79.0.3945.136 Mobile Safari/537.36",
+			expectedUserAgent: entity.UserAgent{
+				Browser:     "Samsung Internet",
+				OS:          "Android",
+				DeviceClass: "mobile",
+			},
+		},
+		{
+			name:      "Chrome on Android tablet",
+			userAgent: "Mozilla/5.0 (Linux; Android 10; SM-T860) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/86.0.4240.99 Safari/537.36",
+			expectedUserAgent: entity.UserAgent{
+				Browser:     "Chrome",
+				OS:          "Android",
+				DeviceClass: "tablet",
+			},
+		},
+		{
+			name:      "search engine crawler",
+			userAgent: "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+			expectedUserAgent: entity.UserAgent{
+				DeviceClass: "bot",
+			},
+		},
+		{
+			name:      "command line client",
+			userAgent: "curl/7.64.1",
+			expectedUserAgent: entity.UserAgent{
+				DeviceClass: "bot",
+			},
+		},
+		{
+			name:              "unrecognized user agent",
+			userAgent:         "SomeApp/1.0",
+			expectedUserAgent: entity.UserAgent{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			parser := NewParser()
+			assert.Equal(t, testCase.expectedUserAgent, parser.Parse(testCase.userAgent))
+		})
+	}
+}
@@ -1,12 +1,17 @@
 package app
 
 import (
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/short-d/app/fw/db"
 	"github.com/short-d/app/fw/env"
-	"github.com/short-d/app/fw/logger"
 	"github.com/short-d/app/fw/security"
+	"github.com/short-d/app/fw/service"
+	"github.com/short-d/short/backend/app/usecase/maintenance"
+	"github.com/short-d/short/backend/app/usecase/route"
 	"github.com/short-d/short/backend/dep"
 	"github.com/short-d/short/backend/dep/provider"
 )
@@ -15,8 +20,12 @@ import (
 type ServiceConfig struct {
 	Runtime              string
 	LogPrefix            string
-	LogLevel             logger.LogLevel
+	LogLevel             string
 	MigrationRoot        string
+	DBMaxOpenConns       int
+	DBMaxIdleConns       int
+	DBConnMaxLifetime    time.Duration
+	DBSlowQueryThreshold time.Duration
 	RecaptchaSecret      string
 	GithubClientID       string
 	GithubClientSecret   string
@@ -26,8 +35,15 @@ type ServiceConfig struct {
 	GoogleClientID       string
 	GoogleClientSecret   string
 	GoogleRedirectURI    string
+	TwitterClientID      string
+	TwitterClientSecret  string
+	TwitterRedirectURI   string
+	AppleClientID        string
+	AppleRedirectURI     string
 	JwtSecret            string
 	WebFrontendURL       string
+	ShortLinkBaseURL     string
+	LongLinkFragment     string
 	GraphQLAPIPort       int
 	HTTPAPIPort          int
 	GRPCAPIPort          int
@@ -35,8 +51,14 @@ type ServiceConfig struct {
 	CertFilePath         string
 	KeyFilePath          string
 	KeyGenBufferSize     int
+	KeyGenBatchMinSize   int
+	KeyGenBatchMaxSize   int
 	KgsHostname          string
 	KgsPort              int
+	KgsMaxAttempts       int
+	KgsInitialBackoff    time.Duration
+	KgsMaxBackoff        time.Duration
+	KgsDialTimeout       time.Duration
 	AuthTokenLifetime    time.Duration
 	SearchTimeout        time.Duration
 	SwaggerUIDir         string
@@ -47,6 +69,94 @@ type ServiceConfig struct {
 	SegmentAPIKey        string
 	IPStackAPIKey        string
 	GoogleAPIKey         string
+	VisitorIPMode        string
+	VisitorReferrer      bool
+	VisitorUserAgent     bool
+	VisitorCampaign      bool
+	LinkHealthInterval   time.Duration
+	LinkHealthBatchSize  int
+	LinkHealthThreshold  int
+	LinkHealthTimeout    time.Duration
+	FeatureFlagConfig    string
+	RiskBlockThreshold   int
+	RiskWarnThreshold    int
+	RiskBreakerThreshold int
+	RiskBreakerCooldown  time.Duration
+	AllowedDomains       []string
+	KeyGenStrategy       string
+	HashidsSalt          string
+	KeyGenWordListPath   string
+	KeyGenWordCount      int
+	KeyGenWordSeparator  string
+	DomainDenylistPath   string
+	RedirectRateLimit    int
+	RedirectRateWindow   time.Duration
+	LinkRateWindow       time.Duration
+	CORSAllowedOrigins   []string
+	CORSAllowedMethods   []string
+	CORSAllowedHeaders   []string
+	CORSCredentials      bool
+	ContentSecPolicy     string
+	ReferrerPolicy       string
+	RedirectReferrer     string
+	ShortLinkChecks      []string
+	ShortLinkDomains     []string
+	MaxRequestBodySize   int
+	RedirectLogSampling  int
+	NotFoundPage         string
+	ExpiredPage          string
+	URLValidationWorkers int
+	BrandProductName     string
+	BrandLogoURL         string
+	BrandPrimaryColor    string
+	TrustedProxies       []string
+	GuestAttribution     bool
+	GuestCreateCooldown  time.Duration
+	LongLinkUniqueness   string
+	AliasCategories      []string
+	CustomAliasQuota     int
+	AutoAliasQuota       int
+	SMTPHost             string
+	SMTPPort             int
+	SMTPUsername         string
+	SMTPPassword         string
+	SMTPFrom             string
+	WebhookURL           string
+	SignInRateLimit      int
+	SignInRateWindow     time.Duration
+	ResetRateLimit       int
+	ResetRateWindow      time.Duration
+	PasswordMinLength    int
+	PasswordCharClasses  []string
+	ReadOnly             bool
+	ProfilingEnabled     bool
+	AliasRetryBudget     int
+	RelationMaxAttempts  int
+	RelationBackoff      time.Duration
+	AliasReservationTTL  time.Duration
+	AliasReservationMax  time.Duration
+	ChainedLinkMode      string
+	ShortenerDomains     []string
+	ChainedMaxRedirects  int
+	ShareURLSecret       string
+	VisitFlushInterval   time.Duration
+	VisitBufferSize      int
+	AliasPrefix          string
+	LongLinkPlainHTTP    string
+	DefaultExpireAfter   time.Duration
+	RoleExpireAfter      []string
+	ExpireGracePeriod    time.Duration
+	ExpiredInterstitial  bool
+	PersistedQueryLimit  int
+	AdminAllowedIPs      []string
+	OutboundMaxCalls     int
+	OutboundWaitTimeout  time.Duration
+	QueryConflict        string
+	RedirectStatusCode   int
+	RedirectMaxAge       time.Duration
+	EditableLinks        bool
+	DomainRedirects      []string
+	TenantHosts          []string
 }
 
 // Start launches the GraphQL & HTTP APIs
@@ -61,39 +171,182 @@ func Start(
 		panic(err)
 	}
 
+	provider.ApplyDBPoolConfig(sqlDB, provider.DBPoolConfig{
+		MaxOpenConns:    config.DBMaxOpenConns,
+		MaxIdleConns:    config.DBMaxIdleConns,
+		ConnMaxLifetime: config.DBConnMaxLifetime,
+	})
+
 	err = dbMigrationTool.MigrateUp(sqlDB, config.MigrationRoot)
 	if err != nil {
 		panic(err)
 	}
 
+	slowQueryThreshold := provider.SlowQueryThreshold(config.DBSlowQueryThreshold)
 	kgsBufferSize := provider.KeyGenBufferSize(config.KeyGenBufferSize)
+	keyGenBatchBounds := provider.KeyGenBatchBounds{
+		MinSize: config.KeyGenBatchMinSize,
+		MaxSize: config.KeyGenBatchMaxSize,
+	}
 	kgsRPCConfig := provider.KgsRPCConfig{
 		Hostname: config.KgsHostname,
 		Port:     config.KgsPort,
 	}
+	kgsConnectRetry := provider.KgsConnectRetry{
+		MaxAttempts:    config.KgsMaxAttempts,
+		InitialBackoff: config.KgsInitialBackoff,
+		MaxBackoff:     config.KgsMaxBackoff,
+		DialTimeout:    config.KgsDialTimeout,
+	}
+
+	keyGenStrategy := provider.KeyGenStrategy(config.KeyGenStrategy)
+	hashidsSalt := provider.HashidsSalt(config.HashidsSalt)
+	keyGenWordsConfig := provider.KeyGenWordsConfig{
+		WordListPath: config.KeyGenWordListPath,
+		WordCount:    config.KeyGenWordCount,
+		Separator:    config.KeyGenWordSeparator,
+	}
 
 	dataDogAPIKey := provider.DataDogAPIKey(config.DataDogAPIKey)
 	segmentAPIKey := provider.SegmentAPIKey(config.SegmentAPIKey)
 	ipStackAPIKey := provider.IPStackAPIKey(config.IPStackAPIKey)
 	googleAPIKey := provider.GoogleAPIKey(config.GoogleAPIKey)
+	featureToggle, err := dep.InjectFeatureFlagToggle(provider.FeatureFlagConfigPath(config.FeatureFlagConfig))
+	if err != nil {
+		panic(err)
+	}
+
+	riskThresholds := provider.RiskThresholds{
+		Block: config.RiskBlockThreshold,
+		Warn:  config.RiskWarnThreshold,
+	}
+	riskBreakerConfig := provider.RiskCircuitBreakerConfig{
+		FailureThreshold: config.RiskBreakerThreshold,
+		Cooldown:         config.RiskBreakerCooldown,
+	}
+	allowedDomains := provider.LongLinkAllowedDomains(config.AllowedDomains)
+	domainDenylistPath := provider.DomainDenylistPath(config.DomainDenylistPath)
+	shortLinkChecks := provider.ShortLinkChecks(config.ShortLinkChecks)
+	longLinkUniqueness := provider.LongLinkUniqueness(config.LongLinkUniqueness)
+	aliasUnicodeCategories := provider.CustomAliasUnicodeCategories(config.AliasCategories)
+	aliasQuota := provider.AliasQuota{
+		CustomAlias: config.CustomAliasQuota,
+		AutoAlias:   config.AutoAliasQuota,
+	}
+	smtpConfig := provider.SMTPConfig{
+		Host:     config.SMTPHost,
+		Port:     config.SMTPPort,
+		Username: config.SMTPUsername,
+		Password: config.SMTPPassword,
+		From:     config.SMTPFrom,
+	}
+	webhookURL := provider.WebhookURL(config.WebhookURL)
+	shortLinkDomains := provider.ShortLinkDomains(config.ShortLinkDomains)
+	maxRequestBodySize := provider.MaxRequestBodySize(config.MaxRequestBodySize)
+	securityHeaderConfig := provider.SecurityHeaderConfig{
+		ContentSecurityPolicy:  config.ContentSecPolicy,
+		ReferrerPolicy:         config.ReferrerPolicy,
+		RedirectReferrerPolicy: config.RedirectReferrer,
+	}
+	redirectRateLimit := provider.RedirectRateLimit{
+		Limit:  config.RedirectRateLimit,
+		Window: config.RedirectRateWindow,
+	}
+	trustedProxies := provider.TrustedProxies(config.TrustedProxies)
+	shortLinkBaseURL := provider.ShortLinkBaseURL(config.ShortLinkBaseURL)
+	longLinkFragment := provider.LongLinkFragment(config.LongLinkFragment)
+	maintenanceMode := maintenance.NewMode(config.ReadOnly)
+	aliasRetryBudget := provider.AliasRetryBudget(config.AliasRetryBudget)
+	relationRetry := provider.RelationRetry{
+		MaxAttempts: config.RelationMaxAttempts,
+		Backoff:     config.RelationBackoff,
+	}
+	reservationTTL := provider.AliasReservationTTL{
+		Default: config.AliasReservationTTL,
+		Max:     config.AliasReservationMax,
+	}
+	chainedLinkConfig := provider.ChainedLinkConfig{
+		Mode:             config.ChainedLinkMode,
+		ShortenerDomains: config.ShortenerDomains,
+		MaxRedirects:     config.ChainedMaxRedirects,
+	}
+	shareURLSecret := provider.ShareURLSecret(config.ShareURLSecret)
+	aliasPrefix := provider.AliasPrefix(config.AliasPrefix)
+	longLinkPlainHTTP := provider.LongLinkPlainHTTP(config.LongLinkPlainHTTP)
+	shortLinkLifetime := provider.ShortLinkLifetime{
+		Lifetime:      config.DefaultExpireAfter,
+		RoleLifetimes: config.RoleExpireAfter,
+	}
+	expiryGrace := provider.ShortLinkExpiryGrace(config.ExpireGracePeriod)
+	outboundLimiter := provider.NewOutboundLimiter(provider.OutboundHTTPLimit{
+		MaxConcurrency: config.OutboundMaxCalls,
+		WaitTimeout:    config.OutboundWaitTimeout,
+	})
+	corsConfig := provider.CORSConfig{
+		AllowedOrigins:   config.CORSAllowedOrigins,
+		AllowedMethods:   config.CORSAllowedMethods,
+		AllowedHeaders:   config.CORSAllowedHeaders,
+		AllowCredentials: config.CORSCredentials,
+	}
 
 	graphqlAPI, err := dep.InjectGraphQLService(
 		env.Runtime(config.Runtime),
 		provider.LogPrefix(config.LogPrefix),
-		config.LogLevel,
+		provider.LogLevel(config.LogLevel),
 		sqlDB,
+		slowQueryThreshold,
 		provider.GraphQLSchemaPath(config.GraphQLSchemaPath),
-		"/graphql",
+		provider.GraphQLPath("/"+route.GraphQL),
 		provider.GraphiQLDefaultQuery(config.GraphiQLDefaultQuery),
 		provider.ReCaptchaSecret(config.RecaptchaSecret),
 		provider.JwtSecret(config.JwtSecret),
 		kgsBufferSize,
+		keyGenBatchBounds,
 		kgsRPCConfig,
+		kgsConnectRetry,
+		keyGenStrategy,
+		hashidsSalt,
+		keyGenWordsConfig,
+		provider.WebFrontendURL(config.WebFrontendURL),
 		provider.TokenValidDuration(config.AuthTokenLifetime),
 		dataDogAPIKey,
 		segmentAPIKey,
 		ipStackAPIKey,
 		googleAPIKey,
+		featureToggle,
+		riskThresholds,
+		allowedDomains,
+		domainDenylistPath,
+		corsConfig,
+		shortLinkChecks,
+		shortLinkDomains,
+		maxRequestBodySize,
+		provider.URLValidationWorkers(config.URLValidationWorkers),
+		longLinkUniqueness,
+		aliasUnicodeCategories,
+		riskBreakerConfig,
+		aliasQuota,
+		smtpConfig,
+		webhookURL,
+		redirectRateLimit,
+		trustedProxies,
+		securityHeaderConfig,
+		shortLinkBaseURL,
+		longLinkFragment,
+		maintenanceMode,
+		aliasRetryBudget,
+		relationRetry,
+		reservationTTL,
+		chainedLinkConfig,
+		shareURLSecret,
+		aliasPrefix,
+		longLinkPlainHTTP,
+		shortLinkLifetime,
+		expiryGrace,
+		provider.PersistedQueryLimit(config.PersistedQueryLimit),
+		outboundLimiter,
+		provider.TenantHosts(config.TenantHosts),
+		provider.AdminAllowedIPs(config.AdminAllowedIPs),
 	)
 	if err != nil {
 		panic(err)
@@ -104,8 +357,9 @@ func Start(
 	httpAPI, err := dep.InjectRoutingService(
 		env.Runtime(config.Runtime),
 		provider.LogPrefix(config.LogPrefix),
-		config.LogLevel,
+		provider.LogLevel(config.LogLevel),
 		sqlDB,
+		slowQueryThreshold,
 		provider.GithubClientID(config.GithubClientID),
 		provider.GithubClientSecret(config.GithubClientSecret),
 		provider.FacebookClientID(config.FacebookClientID),
@@ -114,9 +368,19 @@ func Start(
 		provider.GoogleClientID(config.GoogleClientID),
 		provider.GoogleClientSecret(config.GoogleClientSecret),
 		provider.GoogleRedirectURI(config.GoogleRedirectURI),
+		provider.TwitterClientID(config.TwitterClientID),
+		provider.TwitterClientSecret(config.TwitterClientSecret),
+		provider.TwitterRedirectURI(config.TwitterRedirectURI),
+		provider.AppleClientID(config.AppleClientID),
+		provider.AppleRedirectURI(config.AppleRedirectURI),
 		provider.JwtSecret(config.JwtSecret),
 		kgsBufferSize,
+		keyGenBatchBounds,
 		kgsRPCConfig,
+		kgsConnectRetry,
+		keyGenStrategy,
+		hashidsSalt,
+		keyGenWordsConfig,
 		provider.WebFrontendURL(config.WebFrontendURL),
 		provider.TokenValidDuration(config.AuthTokenLifetime),
 		provider.SearchTimeout(config.SearchTimeout),
@@ -125,6 +389,82 @@ func Start(
 		dataDogAPIKey,
 		segmentAPIKey,
 		ipStackAPIKey,
+		provider.VisitorIPMode(config.VisitorIPMode),
+		provider.VisitorDetails{
+			Referrer:  config.VisitorReferrer,
+			UserAgent: config.VisitorUserAgent,
+			Campaign:  config.VisitorCampaign,
+		},
+		redirectRateLimit,
+		googleAPIKey,
+		riskThresholds,
+		allowedDomains,
+		domainDenylistPath,
+		corsConfig,
+		shortLinkChecks,
+		shortLinkDomains,
+		maxRequestBodySize,
+		provider.ErrorPageConfig{
+			NotFound:     config.NotFoundPage,
+			Expired:      config.ExpiredPage,
+			Interstitial: config.ExpiredInterstitial,
+			ProductName:  config.BrandProductName,
+			LogoURL:      config.BrandLogoURL,
+			PrimaryColor: config.BrandPrimaryColor,
+		},
+		trustedProxies,
+		provider.GuestAttributionEnabled(config.GuestAttribution),
+		provider.GuestCreateCooldown(config.GuestCreateCooldown),
+		longLinkUniqueness,
+		aliasUnicodeCategories,
+		riskBreakerConfig,
+		aliasQuota,
+		smtpConfig,
+		webhookURL,
+		provider.SignInRateLimit{
+			Limit:  config.SignInRateLimit,
+			Window: config.SignInRateWindow,
+		},
+		provider.PasswordResetRateLimit{
+			Limit:  config.ResetRateLimit,
+			Window: config.ResetRateWindow,
+		},
+		provider.PasswordPolicyConfig{
+			MinLength:       config.PasswordMinLength,
+			RequiredClasses: config.PasswordCharClasses,
+		},
+		securityHeaderConfig,
+		shortLinkBaseURL,
+		longLinkFragment,
+		provider.RedirectLogSamplePercent(config.RedirectLogSampling),
+		maintenanceMode,
+		provider.ProfilingEnabled(config.ProfilingEnabled),
+		aliasRetryBudget,
+		relationRetry,
+		reservationTTL,
+		chainedLinkConfig,
+		shareURLSecret,
+		provider.VisitBufferConfig{
+			FlushInterval: config.VisitFlushInterval,
+			MaxVisits:     config.VisitBufferSize,
+			MaxAliases:    config.VisitBufferSize,
+		},
+		aliasPrefix,
+		longLinkPlainHTTP,
+		shortLinkLifetime,
+		expiryGrace,
+		provider.AdminAllowedIPs(config.AdminAllowedIPs),
+		outboundLimiter,
+		provider.QueryConflict(config.QueryConflict),
+		provider.RedirectConfig{
+			StatusCode:    config.RedirectStatusCode,
+			MaxAge:        config.RedirectMaxAge,
+			EditableLinks: config.EditableLinks,
+		},
+		provider.LinkRateLimitWindow(config.LinkRateWindow),
+		provider.DomainRedirects(config.DomainRedirects),
+		featureToggle,
+		provider.TenantHosts(config.TenantHosts),
 	)
 	if err != nil {
 		panic(err)
@@ -132,21 +472,60 @@ func Start(
 
 	httpAPI.StartAsync(config.HTTPAPIPort)
 
+	linkHealthJob, err := dep.InjectLinkHealthJob(
+		env.Runtime(config.Runtime),
+		provider.LogPrefix(config.LogPrefix),
+		provider.LogLevel(config.LogLevel),
+		sqlDB,
+		slowQueryThreshold,
+		dataDogAPIKey,
+		provider.LinkHealthConfig{
+			Interval:         config.LinkHealthInterval,
+			BatchSize:        config.LinkHealthBatchSize,
+			FailureThreshold: config.LinkHealthThreshold,
+			ProbeTimeout:     config.LinkHealthTimeout,
+		},
+		featureToggle,
+		outboundLimiter,
+		webhookURL,
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	linkHealthJob.Start()
+
 	gRPCService, err := dep.InjectGRPCService(
 		env.Runtime(config.Runtime),
 		provider.LogPrefix(config.LogPrefix),
-		config.LogLevel,
+		provider.LogLevel(config.LogLevel),
 		sqlDB,
+		slowQueryThreshold,
 		security.Policy{
 			IsEncrypted:         config.EnableEncryption,
 			CertificateFilePath: config.CertFilePath,
 			KeyFilePath:         config.KeyFilePath,
 		},
 		dataDogAPIKey,
+		outboundLimiter,
 	)
 	if err != nil {
 		panic(err)
 	}
 
+	go stopOnSignal(graphqlAPI, httpAPI, gRPCService)
 	gRPCService.StartAndWait(config.GRPCAPIPort)
 }
+
+// stopOnSignal gracefully stops the services before exiting once the process
+// is interrupted or terminated.
+func stopOnSignal(services ...service.Service) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	<-signals
+
+	for _, s := range services {
+		s.Stop()
+	}
+	os.Exit(0)
+}
@@ -0,0 +1,302 @@
+package app
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/short-d/short/backend/app/usecase/keygen"
+	"github.com/short-d/short/backend/app/usecase/shortlink"
+	"github.com/short-d/short/backend/app/usecase/validator"
+	"github.com/short-d/short/backend/app/usecase/visit"
+	"github.com/short-d/short/backend/dep/provider"
+)
+
+const maxPort = 65535
+
+var logLevels = []string{"", "debug", "info", "warn", "error"}
+
+var keyGenStrategies = []string{
+	string(keygen.StrategyRandom),
+	string(keygen.StrategySequential),
+	string(keygen.StrategyHashids),
+	string(keygen.StrategyWords),
+	string(keygen.StrategyCryptoRandom),
+}
+
+var visitorIPModes = []string{
+	"",
+	string(visit.IPModeFull),
+	string(visit.IPModeAnonymized),
+	string(visit.IPModeNone),
+}
+
+// ErrInvalidConfig lists all the problems found in ServiceConfig, each naming
+// the environment variable to fix, so that they can be fixed at once instead
+// of failing one by one after the service starts.
+type ErrInvalidConfig []string
+
+func (e ErrInvalidConfig) Error() string {
+	return "invalid service config:\n\t" + strings.Join(e, "\n\t")
+}
+
+// Validate checks that the required fields are set and that the fields are
+// well formatted, returning ErrInvalidConfig with all the problems found.
+func (c ServiceConfig) Validate() error {
+	var v configValidator
+
+	v.oneOf("LOG_LEVEL", c.LogLevel, logLevels)
+	v.required("JWT_SECRET", c.JwtSecret)
+	v.url("WEB_FRONTEND_URL", c.WebFrontendURL, true)
+	v.url("SHORT_LINK_BASE_URL", c.ShortLinkBaseURL, false)
+
+	v.port("GRAPHQL_API_PORT", c.GraphQLAPIPort)
+	v.port("HTTP_API_PORT", c.HTTPAPIPort)
+	v.port("GRPC_API_PORT", c.GRPCAPIPort)
+	if c.EnableEncryption {
+		v.requiredBy("CERT_FILE_PATH", c.CertFilePath, "ENABLE_ENCRYPTION")
+		v.requiredBy("KEY_FILE_PATH", c.KeyFilePath, "ENABLE_ENCRYPTION")
+	}
+
+	v.pair("GITHUB_CLIENT_ID", c.GithubClientID, "GITHUB_CLIENT_SECRET", c.GithubClientSecret)
+	v.pair("FACEBOOK_CLIENT_ID", c.FacebookClientID, "FACEBOOK_CLIENT_SECRET", c.FacebookClientSecret)
+	v.pair("FACEBOOK_CLIENT_ID", c.FacebookClientID, "FACEBOOK_REDIRECT_URI", c.FacebookRedirectURI)
+	v.pair("GOOGLE_CLIENT_ID", c.GoogleClientID, "GOOGLE_CLIENT_SECRET", c.GoogleClientSecret)
+	v.pair("GOOGLE_CLIENT_ID", c.GoogleClientID, "GOOGLE_REDIRECT_URI", c.GoogleRedirectURI)
+	v.pair("TWITTER_CLIENT_ID", c.TwitterClientID, "TWITTER_CLIENT_SECRET", c.TwitterClientSecret)
+	v.pair("TWITTER_CLIENT_ID", c.TwitterClientID, "TWITTER_REDIRECT_URI", c.TwitterRedirectURI)
+	v.pair("APPLE_CLIENT_ID", c.AppleClientID, "APPLE_REDIRECT_URI", c.AppleRedirectURI)
+
+	v.oneOf("KEY_GEN_STRATEGY", c.KeyGenStrategy, keyGenStrategies)
+	v.atLeast("KEY_GEN_BUFFER_SIZE", c.KeyGenBufferSize, 1)
+	if c.KeyGenBatchMinSize != 0 || c.KeyGenBatchMaxSize != 0 {
+		v.atLeast("KEY_GEN_BATCH_MIN_SIZE", c.KeyGenBatchMinSize, 1)
+		if c.KeyGenBatchMaxSize < c.KeyGenBatchMinSize {
+			v.addf("KEY_GEN_BATCH_MAX_SIZE must not be less than KEY_GEN_BATCH_MIN_SIZE")
+		}
+	}
+	v.port("KEY_GEN_PORT", c.KgsPort)
+	v.atLeast("KEY_GEN_CONNECT_MAX_ATTEMPTS", c.KgsMaxAttempts, 1)
+	v.nonNegative("KEY_GEN_CONNECT_BACKOFF", c.KgsInitialBackoff)
+	if c.KgsMaxBackoff < c.KgsInitialBackoff {
+		v.addf("KEY_GEN_CONNECT_MAX_BACKOFF must not be shorter than KEY_GEN_CONNECT_BACKOFF")
+	}
+	v.positive("KEY_GEN_DIAL_TIMEOUT", c.KgsDialTimeout)
+	if keygen.Strategy(c.KeyGenStrategy) == keygen.StrategyWords {
+		v.requiredBy("KEY_GEN_WORD_LIST_PATH", c.KeyGenWordListPath, "KEY_GEN_STRATEGY=words")
+		v.atLeast("KEY_GEN_WORD_COUNT", c.KeyGenWordCount, 1)
+	}
+
+	v.positive("AUTH_TOKEN_LIFETIME", c.AuthTokenLifetime)
+	v.positive("SEARCH_TIMEOUT", c.SearchTimeout)
+	v.positive("LINK_REDIRECT_LIMIT_WINDOW", c.LinkRateWindow)
+	v.nonNegative("DB_CONN_MAX_LIFETIME", c.DBConnMaxLifetime)
+	v.nonNegative("DB_SLOW_QUERY_THRESHOLD", c.DBSlowQueryThreshold)
+	v.oneOf("VISITOR_IP_MODE", c.VisitorIPMode, visitorIPModes)
+	v.positive("LINK_HEALTH_CHECK_INTERVAL", c.LinkHealthInterval)
+	v.atLeast("LINK_HEALTH_BATCH_SIZE", c.LinkHealthBatchSize, 1)
+	v.atLeast("LINK_HEALTH_FAILURE_THRESHOLD", c.LinkHealthThreshold, 1)
+	v.positive("LINK_HEALTH_PROBE_TIMEOUT", c.LinkHealthTimeout)
+	v.atLeast("URL_VALIDATION_WORKERS", c.URLValidationWorkers, 1)
+	v.between("REDIRECT_LOG_SAMPLE_PERCENT", c.RedirectLogSampling, 0, 100)
+	v.nonNegative("DEFAULT_EXPIRE_AFTER", c.DefaultExpireAfter)
+	v.nonNegative("EXPIRE_GRACE_PERIOD", c.ExpireGracePeriod)
+	v.nonNegative("GUEST_CREATE_COOLDOWN", c.GuestCreateCooldown)
+	v.nonNegative("OUTBOUND_HTTP_WAIT_TIMEOUT", c.OutboundWaitTimeout)
+	v.atLeast("SHORT_LINK_RELATION_MAX_ATTEMPTS", c.RelationMaxAttempts, 1)
+	v.nonNegative("SHORT_LINK_RELATION_BACKOFF", c.RelationBackoff)
+	v.positive("ALIAS_RESERVATION_TTL", c.AliasReservationTTL)
+	if c.AliasReservationMax < c.AliasReservationTTL {
+		v.addf("ALIAS_RESERVATION_MAX_TTL must not be shorter than ALIAS_RESERVATION_TTL")
+	}
+	v.atLeast("CHAINED_LINK_MAX_REDIRECTS", c.ChainedMaxRedirects, 1)
+	v.atLeast("CUSTOM_ALIAS_QUOTA", c.CustomAliasQuota, 0)
+	v.atLeast("AUTO_ALIAS_QUOTA", c.AutoAliasQuota, 0)
+	v.atLeast("ALIAS_RETRY_BUDGET", c.AliasRetryBudget, 0)
+	v.parse("ALIAS_PREFIX", provider.ValidateAliasPrefix(provider.AliasPrefix(c.AliasPrefix)))
+	v.atLeast("VISIT_COUNT_BUFFER_SIZE", c.VisitBufferSize, 0)
+	if c.VisitBufferSize > 0 {
+		// The buffered visits would wait until the buffer is full otherwise.
+		v.positive("VISIT_COUNT_FLUSH_INTERVAL", c.VisitFlushInterval)
+	}
+	v.atLeast("MAX_REQUEST_BODY_SIZE", c.MaxRequestBodySize, 0)
+	v.atLeast("GRAPHQL_PERSISTED_QUERY_LIMIT", c.PersistedQueryLimit, 0)
+	v.atLeast("REDIRECT_RATE_LIMIT", c.RedirectRateLimit, 0)
+	if c.RedirectRateLimit > 0 {
+		v.positive("REDIRECT_RATE_LIMIT_WINDOW", c.RedirectRateWindow)
+	}
+	v.atLeast("SIGN_IN_RATE_LIMIT", c.SignInRateLimit, 0)
+	if c.SignInRateLimit > 0 {
+		v.positive("SIGN_IN_RATE_WINDOW", c.SignInRateWindow)
+	}
+
+	if c.SMTPHost != "" {
+		v.port("SMTP_PORT", c.SMTPPort)
+	}
+	v.url("WEBHOOK_URL", c.WebhookURL, false)
+
+	v.ipRanges("TRUSTED_PROXIES", c.TrustedProxies)
+	v.ipRanges("ADMIN_ALLOWED_IPS", c.AdminAllowedIPs)
+
+	_, err := validator.ParseFragment(c.LongLinkFragment)
+	v.parse("LONG_LINK_FRAGMENT", err)
+	_, err = validator.ParsePlainHTTP(c.LongLinkPlainHTTP)
+	v.parse("LONG_LINK_PLAIN_HTTP", err)
+	_, err = validator.NewUnicodeCustomAlias(nonEmpty(c.AliasCategories))
+	v.parse("CUSTOM_ALIAS_UNICODE_CATEGORIES", err)
+	_, err = shortlink.ParseChecks(nonEmpty(c.ShortLinkChecks))
+	v.parse("SHORT_LINK_CHECKS", err)
+	_, err = shortlink.ParseLongLinkUniqueness(c.LongLinkUniqueness)
+	v.parse("LONG_LINK_UNIQUENESS", err)
+	_, err = shortlink.ParseRoleLifetimes(nonEmpty(c.RoleExpireAfter))
+	v.parse("ROLE_DEFAULT_EXPIRE_AFTER", err)
+	_, err = shortlink.ParseQueryConflict(c.QueryConflict)
+	v.parse("PASSTHROUGH_QUERY_CONFLICT", err)
+	_, err = shortlink.ParseChainedLinkMode(c.ChainedLinkMode)
+	v.parse("CHAINED_LINK_MODE", err)
+	_, err = provider.NewRedirect(provider.RedirectConfig{
+		StatusCode:    c.RedirectStatusCode,
+		MaxAge:        c.RedirectMaxAge,
+		EditableLinks: c.EditableLinks,
+	})
+	v.parse("REDIRECT_STATUS_CODE", err)
+	v.nonNegative("REDIRECT_CACHE_MAX_AGE", c.RedirectMaxAge)
+	_, err = provider.NewCanonicalDomain(provider.DomainRedirects(c.DomainRedirects))
+	v.parse("SHORT_LINK_DOMAIN_REDIRECTS", err)
+	_, err = provider.NewTenantHosts(provider.TenantHosts(c.TenantHosts))
+	v.parse("TENANT_HOSTS", err)
+	v.atLeast("PASSWORD_RESET_RATE_LIMIT", c.ResetRateLimit, 0)
+	if c.ResetRateLimit > 0 {
+		v.positive("PASSWORD_RESET_RATE_WINDOW", c.ResetRateWindow)
+	}
+	// bcrypt only takes the first 72 bytes of passwords into account.
+	v.between("PASSWORD_MIN_LENGTH", c.PasswordMinLength, 1, 72)
+	if c.PasswordMinLength >= 1 && c.PasswordMinLength <= 72 {
+		_, err = provider.NewPasswordPolicy(provider.PasswordPolicyConfig{
+			MinLength:       c.PasswordMinLength,
+			RequiredClasses: c.PasswordCharClasses,
+		})
+		v.parse("PASSWORD_REQUIRED_CHARS", err)
+	}
+
+	return v.err()
+}
+
+// configValidator collects the problems of the fields instead of stopping at
+// the first one.
+type configValidator struct {
+	violations ErrInvalidConfig
+}
+
+func (v *configValidator) addf(format string, args ...interface{}) {
+	v.violations = append(v.violations, fmt.Sprintf(format, args...))
+}
+
+func (v *configValidator) required(name string, value string) {
+	if value == "" {
+		v.addf("%s is required", name)
+	}
+}
+
+func (v *configValidator) requiredBy(name string, value string, dependent string) {
+	if value == "" {
+		v.addf("%s is required by %s", name, dependent)
+	}
+}
+
+// pair requires the second field when the first one is set, such as the
+// client secret of an OAuth client ID.
+func (v *configValidator) pair(name string, value string, otherName string, otherValue string) {
+	if value != "" {
+		v.requiredBy(otherName, otherValue, name)
+	}
+}
+
+func (v *configValidator) url(name string, value string, isRequired bool) {
+	if value == "" {
+		if isRequired {
+			v.required(name, value)
+		}
+		return
+	}
+	u, err := url.Parse(value)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		v.addf("%s must be an absolute HTTP URL: %s", name, value)
+	}
+}
+
+func (v *configValidator) port(name string, port int) {
+	v.between(name, port, 1, maxPort)
+}
+
+func (v *configValidator) atLeast(name string, value int, min int) {
+	if value < min {
+		v.addf("%s must be at least %d: %d", name, min, value)
+	}
+}
+
+func (v *configValidator) between(name string, value int, min int, max int) {
+	if value < min || value > max {
+		v.addf("%s must be between %d and %d: %d", name, min, max, value)
+	}
+}
+
+func (v *configValidator) positive(name string, duration time.Duration) {
+	if duration <= 0 {
+		v.addf("%s must be positive: %s", name, duration)
+	}
+}
+
+func (v *configValidator) nonNegative(name string, duration time.Duration) {
+	if duration < 0 {
+		v.addf("%s must not be negative: %s", name, duration)
+	}
+}
+
+func (v *configValidator) oneOf(name string, value string, options []string) {
+	for _, option := range options {
+		if value == option {
+			return
+		}
+	}
+	v.addf("%s must be one of %s: %s", name, strings.Join(nonEmpty(options), ", "), value)
+}
+
+func (v *configValidator) ipRanges(name string, entries []string) {
+	for _, entry := range nonEmpty(entries) {
+		if strings.Contains(entry, "/") {
+			_, _, err := net.ParseCIDR(entry)
+			if err == nil {
+				continue
+			}
+		} else if net.ParseIP(entry) != nil {
+			continue
+		}
+		v.addf("%s must list IP addresses or CIDR ranges: %s", name, entry)
+	}
+}
+
+func (v *configValidator) parse(name string, err error) {
+	if err != nil {
+		v.addf("%s is invalid: %s", name, err)
+	}
+}
+
+func (v configValidator) err() error {
+	if len(v.violations) == 0 {
+		return nil
+	}
+	return v.violations
+}
+
+func nonEmpty(values []string) []string {
+	var nonEmptyValues []string
+	for _, value := range values {
+		if value != "" {
+			nonEmptyValues = append(nonEmptyValues, value)
+		}
+	}
+	return nonEmptyValues
+}
@@ -0,0 +1,462 @@
+// +build !integration all
+
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+)
+
+func newValidServiceConfig() ServiceConfig {
+	return ServiceConfig{
+		Runtime:              "production",
+		LogLevel:             "info",
+		DBConnMaxLifetime:    5 * time.Minute,
+		DBSlowQueryThreshold: 500 * time.Millisecond,
+		JwtSecret:            "secret",
+		WebFrontendURL:       "https://short-d.com",
+		ShortLinkBaseURL:     "https://s.short-d.com",
+		LongLinkFragment:     "preserve",
+		GraphQLAPIPort:       8080,
+		HTTPAPIPort:          80,
+		GRPCAPIPort:          8081,
+		KeyGenBufferSize:     50,
+		KgsHostname:          "localhost",
+		KgsPort:              8080,
+		KgsMaxAttempts:       5,
+		KgsInitialBackoff:    time.Second,
+		KgsMaxBackoff:        30 * time.Second,
+		KgsDialTimeout:       5 * time.Second,
+		AuthTokenLifetime:    7 * 24 * time.Hour,
+		SearchTimeout:        time.Second,
+		VisitorIPMode:        "anonymized",
+		LinkHealthInterval:   time.Minute,
+		LinkHealthBatchSize:  10,
+		LinkHealthThreshold:  3,
+		LinkHealthTimeout:    5 * time.Second,
+		KeyGenStrategy:       "random",
+		RedirectLogSampling:  100,
+		ShortLinkChecks:      []string{"custom_alias", "long_link", "risk"},
+		URLValidationWorkers: 10,
+		TrustedProxies:       []string{"127.0.0.0/8", "::1"},
+		GuestCreateCooldown:  10 * time.Second,
+		LinkRateWindow:       time.Minute,
+		LongLinkUniqueness:   "none",
+		AliasCategories:      []string{""},
+		SMTPHost:             "localhost",
+		SMTPPort:             25,
+		LongLinkPlainHTTP:    "allow",
+		RoleExpireAfter:      []string{""},
+		AdminAllowedIPs:      []string{""},
+		OutboundWaitTimeout:  5 * time.Second,
+		QueryConflict:        "keep_long_link",
+		RedirectStatusCode:   303,
+		PasswordMinLength:    8,
+		PasswordCharClasses:  []string{"letter", "digit"},
+		RelationMaxAttempts:  3,
+		RelationBackoff:      50 * time.Millisecond,
+		AliasReservationTTL:  10 * time.Minute,
+		AliasReservationMax:  720 * time.Hour,
+		ChainedLinkMode:      "reject",
+		ChainedMaxRedirects:  5,
+		MaxRequestBodySize:   1 << 20,
+		AliasRetryBudget:     3,
+		VisitFlushInterval:   10 * time.Second,
+		VisitBufferSize:      1000,
+		SignInRateLimit:      5,
+		SignInRateWindow:     15 * time.Minute,
+		PersistedQueryLimit:  1000,
+	}
+}
+
+func TestServiceConfig_Validate(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		update      func(config *ServiceConfig)
+		expectedErr error
+	}{
+		{
+			name:   "valid config",
+			update: func(config *ServiceConfig) {},
+		},
+		{
+			name: "valid config with optional fields",
+			update: func(config *ServiceConfig) {
+				config.EnableEncryption = true
+				config.CertFilePath = "/etc/certs/tls.crt"
+				config.KeyFilePath = "/etc/certs/tls.key"
+				config.GithubClientID = "github-id"
+				config.GithubClientSecret = "github-secret"
+				config.KeyGenStrategy = "words"
+				config.KeyGenWordListPath = "config/words.txt"
+				config.KeyGenWordCount = 3
+				config.WebhookURL = "https://hooks.short-d.com"
+				config.AdminAllowedIPs = []string{"10.0.0.0/8", "2001:db8::1"}
+				config.RoleExpireAfter = []string{"basic=720h"}
+				config.DomainRedirects = []string{"www.s.short-d.com=https://s.short-d.com"}
+				config.TenantHosts = []string{"s.acme.com=acme"}
+				config.KeyGenBatchMinSize = 10
+				config.KeyGenBatchMaxSize = 200
+				config.CustomAliasQuota = 10
+				config.AutoAliasQuota = 100
+				config.AliasPrefix = "eu-"
+				config.RedirectRateLimit = 60
+				config.RedirectRateWindow = time.Minute
+			},
+		},
+		{
+			name: "missing JWT secret",
+			update: func(config *ServiceConfig) {
+				config.JwtSecret = ""
+			},
+			expectedErr: ErrInvalidConfig{"JWT_SECRET is required"},
+		},
+		{
+			name: "missing web frontend URL",
+			update: func(config *ServiceConfig) {
+				config.WebFrontendURL = ""
+			},
+			expectedErr: ErrInvalidConfig{"WEB_FRONTEND_URL is required"},
+		},
+		{
+			name: "relative short link base URL",
+			update: func(config *ServiceConfig) {
+				config.ShortLinkBaseURL = "s.short-d.com"
+			},
+			expectedErr: ErrInvalidConfig{"SHORT_LINK_BASE_URL must be an absolute HTTP URL: s.short-d.com"},
+		},
+		{
+			name: "unknown log level",
+			update: func(config *ServiceConfig) {
+				config.LogLevel = "verbose"
+			},
+			expectedErr: ErrInvalidConfig{"LOG_LEVEL must be one of debug, info, warn, error: verbose"},
+		},
+		{
+			name: "port out of range",
+			update: func(config *ServiceConfig) {
+				config.HTTPAPIPort = 65536
+			},
+			expectedErr: ErrInvalidConfig{"HTTP_API_PORT must be between 1 and 65535: 65536"},
+		},
+		{
+			name: "missing certificate with encryption",
+			update: func(config *ServiceConfig) {
+				config.EnableEncryption = true
+				config.CertFilePath = ""
+				config.KeyFilePath = "/etc/certs/tls.key"
+			},
+			expectedErr: ErrInvalidConfig{"CERT_FILE_PATH is required by ENABLE_ENCRYPTION"},
+		},
+		{
+			name: "missing OAuth client secret",
+			update: func(config *ServiceConfig) {
+				config.GoogleClientID = "google-id"
+				config.GoogleRedirectURI = "https://short-d.com/oauth/google/sign-in/callback"
+			},
+			expectedErr: ErrInvalidConfig{"GOOGLE_CLIENT_SECRET is required by GOOGLE_CLIENT_ID"},
+		},
+		{
+			name: "unknown key generation strategy",
+			update: func(config *ServiceConfig) {
+				config.KeyGenStrategy = "uuid"
+			},
+			expectedErr: ErrInvalidConfig{"KEY_GEN_STRATEGY must be one of random, sequential, hashids, words, crypto_random: uuid"},
+		},
+		{
+			name: "missing word list",
+			update: func(config *ServiceConfig) {
+				config.KeyGenStrategy = "words"
+				config.KeyGenWordCount = 3
+			},
+			expectedErr: ErrInvalidConfig{"KEY_GEN_WORD_LIST_PATH is required by KEY_GEN_STRATEGY=words"},
+		},
+		{
+			name: "max backoff shorter than backoff",
+			update: func(config *ServiceConfig) {
+				config.KgsMaxBackoff = 500 * time.Millisecond
+			},
+			expectedErr: ErrInvalidConfig{"KEY_GEN_CONNECT_MAX_BACKOFF must not be shorter than KEY_GEN_CONNECT_BACKOFF"},
+		},
+		{
+			name: "batch max size less than min size",
+			update: func(config *ServiceConfig) {
+				config.KeyGenBatchMinSize = 100
+				config.KeyGenBatchMaxSize = 10
+			},
+			expectedErr: ErrInvalidConfig{"KEY_GEN_BATCH_MAX_SIZE must not be less than KEY_GEN_BATCH_MIN_SIZE"},
+		},
+		{
+			name: "zero duration",
+			update: func(config *ServiceConfig) {
+				config.LinkHealthInterval = 0
+			},
+			expectedErr: ErrInvalidConfig{"LINK_HEALTH_CHECK_INTERVAL must be positive: 0s"},
+		},
+		{
+			name: "negative duration",
+			update: func(config *ServiceConfig) {
+				config.DefaultExpireAfter = -time.Hour
+			},
+			expectedErr: ErrInvalidConfig{"DEFAULT_EXPIRE_AFTER must not be negative: -1h0m0s"},
+		},
+		{
+			name: "negative expire grace period",
+			update: func(config *ServiceConfig) {
+				config.ExpireGracePeriod = -time.Minute
+			},
+			expectedErr: ErrInvalidConfig{"EXPIRE_GRACE_PERIOD must not be negative: -1m0s"},
+		},
+		{
+			name: "negative slow query threshold",
+			update: func(config *ServiceConfig) {
+				config.DBSlowQueryThreshold = -time.Second
+			},
+			expectedErr: ErrInvalidConfig{"DB_SLOW_QUERY_THRESHOLD must not be negative: -1s"},
+		},
+		{
+			name: "zero workers",
+			update: func(config *ServiceConfig) {
+				config.URLValidationWorkers = 0
+			},
+			expectedErr: ErrInvalidConfig{"URL_VALIDATION_WORKERS must be at least 1: 0"},
+		},
+		{
+			name: "sample percent out of range",
+			update: func(config *ServiceConfig) {
+				config.RedirectLogSampling = 101
+			},
+			expectedErr: ErrInvalidConfig{"REDIRECT_LOG_SAMPLE_PERCENT must be between 0 and 100: 101"},
+		},
+		{
+			name: "invalid trusted proxy",
+			update: func(config *ServiceConfig) {
+				config.TrustedProxies = []string{"10.0.0.0/33"}
+			},
+			expectedErr: ErrInvalidConfig{"TRUSTED_PROXIES must list IP addresses or CIDR ranges: 10.0.0.0/33"},
+		},
+		{
+			name: "unknown passthrough query conflict",
+			update: func(config *ServiceConfig) {
+				config.QueryConflict = "merge"
+			},
+			expectedErr: ErrInvalidConfig{"PASSTHROUGH_QUERY_CONFLICT is invalid: unknown query conflict resolution: merge"},
+		},
+		{
+			name: "unsupported redirect status code",
+			update: func(config *ServiceConfig) {
+				config.RedirectStatusCode = 200
+			},
+			expectedErr: ErrInvalidConfig{"REDIRECT_STATUS_CODE is invalid: unsupported redirect status code: 200"},
+		},
+		{
+			name: "domain redirect without canonical URL",
+			update: func(config *ServiceConfig) {
+				config.DomainRedirects = []string{"www.s.short-d.com"}
+			},
+			expectedErr: ErrInvalidConfig{"SHORT_LINK_DOMAIN_REDIRECTS is invalid: invalid domain redirect: www.s.short-d.com"},
+		},
+		{
+			name: "tenant host without tenant",
+			update: func(config *ServiceConfig) {
+				config.TenantHosts = []string{"s.acme.com"}
+			},
+			expectedErr: ErrInvalidConfig{"TENANT_HOSTS is invalid: invalid tenant host: s.acme.com"},
+		},
+		{
+			name: "negative password reset rate limit",
+			update: func(config *ServiceConfig) {
+				config.ResetRateLimit = -1
+			},
+			expectedErr: ErrInvalidConfig{"PASSWORD_RESET_RATE_LIMIT must be at least 0: -1"},
+		},
+		{
+			name: "password reset rate limited without window",
+			update: func(config *ServiceConfig) {
+				config.ResetRateLimit = 3
+			},
+			expectedErr: ErrInvalidConfig{"PASSWORD_RESET_RATE_WINDOW must be positive: 0s"},
+		},
+		{
+			name: "password minimum length beyond bcrypt limit",
+			update: func(config *ServiceConfig) {
+				config.PasswordMinLength = 100
+			},
+			expectedErr: ErrInvalidConfig{"PASSWORD_MIN_LENGTH must be between 1 and 72: 100"},
+		},
+		{
+			name: "unknown password character class",
+			update: func(config *ServiceConfig) {
+				config.PasswordCharClasses = []string{"letter", "emoji"}
+			},
+			expectedErr: ErrInvalidConfig{
+				"PASSWORD_REQUIRED_CHARS is invalid: invalid password policy: unknown character class: emoji",
+			},
+		},
+		{
+			name: "relation created without attempts",
+			update: func(config *ServiceConfig) {
+				config.RelationMaxAttempts = 0
+			},
+			expectedErr: ErrInvalidConfig{"SHORT_LINK_RELATION_MAX_ATTEMPTS must be at least 1: 0"},
+		},
+		{
+			name: "alias never reserved",
+			update: func(config *ServiceConfig) {
+				config.AliasReservationTTL = 0
+			},
+			expectedErr: ErrInvalidConfig{"ALIAS_RESERVATION_TTL must be positive: 0s"},
+		},
+		{
+			name: "alias reserved longer than allowed by default",
+			update: func(config *ServiceConfig) {
+				config.AliasReservationMax = 5 * time.Minute
+			},
+			expectedErr: ErrInvalidConfig{"ALIAS_RESERVATION_MAX_TTL must not be shorter than ALIAS_RESERVATION_TTL"},
+		},
+		{
+			name: "unknown chained link mode",
+			update: func(config *ServiceConfig) {
+				config.ChainedLinkMode = "follow"
+			},
+			expectedErr: ErrInvalidConfig{"CHAINED_LINK_MODE is invalid: unknown chained link mode: follow"},
+		},
+		{
+			name: "chained link resolved without redirects",
+			update: func(config *ServiceConfig) {
+				config.ChainedMaxRedirects = 0
+			},
+			expectedErr: ErrInvalidConfig{"CHAINED_LINK_MAX_REDIRECTS must be at least 1: 0"},
+		},
+		{
+			name: "negative alias quotas",
+			update: func(config *ServiceConfig) {
+				config.CustomAliasQuota = -1
+				config.AutoAliasQuota = -1
+			},
+			expectedErr: ErrInvalidConfig{
+				"CUSTOM_ALIAS_QUOTA must be at least 0: -1",
+				"AUTO_ALIAS_QUOTA must be at least 0: -1",
+			},
+		},
+		{
+			name: "negative alias retry budget",
+			update: func(config *ServiceConfig) {
+				config.AliasRetryBudget = -1
+			},
+			expectedErr: ErrInvalidConfig{"ALIAS_RETRY_BUDGET must be at least 0: -1"},
+		},
+		{
+			name: "alias prefix with disallowed character",
+			update: func(config *ServiceConfig) {
+				config.AliasPrefix = "eu/"
+			},
+			expectedErr: ErrInvalidConfig{
+				"ALIAS_PREFIX is invalid: alias prefix eu/ can only contain ASCII letters, digits, - and _",
+			},
+		},
+		{
+			name: "alias prefix leaving no room for keys",
+			update: func(config *ServiceConfig) {
+				config.AliasPrefix = strings.Repeat("a", 49)
+			},
+			expectedErr: ErrInvalidConfig{
+				"ALIAS_PREFIX is invalid: alias prefix " + strings.Repeat("a", 49) + " is invalid: AliasTooLong",
+			},
+		},
+		{
+			name: "negative visit buffer size",
+			update: func(config *ServiceConfig) {
+				config.VisitBufferSize = -1
+			},
+			expectedErr: ErrInvalidConfig{"VISIT_COUNT_BUFFER_SIZE must be at least 0: -1"},
+		},
+		{
+			name: "visits buffered without flush interval",
+			update: func(config *ServiceConfig) {
+				config.VisitFlushInterval = 0
+			},
+			expectedErr: ErrInvalidConfig{"VISIT_COUNT_FLUSH_INTERVAL must be positive: 0s"},
+		},
+		{
+			name: "visits written right away without flush interval",
+			update: func(config *ServiceConfig) {
+				config.VisitBufferSize = 0
+				config.VisitFlushInterval = 0
+			},
+		},
+		{
+			name: "negative max request body size",
+			update: func(config *ServiceConfig) {
+				config.MaxRequestBodySize = -1
+			},
+			expectedErr: ErrInvalidConfig{"MAX_REQUEST_BODY_SIZE must be at least 0: -1"},
+		},
+		{
+			name: "negative persisted query limit",
+			update: func(config *ServiceConfig) {
+				config.PersistedQueryLimit = -1
+			},
+			expectedErr: ErrInvalidConfig{"GRAPHQL_PERSISTED_QUERY_LIMIT must be at least 0: -1"},
+		},
+		{
+			name: "negative redirect rate limit",
+			update: func(config *ServiceConfig) {
+				config.RedirectRateLimit = -1
+			},
+			expectedErr: ErrInvalidConfig{"REDIRECT_RATE_LIMIT must be at least 0: -1"},
+		},
+		{
+			name: "redirect rate limited without window",
+			update: func(config *ServiceConfig) {
+				config.RedirectRateLimit = 60
+			},
+			expectedErr: ErrInvalidConfig{"REDIRECT_RATE_LIMIT_WINDOW must be positive: 0s"},
+		},
+		{
+			name: "negative sign in rate limit",
+			update: func(config *ServiceConfig) {
+				config.SignInRateLimit = -1
+			},
+			expectedErr: ErrInvalidConfig{"SIGN_IN_RATE_LIMIT must be at least 0: -1"},
+		},
+		{
+			name: "sign in rate limited without window",
+			update: func(config *ServiceConfig) {
+				config.SignInRateWindow = 0
+			},
+			expectedErr: ErrInvalidConfig{"SIGN_IN_RATE_WINDOW must be positive: 0s"},
+		},
+		{
+			name: "many problems",
+			update: func(config *ServiceConfig) {
+				config.JwtSecret = ""
+				config.LongLinkFragment = "drop"
+				config.ShortLinkChecks = []string{"spam"}
+			},
+			expectedErr: ErrInvalidConfig{
+				"JWT_SECRET is required",
+				"LONG_LINK_FRAGMENT is invalid: unknown fragment handling: drop",
+				"SHORT_LINK_CHECKS is invalid: unknown check: spam",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			config := newValidServiceConfig()
+			testCase.update(&config)
+
+			err := config.Validate()
+			assert.Equal(t, testCase.expectedErr, err)
+		})
+	}
+}
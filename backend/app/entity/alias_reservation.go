@@ -0,0 +1,13 @@
+package entity
+
+import "time"
+
+// AliasReservation represents a custom alias held for a user before the short
+// link is created under it. Only the holder of the token can create the short
+// link until the reservation expires.
+type AliasReservation struct {
+	Alias    string
+	UserID   string
+	Token    string
+	ExpireAt time.Time
+}
@@ -0,0 +1,11 @@
+package entity
+
+import "time"
+
+// FlaggedShortLink represents a short link allowed to be created while its
+// long link is suspicious enough to be reviewed.
+type FlaggedShortLink struct {
+	Alias     string
+	RiskScore int
+	FlaggedAt time.Time
+}
@@ -0,0 +1,24 @@
+package entity
+
+import "time"
+
+// LinkHealthStatus represents whether the long link of a short link is
+// reachable.
+type LinkHealthStatus string
+
+// The constants enumerate all possible link health statuses.
+const (
+	LinkHealthUnknown LinkHealthStatus = "unknown"
+	LinkHealthHealthy LinkHealthStatus = "healthy"
+	LinkHealthBroken  LinkHealthStatus = "broken"
+)
+
+// LinkHealth represents the outcome of the latest checks on the long link of
+// a short link of the tenant.
+type LinkHealth struct {
+	TenantID            string
+	Alias               string
+	Status              LinkHealthStatus
+	ConsecutiveFailures int
+	LastCheckedAt       time.Time
+}
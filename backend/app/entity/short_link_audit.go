@@ -0,0 +1,20 @@
+package entity
+
+import "time"
+
+// ShortLinkAction represents an administrative action taken on a short link.
+type ShortLinkAction string
+
+// ShortLinkActionExpire expires a short link immediately while keeping its
+// history, unlike disabling or deleting it.
+const ShortLinkActionExpire ShortLinkAction = "expire"
+
+// ShortLinkAuditEntry records who took an administrative action on a short
+// link, why and when.
+type ShortLinkAuditEntry struct {
+	Alias     string
+	Action    ShortLinkAction
+	Reason    string
+	UserID    string
+	CreatedAt time.Time
+}
@@ -8,23 +8,66 @@ import (
 
 // ShortLink represents a short link.
 type ShortLink struct {
-	Alias         string
-	LongLink      string
-	ExpireAt      *time.Time
-	CreatedBy     *User
-	CreatedAt     *time.Time
-	UpdatedAt     *time.Time
-	OpenGraphTags metatag.OpenGraph
-	TwitterTags   metatag.Twitter
+	TenantID string
+	// Alias is only unique within the tenant hosting the short link.
+	Alias string
+	// LongLink is the canonical form of the long link, which is redirected to.
+	LongLink string
+	// OriginalLongLink is the long link exactly as given by the user for
+	// display. It is empty for the short links created before it was saved.
+	OriginalLongLink string
+	ExpireAt         *time.Time
+	CreatedBy        *User
+	CreatedAt        *time.Time
+	UpdatedAt        *time.Time
+	OpenGraphTags    metatag.OpenGraph
+	TwitterTags      metatag.Twitter
+	TrackVisits      bool
+	VisitCount       int
+	// PassthroughQuery forwards the query parameters of the requests to the
+	// short link to the long link.
+	PassthroughQuery bool
+	// PassthroughPath appends the path following the alias to the path of the
+	// long link, together with the query parameters.
+	PassthroughPath bool
+	// MaxRedirectsPerIP limits how many times each client IP can be
+	// redirected through the short link within a window. Zero means unlimited.
+	MaxRedirectsPerIP int
+	// MaxVisits limits how many times the short link can be visited in total.
+	// Zero means unlimited.
+	MaxVisits int
+	// Description is a note for the owner's own reference, which is never
+	// shown to other users.
+	Description string
+}
+
+// GetOriginalLongLink fetches the long link given by the user, falling back
+// to the canonical long link when the original one is not saved.
+func (s ShortLink) GetOriginalLongLink() string {
+	if s.OriginalLongLink == "" {
+		return s.LongLink
+	}
+	return s.OriginalLongLink
 }
 
 // ShortLinkInput represents possible ShortLink attributes for a short link.
+// OneTime asks for a short link which can only be visited once shortly after
+// it is created. ReservationToken claims the custom alias reserved beforehand.
 type ShortLinkInput struct {
-	LongLink    *string
-	CustomAlias *string
-	ExpireAt    *time.Time
-	CreatedAt   *time.Time
-	UpdatedAt   *time.Time
+	LongLink          *string
+	OriginalLongLink  *string
+	CustomAlias       *string
+	ExpireAt          *time.Time
+	CreatedAt         *time.Time
+	UpdatedAt         *time.Time
+	TrackVisits       *bool
+	PassthroughQuery  *bool
+	PassthroughPath   *bool
+	MaxRedirectsPerIP *int
+	MaxVisits         *int
+	OneTime           *bool
+	Description       *string
+	ReservationToken  *string
 }
 
 // GetLongLink fetches LongLink for ShortLinkInput with default value.
@@ -35,6 +78,15 @@ func (s *ShortLinkInput) GetLongLink(defaultVal string) string {
 	return *s.LongLink
 }
 
+// GetOriginalLongLink fetches OriginalLongLink for ShortLinkInput with default
+// value.
+func (s *ShortLinkInput) GetOriginalLongLink(defaultVal string) string {
+	if s.OriginalLongLink == nil {
+		return defaultVal
+	}
+	return *s.OriginalLongLink
+}
+
 // GetCustomAlias fetches CustomAlias for ShortLinkInput with default value.
 func (s *ShortLinkInput) GetCustomAlias(defaultVal string) string {
 	if s.CustomAlias == nil {
@@ -42,3 +94,71 @@ func (s *ShortLinkInput) GetCustomAlias(defaultVal string) string {
 	}
 	return *s.CustomAlias
 }
+
+// GetTrackVisits fetches TrackVisits for ShortLinkInput with default value.
+func (s *ShortLinkInput) GetTrackVisits(defaultVal bool) bool {
+	if s.TrackVisits == nil {
+		return defaultVal
+	}
+	return *s.TrackVisits
+}
+
+// GetPassthroughQuery fetches PassthroughQuery for ShortLinkInput with default
+// value.
+func (s *ShortLinkInput) GetPassthroughQuery(defaultVal bool) bool {
+	if s.PassthroughQuery == nil {
+		return defaultVal
+	}
+	return *s.PassthroughQuery
+}
+
+// GetPassthroughPath fetches PassthroughPath for ShortLinkInput with default
+// value.
+func (s *ShortLinkInput) GetPassthroughPath(defaultVal bool) bool {
+	if s.PassthroughPath == nil {
+		return defaultVal
+	}
+	return *s.PassthroughPath
+}
+
+// GetMaxRedirectsPerIP fetches MaxRedirectsPerIP for ShortLinkInput with
+// default value.
+func (s *ShortLinkInput) GetMaxRedirectsPerIP(defaultVal int) int {
+	if s.MaxRedirectsPerIP == nil {
+		return defaultVal
+	}
+	return *s.MaxRedirectsPerIP
+}
+
+// GetMaxVisits fetches MaxVisits for ShortLinkInput with default value.
+func (s *ShortLinkInput) GetMaxVisits(defaultVal int) int {
+	if s.MaxVisits == nil {
+		return defaultVal
+	}
+	return *s.MaxVisits
+}
+
+// GetOneTime fetches OneTime for ShortLinkInput with default value.
+func (s *ShortLinkInput) GetOneTime(defaultVal bool) bool {
+	if s.OneTime == nil {
+		return defaultVal
+	}
+	return *s.OneTime
+}
+
+// GetDescription fetches Description for ShortLinkInput with default value.
+func (s *ShortLinkInput) GetDescription(defaultVal string) string {
+	if s.Description == nil {
+		return defaultVal
+	}
+	return *s.Description
+}
+
+// GetReservationToken fetches ReservationToken for ShortLinkInput with default
+// value.
+func (s *ShortLinkInput) GetReservationToken(defaultVal string) string {
+	if s.ReservationToken == nil {
+		return defaultVal
+	}
+	return *s.ReservationToken
+}
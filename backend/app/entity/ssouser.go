@@ -4,5 +4,8 @@ package entity
 type SSOUser struct {
 	ID    string
 	Email string
-	Name  string
+	// EmailVerified is true when the identity provider confirms the user owns
+	// the email.
+	EmailVerified bool
+	Name          string
 }
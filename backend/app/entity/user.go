@@ -3,10 +3,14 @@ package entity
 import "time"
 
 // User contains basic user information such as, user ID, name, and email.
+// The email is only trusted once its owner proves access to it. The user can
+// only access the short links of the tenant the user belongs to.
 type User struct {
+	TenantID       string
 	ID             string
 	Name           string
 	Email          string
+	EmailVerified  bool
 	LastSignedInAt *time.Time
 	CreatedAt      *time.Time
 	UpdatedAt      *time.Time
@@ -0,0 +1,11 @@
+package entity
+
+import "time"
+
+// UserPreferences represents the default settings applied to the short links
+// created by a user when they are not given explicitly. Nil means the user has
+// no preference.
+type UserPreferences struct {
+	DefaultExpireAfter *time.Duration
+	DefaultTrackVisits *bool
+}
@@ -0,0 +1,29 @@
+package entity
+
+import "time"
+
+// Visit represents a single redirection from a short link to its long link.
+type Visit struct {
+	Alias       string
+	IPAddress   string
+	CountryCode string
+	Referrer    string
+	UserAgent   UserAgent
+	Campaign    Campaign
+	VisitedAt   time.Time
+}
+
+// UserAgent represents the client software used to visit a short link.
+type UserAgent struct {
+	Browser     string
+	OS          string
+	DeviceClass string
+}
+
+// Campaign represents the UTM parameters of the long link a visitor is
+// redirected to, attributing the visit to a marketing campaign.
+type Campaign struct {
+	Source string
+	Medium string
+	Name   string
+}
@@ -0,0 +1,13 @@
+package buildinfo
+
+// Info represents the build of the running service.
+type Info struct {
+	Version   string
+	Commit    string
+	BuildTime string
+}
+
+// Provider retrieves the build information of the running service.
+type Provider interface {
+	GetInfo() Info
+}
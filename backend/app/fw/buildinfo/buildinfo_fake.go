@@ -0,0 +1,18 @@
+package buildinfo
+
+var _ Provider = (*ProviderFake)(nil)
+
+// ProviderFake provides the given build information for testing.
+type ProviderFake struct {
+	info Info
+}
+
+// GetInfo retrieves the given build information.
+func (p ProviderFake) GetInfo() Info {
+	return p.info
+}
+
+// NewProviderFake creates ProviderFake with the given build information.
+func NewProviderFake(info Info) ProviderFake {
+	return ProviderFake{info: info}
+}
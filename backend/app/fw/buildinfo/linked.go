@@ -0,0 +1,31 @@
+package buildinfo
+
+var _ Provider = (*Linked)(nil)
+
+// The variables are overwritten at compile time with:
+//
+//	go build -ldflags "-X github.com/short-d/short/backend/app/fw/buildinfo.version=v1.0.0"
+//
+// They keep the defaults for local builds.
+var (
+	version   = "dev"
+	commit    = "unknown"
+	buildTime = "unknown"
+)
+
+// Linked provides the build information injected by the linker.
+type Linked struct{}
+
+// GetInfo retrieves the build information injected by the linker.
+func (l Linked) GetInfo() Info {
+	return Info{
+		Version:   version,
+		Commit:    commit,
+		BuildTime: buildTime,
+	}
+}
+
+// NewLinked creates Linked.
+func NewLinked() Linked {
+	return Linked{}
+}
@@ -0,0 +1,122 @@
+package cors
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const wildcardOrigin = "*"
+
+// ErrWildcardWithCredentials represents the invalid policy which shares
+// credentials with any origin.
+var ErrWildcardWithCredentials = errors.New("wildcard origin can't be combined with credentials")
+
+// Policy decides which cross origin requests browsers are allowed to make.
+type Policy struct {
+	allowedOrigins   []string
+	allowedMethods   []string
+	allowedHeaders   []string
+	allowCredentials bool
+}
+
+// Handler answers preflight requests and attaches CORS headers to the
+// responses of next. Preflight requests from origins not on the allowlist are
+// rejected with 403 Forbidden. Other requests from those origins are passed
+// through without CORS headers, so that browsers keep their responses from
+// the scripts of the origins while simple cross origin requests, such as form
+// posts from identity providers, still work. Requests without Origin header
+// are passed through as well.
+func (p Policy) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Origin")
+
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		requestMethod := r.Header.Get("Access-Control-Request-Method")
+		isPreflight := r.Method == http.MethodOptions && requestMethod != ""
+		if !p.isOriginAllowed(origin) {
+			if isPreflight {
+				http.Error(w, "origin not allowed", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if isPreflight {
+			p.handlePreflight(w, origin, requestMethod)
+			return
+		}
+
+		p.setAllowOrigin(w, origin)
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (p Policy) handlePreflight(w http.ResponseWriter, origin string, requestMethod string) {
+	if !contains(p.allowedMethods, requestMethod) {
+		http.Error(w, "method not allowed", http.StatusForbidden)
+		return
+	}
+
+	p.setAllowOrigin(w, origin)
+	w.Header().Set("Access-Control-Allow-Methods", strings.Join(p.allowedMethods, ", "))
+	if len(p.allowedHeaders) > 0 {
+		w.Header().Set("Access-Control-Allow-Headers", strings.Join(p.allowedHeaders, ", "))
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (p Policy) setAllowOrigin(w http.ResponseWriter, origin string) {
+	if p.allowCredentials {
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Set("Access-Control-Allow-Credentials", strconv.FormatBool(true))
+		return
+	}
+
+	if contains(p.allowedOrigins, wildcardOrigin) {
+		w.Header().Set("Access-Control-Allow-Origin", wildcardOrigin)
+		return
+	}
+	w.Header().Set("Access-Control-Allow-Origin", origin)
+}
+
+func (p Policy) isOriginAllowed(origin string) bool {
+	return contains(p.allowedOrigins, wildcardOrigin) || contains(p.allowedOrigins, origin)
+}
+
+func contains(values []string, target string) bool {
+	for _, value := range values {
+		if strings.EqualFold(value, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewPolicy creates CORS policy which allows the given origins to make
+// requests with the given methods and headers. Browsers are allowed to send
+// cookies when allowCredentials is true, which can't be combined with "*"
+// origin.
+func NewPolicy(
+	allowedOrigins []string,
+	allowedMethods []string,
+	allowedHeaders []string,
+	allowCredentials bool,
+) (Policy, error) {
+	if allowCredentials && contains(allowedOrigins, wildcardOrigin) {
+		return Policy{}, ErrWildcardWithCredentials
+	}
+	return Policy{
+		allowedOrigins:   allowedOrigins,
+		allowedMethods:   allowedMethods,
+		allowedHeaders:   allowedHeaders,
+		allowCredentials: allowCredentials,
+	}, nil
+}
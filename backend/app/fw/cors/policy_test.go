@@ -0,0 +1,238 @@
+// +build !integration all
+
+package cors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+)
+
+func TestNewPolicy(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name             string
+		allowedOrigins   []string
+		allowCredentials bool
+		expectedErr      error
+	}{
+		{
+			name:             "wildcard origin without credentials",
+			allowedOrigins:   []string{"*"},
+			allowCredentials: false,
+		},
+		{
+			name:             "allowed origins with credentials",
+			allowedOrigins:   []string{"https://short-d.com"},
+			allowCredentials: true,
+		},
+		{
+			name:             "wildcard origin with credentials",
+			allowedOrigins:   []string{"https://short-d.com", "*"},
+			allowCredentials: true,
+			expectedErr:      ErrWildcardWithCredentials,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewPolicy(testCase.allowedOrigins, nil, nil, testCase.allowCredentials)
+			assert.Equal(t, testCase.expectedErr, err)
+		})
+	}
+}
+
+func TestPolicy_Handler(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name               string
+		allowedOrigins     []string
+		allowCredentials   bool
+		method             string
+		headers            map[string]string
+		expectedStatusCode int
+		expectedHandled    bool
+		expectedHeaders    map[string]string
+	}{
+		{
+			name:               "same origin request",
+			allowedOrigins:     []string{"https://short-d.com"},
+			method:             http.MethodPost,
+			expectedStatusCode: http.StatusOK,
+			expectedHandled:    true,
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin": "",
+			},
+		},
+		{
+			name:           "allowed origin",
+			allowedOrigins: []string{"https://short-d.com"},
+			method:         http.MethodPost,
+			headers: map[string]string{
+				"Origin": "https://short-d.com",
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedHandled:    true,
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin":      "https://short-d.com",
+				"Access-Control-Allow-Credentials": "",
+			},
+		},
+		{
+			name:             "allowed origin with credentials",
+			allowedOrigins:   []string{"https://short-d.com"},
+			allowCredentials: true,
+			method:           http.MethodPost,
+			headers: map[string]string{
+				"Origin": "https://short-d.com",
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedHandled:    true,
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin":      "https://short-d.com",
+				"Access-Control-Allow-Credentials": "true",
+			},
+		},
+		{
+			name:           "wildcard origin",
+			allowedOrigins: []string{"*"},
+			method:         http.MethodGet,
+			headers: map[string]string{
+				"Origin": "https://example.com",
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedHandled:    true,
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin": "*",
+			},
+		},
+		{
+			name:           "cross origin POST from disallowed origin",
+			allowedOrigins: []string{"https://short-d.com"},
+			method:         http.MethodPost,
+			headers: map[string]string{
+				"Origin": "https://evil.com",
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedHandled:    true,
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin":      "",
+				"Access-Control-Allow-Credentials": "",
+			},
+		},
+		{
+			name:             "cross origin POST from disallowed origin with credentials",
+			allowedOrigins:   []string{"https://short-d.com"},
+			allowCredentials: true,
+			method:           http.MethodPost,
+			headers: map[string]string{
+				"Origin": "https://evil.com",
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedHandled:    true,
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin":      "",
+				"Access-Control-Allow-Credentials": "",
+			},
+		},
+		{
+			name:           "no origin allowed",
+			allowedOrigins: nil,
+			method:         http.MethodPost,
+			headers: map[string]string{
+				"Origin": "https://short-d.com",
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedHandled:    true,
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin": "",
+			},
+		},
+		{
+			name:           "preflight from allowed origin",
+			allowedOrigins: []string{"https://short-d.com"},
+			method:         http.MethodOptions,
+			headers: map[string]string{
+				"Origin":                         "https://short-d.com",
+				"Access-Control-Request-Method":  "POST",
+				"Access-Control-Request-Headers": "Content-Type",
+			},
+			expectedStatusCode: http.StatusNoContent,
+			expectedHandled:    false,
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin":  "https://short-d.com",
+				"Access-Control-Allow-Methods": "GET, POST",
+				"Access-Control-Allow-Headers": "Content-Type, Authorization",
+			},
+		},
+		{
+			name:           "preflight with disallowed method",
+			allowedOrigins: []string{"https://short-d.com"},
+			method:         http.MethodOptions,
+			headers: map[string]string{
+				"Origin":                        "https://short-d.com",
+				"Access-Control-Request-Method": "DELETE",
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedHandled:    false,
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Methods": "",
+			},
+		},
+		{
+			name:           "preflight from disallowed origin",
+			allowedOrigins: []string{"https://short-d.com"},
+			method:         http.MethodOptions,
+			headers: map[string]string{
+				"Origin":                        "https://evil.com",
+				"Access-Control-Request-Method": "POST",
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedHandled:    false,
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin":  "",
+				"Access-Control-Allow-Methods": "",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			policy, err := NewPolicy(
+				testCase.allowedOrigins,
+				[]string{"GET", "POST"},
+				[]string{"Content-Type", "Authorization"},
+				testCase.allowCredentials,
+			)
+			assert.Equal(t, nil, err)
+
+			isHandled := false
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				isHandled = true
+			})
+
+			req := httptest.NewRequest(testCase.method, "/graphql", nil)
+			for name, value := range testCase.headers {
+				req.Header.Set(name, value)
+			}
+			w := httptest.NewRecorder()
+
+			policy.Handler(next).ServeHTTP(w, req)
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedHandled, isHandled)
+			for name, value := range testCase.expectedHeaders {
+				assert.Equal(t, value, w.Header().Get(name))
+			}
+		})
+	}
+}
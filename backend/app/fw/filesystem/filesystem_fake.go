@@ -0,0 +1,24 @@
+package filesystem
+
+import "os"
+
+var _ FileSystem = (*FileSystemFake)(nil)
+
+// FileSystemFake represents an in memory file system used for testing.
+type FileSystemFake struct {
+	files map[string][]byte
+}
+
+// ReadFile reads the content of the given file from memory.
+func (f FileSystemFake) ReadFile(filepath string) ([]byte, error) {
+	content, ok := f.files[filepath]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return content, nil
+}
+
+// NewFileSystemFake creates FileSystemFake with the given files.
+func NewFileSystemFake(files map[string][]byte) FileSystemFake {
+	return FileSystemFake{files: files}
+}
@@ -0,0 +1,74 @@
+package ipallow
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/short-d/app/fw/network"
+	"github.com/short-d/app/fw/router"
+	"github.com/short-d/short/backend/app/fw/netutil"
+)
+
+// Policy restricts requests to the clients whose IP addresses fall within the
+// allowed IP addresses or CIDR ranges. The client IP addresses are resolved by
+// network, so that they are only taken from the forwarding headers set by the
+// trusted proxies. An empty allowlist allows every client.
+type Policy struct {
+	network   network.Network
+	allowlist []*net.IPNet
+}
+
+// IsAllowed decides whether the client sending the request is allowed.
+func (p Policy) IsAllowed(request *http.Request) bool {
+	if len(p.allowlist) == 0 {
+		return true
+	}
+	return p.IsIPAllowed(p.network.FromHTTP(request).ClientIP)
+}
+
+// IsIPAllowed decides whether the client with the given IP address, already
+// resolved from the trusted forwarding headers, is allowed.
+func (p Policy) IsIPAllowed(ip string) bool {
+	if len(p.allowlist) == 0 {
+		return true
+	}
+
+	clientIP := net.ParseIP(ip)
+	if clientIP == nil {
+		return false
+	}
+
+	for _, ipNet := range p.allowlist {
+		if ipNet.Contains(clientIP) {
+			return true
+		}
+	}
+	return false
+}
+
+// Handle rejects the requests from the clients outside of the allowlist with
+// 403 before they reach next.
+func (p Policy) Handle(next router.Handle) router.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params router.Params) {
+		if !p.IsAllowed(r) {
+			http.Error(w, "client IP not allowed", http.StatusForbidden)
+			return
+		}
+		next(w, r, params)
+	}
+}
+
+// NewPolicy creates Policy which allows the clients from the given IP
+// addresses or CIDR ranges.
+func NewPolicy(network network.Network, allowlist []string) (Policy, error) {
+	var ipNets []*net.IPNet
+	for _, entry := range allowlist {
+		ipNet, err := netutil.ParseIPNet(entry)
+		if err != nil {
+			return Policy{}, fmt.Errorf("invalid allowed IP: %w", err)
+		}
+		ipNets = append(ipNets, ipNet)
+	}
+	return Policy{network: network, allowlist: ipNets}, nil
+}
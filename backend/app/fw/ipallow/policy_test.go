@@ -0,0 +1,208 @@
+// +build !integration all
+
+package ipallow
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/router"
+	"github.com/short-d/short/backend/app/fw/proxy"
+)
+
+func TestNewPolicy(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name      string
+		allowlist []string
+		hasErr    bool
+	}{
+		{
+			name:      "empty allowlist",
+			allowlist: nil,
+		},
+		{
+			name:      "IP addresses and CIDR ranges",
+			allowlist: []string{"10.0.0.0/8", "192.0.2.1", "2001:db8::1", "fc00::/7"},
+		},
+		{
+			name:      "invalid IP address",
+			allowlist: []string{"10.0.0.300"},
+			hasErr:    true,
+		},
+		{
+			name:      "invalid CIDR range",
+			allowlist: []string{"10.0.0.0/33"},
+			hasErr:    true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			trusted, err := proxy.NewTrusted(nil)
+			assert.Equal(t, nil, err)
+
+			_, err = NewPolicy(trusted, testCase.allowlist)
+			if testCase.hasErr {
+				assert.NotEqual(t, nil, err)
+				return
+			}
+			assert.Equal(t, nil, err)
+		})
+	}
+}
+
+func TestPolicy_Handle(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name               string
+		allowlist          []string
+		remoteAddr         string
+		headers            map[string]string
+		expectedStatusCode int
+	}{
+		{
+			name:               "empty allowlist allows every client",
+			allowlist:          nil,
+			remoteAddr:         "203.0.113.7:51234",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "client IP in CIDR range",
+			allowlist:          []string{"203.0.113.0/24"},
+			remoteAddr:         "203.0.113.7:51234",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "client IP matches IP address",
+			allowlist:          []string{"2001:db8::1"},
+			remoteAddr:         "[2001:db8::1]:51234",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "client IP outside allowlist",
+			allowlist:          []string{"203.0.113.0/24"},
+			remoteAddr:         "198.51.100.9:51234",
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			name:       "allowed client behind trusted proxy",
+			allowlist:  []string{"203.0.113.0/24"},
+			remoteAddr: "10.0.0.2:51234",
+			headers: map[string]string{
+				"X-Forwarded-For": "203.0.113.7",
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:       "disallowed client behind trusted proxy",
+			allowlist:  []string{"10.0.0.0/8"},
+			remoteAddr: "10.0.0.2:51234",
+			headers: map[string]string{
+				"X-Forwarded-For": "198.51.100.9",
+			},
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			name:       "forwarded IP from untrusted peer ignored",
+			allowlist:  []string{"203.0.113.0/24"},
+			remoteAddr: "198.51.100.9:51234",
+			headers: map[string]string{
+				"X-Forwarded-For": "203.0.113.7",
+			},
+			expectedStatusCode: http.StatusForbidden,
+		},
+		{
+			name:       "spoofed forwarded IP behind trusted proxy ignored",
+			allowlist:  []string{"203.0.113.0/24"},
+			remoteAddr: "10.0.0.2:51234",
+			headers: map[string]string{
+				"X-Forwarded-For": "203.0.113.7, 198.51.100.9",
+			},
+			expectedStatusCode: http.StatusForbidden,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			trusted, err := proxy.NewTrusted([]string{"10.0.0.0/8"})
+			assert.Equal(t, nil, err)
+			policy, err := NewPolicy(trusted, testCase.allowlist)
+			assert.Equal(t, nil, err)
+
+			handle := policy.Handle(func(w http.ResponseWriter, r *http.Request, params router.Params) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/admin/debug/pprof/", nil)
+			r.RemoteAddr = testCase.remoteAddr
+			for key, value := range testCase.headers {
+				r.Header.Set(key, value)
+			}
+			w := httptest.NewRecorder()
+			handle(w, r, router.Params{})
+
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+		})
+	}
+}
+
+func TestPolicy_IsIPAllowed(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name            string
+		allowlist       []string
+		clientIP        string
+		expectedAllowed bool
+	}{
+		{
+			name:            "empty allowlist allows every client",
+			allowlist:       nil,
+			clientIP:        "",
+			expectedAllowed: true,
+		},
+		{
+			name:            "client IP in CIDR range",
+			allowlist:       []string{"203.0.113.0/24"},
+			clientIP:        "203.0.113.7",
+			expectedAllowed: true,
+		},
+		{
+			name:            "client IP outside allowlist",
+			allowlist:       []string{"203.0.113.0/24"},
+			clientIP:        "198.51.100.9",
+			expectedAllowed: false,
+		},
+		{
+			name:            "unknown client IP",
+			allowlist:       []string{"203.0.113.0/24"},
+			clientIP:        "",
+			expectedAllowed: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			trusted, err := proxy.NewTrusted(nil)
+			assert.Equal(t, nil, err)
+			policy, err := NewPolicy(trusted, testCase.allowlist)
+			assert.Equal(t, nil, err)
+
+			assert.Equal(t, testCase.expectedAllowed, policy.IsIPAllowed(testCase.clientIP))
+		})
+	}
+}
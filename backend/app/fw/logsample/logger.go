@@ -0,0 +1,77 @@
+package logsample
+
+import (
+	"sync/atomic"
+
+	"github.com/short-d/app/fw/logger"
+)
+
+// KeepAll keeps every log message.
+const KeepAll = 100
+
+// Logger keeps a representative fraction of high volume log messages, such as
+// the ones written for every redirect, so that they don't bury the important
+// lines. Errors are never sampled away.
+type Logger struct {
+	logger  logger.Logger
+	percent uint64
+	// count is shared by the copies of Logger so that messages logged by
+	// concurrent requests are sampled together.
+	count *uint64
+}
+
+// Warn logs the warning if it is sampled.
+func (l Logger) Warn(message string) {
+	if !l.isSampled() {
+		return
+	}
+	l.logger.Warn(message)
+}
+
+// Info logs the message if it is sampled.
+func (l Logger) Info(message string) {
+	if !l.isSampled() {
+		return
+	}
+	l.logger.Info(message)
+}
+
+// Debug logs the message if it is sampled.
+func (l Logger) Debug(message string) {
+	if !l.isSampled() {
+		return
+	}
+	l.logger.Debug(message)
+}
+
+// Error always logs the error.
+func (l Logger) Error(err error) {
+	l.logger.Error(err)
+}
+
+// isSampled keeps percent out of every 100 messages, spreading them evenly
+// instead of keeping the first ones.
+func (l Logger) isSampled() bool {
+	if l.percent >= KeepAll {
+		return true
+	}
+	n := atomic.AddUint64(l.count, 1)
+	return n*l.percent/KeepAll != (n-1)*l.percent/KeepAll
+}
+
+// NewLogger creates Logger which keeps the given percent of the messages below
+// error level. Percent is clamped between 0 and 100.
+func NewLogger(logger logger.Logger, percent int) Logger {
+	if percent < 0 {
+		percent = 0
+	}
+	if percent > KeepAll {
+		percent = KeepAll
+	}
+	var count uint64
+	return Logger{
+		logger:  logger,
+		percent: uint64(percent),
+		count:   &count,
+	}
+}
@@ -0,0 +1,96 @@
+// +build !integration all
+
+package logsample
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/logger"
+)
+
+func TestLogger_Info(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name            string
+		percent         int
+		messageCount    int
+		expectedEntries int
+	}{
+		{
+			name:            "keep all",
+			percent:         100,
+			messageCount:    20,
+			expectedEntries: 20,
+		},
+		{
+			name:            "keep a quarter",
+			percent:         25,
+			messageCount:    20,
+			expectedEntries: 5,
+		},
+		{
+			name:            "keep one percent",
+			percent:         1,
+			messageCount:    250,
+			expectedEntries: 2,
+		},
+		{
+			name:            "keep none",
+			percent:         0,
+			messageCount:    20,
+			expectedEntries: 0,
+		},
+		{
+			name:            "percent above 100",
+			percent:         150,
+			messageCount:    20,
+			expectedEntries: 20,
+		},
+		{
+			name:            "negative percent",
+			percent:         -10,
+			messageCount:    20,
+			expectedEntries: 0,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			entryRepo := logger.NewEntryRepoFake()
+			lg, err := logger.NewFake(logger.LogInfo, &entryRepo)
+			assert.Equal(t, nil, err)
+
+			sampledLogger := NewLogger(lg, testCase.percent)
+			for i := 0; i < testCase.messageCount; i++ {
+				sampledLogger.Info("HTTP: url=/r/google")
+			}
+			assert.Equal(t, testCase.expectedEntries, len(entryRepo.GetEntries()))
+		})
+	}
+}
+
+func TestLogger_Error(t *testing.T) {
+	t.Parallel()
+
+	entryRepo := logger.NewEntryRepoFake()
+	lg, err := logger.NewFake(logger.LogInfo, &entryRepo)
+	assert.Equal(t, nil, err)
+
+	sampledLogger := NewLogger(lg, 0)
+	for i := 0; i < 10; i++ {
+		sampledLogger.Info("HTTP: url=/r/google")
+		sampledLogger.Error(errors.New("short link not found"))
+	}
+
+	entries := entryRepo.GetEntries()
+	assert.Equal(t, 10, len(entries))
+	for _, entry := range entries {
+		assert.Equal(t, logger.LogError, entry.Level)
+	}
+}
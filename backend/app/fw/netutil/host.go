@@ -0,0 +1,20 @@
+package netutil
+
+import (
+	"net"
+	"strings"
+)
+
+// NormalizeHost lowercases the host and drops the default HTTP and HTTPS
+// ports, so that the equivalent hosts can be compared directly.
+func NormalizeHost(host string) string {
+	host = strings.ToLower(host)
+	hostname, port, err := net.SplitHostPort(host)
+	if err != nil {
+		return host
+	}
+	if port == "80" || port == "443" {
+		return hostname
+	}
+	return host
+}
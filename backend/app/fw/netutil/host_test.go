@@ -0,0 +1,49 @@
+// +build !integration all
+
+package netutil
+
+import (
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+)
+
+func TestNormalizeHost(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name         string
+		host         string
+		expectedHost string
+	}{
+		{
+			name:         "uppercase host",
+			host:         "S.Short-D.com",
+			expectedHost: "s.short-d.com",
+		},
+		{
+			name:         "default HTTP port",
+			host:         "s.short-d.com:80",
+			expectedHost: "s.short-d.com",
+		},
+		{
+			name:         "default HTTPS port",
+			host:         "s.short-d.com:443",
+			expectedHost: "s.short-d.com",
+		},
+		{
+			name:         "custom port",
+			host:         "s.short-d.com:8080",
+			expectedHost: "s.short-d.com:8080",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, testCase.expectedHost, NormalizeHost(testCase.host))
+		})
+	}
+}
@@ -0,0 +1,61 @@
+// +build !integration all
+
+package netutil
+
+import (
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+)
+
+func TestParseIPNet(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		entry         string
+		hasErr        bool
+		expectedIPNet string
+	}{
+		{
+			name:          "IPv4 address",
+			entry:         "192.0.2.1",
+			expectedIPNet: "192.0.2.1/32",
+		},
+		{
+			name:          "IPv6 address",
+			entry:         "2001:db8::1",
+			expectedIPNet: "2001:db8::1/128",
+		},
+		{
+			name:          "CIDR range",
+			entry:         "10.1.2.3/8",
+			expectedIPNet: "10.0.0.0/8",
+		},
+		{
+			name:   "invalid IP address",
+			entry:  "10.0.0.300",
+			hasErr: true,
+		},
+		{
+			name:   "invalid CIDR range",
+			entry:  "10.0.0.0/33",
+			hasErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			ipNet, err := ParseIPNet(testCase.entry)
+			if testCase.hasErr {
+				assert.NotEqual(t, nil, err)
+				return
+			}
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedIPNet, ipNet.String())
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package outbound
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrWaitTimeout represents no slot freed up for the outbound call to the host
+// before the wait timeout.
+type ErrWaitTimeout string
+
+func (e ErrWaitTimeout) Error() string {
+	return "timeout waiting for outbound HTTP slot: " + string(e)
+}
+
+// Limiter caps the number of simultaneous outbound HTTP calls shared by all
+// the clients using it, so that the upstreams and the file descriptors of the
+// service are not exhausted under load. A call holds its slot until the
+// response body is read to the end or closed. The zero value of Limiter
+// doesn't limit the calls.
+type Limiter struct {
+	slots       chan struct{}
+	waitTimeout time.Duration
+}
+
+// Transport limits the calls sent through next.
+func (l Limiter) Transport(next http.RoundTripper) http.RoundTripper {
+	return limitedTransport{next: next, limiter: l}
+}
+
+// acquire waits for a free slot until the wait timeout or the context is
+// done. The returned function frees the slot.
+func (l Limiter) acquire(ctx context.Context, host string) (func(), error) {
+	if l.slots == nil {
+		return func() {}, nil
+	}
+
+	release := func() { <-l.slots }
+	select {
+	case l.slots <- struct{}{}:
+		return release, nil
+	default:
+	}
+
+	var timeout <-chan time.Time
+	if l.waitTimeout > 0 {
+		timer := time.NewTimer(l.waitTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+		return release, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-timeout:
+		return nil, ErrWaitTimeout(host)
+	}
+}
+
+// NewLimiter creates Limiter which allows at most maxConcurrency simultaneous
+// calls, waiting up to waitTimeout for a free slot. Non-positive maxConcurrency
+// disables the limit and zero waitTimeout waits until a slot is freed.
+func NewLimiter(maxConcurrency int, waitTimeout time.Duration) Limiter {
+	if maxConcurrency <= 0 {
+		return Limiter{}
+	}
+	return Limiter{
+		slots:       make(chan struct{}, maxConcurrency),
+		waitTimeout: waitTimeout,
+	}
+}
+
+type limitedTransport struct {
+	next    http.RoundTripper
+	limiter Limiter
+}
+
+func (l limitedTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	release, err := l.limiter.acquire(request.Context(), request.URL.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := l.next.RoundTrip(request)
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	body := response.Body
+	if body == nil {
+		body = http.NoBody
+	}
+	response.Body = &releasingBody{ReadCloser: body, release: release}
+	return response, nil
+}
+
+// releasingBody frees the slot once, when the body is either read to the end
+// or closed, since some callers never close the body after reading it.
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+	once    sync.Once
+}
+
+func (r *releasingBody) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if err == io.EOF {
+		r.once.Do(r.release)
+	}
+	return n, err
+}
+
+func (r *releasingBody) Close() error {
+	err := r.ReadCloser.Close()
+	r.once.Do(r.release)
+	return err
+}
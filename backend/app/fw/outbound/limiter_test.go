@@ -0,0 +1,147 @@
+// +build !integration all
+
+package outbound
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+)
+
+// blockingTransport responds once unblock is closed.
+type blockingTransport struct {
+	started chan struct{}
+	unblock chan struct{}
+}
+
+func (b blockingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	b.started <- struct{}{}
+	<-b.unblock
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       ioutil.NopCloser(strings.NewReader("ok")),
+		Request:    request,
+	}, nil
+}
+
+func newBlockingTransport() blockingTransport {
+	return blockingTransport{
+		started: make(chan struct{}, 10),
+		unblock: make(chan struct{}),
+	}
+}
+
+func get(client http.Client) error {
+	response, err := client.Get("https://example.com")
+	if err != nil {
+		return err
+	}
+	_, err = ioutil.ReadAll(response.Body)
+	return err
+}
+
+func waitStarted(t *testing.T, transport blockingTransport, calls int) {
+	for idx := 0; idx < calls; idx++ {
+		select {
+		case <-transport.started:
+		case <-time.After(time.Second):
+			t.Fatalf("expect %d calls started, got %d", calls, idx)
+		}
+	}
+}
+
+func TestLimiter_BlocksUntilSlotFrees(t *testing.T) {
+	t.Parallel()
+
+	transport := newBlockingTransport()
+	limiter := NewLimiter(2, 0)
+	client := http.Client{Transport: limiter.Transport(transport)}
+
+	errs := make(chan error, 3)
+	for idx := 0; idx < 2; idx++ {
+		go func() { errs <- get(client) }()
+	}
+	waitStarted(t, transport, 2)
+
+	go func() { errs <- get(client) }()
+	select {
+	case <-transport.started:
+		t.Fatal("expect the third call to wait for a free slot")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(transport.unblock)
+	waitStarted(t, transport, 1)
+	for idx := 0; idx < 3; idx++ {
+		assert.Equal(t, nil, <-errs)
+	}
+}
+
+func TestLimiter_WaitTimeout(t *testing.T) {
+	t.Parallel()
+
+	transport := newBlockingTransport()
+	limiter := NewLimiter(1, 50*time.Millisecond)
+	client := http.Client{Transport: limiter.Transport(transport)}
+
+	errs := make(chan error, 1)
+	go func() { errs <- get(client) }()
+	waitStarted(t, transport, 1)
+
+	err := get(client)
+	var timeoutErr ErrWaitTimeout
+	assert.Equal(t, true, errors.As(err, &timeoutErr))
+
+	close(transport.unblock)
+	assert.Equal(t, nil, <-errs)
+
+	err = get(client)
+	assert.Equal(t, nil, err)
+}
+
+func TestLimiter_ContextCanceled(t *testing.T) {
+	t.Parallel()
+
+	transport := newBlockingTransport()
+	limiter := NewLimiter(1, 0)
+	client := http.Client{Transport: limiter.Transport(transport)}
+
+	errs := make(chan error, 1)
+	go func() { errs <- get(client) }()
+	waitStarted(t, transport, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://example.com", nil)
+	assert.Equal(t, nil, err)
+	_, err = client.Do(request)
+	assert.Equal(t, true, errors.Is(err, context.DeadlineExceeded))
+
+	close(transport.unblock)
+	assert.Equal(t, nil, <-errs)
+}
+
+func TestLimiter_Unlimited(t *testing.T) {
+	t.Parallel()
+
+	transport := newBlockingTransport()
+	limiter := NewLimiter(0, time.Millisecond)
+	client := http.Client{Transport: limiter.Transport(transport)}
+
+	errs := make(chan error, 5)
+	for idx := 0; idx < 5; idx++ {
+		go func() { errs <- get(client) }()
+	}
+	waitStarted(t, transport, 5)
+
+	close(transport.unblock)
+	for idx := 0; idx < 5; idx++ {
+		assert.Equal(t, nil, <-errs)
+	}
+}
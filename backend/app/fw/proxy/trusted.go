@@ -0,0 +1,115 @@
+package proxy
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/short-d/app/fw/network"
+	"github.com/short-d/short/backend/app/fw/netutil"
+)
+
+var _ network.Network = (*Trusted)(nil)
+
+// Trusted extracts client connection info from the forwarding headers only
+// when the request comes from one of the trusted proxies. Requests from any
+// other peer are described by the connection itself so that clients can't
+// spoof their IP addresses.
+type Trusted struct {
+	proxies []*net.IPNet
+}
+
+// FromHTTP retrieves the IP address of the client and the host and protocol
+// originally requested by the client.
+func (t Trusted) FromHTTP(request *http.Request) network.Connection {
+	if request == nil {
+		return network.Connection{}
+	}
+
+	peerIP := remoteIP(request.RemoteAddr)
+	protocol := "http"
+	if request.TLS != nil {
+		protocol = "https"
+	}
+	connection := network.Connection{
+		ClientIP:      peerIP,
+		RequestedHost: request.Host,
+		Protocol:      protocol,
+	}
+	if !t.isTrusted(peerIP) {
+		return connection
+	}
+
+	connection.ClientIP = t.forwardedClientIP(request, peerIP)
+	if host := request.Header.Get("X-Forwarded-Host"); host != "" {
+		connection.RequestedHost = host
+	}
+	if proto := request.Header.Get("X-Forwarded-Proto"); proto != "" {
+		connection.Protocol = proto
+	}
+	return connection
+}
+
+// forwardedClientIP walks X-Forwarded-For from the nearest hop and returns
+// the first address which is not a trusted proxy. Entries further away are
+// ignored because they can be forged by the client.
+func (t Trusted) forwardedClientIP(request *http.Request, peerIP string) string {
+	var hops []string
+	for _, header := range request.Header["X-Forwarded-For"] {
+		for _, hop := range strings.Split(header, ",") {
+			hops = append(hops, strings.TrimSpace(hop))
+		}
+	}
+
+	for idx := len(hops) - 1; idx >= 0; idx-- {
+		if net.ParseIP(hops[idx]) == nil {
+			break
+		}
+		if !t.isTrusted(hops[idx]) || idx == 0 {
+			return hops[idx]
+		}
+	}
+
+	realIP := strings.TrimSpace(request.Header.Get("X-Real-IP"))
+	if len(hops) == 0 && net.ParseIP(realIP) != nil {
+		return realIP
+	}
+	return peerIP
+}
+
+func (t Trusted) isTrusted(ip string) bool {
+	parsedIP := net.ParseIP(ip)
+	if parsedIP == nil {
+		return false
+	}
+
+	for _, proxy := range t.proxies {
+		if proxy.Contains(parsedIP) {
+			return true
+		}
+	}
+	return false
+}
+
+func remoteIP(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return remoteAddr
+	}
+	return host
+}
+
+// NewTrusted creates Trusted with the IP addresses or CIDR ranges of the
+// trusted proxies.
+func NewTrusted(proxies []string) (Trusted, error) {
+	var ipNets []*net.IPNet
+	for _, proxy := range proxies {
+		ipNet, err := netutil.ParseIPNet(proxy)
+		if err != nil {
+			return Trusted{}, fmt.Errorf("invalid trusted proxy: %w", err)
+		}
+		ipNets = append(ipNets, ipNet)
+	}
+	return Trusted{proxies: ipNets}, nil
+}
@@ -0,0 +1,184 @@
+// +build !integration all
+
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/network"
+)
+
+func TestNewTrusted(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		proxies []string
+		hasErr  bool
+	}{
+		{
+			name:    "no trusted proxy",
+			proxies: nil,
+		},
+		{
+			name:    "IP addresses and CIDR ranges",
+			proxies: []string{"10.0.0.0/8", "192.0.2.1", "2001:db8::1", "fc00::/7"},
+		},
+		{
+			name:    "invalid IP address",
+			proxies: []string{"10.0.0.300"},
+			hasErr:  true,
+		},
+		{
+			name:    "invalid CIDR range",
+			proxies: []string{"10.0.0.0/33"},
+			hasErr:  true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewTrusted(testCase.proxies)
+			if testCase.hasErr {
+				assert.NotEqual(t, nil, err)
+				return
+			}
+			assert.Equal(t, nil, err)
+		})
+	}
+}
+
+func TestTrusted_FromHTTP(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name               string
+		remoteAddr         string
+		headers            map[string][]string
+		expectedConnection network.Connection
+	}{
+		{
+			name:       "trusted proxy with forwarded chain",
+			remoteAddr: "10.0.0.2:52000",
+			headers: map[string][]string{
+				"X-Forwarded-For":   {"203.0.113.195, 10.0.0.3"},
+				"X-Forwarded-Host":  {"short-d.com"},
+				"X-Forwarded-Proto": {"https"},
+			},
+			expectedConnection: network.Connection{
+				ClientIP:      "203.0.113.195",
+				RequestedHost: "short-d.com",
+				Protocol:      "https",
+			},
+		},
+		{
+			name:       "trusted proxy with spoofed entries in forwarded chain",
+			remoteAddr: "10.0.0.2:52000",
+			headers: map[string][]string{
+				"X-Forwarded-For": {"1.1.1.1, 198.51.100.7", "203.0.113.195"},
+			},
+			expectedConnection: network.Connection{
+				ClientIP:      "203.0.113.195",
+				RequestedHost: "example.com",
+				Protocol:      "http",
+			},
+		},
+		{
+			name:       "trusted proxy chain only",
+			remoteAddr: "10.0.0.2:52000",
+			headers: map[string][]string{
+				"X-Forwarded-For": {"10.0.0.4, 10.0.0.3"},
+			},
+			expectedConnection: network.Connection{
+				ClientIP:      "10.0.0.4",
+				RequestedHost: "example.com",
+				Protocol:      "http",
+			},
+		},
+		{
+			name:       "trusted proxy with malformed forwarded chain",
+			remoteAddr: "10.0.0.2:52000",
+			headers: map[string][]string{
+				"X-Forwarded-For": {"203.0.113.195, unknown"},
+			},
+			expectedConnection: network.Connection{
+				ClientIP:      "10.0.0.2",
+				RequestedHost: "example.com",
+				Protocol:      "http",
+			},
+		},
+		{
+			name:       "trusted proxy with real IP",
+			remoteAddr: "192.0.2.1:52000",
+			headers: map[string][]string{
+				"X-Real-Ip": {"203.0.113.195"},
+			},
+			expectedConnection: network.Connection{
+				ClientIP:      "203.0.113.195",
+				RequestedHost: "example.com",
+				Protocol:      "http",
+			},
+		},
+		{
+			name:       "trusted IPv6 proxy",
+			remoteAddr: "[2001:db8::1]:52000",
+			headers: map[string][]string{
+				"X-Forwarded-For": {"2001:db8::beef"},
+			},
+			expectedConnection: network.Connection{
+				ClientIP:      "2001:db8::beef",
+				RequestedHost: "example.com",
+				Protocol:      "http",
+			},
+		},
+		{
+			name:       "untrusted peer with forwarded headers",
+			remoteAddr: "198.51.100.7:52000",
+			headers: map[string][]string{
+				"X-Forwarded-For":   {"203.0.113.195"},
+				"X-Real-Ip":         {"203.0.113.195"},
+				"X-Forwarded-Host":  {"evil.com"},
+				"X-Forwarded-Proto": {"https"},
+			},
+			expectedConnection: network.Connection{
+				ClientIP:      "198.51.100.7",
+				RequestedHost: "example.com",
+				Protocol:      "http",
+			},
+		},
+		{
+			name:       "untrusted peer without forwarded headers",
+			remoteAddr: "198.51.100.7:52000",
+			expectedConnection: network.Connection{
+				ClientIP:      "198.51.100.7",
+				RequestedHost: "example.com",
+				Protocol:      "http",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			trusted, err := NewTrusted([]string{"10.0.0.0/8", "192.0.2.1", "2001:db8::1"})
+			assert.Equal(t, nil, err)
+
+			req := httptest.NewRequest(http.MethodGet, "http://example.com/r/google", nil)
+			req.RemoteAddr = testCase.remoteAddr
+			for name, values := range testCase.headers {
+				req.Header[name] = values
+			}
+
+			connection := trusted.FromHTTP(req)
+			assert.Equal(t, testCase.expectedConnection, connection)
+		})
+	}
+}
@@ -0,0 +1,6 @@
+package ptr
+
+// Bool returns the address of a bool literal.
+func Bool(value bool) *bool {
+	return &value
+}
@@ -0,0 +1,79 @@
+package secheader
+
+import "net/http"
+
+// The constants are the defaults used when the policies are not configured.
+const (
+	// DefaultContentSecurityPolicy forbids embedding the pages in frames,
+	// plugins and base URL overrides without restricting the scripts and styles
+	// served by the API explorers and error pages.
+	DefaultContentSecurityPolicy = "frame-ancestors 'none'; object-src 'none'; base-uri 'none'"
+	// DefaultReferrerPolicy only shares the origin with other sites.
+	DefaultReferrerPolicy = "strict-origin-when-cross-origin"
+	// DefaultRedirectReferrerPolicy keeps the destinations of short links from
+	// seeing the short links as referrers.
+	DefaultRedirectReferrerPolicy = "no-referrer"
+)
+
+// Policy decides the security headers attached to every response.
+type Policy struct {
+	contentSecurityPolicy  string
+	referrerPolicy         string
+	redirectReferrerPolicy string
+}
+
+// Handler attaches the security headers to the responses of next. Redirects
+// use the redirect referrer policy instead, which browsers apply when
+// following them.
+func (p Policy) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		if p.contentSecurityPolicy != "" {
+			w.Header().Set("Content-Security-Policy", p.contentSecurityPolicy)
+		}
+		if p.referrerPolicy != "" {
+			w.Header().Set("Referrer-Policy", p.referrerPolicy)
+		}
+
+		next.ServeHTTP(redirectAwareWriter{
+			ResponseWriter: w,
+			referrerPolicy: p.redirectReferrerPolicy,
+		}, r)
+	})
+}
+
+// redirectAwareWriter replaces the referrer policy right before the status
+// code of a redirect is written.
+type redirectAwareWriter struct {
+	http.ResponseWriter
+	referrerPolicy string
+}
+
+func (w redirectAwareWriter) WriteHeader(statusCode int) {
+	isRedirect := statusCode >= 300 && statusCode < 400
+	if isRedirect && w.referrerPolicy != "" {
+		w.Header().Set("Referrer-Policy", w.referrerPolicy)
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+// NewPolicy creates security header policy. Empty policies fall back to the
+// defaults.
+func NewPolicy(
+	contentSecurityPolicy string,
+	referrerPolicy string,
+	redirectReferrerPolicy string,
+) Policy {
+	return Policy{
+		contentSecurityPolicy:  withDefault(contentSecurityPolicy, DefaultContentSecurityPolicy),
+		referrerPolicy:         withDefault(referrerPolicy, DefaultReferrerPolicy),
+		redirectReferrerPolicy: withDefault(redirectReferrerPolicy, DefaultRedirectReferrerPolicy),
+	}
+}
+
+func withDefault(value string, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
@@ -0,0 +1,83 @@
+// +build !integration all
+
+package secheader
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+)
+
+func TestPolicy_Handler(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name                          string
+		contentSecurityPolicy         string
+		referrerPolicy                string
+		redirectReferrerPolicy        string
+		handler                       http.HandlerFunc
+		expectedContentSecurityPolicy string
+		expectedReferrerPolicy        string
+	}{
+		{
+			name: "default policies",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("ok"))
+			},
+			expectedContentSecurityPolicy: DefaultContentSecurityPolicy,
+			expectedReferrerPolicy:        DefaultReferrerPolicy,
+		},
+		{
+			name:                   "configured policies",
+			contentSecurityPolicy:  "default-src 'self'",
+			referrerPolicy:         "same-origin",
+			redirectReferrerPolicy: "origin",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			},
+			expectedContentSecurityPolicy: "default-src 'self'",
+			expectedReferrerPolicy:        "same-origin",
+		},
+		{
+			name: "redirect hides short link from destination by default",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Redirect(w, r, "https://www.google.com", http.StatusSeeOther)
+			},
+			expectedContentSecurityPolicy: DefaultContentSecurityPolicy,
+			expectedReferrerPolicy:        "no-referrer",
+		},
+		{
+			name:                   "redirect with configured referrer policy",
+			redirectReferrerPolicy: "unsafe-url",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Redirect(w, r, "https://www.google.com", http.StatusSeeOther)
+			},
+			expectedContentSecurityPolicy: DefaultContentSecurityPolicy,
+			expectedReferrerPolicy:        "unsafe-url",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			policy := NewPolicy(
+				testCase.contentSecurityPolicy,
+				testCase.referrerPolicy,
+				testCase.redirectReferrerPolicy,
+			)
+			req := httptest.NewRequest(http.MethodGet, "/r/google", nil)
+			w := httptest.NewRecorder()
+
+			policy.Handler(testCase.handler).ServeHTTP(w, req)
+
+			assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+			assert.Equal(t, testCase.expectedContentSecurityPolicy, w.Header().Get("Content-Security-Policy"))
+			assert.Equal(t, testCase.expectedReferrerPolicy, w.Header().Get("Referrer-Policy"))
+		})
+	}
+}
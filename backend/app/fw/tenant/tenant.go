@@ -0,0 +1,86 @@
+package tenant
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/short-d/app/fw/router"
+	"github.com/short-d/short/backend/app/fw/netutil"
+)
+
+// Default is the tenant of the deployments hosting a single organization and
+// of the requests arriving on the hosts not assigned to any tenant.
+const Default = ""
+
+type tenantKey struct{}
+
+// NewContext attaches the tenant to the context so that the repositories only
+// access the short links and users of the tenant.
+func NewContext(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantID)
+}
+
+// FromContext retrieves the tenant attached to the context, falling back to
+// Default when there is none.
+func FromContext(ctx context.Context) string {
+	tenantID, _ := ctx.Value(tenantKey{}).(string)
+	return tenantID
+}
+
+// ErrInvalidTenantHost represents the tenant host not formatted as
+// host=tenant.
+type ErrInvalidTenantHost string
+
+func (e ErrInvalidTenantHost) Error() string {
+	return fmt.Sprintf("invalid tenant host: %s", string(e))
+}
+
+// Hosts decides the tenant of the requests by the host they arrive on, so that
+// each organization is served on its own domains. The zero value of Hosts
+// assigns every request to Default.
+type Hosts struct {
+	tenants map[string]string
+}
+
+// Resolve decides the tenant of the request.
+func (h Hosts) Resolve(r *http.Request) string {
+	tenantID, ok := h.tenants[netutil.NormalizeHost(r.Host)]
+	if !ok {
+		return Default
+	}
+	return tenantID
+}
+
+// Handle attaches the tenant of the request to its context before it reaches
+// next.
+func (h Hosts) Handle(next router.Handle) router.Handle {
+	return func(w http.ResponseWriter, r *http.Request, params router.Params) {
+		ctx := NewContext(r.Context(), h.Resolve(r))
+		next(w, r.WithContext(ctx), params)
+	}
+}
+
+// NewHosts creates Hosts from the entries formatted as host=tenant, such as
+// s.acme.com=acme.
+func NewHosts(entries []string) (Hosts, error) {
+	tenants := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			return Hosts{}, ErrInvalidTenantHost(entry)
+		}
+
+		host := netutil.NormalizeHost(strings.TrimSpace(parts[0]))
+		tenantID := strings.TrimSpace(parts[1])
+		if host == "" || tenantID == "" {
+			return Hosts{}, ErrInvalidTenantHost(entry)
+		}
+		if _, ok := tenants[host]; ok {
+			return Hosts{}, ErrInvalidTenantHost(entry)
+		}
+		tenants[host] = tenantID
+	}
+	return Hosts{tenants: tenants}, nil
+}
@@ -0,0 +1,116 @@
+// +build !integration all
+
+package tenant
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/router"
+)
+
+func TestFromContext(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, Default, FromContext(context.Background()))
+
+	ctx := NewContext(context.Background(), "acme")
+	assert.Equal(t, "acme", FromContext(ctx))
+}
+
+func TestHosts_Resolve(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name           string
+		requestURL     string
+		expectedTenant string
+	}{
+		{
+			name:           "tenant host",
+			requestURL:     "https://s.acme.com/r/promo",
+			expectedTenant: "acme",
+		},
+		{
+			name:           "tenant host in upper case with default port",
+			requestURL:     "https://S.ACME.com:443/r/promo",
+			expectedTenant: "acme",
+		},
+		{
+			name:           "tenant host with port",
+			requestURL:     "http://localhost:8080/r/promo",
+			expectedTenant: "local",
+		},
+		{
+			name:           "unknown host",
+			requestURL:     "https://s.short-d.com/r/promo",
+			expectedTenant: Default,
+		},
+	}
+
+	hosts, err := NewHosts([]string{"s.acme.com=acme", "localhost:8080 = local"})
+	assert.Equal(t, nil, err)
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, testCase.requestURL, nil)
+			assert.Equal(t, testCase.expectedTenant, hosts.Resolve(req))
+
+			var tenantID string
+			handle := hosts.Handle(func(w http.ResponseWriter, r *http.Request, params router.Params) {
+				tenantID = FromContext(r.Context())
+			})
+			handle(httptest.NewRecorder(), req, router.Params{})
+			assert.Equal(t, testCase.expectedTenant, tenantID)
+		})
+	}
+}
+
+func TestNewHosts(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		entries     []string
+		expectedErr error
+	}{
+		{
+			name: "no tenant host",
+		},
+		{
+			name:    "valid tenant hosts",
+			entries: []string{"s.acme.com=acme", "s.globex.com=globex"},
+		},
+		{
+			name:        "missing tenant",
+			entries:     []string{"s.acme.com"},
+			expectedErr: ErrInvalidTenantHost("s.acme.com"),
+		},
+		{
+			name:        "empty tenant",
+			entries:     []string{"s.acme.com= "},
+			expectedErr: ErrInvalidTenantHost("s.acme.com= "),
+		},
+		{
+			name:        "host assigned twice",
+			entries:     []string{"s.acme.com=acme", "S.acme.com=globex"},
+			expectedErr: ErrInvalidTenantHost("S.acme.com=globex"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewHosts(testCase.entries)
+			assert.Equal(t, testCase.expectedErr, err)
+		})
+	}
+}
@@ -0,0 +1,115 @@
+// Package timertest provides a controllable timer.Timer for tests.
+package timertest
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/short-d/app/fw/timer"
+)
+
+var _ timer.Timer = (*FakeTimer)(nil)
+
+type fakeTicker struct {
+	interval  time.Duration
+	nextTick  time.Time
+	operation func()
+	stop      chan bool
+}
+
+func (f fakeTicker) isStopped() bool {
+	select {
+	case <-f.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// FakeTimer represents a timer whose current time only changes when tests
+// advance it. Tickers created from FakeTimer fire synchronously while the time
+// advances past their next tick.
+type FakeTimer struct {
+	mutex   sync.Mutex
+	now     time.Time
+	tickers []*fakeTicker
+}
+
+// Now returns the current time of the fake timer.
+func (f *FakeTimer) Now() time.Time {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.now
+}
+
+// Ticker runs the operation every time the fake timer advances past another
+// interval. Closing the returned channel stops the ticker.
+func (f *FakeTimer) Ticker(interval time.Duration, operation func()) chan bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	ticker := &fakeTicker{
+		interval:  interval,
+		nextTick:  f.now.Add(interval),
+		operation: operation,
+		stop:      make(chan bool),
+	}
+	f.tickers = append(f.tickers, ticker)
+	return ticker.stop
+}
+
+// Advance moves the current time forward by the given duration, running the
+// tickers due in between in the order of their ticks.
+func (f *FakeTimer) Advance(duration time.Duration) {
+	f.mutex.Lock()
+	target := f.now.Add(duration)
+	for {
+		ticker := f.nextTicker(target)
+		if ticker == nil {
+			break
+		}
+		f.now = ticker.nextTick
+		ticker.nextTick = ticker.nextTick.Add(ticker.interval)
+
+		f.mutex.Unlock()
+		ticker.operation()
+		f.mutex.Lock()
+	}
+	f.now = target
+	f.mutex.Unlock()
+}
+
+// Set moves the current time to the given time without running any tickers.
+func (f *FakeTimer) Set(now time.Time) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.now = now
+	for _, ticker := range f.tickers {
+		ticker.nextTick = now.Add(ticker.interval)
+	}
+}
+
+func (f *FakeTimer) nextTicker(target time.Time) *fakeTicker {
+	var active []*fakeTicker
+	for _, ticker := range f.tickers {
+		if !ticker.isStopped() {
+			active = append(active, ticker)
+		}
+	}
+	f.tickers = active
+
+	sort.SliceStable(active, func(i, j int) bool {
+		return active[i].nextTick.Before(active[j].nextTick)
+	})
+	if len(active) == 0 || active[0].nextTick.After(target) {
+		return nil
+	}
+	return active[0]
+}
+
+// NewFakeTimer creates FakeTimer starting at the given time.
+func NewFakeTimer(now time.Time) *FakeTimer {
+	return &FakeTimer{now: now}
+}
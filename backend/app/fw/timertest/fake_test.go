@@ -0,0 +1,60 @@
+// +build !integration all
+
+package timertest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+)
+
+func TestFakeTimer_Advance(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2020, 5, 1, 10, 30, 0, 0, time.UTC)
+	fakeTimer := NewFakeTimer(start)
+	assert.Equal(t, start, fakeTimer.Now())
+
+	fakeTimer.Advance(90 * time.Minute)
+	assert.Equal(t, start.Add(90*time.Minute), fakeTimer.Now())
+
+	later := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	fakeTimer.Set(later)
+	assert.Equal(t, later, fakeTimer.Now())
+}
+
+func TestFakeTimer_Ticker(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2020, 5, 1, 10, 30, 0, 0, time.UTC)
+	fakeTimer := NewFakeTimer(start)
+
+	var ticks []string
+	stopMinutely := fakeTimer.Ticker(time.Minute, func() {
+		ticks = append(ticks, fakeTimer.Now().Format("15:04")+" minutely")
+	})
+	fakeTimer.Ticker(2*time.Minute, func() {
+		ticks = append(ticks, fakeTimer.Now().Format("15:04")+" every 2 minutes")
+	})
+
+	fakeTimer.Advance(30 * time.Second)
+	assert.Equal(t, 0, len(ticks))
+
+	fakeTimer.Advance(2 * time.Minute)
+	assert.Equal(t, []string{
+		"10:31 minutely",
+		"10:32 minutely",
+		"10:32 every 2 minutes",
+	}, ticks)
+	assert.Equal(t, start.Add(150*time.Second), fakeTimer.Now())
+
+	close(stopMinutely)
+	fakeTimer.Advance(2 * time.Minute)
+	assert.Equal(t, []string{
+		"10:31 minutely",
+		"10:32 minutely",
+		"10:32 every 2 minutes",
+		"10:34 every 2 minutes",
+	}, ticks)
+}
@@ -0,0 +1,85 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/short-d/app/fw/graphql"
+	"github.com/short-d/app/fw/logger"
+	"github.com/short-d/app/fw/service"
+	"github.com/short-d/short/backend/app/fw/cors"
+	"github.com/short-d/short/backend/app/fw/logsample"
+	"github.com/short-d/short/backend/app/fw/secheader"
+)
+
+var _ service.Service = (*GraphQL)(nil)
+
+// GraphQL serves GraphQL APIs and GraphiQL under the given CORS and security
+// header policies.
+type GraphQL struct {
+	logger      logger.Logger
+	graphQLPath string
+	webServer   *server
+	guiPath     string
+}
+
+// StartAsync starts serving GraphQL APIs at the given port without blocking.
+func (g GraphQL) StartAsync(port int) {
+	baseURL := fmt.Sprintf("http://localhost:%d", port)
+	defer g.logger.Info(fmt.Sprintf("You can explore the API at: %s%s", baseURL, g.guiPath))
+	msg := fmt.Sprintf("GraphQL service started at %s%s", baseURL, g.graphQLPath)
+	defer g.logger.Info(msg)
+
+	go func() {
+		err := g.webServer.listenAndServe(port)
+		if err != nil {
+			g.logger.Error(err)
+		}
+	}()
+}
+
+// Stop gracefully shuts down the service.
+func (g GraphQL) Stop() {
+	defer g.logger.Info("GraphQL service stopped")
+
+	err := g.webServer.shutdown()
+	if err != nil {
+		g.logger.Error(err)
+	}
+}
+
+// StartAndWait starts serving GraphQL APIs at the given port and blocks
+// forever.
+func (g GraphQL) StartAndWait(port int) {
+	g.StartAsync(port)
+	select {}
+}
+
+func serveWebUI(uiHTML string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(uiHTML))
+	}
+}
+
+// NewGraphQL creates GraphQL service which serves GraphQL APIs at graphQLPath.
+func NewGraphQL(
+	logger logger.Logger,
+	graphQLPath string,
+	handler graphql.Handler,
+	webUI graphql.WebUI,
+	corsPolicy cors.Policy,
+	headerPolicy secheader.Policy,
+	maxBodySize int64,
+) GraphQL {
+	webServer := newServer(logger, corsPolicy, headerPolicy, maxBodySize, logsample.KeepAll)
+	webServer.handle(graphQLPath, handler)
+	guiPath := "/"
+	webServer.handle(guiPath, serveWebUI(webUI.RenderHTML()))
+
+	return GraphQL{
+		logger:      logger,
+		graphQLPath: graphQLPath,
+		webServer:   &webServer,
+		guiPath:     guiPath,
+	}
+}
@@ -0,0 +1,102 @@
+package web
+
+import (
+	"fmt"
+
+	"github.com/short-d/app/fw/logger"
+	"github.com/short-d/app/fw/router"
+	"github.com/short-d/app/fw/service"
+	"github.com/short-d/short/backend/app/fw/cors"
+	"github.com/short-d/short/backend/app/fw/secheader"
+)
+
+var _ service.Service = (*Routing)(nil)
+
+// Worker runs in the background alongside the service which starts it.
+type Worker interface {
+	Start()
+	Stop()
+}
+
+// Routing serves HTTP APIs under the given CORS and security header policies.
+type Routing struct {
+	logger    logger.Logger
+	webServer *server
+	workers   []Worker
+}
+
+// StartAsync starts the workers and serves HTTP APIs at the given port without
+// blocking.
+func (r Routing) StartAsync(port int) {
+	for _, worker := range r.workers {
+		worker.Start()
+	}
+
+	defer r.logger.Info("You can explore the API using Insomnia: https://insomnia.rest")
+	msg := fmt.Sprintf("Routing service started at http://localhost:%d", port)
+	defer r.logger.Info(msg)
+
+	go func() {
+		err := r.webServer.listenAndServe(port)
+		if err != nil {
+			r.logger.Error(err)
+		}
+	}()
+}
+
+// Stop gracefully shuts down the service. The workers are stopped after the
+// in-flight requests finish so that none of their work is dropped.
+func (r Routing) Stop() {
+	defer r.logger.Info("Routing service stopped")
+
+	err := r.webServer.shutdown()
+	if err != nil {
+		r.logger.Error(err)
+	}
+
+	for _, worker := range r.workers {
+		worker.Stop()
+	}
+}
+
+// StartAndWait starts serving HTTP APIs at the given port and blocks forever.
+func (r Routing) StartAndWait(port int) {
+	r.StartAsync(port)
+	select {}
+}
+
+// NewRouting creates Routing service which serves the given routes, logging
+// logSamplePercent percent of the requests. The workers run as long as the
+// service.
+func NewRouting(
+	logger logger.Logger,
+	routes []router.Route,
+	corsPolicy cors.Policy,
+	headerPolicy secheader.Policy,
+	maxBodySize int64,
+	logSamplePercent int,
+	workers []Worker,
+) Routing {
+	httpRouter := router.NewHTTPHandler()
+
+	for _, route := range routes {
+		err := httpRouter.AddRoute(
+			route.Method,
+			route.MatchPrefix,
+			route.Path,
+			route.Handle,
+		)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	webServer := newServer(logger, corsPolicy, headerPolicy, maxBodySize, logSamplePercent)
+	webServer.handle("/", &httpRouter)
+
+	return Routing{
+		logger:    logger,
+		webServer: &webServer,
+		workers:   workers,
+	}
+}
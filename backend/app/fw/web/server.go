@@ -0,0 +1,101 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/short-d/app/fw/logger"
+	"github.com/short-d/short/backend/app/fw/cors"
+	"github.com/short-d/short/backend/app/fw/logsample"
+	"github.com/short-d/short/backend/app/fw/secheader"
+)
+
+// server serves HTTP requests under the given CORS and security header
+// policies. Requests with body larger than maxBodySize bytes are rejected with
+// 413 Payload Too Large unless maxBodySize is not positive. Only a sample of
+// the requests are logged, without their bodies since they may carry
+// credentials.
+type server struct {
+	mux          *http.ServeMux
+	httpServer   *http.Server
+	logger       logsample.Logger
+	corsPolicy   cors.Policy
+	headerPolicy secheader.Policy
+	maxBodySize  int64
+}
+
+func (s *server) listenAndServe(port int) error {
+	addr := fmt.Sprintf(":%d", port)
+
+	s.httpServer = &http.Server{Addr: addr, Handler: s.handler()}
+	err := s.httpServer.ListenAndServe()
+
+	if err == nil || err == http.ErrServerClosed {
+		return nil
+	}
+	return err
+}
+
+// handler attaches the security headers before CORS checks so that rejected
+// requests get them as well.
+func (s server) handler() http.Handler {
+	return s.headerPolicy.Handler(s.corsPolicy.Handler(s.mux))
+}
+
+func (s server) shutdown() error {
+	return s.httpServer.Shutdown(context.Background())
+}
+
+func (s server) handle(pattern string, handler http.Handler) {
+	s.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		body, isTooLarge, err := s.readBody(r.Body)
+		r.Body.Close()
+		if isTooLarge {
+			http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		s.logger.Info(fmt.Sprintf("HTTP: url=%s host=%s method=%s size=%d", r.URL, r.Host, r.Method, len(body)))
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// readBody reads at most one byte more than maxBodySize so that oversized
+// bodies are detected without trusting Content-Length.
+func (s server) readBody(body io.Reader) ([]byte, bool, error) {
+	if s.maxBodySize <= 0 {
+		buf, err := ioutil.ReadAll(body)
+		return buf, false, err
+	}
+
+	buf, err := ioutil.ReadAll(io.LimitReader(body, s.maxBodySize+1))
+	if int64(len(buf)) > s.maxBodySize {
+		return nil, true, nil
+	}
+	return buf, false, err
+}
+
+func newServer(
+	logger logger.Logger,
+	corsPolicy cors.Policy,
+	headerPolicy secheader.Policy,
+	maxBodySize int64,
+	logSamplePercent int,
+) server {
+	return server{
+		mux:          http.NewServeMux(),
+		logger:       logsample.NewLogger(logger, logSamplePercent),
+		corsPolicy:   corsPolicy,
+		headerPolicy: headerPolicy,
+		maxBodySize:  maxBodySize,
+	}
+}
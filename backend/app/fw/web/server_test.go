@@ -0,0 +1,188 @@
+// +build !integration all
+
+package web
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/logger"
+	"github.com/short-d/short/backend/app/fw/cors"
+	"github.com/short-d/short/backend/app/fw/logsample"
+	"github.com/short-d/short/backend/app/fw/secheader"
+)
+
+func TestServer_HandleMaxBodySize(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name               string
+		maxBodySize        int64
+		body               string
+		contentLength      int64
+		expectedStatusCode int
+		expectedHandled    bool
+	}{
+		{
+			name:               "body under limit",
+			maxBodySize:        10,
+			body:               "123456789",
+			contentLength:      9,
+			expectedStatusCode: http.StatusOK,
+			expectedHandled:    true,
+		},
+		{
+			name:               "body at limit",
+			maxBodySize:        10,
+			body:               "1234567890",
+			contentLength:      10,
+			expectedStatusCode: http.StatusOK,
+			expectedHandled:    true,
+		},
+		{
+			name:               "body over limit",
+			maxBodySize:        10,
+			body:               "12345678901",
+			contentLength:      11,
+			expectedStatusCode: http.StatusRequestEntityTooLarge,
+			expectedHandled:    false,
+		},
+		{
+			name:               "body over limit with understated content length",
+			maxBodySize:        10,
+			body:               "12345678901",
+			contentLength:      1,
+			expectedStatusCode: http.StatusRequestEntityTooLarge,
+			expectedHandled:    false,
+		},
+		{
+			name:               "limit disabled",
+			maxBodySize:        0,
+			body:               strings.Repeat("1", 1024),
+			contentLength:      1024,
+			expectedStatusCode: http.StatusOK,
+			expectedHandled:    true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			entryRepo := logger.NewEntryRepoFake()
+			lg, err := logger.NewFake(logger.LogOff, &entryRepo)
+			assert.Equal(t, nil, err)
+
+			var handledBody string
+			isHandled := false
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				isHandled = true
+				buf, err := ioutil.ReadAll(r.Body)
+				assert.Equal(t, nil, err)
+				handledBody = string(buf)
+			})
+
+			webServer := newServer(lg, cors.Policy{}, secheader.Policy{}, testCase.maxBodySize, logsample.KeepAll)
+			webServer.handle("/", handler)
+
+			req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(testCase.body))
+			req.ContentLength = testCase.contentLength
+			w := httptest.NewRecorder()
+
+			webServer.mux.ServeHTTP(w, req)
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, testCase.expectedHandled, isHandled)
+			if testCase.expectedHandled {
+				assert.Equal(t, testCase.body, handledBody)
+			}
+		})
+	}
+}
+
+func TestServer_HandlerSecurityHeaders(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name               string
+		origin             string
+		requestMethod      string
+		expectedStatusCode int
+	}{
+		{
+			name:               "allowed request",
+			origin:             "https://short-d.com",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "preflight rejected by CORS policy",
+			origin:             "https://evil.com",
+			requestMethod:      http.MethodPost,
+			expectedStatusCode: http.StatusForbidden,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			entryRepo := logger.NewEntryRepoFake()
+			lg, err := logger.NewFake(logger.LogOff, &entryRepo)
+			assert.Equal(t, nil, err)
+
+			corsPolicy, err := cors.NewPolicy([]string{"https://short-d.com"}, nil, nil, false)
+			assert.Equal(t, nil, err)
+			headerPolicy := secheader.NewPolicy("default-src 'self'", "same-origin", "")
+
+			webServer := newServer(lg, corsPolicy, headerPolicy, 0, logsample.KeepAll)
+			webServer.handle("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+			method := http.MethodGet
+			if testCase.requestMethod != "" {
+				method = http.MethodOptions
+			}
+			req := httptest.NewRequest(method, "/", nil)
+			req.Header.Set("Origin", testCase.origin)
+			if testCase.requestMethod != "" {
+				req.Header.Set("Access-Control-Request-Method", testCase.requestMethod)
+			}
+			w := httptest.NewRecorder()
+
+			webServer.handler().ServeHTTP(w, req)
+			assert.Equal(t, testCase.expectedStatusCode, w.Code)
+			assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+			assert.Equal(t, "default-src 'self'", w.Header().Get("Content-Security-Policy"))
+			assert.Equal(t, "same-origin", w.Header().Get("Referrer-Policy"))
+		})
+	}
+}
+
+func TestServer_HandleLogging(t *testing.T) {
+	t.Parallel()
+
+	entryRepo := logger.NewEntryRepoFake()
+	lg, err := logger.NewFake(logger.LogInfo, &entryRepo)
+	assert.Equal(t, nil, err)
+
+	webServer := newServer(lg, cors.Policy{}, secheader.Policy{}, 0, logsample.KeepAll)
+	webServer.handle("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	body := `{"email":"alpha@example.com","password":"s3cr3t-passw0rd"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/sign-in", strings.NewReader(body))
+	w := httptest.NewRecorder()
+
+	webServer.mux.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	entries := entryRepo.GetEntries()
+	assert.Equal(t, 1, len(entries))
+	for _, entry := range entries {
+		assert.Equal(t, false, strings.Contains(entry.Message, "s3cr3t-passw0rd"))
+		assert.Equal(t, false, strings.Contains(entry.Message, "alpha@example.com"))
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/short-d/app/fw/crypto"
 	"github.com/short-d/app/fw/timer"
 	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/repository"
 )
 
 // Authenticator securely authenticates an user's identity.
@@ -14,6 +15,7 @@ type Authenticator struct {
 	tokenizer          crypto.Tokenizer
 	timer              timer.Timer
 	tokenValidDuration time.Duration
+	userRepo           repository.User
 }
 
 func (a Authenticator) isTokenValid(payload Payload, validDuring time.Duration) bool {
@@ -25,6 +27,20 @@ func (a Authenticator) isTokenValid(payload Payload, validDuring time.Duration)
 	return !tokenExpireAt.Before(now)
 }
 
+// isTokenRevoked checks whether the token is issued before the tokens of the
+// user are revoked, such as when the password is reset.
+func (a Authenticator) isTokenRevoked(payload Payload) (bool, error) {
+	revokedAt, err := a.userRepo.GetTokensRevokedAt(payload.id)
+	var errNotFound repository.ErrEntryNotFound
+	if errors.As(err, &errNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return payload.issuedAt.Before(revokedAt), nil
+}
+
 func (a Authenticator) getPayload(token string) (Payload, error) {
 	tokenPayload, err := a.tokenizer.Decode(token)
 	if err != nil {
@@ -45,7 +61,12 @@ func (a Authenticator) IsSignedIn(token string) bool {
 		return false
 	}
 
-	return a.isTokenValid(payload, a.tokenValidDuration)
+	if !a.isTokenValid(payload, a.tokenValidDuration) {
+		return false
+	}
+
+	isRevoked, err := a.isTokenRevoked(payload)
+	return err == nil && !isRevoked
 }
 
 // GetUser decodes authentication token to user data
@@ -62,15 +83,24 @@ func (a Authenticator) GetUser(token string) (entity.User, error) {
 	if len(payload.id) < 1 {
 		return entity.User{}, errors.New("id can't be empty")
 	}
+
+	isRevoked, err := a.isTokenRevoked(payload)
+	if err != nil {
+		return entity.User{}, err
+	}
+	if isRevoked {
+		return entity.User{}, errors.New("token revoked")
+	}
 	return entity.User{
-		ID: payload.id,
+		TenantID: payload.tenantID,
+		ID:       payload.id,
 	}, nil
 }
 
 // GenerateToken encodes part of user data into authentication token
 func (a Authenticator) GenerateToken(user entity.User) (string, error) {
 	issuedAt := a.timer.Now()
-	payload := newPayload(user.ID, issuedAt)
+	payload := newPayload(user.ID, user.TenantID, issuedAt)
 	tokenPayload := payload.TokenPayload()
 	return a.tokenizer.Encode(tokenPayload)
 }
@@ -80,10 +110,12 @@ func NewAuthenticator(
 	tokenizer crypto.Tokenizer,
 	timer timer.Timer,
 	tokenValidDuration time.Duration,
+	userRepo repository.User,
 ) Authenticator {
 	return Authenticator{
 		tokenizer:          tokenizer,
 		timer:              timer,
 		tokenValidDuration: tokenValidDuration,
+		userRepo:           userRepo,
 	}
 }
@@ -5,11 +5,13 @@ import (
 
 	"github.com/short-d/app/fw/crypto"
 	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/usecase/repository"
 )
 
 // NewAuthenticatorFake creates fake authenticator for easy testing.
 func NewAuthenticatorFake(current time.Time, validPeriod time.Duration) Authenticator {
 	tokenizer := crypto.NewTokenizerFake()
 	tm := timer.NewStub(current)
-	return NewAuthenticator(tokenizer, tm, validPeriod)
+	userRepo := repository.NewUserFake(nil)
+	return NewAuthenticator(tokenizer, tm, validPeriod, &userRepo)
 }
@@ -10,6 +10,8 @@ import (
 	"github.com/short-d/app/fw/crypto"
 	"github.com/short-d/app/fw/timer"
 	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/fw/timertest"
+	"github.com/short-d/short/backend/app/usecase/repository"
 )
 
 func TestAuthenticator_GenerateToken(t *testing.T) {
@@ -17,10 +19,12 @@ func TestAuthenticator_GenerateToken(t *testing.T) {
 	tokenizer := crypto.NewTokenizerFake()
 	expIssuedAt := time.Now()
 	tm := timer.NewStub(expIssuedAt)
-	authenticator := NewAuthenticator(tokenizer, tm, 2*time.Millisecond)
+	userRepo := repository.NewUserFake(nil)
+	authenticator := NewAuthenticator(tokenizer, tm, 2*time.Millisecond, &userRepo)
 
 	expUser := entity.User{
-		ID: "alpha",
+		TenantID: "acme",
+		ID:       "alpha",
 	}
 	token, err := authenticator.GenerateToken(expUser)
 	assert.Equal(t, nil, err)
@@ -29,6 +33,7 @@ func TestAuthenticator_GenerateToken(t *testing.T) {
 	assert.Equal(t, nil, err)
 
 	assert.Equal(t, expUser.ID, tokenPayload["id"])
+	assert.Equal(t, expUser.TenantID, tokenPayload["tenant_id"])
 
 	expIssuedAtStr := expIssuedAt.Format(time.RFC3339Nano)
 	assert.Equal(t, expIssuedAtStr, tokenPayload["issued_at"])
@@ -115,7 +120,8 @@ func TestAuthenticator_IsSignedIn(t *testing.T) {
 			t.Parallel()
 			tokenizer := crypto.NewTokenizerFake()
 			tm := timer.NewStub(testCase.currentTime)
-			authenticator := NewAuthenticator(tokenizer, tm, testCase.tokenValidDuration)
+			userRepo := repository.NewUserFake(nil)
+			authenticator := NewAuthenticator(tokenizer, tm, testCase.tokenValidDuration, &userRepo)
 
 			token, err := tokenizer.Encode(testCase.tokenPayload)
 			assert.Equal(t, nil, err)
@@ -134,6 +140,7 @@ func TestAuthenticator_GetUser(t *testing.T) {
 		expIssuedAt        time.Time
 		tokenValidDuration time.Duration
 		currentTime        time.Time
+		tokensRevokedAt    time.Time
 		tokenPayload       crypto.TokenPayload
 		hasErr             bool
 		expUser            entity.User
@@ -193,6 +200,34 @@ func TestAuthenticator_GetUser(t *testing.T) {
 			hasErr:  true,
 			expUser: entity.User{},
 		},
+		{
+			name:               "Token revoked",
+			expIssuedAt:        now,
+			tokenValidDuration: time.Hour,
+			currentTime:        now.Add(30 * time.Minute),
+			tokensRevokedAt:    now.Add(10 * time.Minute),
+			tokenPayload: map[string]interface{}{
+				"id":        "alpha",
+				"issued_at": now.Format(time.RFC3339Nano),
+			},
+			hasErr:  true,
+			expUser: entity.User{},
+		},
+		{
+			name:               "Token issued after revoked",
+			expIssuedAt:        now,
+			tokenValidDuration: time.Hour,
+			currentTime:        now.Add(30 * time.Minute),
+			tokensRevokedAt:    now.Add(-10 * time.Minute),
+			tokenPayload: map[string]interface{}{
+				"id":        "alpha",
+				"issued_at": now.Format(time.RFC3339Nano),
+			},
+			hasErr: false,
+			expUser: entity.User{
+				ID: "alpha",
+			},
+		},
 		{
 			name:               "Token valid with correct ID",
 			expIssuedAt:        now,
@@ -207,6 +242,35 @@ func TestAuthenticator_GetUser(t *testing.T) {
 				ID: "alpha",
 			},
 		},
+		{
+			name:               "Token valid with tenant",
+			expIssuedAt:        now,
+			tokenValidDuration: time.Hour,
+			currentTime:        now.Add(30 * time.Minute),
+			tokenPayload: map[string]interface{}{
+				"id":        "alpha",
+				"tenant_id": "acme",
+				"issued_at": now.Format(time.RFC3339Nano),
+			},
+			hasErr: false,
+			expUser: entity.User{
+				TenantID: "acme",
+				ID:       "alpha",
+			},
+		},
+		{
+			name:               "Token with invalid tenant",
+			expIssuedAt:        now,
+			tokenValidDuration: time.Hour,
+			currentTime:        now.Add(30 * time.Minute),
+			tokenPayload: map[string]interface{}{
+				"id":        "alpha",
+				"tenant_id": 1,
+				"issued_at": now.Format(time.RFC3339Nano),
+			},
+			hasErr:  true,
+			expUser: entity.User{},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -215,7 +279,12 @@ func TestAuthenticator_GetUser(t *testing.T) {
 			t.Parallel()
 			tokenizer := crypto.NewTokenizerFake()
 			tm := timer.NewStub(testCase.currentTime)
-			authenticator := NewAuthenticator(tokenizer, tm, testCase.tokenValidDuration)
+			userRepo := repository.NewUserFake([]entity.User{{ID: "alpha"}})
+			if !testCase.tokensRevokedAt.IsZero() {
+				err := userRepo.RevokeTokens("alpha", testCase.tokensRevokedAt)
+				assert.Equal(t, nil, err)
+			}
+			authenticator := NewAuthenticator(tokenizer, tm, testCase.tokenValidDuration, &userRepo)
 
 			token, err := tokenizer.Encode(testCase.tokenPayload)
 			assert.Equal(t, nil, err)
@@ -228,3 +297,31 @@ func TestAuthenticator_GetUser(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthenticator_TokenExpiry(t *testing.T) {
+	t.Parallel()
+
+	issuedAt := time.Date(2020, 5, 1, 10, 30, 0, 0, time.UTC)
+	tm := timertest.NewFakeTimer(issuedAt)
+	tokenizer := crypto.NewTokenizerFake()
+	userRepo := repository.NewUserFake([]entity.User{{ID: "alpha"}})
+	authenticator := NewAuthenticator(tokenizer, tm, time.Hour, &userRepo)
+
+	token, err := authenticator.GenerateToken(entity.User{ID: "alpha"})
+	assert.Equal(t, nil, err)
+
+	tm.Advance(time.Hour)
+	assert.Equal(t, true, authenticator.IsSignedIn(token))
+	user, err := authenticator.GetUser(token)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, entity.User{ID: "alpha"}, user)
+
+	tm.Advance(time.Second)
+	assert.Equal(t, false, authenticator.IsSignedIn(token))
+	_, err = authenticator.GetUser(token)
+	assert.NotEqual(t, nil, err)
+
+	token, err = authenticator.GenerateToken(entity.User{ID: "alpha"})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, authenticator.IsSignedIn(token))
+}
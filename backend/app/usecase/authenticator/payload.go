@@ -5,25 +5,34 @@ import (
 	"time"
 
 	"github.com/short-d/app/fw/crypto"
+	"github.com/short-d/short/backend/app/fw/tenant"
 )
 
 // Payload represents the metadata encoded in the authentication token.
 type Payload struct {
 	id       string
+	tenantID string
 	issuedAt time.Time
 }
 
-// TokenPayload retrieves key-value pairs representation of the payload.
+// TokenPayload retrieves key-value pairs representation of the payload. The
+// tenant is left out for the default tenant so that the tokens stay the same
+// on the deployments hosting a single organization.
 func (p Payload) TokenPayload() crypto.TokenPayload {
-	return map[string]interface{}{
+	tokenPayload := map[string]interface{}{
 		"id":        p.id,
 		"issued_at": p.issuedAt,
 	}
+	if p.tenantID != tenant.Default {
+		tokenPayload["tenant_id"] = p.tenantID
+	}
+	return tokenPayload
 }
 
-func newPayload(id string, issuedAt time.Time) Payload {
+func newPayload(id string, tenantID string, issuedAt time.Time) Payload {
 	return Payload{
 		id:       id,
+		tenantID: tenantID,
 		issuedAt: issuedAt,
 	}
 }
@@ -37,6 +46,14 @@ func fromTokenPayload(tokenPayload crypto.TokenPayload) (Payload, error) {
 		return payload, errors.New("expect payload to contain id")
 	}
 
+	// The tokens issued before multi-tenancy belong to the default tenant.
+	tenantID, ok := tokenPayload["tenant_id"]
+	if ok {
+		if payload.tenantID, ok = tenantID.(string); !ok {
+			return payload, errors.New("expect tenant_id to be string")
+		}
+	}
+
 	issuedAtJSON := tokenPayload["issued_at"]
 	var issuedAtStr string
 	if issuedAtStr, ok = issuedAtJSON.(string); !ok {
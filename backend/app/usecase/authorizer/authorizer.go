@@ -37,6 +37,48 @@ func (a Authorizer) CanViewAdminPanel(user entity.User) (bool, error) {
 	return a.rbac.HasPermission(user, permission.ViewAdminPanel)
 }
 
+// CanReloadDomainDenylist decides whether a user is allowed to reload the
+// denied domains.
+func (a Authorizer) CanReloadDomainDenylist(user entity.User) (bool, error) {
+	return a.rbac.HasPermission(user, permission.ReloadDomainDenylist)
+}
+
+// CanBypassAliasQuota decides whether a user is allowed to own more short links
+// than the alias quota.
+func (a Authorizer) CanBypassAliasQuota(user entity.User) (bool, error) {
+	return a.rbac.HasPermission(user, permission.BypassAliasQuota)
+}
+
+// CanExpireShortLink decides whether a user is allowed to expire any short
+// link immediately.
+func (a Authorizer) CanExpireShortLink(user entity.User) (bool, error) {
+	return a.rbac.HasPermission(user, permission.ExpireShortLink)
+}
+
+// CanSwitchReadOnlyMode decides whether a user is allowed to turn read-only
+// mode on or off.
+func (a Authorizer) CanSwitchReadOnlyMode(user entity.User) (bool, error) {
+	return a.rbac.HasPermission(user, permission.SwitchReadOnlyMode)
+}
+
+// CanViewProfile decides whether a user is allowed to view the runtime
+// profiles of the service.
+func (a Authorizer) CanViewProfile(user entity.User) (bool, error) {
+	return a.rbac.HasPermission(user, permission.ViewProfile)
+}
+
+// CanViewServiceStats decides whether a user is allowed to view the
+// statistics of the whole service.
+func (a Authorizer) CanViewServiceStats(user entity.User) (bool, error) {
+	return a.rbac.HasPermission(user, permission.ViewServiceStats)
+}
+
+// CanOverrideFeatureFlag decides whether a user is allowed to turn features
+// on or off at runtime.
+func (a Authorizer) CanOverrideFeatureFlag(user entity.User) (bool, error) {
+	return a.rbac.HasPermission(user, permission.OverrideFeatureFlag)
+}
+
 // NewAuthorizer creates a new Authorizer object
 func NewAuthorizer(rbac rbac.RBAC) Authorizer {
 	return Authorizer{rbac: rbac}
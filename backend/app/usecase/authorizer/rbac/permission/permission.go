@@ -9,6 +9,7 @@ const (
 	EditShortLink
 	DisableShortLink
 	DeleteShortLink
+	ExpireShortLink
 
 	CreateChange
 	ViewChange
@@ -21,4 +22,12 @@ const (
 	DeleteUser
 
 	ViewAdminPanel
+
+	ReloadDomainDenylist
+	SwitchReadOnlyMode
+	ViewProfile
+	ViewServiceStats
+	OverrideFeatureFlag
+
+	BypassAliasQuota
 )
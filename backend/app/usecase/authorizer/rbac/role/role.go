@@ -52,6 +52,8 @@ var permissions = map[Role][]permission.Permission{
 		permission.DisableShortLink,
 		permission.DisableUser,
 
+		permission.ReloadDomainDenylist,
+
 		permission.ViewAdminPanel,
 	},
 	Admin: {
@@ -60,6 +62,7 @@ var permissions = map[Role][]permission.Permission{
 		permission.EditShortLink,
 		permission.DisableShortLink,
 		permission.DeleteShortLink,
+		permission.ExpireShortLink,
 
 		permission.ViewChange,
 		permission.CreateChange,
@@ -71,6 +74,14 @@ var permissions = map[Role][]permission.Permission{
 		permission.DisableUser,
 		permission.DeleteUser,
 
+		permission.ReloadDomainDenylist,
+		permission.SwitchReadOnlyMode,
+		permission.ViewProfile,
+		permission.ViewServiceStats,
+		permission.OverrideFeatureFlag,
+
+		permission.BypassAliasQuota,
+
 		permission.ViewAdminPanel,
 	},
 }
@@ -84,3 +95,9 @@ func (r Role) HasPermission(permission permission.Permission) bool {
 	}
 	return false
 }
+
+// IsDefined checks whether the role is one of the roles above.
+func (r Role) IsDefined() bool {
+	_, ok := permissions[r]
+	return ok
+}
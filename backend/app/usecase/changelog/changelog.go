@@ -23,7 +23,7 @@ type ErrUnauthorizedAction struct {
 var _ error = (*ErrUnauthorizedAction)(nil)
 
 func (e ErrUnauthorizedAction) Error() string {
-	return fmt.Sprintf("user %s is not allowed to %s", e.user, e.action)
+	return fmt.Sprintf("user %s is not allowed to %s", e.user.ID, e.action)
 }
 
 // ChangeLog retrieves change log and create changes.
@@ -192,7 +192,7 @@ func TestPersist_CreateChange(t *testing.T) {
 
 			changeLogRepo := repository.NewChangeLogFake(testCase.changeLog)
 			keyFetcher := keygen.NewKeyFetcherFake(testCase.availableKeys)
-			keyGen, err := keygen.NewKeyGenerator(2, &keyFetcher)
+			keyGen, err := keygen.NewRemote(2, &keyFetcher)
 			assert.Equal(t, nil, err)
 
 			fakeRolesRepo := repository.NewUserRoleFake(testCase.roles)
@@ -280,7 +280,7 @@ func TestPersist_GetChangeLog(t *testing.T) {
 
 			changeLogRepo := repository.NewChangeLogFake(testCase.changeLog)
 			keyFetcher := keygen.NewKeyFetcherFake(testCase.availableKeys)
-			keyGen, err := keygen.NewKeyGenerator(2, &keyFetcher)
+			keyGen, err := keygen.NewRemote(2, &keyFetcher)
 			assert.Equal(t, nil, err)
 
 			fakeRolesRepo := repository.NewUserRoleFake(testCase.roles)
@@ -351,7 +351,7 @@ func TestPersist_GetLastViewedAt(t *testing.T) {
 
 			changeLogRepo := repository.NewChangeLogFake([]entity.Change{})
 			keyFetcher := keygen.NewKeyFetcherFake([]keygen.Key{})
-			keyGen, err := keygen.NewKeyGenerator(2, &keyFetcher)
+			keyGen, err := keygen.NewRemote(2, &keyFetcher)
 			assert.Equal(t, nil, err)
 
 			tm := timer.NewStub(now)
@@ -423,7 +423,7 @@ func TestPersist_ViewChangeLog(t *testing.T) {
 
 			changeLogRepo := repository.NewChangeLogFake([]entity.Change{})
 			keyFetcher := keygen.NewKeyFetcherFake([]keygen.Key{})
-			keyGen, err := keygen.NewKeyGenerator(2, &keyFetcher)
+			keyGen, err := keygen.NewRemote(2, &keyFetcher)
 			assert.Equal(t, nil, err)
 
 			tm := timer.NewStub(now)
@@ -530,7 +530,7 @@ func TestPersist_GetAllChanges(t *testing.T) {
 
 			changeLogRepo := repository.NewChangeLogFake(testCase.changes)
 			keyFetcher := keygen.NewKeyFetcherFake(testCase.availableKeys)
-			keyGen, err := keygen.NewKeyGenerator(2, &keyFetcher)
+			keyGen, err := keygen.NewRemote(2, &keyFetcher)
 			assert.Equal(t, nil, err)
 
 			fakeRolesRepo := repository.NewUserRoleFake(testCase.roles)
@@ -673,7 +673,7 @@ func TestPersist_DeleteChange(t *testing.T) {
 
 			changeLogRepo := repository.NewChangeLogFake(testCase.changeLog)
 			keyFetcher := keygen.NewKeyFetcherFake([]keygen.Key{})
-			keyGen, err := keygen.NewKeyGenerator(2, &keyFetcher)
+			keyGen, err := keygen.NewRemote(2, &keyFetcher)
 			assert.Equal(t, nil, err)
 
 			fakeRolesRepo := repository.NewUserRoleFake(testCase.roles)
@@ -834,7 +834,7 @@ func TestPersist_UpdateChange(t *testing.T) {
 
 			changeLogRepo := repository.NewChangeLogFake(testCase.changeLog)
 			keyFetcher := keygen.NewKeyFetcherFake([]keygen.Key{})
-			keyGen, err := keygen.NewKeyGenerator(2, &keyFetcher)
+			keyGen, err := keygen.NewRemote(2, &keyFetcher)
 			assert.Equal(t, nil, err)
 
 			fakeRolesRepo := repository.NewUserRoleFake(testCase.roles)
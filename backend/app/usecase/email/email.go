@@ -0,0 +1,7 @@
+package email
+
+// Sender delivers plain text emails, such as notifications to the owners of
+// short links.
+type Sender interface {
+	Send(to string, subject string, body string) error
+}
@@ -0,0 +1,61 @@
+package email
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/short-d/app/fw/logger"
+)
+
+var _ Sender = (*Retry)(nil)
+
+// Retry resends failed emails with exponential backoff. The last failure is
+// logged once all attempts are used up.
+type Retry struct {
+	sender      Sender
+	logger      logger.Logger
+	maxAttempts int
+	backoff     time.Duration
+	sleep       func(duration time.Duration)
+}
+
+// Send makes at most maxAttempts attempts to deliver the email, doubling the
+// wait between consecutive attempts.
+func (r Retry) Send(to string, subject string, body string) error {
+	backoff := r.backoff
+
+	var err error
+	for attempt := 1; attempt <= r.maxAttempts; attempt++ {
+		err = r.sender.Send(to, subject, body)
+		if err == nil {
+			return nil
+		}
+		if attempt < r.maxAttempts {
+			r.sleep(backoff)
+			backoff *= 2
+		}
+	}
+
+	r.logger.Error(fmt.Errorf("fail to send email %q after %d attempts: %v", subject, r.maxAttempts, err))
+	return err
+}
+
+// NewRetry creates Retry. The email is sent once when maxAttempts is not
+// positive.
+func NewRetry(
+	sender Sender,
+	logger logger.Logger,
+	maxAttempts int,
+	backoff time.Duration,
+) Retry {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	return Retry{
+		sender:      sender,
+		logger:      logger,
+		maxAttempts: maxAttempts,
+		backoff:     backoff,
+		sleep:       time.Sleep,
+	}
+}
@@ -0,0 +1,91 @@
+// +build !integration all
+
+package email
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/logger"
+)
+
+func TestRetry_Send(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name             string
+		errs             []error
+		maxAttempts      int
+		expectedErr      error
+		expectedMessages []Message
+		expectedSleeps   []time.Duration
+		expectedLogs     int
+	}{
+		{
+			name:        "sent on first attempt",
+			errs:        []error{},
+			maxAttempts: 3,
+			expectedMessages: []Message{
+				{To: "alpha@example.com", Subject: "Hello", Body: "Hi there"},
+			},
+			expectedSleeps: []time.Duration{},
+		},
+		{
+			name:        "sent after retries",
+			errs:        []error{errors.New("timeout"), errors.New("timeout")},
+			maxAttempts: 3,
+			expectedMessages: []Message{
+				{To: "alpha@example.com", Subject: "Hello", Body: "Hi there"},
+			},
+			expectedSleeps: []time.Duration{time.Second, 2 * time.Second},
+		},
+		{
+			name: "failure logged after all attempts",
+			errs: []error{
+				errors.New("timeout"),
+				errors.New("timeout"),
+				errors.New("connection refused"),
+			},
+			maxAttempts:      3,
+			expectedErr:      errors.New("connection refused"),
+			expectedMessages: []Message{},
+			expectedSleeps:   []time.Duration{time.Second, 2 * time.Second},
+			expectedLogs:     1,
+		},
+		{
+			name:             "sent once without attempts configured",
+			errs:             []error{errors.New("timeout")},
+			maxAttempts:      0,
+			expectedErr:      errors.New("timeout"),
+			expectedMessages: []Message{},
+			expectedSleeps:   []time.Duration{},
+			expectedLogs:     1,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			entryRepo := logger.NewEntryRepoFake()
+			lg, err := logger.NewFake(logger.LogError, &entryRepo)
+			assert.Equal(t, nil, err)
+
+			sender := NewSenderFake(testCase.errs)
+			retry := NewRetry(sender, lg, testCase.maxAttempts, time.Second)
+			sleeps := []time.Duration{}
+			retry.sleep = func(duration time.Duration) {
+				sleeps = append(sleeps, duration)
+			}
+
+			err = retry.Send("alpha@example.com", "Hello", "Hi there")
+			assert.Equal(t, testCase.expectedErr, err)
+			assert.Equal(t, testCase.expectedMessages, sender.Messages())
+			assert.Equal(t, testCase.expectedSleeps, sleeps)
+			assert.Equal(t, testCase.expectedLogs, len(entryRepo.GetEntries()))
+		})
+	}
+}
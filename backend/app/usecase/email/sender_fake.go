@@ -0,0 +1,45 @@
+package email
+
+var _ Sender = (*SenderFake)(nil)
+
+// Message represents an email captured by SenderFake.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// SenderFake represents an in memory Sender used for testing.
+type SenderFake struct {
+	messages *[]Message
+	errs     *[]error
+}
+
+// Send fails with the next configured error, if any, and captures the email
+// otherwise.
+func (s SenderFake) Send(to string, subject string, body string) error {
+	if len(*s.errs) > 0 {
+		err := (*s.errs)[0]
+		*s.errs = (*s.errs)[1:]
+		return err
+	}
+	*s.messages = append(*s.messages, Message{
+		To:      to,
+		Subject: subject,
+		Body:    body,
+	})
+	return nil
+}
+
+// Messages returns the emails sent so far.
+func (s SenderFake) Messages() []Message {
+	return *s.messages
+}
+
+// NewSenderFake creates SenderFake which fails the first len(errs) sends.
+func NewSenderFake(errs []error) SenderFake {
+	return SenderFake{
+		messages: &[]Message{},
+		errs:     &errs,
+	}
+}
@@ -0,0 +1,191 @@
+package emailpassword
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/fw/tenant"
+	"github.com/short-d/short/backend/app/usecase/authenticator"
+	"github.com/short-d/short/backend/app/usecase/keygen"
+	"github.com/short-d/short/backend/app/usecase/ratelimit"
+	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/verification"
+)
+
+// ErrInvalidEmail represents the email is not a valid email address.
+type ErrInvalidEmail string
+
+func (e ErrInvalidEmail) Error() string {
+	return fmt.Sprintf("invalid email: %s", string(e))
+}
+
+// ErrEmailExist represents the email is already used by another account.
+type ErrEmailExist string
+
+func (e ErrEmailExist) Error() string {
+	return fmt.Sprintf("email exists: %s", string(e))
+}
+
+// ErrInvalidCredentials represents the email and the password don't match any
+// account. It doesn't tell which one is wrong to avoid leaking registered
+// emails.
+type ErrInvalidCredentials struct{}
+
+func (e ErrInvalidCredentials) Error() string {
+	return "invalid email or password"
+}
+
+// ErrTooManyAttempts represents the account receives too many sign in
+// attempts within a short period of time.
+type ErrTooManyAttempts string
+
+func (e ErrTooManyAttempts) Error() string {
+	return fmt.Sprintf("too many sign in attempts: %s", string(e))
+}
+
+// Account registers and signs in users with email and password. Password users
+// are stored as regular users so that they are indistinguishable from single
+// sign on users afterwards.
+type Account struct {
+	keyGen           keygen.KeyGenerator
+	userRepo         repository.User
+	userPasswordRepo repository.UserPassword
+	hasher           PasswordHasher
+	passwordPolicy   PasswordPolicy
+	authenticator    authenticator.Authenticator
+	emailVerifier    verification.EmailVerifier
+	signInLimiter    ratelimit.Limiter
+}
+
+// Register creates a new user with the password and asks the user to verify
+// the email. Failing to send the verification email doesn't block signing up
+// since it can be sent again later. The user joins the tenant attached to the
+// context.
+func (a Account) Register(ctx context.Context, name string, email string, password string) (entity.User, error) {
+	email, err := parseEmail(email)
+	if err != nil {
+		return entity.User{}, err
+	}
+
+	err = a.passwordPolicy.Validate(password)
+	if err != nil {
+		return entity.User{}, err
+	}
+
+	isExist, err := a.userRepo.IsEmailExist(email)
+	if err != nil {
+		return entity.User{}, err
+	}
+	if isExist {
+		return entity.User{}, ErrEmailExist(email)
+	}
+
+	passwordHash, err := a.hasher.Hash(password)
+	if err != nil {
+		return entity.User{}, err
+	}
+
+	key, err := a.keyGen.NewKey()
+	if err != nil {
+		return entity.User{}, err
+	}
+
+	user := entity.User{
+		TenantID: tenant.FromContext(ctx),
+		ID:       string(key),
+		Name:     name,
+		Email:    email,
+	}
+	err = a.userRepo.CreateUser(user)
+	if err != nil {
+		return entity.User{}, err
+	}
+
+	err = a.userPasswordRepo.CreatePasswordHash(user.ID, passwordHash)
+	if err != nil {
+		return entity.User{}, err
+	}
+
+	_ = a.emailVerifier.SendVerification(user)
+	return user, nil
+}
+
+// SignIn verifies the password of the account with the given email and
+// issues an authentication token. Sign in attempts are rate limited per email
+// and per client IP to resist brute force attacks, both against one account
+// and across many accounts.
+func (a Account) SignIn(email string, password string, clientIP string) (string, error) {
+	email = strings.TrimSpace(email)
+	for _, key := range []string{"email|" + strings.ToLower(email), "ip|" + clientIP} {
+		isAllowed, err := a.signInLimiter.Allow(key)
+		if err != nil {
+			return "", err
+		}
+		if !isAllowed {
+			return "", ErrTooManyAttempts(email)
+		}
+	}
+
+	user, err := a.userRepo.GetUserByEmail(email)
+	if err != nil {
+		return "", toCredentialsErr(err)
+	}
+
+	passwordHash, err := a.userPasswordRepo.GetPasswordHash(user.ID)
+	if err != nil {
+		return "", toCredentialsErr(err)
+	}
+
+	isMatched, err := a.hasher.Verify(passwordHash, password)
+	if err != nil {
+		return "", err
+	}
+	if !isMatched {
+		return "", ErrInvalidCredentials{}
+	}
+	return a.authenticator.GenerateToken(user)
+}
+
+// toCredentialsErr hides whether the account exists or only signs in with
+// single sign on.
+func toCredentialsErr(err error) error {
+	if isNotFound(err) {
+		return ErrInvalidCredentials{}
+	}
+	return err
+}
+
+func parseEmail(email string) (string, error) {
+	email = strings.TrimSpace(email)
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email {
+		return "", ErrInvalidEmail(email)
+	}
+	return email, nil
+}
+
+// NewAccount creates Account
+func NewAccount(
+	keyGen keygen.KeyGenerator,
+	userRepo repository.User,
+	userPasswordRepo repository.UserPassword,
+	hasher PasswordHasher,
+	passwordPolicy PasswordPolicy,
+	authenticator authenticator.Authenticator,
+	emailVerifier verification.EmailVerifier,
+	signInLimiter ratelimit.Limiter,
+) Account {
+	return Account{
+		keyGen:           keyGen,
+		userRepo:         userRepo,
+		userPasswordRepo: userPasswordRepo,
+		hasher:           hasher,
+		passwordPolicy:   passwordPolicy,
+		authenticator:    authenticator,
+		emailVerifier:    emailVerifier,
+		signInLimiter:    signInLimiter,
+	}
+}
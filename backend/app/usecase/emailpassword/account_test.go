@@ -0,0 +1,297 @@
+// +build !integration all
+
+package emailpassword
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/crypto"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/authenticator"
+	"github.com/short-d/short/backend/app/usecase/email"
+	"github.com/short-d/short/backend/app/usecase/keygen"
+	"github.com/short-d/short/backend/app/usecase/ratelimit"
+	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/verification"
+)
+
+func newAccount(
+	t *testing.T,
+	tm timer.Timer,
+	userRepo repository.User,
+	userPasswordRepo repository.UserPassword,
+	emailSender email.Sender,
+	signInLimiter ratelimit.Limiter,
+) Account {
+	keyFetcher := keygen.NewKeyFetcherFake([]keygen.Key{"alpha"})
+	keyGen, err := keygen.NewRemote(1, &keyFetcher)
+	assert.Equal(t, nil, err)
+
+	emailVerifier := verification.NewEmailVerifier(
+		crypto.NewTokenizerFake(),
+		tm,
+		userRepo,
+		emailSender,
+		url.URL{Scheme: "https", Host: "short-d.com"},
+		24*time.Hour,
+	)
+	return NewAccount(
+		keyGen,
+		userRepo,
+		userPasswordRepo,
+		NewPasswordHasherFake(),
+		DefaultPasswordPolicy,
+		authenticator.NewAuthenticatorFake(time.Now(), time.Hour),
+		emailVerifier,
+		signInLimiter,
+	)
+}
+
+func TestAccount_Register(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name             string
+		users            []entity.User
+		email            string
+		password         string
+		expectedErr      error
+		expectedUser     entity.User
+		expectedHash     string
+		expectedMessages int
+	}{
+		{
+			name:     "register new user",
+			users:    []entity.User{},
+			email:    "alpha@example.com",
+			password: "correct horse 1",
+			expectedUser: entity.User{
+				ID:    "alpha",
+				Name:  "Alpha",
+				Email: "alpha@example.com",
+			},
+			expectedHash:     "hashed:correct horse 1",
+			expectedMessages: 1,
+		},
+		{
+			name:        "invalid email",
+			users:       []entity.User{},
+			email:       "alpha",
+			password:    "correct horse 1",
+			expectedErr: ErrInvalidEmail("alpha"),
+		},
+		{
+			name:        "password too short",
+			users:       []entity.User{},
+			email:       "alpha@example.com",
+			password:    "abc123",
+			expectedErr: ErrWeakPassword("password must have at least 8 characters"),
+		},
+		{
+			name:        "password without digits",
+			users:       []entity.User{},
+			email:       "alpha@example.com",
+			password:    "correcthorse",
+			expectedErr: ErrWeakPassword("password must contain both letters and digits"),
+		},
+		{
+			name: "email used by another account",
+			users: []entity.User{
+				{ID: "beta", Email: "alpha@example.com"},
+			},
+			email:       "alpha@example.com",
+			password:    "correct horse 1",
+			expectedErr: ErrEmailExist("alpha@example.com"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			tm := timer.NewStub(time.Now())
+			userRepo := repository.NewUserFake(testCase.users)
+			userPasswordRepo := repository.NewUserPasswordFake(map[string]string{})
+			emailSender := email.NewSenderFake(nil)
+			account := newAccount(
+				t,
+				tm,
+				&userRepo,
+				&userPasswordRepo,
+				&emailSender,
+				ratelimit.NewMemory(tm, 0, time.Minute),
+			)
+
+			user, err := account.Register(context.Background(), "Alpha", testCase.email, testCase.password)
+			if testCase.expectedErr != nil {
+				assert.Equal(t, testCase.expectedErr, err)
+				return
+			}
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedUser, user)
+
+			savedUser, err := userRepo.GetUserByEmail(testCase.email)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedUser, savedUser)
+
+			passwordHash, err := userPasswordRepo.GetPasswordHash(user.ID)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedHash, passwordHash)
+			assert.Equal(t, testCase.expectedMessages, len(emailSender.Messages()))
+		})
+	}
+}
+
+func TestAccount_SignIn(t *testing.T) {
+	t.Parallel()
+
+	users := []entity.User{
+		{ID: "alpha", Email: "alpha@example.com"},
+		{ID: "beta", Email: "beta@example.com"},
+	}
+	passwordHashes := map[string]string{
+		"alpha": "hashed:correct horse 1",
+	}
+
+	testCases := []struct {
+		name        string
+		email       string
+		password    string
+		expectedErr error
+		expectedID  string
+	}{
+		{
+			name:       "correct password",
+			email:      "alpha@example.com",
+			password:   "correct horse 1",
+			expectedID: "alpha",
+		},
+		{
+			name:        "wrong password",
+			email:       "alpha@example.com",
+			password:    "wrong horse 1",
+			expectedErr: ErrInvalidCredentials{},
+		},
+		{
+			name:        "email not registered",
+			email:       "gamma@example.com",
+			password:    "correct horse 1",
+			expectedErr: ErrInvalidCredentials{},
+		},
+		{
+			name:        "user only signs in with single sign on",
+			email:       "beta@example.com",
+			password:    "correct horse 1",
+			expectedErr: ErrInvalidCredentials{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			tm := timer.NewStub(time.Now())
+			userRepo := repository.NewUserFake(users)
+			userPasswordRepo := repository.NewUserPasswordFake(passwordHashes)
+			emailSender := email.NewSenderFake(nil)
+			account := newAccount(
+				t,
+				tm,
+				&userRepo,
+				&userPasswordRepo,
+				&emailSender,
+				ratelimit.NewMemory(tm, 5, time.Minute),
+			)
+
+			token, err := account.SignIn(testCase.email, testCase.password, "1.1.1.1")
+			if testCase.expectedErr != nil {
+				assert.Equal(t, testCase.expectedErr, err)
+				return
+			}
+			assert.Equal(t, nil, err)
+
+			auth := authenticator.NewAuthenticatorFake(time.Now(), time.Hour)
+			user, err := auth.GetUser(token)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedID, user.ID)
+		})
+	}
+}
+
+func TestAccount_SignInRateLimit(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	tm := timer.NewStub(now)
+	userRepo := repository.NewUserFake([]entity.User{
+		{ID: "alpha", Email: "alpha@example.com"},
+	})
+	userPasswordRepo := repository.NewUserPasswordFake(map[string]string{
+		"alpha": "hashed:correct horse 1",
+	})
+	emailSender := email.NewSenderFake(nil)
+	account := newAccount(
+		t,
+		&tm,
+		&userRepo,
+		&userPasswordRepo,
+		&emailSender,
+		ratelimit.NewMemory(&tm, 3, time.Minute),
+	)
+
+	for idx := 0; idx < 3; idx++ {
+		_, err := account.SignIn("alpha@example.com", "wrong horse 1", fmt.Sprintf("1.1.1.%d", idx))
+		assert.Equal(t, ErrInvalidCredentials{}, err)
+	}
+
+	_, err := account.SignIn("ALPHA@example.com", "correct horse 1", "2.2.2.2")
+	var tooMany ErrTooManyAttempts
+	assert.Equal(t, true, errors.As(err, &tooMany))
+
+	tm.CurrentTime = now.Add(time.Minute)
+	_, err = account.SignIn("alpha@example.com", "correct horse 1", "2.2.2.2")
+	assert.Equal(t, nil, err)
+}
+
+func TestAccount_SignInRateLimitPerIP(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	tm := timer.NewStub(now)
+	userRepo := repository.NewUserFake([]entity.User{
+		{ID: "alpha", Email: "alpha@example.com"},
+	})
+	userPasswordRepo := repository.NewUserPasswordFake(map[string]string{
+		"alpha": "hashed:correct horse 1",
+	})
+	emailSender := email.NewSenderFake(nil)
+	account := newAccount(
+		t,
+		&tm,
+		&userRepo,
+		&userPasswordRepo,
+		&emailSender,
+		ratelimit.NewMemory(&tm, 3, time.Minute),
+	)
+
+	for idx := 0; idx < 3; idx++ {
+		_, err := account.SignIn(fmt.Sprintf("user%d@example.com", idx), "wrong horse 1", "1.1.1.1")
+		assert.Equal(t, ErrInvalidCredentials{}, err)
+	}
+
+	_, err := account.SignIn("alpha@example.com", "correct horse 1", "1.1.1.1")
+	var tooMany ErrTooManyAttempts
+	assert.Equal(t, true, errors.As(err, &tooMany))
+
+	_, err = account.SignIn("alpha@example.com", "correct horse 1", "2.2.2.2")
+	assert.Equal(t, nil, err)
+}
@@ -0,0 +1,8 @@
+package emailpassword
+
+// PasswordHasher derives slow, salted hashes from passwords so that leaked
+// hashes can't be reversed easily.
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Verify(passwordHash string, password string) (bool, error)
+}
@@ -0,0 +1,23 @@
+package emailpassword
+
+var _ PasswordHasher = (*PasswordHasherFake)(nil)
+
+const fakeHashPrefix = "hashed:"
+
+// PasswordHasherFake represents a reversible password hasher used for testing.
+type PasswordHasherFake struct{}
+
+// Hash prefixes the password with a marker.
+func (p PasswordHasherFake) Hash(password string) (string, error) {
+	return fakeHashPrefix + password, nil
+}
+
+// Verify checks whether the hash is produced from the password.
+func (p PasswordHasherFake) Verify(passwordHash string, password string) (bool, error) {
+	return passwordHash == fakeHashPrefix+password, nil
+}
+
+// NewPasswordHasherFake creates PasswordHasherFake
+func NewPasswordHasherFake() PasswordHasherFake {
+	return PasswordHasherFake{}
+}
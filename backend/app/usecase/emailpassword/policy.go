@@ -0,0 +1,142 @@
+package emailpassword
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// maxPasswordBytes matches the longest password bcrypt takes into account.
+const maxPasswordBytes = 72
+
+// ErrWeakPassword represents the password doesn't satisfy the password policy.
+type ErrWeakPassword string
+
+func (e ErrWeakPassword) Error() string {
+	return string(e)
+}
+
+// ErrInvalidPasswordPolicy represents the password policy which no password
+// can satisfy or which requires unknown character classes.
+type ErrInvalidPasswordPolicy string
+
+func (e ErrInvalidPasswordPolicy) Error() string {
+	return fmt.Sprintf("invalid password policy: %s", string(e))
+}
+
+// CharClass represents a kind of characters passwords may be required to
+// contain.
+type CharClass string
+
+// The constants enumerate all the character classes passwords can be required
+// to contain.
+const (
+	CharClassLetter CharClass = "letter"
+	CharClassLower  CharClass = "lower"
+	CharClassUpper  CharClass = "upper"
+	CharClassDigit  CharClass = "digit"
+	// CharClassSymbol covers everything other than letters and digits,
+	// including spaces.
+	CharClassSymbol CharClass = "symbol"
+)
+
+var charClassNames = map[CharClass]string{
+	CharClassLetter: "letters",
+	CharClassLower:  "lowercase letters",
+	CharClassUpper:  "uppercase letters",
+	CharClassDigit:  "digits",
+	CharClassSymbol: "symbols",
+}
+
+func (c CharClass) contains(char rune) bool {
+	switch c {
+	case CharClassLetter:
+		return unicode.IsLetter(char)
+	case CharClassLower:
+		return unicode.IsLower(char)
+	case CharClassUpper:
+		return unicode.IsUpper(char)
+	case CharClassDigit:
+		return unicode.IsDigit(char)
+	default:
+		return !unicode.IsLetter(char) && !unicode.IsDigit(char)
+	}
+}
+
+// PasswordPolicy decides how strong the passwords of local accounts have to
+// be. Passwords need at least MinLength characters, including at least one
+// character of each of the RequiredClasses.
+type PasswordPolicy struct {
+	MinLength       int
+	RequiredClasses []CharClass
+}
+
+// DefaultPasswordPolicy requires passwords to have at least 8 characters,
+// including both letters and digits.
+var DefaultPasswordPolicy = PasswordPolicy{
+	MinLength:       8,
+	RequiredClasses: []CharClass{CharClassLetter, CharClassDigit},
+}
+
+// Validate checks the password against the policy, describing the first
+// violation found.
+func (p PasswordPolicy) Validate(password string) error {
+	if len([]rune(password)) < p.MinLength {
+		return ErrWeakPassword(fmt.Sprintf("password must have at least %d characters", p.MinLength))
+	}
+	if len(password) > maxPasswordBytes {
+		return ErrWeakPassword(fmt.Sprintf("password must not exceed %d bytes", maxPasswordBytes))
+	}
+
+	for _, charClass := range p.RequiredClasses {
+		if strings.IndexFunc(password, charClass.contains) < 0 {
+			return ErrWeakPassword("password must contain " + describeCharClasses(p.RequiredClasses))
+		}
+	}
+	return nil
+}
+
+func describeCharClasses(charClasses []CharClass) string {
+	names := make([]string, 0, len(charClasses))
+	for _, charClass := range charClasses {
+		names = append(names, charClassNames[charClass])
+	}
+
+	switch len(names) {
+	case 1:
+		return names[0]
+	case 2:
+		return fmt.Sprintf("both %s and %s", names[0], names[1])
+	default:
+		last := len(names) - 1
+		return fmt.Sprintf("%s and %s", strings.Join(names[:last], ", "), names[last])
+	}
+}
+
+// NewPasswordPolicy creates PasswordPolicy from the minimum length and the
+// names of the required character classes, such as letter and digit.
+func NewPasswordPolicy(minLength int, names []string) (PasswordPolicy, error) {
+	if minLength < 1 || minLength > maxPasswordBytes {
+		msg := fmt.Sprintf("minimum length must be between 1 and %d", maxPasswordBytes)
+		return PasswordPolicy{}, ErrInvalidPasswordPolicy(msg)
+	}
+
+	seen := make(map[CharClass]bool)
+	charClasses := make([]CharClass, 0, len(names))
+	for _, name := range names {
+		charClass := CharClass(name)
+		if _, ok := charClassNames[charClass]; !ok {
+			return PasswordPolicy{}, ErrInvalidPasswordPolicy("unknown character class: " + name)
+		}
+		if seen[charClass] {
+			continue
+		}
+		seen[charClass] = true
+		charClasses = append(charClasses, charClass)
+	}
+	if len(charClasses) > minLength {
+		msg := fmt.Sprintf("%d character classes can't fit in %d characters", len(charClasses), minLength)
+		return PasswordPolicy{}, ErrInvalidPasswordPolicy(msg)
+	}
+	return PasswordPolicy{MinLength: minLength, RequiredClasses: charClasses}, nil
+}
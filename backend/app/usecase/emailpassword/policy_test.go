@@ -0,0 +1,180 @@
+// +build !integration all
+
+package emailpassword
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+)
+
+func TestPasswordPolicy_Validate(t *testing.T) {
+	t.Parallel()
+
+	strictPolicy := PasswordPolicy{
+		MinLength: 12,
+		RequiredClasses: []CharClass{
+			CharClassLower,
+			CharClassUpper,
+			CharClassDigit,
+			CharClassSymbol,
+		},
+	}
+	testCases := []struct {
+		name        string
+		policy      PasswordPolicy
+		password    string
+		expectedErr error
+	}{
+		{
+			name:     "default policy accepts letters and digits",
+			policy:   DefaultPasswordPolicy,
+			password: "correct horse 1",
+		},
+		{
+			name:        "default policy rejects short password",
+			policy:      DefaultPasswordPolicy,
+			password:    "horse1",
+			expectedErr: ErrWeakPassword("password must have at least 8 characters"),
+		},
+		{
+			name:        "default policy rejects password without digits",
+			policy:      DefaultPasswordPolicy,
+			password:    "correct horse",
+			expectedErr: ErrWeakPassword("password must contain both letters and digits"),
+		},
+		{
+			name:        "password too long for bcrypt",
+			policy:      DefaultPasswordPolicy,
+			password:    strings.Repeat("horse1", 13),
+			expectedErr: ErrWeakPassword("password must not exceed 72 bytes"),
+		},
+		{
+			name:     "length counted in characters",
+			policy:   PasswordPolicy{MinLength: 4},
+			password: "密码密码",
+		},
+		{
+			name:     "strict policy accepts all character classes",
+			policy:   strictPolicy,
+			password: "Correct horse 1",
+		},
+		{
+			name:        "strict policy rejects short password",
+			policy:      strictPolicy,
+			password:    "Correct 1",
+			expectedErr: ErrWeakPassword("password must have at least 12 characters"),
+		},
+		{
+			name:     "strict policy rejects password without uppercase letters",
+			policy:   strictPolicy,
+			password: "correct horse 1",
+			expectedErr: ErrWeakPassword(
+				"password must contain lowercase letters, uppercase letters, digits and symbols",
+			),
+		},
+		{
+			name:     "strict policy rejects password without symbols",
+			policy:   strictPolicy,
+			password: "CorrectHorse1",
+			expectedErr: ErrWeakPassword(
+				"password must contain lowercase letters, uppercase letters, digits and symbols",
+			),
+		},
+		{
+			name:     "length only policy accepts letters",
+			policy:   PasswordPolicy{MinLength: 8},
+			password: "correcthorse",
+		},
+		{
+			name:        "digit only policy rejects letters",
+			policy:      PasswordPolicy{MinLength: 8, RequiredClasses: []CharClass{CharClassDigit}},
+			password:    "correcthorse",
+			expectedErr: ErrWeakPassword("password must contain digits"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := testCase.policy.Validate(testCase.password)
+			assert.Equal(t, testCase.expectedErr, err)
+		})
+	}
+}
+
+func TestNewPasswordPolicy(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name           string
+		minLength      int
+		charClasses    []string
+		expectedPolicy PasswordPolicy
+		expectedErr    error
+	}{
+		{
+			name:           "default policy",
+			minLength:      8,
+			charClasses:    []string{"letter", "digit"},
+			expectedPolicy: DefaultPasswordPolicy,
+		},
+		{
+			name:        "no character classes",
+			minLength:   10,
+			charClasses: []string{},
+			expectedPolicy: PasswordPolicy{
+				MinLength:       10,
+				RequiredClasses: []CharClass{},
+			},
+		},
+		{
+			name:        "duplicated character classes",
+			minLength:   8,
+			charClasses: []string{"upper", "symbol", "upper"},
+			expectedPolicy: PasswordPolicy{
+				MinLength:       8,
+				RequiredClasses: []CharClass{CharClassUpper, CharClassSymbol},
+			},
+		},
+		{
+			name:        "zero minimum length",
+			minLength:   0,
+			expectedErr: ErrInvalidPasswordPolicy("minimum length must be between 1 and 72"),
+		},
+		{
+			name:        "minimum length beyond bcrypt limit",
+			minLength:   73,
+			expectedErr: ErrInvalidPasswordPolicy("minimum length must be between 1 and 72"),
+		},
+		{
+			name:        "unknown character class",
+			minLength:   8,
+			charClasses: []string{"letter", "emoji"},
+			expectedErr: ErrInvalidPasswordPolicy("unknown character class: emoji"),
+		},
+		{
+			name:        "too many character classes for minimum length",
+			minLength:   2,
+			charClasses: []string{"lower", "upper", "digit"},
+			expectedErr: ErrInvalidPasswordPolicy("3 character classes can't fit in 2 characters"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			policy, err := NewPasswordPolicy(testCase.minLength, testCase.charClasses)
+			assert.Equal(t, testCase.expectedErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, testCase.expectedPolicy, policy)
+		})
+	}
+}
@@ -0,0 +1,244 @@
+package emailpassword
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/short-d/app/fw/crypto"
+	"github.com/short-d/app/fw/logger"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/usecase/email"
+	"github.com/short-d/short/backend/app/usecase/ratelimit"
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+// ResetPasswordPath is the web frontend page which the password reset links
+// point to.
+const ResetPasswordPath = "/reset-password"
+
+// ErrInvalidToken represents the password reset token is malformed, not signed
+// by Short, or issued for a user without password.
+type ErrInvalidToken string
+
+func (e ErrInvalidToken) Error() string {
+	return fmt.Sprintf("invalid password reset token: %s", string(e))
+}
+
+// ErrTokenExpired represents the password reset token is too old to be used.
+type ErrTokenExpired string
+
+func (e ErrTokenExpired) Error() string {
+	return string(e)
+}
+
+// ErrTokenUsed represents the password is changed after the password reset
+// token is issued, so the token can't be used again.
+type ErrTokenUsed string
+
+func (e ErrTokenUsed) Error() string {
+	return fmt.Sprintf("password reset token used: %s", string(e))
+}
+
+// ErrTooManyResetRequests represents too many password resets are requested
+// for the email or from the client within a short period of time.
+type ErrTooManyResetRequests string
+
+func (e ErrTooManyResetRequests) Error() string {
+	return fmt.Sprintf("too many password reset requests: %s", string(e))
+}
+
+// PasswordReset lets users who forget their passwords choose new ones by
+// emailing them short lived reset links.
+type PasswordReset struct {
+	tokenizer          crypto.Tokenizer
+	timer              timer.Timer
+	userRepo           repository.User
+	userPasswordRepo   repository.UserPassword
+	hasher             PasswordHasher
+	passwordPolicy     PasswordPolicy
+	emailSender        email.Sender
+	webFrontendURL     url.URL
+	tokenValidDuration time.Duration
+	limiter            ratelimit.Limiter
+	logger             logger.Logger
+	// pending tracks the reset links being sent in the background.
+	pending *sync.WaitGroup
+}
+
+// RequestPasswordReset emails a password reset link to the account with the
+// given email. Requests are rate limited per email and per client IP so that
+// they can't be used to flood inboxes.
+//
+// The account is looked up and the email is sent in the background, so that
+// the caller can't find out which emails are registered, either from the
+// result or from how long the request takes. For the same reason, nothing is
+// reported when the email isn't registered, the account only signs in with
+// single sign on, or the email fails to be sent. The failures are logged
+// instead.
+func (p PasswordReset) RequestPasswordReset(email string, clientIP string) error {
+	email = strings.TrimSpace(email)
+	for _, key := range []string{"email|" + strings.ToLower(email), "ip|" + clientIP} {
+		isAllowed, err := p.limiter.Allow(key)
+		if err != nil {
+			return err
+		}
+		if !isAllowed {
+			return ErrTooManyResetRequests(email)
+		}
+	}
+
+	p.pending.Add(1)
+	go func() {
+		defer p.pending.Done()
+		err := p.sendResetLink(email)
+		if err != nil {
+			p.logger.Error(err)
+		}
+	}()
+	return nil
+}
+
+// Start does nothing since the reset links are only sent on request.
+func (p PasswordReset) Start() {}
+
+// Stop blocks until the reset links requested so far are sent, so that they
+// are not dropped on shutdown.
+func (p PasswordReset) Stop() {
+	p.pending.Wait()
+}
+
+func (p PasswordReset) sendResetLink(email string) error {
+	user, err := p.userRepo.GetUserByEmail(email)
+	if isNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = p.userPasswordRepo.GetPasswordHash(user.ID)
+	if isNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	token := resetToken{
+		userID:   user.ID,
+		issuedAt: p.timer.Now(),
+	}
+	tokenStr, err := p.tokenizer.Encode(token.tokenPayload())
+	if err != nil {
+		return err
+	}
+
+	link := p.webFrontendURL
+	link.Path = path.Join("/", link.Path, ResetPasswordPath)
+	link.RawQuery = url.Values{"token": {tokenStr}}.Encode()
+
+	body := fmt.Sprintf(
+		"Please reset your password by opening the following link within %v:\n\n%s\n\n"+
+			"You can ignore this email if you didn't request a password reset.\n",
+		p.tokenValidDuration, link.String(),
+	)
+	return p.emailSender.Send(user.Email, "Reset your password for Short", body)
+}
+
+// ResetPassword replaces the password of the user with the token from the
+// password reset link, and signs the user out everywhere by revoking existing
+// tokens. Revoking also invalidates the reset token itself along with any
+// other reset tokens issued earlier, so each of them can only be used once.
+func (p PasswordReset) ResetPassword(tokenStr string, newPassword string) error {
+	tokenPayload, err := p.tokenizer.Decode(tokenStr)
+	if err != nil {
+		return ErrInvalidToken(err.Error())
+	}
+
+	token, err := fromTokenPayload(tokenPayload)
+	if err != nil {
+		return ErrInvalidToken(err.Error())
+	}
+
+	now := p.timer.Now()
+	expireAt := token.issuedAt.Add(p.tokenValidDuration)
+	if expireAt.Before(now) {
+		return ErrTokenExpired(fmt.Sprintf("token expired at %v", expireAt))
+	}
+
+	revokedAt, err := p.userRepo.GetTokensRevokedAt(token.userID)
+	if isNotFound(err) {
+		return ErrInvalidToken("user not found")
+	}
+	if err != nil {
+		return err
+	}
+	if !token.issuedAt.After(revokedAt) {
+		return ErrTokenUsed(token.userID)
+	}
+
+	err = p.passwordPolicy.Validate(newPassword)
+	if err != nil {
+		return err
+	}
+
+	passwordHash, err := p.hasher.Hash(newPassword)
+	if err != nil {
+		return err
+	}
+
+	// Revoke before updating the password so that the token can be used again
+	// if the update fails.
+	err = p.userRepo.RevokeTokens(token.userID, now)
+	if err != nil {
+		return err
+	}
+
+	err = p.userPasswordRepo.UpdatePasswordHash(token.userID, passwordHash)
+	if isNotFound(err) {
+		return ErrInvalidToken("password not found")
+	}
+	return err
+}
+
+func isNotFound(err error) bool {
+	var errNotFound repository.ErrEntryNotFound
+	return errors.As(err, &errNotFound)
+}
+
+// NewPasswordReset creates PasswordReset. Password resets are requested at
+// most as often as limiter allows for each email and each client IP. The reset
+// links failed to be sent are reported to logger.
+func NewPasswordReset(
+	tokenizer crypto.Tokenizer,
+	timer timer.Timer,
+	userRepo repository.User,
+	userPasswordRepo repository.UserPassword,
+	hasher PasswordHasher,
+	passwordPolicy PasswordPolicy,
+	emailSender email.Sender,
+	webFrontendURL url.URL,
+	tokenValidDuration time.Duration,
+	limiter ratelimit.Limiter,
+	logger logger.Logger,
+) PasswordReset {
+	return PasswordReset{
+		tokenizer:          tokenizer,
+		timer:              timer,
+		userRepo:           userRepo,
+		userPasswordRepo:   userPasswordRepo,
+		hasher:             hasher,
+		passwordPolicy:     passwordPolicy,
+		emailSender:        emailSender,
+		webFrontendURL:     webFrontendURL,
+		tokenValidDuration: tokenValidDuration,
+		limiter:            limiter,
+		logger:             logger,
+		pending:            &sync.WaitGroup{},
+	}
+}
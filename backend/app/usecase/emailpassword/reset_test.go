@@ -0,0 +1,383 @@
+// +build !integration all
+
+package emailpassword
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/crypto"
+	"github.com/short-d/app/fw/logger"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/authenticator"
+	"github.com/short-d/short/backend/app/usecase/email"
+	"github.com/short-d/short/backend/app/usecase/ratelimit"
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+func newPasswordReset(
+	t *testing.T,
+	tm timer.Timer,
+	userRepo repository.User,
+	userPasswordRepo repository.UserPassword,
+	emailSender email.Sender,
+) PasswordReset {
+	return NewPasswordReset(
+		crypto.NewTokenizerFake(),
+		tm,
+		userRepo,
+		userPasswordRepo,
+		NewPasswordHasherFake(),
+		DefaultPasswordPolicy,
+		emailSender,
+		url.URL{Scheme: "https", Host: "short-d.com"},
+		30*time.Minute,
+		ratelimit.NewMemory(tm, 0, time.Minute),
+		newLogger(t),
+	)
+}
+
+func newLogger(t *testing.T) logger.Logger {
+	entryRepo := logger.NewEntryRepoFake()
+	lg, err := logger.NewFake(logger.LogOff, &entryRepo)
+	assert.Equal(t, nil, err)
+	return lg
+}
+
+func getResetToken(t *testing.T, message email.Message) string {
+	for _, line := range strings.Split(message.Body, "\n") {
+		if !strings.HasPrefix(line, "https://") {
+			continue
+		}
+		link, err := url.Parse(line)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, ResetPasswordPath, link.Path)
+		return link.Query().Get("token")
+	}
+	t.Fatal("expect password reset link in the email")
+	return ""
+}
+
+func TestPasswordReset_RequestPasswordReset(t *testing.T) {
+	t.Parallel()
+
+	users := []entity.User{
+		{ID: "alpha", Email: "alpha@example.com"},
+		{ID: "beta", Email: "beta@example.com"},
+	}
+	passwordHashes := map[string]string{
+		"alpha": "hashed:correct horse 1",
+	}
+
+	testCases := []struct {
+		name         string
+		email        string
+		expectedSent []string
+	}{
+		{
+			name:         "email registered",
+			email:        "alpha@example.com",
+			expectedSent: []string{"alpha@example.com"},
+		},
+		{
+			name:         "email not registered",
+			email:        "gamma@example.com",
+			expectedSent: []string{},
+		},
+		{
+			name:         "user only signs in with single sign on",
+			email:        "beta@example.com",
+			expectedSent: []string{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			tm := timer.NewStub(time.Now())
+			userRepo := repository.NewUserFake(users)
+			userPasswordRepo := repository.NewUserPasswordFake(passwordHashes)
+			emailSender := email.NewSenderFake(nil)
+			passwordReset := newPasswordReset(t, tm, &userRepo, &userPasswordRepo, &emailSender)
+
+			err := passwordReset.RequestPasswordReset(testCase.email, "1.1.1.1")
+			assert.Equal(t, nil, err)
+			passwordReset.Stop()
+
+			sent := []string{}
+			for _, message := range emailSender.Messages() {
+				sent = append(sent, message.To)
+			}
+			assert.Equal(t, testCase.expectedSent, sent)
+		})
+	}
+}
+
+func TestPasswordReset_RequestPasswordResetRateLimit(t *testing.T) {
+	t.Parallel()
+
+	type request struct {
+		email       string
+		clientIP    string
+		expectedErr error
+	}
+
+	testCases := []struct {
+		name         string
+		requests     []request
+		expectedSent int
+	}{
+		{
+			name: "same email from different clients",
+			requests: []request{
+				{email: "alpha@example.com", clientIP: "1.1.1.1"},
+				{email: "alpha@example.com", clientIP: "2.2.2.2"},
+				{
+					email:       "alpha@example.com",
+					clientIP:    "3.3.3.3",
+					expectedErr: ErrTooManyResetRequests("alpha@example.com"),
+				},
+			},
+			expectedSent: 2,
+		},
+		{
+			name: "different emails from same client",
+			requests: []request{
+				{email: "alpha@example.com", clientIP: "1.1.1.1"},
+				{email: "gamma@example.com", clientIP: "1.1.1.1"},
+				{
+					email:       "delta@example.com",
+					clientIP:    "1.1.1.1",
+					expectedErr: ErrTooManyResetRequests("delta@example.com"),
+				},
+			},
+			expectedSent: 1,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			tm := timer.NewStub(time.Now())
+			userRepo := repository.NewUserFake([]entity.User{
+				{ID: "alpha", Email: "alpha@example.com"},
+			})
+			userPasswordRepo := repository.NewUserPasswordFake(map[string]string{
+				"alpha": "hashed:correct horse 1",
+			})
+			emailSender := email.NewSenderFake(nil)
+			passwordReset := NewPasswordReset(
+				crypto.NewTokenizerFake(),
+				tm,
+				&userRepo,
+				&userPasswordRepo,
+				NewPasswordHasherFake(),
+				DefaultPasswordPolicy,
+				&emailSender,
+				url.URL{Scheme: "https", Host: "short-d.com"},
+				30*time.Minute,
+				ratelimit.NewMemory(tm, 2, time.Hour),
+				newLogger(t),
+			)
+
+			for _, req := range testCase.requests {
+				err := passwordReset.RequestPasswordReset(req.email, req.clientIP)
+				assert.Equal(t, req.expectedErr, err)
+				passwordReset.Stop()
+			}
+			assert.Equal(t, testCase.expectedSent, len(emailSender.Messages()))
+		})
+	}
+}
+
+func TestPasswordReset_RequestPasswordResetSendFailed(t *testing.T) {
+	t.Parallel()
+
+	tm := timer.NewStub(time.Now())
+	userRepo := repository.NewUserFake([]entity.User{
+		{ID: "alpha", Email: "alpha@example.com"},
+	})
+	userPasswordRepo := repository.NewUserPasswordFake(map[string]string{
+		"alpha": "hashed:correct horse 1",
+	})
+	emailSender := email.NewSenderFake([]error{errors.New("mailbox unavailable")})
+	entryRepo := logger.NewEntryRepoFake()
+	lg, err := logger.NewFake(logger.LogError, &entryRepo)
+	assert.Equal(t, nil, err)
+	passwordReset := NewPasswordReset(
+		crypto.NewTokenizerFake(),
+		tm,
+		&userRepo,
+		&userPasswordRepo,
+		NewPasswordHasherFake(),
+		DefaultPasswordPolicy,
+		&emailSender,
+		url.URL{Scheme: "https", Host: "short-d.com"},
+		30*time.Minute,
+		ratelimit.NewMemory(tm, 0, time.Minute),
+		lg,
+	)
+
+	err = passwordReset.RequestPasswordReset("alpha@example.com", "1.1.1.1")
+	assert.Equal(t, nil, err)
+	passwordReset.Stop()
+
+	assert.Equal(t, 0, len(emailSender.Messages()))
+	assert.Equal(t, 1, len(entryRepo.GetEntries()))
+}
+
+func TestPasswordReset_ResetPassword(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 6, 1, 8, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name         string
+		resetAfter   time.Duration
+		resetTimes   int
+		newPassword  string
+		expectedErr  error
+		expectedHash string
+	}{
+		{
+			name:         "password reset",
+			resetAfter:   10 * time.Minute,
+			resetTimes:   1,
+			newPassword:  "battery staple 2",
+			expectedHash: "hashed:battery staple 2",
+		},
+		{
+			name:         "token expired",
+			resetAfter:   31 * time.Minute,
+			resetTimes:   1,
+			newPassword:  "battery staple 2",
+			expectedErr:  ErrTokenExpired("token expired at 2020-06-01 08:30:00 +0000 UTC"),
+			expectedHash: "hashed:correct horse 1",
+		},
+		{
+			name:         "token reused",
+			resetAfter:   10 * time.Minute,
+			resetTimes:   2,
+			newPassword:  "battery staple 2",
+			expectedErr:  ErrTokenUsed("alpha"),
+			expectedHash: "hashed:battery staple 2",
+		},
+		{
+			name:         "weak password",
+			resetAfter:   10 * time.Minute,
+			resetTimes:   1,
+			newPassword:  "battery",
+			expectedErr:  ErrWeakPassword("password must have at least 8 characters"),
+			expectedHash: "hashed:correct horse 1",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			tm := timer.NewStub(now)
+			userRepo := repository.NewUserFake([]entity.User{
+				{ID: "alpha", Email: "alpha@example.com"},
+			})
+			userPasswordRepo := repository.NewUserPasswordFake(map[string]string{
+				"alpha": "hashed:correct horse 1",
+			})
+			emailSender := email.NewSenderFake(nil)
+			passwordReset := newPasswordReset(t, &tm, &userRepo, &userPasswordRepo, &emailSender)
+
+			err := passwordReset.RequestPasswordReset("alpha@example.com", "1.1.1.1")
+			assert.Equal(t, nil, err)
+			passwordReset.Stop()
+			assert.Equal(t, 1, len(emailSender.Messages()))
+			token := getResetToken(t, emailSender.Messages()[0])
+
+			tm.CurrentTime = now.Add(testCase.resetAfter)
+			for idx := 0; idx < testCase.resetTimes; idx++ {
+				err = passwordReset.ResetPassword(token, testCase.newPassword)
+			}
+			assert.Equal(t, testCase.expectedErr, err)
+
+			passwordHash, err := userPasswordRepo.GetPasswordHash("alpha")
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedHash, passwordHash)
+		})
+	}
+}
+
+func TestPasswordReset_ResetPasswordRevokesTokens(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 6, 1, 8, 0, 0, 0, time.UTC)
+	alpha := entity.User{ID: "alpha", Email: "alpha@example.com"}
+
+	tm := timer.NewStub(now)
+	userRepo := repository.NewUserFake([]entity.User{alpha})
+	userPasswordRepo := repository.NewUserPasswordFake(map[string]string{
+		"alpha": "hashed:correct horse 1",
+	})
+	emailSender := email.NewSenderFake(nil)
+	passwordReset := newPasswordReset(t, &tm, &userRepo, &userPasswordRepo, &emailSender)
+	auth := authenticator.NewAuthenticator(crypto.NewTokenizerFake(), &tm, time.Hour, &userRepo)
+
+	oldAuthToken, err := auth.GenerateToken(alpha)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, auth.IsSignedIn(oldAuthToken))
+
+	err = passwordReset.RequestPasswordReset(alpha.Email, "1.1.1.1")
+	assert.Equal(t, nil, err)
+	passwordReset.Stop()
+	firstResetToken := getResetToken(t, emailSender.Messages()[0])
+
+	tm.CurrentTime = now.Add(time.Minute)
+	err = passwordReset.RequestPasswordReset(alpha.Email, "1.1.1.1")
+	assert.Equal(t, nil, err)
+	passwordReset.Stop()
+	secondResetToken := getResetToken(t, emailSender.Messages()[1])
+
+	tm.CurrentTime = now.Add(5 * time.Minute)
+	err = passwordReset.ResetPassword(secondResetToken, "battery staple 2")
+	assert.Equal(t, nil, err)
+
+	assert.Equal(t, false, auth.IsSignedIn(oldAuthToken))
+	_, err = auth.GetUser(oldAuthToken)
+	assert.NotEqual(t, nil, err)
+
+	err = passwordReset.ResetPassword(firstResetToken, "battery staple 3")
+	assert.Equal(t, ErrTokenUsed("alpha"), err)
+
+	newAuthToken, err := auth.GenerateToken(alpha)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, auth.IsSignedIn(newAuthToken))
+}
+
+func TestPasswordReset_ResetPasswordInvalidToken(t *testing.T) {
+	t.Parallel()
+
+	tm := timer.NewStub(time.Now())
+	userRepo := repository.NewUserFake([]entity.User{
+		{ID: "alpha", Email: "alpha@example.com"},
+	})
+	userPasswordRepo := repository.NewUserPasswordFake(map[string]string{
+		"alpha": "hashed:correct horse 1",
+	})
+	emailSender := email.NewSenderFake(nil)
+	passwordReset := newPasswordReset(t, tm, &userRepo, &userPasswordRepo, &emailSender)
+
+	auth := authenticator.NewAuthenticatorFake(time.Now(), time.Hour)
+	authToken, err := auth.GenerateToken(entity.User{ID: "alpha"})
+	assert.Equal(t, nil, err)
+
+	err = passwordReset.ResetPassword(authToken, "battery staple 2")
+	assert.Equal(t, ErrInvalidToken("expect payload to be issued for password reset"), err)
+}
@@ -0,0 +1,51 @@
+package emailpassword
+
+import (
+	"errors"
+	"time"
+
+	"github.com/short-d/app/fw/crypto"
+)
+
+// purposePasswordReset prevents tokens issued for other purposes, such as
+// authentication and email verification, from being accepted as password
+// reset tokens.
+const purposePasswordReset = "password_reset"
+
+// resetToken represents the metadata encoded in the password reset token.
+type resetToken struct {
+	userID   string
+	issuedAt time.Time
+}
+
+func (r resetToken) tokenPayload() crypto.TokenPayload {
+	return map[string]interface{}{
+		"purpose":   purposePasswordReset,
+		"user_id":   r.userID,
+		"issued_at": r.issuedAt,
+	}
+}
+
+func fromTokenPayload(tokenPayload crypto.TokenPayload) (resetToken, error) {
+	token := resetToken{}
+
+	purpose, ok := tokenPayload["purpose"].(string)
+	if !ok || purpose != purposePasswordReset {
+		return token, errors.New("expect payload to be issued for password reset")
+	}
+
+	if token.userID, ok = tokenPayload["user_id"].(string); !ok || token.userID == "" {
+		return token, errors.New("expect payload to contain user_id")
+	}
+
+	issuedAtStr, ok := tokenPayload["issued_at"].(string)
+	if !ok {
+		return token, errors.New("expect payload to contain issued_at")
+	}
+	issuedAt, err := time.Parse(time.RFC3339, issuedAtStr)
+	if err != nil {
+		return token, err
+	}
+	token.issuedAt = issuedAt
+	return token, nil
+}
@@ -68,11 +68,13 @@ func (s StaticDecisionMakerFactory) NewDecision(
 	return StaticDecisionMaker{
 		instrumentation: instrumentation,
 		decisions: map[string]bool{
+			"apple-sign-in":            true,
 			"change-log":               true,
 			"facebook-sign-in":         true,
 			"github-sign-in":           true,
 			"google-sign-in":           true,
 			"search-bar":               true,
+			"twitter-sign-in":          true,
 			"user-short-links-section": true,
 			"preference-toggles":       true,
 			"admin-panel":              true,
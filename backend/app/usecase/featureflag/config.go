@@ -0,0 +1,45 @@
+package featureflag
+
+import (
+	"encoding/json"
+
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/fw/filesystem"
+)
+
+var _ Toggle = (*ConfigToggle)(nil)
+
+type config struct {
+	Flags map[Flag]Rollout `json:"flags"`
+}
+
+// ConfigToggle turns features on or off based on the rollouts defined in a
+// config file.
+type ConfigToggle struct {
+	rollouts map[Flag]Rollout
+}
+
+// IsEnabled determines whether a feature is turned on for the user. Features
+// missing from the config file are turned off.
+func (c ConfigToggle) IsEnabled(flag Flag, user *entity.User) bool {
+	rollout, ok := c.rollouts[flag]
+	if !ok {
+		return false
+	}
+	return rollout.isEnabledFor(flag, user)
+}
+
+// NewConfigToggle creates ConfigToggle from the JSON config file.
+func NewConfigToggle(fileSystem filesystem.FileSystem, configPath string) (ConfigToggle, error) {
+	buf, err := fileSystem.ReadFile(configPath)
+	if err != nil {
+		return ConfigToggle{}, err
+	}
+
+	var cfg config
+	err = json.Unmarshal(buf, &cfg)
+	if err != nil {
+		return ConfigToggle{}, err
+	}
+	return ConfigToggle{rollouts: cfg.Flags}, nil
+}
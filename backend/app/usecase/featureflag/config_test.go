@@ -0,0 +1,132 @@
+// +build !integration all
+
+package featureflag
+
+import (
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/fw/filesystem"
+)
+
+func TestConfigToggle_IsEnabled(t *testing.T) {
+	t.Parallel()
+
+	config := []byte(`{
+  "flags": {
+    "on": {"enabled": true},
+    "off": {"enabled": false},
+    "off-with-percentage": {"enabled": false, "percentage": 100},
+    "nobody": {"enabled": true, "percentage": 0},
+    "everyone": {"enabled": true, "percentage": 100}
+  }
+}`)
+	fileSystem := filesystem.NewFileSystemFake(map[string][]byte{
+		"featureflag.json": config,
+	})
+	toggle, err := NewConfigToggle(fileSystem, "featureflag.json")
+	assert.Equal(t, nil, err)
+
+	user := entity.User{ID: "alpha"}
+
+	testCases := []struct {
+		name              string
+		flag              Flag
+		user              *entity.User
+		expectedIsEnabled bool
+	}{
+		{
+			name:              "flag turned on",
+			flag:              "on",
+			user:              &user,
+			expectedIsEnabled: true,
+		},
+		{
+			name:              "flag turned on without user",
+			flag:              "on",
+			user:              nil,
+			expectedIsEnabled: true,
+		},
+		{
+			name:              "flag turned off",
+			flag:              "off",
+			user:              &user,
+			expectedIsEnabled: false,
+		},
+		{
+			name:              "flag turned off ignores percentage",
+			flag:              "off-with-percentage",
+			user:              &user,
+			expectedIsEnabled: false,
+		},
+		{
+			name:              "flag rolled out to nobody",
+			flag:              "nobody",
+			user:              &user,
+			expectedIsEnabled: false,
+		},
+		{
+			name:              "flag rolled out to everyone",
+			flag:              "everyone",
+			user:              nil,
+			expectedIsEnabled: true,
+		},
+		{
+			name:              "flag not found",
+			flag:              "unknown",
+			user:              &user,
+			expectedIsEnabled: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, testCase.expectedIsEnabled, toggle.IsEnabled(testCase.flag, testCase.user))
+		})
+	}
+}
+
+func TestNewConfigToggle(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name   string
+		files  map[string][]byte
+		hasErr bool
+	}{
+		{
+			name: "valid config",
+			files: map[string][]byte{
+				"featureflag.json": []byte(`{"flags": {"on": {"enabled": true}}}`),
+			},
+			hasErr: false,
+		},
+		{
+			name: "malformed config",
+			files: map[string][]byte{
+				"featureflag.json": []byte(`{"flags": `),
+			},
+			hasErr: true,
+		},
+		{
+			name:   "config not found",
+			files:  map[string][]byte{},
+			hasErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			fileSystem := filesystem.NewFileSystemFake(testCase.files)
+			_, err := NewConfigToggle(fileSystem, "featureflag.json")
+			assert.Equal(t, testCase.hasErr, err != nil)
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package featureflag
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/authorizer"
+)
+
+var _ Toggle = (*OverrideToggle)(nil)
+
+// ErrUnauthorizedAction represents unauthorized action error
+type ErrUnauthorizedAction struct {
+	user   entity.User
+	action string
+}
+
+var _ error = (*ErrUnauthorizedAction)(nil)
+
+func (e ErrUnauthorizedAction) Error() string {
+	return fmt.Sprintf("user %s is not allowed to %s", e.user.ID, e.action)
+}
+
+// ErrUnknownFlag represents the flag not consulted by any use case.
+type ErrUnknownFlag string
+
+func (e ErrUnknownFlag) Error() string {
+	return "unknown feature flag: " + string(e)
+}
+
+// overrides keeps the features turned on or off at runtime.
+type overrides struct {
+	mutex     sync.RWMutex
+	isEnabled map[Flag]bool
+}
+
+// OverrideToggle turns features on or off for everyone at runtime, falling
+// back to the wrapped Toggle for the features which are not overridden. The
+// overrides only live in memory, so they are lost once the service restarts.
+// OverrideToggle is safe to read concurrently while it is changed by Switch.
+// The copies of OverrideToggle share the same overrides.
+type OverrideToggle struct {
+	toggle    Toggle
+	overrides *overrides
+}
+
+// IsEnabled determines whether a feature is turned on for the user.
+func (o OverrideToggle) IsEnabled(flag Flag, user *entity.User) bool {
+	o.overrides.mutex.RLock()
+	isEnabled, ok := o.overrides.isEnabled[flag]
+	o.overrides.mutex.RUnlock()
+
+	if ok {
+		return isEnabled
+	}
+	return o.toggle.IsEnabled(flag, user)
+}
+
+func (o OverrideToggle) override(flag Flag, isEnabled bool) {
+	o.overrides.mutex.Lock()
+	defer o.overrides.mutex.Unlock()
+	o.overrides.isEnabled[flag] = isEnabled
+}
+
+func (o OverrideToggle) clearOverride(flag Flag) {
+	o.overrides.mutex.Lock()
+	defer o.overrides.mutex.Unlock()
+	delete(o.overrides.isEnabled, flag)
+}
+
+// NewOverrideToggle creates OverrideToggle without any overrides on top of
+// the given Toggle.
+func NewOverrideToggle(toggle Toggle) OverrideToggle {
+	return OverrideToggle{
+		toggle:    toggle,
+		overrides: &overrides{isEnabled: make(map[Flag]bool)},
+	}
+}
+
+// Switch turns features on or off at runtime.
+type Switch struct {
+	toggle     OverrideToggle
+	authorizer authorizer.Authorizer
+}
+
+// Override turns the feature on or off for everyone on behalf of the given
+// user, until the override is cleared.
+func (s Switch) Override(user entity.User, flag Flag, isEnabled bool) error {
+	err := s.checkOverride(user, flag)
+	if err != nil {
+		return err
+	}
+	s.toggle.override(flag, isEnabled)
+	return nil
+}
+
+// ClearOverride restores the feature to the state defined by the config on
+// behalf of the given user.
+func (s Switch) ClearOverride(user entity.User, flag Flag) error {
+	err := s.checkOverride(user, flag)
+	if err != nil {
+		return err
+	}
+	s.toggle.clearOverride(flag)
+	return nil
+}
+
+// IsEnabled determines whether the feature is turned on for everyone,
+// regardless of the user.
+func (s Switch) IsEnabled(flag Flag) bool {
+	return s.toggle.IsEnabled(flag, nil)
+}
+
+func (s Switch) checkOverride(user entity.User, flag Flag) error {
+	canOverride, err := s.authorizer.CanOverrideFeatureFlag(user)
+	if err != nil {
+		return err
+	}
+	if !canOverride {
+		return ErrUnauthorizedAction{
+			user:   user,
+			action: "override feature flags",
+		}
+	}
+
+	for _, knownFlag := range flags {
+		if flag == knownFlag {
+			return nil
+		}
+	}
+	return ErrUnknownFlag(flag)
+}
+
+// NewSwitch creates Switch which changes the given OverrideToggle.
+func NewSwitch(toggle OverrideToggle, authorizer authorizer.Authorizer) Switch {
+	return Switch{
+		toggle:     toggle,
+		authorizer: authorizer,
+	}
+}
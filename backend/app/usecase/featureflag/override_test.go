@@ -0,0 +1,140 @@
+// +build !integration all
+
+package featureflag
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/authorizer"
+	"github.com/short-d/short/backend/app/usecase/authorizer/rbac"
+	"github.com/short-d/short/backend/app/usecase/authorizer/rbac/role"
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+func newTestSwitch(toggle OverrideToggle, roles map[string][]role.Role) Switch {
+	roleRepo := repository.NewUserRoleFake(roles)
+	return NewSwitch(toggle, authorizer.NewAuthorizer(rbac.NewRBAC(roleRepo)))
+}
+
+func TestOverrideToggle_IsEnabled(t *testing.T) {
+	t.Parallel()
+
+	admin := entity.User{ID: "alpha"}
+	toggle := NewOverrideToggle(NewToggleFake(map[Flag]bool{
+		LinkHealthCheck: true,
+	}))
+	flagSwitch := newTestSwitch(toggle, map[string][]role.Role{
+		admin.ID: {role.Admin},
+	})
+
+	assert.Equal(t, true, toggle.IsEnabled(LinkHealthCheck, nil))
+	assert.Equal(t, false, toggle.IsEnabled(BrokenLinkReport, nil))
+
+	err := flagSwitch.Override(admin, LinkHealthCheck, false)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, toggle.IsEnabled(LinkHealthCheck, nil))
+	assert.Equal(t, false, toggle.IsEnabled(LinkHealthCheck, &admin))
+
+	err = flagSwitch.Override(admin, BrokenLinkReport, true)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, toggle.IsEnabled(BrokenLinkReport, nil))
+
+	err = flagSwitch.ClearOverride(admin, LinkHealthCheck)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, toggle.IsEnabled(LinkHealthCheck, nil))
+	assert.Equal(t, true, toggle.IsEnabled(BrokenLinkReport, nil))
+}
+
+func TestSwitch_Override(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name              string
+		roles             []role.Role
+		flag              Flag
+		expectedErr       error
+		expectedIsEnabled bool
+	}{
+		{
+			name:              "admin turns on feature",
+			roles:             []role.Role{role.Admin},
+			flag:              ProbeInternalHosts,
+			expectedIsEnabled: true,
+		},
+		{
+			name:              "security specialist not allowed to override",
+			roles:             []role.Role{role.SecuritySpecialist},
+			flag:              ProbeInternalHosts,
+			expectedErr:       ErrUnauthorizedAction{entity.User{ID: "alpha"}, "override feature flags"},
+			expectedIsEnabled: false,
+		},
+		{
+			name:              "basic user not allowed to override",
+			roles:             []role.Role{role.Basic},
+			flag:              ProbeInternalHosts,
+			expectedErr:       ErrUnauthorizedAction{entity.User{ID: "alpha"}, "override feature flags"},
+			expectedIsEnabled: false,
+		},
+		{
+			name:              "unknown flag",
+			roles:             []role.Role{role.Admin},
+			flag:              "probe-internal-host",
+			expectedErr:       ErrUnknownFlag("probe-internal-host"),
+			expectedIsEnabled: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			user := entity.User{ID: "alpha"}
+			toggle := NewOverrideToggle(NewToggleFake(nil))
+			flagSwitch := newTestSwitch(toggle, map[string][]role.Role{
+				user.ID: testCase.roles,
+			})
+
+			err := flagSwitch.Override(user, testCase.flag, true)
+			assert.Equal(t, testCase.expectedErr, err)
+			assert.Equal(t, testCase.expectedIsEnabled, toggle.IsEnabled(testCase.flag, nil))
+		})
+	}
+}
+
+func TestSwitch_OverrideConcurrently(t *testing.T) {
+	t.Parallel()
+
+	admin := entity.User{ID: "alpha"}
+	toggle := NewOverrideToggle(NewToggleFake(nil))
+	flagSwitch := newTestSwitch(toggle, map[string][]role.Role{
+		admin.ID: {role.Admin},
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(3)
+		go func(isEnabled bool) {
+			defer wg.Done()
+			err := flagSwitch.Override(admin, LinkHealthCheck, isEnabled)
+			assert.Equal(t, nil, err)
+		}(i%2 == 0)
+		go func() {
+			defer wg.Done()
+			err := flagSwitch.ClearOverride(admin, BrokenLinkReport)
+			assert.Equal(t, nil, err)
+		}()
+		go func() {
+			defer wg.Done()
+			toggle.IsEnabled(LinkHealthCheck, nil)
+		}()
+	}
+	wg.Wait()
+
+	err := flagSwitch.Override(admin, LinkHealthCheck, true)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, toggle.IsEnabled(LinkHealthCheck, nil))
+}
@@ -0,0 +1,41 @@
+package featureflag
+
+import (
+	"hash/fnv"
+
+	"github.com/short-d/short/backend/app/entity"
+)
+
+const bucketCount = 100
+
+// Rollout represents how widely a feature is turned on. The feature is turned
+// on for everyone when Percentage is nil. Otherwise, it is only turned on for
+// the given percentage of the users.
+type Rollout struct {
+	Enabled    bool `json:"enabled"`
+	Percentage *int `json:"percentage"`
+}
+
+func (r Rollout) isEnabledFor(flag Flag, user *entity.User) bool {
+	if !r.Enabled {
+		return false
+	}
+	if r.Percentage == nil {
+		return true
+	}
+	if *r.Percentage >= bucketCount {
+		return true
+	}
+	if user == nil || user.ID == "" {
+		return false
+	}
+	return bucket(flag, user.ID) < *r.Percentage
+}
+
+// bucket consistently assigns the user to one of the buckets. The flag is
+// part of the key so that the same users are not always the early adopters.
+func bucket(flag Flag, userID string) int {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(string(flag) + ":" + userID))
+	return int(hash.Sum32() % bucketCount)
+}
@@ -0,0 +1,76 @@
+// +build !integration all
+
+package featureflag
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/short/backend/app/entity"
+)
+
+func TestRollout_IsEnabledFor(t *testing.T) {
+	t.Parallel()
+
+	percentage := 30
+	rollout := Rollout{Enabled: true, Percentage: &percentage}
+
+	t.Run("same user gets the same decision", func(t *testing.T) {
+		t.Parallel()
+
+		for idx := 0; idx < 100; idx++ {
+			user := entity.User{ID: fmt.Sprintf("user-%d", idx)}
+			expected := rollout.isEnabledFor("interstitial", &user)
+			for attempt := 0; attempt < 5; attempt++ {
+				assert.Equal(t, expected, rollout.isEnabledFor("interstitial", &user))
+			}
+		}
+	})
+
+	t.Run("enabled for roughly the given percentage of users", func(t *testing.T) {
+		t.Parallel()
+
+		enabledCount := 0
+		userCount := 10000
+		for idx := 0; idx < userCount; idx++ {
+			user := entity.User{ID: fmt.Sprintf("user-%d", idx)}
+			if rollout.isEnabledFor("interstitial", &user) {
+				enabledCount++
+			}
+		}
+		assert.Equal(t, true, enabledCount > userCount*27/100)
+		assert.Equal(t, true, enabledCount < userCount*33/100)
+	})
+
+	t.Run("users enabled at lower percentage stay enabled at higher percentage", func(t *testing.T) {
+		t.Parallel()
+
+		lowPercentage := 10
+		lowRollout := Rollout{Enabled: true, Percentage: &lowPercentage}
+		for idx := 0; idx < 1000; idx++ {
+			user := entity.User{ID: fmt.Sprintf("user-%d", idx)}
+			if lowRollout.isEnabledFor("interstitial", &user) {
+				assert.Equal(t, true, rollout.isEnabledFor("interstitial", &user))
+			}
+		}
+	})
+
+	t.Run("anonymous user excluded from partial rollout", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, false, rollout.isEnabledFor("interstitial", nil))
+		assert.Equal(t, false, rollout.isEnabledFor("interstitial", &entity.User{}))
+	})
+}
+
+func TestBucket(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, bucket("interstitial", "alpha"), bucket("interstitial", "alpha"))
+
+	for idx := 0; idx < 1000; idx++ {
+		userBucket := bucket("interstitial", fmt.Sprintf("user-%d", idx))
+		assert.Equal(t, true, userBucket >= 0 && userBucket < bucketCount)
+	}
+}
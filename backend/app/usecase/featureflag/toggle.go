@@ -0,0 +1,39 @@
+package featureflag
+
+import "github.com/short-d/short/backend/app/entity"
+
+// Flag uniquely identifies a feature gated behind a toggle.
+type Flag string
+
+// The constants enumerate all the flags consulted by the use cases.
+const (
+	LinkHealthCheck  Flag = "link-health-check"
+	BrokenLinkReport Flag = "broken-link-report"
+	// ProbeInternalHosts lets the link health checks reach loopback and
+	// private network addresses, which are blocked by default to prevent
+	// server side request forgery.
+	ProbeInternalHosts Flag = "probe-internal-hosts"
+)
+
+var flags = []Flag{
+	LinkHealthCheck,
+	BrokenLinkReport,
+	ProbeInternalHosts,
+}
+
+// Toggle determines whether a feature is turned on, either for the whole
+// deployment or for a given user.
+type Toggle interface {
+	IsEnabled(flag Flag, user *entity.User) bool
+}
+
+// EnabledFlags lists the flags turned on for the whole deployment.
+func EnabledFlags(toggle Toggle) []Flag {
+	enabledFlags := []Flag{}
+	for _, flag := range flags {
+		if toggle.IsEnabled(flag, nil) {
+			enabledFlags = append(enabledFlags, flag)
+		}
+	}
+	return enabledFlags
+}
@@ -0,0 +1,20 @@
+package featureflag
+
+import "github.com/short-d/short/backend/app/entity"
+
+var _ Toggle = (*ToggleFake)(nil)
+
+// ToggleFake represents an in memory Toggle used for testing.
+type ToggleFake struct {
+	flags map[Flag]bool
+}
+
+// IsEnabled determines whether a feature is turned on regardless of the user.
+func (t ToggleFake) IsEnabled(flag Flag, user *entity.User) bool {
+	return t.flags[flag]
+}
+
+// NewToggleFake creates ToggleFake
+func NewToggleFake(flags map[Flag]bool) ToggleFake {
+	return ToggleFake{flags: flags}
+}
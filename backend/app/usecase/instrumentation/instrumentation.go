@@ -23,6 +23,7 @@ type Instrumentation struct {
 	redirectedAliasToLongLinkCh     chan ctx.ExecutionContext
 	longLinkRetrievalSucceedCh      chan ctx.ExecutionContext
 	longLinkRetrievalFailedCh       chan ctx.ExecutionContext
+	visitTrackingFailedCh           chan ctx.ExecutionContext
 	featureToggleRetrievalSucceedCh chan ctx.ExecutionContext
 	featureToggleRetrievalFailedCh  chan ctx.ExecutionContext
 	searchSucceedCh                 chan ctx.ExecutionContext
@@ -75,6 +76,16 @@ func (i Instrumentation) LongLinkRetrievalFailed(err error) {
 	}()
 }
 
+// VisitTrackingFailed tracks the failures when recording the visits of short
+// links.
+func (i Instrumentation) VisitTrackingFailed(err error) {
+	go func() {
+		c := <-i.visitTrackingFailedCh
+		i.logger.Error(err)
+		i.metrics.Count("visit-tracking-failed", 1, 1, c)
+	}()
+}
+
 // FeatureToggleRetrievalSucceed tracks the successes when retrieving the status
 // of the feature toggle.
 func (i Instrumentation) FeatureToggleRetrievalSucceed() {
@@ -151,6 +162,7 @@ func (i Instrumentation) Done() {
 	close(i.redirectedAliasToLongLinkCh)
 	close(i.longLinkRetrievalSucceedCh)
 	close(i.longLinkRetrievalFailedCh)
+	close(i.visitTrackingFailedCh)
 	close(i.featureToggleRetrievalSucceedCh)
 	close(i.featureToggleRetrievalFailedCh)
 }
@@ -174,6 +186,7 @@ func NewInstrumentation(
 	redirectedAliasToLongLinkCh := make(chan ctx.ExecutionContext)
 	longLinkRetrievalSucceedCh := make(chan ctx.ExecutionContext)
 	longLinkRetrievalFailedCh := make(chan ctx.ExecutionContext)
+	visitTrackingFailedCh := make(chan ctx.ExecutionContext)
 	featureToggleRetrievalSucceedCh := make(chan ctx.ExecutionContext)
 	featureToggleRetrievalFailedCh := make(chan ctx.ExecutionContext)
 	searchSucceedCh := make(chan ctx.ExecutionContext)
@@ -191,6 +204,7 @@ func NewInstrumentation(
 		redirectedAliasToLongLinkCh:     redirectedAliasToLongLinkCh,
 		longLinkRetrievalSucceedCh:      longLinkRetrievalSucceedCh,
 		longLinkRetrievalFailedCh:       longLinkRetrievalFailedCh,
+		visitTrackingFailedCh:           visitTrackingFailedCh,
 		featureToggleRetrievalSucceedCh: featureToggleRetrievalSucceedCh,
 		featureToggleRetrievalFailedCh:  featureToggleRetrievalFailedCh,
 		searchSucceedCh:                 searchSucceedCh,
@@ -204,6 +218,7 @@ func NewInstrumentation(
 		go func() { redirectedAliasToLongLinkCh <- c }()
 		go func() { longLinkRetrievalSucceedCh <- c }()
 		go func() { longLinkRetrievalFailedCh <- c }()
+		go func() { visitTrackingFailedCh <- c }()
 		go func() { featureToggleRetrievalSucceedCh <- c }()
 		go func() { featureToggleRetrievalFailedCh <- c }()
 		go func() { searchSucceedCh <- c }()
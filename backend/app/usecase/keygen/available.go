@@ -0,0 +1,106 @@
+package keygen
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+// maxAttempts limits how many candidates are tried before giving up when the
+// previous ones are taken by existing short links.
+const maxAttempts = 10
+
+// ErrKeyUnavailable represents no unused key can be produced.
+type ErrKeyUnavailable string
+
+func (e ErrKeyUnavailable) Error() string {
+	return fmt.Sprintf("no key available: %s", string(e))
+}
+
+// availableKeys hands out the candidate keys which are not used by any short
+// link yet, retrying with another candidate when the previous one is taken.
+type availableKeys struct {
+	shortLinkRepo repository.ShortLink
+	newCandidate  func() (Key, error)
+	peekedMutex   *sync.Mutex
+	// peeked holds the key produced by previewKey until it is handed out by
+	// newKey.
+	peeked *Key
+}
+
+func (a availableKeys) newKey() (Key, error) {
+	a.peekedMutex.Lock()
+	peeked := *a.peeked
+	*a.peeked = ""
+	a.peekedMutex.Unlock()
+
+	if peeked != "" {
+		isAvailable, err := a.isAvailable(peeked)
+		if err != nil {
+			return "", err
+		}
+		if isAvailable {
+			return peeked, nil
+		}
+	}
+	return a.nextKey()
+}
+
+func (a availableKeys) previewKey() (Key, error) {
+	a.peekedMutex.Lock()
+	peeked := *a.peeked
+	a.peekedMutex.Unlock()
+
+	if peeked != "" {
+		return peeked, nil
+	}
+
+	key, err := a.nextKey()
+	if err != nil {
+		return "", err
+	}
+
+	a.peekedMutex.Lock()
+	defer a.peekedMutex.Unlock()
+	*a.peeked = key
+	return key, nil
+}
+
+func (a availableKeys) nextKey() (Key, error) {
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		key, err := a.newCandidate()
+		if err != nil {
+			return "", err
+		}
+		isAvailable, err := a.isAvailable(key)
+		if err != nil {
+			return "", err
+		}
+		if isAvailable {
+			return key, nil
+		}
+	}
+	return "", ErrKeyUnavailable(fmt.Sprintf("all %d keys tried are taken", maxAttempts))
+}
+
+// isAvailable only checks the default tenant since keys are shared by all the
+// tenants. The keys taken in other tenants are retried on creation instead.
+func (a availableKeys) isAvailable(key Key) (bool, error) {
+	isExist, err := a.shortLinkRepo.IsAliasExist(context.Background(), string(key))
+	return !isExist, err
+}
+
+func newAvailableKeys(
+	shortLinkRepo repository.ShortLink,
+	newCandidate func() (Key, error),
+) availableKeys {
+	var peeked Key
+	return availableKeys{
+		shortLinkRepo: shortLinkRepo,
+		newCandidate:  newCandidate,
+		peekedMutex:   &sync.Mutex{},
+		peeked:        &peeked,
+	}
+}
@@ -0,0 +1,82 @@
+// +build !integration all
+
+package keygen
+
+import (
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+func TestAvailableKeys_NewKey(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		shortLinks    map[string]entity.ShortLink
+		candidates    []Key
+		hasErr        bool
+		expectedKey   Key
+		expectedTries int
+	}{
+		{
+			name:          "first candidate available",
+			shortLinks:    map[string]entity.ShortLink{},
+			candidates:    []Key{"abc", "def"},
+			hasErr:        false,
+			expectedKey:   "abc",
+			expectedTries: 1,
+		},
+		{
+			name: "retry on collision",
+			shortLinks: map[string]entity.ShortLink{
+				"abc": {Alias: "abc"},
+				"def": {Alias: "def"},
+			},
+			candidates:    []Key{"abc", "def", "ghi"},
+			hasErr:        false,
+			expectedKey:   "ghi",
+			expectedTries: 3,
+		},
+		{
+			name: "give up after max attempts",
+			shortLinks: map[string]entity.ShortLink{
+				"abc": {Alias: "abc"},
+			},
+			candidates: []Key{
+				"abc", "abc", "abc", "abc", "abc",
+				"abc", "abc", "abc", "abc", "abc",
+				"def",
+			},
+			hasErr:        true,
+			expectedTries: 10,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			tries := 0
+			newCandidate := func() (Key, error) {
+				candidate := testCase.candidates[tries]
+				tries++
+				return candidate, nil
+			}
+			shortLinkRepo := repository.NewShortLinkFake(nil, testCase.shortLinks)
+			keys := newAvailableKeys(&shortLinkRepo, newCandidate)
+
+			key, err := keys.newKey()
+			assert.Equal(t, testCase.expectedTries, tries)
+			if testCase.hasErr {
+				assert.Equal(t, ErrKeyUnavailable("all 10 keys tried are taken"), err)
+				return
+			}
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedKey, key)
+		})
+	}
+}
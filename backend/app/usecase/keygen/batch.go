@@ -0,0 +1,91 @@
+package keygen
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// refillInterval is how often the adaptive batches aim to refill the buffer.
+const refillInterval = time.Minute
+
+// batchSize decides how many keys are fetched from key generation service at
+// a time. The adaptive batch size follows how fast the previous batch was
+// consumed, so that the buffer is refilled about once every refillInterval:
+// bursty demand grows the batch to save round trips while steady low demand
+// shrinks it to avoid wasting keys. The size changes at most twofold per
+// refill to ride out short bursts, and always stays within the bounds.
+type batchSize struct {
+	mutex       sync.Mutex
+	size        int
+	minSize     int
+	maxSize     int
+	lastFetchAt time.Time
+}
+
+// next decides the size of the batch fetched at the given time.
+func (b *batchSize) next(now time.Time) int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.minSize == b.maxSize {
+		return b.size
+	}
+
+	if !b.lastFetchAt.IsZero() {
+		b.adapt(now.Sub(b.lastFetchAt))
+	}
+	b.lastFetchAt = now
+	return b.size
+}
+
+func (b *batchSize) adapt(consumedIn time.Duration) {
+	size := b.size * 2
+	if consumedIn > 0 {
+		rate := float64(b.size) / float64(consumedIn)
+		size = int(rate*float64(refillInterval) + 0.5)
+	}
+
+	size = clamp(size, b.size/2, b.size*2)
+	b.size = clamp(size, b.minSize, b.maxSize)
+}
+
+// capacity is the largest batch ever fetched.
+func (b *batchSize) capacity() int {
+	return b.maxSize
+}
+
+func clamp(value int, min int, max int) int {
+	if value < min {
+		return min
+	}
+	if value > max {
+		return max
+	}
+	return value
+}
+
+func newFixedBatchSize(size int) (*batchSize, error) {
+	if size < 1 {
+		return nil, errors.New("buffer size can't be less than 1")
+	}
+	return newBatchSize(size, size, size), nil
+}
+
+func newAdaptiveBatchSize(initialSize int, minSize int, maxSize int) (*batchSize, error) {
+	if minSize < 1 {
+		return nil, errors.New("min batch size can't be less than 1")
+	}
+	if maxSize < minSize {
+		return nil, errors.New("max batch size can't be less than min batch size")
+	}
+	return newBatchSize(clamp(initialSize, minSize, maxSize), minSize, maxSize), nil
+}
+
+func newBatchSize(size int, minSize int, maxSize int) *batchSize {
+	return &batchSize{
+		size:    size,
+		minSize: minSize,
+		maxSize: maxSize,
+	}
+}
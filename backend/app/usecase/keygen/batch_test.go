@@ -0,0 +1,140 @@
+// +build !integration all
+
+package keygen
+
+import (
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+)
+
+func TestBatchSize_Next(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		initialSize   int
+		minSize       int
+		maxSize       int
+		fetchInterval []time.Duration
+		expectedSizes []int
+	}{
+		{
+			name:          "fixed size",
+			initialSize:   10,
+			minSize:       10,
+			maxSize:       10,
+			fetchInterval: []time.Duration{0, time.Second, time.Hour},
+			expectedSizes: []int{10, 10, 10},
+		},
+		{
+			name:        "bursty demand grows to max",
+			initialSize: 10,
+			minSize:     5,
+			maxSize:     100,
+			fetchInterval: []time.Duration{
+				0,
+				time.Second,
+				time.Second,
+				time.Second,
+				time.Second,
+				time.Second,
+			},
+			expectedSizes: []int{10, 20, 40, 80, 100, 100},
+		},
+		{
+			name:        "steady demand settles",
+			initialSize: 10,
+			minSize:     5,
+			maxSize:     100,
+			fetchInterval: []time.Duration{
+				0,
+				20 * time.Second,
+				30 * time.Second,
+				time.Minute,
+				time.Minute,
+			},
+			expectedSizes: []int{10, 20, 40, 40, 40},
+		},
+		{
+			name:        "low demand shrinks to min",
+			initialSize: 40,
+			minSize:     5,
+			maxSize:     100,
+			fetchInterval: []time.Duration{
+				0,
+				10 * time.Minute,
+				10 * time.Minute,
+				10 * time.Minute,
+				10 * time.Minute,
+			},
+			expectedSizes: []int{40, 20, 10, 5, 5},
+		},
+		{
+			name:        "burst after low demand",
+			initialSize: 10,
+			minSize:     5,
+			maxSize:     100,
+			fetchInterval: []time.Duration{
+				0,
+				time.Hour,
+				time.Millisecond,
+				0,
+				time.Minute,
+			},
+			expectedSizes: []int{10, 5, 10, 20, 20},
+		},
+		{
+			name:          "initial size out of bounds",
+			initialSize:   500,
+			minSize:       5,
+			maxSize:       100,
+			fetchInterval: []time.Duration{0},
+			expectedSizes: []int{100},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			size, err := newAdaptiveBatchSize(testCase.initialSize, testCase.minSize, testCase.maxSize)
+			assert.Equal(t, nil, err)
+
+			now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+			var sizes []int
+			for _, interval := range testCase.fetchInterval {
+				now = now.Add(interval)
+				sizes = append(sizes, size.next(now))
+			}
+			assert.Equal(t, testCase.expectedSizes, sizes)
+		})
+	}
+}
+
+func TestNewAdaptiveBatchSize(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		minSize int
+		maxSize int
+		hasErr  bool
+	}{
+		{name: "valid bounds", minSize: 1, maxSize: 10, hasErr: false},
+		{name: "min less than 1", minSize: 0, maxSize: 10, hasErr: true},
+		{name: "max less than min", minSize: 10, maxSize: 5, hasErr: true},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := newAdaptiveBatchSize(5, testCase.minSize, testCase.maxSize)
+			assert.Equal(t, testCase.hasErr, err != nil)
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package keygen
+
+import (
+	"crypto/rand"
+	"math/big"
+
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+// cryptoRandomKeyLength keeps collisions unlikely: 62^8 is roughly 2.2e14.
+const cryptoRandomKeyLength = 8
+
+var _ KeyGenerator = (*CryptoRandom)(nil)
+
+// CryptoRandom produces unpredictable base62 keys from a cryptographically
+// secure random source without relying on the key generation service. Keys
+// used by existing short links are skipped, so CryptoRandom only suits the
+// aliases of short links.
+type CryptoRandom struct {
+	availableKeys availableKeys
+}
+
+// NewKey produces a key not used by any short link yet.
+func (c CryptoRandom) NewKey() (Key, error) {
+	return c.availableKeys.newKey()
+}
+
+// PreviewKey returns the key NewKey will produce next without consuming it.
+// NewKey produces a different key if the previewed one is taken in between.
+func (c CryptoRandom) PreviewKey() (Key, error) {
+	return c.availableKeys.previewKey()
+}
+
+func randomKey() (Key, error) {
+	alphabetSize := big.NewInt(int64(len(base62Alphabet)))
+	key := make([]byte, cryptoRandomKeyLength)
+	for idx := range key {
+		charIdx, err := rand.Int(rand.Reader, alphabetSize)
+		if err != nil {
+			return "", err
+		}
+		key[idx] = base62Alphabet[charIdx.Int64()]
+	}
+	return Key(key), nil
+}
+
+// NewCryptoRandom creates CryptoRandom key generator. The keys start with
+// prefix, which is part of the aliases checked for availability.
+func NewCryptoRandom(shortLinkRepo repository.ShortLink, prefix string) CryptoRandom {
+	return CryptoRandom{
+		availableKeys: newAvailableKeys(shortLinkRepo, withPrefix(prefix, randomKey)),
+	}
+}
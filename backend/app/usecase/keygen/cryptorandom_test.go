@@ -0,0 +1,59 @@
+// +build !integration all
+
+package keygen
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+func TestCryptoRandom_NewKey(t *testing.T) {
+	t.Parallel()
+
+	keyPattern := regexp.MustCompile(`^[0-9a-zA-Z]{8}$`)
+	shortLinkRepo := repository.NewShortLinkFake(nil, map[string]entity.ShortLink{})
+	cryptoRandom := NewCryptoRandom(&shortLinkRepo, "")
+
+	keys := make(map[Key]bool)
+	for i := 0; i < 1000; i++ {
+		key, err := cryptoRandom.NewKey()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, keyPattern.MatchString(string(key)))
+		assert.Equal(t, false, keys[key])
+		keys[key] = true
+	}
+}
+
+func TestCryptoRandom_PreviewKey(t *testing.T) {
+	t.Parallel()
+
+	shortLinkRepo := repository.NewShortLinkFake(nil, map[string]entity.ShortLink{})
+	cryptoRandom := NewCryptoRandom(&shortLinkRepo, "")
+
+	previewKey, err := cryptoRandom.PreviewKey()
+	assert.Equal(t, nil, err)
+	previewKeyAgain, err := cryptoRandom.PreviewKey()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, previewKey, previewKeyAgain)
+
+	key, err := cryptoRandom.NewKey()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, previewKey, key)
+
+	previewKey, err = cryptoRandom.PreviewKey()
+	assert.Equal(t, nil, err)
+	alias := string(previewKey)
+	err = shortLinkRepo.CreateShortLink(context.Background(), entity.ShortLinkInput{
+		CustomAlias: &alias,
+	})
+	assert.Equal(t, nil, err)
+
+	key, err = cryptoRandom.NewKey()
+	assert.Equal(t, nil, err)
+	assert.NotEqual(t, previewKey, key)
+}
@@ -0,0 +1,95 @@
+package keygen
+
+import (
+	"bytes"
+	"math"
+	"strings"
+
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+const (
+	hashidsAlphabet     = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ1234567890"
+	hashidsSeparators   = "cfhistuCFHISTU"
+	hashidsGuardDivisor = 12
+)
+
+var _ KeyGenerator = (*Hashids)(nil)
+
+// Hashids produces keys by encoding an incrementing counter with hashids
+// algorithm. Different salts produce different keys for the same counter.
+//
+// See https://hashids.org for details.
+type Hashids struct {
+	keyCounter repository.KeyCounter
+	salt       string
+	alphabet   string
+}
+
+// NewKey produces a unique key
+func (h Hashids) NewKey() (Key, error) {
+	id, err := h.keyCounter.NextKeyID()
+	if err != nil {
+		return "", err
+	}
+	return Key(h.encode(id)), nil
+}
+
+// PreviewKey returns the key NewKey is expected to produce next without
+// consuming it.
+func (h Hashids) PreviewKey() (Key, error) {
+	id, err := h.keyCounter.PeekKeyID()
+	if err != nil {
+		return "", err
+	}
+	return Key(h.encode(id)), nil
+}
+
+// encode converts a number into a hashid with the same output as the reference
+// implementations.
+func (h Hashids) encode(num uint64) string {
+	alphabet := []byte(h.alphabet)
+	lottery := alphabet[num%100%uint64(len(alphabet))]
+
+	buffer := string(lottery) + h.salt + string(alphabet)
+	shuffle(alphabet, buffer[:len(alphabet)])
+	return string(lottery) + encode(num, string(alphabet))
+}
+
+// shuffle reorders alphabet in place deterministically given salt.
+func shuffle(alphabet []byte, salt string) {
+	if len(salt) == 0 {
+		return
+	}
+
+	for i, v, p := len(alphabet)-1, 0, 0; i > 0; i, v = i-1, v+1 {
+		v %= len(salt)
+		integer := int(salt[v])
+		p += integer
+		j := (integer + v + p) % i
+		alphabet[i], alphabet[j] = alphabet[j], alphabet[i]
+	}
+}
+
+// NewHashids creates Hashids key generator
+func NewHashids(keyCounter repository.KeyCounter, salt string) Hashids {
+	// Separators are only used to join multiple numbers in a hashid and guards
+	// are only used to pad a hashid to a minimum length. Neither is needed
+	// here, but both still have to be taken out of the alphabet to stay
+	// compatible with other implementations.
+	alphabet := []byte(hashidsAlphabet)
+	alphabet = bytes.Map(func(char rune) rune {
+		if strings.ContainsRune(hashidsSeparators, char) {
+			return -1
+		}
+		return char
+	}, alphabet)
+	shuffle(alphabet, salt)
+
+	guardsLength := int(math.Ceil(float64(len(alphabet)) / hashidsGuardDivisor))
+	return Hashids{
+		keyCounter: keyCounter,
+		salt:       salt,
+		alphabet:   string(alphabet[guardsLength:]),
+	}
+}
@@ -0,0 +1,97 @@
+// +build !integration all
+
+package keygen
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+func TestHashids_NewKey(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		salt        string
+		count       uint64
+		expectedKey Key
+	}{
+		{
+			name:        "without salt",
+			salt:        "",
+			count:       0,
+			expectedKey: "jR",
+		},
+		{
+			name:        "with salt",
+			salt:        "this is my salt",
+			count:       12344,
+			expectedKey: "NkK9",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			keyCounter := repository.NewKeyCounterFake(testCase.count)
+			hashids := NewHashids(keyCounter, testCase.salt)
+
+			key, err := hashids.NewKey()
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedKey, key)
+		})
+	}
+}
+
+func TestHashids_NewKeyUnique(t *testing.T) {
+	t.Parallel()
+
+	keyCounter := repository.NewKeyCounterFake(0)
+	hashids := NewHashids(keyCounter, "short")
+	keyPattern := regexp.MustCompile(`^[0-9a-zA-Z]{2,4}$`)
+
+	keys := make(map[Key]bool)
+	for i := 0; i < 10000; i++ {
+		key, err := hashids.NewKey()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, keyPattern.MatchString(string(key)))
+		assert.Equal(t, false, keys[key])
+		keys[key] = true
+	}
+}
+
+func TestHashids_NewKeySalt(t *testing.T) {
+	t.Parallel()
+
+	hashids1 := NewHashids(repository.NewKeyCounterFake(0), "salt1")
+	hashids2 := NewHashids(repository.NewKeyCounterFake(0), "salt2")
+
+	key1, err := hashids1.NewKey()
+	assert.Equal(t, nil, err)
+	key2, err := hashids2.NewKey()
+	assert.Equal(t, nil, err)
+	assert.NotEqual(t, key1, key2)
+}
+
+func TestHashids_PreviewKey(t *testing.T) {
+	t.Parallel()
+
+	hashids := NewHashids(repository.NewKeyCounterFake(0), "")
+
+	previewedKey, err := hashids.PreviewKey()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, Key("jR"), previewedKey)
+
+	previewedKey, err = hashids.PreviewKey()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, Key("jR"), previewedKey)
+
+	key, err := hashids.NewKey()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, previewedKey, key)
+}
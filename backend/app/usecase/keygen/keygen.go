@@ -1,60 +1,30 @@
 package keygen
 
-import (
-	"errors"
-)
-
-type bufferEntry struct {
-	key Key
-	err error
-}
-
-// KeyGenerator fetches unique keys in batch from key generation service
-// and buffer them in memory for fast response.
-type KeyGenerator struct {
-	bufferSize int
-	buffer     chan bufferEntry
-	keyFetcher KeyFetcher
-}
-
-// NewKey produces a unique key
-func (r KeyGenerator) NewKey() (Key, error) {
-	if len(r.buffer) == 0 {
-		go func() {
-			r.fetchKeys()
-		}()
-	}
-
-	entry := <-r.buffer
-	return entry.key, entry.err
-}
-
-func (r KeyGenerator) fetchKeys() {
-	keys, err := r.keyFetcher.FetchKeys(r.bufferSize)
-	if err != nil {
-		r.buffer <- bufferEntry{
-			key: "",
-			err: err,
-		}
-		return
-	}
-
-	for _, key := range keys {
-		r.buffer <- bufferEntry{
-			key: key,
-			err: nil,
-		}
-	}
+// KeyGenerator produces unique keys. PreviewKey returns the key the next
+// NewKey call is expected to produce without consuming it.
+type KeyGenerator interface {
+	NewKey() (Key, error)
+	PreviewKey() (Key, error)
 }
 
-// NewKeyGenerator creates KeyGenerator
-func NewKeyGenerator(bufferSize int, keyFetcher KeyFetcher) (KeyGenerator, error) {
-	if bufferSize < 1 {
-		return KeyGenerator{}, errors.New("buffer size can't be less than 1")
-	}
-	return KeyGenerator{
-		bufferSize: bufferSize,
-		buffer:     make(chan bufferEntry, bufferSize),
-		keyFetcher: keyFetcher,
-	}, nil
-}
+// Strategy represents how keys are generated.
+type Strategy string
+
+// The constants enumerate all supported key generation strategies.
+const (
+	// StrategyRandom produces unpredictable keys pre-generated by the key
+	// generation service.
+	StrategyRandom Strategy = "random"
+	// StrategySequential produces the shortest keys by encoding an
+	// incrementing counter.
+	StrategySequential Strategy = "sequential"
+	// StrategyHashids produces keys by encoding an incrementing counter with
+	// hashids, making them look random without needing to be stored.
+	StrategyHashids Strategy = "hashids"
+	// StrategyWords produces human readable keys by joining randomly picked
+	// words, retrying when the key is taken.
+	StrategyWords Strategy = "words"
+	// StrategyCryptoRandom produces unpredictable keys from a
+	// cryptographically secure random source, retrying when the key is taken.
+	StrategyCryptoRandom Strategy = "crypto_random"
+)
@@ -0,0 +1,42 @@
+package keygen
+
+var _ KeyGenerator = (*Prefixed)(nil)
+
+// Prefixed prepends a fixed prefix, such as the region of the deployment, to
+// the keys produced by another key generator so that the keys produced by
+// deployments with different prefixes never collide.
+type Prefixed struct {
+	keyGen KeyGenerator
+	prefix string
+}
+
+// NewKey produces a unique key starting with the prefix.
+func (p Prefixed) NewKey() (Key, error) {
+	return withPrefix(p.prefix, p.keyGen.NewKey)()
+}
+
+// PreviewKey returns the key NewKey is expected to produce next without
+// consuming it.
+func (p Prefixed) PreviewKey() (Key, error) {
+	return withPrefix(p.prefix, p.keyGen.PreviewKey)()
+}
+
+// withPrefix prepends prefix to the keys produced by newKey.
+func withPrefix(prefix string, newKey func() (Key, error)) func() (Key, error) {
+	return func() (Key, error) {
+		key, err := newKey()
+		if err != nil {
+			return "", err
+		}
+		return Key(prefix) + key, nil
+	}
+}
+
+// NewPrefixed creates Prefixed key generator which prepends prefix to the keys
+// produced by keyGen.
+func NewPrefixed(keyGen KeyGenerator, prefix string) Prefixed {
+	return Prefixed{
+		keyGen: keyGen,
+		prefix: prefix,
+	}
+}
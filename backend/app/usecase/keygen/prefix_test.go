@@ -0,0 +1,71 @@
+// +build !integration all
+
+package keygen
+
+import (
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+func TestPrefixed_NewKey(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name         string
+		prefix       string
+		expectedKeys []Key
+	}{
+		{
+			name:         "empty prefix",
+			prefix:       "",
+			expectedKeys: []Key{"1", "2", "3"},
+		},
+		{
+			name:         "prefix prepended",
+			prefix:       "eu-",
+			expectedKeys: []Key{"eu-1", "eu-2", "eu-3"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			keyGen := NewPrefixed(NewSequential(repository.NewKeyCounterFake(0)), testCase.prefix)
+
+			for _, expectedKey := range testCase.expectedKeys {
+				previewKey, err := keyGen.PreviewKey()
+				assert.Equal(t, nil, err)
+				assert.Equal(t, expectedKey, previewKey)
+
+				key, err := keyGen.NewKey()
+				assert.Equal(t, nil, err)
+				assert.Equal(t, expectedKey, key)
+			}
+		})
+	}
+}
+
+func TestPrefixed_NewKeyNoCollision(t *testing.T) {
+	t.Parallel()
+
+	euKeyGen := NewPrefixed(NewSequential(repository.NewKeyCounterFake(0)), "eu-")
+	usKeyGen := NewPrefixed(NewSequential(repository.NewKeyCounterFake(0)), "us-")
+
+	keys := make(map[Key]bool)
+	for i := 0; i < 100; i++ {
+		euKey, err := euKeyGen.NewKey()
+		assert.Equal(t, nil, err)
+		usKey, err := usKeyGen.NewKey()
+		assert.Equal(t, nil, err)
+
+		assert.Equal(t, false, keys[euKey])
+		assert.Equal(t, false, keys[usKey])
+		keys[euKey] = true
+		keys[usKey] = true
+	}
+	assert.Equal(t, 200, len(keys))
+}
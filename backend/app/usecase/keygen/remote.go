@@ -0,0 +1,133 @@
+package keygen
+
+import (
+	"sync"
+
+	"github.com/short-d/app/fw/timer"
+)
+
+type bufferEntry struct {
+	key Key
+	err error
+}
+
+var _ KeyGenerator = (*Remote)(nil)
+
+// Remote fetches unique keys in batch from key generation service
+// and buffer them in memory for fast response. The batches are either fixed
+// in size or adapt to the demand.
+type Remote struct {
+	batchSize  *batchSize
+	timer      timer.Timer
+	buffer     chan bufferEntry
+	keyFetcher KeyFetcher
+	// peeked holds the key taken out of the buffer by PreviewKey until it is
+	// handed out by NewKey.
+	peeked      *Key
+	peekedMutex *sync.Mutex
+}
+
+// NewKey produces a unique key
+func (r Remote) NewKey() (Key, error) {
+	r.peekedMutex.Lock()
+	if *r.peeked != "" {
+		key := *r.peeked
+		*r.peeked = ""
+		r.peekedMutex.Unlock()
+		return key, nil
+	}
+	r.peekedMutex.Unlock()
+
+	entry := r.nextEntry()
+	return entry.key, entry.err
+}
+
+// PreviewKey returns the key NewKey will produce next without consuming it.
+func (r Remote) PreviewKey() (Key, error) {
+	r.peekedMutex.Lock()
+	defer r.peekedMutex.Unlock()
+
+	if *r.peeked != "" {
+		return *r.peeked, nil
+	}
+
+	entry := r.nextEntry()
+	if entry.err != nil {
+		return "", entry.err
+	}
+	*r.peeked = entry.key
+	return entry.key, nil
+}
+
+func (r Remote) nextEntry() bufferEntry {
+	if len(r.buffer) == 0 {
+		go func() {
+			r.fetchKeys()
+		}()
+	}
+
+	return <-r.buffer
+}
+
+func (r Remote) fetchKeys() {
+	keys, err := r.keyFetcher.FetchKeys(r.nextBatchSize())
+	if err != nil {
+		r.buffer <- bufferEntry{
+			key: "",
+			err: err,
+		}
+		return
+	}
+
+	for _, key := range keys {
+		r.buffer <- bufferEntry{
+			key: key,
+			err: nil,
+		}
+	}
+}
+
+func (r Remote) nextBatchSize() int {
+	if r.timer == nil {
+		return r.batchSize.size
+	}
+	return r.batchSize.next(r.timer.Now())
+}
+
+// NewRemote creates Remote key generator fetching bufferSize keys at a time.
+func NewRemote(bufferSize int, keyFetcher KeyFetcher) (Remote, error) {
+	size, err := newFixedBatchSize(bufferSize)
+	if err != nil {
+		return Remote{}, err
+	}
+	return newRemote(size, nil, keyFetcher), nil
+}
+
+// NewAdaptiveRemote creates Remote key generator fetching initialSize keys at
+// first. The following batches adapt to how fast the keys are consumed,
+// within minSize and maxSize.
+func NewAdaptiveRemote(
+	initialSize int,
+	minSize int,
+	maxSize int,
+	timer timer.Timer,
+	keyFetcher KeyFetcher,
+) (Remote, error) {
+	size, err := newAdaptiveBatchSize(initialSize, minSize, maxSize)
+	if err != nil {
+		return Remote{}, err
+	}
+	return newRemote(size, timer, keyFetcher), nil
+}
+
+func newRemote(size *batchSize, timer timer.Timer, keyFetcher KeyFetcher) Remote {
+	var peeked Key
+	return Remote{
+		batchSize:   size,
+		timer:       timer,
+		buffer:      make(chan bufferEntry, size.capacity()),
+		keyFetcher:  keyFetcher,
+		peeked:      &peeked,
+		peekedMutex: &sync.Mutex{},
+	}
+}
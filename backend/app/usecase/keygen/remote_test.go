@@ -0,0 +1,184 @@
+// +build !integration all
+
+package keygen
+
+import (
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/timer"
+)
+
+func TestNewRemote(t *testing.T) {
+	t.Parallel()
+
+	keyFetcher := NewKeyFetcherFake([]Key{})
+	_, err := NewRemote(0, &keyFetcher)
+	assert.NotEqual(t, nil, err)
+}
+
+func TestNewAdaptiveRemote(t *testing.T) {
+	t.Parallel()
+
+	keyFetcher := NewKeyFetcherFake([]Key{})
+	_, err := NewAdaptiveRemote(2, 0, 4, timer.NewStub(time.Now()), &keyFetcher)
+	assert.NotEqual(t, nil, err)
+
+	_, err = NewAdaptiveRemote(2, 4, 2, timer.NewStub(time.Now()), &keyFetcher)
+	assert.NotEqual(t, nil, err)
+}
+
+func TestRemote_NewKeyAdaptive(t *testing.T) {
+	t.Parallel()
+
+	availableKeys := []Key{"0K", "0L", "0M", "0N", "0O", "0P", "0Q"}
+	keyFetcher := NewKeyFetcherFake(availableKeys)
+	remote, err := NewAdaptiveRemote(2, 1, 4, timer.NewStub(time.Now()), &keyFetcher)
+	assert.Equal(t, nil, err)
+
+	for _, expectedKey := range availableKeys {
+		key, err := remote.NewKey()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, expectedKey, key)
+	}
+	assert.Equal(t, 4, remote.batchSize.size)
+
+	_, err = remote.NewKey()
+	assert.NotEqual(t, nil, err)
+}
+
+func TestRemote_NewKey(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name              string
+		availableKeys     []Key
+		bufferSize        int
+		expectedGetKeyOps int
+		expectedHasErrs   []bool
+		expectedKeys      []Key
+	}{
+		{
+			name: "buffer size is 2",
+			availableKeys: []Key{
+				Key("0K"),
+				Key("0L"),
+			},
+			bufferSize:        2,
+			expectedGetKeyOps: 2,
+			expectedHasErrs: []bool{
+				false,
+				false,
+			},
+			expectedKeys: []Key{
+				Key("0K"),
+				Key("0L"),
+			},
+		},
+		{
+			name:              "no key available at beginning",
+			availableKeys:     []Key{},
+			bufferSize:        2,
+			expectedGetKeyOps: 1,
+			expectedHasErrs: []bool{
+				true,
+			},
+			expectedKeys: []Key{
+				Key(""),
+			},
+		},
+		{
+			name: "run out of key",
+			availableKeys: []Key{
+				Key("0K"),
+				Key("0L"),
+			},
+			bufferSize:        2,
+			expectedGetKeyOps: 3,
+			expectedHasErrs: []bool{
+				false,
+				false,
+				true,
+			},
+			expectedKeys: []Key{
+				Key("0K"),
+				Key("0L"),
+				Key(""),
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			keyFetcher := NewKeyFetcherFake(testCase.availableKeys)
+			remote, err := NewRemote(testCase.bufferSize, &keyFetcher)
+			assert.Equal(t, nil, err)
+
+			for idx := 0; idx < testCase.expectedGetKeyOps; idx++ {
+				key, err := remote.NewKey()
+
+				if testCase.expectedHasErrs[idx] {
+					assert.NotEqual(t, nil, err)
+					continue
+				}
+				assert.Equal(t, testCase.expectedKeys[idx], key)
+			}
+		})
+	}
+}
+
+func TestRemote_PreviewKey(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		availableKeys []Key
+		hasErr        bool
+		expectedKeys  []Key
+	}{
+		{
+			name:          "preview keeps the key for NewKey",
+			availableKeys: []Key{"0K", "0L"},
+			expectedKeys:  []Key{"0K", "0L"},
+		},
+		{
+			name:          "no key available",
+			availableKeys: []Key{},
+			hasErr:        true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			keyFetcher := NewKeyFetcherFake(testCase.availableKeys)
+			remote, err := NewRemote(2, &keyFetcher)
+			assert.Equal(t, nil, err)
+
+			for _, expectedKey := range testCase.expectedKeys {
+				previewedKey, err := remote.PreviewKey()
+				assert.Equal(t, nil, err)
+				assert.Equal(t, expectedKey, previewedKey)
+
+				previewedKey, err = remote.PreviewKey()
+				assert.Equal(t, nil, err)
+				assert.Equal(t, expectedKey, previewedKey)
+
+				key, err := remote.NewKey()
+				assert.Equal(t, nil, err)
+				assert.Equal(t, expectedKey, key)
+			}
+
+			if testCase.hasErr {
+				_, err := remote.PreviewKey()
+				assert.NotEqual(t, nil, err)
+			}
+		})
+	}
+}
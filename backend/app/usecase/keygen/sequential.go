@@ -0,0 +1,50 @@
+package keygen
+
+import "github.com/short-d/short/backend/app/usecase/repository"
+
+const base62Alphabet = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+var _ KeyGenerator = (*Sequential)(nil)
+
+// Sequential produces keys by encoding an incrementing counter in base 62.
+type Sequential struct {
+	keyCounter repository.KeyCounter
+}
+
+// NewKey produces a unique key
+func (s Sequential) NewKey() (Key, error) {
+	id, err := s.keyCounter.NextKeyID()
+	if err != nil {
+		return "", err
+	}
+	return Key(encode(id, base62Alphabet)), nil
+}
+
+// PreviewKey returns the key NewKey is expected to produce next without
+// consuming it.
+func (s Sequential) PreviewKey() (Key, error) {
+	id, err := s.keyCounter.PeekKeyID()
+	if err != nil {
+		return "", err
+	}
+	return Key(encode(id, base62Alphabet)), nil
+}
+
+// encode converts num to its representation in the base of the length of
+// alphabet.
+func encode(num uint64, alphabet string) string {
+	base := uint64(len(alphabet))
+	var buf []byte
+	for {
+		buf = append([]byte{alphabet[num%base]}, buf...)
+		num /= base
+		if num == 0 {
+			return string(buf)
+		}
+	}
+}
+
+// NewSequential creates Sequential key generator
+func NewSequential(keyCounter repository.KeyCounter) Sequential {
+	return Sequential{keyCounter: keyCounter}
+}
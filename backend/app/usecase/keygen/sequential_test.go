@@ -0,0 +1,90 @@
+// +build !integration all
+
+package keygen
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+func TestSequential_NewKey(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name         string
+		count        uint64
+		expectedKeys []Key
+	}{
+		{
+			name:         "single digit",
+			count:        0,
+			expectedKeys: []Key{"1", "2", "3"},
+		},
+		{
+			name:         "letters after digits",
+			count:        9,
+			expectedKeys: []Key{"a", "b"},
+		},
+		{
+			name:         "carry over",
+			count:        60,
+			expectedKeys: []Key{"Z", "10", "11"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			keyCounter := repository.NewKeyCounterFake(testCase.count)
+			sequential := NewSequential(keyCounter)
+
+			for _, expectedKey := range testCase.expectedKeys {
+				key, err := sequential.NewKey()
+				assert.Equal(t, nil, err)
+				assert.Equal(t, expectedKey, key)
+			}
+		})
+	}
+}
+
+func TestSequential_NewKeyUnique(t *testing.T) {
+	t.Parallel()
+
+	keyCounter := repository.NewKeyCounterFake(0)
+	sequential := NewSequential(keyCounter)
+	keyPattern := regexp.MustCompile(`^[0-9a-zA-Z]{1,3}$`)
+
+	keys := make(map[Key]bool)
+	for i := 0; i < 10000; i++ {
+		key, err := sequential.NewKey()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, keyPattern.MatchString(string(key)))
+		assert.Equal(t, false, keys[key])
+		keys[key] = true
+	}
+}
+
+func TestSequential_PreviewKey(t *testing.T) {
+	t.Parallel()
+
+	sequential := NewSequential(repository.NewKeyCounterFake(60))
+
+	for _, expectedKey := range []Key{"Z", "10"} {
+		previewedKey, err := sequential.PreviewKey()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, expectedKey, previewedKey)
+
+		previewedKey, err = sequential.PreviewKey()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, expectedKey, previewedKey)
+
+		key, err := sequential.NewKey()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, expectedKey, key)
+	}
+}
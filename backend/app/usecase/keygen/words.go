@@ -0,0 +1,132 @@
+package keygen
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/short-d/short/backend/app/fw/filesystem"
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+const wordListCommentPrefix = "#"
+
+var _ KeyGenerator = (*Words)(nil)
+
+// Words produces human readable keys, such as happy-blue-tiger, by joining
+// randomly picked words from a word list. Keys used by existing short links
+// are skipped, so Words only suits the aliases of short links.
+type Words struct {
+	availableKeys availableKeys
+	words         []string
+	wordCount     int
+	separator     string
+	random        *rand.Rand
+	// randomMutex guards random, which is not safe for concurrent use.
+	randomMutex *sync.Mutex
+}
+
+// NewKey produces a key not used by any short link yet.
+func (w Words) NewKey() (Key, error) {
+	return w.availableKeys.newKey()
+}
+
+// PreviewKey returns the key NewKey will produce next without consuming it.
+// NewKey produces a different key if the previewed one is taken in between.
+func (w Words) PreviewKey() (Key, error) {
+	return w.availableKeys.previewKey()
+}
+
+func (w Words) pickWords() (Key, error) {
+	w.randomMutex.Lock()
+	defer w.randomMutex.Unlock()
+
+	picked := make([]string, w.wordCount)
+	for idx := range picked {
+		picked[idx] = w.words[w.random.Intn(len(w.words))]
+	}
+	return Key(strings.Join(picked, w.separator)), nil
+}
+
+// ReadWordList reads the words from the file at the given path, one word per
+// line. Blank lines and lines starting with "#" are ignored.
+func ReadWordList(fileSystem filesystem.FileSystem, path string) ([]string, error) {
+	buf, err := fileSystem.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var words []string
+	scanner := bufio.NewScanner(bytes.NewReader(buf))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, wordListCommentPrefix) {
+			continue
+		}
+		words = append(words, line)
+	}
+	return words, scanner.Err()
+}
+
+func isWord(word string) bool {
+	if word == "" {
+		return false
+	}
+	for _, char := range word {
+		if !unicode.IsLetter(char) && !unicode.IsDigit(char) {
+			return false
+		}
+	}
+	return true
+}
+
+// NewWords creates Words key generator which joins wordCount words from the
+// given word list with separator. Duplicated words are only picked once. The
+// keys start with prefix, which is part of the aliases checked for
+// availability.
+func NewWords(
+	shortLinkRepo repository.ShortLink,
+	words []string,
+	wordCount int,
+	separator string,
+	prefix string,
+) (Words, error) {
+	if wordCount < 1 {
+		return Words{}, errors.New("word count can't be less than 1")
+	}
+
+	seen := make(map[string]bool)
+	var uniqueWords []string
+	for _, word := range words {
+		if !isWord(word) {
+			return Words{}, fmt.Errorf("word %s can only contain letters and digits", word)
+		}
+		if separator != "" && strings.Contains(word, separator) {
+			return Words{}, fmt.Errorf("word %s can't contain separator %s", word, separator)
+		}
+		if seen[word] {
+			continue
+		}
+		seen[word] = true
+		uniqueWords = append(uniqueWords, word)
+	}
+	if len(uniqueWords) < 2 {
+		return Words{}, errors.New("word list needs at least 2 different words")
+	}
+
+	generator := Words{
+		words:       uniqueWords,
+		wordCount:   wordCount,
+		separator:   separator,
+		random:      rand.New(rand.NewSource(time.Now().UnixNano())),
+		randomMutex: &sync.Mutex{},
+	}
+	generator.availableKeys = newAvailableKeys(shortLinkRepo, withPrefix(prefix, generator.pickWords))
+	return generator, nil
+}
@@ -0,0 +1,280 @@
+// +build !integration all
+
+package keygen
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/fw/filesystem"
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+func TestWords_NewKey(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		words      []string
+		wordCount  int
+		separator  string
+		keyPattern *regexp.Regexp
+	}{
+		{
+			name:       "three words joined by dashes",
+			words:      []string{"happy", "blue", "tiger"},
+			wordCount:  3,
+			separator:  "-",
+			keyPattern: regexp.MustCompile(`^(happy|blue|tiger)-(happy|blue|tiger)-(happy|blue|tiger)$`),
+		},
+		{
+			name:       "two words joined by underscore",
+			words:      []string{"happy", "blue", "tiger"},
+			wordCount:  2,
+			separator:  "_",
+			keyPattern: regexp.MustCompile(`^(happy|blue|tiger)_(happy|blue|tiger)$`),
+		},
+		{
+			name:       "words without separator",
+			words:      []string{"happy", "blue", "tiger"},
+			wordCount:  2,
+			separator:  "",
+			keyPattern: regexp.MustCompile(`^(happy|blue|tiger)(happy|blue|tiger)$`),
+		},
+		{
+			name:       "single word",
+			words:      []string{"happy", "blue", "tiger"},
+			wordCount:  1,
+			separator:  "-",
+			keyPattern: regexp.MustCompile(`^(happy|blue|tiger)$`),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			shortLinkRepo := repository.NewShortLinkFake(nil, map[string]entity.ShortLink{})
+			words, err := NewWords(&shortLinkRepo, testCase.words, testCase.wordCount, testCase.separator, "")
+			assert.Equal(t, nil, err)
+
+			for i := 0; i < 20; i++ {
+				key, err := words.NewKey()
+				assert.Equal(t, nil, err)
+				assert.Equal(t, true, testCase.keyPattern.MatchString(string(key)))
+			}
+		})
+	}
+}
+
+func TestWords_NewKeyUnique(t *testing.T) {
+	t.Parallel()
+
+	shortLinkRepo := repository.NewShortLinkFake(nil, map[string]entity.ShortLink{})
+	wordList := []string{
+		"happy", "calm", "brave", "lucky", "swift",
+		"blue", "green", "red", "gold", "teal",
+		"tiger", "otter", "panda", "crane", "koala",
+	}
+	words, err := NewWords(&shortLinkRepo, wordList, 3, "-", "")
+	assert.Equal(t, nil, err)
+
+	keys := make(map[Key]bool)
+	for i := 0; i < 1000; i++ {
+		key, err := words.NewKey()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, false, keys[key])
+		keys[key] = true
+
+		alias := string(key)
+		err = shortLinkRepo.CreateShortLink(context.Background(), entity.ShortLinkInput{
+			CustomAlias: &alias,
+		})
+		assert.Equal(t, nil, err)
+	}
+}
+
+func TestWords_NewKeyTaken(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		prefix      string
+		shortLinks  map[string]entity.ShortLink
+		hasErr      bool
+		expectedKey Key
+	}{
+		{
+			name: "retry until available key found",
+			shortLinks: map[string]entity.ShortLink{
+				"happy-happy": {Alias: "happy-happy"},
+				"happy-tiger": {Alias: "happy-tiger"},
+				"tiger-happy": {Alias: "tiger-happy"},
+			},
+			hasErr:      false,
+			expectedKey: "tiger-tiger",
+		},
+		{
+			name: "all keys taken",
+			shortLinks: map[string]entity.ShortLink{
+				"happy-happy": {Alias: "happy-happy"},
+				"happy-tiger": {Alias: "happy-tiger"},
+				"tiger-happy": {Alias: "tiger-happy"},
+				"tiger-tiger": {Alias: "tiger-tiger"},
+			},
+			hasErr: true,
+		},
+		{
+			name:   "prefixed keys checked for availability",
+			prefix: "eu-",
+			shortLinks: map[string]entity.ShortLink{
+				"eu-happy-happy": {Alias: "eu-happy-happy"},
+				"eu-happy-tiger": {Alias: "eu-happy-tiger"},
+				"eu-tiger-happy": {Alias: "eu-tiger-happy"},
+				"tiger-tiger":    {Alias: "tiger-tiger"},
+			},
+			hasErr:      false,
+			expectedKey: "eu-tiger-tiger",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			shortLinkRepo := repository.NewShortLinkFake(nil, testCase.shortLinks)
+			words, err := NewWords(&shortLinkRepo, []string{"happy", "tiger"}, 2, "-", testCase.prefix)
+			assert.Equal(t, nil, err)
+
+			if !testCase.hasErr {
+				// Each attempt finds the only available key with a chance of
+				// 1/4, so NewKey fails once in a while.
+				for i := 0; i < 20; i++ {
+					key, err := words.NewKey()
+					if err == nil {
+						assert.Equal(t, testCase.expectedKey, key)
+						return
+					}
+				}
+				t.Fatal("expect available key to be found")
+			}
+
+			_, err = words.NewKey()
+			assert.Equal(t, ErrKeyUnavailable("all 10 keys tried are taken"), err)
+		})
+	}
+}
+
+func TestWords_PreviewKey(t *testing.T) {
+	t.Parallel()
+
+	shortLinkRepo := repository.NewShortLinkFake(nil, map[string]entity.ShortLink{})
+	wordList := []string{"happy", "calm", "blue", "green", "tiger", "otter"}
+	words, err := NewWords(&shortLinkRepo, wordList, 3, "-", "")
+	assert.Equal(t, nil, err)
+
+	previewKey, err := words.PreviewKey()
+	assert.Equal(t, nil, err)
+	previewKeyAgain, err := words.PreviewKey()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, previewKey, previewKeyAgain)
+
+	key, err := words.NewKey()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, previewKey, key)
+
+	previewKey, err = words.PreviewKey()
+	assert.Equal(t, nil, err)
+	alias := string(previewKey)
+	err = shortLinkRepo.CreateShortLink(context.Background(), entity.ShortLinkInput{
+		CustomAlias: &alias,
+	})
+	assert.Equal(t, nil, err)
+
+	key, err = words.NewKey()
+	assert.Equal(t, nil, err)
+	assert.NotEqual(t, previewKey, key)
+}
+
+func TestNewWords(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name      string
+		words     []string
+		wordCount int
+		separator string
+		hasErr    bool
+	}{
+		{
+			name:      "valid words",
+			words:     []string{"happy", "blue", "tiger"},
+			wordCount: 3,
+			separator: "-",
+			hasErr:    false,
+		},
+		{
+			name:      "word count less than 1",
+			words:     []string{"happy", "blue", "tiger"},
+			wordCount: 0,
+			separator: "-",
+			hasErr:    true,
+		},
+		{
+			name:      "not enough different words",
+			words:     []string{"happy", "happy"},
+			wordCount: 3,
+			separator: "-",
+			hasErr:    true,
+		},
+		{
+			name:      "word contains separator",
+			words:     []string{"happy", "blue", "tiger"},
+			wordCount: 3,
+			separator: "e",
+			hasErr:    true,
+		},
+		{
+			name:      "word contains punctuation",
+			words:     []string{"happy", "blue", "ti/ger"},
+			wordCount: 3,
+			separator: "-",
+			hasErr:    true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			shortLinkRepo := repository.NewShortLinkFake(nil, map[string]entity.ShortLink{})
+			_, err := NewWords(&shortLinkRepo, testCase.words, testCase.wordCount, testCase.separator, "")
+			if testCase.hasErr {
+				assert.NotEqual(t, nil, err)
+				return
+			}
+			assert.Equal(t, nil, err)
+		})
+	}
+}
+
+func TestReadWordList(t *testing.T) {
+	t.Parallel()
+
+	fileSystem := filesystem.NewFileSystemFake(map[string][]byte{
+		"words.txt": []byte("# Animals\nhappy\n\n  blue \ntiger\n"),
+	})
+
+	words, err := ReadWordList(fileSystem, "words.txt")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"happy", "blue", "tiger"}, words)
+
+	_, err = ReadWordList(fileSystem, "missing.txt")
+	assert.NotEqual(t, nil, err)
+}
@@ -0,0 +1,145 @@
+package linkhealth
+
+import (
+	"context"
+	"errors"
+
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/fw/tenant"
+	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/webhook"
+)
+
+// Checker records whether the long links of short links are still reachable.
+type Checker struct {
+	shortLinkRepo    repository.ShortLink
+	linkHealthRepo   repository.LinkHealth
+	prober           Prober
+	dispatcher       webhook.Dispatcher
+	timer            timer.Timer
+	batchSize        int
+	failureThreshold int
+}
+
+// CheckLinks probes a batch of the least recently checked long links one
+// after another. A long link is only marked as broken after failing
+// failureThreshold checks in a row so that network blips are tolerated. A
+// link.broken event is dispatched when a healthy long link becomes broken. The
+// short links of all the tenants take turns.
+func (c Checker) CheckLinks() error {
+	prevLinkHealths, err := c.linkHealthRepo.FindLinkHealthToCheck(c.batchSize)
+	if err != nil {
+		return err
+	}
+
+	var tenantIDs []string
+	tenantLinkHealths := make(map[string][]entity.LinkHealth)
+	for _, linkHealth := range prevLinkHealths {
+		if _, ok := tenantLinkHealths[linkHealth.TenantID]; !ok {
+			tenantIDs = append(tenantIDs, linkHealth.TenantID)
+		}
+		tenantLinkHealths[linkHealth.TenantID] = append(tenantLinkHealths[linkHealth.TenantID], linkHealth)
+	}
+
+	for _, tenantID := range tenantIDs {
+		err = c.checkTenantLinks(tenantID, tenantLinkHealths[tenantID])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkTenantLinks probes the long links of the short links of the tenant.
+func (c Checker) checkTenantLinks(tenantID string, linkHealths []entity.LinkHealth) error {
+	aliases := make([]string, 0, len(linkHealths))
+	prevLinkHealths := make(map[string]entity.LinkHealth)
+	for _, linkHealth := range linkHealths {
+		aliases = append(aliases, linkHealth.Alias)
+		prevLinkHealths[linkHealth.Alias] = linkHealth
+	}
+
+	ctx := tenant.NewContext(context.Background(), tenantID)
+	shortLinks, err := c.shortLinkRepo.GetShortLinksByAliases(ctx, aliases)
+	if err != nil {
+		return err
+	}
+
+	for _, shortLink := range shortLinks {
+		prevLinkHealth := prevLinkHealths[shortLink.Alias]
+
+		probeErr := c.prober.Probe(shortLink.LongLink)
+		linkHealth := c.nextLinkHealth(prevLinkHealth, probeErr)
+		err = c.linkHealthRepo.UpsertLinkHealth(linkHealth)
+		if err != nil {
+			return err
+		}
+
+		if prevLinkHealth.Status == entity.LinkHealthHealthy &&
+			linkHealth.Status == entity.LinkHealthBroken {
+			c.dispatcher.Dispatch(webhook.Event{
+				Type:       webhook.LinkBroken,
+				OccurredAt: linkHealth.LastCheckedAt,
+				Data: webhook.LinkBrokenData{
+					Alias:    shortLink.Alias,
+					LongLink: shortLink.LongLink,
+				},
+			})
+		}
+	}
+	return nil
+}
+
+// nextLinkHealth keeps the previous status of skipped long links, which are
+// still marked as checked so that the other long links get their turn.
+func (c Checker) nextLinkHealth(prev entity.LinkHealth, probeErr error) entity.LinkHealth {
+	var skipped ErrProbeSkipped
+	if errors.As(probeErr, &skipped) {
+		next := prev
+		next.LastCheckedAt = c.timer.Now().UTC()
+		return next
+	}
+
+	next := entity.LinkHealth{
+		TenantID:      prev.TenantID,
+		Alias:         prev.Alias,
+		Status:        entity.LinkHealthHealthy,
+		LastCheckedAt: c.timer.Now().UTC(),
+	}
+	if probeErr == nil {
+		return next
+	}
+
+	next.ConsecutiveFailures = prev.ConsecutiveFailures + 1
+	if next.ConsecutiveFailures >= c.failureThreshold {
+		next.Status = entity.LinkHealthBroken
+		return next
+	}
+	next.Status = prev.Status
+	return next
+}
+
+// NewChecker creates Checker
+func NewChecker(
+	shortLinkRepo repository.ShortLink,
+	linkHealthRepo repository.LinkHealth,
+	prober Prober,
+	dispatcher webhook.Dispatcher,
+	timer timer.Timer,
+	batchSize int,
+	failureThreshold int,
+) Checker {
+	if failureThreshold < 1 {
+		failureThreshold = 1
+	}
+	return Checker{
+		shortLinkRepo:    shortLinkRepo,
+		linkHealthRepo:   linkHealthRepo,
+		prober:           prober,
+		dispatcher:       dispatcher,
+		timer:            timer,
+		batchSize:        batchSize,
+		failureThreshold: failureThreshold,
+	}
+}
@@ -0,0 +1,278 @@
+// +build !integration all
+
+package linkhealth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/webhook"
+)
+
+func TestChecker_CheckLinks(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 5, 1, 10, 30, 0, 0, time.UTC)
+	hourAgo := now.Add(-time.Hour)
+	dayAgo := now.AddDate(0, 0, -1)
+	errProbe := errors.New("no such host")
+
+	testCases := []struct {
+		name                string
+		shortLinks          map[string]entity.ShortLink
+		aliases             []string
+		linkHealths         []entity.LinkHealth
+		probeErrs           map[string]error
+		batchSize           int
+		failureThreshold    int
+		expectedLinkHealths []entity.LinkHealth
+		expectedEvents      []webhook.Event
+	}{
+		{
+			name: "reachable long link becomes healthy",
+			shortLinks: map[string]entity.ShortLink{
+				"220uFicCJj": {Alias: "220uFicCJj", LongLink: "https://www.google.com"},
+			},
+			aliases: []string{"220uFicCJj"},
+			linkHealths: []entity.LinkHealth{
+				{
+					Alias:               "220uFicCJj",
+					Status:              entity.LinkHealthUnknown,
+					ConsecutiveFailures: 2,
+					LastCheckedAt:       hourAgo,
+				},
+			},
+			probeErrs:        map[string]error{},
+			batchSize:        10,
+			failureThreshold: 3,
+			expectedLinkHealths: []entity.LinkHealth{
+				{
+					Alias:               "220uFicCJj",
+					Status:              entity.LinkHealthHealthy,
+					ConsecutiveFailures: 0,
+					LastCheckedAt:       now,
+				},
+			},
+			expectedEvents: []webhook.Event{},
+		},
+		{
+			name: "failure below threshold keeps previous status",
+			shortLinks: map[string]entity.ShortLink{
+				"220uFicCJj": {Alias: "220uFicCJj", LongLink: "https://www.google.com"},
+			},
+			aliases: []string{"220uFicCJj"},
+			linkHealths: []entity.LinkHealth{
+				{
+					Alias:               "220uFicCJj",
+					Status:              entity.LinkHealthHealthy,
+					ConsecutiveFailures: 1,
+					LastCheckedAt:       hourAgo,
+				},
+			},
+			probeErrs: map[string]error{
+				"https://www.google.com": errProbe,
+			},
+			batchSize:        10,
+			failureThreshold: 3,
+			expectedLinkHealths: []entity.LinkHealth{
+				{
+					Alias:               "220uFicCJj",
+					Status:              entity.LinkHealthHealthy,
+					ConsecutiveFailures: 2,
+					LastCheckedAt:       now,
+				},
+			},
+			expectedEvents: []webhook.Event{},
+		},
+		{
+			name: "failure reaching threshold marks long link broken",
+			shortLinks: map[string]entity.ShortLink{
+				"220uFicCJj": {Alias: "220uFicCJj", LongLink: "https://www.google.com"},
+			},
+			aliases: []string{"220uFicCJj"},
+			linkHealths: []entity.LinkHealth{
+				{
+					Alias:               "220uFicCJj",
+					Status:              entity.LinkHealthHealthy,
+					ConsecutiveFailures: 2,
+					LastCheckedAt:       hourAgo,
+				},
+			},
+			probeErrs: map[string]error{
+				"https://www.google.com": errProbe,
+			},
+			batchSize:        10,
+			failureThreshold: 3,
+			expectedLinkHealths: []entity.LinkHealth{
+				{
+					Alias:               "220uFicCJj",
+					Status:              entity.LinkHealthBroken,
+					ConsecutiveFailures: 3,
+					LastCheckedAt:       now,
+				},
+			},
+			expectedEvents: []webhook.Event{
+				{
+					Type:       webhook.LinkBroken,
+					OccurredAt: now,
+					Data: webhook.LinkBrokenData{
+						Alias:    "220uFicCJj",
+						LongLink: "https://www.google.com",
+					},
+				},
+			},
+		},
+		{
+			name: "never checked long link starts unknown",
+			shortLinks: map[string]entity.ShortLink{
+				"220uFicCJj": {Alias: "220uFicCJj", LongLink: "https://www.google.com"},
+			},
+			aliases:     []string{"220uFicCJj"},
+			linkHealths: []entity.LinkHealth{},
+			probeErrs: map[string]error{
+				"https://www.google.com": errProbe,
+			},
+			batchSize:        10,
+			failureThreshold: 3,
+			expectedLinkHealths: []entity.LinkHealth{
+				{
+					Alias:               "220uFicCJj",
+					Status:              entity.LinkHealthUnknown,
+					ConsecutiveFailures: 1,
+					LastCheckedAt:       now,
+				},
+			},
+			expectedEvents: []webhook.Event{},
+		},
+		{
+			name: "broken long link stays broken",
+			shortLinks: map[string]entity.ShortLink{
+				"220uFicCJj": {Alias: "220uFicCJj", LongLink: "https://www.google.com"},
+			},
+			aliases: []string{"220uFicCJj"},
+			linkHealths: []entity.LinkHealth{
+				{
+					Alias:               "220uFicCJj",
+					Status:              entity.LinkHealthBroken,
+					ConsecutiveFailures: 3,
+					LastCheckedAt:       hourAgo,
+				},
+			},
+			probeErrs: map[string]error{
+				"https://www.google.com": errProbe,
+			},
+			batchSize:        10,
+			failureThreshold: 3,
+			expectedLinkHealths: []entity.LinkHealth{
+				{
+					Alias:               "220uFicCJj",
+					Status:              entity.LinkHealthBroken,
+					ConsecutiveFailures: 4,
+					LastCheckedAt:       now,
+				},
+			},
+			expectedEvents: []webhook.Event{},
+		},
+		{
+			name: "skipped long link keeps previous health",
+			shortLinks: map[string]entity.ShortLink{
+				"220uFicCJj": {Alias: "220uFicCJj", LongLink: "https://www.google.com"},
+			},
+			aliases: []string{"220uFicCJj"},
+			linkHealths: []entity.LinkHealth{
+				{
+					Alias:               "220uFicCJj",
+					Status:              entity.LinkHealthHealthy,
+					ConsecutiveFailures: 2,
+					LastCheckedAt:       hourAgo,
+				},
+			},
+			probeErrs: map[string]error{
+				"https://www.google.com": ErrProbeSkipped("disallowed by robots.txt"),
+			},
+			batchSize:        10,
+			failureThreshold: 3,
+			expectedLinkHealths: []entity.LinkHealth{
+				{
+					Alias:               "220uFicCJj",
+					Status:              entity.LinkHealthHealthy,
+					ConsecutiveFailures: 2,
+					LastCheckedAt:       now,
+				},
+			},
+			expectedEvents: []webhook.Event{},
+		},
+		{
+			name: "only check least recently checked long links",
+			shortLinks: map[string]entity.ShortLink{
+				"220uFicCJj":      {Alias: "220uFicCJj", LongLink: "https://www.google.com"},
+				"yDOBcj5HIPbUAsw": {Alias: "yDOBcj5HIPbUAsw", LongLink: "https://github.com"},
+			},
+			aliases: []string{"220uFicCJj", "yDOBcj5HIPbUAsw"},
+			linkHealths: []entity.LinkHealth{
+				{
+					Alias:         "220uFicCJj",
+					Status:        entity.LinkHealthHealthy,
+					LastCheckedAt: hourAgo,
+				},
+				{
+					Alias:         "yDOBcj5HIPbUAsw",
+					Status:        entity.LinkHealthHealthy,
+					LastCheckedAt: dayAgo,
+				},
+			},
+			probeErrs:        map[string]error{},
+			batchSize:        1,
+			failureThreshold: 3,
+			expectedLinkHealths: []entity.LinkHealth{
+				{
+					Alias:         "220uFicCJj",
+					Status:        entity.LinkHealthHealthy,
+					LastCheckedAt: hourAgo,
+				},
+				{
+					Alias:         "yDOBcj5HIPbUAsw",
+					Status:        entity.LinkHealthHealthy,
+					LastCheckedAt: now,
+				},
+			},
+			expectedEvents: []webhook.Event{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			userShortLinkRepo := repository.NewUserShortLinkRepoFake(nil, nil)
+			shortLinkRepo := repository.NewShortLinkFake(&userShortLinkRepo, testCase.shortLinks)
+			linkHealthRepo := repository.NewLinkHealthFake(testCase.aliases, testCase.linkHealths)
+			prober := NewProberFake(testCase.probeErrs)
+			dispatcher := webhook.NewDispatcherFake()
+			checker := NewChecker(
+				&shortLinkRepo,
+				&linkHealthRepo,
+				prober,
+				dispatcher,
+				timer.NewStub(now),
+				testCase.batchSize,
+				testCase.failureThreshold,
+			)
+
+			err := checker.CheckLinks()
+			assert.Equal(t, nil, err)
+
+			linkHealths, err := linkHealthRepo.FindLinkHealthByAliases(context.Background(), testCase.aliases)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedLinkHealths, linkHealths)
+			assert.Equal(t, testCase.expectedEvents, dispatcher.Events())
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package linkhealth
+
+import (
+	"time"
+
+	"github.com/short-d/app/fw/logger"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/usecase/featureflag"
+)
+
+// Job checks the long links periodically in the background. Only one batch of
+// long links is checked per interval to avoid hammering the destinations.
+type Job struct {
+	checker  Checker
+	toggle   featureflag.Toggle
+	timer    timer.Timer
+	logger   logger.Logger
+	interval time.Duration
+}
+
+// Start schedules the checks. The checks are skipped while the feature is
+// turned off. Sending to or closing the returned channel stops the job.
+func (j Job) Start() chan bool {
+	return j.timer.Ticker(j.interval, func() {
+		if !j.toggle.IsEnabled(featureflag.LinkHealthCheck, nil) {
+			return
+		}
+		err := j.checker.CheckLinks()
+		if err != nil {
+			j.logger.Error(err)
+		}
+	})
+}
+
+// NewJob creates Job
+func NewJob(
+	checker Checker,
+	toggle featureflag.Toggle,
+	timer timer.Timer,
+	logger logger.Logger,
+	interval time.Duration,
+) Job {
+	return Job{
+		checker:  checker,
+		toggle:   toggle,
+		timer:    timer,
+		logger:   logger,
+		interval: interval,
+	}
+}
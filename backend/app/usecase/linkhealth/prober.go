@@ -0,0 +1,14 @@
+package linkhealth
+
+// ErrProbeSkipped represents the long link is not probed, such as when the
+// destination disallows automated access.
+type ErrProbeSkipped string
+
+func (e ErrProbeSkipped) Error() string {
+	return "probe skipped: " + string(e)
+}
+
+// Prober checks whether a long link is reachable.
+type Prober interface {
+	Probe(longLink string) error
+}
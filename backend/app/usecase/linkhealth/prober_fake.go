@@ -0,0 +1,23 @@
+package linkhealth
+
+var _ Prober = (*ProberFake)(nil)
+
+// ProberFake represents an in memory Prober used for testing.
+type ProberFake struct {
+	errs map[string]error
+}
+
+// Probe fails with the error configured for the long link.
+func (p ProberFake) Probe(longLink string) error {
+	return p.errs[longLink]
+}
+
+// SetErr configures the error returned when probing the long link.
+func (p *ProberFake) SetErr(longLink string, err error) {
+	p.errs[longLink] = err
+}
+
+// NewProberFake creates ProberFake
+func NewProberFake(errs map[string]error) ProberFake {
+	return ProberFake{errs: errs}
+}
@@ -0,0 +1,107 @@
+package linkhealth
+
+import (
+	"context"
+
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/fw/tenant"
+	"github.com/short-d/short/backend/app/usecase/featureflag"
+	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/shortlink"
+)
+
+var _ Reporter = (*ReporterPersist)(nil)
+
+// Reporter fetches the health of long links.
+type Reporter interface {
+	GetBrokenShortLinks(ctx context.Context, user entity.User) ([]entity.ShortLink, error)
+	GetLinkHealth(ctx context.Context, user entity.User, alias string) (entity.LinkHealth, error)
+}
+
+// ReporterPersist fetches the short links with broken long links from
+// persistent storage.
+type ReporterPersist struct {
+	shortLinkRepo     repository.ShortLink
+	userShortLinkRepo repository.UserShortLink
+	linkHealthRepo    repository.LinkHealth
+	toggle            featureflag.Toggle
+}
+
+// GetBrokenShortLinks fetches the short links owned by the user whose long links
+// are no longer reachable. Nothing is reported to the users the feature is not
+// rolled out to yet.
+func (r ReporterPersist) GetBrokenShortLinks(ctx context.Context, user entity.User) ([]entity.ShortLink, error) {
+	if !r.toggle.IsEnabled(featureflag.BrokenLinkReport, &user) {
+		return []entity.ShortLink{}, nil
+	}
+
+	ctx = tenant.NewContext(ctx, user.TenantID)
+	aliases, err := r.userShortLinkRepo.FindAliasesByUser(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	linkHealths, err := r.linkHealthRepo.FindLinkHealthByAliases(ctx, aliases)
+	if err != nil {
+		return nil, err
+	}
+
+	var brokenAliases []string
+	for _, linkHealth := range linkHealths {
+		if linkHealth.Status != entity.LinkHealthBroken {
+			continue
+		}
+		brokenAliases = append(brokenAliases, linkHealth.Alias)
+	}
+	if len(brokenAliases) == 0 {
+		return []entity.ShortLink{}, nil
+	}
+	return r.shortLinkRepo.GetShortLinksByAliases(ctx, brokenAliases)
+}
+
+// GetLinkHealth fetches the outcome of the latest checks on the long link of a
+// short link owned by the user. The status stays unknown until the long link
+// is checked, or when the feature is not rolled out to the user yet.
+func (r ReporterPersist) GetLinkHealth(ctx context.Context, user entity.User, alias string) (entity.LinkHealth, error) {
+	ctx = tenant.NewContext(ctx, user.TenantID)
+	hasMapping, err := r.userShortLinkRepo.HasMapping(ctx, user, alias)
+	if err != nil {
+		return entity.LinkHealth{}, err
+	}
+	if !hasMapping {
+		return entity.LinkHealth{}, shortlink.ErrShortLinkNotFound(alias)
+	}
+
+	unknown := entity.LinkHealth{
+		TenantID: user.TenantID,
+		Alias:    alias,
+		Status:   entity.LinkHealthUnknown,
+	}
+	if !r.toggle.IsEnabled(featureflag.BrokenLinkReport, &user) {
+		return unknown, nil
+	}
+
+	linkHealths, err := r.linkHealthRepo.FindLinkHealthByAliases(ctx, []string{alias})
+	if err != nil {
+		return entity.LinkHealth{}, err
+	}
+	if len(linkHealths) == 0 {
+		return unknown, nil
+	}
+	return linkHealths[0], nil
+}
+
+// NewReporterPersist creates ReporterPersist
+func NewReporterPersist(
+	shortLinkRepo repository.ShortLink,
+	userShortLinkRepo repository.UserShortLink,
+	linkHealthRepo repository.LinkHealth,
+	toggle featureflag.Toggle,
+) ReporterPersist {
+	return ReporterPersist{
+		shortLinkRepo:     shortLinkRepo,
+		userShortLinkRepo: userShortLinkRepo,
+		linkHealthRepo:    linkHealthRepo,
+		toggle:            toggle,
+	}
+}
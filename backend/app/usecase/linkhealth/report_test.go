@@ -0,0 +1,207 @@
+// +build !integration all
+
+package linkhealth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/featureflag"
+	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/shortlink"
+)
+
+func TestReporterPersist_GetBrokenShortLinks(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 5, 1, 10, 30, 0, 0, time.UTC)
+	owner := entity.User{ID: "alpha"}
+	otherUser := entity.User{ID: "beta"}
+	shortLinks := []entity.ShortLink{
+		{Alias: "220uFicCJj", LongLink: "https://www.google.com"},
+		{Alias: "yDOBcj5HIPbUAsw", LongLink: "https://github.com"},
+		{Alias: "xvU0K8RL5S", LongLink: "https://short-d.com"},
+	}
+
+	testCases := []struct {
+		name               string
+		user               entity.User
+		isFeatureEnabled   bool
+		linkHealths        []entity.LinkHealth
+		expectedShortLinks []entity.ShortLink
+	}{
+		{
+			name:             "no broken long links",
+			user:             owner,
+			isFeatureEnabled: true,
+			linkHealths: []entity.LinkHealth{
+				{Alias: "220uFicCJj", Status: entity.LinkHealthHealthy, LastCheckedAt: now},
+			},
+			expectedShortLinks: []entity.ShortLink{},
+		},
+		{
+			name:             "broken long links owned by user",
+			user:             owner,
+			isFeatureEnabled: true,
+			linkHealths: []entity.LinkHealth{
+				{Alias: "220uFicCJj", Status: entity.LinkHealthBroken, LastCheckedAt: now},
+				{Alias: "yDOBcj5HIPbUAsw", Status: entity.LinkHealthUnknown, LastCheckedAt: now},
+				{Alias: "xvU0K8RL5S", Status: entity.LinkHealthBroken, LastCheckedAt: now},
+			},
+			expectedShortLinks: []entity.ShortLink{
+				{Alias: "220uFicCJj", LongLink: "https://www.google.com"},
+			},
+		},
+		{
+			name:             "feature not rolled out to user",
+			user:             owner,
+			isFeatureEnabled: false,
+			linkHealths: []entity.LinkHealth{
+				{Alias: "220uFicCJj", Status: entity.LinkHealthBroken, LastCheckedAt: now},
+			},
+			expectedShortLinks: []entity.ShortLink{},
+		},
+		{
+			name:             "user owns no short links",
+			user:             otherUser,
+			isFeatureEnabled: true,
+			linkHealths: []entity.LinkHealth{
+				{Alias: "220uFicCJj", Status: entity.LinkHealthBroken, LastCheckedAt: now},
+			},
+			expectedShortLinks: []entity.ShortLink{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			userShortLinkRepo := repository.NewUserShortLinkRepoFake(
+				[]entity.User{owner, owner},
+				shortLinks[:2],
+			)
+			shortLinkRepo := repository.NewShortLinkFake(&userShortLinkRepo, map[string]entity.ShortLink{
+				"220uFicCJj":      shortLinks[0],
+				"yDOBcj5HIPbUAsw": shortLinks[1],
+				"xvU0K8RL5S":      shortLinks[2],
+			})
+			linkHealthRepo := repository.NewLinkHealthFake(
+				[]string{"220uFicCJj", "yDOBcj5HIPbUAsw", "xvU0K8RL5S"},
+				testCase.linkHealths,
+			)
+			toggle := featureflag.NewToggleFake(map[featureflag.Flag]bool{
+				featureflag.BrokenLinkReport: testCase.isFeatureEnabled,
+			})
+			reporter := NewReporterPersist(&shortLinkRepo, &userShortLinkRepo, &linkHealthRepo, toggle)
+
+			brokenShortLinks, err := reporter.GetBrokenShortLinks(context.Background(), testCase.user)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedShortLinks, brokenShortLinks)
+		})
+	}
+}
+
+func TestReporterPersist_GetLinkHealth(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 5, 1, 10, 30, 0, 0, time.UTC)
+	owner := entity.User{ID: "alpha"}
+	otherUser := entity.User{ID: "beta"}
+	shortLinks := []entity.ShortLink{
+		{Alias: "220uFicCJj", LongLink: "https://www.google.com"},
+		{Alias: "yDOBcj5HIPbUAsw", LongLink: "https://github.com"},
+	}
+
+	testCases := []struct {
+		name               string
+		user               entity.User
+		alias              string
+		isFeatureEnabled   bool
+		linkHealths        []entity.LinkHealth
+		expectedLinkHealth entity.LinkHealth
+		expectedErr        error
+	}{
+		{
+			name:             "broken long link",
+			user:             owner,
+			alias:            "220uFicCJj",
+			isFeatureEnabled: true,
+			linkHealths: []entity.LinkHealth{
+				{Alias: "220uFicCJj", Status: entity.LinkHealthBroken, ConsecutiveFailures: 3, LastCheckedAt: now},
+				{Alias: "yDOBcj5HIPbUAsw", Status: entity.LinkHealthHealthy, LastCheckedAt: now},
+			},
+			expectedLinkHealth: entity.LinkHealth{
+				Alias:               "220uFicCJj",
+				Status:              entity.LinkHealthBroken,
+				ConsecutiveFailures: 3,
+				LastCheckedAt:       now,
+			},
+		},
+		{
+			name:             "long link never checked",
+			user:             owner,
+			alias:            "yDOBcj5HIPbUAsw",
+			isFeatureEnabled: true,
+			linkHealths:      []entity.LinkHealth{},
+			expectedLinkHealth: entity.LinkHealth{
+				Alias:  "yDOBcj5HIPbUAsw",
+				Status: entity.LinkHealthUnknown,
+			},
+		},
+		{
+			name:             "feature not rolled out to user",
+			user:             owner,
+			alias:            "220uFicCJj",
+			isFeatureEnabled: false,
+			linkHealths: []entity.LinkHealth{
+				{Alias: "220uFicCJj", Status: entity.LinkHealthBroken, LastCheckedAt: now},
+			},
+			expectedLinkHealth: entity.LinkHealth{
+				Alias:  "220uFicCJj",
+				Status: entity.LinkHealthUnknown,
+			},
+		},
+		{
+			name:             "user does not own short link",
+			user:             otherUser,
+			alias:            "220uFicCJj",
+			isFeatureEnabled: true,
+			linkHealths: []entity.LinkHealth{
+				{Alias: "220uFicCJj", Status: entity.LinkHealthBroken, LastCheckedAt: now},
+			},
+			expectedErr: shortlink.ErrShortLinkNotFound("220uFicCJj"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			userShortLinkRepo := repository.NewUserShortLinkRepoFake(
+				[]entity.User{owner, owner},
+				shortLinks,
+			)
+			shortLinkRepo := repository.NewShortLinkFake(&userShortLinkRepo, map[string]entity.ShortLink{
+				"220uFicCJj":      shortLinks[0],
+				"yDOBcj5HIPbUAsw": shortLinks[1],
+			})
+			linkHealthRepo := repository.NewLinkHealthFake(
+				[]string{"220uFicCJj", "yDOBcj5HIPbUAsw"},
+				testCase.linkHealths,
+			)
+			toggle := featureflag.NewToggleFake(map[featureflag.Flag]bool{
+				featureflag.BrokenLinkReport: testCase.isFeatureEnabled,
+			})
+			reporter := NewReporterPersist(&shortLinkRepo, &userShortLinkRepo, &linkHealthRepo, toggle)
+
+			linkHealth, err := reporter.GetLinkHealth(context.Background(), testCase.user, testCase.alias)
+			assert.Equal(t, testCase.expectedErr, err)
+			assert.Equal(t, testCase.expectedLinkHealth, linkHealth)
+		})
+	}
+}
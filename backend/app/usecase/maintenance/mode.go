@@ -0,0 +1,84 @@
+package maintenance
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/authorizer"
+)
+
+// ErrUnauthorizedAction represents unauthorized action error
+type ErrUnauthorizedAction struct {
+	user   entity.User
+	action string
+}
+
+var _ error = (*ErrUnauthorizedAction)(nil)
+
+func (e ErrUnauthorizedAction) Error() string {
+	return fmt.Sprintf("user %s is not allowed to %s", e.user.ID, e.action)
+}
+
+// Mode tells whether the service is in read-only mode, where short links keep
+// redirecting but can't be created, updated or deleted. This allows schema
+// changes without a full outage. Mode is safe to read concurrently while it is
+// switched by Switch. The zero value is never read-only.
+type Mode struct {
+	readOnly *int32
+}
+
+// IsReadOnly checks whether writes are currently blocked.
+func (m Mode) IsReadOnly() bool {
+	if m.readOnly == nil {
+		return false
+	}
+	return atomic.LoadInt32(m.readOnly) == 1
+}
+
+func (m Mode) setReadOnly(isReadOnly bool) {
+	var readOnly int32
+	if isReadOnly {
+		readOnly = 1
+	}
+	atomic.StoreInt32(m.readOnly, readOnly)
+}
+
+// NewMode creates Mode which starts in read-only mode if isReadOnly is true.
+// The copies of Mode share the same state.
+func NewMode(isReadOnly bool) Mode {
+	mode := Mode{readOnly: new(int32)}
+	mode.setReadOnly(isReadOnly)
+	return mode
+}
+
+// Switch turns read-only mode on or off at runtime.
+type Switch struct {
+	mode       Mode
+	authorizer authorizer.Authorizer
+}
+
+// SetReadOnly turns read-only mode on or off on behalf of the given user.
+func (s Switch) SetReadOnly(user entity.User, isReadOnly bool) error {
+	canSwitch, err := s.authorizer.CanSwitchReadOnlyMode(user)
+	if err != nil {
+		return err
+	}
+
+	if !canSwitch {
+		return ErrUnauthorizedAction{
+			user:   user,
+			action: "switch read-only mode",
+		}
+	}
+	s.mode.setReadOnly(isReadOnly)
+	return nil
+}
+
+// NewSwitch creates Switch which changes the given Mode.
+func NewSwitch(mode Mode, authorizer authorizer.Authorizer) Switch {
+	return Switch{
+		mode:       mode,
+		authorizer: authorizer,
+	}
+}
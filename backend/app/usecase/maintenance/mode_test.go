@@ -0,0 +1,121 @@
+// +build !integration all
+
+package maintenance
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/authorizer"
+	"github.com/short-d/short/backend/app/usecase/authorizer/rbac"
+	"github.com/short-d/short/backend/app/usecase/authorizer/rbac/role"
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+func TestMode_IsReadOnly(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, false, Mode{}.IsReadOnly())
+	assert.Equal(t, false, NewMode(false).IsReadOnly())
+	assert.Equal(t, true, NewMode(true).IsReadOnly())
+}
+
+func TestSwitch_SetReadOnly(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name               string
+		roles              []role.Role
+		isReadOnly         bool
+		setReadOnly        bool
+		expHasErr          bool
+		expectedIsReadOnly bool
+	}{
+		{
+			name:               "admin turns on read-only mode",
+			roles:              []role.Role{role.Admin},
+			isReadOnly:         false,
+			setReadOnly:        true,
+			expectedIsReadOnly: true,
+		},
+		{
+			name:               "admin turns off read-only mode",
+			roles:              []role.Role{role.Admin},
+			isReadOnly:         true,
+			setReadOnly:        false,
+			expectedIsReadOnly: false,
+		},
+		{
+			name:               "security specialist not allowed to switch",
+			roles:              []role.Role{role.SecuritySpecialist},
+			isReadOnly:         false,
+			setReadOnly:        true,
+			expHasErr:          true,
+			expectedIsReadOnly: false,
+		},
+		{
+			name:               "basic user not allowed to switch",
+			roles:              []role.Role{role.Basic},
+			isReadOnly:         true,
+			setReadOnly:        false,
+			expHasErr:          true,
+			expectedIsReadOnly: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			user := entity.User{ID: "alpha"}
+			roleRepo := repository.NewUserRoleFake(map[string][]role.Role{
+				user.ID: testCase.roles,
+			})
+			au := authorizer.NewAuthorizer(rbac.NewRBAC(roleRepo))
+
+			mode := NewMode(testCase.isReadOnly)
+			modeSwitch := NewSwitch(mode, au)
+
+			err := modeSwitch.SetReadOnly(user, testCase.setReadOnly)
+			if testCase.expHasErr {
+				assert.Equal(t, ErrUnauthorizedAction{user, "switch read-only mode"}, err)
+			} else {
+				assert.Equal(t, nil, err)
+			}
+			assert.Equal(t, testCase.expectedIsReadOnly, mode.IsReadOnly())
+		})
+	}
+}
+
+func TestSwitch_SetReadOnlyConcurrently(t *testing.T) {
+	t.Parallel()
+
+	admin := entity.User{ID: "alpha"}
+	roleRepo := repository.NewUserRoleFake(map[string][]role.Role{
+		admin.ID: {role.Admin},
+	})
+	mode := NewMode(false)
+	modeSwitch := NewSwitch(mode, authorizer.NewAuthorizer(rbac.NewRBAC(roleRepo)))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func(isReadOnly bool) {
+			defer wg.Done()
+			err := modeSwitch.SetReadOnly(admin, isReadOnly)
+			assert.Equal(t, nil, err)
+		}(i%2 == 0)
+		go func() {
+			defer wg.Done()
+			mode.IsReadOnly()
+		}()
+	}
+	wg.Wait()
+
+	err := modeSwitch.SetReadOnly(admin, true)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, mode.IsReadOnly())
+}
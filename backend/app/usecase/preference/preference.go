@@ -0,0 +1,46 @@
+package preference
+
+import (
+	"fmt"
+
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+// ErrInvalidPreferences represents the preferences can't be applied to short
+// links, such as a non positive default expiration.
+type ErrInvalidPreferences string
+
+func (e ErrInvalidPreferences) Error() string {
+	return fmt.Sprintf("invalid preferences: %s", string(e))
+}
+
+// Preference retrieves and updates the default settings users apply to the
+// short links they create.
+type Preference struct {
+	userPreferencesRepo repository.UserPreferences
+}
+
+// GetPreferences fetches the preferences of the user.
+func (p Preference) GetPreferences(user entity.User) (entity.UserPreferences, error) {
+	return p.userPreferencesRepo.GetPreferences(user.ID)
+}
+
+// UpdatePreferences replaces all the preferences of the user. Preferences
+// left nil are cleared.
+func (p Preference) UpdatePreferences(user entity.User, preferences entity.UserPreferences) (entity.UserPreferences, error) {
+	if preferences.DefaultExpireAfter != nil && *preferences.DefaultExpireAfter <= 0 {
+		return entity.UserPreferences{}, ErrInvalidPreferences("default expiration must be positive")
+	}
+
+	err := p.userPreferencesRepo.UpsertPreferences(user.ID, preferences)
+	if err != nil {
+		return entity.UserPreferences{}, err
+	}
+	return preferences, nil
+}
+
+// NewPreference creates Preference
+func NewPreference(userPreferencesRepo repository.UserPreferences) Preference {
+	return Preference{userPreferencesRepo: userPreferencesRepo}
+}
@@ -0,0 +1,88 @@
+// +build !integration all
+
+package preference
+
+import (
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+func TestPreference_UpdatePreferences(t *testing.T) {
+	t.Parallel()
+
+	week := 7 * 24 * time.Hour
+	negative := -time.Hour
+	trackVisits := false
+
+	testCases := []struct {
+		name                string
+		existingPreferences map[string]entity.UserPreferences
+		preferences         entity.UserPreferences
+		expectedErr         error
+		expectedPreferences entity.UserPreferences
+	}{
+		{
+			name: "set preferences",
+			preferences: entity.UserPreferences{
+				DefaultExpireAfter: &week,
+				DefaultTrackVisits: &trackVisits,
+			},
+			expectedPreferences: entity.UserPreferences{
+				DefaultExpireAfter: &week,
+				DefaultTrackVisits: &trackVisits,
+			},
+		},
+		{
+			name: "clear preferences",
+			existingPreferences: map[string]entity.UserPreferences{
+				"alpha": {
+					DefaultExpireAfter: &week,
+					DefaultTrackVisits: &trackVisits,
+				},
+			},
+			preferences:         entity.UserPreferences{},
+			expectedPreferences: entity.UserPreferences{},
+		},
+		{
+			name: "default expiration not positive",
+			existingPreferences: map[string]entity.UserPreferences{
+				"alpha": {
+					DefaultTrackVisits: &trackVisits,
+				},
+			},
+			preferences: entity.UserPreferences{
+				DefaultExpireAfter: &negative,
+			},
+			expectedErr: ErrInvalidPreferences("default expiration must be positive"),
+			expectedPreferences: entity.UserPreferences{
+				DefaultTrackVisits: &trackVisits,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			user := entity.User{ID: "alpha"}
+			userPreferencesRepo := repository.NewUserPreferencesFake(testCase.existingPreferences)
+			preference := NewPreference(&userPreferencesRepo)
+
+			_, err := preference.UpdatePreferences(user, testCase.preferences)
+			assert.Equal(t, testCase.expectedErr, err)
+
+			preferences, err := preference.GetPreferences(user)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedPreferences, preferences)
+
+			otherPreferences, err := preference.GetPreferences(entity.User{ID: "beta"})
+			assert.Equal(t, nil, err)
+			assert.Equal(t, entity.UserPreferences{}, otherPreferences)
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package ratelimit
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/short-d/app/fw/timer"
+)
+
+// ErrTooSoon represents the key is still cooling down from its previous
+// action. Wait is the time left until the cooldown is over.
+type ErrTooSoon struct {
+	Wait time.Duration
+}
+
+func (e ErrTooSoon) Error() string {
+	return fmt.Sprintf("too soon, try again in %s", e.Wait)
+}
+
+// Cooldown requires each key to wait at least interval between actions. The
+// time of the last actions is kept in memory until their cooldown is over.
+// Cooldown is disabled when interval is not positive.
+type Cooldown struct {
+	timer    timer.Timer
+	interval time.Duration
+	mutex    *sync.Mutex
+	actedAt  map[string]time.Time
+	prunedAt *time.Time
+}
+
+// Start begins the cooldown of the key, failing with ErrTooSoon when the
+// previous cooldown of the key is not over yet.
+func (c Cooldown) Start(key string) error {
+	if c.interval <= 0 {
+		return nil
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := c.timer.Now()
+	c.pruneExpired(now)
+
+	actedAt, ok := c.actedAt[key]
+	if ok && !c.isOver(actedAt, now) {
+		return ErrTooSoon{Wait: actedAt.Add(c.interval).Sub(now)}
+	}
+	c.actedAt[key] = now
+	return nil
+}
+
+func (c Cooldown) isOver(actedAt time.Time, now time.Time) bool {
+	return !now.Before(actedAt.Add(c.interval))
+}
+
+// pruneExpired drops the keys which finished cooling down at most once per
+// interval so that memory usage stays bounded by the number of recently
+// active keys.
+func (c Cooldown) pruneExpired(now time.Time) {
+	if now.Before(c.prunedAt.Add(c.interval)) {
+		return
+	}
+	*c.prunedAt = now
+
+	for key, actedAt := range c.actedAt {
+		if c.isOver(actedAt, now) {
+			delete(c.actedAt, key)
+		}
+	}
+}
+
+// NewCooldown creates Cooldown which requires each key to wait at least
+// interval between actions.
+func NewCooldown(timer timer.Timer, interval time.Duration) Cooldown {
+	return Cooldown{
+		timer:    timer,
+		interval: interval,
+		mutex:    &sync.Mutex{},
+		actedAt:  make(map[string]time.Time),
+		prunedAt: &time.Time{},
+	}
+}
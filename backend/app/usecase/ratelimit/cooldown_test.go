@@ -0,0 +1,135 @@
+// +build !integration all
+
+package ratelimit
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/timer"
+)
+
+type action struct {
+	key         string
+	elapsed     time.Duration
+	expectedErr error
+}
+
+func TestCooldown_Start(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		interval time.Duration
+		actions  []action
+	}{
+		{
+			name:     "cooldown disabled",
+			interval: 0,
+			actions: []action{
+				{key: "session1"},
+				{key: "session1"},
+			},
+		},
+		{
+			name:     "second action within interval",
+			interval: time.Minute,
+			actions: []action{
+				{key: "session1"},
+				{key: "session1", elapsed: 20 * time.Second, expectedErr: ErrTooSoon{Wait: 40 * time.Second}},
+			},
+		},
+		{
+			name:     "second action after interval",
+			interval: time.Minute,
+			actions: []action{
+				{key: "session1"},
+				{key: "session1", elapsed: time.Minute},
+			},
+		},
+		{
+			name:     "rejected action doesn't extend cooldown",
+			interval: time.Minute,
+			actions: []action{
+				{key: "session1"},
+				{key: "session1", elapsed: 50 * time.Second, expectedErr: ErrTooSoon{Wait: 10 * time.Second}},
+				{key: "session1", elapsed: time.Minute},
+				{key: "session1", elapsed: 90 * time.Second, expectedErr: ErrTooSoon{Wait: 30 * time.Second}},
+			},
+		},
+		{
+			name:     "keys cool down separately",
+			interval: time.Minute,
+			actions: []action{
+				{key: "session1"},
+				{key: "session2", elapsed: time.Second},
+				{key: "session1", elapsed: 2 * time.Second, expectedErr: ErrTooSoon{Wait: 58 * time.Second}},
+			},
+		},
+		{
+			name:     "expired keys pruned",
+			interval: time.Minute,
+			actions: []action{
+				{key: "session1"},
+				{key: "session2", elapsed: 2 * time.Minute},
+				{key: "session1", elapsed: 2 * time.Minute},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			startedAt := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+			tm := timer.NewStub(startedAt)
+			cooldown := NewCooldown(&tm, testCase.interval)
+
+			for _, act := range testCase.actions {
+				tm.CurrentTime = startedAt.Add(act.elapsed)
+
+				err := cooldown.Start(act.key)
+				assert.Equal(t, act.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestCooldown_StartConcurrently(t *testing.T) {
+	t.Parallel()
+
+	tm := timer.NewStub(time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC))
+	cooldown := NewCooldown(&tm, time.Minute)
+
+	const keyCount = 10
+	const attemptsPerKey = 20
+	started := make(chan string, keyCount*attemptsPerKey)
+
+	var wg sync.WaitGroup
+	for key := 0; key < keyCount; key++ {
+		for attempt := 0; attempt < attemptsPerKey; attempt++ {
+			wg.Add(1)
+			go func(key string) {
+				defer wg.Done()
+				if cooldown.Start(key) == nil {
+					started <- key
+				}
+			}(fmt.Sprintf("session%d", key))
+		}
+	}
+	wg.Wait()
+	close(started)
+
+	startedKeys := make(map[string]int)
+	for key := range started {
+		startedKeys[key]++
+	}
+	assert.Equal(t, keyCount, len(startedKeys))
+	for _, count := range startedKeys {
+		assert.Equal(t, 1, count)
+	}
+}
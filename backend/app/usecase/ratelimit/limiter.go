@@ -0,0 +1,14 @@
+package ratelimit
+
+// Limiter decides whether another request identified by the given key can be
+// served.
+type Limiter interface {
+	Allow(key string) (bool, error)
+}
+
+// VariableLimiter decides whether another request identified by the given key
+// can be served under the limit given along with the request, so that the
+// keys can have different limits.
+type VariableLimiter interface {
+	AllowUpTo(key string, limit int) (bool, error)
+}
@@ -0,0 +1,92 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+
+	"github.com/short-d/app/fw/timer"
+)
+
+var _ Limiter = (*Memory)(nil)
+var _ VariableLimiter = (*Memory)(nil)
+
+type fixedWindow struct {
+	startedAt time.Time
+	count     int
+}
+
+// Memory limits the requests with fixed time windows kept in memory. Each key
+// is allowed up to limit requests per window. Limiting is disabled when limit
+// is not positive.
+type Memory struct {
+	timer    timer.Timer
+	limit    int
+	window   time.Duration
+	mutex    *sync.Mutex
+	windows  map[string]fixedWindow
+	prunedAt *time.Time
+}
+
+// Allow checks whether the key has requests left in its current window and
+// consumes one if so.
+func (m Memory) Allow(key string) (bool, error) {
+	return m.AllowUpTo(key, m.limit)
+}
+
+// AllowUpTo checks whether the key has requests left in its current window
+// under the given limit instead of the limit of Memory, and consumes one if
+// so. Limiting is disabled when limit is not positive.
+func (m Memory) AllowUpTo(key string, limit int) (bool, error) {
+	if limit <= 0 {
+		return true, nil
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := m.timer.Now()
+	w, ok := m.windows[key]
+	if !ok || m.isExpired(w, now) {
+		m.pruneExpired(now)
+		w = fixedWindow{startedAt: now}
+	}
+
+	if w.count >= limit {
+		return false, nil
+	}
+	w.count++
+	m.windows[key] = w
+	return true, nil
+}
+
+func (m Memory) isExpired(w fixedWindow, now time.Time) bool {
+	return !now.Before(w.startedAt.Add(m.window))
+}
+
+// pruneExpired drops the expired windows at most once per window so that
+// memory usage stays bounded by the number of recently active keys.
+func (m Memory) pruneExpired(now time.Time) {
+	if now.Before(m.prunedAt.Add(m.window)) {
+		return
+	}
+	*m.prunedAt = now
+
+	for key, w := range m.windows {
+		if m.isExpired(w, now) {
+			delete(m.windows, key)
+		}
+	}
+}
+
+// NewMemory creates Memory which allows up to limit requests per key in each
+// window.
+func NewMemory(timer timer.Timer, limit int, window time.Duration) Memory {
+	return Memory{
+		timer:    timer,
+		limit:    limit,
+		window:   window,
+		mutex:    &sync.Mutex{},
+		windows:  make(map[string]fixedWindow),
+		prunedAt: &time.Time{},
+	}
+}
@@ -0,0 +1,159 @@
+// +build !integration all
+
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/timer"
+)
+
+type request struct {
+	key             string
+	elapsed         time.Duration
+	expectedAllowed bool
+}
+
+func TestMemory_Allow(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		limit    int
+		window   time.Duration
+		requests []request
+	}{
+		{
+			name:   "limiting disabled",
+			limit:  0,
+			window: time.Minute,
+			requests: []request{
+				{key: "a|1.1.1.1", expectedAllowed: true},
+				{key: "a|1.1.1.1", expectedAllowed: true},
+				{key: "a|1.1.1.1", expectedAllowed: true},
+			},
+		},
+		{
+			name:   "requests within limit",
+			limit:  2,
+			window: time.Minute,
+			requests: []request{
+				{key: "a|1.1.1.1", expectedAllowed: true},
+				{key: "a|1.1.1.1", elapsed: time.Second, expectedAllowed: true},
+			},
+		},
+		{
+			name:   "requests exceed limit",
+			limit:  2,
+			window: time.Minute,
+			requests: []request{
+				{key: "a|1.1.1.1", expectedAllowed: true},
+				{key: "a|1.1.1.1", expectedAllowed: true},
+				{key: "a|1.1.1.1", expectedAllowed: false},
+				{key: "a|1.1.1.1", elapsed: 59 * time.Second, expectedAllowed: false},
+			},
+		},
+		{
+			name:   "keys limited separately",
+			limit:  1,
+			window: time.Minute,
+			requests: []request{
+				{key: "a|1.1.1.1", expectedAllowed: true},
+				{key: "a|1.1.1.1", expectedAllowed: false},
+				{key: "b|1.1.1.1", expectedAllowed: true},
+				{key: "a|2.2.2.2", expectedAllowed: true},
+				{key: "b|1.1.1.1", expectedAllowed: false},
+			},
+		},
+		{
+			name:   "limit resets after window",
+			limit:  1,
+			window: time.Minute,
+			requests: []request{
+				{key: "a|1.1.1.1", expectedAllowed: true},
+				{key: "a|1.1.1.1", elapsed: 30 * time.Second, expectedAllowed: false},
+				{key: "a|1.1.1.1", elapsed: time.Minute, expectedAllowed: true},
+				{key: "a|1.1.1.1", elapsed: 90 * time.Second, expectedAllowed: false},
+				{key: "a|1.1.1.1", elapsed: 2 * time.Minute, expectedAllowed: true},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			startedAt := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+			tm := timer.NewStub(startedAt)
+			limiter := NewMemory(&tm, testCase.limit, testCase.window)
+
+			for _, req := range testCase.requests {
+				tm.CurrentTime = startedAt.Add(req.elapsed)
+
+				allowed, err := limiter.Allow(req.key)
+				assert.Equal(t, nil, err)
+				assert.Equal(t, req.expectedAllowed, allowed)
+			}
+		})
+	}
+}
+
+func TestMemory_AllowUpTo(t *testing.T) {
+	t.Parallel()
+
+	type limitedRequest struct {
+		key             string
+		limit           int
+		expectedAllowed bool
+	}
+
+	testCases := []struct {
+		name     string
+		requests []limitedRequest
+	}{
+		{
+			name: "limiting disabled",
+			requests: []limitedRequest{
+				{key: "a|1.1.1.1", limit: 0, expectedAllowed: true},
+				{key: "a|1.1.1.1", limit: 0, expectedAllowed: true},
+			},
+		},
+		{
+			name: "requests exceed limit",
+			requests: []limitedRequest{
+				{key: "a|1.1.1.1", limit: 2, expectedAllowed: true},
+				{key: "a|1.1.1.1", limit: 2, expectedAllowed: true},
+				{key: "a|1.1.1.1", limit: 2, expectedAllowed: false},
+			},
+		},
+		{
+			name: "keys limited differently",
+			requests: []limitedRequest{
+				{key: "a|1.1.1.1", limit: 1, expectedAllowed: true},
+				{key: "a|1.1.1.1", limit: 1, expectedAllowed: false},
+				{key: "b|1.1.1.1", limit: 2, expectedAllowed: true},
+				{key: "b|1.1.1.1", limit: 2, expectedAllowed: true},
+				{key: "b|1.1.1.1", limit: 2, expectedAllowed: false},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			tm := timer.NewStub(time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC))
+			limiter := NewMemory(&tm, 0, time.Minute)
+
+			for _, req := range testCase.requests {
+				allowed, err := limiter.AllowUpTo(req.key, req.limit)
+				assert.Equal(t, nil, err)
+				assert.Equal(t, req.expectedAllowed, allowed)
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/short-d/short/backend/app/entity"
+)
+
+// AliasReservation accesses the reservations of custom aliases from storage,
+// such as database.
+type AliasReservation interface {
+	CreateReservation(ctx context.Context, reservation entity.AliasReservation, now time.Time) (bool, error)
+	FindReservation(ctx context.Context, alias string) (entity.AliasReservation, error)
+	DeleteReservation(ctx context.Context, alias string, token string) error
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/short-d/short/backend/app/entity"
+)
+
+var _ AliasReservation = (*AliasReservationFake)(nil)
+
+// AliasReservationFake represents in memory implementation of AliasReservation
+// repository. Only the reservations of the tenant attached to the context are
+// accessed.
+type AliasReservationFake struct {
+	mutex        sync.Mutex
+	reservations map[shortLinkKey]entity.AliasReservation
+}
+
+// CreateReservation holds the alias for the user unless another user holds it
+// at the given time. The previous reservation of the same user is replaced.
+func (a *AliasReservationFake) CreateReservation(
+	ctx context.Context,
+	reservation entity.AliasReservation,
+	now time.Time,
+) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.reservations == nil {
+		a.reservations = make(map[shortLinkKey]entity.AliasReservation)
+	}
+	key := newShortLinkKey(ctx, reservation.Alias)
+	prev, ok := a.reservations[key]
+	if ok && prev.UserID != reservation.UserID && prev.ExpireAt.After(now) {
+		return false, nil
+	}
+	a.reservations[key] = reservation
+	return true, nil
+}
+
+// FindReservation fetches the latest reservation of the alias, which may have
+// expired.
+func (a *AliasReservationFake) FindReservation(ctx context.Context, alias string) (entity.AliasReservation, error) {
+	if err := ctx.Err(); err != nil {
+		return entity.AliasReservation{}, err
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	reservation, ok := a.reservations[newShortLinkKey(ctx, alias)]
+	if !ok {
+		return entity.AliasReservation{}, ErrEntryNotFound(fmt.Sprintf("reservation not found (alias=%s)", alias))
+	}
+	return reservation, nil
+}
+
+// DeleteReservation releases the alias held with the given token.
+func (a *AliasReservationFake) DeleteReservation(ctx context.Context, alias string, token string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	key := newShortLinkKey(ctx, alias)
+	reservation, ok := a.reservations[key]
+	if ok && reservation.Token == token {
+		delete(a.reservations, key)
+	}
+	return nil
+}
@@ -0,0 +1,12 @@
+package repository
+
+import "context"
+
+// AliasSkeleton accesses the skeletons of aliases from storage, such as
+// database, so that the aliases looking alike can be found without comparing
+// against every alias.
+type AliasSkeleton interface {
+	CreateAliasSkeleton(ctx context.Context, alias string, skeleton string) error
+	RenameAliasSkeleton(ctx context.Context, oldAlias string, newAlias string, skeleton string) error
+	FindAliasBySkeleton(ctx context.Context, skeleton string, excludedAlias string) (string, bool, error)
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"sort"
+)
+
+var _ AliasSkeleton = (*AliasSkeletonFake)(nil)
+
+// AliasSkeletonFake represents in memory implementation of AliasSkeleton
+// repository.
+type AliasSkeletonFake struct {
+	skeletons map[string]string
+}
+
+// CreateAliasSkeleton saves the skeleton of the alias.
+func (a *AliasSkeletonFake) CreateAliasSkeleton(ctx context.Context, alias string, skeleton string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if a.skeletons == nil {
+		a.skeletons = make(map[string]string)
+	}
+	a.skeletons[alias] = skeleton
+	return nil
+}
+
+// RenameAliasSkeleton replaces the skeleton of the old alias with the
+// skeleton of the new alias.
+func (a *AliasSkeletonFake) RenameAliasSkeleton(ctx context.Context, oldAlias string, newAlias string, skeleton string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	delete(a.skeletons, oldAlias)
+	return a.CreateAliasSkeleton(ctx, newAlias, skeleton)
+}
+
+// FindAliasBySkeleton finds the first alias in alphabetical order with the
+// given skeleton other than the excluded alias.
+func (a AliasSkeletonFake) FindAliasBySkeleton(ctx context.Context, skeleton string, excludedAlias string) (string, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return "", false, err
+	}
+
+	var aliases []string
+	for alias, aliasSkeleton := range a.skeletons {
+		if aliasSkeleton == skeleton && alias != excludedAlias {
+			aliases = append(aliases, alias)
+		}
+	}
+	if len(aliases) == 0 {
+		return "", false, nil
+	}
+	sort.Strings(aliases)
+	return aliases[0], true, nil
+}
+
+// Skeletons retrieves the skeletons of all the aliases.
+func (a AliasSkeletonFake) Skeletons() map[string]string {
+	return a.skeletons
+}
+
+// NewAliasSkeletonFake creates in memory AliasSkeleton repository with the
+// skeletons keyed by the aliases.
+func NewAliasSkeletonFake(skeletons map[string]string) AliasSkeletonFake {
+	return AliasSkeletonFake{skeletons: skeletons}
+}
@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/short-d/short/backend/app/entity"
+)
+
+// FlaggedShortLink accesses the short links pending for review from storage,
+// such as database.
+type FlaggedShortLink interface {
+	CreateFlaggedShortLink(ctx context.Context, flaggedShortLink entity.FlaggedShortLink) error
+	CountFlaggedShortLinks(ctx context.Context) (int, error)
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/short-d/short/backend/app/entity"
+)
+
+var _ FlaggedShortLink = (*FlaggedShortLinkFake)(nil)
+
+// FlaggedShortLinkFake represents in memory implementation of FlaggedShortLink
+// repository.
+type FlaggedShortLinkFake struct {
+	flaggedShortLinks []entity.FlaggedShortLink
+}
+
+// CreateFlaggedShortLink flags a short link for review.
+func (f *FlaggedShortLinkFake) CreateFlaggedShortLink(ctx context.Context, flaggedShortLink entity.FlaggedShortLink) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	f.flaggedShortLinks = append(f.flaggedShortLinks, flaggedShortLink)
+	return nil
+}
+
+// CountFlaggedShortLinks counts all the short links flagged for review.
+func (f FlaggedShortLinkFake) CountFlaggedShortLinks(ctx context.Context) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return len(f.flaggedShortLinks), nil
+}
+
+// FlaggedShortLinks retrieves all the short links flagged for review.
+func (f FlaggedShortLinkFake) FlaggedShortLinks() []entity.FlaggedShortLink {
+	return f.flaggedShortLinks
+}
+
+// NewFlaggedShortLinkFake creates in memory FlaggedShortLink repository
+func NewFlaggedShortLinkFake(flaggedShortLinks []entity.FlaggedShortLink) FlaggedShortLinkFake {
+	return FlaggedShortLinkFake{flaggedShortLinks: flaggedShortLinks}
+}
@@ -0,0 +1,8 @@
+package repository
+
+// KeyCounter hands out incrementing IDs for generating keys from storage, such
+// as database.
+type KeyCounter interface {
+	NextKeyID() (uint64, error)
+	PeekKeyID() (uint64, error)
+}
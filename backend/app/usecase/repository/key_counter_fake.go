@@ -0,0 +1,38 @@
+package repository
+
+import "sync"
+
+var _ KeyCounter = (*KeyCounterFake)(nil)
+
+// KeyCounterFake represents in memory implementation of KeyCounter repository.
+type KeyCounterFake struct {
+	mutex *sync.Mutex
+	count *uint64
+}
+
+// NextKeyID increments the counter and returns its new value.
+func (k KeyCounterFake) NextKeyID() (uint64, error) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	*k.count++
+	return *k.count, nil
+}
+
+// PeekKeyID returns the value NextKeyID will return without incrementing the
+// counter.
+func (k KeyCounterFake) PeekKeyID() (uint64, error) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	return *k.count + 1, nil
+}
+
+// NewKeyCounterFake creates in memory KeyCounter repository which starts
+// counting after the given count.
+func NewKeyCounterFake(count uint64) KeyCounterFake {
+	return KeyCounterFake{
+		mutex: &sync.Mutex{},
+		count: &count,
+	}
+}
@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/short-d/short/backend/app/entity"
+)
+
+// LinkHealth accesses the health of long links from storage, such as database.
+type LinkHealth interface {
+	FindLinkHealthToCheck(limit int) ([]entity.LinkHealth, error)
+	FindLinkHealthByAliases(ctx context.Context, aliases []string) ([]entity.LinkHealth, error)
+	UpsertLinkHealth(linkHealth entity.LinkHealth) error
+}
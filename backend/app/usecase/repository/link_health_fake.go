@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"sort"
+
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/fw/tenant"
+)
+
+var _ LinkHealth = (*LinkHealthFake)(nil)
+
+type linkHealthKey struct {
+	tenantID string
+	alias    string
+}
+
+// LinkHealthFake represents in memory implementation of LinkHealth repository.
+type LinkHealthFake struct {
+	aliases     []string
+	linkHealths map[linkHealthKey]entity.LinkHealth
+}
+
+// FindLinkHealthToCheck fetches the health of the long links of the short
+// links which have not been checked for the longest time. Never checked short
+// links come first with unknown status.
+func (l LinkHealthFake) FindLinkHealthToCheck(limit int) ([]entity.LinkHealth, error) {
+	aliases := append([]string{}, l.aliases...)
+	sort.SliceStable(aliases, func(i, j int) bool {
+		prev, prevChecked := l.linkHealths[linkHealthKey{alias: aliases[i]}]
+		next, nextChecked := l.linkHealths[linkHealthKey{alias: aliases[j]}]
+		if prevChecked != nextChecked {
+			return !prevChecked
+		}
+		if !prev.LastCheckedAt.Equal(next.LastCheckedAt) {
+			return prev.LastCheckedAt.Before(next.LastCheckedAt)
+		}
+		return aliases[i] < aliases[j]
+	})
+
+	if len(aliases) > limit {
+		aliases = aliases[:limit]
+	}
+
+	linkHealths := []entity.LinkHealth{}
+	for _, alias := range aliases {
+		linkHealth, ok := l.linkHealths[linkHealthKey{alias: alias}]
+		if !ok {
+			linkHealth = entity.LinkHealth{
+				TenantID: tenant.Default,
+				Alias:    alias,
+				Status:   entity.LinkHealthUnknown,
+			}
+		}
+		linkHealths = append(linkHealths, linkHealth)
+	}
+	return linkHealths, nil
+}
+
+// FindLinkHealthByAliases fetches the health of the long links of the given
+// short links. Short links never checked are skipped.
+func (l LinkHealthFake) FindLinkHealthByAliases(ctx context.Context, aliases []string) ([]entity.LinkHealth, error) {
+	linkHealths := []entity.LinkHealth{}
+	for _, alias := range aliases {
+		linkHealth, ok := l.linkHealths[linkHealthKey{
+			tenantID: tenant.FromContext(ctx),
+			alias:    alias,
+		}]
+		if !ok {
+			continue
+		}
+		linkHealths = append(linkHealths, linkHealth)
+	}
+	return linkHealths, nil
+}
+
+// UpsertLinkHealth creates or replaces the health of the long link of a short
+// link.
+func (l *LinkHealthFake) UpsertLinkHealth(linkHealth entity.LinkHealth) error {
+	l.linkHealths[linkHealthKey{
+		tenantID: linkHealth.TenantID,
+		alias:    linkHealth.Alias,
+	}] = linkHealth
+	return nil
+}
+
+// NewLinkHealthFake creates in memory LinkHealth repository with the short
+// links of the default tenant.
+func NewLinkHealthFake(aliases []string, linkHealths []entity.LinkHealth) LinkHealthFake {
+	linkHealthMap := make(map[linkHealthKey]entity.LinkHealth)
+	for _, linkHealth := range linkHealths {
+		linkHealthMap[linkHealthKey{
+			tenantID: linkHealth.TenantID,
+			alias:    linkHealth.Alias,
+		}] = linkHealth
+	}
+	return LinkHealthFake{
+		aliases:     aliases,
+		linkHealths: linkHealthMap,
+	}
+}
@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/short-d/short/backend/app/entity"
+)
+
+// ShortLinkAudit accesses the administrative actions taken on short links from
+// storage, such as database.
+type ShortLinkAudit interface {
+	CreateAuditEntry(ctx context.Context, entry entity.ShortLinkAuditEntry) error
+}
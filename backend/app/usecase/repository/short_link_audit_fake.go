@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/short-d/short/backend/app/entity"
+)
+
+var _ ShortLinkAudit = (*ShortLinkAuditFake)(nil)
+
+// ShortLinkAuditFake represents in memory implementation of ShortLinkAudit
+// repository.
+type ShortLinkAuditFake struct {
+	entries []entity.ShortLinkAuditEntry
+}
+
+// CreateAuditEntry records an administrative action taken on a short link.
+func (s *ShortLinkAuditFake) CreateAuditEntry(ctx context.Context, entry entity.ShortLinkAuditEntry) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+// AuditEntries retrieves all the recorded administrative actions.
+func (s ShortLinkAuditFake) AuditEntries() []entity.ShortLinkAuditEntry {
+	return s.entries
+}
+
+// NewShortLinkAuditFake creates in memory ShortLinkAudit repository
+func NewShortLinkAuditFake(entries []entity.ShortLinkAuditEntry) ShortLinkAuditFake {
+	return ShortLinkAuditFake{entries: entries}
+}
@@ -1,14 +1,23 @@
 package repository
 
 import (
+	"context"
+	"time"
+
 	"github.com/short-d/short/backend/app/entity"
 )
 
 // ShortLink accesses shortLinks from storage, such as database.
 type ShortLink interface {
-	IsAliasExist(alias string) (bool, error)
-	GetShortLinkByAlias(alias string) (entity.ShortLink, error)
-	CreateShortLink(shortLinkInput entity.ShortLinkInput) error
-	UpdateShortLink(oldAlias string, shortLinkInput entity.ShortLinkInput) (entity.ShortLink, error)
-	GetShortLinksByAliases(aliases []string) ([]entity.ShortLink, error)
+	IsAliasExist(ctx context.Context, alias string) (bool, error)
+	GetShortLinkByAlias(ctx context.Context, alias string) (entity.ShortLink, error)
+	CreateShortLink(ctx context.Context, shortLinkInput entity.ShortLinkInput) error
+	UpdateShortLink(ctx context.Context, oldAlias string, shortLinkInput entity.ShortLinkInput) (entity.ShortLink, error)
+	GetShortLinksByAliases(ctx context.Context, aliases []string) ([]entity.ShortLink, error)
+	DeleteShortLinks(ctx context.Context, aliases []string) (int, error)
+	FindShortLinkByLongLink(ctx context.Context, longLink string, activeAt time.Time) (entity.ShortLink, error)
+	IncreaseVisitCounts(ctx context.Context, increments map[string]int) error
+	ConsumeVisit(ctx context.Context, alias string) (bool, error)
+	CountShortLinks(ctx context.Context) (int, error)
+	CountShortLinksCreatedSince(ctx context.Context, since time.Time) (int, error)
 }
@@ -1,74 +1,104 @@
 package repository
 
 import (
+	"context"
 	"errors"
+	"sync"
 	"time"
 
 	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/fw/tenant"
 )
 
 var _ ShortLink = (*ShortLinkFake)(nil)
 
+type shortLinkKey struct {
+	tenantID string
+	alias    string
+}
+
+func newShortLinkKey(ctx context.Context, alias string) shortLinkKey {
+	return shortLinkKey{tenantID: tenant.FromContext(ctx), alias: alias}
+}
+
 // ShortLinkFake accesses ShortLink information in short_link table through SQL.
+// Only the short links of the tenant attached to the context are accessed.
 type ShortLinkFake struct {
-	shortLinks map[string]entity.ShortLink
+	shortLinks  map[shortLinkKey]entity.ShortLink
+	usedVisits  map[shortLinkKey]int
+	visitsMutex *sync.Mutex
 	// TODO(issue#958) use eventbus for propagating short link change to all related repos
 	userShortLinkRepoFake *UserShortLinkFake
 }
 
 // IsAliasExist checks whether a given alias exist in short_link table.
-func (s ShortLinkFake) IsAliasExist(alias string) (bool, error) {
-	_, ok := s.shortLinks[alias]
+func (s ShortLinkFake) IsAliasExist(ctx context.Context, alias string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	_, ok := s.shortLinks[newShortLinkKey(ctx, alias)]
 	return ok, nil
 }
 
 // CreateShortLink inserts a new ShortLink into short_link table.
-func (s *ShortLinkFake) CreateShortLink(shortLinkInput entity.ShortLinkInput) error {
+func (s *ShortLinkFake) CreateShortLink(ctx context.Context, shortLinkInput entity.ShortLinkInput) error {
 	if shortLinkInput.CustomAlias == nil {
 		return errors.New("alias empty")
 	}
 	customAlias := shortLinkInput.GetCustomAlias("")
-	isExist, err := s.IsAliasExist(customAlias)
+	isExist, err := s.IsAliasExist(ctx, customAlias)
 	if err != nil {
 		return err
 	}
 	if isExist {
 		return errors.New("alias exists")
 	}
-	s.shortLinks[customAlias] = entity.ShortLink{
-		Alias:     customAlias,
-		LongLink:  shortLinkInput.GetLongLink(""),
-		ExpireAt:  shortLinkInput.ExpireAt,
-		CreatedAt: shortLinkInput.CreatedAt,
+	s.shortLinks[newShortLinkKey(ctx, customAlias)] = entity.ShortLink{
+		TenantID:          tenant.FromContext(ctx),
+		Alias:             customAlias,
+		LongLink:          shortLinkInput.GetLongLink(""),
+		OriginalLongLink:  shortLinkInput.GetOriginalLongLink(""),
+		ExpireAt:          shortLinkInput.ExpireAt,
+		CreatedAt:         shortLinkInput.CreatedAt,
+		TrackVisits:       shortLinkInput.GetTrackVisits(true),
+		PassthroughQuery:  shortLinkInput.GetPassthroughQuery(false),
+		PassthroughPath:   shortLinkInput.GetPassthroughPath(false),
+		MaxRedirectsPerIP: shortLinkInput.GetMaxRedirectsPerIP(0),
+		MaxVisits:         shortLinkInput.GetMaxVisits(0),
+		Description:       shortLinkInput.GetDescription(""),
 	}
 	return nil
 }
 
 // GetShortLinkByAlias finds an ShortLink in short_link table given alias.
-func (s ShortLinkFake) GetShortLinkByAlias(alias string) (entity.ShortLink, error) {
-	isExist, err := s.IsAliasExist(alias)
+func (s ShortLinkFake) GetShortLinkByAlias(ctx context.Context, alias string) (entity.ShortLink, error) {
+	isExist, err := s.IsAliasExist(ctx, alias)
 	if err != nil {
 		return entity.ShortLink{}, err
 	}
 	if !isExist {
-		return entity.ShortLink{}, errors.New("alias not found")
+		return entity.ShortLink{}, ErrEntryNotFound("alias not found")
 	}
-	shortLink := s.shortLinks[alias]
+	shortLink := s.shortLinks[newShortLinkKey(ctx, alias)]
 	return shortLink, nil
 }
 
-// GetShortLinksByAliases finds all ShortLink for a list of aliases
-func (s ShortLinkFake) GetShortLinksByAliases(aliases []string) ([]entity.ShortLink, error) {
+// GetShortLinksByAliases finds all ShortLink for a list of aliases, skipping
+// the aliases which do not exist.
+func (s ShortLinkFake) GetShortLinksByAliases(ctx context.Context, aliases []string) ([]entity.ShortLink, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	if len(aliases) == 0 {
 		return []entity.ShortLink{}, nil
 	}
 
 	var shortLinks []entity.ShortLink
 	for _, alias := range aliases {
-		shortLink, err := s.GetShortLinkByAlias(alias)
-
-		if err != nil {
-			return shortLinks, err
+		shortLink, ok := s.shortLinks[newShortLinkKey(ctx, alias)]
+		if !ok {
+			continue
 		}
 		shortLinks = append(shortLinks, shortLink)
 	}
@@ -76,39 +106,214 @@ func (s ShortLinkFake) GetShortLinksByAliases(aliases []string) ([]entity.ShortL
 }
 
 // UpdateShortLink updates an existing ShortLink with new properties.
-func (s ShortLinkFake) UpdateShortLink(oldAlias string, shortLinkInput entity.ShortLinkInput) (entity.ShortLink, error) {
+func (s ShortLinkFake) UpdateShortLink(ctx context.Context, oldAlias string, shortLinkInput entity.ShortLinkInput) (entity.ShortLink, error) {
+	if err := ctx.Err(); err != nil {
+		return entity.ShortLink{}, err
+	}
+
 	if shortLinkInput.CustomAlias == nil {
 		return entity.ShortLink{}, errors.New("alias empty")
 	}
 
-	prevShortLink, ok := s.shortLinks[oldAlias]
+	prevShortLink, ok := s.shortLinks[newShortLinkKey(ctx, oldAlias)]
 	if !ok {
 		return entity.ShortLink{}, errors.New("alias not found")
 	}
 
 	// TODO(issue#958) use eventbus for propagating short link change to all related repos
-	err := s.userShortLinkRepoFake.UpdateAliasCascade(oldAlias, shortLinkInput)
-	if err != nil {
-		return entity.ShortLink{}, err
+	if s.userShortLinkRepoFake != nil {
+		err := s.userShortLinkRepoFake.UpdateAliasCascade(ctx, oldAlias, shortLinkInput)
+		if err != nil {
+			return entity.ShortLink{}, err
+		}
 	}
 
-	now := time.Now().UTC()
 	createdBy := prevShortLink.CreatedBy
 	createdAt := prevShortLink.CreatedAt
-	return entity.ShortLink{
-		Alias:     shortLinkInput.GetCustomAlias(""),
-		LongLink:  shortLinkInput.GetLongLink(""),
-		ExpireAt:  shortLinkInput.ExpireAt,
-		CreatedBy: createdBy,
-		CreatedAt: createdAt,
-		UpdatedAt: &now,
-	}, nil
+	shortLink := entity.ShortLink{
+		TenantID:          prevShortLink.TenantID,
+		Alias:             shortLinkInput.GetCustomAlias(""),
+		LongLink:          shortLinkInput.GetLongLink(""),
+		OriginalLongLink:  shortLinkInput.GetOriginalLongLink(""),
+		ExpireAt:          shortLinkInput.ExpireAt,
+		CreatedBy:         createdBy,
+		CreatedAt:         createdAt,
+		UpdatedAt:         shortLinkInput.UpdatedAt,
+		TrackVisits:       shortLinkInput.GetTrackVisits(prevShortLink.TrackVisits),
+		PassthroughQuery:  shortLinkInput.GetPassthroughQuery(prevShortLink.PassthroughQuery),
+		PassthroughPath:   shortLinkInput.GetPassthroughPath(prevShortLink.PassthroughPath),
+		MaxRedirectsPerIP: shortLinkInput.GetMaxRedirectsPerIP(prevShortLink.MaxRedirectsPerIP),
+		MaxVisits:         prevShortLink.MaxVisits,
+		Description:       shortLinkInput.GetDescription(prevShortLink.Description),
+	}
+	oldKey := newShortLinkKey(ctx, oldAlias)
+	newKey := newShortLinkKey(ctx, shortLink.Alias)
+	delete(s.shortLinks, oldKey)
+	s.shortLinks[newKey] = shortLink
+
+	s.visitsMutex.Lock()
+	defer s.visitsMutex.Unlock()
+	usedVisits := s.usedVisits[oldKey]
+	delete(s.usedVisits, oldKey)
+	s.usedVisits[newKey] = usedVisits
+	return shortLink, nil
+}
+
+// DeleteShortLinks removes the ShortLinks with the given aliases, skipping the
+// aliases which do not exist, and returns the number of removed ShortLinks.
+func (s ShortLinkFake) DeleteShortLinks(ctx context.Context, aliases []string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, alias := range aliases {
+		key := newShortLinkKey(ctx, alias)
+		if _, ok := s.shortLinks[key]; !ok {
+			continue
+		}
+		delete(s.shortLinks, key)
+		s.visitsMutex.Lock()
+		delete(s.usedVisits, key)
+		s.visitsMutex.Unlock()
+		count++
+
+		// TODO(issue#958) use eventbus for propagating short link change to all related repos
+		if s.userShortLinkRepoFake != nil {
+			s.userShortLinkRepoFake.DeleteAliasCascade(ctx, alias)
+		}
+	}
+	return count, nil
+}
+
+// FindShortLinkByLongLink finds the earliest created ShortLink redirecting to
+// the given long link which is not expired at activeAt. The ShortLinks
+// limiting the total visits are skipped since they can't be shared.
+func (s ShortLinkFake) FindShortLinkByLongLink(ctx context.Context, longLink string, activeAt time.Time) (entity.ShortLink, error) {
+	if err := ctx.Err(); err != nil {
+		return entity.ShortLink{}, err
+	}
+
+	var found *entity.ShortLink
+	for key, shortLink := range s.shortLinks {
+		if key.tenantID != tenant.FromContext(ctx) || shortLink.LongLink != longLink {
+			continue
+		}
+		if shortLink.ExpireAt != nil && !shortLink.ExpireAt.After(activeAt) {
+			continue
+		}
+		if shortLink.MaxVisits > 0 {
+			continue
+		}
+		shortLink := shortLink
+		if found == nil || isCreatedBefore(shortLink, *found) {
+			found = &shortLink
+		}
+	}
+	if found == nil {
+		return entity.ShortLink{}, ErrEntryNotFound("long link not found")
+	}
+	return *found, nil
+}
+
+// CountShortLinks counts all the short links across all the tenants.
+func (s ShortLinkFake) CountShortLinks(ctx context.Context) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return len(s.shortLinks), nil
+}
+
+// CountShortLinksCreatedSince counts the short links across all the tenants
+// created at or after since. The short links without creation time are
+// skipped.
+func (s ShortLinkFake) CountShortLinksCreatedSince(ctx context.Context, since time.Time) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, shortLink := range s.shortLinks {
+		if shortLink.CreatedAt == nil || shortLink.CreatedAt.Before(since) {
+			continue
+		}
+		count++
+	}
+	return count, nil
+}
+
+// IncreaseVisitCounts adds the increments to the visit counts of the short
+// links, ignoring the aliases which do not exist.
+func (s ShortLinkFake) IncreaseVisitCounts(ctx context.Context, increments map[string]int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	for alias, increment := range increments {
+		key := newShortLinkKey(ctx, alias)
+		shortLink, ok := s.shortLinks[key]
+		if !ok {
+			continue
+		}
+		shortLink.VisitCount += increment
+		s.shortLinks[key] = shortLink
+	}
+	return nil
+}
+
+// ConsumeVisit uses up one of the visits of the ShortLink limiting the total
+// visits, reporting false when no visit is left.
+func (s ShortLinkFake) ConsumeVisit(ctx context.Context, alias string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	key := newShortLinkKey(ctx, alias)
+	shortLink, ok := s.shortLinks[key]
+	if !ok {
+		return false, nil
+	}
+
+	s.visitsMutex.Lock()
+	defer s.visitsMutex.Unlock()
+	if s.usedVisits[key] >= shortLink.MaxVisits {
+		return false, nil
+	}
+	s.usedVisits[key]++
+	return true, nil
 }
 
-// NewShortLinkFake creates in memory ShortLink repository
+// isCreatedBefore orders ShortLinks by creation time and then alias, putting
+// the ShortLinks without creation time first.
+func isCreatedBefore(shortLink entity.ShortLink, other entity.ShortLink) bool {
+	createdAt := time.Time{}
+	if shortLink.CreatedAt != nil {
+		createdAt = *shortLink.CreatedAt
+	}
+	otherCreatedAt := time.Time{}
+	if other.CreatedAt != nil {
+		otherCreatedAt = *other.CreatedAt
+	}
+	if !createdAt.Equal(otherCreatedAt) {
+		return createdAt.Before(otherCreatedAt)
+	}
+	return shortLink.Alias < other.Alias
+}
+
+// NewShortLinkFake creates in memory ShortLink repository with the given
+// ShortLinks keyed by alias, each of which belongs to its own tenant.
 func NewShortLinkFake(userShortLinkRepoFake *UserShortLinkFake, shortLinks map[string]entity.ShortLink) ShortLinkFake {
+	tenantShortLinks := make(map[shortLinkKey]entity.ShortLink)
+	for alias, shortLink := range shortLinks {
+		tenantShortLinks[shortLinkKey{tenantID: shortLink.TenantID, alias: alias}] = shortLink
+	}
+	if userShortLinkRepoFake != nil {
+		userShortLinkRepoFake.existingShortLinks = tenantShortLinks
+	}
 	return ShortLinkFake{
-		shortLinks:            shortLinks,
+		shortLinks:            tenantShortLinks,
+		usedVisits:            make(map[shortLinkKey]int),
+		visitsMutex:           &sync.Mutex{},
 		userShortLinkRepoFake: userShortLinkRepoFake,
 	}
 }
@@ -1,6 +1,10 @@
 package repository
 
-import "github.com/short-d/short/backend/app/entity"
+import (
+	"time"
+
+	"github.com/short-d/short/backend/app/entity"
+)
 
 // User accesses users' information from storage, such as database.
 type User interface {
@@ -9,4 +13,8 @@ type User interface {
 	GetUserByID(id string) (entity.User, error)
 	GetUserByEmail(email string) (entity.User, error)
 	CreateUser(user entity.User) error
+	MarkEmailVerified(id string) error
+	RevokeTokens(id string, revokedAt time.Time) error
+	GetTokensRevokedAt(id string) (time.Time, error)
+	CountUsers() (int, error)
 }
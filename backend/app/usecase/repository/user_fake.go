@@ -2,6 +2,7 @@ package repository
 
 import (
 	"errors"
+	"time"
 
 	"github.com/short-d/short/backend/app/entity"
 )
@@ -10,7 +11,8 @@ var _ User = (*UserFake)(nil)
 
 // UserFake represents in memory implementation of user repository.
 type UserFake struct {
-	users []entity.User
+	users           []entity.User
+	tokensRevokedAt map[string]time.Time
 }
 
 // IsIDExist checks whether a given user id exists in the repository.
@@ -74,9 +76,45 @@ func (u *UserFake) CreateUser(user entity.User) error {
 	return nil
 }
 
+// MarkEmailVerified records that the email of the user is verified.
+func (u *UserFake) MarkEmailVerified(id string) error {
+	for idx, user := range u.users {
+		if user.ID == id {
+			u.users[idx].EmailVerified = true
+			return nil
+		}
+	}
+	return ErrEntryNotFound("ID not found")
+}
+
+// RevokeTokens records that the tokens issued to the user before revokedAt
+// are no longer valid.
+func (u *UserFake) RevokeTokens(id string, revokedAt time.Time) error {
+	if !u.IsUserIDExist(id) {
+		return ErrEntryNotFound("ID not found")
+	}
+	u.tokensRevokedAt[id] = revokedAt
+	return nil
+}
+
+// GetTokensRevokedAt fetches the last time when the tokens of the user are
+// revoked. Zero time is returned if the tokens are never revoked.
+func (u UserFake) GetTokensRevokedAt(id string) (time.Time, error) {
+	if !u.IsUserIDExist(id) {
+		return time.Time{}, ErrEntryNotFound("ID not found")
+	}
+	return u.tokensRevokedAt[id], nil
+}
+
+// CountUsers counts all the users.
+func (u UserFake) CountUsers() (int, error) {
+	return len(u.users), nil
+}
+
 // NewUserFake create in memory user repository implementation.
 func NewUserFake(users []entity.User) UserFake {
 	return UserFake{
-		users: users,
+		users:           users,
+		tokensRevokedAt: make(map[string]time.Time),
 	}
 }
@@ -0,0 +1,9 @@
+package repository
+
+// UserPassword accesses the password hashes of users signing in with email
+// and password from storage, such as database.
+type UserPassword interface {
+	CreatePasswordHash(userID string, passwordHash string) error
+	GetPasswordHash(userID string) (string, error)
+	UpdatePasswordHash(userID string, passwordHash string) error
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+)
+
+var _ UserPassword = (*UserPasswordFake)(nil)
+
+// UserPasswordFake represents in memory implementation of UserPassword
+// repository.
+type UserPasswordFake struct {
+	passwordHashes map[string]string
+}
+
+// CreatePasswordHash stores the password hash of the user.
+func (u *UserPasswordFake) CreatePasswordHash(userID string, passwordHash string) error {
+	if _, ok := u.passwordHashes[userID]; ok {
+		return errors.New("password exists")
+	}
+	u.passwordHashes[userID] = passwordHash
+	return nil
+}
+
+// GetPasswordHash fetches the password hash of the user.
+func (u UserPasswordFake) GetPasswordHash(userID string) (string, error) {
+	passwordHash, ok := u.passwordHashes[userID]
+	if !ok {
+		return "", ErrEntryNotFound(fmt.Sprintf("password of user %s not found", userID))
+	}
+	return passwordHash, nil
+}
+
+// UpdatePasswordHash replaces the password hash of the user.
+func (u *UserPasswordFake) UpdatePasswordHash(userID string, passwordHash string) error {
+	if _, ok := u.passwordHashes[userID]; !ok {
+		return ErrEntryNotFound(fmt.Sprintf("password of user %s not found", userID))
+	}
+	u.passwordHashes[userID] = passwordHash
+	return nil
+}
+
+// NewUserPasswordFake creates in memory UserPassword repository
+func NewUserPasswordFake(passwordHashes map[string]string) UserPasswordFake {
+	return UserPasswordFake{passwordHashes: passwordHashes}
+}
@@ -0,0 +1,10 @@
+package repository
+
+import "github.com/short-d/short/backend/app/entity"
+
+// UserPreferences accesses the preferences of users from storage, such as
+// database.
+type UserPreferences interface {
+	GetPreferences(userID string) (entity.UserPreferences, error)
+	UpsertPreferences(userID string, preferences entity.UserPreferences) error
+}
@@ -0,0 +1,31 @@
+package repository
+
+import "github.com/short-d/short/backend/app/entity"
+
+var _ UserPreferences = (*UserPreferencesFake)(nil)
+
+// UserPreferencesFake represents in memory implementation of UserPreferences
+// repository.
+type UserPreferencesFake struct {
+	preferences map[string]entity.UserPreferences
+}
+
+// GetPreferences fetches the preferences of the user. Users who never set
+// preferences get empty preferences.
+func (u UserPreferencesFake) GetPreferences(userID string) (entity.UserPreferences, error) {
+	return u.preferences[userID], nil
+}
+
+// UpsertPreferences creates or replaces the preferences of the user.
+func (u *UserPreferencesFake) UpsertPreferences(userID string, preferences entity.UserPreferences) error {
+	u.preferences[userID] = preferences
+	return nil
+}
+
+// NewUserPreferencesFake creates in memory UserPreferences repository
+func NewUserPreferencesFake(preferences map[string]entity.UserPreferences) UserPreferencesFake {
+	if preferences == nil {
+		preferences = make(map[string]entity.UserPreferences)
+	}
+	return UserPreferencesFake{preferences: preferences}
+}
@@ -1,10 +1,55 @@
 package repository
 
-import "github.com/short-d/short/backend/app/entity"
+import (
+	"context"
+	"time"
+
+	"github.com/short-d/short/backend/app/entity"
+)
 
 // UserShortLink accesses User-ShortLink relationship from storage, such as database.
 type UserShortLink interface {
-	CreateRelation(user entity.User, shortLinkInput entity.ShortLinkInput) error
-	FindAliasesByUser(user entity.User) ([]string, error)
-	HasMapping(user entity.User, alias string) (bool, error)
+	CreateRelation(ctx context.Context, user entity.User, shortLinkInput entity.ShortLinkInput, isCustomAlias bool) error
+	CountAliasesByUser(ctx context.Context, user entity.User, isCustomAlias bool, activeAt time.Time) (int, error)
+	CountShortLinksByUser(ctx context.Context, user entity.User) (int, error)
+	FindAliasesByUser(ctx context.Context, user entity.User) ([]string, error)
+	FindShortLinksByUser(ctx context.Context, user entity.User, after *ShortLinkCursor, limit int) ([]entity.ShortLink, error)
+	FindShortLinksByUserOffset(ctx context.Context, user entity.User, offset int, limit int) ([]entity.ShortLink, error)
+	FindShortLinksByAliases(ctx context.Context, user entity.User, aliases []string) ([]entity.ShortLink, error)
+	HasMapping(ctx context.Context, user entity.User, alias string) (bool, error)
+	CreateGuestRelation(ctx context.Context, sessionID string, shortLinkInput entity.ShortLinkInput) error
+	FindAliasesBySession(ctx context.Context, sessionID string) ([]string, error)
+	ClaimSession(ctx context.Context, sessionID string, user entity.User) (int, error)
+	FindOrphanAliases(ctx context.Context, after string, limit int) ([]string, error)
+	DeleteOrphanRelations(ctx context.Context, aliases []string) (int, error)
+}
+
+// ShortLinkCursor represents the position of a ShortLink in the list ordered
+// by creation time and alias, both descending. ShortLinks without creation
+// time are positioned as if they were created at the zero time.
+type ShortLinkCursor struct {
+	CreatedAt time.Time
+	Alias     string
+}
+
+// Precedes checks whether the given ShortLink comes after the cursor in the
+// list.
+func (c ShortLinkCursor) Precedes(shortLink entity.ShortLink) bool {
+	createdAt := time.Time{}
+	if shortLink.CreatedAt != nil {
+		createdAt = *shortLink.CreatedAt
+	}
+	if !createdAt.Equal(c.CreatedAt) {
+		return createdAt.Before(c.CreatedAt)
+	}
+	return shortLink.Alias < c.Alias
+}
+
+// NewShortLinkCursor creates ShortLinkCursor pointing to the given ShortLink.
+func NewShortLinkCursor(shortLink entity.ShortLink) ShortLinkCursor {
+	cursor := ShortLinkCursor{Alias: shortLink.Alias}
+	if shortLink.CreatedAt != nil {
+		cursor.CreatedAt = *shortLink.CreatedAt
+	}
+	return cursor
 }
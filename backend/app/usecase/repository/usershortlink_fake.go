@@ -1,27 +1,45 @@
 package repository
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/fw/tenant"
 )
 
 var _ UserShortLink = (*UserShortLinkFake)(nil)
 
 // UserShortLinkFake represents in memory implementation of User-ShortLink relationship accessor.
+// Only the relations of the tenant attached to the context are accessed.
 type UserShortLinkFake struct {
-	users      []entity.User
-	shortLinks []entity.ShortLink
+	users         []entity.User
+	shortLinks    []entity.ShortLink
+	customAliases map[shortLinkKey]entity.Empty
+
+	sessionIDs      []string
+	guestShortLinks []entity.ShortLink
+
+	// existingShortLinks is shared with ShortLinkFake to find the relations
+	// of the ShortLinks which no longer exist.
+	// TODO(issue#958) use eventbus for propagating short link change to all related repos
+	existingShortLinks map[shortLinkKey]entity.ShortLink
+}
+
+func isShortLinkOf(ctx context.Context, shortLink entity.ShortLink, alias string) bool {
+	return shortLink.TenantID == tenant.FromContext(ctx) && shortLink.Alias == alias
 }
 
 // CreateRelation creates many to many relationship between User and ShortLink.
-func (u *UserShortLinkFake) CreateRelation(user entity.User, shortLinkInput entity.ShortLinkInput) error {
+func (u *UserShortLinkFake) CreateRelation(ctx context.Context, user entity.User, shortLinkInput entity.ShortLinkInput, isCustomAlias bool) error {
 	if shortLinkInput.CustomAlias == nil {
 		return errors.New("empty alias")
 	}
 	customAlias := shortLinkInput.GetCustomAlias("")
-	isExist, err := u.HasMapping(user, customAlias)
+	isExist, err := u.HasMapping(ctx, user, customAlias)
 	if err != nil {
 		return err
 	}
@@ -30,19 +48,63 @@ func (u *UserShortLinkFake) CreateRelation(user entity.User, shortLinkInput enti
 	}
 	u.users = append(u.users, user)
 	u.shortLinks = append(u.shortLinks, entity.ShortLink{
+		TenantID:  tenant.FromContext(ctx),
 		Alias:     customAlias,
 		LongLink:  shortLinkInput.GetLongLink(""),
 		ExpireAt:  shortLinkInput.ExpireAt,
 		CreatedAt: shortLinkInput.CreatedAt,
 	})
+	if isCustomAlias {
+		if u.customAliases == nil {
+			u.customAliases = make(map[shortLinkKey]entity.Empty)
+		}
+		u.customAliases[newShortLinkKey(ctx, customAlias)] = entity.Empty{}
+	}
 	return nil
 }
 
+// CountAliasesByUser counts the ShortLinks of the given user which are not
+// expired at activeAt, either with custom aliases or auto generated ones.
+func (u UserShortLinkFake) CountAliasesByUser(ctx context.Context, user entity.User, isCustomAlias bool, activeAt time.Time) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for idx, currUser := range u.users {
+		if currUser.ID != user.ID {
+			continue
+		}
+		shortLink := u.shortLinks[idx]
+		if shortLink.TenantID != tenant.FromContext(ctx) {
+			continue
+		}
+		if shortLink.ExpireAt != nil && !shortLink.ExpireAt.After(activeAt) {
+			continue
+		}
+		_, ok := u.customAliases[newShortLinkKey(ctx, shortLink.Alias)]
+		if ok == isCustomAlias {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// CountShortLinksByUser counts the ShortLinks created by the given user.
+func (u UserShortLinkFake) CountShortLinksByUser(ctx context.Context, user entity.User) (int, error) {
+	aliases, err := u.FindAliasesByUser(ctx, user)
+	return len(aliases), err
+}
+
 // FindAliasesByUser fetches the aliases of all the ShortLinks created by the given user.
-func (u UserShortLinkFake) FindAliasesByUser(user entity.User) ([]string, error) {
+func (u UserShortLinkFake) FindAliasesByUser(ctx context.Context, user entity.User) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	var aliases []string
 	for idx, currUser := range u.users {
-		if currUser.ID != user.ID {
+		if currUser.ID != user.ID || u.shortLinks[idx].TenantID != tenant.FromContext(ctx) {
 			continue
 		}
 		aliases = append(aliases, u.shortLinks[idx].Alias)
@@ -50,22 +112,250 @@ func (u UserShortLinkFake) FindAliasesByUser(user entity.User) ([]string, error)
 	return aliases, nil
 }
 
+// FindShortLinksByUser fetches at most limit ShortLinks created by the given
+// user after the cursor, ordered by creation time and alias in descending
+// order.
+func (u UserShortLinkFake) FindShortLinksByUser(
+	ctx context.Context,
+	user entity.User,
+	after *ShortLinkCursor,
+	limit int,
+) ([]entity.ShortLink, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	shortLinks := []entity.ShortLink{}
+	for idx, currUser := range u.users {
+		if currUser.ID != user.ID {
+			continue
+		}
+		shortLink := u.shortLinks[idx]
+		if shortLink.TenantID != tenant.FromContext(ctx) {
+			continue
+		}
+		if after != nil && !after.Precedes(shortLink) {
+			continue
+		}
+		shortLinks = append(shortLinks, shortLink)
+	}
+
+	sort.Slice(shortLinks, func(i, j int) bool {
+		return NewShortLinkCursor(shortLinks[i]).Precedes(shortLinks[j])
+	})
+	if len(shortLinks) > limit {
+		shortLinks = shortLinks[:limit]
+	}
+	return shortLinks, nil
+}
+
+// FindShortLinksByUserOffset fetches at most limit ShortLinks created by the
+// given user after skipping the first offset ones, in the same order as
+// FindShortLinksByUser.
+func (u UserShortLinkFake) FindShortLinksByUserOffset(
+	ctx context.Context,
+	user entity.User,
+	offset int,
+	limit int,
+) ([]entity.ShortLink, error) {
+	shortLinks, err := u.FindShortLinksByUser(ctx, user, nil, offset+limit)
+	if err != nil {
+		return nil, err
+	}
+	if offset >= len(shortLinks) {
+		return []entity.ShortLink{}, nil
+	}
+	return shortLinks[offset:], nil
+}
+
+// FindShortLinksByAliases fetches the ShortLinks of the given aliases created
+// by the given user, in no particular order. The aliases of the ShortLinks
+// created by other users are skipped.
+func (u UserShortLinkFake) FindShortLinksByAliases(
+	ctx context.Context,
+	user entity.User,
+	aliases []string,
+) ([]entity.ShortLink, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	requested := make(map[string]bool)
+	for _, alias := range aliases {
+		requested[alias] = true
+	}
+
+	shortLinks := []entity.ShortLink{}
+	for idx, currUser := range u.users {
+		if currUser.ID != user.ID {
+			continue
+		}
+		shortLink := u.shortLinks[idx]
+		if shortLink.TenantID != tenant.FromContext(ctx) || !requested[shortLink.Alias] {
+			continue
+		}
+		shortLinks = append(shortLinks, shortLink)
+	}
+	return shortLinks, nil
+}
+
 // HasMapping checks whether a given short link belongs to a user.
-func (u UserShortLinkFake) HasMapping(user entity.User, alias string) (bool, error) {
+func (u UserShortLinkFake) HasMapping(ctx context.Context, user entity.User, alias string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
 	for idx, currUser := range u.users {
-		if currUser.ID == user.ID && u.shortLinks[idx].Alias == alias {
+		if currUser.ID == user.ID && isShortLinkOf(ctx, u.shortLinks[idx], alias) {
 			return true, nil
 		}
 	}
 	return false, nil
 }
 
+// CreateGuestRelation attributes a ShortLink created by a signed out user to
+// the guest session.
+func (u *UserShortLinkFake) CreateGuestRelation(ctx context.Context, sessionID string, shortLinkInput entity.ShortLinkInput) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if shortLinkInput.CustomAlias == nil {
+		return errors.New("empty alias")
+	}
+
+	customAlias := shortLinkInput.GetCustomAlias("")
+	for _, shortLink := range u.guestShortLinks {
+		if isShortLinkOf(ctx, shortLink, customAlias) {
+			return errors.New("relationship exists")
+		}
+	}
+	u.sessionIDs = append(u.sessionIDs, sessionID)
+	u.guestShortLinks = append(u.guestShortLinks, entity.ShortLink{
+		TenantID:  tenant.FromContext(ctx),
+		Alias:     customAlias,
+		LongLink:  shortLinkInput.GetLongLink(""),
+		ExpireAt:  shortLinkInput.ExpireAt,
+		CreatedAt: shortLinkInput.CreatedAt,
+	})
+	return nil
+}
+
+// FindAliasesBySession fetches the aliases of all the ShortLinks created in
+// the given guest session.
+func (u UserShortLinkFake) FindAliasesBySession(ctx context.Context, sessionID string) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var aliases []string
+	for idx, currSessionID := range u.sessionIDs {
+		if currSessionID != sessionID || u.guestShortLinks[idx].TenantID != tenant.FromContext(ctx) {
+			continue
+		}
+		aliases = append(aliases, u.guestShortLinks[idx].Alias)
+	}
+	return aliases, nil
+}
+
+// ClaimSession transfers the ShortLinks created in the given guest session to
+// the user and returns the number of transferred ShortLinks.
+func (u *UserShortLinkFake) ClaimSession(ctx context.Context, sessionID string, user entity.User) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	var sessionIDs []string
+	var guestShortLinks []entity.ShortLink
+	count := 0
+	for idx, currSessionID := range u.sessionIDs {
+		shortLink := u.guestShortLinks[idx]
+		if currSessionID != sessionID || shortLink.TenantID != tenant.FromContext(ctx) {
+			sessionIDs = append(sessionIDs, currSessionID)
+			guestShortLinks = append(guestShortLinks, shortLink)
+			continue
+		}
+		u.users = append(u.users, user)
+		u.shortLinks = append(u.shortLinks, shortLink)
+		count++
+	}
+	u.sessionIDs = sessionIDs
+	u.guestShortLinks = guestShortLinks
+	return count, nil
+}
+
+// FindOrphanAliases fetches at most limit aliases after the given one, in
+// ascending order, which are related to users but no longer exist in
+// ShortLinkFake.
+func (u UserShortLinkFake) FindOrphanAliases(ctx context.Context, after string, limit int) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	aliases := []string{}
+	for _, shortLink := range u.shortLinks {
+		if shortLink.TenantID != tenant.FromContext(ctx) {
+			continue
+		}
+		alias := shortLink.Alias
+		if _, ok := u.existingShortLinks[newShortLinkKey(ctx, alias)]; ok || seen[alias] || alias <= after {
+			continue
+		}
+		seen[alias] = true
+		aliases = append(aliases, alias)
+	}
+
+	sort.Strings(aliases)
+	if len(aliases) > limit {
+		aliases = aliases[:limit]
+	}
+	return aliases, nil
+}
+
+// DeleteOrphanRelations removes the relations of the given aliases which no
+// longer exist in ShortLinkFake, and returns the number of removed relations.
+func (u *UserShortLinkFake) DeleteOrphanRelations(ctx context.Context, aliases []string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	isOrphan := make(map[string]bool)
+	for _, alias := range aliases {
+		if _, ok := u.existingShortLinks[newShortLinkKey(ctx, alias)]; !ok {
+			isOrphan[alias] = true
+		}
+	}
+
+	var users []entity.User
+	var shortLinks []entity.ShortLink
+	count := 0
+	for idx, user := range u.users {
+		alias := u.shortLinks[idx].Alias
+		if u.shortLinks[idx].TenantID == tenant.FromContext(ctx) && isOrphan[alias] {
+			delete(u.customAliases, newShortLinkKey(ctx, alias))
+			count++
+			continue
+		}
+		users = append(users, user)
+		shortLinks = append(shortLinks, u.shortLinks[idx])
+	}
+	u.users = users
+	u.shortLinks = shortLinks
+	return count, nil
+}
+
 // UpdateAliasCascade updates user-shortlink relationships to reflect changes to alias.
 // TODO(issue#958) use eventbus for propagating short link change to all related repos
-func (u *UserShortLinkFake) UpdateAliasCascade(oldAlias string, shortLinkInput entity.ShortLinkInput) error {
+func (u *UserShortLinkFake) UpdateAliasCascade(ctx context.Context, oldAlias string, shortLinkInput entity.ShortLinkInput) error {
 	for idx := range u.users {
-		if u.shortLinks[idx].Alias == oldAlias {
+		if isShortLinkOf(ctx, u.shortLinks[idx], oldAlias) {
+			oldKey := newShortLinkKey(ctx, oldAlias)
+			if _, ok := u.customAliases[oldKey]; ok {
+				delete(u.customAliases, oldKey)
+				u.customAliases[newShortLinkKey(ctx, shortLinkInput.GetCustomAlias(""))] = entity.Empty{}
+			}
 			u.shortLinks[idx] = entity.ShortLink{
+				TenantID:  tenant.FromContext(ctx),
 				Alias:     shortLinkInput.GetCustomAlias(""),
 				LongLink:  shortLinkInput.GetLongLink(""),
 				ExpireAt:  shortLinkInput.ExpireAt,
@@ -77,6 +367,35 @@ func (u *UserShortLinkFake) UpdateAliasCascade(oldAlias string, shortLinkInput e
 	return fmt.Errorf("no relationships with alias '%s' exist", oldAlias)
 }
 
+// DeleteAliasCascade removes user-shortlink relationships of the deleted alias.
+// TODO(issue#958) use eventbus for propagating short link change to all related repos
+func (u *UserShortLinkFake) DeleteAliasCascade(ctx context.Context, alias string) {
+	var users []entity.User
+	var shortLinks []entity.ShortLink
+	for idx, user := range u.users {
+		if isShortLinkOf(ctx, u.shortLinks[idx], alias) {
+			continue
+		}
+		users = append(users, user)
+		shortLinks = append(shortLinks, u.shortLinks[idx])
+	}
+	u.users = users
+	u.shortLinks = shortLinks
+	delete(u.customAliases, newShortLinkKey(ctx, alias))
+
+	var sessionIDs []string
+	var guestShortLinks []entity.ShortLink
+	for idx, sessionID := range u.sessionIDs {
+		if isShortLinkOf(ctx, u.guestShortLinks[idx], alias) {
+			continue
+		}
+		sessionIDs = append(sessionIDs, sessionID)
+		guestShortLinks = append(guestShortLinks, u.guestShortLinks[idx])
+	}
+	u.sessionIDs = sessionIDs
+	u.guestShortLinks = guestShortLinks
+}
+
 // NewUserShortLinkRepoFake creates UserShortLinkFake
 func NewUserShortLinkRepoFake(users []entity.User, shortLinks []entity.ShortLink) UserShortLinkFake {
 	return UserShortLinkFake{
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/short-d/short/backend/app/entity"
+)
+
+// Visit accesses the visits of short links from storage, such as database.
+type Visit interface {
+	CreateVisits(ctx context.Context, visits []entity.Visit) error
+	FindVisitsByAlias(ctx context.Context, alias string, from time.Time, to time.Time) ([]entity.Visit, error)
+	CountVisitsByTime(ctx context.Context, alias string, unit string, from time.Time, to time.Time) (map[time.Time]int, error)
+	CountVisitsByReferrer(ctx context.Context, alias string) (map[string]int, error)
+	CountVisitsByUserAgent(ctx context.Context, alias string) (map[entity.UserAgent]int, error)
+	CountVisitsByCampaign(ctx context.Context, alias string) (map[entity.Campaign]int, error)
+	CountVisitsSince(since time.Time) (int, error)
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/fw/tenant"
+)
+
+var _ Visit = (*VisitFake)(nil)
+
+// VisitFake represents in memory implementation of Visit repository.
+type VisitFake struct {
+	visits    []entity.Visit
+	tenantIDs []string
+}
+
+// CreateVisits records new visits of short links.
+func (v *VisitFake) CreateVisits(ctx context.Context, visits []entity.Visit) error {
+	for _, visit := range visits {
+		v.visits = append(v.visits, visit)
+		v.tenantIDs = append(v.tenantIDs, tenant.FromContext(ctx))
+	}
+	return nil
+}
+
+// FindVisitsByAlias fetches the visits of a short link which happened within
+// [from, to).
+func (v VisitFake) FindVisitsByAlias(ctx context.Context, alias string, from time.Time, to time.Time) ([]entity.Visit, error) {
+	var visits []entity.Visit
+	for idx, visit := range v.visits {
+		if !v.isVisitOf(ctx, idx, alias) {
+			continue
+		}
+		if visit.VisitedAt.Before(from) || !visit.VisitedAt.Before(to) {
+			continue
+		}
+		visits = append(visits, visit)
+	}
+	return visits, nil
+}
+
+// CountVisitsByTime counts the visits of a short link which happened within
+// [from, to) for each hour or day in UTC, keyed by the start of the hour or
+// the day.
+func (v VisitFake) CountVisitsByTime(ctx context.Context, alias string, unit string, from time.Time, to time.Time) (map[time.Time]int, error) {
+	visits, err := v.FindVisitsByAlias(ctx, alias, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[time.Time]int)
+	for _, visit := range visits {
+		visitedAt := visit.VisitedAt.UTC()
+		start := time.Date(visitedAt.Year(), visitedAt.Month(), visitedAt.Day(), visitedAt.Hour(), 0, 0, 0, time.UTC)
+		if unit == "day" {
+			start = time.Date(visitedAt.Year(), visitedAt.Month(), visitedAt.Day(), 0, 0, 0, 0, time.UTC)
+		}
+		counts[start]++
+	}
+	return counts, nil
+}
+
+// CountVisitsByReferrer counts the visits of a short link for each referring
+// host.
+func (v VisitFake) CountVisitsByReferrer(ctx context.Context, alias string) (map[string]int, error) {
+	counts := make(map[string]int)
+	for idx, visit := range v.visits {
+		if !v.isVisitOf(ctx, idx, alias) {
+			continue
+		}
+		counts[visit.Referrer]++
+	}
+	return counts, nil
+}
+
+// CountVisitsByUserAgent counts the visits of a short link for each
+// combination of browser, operating system and device class.
+func (v VisitFake) CountVisitsByUserAgent(ctx context.Context, alias string) (map[entity.UserAgent]int, error) {
+	counts := make(map[entity.UserAgent]int)
+	for idx, visit := range v.visits {
+		if !v.isVisitOf(ctx, idx, alias) {
+			continue
+		}
+		counts[visit.UserAgent]++
+	}
+	return counts, nil
+}
+
+// CountVisitsByCampaign counts the visits of a short link for each
+// combination of UTM source, medium and campaign.
+func (v VisitFake) CountVisitsByCampaign(ctx context.Context, alias string) (map[entity.Campaign]int, error) {
+	counts := make(map[entity.Campaign]int)
+	for idx, visit := range v.visits {
+		if !v.isVisitOf(ctx, idx, alias) {
+			continue
+		}
+		counts[visit.Campaign]++
+	}
+	return counts, nil
+}
+
+// CountVisitsSince counts the visits of all short links which happened at or
+// after since.
+func (v VisitFake) CountVisitsSince(since time.Time) (int, error) {
+	count := 0
+	for _, visit := range v.visits {
+		if visit.VisitedAt.Before(since) {
+			continue
+		}
+		count++
+	}
+	return count, nil
+}
+
+// isVisitOf checks whether the visit at idx is a visit of the short link with
+// the given alias in the tenant attached to the context.
+func (v VisitFake) isVisitOf(ctx context.Context, idx int, alias string) bool {
+	return v.tenantIDs[idx] == tenant.FromContext(ctx) && v.visits[idx].Alias == alias
+}
+
+// NewVisitFake creates in memory Visit repository with the visits of the
+// short links of the default tenant.
+func NewVisitFake(visits []entity.Visit) VisitFake {
+	tenantIDs := make([]string, len(visits))
+	for idx := range tenantIDs {
+		tenantIDs[idx] = tenant.Default
+	}
+	return VisitFake{visits: visits, tenantIDs: tenantIDs}
+}
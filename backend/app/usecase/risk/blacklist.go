@@ -2,5 +2,5 @@ package risk
 
 // BlackList checks whether an item is acceptable
 type BlackList interface {
-	HasURL(url string) (bool, error)
+	GetURLScore(url string) (Score, error)
 }
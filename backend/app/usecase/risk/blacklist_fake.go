@@ -4,18 +4,34 @@ var _ BlackList = (*BlackListFake)(nil)
 
 // BlackListFake is a in memory implementation of a BlackList used for testing.
 type BlackListFake struct {
-	blacklist map[string]bool
+	scores map[string]Score
 }
 
-// HasURL checks whether a given url exists in the blacklist.
-func (b BlackListFake) HasURL(url string) (bool, error) {
-	_, found := b.blacklist[url]
-	return found, nil
+// GetURLScore retrieves the score of a given url. Unknown urls are safe.
+func (b BlackListFake) GetURLScore(url string) (Score, error) {
+	score, found := b.scores[url]
+	if !found {
+		return ScoreSafe, nil
+	}
+	return score, nil
 }
 
-// NewBlackListFake initializes an in-memory blacklist.
+// NewBlackListFake initializes an in-memory blacklist where every listed url is
+// confirmed to be malicious.
 func NewBlackListFake(blacklist map[string]bool) BlackListFake {
+	scores := make(map[string]Score)
+	for url, isBlocked := range blacklist {
+		if isBlocked {
+			scores[url] = ScoreMalicious
+		}
+	}
+	return NewScoredBlackListFake(scores)
+}
+
+// NewScoredBlackListFake initializes an in-memory blacklist with the score of
+// each url.
+func NewScoredBlackListFake(scores map[string]Score) BlackListFake {
 	return BlackListFake{
-		blacklist,
+		scores: scores,
 	}
 }
@@ -0,0 +1,152 @@
+package risk
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/short-d/app/fw/logger"
+	"github.com/short-d/app/fw/timer"
+)
+
+// CircuitState represents whether CircuitBreaker lets the calls through.
+type CircuitState string
+
+// The constants enumerate all states of CircuitBreaker.
+const (
+	// CircuitClosed lets all the calls through.
+	CircuitClosed CircuitState = "closed"
+	// CircuitOpen skips all the calls until the cooldown ends.
+	CircuitOpen CircuitState = "open"
+	// CircuitHalfOpen lets a single trial call through to test whether the
+	// blacklist has recovered.
+	CircuitHalfOpen CircuitState = "half-open"
+)
+
+// ErrCircuitOpen represents the call to the blacklist is skipped because it
+// keeps failing.
+type ErrCircuitOpen string
+
+func (e ErrCircuitOpen) Error() string {
+	return string(e)
+}
+
+var _ BlackList = (*CircuitBreaker)(nil)
+
+// CircuitBreaker stops calling the blacklist for a cooldown period once it
+// fails failureThreshold times in a row, so that an outage of the external
+// service doesn't slow down every assessment. Detector allows the URLs while
+// the calls are skipped.
+type CircuitBreaker struct {
+	blacklist        BlackList
+	timer            timer.Timer
+	logger           logger.Logger
+	failureThreshold int
+	cooldown         time.Duration
+	circuit          *circuit
+}
+
+type circuit struct {
+	mutex    sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+}
+
+// GetURLScore retrieves the score of the given URL from the blacklist unless
+// the circuit is open.
+func (c CircuitBreaker) GetURLScore(url string) (Score, error) {
+	if !c.allowCall() {
+		return ScoreSafe, ErrCircuitOpen(fmt.Sprintf("risk circuit breaker is open (url=%s)", url))
+	}
+
+	score, err := c.blacklist.GetURLScore(url)
+	c.recordResult(err)
+	return score, err
+}
+
+// State returns the current state of the circuit.
+func (c CircuitBreaker) State() CircuitState {
+	c.circuit.mutex.Lock()
+	defer c.circuit.mutex.Unlock()
+	return c.circuit.state
+}
+
+func (c CircuitBreaker) allowCall() bool {
+	if c.failureThreshold <= 0 {
+		return true
+	}
+
+	c.circuit.mutex.Lock()
+	defer c.circuit.mutex.Unlock()
+
+	switch c.circuit.state {
+	case CircuitOpen:
+		if c.timer.Now().Sub(c.circuit.openedAt) < c.cooldown {
+			return false
+		}
+		c.transition(CircuitHalfOpen)
+		return true
+	case CircuitHalfOpen:
+		// Only the trial call is let through until it finishes.
+		return false
+	default:
+		return true
+	}
+}
+
+func (c CircuitBreaker) recordResult(err error) {
+	if c.failureThreshold <= 0 {
+		return
+	}
+
+	c.circuit.mutex.Lock()
+	defer c.circuit.mutex.Unlock()
+
+	if err == nil {
+		c.circuit.failures = 0
+		if c.circuit.state != CircuitClosed {
+			c.transition(CircuitClosed)
+		}
+		return
+	}
+
+	c.circuit.failures++
+	if c.circuit.state == CircuitHalfOpen || c.circuit.failures >= c.failureThreshold {
+		c.circuit.openedAt = c.timer.Now()
+		c.transition(CircuitOpen)
+	}
+}
+
+// transition must be called with the circuit locked.
+func (c CircuitBreaker) transition(state CircuitState) {
+	c.circuit.state = state
+	message := fmt.Sprintf(
+		"risk circuit breaker %s (failures=%d,cooldown=%v)",
+		state, c.circuit.failures, c.cooldown,
+	)
+	if state == CircuitOpen {
+		c.logger.Warn(message)
+		return
+	}
+	c.logger.Info(message)
+}
+
+// NewCircuitBreaker creates CircuitBreaker. The circuit never opens when
+// failureThreshold is not positive.
+func NewCircuitBreaker(
+	blacklist BlackList,
+	timer timer.Timer,
+	logger logger.Logger,
+	failureThreshold int,
+	cooldown time.Duration,
+) CircuitBreaker {
+	return CircuitBreaker{
+		blacklist:        blacklist,
+		timer:            timer,
+		logger:           logger,
+		failureThreshold: failureThreshold,
+		cooldown:         cooldown,
+		circuit:          &circuit{state: CircuitClosed},
+	}
+}
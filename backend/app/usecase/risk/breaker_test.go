@@ -0,0 +1,139 @@
+// +build !integration all
+
+package risk
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/logger"
+	"github.com/short-d/app/fw/timer"
+)
+
+// unstableBlackList fails every call while it is down.
+type unstableBlackList struct {
+	isDown *bool
+	calls  *int
+}
+
+func (u unstableBlackList) GetURLScore(url string) (Score, error) {
+	*u.calls++
+	if *u.isDown {
+		return ScoreSafe, errors.New("service unavailable")
+	}
+	return ScoreMalicious, nil
+}
+
+func TestCircuitBreaker_GetURLScore(t *testing.T) {
+	t.Parallel()
+
+	isDown := false
+	calls := 0
+	blacklist := unstableBlackList{isDown: &isDown, calls: &calls}
+	now := time.Date(2020, 6, 1, 8, 0, 0, 0, time.UTC)
+	tm := timer.NewStub(now)
+	entryRepo := logger.NewEntryRepoFake()
+	lg, err := logger.NewFake(logger.LogOff, &entryRepo)
+	assert.Equal(t, nil, err)
+
+	cooldown := time.Minute
+	breaker := NewCircuitBreaker(blacklist, &tm, lg, 2, cooldown)
+	detector := NewDetector(breaker, NewDenylistFake(nil), StrictThresholds)
+	url := "http://malware.example.com"
+
+	verdict, _ := detector.AssessURL(url)
+	assert.Equal(t, VerdictBlock, verdict)
+	assert.Equal(t, CircuitClosed, breaker.State())
+
+	isDown = true
+	_, err = breaker.GetURLScore(url)
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, CircuitClosed, breaker.State())
+
+	_, err = breaker.GetURLScore(url)
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, CircuitOpen, breaker.State())
+
+	isDown = false
+	tm.CurrentTime = now.Add(cooldown / 2)
+	_, err = breaker.GetURLScore(url)
+	assert.Equal(t, ErrCircuitOpen("risk circuit breaker is open (url=http://malware.example.com)"), err)
+	assert.Equal(t, 3, calls)
+
+	verdict, _ = detector.AssessURL(url)
+	assert.Equal(t, VerdictAllow, verdict)
+	assert.Equal(t, 3, calls)
+
+	isDown = true
+	tm.CurrentTime = now.Add(cooldown)
+	_, err = breaker.GetURLScore(url)
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, 4, calls)
+	assert.Equal(t, CircuitOpen, breaker.State())
+
+	isDown = false
+	tm.CurrentTime = now.Add(cooldown + cooldown/2)
+	_, err = breaker.GetURLScore(url)
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, 4, calls)
+
+	tm.CurrentTime = now.Add(2 * cooldown)
+	score, err := breaker.GetURLScore(url)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, ScoreMalicious, score)
+	assert.Equal(t, CircuitClosed, breaker.State())
+
+	verdict, _ = detector.AssessURL(url)
+	assert.Equal(t, VerdictBlock, verdict)
+	assert.Equal(t, 6, calls)
+}
+
+func TestCircuitBreaker_HalfOpen(t *testing.T) {
+	t.Parallel()
+
+	isDown := true
+	calls := 0
+	blacklist := unstableBlackList{isDown: &isDown, calls: &calls}
+	now := time.Date(2020, 6, 1, 8, 0, 0, 0, time.UTC)
+	tm := timer.NewStub(now)
+	entryRepo := logger.NewEntryRepoFake()
+	lg, err := logger.NewFake(logger.LogOff, &entryRepo)
+	assert.Equal(t, nil, err)
+
+	breaker := NewCircuitBreaker(blacklist, &tm, lg, 1, time.Minute)
+	_, err = breaker.GetURLScore("https://www.google.com")
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, CircuitOpen, breaker.State())
+
+	tm.CurrentTime = now.Add(time.Minute)
+	assert.Equal(t, true, breaker.allowCall())
+	assert.Equal(t, CircuitHalfOpen, breaker.State())
+
+	assert.Equal(t, false, breaker.allowCall())
+	assert.Equal(t, CircuitHalfOpen, breaker.State())
+
+	breaker.recordResult(nil)
+	assert.Equal(t, CircuitClosed, breaker.State())
+}
+
+func TestCircuitBreaker_Disabled(t *testing.T) {
+	t.Parallel()
+
+	isDown := true
+	calls := 0
+	blacklist := unstableBlackList{isDown: &isDown, calls: &calls}
+	tm := timer.NewStub(time.Now())
+	entryRepo := logger.NewEntryRepoFake()
+	lg, err := logger.NewFake(logger.LogOff, &entryRepo)
+	assert.Equal(t, nil, err)
+
+	breaker := NewCircuitBreaker(blacklist, tm, lg, 0, time.Minute)
+	for i := 0; i < 5; i++ {
+		_, err = breaker.GetURLScore("https://www.google.com")
+		assert.NotEqual(t, nil, err)
+	}
+	assert.Equal(t, 5, calls)
+	assert.Equal(t, CircuitClosed, breaker.State())
+}
@@ -0,0 +1,122 @@
+package risk
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/fw/filesystem"
+	"github.com/short-d/short/backend/app/usecase/authorizer"
+	"github.com/short-d/short/backend/app/usecase/validator"
+)
+
+const denylistCommentPrefix = "#"
+
+// ErrUnauthorizedAction represents unauthorized action error
+type ErrUnauthorizedAction struct {
+	user   entity.User
+	action string
+}
+
+var _ error = (*ErrUnauthorizedAction)(nil)
+
+func (e ErrUnauthorizedAction) Error() string {
+	return fmt.Sprintf("user %s is not allowed to %s", e.user.ID, e.action)
+}
+
+// Denylist rejects the URLs operators deem abusive.
+type Denylist interface {
+	IsURLDenied(url string) bool
+}
+
+var _ Denylist = (*DomainDenylist)(nil)
+
+// DomainDenylist rejects the URLs on the domains listed in a file, one domain
+// per line. Lines starting with "#" are ignored. The file can be reloaded
+// without restarting the service.
+type DomainDenylist struct {
+	fileSystem filesystem.FileSystem
+	path       string
+	authorizer authorizer.Authorizer
+	mutex      *sync.RWMutex
+	domains    *validator.DomainList
+}
+
+// IsURLDenied checks whether the host of the given URL is denied.
+func (d DomainDenylist) IsURLDenied(url string) bool {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+
+	return d.domains.HasURL(url)
+}
+
+// Reload reads the denied domains from the file again on behalf of the given
+// user and returns the number of domains denied. The previously denied domains
+// are kept when the file can't be read.
+func (d DomainDenylist) Reload(user entity.User) (int, error) {
+	canReload, err := d.authorizer.CanReloadDomainDenylist(user)
+	if err != nil {
+		return 0, err
+	}
+
+	if !canReload {
+		return 0, ErrUnauthorizedAction{
+			user:   user,
+			action: "reload domain denylist",
+		}
+	}
+	return d.load()
+}
+
+func (d DomainDenylist) load() (int, error) {
+	var domains []string
+	if d.path != "" {
+		buf, err := d.fileSystem.ReadFile(d.path)
+		if err != nil {
+			return 0, err
+		}
+		domains = parseDomains(buf)
+	}
+
+	domainList := validator.NewDomainList(domains)
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	*d.domains = domainList
+	return domainList.Len(), nil
+}
+
+func parseDomains(buf []byte) []string {
+	var domains []string
+	scanner := bufio.NewScanner(bytes.NewReader(buf))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, denylistCommentPrefix) {
+			continue
+		}
+		domains = append(domains, line)
+	}
+	return domains
+}
+
+// NewDomainDenylist creates DomainDenylist with the domains listed in the file
+// at the given path. Nothing is denied when the path is empty.
+func NewDomainDenylist(
+	fileSystem filesystem.FileSystem,
+	path string,
+	authorizer authorizer.Authorizer,
+) (DomainDenylist, error) {
+	denylist := DomainDenylist{
+		fileSystem: fileSystem,
+		path:       path,
+		authorizer: authorizer,
+		mutex:      &sync.RWMutex{},
+		domains:    &validator.DomainList{},
+	}
+	_, err := denylist.load()
+	return denylist, err
+}
@@ -0,0 +1,20 @@
+package risk
+
+import "github.com/short-d/short/backend/app/usecase/validator"
+
+var _ Denylist = (*DenylistFake)(nil)
+
+// DenylistFake is an in memory implementation of Denylist used for testing.
+type DenylistFake struct {
+	domains validator.DomainList
+}
+
+// IsURLDenied checks whether the host of the given URL is denied.
+func (d DenylistFake) IsURLDenied(url string) bool {
+	return d.domains.HasURL(url)
+}
+
+// NewDenylistFake creates DenylistFake which denies the given domains.
+func NewDenylistFake(domains []string) DenylistFake {
+	return DenylistFake{domains: validator.NewDomainList(domains)}
+}
@@ -0,0 +1,192 @@
+// +build !integration all
+
+package risk
+
+import (
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/fw/filesystem"
+	"github.com/short-d/short/backend/app/usecase/authorizer"
+	"github.com/short-d/short/backend/app/usecase/authorizer/rbac"
+	"github.com/short-d/short/backend/app/usecase/authorizer/rbac/role"
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+const denylistPath = "config/denylist.txt"
+
+func TestDomainDenylist_IsURLDenied(t *testing.T) {
+	t.Parallel()
+
+	denylistFile := []byte(`
+# abusive domains
+spam.com
+*.phishing.net
+`)
+
+	testCases := []struct {
+		name           string
+		url            string
+		expectedDenied bool
+	}{
+		{
+			name:           "denied host",
+			url:            "https://spam.com/win",
+			expectedDenied: true,
+		},
+		{
+			name:           "denied host ignores case and port",
+			url:            "http://SPAM.com:8080",
+			expectedDenied: true,
+		},
+		{
+			name:           "denied wildcard subdomain",
+			url:            "https://login.bank.phishing.net/signin",
+			expectedDenied: true,
+		},
+		{
+			name:           "subdomain of exact host permitted",
+			url:            "https://blog.spam.com",
+			expectedDenied: false,
+		},
+		{
+			name:           "permitted host",
+			url:            "https://www.google.com",
+			expectedDenied: false,
+		},
+	}
+
+	fileSystem := filesystem.NewFileSystemFake(map[string][]byte{
+		denylistPath: denylistFile,
+	})
+	denylist, err := NewDomainDenylist(fileSystem, denylistPath, authorizer.Authorizer{})
+	assert.Equal(t, nil, err)
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, testCase.expectedDenied, denylist.IsURLDenied(testCase.url))
+		})
+	}
+}
+
+func TestNewDomainDenylist(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name      string
+		files     map[string][]byte
+		path      string
+		expHasErr bool
+	}{
+		{
+			name:      "empty path denies nothing",
+			files:     map[string][]byte{},
+			path:      "",
+			expHasErr: false,
+		},
+		{
+			name:      "file not found",
+			files:     map[string][]byte{},
+			path:      denylistPath,
+			expHasErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			fileSystem := filesystem.NewFileSystemFake(testCase.files)
+			denylist, err := NewDomainDenylist(fileSystem, testCase.path, authorizer.Authorizer{})
+			if testCase.expHasErr {
+				assert.NotEqual(t, nil, err)
+				return
+			}
+			assert.Equal(t, nil, err)
+			assert.Equal(t, false, denylist.IsURLDenied("https://www.google.com"))
+		})
+	}
+}
+
+func TestDomainDenylist_Reload(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		roles         []role.Role
+		updatedFile   []byte
+		expHasErr     bool
+		expectedCount int
+		expectedURLs  map[string]bool
+	}{
+		{
+			name:          "admin reloads denylist",
+			roles:         []role.Role{role.Admin},
+			updatedFile:   []byte("*.phishing.net\nmalware.org\n"),
+			expectedCount: 2,
+			expectedURLs: map[string]bool{
+				"https://spam.com":             false,
+				"https://login.phishing.net":   true,
+				"https://malware.org/download": true,
+			},
+		},
+		{
+			name:          "security specialist reloads denylist",
+			roles:         []role.Role{role.SecuritySpecialist},
+			updatedFile:   []byte("malware.org"),
+			expectedCount: 1,
+			expectedURLs: map[string]bool{
+				"https://spam.com":    false,
+				"https://malware.org": true,
+			},
+		},
+		{
+			name:        "basic user not allowed to reload",
+			roles:       []role.Role{role.Basic},
+			updatedFile: []byte("malware.org"),
+			expHasErr:   true,
+			expectedURLs: map[string]bool{
+				"https://spam.com":    true,
+				"https://malware.org": false,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			user := entity.User{ID: "alpha"}
+			files := map[string][]byte{
+				denylistPath: []byte("spam.com"),
+			}
+			fileSystem := filesystem.NewFileSystemFake(files)
+			roleRepo := repository.NewUserRoleFake(map[string][]role.Role{
+				user.ID: testCase.roles,
+			})
+			au := authorizer.NewAuthorizer(rbac.NewRBAC(roleRepo))
+
+			denylist, err := NewDomainDenylist(fileSystem, denylistPath, au)
+			assert.Equal(t, nil, err)
+
+			files[denylistPath] = testCase.updatedFile
+			count, err := denylist.Reload(user)
+			if testCase.expHasErr {
+				assert.NotEqual(t, nil, err)
+			} else {
+				assert.Equal(t, nil, err)
+				assert.Equal(t, testCase.expectedCount, count)
+			}
+
+			for url, expectedDenied := range testCase.expectedURLs {
+				assert.Equal(t, expectedDenied, denylist.IsURLDenied(url))
+			}
+		})
+	}
+}
@@ -2,19 +2,46 @@ package risk
 
 // Detector determines whether the given items are malicious.
 type Detector struct {
-	blacklist BlackList
+	blacklist  BlackList
+	denylist   Denylist
+	thresholds Thresholds
 }
 
-// IsURLMalicious checks whether the given URL is malicious.
-func (r Detector) IsURLMalicious(url string) bool {
-	hasURL, err := r.blacklist.HasURL(url)
+// AssessURL decides whether the given URL should be allowed, allowed but
+// flagged for review, or blocked, together with the score it is judged by.
+func (r Detector) AssessURL(url string) (Verdict, Score) {
+	if r.denylist.IsURLDenied(url) {
+		return VerdictBlock, ScoreMalicious
+	}
+
+	score, err := r.blacklist.GetURLScore(url)
 	if err != nil {
-		return false
+		return VerdictAllow, ScoreSafe
+	}
+
+	if score <= ScoreSafe {
+		return VerdictAllow, score
+	}
+	if score >= r.thresholds.Block {
+		return VerdictBlock, score
 	}
-	return hasURL
+	if score >= r.thresholds.Warn {
+		return VerdictWarn, score
+	}
+	return VerdictAllow, score
+}
+
+// IsURLMalicious checks whether the given URL is malicious.
+func (r Detector) IsURLMalicious(url string) bool {
+	verdict, _ := r.AssessURL(url)
+	return verdict == VerdictBlock
 }
 
 // NewDetector creates a new Detector
-func NewDetector(blacklist BlackList) Detector {
-	return Detector{blacklist: blacklist}
+func NewDetector(blacklist BlackList, denylist Denylist, thresholds Thresholds) Detector {
+	return Detector{
+		blacklist:  blacklist,
+		denylist:   denylist,
+		thresholds: thresholds,
+	}
 }
@@ -0,0 +1,95 @@
+// +build !integration all
+
+package risk
+
+import (
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+)
+
+func TestDetector_AssessURL(t *testing.T) {
+	t.Parallel()
+
+	blacklist := NewScoredBlackListFake(map[string]Score{
+		"http://suspicious.example.com": ScoreSuspicious,
+		"http://malware.example.com":    ScoreMalicious,
+	})
+	lenientThresholds := Thresholds{
+		Block: ScoreMalicious,
+		Warn:  ScoreSuspicious,
+	}
+
+	testCases := []struct {
+		name            string
+		url             string
+		thresholds      Thresholds
+		deniedDomains   []string
+		expectedVerdict Verdict
+	}{
+		{
+			name:            "safe url under strict thresholds",
+			url:             "https://www.google.com",
+			thresholds:      StrictThresholds,
+			expectedVerdict: VerdictAllow,
+		},
+		{
+			name:            "suspicious url blocked under strict thresholds",
+			url:             "http://suspicious.example.com",
+			thresholds:      StrictThresholds,
+			expectedVerdict: VerdictBlock,
+		},
+		{
+			name:            "suspicious url flagged under lenient thresholds",
+			url:             "http://suspicious.example.com",
+			thresholds:      lenientThresholds,
+			expectedVerdict: VerdictWarn,
+		},
+		{
+			name:            "malicious url blocked under lenient thresholds",
+			url:             "http://malware.example.com",
+			thresholds:      lenientThresholds,
+			expectedVerdict: VerdictBlock,
+		},
+		{
+			name: "suspicious url allowed without warn tier",
+			url:  "http://suspicious.example.com",
+			thresholds: Thresholds{
+				Block: ScoreMalicious,
+				Warn:  ScoreMalicious,
+			},
+			expectedVerdict: VerdictAllow,
+		},
+		{
+			name: "safe url never blocked",
+			url:  "https://www.google.com",
+			thresholds: Thresholds{
+				Block: ScoreSafe,
+				Warn:  ScoreSafe,
+			},
+			expectedVerdict: VerdictAllow,
+		},
+		{
+			name: "denied domain blocked regardless of score",
+			url:  "https://www.google.com",
+			thresholds: Thresholds{
+				Block: ScoreMalicious,
+				Warn:  ScoreMalicious,
+			},
+			deniedDomains:   []string{"*.google.com"},
+			expectedVerdict: VerdictBlock,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			denylist := NewDenylistFake(testCase.deniedDomains)
+			detector := NewDetector(blacklist, denylist, testCase.thresholds)
+			verdict, _ := detector.AssessURL(testCase.url)
+			assert.Equal(t, testCase.expectedVerdict, verdict)
+		})
+	}
+}
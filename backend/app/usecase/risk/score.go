@@ -0,0 +1,36 @@
+package risk
+
+// Score measures how likely an item is malicious, ranging from ScoreSafe to
+// ScoreMalicious.
+type Score int
+
+// The constants enumerate the scores reported by the black lists.
+const (
+	ScoreSafe       Score = 0
+	ScoreSuspicious Score = 50
+	ScoreMalicious  Score = 100
+)
+
+// Verdict represents the action to take on an item given its score.
+type Verdict string
+
+// The constants enumerate all possible verdicts.
+const (
+	VerdictAllow Verdict = "allow"
+	VerdictWarn  Verdict = "warn"
+	VerdictBlock Verdict = "block"
+)
+
+// Thresholds decide the verdict of an item. Items scored at least Block are
+// rejected. Items scored at least Warn but below Block are allowed while
+// flagged for review.
+type Thresholds struct {
+	Block Score
+	Warn  Score
+}
+
+// StrictThresholds blocks every item which is not confirmed to be safe.
+var StrictThresholds = Thresholds{
+	Block: ScoreSuspicious,
+	Warn:  ScoreSuspicious,
+}
@@ -0,0 +1,55 @@
+package route
+
+import "strings"
+
+// The constants enumerate the first path segments served by Short itself,
+// including the ones reserved for the upcoming routes.
+const (
+	API       = "api"
+	GraphQL   = "graphql"
+	Redirect  = "r"
+	OAuth     = "oauth"
+	Features  = "features"
+	Analytics = "analytics"
+	Search    = "search"
+	Metrics   = "metrics"
+	Healthz   = "healthz"
+	Version   = "version"
+)
+
+// reservedPrefixes is the single source of truth of the reserved routes.
+// Both the router and the custom alias validator consult it, so that aliases
+// are never shadowed by the routes.
+var reservedPrefixes = []string{
+	API,
+	GraphQL,
+	Redirect,
+	OAuth,
+	Features,
+	Analytics,
+	Search,
+	Metrics,
+	Healthz,
+	Version,
+}
+
+// ReservedPrefixes returns the first path segments which can't be used as
+// aliases.
+func ReservedPrefixes() []string {
+	prefixes := make([]string, len(reservedPrefixes))
+	copy(prefixes, reservedPrefixes)
+	return prefixes
+}
+
+// IsReserved checks whether the first path segment of the alias is reserved
+// by a route, ignoring case.
+func IsReserved(alias string) bool {
+	segment := strings.TrimPrefix(alias, "/")
+	segment = strings.SplitN(segment, "/", 2)[0]
+	for _, prefix := range reservedPrefixes {
+		if strings.EqualFold(segment, prefix) {
+			return true
+		}
+	}
+	return false
+}
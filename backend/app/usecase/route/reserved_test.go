@@ -0,0 +1,59 @@
+// +build !integration all
+
+package route
+
+import (
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+)
+
+func TestIsReserved(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name               string
+		alias              string
+		expectedIsReserved bool
+	}{
+		{
+			name:               "reserved prefix",
+			alias:              "api",
+			expectedIsReserved: true,
+		},
+		{
+			name:               "reserved prefix in upper case",
+			alias:              "GraphQL",
+			expectedIsReserved: true,
+		},
+		{
+			name:               "path under reserved prefix",
+			alias:              "r/google",
+			expectedIsReserved: true,
+		},
+		{
+			name:               "alias starting with reserved prefix",
+			alias:              "apis",
+			expectedIsReserved: false,
+		},
+		{
+			name:               "regular alias",
+			alias:              "220uFicCJj",
+			expectedIsReserved: false,
+		},
+		{
+			name:               "empty alias",
+			alias:              "",
+			expectedIsReserved: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, testCase.expectedIsReserved, IsReserved(testCase.alias))
+		})
+	}
+}
@@ -1,12 +1,14 @@
 package search
 
 import (
+	"context"
 	"errors"
 	"strings"
 	"time"
 
 	"github.com/short-d/app/fw/logger"
 	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/fw/tenant"
 	"github.com/short-d/short/backend/app/usecase/matcher"
 	"github.com/short-d/short/backend/app/usecase/repository"
 	"github.com/short-d/short/backend/app/usecase/search/order"
@@ -27,7 +29,7 @@ type Result struct {
 }
 
 // Search finds resources based on specified criteria.
-func (s Search) Search(query Query, filter Filter) (Result, error) {
+func (s Search) Search(ctx context.Context, query Query, filter Filter) (Result, error) {
 	resultCh := make(chan Result)
 	defer close(resultCh)
 
@@ -36,7 +38,7 @@ func (s Search) Search(query Query, filter Filter) (Result, error) {
 	for i := range filter.resources {
 		i := i
 		go func() {
-			result, err := s.searchResource(filter.resources[i], orders[i], query, filter)
+			result, err := s.searchResource(ctx, filter.resources[i], orders[i], query, filter)
 			if err != nil {
 				// TODO(issue#865): Handle errors of Search API
 				s.logger.Error(err)
@@ -61,10 +63,10 @@ func (s Search) Search(query Query, filter Filter) (Result, error) {
 	return mergeResults(results), nil
 }
 
-func (s Search) searchResource(resource Resource, orderBy order.Order, query Query, filter Filter) (Result, error) {
+func (s Search) searchResource(ctx context.Context, resource Resource, orderBy order.Order, query Query, filter Filter) (Result, error) {
 	switch resource {
 	case ShortLink:
-		return s.searchShortLink(query, orderBy, filter)
+		return s.searchShortLink(ctx, query, orderBy, filter)
 	case User:
 		return s.searchUser(query, orderBy, filter)
 	default:
@@ -73,13 +75,13 @@ func (s Search) searchResource(resource Resource, orderBy order.Order, query Que
 }
 
 // TODO(issue#866): Simplify searchShortLink function
-func (s Search) searchShortLink(query Query, orderBy order.Order, filter Filter) (Result, error) {
+func (s Search) searchShortLink(ctx context.Context, query Query, orderBy order.Order, filter Filter) (Result, error) {
 	if query.User == nil {
 		s.logger.Error(errors.New("user not provided"))
 		return Result{}, nil
 	}
 
-	shortLinks, err := s.getShortLinkByUser(*query.User)
+	shortLinks, err := s.getShortLinkByUser(ctx, *query.User)
 	if err != nil {
 		return Result{}, err
 	}
@@ -125,13 +127,14 @@ func (s Search) searchUser(query Query, orderBy order.Order, filter Filter) (Res
 	return Result{}, nil
 }
 
-func (s Search) getShortLinkByUser(user entity.User) ([]entity.ShortLink, error) {
-	aliases, err := s.userShortLinkRepo.FindAliasesByUser(user)
+func (s Search) getShortLinkByUser(ctx context.Context, user entity.User) ([]entity.ShortLink, error) {
+	ctx = tenant.NewContext(ctx, user.TenantID)
+	aliases, err := s.userShortLinkRepo.FindAliasesByUser(ctx, user)
 	if err != nil {
 		return []entity.ShortLink{}, err
 	}
 
-	return s.shortLinkRepo.GetShortLinksByAliases(aliases)
+	return s.shortLinkRepo.GetShortLinksByAliases(ctx, aliases)
 }
 
 func getKeywords(query string) []string {
@@ -1,6 +1,7 @@
 package search
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -539,7 +540,7 @@ func TestSearch(t *testing.T) {
 			filter, err := NewFilter(testCase.maxResults, testCase.resources, testCase.orders)
 			assert.Equal(t, nil, err)
 
-			result, err := search.Search(testCase.Query, filter)
+			result, err := search.Search(context.Background(), testCase.Query, filter)
 
 			assert.Equal(t, nil, err)
 			assert.Equal(t, testCase.expectedResult, result)
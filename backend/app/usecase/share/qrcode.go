@@ -0,0 +1,6 @@
+package share
+
+// QRCodeGenerator encodes content into QR code images.
+type QRCodeGenerator interface {
+	GeneratePNG(content string, size int) ([]byte, error)
+}
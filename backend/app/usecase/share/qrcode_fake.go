@@ -0,0 +1,20 @@
+package share
+
+import "fmt"
+
+var _ QRCodeGenerator = (*QRCodeGeneratorFake)(nil)
+
+// QRCodeGeneratorFake represents an in memory QR code generator used for
+// testing.
+type QRCodeGeneratorFake struct{}
+
+// GeneratePNG encodes the content and the size into plain bytes so that tests
+// can verify what is being encoded.
+func (q QRCodeGeneratorFake) GeneratePNG(content string, size int) ([]byte, error) {
+	return []byte(fmt.Sprintf("%s,%d", content, size)), nil
+}
+
+// NewQRCodeGeneratorFake creates QRCodeGeneratorFake.
+func NewQRCodeGeneratorFake() QRCodeGeneratorFake {
+	return QRCodeGeneratorFake{}
+}
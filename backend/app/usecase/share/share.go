@@ -0,0 +1,95 @@
+package share
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/short-d/short/backend/app/entity/metatag"
+	"github.com/short-d/short/backend/app/usecase/shortlink"
+)
+
+const (
+	minQRCodeSize = 64
+	maxQRCodeSize = 1024
+)
+
+// Share composes the information needed to share a short link.
+type Share struct {
+	baseURL         url.URL
+	qrCodeGenerator QRCodeGenerator
+	metaTag         shortlink.MetaTag
+	signer          Signer
+}
+
+// ShortLinkURL builds the full URL which redirects users to the long link.
+// The alias is signed when signing is enabled.
+func (s Share) ShortLinkURL(alias string) string {
+	return composeShortLinkURL(s.baseURL, s.signer.Sign(alias))
+}
+
+// CustomDomainShortLinkURL builds the full URL of a short link served at the
+// root of a custom domain instead of the base URL. The scheme of the base URL
+// is kept.
+func (s Share) CustomDomainShortLinkURL(domain string, alias string) string {
+	baseURL := url.URL{
+		Scheme: s.baseURL.Scheme,
+		Host:   strings.TrimRight(domain, "/"),
+	}
+	return composeShortLinkURL(baseURL, s.signer.Sign(alias))
+}
+
+// QRCodeDataURL encodes the full URL of the short link into a QR code PNG
+// image in data URL format. The size is clamped to a safe range.
+func (s Share) QRCodeDataURL(alias string, size int) (string, error) {
+	buf, err := s.qrCodeGenerator.GeneratePNG(s.ShortLinkURL(alias), clampQRCodeSize(size))
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.StdEncoding.EncodeToString(buf)
+	return fmt.Sprintf("data:image/png;base64,%s", encoded), nil
+}
+
+// OpenGraphTags fetches the open graph tags used when the short link is shared
+// on social media. The short link is looked up within the tenant attached to
+// ctx.
+func (s Share) OpenGraphTags(ctx context.Context, alias string) (metatag.OpenGraph, error) {
+	return s.metaTag.GetOpenGraphTags(ctx, alias)
+}
+
+// composeShortLinkURL appends the alias to the path of the base URL, ignoring
+// the trailing slashes of the base URL so that the path never contains "//".
+func composeShortLinkURL(baseURL url.URL, alias string) string {
+	shortLinkURL := baseURL
+	shortLinkURL.Path = strings.TrimRight(baseURL.Path, "/") + "/" + alias
+	shortLinkURL.RawPath = ""
+	return shortLinkURL.String()
+}
+
+func clampQRCodeSize(size int) int {
+	if size < minQRCodeSize {
+		return minQRCodeSize
+	}
+	if size > maxQRCodeSize {
+		return maxQRCodeSize
+	}
+	return size
+}
+
+// NewShare creates Share which builds the full URLs of short links by
+// appending the aliases signed by signer to baseURL.
+func NewShare(
+	baseURL url.URL,
+	qrCodeGenerator QRCodeGenerator,
+	metaTag shortlink.MetaTag,
+	signer Signer,
+) Share {
+	return Share{
+		baseURL:         baseURL,
+		qrCodeGenerator: qrCodeGenerator,
+		metaTag:         metaTag,
+		signer:          signer,
+	}
+}
@@ -0,0 +1,245 @@
+// +build !integration all
+
+package share
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/entity/metatag"
+	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/shortlink"
+)
+
+func TestShare_ShortLinkURL(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		baseURL     string
+		alias       string
+		expectedURL string
+	}{
+		{
+			name:        "base URL with path",
+			baseURL:     "https://short-d.com/r",
+			alias:       "220uFicCJj",
+			expectedURL: "https://short-d.com/r/220uFicCJj",
+		},
+		{
+			name:        "base URL with trailing slash",
+			baseURL:     "https://short-d.com/r/",
+			alias:       "220uFicCJj",
+			expectedURL: "https://short-d.com/r/220uFicCJj",
+		},
+		{
+			name:        "base URL with multiple trailing slashes",
+			baseURL:     "https://short-d.com/r//",
+			alias:       "220uFicCJj",
+			expectedURL: "https://short-d.com/r/220uFicCJj",
+		},
+		{
+			name:        "base URL without path",
+			baseURL:     "https://s.short-d.com",
+			alias:       "220uFicCJj",
+			expectedURL: "https://s.short-d.com/220uFicCJj",
+		},
+		{
+			name:        "base URL at root",
+			baseURL:     "https://s.short-d.com/",
+			alias:       "220uFicCJj",
+			expectedURL: "https://s.short-d.com/220uFicCJj",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			baseURL, err := url.Parse(testCase.baseURL)
+			assert.Equal(t, nil, err)
+
+			shortLinkRepo := repository.NewShortLinkFake(nil, map[string]entity.ShortLink{})
+			metaTag := shortlink.NewMetaTagPersist(&shortLinkRepo)
+			qrCodeGenerator := NewQRCodeGeneratorFake()
+			share := NewShare(*baseURL, qrCodeGenerator, metaTag, Signer{})
+
+			assert.Equal(t, testCase.expectedURL, share.ShortLinkURL(testCase.alias))
+		})
+	}
+}
+
+func TestShare_CustomDomainShortLinkURL(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		baseURL     string
+		domain      string
+		alias       string
+		expectedURL string
+	}{
+		{
+			name:        "custom domain replaces base URL",
+			baseURL:     "https://short-d.com/r",
+			domain:      "go.example.com",
+			alias:       "220uFicCJj",
+			expectedURL: "https://go.example.com/220uFicCJj",
+		},
+		{
+			name:        "custom domain with trailing slash",
+			baseURL:     "https://short-d.com/r/",
+			domain:      "go.example.com/",
+			alias:       "220uFicCJj",
+			expectedURL: "https://go.example.com/220uFicCJj",
+		},
+		{
+			name:        "scheme of base URL kept",
+			baseURL:     "http://localhost:8080/r",
+			domain:      "localhost:3000",
+			alias:       "220uFicCJj",
+			expectedURL: "http://localhost:3000/220uFicCJj",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			baseURL, err := url.Parse(testCase.baseURL)
+			assert.Equal(t, nil, err)
+
+			shortLinkRepo := repository.NewShortLinkFake(nil, map[string]entity.ShortLink{})
+			metaTag := shortlink.NewMetaTagPersist(&shortLinkRepo)
+			qrCodeGenerator := NewQRCodeGeneratorFake()
+			share := NewShare(*baseURL, qrCodeGenerator, metaTag, Signer{})
+
+			shortLinkURL := share.CustomDomainShortLinkURL(testCase.domain, testCase.alias)
+			assert.Equal(t, testCase.expectedURL, shortLinkURL)
+		})
+	}
+}
+
+func TestShare_QRCodeDataURL(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name            string
+		alias           string
+		size            int
+		expectedDataURL string
+	}{
+		{
+			name:  "size within range",
+			alias: "220uFicCJj",
+			size:  256,
+			// base64 of "https://short-d.com/r/220uFicCJj,256"
+			expectedDataURL: "data:image/png;base64,aHR0cHM6Ly9zaG9ydC1kLmNvbS9yLzIyMHVGaWNDSmosMjU2",
+		},
+		{
+			name:  "size too small",
+			alias: "220uFicCJj",
+			size:  1,
+			// base64 of "https://short-d.com/r/220uFicCJj,64"
+			expectedDataURL: "data:image/png;base64,aHR0cHM6Ly9zaG9ydC1kLmNvbS9yLzIyMHVGaWNDSmosNjQ=",
+		},
+		{
+			name:  "size too large",
+			alias: "220uFicCJj",
+			size:  4096,
+			// base64 of "https://short-d.com/r/220uFicCJj,1024"
+			expectedDataURL: "data:image/png;base64,aHR0cHM6Ly9zaG9ydC1kLmNvbS9yLzIyMHVGaWNDSmosMTAyNA==",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			baseURL, err := url.Parse("https://short-d.com/r")
+			assert.Equal(t, nil, err)
+
+			shortLinkRepo := repository.NewShortLinkFake(nil, map[string]entity.ShortLink{})
+			metaTag := shortlink.NewMetaTagPersist(&shortLinkRepo)
+			qrCodeGenerator := NewQRCodeGeneratorFake()
+			share := NewShare(*baseURL, qrCodeGenerator, metaTag, Signer{})
+
+			dataURL, err := share.QRCodeDataURL(testCase.alias, testCase.size)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedDataURL, dataURL)
+		})
+	}
+}
+
+func TestShare_OpenGraphTags(t *testing.T) {
+	t.Parallel()
+
+	title := "Google"
+	description := "Search the world's information"
+	imageURL := "https://www.google.com/logo.png"
+
+	testCases := []struct {
+		name                  string
+		shortLinks            map[string]entity.ShortLink
+		alias                 string
+		expHasErr             bool
+		expectedOpenGraphTags metatag.OpenGraph
+	}{
+		{
+			name: "short link found",
+			shortLinks: map[string]entity.ShortLink{
+				"220uFicCJj": {
+					Alias:    "220uFicCJj",
+					LongLink: "https://www.google.com",
+					OpenGraphTags: metatag.OpenGraph{
+						Title:       &title,
+						Description: &description,
+						ImageURL:    &imageURL,
+					},
+				},
+			},
+			alias:     "220uFicCJj",
+			expHasErr: false,
+			expectedOpenGraphTags: metatag.OpenGraph{
+				Title:       &title,
+				Description: &description,
+				ImageURL:    &imageURL,
+			},
+		},
+		{
+			name:       "short link not found",
+			shortLinks: map[string]entity.ShortLink{},
+			alias:      "220uFicCJj",
+			expHasErr:  true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			baseURL, err := url.Parse("https://short-d.com/r")
+			assert.Equal(t, nil, err)
+
+			shortLinkRepo := repository.NewShortLinkFake(nil, testCase.shortLinks)
+			metaTag := shortlink.NewMetaTagPersist(&shortLinkRepo)
+			qrCodeGenerator := NewQRCodeGeneratorFake()
+			share := NewShare(*baseURL, qrCodeGenerator, metaTag, Signer{})
+
+			openGraphTags, err := share.OpenGraphTags(context.Background(), testCase.alias)
+			if testCase.expHasErr {
+				assert.NotEqual(t, nil, err)
+				return
+			}
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedOpenGraphTags, openGraphTags)
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package share
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+)
+
+const (
+	signatureSeparator = "~"
+	signatureBytes     = 12
+)
+
+// ErrInvalidSignature represents the signature of the alias is missing or
+// doesn't match the alias.
+type ErrInvalidSignature string
+
+func (e ErrInvalidSignature) Error() string {
+	return string(e)
+}
+
+// Signer appends HMAC signatures to aliases so that modified aliases, such as
+// the ones guessed from nearby aliases, are rejected. Aliases are left
+// unsigned when the secret is empty.
+type Signer struct {
+	secret []byte
+}
+
+// IsEnabled checks whether aliases are signed.
+func (s Signer) IsEnabled() bool {
+	return len(s.secret) > 0
+}
+
+// Sign appends the signature of the alias to the alias.
+func (s Signer) Sign(alias string) string {
+	if !s.IsEnabled() {
+		return alias
+	}
+	return alias + signatureSeparator + s.signature(alias)
+}
+
+// Verify extracts the alias from the signed alias, failing with
+// ErrInvalidSignature when the signature is missing or invalid. The signed
+// alias is returned as is when signing is disabled.
+func (s Signer) Verify(signedAlias string) (string, error) {
+	if !s.IsEnabled() {
+		return signedAlias, nil
+	}
+
+	separatorIdx := strings.LastIndex(signedAlias, signatureSeparator)
+	if separatorIdx < 0 {
+		return "", ErrInvalidSignature("alias is not signed")
+	}
+
+	alias := signedAlias[:separatorIdx]
+	signature := signedAlias[separatorIdx+len(signatureSeparator):]
+	if !hmac.Equal([]byte(signature), []byte(s.signature(alias))) {
+		return "", ErrInvalidSignature("signature doesn't match alias")
+	}
+	return alias, nil
+}
+
+// signature truncates the HMAC-SHA256 of the alias to keep short links short.
+func (s Signer) signature(alias string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(alias))
+	sum := mac.Sum(nil)
+	return base64.RawURLEncoding.EncodeToString(sum[:signatureBytes])
+}
+
+// NewSigner creates Signer which signs aliases with secret. Signing is
+// disabled when secret is empty.
+func NewSigner(secret string) Signer {
+	return Signer{secret: []byte(secret)}
+}
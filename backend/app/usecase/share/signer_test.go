@@ -0,0 +1,96 @@
+// +build !integration all
+
+package share
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/short/backend/app/usecase/shortlink"
+)
+
+func TestSigner_Verify(t *testing.T) {
+	t.Parallel()
+
+	signer := NewSigner("secret")
+	signedAlias := signer.Sign("220uFicCJj")
+	signature := strings.TrimPrefix(signedAlias, "220uFicCJj")
+
+	testCases := []struct {
+		name          string
+		signer        Signer
+		signedAlias   string
+		hasErr        bool
+		expectedAlias string
+	}{
+		{
+			name:          "signing disabled",
+			signer:        NewSigner(""),
+			signedAlias:   "220uFicCJj",
+			expectedAlias: "220uFicCJj",
+		},
+		{
+			name:          "valid signature",
+			signer:        signer,
+			signedAlias:   signedAlias,
+			expectedAlias: "220uFicCJj",
+		},
+		{
+			name:          "alias containing separator",
+			signer:        signer,
+			signedAlias:   signer.Sign("a~b"),
+			expectedAlias: "a~b",
+		},
+		{
+			name:        "tampered alias",
+			signer:      signer,
+			signedAlias: "220uFicCJk" + signature,
+			hasErr:      true,
+		},
+		{
+			name:        "tampered signature",
+			signer:      signer,
+			signedAlias: signedAlias + "A",
+			hasErr:      true,
+		},
+		{
+			name:        "empty signature",
+			signer:      signer,
+			signedAlias: "220uFicCJj~",
+			hasErr:      true,
+		},
+		{
+			name:        "unsigned alias",
+			signer:      signer,
+			signedAlias: "220uFicCJj",
+			hasErr:      true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			alias, err := testCase.signer.Verify(testCase.signedAlias)
+			if testCase.hasErr {
+				assert.NotEqual(t, nil, err)
+				return
+			}
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedAlias, alias)
+		})
+	}
+}
+
+func TestShare_ShortLinkURLSigned(t *testing.T) {
+	t.Parallel()
+
+	signer := NewSigner("secret")
+	share := NewShare(url.URL{Scheme: "https", Host: "s.short-d.com"}, NewQRCodeGeneratorFake(), shortlink.NewMetaTagPersist(nil), signer)
+
+	shortLinkURL := share.ShortLinkURL("220uFicCJj")
+	assert.Equal(t, "https://s.short-d.com/"+signer.Sign("220uFicCJj"), shortLinkURL)
+}
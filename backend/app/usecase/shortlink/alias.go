@@ -0,0 +1,22 @@
+package shortlink
+
+import "golang.org/x/text/unicode/norm"
+
+// normalizeAlias converts the alias into Unicode Normalization Form C, so that
+// the aliases looking the same are always stored and retrieved with the same
+// key. ASCII aliases are returned unchanged.
+func normalizeAlias(alias string) string {
+	return norm.NFC.String(alias)
+}
+
+// aliasLookups returns the keys to look up the alias with, starting with the
+// normalized alias. The aliases stored before normalization was introduced
+// are kept as they were typed, so they are still found by the raw alias or its
+// decomposed form.
+func aliasLookups(alias string) []string {
+	return uniqueAliases([]string{
+		normalizeAlias(alias),
+		alias,
+		norm.NFD.String(alias),
+	})
+}
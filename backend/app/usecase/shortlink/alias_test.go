@@ -0,0 +1,123 @@
+// +build !integration all
+
+package shortlink
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/metrics"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/fw/ptr"
+	"github.com/short-d/short/backend/app/usecase/authorizer"
+	"github.com/short-d/short/backend/app/usecase/email"
+	"github.com/short-d/short/backend/app/usecase/keygen"
+	"github.com/short-d/short/backend/app/usecase/maintenance"
+	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/risk"
+	"github.com/short-d/short/backend/app/usecase/validator"
+	"github.com/short-d/short/backend/app/usecase/webhook"
+)
+
+func TestNormalizeAlias(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		alias         string
+		expectedAlias string
+	}{
+		{
+			name:          "ASCII alias",
+			alias:         "220uFicCJj",
+			expectedAlias: "220uFicCJj",
+		},
+		{
+			name:          "composed alias",
+			alias:         "caf\u00e9",
+			expectedAlias: "caf\u00e9",
+		},
+		{
+			name:          "decomposed alias",
+			alias:         "cafe\u0301",
+			expectedAlias: "caf\u00e9",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, testCase.expectedAlias, normalizeAlias(testCase.alias))
+		})
+	}
+}
+
+func TestShortLinkCreatorPersist_CreateShortLinkNormalizedAlias(t *testing.T) {
+	t.Parallel()
+
+	shortLinkRepo := repository.NewShortLinkFake(nil, shortLinks{})
+	userShortLinkRepo := repository.NewUserShortLinkRepoFake(nil, nil)
+	keyFetcher := keygen.NewKeyFetcherFake(nil)
+	keyGen, err := keygen.NewRemote(2, &keyFetcher)
+	assert.Equal(t, nil, err)
+	flaggedLinkRepo := repository.NewFlaggedShortLinkFake(nil)
+	aliasValidator, err := validator.NewUnicodeCustomAlias([]string{"L", "M", "So"})
+	assert.Equal(t, nil, err)
+
+	preferencesRepo := repository.NewUserPreferencesFake(nil)
+	creator := NewCreatorPersist(
+		&shortLinkRepo,
+		&userShortLinkRepo,
+		keyGen,
+		validator.NewLongLink(nil, nil, validator.FragmentPreserve, validator.PlainHTTPAllow),
+		aliasValidator,
+		timer.NewStub(time.Now()),
+		risk.NewDetector(
+			risk.NewBlackListFake(nil),
+			risk.NewDenylistFake(nil),
+			risk.StrictThresholds,
+		),
+		&flaggedLinkRepo,
+		DefaultChecks,
+		LongLinkUniquenessNone,
+		AliasQuota{},
+		authorizer.Authorizer{},
+		email.NewSenderFake(nil),
+		webhook.NewDispatcherFake(),
+		&preferencesRepo,
+		maintenance.Mode{},
+		metrics.NewFake(),
+		0,
+		ExpirationPolicy{},
+		&repository.AliasSkeletonFake{},
+		RelationRetry{},
+		&repository.AliasReservationFake{},
+		ReservationTTL{},
+		ChainedLink{},
+	)
+	retriever := NewRetrieverPersist(&shortLinkRepo, &userShortLinkRepo, 0)
+
+	user := entity.User{ID: "alpha", Email: "alpha@example.com"}
+	shortLinkInput := entity.ShortLinkInput{
+		LongLink:    ptr.String("https://www.google.com"),
+		CustomAlias: ptr.String("cafe\u0301"),
+	}
+	shortLink, err := creator.CreateShortLink(context.Background(), shortLinkInput, user, false)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "caf\u00e9", shortLink.Alias)
+
+	shortLinkInput.CustomAlias = ptr.String("caf\u00e9")
+	_, err = creator.CreateShortLink(context.Background(), shortLinkInput, user, false)
+	assert.Equal(t, ErrAliasExist("short link alias already exist"), err)
+
+	for _, alias := range []string{"cafe\u0301", "caf\u00e9"} {
+		retrieved, err := retriever.GetShortLink(context.Background(), alias, nil)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, "caf\u00e9", retrieved.Alias)
+	}
+}
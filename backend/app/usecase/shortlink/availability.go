@@ -0,0 +1,116 @@
+package shortlink
+
+import (
+	"context"
+
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/usecase/ratelimit"
+	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/validator"
+)
+
+var _ AliasChecker = (*AliasCheckerPersist)(nil)
+
+// AliasAvailabilityStatus represents whether a custom alias can be used to
+// create a short link.
+type AliasAvailabilityStatus string
+
+// The constants enumerate all supported alias availability statuses.
+const (
+	AliasAvailable AliasAvailabilityStatus = "available"
+	AliasTaken     AliasAvailabilityStatus = "taken"
+	AliasInvalid   AliasAvailabilityStatus = "invalid"
+)
+
+// AliasAvailability represents whether a custom alias is available. Violation
+// explains why the alias is invalid.
+type AliasAvailability struct {
+	Status    AliasAvailabilityStatus
+	Violation validator.Violation
+}
+
+// ErrTooManyAliasChecks represents the client checks the availability of
+// aliases too often.
+type ErrTooManyAliasChecks string
+
+func (e ErrTooManyAliasChecks) Error() string {
+	return string(e)
+}
+
+// AliasChecker checks whether a custom alias is available.
+type AliasChecker interface {
+	CheckAlias(ctx context.Context, alias string, clientIP string) (AliasAvailability, error)
+}
+
+// AliasCheckerPersist checks the availability of custom aliases against
+// persistent storage.
+type AliasCheckerPersist struct {
+	shortLinkRepo   repository.ShortLink
+	aliasValidator  validator.CustomAlias
+	rateLimiter     ratelimit.Limiter
+	reservationRepo repository.AliasReservation
+	timer           timer.Timer
+}
+
+// CheckAlias validates the format of the alias and checks whether it is taken
+// by an existing short link or held by a reservation. Clients looking up
+// aliases too often are rejected so that the aliases of other users' short
+// links can't be enumerated. Invalid aliases are not counted since they are
+// never looked up.
+func (a AliasCheckerPersist) CheckAlias(
+	ctx context.Context,
+	alias string,
+	clientIP string,
+) (AliasAvailability, error) {
+	alias = normalizeAlias(alias)
+	if alias == "" {
+		return AliasAvailability{Status: AliasInvalid, Violation: validator.EmptyAlias}, nil
+	}
+
+	isValid, violation := a.aliasValidator.IsValid(alias)
+	if !isValid {
+		return AliasAvailability{Status: AliasInvalid, Violation: violation}, nil
+	}
+
+	allowed, err := a.rateLimiter.Allow(clientIP)
+	if err != nil {
+		return AliasAvailability{}, err
+	}
+	if !allowed {
+		return AliasAvailability{}, ErrTooManyAliasChecks("too many aliases checked, please try again later")
+	}
+
+	isExist, err := a.shortLinkRepo.IsAliasExist(ctx, alias)
+	if err != nil {
+		return AliasAvailability{}, err
+	}
+	if isExist {
+		return AliasAvailability{Status: AliasTaken, Violation: validator.Valid}, nil
+	}
+
+	_, isReserved, err := findActiveReservation(ctx, a.reservationRepo, alias, a.timer.Now().UTC())
+	if err != nil {
+		return AliasAvailability{}, err
+	}
+	if isReserved {
+		return AliasAvailability{Status: AliasTaken, Violation: validator.Valid}, nil
+	}
+	return AliasAvailability{Status: AliasAvailable, Violation: validator.Valid}, nil
+}
+
+// NewAliasCheckerPersist creates AliasCheckerPersist
+func NewAliasCheckerPersist(
+	shortLinkRepo repository.ShortLink,
+	aliasValidator validator.CustomAlias,
+	rateLimiter ratelimit.Limiter,
+	reservationRepo repository.AliasReservation,
+	timer timer.Timer,
+) AliasCheckerPersist {
+	return AliasCheckerPersist{
+		shortLinkRepo:   shortLinkRepo,
+		aliasValidator:  aliasValidator,
+		rateLimiter:     rateLimiter,
+		reservationRepo: reservationRepo,
+		timer:           timer,
+	}
+}
@@ -0,0 +1,138 @@
+// +build !integration all
+
+package shortlink
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/ratelimit"
+	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/validator"
+)
+
+func TestAliasCheckerPersist_CheckAlias(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name                 string
+		alias                string
+		expectedAvailability AliasAvailability
+	}{
+		{
+			name:  "alias available",
+			alias: "my-blog",
+			expectedAvailability: AliasAvailability{
+				Status:    AliasAvailable,
+				Violation: validator.Valid,
+			},
+		},
+		{
+			name:  "alias taken",
+			alias: "google",
+			expectedAvailability: AliasAvailability{
+				Status:    AliasTaken,
+				Violation: validator.Valid,
+			},
+		},
+		{
+			name:  "alias held by reservation",
+			alias: "my-shop",
+			expectedAvailability: AliasAvailability{
+				Status:    AliasTaken,
+				Violation: validator.Valid,
+			},
+		},
+		{
+			name:  "reservation expired",
+			alias: "my-store",
+			expectedAvailability: AliasAvailability{
+				Status:    AliasAvailable,
+				Violation: validator.Valid,
+			},
+		},
+		{
+			name:  "reserved word",
+			alias: "graphql",
+			expectedAvailability: AliasAvailability{
+				Status:    AliasInvalid,
+				Violation: validator.ReservedAlias,
+			},
+		},
+		{
+			name:  "malformed alias",
+			alias: "my#blog",
+			expectedAvailability: AliasAvailability{
+				Status:    AliasInvalid,
+				Violation: validator.HasFragmentCharacter,
+			},
+		},
+		{
+			name:  "empty alias",
+			alias: "",
+			expectedAvailability: AliasAvailability{
+				Status:    AliasInvalid,
+				Violation: validator.EmptyAlias,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			shortLinkRepo := repository.NewShortLinkFake(nil, shortLinks{
+				"google": {Alias: "google", LongLink: "https://www.google.com"},
+			})
+			now := time.Date(2020, 5, 1, 10, 30, 0, 0, time.UTC)
+			reservationRepo := &repository.AliasReservationFake{}
+			for alias, expireAt := range map[string]time.Time{
+				"my-shop":  now.Add(time.Minute),
+				"my-store": now,
+			} {
+				reservation := entity.AliasReservation{Alias: alias, UserID: "alpha", Token: "token", ExpireAt: expireAt}
+				_, err := reservationRepo.CreateReservation(context.Background(), reservation, now.Add(-time.Hour))
+				assert.Equal(t, nil, err)
+			}
+			tm := timer.NewStub(now)
+			rateLimiter := ratelimit.NewMemory(tm, 10, time.Minute)
+			aliasChecker := NewAliasCheckerPersist(&shortLinkRepo, validator.NewCustomAlias(), rateLimiter, reservationRepo, tm)
+
+			availability, err := aliasChecker.CheckAlias(context.Background(), testCase.alias, "127.0.0.1")
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedAvailability, availability)
+		})
+	}
+}
+
+func TestAliasCheckerPersist_CheckAliasRateLimited(t *testing.T) {
+	t.Parallel()
+
+	shortLinkRepo := repository.NewShortLinkFake(nil, shortLinks{})
+	tm := timer.NewStub(time.Date(2020, 5, 1, 10, 30, 0, 0, time.UTC))
+	rateLimiter := ratelimit.NewMemory(tm, 2, time.Minute)
+	aliasChecker := NewAliasCheckerPersist(&shortLinkRepo, validator.NewCustomAlias(), rateLimiter, &repository.AliasReservationFake{}, tm)
+
+	for _, alias := range []string{"alpha", "beta"} {
+		_, err := aliasChecker.CheckAlias(context.Background(), alias, "127.0.0.1")
+		assert.Equal(t, nil, err)
+	}
+
+	// Invalid aliases are never looked up, so they are not limited.
+	availability, err := aliasChecker.CheckAlias(context.Background(), "graphql", "127.0.0.1")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, AliasInvalid, availability.Status)
+
+	_, err = aliasChecker.CheckAlias(context.Background(), "gamma", "127.0.0.1")
+	var tooMany ErrTooManyAliasChecks
+	assert.Equal(t, true, errors.As(err, &tooMany))
+
+	_, err = aliasChecker.CheckAlias(context.Background(), "gamma", "192.168.0.1")
+	assert.Equal(t, nil, err)
+}
@@ -0,0 +1,69 @@
+package shortlink
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/short-d/short/backend/app/entity"
+)
+
+// maxShortLinksPerBatch limits the number of short links created in a single
+// request to protect the data store.
+const maxShortLinksPerBatch = 100
+
+// ErrTooManyShortLinks represents the number of short links to create exceeds
+// the limit of a single batch.
+type ErrTooManyShortLinks string
+
+func (e ErrTooManyShortLinks) Error() string {
+	return string(e)
+}
+
+// BulkFailure represents an input which fails to create a short link. Index
+// is the position of the input in the batch.
+type BulkFailure struct {
+	Index int
+	Err   error
+}
+
+// BulkReport summarizes the short links created in a batch. Created lists the
+// short links in the order of their inputs.
+type BulkReport struct {
+	Created  []entity.ShortLink
+	Failures []BulkFailure
+}
+
+// CreateShortLinks creates each short link in the batch on its own, the same
+// way as CreateShortLink, so that the failure of one input doesn't stop the
+// others. The whole batch fails when it is too large, the service is
+// read-only or the request is canceled.
+func (c CreatorPersist) CreateShortLinks(
+	ctx context.Context,
+	shortLinkInputs []entity.ShortLinkInput,
+	user entity.User,
+) (BulkReport, error) {
+	report := BulkReport{
+		Created:  []entity.ShortLink{},
+		Failures: []BulkFailure{},
+	}
+	if len(shortLinkInputs) > maxShortLinksPerBatch {
+		msg := fmt.Sprintf("at most %d short links can be created at once", maxShortLinksPerBatch)
+		return report, ErrTooManyShortLinks(msg)
+	}
+	if c.maintenanceMode.IsReadOnly() {
+		return report, ErrServiceReadOnly("create short links")
+	}
+
+	for idx, shortLinkInput := range shortLinkInputs {
+		shortLink, err := c.CreateShortLink(ctx, shortLinkInput, user, false)
+		if ctx.Err() != nil {
+			return report, ctx.Err()
+		}
+		if err != nil {
+			report.Failures = append(report.Failures, BulkFailure{Index: idx, Err: err})
+			continue
+		}
+		report.Created = append(report.Created, shortLink)
+	}
+	return report, nil
+}
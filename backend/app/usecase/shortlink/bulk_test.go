@@ -0,0 +1,163 @@
+// +build !integration all
+
+package shortlink
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/metrics"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/fw/ptr"
+	"github.com/short-d/short/backend/app/usecase/authorizer"
+	"github.com/short-d/short/backend/app/usecase/email"
+	"github.com/short-d/short/backend/app/usecase/keygen"
+	"github.com/short-d/short/backend/app/usecase/maintenance"
+	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/risk"
+	"github.com/short-d/short/backend/app/usecase/validator"
+	"github.com/short-d/short/backend/app/usecase/webhook"
+)
+
+func TestShortLinkCreatorPersist_CreateShortLinks(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 5, 1, 10, 30, 0, 0, time.UTC)
+	user := entity.User{ID: "alpha", Email: "alpha@example.com"}
+
+	var tooManyInputs []entity.ShortLinkInput
+	for idx := 0; idx <= 100; idx++ {
+		tooManyInputs = append(tooManyInputs, entity.ShortLinkInput{
+			LongLink:    ptr.String("https://www.google.com"),
+			CustomAlias: ptr.String(fmt.Sprintf("alias%03d", idx)),
+		})
+	}
+
+	testCases := []struct {
+		name             string
+		shortLinks       shortLinks
+		isReadOnly       bool
+		shortLinkInputs  []entity.ShortLinkInput
+		expectedErr      error
+		expectedAliases  []string
+		expectedFailures []BulkFailure
+	}{
+		{
+			name: "all short links created",
+			shortLinkInputs: []entity.ShortLinkInput{
+				{LongLink: ptr.String("https://www.google.com"), CustomAlias: ptr.String("google")},
+				{LongLink: ptr.String("https://github.com"), CustomAlias: ptr.String("github")},
+			},
+			expectedAliases:  []string{"google", "github"},
+			expectedFailures: []BulkFailure{},
+		},
+		{
+			name: "some short links failed",
+			shortLinks: shortLinks{
+				"taken": entity.ShortLink{Alias: "taken", LongLink: "https://www.taken.com"},
+			},
+			shortLinkInputs: []entity.ShortLinkInput{
+				{LongLink: ptr.String("https://www.google.com"), CustomAlias: ptr.String("google")},
+				{LongLink: ptr.String("https://www.taken.com"), CustomAlias: ptr.String("taken")},
+				{LongLink: ptr.String("not a url"), CustomAlias: ptr.String("broken")},
+				{LongLink: ptr.String("https://github.com"), CustomAlias: ptr.String("github")},
+				{LongLink: ptr.String("https://www.google.com"), CustomAlias: ptr.String("api")},
+				{LongLink: ptr.String("https://www.google.com"), CustomAlias: ptr.String("google")},
+			},
+			expectedAliases: []string{"google", "github"},
+			expectedFailures: []BulkFailure{
+				{Index: 1, Err: ErrAliasExist("short link alias already exist")},
+				{Index: 2, Err: ErrInvalidLongLink{"not a url", validator.LongLinkNotURL}},
+				{Index: 4, Err: ErrInvalidCustomAlias{"api", validator.ReservedAlias}},
+				{Index: 5, Err: ErrAliasExist("short link alias already exist")},
+			},
+		},
+		{
+			name:             "empty batch",
+			shortLinkInputs:  []entity.ShortLinkInput{},
+			expectedAliases:  []string{},
+			expectedFailures: []BulkFailure{},
+		},
+		{
+			name:            "too many short links",
+			shortLinkInputs: tooManyInputs,
+			expectedErr:     ErrTooManyShortLinks("at most 100 short links can be created at once"),
+		},
+		{
+			name:       "service read-only",
+			isReadOnly: true,
+			shortLinkInputs: []entity.ShortLinkInput{
+				{LongLink: ptr.String("https://www.google.com"), CustomAlias: ptr.String("google")},
+			},
+			expectedErr: ErrServiceReadOnly("create short links"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			userShortLinkRepo := repository.NewUserShortLinkRepoFake(nil, nil)
+			shortLinkRepo := repository.NewShortLinkFake(&userShortLinkRepo, testCase.shortLinks)
+			keyFetcher := keygen.NewKeyFetcherFake([]keygen.Key{})
+			keyGen, err := keygen.NewRemote(2, &keyFetcher)
+			assert.Equal(t, nil, err)
+			flaggedLinkRepo := repository.NewFlaggedShortLinkFake(nil)
+			preferencesRepo := repository.NewUserPreferencesFake(nil)
+
+			creator := NewCreatorPersist(
+				&shortLinkRepo,
+				&userShortLinkRepo,
+				keyGen,
+				validator.NewLongLink(nil, nil, validator.FragmentPreserve, validator.PlainHTTPAllow),
+				validator.NewCustomAlias(),
+				timer.NewStub(now),
+				risk.NewDetector(
+					risk.NewBlackListFake(nil),
+					risk.NewDenylistFake(nil),
+					risk.StrictThresholds,
+				),
+				&flaggedLinkRepo,
+				DefaultChecks,
+				LongLinkUniquenessNone,
+				AliasQuota{},
+				authorizer.Authorizer{},
+				email.NewSenderFake(nil),
+				webhook.NewDispatcherFake(),
+				&preferencesRepo,
+				maintenance.NewMode(testCase.isReadOnly),
+				metrics.NewFake(),
+				0,
+				ExpirationPolicy{},
+				&repository.AliasSkeletonFake{},
+				RelationRetry{},
+				&repository.AliasReservationFake{},
+				ReservationTTL{},
+				ChainedLink{},
+			)
+
+			report, err := creator.CreateShortLinks(context.Background(), testCase.shortLinkInputs, user)
+			if testCase.expectedErr != nil {
+				assert.Equal(t, testCase.expectedErr, err)
+				return
+			}
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedFailures, report.Failures)
+
+			aliases := []string{}
+			for _, shortLink := range report.Created {
+				aliases = append(aliases, shortLink.Alias)
+
+				hasMapping, err := userShortLinkRepo.HasMapping(context.Background(), user, shortLink.Alias)
+				assert.Equal(t, nil, err)
+				assert.Equal(t, true, hasMapping)
+			}
+			assert.Equal(t, testCase.expectedAliases, aliases)
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package shortlink
+
+import "github.com/short-d/short/backend/app/usecase/validator"
+
+// canonicalizeLongLink rewrites the long link given by the user into the
+// canonical form saved with the short link. The canonical long link is the one
+// validated, checked for risks and redirected to, while the original long link
+// is only kept for display. The steps run in the following order:
+//
+//  1. The fragment is preserved or stripped according to the fragment mode of
+//     the long link validator.
+//  2. Plain HTTP is upgraded to HTTPS when the long link validator upgrades
+//     plain HTTP.
+//  3. When long links are globally unique, the scheme and the host are
+//     lowercased, the default port is dropped and the empty path becomes "/",
+//     so that the equivalent long links share the same short link.
+func canonicalizeLongLink(
+	longLinkValidator validator.LongLink,
+	uniqueness LongLinkUniqueness,
+	longLink string,
+) string {
+	longLink = longLinkValidator.Normalize(longLink)
+	if uniqueness == LongLinkUniquenessGlobal {
+		longLink = normalizeLongLink(longLink)
+	}
+	return longLink
+}
@@ -0,0 +1,179 @@
+package shortlink
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/validator"
+)
+
+// ChainedLinkMode decides what happens to long links which are short links
+// themselves, either served by Short or by other URL shorteners.
+type ChainedLinkMode string
+
+// The constants enumerate all supported chained link modes.
+const (
+	// ChainedLinkReject rejects long links which are short links, so that
+	// visitors are never redirected more than once.
+	ChainedLinkReject ChainedLinkMode = "reject"
+	// ChainedLinkResolve replaces long links which are short links with the
+	// destinations they redirect to. The short links served by Short are
+	// looked up directly, while the ones of other URL shorteners are followed
+	// through a bounded number of redirects.
+	ChainedLinkResolve ChainedLinkMode = "resolve"
+)
+
+// ErrUnknownChainedLinkMode represents the chained link mode which is not
+// supported.
+type ErrUnknownChainedLinkMode string
+
+func (e ErrUnknownChainedLinkMode) Error() string {
+	return "unknown chained link mode: " + string(e)
+}
+
+// ParseChainedLinkMode converts the name of the mode into ChainedLinkMode.
+// Empty name rejects chained links.
+func ParseChainedLinkMode(name string) (ChainedLinkMode, error) {
+	mode := ChainedLinkMode(name)
+	switch mode {
+	case "":
+		return ChainedLinkReject, nil
+	case ChainedLinkReject, ChainedLinkResolve:
+		return mode, nil
+	default:
+		return "", ErrUnknownChainedLinkMode(name)
+	}
+}
+
+// RedirectFollower finds where URLs redirect to.
+type RedirectFollower interface {
+	// NextHop retrieves the URL the given URL redirects to, or empty string
+	// when it doesn't redirect.
+	NextHop(rawURL string) (string, error)
+}
+
+// ChainedLink configures how long links which are short links are handled.
+// The short links of other URL shorteners are recognized by their domains. The
+// zero value rejects the short links served by Short and accepts all the
+// others.
+type ChainedLink struct {
+	mode             ChainedLinkMode
+	shortenerDomains validator.DomainList
+	maxRedirects     int
+	follower         RedirectFollower
+}
+
+func (c CreatorPersist) resolveChainedLink(ctx context.Context, longLink string) (string, error) {
+	return c.chainedLink.resolve(ctx, c.longLinkValidator, c.shortLinkRepo, c.timer.Now().UTC(), longLink)
+}
+
+func (u UpdaterPersist) resolveChainedLink(ctx context.Context, longLink string) (string, error) {
+	return u.chainedLink.resolve(ctx, u.longLinkValidator, u.shortLinkRepo, u.timer.Now().UTC(), longLink)
+}
+
+// resolve rewrites the long link into the destination it eventually redirects
+// to when it is a short link and chained links are resolved. The short links
+// served by Short are left as is in reject mode, so that the long link
+// validator reports them as self referencing.
+func (c ChainedLink) resolve(
+	ctx context.Context,
+	longLinkValidator validator.LongLink,
+	shortLinkRepo repository.ShortLink,
+	now time.Time,
+	longLink string,
+) (string, error) {
+	if longLinkValidator.IsSelfReferencing(longLink) {
+		if c.mode != ChainedLinkResolve {
+			return longLink, nil
+		}
+		return resolveOwnShortLink(ctx, shortLinkRepo, now, longLink)
+	}
+
+	if !c.shortenerDomains.HasURL(longLink) {
+		return longLink, nil
+	}
+	if c.mode != ChainedLinkResolve {
+		return "", ErrInvalidLongLink{longLink, validator.ShortenedLongLink}
+	}
+	return c.followRedirects(longLink)
+}
+
+// followRedirects follows the redirects of the long link until it leaves the
+// domains of the URL shorteners. The long link is rejected when it doesn't
+// redirect anywhere else within maxRedirects hops.
+func (c ChainedLink) followRedirects(longLink string) (string, error) {
+	current := longLink
+	for hops := 0; hops < c.maxRedirects; hops++ {
+		next, err := c.follower.NextHop(current)
+		if err != nil {
+			return "", err
+		}
+		if next == "" {
+			break
+		}
+		if !c.shortenerDomains.HasURL(next) {
+			return next, nil
+		}
+		current = next
+	}
+	return "", ErrInvalidLongLink{longLink, validator.ShortenedLongLink}
+}
+
+// resolveOwnShortLink looks up the long link of the short link served by
+// Short, such as https://short-d.com/r/google. The short link is left as is
+// when it can't be resolved on its own, including when it is missing, no
+// longer active, limits the total visits, or carries extra path or query
+// which would be passed through on redirect.
+func resolveOwnShortLink(
+	ctx context.Context,
+	shortLinkRepo repository.ShortLink,
+	now time.Time,
+	longLink string,
+) (string, error) {
+	u, err := url.Parse(longLink)
+	if err != nil || u.RawQuery != "" {
+		return longLink, nil
+	}
+
+	alias := strings.TrimPrefix(strings.TrimPrefix(u.Path, "/"), "r/")
+	if alias == "" || strings.Contains(alias, "/") {
+		return longLink, nil
+	}
+
+	for _, lookup := range aliasLookups(alias) {
+		shortLink, err := shortLinkRepo.GetShortLinkByAlias(ctx, lookup)
+		var notFound repository.ErrEntryNotFound
+		if errors.As(err, &notFound) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+
+		if shortLink.MaxVisits > 0 || getExpiryState(shortLink, now, 0) != ExpiryStateActive {
+			return longLink, nil
+		}
+		return shortLink.LongLink, nil
+	}
+	return longLink, nil
+}
+
+// NewChainedLink creates ChainedLink. The redirects of the short links on
+// shortenerDomains are followed by follower for at most maxRedirects hops.
+func NewChainedLink(
+	mode ChainedLinkMode,
+	shortenerDomains []string,
+	maxRedirects int,
+	follower RedirectFollower,
+) ChainedLink {
+	return ChainedLink{
+		mode:             mode,
+		shortenerDomains: validator.NewDomainList(shortenerDomains),
+		maxRedirects:     maxRedirects,
+		follower:         follower,
+	}
+}
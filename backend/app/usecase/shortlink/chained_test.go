@@ -0,0 +1,358 @@
+// +build !integration all
+
+package shortlink
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/metrics"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/fw/ptr"
+	"github.com/short-d/short/backend/app/usecase/authorizer"
+	"github.com/short-d/short/backend/app/usecase/email"
+	"github.com/short-d/short/backend/app/usecase/keygen"
+	"github.com/short-d/short/backend/app/usecase/maintenance"
+	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/risk"
+	"github.com/short-d/short/backend/app/usecase/validator"
+	"github.com/short-d/short/backend/app/usecase/webhook"
+)
+
+func TestShortLinkCreatorPersist_CreateShortLinkChained(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	expiredAt := now.Add(-time.Hour)
+	existingShortLinks := shortLinks{
+		"google": entity.ShortLink{
+			Alias:    "google",
+			LongLink: "https://www.google.com/search",
+		},
+		"secret": entity.ShortLink{
+			Alias:     "secret",
+			LongLink:  "https://www.google.com/secret",
+			MaxVisits: 1,
+		},
+		"old": entity.ShortLink{
+			Alias:    "old",
+			LongLink: "https://www.google.com/old",
+			ExpireAt: &expiredAt,
+		},
+	}
+	redirects := map[string]string{
+		"https://bit.ly/abc":      "https://www.example.com/page",
+		"https://bit.ly/chain":    "https://tinyurl.com/xyz",
+		"https://tinyurl.com/xyz": "https://www.example.com/chain",
+		"https://bit.ly/loop":     "https://bit.ly/loop",
+		"https://bit.ly/back":     "https://short-d.com/r/google",
+	}
+	shortenerDomains := []string{"bit.ly", "tinyurl.com"}
+
+	testCases := []struct {
+		name                     string
+		mode                     ChainedLinkMode
+		longLink                 string
+		expectedErr              error
+		expectedLongLink         string
+		expectedOriginalLongLink string
+	}{
+		{
+			name:                     "not short link",
+			mode:                     ChainedLinkResolve,
+			longLink:                 "https://www.example.com",
+			expectedLongLink:         "https://www.example.com",
+			expectedOriginalLongLink: "https://www.example.com",
+		},
+		{
+			name:        "reject own short link",
+			mode:        ChainedLinkReject,
+			longLink:    "https://short-d.com/r/google",
+			expectedErr: ErrSelfReferentialLink("https://short-d.com/r/google"),
+		},
+		{
+			name:                     "resolve own short link",
+			mode:                     ChainedLinkResolve,
+			longLink:                 "https://short-d.com/r/google",
+			expectedLongLink:         "https://www.google.com/search",
+			expectedOriginalLongLink: "https://short-d.com/r/google",
+		},
+		{
+			name:                     "resolve own short link on short link domain",
+			mode:                     ChainedLinkResolve,
+			longLink:                 "https://S.short-d.com/google",
+			expectedLongLink:         "https://www.google.com/search",
+			expectedOriginalLongLink: "https://S.short-d.com/google",
+		},
+		{
+			name:        "missing own short link",
+			mode:        ChainedLinkResolve,
+			longLink:    "https://short-d.com/r/yahoo",
+			expectedErr: ErrSelfReferentialLink("https://short-d.com/r/yahoo"),
+		},
+		{
+			name:        "own short link limiting visits",
+			mode:        ChainedLinkResolve,
+			longLink:    "https://short-d.com/r/secret",
+			expectedErr: ErrSelfReferentialLink("https://short-d.com/r/secret"),
+		},
+		{
+			name:        "expired own short link",
+			mode:        ChainedLinkResolve,
+			longLink:    "https://short-d.com/r/old",
+			expectedErr: ErrSelfReferentialLink("https://short-d.com/r/old"),
+		},
+		{
+			name:        "own short link with passthrough path",
+			mode:        ChainedLinkResolve,
+			longLink:    "https://short-d.com/r/google/images",
+			expectedErr: ErrSelfReferentialLink("https://short-d.com/r/google/images"),
+		},
+		{
+			name:        "reject competitor short link",
+			mode:        ChainedLinkReject,
+			longLink:    "https://bit.ly/abc",
+			expectedErr: ErrInvalidLongLink{"https://bit.ly/abc", validator.ShortenedLongLink},
+		},
+		{
+			name:                     "resolve competitor short link",
+			mode:                     ChainedLinkResolve,
+			longLink:                 "https://bit.ly/abc",
+			expectedLongLink:         "https://www.example.com/page",
+			expectedOriginalLongLink: "https://bit.ly/abc",
+		},
+		{
+			name:                     "resolve competitor short links chained",
+			mode:                     ChainedLinkResolve,
+			longLink:                 "https://bit.ly/chain",
+			expectedLongLink:         "https://www.example.com/chain",
+			expectedOriginalLongLink: "https://bit.ly/chain",
+		},
+		{
+			name:        "competitor short link redirecting too many times",
+			mode:        ChainedLinkResolve,
+			longLink:    "https://bit.ly/loop",
+			expectedErr: ErrInvalidLongLink{"https://bit.ly/loop", validator.ShortenedLongLink},
+		},
+		{
+			name:        "competitor short link not redirecting",
+			mode:        ChainedLinkResolve,
+			longLink:    "https://bit.ly/gone",
+			expectedErr: ErrInvalidLongLink{"https://bit.ly/gone", validator.ShortenedLongLink},
+		},
+		{
+			name:        "competitor short link redirecting back",
+			mode:        ChainedLinkResolve,
+			longLink:    "https://bit.ly/back",
+			expectedErr: ErrSelfReferentialLink("https://short-d.com/r/google"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			shortLinkRepo := repository.NewShortLinkFake(nil, existingShortLinks)
+			userShortLinkRepo := repository.NewUserShortLinkRepoFake(nil, nil)
+			keyFetcher := keygen.NewKeyFetcherFake([]keygen.Key{})
+			keyGen, err := keygen.NewRemote(2, &keyFetcher)
+			assert.Equal(t, nil, err)
+			flaggedLinkRepo := repository.NewFlaggedShortLinkFake(nil)
+			preferencesRepo := repository.NewUserPreferencesFake(nil)
+
+			creator := NewCreatorPersist(
+				&shortLinkRepo,
+				&userShortLinkRepo,
+				keyGen,
+				validator.NewLongLink(
+					nil,
+					[]string{"short-d.com", "s.short-d.com"},
+					validator.FragmentPreserve,
+					validator.PlainHTTPAllow,
+				),
+				validator.NewCustomAlias(),
+				timer.NewStub(now),
+				risk.NewDetector(risk.NewBlackListFake(nil), risk.NewDenylistFake(nil), risk.StrictThresholds),
+				&flaggedLinkRepo,
+				DefaultChecks,
+				LongLinkUniquenessNone,
+				AliasQuota{},
+				authorizer.Authorizer{},
+				email.NewSenderFake(nil),
+				webhook.NewDispatcherFake(),
+				&preferencesRepo,
+				maintenance.Mode{},
+				metrics.NewFake(),
+				0,
+				ExpirationPolicy{},
+				&repository.AliasSkeletonFake{},
+				RelationRetry{},
+				&repository.AliasReservationFake{},
+				ReservationTTL{},
+				NewChainedLink(testCase.mode, shortenerDomains, 3, NewRedirectFollowerFake(redirects)),
+			)
+
+			shortLinkInput := entity.ShortLinkInput{
+				LongLink:    ptr.String(testCase.longLink),
+				CustomAlias: ptr.String("chained"),
+			}
+			user := entity.User{ID: "alpha", Email: "alpha@example.com"}
+			shortLink, err := creator.CreateShortLink(context.Background(), shortLinkInput, user, false)
+			assert.Equal(t, testCase.expectedErr, err)
+			if err != nil {
+				isExist, err := shortLinkRepo.IsAliasExist(context.Background(), "chained")
+				assert.Equal(t, nil, err)
+				assert.Equal(t, false, isExist)
+				return
+			}
+			assert.Equal(t, testCase.expectedLongLink, shortLink.LongLink)
+			assert.Equal(t, testCase.expectedOriginalLongLink, shortLink.OriginalLongLink)
+
+			savedShortLink, err := shortLinkRepo.GetShortLinkByAlias(context.Background(), "chained")
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedLongLink, savedShortLink.LongLink)
+		})
+	}
+}
+
+func TestShortLinkUpdaterPersist_UpdateShortLinkChained(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	redirects := map[string]string{
+		"https://bit.ly/abc": "https://www.example.com/page",
+	}
+	shortenerDomains := []string{"bit.ly"}
+
+	testCases := []struct {
+		name                     string
+		mode                     ChainedLinkMode
+		longLink                 string
+		expectedErr              error
+		expectedLongLink         string
+		expectedOriginalLongLink string
+	}{
+		{
+			name:        "reject own short link",
+			mode:        ChainedLinkReject,
+			longLink:    "https://short-d.com/r/google",
+			expectedErr: ErrSelfReferentialLink("https://short-d.com/r/google"),
+		},
+		{
+			name:                     "resolve own short link",
+			mode:                     ChainedLinkResolve,
+			longLink:                 "https://short-d.com/r/google",
+			expectedLongLink:         "https://www.google.com/search",
+			expectedOriginalLongLink: "https://short-d.com/r/google",
+		},
+		{
+			name:        "reject competitor short link",
+			mode:        ChainedLinkReject,
+			longLink:    "https://bit.ly/abc",
+			expectedErr: ErrInvalidLongLink{"https://bit.ly/abc", validator.ShortenedLongLink},
+		},
+		{
+			name:                     "resolve competitor short link",
+			mode:                     ChainedLinkResolve,
+			longLink:                 "https://bit.ly/abc",
+			expectedLongLink:         "https://www.example.com/page",
+			expectedOriginalLongLink: "https://bit.ly/abc",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			user := entity.User{ID: "alpha", Email: "alpha@example.com"}
+			chained := entity.ShortLink{Alias: "chained", LongLink: "https://www.example.com"}
+			userShortLinkRepo := repository.NewUserShortLinkRepoFake(
+				[]entity.User{user},
+				[]entity.ShortLink{chained},
+			)
+			shortLinkRepo := repository.NewShortLinkFake(&userShortLinkRepo, shortLinks{
+				"google": entity.ShortLink{
+					Alias:    "google",
+					LongLink: "https://www.google.com/search",
+				},
+				"chained": chained,
+			})
+
+			updater := NewUpdaterPersist(
+				&shortLinkRepo,
+				&userShortLinkRepo,
+				validator.NewLongLink(nil, []string{"short-d.com"}, validator.FragmentPreserve, validator.PlainHTTPAllow),
+				validator.NewCustomAlias(),
+				timer.NewStub(now),
+				risk.NewDetector(risk.NewBlackListFake(nil), risk.NewDenylistFake(nil), risk.StrictThresholds),
+				DefaultChecks,
+				&repository.AliasSkeletonFake{},
+				&repository.FlaggedShortLinkFake{},
+				email.NewSenderFake(nil),
+				maintenance.Mode{},
+				&repository.AliasReservationFake{},
+				NewChainedLink(testCase.mode, shortenerDomains, 3, NewRedirectFollowerFake(redirects)),
+			)
+
+			shortLinkInput := entity.ShortLinkInput{LongLink: ptr.String(testCase.longLink)}
+			shortLink, err := updater.UpdateShortLink(context.Background(), "chained", shortLinkInput, user)
+			assert.Equal(t, testCase.expectedErr, err)
+			if err != nil {
+				savedShortLink, err := shortLinkRepo.GetShortLinkByAlias(context.Background(), "chained")
+				assert.Equal(t, nil, err)
+				assert.Equal(t, "https://www.example.com", savedShortLink.LongLink)
+				return
+			}
+			assert.Equal(t, testCase.expectedLongLink, shortLink.LongLink)
+			assert.Equal(t, testCase.expectedOriginalLongLink, shortLink.OriginalLongLink)
+		})
+	}
+}
+
+func TestParseChainedLinkMode(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name         string
+		modeName     string
+		expectedMode ChainedLinkMode
+		expectedErr  error
+	}{
+		{
+			name:         "empty name",
+			modeName:     "",
+			expectedMode: ChainedLinkReject,
+		},
+		{
+			name:         "reject",
+			modeName:     "reject",
+			expectedMode: ChainedLinkReject,
+		},
+		{
+			name:         "resolve",
+			modeName:     "resolve",
+			expectedMode: ChainedLinkResolve,
+		},
+		{
+			name:        "unknown name",
+			modeName:    "follow",
+			expectedErr: ErrUnknownChainedLinkMode("follow"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			mode, err := ParseChainedLinkMode(testCase.modeName)
+			assert.Equal(t, testCase.expectedErr, err)
+			assert.Equal(t, testCase.expectedMode, mode)
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package shortlink
+
+import (
+	"context"
+
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/risk"
+	"github.com/short-d/short/backend/app/usecase/validator"
+)
+
+// Check represents a stage which inspects short links before they are
+// created or updated.
+type Check string
+
+// The constants enumerate all the checks available when saving short links.
+const (
+	CheckCustomAlias Check = "custom_alias"
+	CheckLongLink    Check = "long_link"
+	CheckRisk        Check = "risk"
+	// CheckConfusableAlias rejects custom aliases looking like existing
+	// aliases. It looks up the database for each short link created, so it is
+	// not enabled by default.
+	CheckConfusableAlias Check = "confusable_alias"
+)
+
+// DefaultChecks runs the cheap local checks before risk detection, which may
+// call external services.
+var DefaultChecks = []Check{CheckCustomAlias, CheckLongLink, CheckRisk}
+
+// ErrUnknownCheck represents the check name which is not supported.
+type ErrUnknownCheck string
+
+func (e ErrUnknownCheck) Error() string {
+	return "unknown check: " + string(e)
+}
+
+// ParseChecks converts the names of the checks into the checks in the same
+// order.
+func ParseChecks(names []string) ([]Check, error) {
+	checks := make([]Check, 0, len(names))
+	for _, name := range names {
+		check := Check(name)
+		switch check {
+		case CheckCustomAlias, CheckLongLink, CheckRisk, CheckConfusableAlias:
+			checks = append(checks, check)
+		default:
+			return nil, ErrUnknownCheck(name)
+		}
+	}
+	return checks, nil
+}
+
+// checkReport collects the findings of the checks which don't reject short
+// links.
+type checkReport struct {
+	riskVerdict risk.Verdict
+	riskScore   risk.Score
+}
+
+// checker runs the configured checks on short links before they are saved, so
+// that creating and updating short links go through the same checks in the
+// same order.
+type checker struct {
+	checks            []Check
+	aliasValidator    validator.CustomAlias
+	longLinkValidator validator.LongLink
+	riskDetector      risk.Detector
+	aliasSkeletonRepo repository.AliasSkeleton
+}
+
+// runChecks runs the configured checks in order and stops at the first
+// failure. The alias is only checked against confusable aliases when
+// checkConfusable is set, since the users don't choose auto generated aliases.
+func (c checker) runChecks(ctx context.Context, shortLinkInput entity.ShortLinkInput, checkConfusable bool) (checkReport, error) {
+	report := checkReport{
+		riskVerdict: risk.VerdictAllow,
+		riskScore:   risk.ScoreSafe,
+	}
+	for _, check := range c.checks {
+		if check == CheckConfusableAlias && !checkConfusable {
+			continue
+		}
+		err := c.runCheck(ctx, check, shortLinkInput, &report)
+		if err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+func (c checker) runCheck(ctx context.Context, check Check, shortLinkInput entity.ShortLinkInput, report *checkReport) error {
+	switch check {
+	case CheckCustomAlias:
+		customAlias := shortLinkInput.GetCustomAlias("")
+		isValid, violation := c.aliasValidator.IsValid(customAlias)
+		if !isValid {
+			return ErrInvalidCustomAlias{customAlias, violation}
+		}
+		return nil
+	case CheckLongLink:
+		longLink := shortLinkInput.GetLongLink("")
+		isValid, violation := c.longLinkValidator.IsValid(longLink)
+		if !isValid {
+			return newLongLinkError(longLink, violation)
+		}
+		return nil
+	case CheckRisk:
+		longLink := shortLinkInput.GetLongLink("")
+		verdict, riskScore := c.riskDetector.AssessURL(longLink)
+		if verdict == risk.VerdictBlock {
+			return ErrMaliciousLongLink(longLink)
+		}
+		report.riskVerdict = verdict
+		report.riskScore = riskScore
+		return nil
+	case CheckConfusableAlias:
+		return c.checkConfusableAlias(ctx, shortLinkInput.GetCustomAlias(""))
+	default:
+		return ErrUnknownCheck(check)
+	}
+}
+
+func (c checker) hasCheck(check Check) bool {
+	for _, enabledCheck := range c.checks {
+		if enabledCheck == check {
+			return true
+		}
+	}
+	return false
+}
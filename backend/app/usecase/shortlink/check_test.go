@@ -0,0 +1,301 @@
+// +build !integration all
+
+package shortlink
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/metrics"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/fw/ptr"
+	"github.com/short-d/short/backend/app/usecase/authorizer"
+	"github.com/short-d/short/backend/app/usecase/email"
+	"github.com/short-d/short/backend/app/usecase/keygen"
+	"github.com/short-d/short/backend/app/usecase/maintenance"
+	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/risk"
+	"github.com/short-d/short/backend/app/usecase/validator"
+	"github.com/short-d/short/backend/app/usecase/webhook"
+)
+
+var _ risk.BlackList = (*countingBlackList)(nil)
+
+type countingBlackList struct {
+	blacklist risk.BlackListFake
+	calls     *int
+}
+
+func (c countingBlackList) GetURLScore(url string) (risk.Score, error) {
+	*c.calls++
+	return c.blacklist.GetURLScore(url)
+}
+
+func TestParseChecks(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name           string
+		names          []string
+		expectedChecks []Check
+		expectedErr    error
+	}{
+		{
+			name:           "default checks",
+			names:          []string{"custom_alias", "long_link", "risk"},
+			expectedChecks: DefaultChecks,
+		},
+		{
+			name:           "custom order",
+			names:          []string{"risk", "custom_alias"},
+			expectedChecks: []Check{CheckRisk, CheckCustomAlias},
+		},
+		{
+			name:           "confusable alias check",
+			names:          []string{"custom_alias", "confusable_alias"},
+			expectedChecks: []Check{CheckCustomAlias, CheckConfusableAlias},
+		},
+		{
+			name:           "no checks",
+			names:          []string{},
+			expectedChecks: []Check{},
+		},
+		{
+			name:        "unknown check",
+			names:       []string{"long_link", "profanity"},
+			expectedErr: ErrUnknownCheck("profanity"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			checks, err := ParseChecks(testCase.names)
+			assert.Equal(t, testCase.expectedErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, testCase.expectedChecks, checks)
+		})
+	}
+}
+
+func TestShortLinkCreatorPersist_CreateShortLinkChecks(t *testing.T) {
+	t.Parallel()
+
+	maliciousLongLink := "http://malware.wicar.org/data/ms14_064_ole_not_xp.html"
+
+	testCases := []struct {
+		name                   string
+		checks                 []Check
+		shortLinkInput         entity.ShortLinkInput
+		expectedErr            error
+		expectedBlackListCalls int
+	}{
+		{
+			name:   "local checks run before risk detection",
+			checks: DefaultChecks,
+			shortLinkInput: entity.ShortLinkInput{
+				LongLink:    ptr.String("https://www.google.com"),
+				CustomAlias: ptr.String("google#"),
+			},
+			expectedErr: ErrInvalidCustomAlias{
+				customAlias: "google#",
+				Violation:   validator.HasFragmentCharacter,
+			},
+			expectedBlackListCalls: 0,
+		},
+		{
+			name:   "risk detection runs first",
+			checks: []Check{CheckRisk, CheckCustomAlias, CheckLongLink},
+			shortLinkInput: entity.ShortLinkInput{
+				LongLink:    ptr.String(maliciousLongLink),
+				CustomAlias: ptr.String("google#"),
+			},
+			expectedErr:            ErrMaliciousLongLink(maliciousLongLink),
+			expectedBlackListCalls: 1,
+		},
+		{
+			name:   "stop at first failed check",
+			checks: []Check{CheckCustomAlias, CheckRisk},
+			shortLinkInput: entity.ShortLinkInput{
+				LongLink:    ptr.String(maliciousLongLink),
+				CustomAlias: ptr.String("google#"),
+			},
+			expectedErr: ErrInvalidCustomAlias{
+				customAlias: "google#",
+				Violation:   validator.HasFragmentCharacter,
+			},
+			expectedBlackListCalls: 0,
+		},
+		{
+			name:   "skip disabled risk detection",
+			checks: []Check{CheckCustomAlias, CheckLongLink},
+			shortLinkInput: entity.ShortLinkInput{
+				LongLink:    ptr.String(maliciousLongLink),
+				CustomAlias: ptr.String("google"),
+			},
+			expectedBlackListCalls: 0,
+		},
+		{
+			name:   "skip disabled long link check",
+			checks: []Check{CheckCustomAlias, CheckRisk},
+			shortLinkInput: entity.ShortLinkInput{
+				LongLink:    ptr.String("google"),
+				CustomAlias: ptr.String("google"),
+			},
+			expectedBlackListCalls: 1,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			shortLinkRepo := repository.NewShortLinkFake(nil, shortLinks{})
+			userShortLinkRepo := repository.NewUserShortLinkRepoFake(nil, nil)
+			keyFetcher := keygen.NewKeyFetcherFake(nil)
+			keyGen, err := keygen.NewRemote(2, &keyFetcher)
+			assert.Equal(t, nil, err)
+			flaggedLinkRepo := repository.NewFlaggedShortLinkFake(nil)
+
+			blackListCalls := 0
+			blacklist := countingBlackList{
+				blacklist: risk.NewBlackListFake(map[string]bool{
+					maliciousLongLink: true,
+				}),
+				calls: &blackListCalls,
+			}
+
+			preferencesRepo := repository.NewUserPreferencesFake(nil)
+			creator := NewCreatorPersist(
+				&shortLinkRepo,
+				&userShortLinkRepo,
+				keyGen,
+				validator.NewLongLink(nil, nil, validator.FragmentPreserve, validator.PlainHTTPAllow),
+				validator.NewCustomAlias(),
+				timer.NewStub(time.Now()),
+				risk.NewDetector(blacklist, risk.NewDenylistFake(nil), risk.StrictThresholds),
+				&flaggedLinkRepo,
+				testCase.checks,
+				LongLinkUniquenessNone,
+				AliasQuota{},
+				authorizer.Authorizer{},
+				email.NewSenderFake(nil),
+				webhook.NewDispatcherFake(),
+				&preferencesRepo,
+				maintenance.Mode{},
+				metrics.NewFake(),
+				0,
+				ExpirationPolicy{},
+				&repository.AliasSkeletonFake{},
+				RelationRetry{},
+				&repository.AliasReservationFake{},
+				ReservationTTL{},
+				ChainedLink{},
+			)
+
+			user := entity.User{ID: "alpha", Email: "alpha@example.com"}
+			_, err = creator.CreateShortLink(context.Background(), testCase.shortLinkInput, user, false)
+			assert.Equal(t, testCase.expectedErr, err)
+			assert.Equal(t, testCase.expectedBlackListCalls, blackListCalls)
+		})
+	}
+}
+
+func TestShortLinkUpdaterPersist_UpdateShortLinkChecks(t *testing.T) {
+	t.Parallel()
+
+	maliciousLongLink := "http://malware.wicar.org/data/ms14_064_ole_not_xp.html"
+
+	testCases := []struct {
+		name                   string
+		checks                 []Check
+		shortLinkInput         entity.ShortLinkInput
+		expectedErr            error
+		expectedBlackListCalls int
+	}{
+		{
+			name:   "local checks run before risk detection",
+			checks: DefaultChecks,
+			shortLinkInput: entity.ShortLinkInput{
+				LongLink:    ptr.String(maliciousLongLink),
+				CustomAlias: ptr.String("google#"),
+			},
+			expectedErr: ErrInvalidCustomAlias{
+				customAlias: "google#",
+				Violation:   validator.HasFragmentCharacter,
+			},
+			expectedBlackListCalls: 0,
+		},
+		{
+			name:   "risk detection runs first",
+			checks: []Check{CheckRisk, CheckCustomAlias, CheckLongLink},
+			shortLinkInput: entity.ShortLinkInput{
+				LongLink:    ptr.String(maliciousLongLink),
+				CustomAlias: ptr.String("google#"),
+			},
+			expectedErr:            ErrMaliciousLongLink(maliciousLongLink),
+			expectedBlackListCalls: 1,
+		},
+		{
+			name:   "skip disabled risk detection",
+			checks: []Check{CheckCustomAlias, CheckLongLink},
+			shortLinkInput: entity.ShortLinkInput{
+				LongLink: ptr.String(maliciousLongLink),
+			},
+			expectedBlackListCalls: 0,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			user := entity.User{ID: "alpha", Email: "alpha@example.com"}
+			shortLink := entity.ShortLink{Alias: "google", LongLink: "https://www.google.com"}
+			userShortLinkRepo := repository.NewUserShortLinkRepoFake(
+				[]entity.User{user},
+				[]entity.ShortLink{shortLink},
+			)
+			shortLinkRepo := repository.NewShortLinkFake(&userShortLinkRepo, shortLinks{
+				"google": shortLink,
+			})
+
+			blackListCalls := 0
+			blacklist := countingBlackList{
+				blacklist: risk.NewBlackListFake(map[string]bool{
+					maliciousLongLink: true,
+				}),
+				calls: &blackListCalls,
+			}
+
+			updater := NewUpdaterPersist(
+				&shortLinkRepo,
+				&userShortLinkRepo,
+				validator.NewLongLink(nil, nil, validator.FragmentPreserve, validator.PlainHTTPAllow),
+				validator.NewCustomAlias(),
+				timer.NewStub(time.Now()),
+				risk.NewDetector(blacklist, risk.NewDenylistFake(nil), risk.StrictThresholds),
+				testCase.checks,
+				&repository.AliasSkeletonFake{},
+				&repository.FlaggedShortLinkFake{},
+				email.NewSenderFake(nil),
+				maintenance.Mode{},
+				&repository.AliasReservationFake{},
+				ChainedLink{},
+			)
+
+			_, err := updater.UpdateShortLink(context.Background(), "google", testCase.shortLinkInput, user)
+			assert.Equal(t, testCase.expectedErr, err)
+			assert.Equal(t, testCase.expectedBlackListCalls, blackListCalls)
+		})
+	}
+}
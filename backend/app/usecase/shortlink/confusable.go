@@ -0,0 +1,127 @@
+package shortlink
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// confusables maps the characters to the ASCII characters they look like,
+// following a subset of the Unicode confusables data. The characters already
+// reduced by compatibility decomposition, such as full width letters, are not
+// listed.
+var confusables = map[rune]rune{
+	'0': 'o',
+	'1': 'l',
+	'I': 'l',
+	// Cyrillic
+	'а': 'a',
+	'в': 'b',
+	'с': 'c',
+	'ԁ': 'd',
+	'е': 'e',
+	'һ': 'h',
+	'і': 'i',
+	'ј': 'j',
+	'к': 'k',
+	'ӏ': 'l',
+	'м': 'm',
+	'о': 'o',
+	'р': 'p',
+	'ԛ': 'q',
+	'ѕ': 's',
+	'т': 't',
+	'у': 'y',
+	'ԝ': 'w',
+	'х': 'x',
+	// Greek
+	'α': 'a',
+	'β': 'b',
+	'ε': 'e',
+	'η': 'n',
+	'ι': 'i',
+	'κ': 'k',
+	'ν': 'v',
+	'ο': 'o',
+	'ρ': 'p',
+	'τ': 't',
+	'υ': 'u',
+	'χ': 'x',
+}
+
+// confusableSequences replaces the character sequences which look like a
+// single character.
+var confusableSequences = strings.NewReplacer("rn", "m", "vv", "w")
+
+// ErrConfusableAlias represents the custom alias looking like an existing
+// alias, such as paypa1 and paypal.
+type ErrConfusableAlias struct {
+	alias         string
+	ExistingAlias string
+}
+
+func (e ErrConfusableAlias) Error() string {
+	return fmt.Sprintf("alias %s looks like existing alias %s", e.alias, e.ExistingAlias)
+}
+
+// skeleton reduces the alias to the form shared by all the aliases looking
+// alike. Diacritics and letter case are dropped, and each character is
+// replaced with the ASCII character it looks like.
+func skeleton(alias string) string {
+	var builder strings.Builder
+	for _, char := range norm.NFKD.String(alias) {
+		if unicode.Is(unicode.Mn, char) {
+			continue
+		}
+		builder.WriteRune(prototype(char))
+	}
+	return confusableSequences.Replace(builder.String())
+}
+
+func prototype(char rune) rune {
+	if proto, ok := confusables[char]; ok {
+		return proto
+	}
+	char = unicode.ToLower(char)
+	if proto, ok := confusables[char]; ok {
+		return proto
+	}
+	return char
+}
+
+// checkConfusableAlias rejects the custom alias whose skeleton is shared by
+// an existing alias. The skeletons are indexed, so that each check costs a
+// single lookup instead of comparing against every alias.
+func (c checker) checkConfusableAlias(ctx context.Context, alias string) error {
+	existingAlias, found, err := c.aliasSkeletonRepo.FindAliasBySkeleton(ctx, skeleton(alias), alias)
+	if err != nil {
+		return err
+	}
+	if found {
+		return ErrConfusableAlias{alias: alias, ExistingAlias: existingAlias}
+	}
+	return nil
+}
+
+// recordSkeleton saves the skeleton of the new alias for the later checks.
+// Nothing is saved when confusable aliases are not checked, so only the
+// aliases created while the check is enabled are compared.
+func (c checker) recordSkeleton(ctx context.Context, alias string) error {
+	if !c.hasCheck(CheckConfusableAlias) {
+		return nil
+	}
+	return c.aliasSkeletonRepo.CreateAliasSkeleton(ctx, alias, skeleton(alias))
+}
+
+// moveSkeleton replaces the skeleton of the old alias with the skeleton of the
+// new alias after the short link is renamed, so that later checks compare
+// against the alias in use.
+func (c checker) moveSkeleton(ctx context.Context, oldAlias string, newAlias string) error {
+	if !c.hasCheck(CheckConfusableAlias) {
+		return nil
+	}
+	return c.aliasSkeletonRepo.RenameAliasSkeleton(ctx, oldAlias, newAlias, skeleton(newAlias))
+}
@@ -0,0 +1,296 @@
+// +build !integration all
+
+package shortlink
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/metrics"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/fw/ptr"
+	"github.com/short-d/short/backend/app/usecase/authorizer"
+	"github.com/short-d/short/backend/app/usecase/email"
+	"github.com/short-d/short/backend/app/usecase/keygen"
+	"github.com/short-d/short/backend/app/usecase/maintenance"
+	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/risk"
+	"github.com/short-d/short/backend/app/usecase/validator"
+	"github.com/short-d/short/backend/app/usecase/webhook"
+)
+
+func TestSkeleton(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name             string
+		alias            string
+		expectedSkeleton string
+	}{
+		{
+			name:             "plain alias",
+			alias:            "paypal",
+			expectedSkeleton: "paypal",
+		},
+		{
+			name:             "digits looking like letters",
+			alias:            "paypa1",
+			expectedSkeleton: "paypal",
+		},
+		{
+			name:             "upper case",
+			alias:            "PayPaI",
+			expectedSkeleton: "paypal",
+		},
+		{
+			name:             "Cyrillic lookalikes",
+			alias:            "раураl",
+			expectedSkeleton: "paypal",
+		},
+		{
+			name:             "Greek lookalikes",
+			alias:            "gοοgle",
+			expectedSkeleton: "google",
+		},
+		{
+			name:             "diacritics",
+			alias:            "pāypal",
+			expectedSkeleton: "paypal",
+		},
+		{
+			name:             "full width letters",
+			alias:            "ｐａｙｐａｌ",
+			expectedSkeleton: "paypal",
+		},
+		{
+			name:             "sequence looking like a letter",
+			alias:            "arnazon",
+			expectedSkeleton: "amazon",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, testCase.expectedSkeleton, skeleton(testCase.alias))
+		})
+	}
+}
+
+func TestShortLinkCreatorPersist_CreateShortLinkConfusableAlias(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name              string
+		checks            []Check
+		skeletons         map[string]string
+		customAlias       *string
+		availableKeys     []keygen.Key
+		expectedErr       error
+		expectedSkeletons map[string]string
+	}{
+		{
+			name:        "reject confusable alias",
+			checks:      []Check{CheckCustomAlias, CheckConfusableAlias},
+			skeletons:   map[string]string{"paypal": "paypal"},
+			customAlias: ptr.String("paypa1"),
+			expectedErr: ErrConfusableAlias{
+				alias:         "paypa1",
+				ExistingAlias: "paypal",
+			},
+			expectedSkeletons: map[string]string{"paypal": "paypal"},
+		},
+		{
+			name:        "reject Cyrillic lookalike",
+			checks:      []Check{CheckConfusableAlias},
+			skeletons:   map[string]string{"paypal": "paypal"},
+			customAlias: ptr.String("раураl"),
+			expectedErr: ErrConfusableAlias{
+				alias:         "раураl",
+				ExistingAlias: "paypal",
+			},
+			expectedSkeletons: map[string]string{"paypal": "paypal"},
+		},
+		{
+			name:        "accept different alias",
+			checks:      []Check{CheckCustomAlias, CheckConfusableAlias},
+			skeletons:   map[string]string{"paypal": "paypal"},
+			customAlias: ptr.String("paydays"),
+			expectedSkeletons: map[string]string{
+				"paypal":  "paypal",
+				"paydays": "paydays",
+			},
+		},
+		{
+			name:          "skip auto alias",
+			checks:        []Check{CheckCustomAlias, CheckConfusableAlias},
+			skeletons:     map[string]string{"paypal": "paypal"},
+			availableKeys: []keygen.Key{"paypa1"},
+			expectedSkeletons: map[string]string{
+				"paypal": "paypal",
+				"paypa1": "paypal",
+			},
+		},
+		{
+			name:              "skip disabled check",
+			checks:            DefaultChecks,
+			skeletons:         map[string]string{"paypal": "paypal"},
+			customAlias:       ptr.String("paypa1"),
+			expectedSkeletons: map[string]string{"paypal": "paypal"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			shortLinkRepo := repository.NewShortLinkFake(nil, shortLinks{
+				"paypal": {Alias: "paypal", LongLink: "https://www.paypal.com"},
+			})
+			userShortLinkRepo := repository.NewUserShortLinkRepoFake(nil, nil)
+			keyFetcher := keygen.NewKeyFetcherFake(testCase.availableKeys)
+			keyGen, err := keygen.NewRemote(2, &keyFetcher)
+			assert.Equal(t, nil, err)
+			flaggedLinkRepo := repository.NewFlaggedShortLinkFake(nil)
+			preferencesRepo := repository.NewUserPreferencesFake(nil)
+			aliasSkeletonRepo := repository.NewAliasSkeletonFake(testCase.skeletons)
+
+			creator := NewCreatorPersist(
+				&shortLinkRepo,
+				&userShortLinkRepo,
+				keyGen,
+				validator.NewLongLink(nil, nil, validator.FragmentPreserve, validator.PlainHTTPAllow),
+				validator.NewCustomAlias(),
+				timer.NewStub(time.Now()),
+				risk.NewDetector(risk.NewBlackListFake(nil), risk.NewDenylistFake(nil), risk.StrictThresholds),
+				&flaggedLinkRepo,
+				testCase.checks,
+				LongLinkUniquenessNone,
+				AliasQuota{},
+				authorizer.Authorizer{},
+				email.NewSenderFake(nil),
+				webhook.NewDispatcherFake(),
+				&preferencesRepo,
+				maintenance.Mode{},
+				metrics.NewFake(),
+				0,
+				ExpirationPolicy{},
+				&aliasSkeletonRepo,
+				RelationRetry{},
+				&repository.AliasReservationFake{},
+				ReservationTTL{},
+				ChainedLink{},
+			)
+
+			shortLinkInput := entity.ShortLinkInput{
+				LongLink:    ptr.String("https://www.example.com"),
+				CustomAlias: testCase.customAlias,
+			}
+			user := entity.User{ID: "alpha", Email: "alpha@example.com"}
+			_, err = creator.CreateShortLink(context.Background(), shortLinkInput, user, false)
+			assert.Equal(t, testCase.expectedErr, err)
+			assert.Equal(t, testCase.expectedSkeletons, aliasSkeletonRepo.Skeletons())
+		})
+	}
+}
+
+func TestShortLinkUpdaterPersist_UpdateShortLinkConfusableAlias(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name              string
+		checks            []Check
+		customAlias       *string
+		expectedErr       error
+		expectedSkeletons map[string]string
+	}{
+		{
+			name:        "reject rename to confusable alias",
+			checks:      []Check{CheckCustomAlias, CheckConfusableAlias},
+			customAlias: ptr.String("paypa1"),
+			expectedErr: ErrConfusableAlias{
+				alias:         "paypa1",
+				ExistingAlias: "paypal",
+			},
+			expectedSkeletons: map[string]string{
+				"paypal": "paypal",
+				"stripe": "stripe",
+			},
+		},
+		{
+			name:        "move skeleton on rename",
+			checks:      []Check{CheckCustomAlias, CheckConfusableAlias},
+			customAlias: ptr.String("paydays"),
+			expectedSkeletons: map[string]string{
+				"paypal":  "paypal",
+				"paydays": "paydays",
+			},
+		},
+		{
+			name:   "keep skeleton without rename",
+			checks: []Check{CheckCustomAlias, CheckConfusableAlias},
+			expectedSkeletons: map[string]string{
+				"paypal": "paypal",
+				"stripe": "stripe",
+			},
+		},
+		{
+			name:        "skip disabled check",
+			checks:      DefaultChecks,
+			customAlias: ptr.String("paypa1"),
+			expectedSkeletons: map[string]string{
+				"paypal": "paypal",
+				"stripe": "stripe",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			user := entity.User{ID: "alpha", Email: "alpha@example.com"}
+			shortLink := entity.ShortLink{Alias: "stripe", LongLink: "https://www.stripe.com"}
+			userShortLinkRepo := repository.NewUserShortLinkRepoFake(
+				[]entity.User{user},
+				[]entity.ShortLink{shortLink},
+			)
+			shortLinkRepo := repository.NewShortLinkFake(&userShortLinkRepo, shortLinks{
+				"paypal": {Alias: "paypal", LongLink: "https://www.paypal.com"},
+				"stripe": shortLink,
+			})
+			aliasSkeletonRepo := repository.NewAliasSkeletonFake(map[string]string{
+				"paypal": "paypal",
+				"stripe": "stripe",
+			})
+
+			updater := NewUpdaterPersist(
+				&shortLinkRepo,
+				&userShortLinkRepo,
+				validator.NewLongLink(nil, nil, validator.FragmentPreserve, validator.PlainHTTPAllow),
+				validator.NewCustomAlias(),
+				timer.NewStub(time.Now()),
+				risk.NewDetector(risk.NewBlackListFake(nil), risk.NewDenylistFake(nil), risk.StrictThresholds),
+				testCase.checks,
+				&aliasSkeletonRepo,
+				&repository.FlaggedShortLinkFake{},
+				email.NewSenderFake(nil),
+				maintenance.Mode{},
+				&repository.AliasReservationFake{},
+				ChainedLink{},
+			)
+
+			shortLinkInput := entity.ShortLinkInput{CustomAlias: testCase.customAlias}
+			_, err := updater.UpdateShortLink(context.Background(), "stripe", shortLinkInput, user)
+			assert.Equal(t, testCase.expectedErr, err)
+			assert.Equal(t, testCase.expectedSkeletons, aliasSkeletonRepo.Skeletons())
+		})
+	}
+}
@@ -1,16 +1,31 @@
 package shortlink
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/short-d/app/fw/metrics"
 	"github.com/short-d/app/fw/timer"
 	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/authorizer"
+	"github.com/short-d/short/backend/app/usecase/email"
 	"github.com/short-d/short/backend/app/usecase/keygen"
+	"github.com/short-d/short/backend/app/usecase/maintenance"
 	"github.com/short-d/short/backend/app/usecase/repository"
 	"github.com/short-d/short/backend/app/usecase/risk"
+	"github.com/short-d/short/backend/app/usecase/route"
 	"github.com/short-d/short/backend/app/usecase/validator"
+	"github.com/short-d/short/backend/app/usecase/webhook"
 )
 
 var _ Creator = (*CreatorPersist)(nil)
 
+// OneTimeLifetime represents how long one-time short links stay active when
+// they are created without expiration time.
+const OneTimeLifetime = time.Hour
+
 // ErrAliasExist represents alias unavailable error
 type ErrAliasExist string
 
@@ -28,6 +43,22 @@ func (e ErrInvalidLongLink) Error() string {
 	return string(e.LongLink)
 }
 
+// ErrSelfReferentialLink represents long link redirecting back to Short error
+type ErrSelfReferentialLink string
+
+func (e ErrSelfReferentialLink) Error() string {
+	return string(e)
+}
+
+// newLongLinkError converts the violation found by the long link validator
+// into error.
+func newLongLinkError(longLink string, violation validator.Violation) error {
+	if violation == validator.SelfReferencing {
+		return ErrSelfReferentialLink(longLink)
+	}
+	return ErrInvalidLongLink{longLink, violation}
+}
+
 // ErrInvalidCustomAlias represents incorrect custom alias format error
 type ErrInvalidCustomAlias struct {
 	customAlias string
@@ -38,6 +69,14 @@ func (e ErrInvalidCustomAlias) Error() string {
 	return string(e.customAlias)
 }
 
+// ErrEmptySessionID represents the guest session creating a short link is not
+// identified
+type ErrEmptySessionID string
+
+func (e ErrEmptySessionID) Error() string {
+	return string(e)
+}
+
 // ErrMaliciousLongLink represents malicious long link error
 type ErrMaliciousLongLink string
 
@@ -45,9 +84,31 @@ func (e ErrMaliciousLongLink) Error() string {
 	return string(e)
 }
 
+// ErrServiceReadOnly represents the failure of changing short links while the
+// service is in read-only mode
+type ErrServiceReadOnly string
+
+func (e ErrServiceReadOnly) Error() string {
+	return fmt.Sprintf("service is read-only, can't %s", string(e))
+}
+
+// ShortLinkPreview represents the short link CreateShortLink would create for
+// the same input. The short link is not saved, so the auto generated alias may
+// be taken by another short link before the actual creation.
+type ShortLinkPreview struct {
+	ShortLink        entity.ShortLink
+	IsAutoAlias      bool
+	IsAliasAvailable bool
+}
+
 // Creator represents a ShortLink alias creator
 type Creator interface {
-	CreateShortLink(shortLinkInput entity.ShortLinkInput, user entity.User, isPublic bool) (entity.ShortLink, error)
+	CreateShortLink(ctx context.Context, shortLinkInput entity.ShortLinkInput, user entity.User, isPublic bool) (entity.ShortLink, error)
+	CreateShortLinks(ctx context.Context, shortLinkInputs []entity.ShortLinkInput, user entity.User) (BulkReport, error)
+	CreateGuestShortLink(ctx context.Context, shortLinkInput entity.ShortLinkInput, sessionID string) (entity.ShortLink, error)
+	PreviewShortLink(ctx context.Context, shortLinkInput entity.ShortLinkInput) (ShortLinkPreview, error)
+	CloneShortLink(ctx context.Context, sourceAlias string, newAlias string, user entity.User) (entity.ShortLink, error)
+	ReserveAlias(ctx context.Context, alias string, user entity.User, ttl time.Duration) (entity.AliasReservation, error)
 }
 
 // CreatorPersist represents a ShortLink alias creator which persist the generated
@@ -60,13 +121,141 @@ type CreatorPersist struct {
 	aliasValidator    validator.CustomAlias
 	timer             timer.Timer
 	riskDetector      risk.Detector
+	flaggedLinkRepo   repository.FlaggedShortLink
+	checks            []Check
+	uniqueness        LongLinkUniqueness
+	aliasQuota        AliasQuota
+	authorizer        authorizer.Authorizer
+	emailSender       email.Sender
+	dispatcher        webhook.Dispatcher
+	preferencesRepo   repository.UserPreferences
+	maintenanceMode   maintenance.Mode
+	metrics           metrics.Metrics
+	aliasRetryBudget  int
+	expirationPolicy  ExpirationPolicy
+	aliasSkeletonRepo repository.AliasSkeleton
+	relationRetry     RelationRetry
+	reservationRepo   repository.AliasReservation
+	reservationTTL    ReservationTTL
+	chainedLink       ChainedLink
 }
 
 // CreateShortLink persists a new short link with a given or auto generated alias in the repository.
+// When long links are globally unique, the existing short link redirecting to
+// the same long link is returned instead, regardless of the user. New short
+// links are rejected once the user reaches the alias quota. The preferences of
+// the user fill in the attributes missing from the input, falling back to the
+// expiration policy when neither gives the expiration time.
 // TODO(issue#235): add functionality for public URLs
-func (c CreatorPersist) CreateShortLink(shortLinkInput entity.ShortLinkInput, user entity.User, isPublic bool) (entity.ShortLink, error) {
-	if shortLinkInput.CustomAlias == nil || shortLinkInput.GetCustomAlias("") == "" {
-		autoAlias, err := c.generateAlias()
+func (c CreatorPersist) CreateShortLink(ctx context.Context, shortLinkInput entity.ShortLinkInput, user entity.User, isPublic bool) (entity.ShortLink, error) {
+	shortLinkInput, err := c.applyPreferences(c.applyOneTime(shortLinkInput), user)
+	if err != nil {
+		return entity.ShortLink{}, err
+	}
+	return c.createUserShortLink(ctx, shortLinkInput, user)
+}
+
+// applyPreferences sets the attributes not given in the input to the defaults
+// preferred by the user. The explicitly given attributes are kept as is. The
+// expiration policy decides the expiration time when the user prefers none.
+func (c CreatorPersist) applyPreferences(shortLinkInput entity.ShortLinkInput, user entity.User) (entity.ShortLinkInput, error) {
+	preferences, err := c.preferencesRepo.GetPreferences(user.ID)
+	if err != nil {
+		return shortLinkInput, err
+	}
+
+	if shortLinkInput.ExpireAt == nil && preferences.DefaultExpireAfter != nil {
+		expireAt := c.timer.Now().UTC().Add(*preferences.DefaultExpireAfter)
+		shortLinkInput.ExpireAt = &expireAt
+	}
+	if shortLinkInput.ExpireAt == nil {
+		expireAt, err := c.expirationPolicy.DefaultExpireAt(user, c.timer.Now().UTC())
+		if err != nil {
+			return shortLinkInput, err
+		}
+		shortLinkInput.ExpireAt = expireAt
+	}
+	if shortLinkInput.TrackVisits == nil {
+		shortLinkInput.TrackVisits = preferences.DefaultTrackVisits
+	}
+	return shortLinkInput, nil
+}
+
+// applyOneTime limits one-time short links to a single visit. They expire
+// after OneTimeLifetime unless the expiration time is given.
+func (c CreatorPersist) applyOneTime(shortLinkInput entity.ShortLinkInput) entity.ShortLinkInput {
+	if !shortLinkInput.GetOneTime(false) {
+		return shortLinkInput
+	}
+
+	maxVisits := 1
+	shortLinkInput.MaxVisits = &maxVisits
+	if shortLinkInput.ExpireAt == nil {
+		expireAt := c.timer.Now().UTC().Add(OneTimeLifetime)
+		shortLinkInput.ExpireAt = &expireAt
+	}
+	return shortLinkInput
+}
+
+func (c CreatorPersist) createUserShortLink(ctx context.Context, shortLinkInput entity.ShortLinkInput, user entity.User) (entity.ShortLink, error) {
+	return c.create(ctx, shortLinkInput, &user, func(shortLinkInput entity.ShortLinkInput, isCustomAlias bool) error {
+		return c.userShortLinkRepo.CreateRelation(ctx, user, shortLinkInput, isCustomAlias)
+	})
+}
+
+// CreateGuestShortLink persists a new short link created by a signed out user
+// and attributes it to the guest session, so that it can be claimed once the
+// user signs in. Guests don't have preferences, so none are applied.
+func (c CreatorPersist) CreateGuestShortLink(ctx context.Context, shortLinkInput entity.ShortLinkInput, sessionID string) (entity.ShortLink, error) {
+	if sessionID == "" {
+		return entity.ShortLink{}, ErrEmptySessionID("session ID can't be empty")
+	}
+	return c.create(ctx, c.applyOneTime(shortLinkInput), nil, func(shortLinkInput entity.ShortLinkInput, isCustomAlias bool) error {
+		return c.userShortLinkRepo.CreateGuestRelation(ctx, sessionID, shortLinkInput)
+	})
+}
+
+// create persists the short link and attributes it to the creator. The alias
+// quota is only checked for signed in users. The custom alias reserved by
+// others can't be taken. Nothing is created in read-only mode. The long link
+// given by the user is kept as the original long link when it is a short link
+// resolved to its destination.
+func (c CreatorPersist) create(
+	ctx context.Context,
+	shortLinkInput entity.ShortLinkInput,
+	user *entity.User,
+	createRelation func(shortLinkInput entity.ShortLinkInput, isCustomAlias bool) error,
+) (entity.ShortLink, error) {
+	if c.maintenanceMode.IsReadOnly() {
+		return entity.ShortLink{}, ErrServiceReadOnly("create short link")
+	}
+
+	longLink, err := c.resolveChainedLink(ctx, shortLinkInput.GetLongLink(""))
+	if err != nil {
+		return entity.ShortLink{}, err
+	}
+	longLink = canonicalizeLongLink(c.longLinkValidator, c.uniqueness, longLink)
+	originalLongLink := shortLinkInput.GetOriginalLongLink(shortLinkInput.GetLongLink(""))
+	isCustomAlias := shortLinkInput.GetCustomAlias("") != ""
+	// The short links limiting the total visits can't be reused.
+	if c.uniqueness == LongLinkUniquenessGlobal && user != nil && !isCustomAlias && shortLinkInput.GetMaxVisits(0) == 0 {
+		shortLink, found, err := c.findCanonicalShortLink(ctx, longLink, *user)
+		if err != nil || found {
+			return shortLink, err
+		}
+	}
+	shortLinkInput.LongLink = &longLink
+	shortLinkInput.OriginalLongLink = &originalLongLink
+
+	if user != nil {
+		err := c.checkAliasQuota(ctx, *user, isCustomAlias)
+		if err != nil {
+			return entity.ShortLink{}, err
+		}
+	}
+
+	if !isCustomAlias {
+		autoAlias, err := c.generateAlias(ctx)
 		if err != nil {
 			// TODO(issue#950) create error type for fail create auto alias
 			return entity.ShortLink{}, err
@@ -74,41 +263,251 @@ func (c CreatorPersist) CreateShortLink(shortLinkInput entity.ShortLinkInput, us
 		shortLinkInput.CustomAlias = &autoAlias
 	}
 
-	customAlias := shortLinkInput.GetCustomAlias("")
-	isValid, violation := c.aliasValidator.IsValid(customAlias)
-	if !isValid {
-		return entity.ShortLink{}, ErrInvalidCustomAlias{customAlias, violation}
+	customAlias := normalizeAlias(shortLinkInput.GetCustomAlias(""))
+	shortLinkInput.CustomAlias = &customAlias
+
+	err = validateDescription(shortLinkInput.GetDescription(""))
+	if err != nil {
+		return entity.ShortLink{}, err
 	}
 
-	longLink := shortLinkInput.GetLongLink("")
-	isValid, violation = c.longLinkValidator.IsValid(longLink)
-	if !isValid {
-		return entity.ShortLink{}, ErrInvalidLongLink{longLink, violation}
+	report, err := c.checker().runChecks(ctx, shortLinkInput, isCustomAlias)
+	var errMalicious ErrMaliciousLongLink
+	if errors.As(err, &errMalicious) {
+		c.dispatchFlagged(user, longLink)
+	}
+	if err != nil {
+		return entity.ShortLink{}, err
 	}
 
-	if c.riskDetector.IsURLMalicious(longLink) {
-		return entity.ShortLink{}, ErrMaliciousLongLink(longLink)
+	reservationToken := shortLinkInput.GetReservationToken("")
+	if isCustomAlias {
+		err = checkReservation(ctx, c.reservationRepo, customAlias, reservationToken, user, c.timer.Now().UTC())
+		if err != nil {
+			return entity.ShortLink{}, err
+		}
 	}
 
+	shortLink, err := c.createShortLink(ctx, shortLinkInput, isCustomAlias, createRelation)
+	if err != nil {
+		return shortLink, err
+	}
+	releaseReservation(ctx, c.reservationRepo, shortLink.Alias, reservationToken)
+
+	err = c.checker().recordSkeleton(ctx, shortLink.Alias)
+	if err != nil || report.riskVerdict != risk.VerdictWarn {
+		return shortLink, err
+	}
+
+	err = c.flaggedLinkRepo.CreateFlaggedShortLink(ctx, entity.FlaggedShortLink{
+		Alias:     shortLink.Alias,
+		RiskScore: int(report.riskScore),
+		FlaggedAt: c.timer.Now().UTC(),
+	})
+	if err != nil {
+		return shortLink, err
+	}
+
+	if user != nil {
+		notifyFlagged(c.emailSender, *user, shortLink)
+	}
+	return shortLink, nil
+}
+
+func (c CreatorPersist) checker() checker {
+	return checker{
+		checks:            c.checks,
+		aliasValidator:    c.aliasValidator,
+		longLinkValidator: c.longLinkValidator,
+		riskDetector:      c.riskDetector,
+		aliasSkeletonRepo: c.aliasSkeletonRepo,
+	}
+}
+
+// notifyFlagged emails the owner that the short link is pending for review.
+// The short link is kept even if the email can't be delivered, since the
+// sender logs its own failures.
+func notifyFlagged(emailSender email.Sender, user entity.User, shortLink entity.ShortLink) {
+	if user.Email == "" {
+		return
+	}
+	subject := fmt.Sprintf("Your short link %s is under review", shortLink.Alias)
+	body := fmt.Sprintf(
+		"Your short link %s redirecting to %s looks suspicious to our "+
+			"safety checks, so it has been flagged for review. It keeps "+
+			"working until the review is done.\n",
+		shortLink.Alias, shortLink.LongLink,
+	)
+	_ = emailSender.Send(user.Email, subject, body)
+}
+
+// dispatchFlagged notifies the webhook of the long link rejected by risk
+// detection so that the abuse can be investigated.
+func (c CreatorPersist) dispatchFlagged(user *entity.User, longLink string) {
+	data := webhook.LinkFlaggedData{LongLink: longLink}
+	if user != nil {
+		data.UserID = user.ID
+	}
+	c.dispatcher.Dispatch(webhook.Event{
+		Type:       webhook.LinkFlagged,
+		OccurredAt: c.timer.Now().UTC(),
+		Data:       data,
+	})
+}
+
+// PreviewShortLink runs the same checks as CreateShortLink without saving the
+// short link or consuming the auto generated alias. The auto generated alias
+// is reported unavailable when it is reserved by the routes, since
+// CreateShortLink skips it. The alias is reported unavailable as well while it
+// is held by a reservation not matching the given token.
+func (c CreatorPersist) PreviewShortLink(ctx context.Context, shortLinkInput entity.ShortLinkInput) (ShortLinkPreview, error) {
+	shortLinkInput = c.applyOneTime(shortLinkInput)
+	isAutoAlias := shortLinkInput.CustomAlias == nil || shortLinkInput.GetCustomAlias("") == ""
+
+	customAlias := normalizeAlias(shortLinkInput.GetCustomAlias(""))
+	shortLinkInput.CustomAlias = &customAlias
+
+	longLink, err := c.resolveChainedLink(ctx, shortLinkInput.GetLongLink(""))
+	if err != nil {
+		return ShortLinkPreview{}, err
+	}
+	longLink = canonicalizeLongLink(c.longLinkValidator, c.uniqueness, longLink)
 	shortLinkInput.LongLink = &longLink
 
-	return c.createShortLink(shortLinkInput, user)
+	err = validateDescription(shortLinkInput.GetDescription(""))
+	if err != nil {
+		return ShortLinkPreview{}, err
+	}
+
+	_, err = c.checker().runChecks(ctx, shortLinkInput, !isAutoAlias)
+	if err != nil {
+		return ShortLinkPreview{}, err
+	}
+
+	alias := customAlias
+	if isAutoAlias {
+		key, err := c.keyGen.PreviewKey()
+		if err != nil {
+			return ShortLinkPreview{}, err
+		}
+		alias = string(key)
+	}
+
+	isExist, err := c.shortLinkRepo.IsAliasExist(ctx, alias)
+	if err != nil {
+		return ShortLinkPreview{}, err
+	}
+
+	reservation, isReserved, err := findActiveReservation(ctx, c.reservationRepo, alias, c.timer.Now().UTC())
+	if err != nil {
+		return ShortLinkPreview{}, err
+	}
+	isHeld := isReserved && (isAutoAlias || reservation.Token != shortLinkInput.GetReservationToken(""))
+
+	return ShortLinkPreview{
+		ShortLink: entity.ShortLink{
+			Alias:    alias,
+			LongLink: longLink,
+			ExpireAt: shortLinkInput.ExpireAt,
+		},
+		IsAutoAlias:      isAutoAlias,
+		IsAliasAvailable: !isExist && !isHeld && !route.IsReserved(alias),
+	}, nil
 }
 
-func (c CreatorPersist) generateAlias() (string, error) {
-	key, err := c.keyGen.NewKey()
+// CloneShortLink creates a new short link owned by the user which redirects to
+// the same long link as the source short link. The clone keeps the expiration
+// time of the source but starts without any visits. An alias is generated
+// when newAlias is empty. The preferences of the user don't apply to clones.
+func (c CreatorPersist) CloneShortLink(ctx context.Context, sourceAlias string, newAlias string, user entity.User) (entity.ShortLink, error) {
+	hasMapping, err := c.userShortLinkRepo.HasMapping(ctx, user, sourceAlias)
 	if err != nil {
-		return "", err
+		return entity.ShortLink{}, err
 	}
-	return string(key), nil
+	if !hasMapping {
+		return entity.ShortLink{}, ErrShortLinkNotFound(sourceAlias)
+	}
+
+	source, err := c.shortLinkRepo.GetShortLinkByAlias(ctx, sourceAlias)
+	if err != nil {
+		return entity.ShortLink{}, err
+	}
+
+	originalLongLink := source.GetOriginalLongLink()
+	shortLinkInput := entity.ShortLinkInput{
+		LongLink:          &source.LongLink,
+		OriginalLongLink:  &originalLongLink,
+		CustomAlias:       &newAlias,
+		ExpireAt:          source.ExpireAt,
+		TrackVisits:       &source.TrackVisits,
+		PassthroughQuery:  &source.PassthroughQuery,
+		PassthroughPath:   &source.PassthroughPath,
+		MaxRedirectsPerIP: &source.MaxRedirectsPerIP,
+		MaxVisits:         &source.MaxVisits,
+		Description:       &source.Description,
+	}
+	return c.createUserShortLink(ctx, shortLinkInput, user)
 }
 
-func (c CreatorPersist) createShortLink(shortLinkInput entity.ShortLinkInput, user entity.User) (entity.ShortLink, error) {
-	isExist, err := c.shortLinkRepo.IsAliasExist(shortLinkInput.GetCustomAlias(""))
+// generateAlias retries with another key while the previous one is taken by
+// an existing short link or a reservation, until the retry budget runs out.
+func (c CreatorPersist) generateAlias(ctx context.Context) (string, error) {
+	for retries := 0; ; retries++ {
+		alias, err := c.nextAlias()
+		if err != nil {
+			return "", err
+		}
+
+		isTaken, err := c.isAliasTaken(ctx, normalizeAlias(alias))
+		if err != nil {
+			return "", err
+		}
+		if !isTaken {
+			c.recordAliasRetries(aliasTypeAuto, retries)
+			return alias, nil
+		}
+		if retries >= c.aliasRetryBudget {
+			c.recordAliasRetries(aliasTypeAuto, retries)
+			return "", ErrAliasRetryExhausted(fmt.Sprintf("all %d aliases tried are taken", retries+1))
+		}
+	}
+}
+
+// nextAlias skips the keys reserved by the routes. It always terminates
+// because the keys are unique and only a few of them are reserved.
+func (c CreatorPersist) nextAlias() (string, error) {
+	for {
+		key, err := c.keyGen.NewKey()
+		if err != nil {
+			return "", err
+		}
+		if !route.IsReserved(string(key)) {
+			return string(key), nil
+		}
+	}
+}
+
+func (c CreatorPersist) createShortLink(
+	ctx context.Context,
+	shortLinkInput entity.ShortLinkInput,
+	isCustomAlias bool,
+	createRelation func(shortLinkInput entity.ShortLinkInput, isCustomAlias bool) error,
+) (entity.ShortLink, error) {
+	isExist, err := c.shortLinkRepo.IsAliasExist(ctx, shortLinkInput.GetCustomAlias(""))
 	if err != nil {
 		return entity.ShortLink{}, err
 	}
 
+	if isCustomAlias {
+		// A taken custom alias counts as one retry since the user has to pick
+		// another one.
+		retries := 0
+		if isExist {
+			retries = 1
+		}
+		c.recordAliasRetries(aliasTypeCustom, retries)
+	}
+
 	if isExist {
 		return entity.ShortLink{}, ErrAliasExist("short link alias already exist")
 	}
@@ -116,18 +515,28 @@ func (c CreatorPersist) createShortLink(shortLinkInput entity.ShortLinkInput, us
 	now := c.timer.Now().UTC()
 	shortLinkInput.CreatedAt = &now
 
-	err = c.shortLinkRepo.CreateShortLink(shortLinkInput)
+	err = c.shortLinkRepo.CreateShortLink(ctx, shortLinkInput)
 	if err != nil {
 		return entity.ShortLink{}, err
 	}
 
-	err = c.userShortLinkRepo.CreateRelation(user, shortLinkInput)
+	err = c.createRelationWithRetry(ctx, shortLinkInput, isCustomAlias, createRelation)
+	if err != nil {
+		return entity.ShortLink{}, c.rollbackShortLink(ctx, shortLinkInput.GetCustomAlias(""), err)
+	}
 	return entity.ShortLink{
-		LongLink:  shortLinkInput.GetLongLink(""),
-		Alias:     shortLinkInput.GetCustomAlias(""),
-		ExpireAt:  shortLinkInput.ExpireAt,
-		CreatedAt: shortLinkInput.CreatedAt,
-	}, err
+		LongLink:          shortLinkInput.GetLongLink(""),
+		OriginalLongLink:  shortLinkInput.GetOriginalLongLink(""),
+		Alias:             shortLinkInput.GetCustomAlias(""),
+		ExpireAt:          shortLinkInput.ExpireAt,
+		CreatedAt:         shortLinkInput.CreatedAt,
+		TrackVisits:       shortLinkInput.GetTrackVisits(true),
+		PassthroughQuery:  shortLinkInput.GetPassthroughQuery(false),
+		PassthroughPath:   shortLinkInput.GetPassthroughPath(false),
+		MaxRedirectsPerIP: shortLinkInput.GetMaxRedirectsPerIP(0),
+		MaxVisits:         shortLinkInput.GetMaxVisits(0),
+		Description:       shortLinkInput.GetDescription(""),
+	}, nil
 }
 
 // NewCreatorPersist creates CreatorPersist
@@ -139,6 +548,23 @@ func NewCreatorPersist(
 	aliasValidator validator.CustomAlias,
 	timer timer.Timer,
 	riskDetector risk.Detector,
+	flaggedLinkRepo repository.FlaggedShortLink,
+	checks []Check,
+	uniqueness LongLinkUniqueness,
+	aliasQuota AliasQuota,
+	authorizer authorizer.Authorizer,
+	emailSender email.Sender,
+	dispatcher webhook.Dispatcher,
+	preferencesRepo repository.UserPreferences,
+	maintenanceMode maintenance.Mode,
+	metrics metrics.Metrics,
+	aliasRetryBudget int,
+	expirationPolicy ExpirationPolicy,
+	aliasSkeletonRepo repository.AliasSkeleton,
+	relationRetry RelationRetry,
+	reservationRepo repository.AliasReservation,
+	reservationTTL ReservationTTL,
+	chainedLink ChainedLink,
 ) CreatorPersist {
 	return CreatorPersist{
 		shortLinkRepo:     shortLinkRepo,
@@ -148,5 +574,22 @@ func NewCreatorPersist(
 		aliasValidator:    aliasValidator,
 		timer:             timer,
 		riskDetector:      riskDetector,
+		flaggedLinkRepo:   flaggedLinkRepo,
+		checks:            checks,
+		uniqueness:        uniqueness,
+		aliasQuota:        aliasQuota,
+		authorizer:        authorizer,
+		emailSender:       emailSender,
+		dispatcher:        dispatcher,
+		preferencesRepo:   preferencesRepo,
+		maintenanceMode:   maintenanceMode,
+		metrics:           metrics,
+		aliasRetryBudget:  aliasRetryBudget,
+		expirationPolicy:  expirationPolicy,
+		aliasSkeletonRepo: aliasSkeletonRepo,
+		relationRetry:     relationRetry,
+		reservationRepo:   reservationRepo,
+		reservationTTL:    reservationTTL,
+		chainedLink:       chainedLink,
 	}
 }
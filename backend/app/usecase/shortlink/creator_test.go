@@ -3,17 +3,26 @@
 package shortlink
 
 import (
+	"context"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/metrics"
 	"github.com/short-d/app/fw/timer"
 	"github.com/short-d/short/backend/app/entity"
 	"github.com/short-d/short/backend/app/fw/ptr"
+	"github.com/short-d/short/backend/app/fw/tenant"
+	"github.com/short-d/short/backend/app/usecase/authorizer"
+	"github.com/short-d/short/backend/app/usecase/authorizer/rbac/role"
+	"github.com/short-d/short/backend/app/usecase/email"
 	"github.com/short-d/short/backend/app/usecase/keygen"
+	"github.com/short-d/short/backend/app/usecase/maintenance"
 	"github.com/short-d/short/backend/app/usecase/repository"
 	"github.com/short-d/short/backend/app/usecase/risk"
 	"github.com/short-d/short/backend/app/usecase/validator"
+	"github.com/short-d/short/backend/app/usecase/webhook"
 )
 
 func TestShortLinkCreatorPersist_CreateShortLink(t *testing.T) {
@@ -31,6 +40,7 @@ func TestShortLinkCreatorPersist_CreateShortLink(t *testing.T) {
 		relationUsers      []entity.User
 		relationShortLinks []entity.ShortLink
 		blockedLongLinks   map[string]bool
+		deniedDomains      []string
 		isPublic           bool
 		// TODO(issue#803): Check error types in tests.
 		expHasErr         bool
@@ -99,10 +109,12 @@ func TestShortLinkCreatorPersist_CreateShortLink(t *testing.T) {
 			isPublic:  false,
 			expHasErr: false,
 			expectedShortLink: entity.ShortLink{
-				Alias:     "220uFicCJj",
-				LongLink:  "https://www.google.com",
-				ExpireAt:  &now,
-				CreatedAt: &utc,
+				Alias:            "220uFicCJj",
+				LongLink:         "https://www.google.com",
+				OriginalLongLink: "https://www.google.com",
+				ExpireAt:         &now,
+				CreatedAt:        &utc,
+				TrackVisits:      true,
 			},
 		},
 		{
@@ -119,9 +131,11 @@ func TestShortLinkCreatorPersist_CreateShortLink(t *testing.T) {
 			},
 			expHasErr: false,
 			expectedShortLink: entity.ShortLink{
-				Alias:     "test",
-				LongLink:  "https://www.google.com",
-				CreatedAt: &utc,
+				Alias:            "test",
+				LongLink:         "https://www.google.com",
+				OriginalLongLink: "https://www.google.com",
+				CreatedAt:        &utc,
+				TrackVisits:      true,
 			},
 		},
 		{
@@ -139,9 +153,48 @@ func TestShortLinkCreatorPersist_CreateShortLink(t *testing.T) {
 			},
 			expHasErr: false,
 			expectedShortLink: entity.ShortLink{
-				Alias:     "test",
-				LongLink:  "https://www.google.com",
-				CreatedAt: &utc,
+				Alias:            "test",
+				LongLink:         "https://www.google.com",
+				OriginalLongLink: "https://www.google.com",
+				CreatedAt:        &utc,
+				TrackVisits:      true,
+			},
+		},
+		{
+			name:       "skip generated alias reserved by route",
+			shortLinks: shortLinks{},
+			availableKeys: []keygen.Key{
+				"r",
+				"test",
+			},
+			user: entity.User{
+				Email: "alpha@example.com",
+			},
+			shortLinkArgs: entity.ShortLinkInput{
+				LongLink: ptr.String("https://www.google.com"),
+			},
+			expHasErr: false,
+			expectedShortLink: entity.ShortLink{
+				Alias:            "test",
+				LongLink:         "https://www.google.com",
+				OriginalLongLink: "https://www.google.com",
+				CreatedAt:        &utc,
+				TrackVisits:      true,
+			},
+		},
+		{
+			name:       "reject custom alias reserved by route",
+			shortLinks: shortLinks{},
+			user: entity.User{
+				Email: "alpha@example.com",
+			},
+			shortLinkArgs: entity.ShortLinkInput{
+				LongLink:    ptr.String("https://www.google.com"),
+				CustomAlias: ptr.String("api"),
+			},
+			expHasErr: true,
+			expectedShortLink: entity.ShortLink{
+				Alias: "api",
 			},
 		},
 		{
@@ -188,6 +241,21 @@ func TestShortLinkCreatorPersist_CreateShortLink(t *testing.T) {
 			isPublic:  false,
 			expHasErr: true,
 		},
+		{
+			name:       "reject long link on denied domain",
+			shortLinks: shortLinks{},
+			user: entity.User{
+				Email: "alpha@example.com",
+			},
+			shortLinkArgs: entity.ShortLinkInput{
+				CustomAlias: ptr.String("220uFicCJj"),
+				LongLink:    ptr.String("https://free.spam.example.com/win"),
+				ExpireAt:    &now,
+			},
+			deniedDomains: []string{"*.spam.example.com"},
+			isPublic:      false,
+			expHasErr:     true,
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -196,19 +264,22 @@ func TestShortLinkCreatorPersist_CreateShortLink(t *testing.T) {
 			t.Parallel()
 
 			blacklist := risk.NewBlackListFake(testCase.blockedLongLinks)
+			denylist := risk.NewDenylistFake(testCase.deniedDomains)
 			shortLinkRepo := repository.NewShortLinkFake(nil, testCase.shortLinks)
 			userShortLinkRepo := repository.NewUserShortLinkRepoFake(
 				testCase.relationUsers,
 				testCase.relationShortLinks,
 			)
 			keyFetcher := keygen.NewKeyFetcherFake(testCase.availableKeys)
-			keyGen, err := keygen.NewKeyGenerator(2, &keyFetcher)
+			keyGen, err := keygen.NewRemote(2, &keyFetcher)
 			assert.Equal(t, nil, err)
-			longLinkValidator := validator.NewLongLink()
+			longLinkValidator := validator.NewLongLink(nil, nil, validator.FragmentPreserve, validator.PlainHTTPAllow)
 			aliasValidator := validator.NewCustomAlias()
 			tm := timer.NewStub(now)
-			riskDetector := risk.NewDetector(blacklist)
+			riskDetector := risk.NewDetector(blacklist, denylist, risk.StrictThresholds)
+			flaggedLinkRepo := repository.NewFlaggedShortLinkFake(nil)
 
+			preferencesRepo := repository.NewUserPreferencesFake(nil)
 			creator := NewCreatorPersist(
 				&shortLinkRepo,
 				&userShortLinkRepo,
@@ -217,41 +288,1474 @@ func TestShortLinkCreatorPersist_CreateShortLink(t *testing.T) {
 				aliasValidator,
 				tm,
 				riskDetector,
+				&flaggedLinkRepo,
+				DefaultChecks,
+				LongLinkUniquenessNone,
+				AliasQuota{},
+				authorizer.Authorizer{},
+				email.NewSenderFake(nil),
+				webhook.NewDispatcherFake(),
+				&preferencesRepo,
+				maintenance.Mode{},
+				metrics.NewFake(),
+				0,
+				ExpirationPolicy{},
+				&repository.AliasSkeletonFake{},
+				RelationRetry{},
+				&repository.AliasReservationFake{},
+				ReservationTTL{},
+				ChainedLink{},
 			)
 
 			if !testCase.shouldAliasExist {
-				_, err = shortLinkRepo.GetShortLinkByAlias(testCase.shortLinkArgs.GetCustomAlias(""))
+				_, err = shortLinkRepo.GetShortLinkByAlias(context.Background(), testCase.shortLinkArgs.GetCustomAlias(""))
 				assert.NotEqual(t, nil, err)
 			}
 
 			if testCase.shortLinkArgs.CustomAlias != nil {
-				isExist, err := userShortLinkRepo.HasMapping(testCase.user, *testCase.shortLinkArgs.CustomAlias)
+				isExist, err := userShortLinkRepo.HasMapping(context.Background(), testCase.user, *testCase.shortLinkArgs.CustomAlias)
 				assert.Equal(t, nil, err)
 				assert.Equal(t, false, isExist)
 			}
 
-			shortLink, err := creator.CreateShortLink(testCase.shortLinkArgs, testCase.user, testCase.isPublic)
+			shortLink, err := creator.CreateShortLink(context.Background(), testCase.shortLinkArgs, testCase.user, testCase.isPublic)
 			if testCase.expHasErr {
 				assert.NotEqual(t, nil, err)
 
-				_, err = shortLinkRepo.GetShortLinkByAlias(testCase.expectedShortLink.Alias)
+				_, err = shortLinkRepo.GetShortLinkByAlias(context.Background(), testCase.expectedShortLink.Alias)
+				assert.NotEqual(t, nil, err)
+
+				isExist, err := userShortLinkRepo.HasMapping(context.Background(), testCase.user, testCase.expectedShortLink.Alias)
+				assert.Equal(t, nil, err)
+				assert.Equal(t, false, isExist)
+				return
+			}
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedShortLink, shortLink)
+
+			savedShortLink, err := shortLinkRepo.GetShortLinkByAlias(context.Background(), testCase.expectedShortLink.Alias)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedShortLink, savedShortLink)
+
+			isExist, err := userShortLinkRepo.HasMapping(context.Background(), testCase.user, testCase.expectedShortLink.Alias)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, true, isExist)
+		})
+	}
+}
+
+func TestShortLinkCreatorPersist_PreviewShortLink(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now().UTC()
+	existing := entity.ShortLink{
+		Alias:    "taken",
+		LongLink: "https://www.google.com",
+	}
+
+	testCases := []struct {
+		name             string
+		shortLinkInput   entity.ShortLinkInput
+		blockedLongLinks map[string]bool
+		hasErr           bool
+		expectedPreview  ShortLinkPreview
+	}{
+		{
+			name: "auto alias",
+			shortLinkInput: entity.ShortLinkInput{
+				LongLink: ptr.String("https://www.bing.com"),
+				ExpireAt: &now,
+			},
+			expectedPreview: ShortLinkPreview{
+				ShortLink: entity.ShortLink{
+					Alias:    "220uFicCJj",
+					LongLink: "https://www.bing.com",
+					ExpireAt: &now,
+				},
+				IsAutoAlias:      true,
+				IsAliasAvailable: true,
+			},
+		},
+		{
+			name: "available custom alias",
+			shortLinkInput: entity.ShortLinkInput{
+				LongLink:    ptr.String("https://www.bing.com"),
+				CustomAlias: ptr.String("bing"),
+			},
+			expectedPreview: ShortLinkPreview{
+				ShortLink: entity.ShortLink{
+					Alias:    "bing",
+					LongLink: "https://www.bing.com",
+				},
+				IsAliasAvailable: true,
+			},
+		},
+		{
+			name: "custom alias taken",
+			shortLinkInput: entity.ShortLinkInput{
+				LongLink:    ptr.String("https://www.bing.com"),
+				CustomAlias: ptr.String("taken"),
+			},
+			expectedPreview: ShortLinkPreview{
+				ShortLink: entity.ShortLink{
+					Alias:    "taken",
+					LongLink: "https://www.bing.com",
+				},
+				IsAliasAvailable: false,
+			},
+		},
+		{
+			name: "invalid long link",
+			shortLinkInput: entity.ShortLinkInput{
+				LongLink:    ptr.String("bing"),
+				CustomAlias: ptr.String("bing"),
+			},
+			hasErr: true,
+		},
+		{
+			name: "invalid custom alias",
+			shortLinkInput: entity.ShortLinkInput{
+				LongLink:    ptr.String("https://www.bing.com"),
+				CustomAlias: ptr.String("bing#top"),
+			},
+			hasErr: true,
+		},
+		{
+			name: "malicious long link",
+			shortLinkInput: entity.ShortLinkInput{
+				LongLink: ptr.String("https://malware.wicar.org"),
+			},
+			blockedLongLinks: map[string]bool{"https://malware.wicar.org": true},
+			hasErr:           true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			userShortLinkRepo := repository.NewUserShortLinkRepoFake(nil, nil)
+			shortLinkRepo := repository.NewShortLinkFake(&userShortLinkRepo, shortLinks{
+				existing.Alias: existing,
+			})
+			keyFetcher := keygen.NewKeyFetcherFake([]keygen.Key{"220uFicCJj", "yDOBcj5HIPbUAsw"})
+			keyGen, err := keygen.NewRemote(2, &keyFetcher)
+			assert.Equal(t, nil, err)
+			flaggedLinkRepo := repository.NewFlaggedShortLinkFake(nil)
+
+			preferencesRepo := repository.NewUserPreferencesFake(nil)
+			creator := NewCreatorPersist(
+				&shortLinkRepo,
+				&userShortLinkRepo,
+				keyGen,
+				validator.NewLongLink(nil, nil, validator.FragmentPreserve, validator.PlainHTTPAllow),
+				validator.NewCustomAlias(),
+				timer.NewStub(now),
+				risk.NewDetector(
+					risk.NewBlackListFake(testCase.blockedLongLinks),
+					risk.NewDenylistFake(nil),
+					risk.StrictThresholds,
+				),
+				&flaggedLinkRepo,
+				DefaultChecks,
+				LongLinkUniquenessNone,
+				AliasQuota{},
+				authorizer.Authorizer{},
+				email.NewSenderFake(nil),
+				webhook.NewDispatcherFake(),
+				&preferencesRepo,
+				maintenance.Mode{},
+				metrics.NewFake(),
+				0,
+				ExpirationPolicy{},
+				&repository.AliasSkeletonFake{},
+				RelationRetry{},
+				&repository.AliasReservationFake{},
+				ReservationTTL{},
+				ChainedLink{},
+			)
+
+			preview, err := creator.PreviewShortLink(context.Background(), testCase.shortLinkInput)
+			if testCase.hasErr {
 				assert.NotEqual(t, nil, err)
+			} else {
+				assert.Equal(t, nil, err)
+				assert.Equal(t, testCase.expectedPreview, preview)
+			}
 
-				isExist, err := userShortLinkRepo.HasMapping(testCase.user, testCase.expectedShortLink.Alias)
+			for _, alias := range []string{"220uFicCJj", "bing"} {
+				isExist, err := shortLinkRepo.IsAliasExist(context.Background(), alias)
 				assert.Equal(t, nil, err)
 				assert.Equal(t, false, isExist)
+			}
+			aliases, err := userShortLinkRepo.FindAliasesByUser(context.Background(), entity.User{})
+			assert.Equal(t, nil, err)
+			assert.Equal(t, 0, len(aliases))
+
+			// The previewed alias is still handed out to the next short link.
+			key, err := keyGen.NewKey()
+			assert.Equal(t, nil, err)
+			assert.Equal(t, keygen.Key("220uFicCJj"), key)
+		})
+	}
+}
+
+func TestShortLinkCreatorPersist_CloneShortLink(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	utc := now.UTC()
+	expireAt := now.Add(time.Hour)
+	owner := entity.User{ID: "alpha", Email: "alpha@example.com"}
+	source := entity.ShortLink{
+		Alias:    "source",
+		LongLink: "https://www.google.com",
+		ExpireAt: &expireAt,
+	}
+
+	testCases := []struct {
+		name              string
+		availableKeys     []keygen.Key
+		user              entity.User
+		newAlias          string
+		expHasErr         bool
+		expectedShortLink entity.ShortLink
+	}{
+		{
+			name:     "clone with custom alias",
+			user:     owner,
+			newAlias: "twitter",
+			expectedShortLink: entity.ShortLink{
+				Alias:            "twitter",
+				LongLink:         "https://www.google.com",
+				OriginalLongLink: "https://www.google.com",
+				ExpireAt:         &expireAt,
+				CreatedAt:        &utc,
+			},
+		},
+		{
+			name:          "clone with auto alias",
+			availableKeys: []keygen.Key{"test"},
+			user:          owner,
+			newAlias:      "",
+			expectedShortLink: entity.ShortLink{
+				Alias:            "test",
+				LongLink:         "https://www.google.com",
+				OriginalLongLink: "https://www.google.com",
+				ExpireAt:         &expireAt,
+				CreatedAt:        &utc,
+			},
+		},
+		{
+			name:      "new alias exists",
+			user:      owner,
+			newAlias:  "source",
+			expHasErr: true,
+		},
+		{
+			name:      "source not owned by user",
+			user:      entity.User{ID: "beta", Email: "beta@example.com"},
+			newAlias:  "twitter",
+			expHasErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			shortLinkRepo := repository.NewShortLinkFake(nil, shortLinks{
+				source.Alias: source,
+			})
+			userShortLinkRepo := repository.NewUserShortLinkRepoFake(
+				[]entity.User{owner},
+				[]entity.ShortLink{source},
+			)
+			keyFetcher := keygen.NewKeyFetcherFake(testCase.availableKeys)
+			keyGen, err := keygen.NewRemote(2, &keyFetcher)
+			assert.Equal(t, nil, err)
+			flaggedLinkRepo := repository.NewFlaggedShortLinkFake(nil)
+
+			preferencesRepo := repository.NewUserPreferencesFake(nil)
+			creator := NewCreatorPersist(
+				&shortLinkRepo,
+				&userShortLinkRepo,
+				keyGen,
+				validator.NewLongLink(nil, nil, validator.FragmentPreserve, validator.PlainHTTPAllow),
+				validator.NewCustomAlias(),
+				timer.NewStub(now),
+				risk.NewDetector(
+					risk.NewBlackListFake(nil),
+					risk.NewDenylistFake(nil),
+					risk.StrictThresholds,
+				),
+				&flaggedLinkRepo,
+				DefaultChecks,
+				LongLinkUniquenessNone,
+				AliasQuota{},
+				authorizer.Authorizer{},
+				email.NewSenderFake(nil),
+				webhook.NewDispatcherFake(),
+				&preferencesRepo,
+				maintenance.Mode{},
+				metrics.NewFake(),
+				0,
+				ExpirationPolicy{},
+				&repository.AliasSkeletonFake{},
+				RelationRetry{},
+				&repository.AliasReservationFake{},
+				ReservationTTL{},
+				ChainedLink{},
+			)
+
+			shortLink, err := creator.CloneShortLink(context.Background(), source.Alias, testCase.newAlias, testCase.user)
+			if testCase.expHasErr {
+				assert.NotEqual(t, nil, err)
+
+				isExist, err := shortLinkRepo.IsAliasExist(context.Background(), testCase.newAlias)
+				assert.Equal(t, nil, err)
+				assert.Equal(t, testCase.newAlias == source.Alias, isExist)
+
+				isExist, err = userShortLinkRepo.HasMapping(context.Background(), testCase.user, testCase.newAlias)
+				assert.Equal(t, nil, err)
+				assert.Equal(t, testCase.user == owner && testCase.newAlias == source.Alias, isExist)
 				return
 			}
 			assert.Equal(t, nil, err)
 			assert.Equal(t, testCase.expectedShortLink, shortLink)
 
-			savedShortLink, err := shortLinkRepo.GetShortLinkByAlias(testCase.expectedShortLink.Alias)
+			savedShortLink, err := shortLinkRepo.GetShortLinkByAlias(context.Background(), testCase.expectedShortLink.Alias)
 			assert.Equal(t, nil, err)
 			assert.Equal(t, testCase.expectedShortLink, savedShortLink)
 
-			isExist, err := userShortLinkRepo.HasMapping(testCase.user, testCase.expectedShortLink.Alias)
+			isExist, err := userShortLinkRepo.HasMapping(context.Background(), testCase.user, testCase.expectedShortLink.Alias)
 			assert.Equal(t, nil, err)
 			assert.Equal(t, true, isExist)
+
+			savedSource, err := shortLinkRepo.GetShortLinkByAlias(context.Background(), source.Alias)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, source, savedSource)
+		})
+	}
+}
+
+func TestShortLinkCreatorPersist_CreateShortLinkRiskThresholds(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	utc := now.UTC()
+	suspiciousLink := "http://suspicious.example.com/download"
+
+	testCases := []struct {
+		name                      string
+		thresholds                risk.Thresholds
+		expHasErr                 bool
+		expectedShortLink         entity.ShortLink
+		expectedFlaggedShortLinks []entity.FlaggedShortLink
+		sendErrs                  []error
+		expectedEmails            []email.Message
+	}{
+		{
+			name:       "suspicious link blocked under strict thresholds",
+			thresholds: risk.StrictThresholds,
+			expHasErr:  true,
+		},
+		{
+			name: "suspicious link allowed with flag under lenient thresholds",
+			thresholds: risk.Thresholds{
+				Block: risk.ScoreMalicious,
+				Warn:  risk.ScoreSuspicious,
+			},
+			expectedShortLink: entity.ShortLink{
+				Alias:            "220uFicCJj",
+				LongLink:         suspiciousLink,
+				OriginalLongLink: suspiciousLink,
+				CreatedAt:        &utc,
+				TrackVisits:      true,
+			},
+			expectedFlaggedShortLinks: []entity.FlaggedShortLink{
+				{
+					Alias:     "220uFicCJj",
+					RiskScore: int(risk.ScoreSuspicious),
+					FlaggedAt: utc,
+				},
+			},
+			expectedEmails: []email.Message{
+				{
+					To:      "alpha@example.com",
+					Subject: "Your short link 220uFicCJj is under review",
+					Body: "Your short link 220uFicCJj redirecting to " +
+						"http://suspicious.example.com/download looks suspicious " +
+						"to our safety checks, so it has been flagged for review. " +
+						"It keeps working until the review is done.\n",
+				},
+			},
+		},
+		{
+			name: "flagged link kept when owner can't be emailed",
+			thresholds: risk.Thresholds{
+				Block: risk.ScoreMalicious,
+				Warn:  risk.ScoreSuspicious,
+			},
+			sendErrs: []error{errors.New("connection refused")},
+			expectedShortLink: entity.ShortLink{
+				Alias:            "220uFicCJj",
+				LongLink:         suspiciousLink,
+				OriginalLongLink: suspiciousLink,
+				CreatedAt:        &utc,
+				TrackVisits:      true,
+			},
+			expectedFlaggedShortLinks: []entity.FlaggedShortLink{
+				{
+					Alias:     "220uFicCJj",
+					RiskScore: int(risk.ScoreSuspicious),
+					FlaggedAt: utc,
+				},
+			},
+		},
+		{
+			name: "suspicious link allowed without flag when warn tier is off",
+			thresholds: risk.Thresholds{
+				Block: risk.ScoreMalicious,
+				Warn:  risk.ScoreMalicious,
+			},
+			expectedShortLink: entity.ShortLink{
+				Alias:            "220uFicCJj",
+				LongLink:         suspiciousLink,
+				OriginalLongLink: suspiciousLink,
+				CreatedAt:        &utc,
+				TrackVisits:      true,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			blacklist := risk.NewScoredBlackListFake(map[string]risk.Score{
+				suspiciousLink: risk.ScoreSuspicious,
+			})
+			shortLinkRepo := repository.NewShortLinkFake(nil, shortLinks{})
+			userShortLinkRepo := repository.NewUserShortLinkRepoFake(nil, nil)
+			keyFetcher := keygen.NewKeyFetcherFake(nil)
+			keyGen, err := keygen.NewRemote(2, &keyFetcher)
+			assert.Equal(t, nil, err)
+			flaggedLinkRepo := repository.NewFlaggedShortLinkFake(nil)
+			emailSender := email.NewSenderFake(testCase.sendErrs)
+
+			preferencesRepo := repository.NewUserPreferencesFake(nil)
+			creator := NewCreatorPersist(
+				&shortLinkRepo,
+				&userShortLinkRepo,
+				keyGen,
+				validator.NewLongLink(nil, nil, validator.FragmentPreserve, validator.PlainHTTPAllow),
+				validator.NewCustomAlias(),
+				timer.NewStub(now),
+				risk.NewDetector(blacklist, risk.NewDenylistFake(nil), testCase.thresholds),
+				&flaggedLinkRepo,
+				DefaultChecks,
+				LongLinkUniquenessNone,
+				AliasQuota{},
+				authorizer.Authorizer{},
+				emailSender,
+				webhook.NewDispatcherFake(),
+				&preferencesRepo,
+				maintenance.Mode{},
+				metrics.NewFake(),
+				0,
+				ExpirationPolicy{},
+				&repository.AliasSkeletonFake{},
+				RelationRetry{},
+				&repository.AliasReservationFake{},
+				ReservationTTL{},
+				ChainedLink{},
+			)
+
+			user := entity.User{Email: "alpha@example.com"}
+			shortLinkArgs := entity.ShortLinkInput{
+				LongLink:    ptr.String(suspiciousLink),
+				CustomAlias: ptr.String("220uFicCJj"),
+			}
+			shortLink, err := creator.CreateShortLink(context.Background(), shortLinkArgs, user, false)
+			if testCase.expHasErr {
+				assert.NotEqual(t, nil, err)
+
+				_, err = shortLinkRepo.GetShortLinkByAlias(context.Background(), "220uFicCJj")
+				assert.NotEqual(t, nil, err)
+				assert.Equal(t, 0, len(flaggedLinkRepo.FlaggedShortLinks()))
+				assert.Equal(t, 0, len(emailSender.Messages()))
+				return
+			}
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedShortLink, shortLink)
+			assert.Equal(t, testCase.expectedFlaggedShortLinks, flaggedLinkRepo.FlaggedShortLinks())
+			if testCase.expectedEmails == nil {
+				assert.Equal(t, 0, len(emailSender.Messages()))
+				return
+			}
+			assert.Equal(t, testCase.expectedEmails, emailSender.Messages())
+		})
+	}
+}
+
+func TestShortLinkCreatorPersist_CreateShortLinkFlaggedWebhook(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	maliciousLink := "http://malware.example.com"
+	cleanLink := "https://www.google.com"
+
+	testCases := []struct {
+		name           string
+		longLink       string
+		user           *entity.User
+		expHasErr      bool
+		expectedEvents []webhook.Event
+	}{
+		{
+			name:      "flagged link submitted by user",
+			longLink:  maliciousLink,
+			user:      &entity.User{ID: "alpha", Email: "alpha@example.com"},
+			expHasErr: true,
+			expectedEvents: []webhook.Event{
+				{
+					Type:       webhook.LinkFlagged,
+					OccurredAt: now.UTC(),
+					Data: webhook.LinkFlaggedData{
+						LongLink: maliciousLink,
+						UserID:   "alpha",
+					},
+				},
+			},
+		},
+		{
+			name:      "flagged link submitted by guest",
+			longLink:  maliciousLink,
+			expHasErr: true,
+			expectedEvents: []webhook.Event{
+				{
+					Type:       webhook.LinkFlagged,
+					OccurredAt: now.UTC(),
+					Data: webhook.LinkFlaggedData{
+						LongLink: maliciousLink,
+					},
+				},
+			},
+		},
+		{
+			name:           "clean link",
+			longLink:       cleanLink,
+			user:           &entity.User{ID: "alpha", Email: "alpha@example.com"},
+			expectedEvents: []webhook.Event{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			blacklist := risk.NewScoredBlackListFake(map[string]risk.Score{
+				maliciousLink: risk.ScoreMalicious,
+			})
+			shortLinkRepo := repository.NewShortLinkFake(nil, shortLinks{})
+			userShortLinkRepo := repository.NewUserShortLinkRepoFake(nil, nil)
+			keyFetcher := keygen.NewKeyFetcherFake(nil)
+			keyGen, err := keygen.NewRemote(2, &keyFetcher)
+			assert.Equal(t, nil, err)
+			flaggedLinkRepo := repository.NewFlaggedShortLinkFake(nil)
+			dispatcher := webhook.NewDispatcherFake()
+
+			preferencesRepo := repository.NewUserPreferencesFake(nil)
+			creator := NewCreatorPersist(
+				&shortLinkRepo,
+				&userShortLinkRepo,
+				keyGen,
+				validator.NewLongLink(nil, nil, validator.FragmentPreserve, validator.PlainHTTPAllow),
+				validator.NewCustomAlias(),
+				timer.NewStub(now),
+				risk.NewDetector(blacklist, risk.NewDenylistFake(nil), risk.StrictThresholds),
+				&flaggedLinkRepo,
+				DefaultChecks,
+				LongLinkUniquenessNone,
+				AliasQuota{},
+				authorizer.Authorizer{},
+				email.NewSenderFake(nil),
+				dispatcher,
+				&preferencesRepo,
+				maintenance.Mode{},
+				metrics.NewFake(),
+				0,
+				ExpirationPolicy{},
+				&repository.AliasSkeletonFake{},
+				RelationRetry{},
+				&repository.AliasReservationFake{},
+				ReservationTTL{},
+				ChainedLink{},
+			)
+
+			shortLinkArgs := entity.ShortLinkInput{
+				LongLink:    ptr.String(testCase.longLink),
+				CustomAlias: ptr.String("220uFicCJj"),
+			}
+			if testCase.user == nil {
+				_, err = creator.CreateGuestShortLink(context.Background(), shortLinkArgs, "session")
+			} else {
+				_, err = creator.CreateShortLink(context.Background(), shortLinkArgs, *testCase.user, false)
+			}
+			if testCase.expHasErr {
+				assert.Equal(t, ErrMaliciousLongLink(testCase.longLink), err)
+			} else {
+				assert.Equal(t, nil, err)
+			}
+			assert.Equal(t, testCase.expectedEvents, dispatcher.Events())
+		})
+	}
+}
+
+// cancelingBlackList cancels the request while the long link is being
+// assessed, as if the client disconnected during the external call.
+type cancelingBlackList struct {
+	cancel context.CancelFunc
+}
+
+func (c cancelingBlackList) GetURLScore(url string) (risk.Score, error) {
+	c.cancel()
+	return risk.ScoreSafe, nil
+}
+
+func TestShortLinkCreatorPersist_CreateShortLinkSelfReferential(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name        string
+		longLink    string
+		expectedErr error
+	}{
+		{
+			name:     "external long link",
+			longLink: "https://www.google.com/r/google",
+		},
+		{
+			name:        "long link on short domain",
+			longLink:    "https://short-d.com/r/google",
+			expectedErr: ErrSelfReferentialLink("https://short-d.com/r/google"),
+		},
+		{
+			name:        "long link on custom domain",
+			longLink:    "https://go.acme.com/r/google",
+			expectedErr: ErrSelfReferentialLink("https://go.acme.com/r/google"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			shortLinkRepo := repository.NewShortLinkFake(nil, shortLinks{})
+			userShortLinkRepo := repository.NewUserShortLinkRepoFake(nil, nil)
+			keyFetcher := keygen.NewKeyFetcherFake(nil)
+			keyGen, err := keygen.NewRemote(2, &keyFetcher)
+			assert.Equal(t, nil, err)
+			flaggedLinkRepo := repository.NewFlaggedShortLinkFake(nil)
+
+			preferencesRepo := repository.NewUserPreferencesFake(nil)
+			creator := NewCreatorPersist(
+				&shortLinkRepo,
+				&userShortLinkRepo,
+				keyGen,
+				validator.NewLongLink(nil, []string{"short-d.com", "go.acme.com"}, validator.FragmentPreserve, validator.PlainHTTPAllow),
+				validator.NewCustomAlias(),
+				timer.NewStub(time.Now()),
+				risk.NewDetector(
+					risk.NewBlackListFake(nil),
+					risk.NewDenylistFake(nil),
+					risk.StrictThresholds,
+				),
+				&flaggedLinkRepo,
+				DefaultChecks,
+				LongLinkUniquenessNone,
+				AliasQuota{},
+				authorizer.Authorizer{},
+				email.NewSenderFake(nil),
+				webhook.NewDispatcherFake(),
+				&preferencesRepo,
+				maintenance.Mode{},
+				metrics.NewFake(),
+				0,
+				ExpirationPolicy{},
+				&repository.AliasSkeletonFake{},
+				RelationRetry{},
+				&repository.AliasReservationFake{},
+				ReservationTTL{},
+				ChainedLink{},
+			)
+
+			user := entity.User{ID: "alpha", Email: "alpha@example.com"}
+			shortLinkInput := entity.ShortLinkInput{
+				LongLink:    ptr.String(testCase.longLink),
+				CustomAlias: ptr.String("google"),
+			}
+			_, err = creator.CreateShortLink(context.Background(), shortLinkInput, user, false)
+			assert.Equal(t, testCase.expectedErr, err)
+
+			isExist, err := shortLinkRepo.IsAliasExist(context.Background(), "google")
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedErr == nil, isExist)
+		})
+	}
+}
+
+func TestShortLinkCreatorPersist_CreateShortLinkFragment(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name             string
+		fragment         validator.Fragment
+		longLink         string
+		expectedLongLink string
+	}{
+		{
+			name:             "preserve fragment",
+			fragment:         validator.FragmentPreserve,
+			longLink:         "https://example.com/app?tab=1#/settings",
+			expectedLongLink: "https://example.com/app?tab=1#/settings",
+		},
+		{
+			name:             "strip fragment",
+			fragment:         validator.FragmentStrip,
+			longLink:         "https://example.com/app?tab=1#/settings",
+			expectedLongLink: "https://example.com/app?tab=1",
+		},
+		{
+			name:             "strip long link without fragment",
+			fragment:         validator.FragmentStrip,
+			longLink:         "https://example.com/app?tab=1",
+			expectedLongLink: "https://example.com/app?tab=1",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			shortLinkRepo := repository.NewShortLinkFake(nil, shortLinks{})
+			userShortLinkRepo := repository.NewUserShortLinkRepoFake(nil, nil)
+			keyFetcher := keygen.NewKeyFetcherFake(nil)
+			keyGen, err := keygen.NewRemote(2, &keyFetcher)
+			assert.Equal(t, nil, err)
+			flaggedLinkRepo := repository.NewFlaggedShortLinkFake(nil)
+
+			preferencesRepo := repository.NewUserPreferencesFake(nil)
+			creator := NewCreatorPersist(
+				&shortLinkRepo,
+				&userShortLinkRepo,
+				keyGen,
+				validator.NewLongLink(nil, nil, testCase.fragment, validator.PlainHTTPAllow),
+				validator.NewCustomAlias(),
+				timer.NewStub(time.Now()),
+				risk.NewDetector(
+					risk.NewBlackListFake(nil),
+					risk.NewDenylistFake(nil),
+					risk.StrictThresholds,
+				),
+				&flaggedLinkRepo,
+				DefaultChecks,
+				LongLinkUniquenessNone,
+				AliasQuota{},
+				authorizer.Authorizer{},
+				email.NewSenderFake(nil),
+				webhook.NewDispatcherFake(),
+				&preferencesRepo,
+				maintenance.Mode{},
+				metrics.NewFake(),
+				0,
+				ExpirationPolicy{},
+				&repository.AliasSkeletonFake{},
+				RelationRetry{},
+				&repository.AliasReservationFake{},
+				ReservationTTL{},
+				ChainedLink{},
+			)
+
+			user := entity.User{ID: "alpha", Email: "alpha@example.com"}
+			shortLinkInput := entity.ShortLinkInput{
+				LongLink:    ptr.String(testCase.longLink),
+				CustomAlias: ptr.String("app"),
+			}
+			shortLink, err := creator.CreateShortLink(context.Background(), shortLinkInput, user, false)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedLongLink, shortLink.LongLink)
+
+			savedShortLink, err := shortLinkRepo.GetShortLinkByAlias(context.Background(), "app")
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedLongLink, savedShortLink.LongLink)
+		})
+	}
+}
+
+func TestShortLinkCreatorPersist_CreateShortLinkOriginalLongLink(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name                     string
+		fragment                 validator.Fragment
+		plainHTTP                validator.PlainHTTP
+		uniqueness               LongLinkUniqueness
+		longLink                 string
+		expectedLongLink         string
+		expectedOriginalLongLink string
+	}{
+		{
+			name:                     "canonical long link unchanged",
+			fragment:                 validator.FragmentPreserve,
+			plainHTTP:                validator.PlainHTTPAllow,
+			uniqueness:               LongLinkUniquenessNone,
+			longLink:                 "http://Example.com/docs#intro",
+			expectedLongLink:         "http://Example.com/docs#intro",
+			expectedOriginalLongLink: "http://Example.com/docs#intro",
+		},
+		{
+			name:                     "strip fragment and upgrade plain HTTP",
+			fragment:                 validator.FragmentStrip,
+			plainHTTP:                validator.PlainHTTPUpgrade,
+			uniqueness:               LongLinkUniquenessNone,
+			longLink:                 "http://Example.com/docs#intro",
+			expectedLongLink:         "https://Example.com/docs",
+			expectedOriginalLongLink: "http://Example.com/docs#intro",
+		},
+		{
+			name:                     "normalize globally unique long link",
+			fragment:                 validator.FragmentStrip,
+			plainHTTP:                validator.PlainHTTPUpgrade,
+			uniqueness:               LongLinkUniquenessGlobal,
+			longLink:                 "http://Example.com:443#intro",
+			expectedLongLink:         "https://example.com/",
+			expectedOriginalLongLink: "http://Example.com:443#intro",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			shortLinkRepo := repository.NewShortLinkFake(nil, shortLinks{})
+			userShortLinkRepo := repository.NewUserShortLinkRepoFake(nil, nil)
+			keyFetcher := keygen.NewKeyFetcherFake(nil)
+			keyGen, err := keygen.NewRemote(2, &keyFetcher)
+			assert.Equal(t, nil, err)
+			flaggedLinkRepo := repository.NewFlaggedShortLinkFake(nil)
+
+			preferencesRepo := repository.NewUserPreferencesFake(nil)
+			creator := NewCreatorPersist(
+				&shortLinkRepo,
+				&userShortLinkRepo,
+				keyGen,
+				validator.NewLongLink(nil, nil, testCase.fragment, testCase.plainHTTP),
+				validator.NewCustomAlias(),
+				timer.NewStub(time.Now()),
+				risk.NewDetector(
+					risk.NewBlackListFake(nil),
+					risk.NewDenylistFake(nil),
+					risk.StrictThresholds,
+				),
+				&flaggedLinkRepo,
+				DefaultChecks,
+				testCase.uniqueness,
+				AliasQuota{},
+				authorizer.Authorizer{},
+				email.NewSenderFake(nil),
+				webhook.NewDispatcherFake(),
+				&preferencesRepo,
+				maintenance.Mode{},
+				metrics.NewFake(),
+				0,
+				ExpirationPolicy{},
+				&repository.AliasSkeletonFake{},
+				RelationRetry{},
+				&repository.AliasReservationFake{},
+				ReservationTTL{},
+				ChainedLink{},
+			)
+
+			user := entity.User{ID: "alpha", Email: "alpha@example.com"}
+			shortLinkInput := entity.ShortLinkInput{
+				LongLink:    ptr.String(testCase.longLink),
+				CustomAlias: ptr.String("docs"),
+			}
+			shortLink, err := creator.CreateShortLink(context.Background(), shortLinkInput, user, false)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedLongLink, shortLink.LongLink)
+			assert.Equal(t, testCase.expectedOriginalLongLink, shortLink.OriginalLongLink)
+
+			savedShortLink, err := shortLinkRepo.GetShortLinkByAlias(context.Background(), "docs")
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedLongLink, savedShortLink.LongLink)
+			assert.Equal(t, testCase.expectedOriginalLongLink, savedShortLink.OriginalLongLink)
+		})
+	}
+}
+
+func TestShortLinkCreatorPersist_CreateShortLinkReadOnly(t *testing.T) {
+	t.Parallel()
+
+	user := entity.User{ID: "alpha", Email: "alpha@example.com"}
+	userShortLinkRepo := repository.NewUserShortLinkRepoFake([]entity.User{user}, []entity.ShortLink{
+		{Alias: "google", LongLink: "https://www.google.com"},
+	})
+	shortLinkRepo := repository.NewShortLinkFake(&userShortLinkRepo, shortLinks{
+		"google": {Alias: "google", LongLink: "https://www.google.com"},
+	})
+	keyFetcher := keygen.NewKeyFetcherFake([]keygen.Key{"abc"})
+	keyGen, err := keygen.NewRemote(2, &keyFetcher)
+	assert.Equal(t, nil, err)
+	flaggedLinkRepo := repository.NewFlaggedShortLinkFake(nil)
+
+	preferencesRepo := repository.NewUserPreferencesFake(nil)
+	creator := NewCreatorPersist(
+		&shortLinkRepo,
+		&userShortLinkRepo,
+		keyGen,
+		validator.NewLongLink(nil, nil, validator.FragmentPreserve, validator.PlainHTTPAllow),
+		validator.NewCustomAlias(),
+		timer.NewStub(time.Now()),
+		risk.NewDetector(
+			risk.NewBlackListFake(nil),
+			risk.NewDenylistFake(nil),
+			risk.StrictThresholds,
+		),
+		&flaggedLinkRepo,
+		DefaultChecks,
+		LongLinkUniquenessNone,
+		AliasQuota{},
+		authorizer.Authorizer{},
+		email.NewSenderFake(nil),
+		webhook.NewDispatcherFake(),
+		&preferencesRepo,
+		maintenance.NewMode(true),
+		metrics.NewFake(),
+		0,
+		ExpirationPolicy{},
+		&repository.AliasSkeletonFake{},
+		RelationRetry{},
+		&repository.AliasReservationFake{},
+		ReservationTTL{},
+		ChainedLink{},
+	)
+
+	ctx := context.Background()
+	shortLinkInput := entity.ShortLinkInput{
+		LongLink:    ptr.String("https://www.facebook.com"),
+		CustomAlias: ptr.String("facebook"),
+	}
+	_, err = creator.CreateShortLink(ctx, shortLinkInput, user, false)
+	assert.Equal(t, ErrServiceReadOnly("create short link"), err)
+
+	_, err = creator.CreateGuestShortLink(ctx, shortLinkInput, "session")
+	assert.Equal(t, ErrServiceReadOnly("create short link"), err)
+
+	_, err = creator.CloneShortLink(ctx, "google", "facebook", user)
+	assert.Equal(t, ErrServiceReadOnly("create short link"), err)
+
+	isExist, err := shortLinkRepo.IsAliasExist(ctx, "facebook")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, isExist)
+}
+
+func TestShortLinkCreatorPersist_CreateShortLinkCanceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	shortLinkRepo := repository.NewShortLinkFake(nil, shortLinks{})
+	userShortLinkRepo := repository.NewUserShortLinkRepoFake(nil, nil)
+	keyFetcher := keygen.NewKeyFetcherFake(nil)
+	keyGen, err := keygen.NewRemote(2, &keyFetcher)
+	assert.Equal(t, nil, err)
+	flaggedLinkRepo := repository.NewFlaggedShortLinkFake(nil)
+
+	preferencesRepo := repository.NewUserPreferencesFake(nil)
+	creator := NewCreatorPersist(
+		&shortLinkRepo,
+		&userShortLinkRepo,
+		keyGen,
+		validator.NewLongLink(nil, nil, validator.FragmentPreserve, validator.PlainHTTPAllow),
+		validator.NewCustomAlias(),
+		timer.NewStub(time.Now()),
+		risk.NewDetector(
+			cancelingBlackList{cancel: cancel},
+			risk.NewDenylistFake(nil),
+			risk.StrictThresholds,
+		),
+		&flaggedLinkRepo,
+		DefaultChecks,
+		LongLinkUniquenessNone,
+		AliasQuota{},
+		authorizer.Authorizer{},
+		email.NewSenderFake(nil),
+		webhook.NewDispatcherFake(),
+		&preferencesRepo,
+		maintenance.Mode{},
+		metrics.NewFake(),
+		0,
+		ExpirationPolicy{},
+		&repository.AliasSkeletonFake{},
+		RelationRetry{},
+		&repository.AliasReservationFake{},
+		ReservationTTL{},
+		ChainedLink{},
+	)
+
+	user := entity.User{Email: "alpha@example.com"}
+	shortLinkArgs := entity.ShortLinkInput{
+		LongLink:    ptr.String("https://www.google.com"),
+		CustomAlias: ptr.String("220uFicCJj"),
+	}
+	_, err = creator.CreateShortLink(ctx, shortLinkArgs, user, false)
+	assert.Equal(t, context.Canceled, err)
+
+	_, err = shortLinkRepo.GetShortLinkByAlias(context.Background(), "220uFicCJj")
+	assert.NotEqual(t, nil, err)
+
+	isExist, err := userShortLinkRepo.HasMapping(context.Background(), user, "220uFicCJj")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, isExist)
+}
+
+func TestShortLinkCreatorPersist_CreateShortLinkTenants(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now().UTC()
+	userShortLinkRepo := repository.NewUserShortLinkRepoFake(nil, nil)
+	shortLinkRepo := repository.NewShortLinkFake(&userShortLinkRepo, shortLinks{})
+	keyFetcher := keygen.NewKeyFetcherFake(nil)
+	keyGen, err := keygen.NewRemote(2, &keyFetcher)
+	assert.Equal(t, nil, err)
+	flaggedLinkRepo := repository.NewFlaggedShortLinkFake(nil)
+
+	preferencesRepo := repository.NewUserPreferencesFake(nil)
+	creator := NewCreatorPersist(
+		&shortLinkRepo,
+		&userShortLinkRepo,
+		keyGen,
+		validator.NewLongLink(nil, nil, validator.FragmentPreserve, validator.PlainHTTPAllow),
+		validator.NewCustomAlias(),
+		timer.NewStub(now),
+		risk.NewDetector(
+			risk.NewBlackListFake(nil),
+			risk.NewDenylistFake(nil),
+			risk.StrictThresholds,
+		),
+		&flaggedLinkRepo,
+		DefaultChecks,
+		LongLinkUniquenessNone,
+		AliasQuota{},
+		authorizer.Authorizer{},
+		email.NewSenderFake(nil),
+		webhook.NewDispatcherFake(),
+		&preferencesRepo,
+		maintenance.Mode{},
+		metrics.NewFake(),
+		0,
+		ExpirationPolicy{},
+		&repository.AliasSkeletonFake{},
+		RelationRetry{},
+		&repository.AliasReservationFake{},
+		ReservationTTL{},
+		ChainedLink{},
+	)
+	retriever := NewRetrieverPersist(&shortLinkRepo, &userShortLinkRepo, 0)
+
+	acmeUser := entity.User{TenantID: "acme", ID: "alpha", Email: "alpha@acme.com"}
+	acmeCtx := tenant.NewContext(context.Background(), acmeUser.TenantID)
+	globexUser := entity.User{TenantID: "globex", ID: "beta", Email: "beta@globex.com"}
+	globexCtx := tenant.NewContext(context.Background(), globexUser.TenantID)
+
+	_, err = creator.CreateShortLink(acmeCtx, entity.ShortLinkInput{
+		LongLink:    ptr.String("https://www.acme.com"),
+		CustomAlias: ptr.String("promo"),
+	}, acmeUser, false)
+	assert.Equal(t, nil, err)
+
+	_, err = creator.CreateShortLink(globexCtx, entity.ShortLinkInput{
+		LongLink:    ptr.String("https://www.globex.com"),
+		CustomAlias: ptr.String("promo"),
+	}, globexUser, false)
+	assert.Equal(t, nil, err)
+
+	_, err = creator.CreateShortLink(acmeCtx, entity.ShortLinkInput{
+		LongLink:    ptr.String("https://www.google.com"),
+		CustomAlias: ptr.String("promo"),
+	}, acmeUser, false)
+	var errAliasExist ErrAliasExist
+	assert.Equal(t, true, errors.As(err, &errAliasExist))
+
+	acmeShortLink, err := retriever.GetShortLink(acmeCtx, "promo", &now)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "acme", acmeShortLink.TenantID)
+	assert.Equal(t, "https://www.acme.com", acmeShortLink.LongLink)
+
+	globexShortLink, err := retriever.GetShortLink(globexCtx, "promo", &now)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "globex", globexShortLink.TenantID)
+	assert.Equal(t, "https://www.globex.com", globexShortLink.LongLink)
+
+	_, err = retriever.GetShortLink(context.Background(), "promo", &now)
+	assert.NotEqual(t, nil, err)
+
+	acmeShortLinks, err := retriever.GetShortLinksByUser(acmeCtx, acmeUser)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(acmeShortLinks))
+	assert.Equal(t, "https://www.acme.com", acmeShortLinks[0].LongLink)
+
+	crossTenantShortLinks, err := retriever.GetShortLinksByUser(globexCtx, acmeUser)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(crossTenantShortLinks))
+
+	isOwner, err := retriever.IsOwner(globexCtx, "promo", acmeUser)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, isOwner)
+}
+
+func TestShortLinkCreatorPersist_CreateShortLinkWithPreferences(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 6, 1, 8, 0, 0, 0, time.UTC)
+	week := 7 * 24 * time.Hour
+	month := 30 * 24 * time.Hour
+	expireAt := now.Add(time.Hour)
+	defaultExpireAt := now.Add(week)
+	policyExpireAt := now.Add(month)
+
+	testCases := []struct {
+		name              string
+		preferences       entity.UserPreferences
+		roles             []role.Role
+		shortLinkInput    entity.ShortLinkInput
+		isGuest           bool
+		expectedShortLink entity.ShortLink
+	}{
+		{
+			name: "preferences fill unset fields",
+			preferences: entity.UserPreferences{
+				DefaultExpireAfter: &week,
+				DefaultTrackVisits: ptr.Bool(false),
+			},
+			shortLinkInput: entity.ShortLinkInput{
+				LongLink:    ptr.String("https://www.google.com"),
+				CustomAlias: ptr.String("google"),
+			},
+			expectedShortLink: entity.ShortLink{
+				Alias:            "google",
+				LongLink:         "https://www.google.com",
+				OriginalLongLink: "https://www.google.com",
+				ExpireAt:         &defaultExpireAt,
+				CreatedAt:        &now,
+				TrackVisits:      false,
+			},
+		},
+		{
+			name: "explicit fields override preferences",
+			preferences: entity.UserPreferences{
+				DefaultExpireAfter: &week,
+				DefaultTrackVisits: ptr.Bool(false),
+			},
+			shortLinkInput: entity.ShortLinkInput{
+				LongLink:    ptr.String("https://www.google.com"),
+				CustomAlias: ptr.String("google"),
+				ExpireAt:    &expireAt,
+				TrackVisits: ptr.Bool(true),
+			},
+			expectedShortLink: entity.ShortLink{
+				Alias:            "google",
+				LongLink:         "https://www.google.com",
+				OriginalLongLink: "https://www.google.com",
+				ExpireAt:         &expireAt,
+				CreatedAt:        &now,
+				TrackVisits:      true,
+			},
+		},
+		{
+			name:        "no preferences",
+			preferences: entity.UserPreferences{},
+			shortLinkInput: entity.ShortLinkInput{
+				LongLink:    ptr.String("https://www.google.com"),
+				CustomAlias: ptr.String("google"),
+			},
+			expectedShortLink: entity.ShortLink{
+				Alias:            "google",
+				LongLink:         "https://www.google.com",
+				OriginalLongLink: "https://www.google.com",
+				CreatedAt:        &now,
+				TrackVisits:      true,
+			},
+		},
+		{
+			name:        "expiration policy applies without preferences",
+			preferences: entity.UserPreferences{},
+			roles:       []role.Role{role.Basic},
+			shortLinkInput: entity.ShortLinkInput{
+				LongLink:    ptr.String("https://www.google.com"),
+				CustomAlias: ptr.String("google"),
+			},
+			expectedShortLink: entity.ShortLink{
+				Alias:            "google",
+				LongLink:         "https://www.google.com",
+				OriginalLongLink: "https://www.google.com",
+				ExpireAt:         &policyExpireAt,
+				CreatedAt:        &now,
+				TrackVisits:      true,
+			},
+		},
+		{
+			name: "preferences override expiration policy",
+			preferences: entity.UserPreferences{
+				DefaultExpireAfter: &week,
+			},
+			roles: []role.Role{role.Basic},
+			shortLinkInput: entity.ShortLinkInput{
+				LongLink:    ptr.String("https://www.google.com"),
+				CustomAlias: ptr.String("google"),
+			},
+			expectedShortLink: entity.ShortLink{
+				Alias:            "google",
+				LongLink:         "https://www.google.com",
+				OriginalLongLink: "https://www.google.com",
+				ExpireAt:         &defaultExpireAt,
+				CreatedAt:        &now,
+				TrackVisits:      true,
+			},
+		},
+		{
+			name: "guest ignores preferences",
+			preferences: entity.UserPreferences{
+				DefaultExpireAfter: &week,
+				DefaultTrackVisits: ptr.Bool(false),
+			},
+			shortLinkInput: entity.ShortLinkInput{
+				LongLink:    ptr.String("https://www.google.com"),
+				CustomAlias: ptr.String("google"),
+			},
+			roles:   []role.Role{role.Basic},
+			isGuest: true,
+			expectedShortLink: entity.ShortLink{
+				Alias:            "google",
+				LongLink:         "https://www.google.com",
+				OriginalLongLink: "https://www.google.com",
+				CreatedAt:        &now,
+				TrackVisits:      true,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			user := entity.User{ID: "alpha", Email: "alpha@example.com"}
+			shortLinkRepo := repository.NewShortLinkFake(nil, shortLinks{})
+			userShortLinkRepo := repository.NewUserShortLinkRepoFake(nil, nil)
+			keyFetcher := keygen.NewKeyFetcherFake(nil)
+			keyGen, err := keygen.NewRemote(2, &keyFetcher)
+			assert.Equal(t, nil, err)
+			flaggedLinkRepo := repository.NewFlaggedShortLinkFake(nil)
+			preferencesRepo := repository.NewUserPreferencesFake(map[string]entity.UserPreferences{
+				user.ID: testCase.preferences,
+			})
+			userRoleRepo := repository.NewUserRoleFake(map[string][]role.Role{
+				user.ID: testCase.roles,
+			})
+			expirationPolicy := NewExpirationPolicy(userRoleRepo, 0, map[role.Role]time.Duration{
+				role.Basic: month,
+			})
+
+			creator := NewCreatorPersist(
+				&shortLinkRepo,
+				&userShortLinkRepo,
+				keyGen,
+				validator.NewLongLink(nil, nil, validator.FragmentPreserve, validator.PlainHTTPAllow),
+				validator.NewCustomAlias(),
+				timer.NewStub(now),
+				risk.NewDetector(
+					risk.NewBlackListFake(nil),
+					risk.NewDenylistFake(nil),
+					risk.StrictThresholds,
+				),
+				&flaggedLinkRepo,
+				DefaultChecks,
+				LongLinkUniquenessNone,
+				AliasQuota{},
+				authorizer.Authorizer{},
+				email.NewSenderFake(nil),
+				webhook.NewDispatcherFake(),
+				&preferencesRepo,
+				maintenance.Mode{},
+				metrics.NewFake(),
+				0,
+				expirationPolicy,
+				&repository.AliasSkeletonFake{},
+				RelationRetry{},
+				&repository.AliasReservationFake{},
+				ReservationTTL{},
+				ChainedLink{},
+			)
+
+			var shortLink entity.ShortLink
+			if testCase.isGuest {
+				shortLink, err = creator.CreateGuestShortLink(context.Background(), testCase.shortLinkInput, "session")
+			} else {
+				shortLink, err = creator.CreateShortLink(context.Background(), testCase.shortLinkInput, user, false)
+			}
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedShortLink, shortLink)
+
+			savedShortLink, err := shortLinkRepo.GetShortLinkByAlias(context.Background(), "google")
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedShortLink.ExpireAt, savedShortLink.ExpireAt)
+			assert.Equal(t, testCase.expectedShortLink.TrackVisits, savedShortLink.TrackVisits)
+		})
+	}
+}
+
+func TestShortLinkCreatorPersist_CreateShortLinkOneTime(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 5, 1, 10, 30, 0, 0, time.UTC)
+	tomorrow := now.Add(24 * time.Hour)
+	inAnHour := now.Add(OneTimeLifetime)
+
+	testCases := []struct {
+		name              string
+		shortLinkInput    entity.ShortLinkInput
+		uniqueness        LongLinkUniqueness
+		expectedShortLink entity.ShortLink
+	}{
+		{
+			name: "one-time short link expires shortly",
+			shortLinkInput: entity.ShortLinkInput{
+				LongLink:    ptr.String("https://www.google.com"),
+				CustomAlias: ptr.String("secret"),
+				OneTime:     ptr.Bool(true),
+			},
+			uniqueness: LongLinkUniquenessNone,
+			expectedShortLink: entity.ShortLink{
+				Alias:     "secret",
+				LongLink:  "https://www.google.com",
+				ExpireAt:  &inAnHour,
+				MaxVisits: 1,
+			},
+		},
+		{
+			name: "one-time short link with expiration time",
+			shortLinkInput: entity.ShortLinkInput{
+				LongLink:    ptr.String("https://www.google.com"),
+				CustomAlias: ptr.String("secret"),
+				ExpireAt:    &tomorrow,
+				OneTime:     ptr.Bool(true),
+			},
+			uniqueness: LongLinkUniquenessNone,
+			expectedShortLink: entity.ShortLink{
+				Alias:     "secret",
+				LongLink:  "https://www.google.com",
+				ExpireAt:  &tomorrow,
+				MaxVisits: 1,
+			},
+		},
+		{
+			name: "one-time short link not shared with globally unique long link",
+			shortLinkInput: entity.ShortLinkInput{
+				LongLink:    ptr.String("https://www.google.com"),
+				CustomAlias: ptr.String("secret"),
+				OneTime:     ptr.Bool(true),
+			},
+			uniqueness: LongLinkUniquenessGlobal,
+			expectedShortLink: entity.ShortLink{
+				Alias:     "secret",
+				LongLink:  "https://www.google.com",
+				ExpireAt:  &inAnHour,
+				MaxVisits: 1,
+			},
+		},
+		{
+			name: "regular short link",
+			shortLinkInput: entity.ShortLinkInput{
+				LongLink:    ptr.String("https://www.google.com"),
+				CustomAlias: ptr.String("secret"),
+				OneTime:     ptr.Bool(false),
+			},
+			uniqueness: LongLinkUniquenessNone,
+			expectedShortLink: entity.ShortLink{
+				Alias:    "secret",
+				LongLink: "https://www.google.com",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			userShortLinkRepo := repository.NewUserShortLinkRepoFake(nil, nil)
+			shortLinkRepo := repository.NewShortLinkFake(&userShortLinkRepo, shortLinks{
+				"google": {Alias: "google", LongLink: "https://www.google.com"},
+			})
+			keyFetcher := keygen.NewKeyFetcherFake(nil)
+			keyGen, err := keygen.NewRemote(2, &keyFetcher)
+			assert.Equal(t, nil, err)
+			flaggedLinkRepo := repository.NewFlaggedShortLinkFake(nil)
+			preferencesRepo := repository.NewUserPreferencesFake(nil)
+
+			creator := NewCreatorPersist(
+				&shortLinkRepo,
+				&userShortLinkRepo,
+				keyGen,
+				validator.NewLongLink(nil, nil, validator.FragmentPreserve, validator.PlainHTTPAllow),
+				validator.NewCustomAlias(),
+				timer.NewStub(now),
+				risk.NewDetector(
+					risk.NewBlackListFake(nil),
+					risk.NewDenylistFake(nil),
+					risk.StrictThresholds,
+				),
+				&flaggedLinkRepo,
+				DefaultChecks,
+				testCase.uniqueness,
+				AliasQuota{},
+				authorizer.Authorizer{},
+				email.NewSenderFake(nil),
+				webhook.NewDispatcherFake(),
+				&preferencesRepo,
+				maintenance.Mode{},
+				metrics.NewFake(),
+				0,
+				ExpirationPolicy{},
+				&repository.AliasSkeletonFake{},
+				RelationRetry{},
+				&repository.AliasReservationFake{},
+				ReservationTTL{},
+				ChainedLink{},
+			)
+
+			user := entity.User{ID: "alpha", Email: "alpha@example.com"}
+			shortLink, err := creator.CreateShortLink(context.Background(), testCase.shortLinkInput, user, false)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedShortLink.Alias, shortLink.Alias)
+			assert.Equal(t, testCase.expectedShortLink.ExpireAt, shortLink.ExpireAt)
+			assert.Equal(t, testCase.expectedShortLink.MaxVisits, shortLink.MaxVisits)
+
+			savedShortLink, err := shortLinkRepo.GetShortLinkByAlias(context.Background(), "secret")
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedShortLink.MaxVisits, savedShortLink.MaxVisits)
 		})
 	}
 }
@@ -0,0 +1,47 @@
+package shortlink
+
+import (
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+const cursorSeparator = "|"
+
+// ErrInvalidCursor represents the pagination cursor is not issued by the
+// system.
+type ErrInvalidCursor string
+
+func (e ErrInvalidCursor) Error() string {
+	return string(e)
+}
+
+// encodeCursor hides the position of the last ShortLink in a page from the
+// clients so that the pagination strategy can change without breaking them.
+func encodeCursor(cursor repository.ShortLinkCursor) string {
+	raw := cursor.CreatedAt.UTC().Format(time.RFC3339Nano) + cursorSeparator + cursor.Alias
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(encoded string) (repository.ShortLinkCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return repository.ShortLinkCursor{}, ErrInvalidCursor("cursor is not base64 encoded")
+	}
+
+	parts := strings.SplitN(string(raw), cursorSeparator, 2)
+	if len(parts) != 2 {
+		return repository.ShortLinkCursor{}, ErrInvalidCursor("cursor is malformed")
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return repository.ShortLinkCursor{}, ErrInvalidCursor("cursor contains invalid time")
+	}
+	return repository.ShortLinkCursor{
+		CreatedAt: createdAt.UTC(),
+		Alias:     parts[1],
+	}, nil
+}
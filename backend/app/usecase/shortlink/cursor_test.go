@@ -0,0 +1,88 @@
+// +build !integration all
+
+package shortlink
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+func TestCursor(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name   string
+		cursor repository.ShortLinkCursor
+	}{
+		{
+			name: "alias with separator",
+			cursor: repository.ShortLinkCursor{
+				CreatedAt: time.Date(2020, 5, 1, 8, 2, 16, 123456000, time.UTC),
+				Alias:     "a|b",
+			},
+		},
+		{
+			name: "zero creation time",
+			cursor: repository.ShortLinkCursor{
+				CreatedAt: time.Time{},
+				Alias:     "google",
+			},
+		},
+		{
+			name: "unicode alias",
+			cursor: repository.ShortLinkCursor{
+				CreatedAt: time.Date(2020, 5, 1, 8, 2, 16, 0, time.UTC),
+				Alias:     "café",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			encoded := encodeCursor(testCase.cursor)
+			decoded, err := decodeCursor(encoded)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.cursor, decoded)
+		})
+	}
+}
+
+func TestDecodeCursor_Invalid(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		encoded string
+	}{
+		{
+			name:    "not base64 encoded",
+			encoded: "!!!",
+		},
+		{
+			name:    "missing separator",
+			encoded: base64.RawURLEncoding.EncodeToString([]byte("google")),
+		},
+		{
+			name:    "invalid time",
+			encoded: base64.RawURLEncoding.EncodeToString([]byte("yesterday|google")),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := decodeCursor(testCase.encoded)
+			_, ok := err.(ErrInvalidCursor)
+			assert.Equal(t, true, ok)
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package shortlink
+
+import (
+	"context"
+
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/maintenance"
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+var _ Deleter = (*DeleterPersist)(nil)
+
+// Deleter removes existing short links.
+type Deleter interface {
+	DeleteExpiredShortLinks(ctx context.Context, user entity.User) (int, error)
+}
+
+// DeleterPersist removes short links from the data store.
+type DeleterPersist struct {
+	shortLinkRepo     repository.ShortLink
+	userShortLinkRepo repository.UserShortLink
+	timer             timer.Timer
+	maintenanceMode   maintenance.Mode
+}
+
+// DeleteExpiredShortLinks removes the short links owned by the user which
+// have expired, and returns the number of removed short links. Nothing is
+// removed in read-only mode.
+func (d DeleterPersist) DeleteExpiredShortLinks(ctx context.Context, user entity.User) (int, error) {
+	if d.maintenanceMode.IsReadOnly() {
+		return 0, ErrServiceReadOnly("delete short links")
+	}
+
+	aliases, err := d.userShortLinkRepo.FindAliasesByUser(ctx, user)
+	if err != nil {
+		return 0, err
+	}
+
+	shortLinks, err := d.shortLinkRepo.GetShortLinksByAliases(ctx, aliases)
+	if err != nil {
+		return 0, err
+	}
+
+	now := d.timer.Now()
+	var expiredAliases []string
+	for _, shortLink := range shortLinks {
+		if shortLink.ExpireAt == nil || !now.After(*shortLink.ExpireAt) {
+			continue
+		}
+		expiredAliases = append(expiredAliases, shortLink.Alias)
+	}
+
+	if len(expiredAliases) == 0 {
+		return 0, nil
+	}
+	return d.shortLinkRepo.DeleteShortLinks(ctx, expiredAliases)
+}
+
+// NewDeleterPersist creates DeleterPersist
+func NewDeleterPersist(
+	shortLinkRepo repository.ShortLink,
+	userShortLinkRepo repository.UserShortLink,
+	timer timer.Timer,
+	maintenanceMode maintenance.Mode,
+) DeleterPersist {
+	return DeleterPersist{
+		shortLinkRepo:     shortLinkRepo,
+		userShortLinkRepo: userShortLinkRepo,
+		timer:             timer,
+		maintenanceMode:   maintenanceMode,
+	}
+}
@@ -0,0 +1,128 @@
+// +build !integration all
+
+package shortlink
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/maintenance"
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+func TestDeleterPersist_DeleteExpiredShortLinks(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	before := now.Add(-time.Hour)
+	after := now.Add(time.Hour)
+
+	alpha := entity.User{ID: "alpha", Email: "alpha@example.com"}
+	beta := entity.User{ID: "beta", Email: "beta@example.com"}
+
+	testCases := []struct {
+		name                string
+		shortLinks          shortLinks
+		users               []entity.User
+		userShortLinks      []entity.ShortLink
+		user                entity.User
+		expectedCount       int
+		expectedAliases     []string
+		expectedUserAliases []string
+	}{
+		{
+			name: "delete expired short links of the user",
+			shortLinks: shortLinks{
+				"expired1": {Alias: "expired1", ExpireAt: &before},
+				"expired2": {Alias: "expired2", ExpireAt: &before},
+				"live":     {Alias: "live", ExpireAt: &after},
+				"forever":  {Alias: "forever"},
+				"others":   {Alias: "others", ExpireAt: &before},
+			},
+			users: []entity.User{alpha, alpha, alpha, alpha, beta},
+			userShortLinks: []entity.ShortLink{
+				{Alias: "expired1"},
+				{Alias: "expired2"},
+				{Alias: "live"},
+				{Alias: "forever"},
+				{Alias: "others"},
+			},
+			user:                alpha,
+			expectedCount:       2,
+			expectedAliases:     []string{"forever", "live", "others"},
+			expectedUserAliases: []string{"forever", "live"},
+		},
+		{
+			name: "short link expiring now is kept",
+			shortLinks: shortLinks{
+				"now": {Alias: "now", ExpireAt: &now},
+			},
+			users:               []entity.User{alpha},
+			userShortLinks:      []entity.ShortLink{{Alias: "now"}},
+			user:                alpha,
+			expectedCount:       0,
+			expectedAliases:     []string{"now"},
+			expectedUserAliases: []string{"now"},
+		},
+		{
+			name: "user without short links",
+			shortLinks: shortLinks{
+				"others": {Alias: "others", ExpireAt: &before},
+			},
+			users:               []entity.User{beta},
+			userShortLinks:      []entity.ShortLink{{Alias: "others"}},
+			user:                alpha,
+			expectedCount:       0,
+			expectedAliases:     []string{"others"},
+			expectedUserAliases: nil,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			userShortLinkRepo := repository.NewUserShortLinkRepoFake(
+				testCase.users,
+				testCase.userShortLinks,
+			)
+			links := shortLinks{}
+			for alias, shortLink := range testCase.shortLinks {
+				links[alias] = shortLink
+			}
+			shortLinkRepo := repository.NewShortLinkFake(&userShortLinkRepo, links)
+			deleter := NewDeleterPersist(&shortLinkRepo, &userShortLinkRepo, timer.NewStub(now), maintenance.Mode{})
+
+			ctx := context.Background()
+			count, err := deleter.DeleteExpiredShortLinks(ctx, testCase.user)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedCount, count)
+
+			var aliases []string
+			for alias := range testCase.shortLinks {
+				isExist, err := shortLinkRepo.IsAliasExist(ctx, alias)
+				assert.Equal(t, nil, err)
+				if isExist {
+					aliases = append(aliases, alias)
+				}
+			}
+			sort.Strings(aliases)
+			assert.Equal(t, testCase.expectedAliases, aliases)
+
+			userAliases, err := userShortLinkRepo.FindAliasesByUser(ctx, testCase.user)
+			assert.Equal(t, nil, err)
+			sort.Strings(userAliases)
+			assert.Equal(t, testCase.expectedUserAliases, userAliases)
+
+			count, err = deleter.DeleteExpiredShortLinks(ctx, testCase.user)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, 0, count)
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package shortlink
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// maxDescriptionLength caps the number of characters in the description of a
+// short link.
+const maxDescriptionLength = 500
+
+// ErrDescriptionTooLong represents the description of a short link with more
+// characters than allowed.
+type ErrDescriptionTooLong int
+
+func (e ErrDescriptionTooLong) Error() string {
+	return fmt.Sprintf("description has %d characters, more than %d allowed", int(e), maxDescriptionLength)
+}
+
+func validateDescription(description string) error {
+	length := utf8.RuneCountInString(description)
+	if length > maxDescriptionLength {
+		return ErrDescriptionTooLong(length)
+	}
+	return nil
+}
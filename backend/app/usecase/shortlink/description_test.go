@@ -0,0 +1,194 @@
+// +build !integration all
+
+package shortlink
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/metrics"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/fw/ptr"
+	"github.com/short-d/short/backend/app/usecase/authorizer"
+	"github.com/short-d/short/backend/app/usecase/email"
+	"github.com/short-d/short/backend/app/usecase/keygen"
+	"github.com/short-d/short/backend/app/usecase/maintenance"
+	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/risk"
+	"github.com/short-d/short/backend/app/usecase/validator"
+	"github.com/short-d/short/backend/app/usecase/webhook"
+)
+
+func TestShortLinkCreatorPersist_CreateShortLinkDescription(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name                string
+		description         *string
+		expectedErr         error
+		expectedDescription string
+	}{
+		{
+			name:                "no description",
+			expectedDescription: "",
+		},
+		{
+			name:                "set description",
+			description:         ptr.String("Campaign for the spring sale"),
+			expectedDescription: "Campaign for the spring sale",
+		},
+		{
+			name:                "description at the limit",
+			description:         ptr.String(strings.Repeat("é", maxDescriptionLength)),
+			expectedDescription: strings.Repeat("é", maxDescriptionLength),
+		},
+		{
+			name:        "description too long",
+			description: ptr.String(strings.Repeat("a", maxDescriptionLength+1)),
+			expectedErr: ErrDescriptionTooLong(maxDescriptionLength + 1),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			shortLinkRepo := repository.NewShortLinkFake(nil, shortLinks{})
+			userShortLinkRepo := repository.NewUserShortLinkRepoFake(nil, nil)
+			keyFetcher := keygen.NewKeyFetcherFake([]keygen.Key{})
+			keyGen, err := keygen.NewRemote(2, &keyFetcher)
+			assert.Equal(t, nil, err)
+			flaggedLinkRepo := repository.NewFlaggedShortLinkFake(nil)
+			preferencesRepo := repository.NewUserPreferencesFake(nil)
+
+			creator := NewCreatorPersist(
+				&shortLinkRepo,
+				&userShortLinkRepo,
+				keyGen,
+				validator.NewLongLink(nil, nil, validator.FragmentPreserve, validator.PlainHTTPAllow),
+				validator.NewCustomAlias(),
+				timer.NewStub(time.Now()),
+				risk.NewDetector(risk.NewBlackListFake(nil), risk.NewDenylistFake(nil), risk.StrictThresholds),
+				&flaggedLinkRepo,
+				DefaultChecks,
+				LongLinkUniquenessNone,
+				AliasQuota{},
+				authorizer.Authorizer{},
+				email.NewSenderFake(nil),
+				webhook.NewDispatcherFake(),
+				&preferencesRepo,
+				maintenance.Mode{},
+				metrics.NewFake(),
+				0,
+				ExpirationPolicy{},
+				&repository.AliasSkeletonFake{},
+				RelationRetry{},
+				&repository.AliasReservationFake{},
+				ReservationTTL{},
+				ChainedLink{},
+			)
+
+			shortLinkInput := entity.ShortLinkInput{
+				LongLink:    ptr.String("https://www.example.com"),
+				CustomAlias: ptr.String("spring-sale"),
+				Description: testCase.description,
+			}
+			user := entity.User{ID: "alpha", Email: "alpha@example.com"}
+			shortLink, err := creator.CreateShortLink(context.Background(), shortLinkInput, user, false)
+			assert.Equal(t, testCase.expectedErr, err)
+			if err != nil {
+				isExist, err := shortLinkRepo.IsAliasExist(context.Background(), "spring-sale")
+				assert.Equal(t, nil, err)
+				assert.Equal(t, false, isExist)
+				return
+			}
+			assert.Equal(t, testCase.expectedDescription, shortLink.Description)
+
+			savedShortLink, err := shortLinkRepo.GetShortLinkByAlias(context.Background(), "spring-sale")
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedDescription, savedShortLink.Description)
+		})
+	}
+}
+
+func TestShortLinkUpdaterPersist_UpdateShortLinkDescription(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name                string
+		description         *string
+		expectedErr         error
+		expectedDescription string
+	}{
+		{
+			name:                "keep description",
+			expectedDescription: "Campaign for the spring sale",
+		},
+		{
+			name:                "change description",
+			description:         ptr.String("Campaign for the summer sale"),
+			expectedDescription: "Campaign for the summer sale",
+		},
+		{
+			name:                "clear description",
+			description:         ptr.String(""),
+			expectedDescription: "",
+		},
+		{
+			name:                "description too long",
+			description:         ptr.String(strings.Repeat("a", maxDescriptionLength+1)),
+			expectedErr:         ErrDescriptionTooLong(maxDescriptionLength + 1),
+			expectedDescription: "Campaign for the spring sale",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			user := entity.User{ID: "alpha", Email: "alpha@example.com"}
+			shortLink := entity.ShortLink{
+				Alias:       "sale",
+				LongLink:    "https://www.example.com",
+				Description: "Campaign for the spring sale",
+			}
+			userShortLinkRepo := repository.NewUserShortLinkRepoFake(
+				[]entity.User{user},
+				[]entity.ShortLink{shortLink},
+			)
+			shortLinkRepo := repository.NewShortLinkFake(&userShortLinkRepo, shortLinks{
+				"sale": shortLink,
+			})
+
+			updater := NewUpdaterPersist(
+				&shortLinkRepo,
+				&userShortLinkRepo,
+				validator.NewLongLink(nil, nil, validator.FragmentPreserve, validator.PlainHTTPAllow),
+				validator.NewCustomAlias(),
+				timer.NewStub(time.Now()),
+				risk.NewDetector(risk.NewBlackListFake(nil), risk.NewDenylistFake(nil), risk.StrictThresholds),
+				DefaultChecks,
+				&repository.AliasSkeletonFake{},
+				&repository.FlaggedShortLinkFake{},
+				email.NewSenderFake(nil),
+				maintenance.Mode{},
+				&repository.AliasReservationFake{},
+				ChainedLink{},
+			)
+
+			shortLinkInput := entity.ShortLinkInput{Description: testCase.description}
+			_, err := updater.UpdateShortLink(context.Background(), "sale", shortLinkInput, user)
+			assert.Equal(t, testCase.expectedErr, err)
+
+			savedShortLink, err := shortLinkRepo.GetShortLinkByAlias(context.Background(), "sale")
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedDescription, savedShortLink.Description)
+		})
+	}
+}
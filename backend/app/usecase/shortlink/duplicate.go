@@ -0,0 +1,136 @@
+package shortlink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/short-d/short/backend/app/entity"
+)
+
+// maxRenameAttempts caps the number of suffixes tried when renaming an
+// imported short link whose alias is taken.
+const maxRenameAttempts = 100
+
+// DuplicateAlias decides what happens to an imported short link whose alias
+// is already taken.
+type DuplicateAlias string
+
+// The constants enumerate all supported ways to resolve duplicate aliases.
+const (
+	// DuplicateAliasSkip leaves the existing short link untouched and skips
+	// the imported one.
+	DuplicateAliasSkip DuplicateAlias = "skip"
+	// DuplicateAliasOverwrite replaces the existing short link with the
+	// imported one, as long as the existing one is owned by the importer.
+	DuplicateAliasOverwrite DuplicateAlias = "overwrite"
+	// DuplicateAliasRename imports the short link under the first available
+	// alias formed by appending a numeric suffix, such as docs-2.
+	DuplicateAliasRename DuplicateAlias = "rename"
+)
+
+// ErrUnknownDuplicateAlias represents the way to resolve duplicate aliases
+// which is not supported.
+type ErrUnknownDuplicateAlias string
+
+func (e ErrUnknownDuplicateAlias) Error() string {
+	return "unknown duplicate alias resolution: " + string(e)
+}
+
+// ParseDuplicateAlias converts the name of the resolution into
+// DuplicateAlias. Empty name skips the duplicates.
+func ParseDuplicateAlias(name string) (DuplicateAlias, error) {
+	duplicateAlias := DuplicateAlias(name)
+	switch duplicateAlias {
+	case "":
+		return DuplicateAliasSkip, nil
+	case DuplicateAliasSkip, DuplicateAliasOverwrite, DuplicateAliasRename:
+		return duplicateAlias, nil
+	default:
+		return "", ErrUnknownDuplicateAlias(name)
+	}
+}
+
+// ImportDuplicate records how an imported short link with a taken alias is
+// resolved. ResolvedAlias is the alias the short link ends up with, which is
+// empty when the short link is skipped.
+type ImportDuplicate struct {
+	Alias         string
+	Resolution    DuplicateAlias
+	ResolvedAlias string
+}
+
+// resolveDuplicate applies the resolution to the taken alias and returns the
+// alias the imported short link ends up with, which is empty when the short
+// link is skipped.
+func (i ImporterPersist) resolveDuplicate(
+	ctx context.Context,
+	alias string,
+	owner entity.User,
+	duplicateAlias DuplicateAlias,
+	importedAliases map[string]bool,
+) (string, error) {
+	switch duplicateAlias {
+	case DuplicateAliasOverwrite:
+		isOwned, err := i.isOwnedByImporter(ctx, alias, owner, importedAliases)
+		if err != nil {
+			return "", err
+		}
+		if !isOwned {
+			action := fmt.Sprintf("overwrite the short link %s", alias)
+			return "", ErrUnauthorizedAction{user: owner, action: action}
+		}
+		return alias, nil
+	case DuplicateAliasRename:
+		return i.renameAlias(ctx, alias, importedAliases)
+	default:
+		return "", nil
+	}
+}
+
+// isDuplicateFailure checks whether the error only prevents the short link
+// with the taken alias from being imported.
+func isDuplicateFailure(err error) bool {
+	var unauthorized ErrUnauthorizedAction
+	var aliasExist ErrAliasExist
+	return errors.As(err, &unauthorized) || errors.As(err, &aliasExist)
+}
+
+// isOwnedByImporter checks whether the short link with the taken alias can be
+// overwritten by the owner of the import.
+func (i ImporterPersist) isOwnedByImporter(
+	ctx context.Context,
+	alias string,
+	owner entity.User,
+	importedAliases map[string]bool,
+) (bool, error) {
+	if importedAliases[alias] {
+		return true, nil
+	}
+	return i.userShortLinkRepo.HasMapping(ctx, owner, alias)
+}
+
+// renameAlias finds the first valid alias which is neither taken nor
+// imported earlier by appending a numeric suffix to the given alias.
+func (i ImporterPersist) renameAlias(
+	ctx context.Context,
+	alias string,
+	importedAliases map[string]bool,
+) (string, error) {
+	for suffix := 2; suffix < maxRenameAttempts+2; suffix++ {
+		candidate := fmt.Sprintf("%s-%d", alias, suffix)
+		isValid, _ := i.aliasValidator.IsValid(candidate)
+		if !isValid {
+			continue
+		}
+
+		isTaken, err := i.isTaken(ctx, candidate, importedAliases)
+		if err != nil {
+			return "", err
+		}
+		if !isTaken {
+			return candidate, nil
+		}
+	}
+	return "", ErrAliasExist(fmt.Sprintf("no available alias to rename %s", alias))
+}
@@ -0,0 +1,57 @@
+// +build !integration all
+
+package shortlink
+
+import (
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+)
+
+func TestParseDuplicateAlias(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name                   string
+		duplicateAliasName     string
+		expectedDuplicateAlias DuplicateAlias
+		expectedErr            error
+	}{
+		{
+			name:                   "empty name",
+			duplicateAliasName:     "",
+			expectedDuplicateAlias: DuplicateAliasSkip,
+		},
+		{
+			name:                   "skip",
+			duplicateAliasName:     "skip",
+			expectedDuplicateAlias: DuplicateAliasSkip,
+		},
+		{
+			name:                   "overwrite",
+			duplicateAliasName:     "overwrite",
+			expectedDuplicateAlias: DuplicateAliasOverwrite,
+		},
+		{
+			name:                   "rename",
+			duplicateAliasName:     "rename",
+			expectedDuplicateAlias: DuplicateAliasRename,
+		},
+		{
+			name:               "unknown name",
+			duplicateAliasName: "merge",
+			expectedErr:        ErrUnknownDuplicateAlias("merge"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			duplicateAlias, err := ParseDuplicateAlias(testCase.duplicateAliasName)
+			assert.Equal(t, testCase.expectedErr, err)
+			assert.Equal(t, testCase.expectedDuplicateAlias, duplicateAlias)
+		})
+	}
+}
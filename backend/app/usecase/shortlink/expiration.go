@@ -0,0 +1,126 @@
+package shortlink
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/authorizer/rbac/role"
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+// ExpirationPolicy decides how long the short links created without expiration
+// time stay active based on the roles of the user, so that account tiers can
+// keep their short links for different periods. A lifetime of zero means the
+// short links never expire. The zero value of ExpirationPolicy never expires
+// short links.
+type ExpirationPolicy struct {
+	userRoleRepo  repository.UserRole
+	lifetime      time.Duration
+	roleLifetimes map[role.Role]time.Duration
+}
+
+// DefaultExpireAt computes the expiration time of the short link created by the
+// user at now. The longest lifetime among the roles of the user with a
+// configured lifetime wins. Users without such roles get the default lifetime.
+// nil is returned when the short link never expires.
+func (e ExpirationPolicy) DefaultExpireAt(user entity.User, now time.Time) (*time.Time, error) {
+	lifetime, err := e.getLifetime(user)
+	if err != nil {
+		return nil, err
+	}
+	if lifetime <= 0 {
+		return nil, nil
+	}
+
+	expireAt := now.Add(lifetime)
+	return &expireAt, nil
+}
+
+func (e ExpirationPolicy) getLifetime(user entity.User) (time.Duration, error) {
+	if len(e.roleLifetimes) == 0 {
+		return e.lifetime, nil
+	}
+
+	roles, err := e.userRoleRepo.GetRoles(user)
+	var notFound repository.ErrEntryNotFound
+	if errors.As(err, &notFound) {
+		return e.lifetime, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	hasRoleLifetime := false
+	var longest time.Duration
+	for _, userRole := range roles {
+		lifetime, ok := e.roleLifetimes[userRole]
+		if !ok {
+			continue
+		}
+		if lifetime <= 0 {
+			return 0, nil
+		}
+		if !hasRoleLifetime || lifetime > longest {
+			longest = lifetime
+		}
+		hasRoleLifetime = true
+	}
+
+	if !hasRoleLifetime {
+		return e.lifetime, nil
+	}
+	return longest, nil
+}
+
+// ErrInvalidRoleLifetime represents the lifetime of a role is not in the form
+// of role=duration, or the role is not defined.
+type ErrInvalidRoleLifetime string
+
+func (e ErrInvalidRoleLifetime) Error() string {
+	return "invalid role lifetime: " + string(e)
+}
+
+// ParseRoleLifetimes converts the lifetimes of roles in the form of
+// role=duration, such as basic=720h, into the lifetime of each role.
+func ParseRoleLifetimes(entries []string) (map[role.Role]time.Duration, error) {
+	roleLifetimes := make(map[role.Role]time.Duration, len(entries))
+	for _, entry := range entries {
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			return nil, ErrInvalidRoleLifetime(entry)
+		}
+
+		userRole := role.Role(strings.TrimSpace(parts[0]))
+		if !userRole.IsDefined() {
+			return nil, ErrInvalidRoleLifetime(entry)
+		}
+
+		lifetime, err := time.ParseDuration(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, ErrInvalidRoleLifetime(fmt.Sprintf("%s: %v", entry, err))
+		}
+		if lifetime < 0 {
+			return nil, ErrInvalidRoleLifetime(entry)
+		}
+		roleLifetimes[userRole] = lifetime
+	}
+	return roleLifetimes, nil
+}
+
+// NewExpirationPolicy creates ExpirationPolicy which expires the short links
+// after the lifetime of the roles of the user, or after lifetime when none of
+// the roles has one.
+func NewExpirationPolicy(
+	userRoleRepo repository.UserRole,
+	lifetime time.Duration,
+	roleLifetimes map[role.Role]time.Duration,
+) ExpirationPolicy {
+	return ExpirationPolicy{
+		userRoleRepo:  userRoleRepo,
+		lifetime:      lifetime,
+		roleLifetimes: roleLifetimes,
+	}
+}
@@ -0,0 +1,159 @@
+// +build !integration all
+
+package shortlink
+
+import (
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/authorizer/rbac/role"
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+func TestExpirationPolicy_DefaultExpireAt(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 6, 1, 8, 0, 0, 0, time.UTC)
+	month := 30 * 24 * time.Hour
+	year := 365 * 24 * time.Hour
+	monthLater := now.Add(month)
+	yearLater := now.Add(year)
+
+	roleLifetimes := map[role.Role]time.Duration{
+		role.Basic:           month,
+		role.ShortLinkEditor: year,
+		role.Admin:           0,
+	}
+
+	testCases := []struct {
+		name             string
+		lifetime         time.Duration
+		roleLifetimes    map[role.Role]time.Duration
+		roles            []role.Role
+		expectedExpireAt *time.Time
+	}{
+		{
+			name:             "policy disabled",
+			expectedExpireAt: nil,
+		},
+		{
+			name:             "default lifetime without role lifetimes",
+			lifetime:         month,
+			roles:            []role.Role{role.Admin},
+			expectedExpireAt: &monthLater,
+		},
+		{
+			name:             "role lifetime",
+			roleLifetimes:    roleLifetimes,
+			roles:            []role.Role{role.Basic},
+			expectedExpireAt: &monthLater,
+		},
+		{
+			name:             "longest role lifetime wins",
+			roleLifetimes:    roleLifetimes,
+			roles:            []role.Role{role.Basic, role.ShortLinkEditor},
+			expectedExpireAt: &yearLater,
+		},
+		{
+			name:             "role never expires",
+			lifetime:         month,
+			roleLifetimes:    roleLifetimes,
+			roles:            []role.Role{role.Basic, role.Admin},
+			expectedExpireAt: nil,
+		},
+		{
+			name:             "default lifetime for roles without lifetime",
+			lifetime:         year,
+			roleLifetimes:    roleLifetimes,
+			roles:            []role.Role{role.ChangeLogViewer},
+			expectedExpireAt: &yearLater,
+		},
+		{
+			name:             "default lifetime for users without roles",
+			lifetime:         month,
+			roleLifetimes:    roleLifetimes,
+			expectedExpireAt: &monthLater,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			user := entity.User{ID: "alpha"}
+			userRoles := map[string][]role.Role{}
+			if testCase.roles != nil {
+				userRoles[user.ID] = testCase.roles
+			}
+			userRoleRepo := repository.NewUserRoleFake(userRoles)
+			policy := NewExpirationPolicy(userRoleRepo, testCase.lifetime, testCase.roleLifetimes)
+
+			expireAt, err := policy.DefaultExpireAt(user, now)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedExpireAt, expireAt)
+		})
+	}
+}
+
+func TestParseRoleLifetimes(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name                  string
+		entries               []string
+		hasErr                bool
+		expectedRoleLifetimes map[role.Role]time.Duration
+	}{
+		{
+			name:                  "no entries",
+			entries:               nil,
+			expectedRoleLifetimes: map[role.Role]time.Duration{},
+		},
+		{
+			name:    "lifetimes of roles",
+			entries: []string{"basic=720h", " admin = 0 "},
+			expectedRoleLifetimes: map[role.Role]time.Duration{
+				role.Basic: 720 * time.Hour,
+				role.Admin: 0,
+			},
+		},
+		{
+			name:    "missing duration",
+			entries: []string{"basic"},
+			hasErr:  true,
+		},
+		{
+			name:    "unknown role",
+			entries: []string{"premium=720h"},
+			hasErr:  true,
+		},
+		{
+			name:    "invalid duration",
+			entries: []string{"basic=month"},
+			hasErr:  true,
+		},
+		{
+			name:    "negative duration",
+			entries: []string{"basic=-1h"},
+			hasErr:  true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			roleLifetimes, err := ParseRoleLifetimes(testCase.entries)
+			if testCase.hasErr {
+				assert.NotEqual(t, nil, err)
+				return
+			}
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedRoleLifetimes, roleLifetimes)
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package shortlink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/authorizer"
+	"github.com/short-d/short/backend/app/usecase/maintenance"
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+var _ Expirer = (*ExpirerPersist)(nil)
+
+// ErrUnauthorizedAction represents unauthorized action error
+type ErrUnauthorizedAction struct {
+	user   entity.User
+	action string
+}
+
+var _ error = (*ErrUnauthorizedAction)(nil)
+
+func (e ErrUnauthorizedAction) Error() string {
+	return fmt.Sprintf("user %s is not allowed to %s", e.user.ID, e.action)
+}
+
+// Expirer stops short links from redirecting on behalf of admins.
+type Expirer interface {
+	ExpireShortLink(ctx context.Context, alias string, reason string, user entity.User) (entity.ShortLink, error)
+}
+
+// ExpirerPersist expires short links in the data store and records the
+// reasons for auditing.
+type ExpirerPersist struct {
+	shortLinkRepo      repository.ShortLink
+	shortLinkAuditRepo repository.ShortLinkAudit
+	authorizer         authorizer.Authorizer
+	timer              timer.Timer
+	maintenanceMode    maintenance.Mode
+}
+
+// ExpireShortLink sets the expiration time of any short link to the current
+// time, so that it stops redirecting immediately while its visits and owners
+// are kept. The action is recorded together with the reason. Nothing is
+// changed in read-only mode.
+func (e ExpirerPersist) ExpireShortLink(
+	ctx context.Context,
+	alias string,
+	reason string,
+	user entity.User,
+) (entity.ShortLink, error) {
+	if e.maintenanceMode.IsReadOnly() {
+		return entity.ShortLink{}, ErrServiceReadOnly("expire short link")
+	}
+
+	canExpire, err := e.authorizer.CanExpireShortLink(user)
+	if err != nil {
+		return entity.ShortLink{}, err
+	}
+	if !canExpire {
+		return entity.ShortLink{}, ErrUnauthorizedAction{
+			user:   user,
+			action: fmt.Sprintf("expire short link %s", alias),
+		}
+	}
+
+	shortLink, err := e.shortLinkRepo.GetShortLinkByAlias(ctx, alias)
+	var notFound repository.ErrEntryNotFound
+	if errors.As(err, &notFound) {
+		return entity.ShortLink{}, ErrShortLinkNotFound(alias)
+	}
+	if err != nil {
+		return entity.ShortLink{}, err
+	}
+
+	now := e.timer.Now()
+	expiredShortLink, err := e.shortLinkRepo.UpdateShortLink(ctx, alias, entity.ShortLinkInput{
+		CustomAlias:       &shortLink.Alias,
+		LongLink:          &shortLink.LongLink,
+		OriginalLongLink:  &shortLink.OriginalLongLink,
+		ExpireAt:          &now,
+		UpdatedAt:         &now,
+		TrackVisits:       &shortLink.TrackVisits,
+		PassthroughQuery:  &shortLink.PassthroughQuery,
+		PassthroughPath:   &shortLink.PassthroughPath,
+		MaxRedirectsPerIP: &shortLink.MaxRedirectsPerIP,
+		Description:       &shortLink.Description,
+	})
+	if err != nil {
+		return entity.ShortLink{}, err
+	}
+
+	err = e.shortLinkAuditRepo.CreateAuditEntry(ctx, entity.ShortLinkAuditEntry{
+		Alias:     alias,
+		Action:    entity.ShortLinkActionExpire,
+		Reason:    reason,
+		UserID:    user.ID,
+		CreatedAt: now,
+	})
+	if err != nil {
+		return entity.ShortLink{}, err
+	}
+	return expiredShortLink, nil
+}
+
+// NewExpirerPersist creates ExpirerPersist
+func NewExpirerPersist(
+	shortLinkRepo repository.ShortLink,
+	shortLinkAuditRepo repository.ShortLinkAudit,
+	authorizer authorizer.Authorizer,
+	timer timer.Timer,
+	maintenanceMode maintenance.Mode,
+) ExpirerPersist {
+	return ExpirerPersist{
+		shortLinkRepo:      shortLinkRepo,
+		shortLinkAuditRepo: shortLinkAuditRepo,
+		authorizer:         authorizer,
+		timer:              timer,
+		maintenanceMode:    maintenanceMode,
+	}
+}
@@ -0,0 +1,152 @@
+// +build !integration all
+
+package shortlink
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/authorizer"
+	"github.com/short-d/short/backend/app/usecase/authorizer/rbac"
+	"github.com/short-d/short/backend/app/usecase/authorizer/rbac/role"
+	"github.com/short-d/short/backend/app/usecase/maintenance"
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+func TestExpirerPersist_ExpireShortLink(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 6, 1, 8, 0, 0, 0, time.UTC)
+	later := now.Add(24 * time.Hour)
+
+	admin := entity.User{ID: "alpha"}
+	editor := entity.User{ID: "beta"}
+	basic := entity.User{ID: "gamma"}
+
+	testCases := []struct {
+		name            string
+		shortLinks      shortLinks
+		alias           string
+		user            entity.User
+		hasErr          bool
+		expectedErr     error
+		expectedEntries []entity.ShortLinkAuditEntry
+	}{
+		{
+			name: "admin expires short link",
+			shortLinks: shortLinks{
+				"phishing": {
+					Alias:            "phishing",
+					LongLink:         "https://phishing.example.com",
+					OriginalLongLink: "http://phishing.example.com#login",
+					ExpireAt:         &later,
+					TrackVisits:      true,
+				},
+			},
+			alias:  "phishing",
+			user:   admin,
+			hasErr: false,
+			expectedEntries: []entity.ShortLinkAuditEntry{
+				{
+					Alias:     "phishing",
+					Action:    entity.ShortLinkActionExpire,
+					Reason:    "phishing reported",
+					UserID:    "alpha",
+					CreatedAt: now,
+				},
+			},
+		},
+		{
+			name: "short link editor not allowed",
+			shortLinks: shortLinks{
+				"phishing": {Alias: "phishing", LongLink: "https://phishing.example.com"},
+			},
+			alias:  "phishing",
+			user:   editor,
+			hasErr: true,
+			expectedErr: ErrUnauthorizedAction{
+				user:   editor,
+				action: "expire short link phishing",
+			},
+		},
+		{
+			name: "basic user not allowed",
+			shortLinks: shortLinks{
+				"phishing": {Alias: "phishing", LongLink: "https://phishing.example.com"},
+			},
+			alias:  "phishing",
+			user:   basic,
+			hasErr: true,
+			expectedErr: ErrUnauthorizedAction{
+				user:   basic,
+				action: "expire short link phishing",
+			},
+		},
+		{
+			name:        "short link not found",
+			shortLinks:  shortLinks{},
+			alias:       "phishing",
+			user:        admin,
+			hasErr:      true,
+			expectedErr: ErrShortLinkNotFound("phishing"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			shortLinkRepo := repository.NewShortLinkFake(nil, testCase.shortLinks)
+			auditRepo := repository.NewShortLinkAuditFake(nil)
+			userRoleRepo := repository.NewUserRoleFake(map[string][]role.Role{
+				admin.ID:  {role.Admin},
+				editor.ID: {role.ShortLinkEditor},
+				basic.ID:  {role.Basic},
+			})
+			tm := timer.NewStub(now)
+
+			expirer := NewExpirerPersist(
+				&shortLinkRepo,
+				&auditRepo,
+				authorizer.NewAuthorizer(rbac.NewRBAC(userRoleRepo)),
+				tm,
+				maintenance.Mode{},
+			)
+			retriever := NewRetrieverPersist(&shortLinkRepo, nil, 0)
+
+			ctx := context.Background()
+			shortLink, err := expirer.ExpireShortLink(ctx, testCase.alias, "phishing reported", testCase.user)
+			if testCase.hasErr {
+				assert.Equal(t, testCase.expectedErr, err)
+				assert.Equal(t, 0, len(auditRepo.AuditEntries()))
+
+				if _, ok := testCase.shortLinks[testCase.alias]; ok {
+					_, err = retriever.GetShortLink(ctx, testCase.alias, &now)
+					assert.Equal(t, nil, err)
+				}
+				return
+			}
+			assert.Equal(t, nil, err)
+			assert.Equal(t, now, *shortLink.ExpireAt)
+			assert.Equal(t, testCase.expectedEntries, auditRepo.AuditEntries())
+
+			prevShortLink := testCase.shortLinks[testCase.alias]
+			savedShortLink, err := shortLinkRepo.GetShortLinkByAlias(ctx, testCase.alias)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, prevShortLink.LongLink, savedShortLink.LongLink)
+			assert.Equal(t, prevShortLink.OriginalLongLink, savedShortLink.OriginalLongLink)
+			assert.Equal(t, prevShortLink.TrackVisits, savedShortLink.TrackVisits)
+
+			redirectAt := now.Add(time.Second)
+			_, err = retriever.GetShortLink(ctx, testCase.alias, &redirectAt)
+			var expired ErrShortLinkExpired
+			assert.Equal(t, true, errors.As(err, &expired))
+		})
+	}
+}
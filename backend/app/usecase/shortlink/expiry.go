@@ -0,0 +1,35 @@
+package shortlink
+
+import (
+	"time"
+
+	"github.com/short-d/short/backend/app/entity"
+)
+
+// ExpiryState represents whether a short link still redirects users at a
+// given time.
+type ExpiryState int
+
+// The constants enumerate all expiry states of a short link.
+const (
+	// ExpiryStateActive represents the short link hasn't expired yet.
+	ExpiryStateActive ExpiryState = iota
+	// ExpiryStateGrace represents the short link has expired within the grace
+	// window, so it still redirects users with a warning.
+	ExpiryStateGrace
+	// ExpiryStateExpired represents the short link has expired beyond the
+	// grace window and no longer redirects users.
+	ExpiryStateExpired
+)
+
+// getExpiryState decides the expiry state of the short link at the given
+// time. Short links without expiration time are always active.
+func getExpiryState(shortLink entity.ShortLink, at time.Time, grace time.Duration) ExpiryState {
+	if shortLink.ExpireAt == nil || !at.After(*shortLink.ExpireAt) {
+		return ExpiryStateActive
+	}
+	if !at.After(shortLink.ExpireAt.Add(grace)) {
+		return ExpiryStateGrace
+	}
+	return ExpiryStateExpired
+}
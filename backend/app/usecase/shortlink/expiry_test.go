@@ -0,0 +1,89 @@
+// +build !integration all
+
+package shortlink
+
+import (
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/short/backend/app/entity"
+)
+
+func TestGetExpiryState(t *testing.T) {
+	t.Parallel()
+
+	expireAt := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name          string
+		expireAt      *time.Time
+		at            time.Time
+		grace         time.Duration
+		expectedState ExpiryState
+	}{
+		{
+			name:          "never expire",
+			expireAt:      nil,
+			at:            expireAt.Add(24 * time.Hour),
+			grace:         time.Hour,
+			expectedState: ExpiryStateActive,
+		},
+		{
+			name:          "before expiration",
+			expireAt:      &expireAt,
+			at:            expireAt.Add(-time.Second),
+			grace:         time.Hour,
+			expectedState: ExpiryStateActive,
+		},
+		{
+			name:          "at expiration",
+			expireAt:      &expireAt,
+			at:            expireAt,
+			grace:         time.Hour,
+			expectedState: ExpiryStateActive,
+		},
+		{
+			name:          "right after expiration",
+			expireAt:      &expireAt,
+			at:            expireAt.Add(time.Second),
+			grace:         time.Hour,
+			expectedState: ExpiryStateGrace,
+		},
+		{
+			name:          "at the end of grace window",
+			expireAt:      &expireAt,
+			at:            expireAt.Add(time.Hour),
+			grace:         time.Hour,
+			expectedState: ExpiryStateGrace,
+		},
+		{
+			name:          "after grace window",
+			expireAt:      &expireAt,
+			at:            expireAt.Add(time.Hour + time.Second),
+			grace:         time.Hour,
+			expectedState: ExpiryStateExpired,
+		},
+		{
+			name:          "no grace window",
+			expireAt:      &expireAt,
+			at:            expireAt.Add(time.Second),
+			grace:         0,
+			expectedState: ExpiryStateExpired,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			shortLink := entity.ShortLink{
+				Alias:    "220uFicCJj",
+				ExpireAt: testCase.expireAt,
+			}
+			state := getExpiryState(shortLink, testCase.at, testCase.grace)
+			assert.Equal(t, testCase.expectedState, state)
+		})
+	}
+}
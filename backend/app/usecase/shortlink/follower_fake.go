@@ -0,0 +1,20 @@
+package shortlink
+
+var _ RedirectFollower = (*RedirectFollowerFake)(nil)
+
+// RedirectFollowerFake represents an in memory RedirectFollower used for
+// testing.
+type RedirectFollowerFake struct {
+	redirects map[string]string
+}
+
+// NextHop retrieves the URL configured as the redirect of the given URL.
+func (r RedirectFollowerFake) NextHop(rawURL string) (string, error) {
+	return r.redirects[rawURL], nil
+}
+
+// NewRedirectFollowerFake creates RedirectFollowerFake which redirects the
+// keys of redirects to their values.
+func NewRedirectFollowerFake(redirects map[string]string) RedirectFollowerFake {
+	return RedirectFollowerFake{redirects: redirects}
+}
@@ -0,0 +1,58 @@
+package shortlink
+
+import (
+	"context"
+
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+var _ GuestSession = (*GuestSessionPersist)(nil)
+
+// GuestSession represents the short links created by a signed out user, grouped
+// by an anonymous session.
+type GuestSession interface {
+	GetShortLinks(ctx context.Context, sessionID string) ([]entity.ShortLink, error)
+	Claim(ctx context.Context, sessionID string, user entity.User) (int, error)
+}
+
+// GuestSessionPersist represents GuestSession backed by persistent storage,
+// such as database.
+type GuestSessionPersist struct {
+	shortLinkRepo     repository.ShortLink
+	userShortLinkRepo repository.UserShortLink
+}
+
+// GetShortLinks retrieves the short links created in the given guest session.
+func (g GuestSessionPersist) GetShortLinks(ctx context.Context, sessionID string) ([]entity.ShortLink, error) {
+	if sessionID == "" {
+		return nil, ErrEmptySessionID("session ID can't be empty")
+	}
+
+	aliases, err := g.userShortLinkRepo.FindAliasesBySession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return g.shortLinkRepo.GetShortLinksByAliases(ctx, aliases)
+}
+
+// Claim makes the user the owner of the short links created in the given guest
+// session, and returns the number of claimed short links. The short links are
+// no longer visible to the guest session afterwards.
+func (g GuestSessionPersist) Claim(ctx context.Context, sessionID string, user entity.User) (int, error) {
+	if sessionID == "" {
+		return 0, ErrEmptySessionID("session ID can't be empty")
+	}
+	return g.userShortLinkRepo.ClaimSession(ctx, sessionID, user)
+}
+
+// NewGuestSessionPersist creates GuestSessionPersist
+func NewGuestSessionPersist(
+	shortLinkRepo repository.ShortLink,
+	userShortLinkRepo repository.UserShortLink,
+) GuestSessionPersist {
+	return GuestSessionPersist{
+		shortLinkRepo:     shortLinkRepo,
+		userShortLinkRepo: userShortLinkRepo,
+	}
+}
@@ -0,0 +1,222 @@
+// +build !integration all
+
+package shortlink
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/metrics"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/fw/ptr"
+	"github.com/short-d/short/backend/app/usecase/authorizer"
+	"github.com/short-d/short/backend/app/usecase/email"
+	"github.com/short-d/short/backend/app/usecase/keygen"
+	"github.com/short-d/short/backend/app/usecase/maintenance"
+	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/risk"
+	"github.com/short-d/short/backend/app/usecase/validator"
+	"github.com/short-d/short/backend/app/usecase/webhook"
+)
+
+func TestGuestSessionPersist(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	user := entity.User{ID: "alpha"}
+
+	testCases := []struct {
+		name                     string
+		guestShortLinks          map[string][]string
+		claimSessionID           string
+		hasErr                   bool
+		expectedClaimed          int
+		expectedUserAliases      []string
+		expectedRemainingAliases map[string][]string
+	}{
+		{
+			name: "claim guest session",
+			guestShortLinks: map[string][]string{
+				"session1": {"google", "bing"},
+				"session2": {"mozilla"},
+			},
+			claimSessionID:      "session1",
+			expectedClaimed:     2,
+			expectedUserAliases: []string{"google", "bing"},
+			expectedRemainingAliases: map[string][]string{
+				"session1": nil,
+				"session2": {"mozilla"},
+			},
+		},
+		{
+			name: "claim session without short link",
+			guestShortLinks: map[string][]string{
+				"session2": {"mozilla"},
+			},
+			claimSessionID:      "session1",
+			expectedClaimed:     0,
+			expectedUserAliases: nil,
+			expectedRemainingAliases: map[string][]string{
+				"session2": {"mozilla"},
+			},
+		},
+		{
+			name: "empty session ID",
+			guestShortLinks: map[string][]string{
+				"session1": {"google"},
+			},
+			claimSessionID: "",
+			hasErr:         true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			userShortLinkRepo := repository.NewUserShortLinkRepoFake(nil, nil)
+			shortLinkRepo := repository.NewShortLinkFake(&userShortLinkRepo, shortLinks{})
+			keyFetcher := keygen.NewKeyFetcherFake(nil)
+			keyGen, err := keygen.NewRemote(2, &keyFetcher)
+			assert.Equal(t, nil, err)
+			flaggedLinkRepo := repository.NewFlaggedShortLinkFake(nil)
+			preferencesRepo := repository.NewUserPreferencesFake(nil)
+			creator := NewCreatorPersist(
+				&shortLinkRepo,
+				&userShortLinkRepo,
+				keyGen,
+				validator.NewLongLink(nil, nil, validator.FragmentPreserve, validator.PlainHTTPAllow),
+				validator.NewCustomAlias(),
+				timer.NewStub(now),
+				risk.NewDetector(
+					risk.NewBlackListFake(nil),
+					risk.NewDenylistFake(nil),
+					risk.StrictThresholds,
+				),
+				&flaggedLinkRepo,
+				DefaultChecks,
+				LongLinkUniquenessNone,
+				AliasQuota{},
+				authorizer.Authorizer{},
+				email.NewSenderFake(nil),
+				webhook.NewDispatcherFake(),
+				&preferencesRepo,
+				maintenance.Mode{},
+				metrics.NewFake(),
+				0,
+				ExpirationPolicy{},
+				&repository.AliasSkeletonFake{},
+				RelationRetry{},
+				&repository.AliasReservationFake{},
+				ReservationTTL{},
+				ChainedLink{},
+			)
+			guestSession := NewGuestSessionPersist(&shortLinkRepo, &userShortLinkRepo)
+			retriever := NewRetrieverPersist(&shortLinkRepo, &userShortLinkRepo, 0)
+
+			for sessionID, aliases := range testCase.guestShortLinks {
+				for _, alias := range aliases {
+					_, err := creator.CreateGuestShortLink(context.Background(), entity.ShortLinkInput{
+						LongLink:    ptr.String("https://www.google.com"),
+						CustomAlias: ptr.String(alias),
+					}, sessionID)
+					assert.Equal(t, nil, err)
+				}
+
+				shortLinks, err := guestSession.GetShortLinks(context.Background(), sessionID)
+				assert.Equal(t, nil, err)
+				assert.Equal(t, aliases, shortLinkAliases(shortLinks))
+			}
+
+			claimed, err := guestSession.Claim(context.Background(), testCase.claimSessionID, user)
+			if testCase.hasErr {
+				assert.NotEqual(t, nil, err)
+				return
+			}
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedClaimed, claimed)
+
+			userShortLinks, err := retriever.GetShortLinksByUser(context.Background(), user)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedUserAliases, shortLinkAliases(userShortLinks))
+
+			for sessionID, expectedAliases := range testCase.expectedRemainingAliases {
+				shortLinks, err := guestSession.GetShortLinks(context.Background(), sessionID)
+				assert.Equal(t, nil, err)
+				assert.Equal(t, expectedAliases, shortLinkAliases(shortLinks))
+			}
+		})
+	}
+}
+
+func TestCreatorPersist_CreateGuestShortLink(t *testing.T) {
+	t.Parallel()
+
+	userShortLinkRepo := repository.NewUserShortLinkRepoFake(nil, nil)
+	shortLinkRepo := repository.NewShortLinkFake(&userShortLinkRepo, shortLinks{})
+	keyFetcher := keygen.NewKeyFetcherFake([]keygen.Key{"220uFicCJj"})
+	keyGen, err := keygen.NewRemote(2, &keyFetcher)
+	assert.Equal(t, nil, err)
+	flaggedLinkRepo := repository.NewFlaggedShortLinkFake(nil)
+	preferencesRepo := repository.NewUserPreferencesFake(nil)
+	creator := NewCreatorPersist(
+		&shortLinkRepo,
+		&userShortLinkRepo,
+		keyGen,
+		validator.NewLongLink(nil, nil, validator.FragmentPreserve, validator.PlainHTTPAllow),
+		validator.NewCustomAlias(),
+		timer.NewStub(time.Now()),
+		risk.NewDetector(
+			risk.NewBlackListFake(nil),
+			risk.NewDenylistFake(nil),
+			risk.StrictThresholds,
+		),
+		&flaggedLinkRepo,
+		DefaultChecks,
+		LongLinkUniquenessNone,
+		AliasQuota{},
+		authorizer.Authorizer{},
+		email.NewSenderFake(nil),
+		webhook.NewDispatcherFake(),
+		&preferencesRepo,
+		maintenance.Mode{},
+		metrics.NewFake(),
+		0,
+		ExpirationPolicy{},
+		&repository.AliasSkeletonFake{},
+		RelationRetry{},
+		&repository.AliasReservationFake{},
+		ReservationTTL{},
+		ChainedLink{},
+	)
+	shortLinkInput := entity.ShortLinkInput{
+		LongLink: ptr.String("https://www.google.com"),
+	}
+
+	_, err = creator.CreateGuestShortLink(context.Background(), shortLinkInput, "")
+	assert.NotEqual(t, nil, err)
+
+	shortLink, err := creator.CreateGuestShortLink(context.Background(), shortLinkInput, "session1")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "220uFicCJj", shortLink.Alias)
+
+	aliases, err := userShortLinkRepo.FindAliasesBySession(context.Background(), "session1")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"220uFicCJj"}, aliases)
+
+	aliases, err = userShortLinkRepo.FindAliasesByUser(context.Background(), entity.User{})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(aliases))
+}
+
+func shortLinkAliases(shortLinks []entity.ShortLink) []string {
+	var aliases []string
+	for _, shortLink := range shortLinks {
+		aliases = append(aliases, shortLink.Alias)
+	}
+	return aliases
+}
@@ -0,0 +1,221 @@
+package shortlink
+
+import (
+	"context"
+
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/validator"
+)
+
+var _ Importer = (*ImporterPersist)(nil)
+
+// ImportFailure represents a short link which can't be imported because it
+// fails validation.
+type ImportFailure struct {
+	Alias string
+	Err   error
+}
+
+// ImportReport summarizes the short links imported. Collisions lists the
+// aliases which are already taken or repeated in the same import and skipped,
+// while Duplicates records how each of the taken aliases is resolved.
+type ImportReport struct {
+	Created    []entity.ShortLink
+	Collisions []string
+	Duplicates []ImportDuplicate
+	Failures   []ImportFailure
+}
+
+// ImportOptions customizes how the short links are imported. Nothing is
+// saved during a dry run.
+type ImportOptions struct {
+	IsDryRun       bool
+	DuplicateAlias DuplicateAlias
+}
+
+// Importer creates short links exported from other URL shorteners.
+type Importer interface {
+	ImportShortLinks(
+		ctx context.Context,
+		shortLinkInputs []entity.ShortLinkInput,
+		owner entity.User,
+		options ImportOptions,
+	) (ImportReport, error)
+}
+
+// ImporterPersist imports short links into the repository.
+type ImporterPersist struct {
+	shortLinkRepo     repository.ShortLink
+	userShortLinkRepo repository.UserShortLink
+	longLinkValidator validator.LongLink
+	aliasValidator    validator.CustomAlias
+	timer             timer.Timer
+}
+
+// ImportShortLinks creates the short links for the owner, keeping their
+// original aliases and creation time. The current time is used when the
+// creation time is missing. Taken aliases are resolved as configured in the
+// options, while invalid short links are skipped and reported. Overwritten
+// short links keep their creation time and are not reported as created.
+// Imported aliases are not counted as custom aliases since they may be
+// generated by the other shortener.
+func (i ImporterPersist) ImportShortLinks(
+	ctx context.Context,
+	shortLinkInputs []entity.ShortLinkInput,
+	owner entity.User,
+	options ImportOptions,
+) (ImportReport, error) {
+	report := ImportReport{
+		Created:    []entity.ShortLink{},
+		Collisions: []string{},
+		Duplicates: []ImportDuplicate{},
+		Failures:   []ImportFailure{},
+	}
+	duplicateAlias, err := ParseDuplicateAlias(string(options.DuplicateAlias))
+	if err != nil {
+		return report, err
+	}
+	importedAliases := make(map[string]bool)
+
+	for _, shortLinkInput := range shortLinkInputs {
+		alias := normalizeAlias(shortLinkInput.GetCustomAlias(""))
+		shortLinkInput.CustomAlias = &alias
+		originalLongLink := shortLinkInput.GetLongLink("")
+		longLink := canonicalizeLongLink(i.longLinkValidator, LongLinkUniquenessNone, originalLongLink)
+		shortLinkInput.LongLink = &longLink
+		shortLinkInput.OriginalLongLink = &originalLongLink
+
+		err := i.validate(shortLinkInput)
+		if err != nil {
+			report.Failures = append(report.Failures, ImportFailure{Alias: alias, Err: err})
+			continue
+		}
+
+		isTaken, err := i.isTaken(ctx, alias, importedAliases)
+		if err != nil {
+			return report, err
+		}
+		if isTaken {
+			resolvedAlias, err := i.resolveDuplicate(ctx, alias, owner, duplicateAlias, importedAliases)
+			if isDuplicateFailure(err) {
+				report.Failures = append(report.Failures, ImportFailure{Alias: alias, Err: err})
+				continue
+			}
+			if err != nil {
+				return report, err
+			}
+			report.Duplicates = append(report.Duplicates, ImportDuplicate{
+				Alias:         alias,
+				Resolution:    duplicateAlias,
+				ResolvedAlias: resolvedAlias,
+			})
+
+			switch duplicateAlias {
+			case DuplicateAliasOverwrite:
+				err = i.overwrite(ctx, shortLinkInput, options.IsDryRun)
+				if err != nil {
+					return report, err
+				}
+				continue
+			case DuplicateAliasRename:
+				alias = resolvedAlias
+				shortLinkInput.CustomAlias = &alias
+			default:
+				report.Collisions = append(report.Collisions, alias)
+				continue
+			}
+		}
+		importedAliases[alias] = true
+
+		if shortLinkInput.CreatedAt == nil {
+			now := i.timer.Now().UTC()
+			shortLinkInput.CreatedAt = &now
+		}
+
+		if !options.IsDryRun {
+			err = i.shortLinkRepo.CreateShortLink(ctx, shortLinkInput)
+			if err != nil {
+				return report, err
+			}
+			err = i.userShortLinkRepo.CreateRelation(ctx, owner, shortLinkInput, false)
+			if err != nil {
+				return report, err
+			}
+		}
+
+		report.Created = append(report.Created, entity.ShortLink{
+			Alias:            alias,
+			LongLink:         shortLinkInput.GetLongLink(""),
+			OriginalLongLink: originalLongLink,
+			ExpireAt:         shortLinkInput.ExpireAt,
+			CreatedAt:        shortLinkInput.CreatedAt,
+			TrackVisits:      shortLinkInput.GetTrackVisits(true),
+		})
+	}
+	return report, nil
+}
+
+func (i ImporterPersist) validate(shortLinkInput entity.ShortLinkInput) error {
+	alias := shortLinkInput.GetCustomAlias("")
+	if alias == "" {
+		return ErrEmptyAlias("alias is empty")
+	}
+
+	isValid, violation := i.aliasValidator.IsValid(alias)
+	if !isValid {
+		return ErrInvalidCustomAlias{alias, violation}
+	}
+
+	longLink := shortLinkInput.GetLongLink("")
+	isValid, violation = i.longLinkValidator.IsValid(longLink)
+	if !isValid {
+		return newLongLinkError(longLink, violation)
+	}
+	return nil
+}
+
+func (i ImporterPersist) isTaken(
+	ctx context.Context,
+	alias string,
+	importedAliases map[string]bool,
+) (bool, error) {
+	if importedAliases[alias] {
+		return true, nil
+	}
+	return i.shortLinkRepo.IsAliasExist(ctx, alias)
+}
+
+// overwrite replaces the long link, the expiration time and the visit
+// tracking of the short link with the same alias.
+func (i ImporterPersist) overwrite(
+	ctx context.Context,
+	shortLinkInput entity.ShortLinkInput,
+	isDryRun bool,
+) error {
+	if isDryRun {
+		return nil
+	}
+	now := i.timer.Now().UTC()
+	shortLinkInput.UpdatedAt = &now
+	_, err := i.shortLinkRepo.UpdateShortLink(ctx, shortLinkInput.GetCustomAlias(""), shortLinkInput)
+	return err
+}
+
+// NewImporterPersist creates ImporterPersist
+func NewImporterPersist(
+	shortLinkRepo repository.ShortLink,
+	userShortLinkRepo repository.UserShortLink,
+	longLinkValidator validator.LongLink,
+	aliasValidator validator.CustomAlias,
+	timer timer.Timer,
+) ImporterPersist {
+	return ImporterPersist{
+		shortLinkRepo:     shortLinkRepo,
+		userShortLinkRepo: userShortLinkRepo,
+		longLinkValidator: longLinkValidator,
+		aliasValidator:    aliasValidator,
+		timer:             timer,
+	}
+}
@@ -0,0 +1,302 @@
+// +build !integration all
+
+package shortlink
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/validator"
+)
+
+func TestImporterPersist_ImportShortLinks(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
+	createdAt := time.Date(2018, 3, 14, 9, 26, 53, 0, time.UTC)
+	owner := entity.User{ID: "alpha", Email: "alpha@example.com"}
+	longLink := "https://www.google.com"
+	newInput := func(alias string, createdAt *time.Time) entity.ShortLinkInput {
+		return entity.ShortLinkInput{
+			CustomAlias: &alias,
+			LongLink:    &longLink,
+			CreatedAt:   createdAt,
+		}
+	}
+
+	testCases := []struct {
+		name               string
+		shortLinks         map[string]entity.ShortLink
+		ownedShortLinks    []entity.ShortLink
+		shortLinkInputs    []entity.ShortLinkInput
+		isDryRun           bool
+		duplicateAlias     DuplicateAlias
+		expectedReport     ImportReport
+		expectedShortLinks []string
+		expectedLongLinks  map[string]string
+	}{
+		{
+			name:       "keep original alias and creation time",
+			shortLinks: map[string]entity.ShortLink{},
+			shortLinkInputs: []entity.ShortLinkInput{
+				newInput("2Xk3cvA", &createdAt),
+				newInput("3fQx8yz", nil),
+			},
+			isDryRun: false,
+			expectedReport: ImportReport{
+				Created: []entity.ShortLink{
+					{Alias: "2Xk3cvA", LongLink: longLink, OriginalLongLink: longLink, CreatedAt: &createdAt, TrackVisits: true},
+					{Alias: "3fQx8yz", LongLink: longLink, OriginalLongLink: longLink, CreatedAt: &now, TrackVisits: true},
+				},
+				Collisions: []string{},
+				Duplicates: []ImportDuplicate{},
+				Failures:   []ImportFailure{},
+			},
+			expectedShortLinks: []string{"2Xk3cvA", "3fQx8yz"},
+		},
+		{
+			name: "skip collisions and reserved aliases",
+			shortLinks: map[string]entity.ShortLink{
+				"2Xk3cvA": {Alias: "2Xk3cvA", LongLink: "https://github.com"},
+			},
+			shortLinkInputs: []entity.ShortLinkInput{
+				newInput("2Xk3cvA", &createdAt),
+				newInput("3fQx8yz", &createdAt),
+				newInput("3fQx8yz", &createdAt),
+				newInput("api", &createdAt),
+			},
+			isDryRun: false,
+			expectedReport: ImportReport{
+				Created: []entity.ShortLink{
+					{Alias: "3fQx8yz", LongLink: longLink, OriginalLongLink: longLink, CreatedAt: &createdAt, TrackVisits: true},
+				},
+				Collisions: []string{"2Xk3cvA", "3fQx8yz"},
+				Duplicates: []ImportDuplicate{
+					{Alias: "2Xk3cvA", Resolution: DuplicateAliasSkip},
+					{Alias: "3fQx8yz", Resolution: DuplicateAliasSkip},
+				},
+				Failures: []ImportFailure{
+					{
+						Alias: "api",
+						Err:   ErrInvalidCustomAlias{"api", validator.ReservedAlias},
+					},
+				},
+			},
+			expectedShortLinks: []string{"3fQx8yz"},
+		},
+		{
+			name:       "report invalid short links",
+			shortLinks: map[string]entity.ShortLink{},
+			shortLinkInputs: []entity.ShortLinkInput{
+				newInput("", &createdAt),
+			},
+			isDryRun: false,
+			expectedReport: ImportReport{
+				Created:    []entity.ShortLink{},
+				Collisions: []string{},
+				Duplicates: []ImportDuplicate{},
+				Failures: []ImportFailure{
+					{Alias: "", Err: ErrEmptyAlias("alias is empty")},
+				},
+			},
+			expectedShortLinks: []string{},
+		},
+		{
+			name:       "dry run",
+			shortLinks: map[string]entity.ShortLink{},
+			shortLinkInputs: []entity.ShortLinkInput{
+				newInput("2Xk3cvA", &createdAt),
+			},
+			isDryRun: true,
+			expectedReport: ImportReport{
+				Created: []entity.ShortLink{
+					{Alias: "2Xk3cvA", LongLink: longLink, OriginalLongLink: longLink, CreatedAt: &createdAt, TrackVisits: true},
+				},
+				Collisions: []string{},
+				Duplicates: []ImportDuplicate{},
+				Failures:   []ImportFailure{},
+			},
+			expectedShortLinks: []string{},
+		},
+		{
+			name: "overwrite duplicates owned by importer",
+			shortLinks: map[string]entity.ShortLink{
+				"2Xk3cvA": {Alias: "2Xk3cvA", LongLink: "https://github.com", CreatedAt: &now},
+				"3fQx8yz": {Alias: "3fQx8yz", LongLink: "https://github.com", CreatedAt: &now},
+			},
+			ownedShortLinks: []entity.ShortLink{
+				{Alias: "2Xk3cvA"},
+			},
+			shortLinkInputs: []entity.ShortLinkInput{
+				newInput("2Xk3cvA", &createdAt),
+				newInput("3fQx8yz", &createdAt),
+				newInput("5aB9wqe", &createdAt),
+				newInput("5aB9wqe", nil),
+			},
+			isDryRun:       false,
+			duplicateAlias: DuplicateAliasOverwrite,
+			expectedReport: ImportReport{
+				Created: []entity.ShortLink{
+					{Alias: "5aB9wqe", LongLink: longLink, OriginalLongLink: longLink, CreatedAt: &createdAt, TrackVisits: true},
+				},
+				Collisions: []string{},
+				Duplicates: []ImportDuplicate{
+					{Alias: "2Xk3cvA", Resolution: DuplicateAliasOverwrite, ResolvedAlias: "2Xk3cvA"},
+					{Alias: "5aB9wqe", Resolution: DuplicateAliasOverwrite, ResolvedAlias: "5aB9wqe"},
+				},
+				Failures: []ImportFailure{
+					{
+						Alias: "3fQx8yz",
+						Err:   ErrUnauthorizedAction{owner, "overwrite the short link 3fQx8yz"},
+					},
+				},
+			},
+			expectedShortLinks: []string{"2Xk3cvA", "5aB9wqe"},
+			expectedLongLinks: map[string]string{
+				"2Xk3cvA": longLink,
+				"3fQx8yz": "https://github.com",
+				"5aB9wqe": longLink,
+			},
+		},
+		{
+			name: "rename duplicates",
+			shortLinks: map[string]entity.ShortLink{
+				"docs":   {Alias: "docs", LongLink: "https://github.com"},
+				"docs-2": {Alias: "docs-2", LongLink: "https://github.com"},
+			},
+			shortLinkInputs: []entity.ShortLinkInput{
+				newInput("docs", &createdAt),
+				newInput("docs", &createdAt),
+				newInput("home", &createdAt),
+			},
+			isDryRun:       false,
+			duplicateAlias: DuplicateAliasRename,
+			expectedReport: ImportReport{
+				Created: []entity.ShortLink{
+					{Alias: "docs-3", LongLink: longLink, OriginalLongLink: longLink, CreatedAt: &createdAt, TrackVisits: true},
+					{Alias: "docs-4", LongLink: longLink, OriginalLongLink: longLink, CreatedAt: &createdAt, TrackVisits: true},
+					{Alias: "home", LongLink: longLink, OriginalLongLink: longLink, CreatedAt: &createdAt, TrackVisits: true},
+				},
+				Collisions: []string{},
+				Duplicates: []ImportDuplicate{
+					{Alias: "docs", Resolution: DuplicateAliasRename, ResolvedAlias: "docs-3"},
+					{Alias: "docs", Resolution: DuplicateAliasRename, ResolvedAlias: "docs-4"},
+				},
+				Failures: []ImportFailure{},
+			},
+			expectedShortLinks: []string{"docs-3", "docs-4", "home"},
+			expectedLongLinks: map[string]string{
+				"docs":   "https://github.com",
+				"docs-2": "https://github.com",
+				"docs-3": longLink,
+				"docs-4": longLink,
+			},
+		},
+		{
+			name: "rename duplicates without valid aliases",
+			shortLinks: map[string]entity.ShortLink{
+				"abcdefghijklmnopqrstuvwxyzabcdefghijklmnopqrstuvw": {
+					Alias:    "abcdefghijklmnopqrstuvwxyzabcdefghijklmnopqrstuvw",
+					LongLink: "https://github.com",
+				},
+			},
+			shortLinkInputs: []entity.ShortLinkInput{
+				newInput("abcdefghijklmnopqrstuvwxyzabcdefghijklmnopqrstuvw", &createdAt),
+			},
+			isDryRun:       false,
+			duplicateAlias: DuplicateAliasRename,
+			expectedReport: ImportReport{
+				Created:    []entity.ShortLink{},
+				Collisions: []string{},
+				Duplicates: []ImportDuplicate{},
+				Failures: []ImportFailure{
+					{
+						Alias: "abcdefghijklmnopqrstuvwxyzabcdefghijklmnopqrstuvw",
+						Err:   ErrAliasExist("no available alias to rename abcdefghijklmnopqrstuvwxyzabcdefghijklmnopqrstuvw"),
+					},
+				},
+			},
+			expectedShortLinks: []string{},
+		},
+		{
+			name: "dry run resolving duplicates",
+			shortLinks: map[string]entity.ShortLink{
+				"docs": {Alias: "docs", LongLink: "https://github.com"},
+			},
+			ownedShortLinks: []entity.ShortLink{
+				{Alias: "docs"},
+			},
+			shortLinkInputs: []entity.ShortLinkInput{
+				newInput("docs", &createdAt),
+			},
+			isDryRun:       true,
+			duplicateAlias: DuplicateAliasOverwrite,
+			expectedReport: ImportReport{
+				Created:    []entity.ShortLink{},
+				Collisions: []string{},
+				Duplicates: []ImportDuplicate{
+					{Alias: "docs", Resolution: DuplicateAliasOverwrite, ResolvedAlias: "docs"},
+				},
+				Failures: []ImportFailure{},
+			},
+			expectedShortLinks: []string{"docs"},
+			expectedLongLinks: map[string]string{
+				"docs": "https://github.com",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			var owners []entity.User
+			for range testCase.ownedShortLinks {
+				owners = append(owners, owner)
+			}
+			userShortLinkRepo := repository.NewUserShortLinkRepoFake(owners, testCase.ownedShortLinks)
+			shortLinkRepo := repository.NewShortLinkFake(&userShortLinkRepo, testCase.shortLinks)
+			importer := NewImporterPersist(
+				&shortLinkRepo,
+				&userShortLinkRepo,
+				validator.NewLongLink(nil, nil, validator.FragmentPreserve, validator.PlainHTTPAllow),
+				validator.NewCustomAlias(),
+				timer.NewStub(now),
+			)
+
+			ctx := context.Background()
+			options := ImportOptions{
+				IsDryRun:       testCase.isDryRun,
+				DuplicateAlias: testCase.duplicateAlias,
+			}
+			report, err := importer.ImportShortLinks(ctx, testCase.shortLinkInputs, owner, options)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedReport, report)
+
+			aliases, err := userShortLinkRepo.FindAliasesByUser(ctx, owner)
+			assert.Equal(t, nil, err)
+			assert.SameElements(t, testCase.expectedShortLinks, aliases)
+
+			for _, shortLink := range testCase.expectedReport.Created {
+				if testCase.isDryRun {
+					break
+				}
+				saved, err := shortLinkRepo.GetShortLinkByAlias(ctx, shortLink.Alias)
+				assert.Equal(t, nil, err)
+				assert.Equal(t, shortLink.CreatedAt, saved.CreatedAt)
+			}
+
+			for alias, expectedLongLink := range testCase.expectedLongLinks {
+				saved, err := shortLinkRepo.GetShortLinkByAlias(ctx, alias)
+				assert.Equal(t, nil, err)
+				assert.Equal(t, expectedLongLink, saved.LongLink)
+			}
+		})
+	}
+}
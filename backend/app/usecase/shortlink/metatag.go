@@ -1,6 +1,8 @@
 package shortlink
 
 import (
+	"context"
+
 	"github.com/short-d/short/backend/app/entity/metatag"
 	"github.com/short-d/short/backend/app/usecase/repository"
 )
@@ -9,8 +11,8 @@ var _ MetaTag = (*MetaTagPersist)(nil)
 
 // MetaTag fetches and updates MetaTags for a short link.
 type MetaTag interface {
-	GetOpenGraphTags(alias string) (metatag.OpenGraph, error)
-	GetTwitterTags(alias string) (metatag.Twitter, error)
+	GetOpenGraphTags(ctx context.Context, alias string) (metatag.OpenGraph, error)
+	GetTwitterTags(ctx context.Context, alias string) (metatag.Twitter, error)
 }
 
 // MetaTagPersist fetches and updates MetaTags for a short link from persistent storage.
@@ -25,8 +27,9 @@ const (
 )
 
 // GetOpenGraphTags retrieves Open Graph tags for a short link from persistent storage given alias.
-func (m MetaTagPersist) GetOpenGraphTags(alias string) (metatag.OpenGraph, error) {
-	shortLink, err := m.shortLinkRepo.GetShortLinkByAlias(alias)
+// The short link is looked up within the tenant attached to ctx.
+func (m MetaTagPersist) GetOpenGraphTags(ctx context.Context, alias string) (metatag.OpenGraph, error) {
+	shortLink, err := m.shortLinkRepo.GetShortLinkByAlias(ctx, alias)
 	if err != nil {
 		return metatag.OpenGraph{}, err
 	}
@@ -50,8 +53,9 @@ func (m MetaTagPersist) GetOpenGraphTags(alias string) (metatag.OpenGraph, error
 }
 
 // GetTwitterTags retrieves Twitter tags for a short link from persistent storage given alias.
-func (m MetaTagPersist) GetTwitterTags(alias string) (metatag.Twitter, error) {
-	shortLink, err := m.shortLinkRepo.GetShortLinkByAlias(alias)
+// The short link is looked up within the tenant attached to ctx.
+func (m MetaTagPersist) GetTwitterTags(ctx context.Context, alias string) (metatag.Twitter, error) {
+	shortLink, err := m.shortLinkRepo.GetShortLinkByAlias(ctx, alias)
 	if err != nil {
 		return metatag.Twitter{}, err
 	}
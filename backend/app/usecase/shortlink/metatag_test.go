@@ -3,11 +3,13 @@
 package shortlink
 
 import (
+	"context"
 	"testing"
 
 	"github.com/short-d/app/fw/assert"
 	"github.com/short-d/short/backend/app/entity"
 	"github.com/short-d/short/backend/app/entity/metatag"
+	"github.com/short-d/short/backend/app/fw/tenant"
 	"github.com/short-d/short/backend/app/usecase/repository"
 )
 
@@ -128,7 +130,7 @@ func TestMetaTagPersist_GetOpenGraphTags(t *testing.T) {
 			shortLinkRepo := repository.NewShortLinkFake(nil, testCase.shortLinks)
 			metaTag := NewMetaTagPersist(&shortLinkRepo)
 
-			ogTags, err := metaTag.GetOpenGraphTags(testCase.alias)
+			ogTags, err := metaTag.GetOpenGraphTags(context.Background(), testCase.alias)
 			if testCase.expHasErr {
 				assert.NotEqual(t, nil, err)
 				return
@@ -256,7 +258,7 @@ func TestMetaTagPersist_GetTwitterTags(t *testing.T) {
 			shortLinkRepo := repository.NewShortLinkFake(nil, testCase.shortLinks)
 			metaTag := NewMetaTagPersist(&shortLinkRepo)
 
-			twitterTags, err := metaTag.GetTwitterTags(testCase.alias)
+			twitterTags, err := metaTag.GetTwitterTags(context.Background(), testCase.alias)
 			if testCase.expHasErr {
 				assert.NotEqual(t, nil, err)
 				return
@@ -267,3 +269,45 @@ func TestMetaTagPersist_GetTwitterTags(t *testing.T) {
 		})
 	}
 }
+
+func TestMetaTagPersist_Tenants(t *testing.T) {
+	t.Parallel()
+
+	acmeTitle := "Acme promo"
+	globexTitle := "Globex launch"
+	shortLinkRepo := repository.NewShortLinkFake(nil, shortLinks{
+		"promo": entity.ShortLink{
+			TenantID:      "acme",
+			Alias:         "promo",
+			OpenGraphTags: metatag.OpenGraph{Title: &acmeTitle},
+			TwitterTags:   metatag.Twitter{Title: &acmeTitle},
+		},
+		"launch": entity.ShortLink{
+			TenantID:      "globex",
+			Alias:         "launch",
+			OpenGraphTags: metatag.OpenGraph{Title: &globexTitle},
+			TwitterTags:   metatag.Twitter{Title: &globexTitle},
+		},
+	})
+	metaTag := NewMetaTagPersist(&shortLinkRepo)
+
+	acmeCtx := tenant.NewContext(context.Background(), "acme")
+	globexCtx := tenant.NewContext(context.Background(), "globex")
+
+	ogTags, err := metaTag.GetOpenGraphTags(acmeCtx, "promo")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, acmeTitle, *ogTags.Title)
+
+	twitterTags, err := metaTag.GetTwitterTags(globexCtx, "launch")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, globexTitle, *twitterTags.Title)
+
+	_, err = metaTag.GetOpenGraphTags(globexCtx, "promo")
+	assert.NotEqual(t, nil, err)
+
+	_, err = metaTag.GetTwitterTags(acmeCtx, "launch")
+	assert.NotEqual(t, nil, err)
+
+	_, err = metaTag.GetOpenGraphTags(context.Background(), "promo")
+	assert.NotEqual(t, nil, err)
+}
@@ -0,0 +1,68 @@
+package shortlink
+
+import (
+	"context"
+	"errors"
+
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+var _ OrphanPruner = (*OrphanPrunerPersist)(nil)
+
+// PruneReport summarizes the relations pruned. OrphanAliases counts the
+// aliases related to users which no longer exist.
+type PruneReport struct {
+	OrphanAliases    int
+	RemovedRelations int
+}
+
+// OrphanPruner removes the relations between users and short links which are
+// left behind after the short links are removed out of band.
+type OrphanPruner interface {
+	PruneOrphanRelations(ctx context.Context, batchSize int, isDryRun bool) (PruneReport, error)
+}
+
+// OrphanPrunerPersist prunes the orphaned relations in the repository.
+type OrphanPrunerPersist struct {
+	userShortLinkRepo repository.UserShortLink
+}
+
+// PruneOrphanRelations removes the relations of the aliases which no longer
+// exist, batchSize aliases at a time to avoid holding locks for long. The
+// orphaned aliases are only counted during a dry run.
+func (o OrphanPrunerPersist) PruneOrphanRelations(ctx context.Context, batchSize int, isDryRun bool) (PruneReport, error) {
+	if batchSize < 1 {
+		return PruneReport{}, errors.New("batch size can't be less than 1")
+	}
+
+	report := PruneReport{}
+	after := ""
+	for {
+		aliases, err := o.userShortLinkRepo.FindOrphanAliases(ctx, after, batchSize)
+		if err != nil {
+			return report, err
+		}
+		if len(aliases) == 0 {
+			return report, nil
+		}
+		report.OrphanAliases += len(aliases)
+		after = aliases[len(aliases)-1]
+
+		if !isDryRun {
+			count, err := o.userShortLinkRepo.DeleteOrphanRelations(ctx, aliases)
+			if err != nil {
+				return report, err
+			}
+			report.RemovedRelations += count
+		}
+
+		if len(aliases) < batchSize {
+			return report, nil
+		}
+	}
+}
+
+// NewOrphanPrunerPersist creates OrphanPrunerPersist
+func NewOrphanPrunerPersist(userShortLinkRepo repository.UserShortLink) OrphanPrunerPersist {
+	return OrphanPrunerPersist{userShortLinkRepo: userShortLinkRepo}
+}
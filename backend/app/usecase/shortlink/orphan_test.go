@@ -0,0 +1,151 @@
+// +build !integration all
+
+package shortlink
+
+import (
+	"context"
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+func TestOrphanPrunerPersist_PruneOrphanRelations(t *testing.T) {
+	t.Parallel()
+
+	alpha := entity.User{ID: "alpha"}
+	beta := entity.User{ID: "beta"}
+
+	testCases := []struct {
+		name            string
+		users           []entity.User
+		relatedLinks    []entity.ShortLink
+		shortLinks      shortLinks
+		batchSize       int
+		isDryRun        bool
+		hasErr          bool
+		expectedReport  PruneReport
+		expectedAliases map[string][]string
+	}{
+		{
+			name:         "orphan relation pruned and valid relation kept",
+			users:        []entity.User{alpha, alpha},
+			relatedLinks: []entity.ShortLink{{Alias: "google"}, {Alias: "gone"}},
+			shortLinks: shortLinks{
+				"google": {Alias: "google"},
+			},
+			batchSize: 10,
+			expectedReport: PruneReport{
+				OrphanAliases:    1,
+				RemovedRelations: 1,
+			},
+			expectedAliases: map[string][]string{
+				"alpha": {"google"},
+			},
+		},
+		{
+			name:  "orphan relations pruned in batches",
+			users: []entity.User{alpha, beta, alpha, beta},
+			relatedLinks: []entity.ShortLink{
+				{Alias: "bing"},
+				{Alias: "gone"},
+				{Alias: "lost"},
+				{Alias: "missing"},
+			},
+			shortLinks: shortLinks{
+				"bing": {Alias: "bing"},
+			},
+			batchSize: 1,
+			expectedReport: PruneReport{
+				OrphanAliases:    3,
+				RemovedRelations: 3,
+			},
+			expectedAliases: map[string][]string{
+				"alpha": {"bing"},
+				"beta":  nil,
+			},
+		},
+		{
+			name:         "relations of same alias pruned together",
+			users:        []entity.User{alpha, beta},
+			relatedLinks: []entity.ShortLink{{Alias: "gone"}, {Alias: "gone"}},
+			shortLinks:   shortLinks{},
+			batchSize:    10,
+			expectedReport: PruneReport{
+				OrphanAliases:    1,
+				RemovedRelations: 2,
+			},
+			expectedAliases: map[string][]string{
+				"alpha": nil,
+				"beta":  nil,
+			},
+		},
+		{
+			name:         "dry run",
+			users:        []entity.User{alpha, alpha, alpha},
+			relatedLinks: []entity.ShortLink{{Alias: "google"}, {Alias: "gone"}, {Alias: "lost"}},
+			shortLinks: shortLinks{
+				"google": {Alias: "google"},
+			},
+			batchSize: 1,
+			isDryRun:  true,
+			expectedReport: PruneReport{
+				OrphanAliases:    2,
+				RemovedRelations: 0,
+			},
+			expectedAliases: map[string][]string{
+				"alpha": {"google", "gone", "lost"},
+			},
+		},
+		{
+			name:         "no orphan relation",
+			users:        []entity.User{alpha},
+			relatedLinks: []entity.ShortLink{{Alias: "google"}},
+			shortLinks: shortLinks{
+				"google": {Alias: "google"},
+			},
+			batchSize:      10,
+			expectedReport: PruneReport{},
+			expectedAliases: map[string][]string{
+				"alpha": {"google"},
+			},
+		},
+		{
+			name:         "invalid batch size",
+			users:        []entity.User{alpha},
+			relatedLinks: []entity.ShortLink{{Alias: "gone"}},
+			shortLinks:   shortLinks{},
+			batchSize:    0,
+			hasErr:       true,
+			expectedAliases: map[string][]string{
+				"alpha": {"gone"},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			userShortLinkRepo := repository.NewUserShortLinkRepoFake(testCase.users, testCase.relatedLinks)
+			repository.NewShortLinkFake(&userShortLinkRepo, testCase.shortLinks)
+			pruner := NewOrphanPrunerPersist(&userShortLinkRepo)
+
+			report, err := pruner.PruneOrphanRelations(context.Background(), testCase.batchSize, testCase.isDryRun)
+			if testCase.hasErr {
+				assert.NotEqual(t, nil, err)
+			} else {
+				assert.Equal(t, nil, err)
+				assert.Equal(t, testCase.expectedReport, report)
+			}
+
+			for userID, expectedAliases := range testCase.expectedAliases {
+				aliases, err := userShortLinkRepo.FindAliasesByUser(context.Background(), entity.User{ID: userID})
+				assert.Equal(t, nil, err)
+				assert.Equal(t, expectedAliases, aliases)
+			}
+		})
+	}
+}
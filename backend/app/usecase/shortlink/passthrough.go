@@ -0,0 +1,136 @@
+package shortlink
+
+import (
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+)
+
+// QueryConflict decides which value is kept when a query parameter passed
+// through from the request to the short link is already in the long link.
+type QueryConflict string
+
+// The constants enumerate all supported ways to resolve query conflicts.
+const (
+	// QueryConflictKeepLongLink keeps the value in the long link, so that
+	// visitors can't override the query parameters set by the owner.
+	QueryConflictKeepLongLink QueryConflict = "keep_long_link"
+	// QueryConflictOverride replaces the value in the long link with the one
+	// in the request.
+	QueryConflictOverride QueryConflict = "override"
+	// QueryConflictAppend keeps the values in both, the long link's first.
+	QueryConflictAppend QueryConflict = "append"
+)
+
+// ErrUnknownQueryConflict represents the way to resolve query conflicts which
+// is not supported.
+type ErrUnknownQueryConflict string
+
+func (e ErrUnknownQueryConflict) Error() string {
+	return "unknown query conflict resolution: " + string(e)
+}
+
+// ParseQueryConflict converts the name of the resolution into QueryConflict.
+// Empty name keeps the values in the long link.
+func ParseQueryConflict(name string) (QueryConflict, error) {
+	conflict := QueryConflict(name)
+	switch conflict {
+	case "":
+		return QueryConflictKeepLongLink, nil
+	case QueryConflictKeepLongLink, QueryConflictOverride, QueryConflictAppend:
+		return conflict, nil
+	default:
+		return "", ErrUnknownQueryConflict(name)
+	}
+}
+
+// MergeQuery adds the query parameters of the request to the long link. The
+// query parameters already in the long link are kept as is, and the fragment
+// stays at the end of the long link.
+func (q QueryConflict) MergeQuery(longLink string, query url.Values) string {
+	if len(query) == 0 {
+		return longLink
+	}
+
+	fragment := ""
+	if idx := strings.Index(longLink, "#"); idx >= 0 {
+		longLink, fragment = longLink[:idx], longLink[idx:]
+	}
+	rawQuery := ""
+	if idx := strings.Index(longLink, "?"); idx >= 0 {
+		longLink, rawQuery = longLink[:idx], longLink[idx+1:]
+	}
+
+	linkKeys := make(map[string]bool)
+	var pairs []string
+	for _, pair := range strings.Split(rawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+		key := queryKey(pair)
+		linkKeys[key] = true
+		if _, ok := query[key]; ok && q == QueryConflictOverride {
+			continue
+		}
+		pairs = append(pairs, pair)
+	}
+
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if linkKeys[key] && q == QueryConflictKeepLongLink {
+			continue
+		}
+		for _, value := range query[key] {
+			pairs = append(pairs, url.QueryEscape(key)+"="+url.QueryEscape(value))
+		}
+	}
+
+	if len(pairs) == 0 {
+		return longLink + fragment
+	}
+	return longLink + "?" + strings.Join(pairs, "&") + fragment
+}
+
+// JoinPath appends the escaped path following the alias to the path of the
+// long link, before the query and the fragment of the long link. The paths
+// are joined with exactly one slash, and the dot segments of the extra path
+// are resolved so that it can't climb above the path of the long link.
+func JoinPath(longLink string, extraPath string) string {
+	extraPath = strings.TrimLeft(cleanPath(extraPath), "/")
+	if extraPath == "" {
+		return longLink
+	}
+
+	suffix := ""
+	if idx := strings.IndexAny(longLink, "?#"); idx >= 0 {
+		longLink, suffix = longLink[:idx], longLink[idx:]
+	}
+	return strings.TrimRight(longLink, "/") + "/" + extraPath + suffix
+}
+
+// cleanPath resolves the dot segments and repeated slashes of the path,
+// keeping the trailing slash.
+func cleanPath(p string) string {
+	if p == "" {
+		return ""
+	}
+	cleaned := path.Clean("/" + p)
+	if strings.HasSuffix(p, "/") && cleaned != "/" {
+		cleaned += "/"
+	}
+	return cleaned
+}
+
+func queryKey(pair string) string {
+	key := strings.SplitN(pair, "=", 2)[0]
+	unescaped, err := url.QueryUnescape(key)
+	if err != nil {
+		return key
+	}
+	return unescaped
+}
@@ -0,0 +1,233 @@
+// +build !integration all
+
+package shortlink
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+)
+
+func TestQueryConflict_MergeQuery(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name             string
+		conflict         QueryConflict
+		longLink         string
+		query            url.Values
+		expectedLongLink string
+	}{
+		{
+			name:             "no query",
+			conflict:         QueryConflictKeepLongLink,
+			longLink:         "https://example.com/promo?id=1",
+			query:            url.Values{},
+			expectedLongLink: "https://example.com/promo?id=1",
+		},
+		{
+			name:             "long link without query",
+			conflict:         QueryConflictKeepLongLink,
+			longLink:         "https://example.com/promo",
+			query:            url.Values{"ref": {"x"}},
+			expectedLongLink: "https://example.com/promo?ref=x",
+		},
+		{
+			name:             "merge with query of long link",
+			conflict:         QueryConflictKeepLongLink,
+			longLink:         "https://example.com/promo?id=1",
+			query:            url.Values{"ref": {"x"}, "utm_source": {"mail"}},
+			expectedLongLink: "https://example.com/promo?id=1&ref=x&utm_source=mail",
+		},
+		{
+			name:             "keep fragment at the end",
+			conflict:         QueryConflictKeepLongLink,
+			longLink:         "https://example.com/app?tab=1#/settings",
+			query:            url.Values{"ref": {"x"}},
+			expectedLongLink: "https://example.com/app?tab=1&ref=x#/settings",
+		},
+		{
+			name:             "escape query",
+			conflict:         QueryConflictKeepLongLink,
+			longLink:         "https://example.com/search?q=a%20b",
+			query:            url.Values{"next": {"/a?b=c&d"}},
+			expectedLongLink: "https://example.com/search?q=a%20b&next=%2Fa%3Fb%3Dc%26d",
+		},
+		{
+			name:             "conflict keeps value of long link",
+			conflict:         QueryConflictKeepLongLink,
+			longLink:         "https://example.com/promo?ref=owner&id=1",
+			query:            url.Values{"ref": {"x"}, "lang": {"en"}},
+			expectedLongLink: "https://example.com/promo?ref=owner&id=1&lang=en",
+		},
+		{
+			name:             "conflict overrides value of long link",
+			conflict:         QueryConflictOverride,
+			longLink:         "https://example.com/promo?ref=owner&id=1",
+			query:            url.Values{"ref": {"x"}, "lang": {"en"}},
+			expectedLongLink: "https://example.com/promo?id=1&lang=en&ref=x",
+		},
+		{
+			name:             "conflict overrides all values of long link",
+			conflict:         QueryConflictOverride,
+			longLink:         "https://example.com/promo?ref=a&ref=b",
+			query:            url.Values{"ref": {"x"}},
+			expectedLongLink: "https://example.com/promo?ref=x",
+		},
+		{
+			name:             "conflict appends values",
+			conflict:         QueryConflictAppend,
+			longLink:         "https://example.com/promo?ref=owner",
+			query:            url.Values{"ref": {"x", "y"}},
+			expectedLongLink: "https://example.com/promo?ref=owner&ref=x&ref=y",
+		},
+		{
+			name:             "conflict matches escaped keys",
+			conflict:         QueryConflictKeepLongLink,
+			longLink:         "https://example.com/promo?a%20b=owner",
+			query:            url.Values{"a b": {"x"}},
+			expectedLongLink: "https://example.com/promo?a%20b=owner",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			longLink := testCase.conflict.MergeQuery(testCase.longLink, testCase.query)
+			assert.Equal(t, testCase.expectedLongLink, longLink)
+		})
+	}
+}
+
+func TestJoinPath(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name             string
+		longLink         string
+		extraPath        string
+		expectedLongLink string
+	}{
+		{
+			name:             "no extra path",
+			longLink:         "https://example.com/docs",
+			extraPath:        "",
+			expectedLongLink: "https://example.com/docs",
+		},
+		{
+			name:             "only slash",
+			longLink:         "https://example.com/docs",
+			extraPath:        "/",
+			expectedLongLink: "https://example.com/docs",
+		},
+		{
+			name:             "append path",
+			longLink:         "https://example.com/docs",
+			extraPath:        "/guide/intro",
+			expectedLongLink: "https://example.com/docs/guide/intro",
+		},
+		{
+			name:             "long link with trailing slash",
+			longLink:         "https://example.com/docs/",
+			extraPath:        "/guide",
+			expectedLongLink: "https://example.com/docs/guide",
+		},
+		{
+			name:             "long link without path",
+			longLink:         "https://example.com",
+			extraPath:        "/guide",
+			expectedLongLink: "https://example.com/guide",
+		},
+		{
+			name:             "repeated slashes",
+			longLink:         "https://example.com/docs/",
+			extraPath:        "//guide//intro",
+			expectedLongLink: "https://example.com/docs/guide/intro",
+		},
+		{
+			name:             "keep trailing slash of extra path",
+			longLink:         "https://example.com/docs",
+			extraPath:        "/guide/",
+			expectedLongLink: "https://example.com/docs/guide/",
+		},
+		{
+			name:             "before query and fragment",
+			longLink:         "https://example.com/docs?lang=en#top",
+			extraPath:        "/guide",
+			expectedLongLink: "https://example.com/docs/guide?lang=en#top",
+		},
+		{
+			name:             "can't climb above long link",
+			longLink:         "https://example.com/docs",
+			extraPath:        "/../../admin",
+			expectedLongLink: "https://example.com/docs/admin",
+		},
+		{
+			name:             "keep escaped characters",
+			longLink:         "https://example.com/docs",
+			extraPath:        "/a%2Fb/c%20d",
+			expectedLongLink: "https://example.com/docs/a%2Fb/c%20d",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			longLink := JoinPath(testCase.longLink, testCase.extraPath)
+			assert.Equal(t, testCase.expectedLongLink, longLink)
+		})
+	}
+}
+
+func TestParseQueryConflict(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name             string
+		conflictName     string
+		expectedConflict QueryConflict
+		expectedErr      error
+	}{
+		{
+			name:             "empty name",
+			conflictName:     "",
+			expectedConflict: QueryConflictKeepLongLink,
+		},
+		{
+			name:             "keep long link",
+			conflictName:     "keep_long_link",
+			expectedConflict: QueryConflictKeepLongLink,
+		},
+		{
+			name:             "override",
+			conflictName:     "override",
+			expectedConflict: QueryConflictOverride,
+		},
+		{
+			name:             "append",
+			conflictName:     "append",
+			expectedConflict: QueryConflictAppend,
+		},
+		{
+			name:         "unknown name",
+			conflictName: "merge",
+			expectedErr:  ErrUnknownQueryConflict("merge"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			conflict, err := ParseQueryConflict(testCase.conflictName)
+			assert.Equal(t, testCase.expectedErr, err)
+			assert.Equal(t, testCase.expectedConflict, conflict)
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package shortlink
+
+import (
+	"context"
+
+	"github.com/short-d/short/backend/app/entity"
+)
+
+// AliasQuota represents the maximum number of active short links a user can
+// own, counted separately for custom aliases and auto generated aliases. Zero
+// means unlimited.
+type AliasQuota struct {
+	CustomAlias int
+	AutoAlias   int
+}
+
+// ErrAliasQuotaExceeded represents the user already owns the maximum number of
+// active short links allowed.
+type ErrAliasQuotaExceeded string
+
+func (e ErrAliasQuotaExceeded) Error() string {
+	return string(e)
+}
+
+// checkAliasQuota rejects the new short link when the user already reaches the
+// quota of its alias type. Admins are exempt from the quota. Expired short
+// links don't count towards the quota.
+func (c CreatorPersist) checkAliasQuota(ctx context.Context, user entity.User, isCustomAlias bool) error {
+	quota := c.aliasQuota.AutoAlias
+	if isCustomAlias {
+		quota = c.aliasQuota.CustomAlias
+	}
+	if quota <= 0 {
+		return nil
+	}
+
+	canBypass, err := c.authorizer.CanBypassAliasQuota(user)
+	if err != nil {
+		return err
+	}
+	if canBypass {
+		return nil
+	}
+
+	now := c.timer.Now().UTC()
+	count, err := c.userShortLinkRepo.CountAliasesByUser(ctx, user, isCustomAlias, now)
+	if err != nil {
+		return err
+	}
+	if count >= quota {
+		return ErrAliasQuotaExceeded("alias quota exceeded")
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+// +build !integration all
+
+package shortlink
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/metrics"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/fw/ptr"
+	"github.com/short-d/short/backend/app/usecase/authorizer"
+	"github.com/short-d/short/backend/app/usecase/authorizer/rbac"
+	"github.com/short-d/short/backend/app/usecase/authorizer/rbac/role"
+	"github.com/short-d/short/backend/app/usecase/email"
+	"github.com/short-d/short/backend/app/usecase/keygen"
+	"github.com/short-d/short/backend/app/usecase/maintenance"
+	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/risk"
+	"github.com/short-d/short/backend/app/usecase/validator"
+	"github.com/short-d/short/backend/app/usecase/webhook"
+)
+
+func TestShortLinkCreatorPersist_CreateShortLinkAliasQuota(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		roles         []role.Role
+		aliasQuota    AliasQuota
+		customAliases []string
+		expectedErrs  []error
+	}{
+		{
+			name:          "custom aliases within quota",
+			aliasQuota:    AliasQuota{CustomAlias: 2},
+			customAliases: []string{"alpha", "beta"},
+			expectedErrs: []error{
+				nil,
+				nil,
+			},
+		},
+		{
+			name:          "custom aliases exceed quota",
+			aliasQuota:    AliasQuota{CustomAlias: 2},
+			customAliases: []string{"alpha", "beta", "gamma"},
+			expectedErrs: []error{
+				nil,
+				nil,
+				ErrAliasQuotaExceeded("alias quota exceeded"),
+			},
+		},
+		{
+			name:          "auto aliases counted separately",
+			aliasQuota:    AliasQuota{CustomAlias: 1, AutoAlias: 2},
+			customAliases: []string{"alpha", "", "", "", "beta"},
+			expectedErrs: []error{
+				nil,
+				nil,
+				nil,
+				ErrAliasQuotaExceeded("alias quota exceeded"),
+				ErrAliasQuotaExceeded("alias quota exceeded"),
+			},
+		},
+		{
+			name:          "unlimited quota",
+			aliasQuota:    AliasQuota{},
+			customAliases: []string{"alpha", "beta", "gamma"},
+			expectedErrs: []error{
+				nil,
+				nil,
+				nil,
+			},
+		},
+		{
+			name:          "admin exempt from quota",
+			roles:         []role.Role{role.Admin},
+			aliasQuota:    AliasQuota{CustomAlias: 1},
+			customAliases: []string{"alpha", "beta", "gamma"},
+			expectedErrs: []error{
+				nil,
+				nil,
+				nil,
+			},
+		},
+		{
+			name:          "basic user not exempt from quota",
+			roles:         []role.Role{role.Basic},
+			aliasQuota:    AliasQuota{CustomAlias: 1},
+			customAliases: []string{"alpha", "beta"},
+			expectedErrs: []error{
+				nil,
+				ErrAliasQuotaExceeded("alias quota exceeded"),
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			user := entity.User{ID: "alpha", Email: "alpha@example.com"}
+			creator, _, _ := newQuotaCreator(t, user, testCase.roles, testCase.aliasQuota)
+
+			for idx, customAlias := range testCase.customAliases {
+				shortLinkInput := entity.ShortLinkInput{
+					LongLink:    ptr.String("https://www.google.com"),
+					CustomAlias: ptr.String(customAlias),
+				}
+				_, err := creator.CreateShortLink(context.Background(), shortLinkInput, user, false)
+				assert.Equal(t, testCase.expectedErrs[idx], err)
+			}
+		})
+	}
+}
+
+func TestShortLinkCreatorPersist_CreateShortLinkAliasQuotaFreed(t *testing.T) {
+	t.Parallel()
+
+	user := entity.User{ID: "alpha", Email: "alpha@example.com"}
+	creator, shortLinkRepo, tm := newQuotaCreator(t, user, nil, AliasQuota{CustomAlias: 2})
+
+	expireAt := tm.Now().Add(time.Hour)
+	shortLinkInputs := []entity.ShortLinkInput{
+		{
+			LongLink:    ptr.String("https://www.google.com"),
+			CustomAlias: ptr.String("alpha"),
+		},
+		{
+			LongLink:    ptr.String("https://www.google.com"),
+			CustomAlias: ptr.String("beta"),
+			ExpireAt:    &expireAt,
+		},
+	}
+	for _, shortLinkInput := range shortLinkInputs {
+		_, err := creator.CreateShortLink(context.Background(), shortLinkInput, user, false)
+		assert.Equal(t, nil, err)
+	}
+
+	gamma := entity.ShortLinkInput{
+		LongLink:    ptr.String("https://www.google.com"),
+		CustomAlias: ptr.String("gamma"),
+	}
+	_, err := creator.CreateShortLink(context.Background(), gamma, user, false)
+	assert.Equal(t, ErrAliasQuotaExceeded("alias quota exceeded"), err)
+
+	count, err := shortLinkRepo.DeleteShortLinks(context.Background(), []string{"alpha"})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, count)
+
+	_, err = creator.CreateShortLink(context.Background(), gamma, user, false)
+	assert.Equal(t, nil, err)
+
+	delta := entity.ShortLinkInput{
+		LongLink:    ptr.String("https://www.google.com"),
+		CustomAlias: ptr.String("delta"),
+	}
+	_, err = creator.CreateShortLink(context.Background(), delta, user, false)
+	assert.Equal(t, ErrAliasQuotaExceeded("alias quota exceeded"), err)
+
+	tm.CurrentTime = expireAt
+	_, err = creator.CreateShortLink(context.Background(), delta, user, false)
+	assert.Equal(t, nil, err)
+}
+
+func newQuotaCreator(
+	t *testing.T,
+	user entity.User,
+	roles []role.Role,
+	aliasQuota AliasQuota,
+) (CreatorPersist, *repository.ShortLinkFake, *timer.Stub) {
+	userShortLinkRepo := repository.NewUserShortLinkRepoFake(nil, nil)
+	shortLinkRepo := repository.NewShortLinkFake(&userShortLinkRepo, shortLinks{})
+	keyFetcher := keygen.NewKeyFetcherFake([]keygen.Key{"220uFicCJj", "yDOBcj5HIPbUAsw", "efpIZ4OS"})
+	keyGen, err := keygen.NewRemote(3, &keyFetcher)
+	assert.Equal(t, nil, err)
+	flaggedLinkRepo := repository.NewFlaggedShortLinkFake(nil)
+	userRoleRepo := repository.NewUserRoleFake(map[string][]role.Role{
+		user.ID: roles,
+	})
+	tm := timer.NewStub(time.Now().UTC())
+
+	preferencesRepo := repository.NewUserPreferencesFake(nil)
+	creator := NewCreatorPersist(
+		&shortLinkRepo,
+		&userShortLinkRepo,
+		keyGen,
+		validator.NewLongLink(nil, nil, validator.FragmentPreserve, validator.PlainHTTPAllow),
+		validator.NewCustomAlias(),
+		&tm,
+		risk.NewDetector(
+			risk.NewBlackListFake(nil),
+			risk.NewDenylistFake(nil),
+			risk.StrictThresholds,
+		),
+		&flaggedLinkRepo,
+		DefaultChecks,
+		LongLinkUniquenessNone,
+		aliasQuota,
+		authorizer.NewAuthorizer(rbac.NewRBAC(userRoleRepo)),
+		email.NewSenderFake(nil),
+		webhook.NewDispatcherFake(),
+		&preferencesRepo,
+		maintenance.Mode{},
+		metrics.NewFake(),
+		0,
+		ExpirationPolicy{},
+		&repository.AliasSkeletonFake{},
+		RelationRetry{},
+		&repository.AliasReservationFake{},
+		ReservationTTL{},
+		ChainedLink{},
+	)
+	return creator, &shortLinkRepo, &tm
+}
@@ -0,0 +1,79 @@
+package shortlink
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/fw/tenant"
+)
+
+// RelationRetry configures how attributing a new short link to its creator is
+// retried when it fails after the short link is saved. The wait between
+// attempts starts at Backoff and doubles after each failure. The relation is
+// created once when MaxAttempts is not positive.
+type RelationRetry struct {
+	MaxAttempts int
+	Backoff     time.Duration
+}
+
+// createRelationWithRetry makes at most MaxAttempts attempts to create the
+// relation, giving up early once the request is canceled.
+func (c CreatorPersist) createRelationWithRetry(
+	ctx context.Context,
+	shortLinkInput entity.ShortLinkInput,
+	isCustomAlias bool,
+	createRelation func(shortLinkInput entity.ShortLinkInput, isCustomAlias bool) error,
+) error {
+	backoff := c.relationRetry.Backoff
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = createRelation(shortLinkInput, isCustomAlias)
+		if err == nil || attempt >= c.relationRetry.MaxAttempts || ctx.Err() != nil {
+			return err
+		}
+		if wait(ctx, c.timer, backoff) != nil {
+			return err
+		}
+		backoff *= 2
+	}
+}
+
+// rollbackShortLink deletes the short link whose relation can't be created,
+// so that the alias is not left taken by a short link nobody owns. The short
+// link is deleted even if the request is canceled.
+func (c CreatorPersist) rollbackShortLink(ctx context.Context, alias string, relationErr error) error {
+	rollbackCtx := tenant.NewContext(context.Background(), tenant.FromContext(ctx))
+	_, err := c.shortLinkRepo.DeleteShortLinks(rollbackCtx, []string{alias})
+	if err != nil {
+		return fmt.Errorf("fail to roll back short link %s: %v, after: %w", alias, err, relationErr)
+	}
+	return relationErr
+}
+
+// wait blocks until the timer ticks once the duration passes, returning the
+// error of the context early once the request is canceled.
+func wait(ctx context.Context, tm timer.Timer, duration time.Duration) error {
+	if duration <= 0 {
+		return nil
+	}
+
+	ticked := make(chan bool, 1)
+	stop := tm.Ticker(duration, func() {
+		select {
+		case ticked <- true:
+		default:
+		}
+	})
+	defer close(stop)
+
+	select {
+	case <-ticked:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
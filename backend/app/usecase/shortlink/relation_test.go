@@ -0,0 +1,199 @@
+// +build !integration all
+
+package shortlink
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/metrics"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/fw/ptr"
+	"github.com/short-d/short/backend/app/fw/timertest"
+	"github.com/short-d/short/backend/app/usecase/authorizer"
+	"github.com/short-d/short/backend/app/usecase/email"
+	"github.com/short-d/short/backend/app/usecase/keygen"
+	"github.com/short-d/short/backend/app/usecase/maintenance"
+	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/risk"
+	"github.com/short-d/short/backend/app/usecase/validator"
+	"github.com/short-d/short/backend/app/usecase/webhook"
+)
+
+// unstableUserShortLinkRepo fails to create the first few relations, as if
+// the database connection dropped for a moment.
+type unstableUserShortLinkRepo struct {
+	*repository.UserShortLinkFake
+	failures int
+	attempts *int
+}
+
+func (u unstableUserShortLinkRepo) CreateRelation(
+	ctx context.Context,
+	user entity.User,
+	shortLinkInput entity.ShortLinkInput,
+	isCustomAlias bool,
+) error {
+	*u.attempts++
+	if *u.attempts <= u.failures {
+		return errors.New("connection reset")
+	}
+	return u.UserShortLinkFake.CreateRelation(ctx, user, shortLinkInput, isCustomAlias)
+}
+
+func TestShortLinkCreatorPersist_CreateShortLinkRelationRetry(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 5, 1, 10, 30, 0, 0, time.UTC)
+	user := entity.User{ID: "alpha", Email: "alpha@example.com"}
+
+	testCases := []struct {
+		name             string
+		failures         int
+		relationRetry    RelationRetry
+		hasErr           bool
+		expectedAttempts int
+		expectedWait     time.Duration
+	}{
+		{
+			name:             "relation created at first attempt",
+			failures:         0,
+			relationRetry:    RelationRetry{MaxAttempts: 3, Backoff: 10 * time.Millisecond},
+			expectedAttempts: 1,
+		},
+		{
+			name:             "relation created after two failures",
+			failures:         2,
+			relationRetry:    RelationRetry{MaxAttempts: 3, Backoff: 10 * time.Millisecond},
+			expectedAttempts: 3,
+			expectedWait:     30 * time.Millisecond,
+		},
+		{
+			name:             "relation never created",
+			failures:         5,
+			relationRetry:    RelationRetry{MaxAttempts: 3, Backoff: 10 * time.Millisecond},
+			hasErr:           true,
+			expectedAttempts: 3,
+			expectedWait:     30 * time.Millisecond,
+		},
+		{
+			name:             "relation created once without retry",
+			failures:         1,
+			relationRetry:    RelationRetry{},
+			hasErr:           true,
+			expectedAttempts: 1,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			fakeUserShortLinkRepo := repository.NewUserShortLinkRepoFake(nil, nil)
+			attempts := 0
+			userShortLinkRepo := unstableUserShortLinkRepo{
+				UserShortLinkFake: &fakeUserShortLinkRepo,
+				failures:          testCase.failures,
+				attempts:          &attempts,
+			}
+			shortLinkRepo := repository.NewShortLinkFake(&fakeUserShortLinkRepo, shortLinks{})
+			keyFetcher := keygen.NewKeyFetcherFake([]keygen.Key{})
+			keyGen, err := keygen.NewRemote(2, &keyFetcher)
+			assert.Equal(t, nil, err)
+			flaggedLinkRepo := repository.NewFlaggedShortLinkFake(nil)
+			preferencesRepo := repository.NewUserPreferencesFake(nil)
+			fakeTimer := timertest.NewFakeTimer(now)
+
+			creator := NewCreatorPersist(
+				&shortLinkRepo,
+				userShortLinkRepo,
+				keyGen,
+				validator.NewLongLink(nil, nil, validator.FragmentPreserve, validator.PlainHTTPAllow),
+				validator.NewCustomAlias(),
+				fakeTimer,
+				risk.NewDetector(
+					risk.NewBlackListFake(nil),
+					risk.NewDenylistFake(nil),
+					risk.StrictThresholds,
+				),
+				&flaggedLinkRepo,
+				DefaultChecks,
+				LongLinkUniquenessNone,
+				AliasQuota{},
+				authorizer.Authorizer{},
+				email.NewSenderFake(nil),
+				webhook.NewDispatcherFake(),
+				&preferencesRepo,
+				maintenance.Mode{},
+				metrics.NewFake(),
+				0,
+				ExpirationPolicy{},
+				&repository.AliasSkeletonFake{},
+				testCase.relationRetry,
+				&repository.AliasReservationFake{},
+				ReservationTTL{},
+				ChainedLink{},
+			)
+
+			shortLinkInput := entity.ShortLinkInput{
+				LongLink:    ptr.String("https://www.google.com"),
+				CustomAlias: ptr.String("google"),
+			}
+			created := make(chan error)
+			go func() {
+				_, err := creator.CreateShortLink(context.Background(), shortLinkInput, user, false)
+				created <- err
+			}()
+
+			// Keep the time moving in small steps until the creation finishes
+			// waiting for the backoff.
+			for isCreating := true; isCreating; {
+				select {
+				case err = <-created:
+					isCreating = false
+				case <-time.After(time.Millisecond):
+					fakeTimer.Advance(time.Millisecond)
+				}
+			}
+
+			assert.Equal(t, testCase.expectedAttempts, attempts)
+			assert.Equal(t, true, fakeTimer.Now().Sub(now) >= testCase.expectedWait)
+
+			isExist, isExistErr := shortLinkRepo.IsAliasExist(context.Background(), "google")
+			assert.Equal(t, nil, isExistErr)
+			hasMapping, hasMappingErr := fakeUserShortLinkRepo.HasMapping(context.Background(), user, "google")
+			assert.Equal(t, nil, hasMappingErr)
+			if testCase.hasErr {
+				assert.NotEqual(t, nil, err)
+				assert.Equal(t, false, isExist)
+				assert.Equal(t, false, hasMapping)
+				return
+			}
+			assert.Equal(t, nil, err)
+			assert.Equal(t, true, isExist)
+			assert.Equal(t, true, hasMapping)
+		})
+	}
+}
+
+func TestWait_Canceled(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 5, 1, 10, 30, 0, 0, time.UTC)
+	fakeTimer := timertest.NewFakeTimer(now)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	waited := make(chan error)
+	go func() {
+		waited <- wait(ctx, fakeTimer, time.Hour)
+	}()
+	cancel()
+
+	err := <-waited
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, now, fakeTimer.Now())
+}
@@ -0,0 +1,174 @@
+package shortlink
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+const reservationTokenBytes = 16
+
+// ReservationTTL configures how long custom aliases can be reserved before the
+// short links are created under them. Default applies when the user doesn't
+// ask for a TTL, while Max bounds the TTL asked for.
+type ReservationTTL struct {
+	Default time.Duration
+	Max     time.Duration
+}
+
+// ErrAliasReserved represents the custom alias is held by another user for the
+// time being.
+type ErrAliasReserved string
+
+func (e ErrAliasReserved) Error() string {
+	return string(e)
+}
+
+// ErrInvalidReservationTTL represents the user asks to reserve the alias for
+// a negative duration or longer than allowed.
+type ErrInvalidReservationTTL string
+
+func (e ErrInvalidReservationTTL) Error() string {
+	return string(e)
+}
+
+// ReserveAlias holds the custom alias for the user without a long link, so
+// that the short link can be created under the alias later with the returned
+// token. The alias is taken for everyone else until the reservation expires
+// after the TTL, which falls back to the default TTL when it is zero.
+// Reserving again before the reservation expires extends it with a new token.
+func (c CreatorPersist) ReserveAlias(
+	ctx context.Context,
+	alias string,
+	user entity.User,
+	ttl time.Duration,
+) (entity.AliasReservation, error) {
+	if c.maintenanceMode.IsReadOnly() {
+		return entity.AliasReservation{}, ErrServiceReadOnly("reserve alias")
+	}
+
+	if ttl == 0 {
+		ttl = c.reservationTTL.Default
+	}
+	if ttl < 0 || ttl > c.reservationTTL.Max {
+		msg := fmt.Sprintf("alias can be reserved for at most %v", c.reservationTTL.Max)
+		return entity.AliasReservation{}, ErrInvalidReservationTTL(msg)
+	}
+
+	alias = normalizeAlias(alias)
+	isValid, violation := c.aliasValidator.IsValid(alias)
+	if !isValid {
+		return entity.AliasReservation{}, ErrInvalidCustomAlias{alias, violation}
+	}
+
+	isExist, err := c.shortLinkRepo.IsAliasExist(ctx, alias)
+	if err != nil {
+		return entity.AliasReservation{}, err
+	}
+	if isExist {
+		return entity.AliasReservation{}, ErrAliasExist("short link alias already exist")
+	}
+
+	token, err := newReservationToken()
+	if err != nil {
+		return entity.AliasReservation{}, err
+	}
+
+	now := c.timer.Now().UTC()
+	reservation := entity.AliasReservation{
+		Alias:    alias,
+		UserID:   user.ID,
+		Token:    token,
+		ExpireAt: now.Add(ttl),
+	}
+	isReserved, err := c.reservationRepo.CreateReservation(ctx, reservation, now)
+	if err != nil {
+		return entity.AliasReservation{}, err
+	}
+	if !isReserved {
+		return entity.AliasReservation{}, ErrAliasReserved("alias reserved by another user")
+	}
+	return reservation, nil
+}
+
+// isAliasTaken checks whether the alias is used by a short link or held by a
+// reservation.
+func (c CreatorPersist) isAliasTaken(ctx context.Context, alias string) (bool, error) {
+	isExist, err := c.shortLinkRepo.IsAliasExist(ctx, alias)
+	if err != nil || isExist {
+		return isExist, err
+	}
+	_, isReserved, err := findActiveReservation(ctx, c.reservationRepo, alias, c.timer.Now().UTC())
+	return isReserved, err
+}
+
+// findActiveReservation retrieves the reservation of the alias which hasn't
+// expired by now, if any.
+func findActiveReservation(
+	ctx context.Context,
+	reservationRepo repository.AliasReservation,
+	alias string,
+	now time.Time,
+) (entity.AliasReservation, bool, error) {
+	reservation, err := reservationRepo.FindReservation(ctx, alias)
+	var notFound repository.ErrEntryNotFound
+	if errors.As(err, &notFound) {
+		return entity.AliasReservation{}, false, nil
+	}
+	if err != nil {
+		return entity.AliasReservation{}, false, err
+	}
+	if !reservation.ExpireAt.After(now) {
+		return entity.AliasReservation{}, false, nil
+	}
+	return reservation, true, nil
+}
+
+// checkReservation rejects the custom alias held by a reservation unless the
+// user holds it with the given token. Guests can't hold reservations.
+func checkReservation(
+	ctx context.Context,
+	reservationRepo repository.AliasReservation,
+	alias string,
+	token string,
+	user *entity.User,
+	now time.Time,
+) error {
+	reservation, found, err := findActiveReservation(ctx, reservationRepo, alias, now)
+	if err != nil || !found {
+		return err
+	}
+	if user != nil && user.ID == reservation.UserID && token == reservation.Token {
+		return nil
+	}
+	return ErrAliasReserved("alias reserved by another user")
+}
+
+// releaseReservation deletes the reservation claimed by the short link. The
+// failure is ignored since the reservation expires anyway.
+func releaseReservation(
+	ctx context.Context,
+	reservationRepo repository.AliasReservation,
+	alias string,
+	token string,
+) {
+	if token == "" {
+		return
+	}
+	_ = reservationRepo.DeleteReservation(ctx, alias, token)
+}
+
+func newReservationToken() (string, error) {
+	buf := make([]byte, reservationTokenBytes)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,403 @@
+// +build !integration all
+
+package shortlink
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/metrics"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/fw/ptr"
+	"github.com/short-d/short/backend/app/fw/timertest"
+	"github.com/short-d/short/backend/app/usecase/authorizer"
+	"github.com/short-d/short/backend/app/usecase/email"
+	"github.com/short-d/short/backend/app/usecase/keygen"
+	"github.com/short-d/short/backend/app/usecase/maintenance"
+	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/risk"
+	"github.com/short-d/short/backend/app/usecase/validator"
+	"github.com/short-d/short/backend/app/usecase/webhook"
+)
+
+const (
+	testReservationTTL    = 10 * time.Minute
+	testReservationMaxTTL = 24 * time.Hour
+)
+
+func TestShortLinkCreatorPersist_ReserveAlias(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 5, 1, 10, 30, 0, 0, time.UTC)
+	alpha := entity.User{ID: "alpha"}
+	beta := entity.User{ID: "beta"}
+
+	testCases := []struct {
+		name        string
+		shortLinks  shortLinks
+		holder      *entity.User
+		elapsed     time.Duration
+		alias       string
+		user        entity.User
+		ttl         time.Duration
+		hasErr      bool
+		expectedErr error
+		expectedTTL time.Duration
+	}{
+		{
+			name:        "alias not reserved",
+			alias:       "google",
+			user:        alpha,
+			expectedTTL: testReservationTTL,
+		},
+		{
+			name:        "alias reserved for custom TTL",
+			alias:       "google",
+			user:        alpha,
+			ttl:         testReservationMaxTTL,
+			expectedTTL: testReservationMaxTTL,
+		},
+		{
+			name:        "TTL longer than allowed",
+			alias:       "google",
+			user:        alpha,
+			ttl:         testReservationMaxTTL + time.Second,
+			hasErr:      true,
+			expectedErr: ErrInvalidReservationTTL("alias can be reserved for at most 24h0m0s"),
+		},
+		{
+			name:        "negative TTL",
+			alias:       "google",
+			user:        alpha,
+			ttl:         -time.Second,
+			hasErr:      true,
+			expectedErr: ErrInvalidReservationTTL("alias can be reserved for at most 24h0m0s"),
+		},
+		{
+			name:        "alias reserved by another user",
+			holder:      &beta,
+			alias:       "google",
+			user:        alpha,
+			hasErr:      true,
+			expectedErr: ErrAliasReserved("alias reserved by another user"),
+		},
+		{
+			name:        "reservation of another user expired",
+			holder:      &beta,
+			elapsed:     testReservationTTL,
+			alias:       "google",
+			user:        alpha,
+			expectedTTL: testReservationTTL,
+		},
+		{
+			name:        "alias reserved again by same user",
+			holder:      &alpha,
+			alias:       "google",
+			user:        alpha,
+			expectedTTL: testReservationTTL,
+		},
+		{
+			name: "alias taken by short link",
+			shortLinks: shortLinks{
+				"google": entity.ShortLink{Alias: "google", LongLink: "https://www.google.com"},
+			},
+			alias:       "google",
+			user:        alpha,
+			hasErr:      true,
+			expectedErr: ErrAliasExist("short link alias already exist"),
+		},
+		{
+			name:        "alias reserved by routes",
+			alias:       "api",
+			user:        alpha,
+			hasErr:      true,
+			expectedErr: ErrInvalidCustomAlias{"api", validator.ReservedAlias},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			fakeTimer := timertest.NewFakeTimer(now)
+			creator, _ := newReservationCreator(t, testCase.shortLinks, nil, fakeTimer)
+
+			ctx := context.Background()
+			if testCase.holder != nil {
+				_, err := creator.ReserveAlias(ctx, testCase.alias, *testCase.holder, 0)
+				assert.Equal(t, nil, err)
+			}
+			fakeTimer.Advance(testCase.elapsed)
+
+			reservation, err := creator.ReserveAlias(ctx, testCase.alias, testCase.user, testCase.ttl)
+			if testCase.hasErr {
+				assert.Equal(t, testCase.expectedErr, err)
+				return
+			}
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.alias, reservation.Alias)
+			assert.Equal(t, testCase.user.ID, reservation.UserID)
+			assert.Equal(t, now.Add(testCase.elapsed+testCase.expectedTTL), reservation.ExpireAt)
+			assert.NotEqual(t, "", reservation.Token)
+		})
+	}
+}
+
+func TestShortLinkCreatorPersist_CreateShortLinkReserved(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 5, 1, 10, 30, 0, 0, time.UTC)
+	alpha := entity.User{ID: "alpha"}
+	beta := entity.User{ID: "beta"}
+
+	testCases := []struct {
+		name        string
+		user        *entity.User
+		useToken    bool
+		elapsed     time.Duration
+		hasErr      bool
+		expectedErr error
+		isReleased  bool
+	}{
+		{
+			name:       "holder creates with token",
+			user:       &alpha,
+			useToken:   true,
+			isReleased: true,
+		},
+		{
+			name:        "holder creates without token",
+			user:        &alpha,
+			useToken:    false,
+			hasErr:      true,
+			expectedErr: ErrAliasReserved("alias reserved by another user"),
+		},
+		{
+			name:        "another user creates with stolen token",
+			user:        &beta,
+			useToken:    true,
+			hasErr:      true,
+			expectedErr: ErrAliasReserved("alias reserved by another user"),
+		},
+		{
+			name:        "guest creates with stolen token",
+			useToken:    true,
+			hasErr:      true,
+			expectedErr: ErrAliasReserved("alias reserved by another user"),
+		},
+		{
+			name:    "another user creates after reservation expired",
+			user:    &beta,
+			elapsed: testReservationTTL,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			fakeTimer := timertest.NewFakeTimer(now)
+			creator, reservationRepo := newReservationCreator(t, shortLinks{}, nil, fakeTimer)
+
+			ctx := context.Background()
+			reservation, err := creator.ReserveAlias(ctx, "google", alpha, 0)
+			assert.Equal(t, nil, err)
+			fakeTimer.Advance(testCase.elapsed)
+
+			shortLinkInput := entity.ShortLinkInput{
+				LongLink:    ptr.String("https://www.google.com"),
+				CustomAlias: ptr.String("google"),
+			}
+			if testCase.useToken {
+				shortLinkInput.ReservationToken = &reservation.Token
+			}
+			if testCase.user == nil {
+				_, err = creator.CreateGuestShortLink(ctx, shortLinkInput, "session")
+			} else {
+				_, err = creator.CreateShortLink(ctx, shortLinkInput, *testCase.user, false)
+			}
+
+			_, findErr := reservationRepo.FindReservation(ctx, "google")
+			assert.Equal(t, !testCase.isReleased, findErr == nil)
+
+			// The alias is either held by the reservation or taken by the new
+			// short link.
+			preview, previewErr := creator.PreviewShortLink(ctx, entity.ShortLinkInput{
+				LongLink:    ptr.String("https://www.google.com"),
+				CustomAlias: ptr.String("google"),
+			})
+			assert.Equal(t, nil, previewErr)
+			assert.Equal(t, false, preview.IsAliasAvailable)
+
+			if testCase.hasErr {
+				assert.Equal(t, testCase.expectedErr, err)
+				return
+			}
+			assert.Equal(t, nil, err)
+		})
+	}
+}
+
+func TestShortLinkCreatorPersist_CreateShortLinkAutoAliasReserved(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 5, 1, 10, 30, 0, 0, time.UTC)
+	alpha := entity.User{ID: "alpha"}
+	beta := entity.User{ID: "beta"}
+
+	fakeTimer := timertest.NewFakeTimer(now)
+	creator, _ := newReservationCreator(t, shortLinks{}, []keygen.Key{"held", "free"}, fakeTimer)
+
+	ctx := context.Background()
+	_, err := creator.ReserveAlias(ctx, "held", alpha, 0)
+	assert.Equal(t, nil, err)
+
+	shortLink, err := creator.CreateShortLink(ctx, entity.ShortLinkInput{
+		LongLink: ptr.String("https://www.google.com"),
+	}, beta, false)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "free", shortLink.Alias)
+}
+
+func TestShortLinkUpdaterPersist_UpdateShortLinkReserved(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 5, 1, 10, 30, 0, 0, time.UTC)
+	alpha := entity.User{ID: "alpha"}
+	beta := entity.User{ID: "beta"}
+
+	testCases := []struct {
+		name        string
+		user        entity.User
+		useToken    bool
+		hasErr      bool
+		expectedErr error
+	}{
+		{
+			name:     "holder renames with token",
+			user:     alpha,
+			useToken: true,
+		},
+		{
+			name:        "holder renames without token",
+			user:        alpha,
+			hasErr:      true,
+			expectedErr: ErrAliasReserved("alias reserved by another user"),
+		},
+		{
+			name:        "another user renames with stolen token",
+			user:        beta,
+			useToken:    true,
+			hasErr:      true,
+			expectedErr: ErrAliasReserved("alias reserved by another user"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			fakeTimer := timertest.NewFakeTimer(now)
+			userShortLinkRepo := repository.NewUserShortLinkRepoFake(
+				[]entity.User{testCase.user},
+				[]entity.ShortLink{{Alias: "old", LongLink: "https://www.google.com"}},
+			)
+			shortLinkRepo := repository.NewShortLinkFake(&userShortLinkRepo, shortLinks{
+				"old": entity.ShortLink{Alias: "old", LongLink: "https://www.google.com"},
+			})
+			reservationRepo := &repository.AliasReservationFake{}
+			reservation := entity.AliasReservation{
+				Alias:    "google",
+				UserID:   alpha.ID,
+				Token:    "token",
+				ExpireAt: now.Add(testReservationTTL),
+			}
+			_, err := reservationRepo.CreateReservation(context.Background(), reservation, now)
+			assert.Equal(t, nil, err)
+
+			updater := NewUpdaterPersist(
+				&shortLinkRepo,
+				&userShortLinkRepo,
+				validator.NewLongLink(nil, nil, validator.FragmentPreserve, validator.PlainHTTPAllow),
+				validator.NewCustomAlias(),
+				fakeTimer,
+				risk.NewDetector(risk.NewBlackListFake(nil), risk.NewDenylistFake(nil), risk.StrictThresholds),
+				DefaultChecks,
+				&repository.AliasSkeletonFake{},
+				&repository.FlaggedShortLinkFake{},
+				email.NewSenderFake(nil),
+				maintenance.Mode{},
+				reservationRepo,
+				ChainedLink{},
+			)
+
+			update := entity.ShortLinkInput{CustomAlias: ptr.String("google")}
+			if testCase.useToken {
+				update.ReservationToken = ptr.String(reservation.Token)
+			}
+			shortLink, err := updater.UpdateShortLink(context.Background(), "old", update, testCase.user)
+			if testCase.hasErr {
+				assert.Equal(t, testCase.expectedErr, err)
+				return
+			}
+			assert.Equal(t, nil, err)
+			assert.Equal(t, "google", shortLink.Alias)
+
+			_, err = reservationRepo.FindReservation(context.Background(), "google")
+			assert.NotEqual(t, nil, err)
+		})
+	}
+}
+
+func newReservationCreator(
+	t *testing.T,
+	shortLinks shortLinks,
+	keys []keygen.Key,
+	fakeTimer *timertest.FakeTimer,
+) (CreatorPersist, *repository.AliasReservationFake) {
+	userShortLinkRepo := repository.NewUserShortLinkRepoFake(nil, nil)
+	shortLinkRepo := repository.NewShortLinkFake(&userShortLinkRepo, shortLinks)
+	keyFetcher := keygen.NewKeyFetcherFake(keys)
+	keyGen, err := keygen.NewRemote(2, &keyFetcher)
+	assert.Equal(t, nil, err)
+	flaggedLinkRepo := repository.NewFlaggedShortLinkFake(nil)
+	preferencesRepo := repository.NewUserPreferencesFake(nil)
+	reservationRepo := &repository.AliasReservationFake{}
+
+	creator := NewCreatorPersist(
+		&shortLinkRepo,
+		&userShortLinkRepo,
+		keyGen,
+		validator.NewLongLink(nil, nil, validator.FragmentPreserve, validator.PlainHTTPAllow),
+		validator.NewCustomAlias(),
+		fakeTimer,
+		risk.NewDetector(
+			risk.NewBlackListFake(nil),
+			risk.NewDenylistFake(nil),
+			risk.StrictThresholds,
+		),
+		&flaggedLinkRepo,
+		DefaultChecks,
+		LongLinkUniquenessNone,
+		AliasQuota{},
+		authorizer.Authorizer{},
+		email.NewSenderFake(nil),
+		webhook.NewDispatcherFake(),
+		&preferencesRepo,
+		maintenance.Mode{},
+		metrics.NewFake(),
+		1,
+		ExpirationPolicy{},
+		&repository.AliasSkeletonFake{},
+		RelationRetry{},
+		reservationRepo,
+		ReservationTTL{Default: testReservationTTL, Max: testReservationMaxTTL},
+		ChainedLink{},
+	)
+	return creator, reservationRepo
+}
@@ -1,7 +1,10 @@
 package shortlink
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/short-d/short/backend/app/entity"
@@ -10,10 +13,65 @@ import (
 
 var _ Retriever = (*RetrieverPersist)(nil)
 
+// ErrShortLinkExpired represents the short link exists but can no longer
+// redirect users.
+type ErrShortLinkExpired string
+
+func (e ErrShortLinkExpired) Error() string {
+	return string(e)
+}
+
+// ErrInvalidPageSize represents the number of ShortLinks requested in a page
+// is out of the supported range.
+type ErrInvalidPageSize string
+
+func (e ErrInvalidPageSize) Error() string {
+	return string(e)
+}
+
+// ErrInvalidPageNumber represents the requested page number is not positive
+// or too large to be paged.
+type ErrInvalidPageNumber string
+
+func (e ErrInvalidPageNumber) Error() string {
+	return string(e)
+}
+
+// MaxPageSize is the maximum number of ShortLinks returned in a page.
+const MaxPageSize = 100
+
+// MaxRecentShortLinks is the maximum number of recently created ShortLinks
+// returned at once.
+const MaxRecentShortLinks = 50
+
+// ShortLinkPage represents a page of ShortLinks created by a user, from the
+// most recently created one. EndCursor is empty when the page has no
+// ShortLink.
+type ShortLinkPage struct {
+	ShortLinks  []entity.ShortLink
+	EndCursor   string
+	HasNextPage bool
+}
+
+// NumberedShortLinkPage represents a page of ShortLinks created by a user
+// located by its page number, in the same order as ShortLinkPage. TotalCount
+// is the number of ShortLinks the user created across all pages.
+type NumberedShortLinkPage struct {
+	ShortLinks []entity.ShortLink
+	TotalCount int
+}
+
 // Retriever represents ShortLink retriever
 type Retriever interface {
-	GetShortLink(alias string, expiringAt *time.Time) (entity.ShortLink, error)
-	GetShortLinksByUser(user entity.User) ([]entity.ShortLink, error)
+	GetShortLink(ctx context.Context, alias string, expiringAt *time.Time) (entity.ShortLink, error)
+	GetShortLinksByUser(ctx context.Context, user entity.User) ([]entity.ShortLink, error)
+	GetShortLinksByAliases(ctx context.Context, user entity.User, aliases []string) ([]entity.ShortLink, error)
+	GetShortLinkPageByUser(ctx context.Context, user entity.User, first int, after string) (ShortLinkPage, error)
+	GetNumberedShortLinkPageByUser(ctx context.Context, user entity.User, page int, pageSize int) (NumberedShortLinkPage, error)
+	GetRecentShortLinksByUser(ctx context.Context, user entity.User, limit int) ([]entity.ShortLink, error)
+	IsOwner(ctx context.Context, alias string, user entity.User) (bool, error)
+	GetExpiryState(shortLink entity.ShortLink, at time.Time) ExpiryState
+	ConsumeVisit(ctx context.Context, shortLink entity.ShortLink) error
 }
 
 // RetrieverPersist represents ShortLink retriever that fetches ShortLink from persistent
@@ -21,56 +79,256 @@ type Retriever interface {
 type RetrieverPersist struct {
 	shortLinkRepo     repository.ShortLink
 	userShortLinkRepo repository.UserShortLink
+	expiryGrace       time.Duration
 }
 
-// GetShortLink retrieves ShortLink from persistent storage given alias
-func (r RetrieverPersist) GetShortLink(alias string, expiringAt *time.Time) (entity.ShortLink, error) {
+// GetShortLink retrieves ShortLink from persistent storage given alias. When
+// expiringAt is provided, the short links expired before expiringAt are still
+// retrieved within the expiry grace window.
+func (r RetrieverPersist) GetShortLink(ctx context.Context, alias string, expiringAt *time.Time) (entity.ShortLink, error) {
 	if expiringAt == nil {
-		return r.getShortLink(alias)
+		return r.getShortLink(ctx, alias)
 	}
-	return r.getShortLinkExpireAfter(alias, *expiringAt)
+	return r.getShortLinkExpireAfter(ctx, alias, *expiringAt)
 }
 
-func (r RetrieverPersist) getShortLinkExpireAfter(alias string, expiringAt time.Time) (entity.ShortLink, error) {
-	shortLink, err := r.getShortLink(alias)
+func (r RetrieverPersist) getShortLinkExpireAfter(ctx context.Context, alias string, expiringAt time.Time) (entity.ShortLink, error) {
+	shortLink, err := r.getShortLink(ctx, alias)
 	if err != nil {
 		return entity.ShortLink{}, err
 	}
 
-	if shortLink.ExpireAt == nil {
-		return shortLink, nil
-	}
-
-	if expiringAt.After(*shortLink.ExpireAt) {
-		return entity.ShortLink{}, fmt.Errorf("shortlink expired (alias=%s,expiringAt=%v)", alias, expiringAt)
+	if r.GetExpiryState(shortLink, expiringAt) == ExpiryStateExpired {
+		return entity.ShortLink{}, ErrShortLinkExpired(fmt.Sprintf("shortlink expired (alias=%s,expiringAt=%v)", alias, expiringAt))
 	}
 
 	return shortLink, nil
 }
 
-func (r RetrieverPersist) getShortLink(alias string) (entity.ShortLink, error) {
-	shortLink, err := r.shortLinkRepo.GetShortLinkByAlias(alias)
+// GetExpiryState decides whether the short link is active, expired within the
+// expiry grace window, or expired beyond it at the given time.
+func (r RetrieverPersist) GetExpiryState(shortLink entity.ShortLink, at time.Time) ExpiryState {
+	return getExpiryState(shortLink, at, r.expiryGrace)
+}
+
+// ConsumeVisit uses up one of the visits of the short link limiting the total
+// visits, such as one-time short links. The short link is reported expired once
+// all the visits are used up. Nothing is recorded for the short links without
+// the limit.
+func (r RetrieverPersist) ConsumeVisit(ctx context.Context, shortLink entity.ShortLink) error {
+	if shortLink.MaxVisits == 0 {
+		return nil
+	}
+
+	ok, err := r.shortLinkRepo.ConsumeVisit(ctx, shortLink.Alias)
 	if err != nil {
-		return entity.ShortLink{}, err
+		return err
+	}
+	if !ok {
+		return ErrShortLinkExpired(fmt.Sprintf("shortlink used up (alias=%s)", shortLink.Alias))
 	}
+	return nil
+}
 
-	return shortLink, nil
+func (r RetrieverPersist) getShortLink(ctx context.Context, alias string) (entity.ShortLink, error) {
+	for _, lookup := range aliasLookups(alias) {
+		shortLink, err := r.shortLinkRepo.GetShortLinkByAlias(ctx, lookup)
+		var notFound repository.ErrEntryNotFound
+		if errors.As(err, &notFound) {
+			continue
+		}
+		if err != nil {
+			return entity.ShortLink{}, err
+		}
+		return shortLink, nil
+	}
+	return entity.ShortLink{}, ErrShortLinkNotFound(normalizeAlias(alias))
 }
 
 // GetShortLinksByUser retrieves ShortLinks created by given user from persistent storage
-func (r RetrieverPersist) GetShortLinksByUser(user entity.User) ([]entity.ShortLink, error) {
-	aliases, err := r.userShortLinkRepo.FindAliasesByUser(user)
+func (r RetrieverPersist) GetShortLinksByUser(ctx context.Context, user entity.User) ([]entity.ShortLink, error) {
+	aliases, err := r.userShortLinkRepo.FindAliasesByUser(ctx, user)
+	if err != nil {
+		return []entity.ShortLink{}, err
+	}
+
+	return r.shortLinkRepo.GetShortLinksByAliases(ctx, aliases)
+}
+
+// GetShortLinksByAliases retrieves the ShortLinks of the given aliases created
+// by the given user from persistent storage, in the order the aliases are
+// first requested. The duplicated aliases are retrieved once. The aliases
+// missing or owned by other users are left out without an error, so that the
+// callers can't tell whether the aliases are taken.
+func (r RetrieverPersist) GetShortLinksByAliases(
+	ctx context.Context,
+	user entity.User,
+	aliases []string,
+) ([]entity.ShortLink, error) {
+	normalized := make([]string, 0, len(aliases))
+	lookups := make(map[string][]string)
+	var allLookups []string
+	for _, alias := range aliases {
+		key := normalizeAlias(alias)
+		normalized = append(normalized, key)
+		lookups[key] = append(lookups[key], aliasLookups(alias)...)
+		allLookups = append(allLookups, lookups[key]...)
+	}
+	aliases = uniqueAliases(normalized)
+	if len(aliases) > maxAliasesPerBatch {
+		msg := fmt.Sprintf("at most %d aliases can be retrieved at once", maxAliasesPerBatch)
+		return []entity.ShortLink{}, ErrTooManyAliases(msg)
+	}
+
+	shortLinks, err := r.userShortLinkRepo.FindShortLinksByAliases(ctx, user, uniqueAliases(allLookups))
 	if err != nil {
 		return []entity.ShortLink{}, err
 	}
 
-	return r.shortLinkRepo.GetShortLinksByAliases(aliases)
+	shortLinkMap := make(map[string]entity.ShortLink)
+	for _, shortLink := range shortLinks {
+		shortLinkMap[shortLink.Alias] = shortLink
+	}
+
+	ordered := []entity.ShortLink{}
+	for _, alias := range aliases {
+		for _, lookup := range lookups[alias] {
+			shortLink, ok := shortLinkMap[lookup]
+			if !ok {
+				continue
+			}
+			ordered = append(ordered, shortLink)
+			break
+		}
+	}
+	return ordered, nil
+}
+
+// IsOwner checks whether the given user owns the short link. It returns false
+// both when the short link is owned by another user and when the alias
+// doesn't exist, so that the callers can't tell whether the alias is taken.
+func (r RetrieverPersist) IsOwner(ctx context.Context, alias string, user entity.User) (bool, error) {
+	for _, lookup := range aliasLookups(alias) {
+		isOwner, err := r.userShortLinkRepo.HasMapping(ctx, user, lookup)
+		if err != nil || isOwner {
+			return isOwner, err
+		}
+	}
+	return false, nil
+}
+
+// GetShortLinkPageByUser retrieves at most first ShortLinks created by the
+// given user after the cursor from persistent storage. An empty cursor starts
+// from the most recently created ShortLink.
+func (r RetrieverPersist) GetShortLinkPageByUser(
+	ctx context.Context,
+	user entity.User,
+	first int,
+	after string,
+) (ShortLinkPage, error) {
+	if first <= 0 || first > MaxPageSize {
+		return ShortLinkPage{}, ErrInvalidPageSize(fmt.Sprintf("page size must be between 1 and %d", MaxPageSize))
+	}
+
+	var cursor *repository.ShortLinkCursor
+	if after != "" {
+		decoded, err := decodeCursor(after)
+		if err != nil {
+			return ShortLinkPage{}, err
+		}
+		cursor = &decoded
+	}
+
+	// Fetch one more ShortLink to tell whether there is a next page.
+	shortLinks, err := r.userShortLinkRepo.FindShortLinksByUser(ctx, user, cursor, first+1)
+	if err != nil {
+		return ShortLinkPage{}, err
+	}
+
+	page := ShortLinkPage{
+		ShortLinks:  shortLinks,
+		HasNextPage: len(shortLinks) > first,
+	}
+	if page.HasNextPage {
+		page.ShortLinks = shortLinks[:first]
+	}
+	if len(page.ShortLinks) > 0 {
+		last := page.ShortLinks[len(page.ShortLinks)-1]
+		page.EndCursor = encodeCursor(repository.NewShortLinkCursor(last))
+	}
+	return page, nil
+}
+
+// GetNumberedShortLinkPageByUser retrieves the pageth page of ShortLinks
+// created by the given user from persistent storage, with at most pageSize
+// ShortLinks in each page. Pages are numbered from 1.
+func (r RetrieverPersist) GetNumberedShortLinkPageByUser(
+	ctx context.Context,
+	user entity.User,
+	page int,
+	pageSize int,
+) (NumberedShortLinkPage, error) {
+	if pageSize <= 0 || pageSize > MaxPageSize {
+		return NumberedShortLinkPage{}, ErrInvalidPageSize(fmt.Sprintf("page size must be between 1 and %d", MaxPageSize))
+	}
+	if page <= 0 {
+		return NumberedShortLinkPage{}, ErrInvalidPageNumber("page number must be positive")
+	}
+	// Reject the page numbers whose offset overflows before multiplying.
+	if page > math.MaxInt32/pageSize+1 {
+		return NumberedShortLinkPage{}, ErrInvalidPageNumber("page number too large")
+	}
+
+	totalCount, err := r.userShortLinkRepo.CountShortLinksByUser(ctx, user)
+	if err != nil {
+		return NumberedShortLinkPage{}, err
+	}
+	numberedPage := NumberedShortLinkPage{
+		ShortLinks: []entity.ShortLink{},
+		TotalCount: totalCount,
+	}
+
+	offset := (page - 1) * pageSize
+	if offset >= totalCount {
+		return numberedPage, nil
+	}
+
+	shortLinks, err := r.userShortLinkRepo.FindShortLinksByUserOffset(ctx, user, offset, pageSize)
+	if err != nil {
+		return NumberedShortLinkPage{}, err
+	}
+	numberedPage.ShortLinks = shortLinks
+	return numberedPage, nil
+}
+
+// GetRecentShortLinksByUser retrieves at most limit ShortLinks created by the
+// given user from persistent storage, starting from the most recently created
+// one. ShortLinks without creation time come last. limit is capped at
+// MaxRecentShortLinks.
+func (r RetrieverPersist) GetRecentShortLinksByUser(
+	ctx context.Context,
+	user entity.User,
+	limit int,
+) ([]entity.ShortLink, error) {
+	if limit <= 0 {
+		return []entity.ShortLink{}, ErrInvalidPageSize("limit must be positive")
+	}
+	if limit > MaxRecentShortLinks {
+		limit = MaxRecentShortLinks
+	}
+	return r.userShortLinkRepo.FindShortLinksByUser(ctx, user, nil, limit)
 }
 
-// NewRetrieverPersist creates persistent ShortLink retriever
-func NewRetrieverPersist(shortLinkRepo repository.ShortLink, userShortLinkRepo repository.UserShortLink) RetrieverPersist {
+// NewRetrieverPersist creates persistent ShortLink retriever. Zero
+// expiryGrace stops redirecting users as soon as the short links expire.
+func NewRetrieverPersist(
+	shortLinkRepo repository.ShortLink,
+	userShortLinkRepo repository.UserShortLink,
+	expiryGrace time.Duration,
+) RetrieverPersist {
 	return RetrieverPersist{
 		shortLinkRepo:     shortLinkRepo,
 		userShortLinkRepo: userShortLinkRepo,
+		expiryGrace:       expiryGrace,
 	}
 }
@@ -3,6 +3,10 @@
 package shortlink
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
 	"testing"
 	"time"
 
@@ -25,6 +29,7 @@ func TestRetriever_GetShortLink(t *testing.T) {
 		shortLinks        shortLinks
 		alias             string
 		expiringAt        *time.Time
+		expiryGrace       time.Duration
 		hasErr            bool
 		expectedShortLink entity.ShortLink
 	}{
@@ -49,6 +54,37 @@ func TestRetriever_GetShortLink(t *testing.T) {
 			hasErr:            true,
 			expectedShortLink: entity.ShortLink{},
 		},
+		{
+			name: "short link expired within grace window",
+			shortLinks: shortLinks{
+				"220uFicCJj": entity.ShortLink{
+					Alias:    "220uFicCJj",
+					ExpireAt: &before,
+				},
+			},
+			alias:       "220uFicCJj",
+			expiringAt:  &now,
+			expiryGrace: time.Minute,
+			hasErr:      false,
+			expectedShortLink: entity.ShortLink{
+				Alias:    "220uFicCJj",
+				ExpireAt: &before,
+			},
+		},
+		{
+			name: "short link expired beyond grace window",
+			shortLinks: shortLinks{
+				"220uFicCJj": entity.ShortLink{
+					Alias:    "220uFicCJj",
+					ExpireAt: &before,
+				},
+			},
+			alias:             "220uFicCJj",
+			expiringAt:        &now,
+			expiryGrace:       time.Second,
+			hasErr:            true,
+			expectedShortLink: entity.ShortLink{},
+		},
 		{
 			name: "short link never expire",
 			shortLinks: shortLinks{
@@ -97,6 +133,20 @@ func TestRetriever_GetShortLink(t *testing.T) {
 				ExpireAt: &after,
 			},
 		},
+		{
+			name: "decomposed alias stored before normalization",
+			shortLinks: shortLinks{
+				"cafe\u0301": entity.ShortLink{
+					Alias: "cafe\u0301",
+				},
+			},
+			alias:      "caf\u00e9",
+			expiringAt: &now,
+			hasErr:     false,
+			expectedShortLink: entity.ShortLink{
+				Alias: "cafe\u0301",
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -106,8 +156,8 @@ func TestRetriever_GetShortLink(t *testing.T) {
 
 			fakeShortLinkRepo := repository.NewShortLinkFake(nil, testCase.shortLinks)
 			fakeUserShortLinkRepo := repository.NewUserShortLinkRepoFake([]entity.User{}, []entity.ShortLink{})
-			retriever := NewRetrieverPersist(&fakeShortLinkRepo, &fakeUserShortLinkRepo)
-			shortLink, err := retriever.GetShortLink(testCase.alias, testCase.expiringAt)
+			retriever := NewRetrieverPersist(&fakeShortLinkRepo, &fakeUserShortLinkRepo, testCase.expiryGrace)
+			shortLink, err := retriever.GetShortLink(context.Background(), testCase.alias, testCase.expiringAt)
 
 			if testCase.hasErr {
 				assert.NotEqual(t, nil, err)
@@ -255,9 +305,9 @@ func TestRetrieverPersist_GetShortLinks(t *testing.T) {
 
 			fakeShortLinkRepo := repository.NewShortLinkFake(nil, testCase.shortLinks)
 			fakeUserShortLinkRepo := repository.NewUserShortLinkRepoFake(testCase.users, testCase.createdShortLinks)
-			retriever := NewRetrieverPersist(&fakeShortLinkRepo, &fakeUserShortLinkRepo)
+			retriever := NewRetrieverPersist(&fakeShortLinkRepo, &fakeUserShortLinkRepo, 0)
 
-			shortLinks, err := retriever.GetShortLinksByUser(testCase.user)
+			shortLinks, err := retriever.GetShortLinksByUser(context.Background(), testCase.user)
 			if testCase.hasErr {
 				assert.NotEqual(t, nil, err)
 				return
@@ -268,3 +318,629 @@ func TestRetrieverPersist_GetShortLinks(t *testing.T) {
 		})
 	}
 }
+
+func TestRetrieverPersist_GetShortLinkPageByUser(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 5, 1, 8, 2, 16, 0, time.UTC)
+	minuteAgo := now.Add(-time.Minute)
+	hourAgo := now.Add(-time.Hour)
+
+	user := entity.User{ID: "12345"}
+	otherUser := entity.User{ID: "12346"}
+	users := []entity.User{user, user, user, user, otherUser}
+	createdShortLinks := []entity.ShortLink{
+		{Alias: "short", CreatedAt: &hourAgo},
+		{Alias: "bing", CreatedAt: &minuteAgo},
+		{Alias: "google", CreatedAt: &minuteAgo},
+		{Alias: "legacy"},
+		{Alias: "mozilla", CreatedAt: &now},
+	}
+
+	testCases := []struct {
+		name                string
+		first               int
+		after               string
+		hasErr              bool
+		expectedAliases     []string
+		expectedHasNextPage bool
+	}{
+		{
+			name:                "first page",
+			first:               2,
+			expectedAliases:     []string{"google", "bing"},
+			expectedHasNextPage: true,
+		},
+		{
+			name:  "page after cursor",
+			first: 2,
+			after: encodeCursor(repository.ShortLinkCursor{
+				CreatedAt: minuteAgo,
+				Alias:     "bing",
+			}),
+			expectedAliases:     []string{"short", "legacy"},
+			expectedHasNextPage: false,
+		},
+		{
+			name:                "page size covers all short links",
+			first:               4,
+			expectedAliases:     []string{"google", "bing", "short", "legacy"},
+			expectedHasNextPage: false,
+		},
+		{
+			name:  "no short link after cursor",
+			first: 2,
+			after: encodeCursor(repository.ShortLinkCursor{
+				CreatedAt: time.Time{},
+				Alias:     "legacy",
+			}),
+			expectedAliases:     []string{},
+			expectedHasNextPage: false,
+		},
+		{
+			name:   "invalid cursor",
+			first:  2,
+			after:  "google",
+			hasErr: true,
+		},
+		{
+			name:   "page size not positive",
+			first:  0,
+			hasErr: true,
+		},
+		{
+			name:   "page size too large",
+			first:  MaxPageSize + 1,
+			hasErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			fakeShortLinkRepo := repository.NewShortLinkFake(nil, shortLinks{})
+			fakeUserShortLinkRepo := repository.NewUserShortLinkRepoFake(users, createdShortLinks)
+			retriever := NewRetrieverPersist(&fakeShortLinkRepo, &fakeUserShortLinkRepo, 0)
+
+			page, err := retriever.GetShortLinkPageByUser(context.Background(), user, testCase.first, testCase.after)
+			if testCase.hasErr {
+				assert.NotEqual(t, nil, err)
+				return
+			}
+			assert.Equal(t, nil, err)
+
+			aliases := []string{}
+			for _, shortLink := range page.ShortLinks {
+				aliases = append(aliases, shortLink.Alias)
+			}
+			assert.Equal(t, testCase.expectedAliases, aliases)
+			assert.Equal(t, testCase.expectedHasNextPage, page.HasNextPage)
+
+			if len(page.ShortLinks) == 0 {
+				assert.Equal(t, "", page.EndCursor)
+				return
+			}
+			last := page.ShortLinks[len(page.ShortLinks)-1]
+			assert.Equal(t, encodeCursor(repository.NewShortLinkCursor(last)), page.EndCursor)
+		})
+	}
+}
+
+func TestRetrieverPersist_GetNumberedShortLinkPageByUser(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 5, 1, 8, 2, 16, 0, time.UTC)
+	minuteAgo := now.Add(-time.Minute)
+	hourAgo := now.Add(-time.Hour)
+
+	user := entity.User{ID: "12345"}
+	otherUser := entity.User{ID: "12346"}
+	users := []entity.User{user, user, user, user, otherUser}
+	createdShortLinks := []entity.ShortLink{
+		{Alias: "short", CreatedAt: &hourAgo},
+		{Alias: "bing", CreatedAt: &minuteAgo},
+		{Alias: "google", CreatedAt: &minuteAgo},
+		{Alias: "legacy"},
+		{Alias: "mozilla", CreatedAt: &now},
+	}
+
+	testCases := []struct {
+		name            string
+		page            int
+		pageSize        int
+		hasErr          bool
+		expectedAliases []string
+	}{
+		{
+			name:            "first page",
+			page:            1,
+			pageSize:        3,
+			expectedAliases: []string{"google", "bing", "short"},
+		},
+		{
+			name:            "last page",
+			page:            2,
+			pageSize:        3,
+			expectedAliases: []string{"legacy"},
+		},
+		{
+			name:            "page beyond last page",
+			page:            3,
+			pageSize:        3,
+			expectedAliases: []string{},
+		},
+		{
+			name:     "page number not positive",
+			page:     0,
+			pageSize: 3,
+			hasErr:   true,
+		},
+		{
+			name:     "page size too large",
+			page:     1,
+			pageSize: MaxPageSize + 1,
+			hasErr:   true,
+		},
+		{
+			name:     "page offset overflows",
+			page:     math.MaxInt32/3 + 2,
+			pageSize: 3,
+			hasErr:   true,
+		},
+		{
+			name:            "largest page number",
+			page:            math.MaxInt32/3 + 1,
+			pageSize:        3,
+			expectedAliases: []string{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			fakeShortLinkRepo := repository.NewShortLinkFake(nil, shortLinks{})
+			fakeUserShortLinkRepo := repository.NewUserShortLinkRepoFake(users, createdShortLinks)
+			retriever := NewRetrieverPersist(&fakeShortLinkRepo, &fakeUserShortLinkRepo, 0)
+
+			page, err := retriever.GetNumberedShortLinkPageByUser(context.Background(), user, testCase.page, testCase.pageSize)
+			if testCase.hasErr {
+				assert.NotEqual(t, nil, err)
+				return
+			}
+			assert.Equal(t, nil, err)
+			assert.Equal(t, 4, page.TotalCount)
+
+			aliases := []string{}
+			for _, shortLink := range page.ShortLinks {
+				aliases = append(aliases, shortLink.Alias)
+			}
+			assert.Equal(t, testCase.expectedAliases, aliases)
+		})
+	}
+}
+
+func TestRetrieverPersist_GetRecentShortLinksByUser(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 5, 1, 8, 2, 16, 0, time.UTC)
+	minuteAgo := now.Add(-time.Minute)
+	hourAgo := now.Add(-time.Hour)
+
+	user := entity.User{ID: "12345"}
+	otherUser := entity.User{ID: "12346"}
+	users := []entity.User{user, user, user, user, user, user, otherUser}
+	createdShortLinks := []entity.ShortLink{
+		{Alias: "legacy1"},
+		{Alias: "short", CreatedAt: &hourAgo},
+		{Alias: "legacy2"},
+		{Alias: "bing", CreatedAt: &minuteAgo},
+		{Alias: "google", CreatedAt: &now},
+		{Alias: "mozilla", CreatedAt: &now},
+		{Alias: "yahoo", CreatedAt: &now},
+	}
+
+	testCases := []struct {
+		name            string
+		limit           int
+		hasErr          bool
+		expectedAliases []string
+	}{
+		{
+			name:            "most recent first",
+			limit:           3,
+			expectedAliases: []string{"mozilla", "google", "bing"},
+		},
+		{
+			name:            "short links without creation time last",
+			limit:           10,
+			expectedAliases: []string{"mozilla", "google", "bing", "short", "legacy2", "legacy1"},
+		},
+		{
+			name:   "limit not positive",
+			limit:  0,
+			hasErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			fakeShortLinkRepo := repository.NewShortLinkFake(nil, shortLinks{})
+			fakeUserShortLinkRepo := repository.NewUserShortLinkRepoFake(users, createdShortLinks)
+			retriever := NewRetrieverPersist(&fakeShortLinkRepo, &fakeUserShortLinkRepo, 0)
+
+			shortLinks, err := retriever.GetRecentShortLinksByUser(context.Background(), user, testCase.limit)
+			if testCase.hasErr {
+				assert.NotEqual(t, nil, err)
+				return
+			}
+			assert.Equal(t, nil, err)
+
+			aliases := []string{}
+			for _, shortLink := range shortLinks {
+				aliases = append(aliases, shortLink.Alias)
+			}
+			assert.Equal(t, testCase.expectedAliases, aliases)
+		})
+	}
+}
+
+func TestRetrieverPersist_IsOwner(t *testing.T) {
+	t.Parallel()
+
+	owner := entity.User{ID: "12345"}
+	otherUser := entity.User{ID: "12346"}
+	legacyOwner := entity.User{ID: "12347"}
+	shortLink := entity.ShortLink{
+		Alias:    "café",
+		LongLink: "https://www.google.com",
+	}
+	legacyShortLink := entity.ShortLink{
+		Alias:    "the\u0301",
+		LongLink: "https://www.tea.com",
+	}
+
+	testCases := []struct {
+		name            string
+		alias           string
+		user            entity.User
+		expectedIsOwner bool
+	}{
+		{
+			name:            "owner",
+			alias:           "café",
+			user:            owner,
+			expectedIsOwner: true,
+		},
+		{
+			name:            "owner with decomposed alias",
+			alias:           "cafe\u0301",
+			user:            owner,
+			expectedIsOwner: true,
+		},
+		{
+			name:            "owner of decomposed alias stored before normalization",
+			alias:           "th\u00e9",
+			user:            legacyOwner,
+			expectedIsOwner: true,
+		},
+		{
+			name:            "owned by another user",
+			alias:           "café",
+			user:            otherUser,
+			expectedIsOwner: false,
+		},
+		{
+			name:            "alias not found",
+			alias:           "tea",
+			user:            otherUser,
+			expectedIsOwner: false,
+		},
+		{
+			name:            "alias not found for owner",
+			alias:           "tea",
+			user:            owner,
+			expectedIsOwner: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			fakeShortLinkRepo := repository.NewShortLinkFake(nil, shortLinks{
+				shortLink.Alias:       shortLink,
+				legacyShortLink.Alias: legacyShortLink,
+			})
+			fakeUserShortLinkRepo := repository.NewUserShortLinkRepoFake(
+				[]entity.User{owner, legacyOwner},
+				[]entity.ShortLink{shortLink, legacyShortLink},
+			)
+			retriever := NewRetrieverPersist(&fakeShortLinkRepo, &fakeUserShortLinkRepo, 0)
+
+			isOwner, err := retriever.IsOwner(context.Background(), testCase.alias, testCase.user)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedIsOwner, isOwner)
+		})
+	}
+}
+
+func TestRetrieverPersist_GetShortLinksByAliases(t *testing.T) {
+	t.Parallel()
+
+	owner := entity.User{ID: "12345"}
+	otherUser := entity.User{ID: "12346"}
+	google := entity.ShortLink{Alias: "google", LongLink: "https://www.google.com"}
+	cafe := entity.ShortLink{Alias: "café", LongLink: "https://www.cafe.com"}
+	github := entity.ShortLink{Alias: "github", LongLink: "https://www.github.com"}
+	legacy := entity.ShortLink{Alias: "the\u0301", LongLink: "https://www.tea.com"}
+
+	var tooManyAliases []string
+	for idx := 0; idx <= maxAliasesPerBatch; idx++ {
+		tooManyAliases = append(tooManyAliases, fmt.Sprintf("alias%03d", idx))
+	}
+	var duplicatedAliases []string
+	for idx := 0; idx <= maxAliasesPerBatch; idx++ {
+		duplicatedAliases = append(duplicatedAliases, "google")
+	}
+
+	testCases := []struct {
+		name               string
+		aliases            []string
+		hasErr             bool
+		expectedShortLinks []entity.ShortLink
+	}{
+		{
+			name:               "no alias",
+			aliases:            []string{},
+			expectedShortLinks: []entity.ShortLink{},
+		},
+		{
+			name:               "owned aliases in requested order",
+			aliases:            []string{"café", "google"},
+			expectedShortLinks: []entity.ShortLink{cafe, google},
+		},
+		{
+			name:               "aliases owned by others and missing left out",
+			aliases:            []string{"github", "google", "tea"},
+			expectedShortLinks: []entity.ShortLink{google},
+		},
+		{
+			name:               "duplicated aliases retrieved once",
+			aliases:            []string{"google", "cafe\u0301", "google", "café"},
+			expectedShortLinks: []entity.ShortLink{google, cafe},
+		},
+		{
+			name:               "decomposed alias stored before normalization",
+			aliases:            []string{"th\u00e9", "google"},
+			expectedShortLinks: []entity.ShortLink{legacy, google},
+		},
+		{
+			name:               "duplicated aliases counted once",
+			aliases:            duplicatedAliases,
+			expectedShortLinks: []entity.ShortLink{google},
+		},
+		{
+			name:    "too many aliases",
+			aliases: tooManyAliases,
+			hasErr:  true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			fakeShortLinkRepo := repository.NewShortLinkFake(nil, shortLinks{
+				google.Alias: google,
+				cafe.Alias:   cafe,
+				github.Alias: github,
+				legacy.Alias: legacy,
+			})
+			fakeUserShortLinkRepo := repository.NewUserShortLinkRepoFake(
+				[]entity.User{owner, owner, otherUser, owner},
+				[]entity.ShortLink{google, cafe, github, legacy},
+			)
+			retriever := NewRetrieverPersist(&fakeShortLinkRepo, &fakeUserShortLinkRepo, 0)
+
+			shortLinks, err := retriever.GetShortLinksByAliases(context.Background(), owner, testCase.aliases)
+			if testCase.hasErr {
+				var ta ErrTooManyAliases
+				assert.Equal(t, true, errors.As(err, &ta))
+				return
+			}
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedShortLinks, shortLinks)
+		})
+	}
+}
+
+func TestRetrieverPersist_GetRecentShortLinksByUser_LimitCapped(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 5, 1, 8, 2, 16, 0, time.UTC)
+	user := entity.User{ID: "12345"}
+
+	var users []entity.User
+	var createdShortLinks []entity.ShortLink
+	for idx := 0; idx < MaxRecentShortLinks+10; idx++ {
+		createdAt := now.Add(time.Duration(idx) * time.Minute)
+		users = append(users, user)
+		createdShortLinks = append(createdShortLinks, entity.ShortLink{
+			Alias:     fmt.Sprintf("alias%03d", idx),
+			CreatedAt: &createdAt,
+		})
+	}
+
+	fakeShortLinkRepo := repository.NewShortLinkFake(nil, shortLinks{})
+	fakeUserShortLinkRepo := repository.NewUserShortLinkRepoFake(users, createdShortLinks)
+	retriever := NewRetrieverPersist(&fakeShortLinkRepo, &fakeUserShortLinkRepo, 0)
+
+	shortLinks, err := retriever.GetRecentShortLinksByUser(context.Background(), user, MaxRecentShortLinks+5)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, MaxRecentShortLinks, len(shortLinks))
+	assert.Equal(t, fmt.Sprintf("alias%03d", MaxRecentShortLinks+9), shortLinks[0].Alias)
+}
+
+func TestRetrieverPersist_GetShortLinkPageByUser_StableAcrossInserts(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 5, 1, 8, 2, 16, 0, time.UTC)
+	user := entity.User{ID: "12345"}
+
+	var users []entity.User
+	var createdShortLinks []entity.ShortLink
+	for idx := 0; idx < 5; idx++ {
+		createdAt := now.Add(time.Duration(idx) * time.Minute)
+		users = append(users, user)
+		createdShortLinks = append(createdShortLinks, entity.ShortLink{
+			Alias:     fmt.Sprintf("alias%d", idx),
+			CreatedAt: &createdAt,
+		})
+	}
+
+	fakeShortLinkRepo := repository.NewShortLinkFake(nil, shortLinks{})
+	fakeUserShortLinkRepo := repository.NewUserShortLinkRepoFake(users, createdShortLinks)
+	retriever := NewRetrieverPersist(&fakeShortLinkRepo, &fakeUserShortLinkRepo, 0)
+
+	var aliases []string
+	page, err := retriever.GetShortLinkPageByUser(context.Background(), user, 2, "")
+	assert.Equal(t, nil, err)
+	for _, shortLink := range page.ShortLinks {
+		aliases = append(aliases, shortLink.Alias)
+	}
+
+	for page.HasNextPage {
+		// New short links show up at the front of the list and must not shift
+		// the pages after the cursor.
+		createdAt := now.Add(time.Hour)
+		alias := fmt.Sprintf("new%d", len(aliases))
+		err = fakeUserShortLinkRepo.CreateRelation(context.Background(), user, entity.ShortLinkInput{
+			CustomAlias: &alias,
+			CreatedAt:   &createdAt,
+		}, true)
+		assert.Equal(t, nil, err)
+
+		page, err = retriever.GetShortLinkPageByUser(context.Background(), user, 2, page.EndCursor)
+		assert.Equal(t, nil, err)
+		for _, shortLink := range page.ShortLinks {
+			aliases = append(aliases, shortLink.Alias)
+		}
+	}
+
+	expectedAliases := []string{"alias4", "alias3", "alias2", "alias1", "alias0"}
+	assert.Equal(t, expectedAliases, aliases)
+}
+
+// cancelingUserShortLinkRepo cancels the request right after the aliases of
+// the user are found, as if the client disconnected halfway.
+type cancelingUserShortLinkRepo struct {
+	*repository.UserShortLinkFake
+	cancel context.CancelFunc
+}
+
+func (c cancelingUserShortLinkRepo) FindAliasesByUser(ctx context.Context, user entity.User) ([]string, error) {
+	defer c.cancel()
+	return c.UserShortLinkFake.FindAliasesByUser(ctx, user)
+}
+
+func TestRetrieverPersist_Canceled(t *testing.T) {
+	t.Parallel()
+
+	user := entity.User{ID: "12345"}
+	shortLink := entity.ShortLink{
+		Alias:    "220uFicCJj",
+		LongLink: "https://www.google.com",
+	}
+
+	t.Run("GetShortLink deadline exceeded", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+		defer cancel()
+
+		fakeShortLinkRepo := repository.NewShortLinkFake(nil, shortLinks{
+			shortLink.Alias: shortLink,
+		})
+		fakeUserShortLinkRepo := repository.NewUserShortLinkRepoFake(nil, nil)
+		retriever := NewRetrieverPersist(&fakeShortLinkRepo, &fakeUserShortLinkRepo, 0)
+
+		_, err := retriever.GetShortLink(ctx, shortLink.Alias, nil)
+		assert.Equal(t, context.DeadlineExceeded, err)
+	})
+
+	t.Run("GetShortLinksByUser canceled halfway", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		fakeShortLinkRepo := repository.NewShortLinkFake(nil, shortLinks{
+			shortLink.Alias: shortLink,
+		})
+		fakeUserShortLinkRepo := repository.NewUserShortLinkRepoFake(
+			[]entity.User{user},
+			[]entity.ShortLink{shortLink},
+		)
+		userShortLinkRepo := cancelingUserShortLinkRepo{
+			UserShortLinkFake: &fakeUserShortLinkRepo,
+			cancel:            cancel,
+		}
+		retriever := NewRetrieverPersist(&fakeShortLinkRepo, userShortLinkRepo, 0)
+
+		shortLinks, err := retriever.GetShortLinksByUser(ctx, user)
+		assert.Equal(t, context.Canceled, err)
+		assert.Equal(t, 0, len(shortLinks))
+	})
+}
+
+func TestRetrieverPersist_ConsumeVisit(t *testing.T) {
+	t.Parallel()
+
+	oneTimeLink := entity.ShortLink{
+		Alias:     "secret",
+		LongLink:  "https://www.google.com",
+		MaxVisits: 1,
+	}
+	regularLink := entity.ShortLink{
+		Alias:    "google",
+		LongLink: "https://www.google.com",
+	}
+	shortLinkRepo := repository.NewShortLinkFake(nil, shortLinks{
+		oneTimeLink.Alias: oneTimeLink,
+		regularLink.Alias: regularLink,
+	})
+	retriever := NewRetrieverPersist(&shortLinkRepo, nil, 0)
+
+	const visitors = 10
+	errs := make(chan error, visitors)
+	for idx := 0; idx < visitors; idx++ {
+		go func() {
+			errs <- retriever.ConsumeVisit(context.Background(), oneTimeLink)
+		}()
+	}
+
+	succeeded := 0
+	for idx := 0; idx < visitors; idx++ {
+		err := <-errs
+		if err == nil {
+			succeeded++
+			continue
+		}
+		assert.Equal(t, ErrShortLinkExpired("shortlink used up (alias=secret)"), err)
+	}
+	assert.Equal(t, 1, succeeded)
+
+	for idx := 0; idx < visitors; idx++ {
+		err := retriever.ConsumeVisit(context.Background(), regularLink)
+		assert.Equal(t, nil, err)
+	}
+}
@@ -0,0 +1,54 @@
+package shortlink
+
+import (
+	"fmt"
+
+	"github.com/short-d/app/fw/ctx"
+)
+
+// aliasRetriesMetric counts the creations of short links by the number of
+// retries they needed because the alias was taken, so that the saturation of
+// the keyspace shows up early.
+const aliasRetriesMetric = "alias-retries"
+
+type aliasType string
+
+const (
+	aliasTypeAuto   aliasType = "auto"
+	aliasTypeCustom aliasType = "custom"
+)
+
+// retryBucketBounds are the inclusive upper bounds of the histogram buckets
+// of alias retries.
+var retryBucketBounds = []int{0, 1, 2, 4, 8, 16}
+
+// ErrAliasRetryExhausted represents all the auto generated aliases tried
+// within the retry budget are taken.
+type ErrAliasRetryExhausted string
+
+func (e ErrAliasRetryExhausted) Error() string {
+	return fmt.Sprintf("alias retry budget exhausted: %s", string(e))
+}
+
+// retryBucket names the histogram bucket the number of retries falls into,
+// such as 3-4 for 3 retries.
+func retryBucket(retries int) string {
+	lower := 0
+	for _, upper := range retryBucketBounds {
+		if retries <= upper {
+			if lower == upper {
+				return fmt.Sprintf("%d", upper)
+			}
+			return fmt.Sprintf("%d-%d", lower, upper)
+		}
+		lower = upper + 1
+	}
+	return fmt.Sprintf("%d-inf", lower)
+}
+
+// recordAliasRetries exports the number of retries a creation needed as a
+// histogram by counting the creation in the bucket the retries fall into.
+func (c CreatorPersist) recordAliasRetries(aliasType aliasType, retries int) {
+	metricID := fmt.Sprintf("%s.%s.%s", aliasRetriesMetric, aliasType, retryBucket(retries))
+	go c.metrics.Count(metricID, 1, 1, ctx.ExecutionContext{})
+}
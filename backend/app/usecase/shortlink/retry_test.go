@@ -0,0 +1,206 @@
+// +build !integration all
+
+package shortlink
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/ctx"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/fw/ptr"
+	"github.com/short-d/short/backend/app/usecase/authorizer"
+	"github.com/short-d/short/backend/app/usecase/email"
+	"github.com/short-d/short/backend/app/usecase/keygen"
+	"github.com/short-d/short/backend/app/usecase/maintenance"
+	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/risk"
+	"github.com/short-d/short/backend/app/usecase/validator"
+	"github.com/short-d/short/backend/app/usecase/webhook"
+)
+
+// metricsRecorder captures the IDs of the counted metrics.
+type metricsRecorder struct {
+	metricIDs chan string
+}
+
+func (m metricsRecorder) Count(metricID string, point int, interval int, ctx ctx.ExecutionContext) {
+	m.metricIDs <- metricID
+}
+
+func (m metricsRecorder) Rate(metricID string, point float32, interval int, ctx ctx.ExecutionContext) {
+}
+
+func (m metricsRecorder) Gauge(metricID string, point float32, ctx ctx.ExecutionContext) {
+}
+
+func TestRetryBucket(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		retries        int
+		expectedBucket string
+	}{
+		{retries: 0, expectedBucket: "0"},
+		{retries: 1, expectedBucket: "1"},
+		{retries: 2, expectedBucket: "2"},
+		{retries: 3, expectedBucket: "3-4"},
+		{retries: 4, expectedBucket: "3-4"},
+		{retries: 5, expectedBucket: "5-8"},
+		{retries: 16, expectedBucket: "9-16"},
+		{retries: 17, expectedBucket: "17-inf"},
+		{retries: 100, expectedBucket: "17-inf"},
+	}
+
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.expectedBucket, retryBucket(testCase.retries))
+	}
+}
+
+func TestShortLinkCreatorPersist_CreateShortLinkAliasRetries(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name             string
+		shortLinks       shortLinks
+		availableKeys    []keygen.Key
+		customAlias      *string
+		retryBudget      int
+		hasErr           bool
+		expectedErr      error
+		expectedAlias    string
+		expectedMetricID string
+	}{
+		{
+			name:             "auto alias available",
+			shortLinks:       shortLinks{},
+			availableKeys:    []keygen.Key{"a", "b", "c"},
+			retryBudget:      3,
+			expectedAlias:    "a",
+			expectedMetricID: "alias-retries.auto.0",
+		},
+		{
+			name: "retry until auto alias available",
+			shortLinks: shortLinks{
+				"a": {Alias: "a", LongLink: "https://www.google.com"},
+				"b": {Alias: "b", LongLink: "https://www.google.com"},
+			},
+			availableKeys:    []keygen.Key{"a", "b", "c"},
+			retryBudget:      3,
+			expectedAlias:    "c",
+			expectedMetricID: "alias-retries.auto.2",
+		},
+		{
+			name: "retry budget exhausted",
+			shortLinks: shortLinks{
+				"a": {Alias: "a", LongLink: "https://www.google.com"},
+				"b": {Alias: "b", LongLink: "https://www.google.com"},
+			},
+			availableKeys:    []keygen.Key{"a", "b", "c"},
+			retryBudget:      1,
+			hasErr:           true,
+			expectedErr:      ErrAliasRetryExhausted("all 2 aliases tried are taken"),
+			expectedMetricID: "alias-retries.auto.1",
+		},
+		{
+			name: "no retry budget",
+			shortLinks: shortLinks{
+				"a": {Alias: "a", LongLink: "https://www.google.com"},
+			},
+			availableKeys:    []keygen.Key{"a", "b"},
+			retryBudget:      0,
+			hasErr:           true,
+			expectedErr:      ErrAliasRetryExhausted("all 1 aliases tried are taken"),
+			expectedMetricID: "alias-retries.auto.0",
+		},
+		{
+			name:             "custom alias available",
+			shortLinks:       shortLinks{},
+			customAlias:      ptr.String("google"),
+			retryBudget:      3,
+			expectedAlias:    "google",
+			expectedMetricID: "alias-retries.custom.0",
+		},
+		{
+			name: "custom alias taken",
+			shortLinks: shortLinks{
+				"google": {Alias: "google", LongLink: "https://www.google.com"},
+			},
+			customAlias:      ptr.String("google"),
+			retryBudget:      3,
+			hasErr:           true,
+			expectedErr:      ErrAliasExist("short link alias already exist"),
+			expectedMetricID: "alias-retries.custom.1",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			shortLinkRepo := repository.NewShortLinkFake(nil, testCase.shortLinks)
+			userShortLinkRepo := repository.NewUserShortLinkRepoFake(nil, nil)
+			keyFetcher := keygen.NewKeyFetcherFake(testCase.availableKeys)
+			keyGen, err := keygen.NewRemote(2, &keyFetcher)
+			assert.Equal(t, nil, err)
+			flaggedLinkRepo := repository.NewFlaggedShortLinkFake(nil)
+			preferencesRepo := repository.NewUserPreferencesFake(nil)
+			metrics := metricsRecorder{metricIDs: make(chan string, 1)}
+
+			creator := NewCreatorPersist(
+				&shortLinkRepo,
+				&userShortLinkRepo,
+				keyGen,
+				validator.NewLongLink(nil, nil, validator.FragmentPreserve, validator.PlainHTTPAllow),
+				validator.NewCustomAlias(),
+				timer.NewStub(time.Now()),
+				risk.NewDetector(
+					risk.NewBlackListFake(nil),
+					risk.NewDenylistFake(nil),
+					risk.StrictThresholds,
+				),
+				&flaggedLinkRepo,
+				DefaultChecks,
+				LongLinkUniquenessNone,
+				AliasQuota{},
+				authorizer.Authorizer{},
+				email.NewSenderFake(nil),
+				webhook.NewDispatcherFake(),
+				&preferencesRepo,
+				maintenance.Mode{},
+				metrics,
+				testCase.retryBudget,
+				ExpirationPolicy{},
+				&repository.AliasSkeletonFake{},
+				RelationRetry{},
+				&repository.AliasReservationFake{},
+				ReservationTTL{},
+				ChainedLink{},
+			)
+
+			user := entity.User{ID: "alpha", Email: "alpha@example.com"}
+			shortLinkInput := entity.ShortLinkInput{
+				LongLink:    ptr.String("https://www.google.com"),
+				CustomAlias: testCase.customAlias,
+			}
+			shortLink, err := creator.CreateShortLink(context.Background(), shortLinkInput, user, false)
+			if testCase.hasErr {
+				assert.Equal(t, testCase.expectedErr, err)
+			} else {
+				assert.Equal(t, nil, err)
+				assert.Equal(t, testCase.expectedAlias, shortLink.Alias)
+			}
+
+			select {
+			case metricID := <-metrics.metricIDs:
+				assert.Equal(t, testCase.expectedMetricID, metricID)
+			case <-time.After(time.Second):
+				t.Fatalf("expect metric %s to be counted", testCase.expectedMetricID)
+			}
+		})
+	}
+}
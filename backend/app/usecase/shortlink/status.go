@@ -0,0 +1,159 @@
+package shortlink
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/fw/tenant"
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+var _ StatusChecker = (*StatusCheckerPersist)(nil)
+
+// maxAliasesPerBatch limits the number of aliases resolved in a single
+// request to protect the data store.
+const maxAliasesPerBatch = 100
+
+// AliasStatus represents whether an alias can redirect users to a long link.
+type AliasStatus string
+
+// The constants enumerate all supported alias statuses.
+const (
+	AliasStatusActive   AliasStatus = "active"
+	AliasStatusExpired  AliasStatus = "expired"
+	AliasStatusNotFound AliasStatus = "notFound"
+)
+
+// ErrTooManyAliases represents the number of requested aliases exceeds the
+// limit of a single batch.
+type ErrTooManyAliases string
+
+func (e ErrTooManyAliases) Error() string {
+	return string(e)
+}
+
+// AliasResolution represents the status of an alias. LongLink is only
+// available when the short link is owned by the viewer.
+type AliasResolution struct {
+	Alias    string
+	Status   AliasStatus
+	LongLink *string
+}
+
+// StatusChecker resolves the status of many aliases at once.
+type StatusChecker interface {
+	ResolveAliases(ctx context.Context, aliases []string, viewer *entity.User) ([]AliasResolution, error)
+}
+
+// StatusCheckerPersist resolves the status of aliases from persistent
+// storage.
+type StatusCheckerPersist struct {
+	shortLinkRepo     repository.ShortLink
+	userShortLinkRepo repository.UserShortLink
+	timer             timer.Timer
+}
+
+// ResolveAliases retrieves the status of the given aliases in the same order.
+// Anonymous viewers and non-owners only see the status. The aliases are
+// resolved within the tenant of the viewer.
+func (s StatusCheckerPersist) ResolveAliases(
+	ctx context.Context,
+	aliases []string,
+	viewer *entity.User,
+) ([]AliasResolution, error) {
+	if len(aliases) > maxAliasesPerBatch {
+		msg := fmt.Sprintf("at most %d aliases can be resolved at once", maxAliasesPerBatch)
+		return nil, ErrTooManyAliases(msg)
+	}
+
+	tenantID := tenant.Default
+	if viewer != nil {
+		tenantID = viewer.TenantID
+	}
+	ctx = tenant.NewContext(ctx, tenantID)
+	shortLinks, err := s.shortLinkRepo.GetShortLinksByAliases(ctx, uniqueAliases(aliases))
+	if err != nil {
+		return nil, err
+	}
+
+	shortLinkMap := make(map[string]entity.ShortLink)
+	for _, shortLink := range shortLinks {
+		shortLinkMap[shortLink.Alias] = shortLink
+	}
+
+	ownedAliases, err := s.getOwnedAliases(ctx, viewer)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.timer.Now()
+	resolutions := make([]AliasResolution, 0, len(aliases))
+	for _, alias := range aliases {
+		shortLink, ok := shortLinkMap[alias]
+		if !ok {
+			resolutions = append(resolutions, AliasResolution{
+				Alias:  alias,
+				Status: AliasStatusNotFound,
+			})
+			continue
+		}
+
+		resolution := AliasResolution{
+			Alias:  alias,
+			Status: AliasStatusActive,
+		}
+		if shortLink.ExpireAt != nil && now.After(*shortLink.ExpireAt) {
+			resolution.Status = AliasStatusExpired
+		}
+		if ownedAliases[alias] {
+			longLink := shortLink.LongLink
+			resolution.LongLink = &longLink
+		}
+		resolutions = append(resolutions, resolution)
+	}
+	return resolutions, nil
+}
+
+func (s StatusCheckerPersist) getOwnedAliases(ctx context.Context, viewer *entity.User) (map[string]bool, error) {
+	ownedAliases := make(map[string]bool)
+	if viewer == nil {
+		return ownedAliases, nil
+	}
+
+	aliases, err := s.userShortLinkRepo.FindAliasesByUser(ctx, *viewer)
+	if err != nil {
+		return nil, err
+	}
+	for _, alias := range aliases {
+		ownedAliases[alias] = true
+	}
+	return ownedAliases, nil
+}
+
+func uniqueAliases(aliases []string) []string {
+	seen := make(map[string]bool)
+	var unique []string
+	for _, alias := range aliases {
+		if seen[alias] {
+			continue
+		}
+		seen[alias] = true
+		unique = append(unique, alias)
+	}
+	return unique
+}
+
+// NewStatusCheckerPersist creates StatusCheckerPersist
+func NewStatusCheckerPersist(
+	shortLinkRepo repository.ShortLink,
+	userShortLinkRepo repository.UserShortLink,
+	timer timer.Timer,
+) StatusCheckerPersist {
+	return StatusCheckerPersist{
+		shortLinkRepo:     shortLinkRepo,
+		userShortLinkRepo: userShortLinkRepo,
+		timer:             timer,
+	}
+}
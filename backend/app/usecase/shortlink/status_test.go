@@ -0,0 +1,154 @@
+// +build !integration all
+
+package shortlink
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/fw/timertest"
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+func TestStatusCheckerPersist_ResolveAliases(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 5, 1, 10, 30, 0, 0, time.UTC)
+	before := now.Add(-time.Hour)
+	after := now.Add(time.Hour)
+
+	owner := entity.User{ID: "alpha"}
+	otherUser := entity.User{ID: "beta"}
+
+	ownedActiveLink := "https://www.google.com"
+	ownedExpiredLink := "https://github.com"
+
+	allShortLinks := shortLinks{
+		"owned-active": {
+			Alias:    "owned-active",
+			LongLink: ownedActiveLink,
+			ExpireAt: &after,
+		},
+		"owned-expired": {
+			Alias:    "owned-expired",
+			LongLink: ownedExpiredLink,
+			ExpireAt: &before,
+		},
+		"others-active": {
+			Alias:    "others-active",
+			LongLink: "https://www.bing.com",
+		},
+	}
+
+	testCases := []struct {
+		name                string
+		aliases             []string
+		viewer              *entity.User
+		expHasErr           bool
+		expectedResolutions []AliasResolution
+	}{
+		{
+			name:    "owner sees long links of owned short links",
+			aliases: []string{"owned-active", "owned-expired", "others-active", "missing"},
+			viewer:  &owner,
+			expectedResolutions: []AliasResolution{
+				{Alias: "owned-active", Status: AliasStatusActive, LongLink: &ownedActiveLink},
+				{Alias: "owned-expired", Status: AliasStatusExpired, LongLink: &ownedExpiredLink},
+				{Alias: "others-active", Status: AliasStatusActive},
+				{Alias: "missing", Status: AliasStatusNotFound},
+			},
+		},
+		{
+			name:    "non-owner only sees status",
+			aliases: []string{"owned-active", "owned-expired", "missing"},
+			viewer:  &otherUser,
+			expectedResolutions: []AliasResolution{
+				{Alias: "owned-active", Status: AliasStatusActive},
+				{Alias: "owned-expired", Status: AliasStatusExpired},
+				{Alias: "missing", Status: AliasStatusNotFound},
+			},
+		},
+		{
+			name:    "anonymous viewer only sees status",
+			aliases: []string{"owned-active", "owned-active"},
+			viewer:  nil,
+			expectedResolutions: []AliasResolution{
+				{Alias: "owned-active", Status: AliasStatusActive},
+				{Alias: "owned-active", Status: AliasStatusActive},
+			},
+		},
+		{
+			name:                "no aliases",
+			aliases:             []string{},
+			viewer:              &owner,
+			expectedResolutions: []AliasResolution{},
+		},
+		{
+			name:      "too many aliases",
+			aliases:   make([]string, maxAliasesPerBatch+1),
+			viewer:    &owner,
+			expHasErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			userShortLinkRepo := repository.NewUserShortLinkRepoFake(
+				[]entity.User{owner, owner},
+				[]entity.ShortLink{
+					allShortLinks["owned-active"],
+					allShortLinks["owned-expired"],
+				},
+			)
+			shortLinkRepo := repository.NewShortLinkFake(&userShortLinkRepo, allShortLinks)
+			statusChecker := NewStatusCheckerPersist(
+				&shortLinkRepo,
+				&userShortLinkRepo,
+				timer.NewStub(now),
+			)
+
+			resolutions, err := statusChecker.ResolveAliases(context.Background(), testCase.aliases, testCase.viewer)
+			if testCase.expHasErr {
+				assert.NotEqual(t, nil, err)
+				return
+			}
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedResolutions, resolutions)
+		})
+	}
+}
+
+func TestStatusCheckerPersist_ResolveAliasesExpiry(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 5, 1, 10, 30, 0, 0, time.UTC)
+	expireAt := now.Add(time.Hour)
+
+	userShortLinkRepo := repository.NewUserShortLinkRepoFake(nil, nil)
+	shortLinkRepo := repository.NewShortLinkFake(&userShortLinkRepo, shortLinks{
+		"expiring": {
+			Alias:    "expiring",
+			LongLink: "https://www.google.com",
+			ExpireAt: &expireAt,
+		},
+	})
+	fakeTimer := timertest.NewFakeTimer(now)
+	statusChecker := NewStatusCheckerPersist(&shortLinkRepo, &userShortLinkRepo, fakeTimer)
+
+	fakeTimer.Advance(time.Hour)
+	resolutions, err := statusChecker.ResolveAliases(context.Background(), []string{"expiring"}, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []AliasResolution{{Alias: "expiring", Status: AliasStatusActive}}, resolutions)
+
+	fakeTimer.Advance(time.Second)
+	resolutions, err = statusChecker.ResolveAliases(context.Background(), []string{"expiring"}, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []AliasResolution{{Alias: "expiring", Status: AliasStatusExpired}}, resolutions)
+}
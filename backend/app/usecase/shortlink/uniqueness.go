@@ -0,0 +1,98 @@
+package shortlink
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+// LongLinkUniqueness represents how many short links can redirect to the same
+// long link.
+type LongLinkUniqueness string
+
+// The constants enumerate all supported long link uniqueness modes.
+const (
+	// LongLinkUniquenessNone allows any number of short links to redirect to
+	// the same long link.
+	LongLinkUniquenessNone LongLinkUniqueness = "none"
+	// LongLinkUniquenessGlobal keeps one canonical short link for each long
+	// link across all users. Creating another short link for the same long
+	// link without a custom alias returns the canonical one instead when it
+	// is owned by the same user. Otherwise, a new short link is created, so
+	// that users never receive the short links they can't manage.
+	LongLinkUniquenessGlobal LongLinkUniqueness = "global"
+)
+
+// ErrUnknownLongLinkUniqueness represents the long link uniqueness mode which
+// is not supported.
+type ErrUnknownLongLinkUniqueness string
+
+func (e ErrUnknownLongLinkUniqueness) Error() string {
+	return "unknown long link uniqueness: " + string(e)
+}
+
+// ParseLongLinkUniqueness converts the name of the mode into
+// LongLinkUniqueness. Empty name disables the uniqueness.
+func ParseLongLinkUniqueness(name string) (LongLinkUniqueness, error) {
+	uniqueness := LongLinkUniqueness(name)
+	switch uniqueness {
+	case "":
+		return LongLinkUniquenessNone, nil
+	case LongLinkUniquenessNone, LongLinkUniquenessGlobal:
+		return uniqueness, nil
+	default:
+		return "", ErrUnknownLongLinkUniqueness(name)
+	}
+}
+
+// findCanonicalShortLink retrieves the active short link redirecting to the
+// normalized long link, if any is owned by the given user.
+func (c CreatorPersist) findCanonicalShortLink(ctx context.Context, longLink string, user entity.User) (entity.ShortLink, bool, error) {
+	now := c.timer.Now().UTC()
+	shortLink, err := c.shortLinkRepo.FindShortLinkByLongLink(ctx, longLink, now)
+	var notFound repository.ErrEntryNotFound
+	if errors.As(err, &notFound) {
+		return entity.ShortLink{}, false, nil
+	}
+	if err != nil {
+		return entity.ShortLink{}, false, err
+	}
+
+	isOwner, err := c.userShortLinkRepo.HasMapping(ctx, user, shortLink.Alias)
+	if err != nil || !isOwner {
+		return entity.ShortLink{}, false, err
+	}
+	return shortLink, true, nil
+}
+
+// normalizeLongLink rewrites the long link into the canonical form shared by
+// all the equivalent URLs, so that they map to the same short link. Long links
+// which are not URLs are returned unchanged.
+func normalizeLongLink(longLink string) string {
+	parsed, err := url.Parse(longLink)
+	if err != nil || parsed.Host == "" {
+		return longLink
+	}
+
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+	host := strings.ToLower(parsed.Hostname())
+	port := parsed.Port()
+	isDefaultPort := (parsed.Scheme == "http" && port == "80") ||
+		(parsed.Scheme == "https" && port == "443")
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if port != "" && !isDefaultPort {
+		host += ":" + port
+	}
+	parsed.Host = host
+
+	if parsed.Path == "" {
+		parsed.Path = "/"
+	}
+	return parsed.String()
+}
@@ -0,0 +1,329 @@
+// +build !integration all
+
+package shortlink
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/metrics"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/fw/ptr"
+	"github.com/short-d/short/backend/app/usecase/authorizer"
+	"github.com/short-d/short/backend/app/usecase/email"
+	"github.com/short-d/short/backend/app/usecase/keygen"
+	"github.com/short-d/short/backend/app/usecase/maintenance"
+	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/risk"
+	"github.com/short-d/short/backend/app/usecase/validator"
+	"github.com/short-d/short/backend/app/usecase/webhook"
+)
+
+func TestShortLinkCreatorPersist_CreateShortLinkUniqueness(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now().UTC()
+	createdAt := now.Add(-time.Hour)
+	expiredAt := now.Add(-time.Minute)
+
+	testCases := []struct {
+		name               string
+		uniqueness         LongLinkUniqueness
+		shortLinks         shortLinks
+		ownedShortLinks    []entity.ShortLink
+		shortLinkInput     entity.ShortLinkInput
+		expectedShortLink  entity.ShortLink
+		expectedHasMapping bool
+	}{
+		{
+			name:       "global mode returns existing short link of the same user",
+			uniqueness: LongLinkUniquenessGlobal,
+			shortLinks: shortLinks{
+				"google": entity.ShortLink{
+					Alias:     "google",
+					LongLink:  "https://google.com/",
+					CreatedAt: &createdAt,
+				},
+			},
+			ownedShortLinks: []entity.ShortLink{{Alias: "google"}},
+			shortLinkInput: entity.ShortLinkInput{
+				LongLink: ptr.String("https://google.com/"),
+			},
+			expectedShortLink: entity.ShortLink{
+				Alias:     "google",
+				LongLink:  "https://google.com/",
+				CreatedAt: &createdAt,
+			},
+			expectedHasMapping: true,
+		},
+		{
+			name:       "global mode matches normalized long link",
+			uniqueness: LongLinkUniquenessGlobal,
+			shortLinks: shortLinks{
+				"google": entity.ShortLink{
+					Alias:     "google",
+					LongLink:  "https://google.com/",
+					CreatedAt: &createdAt,
+				},
+			},
+			ownedShortLinks: []entity.ShortLink{{Alias: "google"}},
+			shortLinkInput: entity.ShortLinkInput{
+				LongLink: ptr.String("HTTPS://Google.com:443"),
+			},
+			expectedShortLink: entity.ShortLink{
+				Alias:     "google",
+				LongLink:  "https://google.com/",
+				CreatedAt: &createdAt,
+			},
+			expectedHasMapping: true,
+		},
+		{
+			name:       "global mode creates own short link when another user owns the existing one",
+			uniqueness: LongLinkUniquenessGlobal,
+			shortLinks: shortLinks{
+				"google": entity.ShortLink{
+					Alias:     "google",
+					LongLink:  "https://google.com/",
+					CreatedAt: &createdAt,
+				},
+			},
+			shortLinkInput: entity.ShortLinkInput{
+				LongLink: ptr.String("https://google.com/"),
+			},
+			expectedShortLink: entity.ShortLink{
+				Alias:            "220uFicCJj",
+				LongLink:         "https://google.com/",
+				OriginalLongLink: "https://google.com/",
+				CreatedAt:        &now,
+				TrackVisits:      true,
+			},
+			expectedHasMapping: true,
+		},
+		{
+			name:       "global mode creates requested custom alias",
+			uniqueness: LongLinkUniquenessGlobal,
+			shortLinks: shortLinks{
+				"google": entity.ShortLink{
+					Alias:     "google",
+					LongLink:  "https://google.com/",
+					CreatedAt: &createdAt,
+				},
+			},
+			ownedShortLinks: []entity.ShortLink{{Alias: "google"}},
+			shortLinkInput: entity.ShortLinkInput{
+				LongLink:    ptr.String("https://google.com/"),
+				CustomAlias: ptr.String("find"),
+			},
+			expectedShortLink: entity.ShortLink{
+				Alias:            "find",
+				LongLink:         "https://google.com/",
+				OriginalLongLink: "https://google.com/",
+				CreatedAt:        &now,
+				TrackVisits:      true,
+			},
+			expectedHasMapping: true,
+		},
+		{
+			name:       "global mode ignores expired short link",
+			uniqueness: LongLinkUniquenessGlobal,
+			shortLinks: shortLinks{
+				"google": entity.ShortLink{
+					Alias:     "google",
+					LongLink:  "https://google.com/",
+					ExpireAt:  &expiredAt,
+					CreatedAt: &createdAt,
+				},
+			},
+			shortLinkInput: entity.ShortLinkInput{
+				LongLink:    ptr.String("https://Google.com"),
+				CustomAlias: ptr.String("find"),
+			},
+			expectedShortLink: entity.ShortLink{
+				Alias:            "find",
+				LongLink:         "https://google.com/",
+				OriginalLongLink: "https://Google.com",
+				CreatedAt:        &now,
+				TrackVisits:      true,
+			},
+			expectedHasMapping: true,
+		},
+		{
+			name:       "default mode allows multiple short links",
+			uniqueness: LongLinkUniquenessNone,
+			shortLinks: shortLinks{
+				"google": entity.ShortLink{
+					Alias:     "google",
+					LongLink:  "https://google.com/",
+					CreatedAt: &createdAt,
+				},
+			},
+			shortLinkInput: entity.ShortLinkInput{
+				LongLink:    ptr.String("https://google.com/"),
+				CustomAlias: ptr.String("find"),
+			},
+			expectedShortLink: entity.ShortLink{
+				Alias:            "find",
+				LongLink:         "https://google.com/",
+				OriginalLongLink: "https://google.com/",
+				CreatedAt:        &now,
+				TrackVisits:      true,
+			},
+			expectedHasMapping: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			user := entity.User{ID: "beta", Email: "beta@example.com"}
+			owners := make([]entity.User, len(testCase.ownedShortLinks))
+			for idx := range owners {
+				owners[idx] = user
+			}
+			shortLinkRepo := repository.NewShortLinkFake(nil, testCase.shortLinks)
+			userShortLinkRepo := repository.NewUserShortLinkRepoFake(owners, testCase.ownedShortLinks)
+			keyFetcher := keygen.NewKeyFetcherFake([]keygen.Key{"220uFicCJj"})
+			keyGen, err := keygen.NewRemote(1, &keyFetcher)
+			assert.Equal(t, nil, err)
+			flaggedLinkRepo := repository.NewFlaggedShortLinkFake(nil)
+
+			preferencesRepo := repository.NewUserPreferencesFake(nil)
+			creator := NewCreatorPersist(
+				&shortLinkRepo,
+				&userShortLinkRepo,
+				keyGen,
+				validator.NewLongLink(nil, nil, validator.FragmentPreserve, validator.PlainHTTPAllow),
+				validator.NewCustomAlias(),
+				timer.NewStub(now),
+				risk.NewDetector(
+					risk.NewBlackListFake(nil),
+					risk.NewDenylistFake(nil),
+					risk.StrictThresholds,
+				),
+				&flaggedLinkRepo,
+				DefaultChecks,
+				testCase.uniqueness,
+				AliasQuota{},
+				authorizer.Authorizer{},
+				email.NewSenderFake(nil),
+				webhook.NewDispatcherFake(),
+				&preferencesRepo,
+				maintenance.Mode{},
+				metrics.NewFake(),
+				0,
+				ExpirationPolicy{},
+				&repository.AliasSkeletonFake{},
+				RelationRetry{},
+				&repository.AliasReservationFake{},
+				ReservationTTL{},
+				ChainedLink{},
+			)
+
+			shortLink, err := creator.CreateShortLink(context.Background(), testCase.shortLinkInput, user, false)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedShortLink, shortLink)
+
+			hasMapping, err := userShortLinkRepo.HasMapping(context.Background(), user, shortLink.Alias)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedHasMapping, hasMapping)
+		})
+	}
+}
+
+func TestParseLongLinkUniqueness(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name               string
+		uniquenessName     string
+		expectedUniqueness LongLinkUniqueness
+		expectedErr        error
+	}{
+		{
+			name:               "empty name",
+			uniquenessName:     "",
+			expectedUniqueness: LongLinkUniquenessNone,
+		},
+		{
+			name:               "none",
+			uniquenessName:     "none",
+			expectedUniqueness: LongLinkUniquenessNone,
+		},
+		{
+			name:               "global",
+			uniquenessName:     "global",
+			expectedUniqueness: LongLinkUniquenessGlobal,
+		},
+		{
+			name:           "unknown name",
+			uniquenessName: "per_user",
+			expectedErr:    ErrUnknownLongLinkUniqueness("per_user"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			uniqueness, err := ParseLongLinkUniqueness(testCase.uniquenessName)
+			assert.Equal(t, testCase.expectedErr, err)
+			assert.Equal(t, testCase.expectedUniqueness, uniqueness)
+		})
+	}
+}
+
+func TestNormalizeLongLink(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name             string
+		longLink         string
+		expectedLongLink string
+	}{
+		{
+			name:             "canonical long link",
+			longLink:         "https://google.com/",
+			expectedLongLink: "https://google.com/",
+		},
+		{
+			name:             "upper case scheme and host",
+			longLink:         "HTTPS://WWW.Google.COM/Search?q=Short",
+			expectedLongLink: "https://www.google.com/Search?q=Short",
+		},
+		{
+			name:             "default port",
+			longLink:         "http://google.com:80/search",
+			expectedLongLink: "http://google.com/search",
+		},
+		{
+			name:             "non default port",
+			longLink:         "https://google.com:8443",
+			expectedLongLink: "https://google.com:8443/",
+		},
+		{
+			name:             "IPv6 host with default port",
+			longLink:         "https://[::1]:443/",
+			expectedLongLink: "https://[::1]/",
+		},
+		{
+			name:             "not URL",
+			longLink:         "google",
+			expectedLongLink: "google",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, testCase.expectedLongLink, normalizeLongLink(testCase.longLink))
+		})
+	}
+}
@@ -1,8 +1,12 @@
 package shortlink
 
 import (
+	"context"
+
 	"github.com/short-d/app/fw/timer"
 	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/email"
+	"github.com/short-d/short/backend/app/usecase/maintenance"
 	"github.com/short-d/short/backend/app/usecase/repository"
 	"github.com/short-d/short/backend/app/usecase/risk"
 	"github.com/short-d/short/backend/app/usecase/validator"
@@ -26,7 +30,7 @@ func (e ErrEmptyAlias) Error() string {
 
 // Updater mutates existing short links.
 type Updater interface {
-	UpdateShortLink(oldAlias string, shortLinkInput entity.ShortLinkInput, user entity.User) (entity.ShortLink, error)
+	UpdateShortLink(ctx context.Context, oldAlias string, shortLinkInput entity.ShortLinkInput, user entity.User) (entity.ShortLink, error)
 }
 
 // UpdaterPersist persists the mutated short link in the data store.
@@ -37,15 +41,33 @@ type UpdaterPersist struct {
 	aliasValidator    validator.CustomAlias
 	timer             timer.Timer
 	riskDetector      risk.Detector
+	checks            []Check
+	aliasSkeletonRepo repository.AliasSkeleton
+	flaggedLinkRepo   repository.FlaggedShortLink
+	emailSender       email.Sender
+	maintenanceMode   maintenance.Mode
+	reservationRepo   repository.AliasReservation
+	chainedLink       ChainedLink
 }
 
-// UpdateShortLink mutates a short link in the repository.
+// UpdateShortLink mutates a short link in the repository unless the service is
+// in read-only mode. The short link can only be renamed to the alias reserved
+// by the user with the reservation token. The new long link is handled the
+// same way as the one of a new short link when it is a short link itself. The mutated short link goes through
+// the same checks as new short links, including the confusable alias check
+// when it is renamed, and is flagged for review when its long
+// link looks suspicious without being malicious.
 func (u UpdaterPersist) UpdateShortLink(
+	ctx context.Context,
 	oldAlias string,
 	shortLinkInput entity.ShortLinkInput,
 	user entity.User,
 ) (entity.ShortLink, error) {
-	hasMapping, err := u.userShortLinkRepo.HasMapping(user, oldAlias)
+	if u.maintenanceMode.IsReadOnly() {
+		return entity.ShortLink{}, ErrServiceReadOnly("update short link")
+	}
+
+	hasMapping, err := u.userShortLinkRepo.HasMapping(ctx, user, oldAlias)
 	if err != nil {
 		return entity.ShortLink{}, err
 	}
@@ -53,51 +75,110 @@ func (u UpdaterPersist) UpdateShortLink(
 		return entity.ShortLink{}, ErrShortLinkNotFound(oldAlias)
 	}
 
-	newAlias := shortLinkInput.GetCustomAlias(oldAlias)
+	newAlias := normalizeAlias(shortLinkInput.GetCustomAlias(oldAlias))
 	if newAlias == "" {
 		return entity.ShortLink{}, ErrEmptyAlias("alias is empty")
 	}
 
 	// Only check if it exists if user is changing the alias to something else
 	if newAlias != oldAlias {
-		aliasExist, err := u.shortLinkRepo.IsAliasExist(newAlias)
+		aliasExist, err := u.shortLinkRepo.IsAliasExist(ctx, newAlias)
 		if err != nil {
 			return entity.ShortLink{}, err
 		}
 		if aliasExist {
 			return entity.ShortLink{}, ErrAliasExist("short link alias already exists")
 		}
+
+		now := u.timer.Now().UTC()
+		err = checkReservation(ctx, u.reservationRepo, newAlias, shortLinkInput.GetReservationToken(""), &user, now)
+		if err != nil {
+			return entity.ShortLink{}, err
+		}
 	}
 
-	shortLink, err := u.shortLinkRepo.GetShortLinkByAlias(oldAlias)
+	shortLink, err := u.shortLinkRepo.GetShortLinkByAlias(ctx, oldAlias)
 	if err != nil {
 		return entity.ShortLink{}, err
 	}
 
-	longLink := shortLinkInput.GetLongLink(shortLink.LongLink)
-
-	isValid, violation := u.aliasValidator.IsValid(newAlias)
-	if !isValid {
-		return entity.ShortLink{}, ErrInvalidCustomAlias{newAlias, violation}
+	longLink := shortLink.LongLink
+	if shortLinkInput.LongLink != nil {
+		longLink, err = u.resolveChainedLink(ctx, shortLinkInput.GetLongLink(""))
+		if err != nil {
+			return entity.ShortLink{}, err
+		}
 	}
+	longLink = canonicalizeLongLink(u.longLinkValidator, LongLinkUniquenessNone, longLink)
+	originalLongLink := shortLinkInput.GetLongLink(shortLink.GetOriginalLongLink())
 
-	isValid, violation = u.longLinkValidator.IsValid(longLink)
-	if !isValid {
-		return entity.ShortLink{}, ErrInvalidLongLink{longLink, violation}
+	description := shortLinkInput.GetDescription(shortLink.Description)
+	err = validateDescription(description)
+	if err != nil {
+		return entity.ShortLink{}, err
 	}
 
-	if u.riskDetector.IsURLMalicious(longLink) {
-		return entity.ShortLink{}, ErrMaliciousLongLink(longLink)
+	report, err := u.checker().runChecks(ctx, entity.ShortLinkInput{
+		CustomAlias: &newAlias,
+		LongLink:    &longLink,
+	}, newAlias != oldAlias)
+	if err != nil {
+		return entity.ShortLink{}, err
 	}
 
+	trackVisits := shortLinkInput.GetTrackVisits(shortLink.TrackVisits)
+	passthroughQuery := shortLinkInput.GetPassthroughQuery(shortLink.PassthroughQuery)
+	passthroughPath := shortLinkInput.GetPassthroughPath(shortLink.PassthroughPath)
+	maxRedirectsPerIP := shortLinkInput.GetMaxRedirectsPerIP(shortLink.MaxRedirectsPerIP)
 	updateTime := u.timer.Now()
 
-	return u.shortLinkRepo.UpdateShortLink(oldAlias, entity.ShortLinkInput{
-		CustomAlias: &newAlias,
-		LongLink:    &longLink,
-		ExpireAt:    shortLink.ExpireAt,
-		UpdatedAt:   &updateTime,
+	updated, err := u.shortLinkRepo.UpdateShortLink(ctx, oldAlias, entity.ShortLinkInput{
+		CustomAlias:       &newAlias,
+		LongLink:          &longLink,
+		OriginalLongLink:  &originalLongLink,
+		ExpireAt:          shortLink.ExpireAt,
+		UpdatedAt:         &updateTime,
+		TrackVisits:       &trackVisits,
+		PassthroughQuery:  &passthroughQuery,
+		PassthroughPath:   &passthroughPath,
+		MaxRedirectsPerIP: &maxRedirectsPerIP,
+		Description:       &description,
 	})
+	if err != nil {
+		return entity.ShortLink{}, err
+	}
+	if newAlias != oldAlias {
+		releaseReservation(ctx, u.reservationRepo, newAlias, shortLinkInput.GetReservationToken(""))
+
+		err = u.checker().moveSkeleton(ctx, oldAlias, newAlias)
+		if err != nil {
+			return updated, err
+		}
+	}
+	if report.riskVerdict != risk.VerdictWarn {
+		return updated, nil
+	}
+
+	err = u.flaggedLinkRepo.CreateFlaggedShortLink(ctx, entity.FlaggedShortLink{
+		Alias:     updated.Alias,
+		RiskScore: int(report.riskScore),
+		FlaggedAt: u.timer.Now().UTC(),
+	})
+	if err != nil {
+		return updated, err
+	}
+	notifyFlagged(u.emailSender, user, updated)
+	return updated, nil
+}
+
+func (u UpdaterPersist) checker() checker {
+	return checker{
+		checks:            u.checks,
+		aliasValidator:    u.aliasValidator,
+		longLinkValidator: u.longLinkValidator,
+		riskDetector:      u.riskDetector,
+		aliasSkeletonRepo: u.aliasSkeletonRepo,
+	}
 }
 
 // NewUpdaterPersist creates a new UpdaterPersist instance.
@@ -108,6 +189,13 @@ func NewUpdaterPersist(
 	aliasValidator validator.CustomAlias,
 	timer timer.Timer,
 	riskDetector risk.Detector,
+	checks []Check,
+	aliasSkeletonRepo repository.AliasSkeleton,
+	flaggedLinkRepo repository.FlaggedShortLink,
+	emailSender email.Sender,
+	maintenanceMode maintenance.Mode,
+	reservationRepo repository.AliasReservation,
+	chainedLink ChainedLink,
 ) UpdaterPersist {
 	return UpdaterPersist{
 		shortLinkRepo,
@@ -116,5 +204,12 @@ func NewUpdaterPersist(
 		aliasValidator,
 		timer,
 		riskDetector,
+		checks,
+		aliasSkeletonRepo,
+		flaggedLinkRepo,
+		emailSender,
+		maintenanceMode,
+		reservationRepo,
+		chainedLink,
 	}
 }
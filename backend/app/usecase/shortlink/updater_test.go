@@ -3,13 +3,16 @@
 package shortlink
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	"github.com/short-d/app/fw/assert"
-	"github.com/short-d/app/fw/timer"
 	"github.com/short-d/short/backend/app/entity"
 	"github.com/short-d/short/backend/app/fw/ptr"
+	"github.com/short-d/short/backend/app/fw/timertest"
+	"github.com/short-d/short/backend/app/usecase/email"
+	"github.com/short-d/short/backend/app/usecase/maintenance"
 	"github.com/short-d/short/backend/app/usecase/repository"
 	"github.com/short-d/short/backend/app/usecase/risk"
 	"github.com/short-d/short/backend/app/usecase/validator"
@@ -354,17 +357,18 @@ func TestShortLinkUpdaterPersist_UpdateShortLink(t *testing.T) {
 		testCase := testCase
 		t.Run(testCase.name, func(t *testing.T) {
 			t.Parallel()
-			tm := timer.NewStub(now)
+			tm := timertest.NewFakeTimer(now)
+			tm.Advance(time.Hour)
 			userShortLinkRepo := repository.NewUserShortLinkRepoFake(
 				testCase.relationUsers,
 				testCase.relationShortLinks,
 			)
 			shortLinkRepo := repository.NewShortLinkFake(&userShortLinkRepo, testCase.shortlinks)
 
-			longLinkValidator := validator.NewLongLink()
+			longLinkValidator := validator.NewLongLink(nil, nil, validator.FragmentPreserve, validator.PlainHTTPAllow)
 			aliasValidator := validator.NewCustomAlias()
 			blacklist := risk.NewBlackListFake(testCase.blockedLongLinks)
-			riskDetector := risk.NewDetector(blacklist)
+			riskDetector := risk.NewDetector(blacklist, risk.NewDenylistFake(nil), risk.StrictThresholds)
 			updater := NewUpdaterPersist(
 				&shortLinkRepo,
 				&userShortLinkRepo,
@@ -372,16 +376,23 @@ func TestShortLinkUpdaterPersist_UpdateShortLink(t *testing.T) {
 				aliasValidator,
 				tm,
 				riskDetector,
+				DefaultChecks,
+				&repository.AliasSkeletonFake{},
+				&repository.FlaggedShortLinkFake{},
+				email.NewSenderFake(nil),
+				maintenance.Mode{},
+				&repository.AliasReservationFake{},
+				ChainedLink{},
 			)
 
-			shortLink, err := updater.UpdateShortLink(testCase.alias, testCase.shortLinkInput, testCase.user)
+			shortLink, err := updater.UpdateShortLink(context.Background(), testCase.alias, testCase.shortLinkInput, testCase.user)
 			if testCase.expectedHasErr {
 				assert.NotEqual(t, nil, err)
 
-				_, err = shortLinkRepo.GetShortLinkByAlias(testCase.expectedShortLink.Alias)
+				_, err = shortLinkRepo.GetShortLinkByAlias(context.Background(), testCase.expectedShortLink.Alias)
 				assert.NotEqual(t, nil, err)
 
-				isExist, err := userShortLinkRepo.HasMapping(testCase.user, testCase.expectedShortLink.Alias)
+				isExist, err := userShortLinkRepo.HasMapping(context.Background(), testCase.user, testCase.expectedShortLink.Alias)
 				assert.Equal(t, nil, err)
 				assert.Equal(t, false, isExist)
 				return
@@ -390,12 +401,121 @@ func TestShortLinkUpdaterPersist_UpdateShortLink(t *testing.T) {
 			assert.Equal(t, testCase.expectedShortLink.LongLink, shortLink.LongLink)
 			assert.Equal(t, testCase.expectedShortLink.Alias, shortLink.Alias)
 			assert.Equal(t, testCase.expectedShortLink.CreatedAt, shortLink.CreatedAt)
-			if shortLink.UpdatedAt != nil {
-				assert.Equal(t, true, shortLink.UpdatedAt.After(now))
-			}
-			isExist, err := userShortLinkRepo.HasMapping(testCase.user, shortLink.Alias)
+			assert.Equal(t, now.Add(time.Hour), *shortLink.UpdatedAt)
+			isExist, err := userShortLinkRepo.HasMapping(context.Background(), testCase.user, shortLink.Alias)
 			assert.Equal(t, nil, err)
 			assert.Equal(t, true, isExist)
 		})
 	}
 }
+
+func TestShortLinkUpdaterPersist_UpdateShortLinkRiskThresholds(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now().UTC()
+	suspiciousLink := "http://suspicious.example.com/download"
+
+	testCases := []struct {
+		name                      string
+		thresholds                risk.Thresholds
+		expHasErr                 bool
+		expectedFlaggedShortLinks []entity.FlaggedShortLink
+		expectedEmails            []email.Message
+	}{
+		{
+			name:       "suspicious link blocked under strict thresholds",
+			thresholds: risk.StrictThresholds,
+			expHasErr:  true,
+		},
+		{
+			name: "suspicious link allowed with flag under lenient thresholds",
+			thresholds: risk.Thresholds{
+				Block: risk.ScoreMalicious,
+				Warn:  risk.ScoreSuspicious,
+			},
+			expectedFlaggedShortLinks: []entity.FlaggedShortLink{
+				{
+					Alias:     "220uFicCJj",
+					RiskScore: int(risk.ScoreSuspicious),
+					FlaggedAt: now,
+				},
+			},
+			expectedEmails: []email.Message{
+				{
+					To:      "alpha@example.com",
+					Subject: "Your short link 220uFicCJj is under review",
+					Body: "Your short link 220uFicCJj redirecting to " +
+						"http://suspicious.example.com/download looks suspicious " +
+						"to our safety checks, so it has been flagged for review. " +
+						"It keeps working until the review is done.\n",
+				},
+			},
+		},
+		{
+			name: "suspicious link allowed without flag when warn tier is off",
+			thresholds: risk.Thresholds{
+				Block: risk.ScoreMalicious,
+				Warn:  risk.ScoreMalicious,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			user := entity.User{ID: "alpha", Email: "alpha@example.com"}
+			shortLink := entity.ShortLink{Alias: "220uFicCJj", LongLink: "https://www.google.com"}
+			userShortLinkRepo := repository.NewUserShortLinkRepoFake(
+				[]entity.User{user},
+				[]entity.ShortLink{shortLink},
+			)
+			shortLinkRepo := repository.NewShortLinkFake(&userShortLinkRepo, shortLinks{
+				"220uFicCJj": shortLink,
+			})
+			blacklist := risk.NewScoredBlackListFake(map[string]risk.Score{
+				suspiciousLink: risk.ScoreSuspicious,
+			})
+			flaggedLinkRepo := repository.NewFlaggedShortLinkFake(nil)
+			emailSender := email.NewSenderFake(nil)
+
+			updater := NewUpdaterPersist(
+				&shortLinkRepo,
+				&userShortLinkRepo,
+				validator.NewLongLink(nil, nil, validator.FragmentPreserve, validator.PlainHTTPAllow),
+				validator.NewCustomAlias(),
+				timertest.NewFakeTimer(now),
+				risk.NewDetector(blacklist, risk.NewDenylistFake(nil), testCase.thresholds),
+				DefaultChecks,
+				&repository.AliasSkeletonFake{},
+				&flaggedLinkRepo,
+				emailSender,
+				maintenance.Mode{},
+				&repository.AliasReservationFake{},
+				ChainedLink{},
+			)
+
+			shortLinkInput := entity.ShortLinkInput{LongLink: ptr.String(suspiciousLink)}
+			updated, err := updater.UpdateShortLink(context.Background(), "220uFicCJj", shortLinkInput, user)
+			if testCase.expHasErr {
+				assert.Equal(t, ErrMaliciousLongLink(suspiciousLink), err)
+
+				shortLink, err := shortLinkRepo.GetShortLinkByAlias(context.Background(), "220uFicCJj")
+				assert.Equal(t, nil, err)
+				assert.Equal(t, "https://www.google.com", shortLink.LongLink)
+				assert.Equal(t, 0, len(flaggedLinkRepo.FlaggedShortLinks()))
+				assert.Equal(t, 0, len(emailSender.Messages()))
+				return
+			}
+			assert.Equal(t, nil, err)
+			assert.Equal(t, suspiciousLink, updated.LongLink)
+			assert.Equal(t, testCase.expectedFlaggedShortLinks, flaggedLinkRepo.FlaggedShortLinks())
+			if testCase.expectedEmails == nil {
+				assert.Equal(t, 0, len(emailSender.Messages()))
+				return
+			}
+			assert.Equal(t, testCase.expectedEmails, emailSender.Messages())
+		})
+	}
+}
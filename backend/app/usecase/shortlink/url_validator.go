@@ -0,0 +1,130 @@
+package shortlink
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/short-d/short/backend/app/usecase/risk"
+	"github.com/short-d/short/backend/app/usecase/validator"
+)
+
+var _ URLValidator = (*URLValidatorConcurrent)(nil)
+
+// maxURLsPerBatch limits the number of URLs validated in a single request to
+// protect the risk detection services.
+const maxURLsPerBatch = 100
+
+// ValidationStatus represents whether a URL can be used as a long link.
+type ValidationStatus string
+
+// The constants enumerate all supported validation statuses.
+const (
+	ValidationStatusValid     ValidationStatus = "valid"
+	ValidationStatusInvalid   ValidationStatus = "invalid"
+	ValidationStatusMalicious ValidationStatus = "malicious"
+)
+
+// ErrTooManyURLs represents the number of URLs to validate exceeds the limit
+// of a single batch.
+type ErrTooManyURLs string
+
+func (e ErrTooManyURLs) Error() string {
+	return string(e)
+}
+
+// ValidationResult represents whether a URL can be used as a long link.
+// Violation explains why an invalid URL is rejected.
+type ValidationResult struct {
+	URL       string
+	Status    ValidationStatus
+	Violation validator.Violation
+}
+
+// URLValidator checks many URLs at once without creating short links.
+type URLValidator interface {
+	ValidateURLs(urls []string) ([]ValidationResult, error)
+}
+
+// URLValidatorConcurrent validates URLs with a bounded number of workers so
+// that risk detection of large batches runs in parallel.
+type URLValidatorConcurrent struct {
+	longLinkValidator validator.LongLink
+	riskDetector      risk.Detector
+	maxWorkers        int
+}
+
+// ValidateURLs checks the given URLs against the long link rules and the risk
+// detector, returning the results in the same order.
+func (u URLValidatorConcurrent) ValidateURLs(urls []string) ([]ValidationResult, error) {
+	if len(urls) > maxURLsPerBatch {
+		msg := fmt.Sprintf("at most %d URLs can be validated at once", maxURLsPerBatch)
+		return nil, ErrTooManyURLs(msg)
+	}
+
+	results := make([]ValidationResult, len(urls))
+	indices := make(chan int)
+
+	workers := u.maxWorkers
+	if workers > len(urls) {
+		workers = len(urls)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for worker := 0; worker < workers; worker++ {
+		go func() {
+			defer wg.Done()
+			for idx := range indices {
+				results[idx] = u.validateURL(urls[idx])
+			}
+		}()
+	}
+
+	for idx := range urls {
+		indices <- idx
+	}
+	close(indices)
+	wg.Wait()
+	return results, nil
+}
+
+func (u URLValidatorConcurrent) validateURL(url string) ValidationResult {
+	isValid, violation := u.longLinkValidator.IsValid(url)
+	if !isValid {
+		return ValidationResult{
+			URL:       url,
+			Status:    ValidationStatusInvalid,
+			Violation: violation,
+		}
+	}
+
+	if u.riskDetector.IsURLMalicious(url) {
+		return ValidationResult{
+			URL:       url,
+			Status:    ValidationStatusMalicious,
+			Violation: validator.Valid,
+		}
+	}
+	return ValidationResult{
+		URL:       url,
+		Status:    ValidationStatusValid,
+		Violation: validator.Valid,
+	}
+}
+
+// NewURLValidatorConcurrent creates URLValidatorConcurrent which runs at most
+// maxWorkers risk checks at the same time.
+func NewURLValidatorConcurrent(
+	longLinkValidator validator.LongLink,
+	riskDetector risk.Detector,
+	maxWorkers int,
+) URLValidatorConcurrent {
+	if maxWorkers < 1 {
+		maxWorkers = 1
+	}
+	return URLValidatorConcurrent{
+		longLinkValidator: longLinkValidator,
+		riskDetector:      riskDetector,
+		maxWorkers:        maxWorkers,
+	}
+}
@@ -0,0 +1,132 @@
+// +build !integration all
+
+package shortlink
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/short/backend/app/usecase/risk"
+	"github.com/short-d/short/backend/app/usecase/validator"
+)
+
+func TestURLValidatorConcurrent_ValidateURLs(t *testing.T) {
+	t.Parallel()
+
+	manyURLs := make([]string, maxURLsPerBatch)
+	manyResults := make([]ValidationResult, maxURLsPerBatch)
+	for idx := range manyURLs {
+		url := fmt.Sprintf("https://www.google.com/%d", idx)
+		manyURLs[idx] = url
+		manyResults[idx] = ValidationResult{
+			URL:       url,
+			Status:    ValidationStatusValid,
+			Violation: validator.Valid,
+		}
+	}
+
+	testCases := []struct {
+		name            string
+		urls            []string
+		blockedURLs     map[string]bool
+		maxWorkers      int
+		hasErr          bool
+		expectedResults []ValidationResult
+	}{
+		{
+			name:            "no URL",
+			urls:            []string{},
+			maxWorkers:      4,
+			expectedResults: []ValidationResult{},
+		},
+		{
+			name: "mix of valid, malformed and malicious URLs",
+			urls: []string{
+				"https://www.google.com",
+				"google",
+				"",
+				"https://malware.wicar.org",
+				"http://www.bing.com",
+			},
+			blockedURLs: map[string]bool{"https://malware.wicar.org": true},
+			maxWorkers:  2,
+			expectedResults: []ValidationResult{
+				{
+					URL:       "https://www.google.com",
+					Status:    ValidationStatusValid,
+					Violation: validator.Valid,
+				},
+				{
+					URL:       "google",
+					Status:    ValidationStatusInvalid,
+					Violation: validator.LongLinkNotURL,
+				},
+				{
+					URL:       "",
+					Status:    ValidationStatusInvalid,
+					Violation: validator.EmptyLongLink,
+				},
+				{
+					URL:       "https://malware.wicar.org",
+					Status:    ValidationStatusMalicious,
+					Violation: validator.Valid,
+				},
+				{
+					URL:       "http://www.bing.com",
+					Status:    ValidationStatusValid,
+					Violation: validator.Valid,
+				},
+			},
+		},
+		{
+			name:       "more workers than URLs",
+			urls:       []string{"https://www.google.com"},
+			maxWorkers: 10,
+			expectedResults: []ValidationResult{
+				{
+					URL:       "https://www.google.com",
+					Status:    ValidationStatusValid,
+					Violation: validator.Valid,
+				},
+			},
+		},
+		{
+			name:            "URLs at the limit",
+			urls:            manyURLs,
+			maxWorkers:      8,
+			expectedResults: manyResults,
+		},
+		{
+			name:       "too many URLs",
+			urls:       append(manyURLs, "https://www.google.com"),
+			maxWorkers: 8,
+			hasErr:     true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			urlValidator := NewURLValidatorConcurrent(
+				validator.NewLongLink(nil, nil, validator.FragmentPreserve, validator.PlainHTTPAllow),
+				risk.NewDetector(
+					risk.NewBlackListFake(testCase.blockedURLs),
+					risk.NewDenylistFake(nil),
+					risk.StrictThresholds,
+				),
+				testCase.maxWorkers,
+			)
+
+			results, err := urlValidator.ValidateURLs(testCase.urls)
+			if testCase.hasErr {
+				assert.NotEqual(t, nil, err)
+				return
+			}
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedResults, results)
+		})
+	}
+}
@@ -4,5 +4,5 @@ import "github.com/short-d/short/backend/app/entity"
 
 // Account accesses account data from the identity provider.
 type Account interface {
-	GetSingleSignOnUser(accessToken string) (entity.SSOUser, error)
+	GetSingleSignOnUser(accessToken string, session Session) (entity.SSOUser, error)
 }
@@ -12,7 +12,7 @@ type AccountFake struct {
 
 // GetSingleSignOnUser retrieves user information from identity provider using
 // access token.
-func (a AccountFake) GetSingleSignOnUser(accessToken string) (entity.SSOUser, error) {
+func (a AccountFake) GetSingleSignOnUser(accessToken string, session Session) (entity.SSOUser, error) {
 	return a.user, nil
 }
 
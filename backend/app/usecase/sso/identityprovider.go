@@ -3,6 +3,6 @@ package sso
 // IdentityProvider represents external service that verifies the user's
 // identity.
 type IdentityProvider interface {
-	GetAuthorizationURL() string
-	RequestAccessToken(authorizationCode string) (accessToken string, err error)
+	GetAuthorizationURL(session Session) string
+	RequestAccessToken(authorizationCode string, session Session) (accessToken string, err error)
 }
@@ -11,12 +11,12 @@ type IdentityProviderFake struct {
 
 // GetAuthorizationURL retrieves the URL where user can sign in and obtain
 // authorization code.
-func (i IdentityProviderFake) GetAuthorizationURL() string {
+func (i IdentityProviderFake) GetAuthorizationURL(session Session) string {
 	return i.authURL
 }
 
 // RequestAccessToken retrieves access token given authorization code.
-func (i IdentityProviderFake) RequestAccessToken(authorizationCode string) (accessToken string, err error) {
+func (i IdentityProviderFake) RequestAccessToken(authorizationCode string, session Session) (accessToken string, err error) {
 	return i.accessToken, nil
 }
 
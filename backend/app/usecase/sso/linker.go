@@ -6,13 +6,23 @@ import (
 	"github.com/short-d/short/backend/app/entity"
 	"github.com/short-d/short/backend/app/usecase/keygen"
 	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/verification"
 )
 
+// ErrEmailNotVerified represents the internal account sharing the same email
+// as the external account can't be trusted because its email is not verified.
+type ErrEmailNotVerified string
+
+func (e ErrEmailNotVerified) Error() string {
+	return string(e)
+}
+
 // AccountLinker maps external user accounts to Short user accounts.
 type AccountLinker struct {
-	keyGen   keygen.KeyGenerator
-	userRepo repository.User
-	ssoMap   repository.SSOMap
+	keyGen        keygen.KeyGenerator
+	userRepo      repository.User
+	ssoMap        repository.SSOMap
+	emailVerifier verification.EmailVerifier
 }
 
 // IsAccountLinked checks whether a given external account is linked to any
@@ -32,7 +42,9 @@ func (a AccountLinker) GetShortUser(ssoUser entity.SSOUser) (entity.User, error)
 
 // CreateAndLinkAccount creates an internal account when there is no internal
 // account sharing the same email as the given external account and link them
-// together afterwards.
+// together afterwards. The accounts are only linked by email when the email
+// of the internal account is verified, otherwise anyone registering the email
+// elsewhere could take over the account.
 func (a AccountLinker) CreateAndLinkAccount(ssoUser entity.SSOUser) error {
 	if len(ssoUser.Email) < 1 {
 		userID, err := a.createAccount(ssoUser)
@@ -44,6 +56,9 @@ func (a AccountLinker) CreateAndLinkAccount(ssoUser entity.SSOUser) error {
 
 	user, err := a.userRepo.GetUserByEmail(ssoUser.Email)
 	if err == nil {
+		if !user.EmailVerified {
+			return ErrEmailNotVerified(user.Email)
+		}
 		return a.ssoMap.CreateMapping(ssoUser.ID, user.ID)
 	}
 
@@ -64,7 +79,7 @@ func (a AccountLinker) createAccount(ssoUser entity.SSOUser) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	err = a.createUser(userID, ssoUser.Name, ssoUser.Email)
+	err = a.createUser(userID, ssoUser)
 	return userID, err
 }
 
@@ -73,19 +88,29 @@ func (a AccountLinker) generateUnassignedUserID() (string, error) {
 	return string(newKey), err
 }
 
-func (a AccountLinker) createUser(id string, name string, email string) error {
+// createUser asks the user to verify the email of the new account unless the
+// identity provider verified it already. Failing to send the verification
+// email doesn't block signing up since it can be sent again later.
+func (a AccountLinker) createUser(id string, ssoUser entity.SSOUser) error {
 	user := entity.User{
-		ID:    id,
-		Name:  name,
-		Email: email,
+		ID:            id,
+		Name:          ssoUser.Name,
+		Email:         ssoUser.Email,
+		EmailVerified: ssoUser.Email != "" && ssoUser.EmailVerified,
+	}
+	err := a.userRepo.CreateUser(user)
+	if err != nil || user.Email == "" || user.EmailVerified {
+		return err
 	}
-	return a.userRepo.CreateUser(user)
+	_ = a.emailVerifier.SendVerification(user)
+	return nil
 }
 
 // AccountLinkerFactory creates AccountLinker.
 type AccountLinkerFactory struct {
-	keyGen   keygen.KeyGenerator
-	userRepo repository.User
+	keyGen        keygen.KeyGenerator
+	userRepo      repository.User
+	emailVerifier verification.EmailVerifier
 }
 
 // NewAccountLinker creates a new account linker.
@@ -93,9 +118,10 @@ func (a AccountLinkerFactory) NewAccountLinker(
 	ssoMap repository.SSOMap,
 ) AccountLinker {
 	return AccountLinker{
-		keyGen:   a.keyGen,
-		userRepo: a.userRepo,
-		ssoMap:   ssoMap,
+		keyGen:        a.keyGen,
+		userRepo:      a.userRepo,
+		ssoMap:        ssoMap,
+		emailVerifier: a.emailVerifier,
 	}
 }
 
@@ -103,9 +129,11 @@ func (a AccountLinkerFactory) NewAccountLinker(
 func NewAccountLinkerFactory(
 	keyGen keygen.KeyGenerator,
 	userRepo repository.User,
+	emailVerifier verification.EmailVerifier,
 ) AccountLinkerFactory {
 	return AccountLinkerFactory{
-		keyGen:   keyGen,
-		userRepo: userRepo,
+		keyGen:        keyGen,
+		userRepo:      userRepo,
+		emailVerifier: emailVerifier,
 	}
 }
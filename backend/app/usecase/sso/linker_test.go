@@ -3,14 +3,31 @@
 package sso
 
 import (
+	"net/url"
 	"testing"
+	"time"
 
 	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/crypto"
+	"github.com/short-d/app/fw/timer"
 	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/email"
 	"github.com/short-d/short/backend/app/usecase/keygen"
 	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/verification"
 )
 
+func newEmailVerifier(userRepo repository.User, emailSender email.Sender) verification.EmailVerifier {
+	return verification.NewEmailVerifier(
+		crypto.NewTokenizerFake(),
+		timer.NewStub(time.Now()),
+		userRepo,
+		emailSender,
+		url.URL{Scheme: "https", Host: "short-d.com"},
+		24*time.Hour,
+	)
+}
+
 func TestLinker_IsAccountLinked(t *testing.T) {
 	t.Parallel()
 
@@ -59,11 +76,11 @@ func TestLinker_IsAccountLinked(t *testing.T) {
 			t.Parallel()
 
 			keyFetcher := keygen.NewKeyFetcherFake([]keygen.Key{})
-			keyGen, err := keygen.NewKeyGenerator(2, &keyFetcher)
+			keyGen, err := keygen.NewRemote(2, &keyFetcher)
 			assert.Equal(t, nil, err)
 
 			userRepo := repository.NewUserFake([]entity.User{})
-			linkerFactory := NewAccountLinkerFactory(keyGen, &userRepo)
+			linkerFactory := NewAccountLinkerFactory(keyGen, &userRepo, newEmailVerifier(&userRepo, email.NewSenderFake(nil)))
 			ssoMap, err := repository.NewsSSOMapFake(testCase.mappingSSOUserIDs, testCase.mappingUserIDs)
 			assert.Equal(t, nil, err)
 
@@ -85,6 +102,7 @@ func TestLinker_CreateAndLinkAccount(t *testing.T) {
 		ssoUser           entity.SSOUser
 		user              entity.User
 		expectedIDExist   bool
+		expectedEmails    int
 	}{
 		{
 			name:              "account exists not linked",
@@ -93,8 +111,9 @@ func TestLinker_CreateAndLinkAccount(t *testing.T) {
 			mappingSSOUserIDs: []string{},
 			users: []entity.User{
 				{
-					ID:    "alpha",
-					Email: "alpha@example.com",
+					ID:            "alpha",
+					Email:         "alpha@example.com",
+					EmailVerified: true,
 				},
 			},
 			ssoUser: entity.SSOUser{
@@ -102,8 +121,9 @@ func TestLinker_CreateAndLinkAccount(t *testing.T) {
 				Email: "alpha@example.com",
 			},
 			user: entity.User{
-				ID:    "alpha",
-				Email: "alpha@example.com",
+				ID:            "alpha",
+				Email:         "alpha@example.com",
+				EmailVerified: true,
 			},
 			expectedIDExist: false,
 		},
@@ -122,6 +142,25 @@ func TestLinker_CreateAndLinkAccount(t *testing.T) {
 				Email: "alpha@example.com",
 			},
 			expectedIDExist: false,
+			expectedEmails:  1,
+		},
+		{
+			name:              "create new account with verified email",
+			key:               "alpha",
+			mappingUserIDs:    []string{},
+			mappingSSOUserIDs: []string{},
+			users:             []entity.User{},
+			ssoUser: entity.SSOUser{
+				ID:            "gama",
+				Email:         "alpha@example.com",
+				EmailVerified: true,
+			},
+			user: entity.User{
+				ID:            "alpha",
+				Email:         "alpha@example.com",
+				EmailVerified: true,
+			},
+			expectedIDExist: false,
 		},
 	}
 
@@ -130,11 +169,12 @@ func TestLinker_CreateAndLinkAccount(t *testing.T) {
 			keyFetcher := keygen.NewKeyFetcherFake([]keygen.Key{
 				keygen.Key(testCase.key),
 			})
-			keyGen, err := keygen.NewKeyGenerator(2, &keyFetcher)
+			keyGen, err := keygen.NewRemote(2, &keyFetcher)
 			assert.Equal(t, nil, err)
 
 			userRepo := repository.NewUserFake(testCase.users)
-			linkerFactory := NewAccountLinkerFactory(keyGen, &userRepo)
+			emailSender := email.NewSenderFake(nil)
+			linkerFactory := NewAccountLinkerFactory(keyGen, &userRepo, newEmailVerifier(&userRepo, emailSender))
 			ssoMap, err := repository.NewsSSOMapFake(testCase.mappingSSOUserIDs, testCase.mappingUserIDs)
 			assert.Equal(t, nil, err)
 
@@ -149,8 +189,40 @@ func TestLinker_CreateAndLinkAccount(t *testing.T) {
 			gotIsRelationExist = ssoMap.IsRelationExist(testCase.ssoUser.ID, testCase.user.ID)
 			assert.Equal(t, true, gotIsRelationExist)
 
-			gotIsIDExist := userRepo.IsUserIDExist(testCase.user.ID)
-			assert.Equal(t, true, gotIsIDExist)
+			gotUser, err := userRepo.GetUserByID(testCase.user.ID)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.user.EmailVerified, gotUser.EmailVerified)
+			assert.Equal(t, testCase.expectedEmails, len(emailSender.Messages()))
 		})
 	}
 }
+
+func TestLinker_CreateAndLinkAccountUnverifiedEmail(t *testing.T) {
+	t.Parallel()
+
+	keyFetcher := keygen.NewKeyFetcherFake([]keygen.Key{"beta"})
+	keyGen, err := keygen.NewRemote(2, &keyFetcher)
+	assert.Equal(t, nil, err)
+
+	userRepo := repository.NewUserFake([]entity.User{
+		{
+			ID:    "alpha",
+			Email: "alpha@example.com",
+		},
+	})
+	linkerFactory := NewAccountLinkerFactory(keyGen, &userRepo, newEmailVerifier(&userRepo, email.NewSenderFake(nil)))
+	ssoMap, err := repository.NewsSSOMapFake([]string{}, []string{})
+	assert.Equal(t, nil, err)
+
+	linker := linkerFactory.NewAccountLinker(&ssoMap)
+	ssoUser := entity.SSOUser{
+		ID:    "gama",
+		Email: "alpha@example.com",
+	}
+	err = linker.CreateAndLinkAccount(ssoUser)
+	assert.Equal(t, ErrEmailNotVerified("alpha@example.com"), err)
+
+	isLinked, err := linker.IsAccountLinked(ssoUser)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, isLinked)
+}
@@ -0,0 +1,69 @@
+package sso
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+)
+
+const sessionSecretBytes = 32
+
+// ErrInvalidState represents the callback of the identity provider which
+// doesn't belong to the sign in started by the same browser.
+type ErrInvalidState string
+
+func (e ErrInvalidState) Error() string {
+	return "invalid sign in state: " + string(e)
+}
+
+// Session holds the secrets generated for each sign in. They are kept by the
+// browser starting the sign in, so that the callback of the identity provider
+// is only accepted from the same browser.
+type Session struct {
+	// State is sent to the identity provider, which passes it back unchanged
+	// to the callback.
+	State string
+	// CodeVerifier proves to the identity provider that the authorization
+	// code is redeemed by the client which requested it, following PKCE.
+	CodeVerifier string
+	// Nonce is embedded by the identity provider into the identity token
+	// issued for the sign in.
+	Nonce string
+}
+
+// CodeChallenge derives the S256 PKCE code challenge from the code verifier.
+func (s Session) CodeChallenge() string {
+	hashed := sha256.Sum256([]byte(s.CodeVerifier))
+	return base64.RawURLEncoding.EncodeToString(hashed[:])
+}
+
+// verifyState checks that the state passed back to the callback is the one
+// issued for the session.
+func (s Session) verifyState(state string) error {
+	if s.State == "" {
+		return ErrInvalidState("sign in session not found")
+	}
+	if subtle.ConstantTimeCompare([]byte(state), []byte(s.State)) != 1 {
+		return ErrInvalidState("state mismatch")
+	}
+	return nil
+}
+
+// NewSession generates random secrets for a new sign in.
+func NewSession() (Session, error) {
+	var secrets [3]string
+	for idx := range secrets {
+		buf := make([]byte, sessionSecretBytes)
+		_, err := rand.Read(buf)
+		if err != nil {
+			return Session{}, err
+		}
+		secrets[idx] = base64.RawURLEncoding.EncodeToString(buf)
+	}
+	return Session{
+		State:        secrets[0],
+		CodeVerifier: secrets[1],
+		Nonce:        secrets[2],
+	}, nil
+}
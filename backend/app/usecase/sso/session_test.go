@@ -0,0 +1,31 @@
+// +build !integration all
+
+package sso
+
+import (
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+)
+
+func TestSession_CodeChallenge(t *testing.T) {
+	t.Parallel()
+
+	session := Session{CodeVerifier: "short-code-verifier"}
+	assert.Equal(t, "wInHDPDl82GZ5hW8ag8emt_t0Mi9n1mXPKodW8wCTiM", session.CodeChallenge())
+}
+
+func TestNewSession(t *testing.T) {
+	t.Parallel()
+
+	session, err := NewSession()
+	assert.Equal(t, nil, err)
+	other, err := NewSession()
+	assert.Equal(t, nil, err)
+
+	assert.NotEqual(t, "", session.State)
+	assert.NotEqual(t, session.State, session.CodeVerifier)
+	assert.NotEqual(t, session.State, other.State)
+	assert.NotEqual(t, session.CodeVerifier, other.CodeVerifier)
+	assert.NotEqual(t, session.Nonce, other.Nonce)
+}
@@ -16,18 +16,24 @@ type SingleSignOn struct {
 }
 
 // SignIn generates access token for a user using authorization code obtained
-// from external identity provider.
-func (o SingleSignOn) SignIn(authorizationCode string) (string, error) {
+// from external identity provider. The state passed back by the identity
+// provider has to match the one issued for the sign in session.
+func (o SingleSignOn) SignIn(authorizationCode string, state string, session Session) (string, error) {
 	if len(authorizationCode) < 1 {
 		return "", errors.New("authorizationCode can't be empty")
 	}
 
-	accessToken, err := o.identityProvider.RequestAccessToken(authorizationCode)
+	err := session.verifyState(state)
 	if err != nil {
 		return "", err
 	}
 
-	ssoUser, err := o.account.GetSingleSignOnUser(accessToken)
+	accessToken, err := o.identityProvider.RequestAccessToken(authorizationCode, session)
+	if err != nil {
+		return "", err
+	}
+
+	ssoUser, err := o.account.GetSingleSignOnUser(accessToken, session)
 	if err != nil {
 		return "", err
 	}
@@ -56,9 +62,10 @@ func (o SingleSignOn) IsSignedIn(authToken string) bool {
 	return o.authenticator.IsSignedIn(authToken)
 }
 
-// GetSignInLink retrieves the sign in link of the external account provider.
-func (o SingleSignOn) GetSignInLink() string {
-	return o.identityProvider.GetAuthorizationURL()
+// GetSignInLink retrieves the sign in link of the external account provider
+// for the sign in session.
+func (o SingleSignOn) GetSignInLink(session Session) string {
+	return o.identityProvider.GetAuthorizationURL(session)
 }
 
 // Factory makes SingleSignOn.
@@ -10,6 +10,7 @@ import (
 	"github.com/short-d/app/fw/assert"
 	"github.com/short-d/short/backend/app/entity"
 	"github.com/short-d/short/backend/app/usecase/authenticator"
+	"github.com/short-d/short/backend/app/usecase/email"
 	"github.com/short-d/short/backend/app/usecase/keygen"
 	"github.com/short-d/short/backend/app/usecase/repository"
 )
@@ -20,6 +21,8 @@ func TestSingleSignOn_SignIn(t *testing.T) {
 	testCases := []struct {
 		name              string
 		authorizationCode string
+		state             string
+		sessionState      string
 		profileSSOUser    entity.SSOUser
 		mappingUserIDs    []string
 		mappingSSOUserIDs []string
@@ -33,6 +36,20 @@ func TestSingleSignOn_SignIn(t *testing.T) {
 			authorizationCode: "",
 			hasErr:            true,
 		},
+		{
+			name:              "state mismatch",
+			authorizationCode: "authorized",
+			state:             "forged",
+			sessionState:      "issued",
+			hasErr:            true,
+		},
+		{
+			name:              "sign in session missing",
+			authorizationCode: "authorized",
+			state:             "issued",
+			sessionState:      "",
+			hasErr:            true,
+		},
 		{
 			name:              "account already linked",
 			authorizationCode: "authorized",
@@ -67,8 +84,9 @@ func TestSingleSignOn_SignIn(t *testing.T) {
 			mappingSSOUserIDs: []string{},
 			users: []entity.User{
 				{
-					ID:    "alpha",
-					Email: "alpha@example.com",
+					ID:            "alpha",
+					Email:         "alpha@example.com",
+					EmailVerified: true,
 				},
 			},
 			expectedUser: entity.User{
@@ -76,6 +94,23 @@ func TestSingleSignOn_SignIn(t *testing.T) {
 			},
 			hasErr: false,
 		},
+		{
+			name:              "account with same unverified email found",
+			authorizationCode: "authorized",
+			profileSSOUser: entity.SSOUser{
+				ID:    "random_sso_id",
+				Email: "alpha@example.com",
+			},
+			mappingUserIDs:    []string{},
+			mappingSSOUserIDs: []string{},
+			users: []entity.User{
+				{
+					ID:    "alpha",
+					Email: "alpha@example.com",
+				},
+			},
+			hasErr: true,
+		},
 		{
 			name:              "account not exist",
 			authorizationCode: "authorized",
@@ -107,11 +142,11 @@ func TestSingleSignOn_SignIn(t *testing.T) {
 			auth := authenticator.NewAuthenticatorFake(now, time.Minute)
 
 			keyFetcher := keygen.NewKeyFetcherFake(testCase.availableKeys)
-			keyGen, err := keygen.NewKeyGenerator(2, &keyFetcher)
+			keyGen, err := keygen.NewRemote(2, &keyFetcher)
 			assert.Equal(t, nil, err)
 
 			userRepo := repository.NewUserFake(testCase.users)
-			linkerFactory := NewAccountLinkerFactory(keyGen, &userRepo)
+			linkerFactory := NewAccountLinkerFactory(keyGen, &userRepo, newEmailVerifier(&userRepo, email.NewSenderFake(nil)))
 
 			ssoMap, err := repository.NewsSSOMapFake(testCase.mappingSSOUserIDs, testCase.mappingUserIDs)
 			assert.Equal(t, nil, err)
@@ -120,7 +155,11 @@ func TestSingleSignOn_SignIn(t *testing.T) {
 			factory := NewFactory(auth)
 
 			singleSignOn := factory.NewSingleSignOn(identityProvider, profileService, linker)
-			gotAuthToken, err := singleSignOn.SignIn(testCase.authorizationCode)
+			state, session := "issued", Session{State: "issued"}
+			if testCase.state != "" {
+				state, session = testCase.state, Session{State: testCase.sessionState}
+			}
+			gotAuthToken, err := singleSignOn.SignIn(testCase.authorizationCode, state, session)
 			if testCase.hasErr {
 				assert.NotEqual(t, nil, err)
 				return
@@ -0,0 +1,126 @@
+package stats
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/authorizer"
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+// lastDay is how far back the recent activities are counted.
+const lastDay = 24 * time.Hour
+
+// ErrUnauthorizedAction represents unauthorized action error
+type ErrUnauthorizedAction struct {
+	user   entity.User
+	action string
+}
+
+var _ error = (*ErrUnauthorizedAction)(nil)
+
+func (e ErrUnauthorizedAction) Error() string {
+	return fmt.Sprintf("user %s is not allowed to %s", e.user.ID, e.action)
+}
+
+// ServiceStats represents the statistics of the whole service. The last day
+// counts cover the 24 hours before the statistics are computed.
+type ServiceStats struct {
+	ShortLinks        int
+	Users             int
+	ShortLinksLastDay int
+	ClicksLastDay     int
+	FlaggedShortLinks int
+}
+
+// Service fetches the statistics of the whole service.
+type Service interface {
+	GetServiceStats(ctx context.Context, user entity.User) (ServiceStats, error)
+}
+
+var _ Service = (*ServicePersist)(nil)
+
+// ServicePersist computes the statistics of the whole service from
+// persistent storage.
+type ServicePersist struct {
+	shortLinkRepo        repository.ShortLink
+	userRepo             repository.User
+	visitRepo            repository.Visit
+	flaggedShortLinkRepo repository.FlaggedShortLink
+	authorizer           authorizer.Authorizer
+	timer                timer.Timer
+}
+
+// GetServiceStats counts the short links, the users and the flagged short
+// links, together with the short links created and the clicks in the last 24
+// hours. Only the users allowed to view the statistics of the service can
+// fetch them.
+func (s ServicePersist) GetServiceStats(ctx context.Context, user entity.User) (ServiceStats, error) {
+	canView, err := s.authorizer.CanViewServiceStats(user)
+	if err != nil {
+		return ServiceStats{}, err
+	}
+	if !canView {
+		return ServiceStats{}, ErrUnauthorizedAction{
+			user:   user,
+			action: "view service stats",
+		}
+	}
+
+	since := s.timer.Now().Add(-lastDay)
+
+	shortLinks, err := s.shortLinkRepo.CountShortLinks(ctx)
+	if err != nil {
+		return ServiceStats{}, err
+	}
+
+	users, err := s.userRepo.CountUsers()
+	if err != nil {
+		return ServiceStats{}, err
+	}
+
+	shortLinksLastDay, err := s.shortLinkRepo.CountShortLinksCreatedSince(ctx, since)
+	if err != nil {
+		return ServiceStats{}, err
+	}
+
+	clicksLastDay, err := s.visitRepo.CountVisitsSince(since)
+	if err != nil {
+		return ServiceStats{}, err
+	}
+
+	flaggedShortLinks, err := s.flaggedShortLinkRepo.CountFlaggedShortLinks(ctx)
+	if err != nil {
+		return ServiceStats{}, err
+	}
+
+	return ServiceStats{
+		ShortLinks:        shortLinks,
+		Users:             users,
+		ShortLinksLastDay: shortLinksLastDay,
+		ClicksLastDay:     clicksLastDay,
+		FlaggedShortLinks: flaggedShortLinks,
+	}, nil
+}
+
+// NewServicePersist creates ServicePersist
+func NewServicePersist(
+	shortLinkRepo repository.ShortLink,
+	userRepo repository.User,
+	visitRepo repository.Visit,
+	flaggedShortLinkRepo repository.FlaggedShortLink,
+	authorizer authorizer.Authorizer,
+	timer timer.Timer,
+) ServicePersist {
+	return ServicePersist{
+		shortLinkRepo:        shortLinkRepo,
+		userRepo:             userRepo,
+		visitRepo:            visitRepo,
+		flaggedShortLinkRepo: flaggedShortLinkRepo,
+		authorizer:           authorizer,
+		timer:                timer,
+	}
+}
@@ -0,0 +1,113 @@
+// +build !integration all
+
+package stats
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/authorizer"
+	"github.com/short-d/short/backend/app/usecase/authorizer/rbac"
+	"github.com/short-d/short/backend/app/usecase/authorizer/rbac/role"
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+func TestServicePersist_GetServiceStats(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 6, 2, 12, 0, 0, 0, time.UTC)
+	twoDaysAgo := now.AddDate(0, 0, -2)
+	dayAgo := now.Add(-24 * time.Hour)
+	hourAgo := now.Add(-time.Hour)
+
+	users := []entity.User{
+		{ID: "alpha", Email: "alpha@example.com"},
+		{ID: "beta", Email: "beta@example.com"},
+		{ID: "gamma", Email: "gamma@example.com"},
+	}
+	shortLinks := map[string]entity.ShortLink{
+		"old":     {Alias: "old", LongLink: "https://www.google.com", CreatedAt: &twoDaysAgo},
+		"dayAgo":  {Alias: "dayAgo", LongLink: "https://github.com", CreatedAt: &dayAgo},
+		"hourAgo": {Alias: "hourAgo", LongLink: "https://short-d.com", CreatedAt: &hourAgo},
+		"legacy":  {Alias: "legacy", LongLink: "https://www.bing.com"},
+	}
+	visits := []entity.Visit{
+		{Alias: "old", VisitedAt: twoDaysAgo},
+		{Alias: "old", VisitedAt: dayAgo.Add(-time.Second)},
+		{Alias: "old", VisitedAt: dayAgo},
+		{Alias: "dayAgo", VisitedAt: hourAgo},
+		{Alias: "hourAgo", VisitedAt: now},
+	}
+	flaggedShortLinks := []entity.FlaggedShortLink{
+		{Alias: "hourAgo", RiskScore: 80, FlaggedAt: hourAgo},
+	}
+
+	testCases := []struct {
+		name          string
+		roles         []role.Role
+		expectedStats ServiceStats
+		expHasErr     bool
+	}{
+		{
+			name:  "admin views service stats",
+			roles: []role.Role{role.Admin},
+			expectedStats: ServiceStats{
+				ShortLinks:        4,
+				Users:             3,
+				ShortLinksLastDay: 2,
+				ClicksLastDay:     3,
+				FlaggedShortLinks: 1,
+			},
+		},
+		{
+			name:      "security specialist not allowed to view service stats",
+			roles:     []role.Role{role.SecuritySpecialist},
+			expHasErr: true,
+		},
+		{
+			name:      "basic user not allowed to view service stats",
+			roles:     []role.Role{role.Basic},
+			expHasErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			user := users[0]
+			roleRepo := repository.NewUserRoleFake(map[string][]role.Role{
+				user.ID: testCase.roles,
+			})
+			au := authorizer.NewAuthorizer(rbac.NewRBAC(roleRepo))
+
+			userShortLinkRepo := repository.NewUserShortLinkRepoFake(nil, nil)
+			shortLinkRepo := repository.NewShortLinkFake(&userShortLinkRepo, shortLinks)
+			userRepo := repository.NewUserFake(users)
+			visitRepo := repository.NewVisitFake(visits)
+			flaggedShortLinkRepo := repository.NewFlaggedShortLinkFake(flaggedShortLinks)
+
+			service := NewServicePersist(
+				&shortLinkRepo,
+				&userRepo,
+				&visitRepo,
+				&flaggedShortLinkRepo,
+				au,
+				timer.NewStub(now),
+			)
+
+			stats, err := service.GetServiceStats(context.Background(), user)
+			if testCase.expHasErr {
+				assert.Equal(t, ErrUnauthorizedAction{user, "view service stats"}, err)
+				return
+			}
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedStats, stats)
+		})
+	}
+}
@@ -1,10 +1,12 @@
 package validator
 
 import (
-	"regexp"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/route"
 )
 
 const (
@@ -15,9 +17,27 @@ var forbiddenCharacters = map[rune]entity.Empty{
 	'#': {},
 }
 
+// scriptGroups merges the scripts commonly written together into the same
+// group so that they are not reported as mixed scripts.
+var scriptGroups = map[string]string{
+	"Hiragana": "Han",
+	"Katakana": "Han",
+	"Bopomofo": "Han",
+	"Hangul":   "Han",
+}
+
+// ErrUnknownUnicodeCategory represents the Unicode category which does not
+// exist.
+type ErrUnknownUnicodeCategory string
+
+func (e ErrUnknownUnicodeCategory) Error() string {
+	return "unknown unicode category: " + string(e)
+}
+
 // CustomAlias represents format validator for custom alias
 type CustomAlias struct {
-	uriPattern *regexp.Regexp
+	unicodeCategories []*unicode.RangeTable
+	autoAliasPrefix   string
 }
 
 // IsValid checks whether the given alias has valid format.
@@ -34,6 +54,15 @@ func (c CustomAlias) IsValid(alias string) (bool, Violation) {
 		return false, HasFragmentCharacter
 	}
 
+	if route.IsReserved(alias) {
+		return false, ReservedAlias
+	}
+
+	violation := c.checkCharacters(strings.TrimPrefix(alias, c.autoAliasPrefix))
+	if violation != Valid {
+		return false, violation
+	}
+
 	return true, Valid
 }
 
@@ -48,7 +77,121 @@ func (c CustomAlias) hasForbiddenCharacter(alias string) bool {
 	return false
 }
 
-// NewCustomAlias creates custom alias validator.
+// checkCharacters rejects the characters which are invisible or outside of the
+// allowed Unicode categories, as well as letters from different scripts, so
+// that an alias can't be spoofed by another one looking the same. All the
+// visible characters are allowed when no category is configured.
+func (c CustomAlias) checkCharacters(alias string) Violation {
+	scripts := make(map[string]entity.Empty)
+	prev := rune(0)
+	for _, ch := range alias {
+		switch {
+		case isInvisible(ch):
+			return InvisibleCharacter
+		case ch < utf8.RuneSelf:
+		case isEmojiVariation(prev, ch):
+		case len(c.unicodeCategories) == 0:
+		case !unicode.IsOneOf(c.unicodeCategories, ch):
+			return DisallowedCharacter
+		}
+
+		script, ok := letterScript(ch)
+		if ok {
+			scripts[script] = entity.Empty{}
+		}
+		prev = ch
+	}
+
+	if len(scripts) > 1 {
+		return MixedScripts
+	}
+	return Valid
+}
+
+// isInvisible returns whether the character is not rendered on its own, such
+// as control characters, zero width characters and non ASCII spaces.
+func isInvisible(ch rune) bool {
+	if unicode.IsControl(ch) || unicode.Is(unicode.Cf, ch) {
+		return true
+	}
+	return ch >= utf8.RuneSelf && unicode.IsSpace(ch)
+}
+
+// isEmojiVariation returns whether the character is the variation selector
+// requesting text or emoji presentation of the preceding symbol.
+func isEmojiVariation(prev rune, ch rune) bool {
+	if ch != '\uFE0E' && ch != '\uFE0F' {
+		return false
+	}
+	return unicode.Is(unicode.So, prev)
+}
+
+// letterScript returns the script group of the given letter. Letters shared
+// by many scripts don't belong to any group.
+func letterScript(ch rune) (string, bool) {
+	if !unicode.IsLetter(ch) {
+		return "", false
+	}
+	for name, table := range unicode.Scripts {
+		if name == "Common" || name == "Inherited" || !unicode.Is(table, ch) {
+			continue
+		}
+		if group, ok := scriptGroups[name]; ok {
+			return group, true
+		}
+		return name, true
+	}
+	return "", false
+}
+
+// IsValidPrefix checks whether aliases can start with the given prefix, such
+// as the prefix of auto generated aliases, leaving room for at least one more
+// character within the maximum length of aliases.
+func (c CustomAlias) IsValidPrefix(prefix string) (bool, Violation) {
+	if len(prefix) >= customAliasMaxLength-1 {
+		return false, AliasTooLong
+	}
+
+	if c.hasForbiddenCharacter(prefix) {
+		return false, HasFragmentCharacter
+	}
+
+	violation := c.checkCharacters(prefix)
+	if violation != Valid {
+		return false, violation
+	}
+
+	return true, Valid
+}
+
+// WithAutoAliasPrefix creates a copy of the validator which checks the
+// characters of the aliases starting with the prefix of auto generated aliases
+// without the prefix, so that the prefix doesn't mix scripts with the
+// generated keys.
+func (c CustomAlias) WithAutoAliasPrefix(prefix string) CustomAlias {
+	c.autoAliasPrefix = prefix
+	return c
+}
+
+// NewCustomAlias creates custom alias validator which accepts all the visible
+// characters, including non ASCII ones, as before Unicode categories could be
+// configured. Invisible characters and letters mixing scripts are rejected.
 func NewCustomAlias() CustomAlias {
 	return CustomAlias{}
 }
+
+// NewUnicodeCustomAlias creates custom alias validator which only accepts non
+// ASCII characters in the given Unicode categories, such as "L" for letters
+// and "So" for emoji. All the visible characters are accepted when no
+// category is given.
+func NewUnicodeCustomAlias(categories []string) (CustomAlias, error) {
+	var tables []*unicode.RangeTable
+	for _, category := range categories {
+		table, ok := unicode.Categories[category]
+		if !ok {
+			return CustomAlias{}, ErrUnknownUnicodeCategory(category)
+		}
+		tables = append(tables, table)
+	}
+	return CustomAlias{unicodeCategories: tables}, nil
+}
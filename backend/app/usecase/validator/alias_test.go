@@ -31,11 +31,26 @@ func TestCustomAlias_IsValid(t *testing.T) {
 			alias:      "fb",
 			expIsValid: true,
 		},
+		{
+			name:       "non ASCII alias",
+			alias:      "café",
+			expIsValid: true,
+		},
 		{
 			name:       "alias has forbidden character",
 			alias:      "#fb",
 			expIsValid: false,
 		},
+		{
+			name:       "alias reserved by route",
+			alias:      "api",
+			expIsValid: false,
+		},
+		{
+			name:       "alias under reserved route",
+			alias:      "OAuth/github",
+			expIsValid: false,
+		},
 	}
 
 	validator := NewCustomAlias()
@@ -49,6 +64,64 @@ func TestCustomAlias_IsValid(t *testing.T) {
 	}
 }
 
+func TestCustomAlias_IsValidPrefix(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name         string
+		prefix       string
+		expIsValid   bool
+		expViolation Violation
+	}{
+		{
+			name:         "empty prefix",
+			prefix:       "",
+			expIsValid:   true,
+			expViolation: Valid,
+		},
+		{
+			name:         "prefix valid",
+			prefix:       "eu-",
+			expIsValid:   true,
+			expViolation: Valid,
+		},
+		{
+			name:         "prefix reserved by route",
+			prefix:       "api",
+			expIsValid:   true,
+			expViolation: Valid,
+		},
+		{
+			name:         "prefix leaves no room for keys",
+			prefix:       strings.Repeat("a", 49),
+			expIsValid:   false,
+			expViolation: AliasTooLong,
+		},
+		{
+			name:         "prefix has forbidden character",
+			prefix:       "eu#",
+			expIsValid:   false,
+			expViolation: HasFragmentCharacter,
+		},
+		{
+			name:         "prefix has invisible character",
+			prefix:       "eu\u200b",
+			expIsValid:   false,
+			expViolation: InvisibleCharacter,
+		},
+	}
+
+	validator := NewCustomAlias()
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			valid, violation := validator.IsValidPrefix(testCase.prefix)
+			assert.Equal(t, testCase.expIsValid, valid)
+			assert.Equal(t, testCase.expViolation, violation)
+		})
+	}
+}
+
 func TestCustomAlias_hasFragmentCharacter(t *testing.T) {
 	t.Parallel()
 	testCases := []struct {
@@ -82,3 +155,145 @@ func TestCustomAlias_hasFragmentCharacter(t *testing.T) {
 		})
 	}
 }
+
+func TestCustomAlias_IsValidUnicode(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name            string
+		categories      []string
+		autoAliasPrefix string
+		alias           string
+		expIsValid      bool
+		expViolation    Violation
+	}{
+		{
+			name:         "emoji alias",
+			categories:   []string{"L", "So"},
+			alias:        "\U0001F680launch",
+			expIsValid:   true,
+			expViolation: Valid,
+		},
+		{
+			name:         "emoji with presentation selector",
+			categories:   []string{"So"},
+			alias:        "\u2708\ufe0ftravel",
+			expIsValid:   true,
+			expViolation: Valid,
+		},
+		{
+			name:         "non Latin letters",
+			categories:   []string{"L"},
+			alias:        "東京タワー",
+			expIsValid:   true,
+			expViolation: Valid,
+		},
+		{
+			name:         "emoji not in allowed categories",
+			categories:   []string{"L"},
+			alias:        "\U0001F680launch",
+			expIsValid:   false,
+			expViolation: DisallowedCharacter,
+		},
+		{
+			name:         "emoji without categories",
+			categories:   nil,
+			alias:        "\U0001F680launch",
+			expIsValid:   true,
+			expViolation: Valid,
+		},
+		{
+			name:         "zero width joiner without categories",
+			categories:   nil,
+			alias:        "pay\u200dpal",
+			expIsValid:   false,
+			expViolation: InvisibleCharacter,
+		},
+		{
+			name:         "zero width joiner between emoji",
+			categories:   []string{"L", "So"},
+			alias:        "\U0001F680\u200d\U0001F680",
+			expIsValid:   false,
+			expViolation: InvisibleCharacter,
+		},
+		{
+			name:         "zero width joiner hidden in ASCII alias",
+			categories:   []string{"L", "So"},
+			alias:        "pay\u200dpal",
+			expIsValid:   false,
+			expViolation: InvisibleCharacter,
+		},
+		{
+			name:         "zero width space",
+			categories:   []string{"L", "So"},
+			alias:        "launch\u200b",
+			expIsValid:   false,
+			expViolation: InvisibleCharacter,
+		},
+		{
+			name:         "control character",
+			categories:   []string{"L", "So"},
+			alias:        "launch\u0007",
+			expIsValid:   false,
+			expViolation: InvisibleCharacter,
+		},
+		{
+			name:         "Cyrillic letter in Latin alias",
+			categories:   []string{"L"},
+			alias:        "p\u0430ypal",
+			expIsValid:   false,
+			expViolation: MixedScripts,
+		},
+		{
+			name:         "dangling presentation selector",
+			categories:   []string{"L"},
+			alias:        "launch\ufe0f",
+			expIsValid:   false,
+			expViolation: DisallowedCharacter,
+		},
+		{
+			name:            "non Latin letters after auto alias prefix",
+			categories:      []string{"L"},
+			autoAliasPrefix: "eu-",
+			alias:           "eu-東京タワー",
+			expIsValid:      true,
+			expViolation:    Valid,
+		},
+		{
+			name:            "non Latin letters after other prefix",
+			categories:      []string{"L"},
+			autoAliasPrefix: "eu-",
+			alias:           "us-東京タワー",
+			expIsValid:      false,
+			expViolation:    MixedScripts,
+		},
+		{
+			name:            "Cyrillic letter after auto alias prefix",
+			categories:      []string{"L"},
+			autoAliasPrefix: "eu-",
+			alias:           "eu-p\u0430ypal",
+			expIsValid:      false,
+			expViolation:    MixedScripts,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			validator, err := NewUnicodeCustomAlias(testCase.categories)
+			assert.Equal(t, nil, err)
+			validator = validator.WithAutoAliasPrefix(testCase.autoAliasPrefix)
+
+			valid, violation := validator.IsValid(testCase.alias)
+			assert.Equal(t, testCase.expIsValid, valid)
+			assert.Equal(t, testCase.expViolation, violation)
+		})
+	}
+}
+
+func TestNewUnicodeCustomAlias(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewUnicodeCustomAlias([]string{"L", "Emoji"})
+	assert.Equal(t, ErrUnknownUnicodeCategory("Emoji"), err)
+}
@@ -0,0 +1,61 @@
+package validator
+
+import (
+	"net/url"
+	"strings"
+)
+
+const wildcardPrefix = "*."
+
+// DomainList matches the hosts of URLs against a list of domains, ignoring
+// case and port. A domain starting with "*." matches all of its subdomains.
+type DomainList struct {
+	domains []string
+}
+
+// Len returns the number of domains in the list.
+func (d DomainList) Len() int {
+	return len(d.domains)
+}
+
+// HasURL checks whether the host of the given URL matches any domain in the
+// list. Malformed URLs never match.
+func (d DomainList) HasURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	return d.HasHost(u.Hostname())
+}
+
+// HasHost checks whether the given host matches any domain in the list.
+func (d DomainList) HasHost(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	for _, domain := range d.domains {
+		if !strings.HasPrefix(domain, wildcardPrefix) {
+			if host == domain {
+				return true
+			}
+			continue
+		}
+
+		suffix := domain[len(wildcardPrefix)-1:]
+		if strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewDomainList creates DomainList, skipping blank domains.
+func NewDomainList(domains []string) DomainList {
+	var normalized []string
+	for _, domain := range domains {
+		domain = strings.ToLower(strings.TrimSpace(domain))
+		if domain == "" {
+			continue
+		}
+		normalized = append(normalized, domain)
+	}
+	return DomainList{domains: normalized}
+}
@@ -0,0 +1,54 @@
+package validator
+
+import "strings"
+
+const fragmentSeparator = "#"
+
+// Fragment represents how the fragments of long links, such as #section, are
+// handled before short links are saved.
+type Fragment string
+
+// The constants enumerate all supported fragment handling modes.
+const (
+	// FragmentPreserve keeps the fragments, so that redirects land on the same
+	// section of the page or the same route of single page applications.
+	FragmentPreserve Fragment = "preserve"
+	// FragmentStrip removes the fragments, including the separator.
+	FragmentStrip Fragment = "strip"
+)
+
+// ErrUnknownFragment represents the fragment handling mode which is not
+// supported.
+type ErrUnknownFragment string
+
+func (e ErrUnknownFragment) Error() string {
+	return "unknown fragment handling: " + string(e)
+}
+
+// ParseFragment converts the name of the mode into Fragment. Fragments are
+// preserved when the name is empty.
+func ParseFragment(name string) (Fragment, error) {
+	fragment := Fragment(name)
+	switch fragment {
+	case "":
+		return FragmentPreserve, nil
+	case FragmentPreserve, FragmentStrip:
+		return fragment, nil
+	default:
+		return "", ErrUnknownFragment(name)
+	}
+}
+
+// apply removes the fragment of the long link when fragments are stripped.
+// The first "#" always starts the fragment since it can't appear unescaped in
+// any other part of URLs.
+func (f Fragment) apply(longLink string) string {
+	if f != FragmentStrip {
+		return longLink
+	}
+	idx := strings.Index(longLink, fragmentSeparator)
+	if idx < 0 {
+		return longLink
+	}
+	return longLink[:idx]
+}
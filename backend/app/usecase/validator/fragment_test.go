@@ -0,0 +1,108 @@
+// +build !integration all
+
+package validator
+
+import (
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+)
+
+func TestParseFragment(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name             string
+		fragmentName     string
+		hasErr           bool
+		expectedFragment Fragment
+	}{
+		{
+			name:             "default to preserve",
+			fragmentName:     "",
+			expectedFragment: FragmentPreserve,
+		},
+		{
+			name:             "preserve",
+			fragmentName:     "preserve",
+			expectedFragment: FragmentPreserve,
+		},
+		{
+			name:             "strip",
+			fragmentName:     "strip",
+			expectedFragment: FragmentStrip,
+		},
+		{
+			name:         "unknown mode",
+			fragmentName: "drop",
+			hasErr:       true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			fragment, err := ParseFragment(testCase.fragmentName)
+			if testCase.hasErr {
+				assert.Equal(t, ErrUnknownFragment(testCase.fragmentName), err)
+				return
+			}
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedFragment, fragment)
+		})
+	}
+}
+
+func TestLongLink_Normalize(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name             string
+		fragment         Fragment
+		longLink         string
+		expectedLongLink string
+	}{
+		{
+			name:             "preserve fragment",
+			fragment:         FragmentPreserve,
+			longLink:         "https://example.com/docs#install",
+			expectedLongLink: "https://example.com/docs#install",
+		},
+		{
+			name:             "preserve single page app route",
+			fragment:         FragmentPreserve,
+			longLink:         "https://example.com/app?tab=1#/settings?panel=2",
+			expectedLongLink: "https://example.com/app?tab=1#/settings?panel=2",
+		},
+		{
+			name:             "strip fragment",
+			fragment:         FragmentStrip,
+			longLink:         "https://example.com/app?tab=1#/settings?panel=2",
+			expectedLongLink: "https://example.com/app?tab=1",
+		},
+		{
+			name:             "strip empty fragment",
+			fragment:         FragmentStrip,
+			longLink:         "https://example.com/docs#",
+			expectedLongLink: "https://example.com/docs",
+		},
+		{
+			name:             "strip keeps escaped hash",
+			fragment:         FragmentStrip,
+			longLink:         "https://example.com/search?q=%23go",
+			expectedLongLink: "https://example.com/search?q=%23go",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			validator := NewLongLink(nil, nil, testCase.fragment, PlainHTTPAllow)
+			assert.Equal(t, testCase.expectedLongLink, validator.Normalize(testCase.longLink))
+		})
+	}
+}
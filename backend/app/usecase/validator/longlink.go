@@ -1,15 +1,30 @@
 package validator
 
-import "regexp"
+import (
+	"net/url"
+	"regexp"
+)
 
 const longLinkMaxLength = 200
 
 // LongLink represents format validator for original long link
 type LongLink struct {
-	uriPattern *regexp.Regexp
+	uriPattern     *regexp.Regexp
+	allowedDomains DomainList
+	shortDomains   DomainList
+	fragment       Fragment
+	plainHTTP      PlainHTTP
 }
 
-// IsValid checks whether the given long link has valid format.
+// Normalize rewrites the long link into the form saved with short links
+// according to the fragment and plain HTTP handling modes.
+func (l LongLink) Normalize(longLink string) string {
+	return l.plainHTTP.apply(l.fragment.apply(longLink))
+}
+
+// IsValid checks whether the given long link has valid format. Fragments are
+// allowed regardless of how they are handled, while plain HTTP long links are
+// only rejected when plain HTTP is rejected.
 func (l LongLink) IsValid(longLink string) (bool, Violation) {
 	if longLink == "" {
 		return false, EmptyLongLink
@@ -23,13 +38,52 @@ func (l LongLink) IsValid(longLink string) (bool, Violation) {
 		return false, LongLinkNotURL
 	}
 
+	if !l.plainHTTP.isAllowed(longLink) {
+		return false, InsecureLongLink
+	}
+
+	if l.allowedDomains.Len() == 0 && l.shortDomains.Len() == 0 {
+		return true, Valid
+	}
+
+	u, err := url.Parse(longLink)
+	if err != nil {
+		return false, LongLinkNotURL
+	}
+
+	if l.shortDomains.HasHost(u.Hostname()) {
+		return false, SelfReferencing
+	}
+
+	if l.allowedDomains.Len() > 0 && !l.allowedDomains.HasHost(u.Hostname()) {
+		return false, DomainNotAllowed
+	}
 	return true, Valid
 }
 
-// NewLongLink creates long link validator.
-func NewLongLink() LongLink {
+// IsSelfReferencing checks whether the long link is on one of the domains
+// serving short links, which means it is a short link itself.
+func (l LongLink) IsSelfReferencing(longLink string) bool {
+	return l.shortDomains.HasURL(longLink)
+}
+
+// NewLongLink creates long link validator. Only the long links on the allowed
+// domains are valid unless allowedDomains is empty. The long links on the
+// domains serving short links are never valid because they redirect back to
+// Short. The fragments and the plain HTTP schemes of the long links are
+// handled according to fragment and plainHTTP respectively.
+func NewLongLink(
+	allowedDomains []string,
+	shortDomains []string,
+	fragment Fragment,
+	plainHTTP PlainHTTP,
+) LongLink {
 	uriPattern := regexp.MustCompile(`^[a-zA-Z]+://.+$`)
 	return LongLink{
-		uriPattern: uriPattern,
+		uriPattern:     uriPattern,
+		allowedDomains: NewDomainList(allowedDomains),
+		shortDomains:   NewDomainList(shortDomains),
+		fragment:       fragment,
+		plainHTTP:      plainHTTP,
 	}
 }
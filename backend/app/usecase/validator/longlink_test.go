@@ -46,9 +46,14 @@ func TestLongLink_IsValid(t *testing.T) {
 			longLink:   "https://google.com",
 			expIsValid: true,
 		},
+		{
+			name:       "link with fragment",
+			longLink:   "https://google.com/search#results",
+			expIsValid: true,
+		},
 	}
 
-	validator := NewLongLink()
+	validator := NewLongLink(nil, nil, FragmentPreserve, PlainHTTPAllow)
 	for _, testCase := range testCases {
 		testCase := testCase
 		t.Run(testCase.name, func(t *testing.T) {
@@ -58,3 +63,157 @@ func TestLongLink_IsValid(t *testing.T) {
 		})
 	}
 }
+
+func TestLongLink_IsValidAllowedDomains(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name           string
+		allowedDomains []string
+		longLink       string
+		expIsValid     bool
+		expViolation   Violation
+	}{
+		{
+			name:           "empty allowlist allows all",
+			allowedDomains: []string{},
+			longLink:       "https://google.com",
+			expIsValid:     true,
+			expViolation:   Valid,
+		},
+		{
+			name:           "exact host match",
+			allowedDomains: []string{"acme.com"},
+			longLink:       "https://acme.com/wiki",
+			expIsValid:     true,
+			expViolation:   Valid,
+		},
+		{
+			name:           "exact host match ignores case and port",
+			allowedDomains: []string{"Acme.com"},
+			longLink:       "https://ACME.com:8080/wiki",
+			expIsValid:     true,
+			expViolation:   Valid,
+		},
+		{
+			name:           "exact host does not match subdomain",
+			allowedDomains: []string{"acme.com"},
+			longLink:       "https://wiki.acme.com",
+			expIsValid:     false,
+			expViolation:   DomainNotAllowed,
+		},
+		{
+			name:           "wildcard subdomain match",
+			allowedDomains: []string{"*.acme.com"},
+			longLink:       "https://docs.wiki.acme.com:443/home",
+			expIsValid:     true,
+			expViolation:   Valid,
+		},
+		{
+			name:           "wildcard does not match look-alike host",
+			allowedDomains: []string{"*.acme.com"},
+			longLink:       "https://evilacme.com",
+			expIsValid:     false,
+			expViolation:   DomainNotAllowed,
+		},
+		{
+			name:           "non-matching host rejected",
+			allowedDomains: []string{"acme.com", "*.acme.com"},
+			longLink:       "https://google.com",
+			expIsValid:     false,
+			expViolation:   DomainNotAllowed,
+		},
+		{
+			name:           "userinfo does not bypass allowlist",
+			allowedDomains: []string{"acme.com"},
+			longLink:       "https://acme.com@google.com",
+			expIsValid:     false,
+			expViolation:   DomainNotAllowed,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			validator := NewLongLink(testCase.allowedDomains, nil, FragmentPreserve, PlainHTTPAllow)
+			valid, violation := validator.IsValid(testCase.longLink)
+			assert.Equal(t, testCase.expIsValid, valid)
+			assert.Equal(t, testCase.expViolation, violation)
+		})
+	}
+}
+
+func TestLongLink_IsValidShortDomains(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name           string
+		allowedDomains []string
+		shortDomains   []string
+		longLink       string
+		expIsValid     bool
+		expViolation   Violation
+	}{
+		{
+			name:         "external long link",
+			shortDomains: []string{"short-d.com"},
+			longLink:     "https://google.com/r/google",
+			expIsValid:   true,
+			expViolation: Valid,
+		},
+		{
+			name:         "long link on short domain",
+			shortDomains: []string{"short-d.com"},
+			longLink:     "https://short-d.com/r/google",
+			expIsValid:   false,
+			expViolation: SelfReferencing,
+		},
+		{
+			name:         "long link on short domain ignores case, port and trailing dot",
+			shortDomains: []string{"short-d.com"},
+			longLink:     "http://SHORT-D.com.:8080/r/google",
+			expIsValid:   false,
+			expViolation: SelfReferencing,
+		},
+		{
+			name:         "long link on custom domain",
+			shortDomains: []string{"short-d.com", "go.acme.com"},
+			longLink:     "https://go.acme.com/r/wiki",
+			expIsValid:   false,
+			expViolation: SelfReferencing,
+		},
+		{
+			name:         "long link on wildcard short domain",
+			shortDomains: []string{"*.short-d.com"},
+			longLink:     "https://api.short-d.com/r/google",
+			expIsValid:   false,
+			expViolation: SelfReferencing,
+		},
+		{
+			name:           "short domain rejected even if allowed",
+			allowedDomains: []string{"short-d.com"},
+			shortDomains:   []string{"short-d.com"},
+			longLink:       "https://short-d.com/r/google",
+			expIsValid:     false,
+			expViolation:   SelfReferencing,
+		},
+		{
+			name:           "allowlist still applies",
+			allowedDomains: []string{"acme.com"},
+			shortDomains:   []string{"short-d.com"},
+			longLink:       "https://google.com",
+			expIsValid:     false,
+			expViolation:   DomainNotAllowed,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			validator := NewLongLink(testCase.allowedDomains, testCase.shortDomains, FragmentPreserve, PlainHTTPAllow)
+			valid, violation := validator.IsValid(testCase.longLink)
+			assert.Equal(t, testCase.expIsValid, valid)
+			assert.Equal(t, testCase.expViolation, violation)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package validator
+
+import "strings"
+
+const (
+	httpScheme  = "http://"
+	httpsScheme = "https://"
+)
+
+// PlainHTTP represents how long links using plain HTTP, which can be
+// intercepted and modified in transit, are handled before short links are
+// saved.
+type PlainHTTP string
+
+// The constants enumerate all supported plain HTTP handling modes.
+const (
+	// PlainHTTPAllow accepts long links using plain HTTP as is.
+	PlainHTTPAllow PlainHTTP = "allow"
+	// PlainHTTPReject refuses long links using plain HTTP, so that only HTTPS
+	// long links can be shortened.
+	PlainHTTPReject PlainHTTP = "reject"
+	// PlainHTTPUpgrade rewrites long links using plain HTTP to HTTPS.
+	PlainHTTPUpgrade PlainHTTP = "upgrade"
+)
+
+// ErrUnknownPlainHTTP represents the plain HTTP handling mode which is not
+// supported.
+type ErrUnknownPlainHTTP string
+
+func (e ErrUnknownPlainHTTP) Error() string {
+	return "unknown plain HTTP handling: " + string(e)
+}
+
+// ParsePlainHTTP converts the name of the mode into PlainHTTP. Plain HTTP is
+// allowed when the name is empty.
+func ParsePlainHTTP(name string) (PlainHTTP, error) {
+	plainHTTP := PlainHTTP(name)
+	switch plainHTTP {
+	case "":
+		return PlainHTTPAllow, nil
+	case PlainHTTPAllow, PlainHTTPReject, PlainHTTPUpgrade:
+		return plainHTTP, nil
+	default:
+		return "", ErrUnknownPlainHTTP(name)
+	}
+}
+
+// apply rewrites the scheme of the long link to HTTPS when plain HTTP is
+// upgraded.
+func (p PlainHTTP) apply(longLink string) string {
+	if p != PlainHTTPUpgrade || !isPlainHTTP(longLink) {
+		return longLink
+	}
+	return httpsScheme + longLink[len(httpScheme):]
+}
+
+// isAllowed checks whether the long link can be saved in the mode. Plain HTTP
+// long links are allowed when they are upgraded because Normalize rewrites
+// them before they are saved.
+func (p PlainHTTP) isAllowed(longLink string) bool {
+	return p != PlainHTTPReject || !isPlainHTTP(longLink)
+}
+
+// isPlainHTTP checks whether the long link uses plain HTTP. Schemes are case
+// insensitive.
+func isPlainHTTP(longLink string) bool {
+	return len(longLink) >= len(httpScheme) &&
+		strings.EqualFold(longLink[:len(httpScheme)], httpScheme)
+}
@@ -0,0 +1,144 @@
+// +build !integration all
+
+package validator
+
+import (
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+)
+
+func TestParsePlainHTTP(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name              string
+		plainHTTPName     string
+		hasErr            bool
+		expectedPlainHTTP PlainHTTP
+	}{
+		{
+			name:              "default to allow",
+			plainHTTPName:     "",
+			expectedPlainHTTP: PlainHTTPAllow,
+		},
+		{
+			name:              "allow",
+			plainHTTPName:     "allow",
+			expectedPlainHTTP: PlainHTTPAllow,
+		},
+		{
+			name:              "reject",
+			plainHTTPName:     "reject",
+			expectedPlainHTTP: PlainHTTPReject,
+		},
+		{
+			name:              "upgrade",
+			plainHTTPName:     "upgrade",
+			expectedPlainHTTP: PlainHTTPUpgrade,
+		},
+		{
+			name:          "unknown mode",
+			plainHTTPName: "redirect",
+			hasErr:        true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			plainHTTP, err := ParsePlainHTTP(testCase.plainHTTPName)
+			if testCase.hasErr {
+				assert.Equal(t, ErrUnknownPlainHTTP(testCase.plainHTTPName), err)
+				return
+			}
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedPlainHTTP, plainHTTP)
+		})
+	}
+}
+
+func TestLongLink_PlainHTTP(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name             string
+		plainHTTP        PlainHTTP
+		longLink         string
+		expIsValid       bool
+		expViolation     Violation
+		expectedLongLink string
+	}{
+		{
+			name:             "allow plain HTTP",
+			plainHTTP:        PlainHTTPAllow,
+			longLink:         "http://example.com/docs",
+			expIsValid:       true,
+			expViolation:     Valid,
+			expectedLongLink: "http://example.com/docs",
+		},
+		{
+			name:             "reject plain HTTP",
+			plainHTTP:        PlainHTTPReject,
+			longLink:         "http://example.com/docs",
+			expIsValid:       false,
+			expViolation:     InsecureLongLink,
+			expectedLongLink: "http://example.com/docs",
+		},
+		{
+			name:             "reject plain HTTP ignores scheme case",
+			plainHTTP:        PlainHTTPReject,
+			longLink:         "HTTP://example.com/docs",
+			expIsValid:       false,
+			expViolation:     InsecureLongLink,
+			expectedLongLink: "HTTP://example.com/docs",
+		},
+		{
+			name:             "reject keeps HTTPS",
+			plainHTTP:        PlainHTTPReject,
+			longLink:         "https://example.com/docs",
+			expIsValid:       true,
+			expViolation:     Valid,
+			expectedLongLink: "https://example.com/docs",
+		},
+		{
+			name:             "reject keeps other schemes",
+			plainHTTP:        PlainHTTPReject,
+			longLink:         "ftp://example.com/docs",
+			expIsValid:       true,
+			expViolation:     Valid,
+			expectedLongLink: "ftp://example.com/docs",
+		},
+		{
+			name:             "upgrade plain HTTP",
+			plainHTTP:        PlainHTTPUpgrade,
+			longLink:         "http://example.com/docs?q=http://a.com",
+			expIsValid:       true,
+			expViolation:     Valid,
+			expectedLongLink: "https://example.com/docs?q=http://a.com",
+		},
+		{
+			name:             "upgrade keeps HTTPS",
+			plainHTTP:        PlainHTTPUpgrade,
+			longLink:         "https://example.com/docs",
+			expIsValid:       true,
+			expViolation:     Valid,
+			expectedLongLink: "https://example.com/docs",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			validator := NewLongLink(nil, nil, FragmentPreserve, testCase.plainHTTP)
+			isValid, violation := validator.IsValid(testCase.longLink)
+			assert.Equal(t, testCase.expIsValid, isValid)
+			assert.Equal(t, testCase.expViolation, violation)
+			assert.Equal(t, testCase.expectedLongLink, validator.Normalize(testCase.longLink))
+		})
+	}
+}
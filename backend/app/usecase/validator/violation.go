@@ -10,4 +10,13 @@ const (
 	AliasTooLong                   = "AliasTooLong"
 	LongLinkTooLong                = "LongLinkTooLong"
 	HasFragmentCharacter           = "HasFragmentCharacter"
+	DomainNotAllowed               = "DomainNotAllowed"
+	SelfReferencing                = "SelfReferencing"
+	ShortenedLongLink              = "ShortenedLongLink"
+	InsecureLongLink               = "InsecureLongLink"
+	InvisibleCharacter             = "InvisibleCharacter"
+	DisallowedCharacter            = "DisallowedCharacter"
+	MixedScripts                   = "MixedScripts"
+	ReservedAlias                  = "ReservedAlias"
+	EmptyAlias                     = "EmptyAlias"
 )
@@ -0,0 +1,160 @@
+package verification
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"time"
+
+	"github.com/short-d/app/fw/crypto"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/email"
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+// VerifyEmailPath is the API path which the verification links point to.
+const VerifyEmailPath = "/api/v1/email/verify"
+
+// ErrInvalidToken represents the verification token is malformed, not signed
+// by Short, or issued for an email the user no longer has.
+type ErrInvalidToken string
+
+func (e ErrInvalidToken) Error() string {
+	return string(e)
+}
+
+// ErrTokenExpired represents the verification token is too old to be used.
+type ErrTokenExpired string
+
+func (e ErrTokenExpired) Error() string {
+	return string(e)
+}
+
+// ErrTokenUsed represents the email is verified already, so the verification
+// token can't be used again.
+type ErrTokenUsed string
+
+func (e ErrTokenUsed) Error() string {
+	return string(e)
+}
+
+// ErrEmptyEmail represents the user has no email to verify.
+type ErrEmptyEmail string
+
+func (e ErrEmptyEmail) Error() string {
+	return string(e)
+}
+
+// EmailVerifier proves users have access to the emails of their accounts by
+// emailing them signed verification links.
+type EmailVerifier struct {
+	tokenizer          crypto.Tokenizer
+	timer              timer.Timer
+	userRepo           repository.User
+	emailSender        email.Sender
+	webFrontendURL     url.URL
+	tokenValidDuration time.Duration
+}
+
+// SendVerification emails the user a link to verify the email of the account.
+func (e EmailVerifier) SendVerification(user entity.User) error {
+	if user.Email == "" {
+		return ErrEmptyEmail(user.ID)
+	}
+	if user.EmailVerified {
+		return ErrTokenUsed(user.Email)
+	}
+
+	token, err := e.IssueToken(user)
+	if err != nil {
+		return err
+	}
+
+	link := e.webFrontendURL
+	link.Path = path.Join("/", link.Path, VerifyEmailPath)
+	link.RawQuery = url.Values{"token": {token}}.Encode()
+
+	body := fmt.Sprintf(
+		"Please verify your email by opening the following link within %v:\n\n%s\n",
+		e.tokenValidDuration, link.String(),
+	)
+	return e.emailSender.Send(user.Email, "Verify your email for Short", body)
+}
+
+// ResendVerification emails the user a new verification link, such as when
+// the previous one expired.
+func (e EmailVerifier) ResendVerification(userID string) error {
+	user, err := e.userRepo.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+	return e.SendVerification(user)
+}
+
+// IssueToken signs a token which verifies the current email of the user until
+// it expires.
+func (e EmailVerifier) IssueToken(user entity.User) (string, error) {
+	token := emailToken{
+		userID:   user.ID,
+		email:    user.Email,
+		issuedAt: e.timer.Now(),
+	}
+	return e.tokenizer.Encode(token.tokenPayload())
+}
+
+// VerifyEmail marks the email of the user as verified. Each token can only be
+// used once because the email stays verified afterwards.
+func (e EmailVerifier) VerifyEmail(tokenStr string) error {
+	tokenPayload, err := e.tokenizer.Decode(tokenStr)
+	if err != nil {
+		return ErrInvalidToken(err.Error())
+	}
+
+	token, err := fromTokenPayload(tokenPayload)
+	if err != nil {
+		return ErrInvalidToken(err.Error())
+	}
+
+	expireAt := token.issuedAt.Add(e.tokenValidDuration)
+	if expireAt.Before(e.timer.Now()) {
+		return ErrTokenExpired(fmt.Sprintf("token expired at %v", expireAt))
+	}
+
+	user, err := e.userRepo.GetUserByID(token.userID)
+	var errNotFound repository.ErrEntryNotFound
+	if errors.As(err, &errNotFound) {
+		return ErrInvalidToken("user not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	if user.Email != token.email {
+		return ErrInvalidToken("email changed")
+	}
+	if user.EmailVerified {
+		return ErrTokenUsed(user.Email)
+	}
+	return e.userRepo.MarkEmailVerified(user.ID)
+}
+
+// NewEmailVerifier creates EmailVerifier
+func NewEmailVerifier(
+	tokenizer crypto.Tokenizer,
+	timer timer.Timer,
+	userRepo repository.User,
+	emailSender email.Sender,
+	webFrontendURL url.URL,
+	tokenValidDuration time.Duration,
+) EmailVerifier {
+	return EmailVerifier{
+		tokenizer:          tokenizer,
+		timer:              timer,
+		userRepo:           userRepo,
+		emailSender:        emailSender,
+		webFrontendURL:     webFrontendURL,
+		tokenValidDuration: tokenValidDuration,
+	}
+}
@@ -0,0 +1,206 @@
+// +build !integration all
+
+package verification
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/crypto"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/email"
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+func TestEmailVerifier_VerifyEmail(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 6, 1, 8, 0, 0, 0, time.UTC)
+	alpha := entity.User{ID: "alpha", Email: "alpha@example.com"}
+
+	testCases := []struct {
+		name            string
+		user            entity.User
+		tokenUser       entity.User
+		issuedAt        time.Time
+		verifyTimes     int
+		expectedErr     error
+		expectedIsValid bool
+	}{
+		{
+			name:            "email verified",
+			user:            alpha,
+			tokenUser:       alpha,
+			issuedAt:        now.Add(-time.Hour),
+			verifyTimes:     1,
+			expectedIsValid: true,
+		},
+		{
+			name:        "token expired",
+			user:        alpha,
+			tokenUser:   alpha,
+			issuedAt:    now.Add(-25 * time.Hour),
+			verifyTimes: 1,
+			expectedErr: ErrTokenExpired("token expired at 2020-06-01 07:00:00 +0000 UTC"),
+		},
+		{
+			name:            "token reused",
+			user:            alpha,
+			tokenUser:       alpha,
+			issuedAt:        now.Add(-time.Hour),
+			verifyTimes:     2,
+			expectedErr:     ErrTokenUsed("alpha@example.com"),
+			expectedIsValid: true,
+		},
+		{
+			name:        "email changed after token issued",
+			user:        entity.User{ID: "alpha", Email: "alpha@short-d.com"},
+			tokenUser:   alpha,
+			issuedAt:    now.Add(-time.Hour),
+			verifyTimes: 1,
+			expectedErr: ErrInvalidToken("email changed"),
+		},
+		{
+			name:        "user not found",
+			user:        entity.User{ID: "beta", Email: "beta@example.com"},
+			tokenUser:   alpha,
+			issuedAt:    now.Add(-time.Hour),
+			verifyTimes: 1,
+			expectedErr: ErrInvalidToken("user not found"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			userRepo := repository.NewUserFake([]entity.User{testCase.user})
+			tm := timer.NewStub(testCase.issuedAt)
+			verifier := NewEmailVerifier(
+				crypto.NewTokenizerFake(),
+				&tm,
+				&userRepo,
+				email.NewSenderFake(nil),
+				url.URL{Scheme: "https", Host: "short-d.com"},
+				24*time.Hour,
+			)
+
+			token, err := verifier.IssueToken(testCase.tokenUser)
+			assert.Equal(t, nil, err)
+
+			tm.CurrentTime = now
+			for i := 0; i < testCase.verifyTimes; i++ {
+				err = verifier.VerifyEmail(token)
+			}
+			assert.Equal(t, testCase.expectedErr, err)
+
+			user, err := userRepo.GetUserByID(testCase.user.ID)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedIsValid, user.EmailVerified)
+		})
+	}
+}
+
+func TestEmailVerifier_VerifyEmailInvalidToken(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name         string
+		tokenPayload crypto.TokenPayload
+	}{
+		{
+			name: "authentication token",
+			tokenPayload: map[string]interface{}{
+				"id":        "alpha",
+				"issued_at": time.Now(),
+			},
+		},
+		{
+			name: "token without email",
+			tokenPayload: map[string]interface{}{
+				"purpose":   "email_verification",
+				"user_id":   "alpha",
+				"issued_at": time.Now(),
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			tokenizer := crypto.NewTokenizerFake()
+			userRepo := repository.NewUserFake([]entity.User{
+				{ID: "alpha", Email: "alpha@example.com"},
+			})
+			verifier := NewEmailVerifier(
+				tokenizer,
+				timer.NewStub(time.Now()),
+				&userRepo,
+				email.NewSenderFake(nil),
+				url.URL{Scheme: "https", Host: "short-d.com"},
+				24*time.Hour,
+			)
+
+			token, err := tokenizer.Encode(testCase.tokenPayload)
+			assert.Equal(t, nil, err)
+
+			err = verifier.VerifyEmail(token)
+			_, ok := err.(ErrInvalidToken)
+			assert.Equal(t, true, ok)
+
+			user, err := userRepo.GetUserByID("alpha")
+			assert.Equal(t, nil, err)
+			assert.Equal(t, false, user.EmailVerified)
+		})
+	}
+}
+
+func TestEmailVerifier_SendVerification(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 6, 1, 8, 0, 0, 0, time.UTC)
+	tokenizer := crypto.NewTokenizerFake()
+	userRepo := repository.NewUserFake(nil)
+	sender := email.NewSenderFake(nil)
+	verifier := NewEmailVerifier(
+		tokenizer,
+		timer.NewStub(now),
+		&userRepo,
+		sender,
+		url.URL{Scheme: "https", Host: "short-d.com"},
+		24*time.Hour,
+	)
+
+	user := entity.User{ID: "alpha", Email: "alpha@example.com"}
+	err := verifier.SendVerification(user)
+	assert.Equal(t, nil, err)
+
+	token, err := verifier.IssueToken(user)
+	assert.Equal(t, nil, err)
+	link := url.URL{
+		Scheme:   "https",
+		Host:     "short-d.com",
+		Path:     "/api/v1/email/verify",
+		RawQuery: url.Values{"token": {token}}.Encode(),
+	}
+	assert.Equal(t, []email.Message{
+		{
+			To:      "alpha@example.com",
+			Subject: "Verify your email for Short",
+			Body:    "Please verify your email by opening the following link within 24h0m0s:\n\n" + link.String() + "\n",
+		},
+	}, sender.Messages())
+
+	err = verifier.SendVerification(entity.User{ID: "beta"})
+	assert.Equal(t, ErrEmptyEmail("beta"), err)
+
+	err = verifier.SendVerification(entity.User{ID: "gamma", Email: "gamma@example.com", EmailVerified: true})
+	assert.Equal(t, ErrTokenUsed("gamma@example.com"), err)
+	assert.Equal(t, 1, len(sender.Messages()))
+}
@@ -0,0 +1,56 @@
+package verification
+
+import (
+	"errors"
+	"time"
+
+	"github.com/short-d/app/fw/crypto"
+)
+
+// purposeEmail prevents tokens issued for other purposes, such as
+// authentication, from being accepted as verification tokens.
+const purposeEmail = "email_verification"
+
+// emailToken represents the metadata encoded in the email verification token.
+type emailToken struct {
+	userID   string
+	email    string
+	issuedAt time.Time
+}
+
+func (e emailToken) tokenPayload() crypto.TokenPayload {
+	return map[string]interface{}{
+		"purpose":   purposeEmail,
+		"user_id":   e.userID,
+		"email":     e.email,
+		"issued_at": e.issuedAt,
+	}
+}
+
+func fromTokenPayload(tokenPayload crypto.TokenPayload) (emailToken, error) {
+	token := emailToken{}
+
+	purpose, ok := tokenPayload["purpose"].(string)
+	if !ok || purpose != purposeEmail {
+		return token, errors.New("expect payload to be issued for email verification")
+	}
+
+	if token.userID, ok = tokenPayload["user_id"].(string); !ok || token.userID == "" {
+		return token, errors.New("expect payload to contain user_id")
+	}
+
+	if token.email, ok = tokenPayload["email"].(string); !ok || token.email == "" {
+		return token, errors.New("expect payload to contain email")
+	}
+
+	issuedAtStr, ok := tokenPayload["issued_at"].(string)
+	if !ok {
+		return token, errors.New("expect payload to contain issued_at")
+	}
+	issuedAt, err := time.Parse(time.RFC3339, issuedAtStr)
+	if err != nil {
+		return token, err
+	}
+	token.issuedAt = issuedAt
+	return token, nil
+}
@@ -0,0 +1,191 @@
+package visit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/short-d/app/fw/geo"
+	"github.com/short-d/app/fw/logger"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/fw/tenant"
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+// Buffer holds the visits of short links in memory and inserts them into the
+// repository in bulk, turning one write per visit into one write per flush.
+// The countries of the visitors are looked up while flushing, so that
+// redirects don't wait for the lookups. The visits are flushed every
+// interval, or as soon as maxVisits visits are buffered. The visits are kept
+// apart for each tenant.
+type Buffer struct {
+	visitRepo  repository.Visit
+	geo        geo.Geo
+	ipMode     IPMode
+	timer      timer.Timer
+	logger     logger.Logger
+	interval   time.Duration
+	maxVisits  int
+	mutex      *sync.Mutex
+	visits     *[]bufferedVisit
+	stopTicker *chan bool
+}
+
+// bufferedVisit keeps the full IP address of the visitor until the country is
+// looked up, after which the IP address is minimized according to the IP mode.
+type bufferedVisit struct {
+	tenantID   string
+	ipAddress  string
+	visit      entity.Visit
+	isResolved bool
+}
+
+// Add buffers the visit of the short link of the tenant attached to the
+// context, flushing the buffer when it is full.
+func (b Buffer) Add(ctx context.Context, visit entity.Visit, ipAddress string) error {
+	b.mutex.Lock()
+	*b.visits = append(*b.visits, bufferedVisit{
+		tenantID:  tenant.FromContext(ctx),
+		ipAddress: ipAddress,
+		visit:     visit,
+	})
+	isFull := len(*b.visits) >= b.maxVisits
+	b.mutex.Unlock()
+
+	if !isFull {
+		return nil
+	}
+	return b.Flush()
+}
+
+// Flush looks up the countries of the buffered visits and writes them to the
+// repository, one write per tenant. The visits are put back into the buffer
+// when the write fails so that they are retried on the next flush instead of
+// being lost.
+func (b Buffer) Flush() error {
+	b.mutex.Lock()
+	buffered := *b.visits
+	*b.visits = nil
+	b.mutex.Unlock()
+
+	var tenantIDs []string
+	tenantVisits := make(map[string][]entity.Visit)
+	for _, visit := range buffered {
+		if _, ok := tenantVisits[visit.tenantID]; !ok {
+			tenantIDs = append(tenantIDs, visit.tenantID)
+		}
+		tenantVisits[visit.tenantID] = append(tenantVisits[visit.tenantID], b.resolve(visit))
+	}
+
+	var lastErr error
+	for _, tenantID := range tenantIDs {
+		visits := tenantVisits[tenantID]
+		ctx := tenant.NewContext(context.Background(), tenantID)
+		err := b.visitRepo.CreateVisits(ctx, visits)
+		if err == nil {
+			continue
+		}
+
+		lastErr = err
+		b.mutex.Lock()
+		for _, visit := range visits {
+			*b.visits = append(*b.visits, bufferedVisit{
+				tenantID:   tenantID,
+				visit:      visit,
+				isResolved: true,
+			})
+		}
+		b.mutex.Unlock()
+	}
+	return lastErr
+}
+
+// resolve looks up the country with the full IP address before the IP
+// address is minimized.
+func (b Buffer) resolve(visit bufferedVisit) entity.Visit {
+	if visit.isResolved {
+		return visit.visit
+	}
+
+	resolved := visit.visit
+	resolved.CountryCode = b.getCountryCode(visit.ipAddress)
+	resolved.IPAddress = minimizeIP(visit.ipAddress, b.ipMode)
+	return resolved
+}
+
+func (b Buffer) getCountryCode(ipAddress string) string {
+	if b.ipMode == IPModeNone || ipAddress == "" {
+		return ""
+	}
+
+	location, err := b.geo.GetLocation(ipAddress)
+	if err != nil {
+		return ""
+	}
+	return location.Country.Code
+}
+
+// Start schedules the periodic flushes. Nothing is scheduled when interval is
+// not positive.
+func (b Buffer) Start() {
+	if b.interval <= 0 {
+		return
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if *b.stopTicker != nil {
+		return
+	}
+	*b.stopTicker = b.timer.Ticker(b.interval, b.flushInBackground)
+}
+
+// Stop cancels the periodic flushes and flushes the remaining visits so that
+// they are not lost on shutdown.
+func (b Buffer) Stop() {
+	b.mutex.Lock()
+	stopTicker := *b.stopTicker
+	*b.stopTicker = nil
+	b.mutex.Unlock()
+
+	if stopTicker != nil {
+		close(stopTicker)
+	}
+	b.flushInBackground()
+}
+
+func (b Buffer) flushInBackground() {
+	err := b.Flush()
+	if err != nil {
+		b.logger.Error(err)
+	}
+}
+
+// NewBuffer creates Buffer which flushes the visits every interval, or as
+// soon as maxVisits visits are buffered. The visits are written right away
+// when maxVisits is not positive. The IP addresses of the visitors are
+// minimized according to ipMode after their countries are looked up with geo.
+func NewBuffer(
+	visitRepo repository.Visit,
+	geo geo.Geo,
+	ipMode IPMode,
+	timer timer.Timer,
+	logger logger.Logger,
+	interval time.Duration,
+	maxVisits int,
+) Buffer {
+	var stopTicker chan bool
+	return Buffer{
+		visitRepo:  visitRepo,
+		geo:        geo,
+		ipMode:     ipMode,
+		timer:      timer,
+		logger:     logger,
+		interval:   interval,
+		maxVisits:  maxVisits,
+		mutex:      &sync.Mutex{},
+		visits:     &[]bufferedVisit{},
+		stopTicker: &stopTicker,
+	}
+}
@@ -0,0 +1,215 @@
+// +build !integration all
+
+package visit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/geo"
+	"github.com/short-d/app/fw/logger"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/fw/tenant"
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+// unstableVisitRepo fails to create visits while isDown is set.
+type unstableVisitRepo struct {
+	repository.Visit
+	isDown *bool
+}
+
+func (u unstableVisitRepo) CreateVisits(ctx context.Context, visits []entity.Visit) error {
+	if *u.isDown {
+		return errors.New("database is down")
+	}
+	return u.Visit.CreateVisits(ctx, visits)
+}
+
+// countingGeo counts the location lookups.
+type countingGeo struct {
+	geo.Geo
+	lookups *int
+}
+
+func (c countingGeo) GetLocation(ipAddress string) (geo.Location, error) {
+	*c.lookups++
+	return c.Geo.GetLocation(ipAddress)
+}
+
+func TestBuffer(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	locations := map[string]geo.Location{
+		"203.0.113.195": {
+			Country: geo.Country{Code: "US", Name: "United States"},
+		},
+	}
+	googleVisit := entity.Visit{Alias: "google", VisitedAt: now}
+	bingVisit := entity.Visit{Alias: "bing", VisitedAt: now}
+
+	testCases := []struct {
+		name            string
+		maxVisits       int
+		visits          []entity.Visit
+		isFlushed       bool
+		expectedLookups int
+		expectedVisits  map[string][]entity.Visit
+	}{
+		{
+			name:            "visits buffered until flushed",
+			maxVisits:       10,
+			visits:          []entity.Visit{googleVisit, bingVisit},
+			expectedLookups: 0,
+			expectedVisits: map[string][]entity.Visit{
+				"google": nil,
+				"bing":   nil,
+			},
+		},
+		{
+			name:            "countries looked up while flushing",
+			maxVisits:       10,
+			visits:          []entity.Visit{googleVisit, bingVisit, googleVisit},
+			isFlushed:       true,
+			expectedLookups: 3,
+			expectedVisits: map[string][]entity.Visit{
+				"google": {
+					{Alias: "google", IPAddress: "203.0.113.0", CountryCode: "US", VisitedAt: now},
+					{Alias: "google", IPAddress: "203.0.113.0", CountryCode: "US", VisitedAt: now},
+				},
+				"bing": {
+					{Alias: "bing", IPAddress: "203.0.113.0", CountryCode: "US", VisitedAt: now},
+				},
+			},
+		},
+		{
+			name:            "flushed when buffer is full",
+			maxVisits:       2,
+			visits:          []entity.Visit{googleVisit, bingVisit, googleVisit},
+			expectedLookups: 2,
+			expectedVisits: map[string][]entity.Visit{
+				"google": {
+					{Alias: "google", IPAddress: "203.0.113.0", CountryCode: "US", VisitedAt: now},
+				},
+				"bing": {
+					{Alias: "bing", IPAddress: "203.0.113.0", CountryCode: "US", VisitedAt: now},
+				},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			visitRepo := repository.NewVisitFake([]entity.Visit{})
+			lookups := 0
+			geo := countingGeo{Geo: NewGeoFake(locations), lookups: &lookups}
+			buffer := newBuffer(t, &visitRepo, geo, IPModeAnonymized, testCase.maxVisits)
+
+			for _, visit := range testCase.visits {
+				err := buffer.Add(context.Background(), visit, "203.0.113.195")
+				assert.Equal(t, nil, err)
+			}
+			if testCase.isFlushed {
+				err := buffer.Flush()
+				assert.Equal(t, nil, err)
+			}
+
+			assert.Equal(t, testCase.expectedLookups, lookups)
+			for alias, expectedVisits := range testCase.expectedVisits {
+				visits, err := visitRepo.FindVisitsByAlias(context.Background(), alias, now, now.Add(time.Second))
+				assert.Equal(t, nil, err)
+				assert.Equal(t, expectedVisits, visits)
+			}
+		})
+	}
+}
+
+func TestBuffer_Tenants(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	visitRepo := repository.NewVisitFake([]entity.Visit{})
+	buffer := newBuffer(t, &visitRepo, NewGeoFake(nil), IPModeNone, 10)
+
+	acme := tenant.NewContext(context.Background(), "acme")
+	globex := tenant.NewContext(context.Background(), "globex")
+	assert.Equal(t, nil, buffer.Add(acme, entity.Visit{Alias: "promo", VisitedAt: now}, ""))
+	assert.Equal(t, nil, buffer.Add(globex, entity.Visit{Alias: "promo", VisitedAt: now.Add(time.Millisecond)}, ""))
+	assert.Equal(t, nil, buffer.Flush())
+
+	visits, err := visitRepo.FindVisitsByAlias(acme, "promo", now, now.Add(time.Second))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []entity.Visit{{Alias: "promo", VisitedAt: now}}, visits)
+
+	visits, err = visitRepo.FindVisitsByAlias(globex, "promo", now, now.Add(time.Second))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []entity.Visit{{Alias: "promo", VisitedAt: now.Add(time.Millisecond)}}, visits)
+}
+
+func TestBuffer_FlushFailed(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	locations := map[string]geo.Location{
+		"203.0.113.195": {
+			Country: geo.Country{Code: "US", Name: "United States"},
+		},
+	}
+	visitRepo := repository.NewVisitFake([]entity.Visit{})
+	lookups := 0
+	geo := countingGeo{Geo: NewGeoFake(locations), lookups: &lookups}
+	isDown := true
+	buffer := newBuffer(t, unstableVisitRepo{
+		Visit:  &visitRepo,
+		isDown: &isDown,
+	}, geo, IPModeFull, 10)
+
+	visit := entity.Visit{Alias: "google", VisitedAt: now}
+	assert.Equal(t, nil, buffer.Add(context.Background(), visit, "203.0.113.195"))
+	assert.NotEqual(t, nil, buffer.Flush())
+
+	isDown = false
+	assert.Equal(t, nil, buffer.Flush())
+	assert.Equal(t, 1, lookups)
+
+	visits, err := visitRepo.FindVisitsByAlias(context.Background(), "google", now, now.Add(time.Second))
+	assert.Equal(t, nil, err)
+	expectedVisits := []entity.Visit{
+		{Alias: "google", IPAddress: "203.0.113.195", CountryCode: "US", VisitedAt: now},
+	}
+	assert.Equal(t, expectedVisits, visits)
+}
+
+func TestBuffer_Stop(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	visitRepo := repository.NewVisitFake([]entity.Visit{})
+	buffer := newBuffer(t, &visitRepo, NewGeoFake(nil), IPModeNone, 10)
+	buffer.Start()
+
+	visit := entity.Visit{Alias: "google", VisitedAt: now}
+	assert.Equal(t, nil, buffer.Add(context.Background(), visit, ""))
+	buffer.Stop()
+
+	visits, err := visitRepo.FindVisitsByAlias(context.Background(), "google", now, now.Add(time.Second))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []entity.Visit{visit}, visits)
+}
+
+func newBuffer(t *testing.T, visitRepo repository.Visit, geo geo.Geo, ipMode IPMode, maxVisits int) Buffer {
+	entryRepo := logger.NewEntryRepoFake()
+	lg, err := logger.NewFake(logger.LogOff, &entryRepo)
+	assert.Equal(t, nil, err)
+
+	tm := timer.NewStub(time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC))
+	return NewBuffer(visitRepo, geo, ipMode, tm, lg, time.Minute, maxVisits)
+}
@@ -0,0 +1,48 @@
+package visit
+
+import (
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/short-d/short/backend/app/entity"
+)
+
+// UnattributedCampaign represents the clicks redirected to a long link without
+// any UTM parameter.
+const UnattributedCampaign = "unattributed"
+
+// maxUTMLength matches the length of the UTM columns in visit table.
+const maxUTMLength = 100
+
+// CampaignStat represents the number of clicks attributed to a combination of
+// UTM source, medium and campaign.
+type CampaignStat struct {
+	Campaign entity.Campaign
+	Clicks   int
+}
+
+// parseCampaign extracts the UTM parameters from the long link the visitor is
+// redirected to. Missing parameters are left empty, and malformed long links
+// are treated as having no UTM parameter.
+func parseCampaign(destination string) entity.Campaign {
+	u, err := url.Parse(destination)
+	if err != nil {
+		return entity.Campaign{}
+	}
+
+	query := u.Query()
+	return entity.Campaign{
+		Source: normalizeUTM(query.Get("utm_source")),
+		Medium: normalizeUTM(query.Get("utm_medium")),
+		Name:   normalizeUTM(query.Get("utm_campaign")),
+	}
+}
+
+func normalizeUTM(value string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if utf8.RuneCountInString(value) <= maxUTMLength {
+		return value
+	}
+	return string([]rune(value)[:maxUTMLength])
+}
@@ -0,0 +1,77 @@
+// +build !integration all
+
+package visit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/short/backend/app/entity"
+)
+
+func TestParseCampaign(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name             string
+		destination      string
+		expectedCampaign entity.Campaign
+	}{
+		{
+			name:             "no destination",
+			destination:      "",
+			expectedCampaign: entity.Campaign{},
+		},
+		{
+			name:             "no UTM parameters",
+			destination:      "https://www.google.com/search?q=short",
+			expectedCampaign: entity.Campaign{},
+		},
+		{
+			name:        "all UTM parameters",
+			destination: "https://example.com/?utm_source=newsletter&utm_medium=email&utm_campaign=spring_sale",
+			expectedCampaign: entity.Campaign{
+				Source: "newsletter",
+				Medium: "email",
+				Name:   "spring_sale",
+			},
+		},
+		{
+			name:        "partial UTM parameters",
+			destination: "https://example.com/?utm_source=twitter&ref=home",
+			expectedCampaign: entity.Campaign{
+				Source: "twitter",
+			},
+		},
+		{
+			name:        "UTM parameters with spaces and uppercase letters",
+			destination: "https://example.com/?utm_source=%20Twitter%20&utm_campaign=Spring+Sale",
+			expectedCampaign: entity.Campaign{
+				Source: "twitter",
+				Name:   "spring sale",
+			},
+		},
+		{
+			name:        "long UTM parameter",
+			destination: "https://example.com/?utm_campaign=" + strings.Repeat("a", 120),
+			expectedCampaign: entity.Campaign{
+				Name: strings.Repeat("a", maxUTMLength),
+			},
+		},
+		{
+			name:             "malformed URL",
+			destination:      "http://[::1%zz/?utm_source=twitter",
+			expectedCampaign: entity.Campaign{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, testCase.expectedCampaign, parseCampaign(testCase.destination))
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package visit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/short-d/app/fw/logger"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/fw/tenant"
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+// CountBuffer aggregates the visit counts of short links in memory and flushes
+// them to the repository in batches, turning one write per visit into one
+// write per flush. The counts are flushed every interval, or as soon as
+// maxAliases aliases are buffered. The counts are kept apart for each tenant.
+// The zero value of CountBuffer discards the counts.
+type CountBuffer struct {
+	shortLinkRepo repository.ShortLink
+	timer         timer.Timer
+	logger        logger.Logger
+	interval      time.Duration
+	maxAliases    int
+	mutex         *sync.Mutex
+	counts        *map[countKey]int
+	stopTicker    *chan bool
+}
+
+type countKey struct {
+	tenantID string
+	alias    string
+}
+
+// Increase buffers one visit of the short link of the tenant attached to the
+// context, flushing the buffer when it is full.
+func (c CountBuffer) Increase(ctx context.Context, alias string) error {
+	if c.mutex == nil {
+		return nil
+	}
+
+	c.mutex.Lock()
+	(*c.counts)[countKey{tenantID: tenant.FromContext(ctx), alias: alias}]++
+	isFull := len(*c.counts) >= c.maxAliases
+	c.mutex.Unlock()
+
+	if !isFull {
+		return nil
+	}
+	return c.Flush()
+}
+
+// Flush writes the buffered counts to the repository, one write per tenant.
+// The counts are put back into the buffer when the write fails so that they
+// are retried on the next flush instead of being lost.
+func (c CountBuffer) Flush() error {
+	if c.mutex == nil {
+		return nil
+	}
+
+	c.mutex.Lock()
+	counts := *c.counts
+	*c.counts = make(map[countKey]int)
+	c.mutex.Unlock()
+
+	tenantCounts := make(map[string]map[string]int)
+	for key, count := range counts {
+		if _, ok := tenantCounts[key.tenantID]; !ok {
+			tenantCounts[key.tenantID] = make(map[string]int)
+		}
+		tenantCounts[key.tenantID][key.alias] = count
+	}
+
+	var lastErr error
+	for tenantID, increments := range tenantCounts {
+		ctx := tenant.NewContext(context.Background(), tenantID)
+		err := c.shortLinkRepo.IncreaseVisitCounts(ctx, increments)
+		if err == nil {
+			continue
+		}
+
+		lastErr = err
+		c.mutex.Lock()
+		for alias, count := range increments {
+			(*c.counts)[countKey{tenantID: tenantID, alias: alias}] += count
+		}
+		c.mutex.Unlock()
+	}
+	return lastErr
+}
+
+// Start schedules the periodic flushes. Nothing is scheduled when interval is
+// not positive.
+func (c CountBuffer) Start() {
+	if c.mutex == nil || c.interval <= 0 {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if *c.stopTicker != nil {
+		return
+	}
+	*c.stopTicker = c.timer.Ticker(c.interval, c.flushInBackground)
+}
+
+// Stop cancels the periodic flushes and flushes the remaining counts so that
+// they are not lost on shutdown.
+func (c CountBuffer) Stop() {
+	if c.mutex == nil {
+		return
+	}
+
+	c.mutex.Lock()
+	stopTicker := *c.stopTicker
+	*c.stopTicker = nil
+	c.mutex.Unlock()
+
+	if stopTicker != nil {
+		close(stopTicker)
+	}
+	c.flushInBackground()
+}
+
+func (c CountBuffer) flushInBackground() {
+	err := c.Flush()
+	if err != nil {
+		c.logger.Error(err)
+	}
+}
+
+// NewCountBuffer creates CountBuffer which flushes the visit counts every
+// interval, or as soon as maxAliases aliases are buffered.
+func NewCountBuffer(
+	shortLinkRepo repository.ShortLink,
+	timer timer.Timer,
+	logger logger.Logger,
+	interval time.Duration,
+	maxAliases int,
+) CountBuffer {
+	counts := make(map[countKey]int)
+	var stopTicker chan bool
+	return CountBuffer{
+		shortLinkRepo: shortLinkRepo,
+		timer:         timer,
+		logger:        logger,
+		interval:      interval,
+		maxAliases:    maxAliases,
+		mutex:         &sync.Mutex{},
+		counts:        &counts,
+		stopTicker:    &stopTicker,
+	}
+}
@@ -0,0 +1,176 @@
+// +build !integration all
+
+package visit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/logger"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+// unstableShortLinkRepo fails to increase visit counts while isDown is set.
+type unstableShortLinkRepo struct {
+	repository.ShortLink
+	isDown *bool
+}
+
+func (u unstableShortLinkRepo) IncreaseVisitCounts(ctx context.Context, increments map[string]int) error {
+	if *u.isDown {
+		return errors.New("database is down")
+	}
+	return u.ShortLink.IncreaseVisitCounts(ctx, increments)
+}
+
+func TestCountBuffer(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name                string
+		maxAliases          int
+		visits              []string
+		isFlushed           bool
+		expectedVisitCounts map[string]int
+	}{
+		{
+			name:       "counts buffered until flushed",
+			maxAliases: 10,
+			visits:     []string{"google", "bing", "google"},
+			expectedVisitCounts: map[string]int{
+				"google": 0,
+				"bing":   0,
+			},
+		},
+		{
+			name:       "counts aggregated per alias",
+			maxAliases: 10,
+			visits:     []string{"google", "bing", "google"},
+			isFlushed:  true,
+			expectedVisitCounts: map[string]int{
+				"google": 2,
+				"bing":   1,
+			},
+		},
+		{
+			name:       "flushed when buffer is full",
+			maxAliases: 2,
+			visits:     []string{"google", "google", "bing", "google"},
+			expectedVisitCounts: map[string]int{
+				"google": 2,
+				"bing":   1,
+			},
+		},
+		{
+			name:       "buffering disabled",
+			maxAliases: 0,
+			visits:     []string{"google", "bing", "google"},
+			expectedVisitCounts: map[string]int{
+				"google": 2,
+				"bing":   1,
+			},
+		},
+		{
+			name:       "missing alias ignored",
+			maxAliases: 10,
+			visits:     []string{"google", "gone"},
+			isFlushed:  true,
+			expectedVisitCounts: map[string]int{
+				"google": 1,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			shortLinkRepo := repository.NewShortLinkFake(nil, map[string]entity.ShortLink{
+				"google": {Alias: "google"},
+				"bing":   {Alias: "bing"},
+			})
+			buffer := newCountBuffer(t, &shortLinkRepo, testCase.maxAliases)
+
+			for _, alias := range testCase.visits {
+				err := buffer.Increase(context.Background(), alias)
+				assert.Equal(t, nil, err)
+			}
+			if testCase.isFlushed {
+				err := buffer.Flush()
+				assert.Equal(t, nil, err)
+			}
+
+			for alias, expectedVisitCount := range testCase.expectedVisitCounts {
+				shortLink, err := shortLinkRepo.GetShortLinkByAlias(context.Background(), alias)
+				assert.Equal(t, nil, err)
+				assert.Equal(t, expectedVisitCount, shortLink.VisitCount)
+			}
+		})
+	}
+}
+
+func TestCountBuffer_FlushFailed(t *testing.T) {
+	t.Parallel()
+
+	shortLinkRepo := repository.NewShortLinkFake(nil, map[string]entity.ShortLink{
+		"google": {Alias: "google"},
+	})
+	isDown := true
+	buffer := newCountBuffer(t, unstableShortLinkRepo{
+		ShortLink: &shortLinkRepo,
+		isDown:    &isDown,
+	}, 10)
+
+	assert.Equal(t, nil, buffer.Increase(context.Background(), "google"))
+	assert.NotEqual(t, nil, buffer.Flush())
+	assert.Equal(t, nil, buffer.Increase(context.Background(), "google"))
+
+	isDown = false
+	assert.Equal(t, nil, buffer.Flush())
+
+	shortLink, err := shortLinkRepo.GetShortLinkByAlias(context.Background(), "google")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, shortLink.VisitCount)
+}
+
+func TestCountBuffer_Stop(t *testing.T) {
+	t.Parallel()
+
+	shortLinkRepo := repository.NewShortLinkFake(nil, map[string]entity.ShortLink{
+		"google": {Alias: "google"},
+		"bing":   {Alias: "bing"},
+	})
+	buffer := newCountBuffer(t, &shortLinkRepo, 10)
+	buffer.Start()
+
+	for _, alias := range []string{"google", "bing", "google"} {
+		err := buffer.Increase(context.Background(), alias)
+		assert.Equal(t, nil, err)
+	}
+	buffer.Stop()
+
+	expectedVisitCounts := map[string]int{
+		"google": 2,
+		"bing":   1,
+	}
+	for alias, expectedVisitCount := range expectedVisitCounts {
+		shortLink, err := shortLinkRepo.GetShortLinkByAlias(context.Background(), alias)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, expectedVisitCount, shortLink.VisitCount)
+	}
+}
+
+func newCountBuffer(t *testing.T, shortLinkRepo repository.ShortLink, maxAliases int) CountBuffer {
+	entryRepo := logger.NewEntryRepoFake()
+	lg, err := logger.NewFake(logger.LogOff, &entryRepo)
+	assert.Equal(t, nil, err)
+
+	tm := timer.NewStub(time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC))
+	return NewCountBuffer(shortLinkRepo, tm, lg, time.Minute, maxAliases)
+}
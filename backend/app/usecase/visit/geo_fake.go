@@ -0,0 +1,28 @@
+package visit
+
+import (
+	"errors"
+
+	"github.com/short-d/app/fw/geo"
+)
+
+var _ geo.Geo = (*GeoFake)(nil)
+
+// GeoFake represents an in memory IP geo location lookup used for testing.
+type GeoFake struct {
+	locations map[string]geo.Location
+}
+
+// GetLocation finds the location of the given IP address.
+func (g GeoFake) GetLocation(ipAddress string) (geo.Location, error) {
+	location, ok := g.locations[ipAddress]
+	if !ok {
+		return geo.Location{}, errors.New("location not found")
+	}
+	return location, nil
+}
+
+// NewGeoFake creates GeoFake
+func NewGeoFake(locations map[string]geo.Location) GeoFake {
+	return GeoFake{locations: locations}
+}
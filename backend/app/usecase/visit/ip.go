@@ -0,0 +1,51 @@
+package visit
+
+import "net"
+
+// IPMode represents how the IP addresses of visitors are stored for
+// analytics.
+type IPMode string
+
+// The constants enumerate all supported IP modes.
+const (
+	IPModeFull       IPMode = "full"
+	IPModeAnonymized IPMode = "anonymized"
+	IPModeNone       IPMode = "none"
+)
+
+const (
+	ipv4AnonymizedPrefixLen = 24
+	ipv6AnonymizedPrefixLen = 48
+)
+
+// minimizeIP strips the IP address to the extent allowed by the IP mode.
+// Unknown modes fall back to anonymization.
+func minimizeIP(ipAddress string, ipMode IPMode) string {
+	switch ipMode {
+	case IPModeNone:
+		return ""
+	case IPModeFull:
+		ip := net.ParseIP(ipAddress)
+		if ip == nil {
+			return ""
+		}
+		return ip.String()
+	default:
+		return anonymizeIP(ipAddress)
+	}
+}
+
+// anonymizeIP zeros the last octet of IPv4 addresses and the last 80 bits of
+// IPv6 addresses.
+func anonymizeIP(ipAddress string) string {
+	ip := net.ParseIP(ipAddress)
+	if ip == nil {
+		return ""
+	}
+
+	ipv4 := ip.To4()
+	if ipv4 != nil {
+		return ipv4.Mask(net.CIDRMask(ipv4AnonymizedPrefixLen, 8*net.IPv4len)).String()
+	}
+	return ip.Mask(net.CIDRMask(ipv6AnonymizedPrefixLen, 8*net.IPv6len)).String()
+}
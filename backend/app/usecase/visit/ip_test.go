@@ -0,0 +1,109 @@
+// +build !integration all
+
+package visit
+
+import (
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+)
+
+func TestAnonymizeIP(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		ipAddress  string
+		expectedIP string
+	}{
+		{
+			name:       "IPv4 address",
+			ipAddress:  "192.168.100.42",
+			expectedIP: "192.168.100.0",
+		},
+		{
+			name:       "IPv4 address with last octet zeroed",
+			ipAddress:  "10.0.0.0",
+			expectedIP: "10.0.0.0",
+		},
+		{
+			name:       "IPv4-mapped IPv6 address",
+			ipAddress:  "::ffff:192.168.100.42",
+			expectedIP: "192.168.100.0",
+		},
+		{
+			name:       "IPv6 address",
+			ipAddress:  "2001:0db8:85a3:1234:5678:8a2e:0370:7334",
+			expectedIP: "2001:db8:85a3::",
+		},
+		{
+			name:       "abbreviated IPv6 address",
+			ipAddress:  "fe80::1",
+			expectedIP: "fe80::",
+		},
+		{
+			name:       "invalid IP address",
+			ipAddress:  "not an ip",
+			expectedIP: "",
+		},
+		{
+			name:       "empty IP address",
+			ipAddress:  "",
+			expectedIP: "",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, testCase.expectedIP, anonymizeIP(testCase.ipAddress))
+		})
+	}
+}
+
+func TestMinimizeIP(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		ipAddress  string
+		ipMode     IPMode
+		expectedIP string
+	}{
+		{
+			name:       "full mode keeps IP address",
+			ipAddress:  "192.168.100.42",
+			ipMode:     IPModeFull,
+			expectedIP: "192.168.100.42",
+		},
+		{
+			name:       "anonymized mode truncates IP address",
+			ipAddress:  "192.168.100.42",
+			ipMode:     IPModeAnonymized,
+			expectedIP: "192.168.100.0",
+		},
+		{
+			name:       "none mode drops IP address",
+			ipAddress:  "192.168.100.42",
+			ipMode:     IPModeNone,
+			expectedIP: "",
+		},
+		{
+			name:       "unknown mode falls back to anonymization",
+			ipAddress:  "2001:db8:85a3::8a2e:370:7334",
+			ipMode:     IPMode("unknown"),
+			expectedIP: "2001:db8:85a3::",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, testCase.expectedIP, minimizeIP(testCase.ipAddress, testCase.ipMode))
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package visit
+
+import (
+	"net/url"
+	"strings"
+)
+
+// DirectReferrer represents the visits without a referrer, such as typing the
+// short link into the browser.
+const DirectReferrer = "direct"
+
+// ReferrerStat represents the number of clicks coming from a referring host.
+type ReferrerStat struct {
+	Referrer string
+	Clicks   int
+}
+
+// normalizeReferrer extracts the host from the Referer header. Malformed
+// values are treated as no referrer.
+func normalizeReferrer(referrer string) string {
+	referrer = strings.TrimSpace(referrer)
+	if referrer == "" {
+		return ""
+	}
+
+	u, err := url.Parse(referrer)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
@@ -0,0 +1,59 @@
+// +build !integration all
+
+package visit
+
+import (
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+)
+
+func TestNormalizeReferrer(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name             string
+		referrer         string
+		expectedReferrer string
+	}{
+		{
+			name:             "no referrer",
+			referrer:         "",
+			expectedReferrer: "",
+		},
+		{
+			name:             "full URL",
+			referrer:         "https://twitter.com/short_d/status/1?s=20",
+			expectedReferrer: "twitter.com",
+		},
+		{
+			name:             "host with port and uppercase letters",
+			referrer:         "http://News.YCombinator.com:8080/item",
+			expectedReferrer: "news.ycombinator.com",
+		},
+		{
+			name:             "surrounding spaces",
+			referrer:         "  https://www.google.com/  ",
+			expectedReferrer: "www.google.com",
+		},
+		{
+			name:             "malformed URL",
+			referrer:         "http://[::1%zz/",
+			expectedReferrer: "",
+		},
+		{
+			name:             "not a URL",
+			referrer:         "not a referrer",
+			expectedReferrer: "",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, testCase.expectedReferrer, normalizeReferrer(testCase.referrer))
+		})
+	}
+}
@@ -0,0 +1,293 @@
+package visit
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/fw/tenant"
+	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/shortlink"
+)
+
+var _ Stats = (*StatsPersist)(nil)
+
+// maxTimeBuckets prevents absurd time ranges, such as hourly granularity
+// over years, from exhausting the memory.
+const maxTimeBuckets = 1000
+
+// Granularity represents the length of each time bucket in a time series.
+type Granularity string
+
+// The constants enumerate all supported granularities.
+const (
+	GranularityHour Granularity = "hour"
+	GranularityDay  Granularity = "day"
+)
+
+// ErrInvalidGranularity represents unsupported granularity provided.
+type ErrInvalidGranularity string
+
+func (e ErrInvalidGranularity) Error() string {
+	return string(e)
+}
+
+// ErrInvalidTimeRange represents the start of the time range is not before
+// the end of the time range.
+type ErrInvalidTimeRange string
+
+func (e ErrInvalidTimeRange) Error() string {
+	return string(e)
+}
+
+// ErrTooManyTimeBuckets represents the time range contains more buckets than
+// the system allows.
+type ErrTooManyTimeBuckets string
+
+func (e ErrTooManyTimeBuckets) Error() string {
+	return string(e)
+}
+
+// ErrInvalidLimit represents the maximum number of results requested is not
+// positive.
+type ErrInvalidLimit string
+
+func (e ErrInvalidLimit) Error() string {
+	return string(e)
+}
+
+// TimeBucket represents the number of clicks within [Start, Start + granularity).
+type TimeBucket struct {
+	Start  time.Time
+	Clicks int
+}
+
+// Stats summarizes the visits of short links.
+type Stats interface {
+	GetClickTimeSeries(
+		ctx context.Context,
+		alias string,
+		user entity.User,
+		granularity Granularity,
+		from time.Time,
+		to time.Time,
+	) ([]TimeBucket, error)
+	GetTopReferrers(ctx context.Context, alias string, user entity.User, limit int) ([]ReferrerStat, error)
+	GetDeviceBreakdown(ctx context.Context, alias string, user entity.User) (DeviceBreakdown, error)
+	GetCampaignBreakdown(ctx context.Context, alias string, user entity.User) ([]CampaignStat, error)
+}
+
+// StatsPersist summarizes the visits of short links from persistent storage.
+type StatsPersist struct {
+	visitRepo         repository.Visit
+	userShortLinkRepo repository.UserShortLink
+}
+
+// GetClickTimeSeries counts the clicks of a short link owned by the user in
+// buckets aligned to UTC. The clicks are counted by the repository, and the
+// buckets without any clicks are filled in so that the time series has no
+// gaps.
+func (s StatsPersist) GetClickTimeSeries(
+	ctx context.Context,
+	alias string,
+	user entity.User,
+	granularity Granularity,
+	from time.Time,
+	to time.Time,
+) ([]TimeBucket, error) {
+	if granularity != GranularityHour && granularity != GranularityDay {
+		return nil, ErrInvalidGranularity(granularity)
+	}
+	if !from.Before(to) {
+		return nil, ErrInvalidTimeRange("from must be before to")
+	}
+
+	start := alignToBucket(from, granularity)
+	bucketDuration := getBucketDuration(granularity)
+	bucketCount := int((to.Sub(start) + bucketDuration - 1) / bucketDuration)
+	if bucketCount > maxTimeBuckets {
+		return nil, ErrTooManyTimeBuckets("time range contains too many buckets")
+	}
+
+	ctx = tenant.NewContext(ctx, user.TenantID)
+	hasMapping, err := s.userShortLinkRepo.HasMapping(ctx, user, alias)
+	if err != nil {
+		return nil, err
+	}
+	if !hasMapping {
+		return nil, shortlink.ErrShortLinkNotFound(alias)
+	}
+
+	end := start.Add(time.Duration(bucketCount) * bucketDuration)
+	counts, err := s.visitRepo.CountVisitsByTime(ctx, alias, string(granularity), start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	buckets := make([]TimeBucket, bucketCount)
+	for idx := range buckets {
+		bucketStart := start.Add(time.Duration(idx) * bucketDuration)
+		buckets[idx] = TimeBucket{Start: bucketStart, Clicks: counts[bucketStart]}
+	}
+	return buckets, nil
+}
+
+// GetTopReferrers retrieves the hosts referring the most clicks to a short link
+// owned by the user, in descending order of clicks. Clicks without a referrer
+// are counted under DirectReferrer.
+func (s StatsPersist) GetTopReferrers(
+	ctx context.Context,
+	alias string,
+	user entity.User,
+	limit int,
+) ([]ReferrerStat, error) {
+	if limit <= 0 {
+		return nil, ErrInvalidLimit("limit must be positive")
+	}
+
+	ctx = tenant.NewContext(ctx, user.TenantID)
+	hasMapping, err := s.userShortLinkRepo.HasMapping(ctx, user, alias)
+	if err != nil {
+		return nil, err
+	}
+	if !hasMapping {
+		return nil, shortlink.ErrShortLinkNotFound(alias)
+	}
+
+	counts, err := s.visitRepo.CountVisitsByReferrer(ctx, alias)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]ReferrerStat, 0, len(counts))
+	for referrer, clicks := range counts {
+		if referrer == "" {
+			referrer = DirectReferrer
+		}
+		stats = append(stats, ReferrerStat{Referrer: referrer, Clicks: clicks})
+	}
+
+	sort.SliceStable(stats, func(i, j int) bool {
+		if stats[i].Clicks != stats[j].Clicks {
+			return stats[i].Clicks > stats[j].Clicks
+		}
+		return stats[i].Referrer < stats[j].Referrer
+	})
+
+	if len(stats) > limit {
+		stats = stats[:limit]
+	}
+	return stats, nil
+}
+
+// GetDeviceBreakdown counts the clicks of a short link owned by the user for
+// each device class, browser and operating system, in descending order of
+// clicks. Clicks without a recognized user agent are counted under
+// UnknownUserAgent.
+func (s StatsPersist) GetDeviceBreakdown(
+	ctx context.Context,
+	alias string,
+	user entity.User,
+) (DeviceBreakdown, error) {
+	ctx = tenant.NewContext(ctx, user.TenantID)
+	hasMapping, err := s.userShortLinkRepo.HasMapping(ctx, user, alias)
+	if err != nil {
+		return DeviceBreakdown{}, err
+	}
+	if !hasMapping {
+		return DeviceBreakdown{}, shortlink.ErrShortLinkNotFound(alias)
+	}
+
+	counts, err := s.visitRepo.CountVisitsByUserAgent(ctx, alias)
+	if err != nil {
+		return DeviceBreakdown{}, err
+	}
+
+	deviceClasses := make(map[string]int)
+	browsers := make(map[string]int)
+	operatingSystems := make(map[string]int)
+	for userAgent, clicks := range counts {
+		deviceClasses[orUnknown(userAgent.DeviceClass)] += clicks
+		browsers[orUnknown(userAgent.Browser)] += clicks
+		operatingSystems[orUnknown(userAgent.OS)] += clicks
+	}
+
+	return DeviceBreakdown{
+		DeviceClasses:    toDeviceStats(deviceClasses),
+		Browsers:         toDeviceStats(browsers),
+		OperatingSystems: toDeviceStats(operatingSystems),
+	}, nil
+}
+
+// GetCampaignBreakdown counts the clicks of a short link owned by the user for
+// each combination of UTM source, medium and campaign, in descending order of
+// clicks. Clicks without any UTM parameter are counted under
+// UnattributedCampaign.
+func (s StatsPersist) GetCampaignBreakdown(
+	ctx context.Context,
+	alias string,
+	user entity.User,
+) ([]CampaignStat, error) {
+	ctx = tenant.NewContext(ctx, user.TenantID)
+	hasMapping, err := s.userShortLinkRepo.HasMapping(ctx, user, alias)
+	if err != nil {
+		return nil, err
+	}
+	if !hasMapping {
+		return nil, shortlink.ErrShortLinkNotFound(alias)
+	}
+
+	counts, err := s.visitRepo.CountVisitsByCampaign(ctx, alias)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]CampaignStat, 0, len(counts))
+	for campaign, clicks := range counts {
+		if campaign == (entity.Campaign{}) {
+			campaign.Name = UnattributedCampaign
+		}
+		stats = append(stats, CampaignStat{Campaign: campaign, Clicks: clicks})
+	}
+
+	sort.SliceStable(stats, func(i, j int) bool {
+		if stats[i].Clicks != stats[j].Clicks {
+			return stats[i].Clicks > stats[j].Clicks
+		}
+		if stats[i].Campaign.Name != stats[j].Campaign.Name {
+			return stats[i].Campaign.Name < stats[j].Campaign.Name
+		}
+		if stats[i].Campaign.Source != stats[j].Campaign.Source {
+			return stats[i].Campaign.Source < stats[j].Campaign.Source
+		}
+		return stats[i].Campaign.Medium < stats[j].Campaign.Medium
+	})
+	return stats, nil
+}
+
+func alignToBucket(t time.Time, granularity Granularity) time.Time {
+	t = t.UTC()
+	if granularity == GranularityHour {
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, time.UTC)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func getBucketDuration(granularity Granularity) time.Duration {
+	if granularity == GranularityHour {
+		return time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// NewStatsPersist creates StatsPersist
+func NewStatsPersist(
+	visitRepo repository.Visit,
+	userShortLinkRepo repository.UserShortLink,
+) StatsPersist {
+	return StatsPersist{
+		visitRepo:         visitRepo,
+		userShortLinkRepo: userShortLinkRepo,
+	}
+}
@@ -0,0 +1,489 @@
+// +build !integration all
+
+package visit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+func TestStatsPersist_GetClickTimeSeries(t *testing.T) {
+	t.Parallel()
+
+	owner := entity.User{ID: "alpha"}
+	otherUser := entity.User{ID: "beta"}
+	from := time.Date(2020, 5, 1, 10, 30, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name            string
+		visits          []entity.Visit
+		user            entity.User
+		granularity     Granularity
+		from            time.Time
+		to              time.Time
+		expHasErr       bool
+		expectedBuckets []TimeBucket
+	}{
+		{
+			name: "hourly buckets with empty buckets zero filled",
+			visits: []entity.Visit{
+				{Alias: "220uFicCJj", VisitedAt: time.Date(2020, 5, 1, 10, 45, 0, 0, time.UTC)},
+				{Alias: "220uFicCJj", VisitedAt: time.Date(2020, 5, 1, 12, 5, 0, 0, time.UTC)},
+				{Alias: "220uFicCJj", VisitedAt: time.Date(2020, 5, 1, 12, 59, 59, 0, time.UTC)},
+				{Alias: "yDOBcj5HIPbUAsw", VisitedAt: time.Date(2020, 5, 1, 11, 0, 0, 0, time.UTC)},
+			},
+			user:        owner,
+			granularity: GranularityHour,
+			from:        from,
+			to:          time.Date(2020, 5, 1, 13, 0, 0, 0, time.UTC),
+			expHasErr:   false,
+			expectedBuckets: []TimeBucket{
+				{Start: time.Date(2020, 5, 1, 10, 0, 0, 0, time.UTC), Clicks: 1},
+				{Start: time.Date(2020, 5, 1, 11, 0, 0, 0, time.UTC), Clicks: 0},
+				{Start: time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC), Clicks: 2},
+			},
+		},
+		{
+			name: "visits on bucket boundaries",
+			visits: []entity.Visit{
+				{Alias: "220uFicCJj", VisitedAt: time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)},
+				{Alias: "220uFicCJj", VisitedAt: time.Date(2020, 5, 1, 23, 59, 59, 999, time.UTC)},
+				{Alias: "220uFicCJj", VisitedAt: time.Date(2020, 5, 2, 0, 0, 0, 0, time.UTC)},
+			},
+			user:        owner,
+			granularity: GranularityDay,
+			from:        from,
+			to:          time.Date(2020, 5, 2, 1, 0, 0, 0, time.UTC),
+			expHasErr:   false,
+			expectedBuckets: []TimeBucket{
+				{Start: time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC), Clicks: 2},
+				{Start: time.Date(2020, 5, 2, 0, 0, 0, 0, time.UTC), Clicks: 1},
+			},
+		},
+		{
+			name: "buckets aligned to UTC",
+			visits: []entity.Visit{
+				{Alias: "220uFicCJj", VisitedAt: time.Date(2020, 5, 1, 23, 30, 0, 0, time.UTC)},
+			},
+			user:        owner,
+			granularity: GranularityDay,
+			from:        time.Date(2020, 5, 1, 20, 0, 0, 0, time.FixedZone("PDT", -7*60*60)),
+			to:          time.Date(2020, 5, 2, 20, 0, 0, 0, time.FixedZone("PDT", -7*60*60)),
+			expHasErr:   false,
+			expectedBuckets: []TimeBucket{
+				{Start: time.Date(2020, 5, 2, 0, 0, 0, 0, time.UTC), Clicks: 0},
+				{Start: time.Date(2020, 5, 3, 0, 0, 0, 0, time.UTC), Clicks: 0},
+			},
+		},
+		{
+			name:        "too many buckets",
+			visits:      []entity.Visit{},
+			user:        owner,
+			granularity: GranularityHour,
+			from:        from,
+			to:          from.AddDate(1, 0, 0),
+			expHasErr:   true,
+		},
+		{
+			name:        "maximum number of buckets",
+			visits:      []entity.Visit{},
+			user:        owner,
+			granularity: GranularityHour,
+			from:        from,
+			to:          from.Add((maxTimeBuckets - 1) * time.Hour),
+			expHasErr:   false,
+		},
+		{
+			name:        "from after to",
+			visits:      []entity.Visit{},
+			user:        owner,
+			granularity: GranularityHour,
+			from:        from,
+			to:          from.Add(-time.Hour),
+			expHasErr:   true,
+		},
+		{
+			name:        "unsupported granularity",
+			visits:      []entity.Visit{},
+			user:        owner,
+			granularity: Granularity("minute"),
+			from:        from,
+			to:          from.Add(time.Hour),
+			expHasErr:   true,
+		},
+		{
+			name:        "user does not own the short link",
+			visits:      []entity.Visit{},
+			user:        otherUser,
+			granularity: GranularityHour,
+			from:        from,
+			to:          from.Add(time.Hour),
+			expHasErr:   true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			visitRepo := repository.NewVisitFake(testCase.visits)
+			userShortLinkRepo := repository.NewUserShortLinkRepoFake(
+				[]entity.User{owner},
+				[]entity.ShortLink{{Alias: "220uFicCJj"}},
+			)
+			stats := NewStatsPersist(&visitRepo, &userShortLinkRepo)
+
+			buckets, err := stats.GetClickTimeSeries(
+				context.Background(),
+				"220uFicCJj",
+				testCase.user,
+				testCase.granularity,
+				testCase.from,
+				testCase.to,
+			)
+			if testCase.expHasErr {
+				assert.NotEqual(t, nil, err)
+				return
+			}
+			assert.Equal(t, nil, err)
+			if testCase.expectedBuckets != nil {
+				assert.Equal(t, testCase.expectedBuckets, buckets)
+			}
+		})
+	}
+}
+
+func TestStatsPersist_GetTopReferrers(t *testing.T) {
+	t.Parallel()
+
+	owner := entity.User{ID: "alpha"}
+	otherUser := entity.User{ID: "beta"}
+
+	testCases := []struct {
+		name          string
+		visits        []entity.Visit
+		user          entity.User
+		limit         int
+		expHasErr     bool
+		expectedStats []ReferrerStat
+	}{
+		{
+			name:          "no visits",
+			visits:        []entity.Visit{},
+			user:          owner,
+			limit:         10,
+			expHasErr:     false,
+			expectedStats: []ReferrerStat{},
+		},
+		{
+			name: "aggregate clicks by referrer",
+			visits: []entity.Visit{
+				{Alias: "220uFicCJj", Referrer: "twitter.com"},
+				{Alias: "220uFicCJj", Referrer: "news.ycombinator.com"},
+				{Alias: "220uFicCJj", Referrer: "twitter.com"},
+				{Alias: "220uFicCJj", Referrer: "www.facebook.com"},
+				{Alias: "220uFicCJj", Referrer: "twitter.com"},
+				{Alias: "220uFicCJj", Referrer: "news.ycombinator.com"},
+				{Alias: "yDOBcj5HIPbUAsw", Referrer: "www.facebook.com"},
+			},
+			user:      owner,
+			limit:     10,
+			expHasErr: false,
+			expectedStats: []ReferrerStat{
+				{Referrer: "twitter.com", Clicks: 3},
+				{Referrer: "news.ycombinator.com", Clicks: 2},
+				{Referrer: "www.facebook.com", Clicks: 1},
+			},
+		},
+		{
+			name: "bucket clicks without referrer as direct",
+			visits: []entity.Visit{
+				{Alias: "220uFicCJj", Referrer: ""},
+				{Alias: "220uFicCJj", Referrer: "twitter.com"},
+				{Alias: "220uFicCJj", Referrer: ""},
+			},
+			user:      owner,
+			limit:     10,
+			expHasErr: false,
+			expectedStats: []ReferrerStat{
+				{Referrer: DirectReferrer, Clicks: 2},
+				{Referrer: "twitter.com", Clicks: 1},
+			},
+		},
+		{
+			name: "break ties by referrer and apply limit",
+			visits: []entity.Visit{
+				{Alias: "220uFicCJj", Referrer: "www.reddit.com"},
+				{Alias: "220uFicCJj", Referrer: "twitter.com"},
+				{Alias: "220uFicCJj", Referrer: "news.ycombinator.com"},
+			},
+			user:      owner,
+			limit:     2,
+			expHasErr: false,
+			expectedStats: []ReferrerStat{
+				{Referrer: "news.ycombinator.com", Clicks: 1},
+				{Referrer: "twitter.com", Clicks: 1},
+			},
+		},
+		{
+			name:      "limit not positive",
+			visits:    []entity.Visit{},
+			user:      owner,
+			limit:     0,
+			expHasErr: true,
+		},
+		{
+			name: "user does not own the short link",
+			visits: []entity.Visit{
+				{Alias: "220uFicCJj", Referrer: "twitter.com"},
+			},
+			user:      otherUser,
+			limit:     10,
+			expHasErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			visitRepo := repository.NewVisitFake(testCase.visits)
+			userShortLinkRepo := repository.NewUserShortLinkRepoFake(
+				[]entity.User{owner},
+				[]entity.ShortLink{{Alias: "220uFicCJj"}},
+			)
+			stats := NewStatsPersist(&visitRepo, &userShortLinkRepo)
+
+			referrerStats, err := stats.GetTopReferrers(context.Background(), "220uFicCJj", testCase.user, testCase.limit)
+			if testCase.expHasErr {
+				assert.NotEqual(t, nil, err)
+				return
+			}
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedStats, referrerStats)
+		})
+	}
+}
+
+func TestStatsPersist_GetDeviceBreakdown(t *testing.T) {
+	t.Parallel()
+
+	owner := entity.User{ID: "alpha"}
+	otherUser := entity.User{ID: "beta"}
+	chromeOnAndroid := entity.UserAgent{
+		Browser:     "Chrome",
+		OS:          "Android",
+		DeviceClass: DeviceClassMobile,
+	}
+	chromeOnWindows := entity.UserAgent{
+		Browser:     "Chrome",
+		OS:          "Windows",
+		DeviceClass: DeviceClassDesktop,
+	}
+	safariOnIPad := entity.UserAgent{
+		Browser:     "Safari",
+		OS:          "iOS",
+		DeviceClass: DeviceClassTablet,
+	}
+
+	testCases := []struct {
+		name              string
+		visits            []entity.Visit
+		user              entity.User
+		expHasErr         bool
+		expectedBreakdown DeviceBreakdown
+	}{
+		{
+			name:      "no visits",
+			visits:    []entity.Visit{},
+			user:      owner,
+			expHasErr: false,
+			expectedBreakdown: DeviceBreakdown{
+				DeviceClasses:    []DeviceStat{},
+				Browsers:         []DeviceStat{},
+				OperatingSystems: []DeviceStat{},
+			},
+		},
+		{
+			name: "aggregate clicks by device class, browser and OS",
+			visits: []entity.Visit{
+				{Alias: "220uFicCJj", UserAgent: chromeOnAndroid},
+				{Alias: "220uFicCJj", UserAgent: chromeOnWindows},
+				{Alias: "220uFicCJj", UserAgent: chromeOnAndroid},
+				{Alias: "220uFicCJj", UserAgent: safariOnIPad},
+				{Alias: "yDOBcj5HIPbUAsw", UserAgent: safariOnIPad},
+			},
+			user:      owner,
+			expHasErr: false,
+			expectedBreakdown: DeviceBreakdown{
+				DeviceClasses: []DeviceStat{
+					{Name: DeviceClassMobile, Clicks: 2},
+					{Name: DeviceClassDesktop, Clicks: 1},
+					{Name: DeviceClassTablet, Clicks: 1},
+				},
+				Browsers: []DeviceStat{
+					{Name: "Chrome", Clicks: 3},
+					{Name: "Safari", Clicks: 1},
+				},
+				OperatingSystems: []DeviceStat{
+					{Name: "Android", Clicks: 2},
+					{Name: "Windows", Clicks: 1},
+					{Name: "iOS", Clicks: 1},
+				},
+			},
+		},
+		{
+			name: "bucket clicks without recognized user agent as unknown",
+			visits: []entity.Visit{
+				{Alias: "220uFicCJj"},
+				{Alias: "220uFicCJj", UserAgent: entity.UserAgent{DeviceClass: DeviceClassBot}},
+				{Alias: "220uFicCJj"},
+			},
+			user:      owner,
+			expHasErr: false,
+			expectedBreakdown: DeviceBreakdown{
+				DeviceClasses: []DeviceStat{
+					{Name: UnknownUserAgent, Clicks: 2},
+					{Name: DeviceClassBot, Clicks: 1},
+				},
+				Browsers: []DeviceStat{
+					{Name: UnknownUserAgent, Clicks: 3},
+				},
+				OperatingSystems: []DeviceStat{
+					{Name: UnknownUserAgent, Clicks: 3},
+				},
+			},
+		},
+		{
+			name: "user does not own the short link",
+			visits: []entity.Visit{
+				{Alias: "220uFicCJj", UserAgent: chromeOnAndroid},
+			},
+			user:      otherUser,
+			expHasErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			visitRepo := repository.NewVisitFake(testCase.visits)
+			userShortLinkRepo := repository.NewUserShortLinkRepoFake(
+				[]entity.User{owner},
+				[]entity.ShortLink{{Alias: "220uFicCJj"}},
+			)
+			stats := NewStatsPersist(&visitRepo, &userShortLinkRepo)
+
+			breakdown, err := stats.GetDeviceBreakdown(context.Background(), "220uFicCJj", testCase.user)
+			if testCase.expHasErr {
+				assert.NotEqual(t, nil, err)
+				return
+			}
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedBreakdown, breakdown)
+		})
+	}
+}
+
+func TestStatsPersist_GetCampaignBreakdown(t *testing.T) {
+	t.Parallel()
+
+	owner := entity.User{ID: "alpha"}
+	otherUser := entity.User{ID: "beta"}
+	springSale := entity.Campaign{
+		Source: "newsletter",
+		Medium: "email",
+		Name:   "spring_sale",
+	}
+	launch := entity.Campaign{
+		Source: "twitter",
+		Medium: "social",
+		Name:   "launch",
+	}
+
+	testCases := []struct {
+		name          string
+		visits        []entity.Visit
+		user          entity.User
+		expHasErr     bool
+		expectedStats []CampaignStat
+	}{
+		{
+			name:          "no visits",
+			visits:        []entity.Visit{},
+			user:          owner,
+			expHasErr:     false,
+			expectedStats: []CampaignStat{},
+		},
+		{
+			name: "bucket clicks by campaign",
+			visits: []entity.Visit{
+				{Alias: "220uFicCJj", Campaign: launch},
+				{Alias: "220uFicCJj", Campaign: springSale},
+				{Alias: "220uFicCJj", Campaign: springSale},
+				{Alias: "yDOBcj5HIPbUAsw", Campaign: launch},
+			},
+			user:      owner,
+			expHasErr: false,
+			expectedStats: []CampaignStat{
+				{Campaign: springSale, Clicks: 2},
+				{Campaign: launch, Clicks: 1},
+			},
+		},
+		{
+			name: "bucket clicks without UTM parameters as unattributed",
+			visits: []entity.Visit{
+				{Alias: "220uFicCJj"},
+				{Alias: "220uFicCJj", Campaign: launch},
+				{Alias: "220uFicCJj"},
+				{Alias: "220uFicCJj", Campaign: entity.Campaign{Source: "twitter"}},
+			},
+			user:      owner,
+			expHasErr: false,
+			expectedStats: []CampaignStat{
+				{Campaign: entity.Campaign{Name: UnattributedCampaign}, Clicks: 2},
+				{Campaign: entity.Campaign{Source: "twitter"}, Clicks: 1},
+				{Campaign: launch, Clicks: 1},
+			},
+		},
+		{
+			name: "user does not own the short link",
+			visits: []entity.Visit{
+				{Alias: "220uFicCJj", Campaign: launch},
+			},
+			user:      otherUser,
+			expHasErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			visitRepo := repository.NewVisitFake(testCase.visits)
+			userShortLinkRepo := repository.NewUserShortLinkRepoFake(
+				[]entity.User{owner},
+				[]entity.ShortLink{{Alias: "220uFicCJj"}},
+			)
+			stats := NewStatsPersist(&visitRepo, &userShortLinkRepo)
+
+			campaignStats, err := stats.GetCampaignBreakdown(context.Background(), "220uFicCJj", testCase.user)
+			if testCase.expHasErr {
+				assert.NotEqual(t, nil, err)
+				return
+			}
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedStats, campaignStats)
+		})
+	}
+}
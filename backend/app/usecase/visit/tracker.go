@@ -0,0 +1,84 @@
+package visit
+
+import (
+	"context"
+
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/entity"
+)
+
+var _ Tracker = (*TrackerPersist)(nil)
+
+// Visitor represents the client visiting a short link.
+type Visitor struct {
+	IPAddress   string
+	Referrer    string
+	UserAgent   string
+	Destination string
+}
+
+// Details represents the optional details of the visitor stored with each
+// visit.
+type Details struct {
+	Referrer  bool
+	UserAgent bool
+	Campaign  bool
+}
+
+// Tracker records the visits of short links.
+type Tracker interface {
+	TrackVisit(ctx context.Context, alias string, visitor Visitor) error
+}
+
+// TrackerPersist records the visits of short links in persistent storage.
+type TrackerPersist struct {
+	timer       timer.Timer
+	details     Details
+	uaParser    UserAgentParser
+	visits      Buffer
+	visitCounts CountBuffer
+}
+
+// TrackVisit records a visit of the short link of the tenant attached to the
+// context at the current time. The referrer, the user agent and the UTM
+// parameters of the destination are only stored when enabled in details. The
+// visit and the visit count of the short link are buffered and written in
+// batches, so that redirects don't wait for the database or the country
+// lookups.
+func (t TrackerPersist) TrackVisit(ctx context.Context, alias string, visitor Visitor) error {
+	visit := entity.Visit{
+		Alias:     alias,
+		VisitedAt: t.timer.Now().UTC(),
+	}
+	if t.details.Referrer {
+		visit.Referrer = normalizeReferrer(visitor.Referrer)
+	}
+	if t.details.UserAgent {
+		visit.UserAgent = t.uaParser.Parse(visitor.UserAgent)
+	}
+	if t.details.Campaign {
+		visit.Campaign = parseCampaign(visitor.Destination)
+	}
+	err := t.visits.Add(ctx, visit, visitor.IPAddress)
+	if err != nil {
+		return err
+	}
+	return t.visitCounts.Increase(ctx, alias)
+}
+
+// NewTrackerPersist creates TrackerPersist
+func NewTrackerPersist(
+	timer timer.Timer,
+	details Details,
+	uaParser UserAgentParser,
+	visits Buffer,
+	visitCounts CountBuffer,
+) TrackerPersist {
+	return TrackerPersist{
+		timer:       timer,
+		details:     details,
+		uaParser:    uaParser,
+		visits:      visits,
+		visitCounts: visitCounts,
+	}
+}
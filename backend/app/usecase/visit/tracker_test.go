@@ -0,0 +1,193 @@
+// +build !integration all
+
+package visit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/geo"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+func TestTrackerPersist_TrackVisit(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 5, 1, 10, 30, 0, 0, time.UTC)
+	locations := map[string]geo.Location{
+		"203.0.113.195": {
+			Country: geo.Country{Code: "US", Name: "United States"},
+		},
+		"2001:db8:85a3::8a2e:370:7334": {
+			Country: geo.Country{Code: "CA", Name: "Canada"},
+		},
+	}
+	iPhoneUserAgent := "Mozilla/5.0 (iPhone; CPU iPhone OS 14_0 like Mac OS X)"
+	userAgents := map[string]entity.UserAgent{
+		iPhoneUserAgent: {
+			Browser:     "Safari",
+			OS:          "iOS",
+			DeviceClass: DeviceClassMobile,
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		ipMode        IPMode
+		details       Details
+		visitor       Visitor
+		expectedVisit entity.Visit
+	}{
+		{
+			name:    "store full IPv4 address",
+			ipMode:  IPModeFull,
+			visitor: Visitor{IPAddress: "203.0.113.195"},
+			expectedVisit: entity.Visit{
+				Alias:       "220uFicCJj",
+				IPAddress:   "203.0.113.195",
+				CountryCode: "US",
+				VisitedAt:   now,
+			},
+		},
+		{
+			name:    "anonymize IPv4 address",
+			ipMode:  IPModeAnonymized,
+			visitor: Visitor{IPAddress: "203.0.113.195"},
+			expectedVisit: entity.Visit{
+				Alias:       "220uFicCJj",
+				IPAddress:   "203.0.113.0",
+				CountryCode: "US",
+				VisitedAt:   now,
+			},
+		},
+		{
+			name:    "anonymize IPv6 address",
+			ipMode:  IPModeAnonymized,
+			visitor: Visitor{IPAddress: "2001:db8:85a3::8a2e:370:7334"},
+			expectedVisit: entity.Visit{
+				Alias:       "220uFicCJj",
+				IPAddress:   "2001:db8:85a3::",
+				CountryCode: "CA",
+				VisitedAt:   now,
+			},
+		},
+		{
+			name:    "location not found",
+			ipMode:  IPModeAnonymized,
+			visitor: Visitor{IPAddress: "198.51.100.7"},
+			expectedVisit: entity.Visit{
+				Alias:     "220uFicCJj",
+				IPAddress: "198.51.100.0",
+				VisitedAt: now,
+			},
+		},
+		{
+			name:    "store referring host",
+			ipMode:  IPModeNone,
+			details: Details{Referrer: true},
+			visitor: Visitor{
+				Referrer: "https://twitter.com/short_d/status/1",
+			},
+			expectedVisit: entity.Visit{
+				Alias:     "220uFicCJj",
+				Referrer:  "twitter.com",
+				VisitedAt: now,
+			},
+		},
+		{
+			name:    "store parsed user agent",
+			ipMode:  IPModeNone,
+			details: Details{UserAgent: true},
+			visitor: Visitor{
+				Referrer:  "https://twitter.com/short_d/status/1",
+				UserAgent: iPhoneUserAgent,
+			},
+			expectedVisit: entity.Visit{
+				Alias: "220uFicCJj",
+				UserAgent: entity.UserAgent{
+					Browser:     "Safari",
+					OS:          "iOS",
+					DeviceClass: DeviceClassMobile,
+				},
+				VisitedAt: now,
+			},
+		},
+		{
+			name:    "store referrer and user agent",
+			ipMode:  IPModeNone,
+			details: Details{Referrer: true, UserAgent: true},
+			visitor: Visitor{
+				Referrer:  "https://twitter.com/short_d/status/1",
+				UserAgent: iPhoneUserAgent,
+			},
+			expectedVisit: entity.Visit{
+				Alias:    "220uFicCJj",
+				Referrer: "twitter.com",
+				UserAgent: entity.UserAgent{
+					Browser:     "Safari",
+					OS:          "iOS",
+					DeviceClass: DeviceClassMobile,
+				},
+				VisitedAt: now,
+			},
+		},
+		{
+			name:    "store UTM campaign of destination",
+			ipMode:  IPModeNone,
+			details: Details{Campaign: true},
+			visitor: Visitor{
+				Referrer:    "https://twitter.com/short_d/status/1",
+				Destination: "https://example.com/?utm_source=twitter&utm_campaign=launch",
+			},
+			expectedVisit: entity.Visit{
+				Alias: "220uFicCJj",
+				Campaign: entity.Campaign{
+					Source: "twitter",
+					Name:   "launch",
+				},
+				VisitedAt: now,
+			},
+		},
+		{
+			name:   "store nothing about visitor",
+			ipMode: IPModeNone,
+			visitor: Visitor{
+				IPAddress:   "203.0.113.195",
+				Referrer:    "https://twitter.com/short_d/status/1",
+				UserAgent:   iPhoneUserAgent,
+				Destination: "https://example.com/?utm_source=twitter",
+			},
+			expectedVisit: entity.Visit{
+				Alias:     "220uFicCJj",
+				VisitedAt: now,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			visitRepo := repository.NewVisitFake([]entity.Visit{})
+			tracker := NewTrackerPersist(
+				timer.NewStub(now),
+				testCase.details,
+				NewUserAgentParserFake(userAgents),
+				newBuffer(t, &visitRepo, NewGeoFake(locations), testCase.ipMode, 0),
+				CountBuffer{},
+			)
+
+			err := tracker.TrackVisit(context.Background(), "220uFicCJj", testCase.visitor)
+			assert.Equal(t, nil, err)
+
+			visits, err := visitRepo.FindVisitsByAlias(context.Background(), "220uFicCJj", now, now.Add(time.Second))
+			assert.Equal(t, nil, err)
+			assert.Equal(t, []entity.Visit{testCase.expectedVisit}, visits)
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package visit
+
+import (
+	"sort"
+
+	"github.com/short-d/short/backend/app/entity"
+)
+
+// UnknownUserAgent represents the browser, operating system or device class
+// which cannot be recognized from the User-Agent header.
+const UnknownUserAgent = "unknown"
+
+// The constants enumerate all device classes a user agent is classified into.
+const (
+	DeviceClassDesktop = "desktop"
+	DeviceClassMobile  = "mobile"
+	DeviceClassTablet  = "tablet"
+	DeviceClassBot     = "bot"
+)
+
+// UserAgentParser extracts the browser, operating system and device class from
+// the User-Agent header.
+type UserAgentParser interface {
+	Parse(userAgent string) entity.UserAgent
+}
+
+// DeviceStat represents the number of clicks coming from a browser, operating
+// system or device class.
+type DeviceStat struct {
+	Name   string
+	Clicks int
+}
+
+// DeviceBreakdown represents the clicks of a short link grouped by device
+// class, browser and operating system.
+type DeviceBreakdown struct {
+	DeviceClasses    []DeviceStat
+	Browsers         []DeviceStat
+	OperatingSystems []DeviceStat
+}
+
+func orUnknown(name string) string {
+	if name == "" {
+		return UnknownUserAgent
+	}
+	return name
+}
+
+func toDeviceStats(counts map[string]int) []DeviceStat {
+	stats := make([]DeviceStat, 0, len(counts))
+	for name, clicks := range counts {
+		stats = append(stats, DeviceStat{Name: name, Clicks: clicks})
+	}
+
+	sort.SliceStable(stats, func(i, j int) bool {
+		if stats[i].Clicks != stats[j].Clicks {
+			return stats[i].Clicks > stats[j].Clicks
+		}
+		return stats[i].Name < stats[j].Name
+	})
+	return stats
+}
@@ -0,0 +1,21 @@
+package visit
+
+import "github.com/short-d/short/backend/app/entity"
+
+var _ UserAgentParser = (*UserAgentParserFake)(nil)
+
+// UserAgentParserFake represents an in memory User-Agent parser used for
+// testing.
+type UserAgentParserFake struct {
+	userAgents map[string]entity.UserAgent
+}
+
+// Parse finds the parsed user agent of the given User-Agent header.
+func (u UserAgentParserFake) Parse(userAgent string) entity.UserAgent {
+	return u.userAgents[userAgent]
+}
+
+// NewUserAgentParserFake creates UserAgentParserFake
+func NewUserAgentParserFake(userAgents map[string]entity.UserAgent) UserAgentParserFake {
+	return UserAgentParserFake{userAgents: userAgents}
+}
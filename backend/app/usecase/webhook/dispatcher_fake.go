@@ -0,0 +1,23 @@
+package webhook
+
+var _ Dispatcher = (*DispatcherFake)(nil)
+
+// DispatcherFake represents an in memory Dispatcher used for testing.
+type DispatcherFake struct {
+	events *[]Event
+}
+
+// Dispatch captures the event.
+func (d DispatcherFake) Dispatch(event Event) {
+	*d.events = append(*d.events, event)
+}
+
+// Events returns the events dispatched so far.
+func (d DispatcherFake) Events() []Event {
+	return *d.events
+}
+
+// NewDispatcherFake creates DispatcherFake
+func NewDispatcherFake() DispatcherFake {
+	return DispatcherFake{events: &[]Event{}}
+}
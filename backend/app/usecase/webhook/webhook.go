@@ -0,0 +1,41 @@
+package webhook
+
+import "time"
+
+// EventType identifies what happened in an Event.
+type EventType string
+
+// The constants enumerate all the events delivered to webhooks.
+const (
+	LinkFlagged EventType = "link.flagged"
+	LinkBroken  EventType = "link.broken"
+)
+
+// Event represents something happened in Short which external services, such
+// as the tools of security teams, subscribe to.
+type Event struct {
+	Type       EventType   `json:"type"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+// LinkFlaggedData represents a short link rejected by risk detection. UserID
+// is empty when the short link was submitted by a signed out user.
+type LinkFlaggedData struct {
+	LongLink string `json:"long_link"`
+	UserID   string `json:"user_id,omitempty"`
+}
+
+// LinkBrokenData represents a short link whose long link was healthy but failed
+// the recent health checks.
+type LinkBrokenData struct {
+	Alias    string `json:"alias"`
+	LongLink string `json:"long_link"`
+}
+
+// Dispatcher delivers events to webhooks. Events are delivered on a best
+// effort basis, so the callers are never blocked by or failed because of the
+// delivery.
+type Dispatcher interface {
+	Dispatch(event Event)
+}
@@ -3,10 +3,12 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strconv"
 
 	"github.com/short-d/app/fw/cli"
 	"github.com/short-d/app/fw/db"
 	"github.com/short-d/short/backend/app"
+	"github.com/short-d/short/backend/app/usecase/shortlink"
 	"github.com/short-d/short/backend/dep"
 	"github.com/short-d/short/backend/dep/provider"
 )
@@ -26,6 +28,12 @@ func NewRootCmd(
 			Usage:        "start",
 			ShortHelpMsg: "Start service",
 			OnExecute: func(cmd cli.Command, args []string) {
+				err := config.Validate()
+				if err != nil {
+					fmt.Println(err)
+					os.Exit(1)
+				}
+
 				config.MigrationRoot = migrationRoot
 				app.Start(
 					dbConfig,
@@ -52,14 +60,21 @@ func NewRootCmd(
 				Hostname: config.KgsHostname,
 				Port:     config.KgsPort,
 			}
+			kgsConnectRetry := provider.KgsConnectRetry{
+				MaxAttempts:    config.KgsMaxAttempts,
+				InitialBackoff: config.KgsInitialBackoff,
+				MaxBackoff:     config.KgsMaxBackoff,
+				DialTimeout:    config.KgsDialTimeout,
+			}
 			keyGenBufferSize := provider.KeyGenBufferSize(config.KeyGenBufferSize)
 			dataTool, err := dep.InjectDataTool(
 				provider.LogPrefix(config.LogPrefix),
-				config.LogLevel,
+				provider.LogLevel(config.LogLevel),
 				dbConfig,
 				dbConnector,
 				keyGenBufferSize,
 				kgsConfig,
+				kgsConnectRetry,
 			)
 			if err != nil {
 				fmt.Println(err)
@@ -75,6 +90,139 @@ func NewRootCmd(
 		"the max number of records to migrate",
 	)
 
+	var exportPath, ownerEmail, dryRun, onDuplicate string
+	importCmd := cmdFactory.NewCommand(cli.CommandConfig{
+		Usage:        "import",
+		ShortHelpMsg: "Import short links from CSV export of another URL shortener",
+		OnExecute: func(cmd cli.Command, args []string) {
+			isDryRun, err := strconv.ParseBool(dryRun)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			duplicateAlias, err := shortlink.ParseDuplicateAlias(onDuplicate)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			export, err := os.Open(exportPath)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			defer export.Close()
+
+			sqlDB, err := dbConnector.Connect(dbConfig)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			defer sqlDB.Close()
+
+			importTool, err := dep.InjectImportTool(
+				provider.LogPrefix(config.LogPrefix),
+				provider.LogLevel(config.LogLevel),
+				sqlDB,
+				provider.LongLinkAllowedDomains(config.AllowedDomains),
+				provider.ShortLinkDomains(config.ShortLinkDomains),
+				provider.WebFrontendURL(config.WebFrontendURL),
+				provider.CustomAliasUnicodeCategories(config.AliasCategories),
+				provider.LongLinkFragment(config.LongLinkFragment),
+				provider.AliasPrefix(config.AliasPrefix),
+				provider.LongLinkPlainHTTP(config.LongLinkPlainHTTP),
+			)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			options := shortlink.ImportOptions{
+				IsDryRun:       isDryRun,
+				DuplicateAlias: duplicateAlias,
+			}
+			_, err = importTool.ImportCSV(export, ownerEmail, options)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		},
+	})
+	importCmd.AddStringFlag(
+		&exportPath,
+		"file",
+		"",
+		"path to the CSV export",
+	)
+	importCmd.AddStringFlag(
+		&ownerEmail,
+		"owner",
+		"",
+		"email of the user who owns the imported short links",
+	)
+	importCmd.AddStringFlag(
+		&dryRun,
+		"dry-run",
+		"false",
+		"report the short links to import without saving them",
+	)
+	importCmd.AddStringFlag(
+		&onDuplicate,
+		"on-duplicate",
+		"skip",
+		"what to do when an alias is taken: skip, overwrite or rename",
+	)
+
+	var pruneBatchSize int
+	var pruneDryRun string
+	pruneCmd := cmdFactory.NewCommand(cli.CommandConfig{
+		Usage:        "prune-orphan-relations",
+		ShortHelpMsg: "Remove the relations between users and short links which no longer exist",
+		OnExecute: func(cmd cli.Command, args []string) {
+			isDryRun, err := strconv.ParseBool(pruneDryRun)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			sqlDB, err := dbConnector.Connect(dbConfig)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			defer sqlDB.Close()
+
+			pruneTool, err := dep.InjectPruneTool(
+				provider.LogPrefix(config.LogPrefix),
+				provider.LogLevel(config.LogLevel),
+				sqlDB,
+			)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+
+			_, err = pruneTool.OrphanRelations(pruneBatchSize, isDryRun)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		},
+	})
+	pruneCmd.AddIntFlag(
+		&pruneBatchSize,
+		"size",
+		1000,
+		"the max number of short links to prune the relations of at a time",
+	)
+	pruneCmd.AddStringFlag(
+		&pruneDryRun,
+		"dry-run",
+		"false",
+		"report the number of missing short links without removing their relations",
+	)
+
 	rootCmd := cmdFactory.NewCommand(
 		cli.CommandConfig{
 			Usage:     "short",
@@ -91,6 +239,16 @@ func NewRootCmd(
 		fmt.Println(err)
 		os.Exit(1)
 	}
+	err = rootCmd.AddSubCommand(importCmd)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	err = rootCmd.AddSubCommand(pruneCmd)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
 	return rootCmd
 }
 
@@ -0,0 +1,59 @@
+package provider
+
+import (
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/app/fw/webreq"
+	"github.com/short-d/short/backend/app/adapter/apple"
+	"github.com/short-d/short/backend/app/adapter/sqldb"
+	"github.com/short-d/short/backend/app/usecase/sso"
+)
+
+// AppleClientID represents the Services ID used for Sign in with Apple.
+type AppleClientID string
+
+// AppleRedirectURI represents redirect URL for Apple single sign on.
+type AppleRedirectURI string
+
+// NewAppleIdentityProvider creates a new Sign in with Apple client with
+// AppleClientID and AppleRedirectURI to uniquely identify clientID and
+// redirectURI during dependency injection.
+func NewAppleIdentityProvider(
+	clientID AppleClientID,
+	redirectURI AppleRedirectURI,
+) apple.IdentityProvider {
+	return apple.NewIdentityProvider(string(clientID), string(redirectURI))
+}
+
+// NewAppleAccount creates Apple account client with AppleClientID to uniquely
+// identify clientID during dependency injection.
+func NewAppleAccount(
+	req webreq.HTTP,
+	timer timer.Timer,
+	clientID AppleClientID,
+) apple.Account {
+	publicKeys := apple.NewPublicKeys(req, timer)
+	return apple.NewAccount(publicKeys, timer, string(clientID))
+}
+
+// NewAppleAccountLinker creates AppleAccountLinker.
+func NewAppleAccountLinker(
+	factory sso.AccountLinkerFactory,
+	appleSSORepo sqldb.AppleSSOSql,
+) apple.AccountLinker {
+	return apple.AccountLinker(factory.NewAccountLinker(appleSSORepo))
+}
+
+// NewAppleSSO creates AppleSingleSignOn.
+func NewAppleSSO(
+	ssoFactory sso.Factory,
+	identityProvider apple.IdentityProvider,
+	account apple.Account,
+	linker apple.AccountLinker,
+) apple.SingleSignOn {
+	return apple.SingleSignOn(
+		ssoFactory.NewSingleSignOn(
+			identityProvider,
+			account,
+			sso.AccountLinker(linker)),
+	)
+}
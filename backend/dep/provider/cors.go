@@ -0,0 +1,47 @@
+package provider
+
+import (
+	"net/url"
+
+	"github.com/short-d/short/backend/app/fw/cors"
+)
+
+// CORSConfig represents which cross origin requests browsers are allowed to
+// make to Short APIs.
+type CORSConfig struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	AllowCredentials bool
+}
+
+// NewCORSPolicy creates CORS policy with CORSConfig to uniquely identify the
+// config during dependency injection. Only the origin of the web frontend is
+// allowed when no origin is configured.
+func NewCORSPolicy(config CORSConfig, webFrontendURL WebFrontendURL) (cors.Policy, error) {
+	allowedOrigins := nonEmpty(config.AllowedOrigins)
+	if len(allowedOrigins) == 0 {
+		frontendURL, err := url.Parse(string(webFrontendURL))
+		if err != nil {
+			return cors.Policy{}, err
+		}
+		allowedOrigins = []string{frontendURL.Scheme + "://" + frontendURL.Host}
+	}
+
+	return cors.NewPolicy(
+		allowedOrigins,
+		nonEmpty(config.AllowedMethods),
+		nonEmpty(config.AllowedHeaders),
+		config.AllowCredentials,
+	)
+}
+
+func nonEmpty(values []string) []string {
+	var nonEmptyValues []string
+	for _, value := range values {
+		if value != "" {
+			nonEmptyValues = append(nonEmptyValues, value)
+		}
+	}
+	return nonEmptyValues
+}
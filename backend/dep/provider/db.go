@@ -0,0 +1,41 @@
+package provider
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/short-d/app/fw/logger"
+	"github.com/short-d/app/fw/metrics"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/adapter/sqldb"
+)
+
+// DBPoolConfig represents the connection pool settings of the SQL database.
+type DBPoolConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+}
+
+// ApplyDBPoolConfig applies the connection pool settings to the SQL database
+// handle shared by all services.
+func ApplyDBPoolConfig(sqlDB *sql.DB, config DBPoolConfig) {
+	sqlDB.SetMaxOpenConns(config.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(config.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(config.ConnMaxLifetime)
+}
+
+// SlowQueryThreshold represents the duration after which SQL queries are
+// reported as slow. Zero turns the reporting off.
+type SlowQueryThreshold time.Duration
+
+// NewSlowQueryLog creates SlowQueryLog with SlowQueryThreshold to uniquely
+// identify threshold during dependency injection.
+func NewSlowQueryLog(
+	threshold SlowQueryThreshold,
+	logger logger.Logger,
+	timer timer.Timer,
+	metrics metrics.Metrics,
+) sqldb.SlowQueryLog {
+	return sqldb.NewSlowQueryLog(time.Duration(threshold), logger, timer, metrics)
+}
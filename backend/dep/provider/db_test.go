@@ -0,0 +1,71 @@
+// +build !integration all
+
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+)
+
+var _ driver.Connector = (*connectorStub)(nil)
+
+type connectorStub struct{}
+
+func (c connectorStub) Connect(context.Context) (driver.Conn, error) {
+	return nil, errors.New("not connected")
+}
+
+func (c connectorStub) Driver() driver.Driver {
+	return nil
+}
+
+func TestApplyDBPoolConfig(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name                 string
+		config               DBPoolConfig
+		expectedMaxOpenConns int
+	}{
+		{
+			name: "limit open connections",
+			config: DBPoolConfig{
+				MaxOpenConns:    25,
+				MaxIdleConns:    10,
+				ConnMaxLifetime: 5 * time.Minute,
+			},
+			expectedMaxOpenConns: 25,
+		},
+		{
+			name: "unlimited open connections",
+			config: DBPoolConfig{
+				MaxOpenConns:    0,
+				MaxIdleConns:    2,
+				ConnMaxLifetime: 0,
+			},
+			expectedMaxOpenConns: 0,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			sqlDB := sql.OpenDB(connectorStub{})
+			defer sqlDB.Close()
+
+			ApplyDBPoolConfig(sqlDB, testCase.config)
+
+			stats := sqlDB.Stats()
+			assert.Equal(t, testCase.expectedMaxOpenConns, stats.MaxOpenConnections)
+			assert.Equal(t, 0, stats.OpenConnections)
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package provider
+
+import (
+	"time"
+
+	"github.com/short-d/app/fw/logger"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/adapter/smtp"
+	"github.com/short-d/short/backend/app/usecase/email"
+)
+
+const (
+	emailMaxAttempts = 3
+	emailBackoff     = time.Second
+)
+
+// SMTPConfig represents the SMTP server emails are delivered through and the
+// address they are sent from.
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// NewEmailSender creates Retry around the SMTP server with SMTPConfig to
+// uniquely identify the config during dependency injection.
+func NewEmailSender(
+	timer timer.Timer,
+	logger logger.Logger,
+	config SMTPConfig,
+) email.Retry {
+	sender := smtp.NewSender(
+		timer,
+		config.Host,
+		config.Port,
+		config.Username,
+		config.Password,
+		config.From,
+	)
+	return email.NewRetry(sender, logger, emailMaxAttempts, emailBackoff)
+}
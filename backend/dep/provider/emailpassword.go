@@ -0,0 +1,108 @@
+package provider
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/short-d/app/fw/crypto"
+	"github.com/short-d/app/fw/logger"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/adapter/bcrypt"
+	"github.com/short-d/short/backend/app/usecase/authenticator"
+	"github.com/short-d/short/backend/app/usecase/email"
+	"github.com/short-d/short/backend/app/usecase/emailpassword"
+	"github.com/short-d/short/backend/app/usecase/keygen"
+	"github.com/short-d/short/backend/app/usecase/ratelimit"
+	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/verification"
+	xbcrypt "golang.org/x/crypto/bcrypt"
+)
+
+const passwordResetTokenValidDuration = 30 * time.Minute
+
+// SignInRateLimit represents how many times each account can be attempted to
+// sign in with password, and how many sign ins can be attempted from each
+// client IP, within each window. Zero limit disables rate limiting.
+type SignInRateLimit struct {
+	Limit  int
+	Window time.Duration
+}
+
+// PasswordResetRateLimit represents how many password resets can be requested
+// for each email and from each client IP within each window. Zero limit
+// disables rate limiting.
+type PasswordResetRateLimit struct {
+	Limit  int
+	Window time.Duration
+}
+
+// PasswordPolicyConfig represents the minimum length of the passwords of local
+// accounts and the names of the character classes they have to contain.
+type PasswordPolicyConfig struct {
+	MinLength       int
+	RequiredClasses []string
+}
+
+// NewPasswordPolicy creates PasswordPolicy with PasswordPolicyConfig to
+// uniquely identify config during dependency injection.
+func NewPasswordPolicy(config PasswordPolicyConfig) (emailpassword.PasswordPolicy, error) {
+	return emailpassword.NewPasswordPolicy(config.MinLength, nonEmpty(config.RequiredClasses))
+}
+
+// NewEmailPasswordAccount creates Account with bcrypt password hasher and in
+// memory rate limiter dedicated to sign in attempts so that it doesn't share
+// limits with redirections.
+func NewEmailPasswordAccount(
+	keyGen keygen.KeyGenerator,
+	userRepo repository.User,
+	userPasswordRepo repository.UserPassword,
+	passwordPolicy emailpassword.PasswordPolicy,
+	authenticator authenticator.Authenticator,
+	emailVerifier verification.EmailVerifier,
+	timer timer.Timer,
+	rateLimit SignInRateLimit,
+) emailpassword.Account {
+	return emailpassword.NewAccount(
+		keyGen,
+		userRepo,
+		userPasswordRepo,
+		bcrypt.NewHasher(xbcrypt.DefaultCost),
+		passwordPolicy,
+		authenticator,
+		emailVerifier,
+		ratelimit.NewMemory(timer, rateLimit.Limit, rateLimit.Window),
+	)
+}
+
+// NewPasswordReset creates PasswordReset with bcrypt password hasher, in
+// memory rate limiter dedicated to password reset requests, and WebFrontendURL
+// to uniquely identify webFrontendURL during dependency injection.
+func NewPasswordReset(
+	tokenizer crypto.Tokenizer,
+	timer timer.Timer,
+	userRepo repository.User,
+	userPasswordRepo repository.UserPassword,
+	passwordPolicy emailpassword.PasswordPolicy,
+	emailSender email.Sender,
+	webFrontendURL WebFrontendURL,
+	rateLimit PasswordResetRateLimit,
+	logger logger.Logger,
+) (emailpassword.PasswordReset, error) {
+	frontendURL, err := url.Parse(string(webFrontendURL))
+	if err != nil {
+		return emailpassword.PasswordReset{}, err
+	}
+	return emailpassword.NewPasswordReset(
+		tokenizer,
+		timer,
+		userRepo,
+		userPasswordRepo,
+		bcrypt.NewHasher(xbcrypt.DefaultCost),
+		passwordPolicy,
+		emailSender,
+		*frontendURL,
+		passwordResetTokenValidDuration,
+		ratelimit.NewMemory(timer, rateLimit.Limit, rateLimit.Window),
+		logger,
+	), nil
+}
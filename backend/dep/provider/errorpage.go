@@ -0,0 +1,79 @@
+package provider
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/short-d/short/backend/app/adapter/routing/handle"
+	"github.com/short-d/short/backend/app/fw/filesystem"
+)
+
+// ErrorPageConfig represents the pages served when aliases are missing or
+// expired. Each page is either the path of an HTML template or an http(s) URL
+// to redirect users to. Empty page renders the default page, with the
+// branding when any branding value is set. Interstitial shows the users of
+// the short links expired within the grace window a page linking to the long
+// links instead of redirecting them.
+type ErrorPageConfig struct {
+	NotFound     string
+	Expired      string
+	Interstitial bool
+	ProductName  string
+	LogoURL      string
+	PrimaryColor string
+}
+
+// NewErrorPages loads ErrorPages with ErrorPageConfig to uniquely identify
+// the pages during dependency injection.
+func NewErrorPages(
+	fileSystem filesystem.FileSystem,
+	config ErrorPageConfig,
+) (handle.ErrorPages, error) {
+	notFound, err := newErrorPage(fileSystem, config.NotFound)
+	if err != nil {
+		return handle.ErrorPages{}, err
+	}
+
+	expired, err := newErrorPage(fileSystem, config.Expired)
+	if err != nil {
+		return handle.ErrorPages{}, err
+	}
+	return handle.ErrorPages{
+		NotFound:     notFound,
+		Expired:      expired,
+		Interstitial: config.Interstitial,
+		Branding:     newBranding(config),
+	}, nil
+}
+
+func newBranding(config ErrorPageConfig) *handle.Branding {
+	if config.ProductName == "" && config.LogoURL == "" && config.PrimaryColor == "" {
+		return nil
+	}
+
+	branding := handle.DefaultBranding
+	if config.ProductName != "" {
+		branding.ProductName = config.ProductName
+	}
+	if config.PrimaryColor != "" {
+		branding.PrimaryColor = config.PrimaryColor
+	}
+	branding.LogoURL = config.LogoURL
+	return &branding
+}
+
+func newErrorPage(fileSystem filesystem.FileSystem, page string) (handle.ErrorPage, error) {
+	if page == "" {
+		return handle.ErrorPage{}, nil
+	}
+
+	if strings.HasPrefix(page, "http://") || strings.HasPrefix(page, "https://") {
+		return handle.NewRedirectErrorPage(page), nil
+	}
+
+	content, err := fileSystem.ReadFile(page)
+	if err != nil {
+		return handle.ErrorPage{}, err
+	}
+	return handle.NewTemplateErrorPage(filepath.Base(page), string(content))
+}
@@ -0,0 +1,23 @@
+package provider
+
+import (
+	"github.com/short-d/short/backend/app/fw/filesystem"
+	"github.com/short-d/short/backend/app/usecase/featureflag"
+)
+
+// FeatureFlagConfigPath represents the location of feature flag config file.
+type FeatureFlagConfigPath string
+
+// NewFeatureFlagToggle creates OverrideToggle on top of ConfigToggle with
+// FeatureFlagConfigPath to uniquely identify configPath during dependency
+// injection.
+func NewFeatureFlagToggle(
+	fileSystem filesystem.FileSystem,
+	configPath FeatureFlagConfigPath,
+) (featureflag.OverrideToggle, error) {
+	configToggle, err := featureflag.NewConfigToggle(fileSystem, string(configPath))
+	if err != nil {
+		return featureflag.OverrideToggle{}, err
+	}
+	return featureflag.NewOverrideToggle(configToggle), nil
+}
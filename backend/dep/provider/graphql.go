@@ -3,10 +3,12 @@ package provider
 import (
 	"github.com/short-d/app/fw/graphql"
 	"github.com/short-d/app/fw/logger"
-	"github.com/short-d/app/fw/service"
 	"github.com/short-d/short/backend/app/adapter/gqlapi"
 	"github.com/short-d/short/backend/app/adapter/gqlapi/resolver"
+	"github.com/short-d/short/backend/app/fw/cors"
 	"github.com/short-d/short/backend/app/fw/filesystem"
+	"github.com/short-d/short/backend/app/fw/secheader"
+	"github.com/short-d/short/backend/app/fw/web"
 )
 
 // GraphQLSchemaPath represents the local of GraphQL schema.
@@ -21,18 +23,44 @@ func NewShortGraphQLAPI(
 	return gqlapi.NewShort(string(schemaPath), fileSystem, resolver)
 }
 
+// PersistedQueryLimit represents the maximum number of GraphQL queries
+// persisted in memory. Zero disables persisted queries.
+type PersistedQueryLimit int
+
+// NewPersistedQueryStore creates PersistedQueryStore with PersistedQueryLimit
+// to uniquely identify the limit during dependency injection. nil is returned
+// when persisted queries are disabled.
+func NewPersistedQueryStore(limit PersistedQueryLimit) gqlapi.PersistedQueryStore {
+	if limit <= 0 {
+		return nil
+	}
+	return gqlapi.NewPersistedQueryMemory(int(limit))
+}
+
 // GraphQLPath represents the path for GraphQL APIs.
 type GraphQLPath string
 
-// NewGraphQLService creates GraphQL service with GraphQLPath to uniquely
-// identify graphQLPath during dependency injection.
+// NewGraphQLService creates GraphQL service with GraphQLPath and
+// MaxRequestBodySize to uniquely identify constructor parameters during
+// dependency injection.
 func NewGraphQLService(
 	gqlPath GraphQLPath,
 	handler graphql.Handler,
 	webUI graphql.WebUI,
 	logger logger.Logger,
-) service.GraphQL {
-	return service.NewGraphQL(logger, string(gqlPath), handler, webUI)
+	corsPolicy cors.Policy,
+	headerPolicy secheader.Policy,
+	maxBodySize MaxRequestBodySize,
+) web.GraphQL {
+	return web.NewGraphQL(
+		logger,
+		string(gqlPath),
+		handler,
+		webUI,
+		corsPolicy,
+		headerPolicy,
+		int64(maxBodySize),
+	)
 }
 
 // GraphiQLDefaultQuery represents the default GraphQL query showing up in
@@ -0,0 +1,37 @@
+package provider
+
+import (
+	"time"
+
+	"github.com/short-d/app/fw/network"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/adapter/routing/handle"
+	"github.com/short-d/short/backend/app/usecase/ratelimit"
+	"github.com/short-d/short/backend/app/usecase/shortlink"
+)
+
+// GuestAttributionEnabled represents whether signed out users can create
+// short links attributed to their guest sessions.
+type GuestAttributionEnabled bool
+
+// GuestCreateCooldown represents the minimum interval signed out users need to
+// wait between creating short links. Zero disables the cooldown.
+type GuestCreateCooldown time.Duration
+
+// NewGuestAttribution creates GuestAttribution with GuestAttributionEnabled
+// and GuestCreateCooldown to uniquely identify enabled and cooldown during
+// dependency injection.
+func NewGuestAttribution(
+	enabled GuestAttributionEnabled,
+	guestSession shortlink.GuestSession,
+	cooldown GuestCreateCooldown,
+	timer timer.Timer,
+	network network.Network,
+) handle.GuestAttribution {
+	return handle.NewGuestAttribution(
+		bool(enabled),
+		guestSession,
+		ratelimit.NewCooldown(timer, time.Duration(cooldown)),
+		network,
+	)
+}
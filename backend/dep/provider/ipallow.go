@@ -0,0 +1,17 @@
+package provider
+
+import (
+	"github.com/short-d/app/fw/network"
+	"github.com/short-d/short/backend/app/fw/ipallow"
+)
+
+// AdminAllowedIPs represents the IP addresses or CIDR ranges of the clients
+// allowed to access admin endpoints, including the admin GraphQL fields. Empty
+// allows every client.
+type AdminAllowedIPs []string
+
+// NewAdminIPPolicy creates IP allowlist policy for admin endpoints with
+// AdminAllowedIPs to uniquely identify allowedIPs during dependency injection.
+func NewAdminIPPolicy(network network.Network, allowedIPs AdminAllowedIPs) (ipallow.Policy, error) {
+	return ipallow.NewPolicy(network, nonEmpty(allowedIPs))
+}
@@ -1,17 +1,153 @@
 package provider
 
 import (
+	"fmt"
+	"regexp"
+
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/fw/filesystem"
 	"github.com/short-d/short/backend/app/usecase/keygen"
+	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/validator"
 )
 
 // KeyGenBufferSize specifies the size of the local cache for fetched keys
 type KeyGenBufferSize int
 
-// NewKeyGenerator creates KeyGenerator with KeyGenBufferSize to uniquely identify
-// bufferSize
+// KeyGenBatchBounds specifies the range the number of keys fetched at a time
+// adapts within, following the demand. The batches stay at KeyGenBufferSize
+// when both bounds are zero.
+type KeyGenBatchBounds struct {
+	MinSize int
+	MaxSize int
+}
+
+// KeyGenStrategy specifies how keys are generated.
+type KeyGenStrategy string
+
+// HashidsSalt makes the keys generated by hashids strategy unique to the
+// deployment.
+type HashidsSalt string
+
+// KeyGenWordsConfig configures the keys generated by words strategy.
+type KeyGenWordsConfig struct {
+	WordListPath string
+	WordCount    int
+	Separator    string
+}
+
+// AliasPrefix represents the prefix of all auto generated aliases, such as
+// "eu-", so that the aliases generated by different deployments never collide
+// when their databases are merged. It can only contain ASCII letters, digits,
+// "-" and "_".
+type AliasPrefix string
+
+var aliasPrefixPattern = regexp.MustCompile(`^[A-Za-z0-9_-]*$`)
+
+// ValidateAliasPrefix checks that AliasPrefix only contains the allowed
+// characters and leaves room for the generated keys within the maximum length
+// of aliases.
+func ValidateAliasPrefix(prefix AliasPrefix) error {
+	if !aliasPrefixPattern.MatchString(string(prefix)) {
+		return fmt.Errorf("alias prefix %s can only contain ASCII letters, digits, - and _", prefix)
+	}
+
+	valid, violation := validator.NewCustomAlias().IsValidPrefix(string(prefix))
+	if !valid {
+		return fmt.Errorf("alias prefix %s is invalid: %s", prefix, violation)
+	}
+	return nil
+}
+
+// AliasKeyGenerator produces the aliases of short links, which can use
+// strategies only suitable for aliases.
+type AliasKeyGenerator keygen.KeyGenerator
+
+// NewRemoteKeyGenerator creates Remote key generator with KeyGenBufferSize to
+// uniquely identify bufferSize
+func NewRemoteKeyGenerator(
+	bufferSize KeyGenBufferSize,
+	keyFetcher keygen.KeyFetcher,
+) (keygen.Remote, error) {
+	return keygen.NewRemote(int(bufferSize), keyFetcher)
+}
+
+// NewAdaptiveKeyGenerator creates Remote key generator fetching
+// KeyGenBufferSize keys at first, adapting the following batches within
+// KeyGenBatchBounds when they are set.
+func NewAdaptiveKeyGenerator(
+	bufferSize KeyGenBufferSize,
+	batchBounds KeyGenBatchBounds,
+	timer timer.Timer,
+	keyFetcher keygen.KeyFetcher,
+) (keygen.Remote, error) {
+	if batchBounds == (KeyGenBatchBounds{}) {
+		return NewRemoteKeyGenerator(bufferSize, keyFetcher)
+	}
+	return keygen.NewAdaptiveRemote(
+		int(bufferSize),
+		batchBounds.MinSize,
+		batchBounds.MaxSize,
+		timer,
+		keyFetcher,
+	)
+}
+
+// NewKeyGenerator creates KeyGenerator of the given KeyGenStrategy.
 func NewKeyGenerator(
+	strategy KeyGenStrategy,
 	bufferSize KeyGenBufferSize,
+	batchBounds KeyGenBatchBounds,
+	timer timer.Timer,
 	keyFetcher keygen.KeyFetcher,
+	keyCounter repository.KeyCounter,
+	salt HashidsSalt,
 ) (keygen.KeyGenerator, error) {
-	return keygen.NewKeyGenerator(int(bufferSize), keyFetcher)
+	switch keygen.Strategy(strategy) {
+	case keygen.StrategyRandom:
+		return NewAdaptiveKeyGenerator(bufferSize, batchBounds, timer, keyFetcher)
+	case keygen.StrategySequential:
+		return keygen.NewSequential(keyCounter), nil
+	case keygen.StrategyHashids:
+		return keygen.NewHashids(keyCounter, string(salt)), nil
+	case keygen.StrategyWords, keygen.StrategyCryptoRandom:
+		// These strategies are only used for aliases. Other keys, such as user
+		// IDs, are still random.
+		return NewAdaptiveKeyGenerator(bufferSize, batchBounds, timer, keyFetcher)
+	default:
+		return nil, fmt.Errorf("unknown key generation strategy: %s", strategy)
+	}
+}
+
+// NewAliasKeyGenerator creates AliasKeyGenerator of the given KeyGenStrategy,
+// which is the same as KeyGenerator unless the strategy is words or
+// crypto_random. The keys start with AliasPrefix.
+func NewAliasKeyGenerator(
+	strategy KeyGenStrategy,
+	keyGen keygen.KeyGenerator,
+	shortLinkRepo repository.ShortLink,
+	fileSystem filesystem.FileSystem,
+	config KeyGenWordsConfig,
+	prefix AliasPrefix,
+) (AliasKeyGenerator, error) {
+	err := ValidateAliasPrefix(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	switch keygen.Strategy(strategy) {
+	case keygen.StrategyWords:
+		words, err := keygen.ReadWordList(fileSystem, config.WordListPath)
+		if err != nil {
+			return nil, err
+		}
+		return keygen.NewWords(shortLinkRepo, words, config.WordCount, config.Separator, string(prefix))
+	case keygen.StrategyCryptoRandom:
+		return keygen.NewCryptoRandom(shortLinkRepo, string(prefix)), nil
+	default:
+		if prefix == "" {
+			return keyGen, nil
+		}
+		return keygen.NewPrefixed(keyGen, string(prefix)), nil
+	}
 }
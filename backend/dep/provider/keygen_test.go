@@ -0,0 +1,176 @@
+// +build !integration all
+
+package provider
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/fw/filesystem"
+	"github.com/short-d/short/backend/app/usecase/keygen"
+	"github.com/short-d/short/backend/app/usecase/repository"
+)
+
+func TestNewKeyGenerator(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name               string
+		strategy           KeyGenStrategy
+		hasErr             bool
+		expectedKeyGenType keygen.KeyGenerator
+	}{
+		{
+			name:               "random",
+			strategy:           "random",
+			expectedKeyGenType: keygen.Remote{},
+		},
+		{
+			name:               "sequential",
+			strategy:           "sequential",
+			expectedKeyGenType: keygen.Sequential{},
+		},
+		{
+			name:               "hashids",
+			strategy:           "hashids",
+			expectedKeyGenType: keygen.Hashids{},
+		},
+		{
+			name:               "words keeps random keys for users",
+			strategy:           "words",
+			expectedKeyGenType: keygen.Remote{},
+		},
+		{
+			name:               "crypto random keeps random keys for users",
+			strategy:           "crypto_random",
+			expectedKeyGenType: keygen.Remote{},
+		},
+		{
+			name:     "unknown strategy",
+			strategy: "unknown",
+			hasErr:   true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			keyFetcher := keygen.NewKeyFetcherFake([]keygen.Key{"abc"})
+			keyCounter := repository.NewKeyCounterFake(0)
+			keyGen, err := NewKeyGenerator(
+				testCase.strategy,
+				1,
+				KeyGenBatchBounds{},
+				timer.NewStub(time.Now()),
+				&keyFetcher,
+				keyCounter,
+				"salt",
+			)
+			if testCase.hasErr {
+				assert.NotEqual(t, nil, err)
+				return
+			}
+			assert.Equal(t, nil, err)
+			assert.Equal(t, true, isSameType(testCase.expectedKeyGenType, keyGen))
+		})
+	}
+}
+
+func TestNewAliasKeyGenerator(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name               string
+		strategy           KeyGenStrategy
+		prefix             AliasPrefix
+		hasErr             bool
+		expectedKeyGenType keygen.KeyGenerator
+	}{
+		{
+			name:               "random",
+			strategy:           "random",
+			expectedKeyGenType: keygen.Sequential{},
+		},
+		{
+			name:               "random with prefix",
+			strategy:           "random",
+			prefix:             "eu-",
+			expectedKeyGenType: keygen.Prefixed{},
+		},
+		{
+			name:               "words with prefix",
+			strategy:           "words",
+			prefix:             "eu-",
+			expectedKeyGenType: keygen.Words{},
+		},
+		{
+			name:     "invalid prefix",
+			strategy: "random",
+			prefix:   "eu/",
+			hasErr:   true,
+		},
+		{
+			name:     "prefix too long",
+			strategy: "random",
+			prefix:   AliasPrefix(strings.Repeat("a", 49)),
+			hasErr:   true,
+		},
+		{
+			name:               "sequential",
+			strategy:           "sequential",
+			expectedKeyGenType: keygen.Sequential{},
+		},
+		{
+			name:               "words",
+			strategy:           "words",
+			expectedKeyGenType: keygen.Words{},
+		},
+		{
+			name:               "crypto random",
+			strategy:           "crypto_random",
+			expectedKeyGenType: keygen.CryptoRandom{},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			keyGen := keygen.NewSequential(repository.NewKeyCounterFake(0))
+			shortLinkRepo := repository.NewShortLinkFake(nil, map[string]entity.ShortLink{})
+			fileSystem := filesystem.NewFileSystemFake(map[string][]byte{
+				"words.txt": []byte("happy\nblue\ntiger\n"),
+			})
+			config := KeyGenWordsConfig{
+				WordListPath: "words.txt",
+				WordCount:    3,
+				Separator:    "-",
+			}
+
+			aliasKeyGen, err := NewAliasKeyGenerator(testCase.strategy, keyGen, &shortLinkRepo, fileSystem, config, testCase.prefix)
+			if testCase.hasErr {
+				assert.NotEqual(t, nil, err)
+				return
+			}
+			assert.Equal(t, nil, err)
+			assert.Equal(t, true, isSameType(testCase.expectedKeyGenType, aliasKeyGen))
+
+			key, err := aliasKeyGen.NewKey()
+			assert.Equal(t, nil, err)
+			assert.Equal(t, true, strings.HasPrefix(string(key), string(testCase.prefix)))
+			assert.NotEqual(t, keygen.Key(testCase.prefix), key)
+		})
+	}
+}
+
+func isSameType(expected keygen.KeyGenerator, actual keygen.KeyGenerator) bool {
+	return reflect.TypeOf(expected) == reflect.TypeOf(actual)
+}
@@ -1,6 +1,10 @@
 package provider
 
-import "github.com/short-d/short/backend/app/adapter/kgs"
+import (
+	"time"
+
+	"github.com/short-d/short/backend/app/adapter/kgs"
+)
 
 // KgsRPCConfig includes hostname and port for key generation service API
 type KgsRPCConfig struct {
@@ -8,7 +12,17 @@ type KgsRPCConfig struct {
 	Port     int
 }
 
+// KgsConnectRetry configures how connecting to key generation service is
+// retried on startup. The wait between attempts starts at InitialBackoff and
+// doubles up to MaxBackoff.
+type KgsConnectRetry struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	DialTimeout    time.Duration
+}
+
 // NewKgsRPC creates RPC
-func NewKgsRPC(config KgsRPCConfig) (kgs.RPC, error) {
-	return kgs.NewRPC(config.Hostname, config.Port)
+func NewKgsRPC(config KgsRPCConfig, retry KgsConnectRetry) (kgs.RPC, error) {
+	return kgs.NewRPC(config.Hostname, config.Port, kgs.ConnectRetry(retry))
 }
@@ -0,0 +1,61 @@
+package provider
+
+import (
+	"time"
+
+	"github.com/short-d/app/fw/logger"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/adapter/probe"
+	"github.com/short-d/short/backend/app/usecase/featureflag"
+	"github.com/short-d/short/backend/app/usecase/linkhealth"
+	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/webhook"
+)
+
+// LinkHealthConfig represents the configuration of the background checks on
+// long links.
+type LinkHealthConfig struct {
+	Interval         time.Duration
+	BatchSize        int
+	FailureThreshold int
+	ProbeTimeout     time.Duration
+}
+
+// NewLinkHealthProber creates HTTP prober with LinkHealthConfig to uniquely
+// identify config during dependency injection.
+func NewLinkHealthProber(config LinkHealthConfig, toggle featureflag.Toggle) probe.HTTP {
+	return probe.NewHTTP(config.ProbeTimeout, toggle)
+}
+
+// NewLinkHealthChecker creates Checker with LinkHealthConfig to uniquely
+// identify config during dependency injection.
+func NewLinkHealthChecker(
+	shortLinkRepo repository.ShortLink,
+	linkHealthRepo repository.LinkHealth,
+	prober linkhealth.Prober,
+	dispatcher webhook.Dispatcher,
+	timer timer.Timer,
+	config LinkHealthConfig,
+) linkhealth.Checker {
+	return linkhealth.NewChecker(
+		shortLinkRepo,
+		linkHealthRepo,
+		prober,
+		dispatcher,
+		timer,
+		config.BatchSize,
+		config.FailureThreshold,
+	)
+}
+
+// NewLinkHealthJob creates Job with LinkHealthConfig to uniquely identify
+// config during dependency injection.
+func NewLinkHealthJob(
+	checker linkhealth.Checker,
+	toggle featureflag.Toggle,
+	timer timer.Timer,
+	logger logger.Logger,
+	config LinkHealthConfig,
+) linkhealth.Job {
+	return linkhealth.NewJob(checker, toggle, timer, logger, config.Interval)
+}
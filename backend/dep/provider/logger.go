@@ -1,6 +1,8 @@
 package provider
 
 import (
+	"fmt"
+
 	"github.com/short-d/app/fw/io"
 	"github.com/short-d/app/fw/logger"
 	"github.com/short-d/app/fw/runtime"
@@ -12,16 +14,35 @@ import (
 // [Short] [Info] 2020-01-07 04:33:22 line 25 at service.go GraphQL API started
 type LogPrefix string
 
-// NewLogger creates logger with LogPrefix to uniquely identify log prefix
-// during dependency injection.
+// LogLevel represents the lowest level of the messages logged, which is one
+// of debug, info, warn and error. Messages below the level are suppressed.
+type LogLevel string
+
+var logLevels = map[LogLevel]logger.LogLevel{
+	"debug": logger.LogDebug,
+	"info":  logger.LogInfo,
+	"warn":  logger.LogWarn,
+	"error": logger.LogError,
+}
+
+// NewLogger creates logger with LogPrefix and LogLevel to uniquely identify
+// log prefix and level during dependency injection. Empty level defaults to
+// info.
 func NewLogger(
 	prefix LogPrefix,
-	level logger.LogLevel,
+	level LogLevel,
 	timer timer.Timer,
 	programRuntime runtime.Program,
 	entryRepo logger.EntryRepository,
-) logger.Logger {
-	return logger.NewLogger(string(prefix), level, timer, programRuntime, entryRepo)
+) (logger.Logger, error) {
+	if level == "" {
+		level = "info"
+	}
+	logLevel, ok := logLevels[level]
+	if !ok {
+		return logger.Logger{}, fmt.Errorf("unknown log level: %s", level)
+	}
+	return logger.NewLogger(string(prefix), logLevel, timer, programRuntime, entryRepo), nil
 }
 
 // NewLocalEntryRepo create LocalEntryRepo with line number disabled in logs.
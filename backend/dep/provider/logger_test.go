@@ -0,0 +1,104 @@
+// +build !integration all
+
+package provider
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/logger"
+	"github.com/short-d/app/fw/runtime"
+	"github.com/short-d/app/fw/timer"
+)
+
+func TestNewLogger(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name           string
+		level          LogLevel
+		hasErr         bool
+		expectedLevels []logger.LogLevel
+	}{
+		{
+			name:  "debug",
+			level: "debug",
+			expectedLevels: []logger.LogLevel{
+				logger.LogDebug,
+				logger.LogInfo,
+				logger.LogWarn,
+				logger.LogError,
+			},
+		},
+		{
+			name:  "info",
+			level: "info",
+			expectedLevels: []logger.LogLevel{
+				logger.LogInfo,
+				logger.LogWarn,
+				logger.LogError,
+			},
+		},
+		{
+			name:  "default to info",
+			level: "",
+			expectedLevels: []logger.LogLevel{
+				logger.LogInfo,
+				logger.LogWarn,
+				logger.LogError,
+			},
+		},
+		{
+			name:  "warn",
+			level: "warn",
+			expectedLevels: []logger.LogLevel{
+				logger.LogWarn,
+				logger.LogError,
+			},
+		},
+		{
+			name:           "error",
+			level:          "error",
+			expectedLevels: []logger.LogLevel{logger.LogError},
+		},
+		{
+			name:   "unknown level",
+			level:  "verbose",
+			hasErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			entryRepo := logger.NewEntryRepoFake()
+			lg, err := NewLogger(
+				"Short",
+				testCase.level,
+				timer.NewStub(time.Now()),
+				runtime.NewProgram(),
+				&entryRepo,
+			)
+			if testCase.hasErr {
+				assert.NotEqual(t, nil, err)
+				return
+			}
+			assert.Equal(t, nil, err)
+
+			lg.Debug("debug")
+			lg.Info("info")
+			lg.Warn("warn")
+			lg.Error(errors.New("error"))
+
+			var levels []logger.LogLevel
+			for _, entry := range entryRepo.GetEntries() {
+				levels = append(levels, entry.Level)
+			}
+			assert.Equal(t, testCase.expectedLevels, levels)
+		})
+	}
+}
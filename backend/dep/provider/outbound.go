@@ -0,0 +1,28 @@
+package provider
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/short-d/short/backend/app/fw/outbound"
+)
+
+// OutboundHTTPLimit represents the maximum number of simultaneous outbound
+// HTTP calls of the deployment, and how long the calls wait for a free slot.
+type OutboundHTTPLimit struct {
+	MaxConcurrency int
+	WaitTimeout    time.Duration
+}
+
+// NewOutboundLimiter creates Limiter with OutboundHTTPLimit to uniquely
+// identify limit during dependency injection. The limiter is shared by the
+// services so that the limit applies to the whole deployment.
+func NewOutboundLimiter(limit OutboundHTTPLimit) outbound.Limiter {
+	return outbound.NewLimiter(limit.MaxConcurrency, limit.WaitTimeout)
+}
+
+// NewOutboundHTTPClient creates HTTP client which sends at most as many
+// simultaneous outbound calls as limiter allows.
+func NewOutboundHTTPClient(limiter outbound.Limiter) http.Client {
+	return http.Client{Transport: limiter.Transport(http.DefaultTransport)}
+}
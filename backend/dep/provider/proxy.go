@@ -0,0 +1,16 @@
+package provider
+
+import "github.com/short-d/short/backend/app/fw/proxy"
+
+// TrustedProxies represents the IP addresses or CIDR ranges of the proxies
+// allowed to report client IP addresses through forwarding headers. Only
+// loopback addresses are trusted by default. Deployments behind load
+// balancers on other hosts opt in by listing the addresses of the load
+// balancers.
+type TrustedProxies []string
+
+// NewTrustedProxy creates Trusted with TrustedProxies to uniquely identify
+// proxies during dependency injection.
+func NewTrustedProxy(proxies TrustedProxies) (proxy.Trusted, error) {
+	return proxy.NewTrusted(nonEmpty(proxies))
+}
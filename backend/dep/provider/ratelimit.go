@@ -0,0 +1,41 @@
+package provider
+
+import (
+	"time"
+
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/usecase/ratelimit"
+)
+
+// RedirectRateLimit represents how many times a client can be redirected
+// through the same alias within each window. Zero limit disables rate limiting.
+type RedirectRateLimit struct {
+	Limit  int
+	Window time.Duration
+}
+
+// NewRedirectRateLimiter creates in memory rate limiter with RedirectRateLimit
+// to uniquely identify the limits during dependency injection.
+func NewRedirectRateLimiter(
+	timer timer.Timer,
+	rateLimit RedirectRateLimit,
+) ratelimit.Memory {
+	return ratelimit.NewMemory(timer, rateLimit.Limit, rateLimit.Window)
+}
+
+// LinkRateLimitWindow represents the window in which the redirects per client
+// IP are limited for the short links setting the limit.
+type LinkRateLimitWindow time.Duration
+
+// LinkRateLimiter limits the redirects per client IP up to the limit set by
+// each short link.
+type LinkRateLimiter ratelimit.VariableLimiter
+
+// NewLinkRateLimiter creates in memory rate limiter with LinkRateLimitWindow
+// to uniquely identify the window during dependency injection.
+func NewLinkRateLimiter(
+	timer timer.Timer,
+	window LinkRateLimitWindow,
+) LinkRateLimiter {
+	return ratelimit.NewMemory(timer, 0, time.Duration(window))
+}
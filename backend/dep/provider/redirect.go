@@ -0,0 +1,32 @@
+package provider
+
+import (
+	"time"
+
+	"github.com/short-d/short/backend/app/adapter/routing/handle"
+)
+
+// RedirectConfig represents the status code of the redirects to long links
+// and how long browsers may cache the permanent redirects when short links
+// can't be edited.
+type RedirectConfig struct {
+	StatusCode    int
+	MaxAge        time.Duration
+	EditableLinks bool
+}
+
+// NewRedirect creates Redirect with RedirectConfig to uniquely identify config
+// during dependency injection.
+func NewRedirect(config RedirectConfig) (handle.Redirect, error) {
+	return handle.NewRedirect(config.StatusCode, config.MaxAge, config.EditableLinks)
+}
+
+// DomainRedirects represents the aliased domains of the short links and the
+// canonical URLs they are redirected to, formatted as domain=canonical URL.
+type DomainRedirects []string
+
+// NewCanonicalDomain creates CanonicalDomain with DomainRedirects to uniquely
+// identify redirects during dependency injection.
+func NewCanonicalDomain(redirects DomainRedirects) (handle.CanonicalDomain, error) {
+	return handle.NewCanonicalDomain(nonEmpty(redirects))
+}
@@ -0,0 +1,65 @@
+package provider
+
+import (
+	"time"
+
+	"github.com/short-d/app/fw/logger"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/adapter/google"
+	"github.com/short-d/short/backend/app/fw/filesystem"
+	"github.com/short-d/short/backend/app/usecase/authorizer"
+	"github.com/short-d/short/backend/app/usecase/risk"
+)
+
+// RiskThresholds represents the risk scores, ranging from 0 to 100, at which
+// long links are flagged for review or rejected.
+type RiskThresholds struct {
+	Block int
+	Warn  int
+}
+
+// RiskCircuitBreakerConfig represents the number of consecutive failures of the
+// blacklist before its calls are skipped, and how long they are skipped for.
+type RiskCircuitBreakerConfig struct {
+	FailureThreshold int
+	Cooldown         time.Duration
+}
+
+// DomainDenylistPath represents the location of the file listing the domains
+// long links can't be on.
+type DomainDenylistPath string
+
+// NewRiskDetector creates Detector with RiskThresholds to uniquely identify
+// thresholds during dependency injection.
+func NewRiskDetector(
+	blacklist risk.BlackList,
+	denylist risk.Denylist,
+	thresholds RiskThresholds,
+) risk.Detector {
+	return risk.NewDetector(blacklist, denylist, risk.Thresholds{
+		Block: risk.Score(thresholds.Block),
+		Warn:  risk.Score(thresholds.Warn),
+	})
+}
+
+// NewRiskCircuitBreaker creates CircuitBreaker around Google Safe Browsing with
+// RiskCircuitBreakerConfig to uniquely identify the config during dependency
+// injection.
+func NewRiskCircuitBreaker(
+	safeBrowsing google.SafeBrowsing,
+	timer timer.Timer,
+	logger logger.Logger,
+	config RiskCircuitBreakerConfig,
+) risk.CircuitBreaker {
+	return risk.NewCircuitBreaker(safeBrowsing, timer, logger, config.FailureThreshold, config.Cooldown)
+}
+
+// NewDomainDenylist creates DomainDenylist with DomainDenylistPath to uniquely
+// identify path during dependency injection.
+func NewDomainDenylist(
+	fileSystem filesystem.FileSystem,
+	path DomainDenylistPath,
+	authorizer authorizer.Authorizer,
+) (risk.DomainDenylist, error) {
+	return risk.NewDomainDenylist(fileSystem, string(path), authorizer)
+}
@@ -1,17 +1,32 @@
 package provider
 
 import (
+	"github.com/short-d/app/fw/network"
 	"github.com/short-d/app/fw/router"
 	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/adapter/apple"
 	"github.com/short-d/short/backend/app/adapter/facebook"
 	"github.com/short-d/short/backend/app/adapter/github"
 	"github.com/short-d/short/backend/app/adapter/google"
 	"github.com/short-d/short/backend/app/adapter/request"
 	"github.com/short-d/short/backend/app/adapter/routing"
+	"github.com/short-d/short/backend/app/adapter/routing/handle"
+	"github.com/short-d/short/backend/app/adapter/twitter"
+	"github.com/short-d/short/backend/app/fw/buildinfo"
+	"github.com/short-d/short/backend/app/fw/ipallow"
+	"github.com/short-d/short/backend/app/fw/tenant"
 	"github.com/short-d/short/backend/app/usecase/authenticator"
+	"github.com/short-d/short/backend/app/usecase/authorizer"
+	"github.com/short-d/short/backend/app/usecase/emailpassword"
 	"github.com/short-d/short/backend/app/usecase/feature"
+	"github.com/short-d/short/backend/app/usecase/featureflag"
+	"github.com/short-d/short/backend/app/usecase/ratelimit"
 	"github.com/short-d/short/backend/app/usecase/search"
+	"github.com/short-d/short/backend/app/usecase/share"
 	"github.com/short-d/short/backend/app/usecase/shortlink"
+	"github.com/short-d/short/backend/app/usecase/stats"
+	"github.com/short-d/short/backend/app/usecase/verification"
+	"github.com/short-d/short/backend/app/usecase/visit"
 )
 
 // WebFrontendURL represents the URL of the web frontend
@@ -23,33 +38,85 @@ type SwaggerUIDir string
 // OpenAPISpecPath represents the location of OpenAPI specification.
 type OpenAPISpecPath string
 
+// ProfilingEnabled decides whether the runtime profiles of the service are
+// served to admins.
+type ProfilingEnabled bool
+
 // NewShortRoutes creates HTTP routes for Short API with WwwRoot to uniquely identify WwwRoot during dependency injection.
 func NewShortRoutes(
 	instrumentationFactory request.InstrumentationFactory,
 	webFrontendURL WebFrontendURL,
 	timer timer.Timer,
 	shortLinkRetriever shortlink.Retriever,
+	shortLinkCreator shortlink.Creator,
+	visitTracker visit.Tracker,
+	network network.Network,
+	redirectRateLimiter ratelimit.Limiter,
+	linkRateLimiter LinkRateLimiter,
 	featureDecisionMakerFactory feature.DecisionMakerFactory,
 	githubSSO github.SingleSignOn,
 	facebookSSO facebook.SingleSignOn,
 	googleSSO google.SingleSignOn,
+	twitterSSO twitter.SingleSignOn,
+	appleSSO apple.SingleSignOn,
 	authenticator authenticator.Authenticator,
 	search search.Search,
 	swaggerUIDir SwaggerUIDir,
 	openAPISpecPath OpenAPISpecPath,
+	errorPages handle.ErrorPages,
+	guestAttribution handle.GuestAttribution,
+	emailVerifier verification.EmailVerifier,
+	emailPasswordAccount emailpassword.Account,
+	passwordReset emailpassword.PasswordReset,
+	shortLinkShare share.Share,
+	authorizer authorizer.Authorizer,
+	profilingEnabled ProfilingEnabled,
+	aliasSigner share.Signer,
+	adminPolicy ipallow.Policy,
+	queryConflict shortlink.QueryConflict,
+	redirect handle.Redirect,
+	canonicalDomain handle.CanonicalDomain,
+	serviceStats stats.Service,
+	buildInfo buildinfo.Provider,
+	featureToggle featureflag.Toggle,
+	tenantHosts tenant.Hosts,
 ) []router.Route {
 	return routing.NewShort(
 		instrumentationFactory,
 		string(webFrontendURL),
 		timer,
 		shortLinkRetriever,
+		shortLinkCreator,
+		visitTracker,
+		network,
+		redirectRateLimiter,
+		linkRateLimiter,
 		featureDecisionMakerFactory,
 		githubSSO,
 		facebookSSO,
 		googleSSO,
+		twitterSSO,
+		appleSSO,
 		authenticator,
 		search,
 		string(swaggerUIDir),
 		string(openAPISpecPath),
+		errorPages,
+		guestAttribution,
+		emailVerifier,
+		emailPasswordAccount,
+		passwordReset,
+		shortLinkShare,
+		authorizer,
+		bool(profilingEnabled),
+		aliasSigner,
+		adminPolicy,
+		queryConflict,
+		redirect,
+		canonicalDomain,
+		serviceStats,
+		buildInfo,
+		featureToggle,
+		tenantHosts,
 	)
 }
@@ -0,0 +1,22 @@
+package provider
+
+import "github.com/short-d/short/backend/app/fw/secheader"
+
+// SecurityHeaderConfig represents the security headers attached to the
+// responses of Short APIs. Empty policies fall back to safe defaults.
+type SecurityHeaderConfig struct {
+	ContentSecurityPolicy  string
+	ReferrerPolicy         string
+	RedirectReferrerPolicy string
+}
+
+// NewSecurityHeaderPolicy creates security header policy with
+// SecurityHeaderConfig to uniquely identify the config during dependency
+// injection.
+func NewSecurityHeaderPolicy(config SecurityHeaderConfig) secheader.Policy {
+	return secheader.NewPolicy(
+		config.ContentSecurityPolicy,
+		config.ReferrerPolicy,
+		config.RedirectReferrerPolicy,
+	)
+}
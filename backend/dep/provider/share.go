@@ -0,0 +1,57 @@
+package provider
+
+import (
+	"net/url"
+	"path"
+
+	"github.com/short-d/short/backend/app/usecase/share"
+	"github.com/short-d/short/backend/app/usecase/shortlink"
+)
+
+// ShortLinkBaseURL represents the URL the aliases are appended to when
+// building the full URLs of short links, such as https://s.example.com. The
+// redirect route of the web frontend is used when it is empty.
+type ShortLinkBaseURL string
+
+// ShareURLSecret represents the per-deployment secret used to sign the aliases
+// of short links. Aliases are left unsigned when it is empty.
+type ShareURLSecret string
+
+// NewAliasSigner creates Signer with ShareURLSecret to uniquely identify it
+// during dependency injection.
+func NewAliasSigner(shareURLSecret ShareURLSecret) share.Signer {
+	return share.NewSigner(string(shareURLSecret))
+}
+
+// NewShare creates Share with WebFrontendURL and ShortLinkBaseURL to uniquely
+// identify them during dependency injection.
+func NewShare(
+	webFrontendURL WebFrontendURL,
+	shortLinkBaseURL ShortLinkBaseURL,
+	qrCodeGenerator share.QRCodeGenerator,
+	metaTag shortlink.MetaTag,
+	aliasSigner share.Signer,
+) (share.Share, error) {
+	baseURL, err := newShortLinkBaseURL(webFrontendURL, shortLinkBaseURL)
+	if err != nil {
+		return share.Share{}, err
+	}
+	return share.NewShare(baseURL, qrCodeGenerator, metaTag, aliasSigner), nil
+}
+
+func newShortLinkBaseURL(webFrontendURL WebFrontendURL, shortLinkBaseURL ShortLinkBaseURL) (url.URL, error) {
+	if shortLinkBaseURL != "" {
+		baseURL, err := url.Parse(string(shortLinkBaseURL))
+		if err != nil {
+			return url.URL{}, err
+		}
+		return *baseURL, nil
+	}
+
+	frontendURL, err := url.Parse(string(webFrontendURL))
+	if err != nil {
+		return url.URL{}, err
+	}
+	frontendURL.Path = path.Join("/", frontendURL.Path, "r")
+	return *frontendURL, nil
+}
@@ -0,0 +1,65 @@
+// +build !integration all
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/share"
+	"github.com/short-d/short/backend/app/usecase/shortlink"
+)
+
+func TestNewShare(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name             string
+		webFrontendURL   WebFrontendURL
+		shortLinkBaseURL ShortLinkBaseURL
+		expectedURL      string
+	}{
+		{
+			name:           "default to redirect route of frontend",
+			webFrontendURL: "https://short-d.com",
+			expectedURL:    "https://short-d.com/r/220uFicCJj",
+		},
+		{
+			name:           "frontend with path and trailing slash",
+			webFrontendURL: "http://localhost:3000/app/",
+			expectedURL:    "http://localhost:3000/app/r/220uFicCJj",
+		},
+		{
+			name:             "configured base URL",
+			webFrontendURL:   "https://short-d.com",
+			shortLinkBaseURL: "https://s.short-d.com",
+			expectedURL:      "https://s.short-d.com/220uFicCJj",
+		},
+		{
+			name:             "configured base URL with trailing slash",
+			webFrontendURL:   "https://short-d.com",
+			shortLinkBaseURL: "https://s.short-d.com/go/",
+			expectedURL:      "https://s.short-d.com/go/220uFicCJj",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			shortLinkRepo := repository.NewShortLinkFake(nil, map[string]entity.ShortLink{})
+			shortLinkShare, err := NewShare(
+				testCase.webFrontendURL,
+				testCase.shortLinkBaseURL,
+				share.NewQRCodeGeneratorFake(),
+				shortlink.NewMetaTagPersist(&shortLinkRepo),
+				share.Signer{},
+			)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedURL, shortLinkShare.ShortLinkURL("220uFicCJj"))
+		})
+	}
+}
@@ -0,0 +1,277 @@
+package provider
+
+import (
+	"errors"
+	"time"
+
+	"github.com/short-d/app/fw/metrics"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/usecase/authorizer"
+	"github.com/short-d/short/backend/app/usecase/email"
+	"github.com/short-d/short/backend/app/usecase/maintenance"
+	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/risk"
+	"github.com/short-d/short/backend/app/usecase/shortlink"
+	"github.com/short-d/short/backend/app/usecase/validator"
+	"github.com/short-d/short/backend/app/usecase/webhook"
+)
+
+// ShortLinkChecks represents the names of the checks run in order before
+// short links are created.
+type ShortLinkChecks []string
+
+// LongLinkUniqueness represents the name of the mode deciding whether multiple
+// short links can redirect to the same long link.
+type LongLinkUniqueness string
+
+// AliasQuota represents the maximum number of active short links a user can
+// own with custom aliases and auto generated aliases. Zero means unlimited.
+type AliasQuota struct {
+	CustomAlias int
+	AutoAlias   int
+}
+
+// AliasRetryBudget represents the maximum number of times another alias is
+// generated when the previous one is taken.
+type AliasRetryBudget int
+
+// RelationRetry configures how attributing new short links to their creators
+// is retried before the short links are rolled back. The wait between
+// attempts starts at Backoff and doubles after each failure.
+type RelationRetry struct {
+	MaxAttempts int
+	Backoff     time.Duration
+}
+
+// AliasReservationTTL represents how long custom aliases stay reserved for
+// the users before the short links are created under them. Default applies
+// when the users don't ask for a TTL, while Max bounds the TTL asked for.
+type AliasReservationTTL struct {
+	Default time.Duration
+	Max     time.Duration
+}
+
+// ShortLinkLifetime represents how long the short links created without
+// expiration time stay active. RoleLifetimes lists the lifetimes of roles in
+// the form of role=duration, which override Lifetime for the users with these
+// roles. Zero means the short links never expire.
+type ShortLinkLifetime struct {
+	Lifetime      time.Duration
+	RoleLifetimes []string
+}
+
+// ShortLinkExpiryGrace represents how long the expired short links keep
+// redirecting users with a warning. Zero stops redirecting users as soon as
+// the short links expire.
+type ShortLinkExpiryGrace time.Duration
+
+// URLValidationWorkers represents the maximum number of URLs checked at the
+// same time when validating URLs in batch.
+type URLValidationWorkers int
+
+// ChainedLinkConfig represents how long links which are short links
+// themselves are handled. Mode names whether they are rejected or resolved to
+// their destinations. The short links of other URL shorteners are recognized
+// by ShortenerDomains and followed through at most MaxRedirects redirects.
+type ChainedLinkConfig struct {
+	Mode             string
+	ShortenerDomains []string
+	MaxRedirects     int
+}
+
+// QueryConflict represents the name of the way to resolve the query parameters
+// passed through to long links which are already in the long links.
+type QueryConflict string
+
+// NewShortLinkCreator creates CreatorPersist with ShortLinkChecks,
+// LongLinkUniqueness, AliasQuota, AliasRetryBudget, ShortLinkLifetime,
+// RelationRetry, AliasReservationTTL and ChainedLinkConfig to uniquely
+// identify checks, uniqueness mode, quota, retry budget, default lifetimes,
+// relation retry, reservation TTL and chained link handling during dependency
+// injection.
+func NewShortLinkCreator(
+	shortLinkRepo repository.ShortLink,
+	userShortLinkRepo repository.UserShortLink,
+	keyGen AliasKeyGenerator,
+	longLinkValidator validator.LongLink,
+	aliasValidator validator.CustomAlias,
+	timer timer.Timer,
+	riskDetector risk.Detector,
+	flaggedLinkRepo repository.FlaggedShortLink,
+	checkNames ShortLinkChecks,
+	uniquenessName LongLinkUniqueness,
+	aliasQuota AliasQuota,
+	authorizer authorizer.Authorizer,
+	emailSender email.Sender,
+	dispatcher webhook.Dispatcher,
+	preferencesRepo repository.UserPreferences,
+	maintenanceMode maintenance.Mode,
+	metrics metrics.Metrics,
+	aliasRetryBudget AliasRetryBudget,
+	userRoleRepo repository.UserRole,
+	lifetime ShortLinkLifetime,
+	aliasSkeletonRepo repository.AliasSkeleton,
+	relationRetry RelationRetry,
+	reservationRepo repository.AliasReservation,
+	reservationTTL AliasReservationTTL,
+	chainedLinkConfig ChainedLinkConfig,
+	redirectFollower shortlink.RedirectFollower,
+) (shortlink.CreatorPersist, error) {
+	if aliasRetryBudget < 0 {
+		return shortlink.CreatorPersist{}, errors.New("alias retry budget can't be negative")
+	}
+	if relationRetry.MaxAttempts < 1 {
+		return shortlink.CreatorPersist{}, errors.New("relation max attempts must be positive")
+	}
+	if relationRetry.Backoff < 0 {
+		return shortlink.CreatorPersist{}, errors.New("relation backoff can't be negative")
+	}
+	if reservationTTL.Default <= 0 {
+		return shortlink.CreatorPersist{}, errors.New("alias reservation TTL must be positive")
+	}
+	if reservationTTL.Max < reservationTTL.Default {
+		return shortlink.CreatorPersist{}, errors.New("alias reservation max TTL can't be shorter than default")
+	}
+	if lifetime.Lifetime < 0 {
+		return shortlink.CreatorPersist{}, errors.New("short link lifetime can't be negative")
+	}
+	roleLifetimes, err := shortlink.ParseRoleLifetimes(nonEmpty(lifetime.RoleLifetimes))
+	if err != nil {
+		return shortlink.CreatorPersist{}, err
+	}
+	checks, err := shortlink.ParseChecks(nonEmpty(checkNames))
+	if err != nil {
+		return shortlink.CreatorPersist{}, err
+	}
+	uniqueness, err := shortlink.ParseLongLinkUniqueness(string(uniquenessName))
+	if err != nil {
+		return shortlink.CreatorPersist{}, err
+	}
+	chainedLink, err := newChainedLink(chainedLinkConfig, redirectFollower)
+	if err != nil {
+		return shortlink.CreatorPersist{}, err
+	}
+	return shortlink.NewCreatorPersist(
+		shortLinkRepo,
+		userShortLinkRepo,
+		keyGen,
+		longLinkValidator,
+		aliasValidator,
+		timer,
+		riskDetector,
+		flaggedLinkRepo,
+		checks,
+		uniqueness,
+		shortlink.AliasQuota{
+			CustomAlias: aliasQuota.CustomAlias,
+			AutoAlias:   aliasQuota.AutoAlias,
+		},
+		authorizer,
+		emailSender,
+		dispatcher,
+		preferencesRepo,
+		maintenanceMode,
+		metrics,
+		int(aliasRetryBudget),
+		shortlink.NewExpirationPolicy(userRoleRepo, lifetime.Lifetime, roleLifetimes),
+		aliasSkeletonRepo,
+		shortlink.RelationRetry(relationRetry),
+		reservationRepo,
+		shortlink.ReservationTTL(reservationTTL),
+		chainedLink,
+	), nil
+}
+
+func newChainedLink(config ChainedLinkConfig, redirectFollower shortlink.RedirectFollower) (shortlink.ChainedLink, error) {
+	if config.MaxRedirects < 1 {
+		return shortlink.ChainedLink{}, errors.New("chained link max redirects must be positive")
+	}
+	mode, err := shortlink.ParseChainedLinkMode(config.Mode)
+	if err != nil {
+		return shortlink.ChainedLink{}, err
+	}
+	return shortlink.NewChainedLink(
+		mode,
+		config.ShortenerDomains,
+		config.MaxRedirects,
+		redirectFollower,
+	), nil
+}
+
+// NewShortLinkUpdater creates UpdaterPersist with ShortLinkChecks and
+// ChainedLinkConfig to uniquely identify checks and chained link handling
+// during dependency injection, so that updated short links are handled the
+// same way as new ones.
+func NewShortLinkUpdater(
+	shortLinkRepo repository.ShortLink,
+	userShortLinkRepo repository.UserShortLink,
+	longLinkValidator validator.LongLink,
+	aliasValidator validator.CustomAlias,
+	timer timer.Timer,
+	riskDetector risk.Detector,
+	checkNames ShortLinkChecks,
+	aliasSkeletonRepo repository.AliasSkeleton,
+	flaggedLinkRepo repository.FlaggedShortLink,
+	emailSender email.Sender,
+	maintenanceMode maintenance.Mode,
+	reservationRepo repository.AliasReservation,
+	chainedLinkConfig ChainedLinkConfig,
+	redirectFollower shortlink.RedirectFollower,
+) (shortlink.UpdaterPersist, error) {
+	checks, err := shortlink.ParseChecks(nonEmpty(checkNames))
+	if err != nil {
+		return shortlink.UpdaterPersist{}, err
+	}
+	chainedLink, err := newChainedLink(chainedLinkConfig, redirectFollower)
+	if err != nil {
+		return shortlink.UpdaterPersist{}, err
+	}
+	return shortlink.NewUpdaterPersist(
+		shortLinkRepo,
+		userShortLinkRepo,
+		longLinkValidator,
+		aliasValidator,
+		timer,
+		riskDetector,
+		checks,
+		aliasSkeletonRepo,
+		flaggedLinkRepo,
+		emailSender,
+		maintenanceMode,
+		reservationRepo,
+		chainedLink,
+	), nil
+}
+
+// NewShortLinkRetriever creates RetrieverPersist with ShortLinkExpiryGrace to
+// uniquely identify the grace window during dependency injection.
+func NewShortLinkRetriever(
+	shortLinkRepo repository.ShortLink,
+	userShortLinkRepo repository.UserShortLink,
+	expiryGrace ShortLinkExpiryGrace,
+) (shortlink.RetrieverPersist, error) {
+	if expiryGrace < 0 {
+		return shortlink.RetrieverPersist{}, errors.New("short link expiry grace can't be negative")
+	}
+	return shortlink.NewRetrieverPersist(
+		shortLinkRepo,
+		userShortLinkRepo,
+		time.Duration(expiryGrace),
+	), nil
+}
+
+// NewURLValidator creates URLValidatorConcurrent with URLValidationWorkers to
+// uniquely identify the number of workers during dependency injection.
+func NewURLValidator(
+	longLinkValidator validator.LongLink,
+	riskDetector risk.Detector,
+	workers URLValidationWorkers,
+) shortlink.URLValidatorConcurrent {
+	return shortlink.NewURLValidatorConcurrent(longLinkValidator, riskDetector, int(workers))
+}
+
+// NewQueryConflict creates QueryConflict with QueryConflict to uniquely
+// identify the name of the resolution during dependency injection.
+func NewQueryConflict(name QueryConflict) (shortlink.QueryConflict, error) {
+	return shortlink.ParseQueryConflict(string(name))
+}
@@ -0,0 +1,13 @@
+package provider
+
+import "github.com/short-d/short/backend/app/fw/tenant"
+
+// TenantHosts represents the hosts of the organizations sharing the
+// deployment and their tenants, formatted as host=tenant.
+type TenantHosts []string
+
+// NewTenantHosts creates Hosts with TenantHosts to uniquely identify hosts
+// during dependency injection.
+func NewTenantHosts(hosts TenantHosts) (tenant.Hosts, error) {
+	return tenant.NewHosts(nonEmpty(hosts))
+}
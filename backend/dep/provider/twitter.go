@@ -0,0 +1,52 @@
+package provider
+
+import (
+	"github.com/short-d/app/fw/webreq"
+	"github.com/short-d/short/backend/app/adapter/sqldb"
+	"github.com/short-d/short/backend/app/adapter/twitter"
+	"github.com/short-d/short/backend/app/usecase/sso"
+)
+
+// TwitterClientID represents client ID used for Twitter OAuth.
+type TwitterClientID string
+
+// TwitterClientSecret represents client secret used for Twitter OAuth.
+type TwitterClientSecret string
+
+// TwitterRedirectURI represents redirect URL for twitter single sign on.
+type TwitterRedirectURI string
+
+// NewTwitterIdentityProvider creates a new Twitter OAuth client with
+// TwitterClientID and TwitterClientSecret to uniquely identify clientID and
+// clientSecret during dependency injection.
+func NewTwitterIdentityProvider(
+	req webreq.HTTP,
+	clientID TwitterClientID,
+	clientSecret TwitterClientSecret,
+	redirectURI TwitterRedirectURI,
+) twitter.IdentityProvider {
+	return twitter.NewIdentityProvider(req, string(clientID), string(clientSecret), string(redirectURI))
+}
+
+// NewTwitterAccountLinker creates TwitterAccountLinker.
+func NewTwitterAccountLinker(
+	factory sso.AccountLinkerFactory,
+	twitterSSORepo sqldb.TwitterSSOSql,
+) twitter.AccountLinker {
+	return twitter.AccountLinker(factory.NewAccountLinker(twitterSSORepo))
+}
+
+// NewTwitterSSO creates TwitterSingleSignOn.
+func NewTwitterSSO(
+	ssoFactory sso.Factory,
+	identityProvider twitter.IdentityProvider,
+	account twitter.Account,
+	linker twitter.AccountLinker,
+) twitter.SingleSignOn {
+	return twitter.SingleSignOn(
+		ssoFactory.NewSingleSignOn(
+			identityProvider,
+			account,
+			sso.AccountLinker(linker)),
+	)
+}
@@ -0,0 +1,16 @@
+package provider
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/short-d/short/backend/app/adapter/unshorten"
+)
+
+const redirectFollowTimeout = 3 * time.Second
+
+// NewRedirectFollower creates HTTP redirect follower which sends the requests
+// through client, so that they count towards the outbound HTTP limit.
+func NewRedirectFollower(client http.Client) unshorten.HTTP {
+	return unshorten.NewHTTP(client, redirectFollowTimeout)
+}
@@ -0,0 +1,79 @@
+package provider
+
+import (
+	"net/url"
+
+	"github.com/short-d/short/backend/app/usecase/validator"
+)
+
+// LongLinkAllowedDomains represents the domains long links must be on. An empty
+// list allows long links on any domain.
+type LongLinkAllowedDomains []string
+
+// ShortLinkDomains represents the domains serving short links, including
+// custom domains. Long links on these domains redirect back to Short.
+type ShortLinkDomains []string
+
+// LongLinkFragment represents the name of the mode deciding whether the
+// fragments of long links are preserved or stripped.
+type LongLinkFragment string
+
+// LongLinkPlainHTTP represents the name of the mode deciding whether long
+// links using plain HTTP are allowed, rejected or upgraded to HTTPS.
+type LongLinkPlainHTTP string
+
+// CustomAliasUnicodeCategories represents the Unicode categories of the non
+// ASCII characters allowed in custom aliases. An empty list allows all the
+// visible characters.
+type CustomAliasUnicodeCategories []string
+
+// NewLongLinkValidator creates LongLink validator with LongLinkAllowedDomains,
+// ShortLinkDomains, LongLinkFragment and LongLinkPlainHTTP to uniquely
+// identify the domains and the handling modes during dependency injection.
+// The domain of the web frontend serves short links unless ShortLinkDomains
+// is configured.
+func NewLongLinkValidator(
+	allowedDomains LongLinkAllowedDomains,
+	shortDomains ShortLinkDomains,
+	webFrontendURL WebFrontendURL,
+	fragmentName LongLinkFragment,
+	plainHTTPName LongLinkPlainHTTP,
+) (validator.LongLink, error) {
+	fragment, err := validator.ParseFragment(string(fragmentName))
+	if err != nil {
+		return validator.LongLink{}, err
+	}
+
+	plainHTTP, err := validator.ParsePlainHTTP(string(plainHTTPName))
+	if err != nil {
+		return validator.LongLink{}, err
+	}
+
+	domains := nonEmpty(shortDomains)
+	if len(domains) == 0 {
+		frontendURL, err := url.Parse(string(webFrontendURL))
+		if err != nil {
+			return validator.LongLink{}, err
+		}
+		domains = []string{frontendURL.Hostname()}
+	}
+	return validator.NewLongLink(allowedDomains, domains, fragment, plainHTTP), nil
+}
+
+// NewCustomAliasValidator creates CustomAlias validator with
+// CustomAliasUnicodeCategories and AliasPrefix to uniquely identify the
+// categories and the prefix during dependency injection.
+func NewCustomAliasValidator(
+	categories CustomAliasUnicodeCategories,
+	prefix AliasPrefix,
+) (validator.CustomAlias, error) {
+	names := nonEmpty(categories)
+	if len(names) == 0 {
+		return validator.NewCustomAlias().WithAutoAliasPrefix(string(prefix)), nil
+	}
+	aliasValidator, err := validator.NewUnicodeCustomAlias(names)
+	if err != nil {
+		return validator.CustomAlias{}, err
+	}
+	return aliasValidator.WithAutoAliasPrefix(string(prefix)), nil
+}
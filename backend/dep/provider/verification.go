@@ -0,0 +1,37 @@
+package provider
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/short-d/app/fw/crypto"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/usecase/email"
+	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/verification"
+)
+
+const emailVerificationTokenValidDuration = 24 * time.Hour
+
+// NewEmailVerifier creates EmailVerifier with WebFrontendURL to uniquely
+// identify webFrontendURL during dependency injection.
+func NewEmailVerifier(
+	tokenizer crypto.Tokenizer,
+	timer timer.Timer,
+	userRepo repository.User,
+	emailSender email.Sender,
+	webFrontendURL WebFrontendURL,
+) (verification.EmailVerifier, error) {
+	frontendURL, err := url.Parse(string(webFrontendURL))
+	if err != nil {
+		return verification.EmailVerifier{}, err
+	}
+	return verification.NewEmailVerifier(
+		tokenizer,
+		timer,
+		userRepo,
+		emailSender,
+		*frontendURL,
+		emailVerificationTokenValidDuration,
+	), nil
+}
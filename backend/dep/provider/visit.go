@@ -0,0 +1,93 @@
+package provider
+
+import (
+	"time"
+
+	"github.com/short-d/app/fw/geo"
+	"github.com/short-d/app/fw/logger"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/visit"
+)
+
+// VisitorIPMode represents how the IP addresses of visitors are stored for
+// analytics.
+type VisitorIPMode string
+
+// VisitorDetails represents whether the referrers, the user agents and the
+// UTM campaigns of visitors are stored for analytics.
+type VisitorDetails struct {
+	Referrer  bool
+	UserAgent bool
+	Campaign  bool
+}
+
+// NewVisitTracker creates TrackerPersist with VisitorDetails to uniquely
+// identify details during dependency injection.
+func NewVisitTracker(
+	timer timer.Timer,
+	details VisitorDetails,
+	uaParser visit.UserAgentParser,
+	visits visit.Buffer,
+	visitCounts visit.CountBuffer,
+) visit.TrackerPersist {
+	return visit.NewTrackerPersist(
+		timer,
+		visit.Details{
+			Referrer:  details.Referrer,
+			UserAgent: details.UserAgent,
+			Campaign:  details.Campaign,
+		},
+		uaParser,
+		visits,
+		visitCounts,
+	)
+}
+
+// VisitBufferConfig represents how often the buffered visits and visit counts
+// are flushed, and how many visits and aliases are buffered at most before
+// flushing.
+type VisitBufferConfig struct {
+	FlushInterval time.Duration
+	MaxVisits     int
+	MaxAliases    int
+}
+
+// NewVisitBuffer creates Buffer with VisitorIPMode and VisitBufferConfig to
+// uniquely identify ipMode, interval and maxVisits during dependency
+// injection.
+func NewVisitBuffer(
+	visitRepo repository.Visit,
+	geo geo.Geo,
+	ipMode VisitorIPMode,
+	timer timer.Timer,
+	logger logger.Logger,
+	config VisitBufferConfig,
+) visit.Buffer {
+	return visit.NewBuffer(
+		visitRepo,
+		geo,
+		visit.IPMode(ipMode),
+		timer,
+		logger,
+		config.FlushInterval,
+		config.MaxVisits,
+	)
+}
+
+// NewVisitCountBuffer creates CountBuffer with VisitBufferConfig to
+// uniquely identify interval and maxAliases during dependency injection.
+func NewVisitCountBuffer(
+	shortLinkRepo repository.ShortLink,
+	timer timer.Timer,
+	logger logger.Logger,
+	config VisitBufferConfig,
+) visit.CountBuffer {
+	return visit.NewCountBuffer(
+		shortLinkRepo,
+		timer,
+		logger,
+		config.FlushInterval,
+		config.MaxAliases,
+	)
+}
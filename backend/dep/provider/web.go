@@ -0,0 +1,46 @@
+package provider
+
+import (
+	"github.com/short-d/app/fw/logger"
+	"github.com/short-d/app/fw/router"
+	"github.com/short-d/short/backend/app/fw/cors"
+	"github.com/short-d/short/backend/app/fw/secheader"
+	"github.com/short-d/short/backend/app/fw/web"
+	"github.com/short-d/short/backend/app/usecase/emailpassword"
+	"github.com/short-d/short/backend/app/usecase/visit"
+)
+
+// MaxRequestBodySize represents the maximum number of bytes allowed in the
+// body of HTTP requests. Zero disables the limit.
+type MaxRequestBodySize int64
+
+// RedirectLogSamplePercent represents the percentage of the requests logged by
+// routing service, which mostly serves high volume redirects.
+type RedirectLogSamplePercent int
+
+// NewRoutingService creates routing service with MaxRequestBodySize and
+// RedirectLogSamplePercent to uniquely identify maxBodySize and
+// logSamplePercent during dependency injection. The visits and the visit
+// counts are flushed in the background while the service runs, and the
+// password reset links being sent are waited for on shutdown.
+func NewRoutingService(
+	logger logger.Logger,
+	routes []router.Route,
+	corsPolicy cors.Policy,
+	headerPolicy secheader.Policy,
+	maxBodySize MaxRequestBodySize,
+	logSamplePercent RedirectLogSamplePercent,
+	visits visit.Buffer,
+	visitCounts visit.CountBuffer,
+	passwordReset emailpassword.PasswordReset,
+) web.Routing {
+	return web.NewRouting(
+		logger,
+		routes,
+		corsPolicy,
+		headerPolicy,
+		int64(maxBodySize),
+		int(logSamplePercent),
+		[]web.Worker{visits, visitCounts, passwordReset},
+	)
+}
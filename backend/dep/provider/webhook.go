@@ -0,0 +1,20 @@
+package provider
+
+import (
+	"time"
+
+	"github.com/short-d/app/fw/logger"
+	"github.com/short-d/short/backend/app/adapter/dispatch"
+)
+
+const webhookTimeout = 5 * time.Second
+
+// WebhookURL represents the endpoint events are posted to. Events are dropped
+// when it is empty.
+type WebhookURL string
+
+// NewWebhookDispatcher creates HTTP dispatcher with WebhookURL to uniquely
+// identify the endpoint during dependency injection.
+func NewWebhookDispatcher(logger logger.Logger, webhookURL WebhookURL) dispatch.HTTP {
+	return dispatch.NewHTTP(logger, string(webhookURL), webhookTimeout)
+}
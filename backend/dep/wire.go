@@ -1,4 +1,4 @@
-//+build wireinject
+// +build wireinject
 
 package dep
 
@@ -22,24 +22,45 @@ import (
 	"github.com/short-d/app/fw/service"
 	"github.com/short-d/app/fw/timer"
 	"github.com/short-d/app/fw/webreq"
+	"github.com/short-d/short/backend/app/adapter/apple"
+	"github.com/short-d/short/backend/app/adapter/dispatch"
 	"github.com/short-d/short/backend/app/adapter/facebook"
 	"github.com/short-d/short/backend/app/adapter/github"
 	"github.com/short-d/short/backend/app/adapter/google"
+	"github.com/short-d/short/backend/app/adapter/gqlapi"
 	"github.com/short-d/short/backend/app/adapter/gqlapi/resolver"
 	"github.com/short-d/short/backend/app/adapter/grpcapi"
 	"github.com/short-d/short/backend/app/adapter/kgs"
+	"github.com/short-d/short/backend/app/adapter/probe"
+	"github.com/short-d/short/backend/app/adapter/qrcode"
 	"github.com/short-d/short/backend/app/adapter/request"
 	"github.com/short-d/short/backend/app/adapter/sqldb"
+	"github.com/short-d/short/backend/app/adapter/twitter"
+	"github.com/short-d/short/backend/app/adapter/unshorten"
+	"github.com/short-d/short/backend/app/adapter/useragent"
+	"github.com/short-d/short/backend/app/fw/buildinfo"
 	"github.com/short-d/short/backend/app/fw/filesystem"
+	"github.com/short-d/short/backend/app/fw/outbound"
+	"github.com/short-d/short/backend/app/fw/proxy"
+	"github.com/short-d/short/backend/app/fw/web"
 	"github.com/short-d/short/backend/app/usecase/authorizer"
 	"github.com/short-d/short/backend/app/usecase/authorizer/rbac"
 	"github.com/short-d/short/backend/app/usecase/changelog"
+	"github.com/short-d/short/backend/app/usecase/email"
+	"github.com/short-d/short/backend/app/usecase/featureflag"
 	"github.com/short-d/short/backend/app/usecase/keygen"
+	"github.com/short-d/short/backend/app/usecase/linkhealth"
+	"github.com/short-d/short/backend/app/usecase/maintenance"
+	"github.com/short-d/short/backend/app/usecase/preference"
+	"github.com/short-d/short/backend/app/usecase/ratelimit"
 	"github.com/short-d/short/backend/app/usecase/repository"
 	"github.com/short-d/short/backend/app/usecase/risk"
+	"github.com/short-d/short/backend/app/usecase/share"
 	"github.com/short-d/short/backend/app/usecase/shortlink"
 	"github.com/short-d/short/backend/app/usecase/sso"
-	"github.com/short-d/short/backend/app/usecase/validator"
+	"github.com/short-d/short/backend/app/usecase/stats"
+	"github.com/short-d/short/backend/app/usecase/visit"
+	"github.com/short-d/short/backend/app/usecase/webhook"
 	"github.com/short-d/short/backend/dep/provider"
 	"github.com/short-d/short/backend/tool"
 )
@@ -61,7 +82,7 @@ var observabilitySet = wire.NewSet(
 	wire.Bind(new(runtime.Runtime), new(runtime.Program)),
 	wire.Bind(new(metrics.Metrics), new(metrics.DataDog)),
 	wire.Bind(new(analytics.Analytics), new(analytics.Segment)),
-	wire.Bind(new(network.Network), new(network.Proxy)),
+	wire.Bind(new(network.Network), new(proxy.Trusted)),
 
 	io.NewStdOut,
 	provider.NewEntryRepositorySwitch,
@@ -69,7 +90,7 @@ var observabilitySet = wire.NewSet(
 	runtime.NewProgram,
 	provider.NewDataDogMetrics,
 	provider.NewSegment,
-	network.NewProxy,
+	provider.NewTrustedProxy,
 	request.NewClient,
 	request.NewInstrumentationFactory,
 )
@@ -92,10 +113,60 @@ var googleAPISet = wire.NewSet(
 	google.NewAPI,
 )
 
+var twitterAPISet = wire.NewSet(
+	provider.NewTwitterIdentityProvider,
+	twitter.NewAccount,
+	twitter.NewAPI,
+)
+
+var appleAPISet = wire.NewSet(
+	provider.NewAppleIdentityProvider,
+	provider.NewAppleAccount,
+	apple.NewAPI,
+)
+
 var keyGenSet = wire.NewSet(
 	wire.Bind(new(keygen.KeyFetcher), new(kgs.RPC)),
+	wire.Bind(new(repository.KeyCounter), new(sqldb.KeyCounterSQL)),
 	provider.NewKgsRPC,
+	sqldb.NewKeyCounterSQL,
 	provider.NewKeyGenerator,
+	provider.NewAliasKeyGenerator,
+)
+
+var remoteKeyGenSet = wire.NewSet(
+	wire.Bind(new(keygen.KeyFetcher), new(kgs.RPC)),
+	wire.Bind(new(keygen.KeyGenerator), new(keygen.Remote)),
+	provider.NewKgsRPC,
+	provider.NewRemoteKeyGenerator,
+)
+
+var shortLinkCreatorSet = wire.NewSet(
+	wire.Bind(new(risk.BlackList), new(risk.CircuitBreaker)),
+	wire.Bind(new(risk.Denylist), new(risk.DomainDenylist)),
+	wire.Bind(new(repository.FlaggedShortLink), new(sqldb.FlaggedShortLinkSQL)),
+	wire.Bind(new(repository.AliasSkeleton), new(sqldb.AliasSkeletonSQL)),
+	wire.Bind(new(repository.AliasReservation), new(sqldb.AliasReservationSQL)),
+	wire.Bind(new(repository.UserPreferences), new(sqldb.UserPreferencesSQL)),
+	wire.Bind(new(shortlink.Creator), new(shortlink.CreatorPersist)),
+	wire.Bind(new(email.Sender), new(email.Retry)),
+	wire.Bind(new(webhook.Dispatcher), new(dispatch.HTTP)),
+	wire.Bind(new(shortlink.RedirectFollower), new(unshorten.HTTP)),
+
+	provider.NewSafeBrowsing,
+	provider.NewRiskCircuitBreaker,
+	provider.NewRiskDetector,
+	provider.NewDomainDenylist,
+	sqldb.NewFlaggedShortLinkSQL,
+	sqldb.NewAliasSkeletonSQL,
+	sqldb.NewAliasReservationSQL,
+	sqldb.NewUserPreferencesSQL,
+	provider.NewLongLinkValidator,
+	provider.NewCustomAliasValidator,
+	provider.NewShortLinkCreator,
+	provider.NewEmailSender,
+	provider.NewWebhookDispatcher,
+	provider.NewRedirectFollower,
 )
 
 var featureDecisionSet = wire.NewSet(
@@ -140,14 +211,30 @@ func InjectEnv() env.Env {
 	return env.GoDotEnv{}
 }
 
+// InjectFeatureFlagToggle creates the feature flags shared by the services
+// with configured dependencies.
+func InjectFeatureFlagToggle(
+	featureFlagConfigPath provider.FeatureFlagConfigPath,
+) (featureflag.OverrideToggle, error) {
+	wire.Build(
+		wire.Bind(new(filesystem.FileSystem), new(filesystem.Local)),
+
+		filesystem.NewLocal,
+		provider.NewFeatureFlagToggle,
+	)
+	return featureflag.OverrideToggle{}, nil
+}
+
 // InjectGRPCService creates gRPC service with configured dependencies.
 func InjectGRPCService(
 	runtime env.Runtime,
 	prefix provider.LogPrefix,
-	logLevel logger.LogLevel,
+	logLevel provider.LogLevel,
 	sqlDB *sql.DB,
+	slowQueryThreshold provider.SlowQueryThreshold,
 	securityPolicy security.Policy,
 	dataDogAPIKey provider.DataDogAPIKey,
+	outboundLimiter outbound.Limiter,
 ) (service.GRPC, error) {
 	wire.Build(
 		wire.Bind(new(timer.Timer), new(timer.System)),
@@ -158,11 +245,12 @@ func InjectGRPCService(
 		observabilitySet,
 
 		timer.NewSystem,
-		webreq.NewHTTPClient,
+		provider.NewOutboundHTTPClient,
 		webreq.NewHTTP,
 		env.NewDeployment,
 		service.NewGRPC,
 
+		provider.NewSlowQueryLog,
 		sqldb.NewShortLinkSQL,
 		shortlink.NewMetaTagPersist,
 		grpcapi.NewShort,
@@ -172,83 +260,202 @@ func InjectGRPCService(
 	return service.GRPC{}, nil
 }
 
+// InjectLinkHealthJob creates the background checks on long links with
+// configured dependencies.
+func InjectLinkHealthJob(
+	runtime env.Runtime,
+	prefix provider.LogPrefix,
+	logLevel provider.LogLevel,
+	sqlDB *sql.DB,
+	slowQueryThreshold provider.SlowQueryThreshold,
+	dataDogAPIKey provider.DataDogAPIKey,
+	linkHealthConfig provider.LinkHealthConfig,
+	featureToggle featureflag.OverrideToggle,
+	outboundLimiter outbound.Limiter,
+	webhookURL provider.WebhookURL,
+) (linkhealth.Job, error) {
+	wire.Build(
+		wire.Bind(new(timer.Timer), new(timer.System)),
+		wire.Bind(new(featureflag.Toggle), new(featureflag.OverrideToggle)),
+		wire.Bind(new(repository.ShortLink), new(sqldb.ShortLinkSQL)),
+		wire.Bind(new(repository.LinkHealth), new(sqldb.LinkHealthSQL)),
+		wire.Bind(new(linkhealth.Prober), new(probe.HTTP)),
+		wire.Bind(new(webhook.Dispatcher), new(dispatch.HTTP)),
+
+		observabilitySet,
+
+		timer.NewSystem,
+		provider.NewOutboundHTTPClient,
+		webreq.NewHTTP,
+		env.NewDeployment,
+
+		provider.NewSlowQueryLog,
+		sqldb.NewShortLinkSQL,
+		sqldb.NewLinkHealthSQL,
+		provider.NewLinkHealthProber,
+		provider.NewWebhookDispatcher,
+		provider.NewLinkHealthChecker,
+		provider.NewLinkHealthJob,
+	)
+	return linkhealth.Job{}, nil
+}
+
 // InjectGraphQLService creates GraphQL service with configured dependencies.
 func InjectGraphQLService(
 	runtime env.Runtime,
 	prefix provider.LogPrefix,
-	logLevel logger.LogLevel,
+	logLevel provider.LogLevel,
 	sqlDB *sql.DB,
+	slowQueryThreshold provider.SlowQueryThreshold,
 	graphqlSchemaPath provider.GraphQLSchemaPath,
 	graphqlPath provider.GraphQLPath,
 	graphiQLDefaultQuery provider.GraphiQLDefaultQuery,
 	secret provider.ReCaptchaSecret,
 	jwtSecret provider.JwtSecret,
 	bufferSize provider.KeyGenBufferSize,
+	keyGenBatchBounds provider.KeyGenBatchBounds,
 	kgsRPCConfig provider.KgsRPCConfig,
+	kgsConnectRetry provider.KgsConnectRetry,
+	keyGenStrategy provider.KeyGenStrategy,
+	hashidsSalt provider.HashidsSalt,
+	keyGenWordsConfig provider.KeyGenWordsConfig,
+	webFrontendURL provider.WebFrontendURL,
 	tokenValidDuration provider.TokenValidDuration,
 	dataDogAPIKey provider.DataDogAPIKey,
 	segmentAPIKey provider.SegmentAPIKey,
 	ipStackAPIKey provider.IPStackAPIKey,
 	googleAPIKey provider.GoogleAPIKey,
-) (service.GraphQL, error) {
+	featureToggle featureflag.OverrideToggle,
+	riskThresholds provider.RiskThresholds,
+	allowedDomains provider.LongLinkAllowedDomains,
+	domainDenylistPath provider.DomainDenylistPath,
+	corsConfig provider.CORSConfig,
+	shortLinkChecks provider.ShortLinkChecks,
+	shortLinkDomains provider.ShortLinkDomains,
+	maxRequestBodySize provider.MaxRequestBodySize,
+	urlValidationWorkers provider.URLValidationWorkers,
+	longLinkUniqueness provider.LongLinkUniqueness,
+	aliasUnicodeCategories provider.CustomAliasUnicodeCategories,
+	riskBreakerConfig provider.RiskCircuitBreakerConfig,
+	aliasQuota provider.AliasQuota,
+	smtpConfig provider.SMTPConfig,
+	webhookURL provider.WebhookURL,
+	redirectRateLimit provider.RedirectRateLimit,
+	trustedProxies provider.TrustedProxies,
+	securityHeaderConfig provider.SecurityHeaderConfig,
+	shortLinkBaseURL provider.ShortLinkBaseURL,
+	longLinkFragment provider.LongLinkFragment,
+	maintenanceMode maintenance.Mode,
+	aliasRetryBudget provider.AliasRetryBudget,
+	relationRetry provider.RelationRetry,
+	reservationTTL provider.AliasReservationTTL,
+	chainedLinkConfig provider.ChainedLinkConfig,
+	shareURLSecret provider.ShareURLSecret,
+	aliasPrefix provider.AliasPrefix,
+	longLinkPlainHTTP provider.LongLinkPlainHTTP,
+	shortLinkLifetime provider.ShortLinkLifetime,
+	expiryGrace provider.ShortLinkExpiryGrace,
+	persistedQueryLimit provider.PersistedQueryLimit,
+	outboundLimiter outbound.Limiter,
+	tenantHosts provider.TenantHosts,
+	adminAllowedIPs provider.AdminAllowedIPs,
+) (web.GraphQL, error) {
 	wire.Build(
 		wire.Bind(new(timer.Timer), new(timer.System)),
-		wire.Bind(new(graphql.Handler), new(graphql.GraphGopherHandler)),
+		wire.Bind(new(graphql.Handler), new(gqlapi.Handler)),
 		wire.Bind(new(graphql.WebUI), new(graphql.GraphiQL)),
 
 		wire.Bind(new(filesystem.FileSystem), new(filesystem.Local)),
-		wire.Bind(new(risk.BlackList), new(google.SafeBrowsing)),
+		wire.Bind(new(share.QRCodeGenerator), new(qrcode.Generator)),
 		wire.Bind(new(repository.UserShortLink), new(sqldb.UserShortLinkSQL)),
 		wire.Bind(new(repository.ChangeLog), new(sqldb.ChangeLogSQL)),
 		wire.Bind(new(repository.UserChangeLog), new(sqldb.UserChangeLogSQL)),
 		wire.Bind(new(repository.ShortLink), new(sqldb.ShortLinkSQL)),
+		wire.Bind(new(repository.Visit), new(sqldb.VisitSQL)),
+		wire.Bind(new(repository.LinkHealth), new(sqldb.LinkHealthSQL)),
+		wire.Bind(new(repository.User), new(sqldb.UserSQL)),
+		wire.Bind(new(repository.ShortLinkAudit), new(sqldb.ShortLinkAuditSQL)),
 
 		wire.Bind(new(changelog.ChangeLog), new(changelog.Persist)),
 		wire.Bind(new(shortlink.Retriever), new(shortlink.RetrieverPersist)),
-		wire.Bind(new(shortlink.Creator), new(shortlink.CreatorPersist)),
 		wire.Bind(new(shortlink.Updater), new(shortlink.UpdaterPersist)),
+		wire.Bind(new(shortlink.Deleter), new(shortlink.DeleterPersist)),
+		wire.Bind(new(shortlink.MetaTag), new(shortlink.MetaTagPersist)),
+		wire.Bind(new(shortlink.StatusChecker), new(shortlink.StatusCheckerPersist)),
+		wire.Bind(new(shortlink.AliasChecker), new(shortlink.AliasCheckerPersist)),
+		wire.Bind(new(shortlink.Expirer), new(shortlink.ExpirerPersist)),
+		wire.Bind(new(ratelimit.Limiter), new(ratelimit.Memory)),
+		wire.Bind(new(shortlink.URLValidator), new(shortlink.URLValidatorConcurrent)),
+		wire.Bind(new(visit.Stats), new(visit.StatsPersist)),
+		wire.Bind(new(linkhealth.Reporter), new(linkhealth.ReporterPersist)),
+		wire.Bind(new(featureflag.Toggle), new(featureflag.OverrideToggle)),
+		wire.Bind(new(stats.Service), new(stats.ServicePersist)),
 
 		observabilitySet,
 		authenticatorSet,
 		authorizerSet,
 		keyGenSet,
+		shortLinkCreatorSet,
 
 		env.NewDeployment,
 		provider.NewGraphQLService,
+		provider.NewCORSPolicy,
+		provider.NewSecurityHeaderPolicy,
 		graphql.NewGraphGopherHandler,
+		gqlapi.NewHandler,
+		provider.NewTenantHosts,
+		provider.NewAdminIPPolicy,
+		provider.NewPersistedQueryStore,
 		provider.NewGraphiQL,
-		webreq.NewHTTPClient,
+		provider.NewOutboundHTTPClient,
 		webreq.NewHTTP,
 		timer.NewSystem,
 
 		filesystem.NewLocal,
 		resolver.NewResolver,
 		provider.NewShortGraphQLAPI,
-		provider.NewSafeBrowsing,
-		risk.NewDetector,
 		provider.NewReCaptchaService,
+		qrcode.NewGenerator,
 		provider.NewVerifier,
 		sqldb.NewChangeLogSQL,
 		sqldb.NewUserChangeLogSQL,
+		provider.NewSlowQueryLog,
 		sqldb.NewShortLinkSQL,
 		sqldb.NewUserShortLinkSQL,
+		sqldb.NewVisitSQL,
+		sqldb.NewLinkHealthSQL,
+		sqldb.NewUserSQL,
+		sqldb.NewShortLinkAuditSQL,
 
-		validator.NewLongLink,
-		validator.NewCustomAlias,
 		changelog.NewPersist,
-		shortlink.NewRetrieverPersist,
-		shortlink.NewCreatorPersist,
-		shortlink.NewUpdaterPersist,
+		provider.NewShortLinkRetriever,
+		provider.NewShortLinkUpdater,
+		shortlink.NewDeleterPersist,
+		shortlink.NewMetaTagPersist,
+		shortlink.NewStatusCheckerPersist,
+		shortlink.NewAliasCheckerPersist,
+		shortlink.NewExpirerPersist,
+		maintenance.NewSwitch,
+		featureflag.NewSwitch,
+		provider.NewRedirectRateLimiter,
+		provider.NewURLValidator,
+		provider.NewAliasSigner,
+		provider.NewShare,
+		visit.NewStatsPersist,
+		linkhealth.NewReporterPersist,
+		preference.NewPreference,
+		stats.NewServicePersist,
 	)
-	return service.GraphQL{}, nil
+	return web.GraphQL{}, nil
 }
 
 // InjectRoutingService creates routing service with configured dependencies.
 func InjectRoutingService(
 	runtime env.Runtime,
 	prefix provider.LogPrefix,
-	logLevel logger.LogLevel,
+	logLevel provider.LogLevel,
 	sqlDB *sql.DB,
+	slowQueryThreshold provider.SlowQueryThreshold,
 	githubClientID provider.GithubClientID,
 	githubClientSecret provider.GithubClientSecret,
 	facebookClientID provider.FacebookClientID,
@@ -257,9 +464,19 @@ func InjectRoutingService(
 	googleClientID provider.GoogleClientID,
 	googleClientSecret provider.GoogleClientSecret,
 	googleRedirectURI provider.GoogleRedirectURI,
+	twitterClientID provider.TwitterClientID,
+	twitterClientSecret provider.TwitterClientSecret,
+	twitterRedirectURI provider.TwitterRedirectURI,
+	appleClientID provider.AppleClientID,
+	appleRedirectURI provider.AppleRedirectURI,
 	jwtSecret provider.JwtSecret,
 	bufferSize provider.KeyGenBufferSize,
+	keyGenBatchBounds provider.KeyGenBatchBounds,
 	kgsRPCConfig provider.KgsRPCConfig,
+	kgsConnectRetry provider.KgsConnectRetry,
+	keyGenStrategy provider.KeyGenStrategy,
+	hashidsSalt provider.HashidsSalt,
+	keyGenWordsConfig provider.KeyGenWordsConfig,
 	webFrontendURL provider.WebFrontendURL,
 	tokenValidDuration provider.TokenValidDuration,
 	searchTimeout provider.SearchTimeout,
@@ -268,15 +485,75 @@ func InjectRoutingService(
 	dataDogAPIKey provider.DataDogAPIKey,
 	segmentAPIKey provider.SegmentAPIKey,
 	ipStackAPIKey provider.IPStackAPIKey,
-) (service.Routing, error) {
+	visitorIPMode provider.VisitorIPMode,
+	visitorDetails provider.VisitorDetails,
+	redirectRateLimit provider.RedirectRateLimit,
+	googleAPIKey provider.GoogleAPIKey,
+	riskThresholds provider.RiskThresholds,
+	allowedDomains provider.LongLinkAllowedDomains,
+	domainDenylistPath provider.DomainDenylistPath,
+	corsConfig provider.CORSConfig,
+	shortLinkChecks provider.ShortLinkChecks,
+	shortLinkDomains provider.ShortLinkDomains,
+	maxRequestBodySize provider.MaxRequestBodySize,
+	errorPageConfig provider.ErrorPageConfig,
+	trustedProxies provider.TrustedProxies,
+	guestAttributionEnabled provider.GuestAttributionEnabled,
+	guestCreateCooldown provider.GuestCreateCooldown,
+	longLinkUniqueness provider.LongLinkUniqueness,
+	aliasUnicodeCategories provider.CustomAliasUnicodeCategories,
+	riskBreakerConfig provider.RiskCircuitBreakerConfig,
+	aliasQuota provider.AliasQuota,
+	smtpConfig provider.SMTPConfig,
+	webhookURL provider.WebhookURL,
+	signInRateLimit provider.SignInRateLimit,
+	passwordResetRateLimit provider.PasswordResetRateLimit,
+	passwordPolicyConfig provider.PasswordPolicyConfig,
+	securityHeaderConfig provider.SecurityHeaderConfig,
+	shortLinkBaseURL provider.ShortLinkBaseURL,
+	longLinkFragment provider.LongLinkFragment,
+	redirectLogSamplePercent provider.RedirectLogSamplePercent,
+	maintenanceMode maintenance.Mode,
+	profilingEnabled provider.ProfilingEnabled,
+	aliasRetryBudget provider.AliasRetryBudget,
+	relationRetry provider.RelationRetry,
+	reservationTTL provider.AliasReservationTTL,
+	chainedLinkConfig provider.ChainedLinkConfig,
+	shareURLSecret provider.ShareURLSecret,
+	visitBuffer provider.VisitBufferConfig,
+	aliasPrefix provider.AliasPrefix,
+	longLinkPlainHTTP provider.LongLinkPlainHTTP,
+	shortLinkLifetime provider.ShortLinkLifetime,
+	expiryGrace provider.ShortLinkExpiryGrace,
+	adminAllowedIPs provider.AdminAllowedIPs,
+	outboundLimiter outbound.Limiter,
+	queryConflict provider.QueryConflict,
+	redirectConfig provider.RedirectConfig,
+	linkRateLimitWindow provider.LinkRateLimitWindow,
+	domainRedirects provider.DomainRedirects,
+	featureToggle featureflag.OverrideToggle,
+	tenantHosts provider.TenantHosts,
+) (web.Routing, error) {
 	wire.Build(
+		wire.Bind(new(featureflag.Toggle), new(featureflag.OverrideToggle)),
+		wire.Bind(new(buildinfo.Provider), new(buildinfo.Linked)),
 		wire.Bind(new(timer.Timer), new(timer.System)),
 		wire.Bind(new(geo.Geo), new(geo.IPStack)),
+		wire.Bind(new(filesystem.FileSystem), new(filesystem.Local)),
 
 		wire.Bind(new(shortlink.Retriever), new(shortlink.RetrieverPersist)),
+		wire.Bind(new(shortlink.GuestSession), new(shortlink.GuestSessionPersist)),
+		wire.Bind(new(visit.Tracker), new(visit.TrackerPersist)),
+		wire.Bind(new(visit.UserAgentParser), new(useragent.Parser)),
+		wire.Bind(new(ratelimit.Limiter), new(ratelimit.Memory)),
 		wire.Bind(new(repository.UserShortLink), new(sqldb.UserShortLinkSQL)),
 		wire.Bind(new(repository.User), new(sqldb.UserSQL)),
 		wire.Bind(new(repository.ShortLink), new(sqldb.ShortLinkSQL)),
+		wire.Bind(new(repository.Visit), new(sqldb.VisitSQL)),
+		wire.Bind(new(repository.UserPassword), new(sqldb.UserPasswordSQL)),
+		wire.Bind(new(share.QRCodeGenerator), new(qrcode.Generator)),
+		wire.Bind(new(shortlink.MetaTag), new(shortlink.MetaTagPersist)),
+		wire.Bind(new(stats.Service), new(stats.ServicePersist)),
 
 		observabilitySet,
 		authenticatorSet,
@@ -284,16 +561,28 @@ func InjectRoutingService(
 		githubAPISet,
 		facebookAPISet,
 		googleAPISet,
+		twitterAPISet,
+		appleAPISet,
 		keyGenSet,
+		shortLinkCreatorSet,
 		featureDecisionSet,
 
-		service.NewRouting,
-		webreq.NewHTTPClient,
+		provider.NewRoutingService,
+		provider.NewAdminIPPolicy,
+		provider.NewQueryConflict,
+		provider.NewRedirect,
+		provider.NewCanonicalDomain,
+		provider.NewTenantHosts,
+		buildinfo.NewLinked,
+		provider.NewCORSPolicy,
+		provider.NewSecurityHeaderPolicy,
+		provider.NewOutboundHTTPClient,
 		webreq.NewHTTP,
 		graphql.NewClientFactory,
 		timer.NewSystem,
 		provider.NewIPStack,
 		env.NewDeployment,
+		filesystem.NewLocal,
 
 		provider.NewGithubAccountLinker,
 		provider.NewGithubSSO,
@@ -301,37 +590,65 @@ func InjectRoutingService(
 		provider.NewFacebookSSO,
 		provider.NewGoogleAccountLinker,
 		provider.NewGoogleSSO,
+		provider.NewTwitterAccountLinker,
+		provider.NewTwitterSSO,
+		provider.NewAppleAccountLinker,
+		provider.NewAppleSSO,
 		sqldb.NewGithubSSOSql,
 		sqldb.NewFacebookSSOSql,
 		sqldb.NewGoogleSSOSql,
+		sqldb.NewTwitterSSOSql,
+		sqldb.NewAppleSSOSql,
 		sqldb.NewUserSQL,
+		provider.NewSlowQueryLog,
 		sqldb.NewShortLinkSQL,
 		sqldb.NewUserShortLinkSQL,
+		sqldb.NewVisitSQL,
 
 		sso.NewAccountLinkerFactory,
 		sso.NewFactory,
-		shortlink.NewRetrieverPersist,
+		provider.NewEmailVerifier,
+		provider.NewEmailPasswordAccount,
+		provider.NewPasswordReset,
+		provider.NewPasswordPolicy,
+		sqldb.NewUserPasswordSQL,
+		provider.NewShortLinkRetriever,
+		provider.NewVisitTracker,
+		provider.NewVisitBuffer,
+		provider.NewVisitCountBuffer,
+		useragent.NewParser,
+		provider.NewRedirectRateLimiter,
+		provider.NewLinkRateLimiter,
 		provider.NewSearch,
+		provider.NewErrorPages,
+		shortlink.NewGuestSessionPersist,
+		provider.NewGuestAttribution,
+		shortlink.NewMetaTagPersist,
+		qrcode.NewGenerator,
+		provider.NewAliasSigner,
+		provider.NewShare,
+		stats.NewServicePersist,
 		provider.NewShortRoutes,
 	)
-	return service.Routing{}, nil
+	return web.Routing{}, nil
 }
 
 // InjectDataTool creates data tool with configured dependencies.
 func InjectDataTool(
 	prefix provider.LogPrefix,
-	logLevel logger.LogLevel,
+	logLevel provider.LogLevel,
 	dbConfig db.Config,
 	dbConnector db.Connector,
 	bufferSize provider.KeyGenBufferSize,
 	kgsRPCConfig provider.KgsRPCConfig,
+	kgsConnectRetry provider.KgsConnectRetry,
 ) (tool.Data, error) {
 	wire.Build(
 		wire.Bind(new(io.Output), new(io.StdOut)),
 		wire.Bind(new(timer.Timer), new(timer.System)),
 		wire.Bind(new(logger.EntryRepository), new(logger.Local)),
 
-		keyGenSet,
+		remoteKeyGenSet,
 
 		io.NewStdOut,
 		runtime.NewProgram,
@@ -342,3 +659,73 @@ func InjectDataTool(
 	)
 	return tool.Data{}, nil
 }
+
+// InjectImportTool creates short link import tool with configured
+// dependencies.
+func InjectImportTool(
+	prefix provider.LogPrefix,
+	logLevel provider.LogLevel,
+	sqlDB *sql.DB,
+	allowedDomains provider.LongLinkAllowedDomains,
+	shortLinkDomains provider.ShortLinkDomains,
+	webFrontendURL provider.WebFrontendURL,
+	aliasUnicodeCategories provider.CustomAliasUnicodeCategories,
+	longLinkFragment provider.LongLinkFragment,
+	aliasPrefix provider.AliasPrefix,
+	longLinkPlainHTTP provider.LongLinkPlainHTTP,
+) (tool.Import, error) {
+	wire.Build(
+		wire.Bind(new(io.Output), new(io.StdOut)),
+		wire.Bind(new(timer.Timer), new(timer.System)),
+		wire.Bind(new(logger.EntryRepository), new(logger.Local)),
+		wire.Bind(new(repository.ShortLink), new(sqldb.ShortLinkSQL)),
+		wire.Bind(new(repository.UserShortLink), new(sqldb.UserShortLinkSQL)),
+		wire.Bind(new(repository.User), new(sqldb.UserSQL)),
+		wire.Bind(new(shortlink.Importer), new(shortlink.ImporterPersist)),
+
+		io.NewStdOut,
+		runtime.NewProgram,
+		provider.NewLocalEntryRepo,
+		provider.NewLogger,
+		timer.NewSystem,
+
+		// The one-off tools don't report slow queries.
+		wire.Value(sqldb.SlowQueryLog{}),
+		sqldb.NewShortLinkSQL,
+		sqldb.NewUserShortLinkSQL,
+		sqldb.NewUserSQL,
+		provider.NewLongLinkValidator,
+		provider.NewCustomAliasValidator,
+		shortlink.NewImporterPersist,
+		tool.NewImport,
+	)
+	return tool.Import{}, nil
+}
+
+// InjectPruneTool creates prune tool with configured dependencies.
+func InjectPruneTool(
+	prefix provider.LogPrefix,
+	logLevel provider.LogLevel,
+	sqlDB *sql.DB,
+) (tool.Prune, error) {
+	wire.Build(
+		wire.Bind(new(io.Output), new(io.StdOut)),
+		wire.Bind(new(timer.Timer), new(timer.System)),
+		wire.Bind(new(logger.EntryRepository), new(logger.Local)),
+		wire.Bind(new(repository.UserShortLink), new(sqldb.UserShortLinkSQL)),
+		wire.Bind(new(shortlink.OrphanPruner), new(shortlink.OrphanPrunerPersist)),
+
+		io.NewStdOut,
+		runtime.NewProgram,
+		provider.NewLocalEntryRepo,
+		provider.NewLogger,
+		timer.NewSystem,
+
+		// The one-off tools don't report slow queries.
+		wire.Value(sqldb.SlowQueryLog{}),
+		sqldb.NewUserShortLinkSQL,
+		shortlink.NewOrphanPrunerPersist,
+		tool.NewPrune,
+	)
+	return tool.Prune{}, nil
+}
@@ -15,7 +15,6 @@ import (
 	"github.com/short-d/app/fw/env"
 	"github.com/short-d/app/fw/graphql"
 	"github.com/short-d/app/fw/io"
-	"github.com/short-d/app/fw/logger"
 	"github.com/short-d/app/fw/metrics"
 	"github.com/short-d/app/fw/network"
 	"github.com/short-d/app/fw/runtime"
@@ -23,24 +22,42 @@ import (
 	"github.com/short-d/app/fw/service"
 	"github.com/short-d/app/fw/timer"
 	"github.com/short-d/app/fw/webreq"
+	"github.com/short-d/short/backend/app/adapter/apple"
+	"github.com/short-d/short/backend/app/adapter/dispatch"
 	"github.com/short-d/short/backend/app/adapter/facebook"
 	"github.com/short-d/short/backend/app/adapter/github"
 	"github.com/short-d/short/backend/app/adapter/google"
+	"github.com/short-d/short/backend/app/adapter/gqlapi"
 	"github.com/short-d/short/backend/app/adapter/gqlapi/resolver"
 	"github.com/short-d/short/backend/app/adapter/grpcapi"
 	"github.com/short-d/short/backend/app/adapter/kgs"
+	"github.com/short-d/short/backend/app/adapter/qrcode"
 	"github.com/short-d/short/backend/app/adapter/request"
 	"github.com/short-d/short/backend/app/adapter/sqldb"
+	"github.com/short-d/short/backend/app/adapter/twitter"
+	"github.com/short-d/short/backend/app/adapter/unshorten"
+	"github.com/short-d/short/backend/app/adapter/useragent"
+	"github.com/short-d/short/backend/app/fw/buildinfo"
 	"github.com/short-d/short/backend/app/fw/filesystem"
+	"github.com/short-d/short/backend/app/fw/outbound"
+	"github.com/short-d/short/backend/app/fw/proxy"
+	"github.com/short-d/short/backend/app/fw/web"
 	"github.com/short-d/short/backend/app/usecase/authorizer"
 	"github.com/short-d/short/backend/app/usecase/authorizer/rbac"
 	"github.com/short-d/short/backend/app/usecase/changelog"
+	"github.com/short-d/short/backend/app/usecase/email"
+	"github.com/short-d/short/backend/app/usecase/featureflag"
 	"github.com/short-d/short/backend/app/usecase/keygen"
+	"github.com/short-d/short/backend/app/usecase/linkhealth"
+	"github.com/short-d/short/backend/app/usecase/maintenance"
+	"github.com/short-d/short/backend/app/usecase/preference"
 	"github.com/short-d/short/backend/app/usecase/repository"
 	"github.com/short-d/short/backend/app/usecase/risk"
 	"github.com/short-d/short/backend/app/usecase/shortlink"
 	"github.com/short-d/short/backend/app/usecase/sso"
-	"github.com/short-d/short/backend/app/usecase/validator"
+	"github.com/short-d/short/backend/app/usecase/stats"
+	"github.com/short-d/short/backend/app/usecase/visit"
+	"github.com/short-d/short/backend/app/usecase/webhook"
 	"github.com/short-d/short/backend/dep/provider"
 	"github.com/short-d/short/backend/tool"
 )
@@ -67,111 +84,280 @@ func InjectEnv() env.Env {
 	return goDotEnv
 }
 
-func InjectGRPCService(runtime2 env.Runtime, prefix provider.LogPrefix, logLevel logger.LogLevel, sqlDB *sql.DB, securityPolicy security.Policy, dataDogAPIKey provider.DataDogAPIKey) (service.GRPC, error) {
+func InjectFeatureFlagToggle(featureFlagConfigPath provider.FeatureFlagConfigPath) (featureflag.OverrideToggle, error) {
+	local := filesystem.NewLocal()
+	overrideToggle, err := provider.NewFeatureFlagToggle(local, featureFlagConfigPath)
+	if err != nil {
+		return featureflag.OverrideToggle{}, err
+	}
+	return overrideToggle, nil
+}
+
+func InjectGRPCService(runtime2 env.Runtime, prefix provider.LogPrefix, logLevel provider.LogLevel, sqlDB *sql.DB, slowQueryThreshold provider.SlowQueryThreshold, securityPolicy security.Policy, dataDogAPIKey provider.DataDogAPIKey, outboundLimiter outbound.Limiter) (service.GRPC, error) {
 	system := timer.NewSystem()
 	program := runtime.NewProgram()
 	deployment := env.NewDeployment(runtime2)
 	stdOut := io.NewStdOut()
-	client := webreq.NewHTTPClient()
+	client := provider.NewOutboundHTTPClient(outboundLimiter)
 	http := webreq.NewHTTP(client)
 	entryRepository := provider.NewEntryRepositorySwitch(runtime2, deployment, stdOut, dataDogAPIKey, http)
-	loggerLogger := provider.NewLogger(prefix, logLevel, system, program, entryRepository)
-	shortLinkSQL := sqldb.NewShortLinkSQL(sqlDB)
+	logger, err := provider.NewLogger(prefix, logLevel, system, program, entryRepository)
+	if err != nil {
+		return service.GRPC{}, err
+	}
+	dataDog := provider.NewDataDogMetrics(dataDogAPIKey, http, system, runtime2)
+	slowQueryLog := provider.NewSlowQueryLog(slowQueryThreshold, logger, system, dataDog)
+	shortLinkSQL := sqldb.NewShortLinkSQL(sqlDB, slowQueryLog)
 	metaTagPersist := shortlink.NewMetaTagPersist(shortLinkSQL)
 	metaTagServiceServer := grpcapi.NewMetaTagServer(metaTagPersist)
 	short := grpcapi.NewShort(metaTagServiceServer)
-	grpc, err := service.NewGRPC(loggerLogger, short, securityPolicy)
+	grpc, err := service.NewGRPC(logger, short, securityPolicy)
 	if err != nil {
 		return service.GRPC{}, err
 	}
 	return grpc, nil
 }
 
-func InjectGraphQLService(runtime2 env.Runtime, prefix provider.LogPrefix, logLevel logger.LogLevel, sqlDB *sql.DB, graphqlSchemaPath provider.GraphQLSchemaPath, graphqlPath provider.GraphQLPath, graphiQLDefaultQuery provider.GraphiQLDefaultQuery, secret provider.ReCaptchaSecret, jwtSecret provider.JwtSecret, bufferSize provider.KeyGenBufferSize, kgsRPCConfig provider.KgsRPCConfig, tokenValidDuration provider.TokenValidDuration, dataDogAPIKey provider.DataDogAPIKey, segmentAPIKey provider.SegmentAPIKey, ipStackAPIKey provider.IPStackAPIKey, googleAPIKey provider.GoogleAPIKey) (service.GraphQL, error) {
+func InjectLinkHealthJob(runtime2 env.Runtime, prefix provider.LogPrefix, logLevel provider.LogLevel, sqlDB *sql.DB, slowQueryThreshold provider.SlowQueryThreshold, dataDogAPIKey provider.DataDogAPIKey, linkHealthConfig provider.LinkHealthConfig, featureToggle featureflag.OverrideToggle, outboundLimiter outbound.Limiter, webhookURL provider.WebhookURL) (linkhealth.Job, error) {
+	system := timer.NewSystem()
+	program := runtime.NewProgram()
+	deployment := env.NewDeployment(runtime2)
+	stdOut := io.NewStdOut()
+	client := provider.NewOutboundHTTPClient(outboundLimiter)
+	http := webreq.NewHTTP(client)
+	entryRepository := provider.NewEntryRepositorySwitch(runtime2, deployment, stdOut, dataDogAPIKey, http)
+	logger, err := provider.NewLogger(prefix, logLevel, system, program, entryRepository)
+	if err != nil {
+		return linkhealth.Job{}, err
+	}
+	dataDog := provider.NewDataDogMetrics(dataDogAPIKey, http, system, runtime2)
+	slowQueryLog := provider.NewSlowQueryLog(slowQueryThreshold, logger, system, dataDog)
+	shortLinkSQL := sqldb.NewShortLinkSQL(sqlDB, slowQueryLog)
+	linkHealthSQL := sqldb.NewLinkHealthSQL(sqlDB)
+	probeHTTP := provider.NewLinkHealthProber(linkHealthConfig, featureToggle)
+	dispatchHTTP := provider.NewWebhookDispatcher(logger, webhookURL)
+	checker := provider.NewLinkHealthChecker(shortLinkSQL, linkHealthSQL, probeHTTP, dispatchHTTP, system, linkHealthConfig)
+	job := provider.NewLinkHealthJob(checker, featureToggle, system, logger, linkHealthConfig)
+	return job, nil
+}
+
+func InjectGraphQLService(runtime2 env.Runtime, prefix provider.LogPrefix, logLevel provider.LogLevel, sqlDB *sql.DB, slowQueryThreshold provider.SlowQueryThreshold, graphqlSchemaPath provider.GraphQLSchemaPath, graphqlPath provider.GraphQLPath, graphiQLDefaultQuery provider.GraphiQLDefaultQuery, secret provider.ReCaptchaSecret, jwtSecret provider.JwtSecret, bufferSize provider.KeyGenBufferSize, keyGenBatchBounds provider.KeyGenBatchBounds, kgsRPCConfig provider.KgsRPCConfig, kgsConnectRetry provider.KgsConnectRetry, keyGenStrategy provider.KeyGenStrategy, hashidsSalt provider.HashidsSalt, keyGenWordsConfig provider.KeyGenWordsConfig, webFrontendURL provider.WebFrontendURL, tokenValidDuration provider.TokenValidDuration, dataDogAPIKey provider.DataDogAPIKey, segmentAPIKey provider.SegmentAPIKey, ipStackAPIKey provider.IPStackAPIKey, googleAPIKey provider.GoogleAPIKey, featureToggle featureflag.OverrideToggle, riskThresholds provider.RiskThresholds, allowedDomains provider.LongLinkAllowedDomains, domainDenylistPath provider.DomainDenylistPath, corsConfig provider.CORSConfig, shortLinkChecks provider.ShortLinkChecks, shortLinkDomains provider.ShortLinkDomains, maxRequestBodySize provider.MaxRequestBodySize, urlValidationWorkers provider.URLValidationWorkers, longLinkUniqueness provider.LongLinkUniqueness, aliasUnicodeCategories provider.CustomAliasUnicodeCategories, riskBreakerConfig provider.RiskCircuitBreakerConfig, aliasQuota provider.AliasQuota, smtpConfig provider.SMTPConfig, webhookURL provider.WebhookURL, redirectRateLimit provider.RedirectRateLimit, trustedProxies provider.TrustedProxies, securityHeaderConfig provider.SecurityHeaderConfig, shortLinkBaseURL provider.ShortLinkBaseURL, longLinkFragment provider.LongLinkFragment, maintenanceMode maintenance.Mode, aliasRetryBudget provider.AliasRetryBudget, relationRetry provider.RelationRetry, reservationTTL provider.AliasReservationTTL, chainedLinkConfig provider.ChainedLinkConfig, shareURLSecret provider.ShareURLSecret, aliasPrefix provider.AliasPrefix, longLinkPlainHTTP provider.LongLinkPlainHTTP, shortLinkLifetime provider.ShortLinkLifetime, expiryGrace provider.ShortLinkExpiryGrace, persistedQueryLimit provider.PersistedQueryLimit, outboundLimiter outbound.Limiter, tenantHosts provider.TenantHosts, adminAllowedIPs provider.AdminAllowedIPs) (web.GraphQL, error) {
 	local := filesystem.NewLocal()
 	system := timer.NewSystem()
 	program := runtime.NewProgram()
 	deployment := env.NewDeployment(runtime2)
 	stdOut := io.NewStdOut()
-	client := webreq.NewHTTPClient()
+	client := provider.NewOutboundHTTPClient(outboundLimiter)
 	http := webreq.NewHTTP(client)
 	entryRepository := provider.NewEntryRepositorySwitch(runtime2, deployment, stdOut, dataDogAPIKey, http)
-	loggerLogger := provider.NewLogger(prefix, logLevel, system, program, entryRepository)
-	shortLinkSQL := sqldb.NewShortLinkSQL(sqlDB)
-	userShortLinkSQL := sqldb.NewUserShortLinkSQL(sqlDB)
-	retrieverPersist := shortlink.NewRetrieverPersist(shortLinkSQL, userShortLinkSQL)
-	rpc, err := provider.NewKgsRPC(kgsRPCConfig)
+	logger, err := provider.NewLogger(prefix, logLevel, system, program, entryRepository)
+	if err != nil {
+		return web.GraphQL{}, err
+	}
+	dataDog := provider.NewDataDogMetrics(dataDogAPIKey, http, system, runtime2)
+	slowQueryLog := provider.NewSlowQueryLog(slowQueryThreshold, logger, system, dataDog)
+	shortLinkSQL := sqldb.NewShortLinkSQL(sqlDB, slowQueryLog)
+	userShortLinkSQL := sqldb.NewUserShortLinkSQL(sqlDB, slowQueryLog)
+	retrieverPersist, err := provider.NewShortLinkRetriever(shortLinkSQL, userShortLinkSQL, expiryGrace)
+	if err != nil {
+		return web.GraphQL{}, err
+	}
+	rpc, err := provider.NewKgsRPC(kgsRPCConfig, kgsConnectRetry)
 	if err != nil {
-		return service.GraphQL{}, err
+		return web.GraphQL{}, err
 	}
-	keyGenerator, err := provider.NewKeyGenerator(bufferSize, rpc)
+	keyCounterSQL := sqldb.NewKeyCounterSQL(sqlDB)
+	keyGenerator, err := provider.NewKeyGenerator(keyGenStrategy, bufferSize, keyGenBatchBounds, system, rpc, keyCounterSQL, hashidsSalt)
 	if err != nil {
-		return service.GraphQL{}, err
+		return web.GraphQL{}, err
+	}
+	aliasKeyGenerator, err := provider.NewAliasKeyGenerator(keyGenStrategy, keyGenerator, shortLinkSQL, local, keyGenWordsConfig, aliasPrefix)
+	if err != nil {
+		return web.GraphQL{}, err
+	}
+	longLink, err := provider.NewLongLinkValidator(allowedDomains, shortLinkDomains, webFrontendURL, longLinkFragment, longLinkPlainHTTP)
+	if err != nil {
+		return web.GraphQL{}, err
+	}
+	customAlias, err := provider.NewCustomAliasValidator(aliasUnicodeCategories, aliasPrefix)
+	if err != nil {
+		return web.GraphQL{}, err
 	}
-	longLink := validator.NewLongLink()
-	customAlias := validator.NewCustomAlias()
 	safeBrowsing := provider.NewSafeBrowsing(googleAPIKey, http)
-	detector := risk.NewDetector(safeBrowsing)
-	creatorPersist := shortlink.NewCreatorPersist(shortLinkSQL, userShortLinkSQL, keyGenerator, longLink, customAlias, system, detector)
-	updaterPersist := shortlink.NewUpdaterPersist(shortLinkSQL, userShortLinkSQL, longLink, customAlias, system, detector)
-	changeLogSQL := sqldb.NewChangeLogSQL(sqlDB)
-	userChangeLogSQL := sqldb.NewUserChangeLogSQL(sqlDB)
+	circuitBreaker := provider.NewRiskCircuitBreaker(safeBrowsing, system, logger, riskBreakerConfig)
 	userRoleSQL := sqldb.NewUserRoleSQL(sqlDB)
 	rbacRBAC := rbac.NewRBAC(userRoleSQL)
 	authorizerAuthorizer := authorizer.NewAuthorizer(rbacRBAC)
+	domainDenylist, err := provider.NewDomainDenylist(local, domainDenylistPath, authorizerAuthorizer)
+	if err != nil {
+		return web.GraphQL{}, err
+	}
+	detector := provider.NewRiskDetector(circuitBreaker, domainDenylist, riskThresholds)
+	flaggedShortLinkSQL := sqldb.NewFlaggedShortLinkSQL(sqlDB)
+	retry := provider.NewEmailSender(system, logger, smtpConfig)
+	dispatchHTTP := provider.NewWebhookDispatcher(logger, webhookURL)
+	userPreferencesSQL := sqldb.NewUserPreferencesSQL(sqlDB)
+	aliasSkeletonSQL := sqldb.NewAliasSkeletonSQL(sqlDB)
+	aliasReservationSQL := sqldb.NewAliasReservationSQL(sqlDB)
+	unshortenHTTP := provider.NewRedirectFollower(client)
+	creatorPersist, err := provider.NewShortLinkCreator(shortLinkSQL, userShortLinkSQL, aliasKeyGenerator, longLink, customAlias, system, detector, flaggedShortLinkSQL, shortLinkChecks, longLinkUniqueness, aliasQuota, authorizerAuthorizer, retry, dispatchHTTP, userPreferencesSQL, maintenanceMode, dataDog, aliasRetryBudget, userRoleSQL, shortLinkLifetime, aliasSkeletonSQL, relationRetry, aliasReservationSQL, reservationTTL, chainedLinkConfig, unshortenHTTP)
+	if err != nil {
+		return web.GraphQL{}, err
+	}
+	updaterPersist, err := provider.NewShortLinkUpdater(shortLinkSQL, userShortLinkSQL, longLink, customAlias, system, detector, shortLinkChecks, aliasSkeletonSQL, flaggedShortLinkSQL, retry, maintenanceMode, aliasReservationSQL, chainedLinkConfig, unshortenHTTP)
+	if err != nil {
+		return web.GraphQL{}, err
+	}
+	deleterPersist := shortlink.NewDeleterPersist(shortLinkSQL, userShortLinkSQL, system, maintenanceMode)
+	changeLogSQL := sqldb.NewChangeLogSQL(sqlDB)
+	userChangeLogSQL := sqldb.NewUserChangeLogSQL(sqlDB)
 	persist := changelog.NewPersist(keyGenerator, system, changeLogSQL, userChangeLogSQL, authorizerAuthorizer)
 	reCaptcha := provider.NewReCaptchaService(http, secret)
 	verifier := provider.NewVerifier(deployment, reCaptcha)
 	tokenizer := provider.NewJwtGo(jwtSecret)
-	authenticator := provider.NewAuthenticator(tokenizer, system, tokenValidDuration)
-	resolverResolver := resolver.NewResolver(loggerLogger, retrieverPersist, creatorPersist, updaterPersist, persist, verifier, authenticator)
+	userSQL := sqldb.NewUserSQL(sqlDB)
+	authenticator := provider.NewAuthenticator(tokenizer, system, tokenValidDuration, userSQL)
+	generator := qrcode.NewGenerator()
+	metaTagPersist := shortlink.NewMetaTagPersist(shortLinkSQL)
+	signer := provider.NewAliasSigner(shareURLSecret)
+	share, err := provider.NewShare(webFrontendURL, shortLinkBaseURL, generator, metaTagPersist, signer)
+	if err != nil {
+		return web.GraphQL{}, err
+	}
+	visitSQL := sqldb.NewVisitSQL(sqlDB)
+	statsPersist := visit.NewStatsPersist(visitSQL, userShortLinkSQL)
+	statusCheckerPersist := shortlink.NewStatusCheckerPersist(shortLinkSQL, userShortLinkSQL, system)
+	linkHealthSQL := sqldb.NewLinkHealthSQL(sqlDB)
+	reporterPersist := linkhealth.NewReporterPersist(shortLinkSQL, userShortLinkSQL, linkHealthSQL, featureToggle)
+	urlValidatorConcurrent := provider.NewURLValidator(longLink, detector, urlValidationWorkers)
+	preferencePreference := preference.NewPreference(userPreferencesSQL)
+	memory := provider.NewRedirectRateLimiter(system, redirectRateLimit)
+	aliasCheckerPersist := shortlink.NewAliasCheckerPersist(shortLinkSQL, customAlias, memory, aliasReservationSQL, system)
+	shortLinkAuditSQL := sqldb.NewShortLinkAuditSQL(sqlDB)
+	expirerPersist := shortlink.NewExpirerPersist(shortLinkSQL, shortLinkAuditSQL, authorizerAuthorizer, system, maintenanceMode)
+	maintenanceSwitch := maintenance.NewSwitch(maintenanceMode, authorizerAuthorizer)
+	featureflagSwitch := featureflag.NewSwitch(featureToggle, authorizerAuthorizer)
+	servicePersist := stats.NewServicePersist(shortLinkSQL, userSQL, visitSQL, flaggedShortLinkSQL, authorizerAuthorizer, system)
+	trusted, err := provider.NewTrustedProxy(trustedProxies)
+	if err != nil {
+		return web.GraphQL{}, err
+	}
+	policy, err := provider.NewAdminIPPolicy(trusted, adminAllowedIPs)
+	if err != nil {
+		return web.GraphQL{}, err
+	}
+	resolverResolver := resolver.NewResolver(logger, retrieverPersist, creatorPersist, updaterPersist, deleterPersist, persist, verifier, authenticator, share, statsPersist, statusCheckerPersist, reporterPersist, urlValidatorConcurrent, domainDenylist, preferencePreference, aliasCheckerPersist, expirerPersist, maintenanceSwitch, featureflagSwitch, servicePersist, policy)
 	api, err := provider.NewShortGraphQLAPI(graphqlSchemaPath, local, resolverResolver)
 	if err != nil {
-		return service.GraphQL{}, err
+		return web.GraphQL{}, err
 	}
 	graphGopherHandler := graphql.NewGraphGopherHandler(api)
+	persistedQueryStore := provider.NewPersistedQueryStore(persistedQueryLimit)
+	hosts, err := provider.NewTenantHosts(tenantHosts)
+	if err != nil {
+		return web.GraphQL{}, err
+	}
+	handler := gqlapi.NewHandler(graphGopherHandler, trusted, persistedQueryStore, hosts)
 	graphiQL := provider.NewGraphiQL(graphqlPath, graphiQLDefaultQuery)
-	graphQL := provider.NewGraphQLService(graphqlPath, graphGopherHandler, graphiQL, loggerLogger)
+	corsPolicy, err := provider.NewCORSPolicy(corsConfig, webFrontendURL)
+	if err != nil {
+		return web.GraphQL{}, err
+	}
+	secheaderPolicy := provider.NewSecurityHeaderPolicy(securityHeaderConfig)
+	graphQL := provider.NewGraphQLService(graphqlPath, handler, graphiQL, logger, corsPolicy, secheaderPolicy, maxRequestBodySize)
 	return graphQL, nil
 }
 
-func InjectRoutingService(runtime2 env.Runtime, prefix provider.LogPrefix, logLevel logger.LogLevel, sqlDB *sql.DB, githubClientID provider.GithubClientID, githubClientSecret provider.GithubClientSecret, facebookClientID provider.FacebookClientID, facebookClientSecret provider.FacebookClientSecret, facebookRedirectURI provider.FacebookRedirectURI, googleClientID provider.GoogleClientID, googleClientSecret provider.GoogleClientSecret, googleRedirectURI provider.GoogleRedirectURI, jwtSecret provider.JwtSecret, bufferSize provider.KeyGenBufferSize, kgsRPCConfig provider.KgsRPCConfig, webFrontendURL provider.WebFrontendURL, tokenValidDuration provider.TokenValidDuration, searchTimeout provider.SearchTimeout, swaggerUIDir provider.SwaggerUIDir, openAPISpecPath provider.OpenAPISpecPath, dataDogAPIKey provider.DataDogAPIKey, segmentAPIKey provider.SegmentAPIKey, ipStackAPIKey provider.IPStackAPIKey) (service.Routing, error) {
+func InjectRoutingService(runtime2 env.Runtime, prefix provider.LogPrefix, logLevel provider.LogLevel, sqlDB *sql.DB, slowQueryThreshold provider.SlowQueryThreshold, githubClientID provider.GithubClientID, githubClientSecret provider.GithubClientSecret, facebookClientID provider.FacebookClientID, facebookClientSecret provider.FacebookClientSecret, facebookRedirectURI provider.FacebookRedirectURI, googleClientID provider.GoogleClientID, googleClientSecret provider.GoogleClientSecret, googleRedirectURI provider.GoogleRedirectURI, twitterClientID provider.TwitterClientID, twitterClientSecret provider.TwitterClientSecret, twitterRedirectURI provider.TwitterRedirectURI, appleClientID provider.AppleClientID, appleRedirectURI provider.AppleRedirectURI, jwtSecret provider.JwtSecret, bufferSize provider.KeyGenBufferSize, keyGenBatchBounds provider.KeyGenBatchBounds, kgsRPCConfig provider.KgsRPCConfig, kgsConnectRetry provider.KgsConnectRetry, keyGenStrategy provider.KeyGenStrategy, hashidsSalt provider.HashidsSalt, keyGenWordsConfig provider.KeyGenWordsConfig, webFrontendURL provider.WebFrontendURL, tokenValidDuration provider.TokenValidDuration, searchTimeout provider.SearchTimeout, swaggerUIDir provider.SwaggerUIDir, openAPISpecPath provider.OpenAPISpecPath, dataDogAPIKey provider.DataDogAPIKey, segmentAPIKey provider.SegmentAPIKey, ipStackAPIKey provider.IPStackAPIKey, visitorIPMode provider.VisitorIPMode, visitorDetails provider.VisitorDetails, redirectRateLimit provider.RedirectRateLimit, googleAPIKey provider.GoogleAPIKey, riskThresholds provider.RiskThresholds, allowedDomains provider.LongLinkAllowedDomains, domainDenylistPath provider.DomainDenylistPath, corsConfig provider.CORSConfig, shortLinkChecks provider.ShortLinkChecks, shortLinkDomains provider.ShortLinkDomains, maxRequestBodySize provider.MaxRequestBodySize, errorPageConfig provider.ErrorPageConfig, trustedProxies provider.TrustedProxies, guestAttributionEnabled provider.GuestAttributionEnabled, guestCreateCooldown provider.GuestCreateCooldown, longLinkUniqueness provider.LongLinkUniqueness, aliasUnicodeCategories provider.CustomAliasUnicodeCategories, riskBreakerConfig provider.RiskCircuitBreakerConfig, aliasQuota provider.AliasQuota, smtpConfig provider.SMTPConfig, webhookURL provider.WebhookURL, signInRateLimit provider.SignInRateLimit, passwordResetRateLimit provider.PasswordResetRateLimit, passwordPolicyConfig provider.PasswordPolicyConfig, securityHeaderConfig provider.SecurityHeaderConfig, shortLinkBaseURL provider.ShortLinkBaseURL, longLinkFragment provider.LongLinkFragment, redirectLogSamplePercent provider.RedirectLogSamplePercent, maintenanceMode maintenance.Mode, profilingEnabled provider.ProfilingEnabled, aliasRetryBudget provider.AliasRetryBudget, relationRetry provider.RelationRetry, reservationTTL provider.AliasReservationTTL, chainedLinkConfig provider.ChainedLinkConfig, shareURLSecret provider.ShareURLSecret, visitBuffer provider.VisitBufferConfig, aliasPrefix provider.AliasPrefix, longLinkPlainHTTP provider.LongLinkPlainHTTP, shortLinkLifetime provider.ShortLinkLifetime, expiryGrace provider.ShortLinkExpiryGrace, adminAllowedIPs provider.AdminAllowedIPs, outboundLimiter outbound.Limiter, queryConflict provider.QueryConflict, redirectConfig provider.RedirectConfig, linkRateLimitWindow provider.LinkRateLimitWindow, domainRedirects provider.DomainRedirects, featureToggle featureflag.OverrideToggle, tenantHosts provider.TenantHosts) (web.Routing, error) {
 	system := timer.NewSystem()
 	program := runtime.NewProgram()
 	deployment := env.NewDeployment(runtime2)
 	stdOut := io.NewStdOut()
-	client := webreq.NewHTTPClient()
+	client := provider.NewOutboundHTTPClient(outboundLimiter)
 	http := webreq.NewHTTP(client)
 	entryRepository := provider.NewEntryRepositorySwitch(runtime2, deployment, stdOut, dataDogAPIKey, http)
-	loggerLogger := provider.NewLogger(prefix, logLevel, system, program, entryRepository)
+	logger, err := provider.NewLogger(prefix, logLevel, system, program, entryRepository)
+	if err != nil {
+		return web.Routing{}, err
+	}
 	dataDog := provider.NewDataDogMetrics(dataDogAPIKey, http, system, runtime2)
-	segment := provider.NewSegment(segmentAPIKey, system, loggerLogger)
-	rpc, err := provider.NewKgsRPC(kgsRPCConfig)
+	segment := provider.NewSegment(segmentAPIKey, system, logger)
+	rpc, err := provider.NewKgsRPC(kgsRPCConfig, kgsConnectRetry)
 	if err != nil {
-		return service.Routing{}, err
+		return web.Routing{}, err
 	}
-	keyGenerator, err := provider.NewKeyGenerator(bufferSize, rpc)
+	keyCounterSQL := sqldb.NewKeyCounterSQL(sqlDB)
+	keyGenerator, err := provider.NewKeyGenerator(keyGenStrategy, bufferSize, keyGenBatchBounds, system, rpc, keyCounterSQL, hashidsSalt)
 	if err != nil {
-		return service.Routing{}, err
+		return web.Routing{}, err
 	}
-	proxy := network.NewProxy()
-	ipStack := provider.NewIPStack(ipStackAPIKey, http, loggerLogger)
-	requestClient := request.NewClient(proxy, ipStack)
-	instrumentationFactory := request.NewInstrumentationFactory(loggerLogger, system, dataDog, segment, keyGenerator, requestClient)
-	shortLinkSQL := sqldb.NewShortLinkSQL(sqlDB)
-	userShortLinkSQL := sqldb.NewUserShortLinkSQL(sqlDB)
-	retrieverPersist := shortlink.NewRetrieverPersist(shortLinkSQL, userShortLinkSQL)
-	featureToggleSQL := sqldb.NewFeatureToggleSQL(sqlDB)
+	trusted, err := provider.NewTrustedProxy(trustedProxies)
+	if err != nil {
+		return web.Routing{}, err
+	}
+	ipStack := provider.NewIPStack(ipStackAPIKey, http, logger)
+	requestClient := request.NewClient(trusted, ipStack)
+	instrumentationFactory := request.NewInstrumentationFactory(logger, system, dataDog, segment, keyGenerator, requestClient)
+	slowQueryLog := provider.NewSlowQueryLog(slowQueryThreshold, logger, system, dataDog)
+	shortLinkSQL := sqldb.NewShortLinkSQL(sqlDB, slowQueryLog)
+	userShortLinkSQL := sqldb.NewUserShortLinkSQL(sqlDB, slowQueryLog)
+	retrieverPersist, err := provider.NewShortLinkRetriever(shortLinkSQL, userShortLinkSQL, expiryGrace)
+	if err != nil {
+		return web.Routing{}, err
+	}
+	local := filesystem.NewLocal()
+	aliasKeyGenerator, err := provider.NewAliasKeyGenerator(keyGenStrategy, keyGenerator, shortLinkSQL, local, keyGenWordsConfig, aliasPrefix)
+	if err != nil {
+		return web.Routing{}, err
+	}
+	longLink, err := provider.NewLongLinkValidator(allowedDomains, shortLinkDomains, webFrontendURL, longLinkFragment, longLinkPlainHTTP)
+	if err != nil {
+		return web.Routing{}, err
+	}
+	customAlias, err := provider.NewCustomAliasValidator(aliasUnicodeCategories, aliasPrefix)
+	if err != nil {
+		return web.Routing{}, err
+	}
+	safeBrowsing := provider.NewSafeBrowsing(googleAPIKey, http)
+	circuitBreaker := provider.NewRiskCircuitBreaker(safeBrowsing, system, logger, riskBreakerConfig)
 	userRoleSQL := sqldb.NewUserRoleSQL(sqlDB)
 	rbacRBAC := rbac.NewRBAC(userRoleSQL)
 	authorizerAuthorizer := authorizer.NewAuthorizer(rbacRBAC)
+	domainDenylist, err := provider.NewDomainDenylist(local, domainDenylistPath, authorizerAuthorizer)
+	if err != nil {
+		return web.Routing{}, err
+	}
+	detector := provider.NewRiskDetector(circuitBreaker, domainDenylist, riskThresholds)
+	flaggedShortLinkSQL := sqldb.NewFlaggedShortLinkSQL(sqlDB)
+	retry := provider.NewEmailSender(system, logger, smtpConfig)
+	dispatchHTTP := provider.NewWebhookDispatcher(logger, webhookURL)
+	userPreferencesSQL := sqldb.NewUserPreferencesSQL(sqlDB)
+	aliasSkeletonSQL := sqldb.NewAliasSkeletonSQL(sqlDB)
+	aliasReservationSQL := sqldb.NewAliasReservationSQL(sqlDB)
+	unshortenHTTP := provider.NewRedirectFollower(client)
+	creatorPersist, err := provider.NewShortLinkCreator(shortLinkSQL, userShortLinkSQL, aliasKeyGenerator, longLink, customAlias, system, detector, flaggedShortLinkSQL, shortLinkChecks, longLinkUniqueness, aliasQuota, authorizerAuthorizer, retry, dispatchHTTP, userPreferencesSQL, maintenanceMode, dataDog, aliasRetryBudget, userRoleSQL, shortLinkLifetime, aliasSkeletonSQL, relationRetry, aliasReservationSQL, reservationTTL, chainedLinkConfig, unshortenHTTP)
+	if err != nil {
+		return web.Routing{}, err
+	}
+	parser := useragent.NewParser()
+	visitSQL := sqldb.NewVisitSQL(sqlDB)
+	buffer := provider.NewVisitBuffer(visitSQL, ipStack, visitorIPMode, system, logger, visitBuffer)
+	countBuffer := provider.NewVisitCountBuffer(shortLinkSQL, system, logger, visitBuffer)
+	trackerPersist := provider.NewVisitTracker(system, visitorDetails, parser, buffer, countBuffer)
+	memory := provider.NewRedirectRateLimiter(system, redirectRateLimit)
+	linkRateLimiter := provider.NewLinkRateLimiter(system, linkRateLimitWindow)
+	featureToggleSQL := sqldb.NewFeatureToggleSQL(sqlDB)
 	decisionMakerFactory := provider.NewFeatureDecisionMakerFactorySwitch(deployment, featureToggleSQL, authorizerAuthorizer)
 	tokenizer := provider.NewJwtGo(jwtSecret)
-	authenticator := provider.NewAuthenticator(tokenizer, system, tokenValidDuration)
-	factory := sso.NewFactory(authenticator)
 	userSQL := sqldb.NewUserSQL(sqlDB)
-	accountLinkerFactory := sso.NewAccountLinkerFactory(keyGenerator, userSQL)
-	githubSSOSql := sqldb.NewGithubSSOSql(sqlDB, loggerLogger)
+	authenticator := provider.NewAuthenticator(tokenizer, system, tokenValidDuration, userSQL)
+	factory := sso.NewFactory(authenticator)
+	emailVerifier, err := provider.NewEmailVerifier(tokenizer, system, userSQL, retry, webFrontendURL)
+	if err != nil {
+		return web.Routing{}, err
+	}
+	accountLinkerFactory := sso.NewAccountLinkerFactory(keyGenerator, userSQL, emailVerifier)
+	githubSSOSql := sqldb.NewGithubSSOSql(sqlDB, logger)
 	accountLinker := provider.NewGithubAccountLinker(accountLinkerFactory, githubSSOSql)
 	identityProvider := provider.NewGithubIdentityProvider(http, githubClientID, githubClientSecret)
 	clientFactory := graphql.NewClientFactory(http)
@@ -179,26 +365,86 @@ func InjectRoutingService(runtime2 env.Runtime, prefix provider.LogPrefix, logLe
 	singleSignOn := provider.NewGithubSSO(factory, accountLinker, identityProvider, account)
 	facebookIdentityProvider := provider.NewFacebookIdentityProvider(http, facebookClientID, facebookClientSecret, facebookRedirectURI)
 	facebookAccount := facebook.NewAccount(http)
-	facebookSSOSql := sqldb.NewFacebookSSOSql(sqlDB, loggerLogger)
+	facebookSSOSql := sqldb.NewFacebookSSOSql(sqlDB, logger)
 	facebookAccountLinker := provider.NewFacebookAccountLinker(accountLinkerFactory, facebookSSOSql)
 	facebookSingleSignOn := provider.NewFacebookSSO(factory, facebookIdentityProvider, facebookAccount, facebookAccountLinker)
 	googleIdentityProvider := provider.NewGoogleIdentityProvider(http, googleClientID, googleClientSecret, googleRedirectURI)
 	googleAccount := google.NewAccount(http)
-	googleSSOSql := sqldb.NewGoogleSSOSql(sqlDB, loggerLogger)
+	googleSSOSql := sqldb.NewGoogleSSOSql(sqlDB, logger)
 	googleAccountLinker := provider.NewGoogleAccountLinker(accountLinkerFactory, googleSSOSql)
 	googleSingleSignOn := provider.NewGoogleSSO(factory, googleIdentityProvider, googleAccount, googleAccountLinker)
-	search := provider.NewSearch(loggerLogger, shortLinkSQL, userShortLinkSQL, searchTimeout)
-	v := provider.NewShortRoutes(instrumentationFactory, webFrontendURL, system, retrieverPersist, decisionMakerFactory, singleSignOn, facebookSingleSignOn, googleSingleSignOn, authenticator, search, swaggerUIDir, openAPISpecPath)
-	routing := service.NewRouting(loggerLogger, v)
+	twitterIdentityProvider := provider.NewTwitterIdentityProvider(http, twitterClientID, twitterClientSecret, twitterRedirectURI)
+	twitterAccount := twitter.NewAccount(http)
+	twitterSSOSql := sqldb.NewTwitterSSOSql(sqlDB, logger)
+	twitterAccountLinker := provider.NewTwitterAccountLinker(accountLinkerFactory, twitterSSOSql)
+	twitterSingleSignOn := provider.NewTwitterSSO(factory, twitterIdentityProvider, twitterAccount, twitterAccountLinker)
+	appleIdentityProvider := provider.NewAppleIdentityProvider(appleClientID, appleRedirectURI)
+	appleAccount := provider.NewAppleAccount(http, system, appleClientID)
+	appleSSOSql := sqldb.NewAppleSSOSql(sqlDB, logger)
+	appleAccountLinker := provider.NewAppleAccountLinker(accountLinkerFactory, appleSSOSql)
+	appleSingleSignOn := provider.NewAppleSSO(factory, appleIdentityProvider, appleAccount, appleAccountLinker)
+	search := provider.NewSearch(logger, shortLinkSQL, userShortLinkSQL, searchTimeout)
+	errorPages, err := provider.NewErrorPages(local, errorPageConfig)
+	if err != nil {
+		return web.Routing{}, err
+	}
+	guestSessionPersist := shortlink.NewGuestSessionPersist(shortLinkSQL, userShortLinkSQL)
+	guestAttribution := provider.NewGuestAttribution(guestAttributionEnabled, guestSessionPersist, guestCreateCooldown, system, trusted)
+	userPasswordSQL := sqldb.NewUserPasswordSQL(sqlDB)
+	passwordPolicy, err := provider.NewPasswordPolicy(passwordPolicyConfig)
+	if err != nil {
+		return web.Routing{}, err
+	}
+	emailpasswordAccount := provider.NewEmailPasswordAccount(keyGenerator, userSQL, userPasswordSQL, passwordPolicy, authenticator, emailVerifier, system, signInRateLimit)
+	passwordReset, err := provider.NewPasswordReset(tokenizer, system, userSQL, userPasswordSQL, passwordPolicy, retry, webFrontendURL, passwordResetRateLimit, logger)
+	if err != nil {
+		return web.Routing{}, err
+	}
+	generator := qrcode.NewGenerator()
+	metaTagPersist := shortlink.NewMetaTagPersist(shortLinkSQL)
+	signer := provider.NewAliasSigner(shareURLSecret)
+	share, err := provider.NewShare(webFrontendURL, shortLinkBaseURL, generator, metaTagPersist, signer)
+	if err != nil {
+		return web.Routing{}, err
+	}
+	policy, err := provider.NewAdminIPPolicy(trusted, adminAllowedIPs)
+	if err != nil {
+		return web.Routing{}, err
+	}
+	shortlinkQueryConflict, err := provider.NewQueryConflict(queryConflict)
+	if err != nil {
+		return web.Routing{}, err
+	}
+	redirect, err := provider.NewRedirect(redirectConfig)
+	if err != nil {
+		return web.Routing{}, err
+	}
+	canonicalDomain, err := provider.NewCanonicalDomain(domainRedirects)
+	if err != nil {
+		return web.Routing{}, err
+	}
+	servicePersist := stats.NewServicePersist(shortLinkSQL, userSQL, visitSQL, flaggedShortLinkSQL, authorizerAuthorizer, system)
+	linked := buildinfo.NewLinked()
+	hosts, err := provider.NewTenantHosts(tenantHosts)
+	if err != nil {
+		return web.Routing{}, err
+	}
+	v := provider.NewShortRoutes(instrumentationFactory, webFrontendURL, system, retrieverPersist, creatorPersist, trackerPersist, trusted, memory, linkRateLimiter, decisionMakerFactory, singleSignOn, facebookSingleSignOn, googleSingleSignOn, twitterSingleSignOn, appleSingleSignOn, authenticator, search, swaggerUIDir, openAPISpecPath, errorPages, guestAttribution, emailVerifier, emailpasswordAccount, passwordReset, share, authorizerAuthorizer, profilingEnabled, signer, policy, shortlinkQueryConflict, redirect, canonicalDomain, servicePersist, linked, featureToggle, hosts)
+	corsPolicy, err := provider.NewCORSPolicy(corsConfig, webFrontendURL)
+	if err != nil {
+		return web.Routing{}, err
+	}
+	secheaderPolicy := provider.NewSecurityHeaderPolicy(securityHeaderConfig)
+	routing := provider.NewRoutingService(logger, v, corsPolicy, secheaderPolicy, maxRequestBodySize, redirectLogSamplePercent, buffer, countBuffer, passwordReset)
 	return routing, nil
 }
 
-func InjectDataTool(prefix provider.LogPrefix, logLevel logger.LogLevel, dbConfig db.Config, dbConnector db.Connector, bufferSize provider.KeyGenBufferSize, kgsRPCConfig provider.KgsRPCConfig) (tool.Data, error) {
-	rpc, err := provider.NewKgsRPC(kgsRPCConfig)
+func InjectDataTool(prefix provider.LogPrefix, logLevel provider.LogLevel, dbConfig db.Config, dbConnector db.Connector, bufferSize provider.KeyGenBufferSize, kgsRPCConfig provider.KgsRPCConfig, kgsConnectRetry provider.KgsConnectRetry) (tool.Data, error) {
+	rpc, err := provider.NewKgsRPC(kgsRPCConfig, kgsConnectRetry)
 	if err != nil {
 		return tool.Data{}, err
 	}
-	keyGenerator, err := provider.NewKeyGenerator(bufferSize, rpc)
+	remote, err := provider.NewRemoteKeyGenerator(bufferSize, rpc)
 	if err != nil {
 		return tool.Data{}, err
 	}
@@ -206,21 +452,74 @@ func InjectDataTool(prefix provider.LogPrefix, logLevel logger.LogLevel, dbConfi
 	program := runtime.NewProgram()
 	stdOut := io.NewStdOut()
 	local := provider.NewLocalEntryRepo(stdOut)
-	loggerLogger := provider.NewLogger(prefix, logLevel, system, program, local)
-	data, err := tool.NewData(dbConfig, dbConnector, keyGenerator, loggerLogger)
+	logger, err := provider.NewLogger(prefix, logLevel, system, program, local)
+	if err != nil {
+		return tool.Data{}, err
+	}
+	data, err := tool.NewData(dbConfig, dbConnector, remote, logger)
 	if err != nil {
 		return tool.Data{}, err
 	}
 	return data, nil
 }
 
+func InjectImportTool(prefix provider.LogPrefix, logLevel provider.LogLevel, sqlDB *sql.DB, allowedDomains provider.LongLinkAllowedDomains, shortLinkDomains provider.ShortLinkDomains, webFrontendURL provider.WebFrontendURL, aliasUnicodeCategories provider.CustomAliasUnicodeCategories, longLinkFragment provider.LongLinkFragment, aliasPrefix provider.AliasPrefix, longLinkPlainHTTP provider.LongLinkPlainHTTP) (tool.Import, error) {
+	slowQueryLog := _wireSlowQueryLogValue
+	shortLinkSQL := sqldb.NewShortLinkSQL(sqlDB, slowQueryLog)
+	userShortLinkSQL := sqldb.NewUserShortLinkSQL(sqlDB, slowQueryLog)
+	longLink, err := provider.NewLongLinkValidator(allowedDomains, shortLinkDomains, webFrontendURL, longLinkFragment, longLinkPlainHTTP)
+	if err != nil {
+		return tool.Import{}, err
+	}
+	customAlias, err := provider.NewCustomAliasValidator(aliasUnicodeCategories, aliasPrefix)
+	if err != nil {
+		return tool.Import{}, err
+	}
+	system := timer.NewSystem()
+	importerPersist := shortlink.NewImporterPersist(shortLinkSQL, userShortLinkSQL, longLink, customAlias, system)
+	userSQL := sqldb.NewUserSQL(sqlDB)
+	program := runtime.NewProgram()
+	stdOut := io.NewStdOut()
+	local := provider.NewLocalEntryRepo(stdOut)
+	logger, err := provider.NewLogger(prefix, logLevel, system, program, local)
+	if err != nil {
+		return tool.Import{}, err
+	}
+	toolImport := tool.NewImport(importerPersist, userSQL, logger)
+	return toolImport, nil
+}
+
+var (
+	_wireSlowQueryLogValue = sqldb.SlowQueryLog{}
+)
+
+func InjectPruneTool(prefix provider.LogPrefix, logLevel provider.LogLevel, sqlDB *sql.DB) (tool.Prune, error) {
+	slowQueryLog := _wireSqldbSlowQueryLogValue
+	userShortLinkSQL := sqldb.NewUserShortLinkSQL(sqlDB, slowQueryLog)
+	orphanPrunerPersist := shortlink.NewOrphanPrunerPersist(userShortLinkSQL)
+	system := timer.NewSystem()
+	program := runtime.NewProgram()
+	stdOut := io.NewStdOut()
+	local := provider.NewLocalEntryRepo(stdOut)
+	logger, err := provider.NewLogger(prefix, logLevel, system, program, local)
+	if err != nil {
+		return tool.Prune{}, err
+	}
+	prune := tool.NewPrune(orphanPrunerPersist, logger)
+	return prune, nil
+}
+
+var (
+	_wireSqldbSlowQueryLogValue = sqldb.SlowQueryLog{}
+)
+
 // wire.go:
 
 var authenticatorSet = wire.NewSet(provider.NewJwtGo, provider.NewAuthenticator)
 
 var authorizerSet = wire.NewSet(wire.Bind(new(repository.UserRole), new(sqldb.UserRoleSQL)), sqldb.NewUserRoleSQL, rbac.NewRBAC, authorizer.NewAuthorizer)
 
-var observabilitySet = wire.NewSet(wire.Bind(new(io.Output), new(io.StdOut)), wire.Bind(new(runtime.Runtime), new(runtime.Program)), wire.Bind(new(metrics.Metrics), new(metrics.DataDog)), wire.Bind(new(analytics.Analytics), new(analytics.Segment)), wire.Bind(new(network.Network), new(network.Proxy)), io.NewStdOut, provider.NewEntryRepositorySwitch, provider.NewLogger, runtime.NewProgram, provider.NewDataDogMetrics, provider.NewSegment, network.NewProxy, request.NewClient, request.NewInstrumentationFactory)
+var observabilitySet = wire.NewSet(wire.Bind(new(io.Output), new(io.StdOut)), wire.Bind(new(runtime.Runtime), new(runtime.Program)), wire.Bind(new(metrics.Metrics), new(metrics.DataDog)), wire.Bind(new(analytics.Analytics), new(analytics.Segment)), wire.Bind(new(network.Network), new(proxy.Trusted)), io.NewStdOut, provider.NewEntryRepositorySwitch, provider.NewLogger, runtime.NewProgram, provider.NewDataDogMetrics, provider.NewSegment, provider.NewTrustedProxy, request.NewClient, request.NewInstrumentationFactory)
 
 var githubAPISet = wire.NewSet(provider.NewGithubIdentityProvider, github.NewAccount, github.NewAPI)
 
@@ -228,6 +527,14 @@ var facebookAPISet = wire.NewSet(provider.NewFacebookIdentityProvider, facebook.
 
 var googleAPISet = wire.NewSet(provider.NewGoogleIdentityProvider, google.NewAccount, google.NewAPI)
 
-var keyGenSet = wire.NewSet(wire.Bind(new(keygen.KeyFetcher), new(kgs.RPC)), provider.NewKgsRPC, provider.NewKeyGenerator)
+var twitterAPISet = wire.NewSet(provider.NewTwitterIdentityProvider, twitter.NewAccount, twitter.NewAPI)
+
+var appleAPISet = wire.NewSet(provider.NewAppleIdentityProvider, provider.NewAppleAccount, apple.NewAPI)
+
+var keyGenSet = wire.NewSet(wire.Bind(new(keygen.KeyFetcher), new(kgs.RPC)), wire.Bind(new(repository.KeyCounter), new(sqldb.KeyCounterSQL)), provider.NewKgsRPC, sqldb.NewKeyCounterSQL, provider.NewKeyGenerator, provider.NewAliasKeyGenerator)
+
+var remoteKeyGenSet = wire.NewSet(wire.Bind(new(keygen.KeyFetcher), new(kgs.RPC)), wire.Bind(new(keygen.KeyGenerator), new(keygen.Remote)), provider.NewKgsRPC, provider.NewRemoteKeyGenerator)
+
+var shortLinkCreatorSet = wire.NewSet(wire.Bind(new(risk.BlackList), new(risk.CircuitBreaker)), wire.Bind(new(risk.Denylist), new(risk.DomainDenylist)), wire.Bind(new(repository.FlaggedShortLink), new(sqldb.FlaggedShortLinkSQL)), wire.Bind(new(repository.AliasSkeleton), new(sqldb.AliasSkeletonSQL)), wire.Bind(new(repository.AliasReservation), new(sqldb.AliasReservationSQL)), wire.Bind(new(repository.UserPreferences), new(sqldb.UserPreferencesSQL)), wire.Bind(new(shortlink.Creator), new(shortlink.CreatorPersist)), wire.Bind(new(email.Sender), new(email.Retry)), wire.Bind(new(webhook.Dispatcher), new(dispatch.HTTP)), wire.Bind(new(shortlink.RedirectFollower), new(unshorten.HTTP)), provider.NewSafeBrowsing, provider.NewRiskCircuitBreaker, provider.NewRiskDetector, provider.NewDomainDenylist, sqldb.NewFlaggedShortLinkSQL, sqldb.NewAliasSkeletonSQL, sqldb.NewAliasReservationSQL, sqldb.NewUserPreferencesSQL, provider.NewLongLinkValidator, provider.NewCustomAliasValidator, provider.NewShortLinkCreator, provider.NewEmailSender, provider.NewWebhookDispatcher, provider.NewRedirectFollower)
 
 var featureDecisionSet = wire.NewSet(wire.Bind(new(repository.FeatureToggle), new(sqldb.FeatureToggleSQL)), sqldb.NewFeatureToggleSQL, provider.NewFeatureDecisionMakerFactorySwitch)
@@ -11,11 +11,14 @@ require (
 	github.com/short-d/app v0.0.0-20200627081605-eabc0539025f
 	github.com/short-d/eventbus v0.0.0-20200515152349-a8a7cb883a47 // indirect
 	github.com/short-d/kgs v0.0.0-20200505215800-7d538f015ea1
+	github.com/skip2/go-qrcode v0.0.0-20200617195104-da1b6568686e
 	github.com/spf13/cobra v1.0.0 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
 	github.com/stretchr/testify v1.5.1 // indirect
+	golang.org/x/crypto v0.0.0-20191011191535-87dc89f01550
 	golang.org/x/net v0.0.0-20200513185701-a91f0712d120 // indirect
 	golang.org/x/sys v0.0.0-20200515095857-1151b9dac4a9 // indirect
+	golang.org/x/text v0.3.2
 	google.golang.org/genproto v0.0.0-20200515170657-fc4c6c6a6587 // indirect
 	google.golang.org/grpc v1.29.1
 	google.golang.org/protobuf v1.23.0
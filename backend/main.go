@@ -1,11 +1,11 @@
 package main
 
 import (
+	"strings"
 	"time"
 
 	"github.com/short-d/app/fw/db"
 	"github.com/short-d/app/fw/envconfig"
-	"github.com/short-d/app/fw/logger"
 	"github.com/short-d/short/backend/app"
 	"github.com/short-d/short/backend/cmd"
 	"github.com/short-d/short/backend/dep"
@@ -19,11 +19,16 @@ func main() {
 
 	config := struct {
 		Runtime              string        `env:"ENV" default:"development"`
+		LogLevel             string        `env:"LOG_LEVEL" default:"info"`
 		DBHost               string        `env:"DB_HOST" default:"localhost"`
 		DBPort               int           `env:"DB_PORT" default:"5432"`
 		DBUser               string        `env:"DB_USER" default:"postgres"`
 		DBPassword           string        `env:"DB_PASSWORD" default:"password"`
 		DBName               string        `env:"DB_NAME" default:"short"`
+		DBMaxOpenConns       int           `env:"DB_MAX_OPEN_CONNS" default:"25"`
+		DBMaxIdleConns       int           `env:"DB_MAX_IDLE_CONNS" default:"25"`
+		DBConnMaxLifetime    time.Duration `env:"DB_CONN_MAX_LIFETIME" default:"5m"`
+		DBSlowQueryThreshold time.Duration `env:"DB_SLOW_QUERY_THRESHOLD" default:"500ms"`
 		ReCaptchaSecret      string        `env:"RECAPTCHA_SECRET" default:""`
 		GithubClientID       string        `env:"GITHUB_CLIENT_ID" default:""`
 		GithubClientSecret   string        `env:"GITHUB_CLIENT_SECRET" default:""`
@@ -33,11 +38,24 @@ func main() {
 		GoogleClientID       string        `env:"GOOGLE_CLIENT_ID" default:""`
 		GoogleClientSecret   string        `env:"GOOGLE_CLIENT_SECRET" default:""`
 		GoogleRedirectURI    string        `env:"GOOGLE_REDIRECT_URI" default:""`
+		TwitterClientID      string        `env:"TWITTER_CLIENT_ID" default:""`
+		TwitterClientSecret  string        `env:"TWITTER_CLIENT_SECRET" default:""`
+		TwitterRedirectURI   string        `env:"TWITTER_REDIRECT_URI" default:""`
+		AppleClientID        string        `env:"APPLE_CLIENT_ID" default:""`
+		AppleRedirectURI     string        `env:"APPLE_REDIRECT_URI" default:""`
 		JWTSecret            string        `env:"JWT_SECRET" default:""`
 		WebFrontendURL       string        `env:"WEB_FRONTEND_URL" default:""`
+		ShortLinkBaseURL     string        `env:"SHORT_LINK_BASE_URL" default:""`
+		LongLinkFragment     string        `env:"LONG_LINK_FRAGMENT" default:"preserve"`
 		KeyGenBufferSize     int           `env:"KEY_GEN_BUFFER_SIZE" default:"50"`
+		KeyGenBatchMinSize   int           `env:"KEY_GEN_BATCH_MIN_SIZE" default:"0"`
+		KeyGenBatchMaxSize   int           `env:"KEY_GEN_BATCH_MAX_SIZE" default:"0"`
 		KgsHostname          string        `env:"KEY_GEN_HOSTNAME" default:"localhost"`
 		KgsPort              int           `env:"KEY_GEN_PORT" default:"8080"`
+		KgsMaxAttempts       int           `env:"KEY_GEN_CONNECT_MAX_ATTEMPTS" default:"5"`
+		KgsInitialBackoff    time.Duration `env:"KEY_GEN_CONNECT_BACKOFF" default:"1s"`
+		KgsMaxBackoff        time.Duration `env:"KEY_GEN_CONNECT_MAX_BACKOFF" default:"30s"`
+		KgsDialTimeout       time.Duration `env:"KEY_GEN_DIAL_TIMEOUT" default:"5s"`
 		GraphQLAPIPort       int           `env:"GRAPHQL_API_PORT" default:"8080"`
 		HTTPAPIPort          int           `env:"HTTP_API_PORT" default:"80"`
 		GRPCAPIPort          int           `env:"GRPC_API_PORT" default:"8081"`
@@ -54,6 +72,94 @@ func main() {
 		SegmentAPIKey        string        `env:"SEGMENT_API_KEY" default:""`
 		IPStackAPIKey        string        `env:"IP_STACK_API_KEY" default:""`
 		GoogleAPIKey         string        `env:"GOOGLE_API_KEY" default:""`
+		VisitorIPMode        string        `env:"VISITOR_IP_MODE" default:"anonymized"`
+		VisitorReferrer      bool          `env:"VISITOR_REFERRER" default:"true"`
+		VisitorUserAgent     bool          `env:"VISITOR_USER_AGENT" default:"true"`
+		VisitorCampaign      bool          `env:"VISITOR_CAMPAIGN" default:"true"`
+		LinkHealthInterval   time.Duration `env:"LINK_HEALTH_CHECK_INTERVAL" default:"1m"`
+		LinkHealthBatchSize  int           `env:"LINK_HEALTH_BATCH_SIZE" default:"10"`
+		LinkHealthThreshold  int           `env:"LINK_HEALTH_FAILURE_THRESHOLD" default:"3"`
+		LinkHealthTimeout    time.Duration `env:"LINK_HEALTH_PROBE_TIMEOUT" default:"5s"`
+		FeatureFlagConfig    string        `env:"FEATURE_FLAG_CONFIG_PATH" default:"config/featureflag.json"`
+		RiskBlockThreshold   int           `env:"RISK_BLOCK_THRESHOLD" default:"50"`
+		RiskWarnThreshold    int           `env:"RISK_WARN_THRESHOLD" default:"50"`
+		RiskBreakerThreshold int           `env:"RISK_BREAKER_FAILURE_THRESHOLD" default:"5"`
+		RiskBreakerCooldown  time.Duration `env:"RISK_BREAKER_COOLDOWN" default:"30s"`
+		AllowedDomains       string        `env:"LONG_LINK_ALLOWED_DOMAINS" default:""`
+		KeyGenStrategy       string        `env:"KEY_GEN_STRATEGY" default:"random"`
+		HashidsSalt          string        `env:"HASHIDS_SALT" default:""`
+		KeyGenWordListPath   string        `env:"KEY_GEN_WORD_LIST_PATH" default:"config/words.txt"`
+		KeyGenWordCount      int           `env:"KEY_GEN_WORD_COUNT" default:"3"`
+		KeyGenWordSeparator  string        `env:"KEY_GEN_WORD_SEPARATOR" default:"-"`
+		DomainDenylistPath   string        `env:"DOMAIN_DENYLIST_PATH" default:"config/denylist.txt"`
+		RedirectRateLimit    int           `env:"REDIRECT_RATE_LIMIT" default:"0"`
+		RedirectRateWindow   time.Duration `env:"REDIRECT_RATE_LIMIT_WINDOW" default:"1m"`
+		LinkRateWindow       time.Duration `env:"LINK_REDIRECT_LIMIT_WINDOW" default:"1m"`
+		CORSAllowedOrigins   string        `env:"CORS_ALLOWED_ORIGINS" default:""`
+		CORSAllowedMethods   string        `env:"CORS_ALLOWED_METHODS" default:"GET,POST"`
+		CORSAllowedHeaders   string        `env:"CORS_ALLOWED_HEADERS" default:"Accept,Content-Type,Authorization"`
+		CORSCredentials      bool          `env:"CORS_ALLOW_CREDENTIALS" default:"false"`
+		ContentSecPolicy     string        `env:"CONTENT_SECURITY_POLICY" default:""`
+		ReferrerPolicy       string        `env:"REFERRER_POLICY" default:""`
+		RedirectReferrer     string        `env:"REDIRECT_REFERRER_POLICY" default:""`
+		ShortLinkChecks      string        `env:"SHORT_LINK_CHECKS" default:"custom_alias,long_link,risk"`
+		ShortLinkDomains     string        `env:"SHORT_LINK_DOMAINS" default:""`
+		MaxRequestBodySize   int           `env:"MAX_REQUEST_BODY_SIZE" default:"1048576"`
+		RedirectLogSampling  int           `env:"REDIRECT_LOG_SAMPLE_PERCENT" default:"100"`
+		NotFoundPage         string        `env:"NOT_FOUND_PAGE" default:""`
+		ExpiredPage          string        `env:"EXPIRED_PAGE" default:""`
+		URLValidationWorkers int           `env:"URL_VALIDATION_WORKERS" default:"10"`
+		BrandProductName     string        `env:"BRAND_PRODUCT_NAME" default:""`
+		BrandLogoURL         string        `env:"BRAND_LOGO_URL" default:""`
+		BrandPrimaryColor    string        `env:"BRAND_PRIMARY_COLOR" default:""`
+		TrustedProxies       string        `env:"TRUSTED_PROXIES" default:"127.0.0.0/8,::1/128"`
+		GuestAttribution     bool          `env:"GUEST_ATTRIBUTION" default:"false"`
+		GuestCreateCooldown  time.Duration `env:"GUEST_CREATE_COOLDOWN" default:"10s"`
+		LongLinkUniqueness   string        `env:"LONG_LINK_UNIQUENESS" default:"none"`
+		AliasCategories      string        `env:"CUSTOM_ALIAS_UNICODE_CATEGORIES" default:""`
+		CustomAliasQuota     int           `env:"CUSTOM_ALIAS_QUOTA" default:"0"`
+		AutoAliasQuota       int           `env:"AUTO_ALIAS_QUOTA" default:"0"`
+		SMTPHost             string        `env:"SMTP_HOST" default:"localhost"`
+		SMTPPort             int           `env:"SMTP_PORT" default:"25"`
+		SMTPUsername         string        `env:"SMTP_USERNAME" default:""`
+		SMTPPassword         string        `env:"SMTP_PASSWORD" default:""`
+		SMTPFrom             string        `env:"SMTP_FROM" default:"noreply@short-d.com"`
+		WebhookURL           string        `env:"WEBHOOK_URL" default:""`
+		SignInRateLimit      int           `env:"SIGN_IN_RATE_LIMIT" default:"5"`
+		SignInRateWindow     time.Duration `env:"SIGN_IN_RATE_WINDOW" default:"15m"`
+		ResetRateLimit       int           `env:"PASSWORD_RESET_RATE_LIMIT" default:"3"`
+		ResetRateWindow      time.Duration `env:"PASSWORD_RESET_RATE_WINDOW" default:"1h"`
+		PasswordMinLength    int           `env:"PASSWORD_MIN_LENGTH" default:"8"`
+		PasswordCharClasses  string        `env:"PASSWORD_REQUIRED_CHARS" default:"letter,digit"`
+		ReadOnly             bool          `env:"READ_ONLY" default:"false"`
+		ProfilingEnabled     bool          `env:"PROFILING_ENABLED" default:"false"`
+		AliasRetryBudget     int           `env:"ALIAS_RETRY_BUDGET" default:"3"`
+		RelationMaxAttempts  int           `env:"SHORT_LINK_RELATION_MAX_ATTEMPTS" default:"3"`
+		RelationBackoff      time.Duration `env:"SHORT_LINK_RELATION_BACKOFF" default:"50ms"`
+		AliasReservationTTL  time.Duration `env:"ALIAS_RESERVATION_TTL" default:"10m"`
+		AliasReservationMax  time.Duration `env:"ALIAS_RESERVATION_MAX_TTL" default:"720h"`
+		ChainedLinkMode      string        `env:"CHAINED_LINK_MODE" default:"reject"`
+		ShortenerDomains     string        `env:"SHORTENER_DOMAINS" default:""`
+		ChainedMaxRedirects  int           `env:"CHAINED_LINK_MAX_REDIRECTS" default:"5"`
+		ShareURLSecret       string        `env:"SHARE_URL_SECRET" default:""`
+		VisitFlushInterval   time.Duration `env:"VISIT_COUNT_FLUSH_INTERVAL" default:"10s"`
+		VisitBufferSize      int           `env:"VISIT_COUNT_BUFFER_SIZE" default:"1000"`
+		AliasPrefix          string        `env:"ALIAS_PREFIX" default:""`
+		LongLinkPlainHTTP    string        `env:"LONG_LINK_PLAIN_HTTP" default:"allow"`
+		DefaultExpireAfter   time.Duration `env:"DEFAULT_EXPIRE_AFTER" default:"0s"`
+		RoleExpireAfter      string        `env:"ROLE_DEFAULT_EXPIRE_AFTER" default:""`
+		ExpireGracePeriod    time.Duration `env:"EXPIRE_GRACE_PERIOD" default:"0s"`
+		ExpiredInterstitial  bool          `env:"EXPIRE_GRACE_INTERSTITIAL" default:"false"`
+		PersistedQueryLimit  int           `env:"GRAPHQL_PERSISTED_QUERY_LIMIT" default:"1000"`
+		AdminAllowedIPs      string        `env:"ADMIN_ALLOWED_IPS" default:""`
+		OutboundMaxCalls     int           `env:"OUTBOUND_HTTP_MAX_CONCURRENCY" default:"100"`
+		OutboundWaitTimeout  time.Duration `env:"OUTBOUND_HTTP_WAIT_TIMEOUT" default:"5s"`
+		QueryConflict        string        `env:"PASSTHROUGH_QUERY_CONFLICT" default:"keep_long_link"`
+		RedirectStatusCode   int           `env:"REDIRECT_STATUS_CODE" default:"303"`
+		RedirectMaxAge       time.Duration `env:"REDIRECT_CACHE_MAX_AGE" default:"0s"`
+		EditableLinks        bool          `env:"SHORT_LINK_EDITABLE" default:"true"`
+		DomainRedirects      string        `env:"SHORT_LINK_DOMAIN_REDIRECTS" default:""`
+		TenantHosts          string        `env:"TENANT_HOSTS" default:""`
 	}{}
 
 	err := envConfig.ParseConfigFromEnv(&config)
@@ -76,7 +182,11 @@ func main() {
 	serviceConfig := app.ServiceConfig{
 		Runtime:              config.Runtime,
 		LogPrefix:            "Short",
-		LogLevel:             logger.LogInfo,
+		LogLevel:             config.LogLevel,
+		DBMaxOpenConns:       config.DBMaxOpenConns,
+		DBMaxIdleConns:       config.DBMaxIdleConns,
+		DBConnMaxLifetime:    config.DBConnMaxLifetime,
+		DBSlowQueryThreshold: config.DBSlowQueryThreshold,
 		RecaptchaSecret:      config.ReCaptchaSecret,
 		GithubClientID:       config.GithubClientID,
 		GithubClientSecret:   config.GithubClientSecret,
@@ -86,8 +196,15 @@ func main() {
 		GoogleClientID:       config.GoogleClientID,
 		GoogleClientSecret:   config.GoogleClientSecret,
 		GoogleRedirectURI:    config.GoogleRedirectURI,
+		TwitterClientID:      config.TwitterClientID,
+		TwitterClientSecret:  config.TwitterClientSecret,
+		TwitterRedirectURI:   config.TwitterRedirectURI,
+		AppleClientID:        config.AppleClientID,
+		AppleRedirectURI:     config.AppleRedirectURI,
 		JwtSecret:            config.JWTSecret,
 		WebFrontendURL:       config.WebFrontendURL,
+		ShortLinkBaseURL:     config.ShortLinkBaseURL,
+		LongLinkFragment:     config.LongLinkFragment,
 		GraphQLAPIPort:       config.GraphQLAPIPort,
 		HTTPAPIPort:          config.HTTPAPIPort,
 		GRPCAPIPort:          config.GRPCAPIPort,
@@ -95,8 +212,14 @@ func main() {
 		CertFilePath:         config.CertFilePath,
 		KeyFilePath:          config.KeyFilePath,
 		KeyGenBufferSize:     config.KeyGenBufferSize,
+		KeyGenBatchMinSize:   config.KeyGenBatchMinSize,
+		KeyGenBatchMaxSize:   config.KeyGenBatchMaxSize,
 		KgsHostname:          config.KgsHostname,
 		KgsPort:              config.KgsPort,
+		KgsMaxAttempts:       config.KgsMaxAttempts,
+		KgsInitialBackoff:    config.KgsInitialBackoff,
+		KgsMaxBackoff:        config.KgsMaxBackoff,
+		KgsDialTimeout:       config.KgsDialTimeout,
 		AuthTokenLifetime:    config.AuthTokenLifeTime,
 		SearchTimeout:        config.SearchTimeout,
 		SwaggerUIDir:         config.SwaggerUIDir,
@@ -107,6 +230,94 @@ func main() {
 		SegmentAPIKey:        config.SegmentAPIKey,
 		IPStackAPIKey:        config.IPStackAPIKey,
 		GoogleAPIKey:         config.GoogleAPIKey,
+		VisitorIPMode:        config.VisitorIPMode,
+		VisitorReferrer:      config.VisitorReferrer,
+		VisitorUserAgent:     config.VisitorUserAgent,
+		VisitorCampaign:      config.VisitorCampaign,
+		LinkHealthInterval:   config.LinkHealthInterval,
+		LinkHealthBatchSize:  config.LinkHealthBatchSize,
+		LinkHealthThreshold:  config.LinkHealthThreshold,
+		LinkHealthTimeout:    config.LinkHealthTimeout,
+		FeatureFlagConfig:    config.FeatureFlagConfig,
+		RiskBlockThreshold:   config.RiskBlockThreshold,
+		RiskWarnThreshold:    config.RiskWarnThreshold,
+		RiskBreakerThreshold: config.RiskBreakerThreshold,
+		RiskBreakerCooldown:  config.RiskBreakerCooldown,
+		AllowedDomains:       strings.Split(config.AllowedDomains, ","),
+		KeyGenStrategy:       config.KeyGenStrategy,
+		HashidsSalt:          config.HashidsSalt,
+		KeyGenWordListPath:   config.KeyGenWordListPath,
+		KeyGenWordCount:      config.KeyGenWordCount,
+		KeyGenWordSeparator:  config.KeyGenWordSeparator,
+		DomainDenylistPath:   config.DomainDenylistPath,
+		RedirectRateLimit:    config.RedirectRateLimit,
+		RedirectRateWindow:   config.RedirectRateWindow,
+		LinkRateWindow:       config.LinkRateWindow,
+		CORSAllowedOrigins:   strings.Split(config.CORSAllowedOrigins, ","),
+		CORSAllowedMethods:   strings.Split(config.CORSAllowedMethods, ","),
+		CORSAllowedHeaders:   strings.Split(config.CORSAllowedHeaders, ","),
+		CORSCredentials:      config.CORSCredentials,
+		ContentSecPolicy:     config.ContentSecPolicy,
+		ReferrerPolicy:       config.ReferrerPolicy,
+		RedirectReferrer:     config.RedirectReferrer,
+		ShortLinkChecks:      strings.Split(config.ShortLinkChecks, ","),
+		ShortLinkDomains:     strings.Split(config.ShortLinkDomains, ","),
+		MaxRequestBodySize:   config.MaxRequestBodySize,
+		RedirectLogSampling:  config.RedirectLogSampling,
+		NotFoundPage:         config.NotFoundPage,
+		ExpiredPage:          config.ExpiredPage,
+		URLValidationWorkers: config.URLValidationWorkers,
+		BrandProductName:     config.BrandProductName,
+		BrandLogoURL:         config.BrandLogoURL,
+		BrandPrimaryColor:    config.BrandPrimaryColor,
+		TrustedProxies:       strings.Split(config.TrustedProxies, ","),
+		GuestAttribution:     config.GuestAttribution,
+		GuestCreateCooldown:  config.GuestCreateCooldown,
+		LongLinkUniqueness:   config.LongLinkUniqueness,
+		AliasCategories:      strings.Split(config.AliasCategories, ","),
+		CustomAliasQuota:     config.CustomAliasQuota,
+		AutoAliasQuota:       config.AutoAliasQuota,
+		SMTPHost:             config.SMTPHost,
+		SMTPPort:             config.SMTPPort,
+		SMTPUsername:         config.SMTPUsername,
+		SMTPPassword:         config.SMTPPassword,
+		SMTPFrom:             config.SMTPFrom,
+		WebhookURL:           config.WebhookURL,
+		SignInRateLimit:      config.SignInRateLimit,
+		SignInRateWindow:     config.SignInRateWindow,
+		ResetRateLimit:       config.ResetRateLimit,
+		ResetRateWindow:      config.ResetRateWindow,
+		PasswordMinLength:    config.PasswordMinLength,
+		PasswordCharClasses:  strings.Split(config.PasswordCharClasses, ","),
+		ReadOnly:             config.ReadOnly,
+		ProfilingEnabled:     config.ProfilingEnabled,
+		AliasRetryBudget:     config.AliasRetryBudget,
+		RelationMaxAttempts:  config.RelationMaxAttempts,
+		RelationBackoff:      config.RelationBackoff,
+		AliasReservationTTL:  config.AliasReservationTTL,
+		AliasReservationMax:  config.AliasReservationMax,
+		ChainedLinkMode:      config.ChainedLinkMode,
+		ShortenerDomains:     strings.Split(config.ShortenerDomains, ","),
+		ChainedMaxRedirects:  config.ChainedMaxRedirects,
+		ShareURLSecret:       config.ShareURLSecret,
+		VisitFlushInterval:   config.VisitFlushInterval,
+		VisitBufferSize:      config.VisitBufferSize,
+		AliasPrefix:          config.AliasPrefix,
+		LongLinkPlainHTTP:    config.LongLinkPlainHTTP,
+		DefaultExpireAfter:   config.DefaultExpireAfter,
+		RoleExpireAfter:      strings.Split(config.RoleExpireAfter, ","),
+		ExpireGracePeriod:    config.ExpireGracePeriod,
+		ExpiredInterstitial:  config.ExpiredInterstitial,
+		PersistedQueryLimit:  config.PersistedQueryLimit,
+		AdminAllowedIPs:      strings.Split(config.AdminAllowedIPs, ","),
+		OutboundMaxCalls:     config.OutboundMaxCalls,
+		OutboundWaitTimeout:  config.OutboundWaitTimeout,
+		QueryConflict:        config.QueryConflict,
+		RedirectStatusCode:   config.RedirectStatusCode,
+		RedirectMaxAge:       config.RedirectMaxAge,
+		EditableLinks:        config.EditableLinks,
+		DomainRedirects:      strings.Split(config.DomainRedirects, ","),
+		TenantHosts:          strings.Split(config.TenantHosts, ","),
 	}
 
 	rootCmd := cmd.NewRootCmd(
@@ -0,0 +1,164 @@
+package tool
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/short-d/short/backend/app/entity"
+)
+
+type column string
+
+const (
+	columnAlias     column = "alias"
+	columnLongLink  column = "long_link"
+	columnCreatedAt column = "created_at"
+	columnExpireAt  column = "expire_at"
+)
+
+// headerColumns maps the headers used by the exports of different URL
+// shorteners, such as Bitly, to the columns the import understands.
+var headerColumns = map[string]column{
+	"alias":         columnAlias,
+	"custom_alias":  columnAlias,
+	"bitlink":       columnAlias,
+	"short_url":     columnAlias,
+	"short_link":    columnAlias,
+	"long_link":     columnLongLink,
+	"long_url":      columnLongLink,
+	"url":           columnLongLink,
+	"destination":   columnLongLink,
+	"created_at":    columnCreatedAt,
+	"created":       columnCreatedAt,
+	"creation_date": columnCreatedAt,
+	"expire_at":     columnExpireAt,
+	"expires_at":    columnExpireAt,
+}
+
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// ErrInvalidExport represents the export can't be parsed.
+type ErrInvalidExport string
+
+func (e ErrInvalidExport) Error() string {
+	return string(e)
+}
+
+// ParseExport reads the short links from the CSV export of another URL
+// shortener. The first row is the header naming the columns. The alias may be
+// the full short link, in which case the last path segment is used. Times
+// without time zone are treated as UTC.
+func ParseExport(export io.Reader) ([]entity.ShortLinkInput, error) {
+	reader := csv.NewReader(export)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return []entity.ShortLinkInput{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns, err := parseHeader(header)
+	if err != nil {
+		return nil, err
+	}
+
+	shortLinkInputs := []entity.ShortLinkInput{}
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return shortLinkInputs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		shortLinkInput, err := parseRecord(record, columns)
+		if err != nil {
+			return nil, ErrInvalidExport(fmt.Sprintf("row %d: %s", row, err))
+		}
+		shortLinkInputs = append(shortLinkInputs, shortLinkInput)
+	}
+}
+
+func parseHeader(header []string) (map[column]int, error) {
+	columns := make(map[column]int)
+	for idx, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		name = strings.ReplaceAll(name, " ", "_")
+		col, ok := headerColumns[name]
+		if !ok {
+			continue
+		}
+		if _, ok = columns[col]; !ok {
+			columns[col] = idx
+		}
+	}
+
+	if _, ok := columns[columnAlias]; !ok {
+		return nil, ErrInvalidExport("alias column not found")
+	}
+	if _, ok := columns[columnLongLink]; !ok {
+		return nil, ErrInvalidExport("long link column not found")
+	}
+	return columns, nil
+}
+
+func parseRecord(record []string, columns map[column]int) (entity.ShortLinkInput, error) {
+	alias := parseAlias(getField(record, columns, columnAlias))
+	longLink := getField(record, columns, columnLongLink)
+	shortLinkInput := entity.ShortLinkInput{
+		CustomAlias: &alias,
+		LongLink:    &longLink,
+	}
+
+	createdAt, err := parseTime(getField(record, columns, columnCreatedAt))
+	if err != nil {
+		return entity.ShortLinkInput{}, err
+	}
+	shortLinkInput.CreatedAt = createdAt
+
+	expireAt, err := parseTime(getField(record, columns, columnExpireAt))
+	if err != nil {
+		return entity.ShortLinkInput{}, err
+	}
+	shortLinkInput.ExpireAt = expireAt
+	return shortLinkInput, nil
+}
+
+func getField(record []string, columns map[column]int, col column) string {
+	idx, ok := columns[col]
+	if !ok || idx >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[idx])
+}
+
+func parseAlias(alias string) string {
+	alias = strings.TrimRight(alias, "/")
+	idx := strings.LastIndex(alias, "/")
+	return alias[idx+1:]
+}
+
+func parseTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	for _, layout := range timeLayouts {
+		t, err := time.Parse(layout, value)
+		if err == nil {
+			t = t.UTC()
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown time format: %s", value)
+}
@@ -0,0 +1,83 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/short-d/app/fw/logger"
+	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/shortlink"
+)
+
+// Import moves short links exported from another URL shortener into Short.
+type Import struct {
+	importer shortlink.Importer
+	userRepo repository.User
+	logger   logger.Logger
+}
+
+// ImportCSV creates the short links in the CSV export for the owner with the
+// given email, and logs how the taken aliases are resolved and the aliases
+// which are skipped. Nothing is saved during a dry run.
+func (i Import) ImportCSV(
+	export io.Reader,
+	ownerEmail string,
+	options shortlink.ImportOptions,
+) (shortlink.ImportReport, error) {
+	owner, err := i.userRepo.GetUserByEmail(ownerEmail)
+	if err != nil {
+		return shortlink.ImportReport{}, err
+	}
+
+	shortLinkInputs, err := ParseExport(export)
+	if err != nil {
+		return shortlink.ImportReport{}, err
+	}
+
+	report, err := i.importer.ImportShortLinks(context.Background(), shortLinkInputs, owner, options)
+	if err != nil {
+		return report, err
+	}
+
+	for _, duplicate := range report.Duplicates {
+		switch duplicate.Resolution {
+		case shortlink.DuplicateAliasOverwrite:
+			i.logger.Info(fmt.Sprintf("Overwrote %s: alias already exists", duplicate.Alias))
+		case shortlink.DuplicateAliasRename:
+			i.logger.Info(fmt.Sprintf("Renamed %s to %s: alias already exists", duplicate.Alias, duplicate.ResolvedAlias))
+		default:
+			i.logger.Info(fmt.Sprintf("Skipped %s: alias already exists", duplicate.Alias))
+		}
+	}
+	for _, failure := range report.Failures {
+		i.logger.Info(fmt.Sprintf("Skipped %s: %s", failure.Alias, failure.Err))
+	}
+
+	action := "Imported"
+	if options.IsDryRun {
+		action = "Would import"
+	}
+	i.logger.Info(fmt.Sprintf(
+		"%s %d short links, resolved %d duplicate aliases, skipped %d collisions and %d invalid short links.",
+		action,
+		len(report.Created),
+		len(report.Duplicates)-len(report.Collisions),
+		len(report.Collisions),
+		len(report.Failures),
+	))
+	return report, nil
+}
+
+// NewImport creates Import
+func NewImport(
+	importer shortlink.Importer,
+	userRepo repository.User,
+	logger logger.Logger,
+) Import {
+	return Import{
+		importer: importer,
+		userRepo: userRepo,
+		logger:   logger,
+	}
+}
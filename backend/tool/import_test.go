@@ -0,0 +1,158 @@
+// +build !integration all
+
+package tool
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/short-d/app/fw/assert"
+	"github.com/short-d/app/fw/logger"
+	"github.com/short-d/app/fw/timer"
+	"github.com/short-d/short/backend/app/entity"
+	"github.com/short-d/short/backend/app/usecase/repository"
+	"github.com/short-d/short/backend/app/usecase/shortlink"
+	"github.com/short-d/short/backend/app/usecase/validator"
+)
+
+const bitlyExport = `Created,Title,Long URL,Bitlink,Custom Bitlinks
+2018-03-14 09:26:53,Google,https://www.google.com,https://bit.ly/2Xk3cvA,
+2019-07-01 18:00:00,GitHub,https://github.com,bit.ly/3fQx8yz,
+2019-08-02 12:30:00,Short,https://short-d.com/about,bit.ly/short,
+`
+
+func TestImport_ImportCSV(t *testing.T) {
+	t.Parallel()
+
+	owner := entity.User{ID: "alpha", Email: "alpha@example.com"}
+	now := time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
+	googleCreatedAt := time.Date(2018, 3, 14, 9, 26, 53, 0, time.UTC)
+	githubCreatedAt := time.Date(2019, 7, 1, 18, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name               string
+		export             string
+		shortLinks         map[string]entity.ShortLink
+		isDryRun           bool
+		duplicateAlias     shortlink.DuplicateAlias
+		expHasErr          bool
+		expectedCreated    []entity.ShortLink
+		expectedCollisions []string
+		expectedAliases    []string
+	}{
+		{
+			name:       "import Bitly export",
+			export:     bitlyExport,
+			shortLinks: map[string]entity.ShortLink{},
+			isDryRun:   false,
+			expectedCreated: []entity.ShortLink{
+				{Alias: "2Xk3cvA", LongLink: "https://www.google.com", OriginalLongLink: "https://www.google.com", CreatedAt: &googleCreatedAt, TrackVisits: true},
+				{Alias: "3fQx8yz", LongLink: "https://github.com", OriginalLongLink: "https://github.com", CreatedAt: &githubCreatedAt, TrackVisits: true},
+				{Alias: "short", LongLink: "https://short-d.com/about", OriginalLongLink: "https://short-d.com/about", CreatedAt: timePtr(time.Date(2019, 8, 2, 12, 30, 0, 0, time.UTC)), TrackVisits: true},
+			},
+			expectedCollisions: []string{},
+			expectedAliases:    []string{"2Xk3cvA", "3fQx8yz", "short"},
+		},
+		{
+			name:   "skip collided aliases",
+			export: bitlyExport,
+			shortLinks: map[string]entity.ShortLink{
+				"short": {Alias: "short", LongLink: "https://www.bing.com"},
+			},
+			isDryRun: false,
+			expectedCreated: []entity.ShortLink{
+				{Alias: "2Xk3cvA", LongLink: "https://www.google.com", OriginalLongLink: "https://www.google.com", CreatedAt: &googleCreatedAt, TrackVisits: true},
+				{Alias: "3fQx8yz", LongLink: "https://github.com", OriginalLongLink: "https://github.com", CreatedAt: &githubCreatedAt, TrackVisits: true},
+			},
+			expectedCollisions: []string{"short"},
+			expectedAliases:    []string{"2Xk3cvA", "3fQx8yz"},
+		},
+		{
+			name:   "rename collided aliases",
+			export: bitlyExport,
+			shortLinks: map[string]entity.ShortLink{
+				"short": {Alias: "short", LongLink: "https://www.bing.com"},
+			},
+			isDryRun:       false,
+			duplicateAlias: shortlink.DuplicateAliasRename,
+			expectedCreated: []entity.ShortLink{
+				{Alias: "2Xk3cvA", LongLink: "https://www.google.com", OriginalLongLink: "https://www.google.com", CreatedAt: &googleCreatedAt, TrackVisits: true},
+				{Alias: "3fQx8yz", LongLink: "https://github.com", OriginalLongLink: "https://github.com", CreatedAt: &githubCreatedAt, TrackVisits: true},
+				{Alias: "short-2", LongLink: "https://short-d.com/about", OriginalLongLink: "https://short-d.com/about", CreatedAt: timePtr(time.Date(2019, 8, 2, 12, 30, 0, 0, time.UTC)), TrackVisits: true},
+			},
+			expectedCollisions: []string{},
+			expectedAliases:    []string{"2Xk3cvA", "3fQx8yz", "short-2"},
+		},
+		{
+			name: "import without header aliases",
+			export: `alias,long_link,created_at
+docs,https://github.com/short-d/short/wiki,2020-01-02T03:04:05-08:00
+home,https://short-d.com,
+`,
+			shortLinks: map[string]entity.ShortLink{},
+			isDryRun:   true,
+			expectedCreated: []entity.ShortLink{
+				{Alias: "docs", LongLink: "https://github.com/short-d/short/wiki", OriginalLongLink: "https://github.com/short-d/short/wiki", CreatedAt: timePtr(time.Date(2020, 1, 2, 11, 4, 5, 0, time.UTC)), TrackVisits: true},
+				{Alias: "home", LongLink: "https://short-d.com", OriginalLongLink: "https://short-d.com", CreatedAt: &now, TrackVisits: true},
+			},
+			expectedCollisions: []string{},
+			expectedAliases:    []string{},
+		},
+		{
+			name:      "long link column missing",
+			export:    "alias,created_at\ndocs,2020-01-02\n",
+			expHasErr: true,
+		},
+		{
+			name:      "malformed creation time",
+			export:    "alias,long_link,created_at\ndocs,https://short-d.com,yesterday\n",
+			expHasErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			userRepo := repository.NewUserFake([]entity.User{owner})
+			userShortLinkRepo := repository.NewUserShortLinkRepoFake(nil, nil)
+			shortLinkRepo := repository.NewShortLinkFake(&userShortLinkRepo, testCase.shortLinks)
+			importer := shortlink.NewImporterPersist(
+				&shortLinkRepo,
+				&userShortLinkRepo,
+				validator.NewLongLink(nil, nil, validator.FragmentPreserve, validator.PlainHTTPAllow),
+				validator.NewCustomAlias(),
+				timer.NewStub(now),
+			)
+
+			entryRepo := logger.NewEntryRepoFake()
+			lg, err := logger.NewFake(logger.LogOff, &entryRepo)
+			assert.Equal(t, nil, err)
+
+			tool := NewImport(importer, &userRepo, lg)
+			options := shortlink.ImportOptions{
+				IsDryRun:       testCase.isDryRun,
+				DuplicateAlias: testCase.duplicateAlias,
+			}
+			report, err := tool.ImportCSV(strings.NewReader(testCase.export), owner.Email, options)
+			if testCase.expHasErr {
+				assert.NotEqual(t, nil, err)
+				return
+			}
+			assert.Equal(t, nil, err)
+			assert.Equal(t, testCase.expectedCreated, report.Created)
+			assert.Equal(t, testCase.expectedCollisions, report.Collisions)
+
+			aliases, err := userShortLinkRepo.FindAliasesByUser(context.Background(), owner)
+			assert.Equal(t, nil, err)
+			assert.SameElements(t, testCase.expectedAliases, aliases)
+		})
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
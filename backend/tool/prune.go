@@ -0,0 +1,48 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/short-d/app/fw/logger"
+	"github.com/short-d/short/backend/app/usecase/shortlink"
+)
+
+// Prune removes the data left behind by the short links which no longer
+// exist.
+type Prune struct {
+	orphanPruner shortlink.OrphanPruner
+	logger       logger.Logger
+}
+
+// OrphanRelations removes the relations between users and the short links
+// which no longer exist, batchSize short links at a time. Nothing is removed
+// during a dry run.
+func (p Prune) OrphanRelations(batchSize int, isDryRun bool) (shortlink.PruneReport, error) {
+	report, err := p.orphanPruner.PruneOrphanRelations(context.Background(), batchSize, isDryRun)
+	if err != nil {
+		return report, err
+	}
+
+	if isDryRun {
+		p.logger.Info(fmt.Sprintf(
+			"Would remove the relations of %d missing short links.",
+			report.OrphanAliases,
+		))
+		return report, nil
+	}
+	p.logger.Info(fmt.Sprintf(
+		"Removed %d relations of %d missing short links.",
+		report.RemovedRelations,
+		report.OrphanAliases,
+	))
+	return report, nil
+}
+
+// NewPrune creates Prune
+func NewPrune(orphanPruner shortlink.OrphanPruner, logger logger.Logger) Prune {
+	return Prune{
+		orphanPruner: orphanPruner,
+		logger:       logger,
+	}
+}